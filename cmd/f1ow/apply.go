@@ -0,0 +1,113 @@
+package main
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/nuumz/f1ow/pkg/client"
+
+	"github.com/spf13/cobra"
+)
+
+func newApplyCommand() *cobra.Command {
+	var source string
+	var dryRun bool
+	cmd := &cobra.Command{
+		Use:   "apply <dir>",
+		Short: "Make the server's workflows match the definitions in a directory",
+		Long: `Make the server's workflows match the workflow files (.json, .yaml, or .yml)
+in a directory and its subdirectories, one workflow per file. Workflows are
+matched by name: missing ones are created, changed ones updated, and active
+workflows applied earlier from the same --source whose files were removed
+are deactivated. A workflow created by hand with the same name as a file is
+taken over. Use --dry-run to see the changes first.`,
+		Args: cobra.ExactArgs(1),
+	}
+	flags := addClientFlags(cmd)
+	cmd.Flags().StringVar(&source, "source", "default", "name of the set of workflows the directory manages")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "print the changes without making them")
+
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		workflows, err := readWorkflowDir(args[0])
+		if err != nil {
+			return err
+		}
+
+		result, err := flags.client().SyncWorkflows(cmd.Context(), &client.SyncRequest{
+			Source:    source,
+			Workflows: workflows,
+			DryRun:    dryRun,
+		})
+		if err != nil {
+			return err
+		}
+
+		out := cmd.OutOrStdout()
+		changed := 0
+		for _, change := range result.Changes {
+			if change.Action == "unchanged" {
+				continue
+			}
+			changed++
+			fmt.Fprintf(out, "%-10s  %s\n", change.Action, change.Name)
+			for _, field := range change.Changes {
+				fmt.Fprintf(out, "            ~ %s\n", field.Path)
+			}
+		}
+		switch {
+		case changed == 0:
+			fmt.Fprintf(out, "%d workflows up to date\n", len(result.Changes))
+		case dryRun:
+			fmt.Fprintf(out, "%d of %d workflows would change (dry run)\n", changed, len(result.Changes))
+		default:
+			fmt.Fprintf(out, "%d of %d workflows changed\n", changed, len(result.Changes))
+		}
+		return nil
+	}
+	return cmd
+}
+
+// readWorkflowDir reads the workflow files under dir, rejecting two files
+// that define the same workflow
+func readWorkflowDir(dir string) ([]client.Workflow, error) {
+	var workflows []client.Workflow
+	paths := map[string]string{}
+	err := filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		switch strings.ToLower(filepath.Ext(path)) {
+		case ".json", ".yaml", ".yml":
+		default:
+			return nil
+		}
+		if entry.IsDir() {
+			return nil
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		file, err := parseWorkflowFile(path, data)
+		if err != nil {
+			return err
+		}
+		if other, ok := paths[file.Name]; ok {
+			return fmt.Errorf("%s and %s both define workflow %q", other, path, file.Name)
+		}
+		paths[file.Name] = path
+		workflows = append(workflows, file.workflow())
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(workflows) == 0 {
+		return nil, fmt.Errorf("no workflow files in %s", dir)
+	}
+	return workflows, nil
+}
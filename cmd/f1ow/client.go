@@ -0,0 +1,50 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+
+	"github.com/nuumz/f1ow/pkg/client"
+
+	"github.com/spf13/cobra"
+)
+
+// clientFlags are the flags of the commands that call a running server
+type clientFlags struct {
+	server string
+	token  string
+	apiKey string
+}
+
+// addClientFlags adds the server URL and credential flags to cmd. They
+// default to F1OW_SERVER, F1OW_TOKEN, and F1OW_API_KEY.
+func addClientFlags(cmd *cobra.Command) *clientFlags {
+	flags := &clientFlags{}
+	server := os.Getenv("F1OW_SERVER")
+	if server == "" {
+		server = "http://localhost:8080"
+	}
+	cmd.Flags().StringVar(&flags.server, "server", server, "URL of the f1ow server")
+	cmd.Flags().StringVar(&flags.token, "token", os.Getenv("F1OW_TOKEN"), "JWT bearer token")
+	cmd.Flags().StringVar(&flags.apiKey, "api-key", os.Getenv("F1OW_API_KEY"), "API key")
+	return flags
+}
+
+// client returns an API client for the server the flags name
+func (f *clientFlags) client() *client.Client {
+	var opts []client.Option
+	if f.token != "" {
+		opts = append(opts, client.WithToken(f.token))
+	}
+	if f.apiKey != "" {
+		opts = append(opts, client.WithAPIKey(f.apiKey))
+	}
+	return client.New(f.server, opts...)
+}
+
+// printJSON writes v to stdout as indented JSON
+func printJSON(cmd *cobra.Command, v interface{}) error {
+	encoder := json.NewEncoder(cmd.OutOrStdout())
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
@@ -0,0 +1,156 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/nuumz/f1ow/pkg/client"
+
+	"github.com/spf13/cobra"
+)
+
+func newExecuteCommand() *cobra.Command {
+	var input, environment string
+	var follow bool
+	cmd := &cobra.Command{
+		Use:   "execute <workflow-id>",
+		Short: "Execute a workflow and print the execution",
+		Long: `Execute a workflow and print the execution. With --follow the node events
+are printed as they happen until the execution finishes, and the command
+exits non-zero unless it completed, for use in CI.`,
+		Args: cobra.ExactArgs(1),
+	}
+	flags := addClientFlags(cmd)
+	cmd.Flags().StringVar(&input, "input", "", "input as a JSON object, or @file to read it from a file (@- for stdin)")
+	cmd.Flags().StringVar(&environment, "environment", "", "run the version deployed to this environment")
+	cmd.Flags().BoolVarP(&follow, "follow", "f", false, "stream the execution's events until it finishes")
+
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		payload, err := readInput(cmd, input)
+		if err != nil {
+			return err
+		}
+
+		api := flags.client()
+		execution, err := api.ExecuteWorkflow(cmd.Context(), args[0], payload, &client.ExecuteWorkflowParams{Environment: environment})
+		if err != nil {
+			return err
+		}
+		if !follow {
+			return printJSON(cmd, execution)
+		}
+
+		out := cmd.OutOrStdout()
+		fmt.Fprintf(out, "Execution %s started\n", execution.ID)
+		execution, err = api.FollowExecution(cmd.Context(), execution.ID.String(), func(event client.ExecutionEvent) error {
+			printEvent(out, event)
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		duration := "-"
+		if execution.CompletedAt != nil {
+			duration = execution.CompletedAt.Sub(execution.StartedAt).Round(time.Millisecond).String()
+		}
+		fmt.Fprintf(out, "Execution %s %s in %s\n", execution.ID, execution.Status, duration)
+		if execution.Status != "completed" {
+			message := execution.Status
+			if execution.Error != nil {
+				message = *execution.Error
+			}
+			return fmt.Errorf("execution %s: %s", execution.Status, message)
+		}
+		return printJSON(cmd, execution.Output)
+	}
+	return cmd
+}
+
+// readInput parses the --input flag: a JSON object, or @path to read one
+// from a file, with @- for stdin. An empty flag is an empty input.
+func readInput(cmd *cobra.Command, input string) (map[string]interface{}, error) {
+	data := []byte(input)
+	if path, ok := strings.CutPrefix(input, "@"); ok {
+		var err error
+		if path == "-" {
+			data, err = io.ReadAll(cmd.InOrStdin())
+		} else {
+			data, err = os.ReadFile(path)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	payload := map[string]interface{}{}
+	if len(bytes.TrimSpace(data)) > 0 {
+		if err := json.Unmarshal(data, &payload); err != nil {
+			return nil, fmt.Errorf("input must be a JSON object: %w", err)
+		}
+	}
+	return payload, nil
+}
+
+// printEvent writes an execution event as a line of the followed log
+func printEvent(w io.Writer, event client.ExecutionEvent) {
+	line := fmt.Sprintf("%s  %-20s", event.Time.Local().Format("15:04:05.000"), event.Type)
+	if event.NodeID != "" {
+		line += "  " + event.NodeID
+	}
+	if event.Error != "" {
+		line += "  error: " + event.Error
+	}
+	fmt.Fprintln(w, strings.TrimRight(line, " "))
+}
+
+func newListCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List resources of a server",
+	}
+
+	var params client.ListExecutionsParams
+	var jsonOutput bool
+	executions := &cobra.Command{
+		Use:   "executions",
+		Short: "List executions, most recent first",
+		Args:  cobra.NoArgs,
+	}
+	flags := addClientFlags(executions)
+	executions.Flags().StringVar(&params.WorkflowID, "workflow", "", "only executions of this workflow")
+	executions.Flags().StringSliceVar(&params.Status, "status", nil, "only executions with these statuses")
+	executions.Flags().IntVar(&params.Limit, "limit", 20, "maximum number of executions")
+	executions.Flags().BoolVar(&jsonOutput, "json", false, "print the executions as JSON")
+	executions.RunE = func(cmd *cobra.Command, args []string) error {
+		params.Fields = "summary"
+		list, err := flags.client().ListExecutions(cmd.Context(), &params)
+		if err != nil {
+			return err
+		}
+		if jsonOutput {
+			return printJSON(cmd, list)
+		}
+
+		w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, "ID\tWORKFLOW\tSTATUS\tSTARTED\tDURATION")
+		for _, execution := range list {
+			duration := "-"
+			if execution.CompletedAt != nil {
+				duration = execution.CompletedAt.Sub(execution.StartedAt).Round(time.Millisecond).String()
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", execution.ID, execution.WorkflowID, execution.Status,
+				execution.StartedAt.Local().Format(time.DateTime), duration)
+		}
+		return w.Flush()
+	}
+
+	cmd.AddCommand(executions)
+	return cmd
+}
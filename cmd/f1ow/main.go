@@ -0,0 +1,71 @@
+// Command f1ow runs the f1ow API server, workers, and scheduler, migrates
+// the database, and calls a running server's API:
+//
+//	f1ow serve                      # API server
+//	f1ow worker                     # run queued jobs
+//	f1ow scheduler                  # run triggers without taking jobs
+//	f1ow migrate [up|status|baseline <version>]
+//	f1ow export workflow <id>       # print a workflow as JSON
+//	f1ow import workflow <file>     # create a workflow from a file
+//	f1ow apply <dir>                # sync workflows with a directory
+//	f1ow execute <workflow-id>      # queue an execution
+//	f1ow list executions            # recent executions
+package main
+
+import (
+	"os"
+
+	"github.com/nuumz/f1ow/internal/config"
+
+	"github.com/joho/godotenv"
+	"github.com/spf13/cobra"
+)
+
+// version is set at build time with -ldflags "-X main.version=..."
+var version = "dev"
+
+func main() {
+	if err := newRootCommand().Execute(); err != nil {
+		os.Exit(1)
+	}
+}
+
+func newRootCommand() *cobra.Command {
+	root := &cobra.Command{
+		Use:          "f1ow",
+		Short:        "Workflow automation server, worker, and command line client",
+		Version:      version,
+		SilenceUsage: true,
+	}
+	root.PersistentFlags().String("config", os.Getenv("F1OW_CONFIG"), "YAML or TOML config file; environment variables override its settings")
+
+	root.AddCommand(
+		newServeCommand(),
+		newWorkerCommand(),
+		newSchedulerCommand(),
+		newMigrateCommand(),
+		newExportCommand(),
+		newImportCommand(),
+		newApplyCommand(),
+		newExecuteCommand(),
+		newListCommand(),
+	)
+	return root
+}
+
+// loadConfig reads the configuration of the server commands: .env files in
+// the working directory, if any, then the --config file and the environment
+func loadConfig(cmd *cobra.Command) (*config.Config, error) {
+	// godotenv never overrides variables that are already set
+	for _, file := range []string{".env", ".env.development", ".env.mysql"} {
+		if err := godotenv.Load(file); err == nil {
+			break
+		}
+	}
+
+	path, err := cmd.Flags().GetString("config")
+	if err != nil {
+		return nil, err
+	}
+	return config.Load(path)
+}
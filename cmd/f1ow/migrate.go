@@ -0,0 +1,89 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/nuumz/f1ow/internal/storage"
+
+	"github.com/spf13/cobra"
+)
+
+func newMigrateCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "migrate",
+		Short: "Apply pending database migrations",
+		Args:  cobra.NoArgs,
+		RunE: withDB(func(db *storage.DB, args []string) error {
+			return migrate(db)
+		}),
+	}
+
+	cmd.AddCommand(
+		&cobra.Command{
+			Use:   "up",
+			Short: "Apply pending database migrations",
+			Args:  cobra.NoArgs,
+			RunE: withDB(func(db *storage.DB, args []string) error {
+				return migrate(db)
+			}),
+		},
+		&cobra.Command{
+			Use:   "status",
+			Short: "List migrations and when they were applied",
+			Args:  cobra.NoArgs,
+			RunE: withDB(func(db *storage.DB, args []string) error {
+				statuses, err := db.MigrationStatus(context.Background())
+				if err != nil {
+					return err
+				}
+				for _, status := range statuses {
+					applied := "pending"
+					if status.AppliedAt != nil {
+						applied = "applied " + status.AppliedAt.Format(time.RFC3339)
+					}
+					fmt.Printf("%03d_%s\t%s\n", status.Version, status.Name, applied)
+				}
+				return nil
+			}),
+		},
+		&cobra.Command{
+			Use:   "baseline <version>",
+			Short: "Record migrations up to version as applied without running them",
+			Long: `Record migrations up to version as applied without running them, for a
+database created before migrations were tracked.`,
+			Args: cobra.ExactArgs(1),
+			RunE: withDB(func(db *storage.DB, args []string) error {
+				version, err := strconv.Atoi(args[0])
+				if err != nil {
+					return fmt.Errorf("invalid version %q", args[0])
+				}
+				if err := db.BaselineMigrations(context.Background(), version); err != nil {
+					return err
+				}
+				log.Printf("Recorded migrations up to %03d as applied", version)
+				return nil
+			}),
+		},
+	)
+	return cmd
+}
+
+// withDB runs fn with a connection to the configured database
+func withDB(fn func(db *storage.DB, args []string) error) func(cmd *cobra.Command, args []string) error {
+	return func(cmd *cobra.Command, args []string) error {
+		cfg, err := loadConfig(cmd)
+		if err != nil {
+			return err
+		}
+		db, err := storage.NewDB(cfg.Database.URL)
+		if err != nil {
+			return fmt.Errorf("failed to connect to database: %w", err)
+		}
+		defer db.Close()
+		return fn(db, args)
+	}
+}
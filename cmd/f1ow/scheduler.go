@@ -0,0 +1,69 @@
+package main
+
+import (
+	"context"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/nuumz/f1ow/internal/config"
+	"github.com/nuumz/f1ow/internal/engine"
+	"github.com/nuumz/f1ow/internal/scheduling"
+	"github.com/nuumz/f1ow/internal/storage"
+
+	"github.com/spf13/cobra"
+)
+
+func newSchedulerCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "scheduler",
+		Short: "Run triggers, retention, and the execution reaper without taking jobs",
+		Long: `Run the triggers of active workflows, execution retention and binary data
+cleanup, and the reaper that requeues executions of stopped workers. The
+executions it starts are queued for workers; run workers with
+--scheduler=false to leave this work to the scheduler.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := loadConfig(cmd)
+			if err != nil {
+				return err
+			}
+			runScheduler(cfg)
+			return nil
+		},
+	}
+}
+
+// runScheduler runs the scheduling work until SIGINT or SIGTERM
+func runScheduler(cfg *config.Config) {
+	if cfg.Redis.URL == "" {
+		log.Fatal("The scheduler queues executions in Redis; with REDIS_URL empty run the server alone")
+	}
+
+	log.Println("Starting workflow engine scheduler...")
+
+	db, err := storage.NewDB(cfg.Database.URL)
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+	defer db.Close()
+
+	redis := connectRedis(cfg.Redis)
+	defer redis.Close()
+
+	// Initialize workflow engine with the node types workflows use
+	options, binaryData := engineOptions(db, redis, cfg)
+	eng := engine.NewEngine(db, redis, options...)
+	registerNodeTypes(eng, db, redis, cfg.Nodes)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	scheduling.Start(ctx, eng, db, redis, binaryData, cfg)
+
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+	<-quit
+
+	log.Println("Scheduler stopped")
+}
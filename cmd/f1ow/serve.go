@@ -0,0 +1,314 @@
+package main
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/nuumz/f1ow/internal/alerting"
+	"github.com/nuumz/f1ow/internal/api"
+	"github.com/nuumz/f1ow/internal/auth"
+	"github.com/nuumz/f1ow/internal/config"
+	"github.com/nuumz/f1ow/internal/engine"
+	"github.com/nuumz/f1ow/internal/models"
+	"github.com/nuumz/f1ow/internal/netpolicy"
+	"github.com/nuumz/f1ow/internal/ratelimit"
+	"github.com/nuumz/f1ow/internal/schedules"
+	"github.com/nuumz/f1ow/internal/scheduling"
+	"github.com/nuumz/f1ow/internal/storage"
+	"github.com/nuumz/f1ow/internal/subscriptions"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+func newServeCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "serve",
+		Short: "Run the API server",
+		Long: `Run the API server. Pending migrations are applied on startup unless
+server.migrate_on_start is false. Without Redis the server also queues and
+runs executions itself, and runs triggers and retention.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := loadConfig(cmd)
+			if err != nil {
+				return err
+			}
+			serve(cfg)
+			return nil
+		},
+	}
+}
+
+// serve runs the API server until SIGINT or SIGTERM
+func serve(cfg *config.Config) {
+	log.Printf("Starting workflow engine on port %s", cfg.Server.Port)
+	log.Printf("Database URL: %s", maskPassword(cfg.Database.URL))
+	log.Printf("Redis URL: %s", cfg.Redis.URL)
+	log.Printf("Debug mode: %v", cfg.Server.Debug)
+
+	// Initialize database
+	db, err := storage.NewDB(cfg.Database.URL)
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+	defer db.Close()
+
+	if cfg.Server.MigrateOnStart {
+		if err := migrate(db); err != nil {
+			log.Fatalf("Failed to migrate database: %v", err)
+		}
+	}
+
+	// Serve listings and stats from read replicas, if any
+	replicaCtx, stopReplicaChecks := context.WithCancel(context.Background())
+	defer stopReplicaChecks()
+	connectReplicas(replicaCtx, db, cfg.Database)
+
+	// Initialize Redis, unless its URL is set empty
+	redis := connectRedis(cfg.Redis)
+	if redis != nil {
+		defer redis.Close()
+	}
+
+	// Initialize workflow engine
+	options, binaryData := engineOptions(db, redis, cfg)
+	if redis == nil {
+		// Jobs wait in this process, persisted in the database, and run on
+		// the worker started below
+		log.Println("REDIS_URL is empty; jobs are queued in process and run by this server")
+		queue := engine.NewMemoryQueue(db)
+		if err := queue.Load(context.Background()); err != nil {
+			log.Fatalf("Failed to restore queued jobs: %v", err)
+		}
+		options = append(options, engine.WithQueue(queue), engine.WithWorker(workerOptions(cfg.Worker)))
+	}
+	eng := engine.NewEngine(db, redis, options...)
+
+	// Register built-in node types
+	registerNodeTypes(eng, db, redis, cfg.Nodes)
+
+	// Keep the connection pool gauges current
+	poolCtx, stopPoolMetrics := context.WithCancel(context.Background())
+	defer stopPoolMetrics()
+	eng.StartPoolMetrics(poolCtx, 15*time.Second)
+
+	// Evaluate alert rules in the background
+	networkPolicy := newNetworkPolicy(db, cfg.Network)
+	alerts := newAlertsManager(db, cfg.SMTP, networkPolicy)
+	backgroundCtx, stopBackground := context.WithCancel(context.Background())
+	defer stopBackground()
+	var alertsLocker alerting.Locker
+	var deliveriesLocker subscriptions.Locker
+	if redis != nil {
+		locker := engine.NewRedisTriggerLocker(redis)
+		alertsLocker, deliveriesLocker = locker, locker
+	}
+	go alerts.Run(backgroundCtx, cfg.Alerts.EvaluationInterval, alertsLocker)
+
+	// Deliver events to subscribed URLs and retry failed deliveries
+	subscriptionsManager := subscriptions.NewManager(db, logrus.StandardLogger())
+	subscriptionsManager.SetNetworkPolicy(networkPolicy)
+	go consumeEventSubscriptions(backgroundCtx, eng, subscriptionsManager)
+	go subscriptionsManager.Run(backgroundCtx, 15*time.Second, deliveriesLocker)
+
+	// Queue executions of cron schedules when they are due
+	schedulesManager := schedules.NewManager(db, func(ctx context.Context, workflowID string, input map[string]interface{}) (*models.Execution, error) {
+		return eng.Submit(ctx, workflowID, "", input)
+	}, logrus.StandardLogger())
+	go schedulesManager.Run(backgroundCtx, 15*time.Second)
+
+	// Without Redis no separate worker or scheduler can reach the queue, so
+	// this process runs the jobs and the scheduling work
+	var workerStopped <-chan struct{}
+	if redis == nil {
+		workerStopped = scheduling.RunInProcess(backgroundCtx, eng, db, binaryData, cfg)
+	} else {
+		stopped := make(chan struct{})
+		close(stopped)
+		workerStopped = stopped
+	}
+
+	// Initialize Gin router
+	if !cfg.Server.Debug {
+		gin.SetMode(gin.ReleaseMode)
+	}
+
+	router, err := api.NewRouter(cfg.Server.TrustedProxies)
+	if err != nil {
+		log.Fatalf("Invalid trusted proxies: %v", err)
+	}
+	router.Use(api.RecordMetrics(eng.Metrics()))
+
+	router.Use(api.SecurityHeaders(api.SecurityHeadersConfig{
+		HSTSMaxAge:            cfg.Server.Headers.HSTSMaxAge,
+		HSTSIncludeSubdomains: cfg.Server.Headers.HSTSIncludeSubdomains,
+		ContentTypeNosniff:    cfg.Server.Headers.ContentTypeNosniff,
+		FrameOptions:          cfg.Server.Headers.FrameOptions,
+		ReferrerPolicy:        cfg.Server.Headers.ReferrerPolicy,
+	}))
+	router.Use(api.CORS(api.CORSConfig{
+		AllowedOrigins:   cfg.Server.CORS.Origins,
+		AllowedMethods:   cfg.Server.CORS.Methods,
+		AllowedHeaders:   cfg.Server.CORS.Headers,
+		ExposedHeaders:   cfg.Server.CORS.ExposedHeaders,
+		AllowCredentials: cfg.Server.CORS.AllowCredentials,
+		MaxAge:           cfg.Server.CORS.MaxAge,
+	}))
+
+	// Setup routes
+	api.SetupRoutes(router, eng, db, redis, api.RouterConfig{
+		Auth:      newAuthConfig(db, redis, cfg.Auth),
+		RateLimit: newAPIRateLimit(redis, cfg.RateLimit),
+		// "inline" runs executions in the API process, for single-process setups
+		InlineExecution: cfg.Server.ExecutionMode == "inline",
+		Alerts:          alerts,
+		Subscriptions:   subscriptionsManager,
+		Schedules:       schedulesManager,
+		Health: api.HealthConfig{
+			Timeout:     cfg.Server.Readiness.Timeout,
+			MaxQueueLag: cfg.Server.Readiness.MaxQueueLag,
+		},
+	})
+
+	// Add metrics endpoint
+	router.GET("/metrics", gin.WrapH(promhttp.Handler()))
+
+	// Start server
+	srv := &http.Server{
+		Addr:    ":" + cfg.Server.Port,
+		Handler: router,
+	}
+
+	go func() {
+		log.Printf("Server starting on port %s", cfg.Server.Port)
+		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Fatalf("Failed to start server: %v", err)
+		}
+	}()
+
+	// Wait for interrupt signal to gracefully shutdown the server
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+	<-quit
+	log.Println("Shutting down server...")
+
+	// Stop accepting requests and give those in flight the shutdown
+	// timeout to finish
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
+	defer cancel()
+	if err := srv.Shutdown(ctx); err != nil {
+		log.Printf("Server forced to shutdown: %v", err)
+	}
+
+	// Stop background work; the in-process worker drains its executions
+	stopBackground()
+	<-workerStopped
+	log.Println("Server stopped")
+}
+
+// newAlertsManager returns the alerting manager. Email channels send
+// through the configured SMTP host; HTTP channels are held to the network
+// policy.
+func newAlertsManager(db *storage.DB, smtp config.SMTPConfig, network *netpolicy.Policy) *alerting.Manager {
+	notifier := alerting.NewNotifier(alerting.SMTPConfig{
+		Host:     smtp.Host,
+		Port:     smtp.Port,
+		Username: smtp.Username,
+		Password: smtp.Password,
+		From:     smtp.From,
+	})
+	notifier.SetNetworkPolicy(network)
+	return alerting.NewManager(db, notifier, logrus.StandardLogger())
+}
+
+// consumeEventSubscriptions feeds the event bus to the subscriptions
+// manager. Servers share the "event-subscriptions" consumer group, so each
+// event is delivered once.
+func consumeEventSubscriptions(ctx context.Context, eng *engine.Engine, manager *subscriptions.Manager) {
+	bus := eng.EventBus()
+	if bus == nil {
+		return
+	}
+	consumer, err := os.Hostname()
+	if err != nil {
+		consumer = "server"
+	}
+
+	err = bus.Consume(ctx, "event-subscriptions", consumer, func(ctx context.Context, event engine.StreamedEvent) error {
+		tenantID, _ := uuid.Parse(event.Event.TenantID)
+		return manager.HandleEvent(ctx, subscriptions.Event{
+			ID:       event.ID,
+			Type:     string(event.Event.Type),
+			TenantID: tenantID,
+			Data:     event.Event,
+		})
+	})
+	if err != nil && ctx.Err() == nil {
+		log.Printf("Event subscriptions stopped consuming events: %v", err)
+	}
+}
+
+// connectReplicas connects to the database's read replicas, if any, and
+// checks their health until ctx is done
+func connectReplicas(ctx context.Context, db *storage.DB, settings config.DatabaseConfig) {
+	if len(settings.ReplicaURLs) == 0 {
+		return
+	}
+
+	if err := db.ConnectReplicas(settings.ReplicaURLs); err != nil {
+		log.Fatalf("Failed to connect to read replicas: %v", err)
+	}
+	log.Printf("Reading listings and stats from %d replica(s)", len(settings.ReplicaURLs))
+	db.StartReplicaHealthChecks(ctx, settings.ReplicaCheckInterval, func(err error) {
+		log.Printf("Read replica health check failed; reading from the primary until it recovers: %v", err)
+	})
+}
+
+// newAuthConfig verifies API keys and bearer tokens signed with the JWT
+// secret. Credentials are optional unless auth is required.
+func newAuthConfig(db *storage.DB, redis *storage.RedisClient, settings config.AuthConfig) api.AuthConfig {
+	authConfig := api.AuthConfig{
+		APIKeys:     db,
+		RateLimiter: ratelimit.NewLimiter(rateLimitBackend(redis), nil),
+		Required:    settings.Required,
+	}
+	secret := settings.JWTSecret
+	if secret == "" || secret == "your-secret-key-change-this" {
+		log.Println("JWT_SECRET is not set; only API keys authenticate, other requests use the default tenant")
+		return authConfig
+	}
+	authConfig.Verifier = auth.NewVerifier(secret)
+	return authConfig
+}
+
+// newAPIRateLimit limits each API client to the API rate limit, e.g.
+// "600/m", in a sliding window. Excluded paths are exempt.
+func newAPIRateLimit(redis *storage.RedisClient, limits config.RateLimitConfig) api.RateLimitConfig {
+	rateLimit := api.RateLimitConfig{Exclude: api.DefaultRateLimitExclude}
+	if limits.API == "" {
+		return rateLimit
+	}
+
+	rule, err := ratelimit.ParseRule(limits.API)
+	if err != nil {
+		log.Fatalf("Invalid API_RATE_LIMIT: %v", err)
+	}
+	if redis == nil {
+		rateLimit.Backend = ratelimit.NewMemoryBackend()
+	} else {
+		rateLimit.Backend = ratelimit.NewRedisBackend(redis.Client())
+	}
+	rateLimit.Limit = int(rule.Limit)
+	rateLimit.Window = rule.Period
+	rateLimit.Exclude = append(rateLimit.Exclude, limits.APIExclude...)
+	return rateLimit
+}
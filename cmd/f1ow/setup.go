@@ -0,0 +1,287 @@
+package main
+
+import (
+	"context"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/nuumz/f1ow/internal/binarydata"
+	"github.com/nuumz/f1ow/internal/config"
+	"github.com/nuumz/f1ow/internal/credentials"
+	"github.com/nuumz/f1ow/internal/engine"
+	"github.com/nuumz/f1ow/internal/models"
+	"github.com/nuumz/f1ow/internal/netpolicy"
+	"github.com/nuumz/f1ow/internal/nodes"
+	"github.com/nuumz/f1ow/internal/quota"
+	"github.com/nuumz/f1ow/internal/ratelimit"
+	"github.com/nuumz/f1ow/internal/storage"
+	"github.com/nuumz/f1ow/internal/variables"
+
+	"github.com/sirupsen/logrus"
+)
+
+// engineOptions returns the engine options shared by the server, workers,
+// and the scheduler, and the binary data manager they store outputs with
+func engineOptions(db *storage.DB, redis *storage.RedisClient, cfg *config.Config) ([]engine.Option, *binarydata.Manager) {
+	binaryData := newBinaryDataManager(db, redis, cfg.BinaryData)
+	return []engine.Option{
+		engine.WithBinaryData(binaryData),
+		engine.WithCredentials(newCredentialsManager(db, redis, cfg.Credentials)),
+		engine.WithVariables(newVariablesManager(db, cfg.Credentials)),
+		engine.WithEnvironment(cfg.Execution.Environment),
+		engine.WithRateLimiter(newRateLimiter(redis, cfg.RateLimit)),
+		engine.WithNetworkPolicy(newNetworkPolicy(db, cfg.Network)),
+		engine.WithFairness(newFairness(cfg.Queue)),
+		engine.WithQuotas(newQuotas(db, cfg.Quotas)),
+		engine.WithLintOptions(newLintOptions(cfg.Lint)),
+		engine.WithMaxPayloadSize(cfg.Execution.MaxPayloadSize),
+		engine.WithNodeLimits(engine.NodeLimits{
+			Timeout:       cfg.Execution.NodeTimeout,
+			MaxInputSize:  cfg.Execution.MaxNodeInputSize,
+			MaxOutputSize: cfg.Execution.MaxNodeOutputSize,
+		}),
+	}, binaryData
+}
+
+// connectRedis connects to Redis, or returns nil when its URL is empty
+func connectRedis(settings config.RedisConfig) *storage.RedisClient {
+	if settings.URL == "" {
+		return nil
+	}
+	redis, err := storage.NewRedisClient(settings.URL)
+	if err != nil {
+		log.Fatalf("Failed to connect to Redis: %v", err)
+	}
+	return redis
+}
+
+func migrate(db *storage.DB) error {
+	applied, err := db.Migrate(context.Background())
+	for _, migration := range applied {
+		log.Printf("Applied migration %03d_%s", migration.Version, migration.Name)
+	}
+	return err
+}
+
+func maskPassword(url string) string {
+	// Simple password masking for logging
+	if len(url) == 0 {
+		return url
+	}
+	// Find password part and replace with ***
+	if idx := strings.Index(url, "://"); idx != -1 {
+		if pwdIdx := strings.Index(url[idx:], ":"); pwdIdx != -1 {
+			if atIdx := strings.Index(url[idx+pwdIdx:], "@"); atIdx != -1 {
+				return url[:idx+pwdIdx+1] + "***" + url[idx+pwdIdx+atIdx:]
+			}
+		}
+	}
+	return url
+}
+
+// newFairness returns how the queue shares dequeues between tenants or
+// workflows
+func newFairness(settings config.QueueConfig) engine.Fairness {
+	weights, err := engine.ParseWeights(settings.Weights)
+	if err != nil {
+		log.Fatalf("Invalid QUEUE_FAIRNESS_WEIGHTS: %v", err)
+	}
+	return engine.Fairness{By: settings.Fairness, Weights: weights}
+}
+
+// newQuotas returns the enforcer holding tenants to their quotas, with
+// the configured quotas for those their plan and they leave unset
+func newQuotas(db *storage.DB, settings config.QuotasConfig) *quota.Enforcer {
+	seconds := int((settings.MaxExecutionDuration + time.Second - 1) / time.Second)
+	return quota.New(models.Quotas{
+		MaxActiveWorkflows:  &settings.MaxActiveWorkflows,
+		MaxExecutionsPerDay: &settings.MaxExecutionsPerDay,
+		MaxExecutionSeconds: &seconds,
+		MaxPayloadSize:      &settings.MaxPayloadSize,
+	}, db)
+}
+
+// newLintOptions returns the severities of the lint rules and where
+// insecure TLS is an issue
+func newLintOptions(settings config.LintConfig) engine.LintOptions {
+	severities, err := engine.ParseLintSeverities(settings.Rules)
+	if err != nil {
+		log.Fatalf("Invalid LINT_RULES: %v", err)
+	}
+	return engine.LintOptions{Severities: severities, ProductionEnvironments: settings.ProductionEnvironments}
+}
+
+// newCredentialsManager returns the credentials vault, or nil when no
+// encryption key is configured. Pending OAuth2 authorizations are kept in
+// Redis when it is available so any API server can complete them.
+func newCredentialsManager(db *storage.DB, redis *storage.RedisClient, settings config.CredentialsConfig) *credentials.Manager {
+	if settings.EncryptionKey == "" {
+		log.Println("CREDENTIALS_ENCRYPTION_KEY is not set; the credentials vault is disabled")
+		return nil
+	}
+
+	cipher, err := credentials.NewCipher(settings.EncryptionKey)
+	if err != nil {
+		log.Fatalf("Failed to initialize credentials vault: %v", err)
+	}
+
+	manager := credentials.NewManager(db, cipher, settings.OAuth2CallbackURL, logrus.StandardLogger())
+	if redis != nil {
+		manager.UseStateStore(credentials.NewRedisStateStore(redis.Client()))
+	}
+	return manager
+}
+
+// newVariablesManager returns the variables store. Secret variables need
+// CREDENTIALS_ENCRYPTION_KEY.
+func newVariablesManager(db *storage.DB, settings config.CredentialsConfig) *variables.Manager {
+	var cipher *credentials.Cipher
+	if settings.EncryptionKey != "" {
+		var err error
+		if cipher, err = credentials.NewCipher(settings.EncryptionKey); err != nil {
+			log.Fatalf("Failed to initialize variables encryption: %v", err)
+		}
+	}
+	return variables.NewManager(db, cipher)
+}
+
+// rateLimitBackend counts requests in Redis, shared by every instance, or
+// in this process without Redis
+func rateLimitBackend(redis *storage.RedisClient) ratelimit.Backend {
+	if redis == nil {
+		return ratelimit.NewMemoryBackend()
+	}
+	return ratelimit.NewRedisBackend(redis.Client())
+}
+
+// newRateLimiter returns the outbound request limiter configured by the
+// default and per-host rate limits
+func newRateLimiter(redis *storage.RedisClient, limits config.RateLimitConfig) *ratelimit.Limiter {
+	var defaultRule *ratelimit.Rule
+	if limits.Default != "" {
+		rule, err := ratelimit.ParseRule(limits.Default)
+		if err != nil {
+			log.Fatalf("Invalid RATE_LIMIT_DEFAULT: %v", err)
+		}
+		defaultRule = &rule
+	}
+
+	limiter := ratelimit.NewLimiter(rateLimitBackend(redis), defaultRule)
+	if err := limiter.ConfigureHosts(limits.Hosts); err != nil {
+		log.Fatalf("Invalid RATE_LIMIT_HOSTS: %v", err)
+	}
+	return limiter
+}
+
+// newNetworkPolicy returns the policy outbound connections are checked
+// against: the configured ranges, and each tenant's own ranges
+func newNetworkPolicy(db *storage.DB, settings config.NetworkConfig) *netpolicy.Policy {
+	policy, err := netpolicy.New(models.NetworkPolicy{Allow: settings.Allow, Deny: settings.Deny})
+	if err != nil {
+		log.Fatalf("Invalid network policy: %v", err)
+	}
+	policy.SetTenantRules(db)
+	return policy
+}
+
+func newBinaryDataManager(db *storage.DB, redis *storage.RedisClient, settings config.BinaryDataConfig) *binarydata.Manager {
+	store, err := binarydata.NewStore(binarydata.Config{
+		Storage:  settings.Storage,
+		Path:     settings.Path,
+		RedisTTL: settings.RedisTTL,
+		S3: binarydata.S3Config{
+			Endpoint:  settings.S3.Endpoint,
+			Bucket:    settings.S3.Bucket,
+			Region:    settings.S3.Region,
+			AccessKey: settings.S3.AccessKey,
+			SecretKey: settings.S3.SecretKey,
+			UseSSL:    settings.S3.UseSSL,
+			Prefix:    settings.S3.Prefix,
+		},
+	}, redis.Client())
+	if err != nil {
+		log.Fatalf("Failed to initialize binary data storage: %v", err)
+	}
+
+	return binarydata.NewManager(store, db, logrus.StandardLogger())
+}
+
+func registerNodeTypes(eng *engine.Engine, db *storage.DB, redis *storage.RedisClient, settings config.NodesConfig) {
+	// Register built-in node types
+	cache := cacheStore(redis)
+	eng.RegisterNode("http", nodes.NewHTTPNode(cache))
+	eng.RegisterNode("transform", &nodes.TransformNode{})
+	eng.RegisterNode("conditional", &nodes.ConditionalNode{})
+	eng.RegisterNode("loop", &nodes.LoopNode{})
+	eng.RegisterNode("parallel", &nodes.ParallelNode{})
+	eng.RegisterNode("set", nodes.NewSetNode())
+	eng.RegisterNode("set_variable", nodes.NewSetVariableNode())
+	eng.RegisterNode("filter", nodes.NewFilterNode())
+	var seen nodes.SeenStore
+	if redis != nil {
+		seen = redis
+	}
+	eng.RegisterNode("dedupe", nodes.NewDedupeNode(seen, db))
+	eng.RegisterNode("state", nodes.NewStateNode(db))
+	eng.RegisterNode("external_task", nodes.NewExternalTaskNode(db))
+	eng.RegisterNode("redis", nodes.NewRedisNode(redis.Client()))
+	eng.RegisterNode("cache", nodes.NewCacheNode(cache))
+	eng.RegisterNode("email_trigger", nodes.NewEmailTriggerNode())
+	eng.RegisterNode("poll_trigger", nodes.NewPollTriggerNode())
+	eng.RegisterNode("file_watch_trigger", nodes.NewFileWatchTriggerNode(settings.FileStoragePath))
+	eng.RegisterNode("notify", nodes.NewNotifyNode())
+	eng.RegisterNode("kafka", nodes.NewKafkaNode())
+	eng.RegisterNode("kafka_trigger", nodes.NewKafkaTriggerNode())
+	eng.RegisterNode("amqp", nodes.NewAMQPNode())
+	eng.RegisterNode("amqp_trigger", nodes.NewAMQPTriggerNode())
+	eng.RegisterNode("mqtt", nodes.NewMQTTNode())
+	eng.RegisterNode("mqtt_trigger", nodes.NewMQTTTriggerNode())
+	eng.RegisterNode("grpc", nodes.NewGRPCNode())
+	eng.RegisterNode("soap", nodes.NewSOAPNode())
+	eng.RegisterNode("file", nodes.NewFileNode(settings.FileStoragePath))
+	eng.RegisterNode("csv_parse", nodes.NewCSVParseNode())
+	eng.RegisterNode("csv_generate", nodes.NewCSVGenerateNode())
+	eng.RegisterNode("llm", nodes.NewLLMNode())
+	eng.RegisterNode("vector_store", nodes.NewVectorStoreNode())
+	eng.RegisterNode("ssh", nodes.NewSSHNode(settings.SSHAllowedCommands))
+	eng.RegisterNode("exec", nodes.NewExecNode(settings.EnableExec, db))
+	eng.RegisterNode("git", nodes.NewGitNode(settings.FileStoragePath))
+	eng.RegisterNode("github", nodes.NewGitHubNode())
+	eng.RegisterNode("gitlab", nodes.NewGitLabNode())
+	eng.RegisterNode("github_trigger", nodes.NewGitHubTriggerNode())
+	eng.RegisterNode("gitlab_trigger", nodes.NewGitLabTriggerNode())
+	eng.RegisterNode("jira", nodes.NewJiraNode())
+	eng.RegisterNode("servicenow", nodes.NewServiceNowNode())
+
+	log.Println("Registered built-in node types")
+
+	registerCommunityNodes(eng, cache, settings.CommunityPath)
+}
+
+// cacheStore returns Redis as the node cache, or nil without Redis
+func cacheStore(redis *storage.RedisClient) nodes.CacheStore {
+	if redis == nil {
+		return nil
+	}
+	return redis
+}
+
+// registerCommunityNodes registers the declarative node definitions found in
+// dir
+func registerCommunityNodes(eng *engine.Engine, cache nodes.CacheStore, dir string) {
+	if dir == "" {
+		return
+	}
+
+	definitions, err := nodes.LoadCommunityNodeDefinitions(dir)
+	if err != nil {
+		log.Fatalf("Failed to load community nodes: %v", err)
+	}
+	for _, definition := range definitions {
+		if err := eng.RegisterNode(definition.Type, nodes.NewCommunityNode(*definition, cache)); err != nil {
+			log.Fatalf("Failed to register community node %s: %v", definition.Type, err)
+		}
+	}
+	log.Printf("Registered %d community node types", len(definitions))
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/nuumz/f1ow/internal/config"
+	"github.com/nuumz/f1ow/internal/engine"
+	"github.com/nuumz/f1ow/internal/scheduling"
+	"github.com/nuumz/f1ow/internal/storage"
+
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/spf13/cobra"
+)
+
+func newWorkerCommand() *cobra.Command {
+	var runScheduling bool
+	cmd := &cobra.Command{
+		Use:   "worker",
+		Short: "Run queued executions",
+		Long: `Run the executions queued in Redis. Workers also run the triggers of active
+workflows, retention, and the execution reaper unless --scheduler=false,
+for fleets where a separate "f1ow scheduler" does that work.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := loadConfig(cmd)
+			if err != nil {
+				return err
+			}
+			runWorker(cfg, runScheduling)
+			return nil
+		},
+	}
+	cmd.Flags().BoolVar(&runScheduling, "scheduler", true, "also run triggers, retention, and the execution reaper")
+	return cmd
+}
+
+// runWorker takes jobs from the queue until SIGINT or SIGTERM, or until the
+// worker is drained or stopped through the API
+func runWorker(cfg *config.Config, runScheduling bool) {
+	if cfg.Redis.URL == "" {
+		log.Fatal("Workers take jobs from Redis; with REDIS_URL empty the server runs jobs itself")
+	}
+
+	log.Println("Starting workflow engine worker...")
+
+	// Initialize database
+	db, err := storage.NewDB(cfg.Database.URL)
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+	defer db.Close()
+
+	// Initialize Redis
+	redis := connectRedis(cfg.Redis)
+	defer redis.Close()
+
+	// Initialize workflow engine
+	options, binaryData := engineOptions(db, redis, cfg)
+	eng := engine.NewEngine(db, redis, append(options, engine.WithWorker(workerOptions(cfg.Worker)))...)
+
+	// Register built-in node types
+	registerNodeTypes(eng, db, redis, cfg.Nodes)
+
+	// Create context for graceful shutdown
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	if runScheduling {
+		scheduling.Start(ctx, eng, db, redis, binaryData, cfg)
+	}
+
+	// Expose queue and job metrics for Prometheus and autoscalers
+	startMetricsServer(cfg.Worker.MetricsPort)
+	eng.StartPoolMetrics(ctx, 15*time.Second)
+
+	// Start worker; it returns early when drained or stopped through the API
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		log.Println("Worker started, listening for workflows...")
+		if err := eng.StartWorker(ctx); err != nil && err != context.Canceled {
+			log.Printf("Worker error: %v", err)
+		}
+	}()
+
+	// Wait for interrupt signal
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+	select {
+	case <-quit:
+	case <-stopped:
+	}
+
+	log.Println("Shutting down worker...")
+
+	// Stop taking jobs; running executions get the drain timeout to
+	// finish before they are requeued
+	cancel()
+	<-stopped
+
+	log.Println("Worker stopped")
+}
+
+// startMetricsServer serves /metrics on port; an empty port disables it
+func startMetricsServer(port string) {
+	if port == "" {
+		return
+	}
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.Handler())
+	go func() {
+		if err := http.ListenAndServe(":"+port, mux); err != nil {
+			log.Printf("Metrics server failed: %v", err)
+		}
+	}()
+}
+
+// workerOptions returns the worker's fleet identity, concurrency, and
+// labels ("key=value,key=value")
+func workerOptions(settings config.WorkerConfig) engine.WorkerOptions {
+	labels, err := engine.ParseLabels(settings.Labels)
+	if err != nil {
+		log.Fatalf("Invalid WORKER_LABELS: %v", err)
+	}
+	return engine.WorkerOptions{
+		ID:           settings.ID,
+		Version:      version,
+		Concurrency:  settings.Concurrency,
+		Labels:       labels,
+		DrainTimeout: settings.DrainTimeout,
+	}
+}
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/nuumz/f1ow/pkg/client"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+// workflowFile is an exported workflow: what defines it, without the IDs
+// and version of the server it came from
+type workflowFile struct {
+	Name        string                    `json:"name"`
+	Description string                    `json:"description,omitempty"`
+	Tags        []string                  `json:"tags,omitempty"`
+	Metadata    map[string]interface{}    `json:"metadata,omitempty"`
+	Status      string                    `json:"status,omitempty"`
+	Definition  client.WorkflowDefinition `json:"definition"`
+}
+
+// workflow returns the workflow the file defines
+func (f *workflowFile) workflow() client.Workflow {
+	return client.Workflow{
+		Name:        f.Name,
+		Description: f.Description,
+		Tags:        f.Tags,
+		Metadata:    f.Metadata,
+		Status:      f.Status,
+		Definition:  f.Definition,
+	}
+}
+
+func newExportCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "Export resources from a server",
+	}
+
+	var output string
+	workflow := &cobra.Command{
+		Use:   "workflow <id>",
+		Short: "Print a workflow as JSON, ready for import",
+		Args:  cobra.ExactArgs(1),
+	}
+	flags := addClientFlags(workflow)
+	workflow.Flags().StringVarP(&output, "output", "o", "", "write to this file instead of stdout")
+	workflow.RunE = func(cmd *cobra.Command, args []string) error {
+		w, err := flags.client().GetWorkflow(cmd.Context(), args[0])
+		if err != nil {
+			return err
+		}
+		file := workflowFile{
+			Name:        w.Name,
+			Description: w.Description,
+			Tags:        w.Tags,
+			Metadata:    w.Metadata,
+			Status:      w.Status,
+			Definition:  w.Definition,
+		}
+
+		if output == "" {
+			return printJSON(cmd, file)
+		}
+		data, err := json.MarshalIndent(file, "", "  ")
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(output, append(data, '\n'), 0o644)
+	}
+
+	cmd.AddCommand(workflow)
+	return cmd
+}
+
+func newImportCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "import",
+		Short: "Import resources into a server",
+	}
+
+	workflow := &cobra.Command{
+		Use:   "workflow <file>",
+		Short: "Create a workflow from an exported JSON or YAML file, or - for stdin",
+		Args:  cobra.ExactArgs(1),
+	}
+	flags := addClientFlags(workflow)
+	workflow.RunE = func(cmd *cobra.Command, args []string) error {
+		file, err := readWorkflowFile(cmd, args[0])
+		if err != nil {
+			return err
+		}
+		workflow := file.workflow()
+		created, err := flags.client().CreateWorkflow(cmd.Context(), &workflow)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "Created workflow %s (%s)\n", created.ID, created.Name)
+		return nil
+	}
+
+	cmd.AddCommand(workflow)
+	return cmd
+}
+
+// readWorkflowFile reads an exported workflow from path, or stdin for "-"
+func readWorkflowFile(cmd *cobra.Command, path string) (*workflowFile, error) {
+	var data []byte
+	var err error
+	if path == "-" {
+		data, err = io.ReadAll(cmd.InOrStdin())
+	} else {
+		data, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return parseWorkflowFile(path, data)
+}
+
+// parseWorkflowFile parses a workflow file, YAML when its name ends in
+// .yaml or .yml and JSON otherwise. Fields of a full workflow from the API,
+// like its ID, are ignored.
+func parseWorkflowFile(path string, data []byte) (*workflowFile, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		// The definition types only have JSON field names
+		var values interface{}
+		if err := yaml.Unmarshal(data, &values); err != nil {
+			return nil, fmt.Errorf("%s is not a workflow file: %w", path, err)
+		}
+		var err error
+		if data, err = json.Marshal(values); err != nil {
+			return nil, fmt.Errorf("%s is not a workflow file: %w", path, err)
+		}
+	}
+
+	var file workflowFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("%s is not a workflow file: %w", path, err)
+	}
+	if file.Name == "" {
+		return nil, fmt.Errorf("%s has no workflow name", path)
+	}
+	return &file, nil
+}
@@ -0,0 +1,44 @@
+// Command openapi writes the API's OpenAPI spec and the Go client generated
+// from it. Run it with go generate ./pkg/client after changing routes.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"log"
+	"os"
+
+	"github.com/nuumz/f1ow/internal/api"
+	"github.com/nuumz/f1ow/internal/openapi"
+
+	"github.com/gin-gonic/gin"
+)
+
+func main() {
+	specPath := flag.String("spec", "api/openapi.json", "where to write the OpenAPI spec")
+	clientPath := flag.String("client", "pkg/client/client_gen.go", "where to write the generated Go client")
+	pkg := flag.String("package", "client", "package name of the generated client")
+	flag.Parse()
+
+	// Handlers are never called, so the routes need no engine or storage
+	gin.SetMode(gin.ReleaseMode)
+	router := gin.New()
+	api.SetupRoutes(router, nil, nil, nil, api.RouterConfig{})
+	doc := api.OpenAPISpec(router.Routes())
+
+	spec, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		log.Fatalf("Failed to marshal spec: %v", err)
+	}
+	if err := os.WriteFile(*specPath, append(spec, '\n'), 0644); err != nil {
+		log.Fatalf("Failed to write spec: %v", err)
+	}
+
+	client, err := openapi.GenerateClient(doc, *pkg)
+	if err != nil {
+		log.Fatalf("Failed to generate client: %v", err)
+	}
+	if err := os.WriteFile(*clientPath, client, 0644); err != nil {
+		log.Fatalf("Failed to write client: %v", err)
+	}
+}
@@ -0,0 +1,182 @@
+package alerting
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/smtp"
+	"strings"
+	"time"
+
+	"github.com/nuumz/f1ow/internal/models"
+	"github.com/nuumz/f1ow/internal/netpolicy"
+)
+
+// pagerDutyEventsURL is the PagerDuty Events API v2 endpoint
+const pagerDutyEventsURL = "https://events.pagerduty.com/v2/enqueue"
+
+// Sender delivers an alert to a channel
+type Sender interface {
+	Send(ctx context.Context, channel *models.AlertChannel, alert *models.Alert) error
+}
+
+// SMTPConfig is the mail server email channels send through
+type SMTPConfig struct {
+	Host     string // email channels fail when empty
+	Port     string
+	Username string
+	Password string
+	From     string
+}
+
+// Notifier sends alerts over HTTP to Slack, webhooks, and PagerDuty, and by
+// email through an SMTP server
+type Notifier struct {
+	client       *http.Client
+	network      *netpolicy.Policy
+	smtp         SMTPConfig
+	pagerDutyURL string
+}
+
+// NewNotifier creates a notifier that sends email through smtpConfig
+func NewNotifier(smtpConfig SMTPConfig) *Notifier {
+	return &Notifier{
+		client:       &http.Client{Timeout: 10 * time.Second},
+		smtp:         smtpConfig,
+		pagerDutyURL: pagerDutyEventsURL,
+	}
+}
+
+// SetNetworkPolicy checks the addresses Slack, webhook, and PagerDuty
+// alerts connect to against policy
+func (n *Notifier) SetNetworkPolicy(policy *netpolicy.Policy) {
+	n.network = policy
+}
+
+// Send delivers the alert to the channel
+func (n *Notifier) Send(ctx context.Context, channel *models.AlertChannel, alert *models.Alert) error {
+	config := channel.Config
+	switch channel.Type {
+	case models.AlertChannelSlack:
+		url, _ := config["webhook_url"].(string)
+		return n.post(ctx, url, map[string]interface{}{"text": fmt.Sprintf(":rotating_light: *%s*\n%s", alert.RuleName, alert.Message)}, nil)
+
+	case models.AlertChannelWebhook:
+		url, _ := config["url"].(string)
+		headers := map[string]string{}
+		if raw, ok := config["headers"].(map[string]interface{}); ok {
+			for key, value := range raw {
+				headers[key] = fmt.Sprint(value)
+			}
+		}
+		return n.post(ctx, url, alert, headers)
+
+	case models.AlertChannelPagerDuty:
+		routingKey, _ := config["routing_key"].(string)
+		severity, _ := config["severity"].(string)
+		if severity == "" {
+			severity = "error"
+		}
+		return n.post(ctx, n.pagerDutyURL, map[string]interface{}{
+			"routing_key":  routingKey,
+			"event_action": "trigger",
+			"dedup_key":    "f1ow-" + alert.RuleID.String(),
+			"payload": map[string]interface{}{
+				"summary":        alert.Message,
+				"source":         "f1ow",
+				"severity":       severity,
+				"timestamp":      alert.FiredAt.Format(time.RFC3339),
+				"custom_details": alert,
+			},
+		}, nil)
+
+	case models.AlertChannelEmail:
+		return n.mail(recipients(config["to"]), alert)
+
+	default:
+		return fmt.Errorf("%w: unsupported channel type %s", ErrInvalid, channel.Type)
+	}
+}
+
+func (n *Notifier) post(ctx context.Context, url string, payload interface{}, headers map[string]string) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal alert: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+
+	client := n.client
+	if n.network != nil {
+		client = n.network.Client(client)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send alert: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("alert endpoint returned status %d: %s", resp.StatusCode, string(respBody))
+	}
+	return nil
+}
+
+func (n *Notifier) mail(to []string, alert *models.Alert) error {
+	if n.smtp.Host == "" {
+		return fmt.Errorf("email alerts need an SMTP server; none is configured")
+	}
+
+	var message strings.Builder
+	fmt.Fprintf(&message, "From: %s\r\n", n.smtp.From)
+	fmt.Fprintf(&message, "To: %s\r\n", strings.Join(to, ", "))
+	fmt.Fprintf(&message, "Subject: [f1ow] %s\r\n", alert.RuleName)
+	message.WriteString("Content-Type: text/plain; charset=utf-8\r\n\r\n")
+	fmt.Fprintf(&message, "%s\r\n\r\nFired at %s\r\n", alert.Message, alert.FiredAt.Format(time.RFC1123))
+
+	var auth smtp.Auth
+	if n.smtp.Username != "" {
+		auth = smtp.PlainAuth("", n.smtp.Username, n.smtp.Password, n.smtp.Host)
+	}
+	addr := net.JoinHostPort(n.smtp.Host, n.smtp.Port)
+	if err := smtp.SendMail(addr, auth, n.smtp.From, to, []byte(message.String())); err != nil {
+		return fmt.Errorf("failed to send alert email: %w", err)
+	}
+	return nil
+}
+
+// recipients returns the addresses of an email channel's to field, a string
+// of comma-separated addresses or a list
+func recipients(value interface{}) []string {
+	var raw []string
+	switch v := value.(type) {
+	case string:
+		raw = strings.Split(v, ",")
+	case []interface{}:
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				raw = append(raw, s)
+			}
+		}
+	}
+
+	addresses := []string{}
+	for _, address := range raw {
+		if address = strings.TrimSpace(address); address != "" {
+			addresses = append(addresses, address)
+		}
+	}
+	return addresses
+}
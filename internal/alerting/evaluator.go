@@ -0,0 +1,186 @@
+package alerting
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/nuumz/f1ow/internal/models"
+	"github.com/nuumz/f1ow/internal/tenant"
+
+	"github.com/google/uuid"
+)
+
+// evaluatorLeaseKey is the lease held by the instance that evaluates rules
+const evaluatorLeaseKey = "f1ow:alerting:evaluator"
+
+// Locker leases the evaluator to one instance at a time; it is implemented
+// by engine.RedisTriggerLocker
+type Locker interface {
+	Acquire(ctx context.Context, key, owner string, ttl time.Duration) (bool, error)
+}
+
+// Run evaluates the rules every interval until ctx is done. With a locker,
+// only the instance holding the lease evaluates, so alerts fire once.
+func (m *Manager) Run(ctx context.Context, interval time.Duration, locker Locker) {
+	owner := uuid.New().String()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if locker != nil {
+			held, err := locker.Acquire(ctx, evaluatorLeaseKey, owner, 2*interval)
+			if err != nil {
+				m.logger.Errorf("Failed to take the alert evaluator lease: %v", err)
+				continue
+			}
+			if !held {
+				continue
+			}
+		}
+		if _, err := m.Evaluate(ctx); err != nil {
+			m.logger.Errorf("Alert evaluation failed: %v", err)
+		}
+	}
+}
+
+// Evaluate checks the enabled rules of every tenant and fires those whose
+// condition holds and whose cooldown has passed. It returns the alerts
+// fired.
+func (m *Manager) Evaluate(ctx context.Context) ([]models.Alert, error) {
+	rules, err := m.store.ListAlertRules(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list alert rules: %w", err)
+	}
+
+	fired := []models.Alert{}
+	for i := range rules {
+		rule := &rules[i]
+		if !rule.Enabled || m.coolingDown(rule) {
+			continue
+		}
+
+		ruleCtx := tenant.WithID(ctx, rule.TenantID)
+		alert, err := m.check(ruleCtx, rule)
+		if err != nil {
+			m.logger.Errorf("Failed to evaluate alert rule %s: %v", rule.ID, err)
+			continue
+		}
+		if alert == nil {
+			continue
+		}
+		if err := m.fire(ruleCtx, rule, alert); err != nil {
+			m.logger.Errorf("Failed to record alert for rule %s: %v", rule.ID, err)
+			continue
+		}
+		fired = append(fired, *alert)
+	}
+	return fired, nil
+}
+
+func (m *Manager) coolingDown(rule *models.AlertRule) bool {
+	if rule.LastFiredAt == nil {
+		return false
+	}
+	cooldown := time.Duration(rule.Cooldown) * time.Second
+	if cooldown == 0 {
+		cooldown = window(rule)
+	}
+	return time.Since(*rule.LastFiredAt) < cooldown
+}
+
+func window(rule *models.AlertRule) time.Duration {
+	if rule.Window > 0 {
+		return time.Duration(rule.Window) * time.Second
+	}
+	return defaultWindow
+}
+
+// check returns the alert the rule fires now, or nil
+func (m *Manager) check(ctx context.Context, rule *models.AlertRule) (*models.Alert, error) {
+	now := time.Now()
+	executions, err := m.store.ListRecentExecutions(ctx, rule.WorkflowID, now.Add(-window(rule)))
+	if err != nil {
+		return nil, err
+	}
+
+	subject := "All workflows"
+	if rule.WorkflowID != nil {
+		subject = fmt.Sprintf("Workflow %s", rule.WorkflowID)
+	}
+	alert := &models.Alert{
+		ID:         uuid.New(),
+		TenantID:   rule.TenantID,
+		RuleID:     rule.ID,
+		RuleName:   rule.Name,
+		WorkflowID: rule.WorkflowID,
+		FiredAt:    now,
+	}
+
+	switch rule.Condition {
+	case models.AlertOnFailures:
+		failures := 0
+		for _, execution := range executions {
+			if execution.Status == models.ExecutionStatusFailed {
+				failures++
+			}
+		}
+		if failures < rule.Threshold {
+			return nil, nil
+		}
+		alert.Value = float64(failures)
+		alert.Message = fmt.Sprintf("%s: %d failed executions in the last %s", subject, failures, window(rule))
+
+	case models.AlertOnDuration:
+		// One alert names the slowest execution over the limit
+		limit := time.Duration(rule.Threshold) * time.Second
+		var slowest *models.ExecutionSummary
+		var longest time.Duration
+		for i, execution := range executions {
+			end := now
+			if execution.CompletedAt != nil {
+				end = *execution.CompletedAt
+			}
+			if took := end.Sub(execution.StartedAt); took > limit && took > longest {
+				slowest, longest = &executions[i], took
+			}
+		}
+		if slowest == nil {
+			return nil, nil
+		}
+		alert.ExecutionID, alert.WorkflowID = &slowest.ID, &slowest.WorkflowID
+		alert.Value = longest.Round(time.Second).Seconds()
+		alert.Message = fmt.Sprintf("Execution %s of workflow %s ran for %s, over the %s limit",
+			slowest.ID, slowest.WorkflowID, longest.Round(time.Second), limit)
+	}
+	return alert, nil
+}
+
+// fire sends the alert to the rule's channels and records it
+func (m *Manager) fire(ctx context.Context, rule *models.AlertRule, alert *models.Alert) error {
+	alert.Deliveries = make([]models.AlertDelivery, 0, len(rule.ChannelIDs))
+	for _, channelID := range rule.ChannelIDs {
+		delivery := models.AlertDelivery{ChannelID: channelID}
+		channel, err := m.store.GetAlertChannel(ctx, channelID)
+		if err == nil {
+			err = m.sender.Send(ctx, channel, alert)
+		}
+		if err != nil {
+			delivery.Error = err.Error()
+			m.logger.Warnf("Failed to deliver alert %s to channel %s: %v", alert.ID, channelID, err)
+		}
+		alert.Deliveries = append(alert.Deliveries, delivery)
+	}
+
+	if err := m.store.CreateAlert(ctx, alert); err != nil {
+		return err
+	}
+	rule.LastFiredAt = &alert.FiredAt
+	return m.store.MarkAlertRuleFired(ctx, rule.ID, alert.FiredAt)
+}
@@ -0,0 +1,224 @@
+// Package alerting evaluates user-defined rules on execution outcomes and
+// sends alerts to email, Slack, webhook, and PagerDuty channels.
+package alerting
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/nuumz/f1ow/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+var (
+	// ErrRuleNotFound is returned when an alert rule does not exist
+	ErrRuleNotFound = errors.New("alert rule not found")
+	// ErrChannelNotFound is returned when an alert channel does not exist
+	ErrChannelNotFound = errors.New("alert channel not found")
+	// ErrInvalid is returned for rules and channels that cannot be evaluated
+	// or delivered to
+	ErrInvalid = errors.New("invalid alert configuration")
+)
+
+// defaultWindow is how far back rules without a window count executions
+const defaultWindow = 10 * time.Minute
+
+// Store persists rules, channels, and alert history; it is implemented by
+// storage.DB
+type Store interface {
+	CreateAlertRule(ctx context.Context, rule *models.AlertRule) error
+	GetAlertRule(ctx context.Context, id uuid.UUID) (*models.AlertRule, error)
+	ListAlertRules(ctx context.Context) ([]models.AlertRule, error)
+	UpdateAlertRule(ctx context.Context, rule *models.AlertRule) error
+	DeleteAlertRule(ctx context.Context, id uuid.UUID) error
+	MarkAlertRuleFired(ctx context.Context, id uuid.UUID, firedAt time.Time) error
+
+	CreateAlertChannel(ctx context.Context, channel *models.AlertChannel) error
+	GetAlertChannel(ctx context.Context, id uuid.UUID) (*models.AlertChannel, error)
+	ListAlertChannels(ctx context.Context) ([]models.AlertChannel, error)
+	UpdateAlertChannel(ctx context.Context, channel *models.AlertChannel) error
+	DeleteAlertChannel(ctx context.Context, id uuid.UUID) error
+
+	CreateAlert(ctx context.Context, alert *models.Alert) error
+	ListAlerts(ctx context.Context, ruleID *uuid.UUID, limit int) ([]models.Alert, error)
+
+	// ListRecentExecutions returns the workflow's executions, or those of
+	// all workflows when workflowID is nil, that are running or finished
+	// at or after since
+	ListRecentExecutions(ctx context.Context, workflowID *uuid.UUID, since time.Time) ([]models.ExecutionSummary, error)
+}
+
+// Manager validates and stores alert rules and channels, and evaluates the
+// rules
+type Manager struct {
+	store  Store
+	sender Sender
+	logger *logrus.Logger
+}
+
+// NewManager creates an alerting manager that delivers alerts with sender
+func NewManager(store Store, sender Sender, logger *logrus.Logger) *Manager {
+	return &Manager{store: store, sender: sender, logger: logger}
+}
+
+// CreateRule stores a new alert rule in the context's tenant
+func (m *Manager) CreateRule(ctx context.Context, rule *models.AlertRule) (*models.AlertRule, error) {
+	if err := m.validateRule(ctx, rule); err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	rule.ID = uuid.New()
+	rule.CreatedAt = now
+	rule.UpdatedAt = now
+	rule.LastFiredAt = nil
+	if err := m.store.CreateAlertRule(ctx, rule); err != nil {
+		return nil, fmt.Errorf("failed to save alert rule: %w", err)
+	}
+	return rule, nil
+}
+
+// GetRule returns an alert rule
+func (m *Manager) GetRule(ctx context.Context, id uuid.UUID) (*models.AlertRule, error) {
+	return m.store.GetAlertRule(ctx, id)
+}
+
+// ListRules returns the alert rules of the context's tenant
+func (m *Manager) ListRules(ctx context.Context) ([]models.AlertRule, error) {
+	return m.store.ListAlertRules(ctx)
+}
+
+// UpdateRule replaces an alert rule, keeping when it last fired
+func (m *Manager) UpdateRule(ctx context.Context, rule *models.AlertRule) (*models.AlertRule, error) {
+	existing, err := m.store.GetAlertRule(ctx, rule.ID)
+	if err != nil {
+		return nil, err
+	}
+	if err := m.validateRule(ctx, rule); err != nil {
+		return nil, err
+	}
+	rule.CreatedAt = existing.CreatedAt
+	rule.LastFiredAt = existing.LastFiredAt
+	rule.UpdatedAt = time.Now()
+	if err := m.store.UpdateAlertRule(ctx, rule); err != nil {
+		return nil, err
+	}
+	return rule, nil
+}
+
+// DeleteRule removes an alert rule and its alert history
+func (m *Manager) DeleteRule(ctx context.Context, id uuid.UUID) error {
+	return m.store.DeleteAlertRule(ctx, id)
+}
+
+// CreateChannel stores a new alert channel in the context's tenant
+func (m *Manager) CreateChannel(ctx context.Context, channel *models.AlertChannel) (*models.AlertChannel, error) {
+	if err := validateChannel(channel); err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	channel.ID = uuid.New()
+	channel.CreatedAt = now
+	channel.UpdatedAt = now
+	if err := m.store.CreateAlertChannel(ctx, channel); err != nil {
+		return nil, fmt.Errorf("failed to save alert channel: %w", err)
+	}
+	return channel, nil
+}
+
+// GetChannel returns an alert channel
+func (m *Manager) GetChannel(ctx context.Context, id uuid.UUID) (*models.AlertChannel, error) {
+	return m.store.GetAlertChannel(ctx, id)
+}
+
+// ListChannels returns the alert channels of the context's tenant
+func (m *Manager) ListChannels(ctx context.Context) ([]models.AlertChannel, error) {
+	return m.store.ListAlertChannels(ctx)
+}
+
+// UpdateChannel replaces an alert channel's name, type, and config
+func (m *Manager) UpdateChannel(ctx context.Context, channel *models.AlertChannel) (*models.AlertChannel, error) {
+	existing, err := m.store.GetAlertChannel(ctx, channel.ID)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateChannel(channel); err != nil {
+		return nil, err
+	}
+	channel.CreatedAt = existing.CreatedAt
+	channel.UpdatedAt = time.Now()
+	if err := m.store.UpdateAlertChannel(ctx, channel); err != nil {
+		return nil, err
+	}
+	return channel, nil
+}
+
+// DeleteChannel removes an alert channel. Rules that use it skip it.
+func (m *Manager) DeleteChannel(ctx context.Context, id uuid.UUID) error {
+	return m.store.DeleteAlertChannel(ctx, id)
+}
+
+// ListAlerts returns the most recent alerts, of one rule when ruleID is set
+func (m *Manager) ListAlerts(ctx context.Context, ruleID *uuid.UUID, limit int) ([]models.Alert, error) {
+	if limit <= 0 {
+		limit = 100
+	}
+	if limit > 500 {
+		limit = 500
+	}
+	return m.store.ListAlerts(ctx, ruleID, limit)
+}
+
+func (m *Manager) validateRule(ctx context.Context, rule *models.AlertRule) error {
+	switch {
+	case rule.Name == "":
+		return fmt.Errorf("%w: name is required", ErrInvalid)
+	case rule.Condition != models.AlertOnFailures && rule.Condition != models.AlertOnDuration:
+		return fmt.Errorf("%w: condition must be failures or duration", ErrInvalid)
+	case rule.Threshold <= 0:
+		return fmt.Errorf("%w: threshold must be positive", ErrInvalid)
+	case rule.Window < 0 || rule.Cooldown < 0:
+		return fmt.Errorf("%w: window and cooldown cannot be negative", ErrInvalid)
+	case len(rule.ChannelIDs) == 0:
+		return fmt.Errorf("%w: at least one channel is required", ErrInvalid)
+	}
+	for _, id := range rule.ChannelIDs {
+		if _, err := m.store.GetAlertChannel(ctx, id); err != nil {
+			if errors.Is(err, ErrChannelNotFound) {
+				return fmt.Errorf("%w: channel %s does not exist", ErrInvalid, id)
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+func validateChannel(channel *models.AlertChannel) error {
+	if channel.Name == "" {
+		return fmt.Errorf("%w: name is required", ErrInvalid)
+	}
+
+	var required string
+	switch channel.Type {
+	case models.AlertChannelEmail:
+		if len(recipients(channel.Config["to"])) == 0 {
+			return fmt.Errorf("%w: email channels need a to address", ErrInvalid)
+		}
+		return nil
+	case models.AlertChannelSlack:
+		required = "webhook_url"
+	case models.AlertChannelWebhook:
+		required = "url"
+	case models.AlertChannelPagerDuty:
+		required = "routing_key"
+	default:
+		return fmt.Errorf("%w: type must be email, slack, webhook, or pagerduty", ErrInvalid)
+	}
+	if value, _ := channel.Config[required].(string); value == "" {
+		return fmt.Errorf("%w: %s channels need %s", ErrInvalid, channel.Type, required)
+	}
+	return nil
+}
@@ -0,0 +1,333 @@
+package api
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/nuumz/f1ow/internal/alerting"
+	"github.com/nuumz/f1ow/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// alertRuleRequest is the body of POST /alerts/rules and PUT /alerts/rules/:id
+type alertRuleRequest struct {
+	Name       string                `json:"name" binding:"required"`
+	WorkflowID *uuid.UUID            `json:"workflow_id"` // all workflows when omitted
+	Condition  models.AlertCondition `json:"condition" binding:"required"`
+	Threshold  int                   `json:"threshold"`
+	Window     int                   `json:"window_seconds"`
+	Cooldown   int                   `json:"cooldown_seconds"`
+	ChannelIDs []uuid.UUID           `json:"channel_ids"`
+	Enabled    *bool                 `json:"enabled"` // true when omitted
+}
+
+func (r alertRuleRequest) rule() *models.AlertRule {
+	rule := &models.AlertRule{
+		Name:       r.Name,
+		WorkflowID: r.WorkflowID,
+		Condition:  r.Condition,
+		Threshold:  r.Threshold,
+		Window:     r.Window,
+		Cooldown:   r.Cooldown,
+		ChannelIDs: r.ChannelIDs,
+		Enabled:    true,
+	}
+	if r.Enabled != nil {
+		rule.Enabled = *r.Enabled
+	}
+	return rule
+}
+
+// alertChannelRequest is the body of POST /alerts/channels and PUT /alerts/channels/:id
+type alertChannelRequest struct {
+	Name   string                  `json:"name" binding:"required"`
+	Type   models.AlertChannelType `json:"type" binding:"required"`
+	Config map[string]interface{}  `json:"config"`
+}
+
+func (r alertChannelRequest) channel() *models.AlertChannel {
+	return &models.AlertChannel{Name: r.Name, Type: r.Type, Config: r.Config}
+}
+
+// alertsManager returns the alerting manager or writes an error response when it is not configured
+func alertsManager(c *gin.Context, manager *alerting.Manager) *alerting.Manager {
+	if manager == nil {
+		c.JSON(503, gin.H{"error": "alerting is not configured"})
+	}
+	return manager
+}
+
+// alertError writes the response for an alerting manager error
+func alertError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, alerting.ErrRuleNotFound), errors.Is(err, alerting.ErrChannelNotFound):
+		c.JSON(404, gin.H{"error": err.Error()})
+	case errors.Is(err, alerting.ErrInvalid):
+		c.JSON(400, gin.H{"error": err.Error()})
+	default:
+		c.JSON(500, gin.H{"error": err.Error()})
+	}
+}
+
+// GetAlerts returns the alert history, most recent first. The rule_id query
+// parameter selects one rule's alerts.
+func GetAlerts(alerts *alerting.Manager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		manager := alertsManager(c, alerts)
+		if manager == nil {
+			return
+		}
+
+		var ruleID *uuid.UUID
+		if value := c.Query("rule_id"); value != "" {
+			id, err := uuid.Parse(value)
+			if err != nil {
+				c.JSON(400, gin.H{"error": "invalid rule ID"})
+				return
+			}
+			ruleID = &id
+		}
+		limit, _ := strconv.Atoi(c.Query("limit"))
+
+		list, err := manager.ListAlerts(c.Request.Context(), ruleID, limit)
+		if err != nil {
+			alertError(c, err)
+			return
+		}
+		c.JSON(200, list)
+	}
+}
+
+// GetAlertRules lists alert rules
+func GetAlertRules(alerts *alerting.Manager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		manager := alertsManager(c, alerts)
+		if manager == nil {
+			return
+		}
+
+		rules, err := manager.ListRules(c.Request.Context())
+		if err != nil {
+			alertError(c, err)
+			return
+		}
+		c.JSON(200, rules)
+	}
+}
+
+// CreateAlertRule stores an alert rule
+func CreateAlertRule(alerts *alerting.Manager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		manager := alertsManager(c, alerts)
+		if manager == nil {
+			return
+		}
+
+		var req alertRuleRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(400, gin.H{"error": err.Error()})
+			return
+		}
+
+		rule, err := manager.CreateRule(c.Request.Context(), req.rule())
+		if err != nil {
+			alertError(c, err)
+			return
+		}
+		c.JSON(201, rule)
+	}
+}
+
+// GetAlertRule returns an alert rule
+func GetAlertRule(alerts *alerting.Manager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		manager := alertsManager(c, alerts)
+		if manager == nil {
+			return
+		}
+
+		id, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			c.JSON(400, gin.H{"error": "invalid rule ID"})
+			return
+		}
+
+		rule, err := manager.GetRule(c.Request.Context(), id)
+		if err != nil {
+			alertError(c, err)
+			return
+		}
+		c.JSON(200, rule)
+	}
+}
+
+// UpdateAlertRule replaces an alert rule
+func UpdateAlertRule(alerts *alerting.Manager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		manager := alertsManager(c, alerts)
+		if manager == nil {
+			return
+		}
+
+		id, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			c.JSON(400, gin.H{"error": "invalid rule ID"})
+			return
+		}
+
+		var req alertRuleRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(400, gin.H{"error": err.Error()})
+			return
+		}
+
+		rule := req.rule()
+		rule.ID = id
+		updated, err := manager.UpdateRule(c.Request.Context(), rule)
+		if err != nil {
+			alertError(c, err)
+			return
+		}
+		c.JSON(200, updated)
+	}
+}
+
+// DeleteAlertRule removes an alert rule and its alert history
+func DeleteAlertRule(alerts *alerting.Manager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		manager := alertsManager(c, alerts)
+		if manager == nil {
+			return
+		}
+
+		id, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			c.JSON(400, gin.H{"error": "invalid rule ID"})
+			return
+		}
+
+		if err := manager.DeleteRule(c.Request.Context(), id); err != nil {
+			alertError(c, err)
+			return
+		}
+		c.JSON(200, gin.H{"message": "alert rule deleted"})
+	}
+}
+
+// GetAlertChannels lists alert channels
+func GetAlertChannels(alerts *alerting.Manager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		manager := alertsManager(c, alerts)
+		if manager == nil {
+			return
+		}
+
+		channels, err := manager.ListChannels(c.Request.Context())
+		if err != nil {
+			alertError(c, err)
+			return
+		}
+		c.JSON(200, channels)
+	}
+}
+
+// CreateAlertChannel stores an alert channel
+func CreateAlertChannel(alerts *alerting.Manager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		manager := alertsManager(c, alerts)
+		if manager == nil {
+			return
+		}
+
+		var req alertChannelRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(400, gin.H{"error": err.Error()})
+			return
+		}
+
+		channel, err := manager.CreateChannel(c.Request.Context(), req.channel())
+		if err != nil {
+			alertError(c, err)
+			return
+		}
+		c.JSON(201, channel)
+	}
+}
+
+// GetAlertChannel returns an alert channel
+func GetAlertChannel(alerts *alerting.Manager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		manager := alertsManager(c, alerts)
+		if manager == nil {
+			return
+		}
+
+		id, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			c.JSON(400, gin.H{"error": "invalid channel ID"})
+			return
+		}
+
+		channel, err := manager.GetChannel(c.Request.Context(), id)
+		if err != nil {
+			alertError(c, err)
+			return
+		}
+		c.JSON(200, channel)
+	}
+}
+
+// UpdateAlertChannel replaces an alert channel
+func UpdateAlertChannel(alerts *alerting.Manager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		manager := alertsManager(c, alerts)
+		if manager == nil {
+			return
+		}
+
+		id, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			c.JSON(400, gin.H{"error": "invalid channel ID"})
+			return
+		}
+
+		var req alertChannelRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(400, gin.H{"error": err.Error()})
+			return
+		}
+
+		channel := req.channel()
+		channel.ID = id
+		updated, err := manager.UpdateChannel(c.Request.Context(), channel)
+		if err != nil {
+			alertError(c, err)
+			return
+		}
+		c.JSON(200, updated)
+	}
+}
+
+// DeleteAlertChannel removes an alert channel
+func DeleteAlertChannel(alerts *alerting.Manager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		manager := alertsManager(c, alerts)
+		if manager == nil {
+			return
+		}
+
+		id, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			c.JSON(400, gin.H{"error": "invalid channel ID"})
+			return
+		}
+
+		if err := manager.DeleteChannel(c.Request.Context(), id); err != nil {
+			alertError(c, err)
+			return
+		}
+		c.JSON(200, gin.H{"message": "alert channel deleted"})
+	}
+}
@@ -0,0 +1,115 @@
+package api
+
+import (
+	"errors"
+	"time"
+
+	"github.com/nuumz/f1ow/internal/auth"
+	"github.com/nuumz/f1ow/internal/models"
+	"github.com/nuumz/f1ow/internal/ratelimit"
+	"github.com/nuumz/f1ow/internal/storage"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// createAPIKeyRequest is the body of POST /api-keys. Keys belong to the
+// calling user unless service_account names a service account.
+type createAPIKeyRequest struct {
+	Name           string     `json:"name" binding:"required"`
+	Scopes         []string   `json:"scopes"`
+	ServiceAccount string     `json:"service_account"`
+	RateLimit      string     `json:"rate_limit"`
+	ExpiresAt      *time.Time `json:"expires_at"`
+}
+
+// createAPIKeyResponse includes the key itself, which is never shown again
+type createAPIKeyResponse struct {
+	models.APIKey
+	Key string `json:"key"`
+}
+
+// CreateAPIKey issues a new API key
+func CreateAPIKey(db *storage.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req createAPIKeyRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(400, gin.H{"error": err.Error()})
+			return
+		}
+		if err := auth.ValidateScopes(req.Scopes); err != nil {
+			c.JSON(400, gin.H{"error": err.Error()})
+			return
+		}
+		if req.RateLimit != "" {
+			if _, err := ratelimit.ParseRule(req.RateLimit); err != nil {
+				c.JSON(400, gin.H{"error": err.Error()})
+				return
+			}
+		}
+		if req.ExpiresAt != nil && req.ExpiresAt.Before(time.Now()) {
+			c.JSON(400, gin.H{"error": "expires_at must be in the future"})
+			return
+		}
+
+		secret, prefix, hash, err := auth.GenerateAPIKey()
+		if err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+
+		key := models.APIKey{
+			Name:           req.Name,
+			Prefix:         prefix,
+			KeyHash:        hash,
+			ServiceAccount: req.ServiceAccount,
+			Scopes:         req.Scopes,
+			RateLimit:      req.RateLimit,
+			ExpiresAt:      req.ExpiresAt,
+		}
+		if req.ServiceAccount == "" {
+			userID := currentUserID(c)
+			key.UserID = &userID
+		}
+
+		if err := db.CreateAPIKey(c.Request.Context(), &key); err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(201, createAPIKeyResponse{APIKey: key, Key: secret})
+	}
+}
+
+// GetAPIKeys lists API keys without their secrets
+func GetAPIKeys(db *storage.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		keys, err := db.ListAPIKeys(c.Request.Context())
+		if err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(200, keys)
+	}
+}
+
+// RevokeAPIKey revokes an API key
+func RevokeAPIKey(db *storage.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			c.JSON(400, gin.H{"error": "invalid API key ID"})
+			return
+		}
+
+		if err := db.RevokeAPIKey(c.Request.Context(), id); err != nil {
+			if errors.Is(err, storage.ErrAPIKeyNotFound) {
+				c.JSON(404, gin.H{"error": err.Error()})
+				return
+			}
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(200, gin.H{"message": "API key revoked"})
+	}
+}
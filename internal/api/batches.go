@@ -0,0 +1,115 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/nuumz/f1ow/internal/engine"
+	"github.com/nuumz/f1ow/internal/models"
+	"github.com/nuumz/f1ow/internal/storage"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// batchRequest is the body of POST /workflows/:id/execute/batch
+type batchRequest struct {
+	Inputs      []map[string]interface{} `json:"inputs" binding:"required"`
+	BatchID     string                   `json:"batch_id,omitempty"`    // adds to this batch, creating it when new
+	Concurrency int                      `json:"concurrency,omitempty"` // most executions of the batch running at once
+}
+
+// batchResponse is the batch a batch execute request queued executions in
+type batchResponse struct {
+	Batch        *models.ExecutionBatch `json:"batch"`
+	ExecutionIDs []uuid.UUID            `json:"execution_ids"` // in the order of the inputs
+}
+
+// ExecuteWorkflowBatch queues one execution per input for the workers and
+// responds 202 with their batch
+func ExecuteWorkflowBatch(eng *engine.Engine) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			c.JSON(400, gin.H{"error": "invalid workflow ID"})
+			return
+		}
+
+		var req batchRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(400, gin.H{"error": err.Error()})
+			return
+		}
+		if len(req.Inputs) == 0 || len(req.Inputs) > engine.MaxBatchSize {
+			c.JSON(400, gin.H{"error": fmt.Sprintf("inputs must have 1 to %d items", engine.MaxBatchSize)})
+			return
+		}
+		if req.Concurrency < 0 {
+			c.JSON(400, gin.H{"error": "concurrency must be at least 0"})
+			return
+		}
+		opts := engine.BatchOptions{Concurrency: req.Concurrency}
+		if req.BatchID != "" {
+			if opts.ID, err = uuid.Parse(req.BatchID); err != nil {
+				c.JSON(400, gin.H{"error": "invalid batch ID"})
+				return
+			}
+		}
+
+		ctx := c.Request.Context()
+		pinned := c.Query("pinned_data") == "true"
+		if pinned {
+			ctx = engine.WithPinnedData(ctx)
+		}
+		environment := c.Query("environment")
+		if environment != "" && pinned {
+			c.JSON(400, gin.H{"error": "pinned data cannot be used with an environment"})
+			return
+		}
+
+		batch, executions, err := eng.SubmitBatch(ctx, id.String(), environment, req.Inputs, opts)
+		if errors.Is(err, storage.ErrWorkflowNotFound) || errors.Is(err, storage.ErrDeploymentNotFound) {
+			c.JSON(404, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, engine.ErrBatchWorkflowMismatch) {
+			c.JSON(409, gin.H{"error": err.Error()})
+			return
+		}
+		if quotaExceeded(c, err) {
+			return
+		}
+		if err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+
+		ids := make([]uuid.UUID, len(executions))
+		for i, execution := range executions {
+			ids[i] = execution.ID
+		}
+		c.JSON(202, batchResponse{Batch: batch, ExecutionIDs: ids})
+	}
+}
+
+// GetExecutionBatch returns a batch with its executions counted by status
+func GetExecutionBatch(eng *engine.Engine) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			c.JSON(400, gin.H{"error": "invalid batch ID"})
+			return
+		}
+
+		batch, err := eng.ExecutionBatch(c.Request.Context(), id)
+		if errors.Is(err, storage.ErrBatchNotFound) {
+			c.JSON(404, gin.H{"error": err.Error()})
+			return
+		}
+		if err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(200, batch)
+	}
+}
@@ -0,0 +1,105 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/nuumz/f1ow/internal/binarydata"
+	"github.com/nuumz/f1ow/internal/engine"
+	"github.com/nuumz/f1ow/internal/storage"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// DownloadBinaryData streams binary content referenced by a node output handle
+func DownloadBinaryData(eng *engine.Engine) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		manager := eng.BinaryData()
+		if manager == nil {
+			c.JSON(404, gin.H{"error": "binary data storage is not configured"})
+			return
+		}
+
+		id, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			c.JSON(400, gin.H{"error": "invalid binary data ID"})
+			return
+		}
+
+		data, reader, err := manager.Open(c.Request.Context(), id)
+		if errors.Is(err, binarydata.ErrNotFound) {
+			c.JSON(404, gin.H{"error": err.Error()})
+			return
+		}
+		if err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+		defer reader.Close()
+
+		mimeType := data.MimeType
+		if mimeType == "" {
+			mimeType = "application/octet-stream"
+		}
+
+		c.Header("Content-Type", mimeType)
+		if data.FileName != "" {
+			c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", data.FileName))
+		}
+		c.Status(200)
+		io.Copy(c.Writer, reader)
+	}
+}
+
+// GetExecutionNodeOutput returns a node's output with offloaded payloads
+// loaded back from binary data storage
+func GetExecutionNodeOutput(eng *engine.Engine, db *storage.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			c.JSON(400, gin.H{"error": "invalid execution ID"})
+			return
+		}
+
+		execution, err := db.GetExecution(c.Request.Context(), id)
+		if err != nil {
+			c.JSON(404, gin.H{"error": err.Error()})
+			return
+		}
+
+		output, ok := execution.Output[c.Param("node")]
+		if !ok {
+			c.JSON(404, gin.H{"error": "node output not found"})
+			return
+		}
+
+		output, ok = loadNodeOutput(c, eng, output)
+		if !ok {
+			return
+		}
+
+		c.JSON(200, output)
+	}
+}
+
+// loadNodeOutput loads an offloaded node output back from binary data
+// storage, writing the error response when that fails
+func loadNodeOutput(c *gin.Context, eng *engine.Engine, output interface{}) (interface{}, bool) {
+	if !binarydata.IsOffloaded(output) {
+		return output, true
+	}
+
+	manager := eng.BinaryData()
+	if manager == nil {
+		c.JSON(404, gin.H{"error": "binary data storage is not configured"})
+		return nil, false
+	}
+	loaded, err := manager.Load(c.Request.Context(), output)
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return nil, false
+	}
+	return loaded, true
+}
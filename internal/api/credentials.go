@@ -0,0 +1,166 @@
+package api
+
+import (
+	"errors"
+	"html"
+	"net/http"
+	"time"
+
+	"github.com/nuumz/f1ow/internal/auth"
+	"github.com/nuumz/f1ow/internal/credentials"
+	"github.com/nuumz/f1ow/internal/engine"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// createCredentialRequest is the body of POST /credentials
+type createCredentialRequest struct {
+	Name string                 `json:"name" binding:"required"`
+	Type string                 `json:"type" binding:"required"`
+	Data map[string]interface{} `json:"data"`
+}
+
+// credentialsManager returns the vault or writes an error response when it is not configured
+func credentialsManager(c *gin.Context, eng *engine.Engine) *credentials.Manager {
+	manager := eng.Credentials()
+	if manager == nil {
+		c.JSON(503, gin.H{"error": "credentials vault is not configured; set CREDENTIALS_ENCRYPTION_KEY"})
+	}
+	return manager
+}
+
+// CreateCredential stores a new encrypted credential. Secrets are never returned.
+func CreateCredential(eng *engine.Engine) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		manager := credentialsManager(c, eng)
+		if manager == nil {
+			return
+		}
+
+		var req createCredentialRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(400, gin.H{"error": err.Error()})
+			return
+		}
+
+		credential, err := manager.Create(c.Request.Context(), req.Name, req.Type, currentUserID(c), req.Data)
+		if err != nil {
+			c.JSON(400, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(201, credential)
+	}
+}
+
+// GetCredentials lists credential metadata
+func GetCredentials(eng *engine.Engine) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		manager := credentialsManager(c, eng)
+		if manager == nil {
+			return
+		}
+
+		list, err := manager.List(c.Request.Context())
+		if err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(200, list)
+	}
+}
+
+// DeleteCredential removes a credential
+func DeleteCredential(eng *engine.Engine) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		manager := credentialsManager(c, eng)
+		if manager == nil {
+			return
+		}
+
+		id, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			c.JSON(400, gin.H{"error": "invalid credential ID"})
+			return
+		}
+
+		if err := manager.Delete(c.Request.Context(), id); err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(200, gin.H{"message": "Credential deleted successfully"})
+	}
+}
+
+// oauth2SessionCookie binds an OAuth2 authorization to the browser that
+// started it
+const oauth2SessionCookie = "f1ow_oauth2_session"
+
+// oauth2SessionMaxAge matches how long an authorization request is kept
+const oauth2SessionMaxAge = 10 * time.Minute
+
+// AuthorizeOAuth2 redirects the browser to the provider to authorize the
+// OAuth2 credential given by the credential_id query parameter
+func AuthorizeOAuth2(eng *engine.Engine) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		manager := credentialsManager(c, eng)
+		if manager == nil {
+			return
+		}
+
+		id, err := uuid.Parse(c.Query("credential_id"))
+		if err != nil {
+			c.JSON(400, gin.H{"error": "invalid credential ID"})
+			return
+		}
+
+		var subject string
+		if claims, ok := auth.FromContext(c.Request.Context()); ok {
+			subject = claims.Subject
+		}
+
+		authURL, session, err := manager.AuthorizeURL(c.Request.Context(), id, subject)
+		if errors.Is(err, credentials.ErrNotFound) {
+			c.JSON(404, gin.H{"error": err.Error()})
+			return
+		}
+		if err != nil {
+			c.JSON(400, gin.H{"error": err.Error()})
+			return
+		}
+
+		// Only the browser that started the flow can complete it
+		c.SetSameSite(http.SameSiteLaxMode)
+		c.SetCookie(oauth2SessionCookie, session, int(oauth2SessionMaxAge.Seconds()), "/", "", c.Request.TLS != nil, true)
+		c.Redirect(302, authURL)
+	}
+}
+
+// OAuth2Callback completes the authorization-code flow and stores the
+// encrypted tokens on the credential
+func OAuth2Callback(eng *engine.Engine) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		manager := credentialsManager(c, eng)
+		if manager == nil {
+			return
+		}
+
+		if providerErr := c.Query("error"); providerErr != "" {
+			c.JSON(400, gin.H{"error": providerErr, "description": c.Query("error_description")})
+			return
+		}
+
+		session, _ := c.Cookie(oauth2SessionCookie)
+		c.SetCookie(oauth2SessionCookie, "", -1, "/", "", c.Request.TLS != nil, true)
+
+		credential, err := manager.CompleteAuthorization(c.Request.Context(), c.Query("state"), session, c.Query("code"))
+		if err != nil {
+			c.JSON(400, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.Data(200, "text/html; charset=utf-8", []byte("<html><body><p>Credential <b>"+
+			html.EscapeString(credential.Name)+"</b> is connected. You can close this window.</p></body></html>"))
+	}
+}
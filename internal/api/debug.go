@@ -0,0 +1,160 @@
+package api
+
+import (
+	"errors"
+
+	"github.com/nuumz/f1ow/internal/engine"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+)
+
+var debugUpgrader = websocket.Upgrader{}
+
+// debugError writes the response for a debugger error
+func debugError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, engine.ErrNotDebugging):
+		c.JSON(404, gin.H{"error": err.Error()})
+	case errors.Is(err, engine.ErrNotPaused):
+		c.JSON(409, gin.H{"error": err.Error()})
+	default:
+		c.JSON(400, gin.H{"error": err.Error()})
+	}
+}
+
+// DebugWorkflow starts a debug execution that pauses before each node
+func DebugWorkflow(eng *engine.Engine) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		workflowID := c.Param("id")
+
+		var input map[string]interface{}
+		if err := c.ShouldBindJSON(&input); err != nil {
+			c.JSON(400, gin.H{"error": err.Error()})
+			return
+		}
+
+		ctx := c.Request.Context()
+		if c.Query("pinned_data") == "true" {
+			ctx = engine.WithPinnedData(ctx)
+		}
+
+		execution, err := eng.Debug(ctx, workflowID, input)
+		if quotaExceeded(c, err) {
+			return
+		}
+		if err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(202, execution)
+	}
+}
+
+// GetDebugState returns the node a debug execution is paused at and its
+// prepared input
+func GetDebugState(eng *engine.Engine) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		state, err := eng.DebugState(c.Param("id"))
+		if err != nil {
+			debugError(c, err)
+			return
+		}
+		c.JSON(200, state)
+	}
+}
+
+// SendDebugCommand resumes a paused debug execution
+func SendDebugCommand(eng *engine.Engine) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var command engine.DebugCommand
+		if err := c.ShouldBindJSON(&command); err != nil {
+			c.JSON(400, gin.H{"error": err.Error()})
+			return
+		}
+
+		if err := eng.SendDebugCommand(c.Param("id"), command); err != nil {
+			debugError(c, err)
+			return
+		}
+		c.JSON(200, gin.H{"message": "debug command accepted"})
+	}
+}
+
+// DebugWebSocket streams a debug execution's events, including node.paused
+// with the prepared input, and accepts DebugCommand messages. The socket
+// closes when the execution finishes.
+func DebugWebSocket(eng *engine.Engine) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		executionID := c.Param("id")
+		if _, err := eng.DebugState(executionID); err != nil {
+			debugError(c, err)
+			return
+		}
+
+		conn, err := debugUpgrader.Upgrade(c.Writer, c.Request, nil)
+		if err != nil {
+			// The upgrader has written the error response
+			return
+		}
+		defer conn.Close()
+
+		outgoing := make(chan interface{}, 64)
+		done := make(chan struct{})
+		unsubscribe := eng.Subscribe(func(event engine.Event) {
+			if event.ExecutionID != executionID {
+				return
+			}
+			// Subscribers must not block the execution; a client that falls
+			// this far behind can re-read the state over HTTP
+			select {
+			case outgoing <- event:
+			default:
+			}
+		})
+		defer unsubscribe()
+
+		// Commands arrive on the read loop; replies go through the writer
+		go func() {
+			defer close(done)
+			for {
+				var command engine.DebugCommand
+				if err := conn.ReadJSON(&command); err != nil {
+					return
+				}
+				if err := eng.SendDebugCommand(executionID, command); err != nil {
+					select {
+					case outgoing <- gin.H{"error": err.Error()}:
+					default:
+					}
+				}
+			}
+		}()
+
+		// Read the state after subscribing so a pause in between is not missed
+		state, err := eng.DebugState(executionID)
+		if err != nil {
+			conn.WriteJSON(gin.H{"error": err.Error()})
+			return
+		}
+		if err := conn.WriteJSON(state); err != nil {
+			return
+		}
+		for {
+			select {
+			case <-done:
+				return
+			case message := <-outgoing:
+				if err := conn.WriteJSON(message); err != nil {
+					return
+				}
+				if event, ok := message.(engine.Event); ok &&
+					(event.Type == engine.EventExecutionCompleted || event.Type == engine.EventExecutionFailed) {
+					conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+					return
+				}
+			}
+		}
+	}
+}
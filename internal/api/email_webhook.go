@@ -0,0 +1,135 @@
+package api
+
+import (
+	"bytes"
+	"crypto/subtle"
+	"io"
+	"strings"
+
+	"github.com/nuumz/f1ow/internal/engine"
+	"github.com/nuumz/f1ow/internal/models"
+	"github.com/nuumz/f1ow/internal/nodes"
+	"github.com/nuumz/f1ow/internal/storage"
+	"github.com/nuumz/f1ow/internal/triggers"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// maxInboundEmailSize limits the size of provider-delivered messages
+const maxInboundEmailSize = 25 << 20
+
+// emailTokenHeader carries the webhook secret of an email_trigger node;
+// providers that cannot set headers send it as the token query parameter
+const emailTokenHeader = "X-F1ow-Token"
+
+// ReceiveEmailWebhook accepts inbound email from a mail provider and queues
+// an execution of the workflow. The delivery must carry the webhook secret
+// of one of the workflow's email_trigger nodes. Raw MIME (message/rfc822),
+// provider JSON, and form posts (with an optional raw "email" field) are
+// supported.
+func ReceiveEmailWebhook(eng *engine.Engine, db *storage.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		idStr := c.Param("id")
+		id, err := uuid.Parse(idStr)
+		if err != nil {
+			c.JSON(400, gin.H{"error": "invalid workflow ID"})
+			return
+		}
+		workflow, ok := activeWorkflow(c, db, id)
+		if !ok {
+			return
+		}
+
+		emailNodes := triggerNodes(workflow, "email_trigger")
+		if len(emailNodes) == 0 {
+			c.JSON(404, gin.H{"error": "workflow has no email trigger"})
+			return
+		}
+		token := c.GetHeader(emailTokenHeader)
+		if token == "" {
+			token = c.Query("token")
+		}
+		if !emailTokenValid(emailNodes, token) {
+			c.JSON(401, gin.H{"error": "invalid token"})
+			return
+		}
+
+		c.Request.Body = io.NopCloser(io.LimitReader(c.Request.Body, maxInboundEmailSize))
+		contentType := c.ContentType()
+
+		var payload map[string]interface{}
+
+		switch {
+		case contentType == "application/json":
+			if err := c.ShouldBindJSON(&payload); err != nil {
+				c.JSON(400, gin.H{"error": err.Error()})
+				return
+			}
+
+		case strings.HasPrefix(contentType, "multipart/form-data"),
+			contentType == "application/x-www-form-urlencoded":
+			if err := c.Request.ParseMultipartForm(maxInboundEmailSize); err != nil && contentType != "application/x-www-form-urlencoded" {
+				c.JSON(400, gin.H{"error": err.Error()})
+				return
+			}
+			if raw := c.PostForm("email"); raw != "" {
+				payload, err = triggers.ParseEmailMessage(strings.NewReader(raw), true)
+				if err != nil {
+					c.JSON(400, gin.H{"error": err.Error()})
+					return
+				}
+			} else {
+				payload = make(map[string]interface{})
+				for key, values := range c.Request.PostForm {
+					if len(values) == 1 {
+						payload[key] = values[0]
+					} else {
+						payload[key] = values
+					}
+				}
+			}
+
+		default:
+			raw, err := io.ReadAll(c.Request.Body)
+			if err != nil {
+				c.JSON(400, gin.H{"error": err.Error()})
+				return
+			}
+			payload, err = triggers.ParseEmailMessage(bytes.NewReader(raw), true)
+			if err != nil {
+				c.JSON(400, gin.H{"error": err.Error()})
+				return
+			}
+		}
+
+		// Providers that retry deliveries send the same Idempotency-Key
+		ctx := c.Request.Context()
+		if key := c.GetHeader("Idempotency-Key"); key != "" {
+			ctx = engine.WithIdempotencyKey(ctx, key)
+		}
+		job, err := eng.Enqueue(ctx, id.String(), payload)
+		if quotaExceeded(c, err) {
+			return
+		}
+		if err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(202, gin.H{"job_id": job.ID})
+	}
+}
+
+// emailTokenValid reports whether token matches the webhook secret of one of
+// the email_trigger nodes; nodes without a secret accept no deliveries
+func emailTokenValid(emailNodes []models.Node, token string) bool {
+	for _, node := range emailNodes {
+		config, err := nodes.ParseEmailTriggerConfig(node.Config)
+		if err == nil && config.WebhookSecret != "" &&
+			subtle.ConstantTimeCompare([]byte(config.WebhookSecret), []byte(token)) == 1 {
+			return true
+		}
+	}
+	return false
+}
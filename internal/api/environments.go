@@ -0,0 +1,344 @@
+package api
+
+import (
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/nuumz/f1ow/internal/diff"
+	"github.com/nuumz/f1ow/internal/models"
+	"github.com/nuumz/f1ow/internal/storage"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// deployRequest is the body of PUT /workflows/:id/deployments/:environment
+type deployRequest struct {
+	Version     int                    `json:"version"` // the current version when zero
+	Credentials map[string]string      `json:"credentials"`
+	Variables   map[string]interface{} `json:"variables"`
+}
+
+// promoteRequest is the body of POST /workflows/:id/promote
+type promoteRequest struct {
+	From    string `json:"from" binding:"required"`
+	To      string `json:"to" binding:"required"`
+	Version int    `json:"version"` // the version deployed to From when zero
+	DryRun  bool   `json:"dry_run"`
+}
+
+// promoteResponse previews or reports a promotion. Diff compares the
+// version deployed to the target with the promoted one, both with the
+// target's mappings applied.
+type promoteResponse struct {
+	From       string             `json:"from"`
+	To         string             `json:"to"`
+	Version    int                `json:"version"`
+	Previous   *int               `json:"previous_version"`
+	Diff       *diff.WorkflowDiff `json:"diff"`
+	Deployment *models.Deployment `json:"deployment"` // nil for dry runs
+}
+
+// versionDiff is the response of GET /workflows/:id/diff
+type versionDiff struct {
+	From int                `json:"from"`
+	To   int                `json:"to"`
+	Diff *diff.WorkflowDiff `json:"diff"`
+}
+
+// environmentError writes the response for an environment, version, or deployment error
+func environmentError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, storage.ErrEnvironmentNotFound), errors.Is(err, storage.ErrDeploymentNotFound),
+		errors.Is(err, storage.ErrWorkflowVersionNotFound):
+		c.JSON(404, gin.H{"error": err.Error()})
+	case errors.Is(err, storage.ErrEnvironmentExists):
+		c.JSON(409, gin.H{"error": err.Error()})
+	default:
+		c.JSON(500, gin.H{"error": err.Error()})
+	}
+}
+
+// workflowParam parses the :id parameter and checks the workflow exists
+func workflowParam(c *gin.Context, db storage.WorkflowRepository) (*models.Workflow, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(400, gin.H{"error": "invalid workflow ID"})
+		return nil, false
+	}
+
+	workflow, err := db.GetWorkflow(c.Request.Context(), id)
+	if errors.Is(err, storage.ErrWorkflowNotFound) {
+		c.JSON(404, gin.H{"error": err.Error()})
+		return nil, false
+	}
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return nil, false
+	}
+	return workflow, true
+}
+
+func GetEnvironments(db storage.DeploymentRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		environments, err := db.ListEnvironments(c.Request.Context())
+		if err != nil {
+			environmentError(c, err)
+			return
+		}
+		c.JSON(200, environments)
+	}
+}
+
+func CreateEnvironment(db storage.DeploymentRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var environment models.Environment
+		if err := c.ShouldBindJSON(&environment); err != nil {
+			c.JSON(400, gin.H{"error": err.Error()})
+			return
+		}
+
+		if err := db.CreateEnvironment(c.Request.Context(), &environment); err != nil {
+			environmentError(c, err)
+			return
+		}
+		c.JSON(201, environment)
+	}
+}
+
+// DeleteEnvironment removes an environment and every deployment to it
+func DeleteEnvironment(db storage.DeploymentRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if err := db.DeleteEnvironment(c.Request.Context(), c.Param("name")); err != nil {
+			environmentError(c, err)
+			return
+		}
+		c.JSON(200, gin.H{"message": "environment deleted"})
+	}
+}
+
+// GetWorkflowVersions lists a workflow's saved versions without definitions
+func GetWorkflowVersions(db storage.WorkflowRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		workflow, ok := workflowParam(c, db)
+		if !ok {
+			return
+		}
+
+		versions, err := db.ListWorkflowVersions(c.Request.Context(), workflow.ID)
+		if err != nil {
+			environmentError(c, err)
+			return
+		}
+		c.JSON(200, versions)
+	}
+}
+
+func GetWorkflowVersion(db storage.WorkflowRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			c.JSON(400, gin.H{"error": "invalid workflow ID"})
+			return
+		}
+		number, err := strconv.Atoi(c.Param("version"))
+		if err != nil {
+			c.JSON(400, gin.H{"error": "invalid version"})
+			return
+		}
+
+		version, err := db.GetWorkflowVersion(c.Request.Context(), id, number)
+		if err != nil {
+			environmentError(c, err)
+			return
+		}
+		c.JSON(200, version)
+	}
+}
+
+// GetWorkflowDiff compares two saved versions of a workflow. to defaults
+// to the current version.
+func GetWorkflowDiff(db storage.WorkflowRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		workflow, ok := workflowParam(c, db)
+		if !ok {
+			return
+		}
+
+		from, err := strconv.Atoi(c.Query("from"))
+		if err != nil {
+			c.JSON(400, gin.H{"error": "invalid from version"})
+			return
+		}
+		to := workflow.Version
+		if value := c.Query("to"); value != "" {
+			if to, err = strconv.Atoi(value); err != nil {
+				c.JSON(400, gin.H{"error": "invalid to version"})
+				return
+			}
+		}
+
+		ctx := c.Request.Context()
+		fromVersion, err := db.GetWorkflowVersion(ctx, workflow.ID, from)
+		if err != nil {
+			environmentError(c, err)
+			return
+		}
+		toVersion, err := db.GetWorkflowVersion(ctx, workflow.ID, to)
+		if err != nil {
+			environmentError(c, err)
+			return
+		}
+
+		c.JSON(200, versionDiff{
+			From: from,
+			To:   to,
+			Diff: diff.Workflows(&fromVersion.Definition, &toVersion.Definition),
+		})
+	}
+}
+
+// GetDeployments lists the environments a workflow is deployed to
+func GetDeployments(db storage.Repository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		workflow, ok := workflowParam(c, db)
+		if !ok {
+			return
+		}
+
+		deployments, err := db.ListDeployments(c.Request.Context(), workflow.ID)
+		if err != nil {
+			environmentError(c, err)
+			return
+		}
+		c.JSON(200, deployments)
+	}
+}
+
+// DeployWorkflow deploys a workflow version to an environment with the
+// environment's credential and variable mappings
+func DeployWorkflow(db storage.Repository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		workflow, ok := workflowParam(c, db)
+		if !ok {
+			return
+		}
+
+		var req deployRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(400, gin.H{"error": err.Error()})
+			return
+		}
+		if req.Version == 0 {
+			req.Version = workflow.Version
+		}
+		if _, err := db.GetWorkflowVersion(c.Request.Context(), workflow.ID, req.Version); err != nil {
+			environmentError(c, err)
+			return
+		}
+
+		deployment := &models.Deployment{
+			WorkflowID:  workflow.ID,
+			Environment: c.Param("environment"),
+			Version:     req.Version,
+			Credentials: req.Credentials,
+			Variables:   req.Variables,
+			DeployedBy:  currentUserID(c),
+			DeployedAt:  time.Now(),
+		}
+		if err := db.SaveDeployment(c.Request.Context(), deployment); err != nil {
+			environmentError(c, err)
+			return
+		}
+		c.JSON(200, deployment)
+	}
+}
+
+// UndeployWorkflow removes a workflow from an environment
+func UndeployWorkflow(db storage.Repository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		workflow, ok := workflowParam(c, db)
+		if !ok {
+			return
+		}
+
+		if err := db.DeleteDeployment(c.Request.Context(), workflow.ID, c.Param("environment")); err != nil {
+			environmentError(c, err)
+			return
+		}
+		c.JSON(200, gin.H{"message": "deployment removed"})
+	}
+}
+
+// PromoteWorkflow copies the version deployed to one environment to
+// another, keeping the target's credential and variable mappings. With
+// dry_run it only returns the diff against the target's current version.
+func PromoteWorkflow(db storage.Repository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		workflow, ok := workflowParam(c, db)
+		if !ok {
+			return
+		}
+
+		var req promoteRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(400, gin.H{"error": err.Error()})
+			return
+		}
+		ctx := c.Request.Context()
+
+		source, err := db.GetDeployment(ctx, workflow.ID, req.From)
+		if err != nil {
+			environmentError(c, err)
+			return
+		}
+		if req.Version == 0 {
+			req.Version = source.Version
+		}
+		version, err := db.GetWorkflowVersion(ctx, workflow.ID, req.Version)
+		if err != nil {
+			environmentError(c, err)
+			return
+		}
+
+		// The target keeps its mappings; a first deployment starts without any
+		target, err := db.GetDeployment(ctx, workflow.ID, req.To)
+		if errors.Is(err, storage.ErrDeploymentNotFound) {
+			if _, err := db.GetEnvironmentByName(ctx, req.To); err != nil {
+				environmentError(c, err)
+				return
+			}
+			target = &models.Deployment{WorkflowID: workflow.ID, Environment: req.To}
+		} else if err != nil {
+			environmentError(c, err)
+			return
+		}
+
+		response := promoteResponse{From: req.From, To: req.To, Version: version.Version}
+		current := models.WorkflowDefinition{}
+		if target.Version != 0 {
+			previous, err := db.GetWorkflowVersion(ctx, workflow.ID, target.Version)
+			if err != nil {
+				environmentError(c, err)
+				return
+			}
+			current = target.Apply(previous)
+			response.Previous = &previous.Version
+		}
+		promoted := target.Apply(version)
+		response.Diff = diff.Workflows(&current, &promoted)
+
+		if !req.DryRun {
+			target.Version = version.Version
+			target.DeployedBy = currentUserID(c)
+			target.DeployedAt = time.Now()
+			if err := db.SaveDeployment(ctx, target); err != nil {
+				environmentError(c, err)
+				return
+			}
+			response.Deployment = target
+		}
+		c.JSON(200, response)
+	}
+}
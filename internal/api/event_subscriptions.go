@@ -0,0 +1,219 @@
+package api
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/nuumz/f1ow/internal/models"
+	"github.com/nuumz/f1ow/internal/subscriptions"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// eventSubscriptionRequest is the body of POST /event-subscriptions and
+// PUT /event-subscriptions/:id
+type eventSubscriptionRequest struct {
+	URL         string   `json:"url" binding:"required"`
+	Description string   `json:"description"`
+	Events      []string `json:"events" binding:"required"`
+	Secret      string   `json:"secret"`  // generated when creating without one; kept when updating without one
+	Enabled     *bool    `json:"enabled"` // true when omitted
+}
+
+func (r eventSubscriptionRequest) subscription() *models.EventSubscription {
+	subscription := &models.EventSubscription{
+		URL:         r.URL,
+		Description: r.Description,
+		Events:      r.Events,
+		Secret:      r.Secret,
+		Enabled:     true,
+	}
+	if r.Enabled != nil {
+		subscription.Enabled = *r.Enabled
+	}
+	return subscription
+}
+
+// subscriptionsManager returns the subscriptions manager or writes an error response when it is not configured
+func subscriptionsManager(c *gin.Context, manager *subscriptions.Manager) *subscriptions.Manager {
+	if manager == nil {
+		c.JSON(503, gin.H{"error": "event subscriptions are not configured"})
+	}
+	return manager
+}
+
+// subscriptionError writes the response for a subscriptions manager error
+func subscriptionError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, subscriptions.ErrNotFound), errors.Is(err, subscriptions.ErrDeliveryNotFound):
+		c.JSON(404, gin.H{"error": err.Error()})
+	case errors.Is(err, subscriptions.ErrInvalid):
+		c.JSON(400, gin.H{"error": err.Error()})
+	default:
+		c.JSON(500, gin.H{"error": err.Error()})
+	}
+}
+
+// subscriptionParam parses the :id parameter
+func subscriptionParam(c *gin.Context) (uuid.UUID, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(400, gin.H{"error": "invalid subscription ID"})
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+// GetEventSubscriptions lists event subscriptions without their secrets
+func GetEventSubscriptions(manager *subscriptions.Manager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if subscriptionsManager(c, manager) == nil {
+			return
+		}
+
+		list, err := manager.List(c.Request.Context())
+		if err != nil {
+			subscriptionError(c, err)
+			return
+		}
+		c.JSON(200, list)
+	}
+}
+
+// CreateEventSubscription registers a URL to call on events. The response
+// is the only one that includes the signing secret.
+func CreateEventSubscription(manager *subscriptions.Manager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if subscriptionsManager(c, manager) == nil {
+			return
+		}
+
+		var req eventSubscriptionRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(400, gin.H{"error": err.Error()})
+			return
+		}
+
+		subscription, err := manager.Create(c.Request.Context(), req.subscription())
+		if err != nil {
+			subscriptionError(c, err)
+			return
+		}
+		c.JSON(201, subscription)
+	}
+}
+
+// GetEventSubscription returns an event subscription without its secret
+func GetEventSubscription(manager *subscriptions.Manager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if subscriptionsManager(c, manager) == nil {
+			return
+		}
+		id, ok := subscriptionParam(c)
+		if !ok {
+			return
+		}
+
+		subscription, err := manager.Get(c.Request.Context(), id)
+		if err != nil {
+			subscriptionError(c, err)
+			return
+		}
+		c.JSON(200, subscription)
+	}
+}
+
+// UpdateEventSubscription replaces an event subscription
+func UpdateEventSubscription(manager *subscriptions.Manager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if subscriptionsManager(c, manager) == nil {
+			return
+		}
+		id, ok := subscriptionParam(c)
+		if !ok {
+			return
+		}
+
+		var req eventSubscriptionRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(400, gin.H{"error": err.Error()})
+			return
+		}
+
+		subscription := req.subscription()
+		subscription.ID = id
+		updated, err := manager.Update(c.Request.Context(), subscription)
+		if err != nil {
+			subscriptionError(c, err)
+			return
+		}
+		c.JSON(200, updated)
+	}
+}
+
+// DeleteEventSubscription removes an event subscription and its deliveries
+func DeleteEventSubscription(manager *subscriptions.Manager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if subscriptionsManager(c, manager) == nil {
+			return
+		}
+		id, ok := subscriptionParam(c)
+		if !ok {
+			return
+		}
+
+		if err := manager.Delete(c.Request.Context(), id); err != nil {
+			subscriptionError(c, err)
+			return
+		}
+		c.JSON(200, gin.H{"message": "event subscription deleted"})
+	}
+}
+
+// GetEventDeliveries returns a subscription's most recent deliveries
+func GetEventDeliveries(manager *subscriptions.Manager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if subscriptionsManager(c, manager) == nil {
+			return
+		}
+		id, ok := subscriptionParam(c)
+		if !ok {
+			return
+		}
+		limit, _ := strconv.Atoi(c.Query("limit"))
+
+		deliveries, err := manager.Deliveries(c.Request.Context(), id, limit)
+		if err != nil {
+			subscriptionError(c, err)
+			return
+		}
+		c.JSON(200, deliveries)
+	}
+}
+
+// RedeliverEvent sends a logged delivery's payload again as a new delivery
+// and responds with it after the first attempt
+func RedeliverEvent(manager *subscriptions.Manager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if subscriptionsManager(c, manager) == nil {
+			return
+		}
+		id, ok := subscriptionParam(c)
+		if !ok {
+			return
+		}
+		deliveryID, err := uuid.Parse(c.Param("delivery"))
+		if err != nil {
+			c.JSON(400, gin.H{"error": "invalid delivery ID"})
+			return
+		}
+
+		delivery, err := manager.Redeliver(c.Request.Context(), id, deliveryID)
+		if err != nil {
+			subscriptionError(c, err)
+			return
+		}
+		c.JSON(201, delivery)
+	}
+}
@@ -0,0 +1,75 @@
+package api
+
+import (
+	"errors"
+
+	"github.com/nuumz/f1ow/internal/binarydata"
+	"github.com/nuumz/f1ow/internal/diff"
+	"github.com/nuumz/f1ow/internal/engine"
+	"github.com/nuumz/f1ow/internal/models"
+	"github.com/nuumz/f1ow/internal/storage"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// CompareExecutions diffs two executions of a workflow, a and b, node by
+// node: their status, duration, and output
+func CompareExecutions(eng *engine.Engine, db storage.ExecutionRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		a, ok := comparedExecution(c, db, "a")
+		if !ok {
+			return
+		}
+		b, ok := comparedExecution(c, db, "b")
+		if !ok {
+			return
+		}
+		if a.WorkflowID != b.WorkflowID {
+			c.JSON(400, gin.H{"error": "executions belong to different workflows"})
+			return
+		}
+		if !loadNodeOutputs(c, eng, a) || !loadNodeOutputs(c, eng, b) {
+			return
+		}
+
+		c.JSON(200, diff.Executions(a, b))
+	}
+}
+
+// comparedExecution loads the execution named by the query parameter
+func comparedExecution(c *gin.Context, db storage.ExecutionRepository, param string) (*models.Execution, bool) {
+	id, err := uuid.Parse(c.Query(param))
+	if err != nil {
+		c.JSON(400, gin.H{"error": "invalid execution ID in " + param})
+		return nil, false
+	}
+
+	execution, err := db.GetExecution(c.Request.Context(), id)
+	if errors.Is(err, storage.ErrExecutionNotFound) {
+		c.JSON(404, gin.H{"error": err.Error()})
+		return nil, false
+	}
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return nil, false
+	}
+	return execution, true
+}
+
+// loadNodeOutputs replaces the offloaded node outputs of an execution with
+// the outputs themselves so they can be compared
+func loadNodeOutputs(c *gin.Context, eng *engine.Engine, execution *models.Execution) bool {
+	for nodeID, run := range execution.Context.NodeExecutions {
+		if !binarydata.IsOffloaded(run.Output) {
+			continue
+		}
+		output, ok := loadNodeOutput(c, eng, run.Output)
+		if !ok {
+			return false
+		}
+		run.Output, _ = output.(map[string]interface{})
+		execution.Context.NodeExecutions[nodeID] = run
+	}
+	return true
+}
@@ -0,0 +1,158 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/nuumz/f1ow/internal/engine"
+	"github.com/nuumz/f1ow/internal/models"
+	"github.com/nuumz/f1ow/internal/storage"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// sseKeepAlive is how often an idle event stream sends a comment so
+// proxies keep the connection open
+const sseKeepAlive = 15 * time.Second
+
+// executionFinished reports whether the execution has stopped running
+func executionFinished(status models.ExecutionStatus) bool {
+	return status == models.ExecutionStatusCompleted || status == models.ExecutionStatusFailed ||
+		status == models.ExecutionStatusCancelled
+}
+
+// finalEvent reports whether the event ends its execution
+func finalEvent(event engine.Event) bool {
+	return event.Type == engine.EventExecutionCompleted || event.Type == engine.EventExecutionFailed
+}
+
+// writeSSE writes one Server-Sent Event and flushes it to the client
+func writeSSE(c *gin.Context, id string, event engine.Event) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	if id != "" {
+		fmt.Fprintf(c.Writer, "id: %s\n", id)
+	}
+	if _, err := fmt.Fprintf(c.Writer, "event: %s\ndata: %s\n\n", event.Type, data); err != nil {
+		return err
+	}
+	c.Writer.Flush()
+	return nil
+}
+
+// StreamExecutionEvents streams an execution's events as Server-Sent Events,
+// for clients behind proxies that block WebSockets. Each event carries its
+// stream ID; a client that reconnects with Last-Event-ID resumes after that
+// event, and one that connects without it gets the events from the start.
+// The stream ends after the execution completes or fails.
+func StreamExecutionEvents(eng *engine.Engine, db *storage.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			c.JSON(400, gin.H{"error": "invalid execution ID"})
+			return
+		}
+		ctx := c.Request.Context()
+		if _, err := db.GetExecution(ctx, id); err != nil {
+			c.JSON(404, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.Header("Content-Type", "text/event-stream")
+		c.Header("Cache-Control", "no-cache")
+		c.Header("Connection", "keep-alive")
+		c.Header("X-Accel-Buffering", "no") // disable nginx response buffering
+		c.Status(200)
+		fmt.Fprint(c.Writer, "retry: 3000\n\n")
+		c.Writer.Flush()
+
+		lastID := c.GetHeader("Last-Event-ID")
+		if lastID == "" {
+			lastID = c.Query("last_event_id")
+		}
+		for {
+			events, err := eng.ExecutionEvents(ctx, id.String(), lastID, sseKeepAlive)
+			if errors.Is(err, engine.ErrEventStreamUnavailable) {
+				streamLocalEvents(c, eng, db, id)
+				return
+			}
+			if err != nil {
+				if ctx.Err() == nil {
+					fmt.Fprintf(c.Writer, "event: error\ndata: %q\n\n", err.Error())
+					c.Writer.Flush()
+				}
+				return
+			}
+
+			for _, streamed := range events {
+				if err := writeSSE(c, streamed.ID, streamed.Event); err != nil {
+					return
+				}
+				lastID = streamed.ID
+				if finalEvent(streamed.Event) {
+					return
+				}
+			}
+			if len(events) > 0 {
+				continue
+			}
+
+			// Nothing new: stop if the execution finished without a final
+			// event left in the stream, such as when it has expired
+			execution, err := db.GetExecution(ctx, id)
+			if err != nil || executionFinished(execution.Status) {
+				return
+			}
+			if _, err := io.WriteString(c.Writer, ": keep-alive\n\n"); err != nil {
+				return
+			}
+			c.Writer.Flush()
+		}
+	}
+}
+
+// streamLocalEvents streams the events of an execution run by this process,
+// for engines without Redis. Events before the client connected are not
+// replayed.
+func streamLocalEvents(c *gin.Context, eng *engine.Engine, db *storage.DB, id uuid.UUID) {
+	events := make(chan engine.Event, 64)
+	unsubscribe := eng.Subscribe(func(event engine.Event) {
+		if event.ExecutionID != id.String() {
+			return
+		}
+		select {
+		case events <- event:
+		default:
+		}
+	})
+	defer unsubscribe()
+
+	ctx := c.Request.Context()
+	if execution, err := db.GetExecution(ctx, id); err != nil || executionFinished(execution.Status) {
+		return
+	}
+
+	ticker := time.NewTicker(sseKeepAlive)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event := <-events:
+			if err := writeSSE(c, "", event); err != nil || finalEvent(event) {
+				return
+			}
+		case <-ticker.C:
+			if _, err := io.WriteString(c.Writer, ": keep-alive\n\n"); err != nil {
+				return
+			}
+			c.Writer.Flush()
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package api
+
+import (
+	"errors"
+
+	"github.com/nuumz/f1ow/internal/engine"
+	"github.com/nuumz/f1ow/internal/storage"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// evaluateRequest is the body of POST /expressions/evaluate. The expression
+// is evaluated against Payload or, with ExecutionID, against the execution:
+// {"nodes": outputs by node ID, "input": ..., "vars": ...}, or only the
+// output of NodeID when it is set.
+type evaluateRequest struct {
+	Expression  string                 `json:"expression" binding:"required"`
+	Payload     map[string]interface{} `json:"payload"`
+	ExecutionID string                 `json:"execution_id"`
+	NodeID      string                 `json:"node_id"`
+}
+
+// EvaluateExpression previews an expression against a sample payload or a
+// previous execution. Malformed expressions are reported with status 200
+// and an error, like invalid node configs.
+func EvaluateExpression(eng *engine.Engine, db storage.ExecutionRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req evaluateRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(400, gin.H{"error": err.Error()})
+			return
+		}
+
+		payload := req.Payload
+		if req.ExecutionID != "" {
+			var ok bool
+			if payload, ok = executionPayload(c, eng, db, req.ExecutionID, req.NodeID); !ok {
+				return
+			}
+		}
+
+		c.JSON(200, engine.EvaluateExpression(req.Expression, payload))
+	}
+}
+
+// executionPayload returns what expressions are evaluated against for an
+// execution, or for one of its nodes' output, writing the error response
+// when it cannot be loaded
+func executionPayload(c *gin.Context, eng *engine.Engine, db storage.ExecutionRepository, executionID, nodeID string) (map[string]interface{}, bool) {
+	id, err := uuid.Parse(executionID)
+	if err != nil {
+		c.JSON(400, gin.H{"error": "invalid execution ID"})
+		return nil, false
+	}
+	execution, err := db.GetExecution(c.Request.Context(), id)
+	if errors.Is(err, storage.ErrExecutionNotFound) {
+		c.JSON(404, gin.H{"error": err.Error()})
+		return nil, false
+	}
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return nil, false
+	}
+	if !loadNodeOutputs(c, eng, execution) {
+		return nil, false
+	}
+
+	if nodeID != "" {
+		run, ok := execution.Context.NodeExecutions[nodeID]
+		if !ok {
+			c.JSON(404, gin.H{"error": "node did not run in the execution"})
+			return nil, false
+		}
+		return run.Output, true
+	}
+	nodes := make(map[string]interface{}, len(execution.Context.NodeExecutions))
+	for id, run := range execution.Context.NodeExecutions {
+		nodes[id] = run.Output
+	}
+	return map[string]interface{}{
+		"nodes": nodes,
+		"input": execution.Input,
+		"vars":  execution.Context.Variables,
+	}, true
+}
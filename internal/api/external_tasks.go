@@ -0,0 +1,208 @@
+package api
+
+import (
+	"errors"
+	"time"
+
+	"github.com/nuumz/f1ow/internal/storage"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+const (
+	maxExternalTasksPerFetch   = 100
+	defaultExternalTaskLock    = time.Minute
+	maxExternalTaskWait        = 30 * time.Second
+	externalTaskFetchPollDelay = 250 * time.Millisecond
+)
+
+// fetchAndLockRequest is the body of POST /external-tasks/fetch-and-lock
+type fetchAndLockRequest struct {
+	WorkerID       string   `json:"worker_id" binding:"required"`
+	Topics         []string `json:"topics" binding:"required"`
+	MaxTasks       int      `json:"max_tasks,omitempty"`        // 1 when unset, at most 100
+	LockDurationMs int      `json:"lock_duration_ms,omitempty"` // one minute when unset
+	WaitMs         int      `json:"wait_ms,omitempty"`          // long-polls for tasks up to this long, at most 30s
+}
+
+// completeTaskRequest is the body of POST /external-tasks/:id/complete
+type completeTaskRequest struct {
+	WorkerID string                 `json:"worker_id" binding:"required"`
+	Result   map[string]interface{} `json:"result"`
+}
+
+// failTaskRequest is the body of POST /external-tasks/:id/failure
+type failTaskRequest struct {
+	WorkerID       string `json:"worker_id" binding:"required"`
+	ErrorMessage   string `json:"error_message"`
+	Retries        int    `json:"retries,omitempty"`          // above 0 makes the task available again
+	RetryTimeoutMs int    `json:"retry_timeout_ms,omitempty"` // delay before a retry
+}
+
+// extendLockRequest is the body of POST /external-tasks/:id/extend-lock
+type extendLockRequest struct {
+	WorkerID       string `json:"worker_id" binding:"required"`
+	LockDurationMs int    `json:"lock_duration_ms" binding:"required"` // new lock expiry, from now
+}
+
+// FetchAndLockExternalTasks locks available tasks of the topics for the
+// worker and returns them, waiting up to wait_ms for some to arrive
+func FetchAndLockExternalTasks(db *storage.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req fetchAndLockRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(400, gin.H{"error": err.Error()})
+			return
+		}
+		if len(req.Topics) == 0 {
+			c.JSON(400, gin.H{"error": "topics is required"})
+			return
+		}
+		if req.MaxTasks <= 0 {
+			req.MaxTasks = 1
+		}
+		if req.MaxTasks > maxExternalTasksPerFetch {
+			req.MaxTasks = maxExternalTasksPerFetch
+		}
+		lock := defaultExternalTaskLock
+		if req.LockDurationMs > 0 {
+			lock = time.Duration(req.LockDurationMs) * time.Millisecond
+		}
+		wait := time.Duration(req.WaitMs) * time.Millisecond
+		if wait > maxExternalTaskWait {
+			wait = maxExternalTaskWait
+		}
+
+		ctx := c.Request.Context()
+		deadline := time.Now().Add(wait)
+		for {
+			tasks, err := db.LockExternalTasks(ctx, req.WorkerID, req.Topics, req.MaxTasks, lock)
+			if err != nil {
+				c.JSON(500, gin.H{"error": err.Error()})
+				return
+			}
+			if len(tasks) > 0 || !time.Now().Before(deadline) {
+				c.JSON(200, tasks)
+				return
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(externalTaskFetchPollDelay):
+			}
+		}
+	}
+}
+
+// GetExternalTask returns an external task
+func GetExternalTask(db *storage.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, ok := externalTaskParam(c)
+		if !ok {
+			return
+		}
+
+		task, err := db.GetExternalTask(c.Request.Context(), id)
+		if err != nil {
+			externalTaskError(c, err)
+			return
+		}
+		c.JSON(200, task)
+	}
+}
+
+// CompleteExternalTask records the result of a task the worker has locked;
+// the waiting node outputs it
+func CompleteExternalTask(db *storage.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, ok := externalTaskParam(c)
+		if !ok {
+			return
+		}
+		var req completeTaskRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(400, gin.H{"error": err.Error()})
+			return
+		}
+
+		if err := db.CompleteExternalTask(c.Request.Context(), id, req.WorkerID, req.Result); err != nil {
+			externalTaskError(c, err)
+			return
+		}
+		c.JSON(200, gin.H{"message": "external task completed"})
+	}
+}
+
+// ReportExternalTaskFailure records a failed attempt at a task the worker
+// has locked. With retries left the task is fetched again after the retry
+// timeout; otherwise the waiting node fails.
+func ReportExternalTaskFailure(db *storage.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, ok := externalTaskParam(c)
+		if !ok {
+			return
+		}
+		var req failTaskRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(400, gin.H{"error": err.Error()})
+			return
+		}
+
+		var retryAt *time.Time
+		if req.Retries > 0 {
+			at := time.Now().Add(time.Duration(req.RetryTimeoutMs) * time.Millisecond)
+			retryAt = &at
+		}
+		if err := db.FailExternalTask(c.Request.Context(), id, req.WorkerID, req.ErrorMessage, retryAt); err != nil {
+			externalTaskError(c, err)
+			return
+		}
+		c.JSON(200, gin.H{"message": "external task failure recorded"})
+	}
+}
+
+// ExtendExternalTaskLock keeps a task locked for the worker for longer
+func ExtendExternalTaskLock(db *storage.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, ok := externalTaskParam(c)
+		if !ok {
+			return
+		}
+		var req extendLockRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(400, gin.H{"error": err.Error()})
+			return
+		}
+
+		until := time.Now().Add(time.Duration(req.LockDurationMs) * time.Millisecond)
+		if err := db.ExtendExternalTaskLock(c.Request.Context(), id, req.WorkerID, until); err != nil {
+			externalTaskError(c, err)
+			return
+		}
+		c.JSON(200, gin.H{"message": "external task lock extended"})
+	}
+}
+
+func externalTaskParam(c *gin.Context) (uuid.UUID, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(400, gin.H{"error": "invalid external task ID"})
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+// externalTaskError responds 404 for unknown tasks, 409 for tasks the
+// worker does not hold, and 500 otherwise
+func externalTaskError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, storage.ErrExternalTaskNotFound):
+		c.JSON(404, gin.H{"error": err.Error()})
+	case errors.Is(err, storage.ErrExternalTaskNotLocked):
+		c.JSON(409, gin.H{"error": err.Error()})
+	default:
+		c.JSON(500, gin.H{"error": err.Error()})
+	}
+}
@@ -0,0 +1,148 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/nuumz/f1ow/internal/engine"
+	"github.com/nuumz/f1ow/internal/storage"
+
+	"github.com/gin-gonic/gin"
+)
+
+// HealthConfig configures the readiness checks
+type HealthConfig struct {
+	// Timeout bounds each dependency check; 2s when zero
+	Timeout time.Duration
+	// MaxQueueLag fails readiness when the oldest queued job has waited
+	// longer. Zero only reports the lag.
+	MaxQueueLag time.Duration
+}
+
+// dependencyCheck is the result of checking one dependency
+type dependencyCheck struct {
+	Status    string      `json:"status"` // "ok" or "fail"
+	LatencyMS float64     `json:"latency_ms"`
+	Error     string      `json:"error,omitempty"`
+	Detail    interface{} `json:"detail,omitempty"`
+}
+
+// readinessResponse reports whether the server can serve traffic and the
+// state of each dependency
+type readinessResponse struct {
+	Status string                     `json:"status"` // "ready" or "not_ready"
+	Checks map[string]dependencyCheck `json:"checks"`
+}
+
+// Liveness reports that the process is up and serving requests. It checks
+// no dependencies, so an outage of one does not get the server restarted.
+func Liveness() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.JSON(200, gin.H{"status": "ok"})
+	}
+}
+
+// Readiness checks the database, its migrations, Redis, and the queue in
+// parallel and responds 503 when any of them fails, so load balancers stop
+// routing to a server that cannot handle requests
+func Readiness(db *storage.DB, redis *storage.RedisClient, eng *engine.Engine, config HealthConfig) gin.HandlerFunc {
+	if config.Timeout <= 0 {
+		config.Timeout = 2 * time.Second
+	}
+
+	checks := map[string]func(ctx context.Context) (interface{}, error){}
+	if db != nil {
+		checks["database"] = func(ctx context.Context) (interface{}, error) {
+			return nil, db.PingContext(ctx)
+		}
+		checks["migrations"] = func(ctx context.Context) (interface{}, error) {
+			return checkMigrations(ctx, db)
+		}
+	}
+	if redis != nil {
+		checks["redis"] = func(ctx context.Context) (interface{}, error) {
+			return nil, redis.Client().Ping(ctx).Err()
+		}
+	}
+	if eng != nil {
+		checks["queue"] = func(ctx context.Context) (interface{}, error) {
+			return checkQueue(ctx, eng, config.MaxQueueLag)
+		}
+	}
+
+	return func(c *gin.Context) {
+		response := readinessResponse{Status: "ready", Checks: make(map[string]dependencyCheck, len(checks))}
+		var mu sync.Mutex
+		var wg sync.WaitGroup
+		for name, check := range checks {
+			wg.Add(1)
+			go func(name string, check func(ctx context.Context) (interface{}, error)) {
+				defer wg.Done()
+				ctx, cancel := context.WithTimeout(c.Request.Context(), config.Timeout)
+				defer cancel()
+
+				started := time.Now()
+				detail, err := check(ctx)
+				result := dependencyCheck{
+					Status:    "ok",
+					LatencyMS: float64(time.Since(started).Microseconds()) / 1000,
+					Detail:    detail,
+				}
+				if err != nil {
+					result.Status, result.Error = "fail", err.Error()
+				}
+
+				mu.Lock()
+				defer mu.Unlock()
+				response.Checks[name] = result
+				if err != nil {
+					response.Status = "not_ready"
+				}
+			}(name, check)
+		}
+		wg.Wait()
+
+		status := 200
+		if response.Status != "ready" {
+			status = 503
+		}
+		c.JSON(status, response)
+	}
+}
+
+// checkMigrations fails when embedded migrations have not been applied
+func checkMigrations(ctx context.Context, db *storage.DB) (interface{}, error) {
+	statuses, err := db.MigrationStatus(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var latest, pending int
+	for _, status := range statuses {
+		if status.AppliedAt == nil {
+			pending++
+		} else if status.Version > latest {
+			latest = status.Version
+		}
+	}
+	detail := gin.H{"version": latest, "pending": pending}
+	if pending > 0 {
+		return detail, fmt.Errorf("%d migrations not applied", pending)
+	}
+	return detail, nil
+}
+
+// checkQueue reports the backlog and fails when its oldest job has waited
+// longer than maxLag, if set
+func checkQueue(ctx context.Context, eng *engine.Engine, maxLag time.Duration) (interface{}, error) {
+	stats, err := eng.QueueStats(ctx)
+	if err != nil {
+		return nil, err
+	}
+	detail := gin.H{"pending": stats.Pending, "oldest_job_age_seconds": stats.OldestJobAge, "workers": stats.Workers}
+	if maxLag > 0 && stats.OldestJobAge > maxLag.Seconds() {
+		return detail, fmt.Errorf("oldest job has waited %.0fs, more than %s", stats.OldestJobAge, maxLag)
+	}
+	return detail, nil
+}
@@ -0,0 +1,63 @@
+package api
+
+import (
+	"strconv"
+
+	"github.com/nuumz/f1ow/internal/engine"
+	"github.com/nuumz/f1ow/internal/models"
+	"github.com/nuumz/f1ow/internal/storage"
+
+	"github.com/gin-gonic/gin"
+)
+
+// lintResponse is the response of POST /workflows/:id/lint. Valid is false
+// when an issue has error severity, which would block saving the workflow.
+type lintResponse struct {
+	Valid  bool               `json:"valid"`
+	Issues []engine.LintIssue `json:"issues"`
+}
+
+// lintErrorResponse rejects a workflow with lint errors
+type lintErrorResponse struct {
+	Error  string             `json:"error"`
+	Issues []engine.LintIssue `json:"issues"`
+}
+
+// hasLintErrors reports whether any issue has error severity
+func hasLintErrors(issues []engine.LintIssue) bool {
+	for _, issue := range issues {
+		if issue.Severity == engine.LintError {
+			return true
+		}
+	}
+	return false
+}
+
+// lintWorkflow rejects workflows with lint errors, writing every issue as a
+// 422 response. Warnings and infos do not block; their count is set in the
+// X-Lint-Issues header.
+func lintWorkflow(c *gin.Context, eng *engine.Engine, workflow *models.Workflow) bool {
+	issues := eng.LintWorkflow(workflow, "")
+	if hasLintErrors(issues) {
+		c.JSON(422, lintErrorResponse{Error: "workflow has lint errors", Issues: issues})
+		return false
+	}
+	if len(issues) > 0 {
+		c.Header("X-Lint-Issues", strconv.Itoa(len(issues)))
+	}
+	return true
+}
+
+// LintWorkflow runs the lint rules on a saved workflow as it would run in
+// the environment query parameter, or the engine's environment
+func LintWorkflow(eng *engine.Engine, db storage.WorkflowRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		workflow, ok := workflowParam(c, db)
+		if !ok {
+			return
+		}
+
+		issues := eng.LintWorkflow(workflow, c.Query("environment"))
+		c.JSON(200, lintResponse{Valid: !hasLintErrors(issues), Issues: issues})
+	}
+}
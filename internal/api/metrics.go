@@ -0,0 +1,26 @@
+package api
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/nuumz/f1ow/internal/engine"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RecordMetrics records the duration and count of every request by method,
+// route pattern, and status. Requests that match no route are recorded
+// under "unmatched" so scanners cannot inflate the label set.
+func RecordMetrics(metrics *engine.Metrics) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		started := time.Now()
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		metrics.RecordAPIRequest(c.Request.Method, route, strconv.Itoa(c.Writer.Status()), time.Since(started))
+	}
+}
@@ -0,0 +1,84 @@
+package api
+
+import (
+	"errors"
+	"time"
+
+	"github.com/nuumz/f1ow/internal/engine"
+	"github.com/nuumz/f1ow/internal/models"
+	"github.com/nuumz/f1ow/internal/storage"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// nodeContextResponse is what a node saw when it last ran in an execution
+type nodeContextResponse struct {
+	ExecutionID string                   `json:"execution_id"`
+	NodeID      string                   `json:"node_id"`
+	Status      models.ExecutionStatus   `json:"status"`
+	StartedAt   time.Time                `json:"started_at"`
+	Input       map[string]interface{}   `json:"input"`
+	Items       []map[string]interface{} `json:"items,omitempty"` // in item mode, the input of each item's run
+	Variables   map[string]interface{}   `json:"variables"`
+	// NodeOutputs holds the outputs of the nodes that had run before it, by
+	// node ID, as its input held them under nodeOutputs
+	NodeOutputs map[string]interface{} `json:"node_outputs"`
+}
+
+// GetNodeContext returns the input and variables a node of an execution
+// ran with, and the outputs of the nodes before it. Values of secret
+// variables are redacted.
+func GetNodeContext(eng *engine.Engine, db storage.ExecutionRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			c.JSON(400, gin.H{"error": "invalid execution ID"})
+			return
+		}
+
+		execution, err := db.GetExecution(c.Request.Context(), id)
+		if errors.Is(err, storage.ErrExecutionNotFound) {
+			c.JSON(404, gin.H{"error": err.Error()})
+			return
+		}
+		if err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+
+		nodeID := c.Param("node_id")
+		snapshot, err := db.GetNodeSnapshot(c.Request.Context(), id, nodeID)
+		if errors.Is(err, storage.ErrNodeSnapshotNotFound) {
+			c.JSON(404, gin.H{"error": err.Error()})
+			return
+		}
+		if err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+
+		response := nodeContextResponse{
+			ExecutionID: id.String(),
+			NodeID:      nodeID,
+			Input:       snapshot.Input,
+			Items:       snapshot.Items,
+			Variables:   snapshot.Variables,
+			NodeOutputs: make(map[string]interface{}, len(snapshot.Upstream)),
+		}
+		if run, ok := execution.Context.NodeExecutions[nodeID]; ok {
+			response.Status, response.StartedAt = run.Status, run.StartedAt
+		}
+		for _, upstream := range snapshot.Upstream {
+			run, ok := execution.Context.NodeExecutions[upstream]
+			if !ok {
+				continue
+			}
+			if response.NodeOutputs[upstream], ok = loadNodeOutput(c, eng, run.Output); !ok {
+				return
+			}
+		}
+
+		c.JSON(200, response)
+	}
+}
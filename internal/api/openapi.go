@@ -0,0 +1,582 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nuumz/f1ow/internal/diff"
+	"github.com/nuumz/f1ow/internal/engine"
+	"github.com/nuumz/f1ow/internal/models"
+	"github.com/nuumz/f1ow/internal/openapi"
+
+	"github.com/gin-gonic/gin"
+)
+
+// routeDoc describes a route in the OpenAPI spec
+type routeDoc struct {
+	ID       string // operation ID, also the generated client method name
+	Summary  string
+	Query    []queryParam
+	Body     interface{} // request body type, nil for none
+	Response interface{} // response body type, nil for any JSON
+	Status   int         // success status, 200 when zero
+	Content  string      // non-JSON response content type
+	Headers  map[string]string
+	Public   bool // authenticates without a token or API key
+}
+
+// queryParam describes a query parameter; Type is a zero value of its Go type
+type queryParam struct {
+	Name        string
+	Type        interface{}
+	Description string
+}
+
+// Response bodies built with gin.H, described for the spec
+type (
+	errorResponse struct {
+		Error string `json:"error"`
+	}
+	messageResponse struct {
+		Message string `json:"message"`
+	}
+	jobResponse struct {
+		JobID string `json:"job_id"`
+	}
+	healthResponse struct {
+		Status   string          `json:"status"`
+		Services map[string]bool `json:"services"`
+	}
+	nodeInfo struct {
+		Type        string `json:"type"`
+		Name        string `json:"name"`
+		Description string `json:"description"`
+		Category    string `json:"category"`
+		Icon        string `json:"icon"`
+		Version     int    `json:"version"`  // latest
+		Versions    []int  `json:"versions"` // registered, in order
+		// Deprecated holds the migration notes of deprecated versions,
+		// keyed by version
+		Deprecated map[string]string `json:"deprecated,omitempty"`
+	}
+	nodeListResponse struct {
+		Nodes []nodeInfo `json:"nodes"`
+	}
+)
+
+// routeDocs documents the routes registered by SetupRoutes, keyed by
+// "METHOD path". Undocumented routes still appear in the spec.
+var routeDocs = map[string]routeDoc{
+	"GET /health":  {ID: "Health", Summary: "Report service health", Response: healthResponse{}, Public: true},
+	"GET /healthz": {ID: "Liveness", Summary: "Report that the process is up", Response: map[string]string{}, Public: true},
+	"GET /readyz": {
+		ID: "Readiness", Summary: "Check the database, migrations, Redis, and queue; 503 when not ready",
+		Response: readinessResponse{}, Public: true,
+	},
+
+	"GET /api/v1/workflows": {
+		ID: "ListWorkflows", Summary: "List workflows", Response: []models.Workflow{},
+		Query: []queryParam{
+			{"limit", 0, "Maximum number of workflows, up to 1000"},
+			{"offset", 0, "Number of workflows to skip"},
+			{"q", "", "Case-insensitive search in the name"},
+			{"tag", []string{}, "Only workflows with all these tags"},
+			{"sort", "", "Sort field: name, created_at, or updated_at"},
+			{"order", "", "Sort order: asc or desc"},
+			{"fields", "", "summary omits definitions"},
+			{"project_id", "", "Only workflows in this project"},
+			{"status", []string{}, "Only workflows with these statuses; draft and active by default"},
+		},
+		Headers: map[string]string{
+			"X-Total-Count": "Total number of matching workflows",
+			"X-Limit":       "Applied limit",
+			"X-Offset":      "Applied offset",
+		},
+	},
+	"POST /api/v1/workflows": {ID: "CreateWorkflow", Summary: "Create a workflow", Body: models.Workflow{}, Response: models.Workflow{}, Status: 201},
+	"POST /api/v1/workflows/sync": {
+		ID: "SyncWorkflows", Summary: "Create, update, and deactivate a source's workflows to match the given definitions",
+		Body: syncRequest{}, Response: syncResponse{},
+	},
+	"GET /api/v1/workflows/:id":    {ID: "GetWorkflow", Summary: "Get a workflow", Response: models.Workflow{}},
+	"PUT /api/v1/workflows/:id":    {ID: "UpdateWorkflow", Summary: "Update a workflow", Body: models.Workflow{}, Response: models.Workflow{}},
+	"DELETE /api/v1/workflows/:id": {ID: "DeleteWorkflow", Summary: "Delete a workflow", Response: messageResponse{}},
+	"POST /api/v1/workflows/:id/activate": {
+		ID: "ActivateWorkflow", Summary: "Activate a workflow so its triggers and webhooks run", Response: models.Workflow{},
+	},
+	"POST /api/v1/workflows/:id/deactivate": {ID: "DeactivateWorkflow", Summary: "Return a workflow to draft", Response: models.Workflow{}},
+	"POST /api/v1/workflows/:id/archive":    {ID: "ArchiveWorkflow", Summary: "Archive a workflow", Response: models.Workflow{}},
+	"GET /api/v1/workflows/:id/stats": {
+		ID: "GetWorkflowStats", Summary: "Get execution statistics for a workflow", Response: models.WorkflowStats{},
+		Query: []queryParam{
+			{"window", "", "Time window such as 24h or 7d"},
+			{"refresh", false, "Bypass the cache"},
+		},
+	},
+	"GET /api/v1/workflows/:id/plan": {
+		ID: "GetWorkflowPlan", Summary: "Get the execution order, parallel groups, cycles, and estimated critical path of a workflow",
+		Response: engine.ExecutionPlan{},
+		Query:    []queryParam{{"window", "", "Time window of the node durations the critical path is estimated from, such as 24h or 7d"}},
+	},
+	"GET /api/v1/workflows/:id/schedules": {ID: "ListWorkflowSchedules", Summary: "List a workflow's cron schedules", Response: []models.Schedule{}},
+	"POST /api/v1/workflows/:id/lint": {
+		ID: "LintWorkflow", Summary: "Check a workflow against the lint rules and report issues with severities and fix hints",
+		Response: lintResponse{},
+		Query:    []queryParam{{"environment", "", "Lint the workflow as it would run in this environment instead of the server's"}},
+	},
+	"GET /api/v1/workflows/:id/state/:key": {
+		ID: "GetWorkflowState", Summary: "Get a value the workflow keeps between executions", Response: models.WorkflowState{},
+	},
+	"PUT /api/v1/workflows/:id/state/:key": {
+		ID: "SetWorkflowState", Summary: "Set a value the workflow keeps between executions",
+		Body: stateRequest{}, Response: models.WorkflowState{},
+	},
+	"DELETE /api/v1/workflows/:id/state/:key": {
+		ID: "DeleteWorkflowState", Summary: "Remove a value the workflow keeps between executions", Response: messageResponse{},
+	},
+	"POST /api/v1/workflows/:id/execute": {
+		ID: "ExecuteWorkflow", Summary: "Queue a workflow execution for the workers, or run it inline when the server is configured to",
+		Body: map[string]interface{}{}, Response: models.Execution{}, Status: 202,
+		Query: []queryParam{
+			{"environment", "", "Run the version deployed to this environment"},
+			{"pinned_data", "", "true to use the pinned data of nodes that have it instead of running them"},
+		},
+		Headers: map[string]string{
+			"Idempotent-Replayed": "true when an Idempotency-Key request header repeated an earlier request and the original execution is returned",
+		},
+	},
+	"POST /api/v1/workflows/:id/execute/batch": {
+		ID: "ExecuteWorkflowBatch", Summary: "Queue one workflow execution per input, grouped in a batch",
+		Body: batchRequest{}, Response: batchResponse{}, Status: 202,
+		Query: []queryParam{
+			{"environment", "", "Run the version deployed to this environment"},
+			{"pinned_data", "", "true to use the pinned data of nodes that have it instead of running them"},
+		},
+	},
+	"GET /api/v1/batches/:id": {
+		ID: "GetExecutionBatch", Summary: "Get a batch of executions with their counts by status", Response: models.ExecutionBatch{},
+	},
+	"POST /api/v1/external-tasks/fetch-and-lock": {
+		ID: "FetchAndLockExternalTasks", Summary: "Lock available external tasks of the topics for a worker, long-polling up to wait_ms",
+		Body: fetchAndLockRequest{}, Response: []models.ExternalTask{},
+	},
+	"GET /api/v1/external-tasks/:id": {ID: "GetExternalTask", Summary: "Get an external task", Response: models.ExternalTask{}},
+	"POST /api/v1/external-tasks/:id/complete": {
+		ID: "CompleteExternalTask", Summary: "Complete an external task the worker has locked",
+		Body: completeTaskRequest{}, Response: messageResponse{},
+	},
+	"POST /api/v1/external-tasks/:id/failure": {
+		ID: "ReportExternalTaskFailure", Summary: "Report a failed attempt at an external task, retrying it while retries are left",
+		Body: failTaskRequest{}, Response: messageResponse{},
+	},
+	"POST /api/v1/external-tasks/:id/extend-lock": {
+		ID: "ExtendExternalTaskLock", Summary: "Keep an external task locked for the worker for longer",
+		Body: extendLockRequest{}, Response: messageResponse{},
+	},
+	"POST /api/v1/workflows/:id/debug": {
+		ID: "DebugWorkflow", Summary: "Start a debug execution that pauses before each node",
+		Body: map[string]interface{}{}, Response: models.Execution{}, Status: 202,
+		Query: []queryParam{{"pinned_data", "", "true to use the pinned data of nodes that have it instead of running them"}},
+	},
+	"POST /api/v1/workflows/:id/duplicate": {
+		ID: "DuplicateWorkflow", Summary: "Copy a workflow with new node and edge IDs", Body: duplicateRequest{}, Response: models.Workflow{}, Status: 201,
+	},
+	"GET /api/v1/workflows/:id/versions":          {ID: "ListWorkflowVersions", Summary: "List a workflow's saved versions, newest first", Response: []models.WorkflowVersion{}},
+	"GET /api/v1/workflows/:id/versions/:version": {ID: "GetWorkflowVersion", Summary: "Get a saved workflow version", Response: models.WorkflowVersion{}},
+	"GET /api/v1/workflows/:id/diff": {
+		ID: "DiffWorkflowVersions", Summary: "Compare two saved versions of a workflow", Response: versionDiff{},
+		Query: []queryParam{
+			{"from", 0, "Version to compare from"},
+			{"to", 0, "Version to compare to; defaults to the current version"},
+		},
+	},
+	"GET /api/v1/workflows/:id/deployments": {ID: "ListDeployments", Summary: "List the environments a workflow is deployed to", Response: []models.Deployment{}},
+	"PUT /api/v1/workflows/:id/deployments/:environment": {
+		ID: "DeployWorkflow", Summary: "Deploy a workflow version to an environment", Body: deployRequest{}, Response: models.Deployment{},
+	},
+	"DELETE /api/v1/workflows/:id/deployments/:environment": {ID: "UndeployWorkflow", Summary: "Remove a workflow from an environment", Response: messageResponse{}},
+	"POST /api/v1/workflows/:id/promote": {
+		ID: "PromoteWorkflow", Summary: "Promote a workflow version from one environment to another", Body: promoteRequest{}, Response: promoteResponse{},
+	},
+	"PUT /api/v1/workflows/:id/nodes/:node/pinned-data": {
+		ID: "SetPinnedData", Summary: "Pin sample output data to a node", Body: pinnedDataRequest{}, Response: models.Workflow{},
+	},
+	"DELETE /api/v1/workflows/:id/nodes/:node/pinned-data": {ID: "DeletePinnedData", Summary: "Remove a node's pinned data", Response: models.Workflow{}},
+	"POST /api/v1/workflows/:id/nodes/:node/pinned-data/capture": {
+		ID: "CapturePinnedData", Summary: "Pin a node's output from a previous execution", Body: captureRequest{}, Response: models.Workflow{},
+	},
+
+	"GET /api/v1/projects": {
+		ID: "ListProjects", Summary: "List projects", Response: []models.Project{},
+		Query: []queryParam{{"parent_id", "", "Only sub-projects of this project"}},
+	},
+	"POST /api/v1/projects":       {ID: "CreateProject", Summary: "Create a project", Body: models.Project{}, Response: models.Project{}, Status: 201},
+	"GET /api/v1/projects/:id":    {ID: "GetProject", Summary: "Get a project", Response: models.Project{}},
+	"PUT /api/v1/projects/:id":    {ID: "UpdateProject", Summary: "Update a project", Body: models.Project{}, Response: models.Project{}},
+	"DELETE /api/v1/projects/:id": {ID: "DeleteProject", Summary: "Delete an empty project", Response: messageResponse{}},
+
+	"GET /api/v1/executions": {
+		ID: "ListExecutions", Summary: "List executions, newest first", Response: []models.Execution{},
+		Query: []queryParam{
+			{"workflow_id", "", "Only executions of this workflow"},
+			{"status", []string{}, "Only executions with these statuses"},
+			{"started_after", time.Time{}, "Only executions started at or after this time"},
+			{"started_before", time.Time{}, "Only executions started before this time"},
+			{"cursor", "", "X-Next-Cursor of the previous page"},
+			{"limit", 0, "Maximum number of executions, up to 1000"},
+			{"fields", "", "summary omits input, output, and context"},
+		},
+		Headers: map[string]string{
+			"X-Total-Count": "Total number of matching executions",
+			"X-Next-Cursor": "Cursor of the next page, absent on the last page",
+		},
+	},
+	"GET /api/v1/executions/compare": {
+		ID: "CompareExecutions", Summary: "Diff two executions of a workflow node by node: status, duration, and output",
+		Response: diff.ExecutionDiff{},
+		Query: []queryParam{
+			{"a", "", "ID of the execution to compare from, such as the last successful one"},
+			{"b", "", "ID of the execution to compare to"},
+		},
+	},
+	"GET /api/v1/executions/:id":               {ID: "GetExecution", Summary: "Get an execution", Response: models.Execution{}},
+	"GET /api/v1/executions/:id/outputs/:node": {ID: "GetExecutionNodeOutput", Summary: "Get a node's output with offloaded payloads loaded"},
+	"GET /api/v1/executions/:id/nodes/:node_id/context": {
+		ID: "GetNodeContext", Summary: "Get the input, variables, and upstream outputs a node ran with, secrets redacted",
+		Response: nodeContextResponse{},
+	},
+	"GET /api/v1/executions/:id/events": {
+		ID: "StreamExecutionEvents", Summary: "Stream an execution's events as Server-Sent Events, resuming after Last-Event-ID", Content: "text/event-stream",
+		Query: []queryParam{{"last_event_id", "", "Resume after this event, for clients that cannot set the Last-Event-ID header"}},
+	},
+	"POST /api/v1/expressions/evaluate": {
+		ID: "EvaluateExpression", Summary: "Preview an expression against a sample payload or a previous execution",
+		Body: evaluateRequest{}, Response: engine.ExpressionResult{},
+	},
+	"GET /api/v1/executions/:id/debug": {ID: "GetDebugState", Summary: "Get the node a debug execution is paused at and its input", Response: engine.DebugState{}},
+	"POST /api/v1/executions/:id/debug": {
+		ID: "SendDebugCommand", Summary: "Continue, skip, or modify the input of the paused node", Body: engine.DebugCommand{}, Response: messageResponse{},
+	},
+	"GET /api/v1/executions/:id/debug/ws": {ID: "DebugWebSocket", Summary: "WebSocket streaming debug events and accepting debug commands"},
+	"GET /api/v1/usage": {
+		ID: "GetUsage", Summary: "Total the resources used by finished executions, per tenant or workflow", Response: models.UsageReport{},
+		Query: []queryParam{
+			{"from", "", "Start of the period, RFC 3339; the start of this month by default"},
+			{"to", "", "End of the period, RFC 3339; now by default"},
+			{"group_by", "", "tenant or workflow"},
+		},
+	},
+	"GET /api/v1/limits": {ID: "GetLimits", Summary: "Get the caller's tenant quotas and how much of them it uses", Response: models.Limits{}},
+
+	"GET /api/v1/binary/:id": {ID: "DownloadBinaryData", Summary: "Download binary data", Content: "application/octet-stream"},
+
+	"GET /api/v1/credentials":        {ID: "ListCredentials", Summary: "List credentials without their secrets", Response: []models.Credential{}},
+	"POST /api/v1/credentials":       {ID: "CreateCredential", Summary: "Create a credential", Body: createCredentialRequest{}, Response: models.Credential{}, Status: 201},
+	"DELETE /api/v1/credentials/:id": {ID: "DeleteCredential", Summary: "Delete a credential", Response: messageResponse{}},
+	"GET /api/v1/oauth2/authorize": {
+		ID: "AuthorizeOAuth2", Summary: "Redirect to the provider to authorize an OAuth2 credential", Status: 302,
+		Query: []queryParam{{"credential_id", "", "Credential to authorize"}},
+	},
+
+	"GET /api/v1/environments":          {ID: "ListEnvironments", Summary: "List environments", Response: []models.Environment{}},
+	"POST /api/v1/environments":         {ID: "CreateEnvironment", Summary: "Create an environment", Body: models.Environment{}, Response: models.Environment{}, Status: 201},
+	"DELETE /api/v1/environments/:name": {ID: "DeleteEnvironment", Summary: "Delete an environment and its deployments", Response: messageResponse{}},
+
+	"GET /api/v1/variables": {
+		ID: "ListVariables", Summary: "List variables without secret values", Response: []models.Variable{},
+		Query: []queryParam{{"environment", "", "Only variables of this environment; empty for global variables"}},
+	},
+	"POST /api/v1/variables":       {ID: "CreateVariable", Summary: "Create a global or environment variable", Body: variableRequest{}, Response: models.Variable{}, Status: 201},
+	"GET /api/v1/variables/:id":    {ID: "GetVariable", Summary: "Get a variable", Response: models.Variable{}},
+	"PUT /api/v1/variables/:id":    {ID: "UpdateVariable", Summary: "Update a variable", Body: variableRequest{}, Response: models.Variable{}},
+	"DELETE /api/v1/variables/:id": {ID: "DeleteVariable", Summary: "Delete a variable", Response: messageResponse{}},
+
+	"GET /api/v1/alerts": {
+		ID: "ListAlerts", Summary: "List fired alerts, most recent first", Response: []models.Alert{},
+		Query: []queryParam{{"rule_id", "", "Only alerts of this rule"}, {"limit", 0, "Maximum number of alerts, up to 500; 100 when omitted"}},
+	},
+	"GET /api/v1/alerts/rules":           {ID: "ListAlertRules", Summary: "List alert rules", Response: []models.AlertRule{}},
+	"POST /api/v1/alerts/rules":          {ID: "CreateAlertRule", Summary: "Create an alert rule", Body: alertRuleRequest{}, Response: models.AlertRule{}, Status: 201},
+	"GET /api/v1/alerts/rules/:id":       {ID: "GetAlertRule", Summary: "Get an alert rule", Response: models.AlertRule{}},
+	"PUT /api/v1/alerts/rules/:id":       {ID: "UpdateAlertRule", Summary: "Update an alert rule", Body: alertRuleRequest{}, Response: models.AlertRule{}},
+	"DELETE /api/v1/alerts/rules/:id":    {ID: "DeleteAlertRule", Summary: "Delete an alert rule and its alert history", Response: messageResponse{}},
+	"GET /api/v1/alerts/channels":        {ID: "ListAlertChannels", Summary: "List alert channels", Response: []models.AlertChannel{}},
+	"POST /api/v1/alerts/channels":       {ID: "CreateAlertChannel", Summary: "Create an email, Slack, webhook, or PagerDuty alert channel", Body: alertChannelRequest{}, Response: models.AlertChannel{}, Status: 201},
+	"GET /api/v1/alerts/channels/:id":    {ID: "GetAlertChannel", Summary: "Get an alert channel", Response: models.AlertChannel{}},
+	"PUT /api/v1/alerts/channels/:id":    {ID: "UpdateAlertChannel", Summary: "Update an alert channel", Body: alertChannelRequest{}, Response: models.AlertChannel{}},
+	"DELETE /api/v1/alerts/channels/:id": {ID: "DeleteAlertChannel", Summary: "Delete an alert channel", Response: messageResponse{}},
+
+	"GET /api/v1/event-subscriptions": {ID: "ListEventSubscriptions", Summary: "List event subscriptions without their secrets", Response: []models.EventSubscription{}},
+	"POST /api/v1/event-subscriptions": {
+		ID: "CreateEventSubscription", Summary: "Register a URL to call on events; the signing secret is only returned once",
+		Body: eventSubscriptionRequest{}, Response: models.EventSubscription{}, Status: 201,
+	},
+	"GET /api/v1/event-subscriptions/:id":    {ID: "GetEventSubscription", Summary: "Get an event subscription", Response: models.EventSubscription{}},
+	"PUT /api/v1/event-subscriptions/:id":    {ID: "UpdateEventSubscription", Summary: "Update an event subscription", Body: eventSubscriptionRequest{}, Response: models.EventSubscription{}},
+	"DELETE /api/v1/event-subscriptions/:id": {ID: "DeleteEventSubscription", Summary: "Delete an event subscription and its deliveries", Response: messageResponse{}},
+	"GET /api/v1/event-subscriptions/:id/deliveries": {
+		ID: "ListEventDeliveries", Summary: "List a subscription's most recent deliveries", Response: []models.EventDelivery{},
+		Query: []queryParam{{"limit", 0, "Maximum number of deliveries, up to 500; 50 when omitted"}},
+	},
+	"POST /api/v1/event-subscriptions/:id/deliveries/:delivery/redeliver": {
+		ID: "RedeliverEvent", Summary: "Send a delivery's payload again as a new delivery", Response: models.EventDelivery{}, Status: 201,
+	},
+
+	"GET /api/v1/schedules": {
+		ID: "ListSchedules", Summary: "List cron schedules", Response: []models.Schedule{},
+		Query: []queryParam{{"workflow_id", "", "Only the schedules of this workflow"}},
+	},
+	"POST /api/v1/schedules": {
+		ID: "CreateSchedule", Summary: "Schedule a workflow to run with an input on a cron expression",
+		Body: scheduleRequest{}, Response: models.Schedule{}, Status: 201,
+	},
+	"GET /api/v1/schedules/preview": {
+		ID: "PreviewSchedule", Summary: "List the next times a cron expression fires", Response: schedulePreview{},
+		Query: []queryParam{
+			{"cron_expr", "", "Cron expression, such as */15 9-17 * * MON-FRI or @daily"},
+			{"timezone", "", "IANA timezone the expression fires in; UTC when omitted"},
+			{"count", 0, "Number of times, up to 100; 5 when omitted"},
+		},
+	},
+	"GET /api/v1/schedules/:id":    {ID: "GetSchedule", Summary: "Get a schedule", Response: models.Schedule{}},
+	"PUT /api/v1/schedules/:id":    {ID: "UpdateSchedule", Summary: "Update a schedule", Body: scheduleRequest{}, Response: models.Schedule{}},
+	"DELETE /api/v1/schedules/:id": {ID: "DeleteSchedule", Summary: "Delete a schedule", Response: messageResponse{}},
+	"POST /api/v1/schedules/:id/pause": {
+		ID: "PauseSchedule", Summary: "Pause a schedule", Response: models.Schedule{},
+	},
+	"POST /api/v1/schedules/:id/resume": {
+		ID: "ResumeSchedule", Summary: "Resume a schedule from its next time, skipping the runs missed while paused", Response: models.Schedule{},
+	},
+	"POST /api/v1/schedules/:id/run-now": {
+		ID: "RunScheduleNow", Summary: "Queue an execution of a schedule's workflow with its input now", Response: models.Execution{}, Status: 202,
+	},
+
+	"GET /api/v1/nodes":              {ID: "ListNodes", Summary: "List available node types", Response: nodeListResponse{}},
+	"GET /api/v1/nodes/:type/schema": {ID: "GetNodeSchema", Summary: "Get the schema of a node type"},
+	"GET /api/v1/nodes/deprecations": {
+		ID: "ListDeprecatedNodeUsages", Summary: "List workflow nodes that run a deprecated node version",
+		Response: []engine.DeprecatedNodeUsage{},
+	},
+	"POST /api/v1/nodes/:type/validate": {
+		ID: "ValidateNodeConfig", Summary: "Validate a node config and report field-level errors",
+		Body: map[string]interface{}{}, Response: validationResponse{},
+	},
+
+	"GET /api/v1/api-keys":        {ID: "ListAPIKeys", Summary: "List the tenant's API keys", Response: []models.APIKey{}},
+	"POST /api/v1/api-keys":       {ID: "CreateAPIKey", Summary: "Create an API key; the key is only returned once", Body: createAPIKeyRequest{}, Response: createAPIKeyResponse{}, Status: 201},
+	"DELETE /api/v1/api-keys/:id": {ID: "RevokeAPIKey", Summary: "Revoke an API key", Response: messageResponse{}},
+
+	"GET /api/v1/tenants":  {ID: "ListTenants", Summary: "List tenants", Response: []models.Tenant{}},
+	"POST /api/v1/tenants": {ID: "CreateTenant", Summary: "Create a tenant", Body: models.Tenant{}, Response: models.Tenant{}, Status: 201},
+	"PUT /api/v1/tenants/:id/network-policy": {
+		ID: "UpdateTenantNetworkPolicy", Summary: "Replace the networks a tenant's nodes and webhooks may reach",
+		Body: models.NetworkPolicy{}, Response: models.NetworkPolicy{},
+	},
+	"PUT /api/v1/tenants/:id/quotas": {
+		ID: "UpdateTenantQuotas", Summary: "Put a tenant on a plan and override the plan's quotas",
+		Body: models.TenantQuotas{}, Response: models.TenantQuotas{},
+	},
+
+	"GET /api/v1/plans":          {ID: "ListPlans", Summary: "List quota plans", Response: []models.Plan{}},
+	"PUT /api/v1/plans/:name":    {ID: "SavePlan", Summary: "Create or replace a quota plan", Body: planRequest{}, Response: models.Plan{}},
+	"DELETE /api/v1/plans/:name": {ID: "DeletePlan", Summary: "Delete a quota plan no tenant is on", Response: messageResponse{}},
+
+	"GET /api/v1/workers":            {ID: "ListWorkers", Summary: "List live workers with their capabilities and load", Response: []engine.WorkerInfo{}},
+	"GET /api/v1/workers/:id":        {ID: "GetWorker", Summary: "Get a worker", Response: engine.WorkerInfo{}},
+	"POST /api/v1/workers/:id/drain": {ID: "DrainWorker", Summary: "Stop a worker taking jobs and exit once in-flight jobs finish", Response: messageResponse{}, Status: 202},
+	"POST /api/v1/workers/:id/stop":  {ID: "StopWorker", Summary: "Cancel a worker's in-flight jobs and exit", Response: messageResponse{}, Status: 202},
+	"GET /api/v1/queue/stats":        {ID: "GetQueueStats", Summary: "Get the queue backlog and worker load, for autoscaling workers", Response: engine.QueueStats{}},
+
+	"GET /api/v1/oauth2/callback": {ID: "OAuth2Callback", Summary: "Complete OAuth2 authorization", Content: "text/html", Public: true},
+	"POST /api/v1/webhooks/email/:id": {
+		ID: "ReceiveEmailWebhook", Summary: "Receive an inbound email for a workflow", Response: jobResponse{}, Status: 202, Public: true,
+		Query: []queryParam{{"token", "", "Webhook secret of an email_trigger node, when not sent in the X-F1ow-Token header"}},
+	},
+	"POST /api/v1/webhooks/github/:id": {ID: "ReceiveGitHubWebhook", Summary: "Receive a GitHub webhook for a workflow", Response: jobResponse{}, Status: 202, Public: true},
+	"POST /api/v1/webhooks/gitlab/:id": {ID: "ReceiveGitLabWebhook", Summary: "Receive a GitLab webhook for a workflow", Response: jobResponse{}, Status: 202, Public: true},
+}
+
+// openAPIExcluded lists routes left out of the spec
+var openAPIExcluded = map[string]bool{
+	"/ws":                  true, // not implemented
+	"/metrics":             true, // Prometheus text format
+	"/api/v1/openapi.json": true,
+	"/api/v1/docs":         true,
+}
+
+// publicPrefixes are served without authentication; other /api/v1 routes
+// accept a bearer token or API key
+var publicPrefixes = []string{"/api/v1/oauth2/callback", "/api/v1/webhooks/"}
+
+// OpenAPISpec builds the OpenAPI spec of the given routes
+func OpenAPISpec(routes gin.RoutesInfo) *openapi.Document {
+	doc := openapi.NewDocument(openapi.Info{
+		Title:       "f1ow API",
+		Description: "Workflow automation engine API",
+		Version:     "1.0.0",
+	})
+	doc.Components.SecuritySchemes["bearerAuth"] = &openapi.SecurityScheme{Type: "http", Scheme: "bearer", BearerFormat: "JWT"}
+	doc.Components.SecuritySchemes["apiKeyAuth"] = &openapi.SecurityScheme{Type: "apiKey", In: "header", Name: "X-API-Key"}
+	errorSchema := doc.Schema(errorResponse{})
+
+	sort.Slice(routes, func(i, j int) bool {
+		if routes[i].Path != routes[j].Path {
+			return routes[i].Path < routes[j].Path
+		}
+		return routes[i].Method < routes[j].Method
+	})
+
+	for _, route := range routes {
+		if openAPIExcluded[route.Path] {
+			continue
+		}
+		rd, ok := routeDocs[route.Method+" "+route.Path]
+		if !ok {
+			rd = routeDoc{ID: operationID(route.Method, route.Path), Public: !strings.HasPrefix(route.Path, "/api/")}
+		}
+		for _, prefix := range publicPrefixes {
+			rd.Public = rd.Public || strings.HasPrefix(route.Path, prefix)
+		}
+
+		op := &openapi.Operation{
+			OperationID: rd.ID,
+			Summary:     rd.Summary,
+			Tags:        []string{routeTag(route.Path)},
+			Responses:   map[string]*openapi.Response{"default": {Description: "Error", Content: jsonContent(errorSchema)}},
+		}
+		if !rd.Public {
+			op.Security = []map[string][]string{{"bearerAuth": {}}, {"apiKeyAuth": {}}}
+		}
+
+		path, params := openAPIPath(route.Path)
+		for _, name := range params {
+			schema := &openapi.Schema{Type: "string"}
+			if name == "id" {
+				schema.Format = "uuid"
+			}
+			op.Parameters = append(op.Parameters, openapi.Parameter{Name: name, In: "path", Required: true, Schema: schema})
+		}
+		for _, q := range rd.Query {
+			op.Parameters = append(op.Parameters, openapi.Parameter{Name: q.Name, In: "query", Description: q.Description, Schema: doc.Schema(q.Type)})
+		}
+
+		if rd.Body != nil {
+			op.RequestBody = &openapi.RequestBody{Required: true, Content: jsonContent(doc.Schema(rd.Body))}
+		}
+
+		status := rd.Status
+		if status == 0 {
+			status = http.StatusOK
+		}
+		response := &openapi.Response{Description: http.StatusText(status)}
+		switch {
+		case rd.Content != "":
+			response.Content = map[string]openapi.MediaType{rd.Content: {Schema: &openapi.Schema{Type: "string", Format: "binary"}}}
+		case status < 300:
+			response.Content = jsonContent(doc.Schema(rd.Response))
+		}
+		for name, description := range rd.Headers {
+			if response.Headers == nil {
+				response.Headers = make(map[string]openapi.Header)
+			}
+			response.Headers[name] = openapi.Header{Description: description, Schema: &openapi.Schema{Type: "string"}}
+		}
+		op.Responses[strconv.Itoa(status)] = response
+
+		doc.AddOperation(path, route.Method, op)
+	}
+	return doc
+}
+
+// GetOpenAPISpec serves the OpenAPI spec of the router's routes. The spec is
+// built on the first request, once every route has been registered.
+func GetOpenAPISpec(router *gin.Engine) gin.HandlerFunc {
+	var (
+		once sync.Once
+		spec []byte
+		err  error
+	)
+	return func(c *gin.Context) {
+		once.Do(func() {
+			spec, err = json.MarshalIndent(OpenAPISpec(router.Routes()), "", "  ")
+		})
+		if err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+		c.Data(200, "application/json; charset=utf-8", spec)
+	}
+}
+
+// swaggerUIPage renders the spec next to it with Swagger UI
+const swaggerUIPage = `<!DOCTYPE html>
+<html lang="en">
+<head>
+  <meta charset="utf-8">
+  <title>f1ow API</title>
+  <link rel="stylesheet" href="https://unpkg.com/swagger-ui-dist@5/swagger-ui.css">
+</head>
+<body>
+  <div id="swagger-ui"></div>
+  <script src="https://unpkg.com/swagger-ui-dist@5/swagger-ui-bundle.js"></script>
+  <script>
+    window.ui = SwaggerUIBundle({ url: "openapi.json", dom_id: "#swagger-ui" });
+  </script>
+</body>
+</html>
+`
+
+// SwaggerUI serves an interactive page for the OpenAPI spec
+func SwaggerUI() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Data(200, "text/html; charset=utf-8", []byte(swaggerUIPage))
+	}
+}
+
+// openAPIPath converts gin's :param and *param segments to {param}
+func openAPIPath(path string) (string, []string) {
+	segments := strings.Split(path, "/")
+	var params []string
+	for i, segment := range segments {
+		if strings.HasPrefix(segment, ":") || strings.HasPrefix(segment, "*") {
+			params = append(params, segment[1:])
+			segments[i] = "{" + segment[1:] + "}"
+		}
+	}
+	return strings.Join(segments, "/"), params
+}
+
+// routeTag groups operations by their first segment after /api/v1
+func routeTag(path string) string {
+	segments := strings.Split(strings.TrimPrefix(path, "/api/v1"), "/")
+	if len(segments) > 1 && segments[1] != "" {
+		return segments[1]
+	}
+	return "system"
+}
+
+// operationID names an undocumented route, e.g. GET /api/v1/foo/:id is GetFooID
+func operationID(method, path string) string {
+	var b strings.Builder
+	b.WriteString(strings.ToUpper(method[:1]) + strings.ToLower(method[1:]))
+	for _, segment := range strings.Split(strings.TrimPrefix(path, "/api/v1"), "/") {
+		segment = strings.TrimLeft(segment, ":*")
+		for _, part := range strings.FieldsFunc(segment, func(r rune) bool { return r == '-' || r == '_' || r == '.' }) {
+			if part == "id" {
+				b.WriteString("ID")
+				continue
+			}
+			b.WriteString(strings.ToUpper(part[:1]) + part[1:])
+		}
+	}
+	return b.String()
+}
+
+func jsonContent(schema *openapi.Schema) map[string]openapi.MediaType {
+	return map[string]openapi.MediaType{"application/json": {Schema: schema}}
+}
@@ -0,0 +1,122 @@
+package api
+
+import (
+	"github.com/nuumz/f1ow/internal/engine"
+	"github.com/nuumz/f1ow/internal/models"
+	"github.com/nuumz/f1ow/internal/storage"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// pinnedDataRequest is the body of PUT /workflows/:id/nodes/:node/pinned-data
+type pinnedDataRequest struct {
+	Data map[string]interface{} `json:"data" binding:"required"`
+}
+
+// captureRequest is the body of POST /workflows/:id/nodes/:node/pinned-data/capture
+type captureRequest struct {
+	ExecutionID string `json:"execution_id" binding:"required"`
+}
+
+// workflowNodeParam loads the workflow named by :id and finds the node named
+// by :node in it, writing the error response when either does not exist
+func workflowNodeParam(c *gin.Context, db *storage.DB) (*models.Workflow, *models.Node, bool) {
+	workflow, ok := workflowParam(c, db)
+	if !ok {
+		return nil, nil, false
+	}
+
+	nodeID := c.Param("node")
+	for i := range workflow.Definition.Nodes {
+		if workflow.Definition.Nodes[i].ID == nodeID {
+			return workflow, &workflow.Definition.Nodes[i], true
+		}
+	}
+	c.JSON(404, gin.H{"error": "node not found"})
+	return nil, nil, false
+}
+
+// savePinnedData stores the workflow with the node's pinned data set to
+// data, or cleared when data is nil
+func savePinnedData(c *gin.Context, db *storage.DB, workflow *models.Workflow, node *models.Node, data map[string]interface{}) {
+	node.PinnedData = data
+	if err := db.UpdateWorkflow(c.Request.Context(), workflow); err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(200, workflow)
+}
+
+// SetPinnedData pins sample output data to a node
+func SetPinnedData(db *storage.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req pinnedDataRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(400, gin.H{"error": err.Error()})
+			return
+		}
+
+		workflow, node, ok := workflowNodeParam(c, db)
+		if !ok {
+			return
+		}
+		savePinnedData(c, db, workflow, node, req.Data)
+	}
+}
+
+// DeletePinnedData removes a node's pinned data
+func DeletePinnedData(db *storage.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		workflow, node, ok := workflowNodeParam(c, db)
+		if !ok {
+			return
+		}
+		savePinnedData(c, db, workflow, node, nil)
+	}
+}
+
+// CapturePinnedData pins the node's output from a previous execution of the
+// workflow
+func CapturePinnedData(eng *engine.Engine, db *storage.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req captureRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(400, gin.H{"error": err.Error()})
+			return
+		}
+		executionID, err := uuid.Parse(req.ExecutionID)
+		if err != nil {
+			c.JSON(400, gin.H{"error": "invalid execution ID"})
+			return
+		}
+
+		workflow, node, ok := workflowNodeParam(c, db)
+		if !ok {
+			return
+		}
+
+		execution, err := db.GetExecution(c.Request.Context(), executionID)
+		if err != nil || execution.WorkflowID != workflow.ID {
+			c.JSON(404, gin.H{"error": "execution not found"})
+			return
+		}
+
+		output, ok := execution.Output[node.ID]
+		if !ok {
+			c.JSON(404, gin.H{"error": "node output not found"})
+			return
+		}
+		output, ok = loadNodeOutput(c, eng, output)
+		if !ok {
+			return
+		}
+
+		data, ok := output.(map[string]interface{})
+		if !ok {
+			c.JSON(422, gin.H{"error": "node output is not an object"})
+			return
+		}
+		savePinnedData(c, db, workflow, node, data)
+	}
+}
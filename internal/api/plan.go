@@ -0,0 +1,45 @@
+package api
+
+import (
+	"time"
+
+	"github.com/nuumz/f1ow/internal/engine"
+	"github.com/nuumz/f1ow/internal/storage"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// GetWorkflowPlan returns the static execution plan of a workflow: its run
+// order, the groups of nodes that can run in parallel, any cycles, and the
+// critical path estimated from node durations over ?window=24h|7d|30d
+func GetWorkflowPlan(db *storage.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			c.JSON(400, gin.H{"error": "invalid workflow ID"})
+			return
+		}
+
+		duration, err := parseStatsWindow(c.DefaultQuery("window", defaultStatsWindow))
+		if err != nil {
+			c.JSON(400, gin.H{"error": err.Error()})
+			return
+		}
+
+		ctx := c.Request.Context()
+		workflow, err := db.GetWorkflow(ctx, id)
+		if err != nil {
+			c.JSON(404, gin.H{"error": "workflow not found"})
+			return
+		}
+
+		durations, err := db.GetNodeDurations(ctx, id, time.Now().UTC().Add(-duration))
+		if err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(200, engine.PlanWorkflow(&workflow.Definition, durations))
+	}
+}
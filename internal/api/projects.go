@@ -0,0 +1,154 @@
+package api
+
+import (
+	"errors"
+
+	"github.com/nuumz/f1ow/internal/models"
+	"github.com/nuumz/f1ow/internal/storage"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// GetProjects lists top-level projects, or the sub-projects of ?parent_id
+func GetProjects(db *storage.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var parentID *uuid.UUID
+		if parentIDStr := c.Query("parent_id"); parentIDStr != "" {
+			id, err := uuid.Parse(parentIDStr)
+			if err != nil {
+				c.JSON(400, gin.H{"error": "invalid parent ID"})
+				return
+			}
+			parentID = &id
+		}
+
+		projects, err := db.ListProjects(c.Request.Context(), parentID)
+		if err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(200, projects)
+	}
+}
+
+// CreateProject creates a project, optionally nested under parent_id
+func CreateProject(db *storage.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var project models.Project
+		if err := c.ShouldBindJSON(&project); err != nil {
+			c.JSON(400, gin.H{"error": err.Error()})
+			return
+		}
+
+		project.ID = uuid.Nil
+		project.UserID = currentUserID(c)
+
+		if err := db.CreateProject(c.Request.Context(), &project); err != nil {
+			c.JSON(projectErrorStatus(err), gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(201, project)
+	}
+}
+
+// GetProject returns a project
+func GetProject(db *storage.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			c.JSON(400, gin.H{"error": "invalid project ID"})
+			return
+		}
+
+		project, err := db.GetProject(c.Request.Context(), id)
+		if err != nil {
+			c.JSON(projectErrorStatus(err), gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(200, project)
+	}
+}
+
+// UpdateProject renames a project or moves it under another parent
+func UpdateProject(db *storage.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			c.JSON(400, gin.H{"error": "invalid project ID"})
+			return
+		}
+
+		existing, err := db.GetProject(c.Request.Context(), id)
+		if err != nil {
+			c.JSON(projectErrorStatus(err), gin.H{"error": err.Error()})
+			return
+		}
+
+		var project models.Project
+		if err := c.ShouldBindJSON(&project); err != nil {
+			c.JSON(400, gin.H{"error": err.Error()})
+			return
+		}
+
+		project.ID = id
+		project.UserID = existing.UserID
+		project.CreatedAt = existing.CreatedAt
+		if err := db.UpdateProject(c.Request.Context(), &project); err != nil {
+			c.JSON(projectErrorStatus(err), gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(200, project)
+	}
+}
+
+// DeleteProject deletes a project without sub-projects. Its workflows are
+// kept and become unassigned.
+func DeleteProject(db *storage.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			c.JSON(400, gin.H{"error": "invalid project ID"})
+			return
+		}
+
+		if err := db.DeleteProject(c.Request.Context(), id); err != nil {
+			c.JSON(projectErrorStatus(err), gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(200, gin.H{"message": "project deleted"})
+	}
+}
+
+// projectExists writes a 400 response and returns false when a workflow
+// references a project that does not exist
+func projectExists(c *gin.Context, db *storage.DB, projectID *uuid.UUID) bool {
+	if projectID == nil {
+		return true
+	}
+	if _, err := db.GetProject(c.Request.Context(), *projectID); err != nil {
+		status := 500
+		if errors.Is(err, storage.ErrProjectNotFound) {
+			status = 400
+		}
+		c.JSON(status, gin.H{"error": err.Error()})
+		return false
+	}
+	return true
+}
+
+// projectErrorStatus maps project storage errors to HTTP status codes
+func projectErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, storage.ErrParentProjectNotFound):
+		return 400
+	case errors.Is(err, storage.ErrProjectNotFound):
+		return 404
+	case errors.Is(err, storage.ErrProjectNotEmpty), errors.Is(err, storage.ErrProjectCycle):
+		return 409
+	default:
+		return 500
+	}
+}
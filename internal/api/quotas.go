@@ -0,0 +1,162 @@
+package api
+
+import (
+	"errors"
+	"math"
+	"strconv"
+
+	"github.com/nuumz/f1ow/internal/engine"
+	"github.com/nuumz/f1ow/internal/models"
+	"github.com/nuumz/f1ow/internal/quota"
+	"github.com/nuumz/f1ow/internal/storage"
+	"github.com/nuumz/f1ow/internal/tenant"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// quotaExceeded responds to an ExceededError and reports whether err was
+// one. Quotas that free up with time get 429 with Retry-After; the others
+// get 403.
+func quotaExceeded(c *gin.Context, err error) bool {
+	var exceeded *quota.ExceededError
+	if !errors.As(err, &exceeded) {
+		return false
+	}
+
+	status := 403
+	if exceeded.RetryAfter > 0 {
+		status = 429
+		c.Header("Retry-After", strconv.Itoa(int(math.Ceil(exceeded.RetryAfter.Seconds()))))
+	}
+	c.JSON(status, gin.H{"error": exceeded.Error(), "quota": exceeded.Quota, "limit": exceeded.Limit})
+	return true
+}
+
+// checkActivation responds 403 and returns false when the tenant may not
+// have count more workflows active
+func checkActivation(c *gin.Context, eng *engine.Engine, tenantID uuid.UUID, count int) bool {
+	err := eng.Quotas().CheckActivation(c.Request.Context(), tenantID, count)
+	if err == nil {
+		return true
+	}
+	if !quotaExceeded(c, err) {
+		c.JSON(500, gin.H{"error": err.Error()})
+	}
+	return false
+}
+
+// GetLimits returns the quotas of the caller's tenant and how much of them
+// it uses
+func GetLimits(eng *engine.Engine) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+		limits, err := eng.Quotas().Limits(ctx, tenant.IDOrDefault(ctx))
+		if err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(200, limits)
+	}
+}
+
+// GetPlans lists the plans tenants can be put on
+func GetPlans(db *storage.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		plans, err := db.ListPlans(c.Request.Context())
+		if err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(200, plans)
+	}
+}
+
+// planRequest is the body of PUT /plans/:name
+type planRequest struct {
+	Description string        `json:"description"`
+	Quotas      models.Quotas `json:"quotas"`
+}
+
+// SavePlan creates or replaces a plan. Tenants on it are held to the new
+// quotas within 30 seconds.
+func SavePlan(db *storage.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		name := c.Param("name")
+		if len(name) > 100 {
+			c.JSON(400, gin.H{"error": "plan name must be at most 100 characters"})
+			return
+		}
+
+		var req planRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(400, gin.H{"error": err.Error()})
+			return
+		}
+		if err := quota.Validate(req.Quotas); err != nil {
+			c.JSON(400, gin.H{"error": err.Error()})
+			return
+		}
+
+		plan := &models.Plan{Name: name, Description: req.Description, Quotas: req.Quotas}
+		if err := db.SavePlan(c.Request.Context(), plan); err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(200, plan)
+	}
+}
+
+// DeletePlan deletes a plan no tenant is on
+func DeletePlan(db *storage.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if err := db.DeletePlan(c.Request.Context(), c.Param("name")); err != nil {
+			status := 500
+			switch {
+			case errors.Is(err, storage.ErrPlanNotFound):
+				status = 404
+			case errors.Is(err, storage.ErrPlanInUse):
+				status = 409
+			}
+			c.JSON(status, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(200, gin.H{"message": "plan deleted"})
+	}
+}
+
+// UpdateTenantQuotas puts a tenant on a plan and replaces the quotas it
+// overrides. Servers other than the one handling the request pick up the
+// change within 30 seconds.
+func UpdateTenantQuotas(eng *engine.Engine, db *storage.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			c.JSON(400, gin.H{"error": "invalid tenant ID"})
+			return
+		}
+
+		var quotas models.TenantQuotas
+		if err := c.ShouldBindJSON(&quotas); err != nil {
+			c.JSON(400, gin.H{"error": err.Error()})
+			return
+		}
+		if quotas.Quotas != nil {
+			if err := quota.Validate(*quotas.Quotas); err != nil {
+				c.JSON(400, gin.H{"error": err.Error()})
+				return
+			}
+		}
+
+		if err := db.UpdateTenantQuotas(c.Request.Context(), id, quotas); err != nil {
+			status := 500
+			if errors.Is(err, storage.ErrTenantNotFound) || errors.Is(err, storage.ErrPlanNotFound) {
+				status = 404
+			}
+			c.JSON(status, gin.H{"error": err.Error()})
+			return
+		}
+		eng.Quotas().Forget(id)
+		c.JSON(200, quotas)
+	}
+}
@@ -0,0 +1,81 @@
+package api
+
+import (
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/nuumz/f1ow/internal/auth"
+	"github.com/nuumz/f1ow/internal/ratelimit"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RateLimitConfig controls per-client API rate limiting
+type RateLimitConfig struct {
+	// Backend counts requests; nil disables rate limiting
+	Backend ratelimit.WindowBackend
+	// Limit is the number of requests each client may make per Window
+	Limit  int
+	Window time.Duration
+	// Exclude lists path prefixes that are never limited
+	Exclude []string
+}
+
+// DefaultRateLimitExclude keeps probes and scrapes out of client limits
+var DefaultRateLimitExclude = []string{"/health", "/healthz", "/readyz", "/metrics"}
+
+// RateLimit limits each client to the configured requests per sliding
+// window. Clients are identified by API key, then user, then IP address, so
+// it must run after Authenticate. Every response carries X-RateLimit-*
+// headers; rejected requests get 429 with Retry-After.
+func RateLimit(config RateLimitConfig) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if config.Backend == nil || config.Limit <= 0 || excludedPath(c.Request.URL.Path, config.Exclude) {
+			c.Next()
+			return
+		}
+
+		result, err := config.Backend.Hit(c.Request.Context(), "ratelimit:api:"+clientKey(c), config.Limit, config.Window)
+		if err != nil {
+			// Fail open: an unavailable limiter should not take the API down
+			c.Next()
+			return
+		}
+
+		resetSeconds := int(math.Ceil(result.Reset.Seconds()))
+		c.Header("X-RateLimit-Limit", strconv.Itoa(result.Limit))
+		c.Header("X-RateLimit-Remaining", strconv.Itoa(result.Remaining))
+		c.Header("X-RateLimit-Reset", strconv.FormatInt(time.Now().Add(result.Reset).Unix(), 10))
+
+		if !result.Allowed {
+			c.Header("Retry-After", strconv.Itoa(resetSeconds))
+			c.AbortWithStatusJSON(429, gin.H{"error": "rate limit exceeded"})
+			return
+		}
+		c.Next()
+	}
+}
+
+// clientKey identifies the caller for rate limiting
+func clientKey(c *gin.Context) string {
+	if claims, ok := auth.FromContext(c.Request.Context()); ok {
+		if claims.APIKeyID != "" {
+			return "apikey:" + claims.APIKeyID
+		}
+		if claims.Subject != "" {
+			return "user:" + claims.Subject
+		}
+	}
+	return "ip:" + c.ClientIP()
+}
+
+func excludedPath(path string, prefixes []string) bool {
+	for _, prefix := range prefixes {
+		if path == prefix || strings.HasPrefix(path, strings.TrimSuffix(prefix, "/")+"/") {
+			return true
+		}
+	}
+	return false
+}
@@ -1,63 +1,346 @@
 package api
 
 import (
+	"errors"
+	"io"
 	"strconv"
+	"strings"
+	"time"
 
+	"github.com/nuumz/f1ow/internal/alerting"
 	"github.com/nuumz/f1ow/internal/engine"
 	"github.com/nuumz/f1ow/internal/models"
+	"github.com/nuumz/f1ow/internal/schedules"
 	"github.com/nuumz/f1ow/internal/storage"
+	"github.com/nuumz/f1ow/internal/subscriptions"
+	"github.com/nuumz/f1ow/internal/tenant"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 )
 
-func SetupRoutes(router *gin.Engine, eng *engine.Engine, db *storage.DB, redis *storage.RedisClient) {
-	// Health check
+// RouterConfig configures authentication and rate limiting for the API
+type RouterConfig struct {
+	Auth      AuthConfig
+	RateLimit RateLimitConfig
+
+	// InlineExecution runs executions in the API process and responds with
+	// the result. Otherwise they are queued for workers and the response is
+	// the pending execution.
+	InlineExecution bool
+
+	// Alerts manages alert rules and channels; alerting routes respond 503
+	// when nil
+	Alerts *alerting.Manager
+
+	// Subscriptions manages outbound event webhooks; their routes respond
+	// 503 when nil
+	Subscriptions *subscriptions.Manager
+
+	// Schedules manages cron schedules of workflows; their routes respond
+	// 503 when nil
+	Schedules *schedules.Manager
+
+	// Health configures the readiness checks of /readyz
+	Health HealthConfig
+}
+
+// NewRouter creates the API router with request logging and panic recovery.
+// Client addresses, which anonymous requests are rate limited by, come from
+// X-Forwarded-For only when one of trustedProxies (IPs or CIDRs) sent the
+// request; with none, they are the addresses of the connections.
+func NewRouter(trustedProxies []string) (*gin.Engine, error) {
+	router := gin.Default()
+	if err := router.SetTrustedProxies(trustedProxies); err != nil {
+		return nil, err
+	}
+	return router, nil
+}
+
+func SetupRoutes(router *gin.Engine, eng *engine.Engine, db *storage.DB, redis *storage.RedisClient, config RouterConfig) {
+	// Liveness and readiness probes
+	router.GET("/healthz", Liveness())
+	router.GET("/readyz", Readiness(db, redis, eng, config.Health))
+
+	// Health check kept for existing monitors; probes should use the above
 	router.GET("/health", func(c *gin.Context) {
+		services := gin.H{"database": db.Ping() == nil}
+		if redis != nil {
+			services["redis"] = redis.Ping() == nil
+		}
 		c.JSON(200, gin.H{
-			"status": "healthy",
-			"services": gin.H{
-				"database": db.Ping() == nil,
-				"redis":    redis.Ping() == nil,
-			},
+			"status":   "healthy",
+			"services": services,
 		})
 	})
 
-	api := router.Group("/api/v1")
+	api := router.Group("/api/v1", Authenticate(config.Auth), RateLimit(config.RateLimit), AuthorizeScopes())
 	{
 		// Workflow routes
 		api.GET("/workflows", GetWorkflows(db))
-		api.POST("/workflows", CreateWorkflow(db))
+		api.POST("/workflows", CreateWorkflow(eng, db))
+		api.POST("/workflows/sync", SyncWorkflows(eng, db))
 		api.GET("/workflows/:id", GetWorkflow(db))
-		api.PUT("/workflows/:id", UpdateWorkflow(db))
-		api.DELETE("/workflows/:id", DeleteWorkflow(db))
+		api.PUT("/workflows/:id", UpdateWorkflow(eng, db))
+		api.DELETE("/workflows/:id", DeleteWorkflow(eng, db))
+		api.POST("/workflows/:id/duplicate", DuplicateWorkflow(eng, db))
+		api.POST("/workflows/:id/activate", SetWorkflowStatus(eng, db, models.WorkflowStatusActive))
+		api.POST("/workflows/:id/deactivate", SetWorkflowStatus(eng, db, models.WorkflowStatusDraft))
+		api.POST("/workflows/:id/archive", SetWorkflowStatus(eng, db, models.WorkflowStatusArchived))
+		api.GET("/workflows/:id/stats", GetWorkflowStats(db, redis))
+		api.GET("/workflows/:id/plan", GetWorkflowPlan(db))
+		api.POST("/workflows/:id/lint", LintWorkflow(eng, db))
+		api.GET("/workflows/:id/state/:key", GetWorkflowState(db))
+		api.PUT("/workflows/:id/state/:key", SetWorkflowState(db))
+		api.DELETE("/workflows/:id/state/:key", DeleteWorkflowState(db))
+		api.GET("/workflows/:id/versions", GetWorkflowVersions(db))
+		api.GET("/workflows/:id/versions/:version", GetWorkflowVersion(db))
+		api.GET("/workflows/:id/diff", GetWorkflowDiff(db))
+		api.GET("/workflows/:id/deployments", GetDeployments(db))
+		api.PUT("/workflows/:id/deployments/:environment", DeployWorkflow(db))
+		api.DELETE("/workflows/:id/deployments/:environment", UndeployWorkflow(db))
+		api.POST("/workflows/:id/promote", PromoteWorkflow(db))
+		api.PUT("/workflows/:id/nodes/:node/pinned-data", SetPinnedData(db))
+		api.DELETE("/workflows/:id/nodes/:node/pinned-data", DeletePinnedData(db))
+		api.POST("/workflows/:id/nodes/:node/pinned-data/capture", CapturePinnedData(eng, db))
+		api.GET("/workflows/:id/schedules", GetWorkflowSchedules(config.Schedules))
+
+		// Project routes
+		api.GET("/projects", GetProjects(db))
+		api.POST("/projects", CreateProject(db))
+		api.GET("/projects/:id", GetProject(db))
+		api.PUT("/projects/:id", UpdateProject(db))
+		api.DELETE("/projects/:id", DeleteProject(db))
 
 		// Execution routes
-		api.POST("/workflows/:id/execute", ExecuteWorkflow(eng))
+		api.POST("/workflows/:id/execute", ExecuteWorkflow(eng, config.InlineExecution))
+		api.POST("/workflows/:id/execute/batch", ExecuteWorkflowBatch(eng))
+		api.GET("/batches/:id", GetExecutionBatch(eng))
+		api.POST("/workflows/:id/debug", DebugWorkflow(eng))
 		api.GET("/executions", GetExecutions(db))
+		api.GET("/executions/compare", CompareExecutions(eng, db))
 		api.GET("/executions/:id", GetExecution(db))
+		api.GET("/executions/:id/outputs/:node", GetExecutionNodeOutput(eng, db))
+		api.GET("/executions/:id/nodes/:node_id/context", GetNodeContext(eng, db))
+		api.GET("/executions/:id/events", StreamExecutionEvents(eng, db))
+		api.GET("/executions/:id/debug", GetDebugState(eng))
+		api.POST("/executions/:id/debug", SendDebugCommand(eng))
+		api.GET("/executions/:id/debug/ws", DebugWebSocket(eng))
+		api.POST("/expressions/evaluate", EvaluateExpression(eng, db))
+		api.GET("/usage", GetUsage(db))
+		api.GET("/limits", GetLimits(eng))
+
+		// External task routes, for workers outside the engine
+		api.POST("/external-tasks/fetch-and-lock", FetchAndLockExternalTasks(db))
+		api.GET("/external-tasks/:id", GetExternalTask(db))
+		api.POST("/external-tasks/:id/complete", CompleteExternalTask(db))
+		api.POST("/external-tasks/:id/failure", ReportExternalTaskFailure(db))
+		api.POST("/external-tasks/:id/extend-lock", ExtendExternalTaskLock(db))
+
+		// Binary data routes
+		api.GET("/binary/:id", DownloadBinaryData(eng))
+
+		// Credential routes
+		api.GET("/credentials", GetCredentials(eng))
+		api.POST("/credentials", CreateCredential(eng))
+		api.DELETE("/credentials/:id", DeleteCredential(eng))
+		api.GET("/oauth2/authorize", AuthorizeOAuth2(eng))
+
+		// Environment routes
+		api.GET("/environments", GetEnvironments(db))
+		api.POST("/environments", CreateEnvironment(db))
+		api.DELETE("/environments/:name", DeleteEnvironment(db))
+
+		// Variable routes
+		api.GET("/variables", GetVariables(eng))
+		api.POST("/variables", CreateVariable(eng))
+		api.GET("/variables/:id", GetVariable(eng))
+		api.PUT("/variables/:id", UpdateVariable(eng))
+		api.DELETE("/variables/:id", DeleteVariable(eng))
+
+		// Alerting routes
+		api.GET("/alerts", GetAlerts(config.Alerts))
+		api.GET("/alerts/rules", GetAlertRules(config.Alerts))
+		api.POST("/alerts/rules", CreateAlertRule(config.Alerts))
+		api.GET("/alerts/rules/:id", GetAlertRule(config.Alerts))
+		api.PUT("/alerts/rules/:id", UpdateAlertRule(config.Alerts))
+		api.DELETE("/alerts/rules/:id", DeleteAlertRule(config.Alerts))
+		api.GET("/alerts/channels", GetAlertChannels(config.Alerts))
+		api.POST("/alerts/channels", CreateAlertChannel(config.Alerts))
+		api.GET("/alerts/channels/:id", GetAlertChannel(config.Alerts))
+		api.PUT("/alerts/channels/:id", UpdateAlertChannel(config.Alerts))
+		api.DELETE("/alerts/channels/:id", DeleteAlertChannel(config.Alerts))
+
+		// Schedule routes
+		api.GET("/schedules", GetSchedules(config.Schedules))
+		api.POST("/schedules", CreateSchedule(config.Schedules, db))
+		api.GET("/schedules/preview", PreviewSchedule())
+		api.GET("/schedules/:id", GetSchedule(config.Schedules))
+		api.PUT("/schedules/:id", UpdateSchedule(config.Schedules))
+		api.DELETE("/schedules/:id", DeleteSchedule(config.Schedules))
+		api.POST("/schedules/:id/pause", SetScheduleActive(config.Schedules, false))
+		api.POST("/schedules/:id/resume", SetScheduleActive(config.Schedules, true))
+		api.POST("/schedules/:id/run-now", RunScheduleNow(config.Schedules))
+
+		// Event subscription routes
+		api.GET("/event-subscriptions", GetEventSubscriptions(config.Subscriptions))
+		api.POST("/event-subscriptions", CreateEventSubscription(config.Subscriptions))
+		api.GET("/event-subscriptions/:id", GetEventSubscription(config.Subscriptions))
+		api.PUT("/event-subscriptions/:id", UpdateEventSubscription(config.Subscriptions))
+		api.DELETE("/event-subscriptions/:id", DeleteEventSubscription(config.Subscriptions))
+		api.GET("/event-subscriptions/:id/deliveries", GetEventDeliveries(config.Subscriptions))
+		api.POST("/event-subscriptions/:id/deliveries/:delivery/redeliver", RedeliverEvent(config.Subscriptions))
 
 		// Node routes
 		api.GET("/nodes", GetAvailableNodes(eng))
+		api.GET("/nodes/deprecations", GetDeprecatedNodeUsages(eng, db))
 		api.GET("/nodes/:type/schema", GetNodeSchema(eng))
+		api.POST("/nodes/:type/validate", ValidateNodeConfig(eng))
+
+		// API key routes
+		apiKeys := api.Group("/api-keys", RequireUser())
+		apiKeys.GET("", GetAPIKeys(db))
+		apiKeys.POST("", CreateAPIKey(db))
+		apiKeys.DELETE("/:id", RevokeAPIKey(db))
+
+		// Tenant routes
+		tenants := api.Group("/tenants", RequireOperator())
+		tenants.GET("", GetTenants(db))
+		tenants.POST("", CreateTenant(db))
+		tenants.PUT("/:id/network-policy", UpdateTenantNetworkPolicy(db))
+		tenants.PUT("/:id/quotas", UpdateTenantQuotas(eng, db))
+
+		// Quota plan routes
+		plans := api.Group("/plans", RequireOperator())
+		plans.GET("", GetPlans(db))
+		plans.PUT("/:name", SavePlan(db))
+		plans.DELETE("/:name", DeletePlan(db))
+
+		// Worker fleet routes
+		workers := api.Group("/workers", RequireRole("admin"))
+		workers.GET("", GetWorkers(eng))
+		workers.GET("/:id", GetWorker(eng))
+		workers.POST("/:id/drain", SendWorkerCommand(eng, engine.WorkerDrain))
+		workers.POST("/:id/stop", SendWorkerCommand(eng, engine.WorkerStop))
+		api.GET("/queue/stats", RequireRole("admin"), GetQueueStats(eng))
+	}
+
+	// Routes called by browsers and third parties without bearer tokens.
+	// They authenticate with their own state or signatures and run unscoped.
+	public := router.Group("/api/v1", RateLimit(config.RateLimit))
+	{
+		// OAuth2 routes
+		public.GET("/oauth2/callback", OAuth2Callback(eng))
+
+		// Webhook routes
+		public.POST("/webhooks/email/:id", ReceiveEmailWebhook(eng, db))
+		public.POST("/webhooks/github/:id", ReceiveGitHubWebhook(eng, db))
+		public.POST("/webhooks/gitlab/:id", ReceiveGitLabWebhook(eng, db))
+
+		// API documentation
+		public.GET("/openapi.json", GetOpenAPISpec(router))
+		public.GET("/docs", SwaggerUI())
 	}
 
 	// WebSocket for real-time updates
 	router.GET("/ws", HandleWebSocket())
 }
 
-func GetWorkflows(db *storage.DB) gin.HandlerFunc {
+func GetWorkflows(db storage.WorkflowRepository) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		workflows, err := db.GetWorkflows(c.Request.Context())
+		opts := storage.WorkflowListOptions{
+			Limit: 100,
+			Query: c.Query("q"),
+			Sort:  c.DefaultQuery("sort", "created_at"),
+			Desc:  c.DefaultQuery("order", "desc") == "desc",
+		}
+
+		if _, ok := storage.WorkflowSortFields[opts.Sort]; !ok {
+			c.JSON(400, gin.H{"error": "invalid sort field: " + opts.Sort})
+			return
+		}
+		if order := c.Query("order"); order != "" && order != "asc" && order != "desc" {
+			c.JSON(400, gin.H{"error": "order must be asc or desc"})
+			return
+		}
+
+		if limitStr := c.Query("limit"); limitStr != "" {
+			if l, err := strconv.Atoi(limitStr); err == nil && l > 0 {
+				opts.Limit = l
+			}
+		}
+		if opts.Limit > 1000 {
+			opts.Limit = 1000
+		}
+		if offsetStr := c.Query("offset"); offsetStr != "" {
+			if o, err := strconv.Atoi(offsetStr); err == nil && o > 0 {
+				opts.Offset = o
+			}
+		}
+
+		for _, tags := range c.QueryArray("tag") {
+			for _, tag := range strings.Split(tags, ",") {
+				if tag = strings.TrimSpace(tag); tag != "" {
+					opts.Tags = append(opts.Tags, tag)
+				}
+			}
+		}
+
+		if projectIDStr := c.Query("project_id"); projectIDStr != "" {
+			projectID, err := uuid.Parse(projectIDStr)
+			if err != nil {
+				c.JSON(400, gin.H{"error": "invalid project ID"})
+				return
+			}
+			opts.ProjectID = &projectID
+		}
+
+		// status may be repeated or comma-separated; archived and deleted
+		// workflows are only listed when asked for
+		for _, statuses := range c.QueryArray("status") {
+			for _, status := range strings.Split(statuses, ",") {
+				if status = strings.TrimSpace(status); status == "" {
+					continue
+				}
+				if !models.WorkflowStatus(status).Valid() {
+					c.JSON(400, gin.H{"error": "invalid status: " + status})
+					return
+				}
+				opts.Statuses = append(opts.Statuses, models.WorkflowStatus(status))
+			}
+		}
+
+		opts.Summary = c.Query("fields") == "summary"
+
+		workflows, total, err := db.ListWorkflows(c.Request.Context(), opts)
 		if err != nil {
 			c.JSON(500, gin.H{"error": err.Error()})
 			return
 		}
+
+		// Paging metadata goes in headers so the body stays a plain array
+		c.Header("X-Total-Count", strconv.Itoa(total))
+		c.Header("X-Limit", strconv.Itoa(opts.Limit))
+		c.Header("X-Offset", strconv.Itoa(opts.Offset))
+
+		if opts.Summary {
+			summaries := make([]models.WorkflowSummary, 0, len(workflows))
+			for i := range workflows {
+				summaries = append(summaries, workflows[i].Summary())
+			}
+			c.JSON(200, summaries)
+			return
+		}
 		c.JSON(200, workflows)
 	}
 }
 
-func CreateWorkflow(db *storage.DB) gin.HandlerFunc {
+// publishWorkflowEvent reports a change to a workflow on the event bus
+func publishWorkflowEvent(c *gin.Context, eng *engine.Engine, eventType engine.EventType, workflowID uuid.UUID) {
+	eng.PublishEvent(c.Request.Context(), engine.Event{Type: eventType, WorkflowID: workflowID.String()})
+}
+
+func CreateWorkflow(eng *engine.Engine, db *storage.DB) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		var workflow models.Workflow
 		if err := c.ShouldBindJSON(&workflow); err != nil {
@@ -65,19 +348,32 @@ func CreateWorkflow(db *storage.DB) gin.HandlerFunc {
 			return
 		}
 
-		// TODO: Get user ID from JWT token
-		workflow.UserID = uuid.New() // Placeholder
+		workflow.UserID = currentUserID(c)
+		if workflow.Status != "" && workflow.Status != models.WorkflowStatusDraft && workflow.Status != models.WorkflowStatusActive {
+			c.JSON(400, gin.H{"error": "new workflows must be draft or active"})
+			return
+		}
+
+		if !validWorkflow(c, eng, &workflow) || !projectExists(c, db, workflow.ProjectID) {
+			return
+		}
+		if workflow.Status == models.WorkflowStatusActive || (workflow.Status == "" && workflow.IsActive) {
+			if !checkActivation(c, eng, tenant.IDOrDefault(c.Request.Context()), 1) {
+				return
+			}
+		}
 
 		if err := db.CreateWorkflow(c.Request.Context(), &workflow); err != nil {
 			c.JSON(500, gin.H{"error": err.Error()})
 			return
 		}
+		publishWorkflowEvent(c, eng, engine.EventWorkflowCreated, workflow.ID)
 
 		c.JSON(201, workflow)
 	}
 }
 
-func GetWorkflow(db *storage.DB) gin.HandlerFunc {
+func GetWorkflow(db storage.WorkflowRepository) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		idStr := c.Param("id")
 		id, err := uuid.Parse(idStr)
@@ -87,16 +383,20 @@ func GetWorkflow(db *storage.DB) gin.HandlerFunc {
 		}
 
 		workflow, err := db.GetWorkflow(c.Request.Context(), id)
-		if err != nil {
+		if errors.Is(err, storage.ErrWorkflowNotFound) {
 			c.JSON(404, gin.H{"error": err.Error()})
 			return
 		}
+		if err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
 
 		c.JSON(200, workflow)
 	}
 }
 
-func UpdateWorkflow(db *storage.DB) gin.HandlerFunc {
+func UpdateWorkflow(eng *engine.Engine, db *storage.DB) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		idStr := c.Param("id")
 		id, err := uuid.Parse(idStr)
@@ -112,16 +412,94 @@ func UpdateWorkflow(db *storage.DB) gin.HandlerFunc {
 		}
 
 		workflow.ID = id
+		if !validWorkflow(c, eng, &workflow) || !projectExists(c, db, workflow.ProjectID) {
+			return
+		}
+
 		if err := db.UpdateWorkflow(c.Request.Context(), &workflow); err != nil {
 			c.JSON(500, gin.H{"error": err.Error()})
 			return
 		}
+		publishWorkflowEvent(c, eng, engine.EventWorkflowUpdated, workflow.ID)
 
 		c.JSON(200, workflow)
 	}
 }
 
-func DeleteWorkflow(db *storage.DB) gin.HandlerFunc {
+// SetWorkflowStatus returns a handler that moves a workflow to status.
+// Activating re-validates the definition so triggers never start for an
+// invalid workflow.
+func SetWorkflowStatus(eng *engine.Engine, db storage.WorkflowRepository, status models.WorkflowStatus) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		workflow, ok := workflowParam(c, db)
+		if !ok {
+			return
+		}
+		if workflow.Status == models.WorkflowStatusDeleted {
+			c.JSON(409, gin.H{"error": "workflow is deleted"})
+			return
+		}
+		if status == models.WorkflowStatusActive && !validWorkflow(c, eng, workflow) {
+			return
+		}
+		if status == models.WorkflowStatusActive && workflow.Status != status && !checkActivation(c, eng, workflow.TenantID, 1) {
+			return
+		}
+
+		if err := db.SetWorkflowStatus(c.Request.Context(), workflow.ID, status); err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+		publishWorkflowEvent(c, eng, engine.EventWorkflowUpdated, workflow.ID)
+
+		workflow.Status = status
+		workflow.IsActive = status == models.WorkflowStatusActive
+		c.JSON(200, workflow)
+	}
+}
+
+// duplicateRequest is the optional body of POST /workflows/:id/duplicate
+type duplicateRequest struct {
+	Name        string            `json:"name"`        // "Copy of <name>" when empty
+	Credentials map[string]string `json:"credentials"` // credential IDs to replace in the copy
+}
+
+// DuplicateWorkflow creates a draft copy of a workflow with new node and
+// edge IDs, starting at version 1 without execution history
+func DuplicateWorkflow(eng *engine.Engine, db storage.WorkflowRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		source, ok := workflowParam(c, db)
+		if !ok {
+			return
+		}
+
+		var req duplicateRequest
+		if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+			c.JSON(400, gin.H{"error": err.Error()})
+			return
+		}
+
+		workflow, err := source.Duplicate(req.Credentials)
+		if err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+		if req.Name != "" {
+			workflow.Name = req.Name
+		}
+		workflow.UserID = currentUserID(c)
+
+		if err := db.CreateWorkflow(c.Request.Context(), workflow); err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+		publishWorkflowEvent(c, eng, engine.EventWorkflowCreated, workflow.ID)
+
+		c.JSON(201, workflow)
+	}
+}
+
+func DeleteWorkflow(eng *engine.Engine, db storage.WorkflowRepository) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		idStr := c.Param("id")
 		id, err := uuid.Parse(idStr)
@@ -130,16 +508,23 @@ func DeleteWorkflow(db *storage.DB) gin.HandlerFunc {
 			return
 		}
 
-		if err := db.DeleteWorkflow(c.Request.Context(), id); err != nil {
+		if err := db.DeleteWorkflow(c.Request.Context(), id); errors.Is(err, storage.ErrWorkflowNotFound) {
+			c.JSON(404, gin.H{"error": err.Error()})
+			return
+		} else if err != nil {
 			c.JSON(500, gin.H{"error": err.Error()})
 			return
 		}
+		publishWorkflowEvent(c, eng, engine.EventWorkflowDeleted, id)
 
 		c.JSON(200, gin.H{"message": "workflow deleted"})
 	}
 }
 
-func ExecuteWorkflow(eng *engine.Engine) gin.HandlerFunc {
+// ExecuteWorkflow queues an execution for the workers and responds 202 with
+// the pending execution, or with inline set runs it and responds with the
+// result
+func ExecuteWorkflow(eng *engine.Engine, inline bool) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		idStr := c.Param("id")
 		id, err := uuid.Parse(idStr)
@@ -154,50 +539,150 @@ func ExecuteWorkflow(eng *engine.Engine) gin.HandlerFunc {
 			return
 		}
 
-		result, err := eng.Execute(c.Request.Context(), id.String(), input)
+		// ?pinned_data=true is a manual test run that uses the nodes' pinned data
+		ctx := c.Request.Context()
+		pinned := c.Query("pinned_data") == "true"
+		if pinned {
+			ctx = engine.WithPinnedData(ctx)
+		}
+
+		// ?environment= runs the version deployed there with its mappings
+		environment := c.Query("environment")
+		if environment != "" && pinned {
+			c.JSON(400, gin.H{"error": "pinned data cannot be used with an environment"})
+			return
+		}
+
+		// A repeated Idempotency-Key returns the execution the key started
+		if key := c.GetHeader("Idempotency-Key"); key != "" {
+			if len(key) > 255 {
+				c.JSON(400, gin.H{"error": "Idempotency-Key must be at most 255 characters"})
+				return
+			}
+			existing, err := eng.IdempotentExecution(ctx, id.String(), key)
+			if err != nil {
+				c.JSON(500, gin.H{"error": err.Error()})
+				return
+			}
+			if existing != nil {
+				c.Header("Idempotent-Replayed", "true")
+				c.JSON(200, existing)
+				return
+			}
+			ctx = engine.WithIdempotencyKey(ctx, key)
+		}
+
+		var result *models.Execution
+		switch {
+		case !inline:
+			result, err = eng.Submit(ctx, id.String(), environment, input)
+		case environment != "":
+			result, err = eng.ExecuteInEnvironment(ctx, id.String(), environment, input)
+		default:
+			result, err = eng.Execute(ctx, id.String(), input)
+		}
+		if errors.Is(err, storage.ErrDeploymentNotFound) {
+			c.JSON(404, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, engine.ErrConcurrencyLimit) {
+			c.JSON(409, gin.H{"error": err.Error()})
+			return
+		}
+		if quotaExceeded(c, err) {
+			return
+		}
 		if err != nil {
 			c.JSON(500, gin.H{"error": err.Error()})
 			return
 		}
 
+		if !inline {
+			c.JSON(202, result)
+			return
+		}
 		c.JSON(200, result)
 	}
 }
 
-func GetExecutions(db *storage.DB) gin.HandlerFunc {
+func GetExecutions(db storage.ExecutionRepository) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		var workflowID *uuid.UUID
-		var status *models.ExecutionStatus
+		opts := storage.ExecutionListOptions{
+			Limit:   100,
+			Cursor:  c.Query("cursor"),
+			Summary: c.Query("fields") == "summary",
+		}
 
 		if wfIDStr := c.Query("workflow_id"); wfIDStr != "" {
 			if wfID, err := uuid.Parse(wfIDStr); err == nil {
-				workflowID = &wfID
+				opts.WorkflowID = &wfID
 			}
 		}
 
-		if statusStr := c.Query("status"); statusStr != "" {
-			s := models.ExecutionStatus(statusStr)
-			status = &s
+		// status may be repeated or comma-separated
+		for _, statuses := range c.QueryArray("status") {
+			for _, status := range strings.Split(statuses, ",") {
+				if status = strings.TrimSpace(status); status != "" {
+					opts.Statuses = append(opts.Statuses, models.ExecutionStatus(status))
+				}
+			}
+		}
+
+		for param, target := range map[string]**time.Time{
+			"started_after":  &opts.StartedAfter,
+			"started_before": &opts.StartedBefore,
+		} {
+			if value := c.Query(param); value != "" {
+				t, err := time.Parse(time.RFC3339, value)
+				if err != nil {
+					c.JSON(400, gin.H{"error": param + " must be an RFC 3339 timestamp"})
+					return
+				}
+				*target = &t
+			}
 		}
 
-		limit := 100
 		if limitStr := c.Query("limit"); limitStr != "" {
-			if l, err := strconv.Atoi(limitStr); err == nil {
-				limit = l
+			if l, err := strconv.Atoi(limitStr); err == nil && l > 0 {
+				opts.Limit = l
 			}
 		}
+		if opts.Limit > 1000 {
+			opts.Limit = 1000
+		}
 
-		executions, err := db.GetExecutions(c.Request.Context(), workflowID, status, limit)
+		if opts.Cursor != "" {
+			if _, _, err := storage.DecodeExecutionCursor(opts.Cursor); err != nil {
+				c.JSON(400, gin.H{"error": err.Error()})
+				return
+			}
+		}
+
+		page, err := db.ListExecutions(c.Request.Context(), opts)
 		if err != nil {
 			c.JSON(500, gin.H{"error": err.Error()})
 			return
 		}
 
-		c.JSON(200, executions)
+		// Paging metadata goes in headers so the body stays a plain array
+		c.Header("X-Total-Count", strconv.Itoa(page.Total))
+		if page.NextCursor != "" {
+			c.Header("X-Next-Cursor", page.NextCursor)
+		}
+
+		if opts.Summary {
+			summaries := make([]models.ExecutionSummary, 0, len(page.Executions))
+			for i := range page.Executions {
+				summaries = append(summaries, page.Executions[i].Summary())
+			}
+			c.JSON(200, summaries)
+			return
+		}
+		c.JSON(200, page.Executions)
 	}
 }
 
-func GetExecution(db *storage.DB) gin.HandlerFunc {
+func GetExecution(db storage.ExecutionRepository) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		idStr := c.Param("id")
 		id, err := uuid.Parse(idStr)
@@ -207,10 +692,14 @@ func GetExecution(db *storage.DB) gin.HandlerFunc {
 		}
 
 		execution, err := db.GetExecution(c.Request.Context(), id)
-		if err != nil {
+		if errors.Is(err, storage.ErrExecutionNotFound) {
 			c.JSON(404, gin.H{"error": err.Error()})
 			return
 		}
+		if err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
 
 		c.JSON(200, execution)
 	}
@@ -222,13 +711,26 @@ func GetAvailableNodes(eng *engine.Engine) gin.HandlerFunc {
 
 		nodeList := make([]gin.H, 0, len(nodes))
 		for nodeType, node := range nodes {
-			nodeList = append(nodeList, gin.H{
+			versions := eng.NodeVersions(nodeType)
+			info := gin.H{
 				"type":        nodeType,
 				"name":        node.Name(),
 				"description": node.Description(),
 				"category":    node.Category(),
 				"icon":        node.Icon(),
-			})
+				"version":     versions[len(versions)-1],
+				"versions":    versions,
+			}
+			deprecated := gin.H{}
+			for _, version := range versions {
+				if message, ok := eng.NodeDeprecation(models.NodeTypeRef(nodeType, version)); ok {
+					deprecated[strconv.Itoa(version)] = message
+				}
+			}
+			if len(deprecated) > 0 {
+				info["deprecated"] = deprecated
+			}
+			nodeList = append(nodeList, info)
 		}
 
 		c.JSON(200, gin.H{
@@ -251,6 +753,70 @@ func GetNodeSchema(eng *engine.Engine) gin.HandlerFunc {
 	}
 }
 
+// GetDeprecatedNodeUsages lists the nodes of the caller's draft and active
+// workflows that run a deprecated version of their type
+func GetDeprecatedNodeUsages(eng *engine.Engine, db *storage.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		workflows, _, err := db.ListWorkflows(c.Request.Context(), storage.WorkflowListOptions{})
+		if err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(200, eng.DeprecatedNodeUsages(workflows))
+	}
+}
+
+// validationResponse is the result of validating a node config
+type validationResponse struct {
+	Valid  bool                `json:"valid"`
+	Errors []engine.FieldError `json:"errors"`
+}
+
+// validationErrorResponse rejects a workflow with invalid node configs
+type validationErrorResponse struct {
+	Error  string              `json:"error"`
+	Errors []engine.FieldError `json:"errors"`
+}
+
+// ValidateNodeConfig checks a config against a node type without saving
+// anything. Invalid configs are reported with status 200 and valid false.
+func ValidateNodeConfig(eng *engine.Engine) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var config map[string]interface{}
+		if err := c.ShouldBindJSON(&config); err != nil {
+			c.JSON(400, gin.H{"error": err.Error()})
+			return
+		}
+
+		fieldErrs, err := eng.ValidateNodeConfig(c.Param("type"), config)
+		if err != nil {
+			c.JSON(404, gin.H{"error": err.Error()})
+			return
+		}
+		if fieldErrs == nil {
+			fieldErrs = []engine.FieldError{}
+		}
+
+		c.JSON(200, validationResponse{Valid: len(fieldErrs) == 0, Errors: fieldErrs})
+	}
+}
+
+// validWorkflow rejects workflows whose node configs are invalid, writing
+// the field errors as a 422 response, and then those with lint errors
+func validWorkflow(c *gin.Context, eng *engine.Engine, workflow *models.Workflow) bool {
+	err := eng.ValidateWorkflow(workflow)
+	if err == nil {
+		return lintWorkflow(c, eng, workflow)
+	}
+	var validationErr *engine.ValidationError
+	if errors.As(err, &validationErr) {
+		c.JSON(422, validationErrorResponse{Error: "invalid workflow definition", Errors: validationErr.Errors})
+	} else {
+		c.JSON(500, gin.H{"error": err.Error()})
+	}
+	return false
+}
+
 func HandleWebSocket() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// TODO: Implement WebSocket handler
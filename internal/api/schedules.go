@@ -0,0 +1,292 @@
+package api
+
+import (
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/nuumz/f1ow/internal/models"
+	"github.com/nuumz/f1ow/internal/schedules"
+	"github.com/nuumz/f1ow/internal/storage"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// scheduleRequest is the body of POST /schedules and PUT /schedules/:id
+type scheduleRequest struct {
+	WorkflowID string                 `json:"workflow_id"` // required when creating; a schedule cannot move to another workflow
+	Name       string                 `json:"name"`
+	CronExpr   string                 `json:"cron_expr" binding:"required"`
+	Timezone   string                 `json:"timezone"` // UTC when omitted
+	Input      map[string]interface{} `json:"input"`
+	Active     *bool                  `json:"is_active"` // true when omitted
+}
+
+func (r scheduleRequest) schedule() *models.Schedule {
+	schedule := &models.Schedule{
+		Name:     r.Name,
+		CronExpr: r.CronExpr,
+		Timezone: r.Timezone,
+		Input:    r.Input,
+		IsActive: true,
+	}
+	if r.Active != nil {
+		schedule.IsActive = *r.Active
+	}
+	return schedule
+}
+
+// schedulePreview is the response of GET /schedules/preview
+type schedulePreview struct {
+	CronExpr string      `json:"cron_expr"`
+	Timezone string      `json:"timezone"`
+	Next     []time.Time `json:"next"`
+}
+
+// schedulesManager returns the schedules manager or writes an error response when it is not configured
+func schedulesManager(c *gin.Context, manager *schedules.Manager) *schedules.Manager {
+	if manager == nil {
+		c.JSON(503, gin.H{"error": "schedules are not configured"})
+	}
+	return manager
+}
+
+// scheduleError writes the response for a schedules manager error
+func scheduleError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, schedules.ErrNotFound), errors.Is(err, storage.ErrWorkflowNotFound):
+		c.JSON(404, gin.H{"error": err.Error()})
+	case errors.Is(err, schedules.ErrInvalid):
+		c.JSON(400, gin.H{"error": err.Error()})
+	default:
+		c.JSON(500, gin.H{"error": err.Error()})
+	}
+}
+
+// scheduleParam parses the :id parameter
+func scheduleParam(c *gin.Context) (uuid.UUID, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(400, gin.H{"error": "invalid schedule ID"})
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+// GetSchedules lists schedules, only those of a workflow with the
+// workflow_id query parameter
+func GetSchedules(manager *schedules.Manager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if schedulesManager(c, manager) == nil {
+			return
+		}
+
+		workflowID := uuid.Nil
+		if value := c.Query("workflow_id"); value != "" {
+			var err error
+			if workflowID, err = uuid.Parse(value); err != nil {
+				c.JSON(400, gin.H{"error": "invalid workflow ID"})
+				return
+			}
+		}
+
+		list, err := manager.List(c.Request.Context(), workflowID)
+		if err != nil {
+			scheduleError(c, err)
+			return
+		}
+		c.JSON(200, list)
+	}
+}
+
+// GetWorkflowSchedules lists the schedules of a workflow
+func GetWorkflowSchedules(manager *schedules.Manager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if schedulesManager(c, manager) == nil {
+			return
+		}
+		workflowID, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			c.JSON(400, gin.H{"error": "invalid workflow ID"})
+			return
+		}
+
+		list, err := manager.List(c.Request.Context(), workflowID)
+		if err != nil {
+			scheduleError(c, err)
+			return
+		}
+		c.JSON(200, list)
+	}
+}
+
+// CreateSchedule schedules a workflow of the caller's tenant
+func CreateSchedule(manager *schedules.Manager, db storage.WorkflowRepository) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if schedulesManager(c, manager) == nil {
+			return
+		}
+
+		var req scheduleRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(400, gin.H{"error": err.Error()})
+			return
+		}
+		workflowID, err := uuid.Parse(req.WorkflowID)
+		if err != nil {
+			c.JSON(400, gin.H{"error": "invalid workflow ID"})
+			return
+		}
+		if _, err := db.GetWorkflow(c.Request.Context(), workflowID); err != nil {
+			scheduleError(c, err)
+			return
+		}
+
+		schedule := req.schedule()
+		schedule.WorkflowID = workflowID
+		created, err := manager.Create(c.Request.Context(), schedule)
+		if err != nil {
+			scheduleError(c, err)
+			return
+		}
+		c.JSON(201, created)
+	}
+}
+
+// GetSchedule returns a schedule
+func GetSchedule(manager *schedules.Manager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if schedulesManager(c, manager) == nil {
+			return
+		}
+		id, ok := scheduleParam(c)
+		if !ok {
+			return
+		}
+
+		schedule, err := manager.Get(c.Request.Context(), id)
+		if err != nil {
+			scheduleError(c, err)
+			return
+		}
+		c.JSON(200, schedule)
+	}
+}
+
+// UpdateSchedule replaces a schedule's name, cron expression, timezone,
+// input, and active flag
+func UpdateSchedule(manager *schedules.Manager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if schedulesManager(c, manager) == nil {
+			return
+		}
+		id, ok := scheduleParam(c)
+		if !ok {
+			return
+		}
+
+		var req scheduleRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(400, gin.H{"error": err.Error()})
+			return
+		}
+
+		schedule := req.schedule()
+		schedule.ID = id
+		updated, err := manager.Update(c.Request.Context(), schedule)
+		if err != nil {
+			scheduleError(c, err)
+			return
+		}
+		c.JSON(200, updated)
+	}
+}
+
+// DeleteSchedule removes a schedule
+func DeleteSchedule(manager *schedules.Manager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if schedulesManager(c, manager) == nil {
+			return
+		}
+		id, ok := scheduleParam(c)
+		if !ok {
+			return
+		}
+
+		if err := manager.Delete(c.Request.Context(), id); err != nil {
+			scheduleError(c, err)
+			return
+		}
+		c.JSON(200, gin.H{"message": "schedule deleted"})
+	}
+}
+
+// SetScheduleActive pauses or resumes a schedule
+func SetScheduleActive(manager *schedules.Manager, active bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if schedulesManager(c, manager) == nil {
+			return
+		}
+		id, ok := scheduleParam(c)
+		if !ok {
+			return
+		}
+
+		schedule, err := manager.SetActive(c.Request.Context(), id, active)
+		if err != nil {
+			scheduleError(c, err)
+			return
+		}
+		c.JSON(200, schedule)
+	}
+}
+
+// RunScheduleNow queues an execution of a schedule's workflow with its
+// input, without changing when it next runs
+func RunScheduleNow(manager *schedules.Manager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if schedulesManager(c, manager) == nil {
+			return
+		}
+		id, ok := scheduleParam(c)
+		if !ok {
+			return
+		}
+
+		execution, err := manager.RunNow(c.Request.Context(), id)
+		if err != nil {
+			scheduleError(c, err)
+			return
+		}
+		c.JSON(202, execution)
+	}
+}
+
+// PreviewSchedule returns the next times a cron expression fires, five
+// unless count says otherwise
+func PreviewSchedule() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		expr := c.Query("cron_expr")
+		if expr == "" {
+			c.JSON(400, gin.H{"error": "cron_expr is required"})
+			return
+		}
+		count := 5
+		if value := c.Query("count"); value != "" {
+			var err error
+			if count, err = strconv.Atoi(value); err != nil || count < 1 || count > 100 {
+				c.JSON(400, gin.H{"error": "count must be between 1 and 100"})
+				return
+			}
+		}
+
+		timezone := c.DefaultQuery("timezone", "UTC")
+		next, err := schedules.Preview(expr, timezone, time.Now(), count)
+		if err != nil {
+			scheduleError(c, err)
+			return
+		}
+		c.JSON(200, schedulePreview{CronExpr: expr, Timezone: timezone, Next: next})
+	}
+}
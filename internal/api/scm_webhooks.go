@@ -0,0 +1,182 @@
+package api
+
+import (
+	"encoding/json"
+	"io"
+	"net/url"
+	"strings"
+
+	"github.com/nuumz/f1ow/internal/engine"
+	"github.com/nuumz/f1ow/internal/models"
+	"github.com/nuumz/f1ow/internal/nodes"
+	"github.com/nuumz/f1ow/internal/storage"
+	"github.com/nuumz/f1ow/internal/triggers"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// maxSCMWebhookSize matches the largest payload GitHub delivers
+const maxSCMWebhookSize = 25 << 20
+
+// ReceiveGitHubWebhook verifies a GitHub delivery against the workflow's
+// github_trigger nodes and queues an execution with the mapped event
+func ReceiveGitHubWebhook(eng *engine.Engine, db *storage.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		workflow, body, ok := loadSCMWebhook(c, db)
+		if !ok {
+			return
+		}
+
+		signature := c.GetHeader("X-Hub-Signature-256")
+		var config *nodes.GitHubTriggerConfig
+		for _, node := range triggerNodes(workflow, "github_trigger") {
+			nodeConfig, err := nodes.ParseGitHubTriggerConfig(node.Config)
+			if err == nil && triggers.VerifyGitHubSignature(nodeConfig.Secret, body, signature) {
+				config = nodeConfig
+				break
+			}
+		}
+		if config == nil {
+			c.JSON(401, gin.H{"error": "invalid signature"})
+			return
+		}
+
+		event := c.GetHeader("X-GitHub-Event")
+		if event == "ping" {
+			c.JSON(200, gin.H{"status": "pong"})
+			return
+		}
+
+		// Webhooks configured with the form content type wrap the JSON in a "payload" field
+		if strings.HasPrefix(c.ContentType(), "application/x-www-form-urlencoded") {
+			form, err := url.ParseQuery(string(body))
+			if err != nil {
+				c.JSON(400, gin.H{"error": err.Error()})
+				return
+			}
+			body = []byte(form.Get("payload"))
+		}
+
+		var data map[string]interface{}
+		if err := json.Unmarshal(body, &data); err != nil {
+			c.JSON(400, gin.H{"error": "invalid JSON payload"})
+			return
+		}
+
+		payload := triggers.GitHubEventPayload(event, data)
+		payload["delivery_id"] = c.GetHeader("X-GitHub-Delivery")
+		enqueueSCMEvent(c, eng, workflow, payload, config.Events, config.Actions)
+	}
+}
+
+// ReceiveGitLabWebhook verifies a GitLab delivery against the workflow's
+// gitlab_trigger nodes and queues an execution with the mapped event
+func ReceiveGitLabWebhook(eng *engine.Engine, db *storage.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		workflow, body, ok := loadSCMWebhook(c, db)
+		if !ok {
+			return
+		}
+
+		token := c.GetHeader("X-Gitlab-Token")
+		var config *nodes.GitLabTriggerConfig
+		for _, node := range triggerNodes(workflow, "gitlab_trigger") {
+			nodeConfig, err := nodes.ParseGitLabTriggerConfig(node.Config)
+			if err == nil && triggers.VerifyGitLabToken(nodeConfig.Secret, token) {
+				config = nodeConfig
+				break
+			}
+		}
+		if config == nil {
+			c.JSON(401, gin.H{"error": "invalid token"})
+			return
+		}
+
+		var data map[string]interface{}
+		if err := json.Unmarshal(body, &data); err != nil {
+			c.JSON(400, gin.H{"error": "invalid JSON payload"})
+			return
+		}
+
+		payload := triggers.GitLabEventPayload(triggers.GitLabEventName(c.GetHeader("X-Gitlab-Event")), data)
+		payload["delivery_id"] = c.GetHeader("X-Gitlab-Event-UUID")
+		enqueueSCMEvent(c, eng, workflow, payload, config.Events, config.Actions)
+	}
+}
+
+// loadSCMWebhook reads the delivery body and the target workflow, writing an
+// error response and returning false on failure
+func loadSCMWebhook(c *gin.Context, db *storage.DB) (*models.Workflow, []byte, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(400, gin.H{"error": "invalid workflow ID"})
+		return nil, nil, false
+	}
+
+	body, err := io.ReadAll(io.LimitReader(c.Request.Body, maxSCMWebhookSize))
+	if err != nil {
+		c.JSON(400, gin.H{"error": err.Error()})
+		return nil, nil, false
+	}
+
+	workflow, ok := activeWorkflow(c, db, id)
+	if !ok {
+		return nil, nil, false
+	}
+
+	return workflow, body, true
+}
+
+// activeWorkflow loads a workflow for a webhook delivery; only active
+// workflows accept deliveries
+func activeWorkflow(c *gin.Context, db *storage.DB, id uuid.UUID) (*models.Workflow, bool) {
+	workflow, err := db.GetWorkflow(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(404, gin.H{"error": err.Error()})
+		return nil, false
+	}
+	if workflow.Status != models.WorkflowStatusActive {
+		c.JSON(409, gin.H{"error": "workflow is not active"})
+		return nil, false
+	}
+	return workflow, true
+}
+
+// triggerNodes returns the workflow nodes of the given trigger type
+func triggerNodes(workflow *models.Workflow, nodeType string) []models.Node {
+	var matches []models.Node
+	for _, node := range workflow.Definition.Nodes {
+		if node.Type == nodeType {
+			matches = append(matches, node)
+		}
+	}
+	return matches
+}
+
+// enqueueSCMEvent queues an execution for events that pass the trigger
+// filters. Filtered events are acknowledged so the provider does not retry.
+func enqueueSCMEvent(c *gin.Context, eng *engine.Engine, workflow *models.Workflow, payload map[string]interface{}, events, actions []string) {
+	event, _ := payload["event"].(string)
+	action, _ := payload["action"].(string)
+	if !triggers.EventAccepted(events, actions, event, action) {
+		c.JSON(200, gin.H{"status": "ignored"})
+		return
+	}
+
+	// Redelivered events carry the delivery ID of the original
+	ctx := c.Request.Context()
+	if delivery, _ := payload["delivery_id"].(string); delivery != "" {
+		ctx = engine.WithIdempotencyKey(ctx, delivery)
+	}
+	job, err := eng.Enqueue(ctx, workflow.ID.String(), payload)
+	if quotaExceeded(c, err) {
+		return
+	}
+	if err != nil {
+		c.JSON(500, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(202, gin.H{"job_id": job.ID})
+}
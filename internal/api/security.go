@@ -0,0 +1,139 @@
+package api
+
+import (
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CORSConfig controls which browser origins may call the API
+type CORSConfig struct {
+	// AllowedOrigins lists origins such as "https://app.example.com" or
+	// "https://*.example.com"; "*" allows any origin
+	AllowedOrigins []string
+	AllowedMethods []string
+	AllowedHeaders []string
+	// ExposedHeaders lists response headers scripts may read
+	ExposedHeaders []string
+	// AllowCredentials lets browsers send cookies and authorization with
+	// requests. It cannot be combined with the "*" origin.
+	AllowCredentials bool
+	// MaxAge is how long browsers may cache a preflight response
+	MaxAge time.Duration
+}
+
+// CORS answers preflight requests and marks responses to allowed origins.
+// Requests from other origins are served without CORS headers, so browsers
+// do not hand their responses to scripts, and their preflights get 403.
+func CORS(config CORSConfig) gin.HandlerFunc {
+	methods := strings.Join(config.AllowedMethods, ", ")
+	headers := strings.Join(config.AllowedHeaders, ", ")
+	exposed := strings.Join(config.ExposedHeaders, ", ")
+	maxAge := strconv.Itoa(int(config.MaxAge.Seconds()))
+
+	return func(c *gin.Context) {
+		origin := c.GetHeader("Origin")
+		if origin == "" {
+			c.Next()
+			return
+		}
+		preflight := c.Request.Method == http.MethodOptions && c.GetHeader("Access-Control-Request-Method") != ""
+
+		c.Writer.Header().Add("Vary", "Origin")
+		allowed, wildcard := matchOrigin(origin, config.AllowedOrigins)
+		if !allowed {
+			if preflight {
+				c.AbortWithStatus(http.StatusForbidden)
+				return
+			}
+			c.Next()
+			return
+		}
+
+		if wildcard && !config.AllowCredentials {
+			c.Header("Access-Control-Allow-Origin", "*")
+		} else {
+			c.Header("Access-Control-Allow-Origin", origin)
+		}
+		if config.AllowCredentials {
+			c.Header("Access-Control-Allow-Credentials", "true")
+		}
+
+		if !preflight {
+			if exposed != "" {
+				c.Header("Access-Control-Expose-Headers", exposed)
+			}
+			c.Next()
+			return
+		}
+
+		c.Header("Access-Control-Allow-Methods", methods)
+		c.Header("Access-Control-Allow-Headers", headers)
+		if config.MaxAge > 0 {
+			c.Header("Access-Control-Max-Age", maxAge)
+		}
+		c.AbortWithStatus(http.StatusNoContent)
+	}
+}
+
+// matchOrigin reports whether origin is allowed, and whether it was allowed
+// by the "*" entry
+func matchOrigin(origin string, allowed []string) (bool, bool) {
+	origin = strings.ToLower(origin)
+	for _, pattern := range allowed {
+		if pattern == "*" {
+			return true, true
+		}
+		if matched, _ := path.Match(strings.ToLower(pattern), origin); matched {
+			return true, false
+		}
+	}
+	return false, false
+}
+
+// SecurityHeadersConfig sets the security headers added to every response
+type SecurityHeadersConfig struct {
+	// HSTSMaxAge is sent in Strict-Transport-Security; 0 omits the header.
+	// Browsers ignore it on plain HTTP, so set it when TLS terminates in
+	// front of the server.
+	HSTSMaxAge            time.Duration
+	HSTSIncludeSubdomains bool
+	// ContentTypeNosniff sends X-Content-Type-Options: nosniff
+	ContentTypeNosniff bool
+	// FrameOptions is sent in X-Frame-Options, e.g. "DENY"; empty omits it
+	FrameOptions string
+	// ReferrerPolicy is sent in Referrer-Policy; empty omits it
+	ReferrerPolicy string
+}
+
+// SecurityHeaders adds the configured security headers to every response
+func SecurityHeaders(config SecurityHeadersConfig) gin.HandlerFunc {
+	var hsts string
+	if config.HSTSMaxAge > 0 {
+		hsts = "max-age=" + strconv.Itoa(int(config.HSTSMaxAge.Seconds()))
+		if config.HSTSIncludeSubdomains {
+			hsts += "; includeSubDomains"
+		}
+	}
+
+	return func(c *gin.Context) {
+		header := c.Writer.Header()
+		if hsts != "" {
+			header.Set("Strict-Transport-Security", hsts)
+		}
+		if config.ContentTypeNosniff {
+			header.Set("X-Content-Type-Options", "nosniff")
+		}
+		if config.FrameOptions != "" {
+			header.Set("X-Frame-Options", config.FrameOptions)
+		}
+		if config.ReferrerPolicy != "" {
+			header.Set("Referrer-Policy", config.ReferrerPolicy)
+		}
+		c.Next()
+	}
+}
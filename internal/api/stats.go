@@ -0,0 +1,107 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/nuumz/f1ow/internal/models"
+	"github.com/nuumz/f1ow/internal/storage"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+const (
+	defaultStatsWindow = "7d"
+	maxStatsWindow     = 365 * 24 * time.Hour
+	statsCacheTTL      = time.Minute
+)
+
+// GetWorkflowStats returns execution statistics for a workflow over the
+// window given as ?window=24h|7d|30d. Results are cached in Redis briefly;
+// ?refresh=true bypasses the cache.
+func GetWorkflowStats(db *storage.DB, redis *storage.RedisClient) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			c.JSON(400, gin.H{"error": "invalid workflow ID"})
+			return
+		}
+
+		window := c.DefaultQuery("window", defaultStatsWindow)
+		duration, err := parseStatsWindow(window)
+		if err != nil {
+			c.JSON(400, gin.H{"error": err.Error()})
+			return
+		}
+
+		ctx := c.Request.Context()
+		cacheKey := fmt.Sprintf("stats:workflow:%s:%s", id, window)
+
+		if redis != nil && c.Query("refresh") != "true" {
+			if cached, found, err := redis.GetCache(ctx, cacheKey); err == nil && found {
+				c.Data(200, "application/json; charset=utf-8", cached)
+				return
+			}
+		}
+
+		if _, err := db.GetWorkflow(ctx, id); err != nil {
+			c.JSON(404, gin.H{"error": "workflow not found"})
+			return
+		}
+
+		since := time.Now().UTC().Add(-duration)
+		stats, err := db.GetWorkflowStats(ctx, id, since)
+		if err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+		stats.Window = window
+
+		if redis != nil {
+			cacheStats(c, redis, cacheKey, stats)
+		}
+
+		c.JSON(200, stats)
+	}
+}
+
+// cacheStats stores the stats response; failures only cost a recompute
+func cacheStats(c *gin.Context, redis *storage.RedisClient, key string, stats *models.WorkflowStats) {
+	encoded, err := json.Marshal(stats)
+	if err != nil {
+		return
+	}
+	redis.SetCache(c.Request.Context(), key, encoded, statsCacheTTL)
+}
+
+// parseStatsWindow parses windows like 24h, 7d, or 30d
+func parseStatsWindow(window string) (time.Duration, error) {
+	invalid := fmt.Errorf("invalid window %q: use a number followed by h or d, e.g. 24h or 7d", window)
+	if len(window) < 2 {
+		return 0, invalid
+	}
+
+	n, err := strconv.Atoi(window[:len(window)-1])
+	if err != nil || n <= 0 {
+		return 0, invalid
+	}
+
+	var duration time.Duration
+	switch strings.ToLower(window[len(window)-1:]) {
+	case "h":
+		duration = time.Duration(n) * time.Hour
+	case "d":
+		duration = time.Duration(n) * 24 * time.Hour
+	default:
+		return 0, invalid
+	}
+
+	if duration > maxStatsWindow {
+		return 0, fmt.Errorf("window must not exceed 365d")
+	}
+	return duration, nil
+}
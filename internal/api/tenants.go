@@ -0,0 +1,92 @@
+package api
+
+import (
+	"errors"
+
+	"github.com/nuumz/f1ow/internal/models"
+	"github.com/nuumz/f1ow/internal/netpolicy"
+	"github.com/nuumz/f1ow/internal/quota"
+	"github.com/nuumz/f1ow/internal/storage"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// GetTenants lists tenants
+func GetTenants(db *storage.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		tenants, err := db.ListTenants(c.Request.Context())
+		if err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(200, tenants)
+	}
+}
+
+// CreateTenant creates a tenant. Tokens name it through the tenant_id claim.
+func CreateTenant(db *storage.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var t models.Tenant
+		if err := c.ShouldBindJSON(&t); err != nil {
+			c.JSON(400, gin.H{"error": err.Error()})
+			return
+		}
+
+		t.ID = uuid.Nil
+		if t.NetworkPolicy != nil {
+			if err := netpolicy.Validate(*t.NetworkPolicy); err != nil {
+				c.JSON(400, gin.H{"error": err.Error()})
+				return
+			}
+		}
+		if t.Quotas != nil {
+			if err := quota.Validate(*t.Quotas); err != nil {
+				c.JSON(400, gin.H{"error": err.Error()})
+				return
+			}
+		}
+		if err := db.CreateTenant(c.Request.Context(), &t); err != nil {
+			status := 500
+			if errors.Is(err, storage.ErrPlanNotFound) {
+				status = 400
+			}
+			c.JSON(status, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(201, t)
+	}
+}
+
+// UpdateTenantNetworkPolicy replaces the networks a tenant's nodes and
+// webhooks may and may not reach. Workers pick up the change within 30
+// seconds.
+func UpdateTenantNetworkPolicy(db *storage.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			c.JSON(400, gin.H{"error": "invalid tenant ID"})
+			return
+		}
+
+		var policy models.NetworkPolicy
+		if err := c.ShouldBindJSON(&policy); err != nil {
+			c.JSON(400, gin.H{"error": err.Error()})
+			return
+		}
+		if err := netpolicy.Validate(policy); err != nil {
+			c.JSON(400, gin.H{"error": err.Error()})
+			return
+		}
+
+		if err := db.UpdateTenantNetworkPolicy(c.Request.Context(), id, &policy); err != nil {
+			status := 500
+			if errors.Is(err, storage.ErrTenantNotFound) {
+				status = 404
+			}
+			c.JSON(status, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(200, policy)
+	}
+}
@@ -0,0 +1,58 @@
+package api
+
+import (
+	"time"
+
+	"github.com/nuumz/f1ow/internal/storage"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetUsage totals the resources used by the executions that finished
+// between ?from= and ?to= (RFC 3339; the start of this month and now by
+// default), per tenant, or per workflow with ?group_by=workflow. Tenants
+// only see their own usage.
+func GetUsage(db *storage.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		now := time.Now().UTC()
+		opts := storage.UsageReportOptions{
+			From: time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC),
+			To:   now,
+		}
+		for _, param := range []struct {
+			name  string
+			value *time.Time
+		}{{"from", &opts.From}, {"to", &opts.To}} {
+			raw := c.Query(param.name)
+			if raw == "" {
+				continue
+			}
+			parsed, err := time.Parse(time.RFC3339, raw)
+			if err != nil {
+				c.JSON(400, gin.H{"error": "invalid " + param.name + ": expected an RFC 3339 time"})
+				return
+			}
+			*param.value = parsed.UTC()
+		}
+		if !opts.From.Before(opts.To) {
+			c.JSON(400, gin.H{"error": "from must be before to"})
+			return
+		}
+
+		switch c.DefaultQuery("group_by", "tenant") {
+		case "tenant":
+		case "workflow":
+			opts.ByWorkflow = true
+		default:
+			c.JSON(400, gin.H{"error": "group_by must be tenant or workflow"})
+			return
+		}
+
+		report, err := db.GetUsageReport(c.Request.Context(), opts)
+		if err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(200, report)
+	}
+}
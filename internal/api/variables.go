@@ -0,0 +1,170 @@
+package api
+
+import (
+	"errors"
+
+	"github.com/nuumz/f1ow/internal/engine"
+	"github.com/nuumz/f1ow/internal/models"
+	"github.com/nuumz/f1ow/internal/variables"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// variableRequest is the body of POST /variables and PUT /variables/:id
+type variableRequest struct {
+	Name        string      `json:"name" binding:"required"`
+	Value       interface{} `json:"value"`
+	Secret      bool        `json:"secret"`
+	Environment string      `json:"environment"`
+}
+
+func (r variableRequest) variable() *models.Variable {
+	return &models.Variable{Name: r.Name, Value: r.Value, Secret: r.Secret, Environment: r.Environment}
+}
+
+// variablesManager returns the variables store or writes an error response when it is not configured
+func variablesManager(c *gin.Context, eng *engine.Engine) *variables.Manager {
+	manager := eng.Variables()
+	if manager == nil {
+		c.JSON(503, gin.H{"error": "variables are not configured"})
+	}
+	return manager
+}
+
+// variableError writes the response for a variables manager error
+func variableError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, variables.ErrNotFound):
+		c.JSON(404, gin.H{"error": err.Error()})
+	case errors.Is(err, variables.ErrExists):
+		c.JSON(409, gin.H{"error": err.Error()})
+	case errors.Is(err, variables.ErrInvalidName), errors.Is(err, variables.ErrSecretsDisabled):
+		c.JSON(400, gin.H{"error": err.Error()})
+	default:
+		c.JSON(500, gin.H{"error": err.Error()})
+	}
+}
+
+// GetVariables lists variables. The environment query parameter selects one
+// environment; pass it empty for global variables only. Secret values are
+// never returned.
+func GetVariables(eng *engine.Engine) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		manager := variablesManager(c, eng)
+		if manager == nil {
+			return
+		}
+
+		var environment *string
+		if value, ok := c.GetQuery("environment"); ok {
+			environment = &value
+		}
+
+		list, err := manager.List(c.Request.Context(), environment)
+		if err != nil {
+			variableError(c, err)
+			return
+		}
+		c.JSON(200, list)
+	}
+}
+
+// CreateVariable stores a global or environment variable
+func CreateVariable(eng *engine.Engine) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		manager := variablesManager(c, eng)
+		if manager == nil {
+			return
+		}
+
+		var req variableRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(400, gin.H{"error": err.Error()})
+			return
+		}
+
+		variable, err := manager.Create(c.Request.Context(), req.variable())
+		if err != nil {
+			variableError(c, err)
+			return
+		}
+		c.JSON(201, variable)
+	}
+}
+
+// GetVariable returns a variable
+func GetVariable(eng *engine.Engine) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		manager := variablesManager(c, eng)
+		if manager == nil {
+			return
+		}
+
+		id, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			c.JSON(400, gin.H{"error": "invalid variable ID"})
+			return
+		}
+
+		variable, err := manager.Get(c.Request.Context(), id)
+		if err != nil {
+			variableError(c, err)
+			return
+		}
+		c.JSON(200, variable)
+	}
+}
+
+// UpdateVariable replaces a variable
+func UpdateVariable(eng *engine.Engine) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		manager := variablesManager(c, eng)
+		if manager == nil {
+			return
+		}
+
+		id, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			c.JSON(400, gin.H{"error": "invalid variable ID"})
+			return
+		}
+
+		var req variableRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(400, gin.H{"error": err.Error()})
+			return
+		}
+
+		variable := req.variable()
+		variable.ID = id
+		updated, err := manager.Update(c.Request.Context(), variable)
+		if err != nil {
+			variableError(c, err)
+			return
+		}
+		c.JSON(200, updated)
+	}
+}
+
+// DeleteVariable removes a variable
+func DeleteVariable(eng *engine.Engine) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		manager := variablesManager(c, eng)
+		if manager == nil {
+			return
+		}
+
+		id, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			c.JSON(400, gin.H{"error": "invalid variable ID"})
+			return
+		}
+
+		if err := manager.Delete(c.Request.Context(), id); err != nil {
+			variableError(c, err)
+			return
+		}
+		c.JSON(200, gin.H{"message": "variable deleted"})
+	}
+}
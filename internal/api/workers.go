@@ -0,0 +1,67 @@
+package api
+
+import (
+	"errors"
+
+	"github.com/nuumz/f1ow/internal/engine"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetWorkers lists the live workers with their capabilities and load
+func GetWorkers(eng *engine.Engine) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		workers, err := eng.Workers(c.Request.Context())
+		if err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(200, workers)
+	}
+}
+
+// GetWorker returns a live worker
+func GetWorker(eng *engine.Engine) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		worker, err := eng.Worker(c.Request.Context(), c.Param("id"))
+		if errors.Is(err, engine.ErrWorkerNotFound) {
+			c.JSON(404, gin.H{"error": err.Error()})
+			return
+		}
+		if err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(200, worker)
+	}
+}
+
+// SendWorkerCommand returns a handler that asks a worker to drain or stop.
+// The worker acts on it at its next heartbeat, so the response is 202.
+func SendWorkerCommand(eng *engine.Engine, command engine.WorkerCommand) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		err := eng.SendWorkerCommand(c.Request.Context(), c.Param("id"), command)
+		if errors.Is(err, engine.ErrWorkerNotFound) {
+			c.JSON(404, gin.H{"error": err.Error()})
+			return
+		}
+		if err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(202, gin.H{"message": "worker " + string(command) + " requested"})
+	}
+}
+
+// GetQueueStats returns the queue backlog and fleet load that worker
+// autoscalers scale on
+func GetQueueStats(eng *engine.Engine) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		stats, err := eng.QueueStats(c.Request.Context())
+		if err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(200, stats)
+	}
+}
@@ -0,0 +1,85 @@
+package api
+
+import (
+	"errors"
+
+	"github.com/nuumz/f1ow/internal/models"
+	"github.com/nuumz/f1ow/internal/storage"
+
+	"github.com/gin-gonic/gin"
+)
+
+// stateRequest is the body of PUT /workflows/:id/state/:key
+type stateRequest struct {
+	Value interface{} `json:"value"`
+}
+
+// GetWorkflowState returns the value a workflow keeps under :key
+func GetWorkflowState(db *storage.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		workflow, ok := workflowParam(c, db)
+		if !ok {
+			return
+		}
+
+		state, err := db.GetWorkflowState(c.Request.Context(), workflow.ID, c.Param("key"))
+		if err != nil {
+			stateError(c, err)
+			return
+		}
+		c.JSON(200, state)
+	}
+}
+
+// SetWorkflowState stores a value under :key for the workflow's executions
+// to read
+func SetWorkflowState(db *storage.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req stateRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(400, gin.H{"error": err.Error()})
+			return
+		}
+		if req.Value == nil {
+			c.JSON(400, gin.H{"error": "value is required"})
+			return
+		}
+
+		workflow, ok := workflowParam(c, db)
+		if !ok {
+			return
+		}
+
+		state := &models.WorkflowState{WorkflowID: workflow.ID, Key: c.Param("key"), Value: req.Value}
+		if err := db.SetWorkflowState(c.Request.Context(), state); err != nil {
+			stateError(c, err)
+			return
+		}
+		c.JSON(200, state)
+	}
+}
+
+// DeleteWorkflowState removes the value a workflow keeps under :key
+func DeleteWorkflowState(db *storage.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		workflow, ok := workflowParam(c, db)
+		if !ok {
+			return
+		}
+
+		if err := db.DeleteWorkflowState(c.Request.Context(), workflow.ID, c.Param("key")); err != nil {
+			stateError(c, err)
+			return
+		}
+		c.JSON(200, gin.H{"message": "state deleted"})
+	}
+}
+
+// stateError writes the response for a workflow state error
+func stateError(c *gin.Context, err error) {
+	if errors.Is(err, storage.ErrStateNotFound) || errors.Is(err, storage.ErrWorkflowNotFound) {
+		c.JSON(404, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(500, gin.H{"error": err.Error()})
+}
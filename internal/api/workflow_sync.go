@@ -0,0 +1,298 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/nuumz/f1ow/internal/diff"
+	"github.com/nuumz/f1ow/internal/engine"
+	"github.com/nuumz/f1ow/internal/models"
+	"github.com/nuumz/f1ow/internal/storage"
+	"github.com/nuumz/f1ow/internal/tenant"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// syncSourceKey is the metadata key naming the sync source that manages a
+// workflow
+const syncSourceKey = "sync_source"
+
+// Actions a sync takes on a workflow
+const (
+	syncCreate     = "create"
+	syncUpdate     = "update"
+	syncDeactivate = "deactivate"
+	syncUnchanged  = "unchanged"
+)
+
+// syncRequest is the body of POST /workflows/sync
+type syncRequest struct {
+	Source    string            `json:"source"`    // names the workflows this sync manages; "default" when empty
+	Workflows []models.Workflow `json:"workflows"` // the desired workflows, matched to existing ones by name
+	DryRun    bool              `json:"dry_run"`   // report the changes without making them
+}
+
+// syncChange is what a sync did, or would do, to one workflow
+type syncChange struct {
+	Name    string        `json:"name"`
+	ID      string        `json:"id,omitempty"` // empty for a workflow a dry run would create
+	Action  string        `json:"action"`       // create, update, deactivate, or unchanged
+	Changes []diff.Change `json:"changes,omitempty"`
+}
+
+// syncResponse lists the changes of a sync in request order, followed by
+// the workflows it deactivated
+type syncResponse struct {
+	DryRun  bool         `json:"dry_run"`
+	Changes []syncChange `json:"changes"`
+}
+
+// syncedFields are the fields of a workflow a sync sets, compared to find
+// what an update changes
+type syncedFields struct {
+	Description string                    `json:"description"`
+	Tags        []string                  `json:"tags"`
+	Metadata    map[string]interface{}    `json:"metadata"`
+	ProjectID   *uuid.UUID                `json:"project_id"`
+	Status      models.WorkflowStatus     `json:"status"`
+	Definition  models.WorkflowDefinition `json:"definition"`
+}
+
+func syncedFieldsOf(workflow *models.Workflow) syncedFields {
+	return syncedFields{
+		Description: workflow.Description,
+		Tags:        workflow.Tags,
+		Metadata:    workflow.Metadata,
+		ProjectID:   workflow.ProjectID,
+		Status:      workflow.Status,
+		Definition:  workflow.Definition,
+	}
+}
+
+// plannedSync is a change and the workflows it applies to
+type plannedSync struct {
+	syncChange
+	existing *models.Workflow // nil for a create
+	desired  *models.Workflow // nil for a deactivate
+}
+
+// SyncWorkflows makes the workflows of a source match the request, for
+// managing workflows as files: workflows are matched by name, missing ones
+// are created, changed ones updated, and active workflows of the source
+// that are no longer in the request are deactivated. A workflow with a
+// desired name but no source is adopted. Every definition is validated
+// before anything changes.
+func SyncWorkflows(eng *engine.Engine, db *storage.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req syncRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(400, gin.H{"error": err.Error()})
+			return
+		}
+		if req.Source == "" {
+			req.Source = "default"
+		}
+
+		names := make(map[string]bool, len(req.Workflows))
+		for i := range req.Workflows {
+			workflow := &req.Workflows[i]
+			switch {
+			case workflow.Name == "":
+				c.JSON(400, gin.H{"error": fmt.Sprintf("workflow %d has no name", i+1)})
+				return
+			case names[workflow.Name]:
+				c.JSON(400, gin.H{"error": fmt.Sprintf("workflow %q appears more than once", workflow.Name)})
+				return
+			case workflow.Status != "" && workflow.Status != models.WorkflowStatusDraft && workflow.Status != models.WorkflowStatusActive:
+				c.JSON(400, gin.H{"error": fmt.Sprintf("workflow %q must be draft or active", workflow.Name)})
+				return
+			}
+			names[workflow.Name] = true
+
+			if err := eng.ValidateWorkflow(workflow); err != nil {
+				var validationErr *engine.ValidationError
+				if errors.As(err, &validationErr) {
+					c.JSON(422, validationErrorResponse{
+						Error:  fmt.Sprintf("invalid workflow definition of %q", workflow.Name),
+						Errors: validationErr.Errors,
+					})
+				} else {
+					c.JSON(500, gin.H{"error": err.Error()})
+				}
+				return
+			}
+			if issues := eng.LintWorkflow(workflow, ""); hasLintErrors(issues) {
+				c.JSON(422, lintErrorResponse{Error: fmt.Sprintf("workflow %q has lint errors", workflow.Name), Issues: issues})
+				return
+			}
+			if !projectExists(c, db, workflow.ProjectID) {
+				return
+			}
+		}
+
+		existing, _, err := db.ListWorkflows(c.Request.Context(), storage.WorkflowListOptions{})
+		if err != nil {
+			c.JSON(500, gin.H{"error": err.Error()})
+			return
+		}
+		plan, err := planSync(req, existing)
+		if err != nil {
+			c.JSON(409, gin.H{"error": err.Error()})
+			return
+		}
+		if n := activations(plan); n > 0 && !checkActivation(c, eng, tenant.IDOrDefault(c.Request.Context()), n) {
+			return
+		}
+
+		resp := syncResponse{DryRun: req.DryRun, Changes: make([]syncChange, 0, len(plan))}
+		for _, step := range plan {
+			if !req.DryRun {
+				if err := applySync(c, eng, db, &step); err != nil {
+					c.JSON(500, gin.H{"error": fmt.Sprintf("failed to %s workflow %q: %v", step.Action, step.Name, err)})
+					return
+				}
+			}
+			resp.Changes = append(resp.Changes, step.syncChange)
+		}
+		c.JSON(200, resp)
+	}
+}
+
+// planSync works out the change to each desired workflow and the
+// workflows of the source to deactivate
+func planSync(req syncRequest, existing []models.Workflow) ([]plannedSync, error) {
+	byName := make(map[string][]*models.Workflow)
+	for i := range existing {
+		byName[existing[i].Name] = append(byName[existing[i].Name], &existing[i])
+	}
+
+	plan := make([]plannedSync, 0, len(req.Workflows))
+	for i := range req.Workflows {
+		desired := &req.Workflows[i]
+		current, err := syncTarget(req.Source, byName[desired.Name])
+		if err != nil {
+			return nil, err
+		}
+
+		metadata := make(map[string]interface{}, len(desired.Metadata)+1)
+		for key, value := range desired.Metadata {
+			metadata[key] = value
+		}
+		metadata[syncSourceKey] = req.Source
+		desired.Metadata = metadata
+		if desired.Status == "" && desired.IsActive {
+			desired.Status = models.WorkflowStatusActive
+		}
+
+		if current == nil {
+			desired.ID = uuid.Nil
+			if desired.Status == "" {
+				desired.Status = models.WorkflowStatusDraft
+			}
+			plan = append(plan, plannedSync{syncChange: syncChange{Name: desired.Name, Action: syncCreate}, desired: desired})
+			continue
+		}
+
+		if desired.Status == "" {
+			desired.Status = current.Status
+		}
+		step := plannedSync{
+			syncChange: syncChange{Name: desired.Name, ID: current.ID.String(), Action: syncUnchanged},
+			existing:   current,
+			desired:    desired,
+		}
+		if changes := diff.Values(syncedFieldsOf(current), syncedFieldsOf(desired)); len(changes) > 0 {
+			step.Action, step.Changes = syncUpdate, changes
+		}
+		plan = append(plan, step)
+	}
+
+	desiredNames := make(map[string]bool, len(req.Workflows))
+	for _, workflow := range req.Workflows {
+		desiredNames[workflow.Name] = true
+	}
+	for i := range existing {
+		workflow := &existing[i]
+		if workflow.Metadata[syncSourceKey] != req.Source || workflow.Status != models.WorkflowStatusActive || desiredNames[workflow.Name] {
+			continue
+		}
+		plan = append(plan, plannedSync{
+			syncChange: syncChange{Name: workflow.Name, ID: workflow.ID.String(), Action: syncDeactivate},
+			existing:   workflow,
+		})
+	}
+	return plan, nil
+}
+
+// activations returns how many more workflows are active once the plan is
+// applied; negative when fewer are
+func activations(plan []plannedSync) int {
+	n := 0
+	for _, step := range plan {
+		was := step.existing != nil && step.existing.Status == models.WorkflowStatusActive
+		will := step.Action != syncDeactivate && step.desired.Status == models.WorkflowStatusActive
+		switch {
+		case will && !was:
+			n++
+		case was && !will:
+			n--
+		}
+	}
+	return n
+}
+
+// syncTarget picks the existing workflow a desired one replaces among those
+// with its name: the one the source manages, or the only one
+func syncTarget(source string, candidates []*models.Workflow) (*models.Workflow, error) {
+	if len(candidates) == 0 {
+		return nil, nil
+	}
+	var managed []*models.Workflow
+	for _, candidate := range candidates {
+		if candidate.Metadata[syncSourceKey] == source {
+			managed = append(managed, candidate)
+		}
+	}
+	switch {
+	case len(managed) == 1:
+		return managed[0], nil
+	case len(managed) == 0 && len(candidates) == 1:
+		return candidates[0], nil
+	}
+	return nil, fmt.Errorf("%d workflows are named %q; rename or delete all but one", len(candidates), candidates[0].Name)
+}
+
+// applySync makes one planned change, filling in the ID of a created
+// workflow
+func applySync(c *gin.Context, eng *engine.Engine, db *storage.DB, step *plannedSync) error {
+	ctx := c.Request.Context()
+	switch step.Action {
+	case syncCreate:
+		step.desired.UserID = currentUserID(c)
+		if err := db.CreateWorkflow(ctx, step.desired); err != nil {
+			return err
+		}
+		step.ID = step.desired.ID.String()
+		publishWorkflowEvent(c, eng, engine.EventWorkflowCreated, step.desired.ID)
+	case syncUpdate:
+		workflow := *step.desired
+		workflow.ID = step.existing.ID
+		if err := db.UpdateWorkflow(ctx, &workflow); err != nil {
+			return err
+		}
+		// UpdateWorkflow keeps the status, which only changes on its own
+		if step.desired.Status != step.existing.Status {
+			if err := db.SetWorkflowStatus(ctx, workflow.ID, step.desired.Status); err != nil {
+				return err
+			}
+		}
+		publishWorkflowEvent(c, eng, engine.EventWorkflowUpdated, workflow.ID)
+	case syncDeactivate:
+		if err := db.SetWorkflowStatus(ctx, step.existing.ID, models.WorkflowStatusDraft); err != nil {
+			return err
+		}
+		publishWorkflowEvent(c, eng, engine.EventWorkflowUpdated, step.existing.ID)
+	}
+	return nil
+}
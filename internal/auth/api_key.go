@@ -0,0 +1,66 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// APIKeyPrefix starts every API key so keys are recognizable in headers and
+// secret scanners
+const APIKeyPrefix = "f1ow_"
+
+// API key scopes
+const (
+	ScopeRead    = "read"    // GET requests
+	ScopeWrite   = "write"   // create, update, and delete
+	ScopeExecute = "execute" // run workflows
+)
+
+// ValidScopes lists the scopes an API key may be granted
+var ValidScopes = []string{ScopeRead, ScopeWrite, ScopeExecute}
+
+// GenerateAPIKey returns a new random key, the prefix shown in listings, and
+// the hash to store
+func GenerateAPIKey() (key, prefix, hash string, err error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", "", "", fmt.Errorf("failed to generate API key: %w", err)
+	}
+	key = APIKeyPrefix + hex.EncodeToString(secret)
+	return key, key[:len(APIKeyPrefix)+8], HashAPIKey(key), nil
+}
+
+// HashAPIKey returns the stored form of a key. Keys are long and random, so
+// a fast hash is enough.
+func HashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// IsAPIKey reports whether a credential looks like an API key rather than a JWT
+func IsAPIKey(credential string) bool {
+	return strings.HasPrefix(credential, APIKeyPrefix)
+}
+
+// ValidateScopes checks that scopes is non-empty and only contains known scopes
+func ValidateScopes(scopes []string) error {
+	if len(scopes) == 0 {
+		return fmt.Errorf("at least one scope is required")
+	}
+	for _, scope := range scopes {
+		valid := false
+		for _, known := range ValidScopes {
+			if scope == known {
+				valid = true
+				break
+			}
+		}
+		if !valid {
+			return fmt.Errorf("unknown scope %q; valid scopes are %s", scope, strings.Join(ValidScopes, ", "))
+		}
+	}
+	return nil
+}
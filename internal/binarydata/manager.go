@@ -0,0 +1,164 @@
+package binarydata
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"path"
+	"time"
+
+	"github.com/nuumz/f1ow/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+)
+
+// Index records binary data metadata; it is implemented by storage.DB
+type Index interface {
+	SaveBinaryData(ctx context.Context, data *models.BinaryData) error
+	GetBinaryData(ctx context.Context, id uuid.UUID) (*models.BinaryData, error)
+	ListOrphanedBinaryData(ctx context.Context, limit int) ([]models.BinaryData, error)
+	DeleteBinaryData(ctx context.Context, id uuid.UUID) error
+}
+
+// Config selects and configures the binary data store
+type Config struct {
+	Storage  string // filesystem, s3, or redis
+	Path     string
+	RedisTTL time.Duration
+	S3       S3Config
+}
+
+// NewStore creates the store selected by cfg
+func NewStore(cfg Config, redisClient redis.Cmdable) (Store, error) {
+	switch cfg.Storage {
+	case "", "filesystem":
+		return NewFilesystemStore(cfg.Path)
+	case "s3":
+		return NewS3Store(cfg.S3)
+	case "redis":
+		if redisClient == nil {
+			return nil, fmt.Errorf("redis binary data storage requires a redis client")
+		}
+		return NewRedisStore(redisClient, cfg.RedisTTL), nil
+	default:
+		return nil, fmt.Errorf("unsupported binary data storage: %s", cfg.Storage)
+	}
+}
+
+// Manager writes and reads binary data and keeps the index in sync with the store
+type Manager struct {
+	store  Store
+	index  Index
+	logger *logrus.Logger
+}
+
+// NewManager creates a binary data manager
+func NewManager(store Store, index Index, logger *logrus.Logger) *Manager {
+	if logger == nil {
+		logger = logrus.New()
+	}
+	return &Manager{store: store, index: index, logger: logger}
+}
+
+// Write streams r into the store and records it against the execution.
+// A size of -1 means the size is unknown.
+func (m *Manager) Write(ctx context.Context, executionID uuid.UUID, fileName, mimeType string, r io.Reader, size int64) (*models.BinaryData, error) {
+	data := &models.BinaryData{
+		ID:          uuid.New(),
+		ExecutionID: executionID,
+		Storage:     m.store.Name(),
+		FileName:    fileName,
+		MimeType:    mimeType,
+		CreatedAt:   time.Now(),
+	}
+	data.StorageKey = path.Join(executionID.String(), data.ID.String())
+
+	written, err := m.store.Put(ctx, data.StorageKey, r, size, mimeType)
+	if err != nil {
+		return nil, fmt.Errorf("failed to store binary data: %w", err)
+	}
+	data.Size = written
+
+	if m.index != nil {
+		if err := m.index.SaveBinaryData(ctx, data); err != nil {
+			m.store.Delete(ctx, data.StorageKey)
+			return nil, fmt.Errorf("failed to record binary data: %w", err)
+		}
+	}
+
+	return data, nil
+}
+
+// Get returns the metadata for id
+func (m *Manager) Get(ctx context.Context, id uuid.UUID) (*models.BinaryData, error) {
+	if m.index == nil {
+		return nil, ErrNotFound
+	}
+	return m.index.GetBinaryData(ctx, id)
+}
+
+// Open returns the metadata and a reader for id
+func (m *Manager) Open(ctx context.Context, id uuid.UUID) (*models.BinaryData, io.ReadCloser, error) {
+	data, err := m.Get(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	reader, err := m.store.Open(ctx, data.StorageKey)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return data, reader, nil
+}
+
+// Cleanup removes binary data whose execution no longer exists and returns
+// the number of entries removed
+func (m *Manager) Cleanup(ctx context.Context) (int, error) {
+	if m.index == nil {
+		return 0, nil
+	}
+
+	removed := 0
+	for {
+		orphans, err := m.index.ListOrphanedBinaryData(ctx, 100)
+		if err != nil {
+			return removed, fmt.Errorf("failed to list orphaned binary data: %w", err)
+		}
+		if len(orphans) == 0 {
+			return removed, nil
+		}
+
+		for _, orphan := range orphans {
+			if err := m.store.Delete(ctx, orphan.StorageKey); err != nil {
+				return removed, fmt.Errorf("failed to delete binary data %s: %w", orphan.ID, err)
+			}
+			if err := m.index.DeleteBinaryData(ctx, orphan.ID); err != nil {
+				return removed, fmt.Errorf("failed to delete binary data %s: %w", orphan.ID, err)
+			}
+			removed++
+		}
+	}
+}
+
+// Run calls Cleanup every interval until ctx is cancelled
+func (m *Manager) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			removed, err := m.Cleanup(ctx)
+			if err != nil {
+				m.logger.Errorf("Binary data cleanup failed: %v", err)
+			} else if removed > 0 {
+				m.logger.Infof("Removed %d orphaned binary data entries", removed)
+			}
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package binarydata
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+)
+
+// previewSize is the number of bytes of an offloaded payload kept inline
+const previewSize = 1024
+
+// OffloadedKey marks a value that was moved to binary data storage
+const OffloadedKey = "offloaded"
+
+// Offload stores value as JSON when its encoding is larger than maxSize and
+// returns a handle carrying a truncated preview in its place. Smaller values
+// are returned unchanged.
+func (m *Manager) Offload(ctx context.Context, executionID uuid.UUID, name string, value interface{}, maxSize int) (interface{}, error) {
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode %s: %w", name, err)
+	}
+	if maxSize <= 0 || len(encoded) <= maxSize {
+		return value, nil
+	}
+
+	data, err := m.Write(ctx, executionID, name+".json", "application/json", bytes.NewReader(encoded), int64(len(encoded)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to offload %s: %w", name, err)
+	}
+
+	ref := RefMap(data)
+	ref[OffloadedKey] = true
+	ref["preview"] = preview(encoded)
+	return ref, nil
+}
+
+// Load returns the original value of an offloaded handle, or the value
+// itself when it was not offloaded
+func (m *Manager) Load(ctx context.Context, value interface{}) (interface{}, error) {
+	if !IsOffloaded(value) {
+		return value, nil
+	}
+	id, ok := IDFromValue(value)
+	if !ok {
+		return nil, fmt.Errorf("offloaded value has no binary data ID")
+	}
+
+	_, reader, err := m.Open(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	content, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read offloaded value: %w", err)
+	}
+
+	var loaded interface{}
+	if err := json.Unmarshal(content, &loaded); err != nil {
+		return nil, fmt.Errorf("failed to decode offloaded value: %w", err)
+	}
+	return loaded, nil
+}
+
+// IsOffloaded reports whether value is a handle produced by Offload
+func IsOffloaded(value interface{}) bool {
+	ref, ok := value.(map[string]interface{})
+	if !ok {
+		return false
+	}
+	offloaded, _ := ref[OffloadedKey].(bool)
+	return offloaded
+}
+
+// preview truncates encoded JSON to previewSize bytes without splitting a
+// UTF-8 sequence
+func preview(encoded []byte) string {
+	if len(encoded) <= previewSize {
+		return string(encoded)
+	}
+	cut := previewSize
+	for cut > 0 && !utf8.RuneStart(encoded[cut]) {
+		cut--
+	}
+	return string(encoded[:cut]) + "..."
+}
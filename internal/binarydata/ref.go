@@ -0,0 +1,54 @@
+package binarydata
+
+import (
+	"context"
+
+	"github.com/nuumz/f1ow/internal/models"
+
+	"github.com/google/uuid"
+)
+
+type contextKey string
+
+const managerKey contextKey = "binary_data_manager"
+
+// WithManager returns a context carrying the binary data manager
+func WithManager(ctx context.Context, manager *Manager) context.Context {
+	return context.WithValue(ctx, managerKey, manager)
+}
+
+// FromContext returns the binary data manager stored in the context, if any
+func FromContext(ctx context.Context) (*Manager, bool) {
+	manager, ok := ctx.Value(managerKey).(*Manager)
+	return manager, ok && manager != nil
+}
+
+// RefMap returns the handle placed in node outputs in place of the content
+func RefMap(data *models.BinaryData) map[string]interface{} {
+	return map[string]interface{}{
+		"binary_id": data.ID.String(),
+		"file_name": data.FileName,
+		"mime_type": data.MimeType,
+		"size":      data.Size,
+	}
+}
+
+// IDFromValue extracts the binary data ID from a handle produced by RefMap
+func IDFromValue(value interface{}) (uuid.UUID, bool) {
+	ref, ok := value.(map[string]interface{})
+	if !ok {
+		return uuid.Nil, false
+	}
+
+	raw, ok := ref["binary_id"].(string)
+	if !ok {
+		return uuid.Nil, false
+	}
+
+	id, err := uuid.Parse(raw)
+	if err != nil {
+		return uuid.Nil, false
+	}
+
+	return id, true
+}
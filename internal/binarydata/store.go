@@ -0,0 +1,154 @@
+package binarydata
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// ErrNotFound is returned when binary content does not exist
+var ErrNotFound = errors.New("binary data not found")
+
+// Store persists binary content under opaque keys
+type Store interface {
+	// Name identifies the store kind, e.g. "filesystem"
+	Name() string
+
+	// Put writes the content read from r and returns its size
+	Put(ctx context.Context, key string, r io.Reader, size int64, mimeType string) (int64, error)
+
+	// Open returns a reader for the content stored under key
+	Open(ctx context.Context, key string) (io.ReadCloser, error)
+
+	// Delete removes the content stored under key
+	Delete(ctx context.Context, key string) error
+}
+
+// FilesystemStore stores binary content as files under a root directory
+type FilesystemStore struct {
+	root string
+}
+
+// NewFilesystemStore creates a filesystem store rooted at root
+func NewFilesystemStore(root string) (*FilesystemStore, error) {
+	if err := os.MkdirAll(root, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create binary data directory: %w", err)
+	}
+	return &FilesystemStore{root: root}, nil
+}
+
+// Name returns the store kind
+func (s *FilesystemStore) Name() string {
+	return "filesystem"
+}
+
+// Put streams content into a file, writing to a temporary file first so
+// readers never observe partial content
+func (s *FilesystemStore) Put(ctx context.Context, key string, r io.Reader, size int64, mimeType string) (int64, error) {
+	path := s.path(key)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return 0, err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".upload-*")
+	if err != nil {
+		return 0, err
+	}
+	defer os.Remove(tmp.Name())
+
+	written, err := io.Copy(tmp, r)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return 0, err
+	}
+
+	return written, nil
+}
+
+// Open opens the file stored under key
+func (s *FilesystemStore) Open(ctx context.Context, key string) (io.ReadCloser, error) {
+	file, err := os.Open(s.path(key))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	return file, err
+}
+
+// Delete removes the file stored under key
+func (s *FilesystemStore) Delete(ctx context.Context, key string) error {
+	err := os.Remove(s.path(key))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+// path maps a key to a file path inside the root
+func (s *FilesystemStore) path(key string) string {
+	return filepath.Join(s.root, filepath.Clean("/"+key))
+}
+
+// RedisStore keeps binary content in Redis. Content is read into memory, so
+// it suits small payloads; entries expire after ttl as a safety net.
+type RedisStore struct {
+	client redis.Cmdable
+	ttl    time.Duration
+}
+
+// NewRedisStore creates a Redis store
+func NewRedisStore(client redis.Cmdable, ttl time.Duration) *RedisStore {
+	return &RedisStore{client: client, ttl: ttl}
+}
+
+// Name returns the store kind
+func (s *RedisStore) Name() string {
+	return "redis"
+}
+
+// Put stores the content under key
+func (s *RedisStore) Put(ctx context.Context, key string, r io.Reader, size int64, mimeType string) (int64, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := s.client.Set(ctx, s.key(key), data, s.ttl).Err(); err != nil {
+		return 0, err
+	}
+
+	return int64(len(data)), nil
+}
+
+// Open returns the content stored under key
+func (s *RedisStore) Open(ctx context.Context, key string) (io.ReadCloser, error) {
+	data, err := s.client.Get(ctx, s.key(key)).Bytes()
+	if err == redis.Nil {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return io.NopCloser(bytes.NewReader(data)), nil
+}
+
+// Delete removes the content stored under key
+func (s *RedisStore) Delete(ctx context.Context, key string) error {
+	return s.client.Del(ctx, s.key(key)).Err()
+}
+
+func (s *RedisStore) key(key string) string {
+	return "binary:" + key
+}
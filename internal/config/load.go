@@ -0,0 +1,194 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// Load reads the configuration: defaults, then the file at path if it is
+// not empty, then environment variables, and validates the result. The
+// file is YAML, or TOML when its name ends in .toml.
+func Load(path string) (*Config, error) {
+	cfg := &Config{}
+	if err := walk(reflect.ValueOf(cfg).Elem(), "", setDefault); err != nil {
+		return nil, err
+	}
+
+	if path != "" {
+		values, err := readFile(path)
+		if err != nil {
+			return nil, err
+		}
+		if err := applyFile(reflect.ValueOf(cfg).Elem(), values, ""); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+	}
+
+	if err := walk(reflect.ValueOf(cfg).Elem(), "", setFromEnv); err != nil {
+		return nil, err
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid configuration:\n%w", err)
+	}
+	return cfg, nil
+}
+
+func readFile(path string) (map[string]interface{}, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	values := map[string]interface{}{}
+	if strings.EqualFold(filepath.Ext(path), ".toml") {
+		err = toml.Unmarshal(data, &values)
+	} else {
+		err = yaml.Unmarshal(data, &values)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+	return values, nil
+}
+
+// walk calls set on every setting of the struct v, with its dotted key
+func walk(v reflect.Value, prefix string, set func(field reflect.Value, tag reflect.StructTag, key string) error) error {
+	for i := 0; i < v.NumField(); i++ {
+		field, info := v.Field(i), v.Type().Field(i)
+		key := prefix + info.Tag.Get("config")
+		if field.Kind() == reflect.Struct && field.Type() != reflect.TypeOf(time.Duration(0)) {
+			if err := walk(field, key+".", set); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := set(field, info.Tag, key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func setDefault(field reflect.Value, tag reflect.StructTag, key string) error {
+	value, ok := tag.Lookup("default")
+	if !ok {
+		return nil
+	}
+	if err := setValue(field, value); err != nil {
+		return fmt.Errorf("default of %s: %w", key, err)
+	}
+	return nil
+}
+
+// setFromEnv overrides a setting with its environment variable. An empty
+// variable is ignored unless its env tag has the empty option.
+func setFromEnv(field reflect.Value, tag reflect.StructTag, key string) error {
+	name, option, _ := strings.Cut(tag.Get("env"), ",")
+	if name == "" {
+		return nil
+	}
+	value, ok := os.LookupEnv(name)
+	if !ok || (value == "" && option != "empty") {
+		return nil
+	}
+	if err := setValue(field, value); err != nil {
+		return fmt.Errorf("invalid %s: %w", name, err)
+	}
+	return nil
+}
+
+// applyFile sets the settings of the struct v found in values, the parsed
+// file's section for v. Unknown keys are rejected so typos are not ignored.
+func applyFile(v reflect.Value, values map[string]interface{}, prefix string) error {
+	fields := make(map[string]int, v.NumField())
+	for i := 0; i < v.NumField(); i++ {
+		fields[v.Type().Field(i).Tag.Get("config")] = i
+	}
+
+	for name, value := range values {
+		i, ok := fields[name]
+		if !ok {
+			return fmt.Errorf("unknown setting %s%s", prefix, name)
+		}
+		field, key := v.Field(i), prefix+name
+
+		if section, ok := value.(map[string]interface{}); ok {
+			if field.Kind() != reflect.Struct || field.Type() == reflect.TypeOf(time.Duration(0)) {
+				return fmt.Errorf("%s is a setting, not a section", key)
+			}
+			if err := applyFile(field, section, key+"."); err != nil {
+				return err
+			}
+			continue
+		}
+		if field.Kind() == reflect.Struct && field.Type() != reflect.TypeOf(time.Duration(0)) {
+			return fmt.Errorf("%s is a section, not a setting", key)
+		}
+
+		raw := fmt.Sprint(value)
+		if list, ok := value.([]interface{}); ok {
+			items := make([]string, len(list))
+			for j, item := range list {
+				items[j] = fmt.Sprint(item)
+			}
+			raw = strings.Join(items, ",")
+		} else if value == nil {
+			raw = ""
+		}
+		if err := setValue(field, raw); err != nil {
+			return fmt.Errorf("invalid %s: %w", key, err)
+		}
+	}
+	return nil
+}
+
+// setValue parses raw into field: a string, bool, int, duration, or
+// comma-separated list
+func setValue(field reflect.Value, raw string) error {
+	switch {
+	case field.Type() == reflect.TypeOf(time.Duration(0)):
+		if raw == "" {
+			field.SetInt(0)
+			return nil
+		}
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			return err
+		}
+		field.SetInt(int64(d))
+	case field.Kind() == reflect.String:
+		field.SetString(raw)
+	case field.Kind() == reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return fmt.Errorf("%q is not true or false", raw)
+		}
+		field.SetBool(b)
+	case field.Kind() == reflect.Int:
+		n, err := strconv.Atoi(raw)
+		if err != nil {
+			return fmt.Errorf("%q is not a whole number", raw)
+		}
+		field.SetInt(int64(n))
+	case field.Kind() == reflect.Slice && field.Type().Elem().Kind() == reflect.String:
+		var items []string
+		for _, item := range strings.Split(raw, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		field.Set(reflect.ValueOf(items))
+	default:
+		return fmt.Errorf("unsupported setting type %s", field.Type())
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+package credentials
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"io"
+)
+
+// Cipher encrypts credential data with AES-256-GCM
+type Cipher struct {
+	aead    cipher.AEAD
+	signKey []byte
+}
+
+// NewCipher derives the encryption and signing keys from secret
+func NewCipher(secret string) (*Cipher, error) {
+	if secret == "" {
+		return nil, fmt.Errorf("encryption key is required")
+	}
+
+	key := sha256.Sum256([]byte("f1ow-credentials-encryption:" + secret))
+	block, err := aes.NewCipher(key[:])
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+
+	signKey := sha256.Sum256([]byte("f1ow-credentials-signing:" + secret))
+	return &Cipher{aead: aead, signKey: signKey[:]}, nil
+}
+
+// Encrypt returns the base64 encoded nonce and ciphertext of plaintext
+func (c *Cipher) Encrypt(plaintext []byte) (string, error) {
+	nonce := make([]byte, c.aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return "", fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	sealed := c.aead.Seal(nonce, nonce, plaintext, nil)
+	return base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// Decrypt reverses Encrypt
+func (c *Cipher) Decrypt(encoded string) ([]byte, error) {
+	sealed, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ciphertext: %w", err)
+	}
+	if len(sealed) < c.aead.NonceSize() {
+		return nil, fmt.Errorf("invalid ciphertext: too short")
+	}
+
+	nonce, ciphertext := sealed[:c.aead.NonceSize()], sealed[c.aead.NonceSize():]
+	plaintext, err := c.aead.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt credential: %w", err)
+	}
+	return plaintext, nil
+}
+
+// Sign returns an HMAC-SHA256 of message, used for OAuth2 state values
+func (c *Cipher) Sign(message string) []byte {
+	mac := hmac.New(sha256.New, c.signKey)
+	mac.Write([]byte(message))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,147 @@
+package credentials
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/nuumz/f1ow/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+// ErrNotFound is returned when a credential does not exist
+var ErrNotFound = errors.New("credential not found")
+
+// Store persists encrypted credentials; it is implemented by storage.DB
+type Store interface {
+	CreateCredential(ctx context.Context, credential *models.Credential) error
+	GetCredential(ctx context.Context, id uuid.UUID) (*models.Credential, error)
+	ListCredentials(ctx context.Context) ([]models.Credential, error)
+	UpdateCredentialData(ctx context.Context, id uuid.UUID, data string) error
+	DeleteCredential(ctx context.Context, id uuid.UUID) error
+}
+
+// Manager encrypts, stores, and resolves credentials
+type Manager struct {
+	store       Store
+	cipher      *Cipher
+	callbackURL string
+	states      StateStore
+	logger      *logrus.Logger
+
+	// refreshMu serializes OAuth2 refreshes so concurrent executions do not
+	// race to rotate the same refresh token
+	refreshMu sync.Mutex
+}
+
+// NewManager creates a credentials manager. callbackURL is the public URL of
+// the OAuth2 callback endpoint registered with providers.
+func NewManager(store Store, cipher *Cipher, callbackURL string, logger *logrus.Logger) *Manager {
+	if logger == nil {
+		logger = logrus.New()
+	}
+	return &Manager{store: store, cipher: cipher, callbackURL: callbackURL, states: NewMemoryStateStore(), logger: logger}
+}
+
+// UseStateStore sets where pending OAuth2 authorizations are kept. Managers
+// keep them in process by default.
+func (m *Manager) UseStateStore(states StateStore) {
+	m.states = states
+}
+
+// Create encrypts data and stores a new credential
+func (m *Manager) Create(ctx context.Context, name, credentialType string, userID uuid.UUID, data map[string]interface{}) (*models.Credential, error) {
+	switch credentialType {
+	case models.CredentialTypeGeneric:
+	case models.CredentialTypeOAuth2:
+		if _, err := oauth2DataFromMap(data); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unsupported credential type: %s", credentialType)
+	}
+
+	credential := &models.Credential{
+		ID:        uuid.New(),
+		Name:      name,
+		Type:      credentialType,
+		UserID:    userID,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+
+	encrypted, err := m.encrypt(data)
+	if err != nil {
+		return nil, err
+	}
+	credential.Data = encrypted
+
+	if err := m.store.CreateCredential(ctx, credential); err != nil {
+		return nil, fmt.Errorf("failed to save credential: %w", err)
+	}
+	return credential, nil
+}
+
+// Get returns credential metadata without decrypting it
+func (m *Manager) Get(ctx context.Context, id uuid.UUID) (*models.Credential, error) {
+	return m.store.GetCredential(ctx, id)
+}
+
+// List returns metadata for all credentials
+func (m *Manager) List(ctx context.Context) ([]models.Credential, error) {
+	return m.store.ListCredentials(ctx)
+}
+
+// Delete removes a credential
+func (m *Manager) Delete(ctx context.Context, id uuid.UUID) error {
+	return m.store.DeleteCredential(ctx, id)
+}
+
+// Resolve returns the fields a node receives for the credential. Generic
+// credentials return their data as stored; OAuth2 credentials return a fresh
+// access token, refreshing it first when it has expired.
+func (m *Manager) Resolve(ctx context.Context, id string) (map[string]interface{}, error) {
+	credentialID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, fmt.Errorf("invalid credential ID: %w", err)
+	}
+
+	credential, err := m.store.GetCredential(ctx, credentialID)
+	if err != nil {
+		return nil, err
+	}
+
+	switch credential.Type {
+	case models.CredentialTypeGeneric:
+		return m.decrypt(credential)
+	case models.CredentialTypeOAuth2:
+		return m.resolveOAuth2(ctx, credential.ID)
+	default:
+		return nil, fmt.Errorf("unsupported credential type: %s", credential.Type)
+	}
+}
+
+func (m *Manager) encrypt(data map[string]interface{}) (string, error) {
+	plaintext, err := json.Marshal(data)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode credential data: %w", err)
+	}
+	return m.cipher.Encrypt(plaintext)
+}
+
+func (m *Manager) decrypt(credential *models.Credential) (map[string]interface{}, error) {
+	plaintext, err := m.cipher.Decrypt(credential.Data)
+	if err != nil {
+		return nil, err
+	}
+	var data map[string]interface{}
+	if err := json.Unmarshal(plaintext, &data); err != nil {
+		return nil, fmt.Errorf("failed to decode credential data: %w", err)
+	}
+	return data, nil
+}
@@ -0,0 +1,307 @@
+package credentials
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/nuumz/f1ow/internal/models"
+	"github.com/nuumz/f1ow/internal/tenant"
+
+	"github.com/google/uuid"
+	"golang.org/x/oauth2"
+)
+
+// stateTTL bounds how long an authorization request may take
+const stateTTL = 10 * time.Minute
+
+// refreshMargin refreshes access tokens shortly before they expire so they
+// remain valid for the duration of a node execution
+const refreshMargin = time.Minute
+
+// OAuth2Data is the stored data of an OAuth2 credential
+type OAuth2Data struct {
+	ClientID     string            `json:"client_id"`
+	ClientSecret string            `json:"client_secret"`
+	AuthURL      string            `json:"auth_url"`
+	TokenURL     string            `json:"token_url"`
+	Scopes       []string          `json:"scopes"`
+	AuthStyle    string            `json:"auth_style"`  // "header", "params", or empty to auto-detect
+	AuthParams   map[string]string `json:"auth_params"` // extra authorize parameters, e.g. access_type=offline
+
+	AccessToken  string    `json:"access_token,omitempty"`
+	RefreshToken string    `json:"refresh_token,omitempty"`
+	TokenType    string    `json:"token_type,omitempty"`
+	Expiry       time.Time `json:"expiry,omitempty"`
+}
+
+func oauth2DataFromMap(data map[string]interface{}) (*OAuth2Data, error) {
+	dataJSON, err := json.Marshal(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal oauth2 data: %w", err)
+	}
+
+	var oauthData OAuth2Data
+	if err := json.Unmarshal(dataJSON, &oauthData); err != nil {
+		return nil, fmt.Errorf("failed to parse oauth2 data: %w", err)
+	}
+
+	if oauthData.ClientID == "" || oauthData.AuthURL == "" || oauthData.TokenURL == "" {
+		return nil, fmt.Errorf("client_id, auth_url, and token_url are required for oauth2 credentials")
+	}
+	return &oauthData, nil
+}
+
+func (d *OAuth2Data) toMap() (map[string]interface{}, error) {
+	dataJSON, err := json.Marshal(d)
+	if err != nil {
+		return nil, err
+	}
+	var data map[string]interface{}
+	err = json.Unmarshal(dataJSON, &data)
+	return data, err
+}
+
+func (m *Manager) oauth2Config(data *OAuth2Data) *oauth2.Config {
+	config := &oauth2.Config{
+		ClientID:     data.ClientID,
+		ClientSecret: data.ClientSecret,
+		Endpoint:     oauth2.Endpoint{AuthURL: data.AuthURL, TokenURL: data.TokenURL},
+		RedirectURL:  m.callbackURL,
+		Scopes:       data.Scopes,
+	}
+	switch data.AuthStyle {
+	case "header":
+		config.Endpoint.AuthStyle = oauth2.AuthStyleInHeader
+	case "params":
+		config.Endpoint.AuthStyle = oauth2.AuthStyleInParams
+	}
+	return config
+}
+
+// AuthorizeURL returns the provider URL that starts the authorization-code
+// flow for an OAuth2 credential on behalf of subject, and the session value
+// the caller must hand to the same browser, e.g. in a cookie. The callback
+// completes only with that session and only once.
+func (m *Manager) AuthorizeURL(ctx context.Context, id uuid.UUID, subject string) (authURL, session string, err error) {
+	credential, data, err := m.loadOAuth2(ctx, id)
+	if err != nil {
+		return "", "", err
+	}
+
+	state, session, err := m.saveState(ctx, credential.ID, subject)
+	if err != nil {
+		return "", "", err
+	}
+
+	var options []oauth2.AuthCodeOption
+	for key, value := range data.AuthParams {
+		options = append(options, oauth2.SetAuthURLParam(key, value))
+	}
+
+	return m.oauth2Config(data).AuthCodeURL(state, options...), session, nil
+}
+
+// CompleteAuthorization exchanges the authorization code returned to the
+// callback and stores the resulting tokens on the credential the pending
+// authorization named by state is for. session must be the value
+// AuthorizeURL returned for it.
+func (m *Manager) CompleteAuthorization(ctx context.Context, state, session, code string) (*models.Credential, error) {
+	pending, err := m.takeState(ctx, state, session)
+	if err != nil {
+		return nil, err
+	}
+	if pending.TenantID != uuid.Nil {
+		ctx = tenant.WithID(ctx, pending.TenantID)
+	}
+
+	credential, data, err := m.loadOAuth2(ctx, pending.CredentialID)
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := m.oauth2Config(data).Exchange(ctx, code)
+	if err != nil {
+		return nil, fmt.Errorf("failed to exchange authorization code: %w", err)
+	}
+
+	if err := m.saveToken(ctx, credential, data, token); err != nil {
+		return nil, err
+	}
+	m.logger.Infof("Authorized oauth2 credential %s for %s", credential.ID, pending.Subject)
+	return credential, nil
+}
+
+// resolveOAuth2 returns node fields for a valid access token, refreshing and
+// persisting a new token when the stored one is about to expire
+func (m *Manager) resolveOAuth2(ctx context.Context, id uuid.UUID) (map[string]interface{}, error) {
+	m.refreshMu.Lock()
+	defer m.refreshMu.Unlock()
+
+	// Reload under the lock in case another execution just refreshed it
+	credential, data, err := m.loadOAuth2(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if data.AccessToken == "" {
+		return nil, fmt.Errorf("oauth2 credential %q has not been authorized; open /api/v1/oauth2/authorize?credential_id=%s", credential.Name, credential.ID)
+	}
+
+	if !data.Expiry.IsZero() && time.Until(data.Expiry) < refreshMargin {
+		if data.RefreshToken == "" {
+			return nil, fmt.Errorf("oauth2 credential %q has expired and has no refresh token; authorize it again", credential.Name)
+		}
+
+		token, err := m.oauth2Config(data).TokenSource(ctx, &oauth2.Token{RefreshToken: data.RefreshToken}).Token()
+		if err != nil {
+			return nil, fmt.Errorf("failed to refresh oauth2 credential %q: %w", credential.Name, err)
+		}
+		if err := m.saveToken(ctx, credential, data, token); err != nil {
+			return nil, err
+		}
+		m.logger.Infof("Refreshed oauth2 credential %s", credential.ID)
+	}
+
+	return oauth2Fields(data), nil
+}
+
+// oauth2Fields maps an access token to the config fields nodes read: the
+// HTTP node's bearer authentication, "token" (GitHub, GitLab, ServiceNow),
+// and "api_token" with bearer auth_type (Jira)
+func oauth2Fields(data *OAuth2Data) map[string]interface{} {
+	tokenType := data.TokenType
+	if tokenType == "" {
+		tokenType = "Bearer"
+	}
+
+	fields := map[string]interface{}{
+		"access_token": data.AccessToken,
+		"token_type":   tokenType,
+		"token":        data.AccessToken,
+		"api_token":    data.AccessToken,
+		"auth_type":    "bearer",
+		"authentication": map[string]interface{}{
+			"type":  "bearer",
+			"token": data.AccessToken,
+		},
+	}
+	if !data.Expiry.IsZero() {
+		fields["expires_at"] = data.Expiry.Format(time.RFC3339)
+	}
+	return fields
+}
+
+func (m *Manager) loadOAuth2(ctx context.Context, id uuid.UUID) (*models.Credential, *OAuth2Data, error) {
+	credential, err := m.store.GetCredential(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	if credential.Type != models.CredentialTypeOAuth2 {
+		return nil, nil, fmt.Errorf("credential %s is not an oauth2 credential", id)
+	}
+
+	raw, err := m.decrypt(credential)
+	if err != nil {
+		return nil, nil, err
+	}
+	data, err := oauth2DataFromMap(raw)
+	if err != nil {
+		return nil, nil, err
+	}
+	return credential, data, nil
+}
+
+func (m *Manager) saveToken(ctx context.Context, credential *models.Credential, data *OAuth2Data, token *oauth2.Token) error {
+	data.AccessToken = token.AccessToken
+	data.TokenType = token.TokenType
+	data.Expiry = token.Expiry
+	// Providers that do not rotate refresh tokens omit them from refresh responses
+	if token.RefreshToken != "" {
+		data.RefreshToken = token.RefreshToken
+	}
+
+	raw, err := data.toMap()
+	if err != nil {
+		return fmt.Errorf("failed to encode oauth2 data: %w", err)
+	}
+	encrypted, err := m.encrypt(raw)
+	if err != nil {
+		return err
+	}
+
+	if err := m.store.UpdateCredentialData(ctx, credential.ID, encrypted); err != nil {
+		return fmt.Errorf("failed to save oauth2 token: %w", err)
+	}
+	credential.Data = encrypted
+	return nil
+}
+
+// pendingAuthorization is what the state of an authorization request
+// refers to until the callback takes it
+type pendingAuthorization struct {
+	CredentialID uuid.UUID `json:"credential_id"`
+	TenantID     uuid.UUID `json:"tenant_id,omitempty"`
+	Subject      string    `json:"subject"`
+	Session      []byte    `json:"session"` // signature of the browser's session value
+}
+
+// saveState stores a pending authorization of credential id for subject
+// under a random state, bound to a random session value
+func (m *Manager) saveState(ctx context.Context, id uuid.UUID, subject string) (state, session string, err error) {
+	if state, err = randomToken(); err != nil {
+		return "", "", err
+	}
+	if session, err = randomToken(); err != nil {
+		return "", "", err
+	}
+
+	pending := pendingAuthorization{CredentialID: id, Subject: subject, Session: m.cipher.Sign(session)}
+	if tenantID, ok := tenant.FromContext(ctx); ok {
+		pending.TenantID = tenantID
+	}
+	value, err := json.Marshal(pending)
+	if err != nil {
+		return "", "", err
+	}
+	if err := m.states.Save(ctx, state, value, stateTTL); err != nil {
+		return "", "", fmt.Errorf("failed to save authorization request: %w", err)
+	}
+	return state, session, nil
+}
+
+// takeState consumes the pending authorization stored under state, which
+// must have been started with session
+func (m *Manager) takeState(ctx context.Context, state, session string) (*pendingAuthorization, error) {
+	if state == "" {
+		return nil, fmt.Errorf("invalid state")
+	}
+	value, ok, err := m.states.Take(ctx, state)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load authorization request: %w", err)
+	}
+	if !ok {
+		return nil, fmt.Errorf("authorization request is unknown, expired, or already used")
+	}
+
+	var pending pendingAuthorization
+	if err := json.Unmarshal(value, &pending); err != nil {
+		return nil, fmt.Errorf("invalid state")
+	}
+	if session == "" || !hmac.Equal(pending.Session, m.cipher.Sign(session)) {
+		return nil, fmt.Errorf("authorization request was started in another session")
+	}
+	return &pending, nil
+}
+
+func randomToken() (string, error) {
+	token := make([]byte, 32)
+	if _, err := rand.Read(token); err != nil {
+		return "", fmt.Errorf("failed to generate state: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(token), nil
+}
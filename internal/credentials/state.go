@@ -0,0 +1,99 @@
+package credentials
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// StateStore keeps pending OAuth2 authorization requests between the
+// authorize redirect and the provider's callback. Each request can be taken
+// once.
+type StateStore interface {
+	// Save stores value under key for ttl
+	Save(ctx context.Context, key string, value []byte, ttl time.Duration) error
+
+	// Take removes and returns the value stored under key, and reports
+	// whether there was one
+	Take(ctx context.Context, key string) ([]byte, bool, error)
+}
+
+// MemoryStateStore keeps pending authorizations in process. The callback
+// must reach the server that started the authorization; deployments with
+// several API servers use a RedisStateStore.
+type MemoryStateStore struct {
+	mu      sync.Mutex
+	entries map[string]memoryState
+}
+
+type memoryState struct {
+	value   []byte
+	expires time.Time
+}
+
+// NewMemoryStateStore creates an in-process state store
+func NewMemoryStateStore() *MemoryStateStore {
+	return &MemoryStateStore{entries: make(map[string]memoryState)}
+}
+
+// Save implements StateStore
+func (s *MemoryStateStore) Save(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	for k, entry := range s.entries {
+		if now.After(entry.expires) {
+			delete(s.entries, k)
+		}
+	}
+	s.entries[key] = memoryState{value: value, expires: now.Add(ttl)}
+	return nil
+}
+
+// Take implements StateStore
+func (s *MemoryStateStore) Take(ctx context.Context, key string) ([]byte, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.entries[key]
+	delete(s.entries, key)
+	if !ok || time.Now().After(entry.expires) {
+		return nil, false, nil
+	}
+	return entry.value, true, nil
+}
+
+// RedisStateStore keeps pending authorizations in Redis keys that expire,
+// so any API server can complete them
+type RedisStateStore struct {
+	client redis.Cmdable
+}
+
+// NewRedisStateStore creates a state store backed by Redis
+func NewRedisStateStore(client redis.Cmdable) *RedisStateStore {
+	return &RedisStateStore{client: client}
+}
+
+// Save implements StateStore
+func (s *RedisStateStore) Save(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	return s.client.Set(ctx, redisStateKey(key), value, ttl).Err()
+}
+
+// Take implements StateStore
+func (s *RedisStateStore) Take(ctx context.Context, key string) ([]byte, bool, error) {
+	value, err := s.client.GetDel(ctx, redisStateKey(key)).Bytes()
+	if err == redis.Nil {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	return value, true, nil
+}
+
+func redisStateKey(key string) string {
+	return "f1ow:oauth2:state:" + key
+}
@@ -0,0 +1,212 @@
+// Package diff compares workflow definitions and arbitrary JSON values.
+package diff
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/nuumz/f1ow/internal/models"
+)
+
+// Change is a field whose value differs. From is nil when the field was
+// added and To is nil when it was removed.
+type Change struct {
+	Path string      `json:"path"`
+	From interface{} `json:"from,omitempty"`
+	To   interface{} `json:"to,omitempty"`
+}
+
+// ElementChange lists the field changes of a node or edge present on both sides
+type ElementChange struct {
+	ID      string   `json:"id"`
+	Changes []Change `json:"changes"`
+}
+
+// WorkflowDiff is the structural difference between two workflow definitions
+type WorkflowDiff struct {
+	NodesAdded   []models.Node   `json:"nodes_added"`
+	NodesRemoved []models.Node   `json:"nodes_removed"`
+	NodesChanged []ElementChange `json:"nodes_changed"`
+	EdgesAdded   []models.Edge   `json:"edges_added"`
+	EdgesRemoved []models.Edge   `json:"edges_removed"`
+	EdgesChanged []ElementChange `json:"edges_changed"`
+	Changes      []Change        `json:"changes"` // variables, settings, and the start node
+}
+
+// Empty reports whether the definitions are equivalent
+func (d *WorkflowDiff) Empty() bool {
+	return len(d.NodesAdded) == 0 && len(d.NodesRemoved) == 0 && len(d.NodesChanged) == 0 &&
+		len(d.EdgesAdded) == 0 && len(d.EdgesRemoved) == 0 && len(d.EdgesChanged) == 0 &&
+		len(d.Changes) == 0
+}
+
+// Workflows returns what changed from one definition to the other. Nodes
+// are matched by ID, and edges by ID or, without one, by their endpoints.
+func Workflows(from, to *models.WorkflowDefinition) *WorkflowDiff {
+	result := &WorkflowDiff{
+		NodesAdded:   []models.Node{},
+		NodesRemoved: []models.Node{},
+		NodesChanged: []ElementChange{},
+		EdgesAdded:   []models.Edge{},
+		EdgesRemoved: []models.Edge{},
+		EdgesChanged: []ElementChange{},
+		Changes:      []Change{},
+	}
+
+	fromNodes := make(map[string]models.Node, len(from.Nodes))
+	for _, node := range from.Nodes {
+		fromNodes[node.ID] = node
+	}
+	toNodes := make(map[string]bool, len(to.Nodes))
+	for _, node := range to.Nodes {
+		toNodes[node.ID] = true
+		previous, ok := fromNodes[node.ID]
+		if !ok {
+			result.NodesAdded = append(result.NodesAdded, node)
+			continue
+		}
+		if changes := Values(previous, node); len(changes) > 0 {
+			result.NodesChanged = append(result.NodesChanged, ElementChange{ID: node.ID, Changes: changes})
+		}
+	}
+	for _, node := range from.Nodes {
+		if !toNodes[node.ID] {
+			result.NodesRemoved = append(result.NodesRemoved, node)
+		}
+	}
+
+	fromEdges := make(map[string]models.Edge, len(from.Edges))
+	for _, edge := range from.Edges {
+		fromEdges[edgeKey(edge)] = edge
+	}
+	toEdges := make(map[string]bool, len(to.Edges))
+	for _, edge := range to.Edges {
+		key := edgeKey(edge)
+		toEdges[key] = true
+		previous, ok := fromEdges[key]
+		if !ok {
+			result.EdgesAdded = append(result.EdgesAdded, edge)
+			continue
+		}
+		if changes := Values(previous, edge); len(changes) > 0 {
+			result.EdgesChanged = append(result.EdgesChanged, ElementChange{ID: key, Changes: changes})
+		}
+	}
+	for _, edge := range from.Edges {
+		if !toEdges[edgeKey(edge)] {
+			result.EdgesRemoved = append(result.EdgesRemoved, edge)
+		}
+	}
+
+	result.Changes = append(result.Changes, prefix("variables", Values(from.Variables, to.Variables))...)
+	result.Changes = append(result.Changes, prefix("settings", Values(from.Settings, to.Settings))...)
+	if from.StartNodeID != to.StartNodeID {
+		result.Changes = append(result.Changes, Change{Path: "start_node_id", From: from.StartNodeID, To: to.StartNodeID})
+	}
+	return result
+}
+
+// Values returns the field-level changes between the JSON encodings of two
+// values. Paths use dots for object fields and brackets for array indexes.
+func Values(from, to interface{}) []Change {
+	changes := []Change{}
+	compare("", normalize(from), normalize(to), &changes)
+	return changes
+}
+
+func compare(path string, from, to interface{}, changes *[]Change) {
+	// A missing object or array is the same as an empty one
+	if isEmpty(from) && isEmpty(to) {
+		return
+	}
+
+	fromMap, fromIsMap := from.(map[string]interface{})
+	toMap, toIsMap := to.(map[string]interface{})
+	if fromIsMap && toIsMap {
+		keys := make(map[string]bool, len(fromMap)+len(toMap))
+		for key := range fromMap {
+			keys[key] = true
+		}
+		for key := range toMap {
+			keys[key] = true
+		}
+		sorted := make([]string, 0, len(keys))
+		for key := range keys {
+			sorted = append(sorted, key)
+		}
+		sort.Strings(sorted)
+		for _, key := range sorted {
+			compare(join(path, key), fromMap[key], toMap[key], changes)
+		}
+		return
+	}
+
+	fromSlice, fromIsSlice := from.([]interface{})
+	toSlice, toIsSlice := to.([]interface{})
+	if fromIsSlice && toIsSlice {
+		for i := 0; i < len(fromSlice) || i < len(toSlice); i++ {
+			var fromItem, toItem interface{}
+			if i < len(fromSlice) {
+				fromItem = fromSlice[i]
+			}
+			if i < len(toSlice) {
+				toItem = toSlice[i]
+			}
+			compare(fmt.Sprintf("%s[%d]", path, i), fromItem, toItem, changes)
+		}
+		return
+	}
+
+	if !reflect.DeepEqual(from, to) {
+		*changes = append(*changes, Change{Path: path, From: from, To: to})
+	}
+}
+
+// normalize converts a value to its generic JSON form so structs and maps
+// compare alike
+func normalize(v interface{}) interface{} {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return v
+	}
+	var result interface{}
+	if err := json.Unmarshal(data, &result); err != nil {
+		return v
+	}
+	return result
+}
+
+func isEmpty(v interface{}) bool {
+	switch v := v.(type) {
+	case nil:
+		return true
+	case map[string]interface{}:
+		return len(v) == 0
+	case []interface{}:
+		return len(v) == 0
+	}
+	return false
+}
+
+func edgeKey(edge models.Edge) string {
+	if edge.ID != "" {
+		return edge.ID
+	}
+	return fmt.Sprintf("%s:%s->%s:%s", edge.Source, edge.SourcePort, edge.Target, edge.TargetPort)
+}
+
+func join(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+func prefix(path string, changes []Change) []Change {
+	for i := range changes {
+		changes[i].Path = join(path, changes[i].Path)
+	}
+	return changes
+}
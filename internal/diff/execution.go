@@ -0,0 +1,136 @@
+package diff
+
+import (
+	"sort"
+	"time"
+
+	"github.com/nuumz/f1ow/internal/models"
+)
+
+// ExecutionDiff compares two executions of a workflow node by node
+type ExecutionDiff struct {
+	A            ExecutionRun `json:"a"`
+	B            ExecutionRun `json:"b"`
+	InputChanges []Change     `json:"input_changes"`
+	Nodes        []NodeDiff   `json:"nodes"` // in the order the nodes started
+	// FirstDivergence is the first node, in that order, whose status or
+	// output differs; empty when none does
+	FirstDivergence string `json:"first_divergence,omitempty"`
+}
+
+// ExecutionRun is one side of an execution comparison
+type ExecutionRun struct {
+	ID         string                 `json:"id"`
+	Status     models.ExecutionStatus `json:"status"`
+	Error      *string                `json:"error,omitempty"`
+	StartedAt  time.Time              `json:"started_at"`
+	DurationMS *int64                 `json:"duration_ms,omitempty"` // nil until the execution finishes
+}
+
+// NodeRun is how a node ran in one of the compared executions
+type NodeRun struct {
+	Status     models.ExecutionStatus `json:"status"`
+	Error      *string                `json:"error,omitempty"`
+	DurationMS *int64                 `json:"duration_ms,omitempty"` // nil until the node finishes
+	Cached     bool                   `json:"cached,omitempty"`
+}
+
+// NodeDiff compares the runs of a node. A or B is nil when the node did not
+// run in that execution.
+type NodeDiff struct {
+	NodeID          string   `json:"node_id"`
+	A               *NodeRun `json:"a"`
+	B               *NodeRun `json:"b"`
+	Changed         bool     `json:"changed"` // the status or output differs
+	DurationDeltaMS *int64   `json:"duration_delta_ms,omitempty"`
+	OutputChanges   []Change `json:"output_changes"`
+}
+
+// Executions returns what changed from execution a to execution b: their
+// inputs, and for every node that ran in either, its status, duration, and
+// output
+func Executions(a, b *models.Execution) *ExecutionDiff {
+	result := &ExecutionDiff{
+		A:            executionRun(a),
+		B:            executionRun(b),
+		InputChanges: Values(a.Input, b.Input),
+		Nodes:        []NodeDiff{},
+	}
+
+	started := make(map[string]time.Time)
+	for _, execution := range []*models.Execution{a, b} {
+		for nodeID, run := range execution.Context.NodeExecutions {
+			if first, ok := started[nodeID]; !ok || run.StartedAt.Before(first) {
+				started[nodeID] = run.StartedAt
+			}
+		}
+	}
+	nodeIDs := make([]string, 0, len(started))
+	for nodeID := range started {
+		nodeIDs = append(nodeIDs, nodeID)
+	}
+	sort.Slice(nodeIDs, func(i, j int) bool {
+		if !started[nodeIDs[i]].Equal(started[nodeIDs[j]]) {
+			return started[nodeIDs[i]].Before(started[nodeIDs[j]])
+		}
+		return nodeIDs[i] < nodeIDs[j]
+	})
+
+	for _, nodeID := range nodeIDs {
+		node := NodeDiff{NodeID: nodeID, OutputChanges: []Change{}}
+		// Outputs are compared field by field, a missing one as empty
+		fromOutput, toOutput := map[string]interface{}{}, map[string]interface{}{}
+		if run, ok := a.Context.NodeExecutions[nodeID]; ok {
+			node.A = nodeRun(run)
+			if run.Output != nil {
+				fromOutput = run.Output
+			}
+		}
+		if run, ok := b.Context.NodeExecutions[nodeID]; ok {
+			node.B = nodeRun(run)
+			if run.Output != nil {
+				toOutput = run.Output
+			}
+		}
+		node.OutputChanges = Values(fromOutput, toOutput)
+		node.Changed = node.A == nil || node.B == nil || node.A.Status != node.B.Status || len(node.OutputChanges) > 0
+		if node.A != nil && node.B != nil && node.A.DurationMS != nil && node.B.DurationMS != nil {
+			delta := *node.B.DurationMS - *node.A.DurationMS
+			node.DurationDeltaMS = &delta
+		}
+		if node.Changed && result.FirstDivergence == "" {
+			result.FirstDivergence = nodeID
+		}
+		result.Nodes = append(result.Nodes, node)
+	}
+	return result
+}
+
+func executionRun(execution *models.Execution) ExecutionRun {
+	return ExecutionRun{
+		ID:         execution.ID.String(),
+		Status:     execution.Status,
+		Error:      execution.Error,
+		StartedAt:  execution.StartedAt,
+		DurationMS: durationMS(execution.StartedAt, execution.CompletedAt),
+	}
+}
+
+func nodeRun(run models.NodeExecution) *NodeRun {
+	return &NodeRun{
+		Status:     run.Status,
+		Error:      run.Error,
+		DurationMS: durationMS(run.StartedAt, run.CompletedAt),
+		Cached:     run.Cached,
+	}
+}
+
+// durationMS returns the milliseconds from start to end, or nil without an
+// end
+func durationMS(start time.Time, end *time.Time) *int64 {
+	if end == nil {
+		return nil
+	}
+	ms := end.Sub(start).Milliseconds()
+	return &ms
+}
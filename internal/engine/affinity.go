@@ -0,0 +1,74 @@
+package engine
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/nuumz/f1ow/internal/models"
+)
+
+// WorkerSelector returns the labels a worker needs to run the workflow: the
+// workflow's worker_labels setting combined with the worker_labels of its
+// enabled nodes. Nodes that need local resources, such as exec or file
+// nodes, pin the whole execution to workers that have them.
+func WorkerSelector(workflow *models.Workflow) (map[string]string, error) {
+	selector := make(map[string]string)
+	for key, value := range workflow.Definition.Settings.WorkerLabels {
+		selector[key] = value
+	}
+	for _, node := range workflow.Definition.Nodes {
+		if node.Disabled {
+			continue
+		}
+		for key, value := range node.WorkerLabels {
+			if current, ok := selector[key]; ok && current != value {
+				return nil, &ValidationError{Errors: []FieldError{{
+					NodeID:  node.ID,
+					Field:   "worker_labels",
+					Message: fmt.Sprintf("requires %s=%s but the workflow already requires %s=%s", key, value, key, current),
+				}}}
+			}
+			selector[key] = value
+		}
+	}
+	return selector, nil
+}
+
+// ParseLabels parses worker labels written as "key=value,key=value"
+func ParseLabels(s string) (map[string]string, error) {
+	labels := make(map[string]string)
+	for _, pair := range strings.Split(s, ",") {
+		if pair = strings.TrimSpace(pair); pair == "" {
+			continue
+		}
+		key, value, ok := strings.Cut(pair, "=")
+		key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+		if !ok || key == "" {
+			return nil, fmt.Errorf("invalid label %q: expected key=value", pair)
+		}
+		labels[key] = value
+	}
+	return labels, nil
+}
+
+// formatLabels writes labels in the form ParseLabels reads, sorted by key
+func formatLabels(labels map[string]string) string {
+	pairs := make([]string, 0, len(labels))
+	for key, value := range labels {
+		pairs = append(pairs, key+"="+value)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
+
+// matchesSelector reports whether labels have every key and value of
+// selector
+func matchesSelector(labels, selector map[string]string) bool {
+	for key, value := range selector {
+		if labels[key] != value {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,209 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/nuumz/f1ow/internal/models"
+	"github.com/nuumz/f1ow/internal/storage"
+	"github.com/nuumz/f1ow/internal/tenant"
+
+	"github.com/google/uuid"
+)
+
+// MaxBatchSize is the most inputs one batch submission takes
+const MaxBatchSize = 1000
+
+// batchRetryDelay is how long a batch's job waits before trying again
+// while the batch is at its concurrency limit
+var batchRetryDelay = time.Second
+
+// ErrBatchWorkflowMismatch is returned when executions are added to a
+// batch of another workflow
+var ErrBatchWorkflowMismatch = errors.New("batch belongs to another workflow")
+
+// BatchOptions configures SubmitBatch
+type BatchOptions struct {
+	// ID adds the executions to this batch, which is created when it does
+	// not exist yet. A new batch is created when unset.
+	ID uuid.UUID
+
+	// Concurrency caps how many of the batch's executions run at once, 0
+	// for no cap. It is set when the batch is created; a workflow with a
+	// concurrency limit caps it at that limit.
+	Concurrency int
+}
+
+// SubmitBatch queues one pending execution of the workflow per input,
+// grouped in a batch, and returns the batch with the executions. The
+// executions skip the workflow's concurrency policy at submission, which
+// would otherwise refuse all but the first few; the batch's concurrency
+// holds them back instead.
+func (e *Engine) SubmitBatch(ctx context.Context, workflowID string, environment string, inputs []map[string]interface{}, opts BatchOptions) (*models.ExecutionBatch, []*models.Execution, error) {
+	if len(inputs) == 0 {
+		return nil, nil, fmt.Errorf("batch has no inputs")
+	}
+	if len(inputs) > MaxBatchSize {
+		return nil, nil, fmt.Errorf("batch has %d inputs; at most %d are allowed", len(inputs), MaxBatchSize)
+	}
+	if opts.Concurrency < 0 {
+		return nil, nil, fmt.Errorf("batch concurrency must be at least 0")
+	}
+
+	workflow, err := e.loadWorkflow(ctx, workflowID, environment)
+	if err != nil {
+		return nil, nil, err
+	}
+	labels, err := WorkerSelector(workflow)
+	if err != nil {
+		return nil, nil, err
+	}
+	ctx = tenant.WithID(ctx, workflow.TenantID)
+	if err := e.quotas.CheckExecutions(ctx, workflow.TenantID, inputs...); err != nil {
+		return nil, nil, err
+	}
+
+	batch, err := e.openBatch(ctx, workflow, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	executions := make([]*models.Execution, 0, len(inputs))
+	executionIDs := make([]uuid.UUID, 0, len(inputs))
+	for _, input := range inputs {
+		execution := e.newExecution(ctx, workflow, input, environment)
+		execution.Status = models.ExecutionStatusPending
+		execution.Metadata["batch_id"] = batch.ID.String()
+		if err := e.db.CreateExecution(ctx, execution); err != nil {
+			e.abandon(ctx, executions, err)
+			return nil, nil, fmt.Errorf("failed to create execution: %w", err)
+		}
+		executions = append(executions, execution)
+		executionIDs = append(executionIDs, execution.ID)
+	}
+
+	// Executions join the batch before they are queued, so the first
+	// workers already count them against its concurrency
+	if err := e.db.AddBatchExecutions(ctx, batch.ID, executionIDs); err != nil {
+		e.abandon(ctx, executions, err)
+		return nil, nil, err
+	}
+
+	for i, execution := range executions {
+		job := &Job{
+			ID:          uuid.New().String(),
+			WorkflowID:  workflowID,
+			ExecutionID: execution.ID.String(),
+			TenantID:    workflow.TenantID.String(),
+			Input:       execution.Input,
+			Metadata:    map[string]interface{}{"batch_id": batch.ID.String()},
+			Labels:      labels,
+			Partition:   JobPartition(workflow, execution.Input),
+		}
+		if environment != "" {
+			job.Metadata["environment"] = environment
+		}
+		if usesPinnedData(ctx) {
+			job.Metadata["pinned_data"] = true
+		}
+		if err := e.queue.Enqueue(ctx, job); err != nil {
+			e.abandon(ctx, executions[i:], err)
+			return nil, nil, err
+		}
+	}
+
+	batch.Total += len(executions)
+	batch.Counts[models.ExecutionStatusPending] += len(executions)
+	batch.CountDone()
+	return batch, executions, nil
+}
+
+// openBatch returns the batch opts names, creating it when it is new
+func (e *Engine) openBatch(ctx context.Context, workflow *models.Workflow, opts BatchOptions) (*models.ExecutionBatch, error) {
+	if opts.ID != uuid.Nil {
+		batch, err := e.db.GetExecutionBatch(ctx, opts.ID)
+		if err == nil {
+			if batch.WorkflowID != workflow.ID {
+				return nil, ErrBatchWorkflowMismatch
+			}
+			return batch, nil
+		}
+		if !errors.Is(err, storage.ErrBatchNotFound) {
+			return nil, err
+		}
+	}
+
+	concurrency := opts.Concurrency
+	if limit := concurrencyLimit(workflow.Definition.Settings); limit > 0 && (concurrency == 0 || concurrency > limit) {
+		concurrency = limit
+	}
+	batch := &models.ExecutionBatch{
+		ID:          opts.ID,
+		WorkflowID:  workflow.ID,
+		Concurrency: concurrency,
+		Counts:      make(map[models.ExecutionStatus]int),
+	}
+	if err := e.db.CreateExecutionBatch(ctx, batch); err != nil {
+		return nil, err
+	}
+	return batch, nil
+}
+
+// abandon fails executions of a batch that could not be queued
+func (e *Engine) abandon(ctx context.Context, executions []*models.Execution, err error) {
+	for _, execution := range executions {
+		e.finish(ctx, execution, err)
+	}
+}
+
+// ExecutionBatch returns a batch with its executions counted by status
+func (e *Engine) ExecutionBatch(ctx context.Context, id uuid.UUID) (*models.ExecutionBatch, error) {
+	return e.db.GetExecutionBatch(ctx, id)
+}
+
+// gateBatch holds a job back while its batch has as many executions
+// running as its concurrency allows, scheduling the job to try again. When
+// the job may run, the returned func must be called once its execution is
+// claimed, so the next job of the batch counts it as running.
+func (e *Engine) gateBatch(ctx context.Context, job *Job, owner string) (release func(), deferred bool, err error) {
+	release = func() {}
+	raw, _ := job.Metadata["batch_id"].(string)
+	if raw == "" {
+		return release, false, nil
+	}
+	batchID, err := uuid.Parse(raw)
+	if err != nil {
+		return nil, false, fmt.Errorf("job %s has invalid batch ID %q", job.ID, raw)
+	}
+
+	batch, err := e.db.GetExecutionBatch(ctx, batchID)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to get batch %s: %w", batchID, err)
+	}
+	if batch.Concurrency == 0 {
+		return release, false, nil
+	}
+
+	// Count again under the batch's lock, so workers taking jobs of the
+	// batch at once cannot all start one
+	release, err = e.lockAdmission(ctx, "batch:"+raw, owner)
+	if err != nil {
+		return nil, false, err
+	}
+	batch, err = e.db.GetExecutionBatch(ctx, batchID)
+	if err != nil {
+		release()
+		return nil, false, fmt.Errorf("failed to get batch %s: %w", batchID, err)
+	}
+	if batch.Counts[models.ExecutionStatusRunning] < batch.Concurrency {
+		return release, false, nil
+	}
+	release()
+
+	if err := e.queue.ScheduleJob(ctx, job, time.Now().Add(batchRetryDelay)); err != nil {
+		return nil, false, fmt.Errorf("failed to defer job %s: %w", job.ID, err)
+	}
+	return nil, true, nil
+}
@@ -0,0 +1,230 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/nuumz/f1ow/internal/models"
+	"github.com/nuumz/f1ow/internal/quota"
+	"github.com/nuumz/f1ow/internal/storage"
+	"github.com/nuumz/f1ow/internal/tenant"
+
+	"github.com/google/uuid"
+)
+
+// executionClaimTTL is how long a worker's claim on an execution lasts
+// without renewal. Claims are renewed at a third of it.
+var executionClaimTTL = 30 * time.Second
+
+// ExecutionClaimer leases executions to workers so each execution runs on
+// one worker at a time
+type ExecutionClaimer interface {
+	// Claim takes the lease on the execution for owner, or renews it if
+	// owner already holds it, and reports whether owner holds it
+	Claim(ctx context.Context, executionID, owner string, ttl time.Duration) (bool, error)
+
+	// Release gives up owner's lease on the execution
+	Release(ctx context.Context, executionID, owner string) error
+}
+
+// RedisExecutionClaimer leases executions through Redis keys that expire
+// unless renewed
+type RedisExecutionClaimer struct {
+	redis *storage.RedisClient
+}
+
+// NewRedisExecutionClaimer creates an execution claimer backed by Redis
+func NewRedisExecutionClaimer(redis *storage.RedisClient) *RedisExecutionClaimer {
+	return &RedisExecutionClaimer{redis: redis}
+}
+
+// Claim takes or renews the lease on the execution for owner
+func (c *RedisExecutionClaimer) Claim(ctx context.Context, executionID, owner string, ttl time.Duration) (bool, error) {
+	held, err := acquireLeaseScript.Run(ctx, c.redis.Client(), []string{executionClaimKey(executionID)}, owner, ttl.Milliseconds()).Int()
+	if err != nil {
+		return false, err
+	}
+	return held == 1, nil
+}
+
+// Release gives up owner's lease on the execution
+func (c *RedisExecutionClaimer) Release(ctx context.Context, executionID, owner string) error {
+	return releaseLeaseScript.Run(ctx, c.redis.Client(), []string{executionClaimKey(executionID)}, owner).Err()
+}
+
+func executionClaimKey(executionID string) string {
+	return "f1ow:executions:claim:" + executionID
+}
+
+// WithExecutionClaimer sets the claimer workers lease executions from.
+// Engines with Redis use a RedisExecutionClaimer by default.
+func WithExecutionClaimer(claimer ExecutionClaimer) Option {
+	return func(e *Engine) {
+		e.claimer = claimer
+	}
+}
+
+// runJob runs the job's execution on behalf of owner. The worker first
+// leases the execution so a job delivered twice runs once, then claims it
+// in the database for a fencing token: if the lease lapses and another
+// worker takes the execution over, this worker's writes are rejected.
+func (e *Engine) runJob(ctx context.Context, job *Job, owner string) error {
+	if job.ExecutionID == "" {
+		// Jobs queued before executions were assigned IDs up front
+		job.ExecutionID = uuid.New().String()
+	}
+	executionID, err := uuid.Parse(job.ExecutionID)
+	if err != nil {
+		return fmt.Errorf("job %s has invalid execution ID %q", job.ID, job.ExecutionID)
+	}
+
+	if e.claimer != nil {
+		claimed, err := e.claimer.Claim(ctx, job.ExecutionID, owner, executionClaimTTL)
+		if err != nil {
+			return fmt.Errorf("failed to claim execution %s: %w", job.ExecutionID, err)
+		}
+		if !claimed {
+			e.logger.Infof("Execution %s is claimed by another worker; skipping job %s", job.ExecutionID, job.ID)
+			return nil
+		}
+		defer func() {
+			if err := e.claimer.Release(context.WithoutCancel(ctx), job.ExecutionID, owner); err != nil {
+				e.logger.Errorf("Failed to release execution %s: %v", job.ExecutionID, err)
+			}
+		}()
+
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithCancel(ctx)
+		defer cancel()
+		go e.renewClaim(ctx, job.ExecutionID, owner, cancel)
+	}
+
+	environment, _ := job.Metadata["environment"].(string)
+	if pinned, _ := job.Metadata["pinned_data"].(bool); pinned {
+		ctx = WithPinnedData(ctx)
+	}
+	if job.IdempotencyKey != "" {
+		ctx = WithIdempotencyKey(ctx, job.IdempotencyKey)
+	}
+
+	// Executions submitted through the API already exist as pending
+	execution, err := e.db.GetExecution(ctx, executionID)
+	if err != nil {
+		execution = nil
+	}
+
+	workflow, err := e.loadWorkflow(ctx, job.WorkflowID, environment)
+	if err != nil {
+		if execution != nil && execution.Status == models.ExecutionStatusPending {
+			e.finish(ctx, execution, err)
+		}
+		return err
+	}
+	ctx = tenant.WithID(ctx, workflow.TenantID)
+
+	if execution == nil {
+		execution = e.newExecution(ctx, workflow, job.Input, environment)
+		execution.ID = executionID
+		execution.Status = models.ExecutionStatusPending
+
+		// Triggered jobs meet the tenant's quotas and the concurrency policy
+		// when they run, so an overlapping schedule tick is skipped rather
+		// than piled up
+		var exceeded *quota.ExceededError
+		if err := e.quotas.CheckExecutions(ctx, workflow.TenantID, job.Input); errors.As(err, &exceeded) {
+			e.logger.Infof("Tenant %s is over its quota; skipping job %s: %v", workflow.TenantID, job.ID, err)
+			return nil
+		} else if err != nil {
+			return err
+		}
+		admitted, err := e.admit(ctx, workflow, executionID)
+		if errors.Is(err, ErrConcurrencyLimit) {
+			e.logger.Infof("Workflow %s is at its concurrency limit; skipping job %s", job.WorkflowID, job.ID)
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		err = e.db.CreateExecution(ctx, execution)
+		admitted()
+		if err != nil {
+			return fmt.Errorf("failed to create execution: %w", err)
+		}
+	}
+
+	admitted, deferred, err := e.gateBatch(ctx, job, owner)
+	if err != nil {
+		return err
+	}
+	if deferred {
+		e.logger.Debugf("Batch of execution %s is at its concurrency limit; deferring job %s", job.ExecutionID, job.ID)
+		return nil
+	}
+	token, err := e.db.ClaimExecution(ctx, executionID, owner)
+	admitted()
+	if errors.Is(err, storage.ErrExecutionNotClaimable) {
+		e.logger.Infof("Execution %s has already finished; skipping job %s", job.ExecutionID, job.ID)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	execution.Status = models.ExecutionStatusRunning
+	execution.ClaimToken = token
+
+	if environment == "" {
+		environment = e.environment
+	}
+	runs := e.nodeRuns(execution)
+	err = e.run(ctx, workflow, execution, environment, runs)
+	if errors.Is(err, errWorkerShutdown) {
+		return err
+	}
+
+	if updateErr := e.db.UpdateExecution(context.WithoutCancel(ctx), execution, runs.unsaved()...); errors.Is(updateErr, storage.ErrExecutionFenced) {
+		e.logger.Warnf("Execution %s was cancelled or taken over by another worker; discarding this run's result", execution.ID)
+	} else if updateErr != nil {
+		e.logger.Errorf("Failed to update execution: %v", updateErr)
+	}
+	return err
+}
+
+// renewClaim keeps owner's lease on the execution until ctx is done, and
+// cancels the run if the lease is lost
+func (e *Engine) renewClaim(ctx context.Context, executionID, owner string, cancel context.CancelFunc) {
+	ticker := time.NewTicker(executionClaimTTL / 3)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			held, err := e.claimer.Claim(ctx, executionID, owner, executionClaimTTL)
+			if err != nil {
+				// Keep running; the lease survives a few failed renewals
+				e.logger.Errorf("Failed to renew claim on execution %s: %v", executionID, err)
+				continue
+			}
+			if !held {
+				e.logger.Warnf("Lost claim on execution %s; cancelling it", executionID)
+				cancel()
+				return
+			}
+		}
+	}
+}
+
+// finish records an execution that failed before it could run
+func (e *Engine) finish(ctx context.Context, execution *models.Execution, err error) {
+	completedAt := time.Now()
+	errStr := err.Error()
+	execution.Status = models.ExecutionStatusFailed
+	execution.CompletedAt = &completedAt
+	execution.Error = &errStr
+	if err := e.db.UpdateExecution(ctx, execution); err != nil {
+		e.logger.Errorf("Failed to update execution: %v", err)
+	}
+}
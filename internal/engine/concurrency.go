@@ -0,0 +1,142 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/nuumz/f1ow/internal/models"
+
+	"github.com/google/uuid"
+)
+
+// ErrConcurrencyLimit is returned for executions a workflow's concurrency
+// policy skips because enough executions are already in progress
+var ErrConcurrencyLimit = errors.New("workflow has reached its concurrency limit")
+
+// errExecutionReplaced is the cancellation cause of executions replaced by
+// a newer one
+var errExecutionReplaced = errors.New("replaced by a newer execution")
+
+// admissionLockTTL bounds how long admitting one execution holds the
+// workflow's admission lock
+var admissionLockTTL = 10 * time.Second
+
+// concurrencyLimit returns how many executions of the workflow may be in
+// progress at once, or 0 for no limit
+func concurrencyLimit(settings models.WorkflowSettings) int {
+	if settings.MaxConcurrency > 0 {
+		return settings.MaxConcurrency
+	}
+	if settings.ConcurrencyPolicy == models.ConcurrencyForbid || settings.ConcurrencyPolicy == models.ConcurrencyReplace {
+		return 1
+	}
+	return 0
+}
+
+func validateConcurrency(settings models.WorkflowSettings) error {
+	if !settings.ConcurrencyPolicy.Valid() {
+		return &ValidationError{Errors: []FieldError{{
+			Field:   "settings.concurrency_policy",
+			Message: "must be one of allow, forbid, replace",
+		}}}
+	}
+	if settings.MaxConcurrency < 0 {
+		return &ValidationError{Errors: []FieldError{{
+			Field:   "settings.max_concurrency",
+			Message: "must be at least 0",
+		}}}
+	}
+	return nil
+}
+
+// admit applies the workflow's concurrency policy to a new execution
+// before it is stored. At the limit, forbid and allow return
+// ErrConcurrencyLimit and replace cancels the oldest executions. The
+// returned func releases the workflow's admission lock and must be called
+// once the execution is stored, so the next admission counts it.
+func (e *Engine) admit(ctx context.Context, workflow *models.Workflow, executionID uuid.UUID) (func(), error) {
+	settings := workflow.Definition.Settings
+	limit := concurrencyLimit(settings)
+	if limit == 0 || e.db == nil {
+		return func() {}, nil
+	}
+
+	release, err := e.lockAdmission(ctx, workflow.ID.String(), executionID.String())
+	if err != nil {
+		return nil, err
+	}
+
+	active, err := e.db.ListActiveExecutions(ctx, workflow.ID)
+	if err != nil {
+		release()
+		return nil, err
+	}
+	if len(active) < limit {
+		return release, nil
+	}
+	if settings.ConcurrencyPolicy != models.ConcurrencyReplace {
+		release()
+		return nil, ErrConcurrencyLimit
+	}
+
+	for _, execution := range active[:len(active)-limit+1] {
+		if err := e.cancelExecution(ctx, execution.ID, errExecutionReplaced); err != nil {
+			release()
+			return nil, err
+		}
+		e.logger.Infof("Execution %s of workflow %s replaced by %s", execution.ID, workflow.ID, executionID)
+	}
+	return release, nil
+}
+
+// lockAdmission serializes admissions of the workflow's executions: across
+// processes through a Redis lease, otherwise within this process
+func (e *Engine) lockAdmission(ctx context.Context, workflowID, owner string) (func(), error) {
+	if e.redis == nil {
+		e.admission.Lock()
+		return e.admission.Unlock, nil
+	}
+
+	key := "f1ow:concurrency:" + workflowID
+	client := e.redis.Client()
+	deadline := time.Now().Add(admissionLockTTL)
+	for {
+		held, err := acquireLeaseScript.Run(ctx, client, []string{key}, owner, admissionLockTTL.Milliseconds()).Int()
+		if err != nil {
+			return nil, fmt.Errorf("failed to lock workflow admission: %w", err)
+		}
+		if held == 1 {
+			return func() {
+				releaseLeaseScript.Run(context.WithoutCancel(ctx), client, []string{key}, owner)
+			}, nil
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("timed out waiting to admit execution of workflow %s", workflowID)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(50 * time.Millisecond):
+		}
+	}
+}
+
+// cancelExecution marks an execution cancelled and stops it. An execution
+// running in this process stops at once; one running on another worker
+// stops when its next node completes and the journal write is fenced off.
+func (e *Engine) cancelExecution(ctx context.Context, id uuid.UUID, cause error) error {
+	if _, err := e.db.CancelExecution(ctx, id, cause.Error()); err != nil {
+		return err
+	}
+
+	e.mu.RLock()
+	cancel, ok := e.running[id.String()]
+	e.mu.RUnlock()
+	if ok {
+		cancel(cause)
+	}
+	return nil
+}
@@ -0,0 +1,40 @@
+package engine
+
+import "context"
+
+type contextKey string
+
+const executionInfoKey contextKey = "execution_info"
+
+// ExecutionInfo identifies the workflow, execution, and node currently running
+type ExecutionInfo struct {
+	WorkflowID  string
+	ExecutionID string
+	NodeID      string
+	UserID      string // owner of the workflow
+}
+
+// WithExecutionInfo returns a context carrying the execution info
+func WithExecutionInfo(ctx context.Context, info ExecutionInfo) context.Context {
+	return context.WithValue(ctx, executionInfoKey, info)
+}
+
+// ExecutionInfoFromContext returns the execution info stored in the context, if any
+func ExecutionInfoFromContext(ctx context.Context) (ExecutionInfo, bool) {
+	info, ok := ctx.Value(executionInfoKey).(ExecutionInfo)
+	return info, ok
+}
+
+const pinnedDataKey contextKey = "pinned_data"
+
+// WithPinnedData returns a context whose executions use the pinned data of
+// nodes that have it instead of running them, for manual test runs
+func WithPinnedData(ctx context.Context) context.Context {
+	return context.WithValue(ctx, pinnedDataKey, true)
+}
+
+// usesPinnedData reports whether executions under ctx use pinned data
+func usesPinnedData(ctx context.Context) bool {
+	pinned, _ := ctx.Value(pinnedDataKey).(bool)
+	return pinned
+}
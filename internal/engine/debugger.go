@@ -0,0 +1,233 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/nuumz/f1ow/internal/models"
+	"github.com/nuumz/f1ow/internal/tenant"
+
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrNotDebugging is returned for executions without a debug session on
+	// this engine
+	ErrNotDebugging = errors.New("execution is not being debugged")
+
+	// ErrNotPaused is returned when a debug command arrives while the
+	// execution is between nodes
+	ErrNotPaused = errors.New("execution is not paused")
+)
+
+// debugPauseTimeout fails a debug execution left paused this long
+var debugPauseTimeout = 30 * time.Minute
+
+// DebugAction tells a paused debug execution how to proceed
+type DebugAction string
+
+const (
+	DebugContinue DebugAction = "continue" // run the node with the prepared input
+	DebugSkip     DebugAction = "skip"     // move on without running the node
+	DebugModify   DebugAction = "modify"   // run the node with the command's input
+	DebugStop     DebugAction = "stop"     // fail the execution
+)
+
+// Valid reports whether a is a known debug action
+func (a DebugAction) Valid() bool {
+	switch a {
+	case DebugContinue, DebugSkip, DebugModify, DebugStop:
+		return true
+	}
+	return false
+}
+
+// DebugCommand resumes a paused debug execution
+type DebugCommand struct {
+	Action DebugAction            `json:"action" binding:"required"`
+	Input  map[string]interface{} `json:"input,omitempty"` // replaces the prepared input for modify
+}
+
+// DebugState describes where a debug execution is
+type DebugState struct {
+	ExecutionID string                 `json:"execution_id"`
+	Paused      bool                   `json:"paused"`
+	NodeID      string                 `json:"node_id,omitempty"`
+	NodeType    string                 `json:"node_type,omitempty"`
+	Input       map[string]interface{} `json:"input,omitempty"`
+	PausedAt    *time.Time             `json:"paused_at,omitempty"`
+}
+
+// debugSession pauses an execution before each node until a command arrives
+type debugSession struct {
+	mu       sync.Mutex
+	state    DebugState
+	commands chan DebugCommand
+}
+
+func newDebugSession(executionID string) *debugSession {
+	return &debugSession{
+		state:    DebugState{ExecutionID: executionID},
+		commands: make(chan DebugCommand, 1),
+	}
+}
+
+// wait pauses before node with its prepared input and returns the command
+// that resumes it
+func (s *debugSession) wait(ctx context.Context, node *models.Node, input map[string]interface{}) (DebugCommand, error) {
+	now := time.Now()
+	s.mu.Lock()
+	s.state = DebugState{
+		ExecutionID: s.state.ExecutionID,
+		Paused:      true,
+		NodeID:      node.ID,
+		NodeType:    node.Type,
+		Input:       input,
+		PausedAt:    &now,
+	}
+	s.mu.Unlock()
+
+	defer func() {
+		s.mu.Lock()
+		s.state = DebugState{ExecutionID: s.state.ExecutionID}
+		s.mu.Unlock()
+	}()
+
+	timer := time.NewTimer(debugPauseTimeout)
+	defer timer.Stop()
+
+	select {
+	case command := <-s.commands:
+		return command, nil
+	case <-ctx.Done():
+		return DebugCommand{}, ctx.Err()
+	case <-timer.C:
+		return DebugCommand{}, fmt.Errorf("debug session timed out waiting at node %s", node.ID)
+	}
+}
+
+// send delivers a command to the paused execution
+func (s *debugSession) send(command DebugCommand) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.state.Paused {
+		return ErrNotPaused
+	}
+	select {
+	case s.commands <- command:
+		// Reject further commands until the next pause
+		s.state.Paused = false
+		return nil
+	default:
+		return ErrNotPaused
+	}
+}
+
+func (s *debugSession) snapshot() DebugState {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.state
+}
+
+const debugSessionKey contextKey = "debug_session"
+
+func withDebugSession(ctx context.Context, session *debugSession) context.Context {
+	return context.WithValue(ctx, debugSessionKey, session)
+}
+
+func debugSessionFromContext(ctx context.Context) *debugSession {
+	session, _ := ctx.Value(debugSessionKey).(*debugSession)
+	return session
+}
+
+// Debug starts an execution that pauses before each node until it receives
+// a DebugCommand. It returns once the execution is stored; the execution
+// runs in the background on this engine, which holds the debug session.
+func (e *Engine) Debug(ctx context.Context, workflowID string, input map[string]interface{}) (*models.Execution, error) {
+	wfID, err := uuid.Parse(workflowID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid workflow ID: %w", err)
+	}
+
+	workflow, err := e.db.GetWorkflow(ctx, wfID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get workflow: %w", err)
+	}
+
+	ctx = tenant.WithID(ctx, workflow.TenantID)
+	if err := e.quotas.CheckExecutions(ctx, workflow.TenantID, input); err != nil {
+		return nil, err
+	}
+	execution := e.newExecution(ctx, workflow, input, e.environment)
+	execution.Metadata["debug"] = true
+	if err := e.db.CreateExecution(ctx, execution); err != nil {
+		return nil, fmt.Errorf("failed to create execution: %w", err)
+	}
+
+	session := newDebugSession(execution.ID.String())
+	e.mu.Lock()
+	e.debugSessions[execution.ID.String()] = session
+	e.mu.Unlock()
+
+	started := *execution
+
+	// The execution outlives the request that started it
+	ctx = withDebugSession(context.WithoutCancel(ctx), session)
+	go func() {
+		defer func() {
+			e.mu.Lock()
+			delete(e.debugSessions, execution.ID.String())
+			e.mu.Unlock()
+		}()
+
+		runs := e.nodeRuns(execution)
+		if err := e.run(ctx, workflow, execution, e.environment, runs); err != nil {
+			e.logger.Infof("Debug execution %s failed: %v", execution.ID, err)
+		}
+		if err := e.db.UpdateExecution(ctx, execution, runs.unsaved()...); err != nil {
+			e.logger.Errorf("Failed to update execution: %v", err)
+		}
+	}()
+
+	return &started, nil
+}
+
+// DebugState returns where a debug execution running on this engine is
+func (e *Engine) DebugState(executionID string) (DebugState, error) {
+	session, err := e.debugSession(executionID)
+	if err != nil {
+		return DebugState{}, err
+	}
+	return session.snapshot(), nil
+}
+
+// SendDebugCommand resumes a paused debug execution
+func (e *Engine) SendDebugCommand(executionID string, command DebugCommand) error {
+	if !command.Action.Valid() {
+		return fmt.Errorf("invalid debug action: %s", command.Action)
+	}
+	if command.Action == DebugModify && command.Input == nil {
+		return fmt.Errorf("modify requires an input")
+	}
+
+	session, err := e.debugSession(executionID)
+	if err != nil {
+		return err
+	}
+	return session.send(command)
+}
+
+func (e *Engine) debugSession(executionID string) (*debugSession, error) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	session, ok := e.debugSessions[executionID]
+	if !ok {
+		return nil, ErrNotDebugging
+	}
+	return session, nil
+}
@@ -2,28 +2,57 @@ package engine
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"sync"
 	"time"
 
+	"github.com/nuumz/f1ow/internal/binarydata"
+	"github.com/nuumz/f1ow/internal/credentials"
 	"github.com/nuumz/f1ow/internal/models"
+	"github.com/nuumz/f1ow/internal/netpolicy"
+	"github.com/nuumz/f1ow/internal/quota"
+	"github.com/nuumz/f1ow/internal/ratelimit"
 	"github.com/nuumz/f1ow/internal/storage"
+	"github.com/nuumz/f1ow/internal/tenant"
+	"github.com/nuumz/f1ow/internal/usage"
+	"github.com/nuumz/f1ow/internal/variables"
 
 	"github.com/google/uuid"
-	"github.com/prometheus/client_golang/prometheus"
 	"github.com/sirupsen/logrus"
 )
 
 type Engine struct {
-	db           *storage.DB
-	redis        *storage.RedisClient
-	nodeRegistry *NodeRegistry
-	executors    map[string]*Executor
-	queue        *WorkQueue
-	metrics      *Metrics
-	logger       *logrus.Logger
-	mu           sync.RWMutex
-	config       *Config
+	db            storage.Repository
+	redis         *storage.RedisClient
+	resultCache   ResultCache
+	nodeLimits    NodeLimits
+	nodeRegistry  *NodeRegistry
+	executors     map[string]*Executor
+	queue         Queue
+	fairness      Fairness
+	metrics       *Metrics
+	logger        *logrus.Logger
+	mu            sync.RWMutex
+	config        *Config
+	binaryData    *binarydata.Manager
+	credentials   *credentials.Manager
+	rateLimiter   *ratelimit.Limiter
+	networkPolicy *netpolicy.Policy
+	quotas        *quota.Enforcer
+	variables     *variables.Manager
+	environment   string
+	lint          LintOptions
+	events        *eventHub
+	bus           *EventBus // nil without Redis
+	triggers      *TriggerManager
+	debugSessions map[string]*debugSession
+	workers       WorkerRegistry
+	claimer       ExecutionClaimer
+	idempotency   IdempotencyStore
+	workerOptions WorkerOptions
+	running       map[string]context.CancelCauseFunc // executions running in this process by ID
+	admission     sync.Mutex                         // serializes admissions without Redis
 }
 
 type Config struct {
@@ -31,11 +60,12 @@ type Config struct {
 	DefaultTimeout         time.Duration
 	EnableMetrics          bool
 	EnableTracing          bool
+	MaxPayloadSize         int // bytes; larger node outputs are offloaded to binary data storage, 0 = unlimited
 }
 
 type Option func(*Engine)
 
-func WithDatabase(db *storage.DB) Option {
+func WithDatabase(db storage.Repository) Option {
 	return func(e *Engine) {
 		e.db = db
 	}
@@ -59,16 +89,85 @@ func WithLogger(logger *logrus.Logger) Option {
 	}
 }
 
-// NewEngine creates a new workflow engine instance
-func NewEngine(db *storage.DB, redis *storage.RedisClient) *Engine {
+// WithBinaryData sets the manager nodes use to store binary content
+func WithBinaryData(manager *binarydata.Manager) Option {
+	return func(e *Engine) {
+		e.binaryData = manager
+	}
+}
+
+// WithCredentials sets the vault that resolves node credential_id references
+func WithCredentials(manager *credentials.Manager) Option {
+	return func(e *Engine) {
+		e.credentials = manager
+	}
+}
+
+// WithRateLimiter sets the limiter nodes consult before outbound requests
+func WithRateLimiter(limiter *ratelimit.Limiter) Option {
+	return func(e *Engine) {
+		e.rateLimiter = limiter
+	}
+}
+
+// WithNetworkPolicy sets the policy that decides which addresses nodes may
+// connect to
+func WithNetworkPolicy(policy *netpolicy.Policy) Option {
+	return func(e *Engine) {
+		e.networkPolicy = policy
+	}
+}
+
+// WithQuotas sets the enforcer that holds tenants to their quotas
+func WithQuotas(enforcer *quota.Enforcer) Option {
+	return func(e *Engine) {
+		e.quotas = enforcer
+	}
+}
+
+// Quotas returns the enforcer that holds tenants to their quotas, or nil
+// when they have none
+func (e *Engine) Quotas() *quota.Enforcer {
+	return e.quotas
+}
+
+// WithVariables sets the store of global and environment variables nodes
+// read as {{vars.NAME}}
+func WithVariables(manager *variables.Manager) Option {
+	return func(e *Engine) {
+		e.variables = manager
+	}
+}
+
+// WithEnvironment sets the environment executions run in; its variables
+// override global ones
+func WithEnvironment(name string) Option {
+	return func(e *Engine) {
+		e.environment = name
+	}
+}
+
+// WithMaxPayloadSize sets the largest node output, in bytes, persisted inline
+// with the execution. Larger outputs are offloaded to binary data storage.
+func WithMaxPayloadSize(size int) Option {
+	return func(e *Engine) {
+		e.config.MaxPayloadSize = size
+	}
+}
+
+// NewEngine creates a new workflow engine instance. db is usually a
+// *storage.DB; storage.NewMemoryRepository runs it without a database.
+func NewEngine(db storage.Repository, redis *storage.RedisClient, opts ...Option) *Engine {
 	engine := &Engine{
-		db:           db,
-		redis:        redis,
-		nodeRegistry: NewNodeRegistry(),
-		executors:    make(map[string]*Executor),
-		queue:        NewWorkQueue(redis),
-		metrics:      NewMetrics(),
-		logger:       logrus.New(),
+		db:            db,
+		redis:         redis,
+		nodeRegistry:  NewNodeRegistry(),
+		executors:     make(map[string]*Executor),
+		metrics:       sharedMetrics(),
+		logger:        logrus.New(),
+		events:        newEventHub(),
+		debugSessions: make(map[string]*debugSession),
+		running:       make(map[string]context.CancelCauseFunc),
 		config: &Config{
 			MaxConcurrentWorkflows: 100,
 			DefaultTimeout:         30 * time.Minute,
@@ -77,105 +176,612 @@ func NewEngine(db *storage.DB, redis *storage.RedisClient) *Engine {
 		},
 	}
 
-	// Register default metrics with error handling
-	if engine.config.EnableMetrics {
-		metrics := engine.metrics
-		if metrics.WorkflowsTotal != nil {
-			if err := prometheus.Register(metrics.WorkflowsTotal); err != nil {
-				// Already registered, ignore
-			}
-		}
-		if metrics.WorkflowDuration != nil {
-			if err := prometheus.Register(metrics.WorkflowDuration); err != nil {
-				// Already registered, ignore
-			}
+	for _, opt := range opts {
+		opt(engine)
+	}
+	if engine.queue == nil {
+		if redis != nil {
+			engine.queue = NewWorkQueue(redis)
+		} else {
+			engine.queue = NewMemoryQueue(nil)
 		}
-		if metrics.NodeExecutionDuration != nil {
-			if err := prometheus.Register(metrics.NodeExecutionDuration); err != nil {
-				// Already registered, ignore
-			}
+	}
+	if recorder, ok := engine.queue.(metricsRecorder); ok {
+		recorder.setMetrics(engine.metrics)
+	}
+	if fair, ok := engine.queue.(fairQueue); ok && engine.fairness.By != "" {
+		fair.SetFairness(engine.fairness)
+	}
+	if engine.resultCache == nil {
+		if redis != nil {
+			engine.resultCache = redis
+		} else {
+			engine.resultCache = NewMemoryResultCache()
 		}
-		if metrics.ActiveWorkflows != nil {
-			if err := prometheus.Register(metrics.ActiveWorkflows); err != nil {
-				// Already registered, ignore
-			}
+	}
+	if engine.workers == nil && redis != nil {
+		engine.workers = NewRedisWorkerRegistry(redis)
+	}
+	if engine.claimer == nil && redis != nil {
+		engine.claimer = NewRedisExecutionClaimer(redis)
+	}
+	if engine.idempotency == nil && redis != nil {
+		engine.idempotency = NewRedisIdempotencyStore(redis)
+	}
+
+	if engine.redis != nil {
+		engine.bus = NewEventBus(engine.redis, engine.logger)
+		engine.events.subscribe(engine.recordEvent)
+	}
+
+	engine.triggers = NewTriggerManager(func(ctx context.Context, workflowID string, payload map[string]interface{}) error {
+		job, err := engine.Enqueue(ctx, workflowID, payload)
+		if err != nil {
+			return err
 		}
+		engine.PublishEvent(ctx, Event{Type: EventTriggerFired, TenantID: job.TenantID, WorkflowID: workflowID, ExecutionID: job.ExecutionID})
+		return nil
+	}, engine.logger)
+	if engine.networkPolicy != nil {
+		engine.triggers.UseNetworkPolicy(engine.networkPolicy)
 	}
 
 	return engine
 }
 
-// Execute executes a workflow with given input
+// Execute executes a workflow with given input in this process. Servers
+// that share work with workers use Submit instead.
 func (e *Engine) Execute(ctx context.Context, workflowID string, input map[string]interface{}) (*models.Execution, error) {
-	// Parse workflow ID
+	workflow, err := e.loadWorkflow(ctx, workflowID, "")
+	if err != nil {
+		return nil, err
+	}
+	return e.execute(ctx, workflow, input, e.environment)
+}
+
+// ExecuteInEnvironment executes the workflow version deployed to the
+// environment, with the deployment's credential and variable mappings and
+// the environment's variables
+func (e *Engine) ExecuteInEnvironment(ctx context.Context, workflowID string, environment string, input map[string]interface{}) (*models.Execution, error) {
+	workflow, err := e.loadWorkflow(ctx, workflowID, environment)
+	if err != nil {
+		return nil, err
+	}
+	return e.execute(ctx, workflow, input, environment)
+}
+
+// Submit stores a pending execution of the workflow and queues it for a
+// worker, which claims and runs it. With an environment, the worker runs
+// the version deployed there.
+func (e *Engine) Submit(ctx context.Context, workflowID string, environment string, input map[string]interface{}) (*models.Execution, error) {
+	workflow, err := e.loadWorkflow(ctx, workflowID, environment)
+	if err != nil {
+		return nil, err
+	}
+
+	labels, err := WorkerSelector(workflow)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx = tenant.WithID(ctx, workflow.TenantID)
+	execution := e.newExecution(ctx, workflow, input, environment)
+	execution.Status = models.ExecutionStatusPending
+	job := &Job{
+		ID:             uuid.New().String(),
+		WorkflowID:     workflowID,
+		ExecutionID:    execution.ID.String(),
+		TenantID:       workflow.TenantID.String(),
+		Input:          input,
+		Metadata:       map[string]interface{}{},
+		Labels:         labels,
+		Partition:      JobPartition(workflow, input),
+		IdempotencyKey: idempotencyKeyFromContext(ctx),
+	}
+
+	existing, err := e.reserveIdempotencyKey(ctx, workflowID, idempotencyRecord{JobID: job.ID, ExecutionID: job.ExecutionID})
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return e.replayExecution(ctx, workflowID, job.IdempotencyKey, existing)
+	}
+
+	if err := e.quotas.CheckExecutions(ctx, workflow.TenantID, input); err != nil {
+		e.releaseIdempotencyKey(ctx, workflowID)
+		return nil, err
+	}
+	admitted, err := e.admit(ctx, workflow, execution.ID)
+	if err != nil {
+		e.releaseIdempotencyKey(ctx, workflowID)
+		return nil, err
+	}
+	err = e.db.CreateExecution(ctx, execution)
+	admitted()
+	if err != nil {
+		e.releaseIdempotencyKey(ctx, workflowID)
+		return nil, fmt.Errorf("failed to create execution: %w", err)
+	}
+
+	if environment != "" {
+		job.Metadata["environment"] = environment
+	}
+	if usesPinnedData(ctx) {
+		job.Metadata["pinned_data"] = true
+	}
+	if err := e.queue.Enqueue(ctx, job); err != nil {
+		e.finish(ctx, execution, err)
+		e.releaseIdempotencyKey(ctx, workflowID)
+		return nil, err
+	}
+	return execution, nil
+}
+
+// loadWorkflow returns the workflow to run: its current version, or with
+// an environment the version deployed there with the deployment's mappings
+// applied
+func (e *Engine) loadWorkflow(ctx context.Context, workflowID string, environment string) (*models.Workflow, error) {
 	wfID, err := uuid.Parse(workflowID)
 	if err != nil {
 		return nil, fmt.Errorf("invalid workflow ID: %w", err)
 	}
 
-	// Create execution record
+	// Get workflow, scoped to the caller's tenant when there is one
+	workflow, err := e.db.GetWorkflow(ctx, wfID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get workflow: %w", err)
+	}
+	if environment == "" {
+		return workflow, nil
+	}
+
+	deployment, err := e.db.GetDeployment(ctx, wfID, environment)
+	if err != nil {
+		return nil, err
+	}
+	version, err := e.db.GetWorkflowVersion(ctx, wfID, deployment.Version)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get deployed version %d: %w", deployment.Version, err)
+	}
+
+	deployed := *workflow
+	deployed.Version = version.Version
+	deployed.Definition = deployment.Apply(version)
+	return &deployed, nil
+}
+
+// execute stores an execution of the workflow and runs it
+func (e *Engine) execute(ctx context.Context, workflow *models.Workflow, input map[string]interface{}, environment string) (*models.Execution, error) {
+	// Everything the execution touches belongs to the workflow's tenant
+	ctx = tenant.WithID(ctx, workflow.TenantID)
+
+	execution := e.newExecution(ctx, workflow, input, environment)
+	existing, err := e.reserveIdempotencyKey(ctx, workflow.ID.String(), idempotencyRecord{ExecutionID: execution.ID.String()})
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return e.replayExecution(ctx, workflow.ID.String(), idempotencyKeyFromContext(ctx), existing)
+	}
+
+	if err := e.quotas.CheckExecutions(ctx, workflow.TenantID, input); err != nil {
+		e.releaseIdempotencyKey(ctx, workflow.ID.String())
+		return nil, err
+	}
+	admitted, err := e.admit(ctx, workflow, execution.ID)
+	if err != nil {
+		e.releaseIdempotencyKey(ctx, workflow.ID.String())
+		return nil, err
+	}
+	err = e.db.CreateExecution(ctx, execution)
+	admitted()
+	if err != nil {
+		e.releaseIdempotencyKey(ctx, workflow.ID.String())
+		return nil, fmt.Errorf("failed to create execution: %w", err)
+	}
+
+	runs := e.nodeRuns(execution)
+	err = e.run(ctx, workflow, execution, environment, runs)
+
+	if err := e.db.UpdateExecution(ctx, execution, runs.unsaved()...); err != nil {
+		e.logger.Errorf("Failed to update execution: %v", err)
+	}
+
+	return execution, err
+}
+
+// newExecution builds the record of a new running execution
+func (e *Engine) newExecution(ctx context.Context, workflow *models.Workflow, input map[string]interface{}, environment string) *models.Execution {
 	execution := &models.Execution{
 		ID:         uuid.New(),
-		WorkflowID: wfID,
+		WorkflowID: workflow.ID,
 		Status:     models.ExecutionStatusRunning,
 		Input:      input,
 		StartedAt:  time.Now(),
+		TenantID:   workflow.TenantID,
+		Metadata:   map[string]interface{}{"version": workflow.Version},
 	}
-
-	if err := e.db.CreateExecution(ctx, execution); err != nil {
-		return nil, fmt.Errorf("failed to create execution: %w", err)
+	if environment != "" {
+		execution.Metadata["environment"] = environment
+	}
+	if usesPinnedData(ctx) {
+		execution.Metadata["pinned_data"] = true
 	}
+	if key := idempotencyKeyFromContext(ctx); key != "" {
+		execution.Metadata["idempotency_key"] = key
+	}
+	return execution
+}
 
-	// Get workflow
-	workflow, err := e.db.GetWorkflow(ctx, wfID)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get workflow: %w", err)
+// Run executes a workflow definition without storing the workflow or its
+// execution, so embedders can run workflows without a database
+func (e *Engine) Run(ctx context.Context, workflow *models.Workflow, input map[string]interface{}) (*models.Execution, error) {
+	execution := &models.Execution{
+		ID:         uuid.New(),
+		WorkflowID: workflow.ID,
+		Status:     models.ExecutionStatusRunning,
+		Input:      input,
+		StartedAt:  time.Now(),
+		TenantID:   workflow.TenantID,
 	}
 
+	err := e.run(ctx, workflow, execution, e.environment, nil)
+	return execution, err
+}
+
+// run executes the workflow and records the result on the execution. Node
+// runs are collected by runs, which is nil when they are not stored.
+func (e *Engine) run(ctx context.Context, workflow *models.Workflow, execution *models.Execution, environment string, runs *nodeRuns) error {
 	// Create execution context
 	executionCtx := &models.ExecutionContext{
-		Variables: input,
+		Variables: execution.Input,
 	}
 
 	// Create executor
-	executor := NewExecutor(e.nodeRegistry, e.metrics, e.logger)
+	executor := NewExecutor(e.nodeRegistry, e.metrics, e.logger, e.credentials)
+	executor.events = e.events
+	executor.resultCache = e.resultCache
+	executor.limits = e.nodeLimits.forWorkflow(workflow.Definition.Settings)
+	if runs != nil {
+		executor.record = runs.record
+	}
 
-	// Store executor
+	// Store executor; replacing the execution cancels ctx
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
 	e.mu.Lock()
 	e.executors[execution.ID.String()] = executor
+	e.running[execution.ID.String()] = cancel
 	e.mu.Unlock()
+	defer func() {
+		e.mu.Lock()
+		delete(e.executors, execution.ID.String())
+		delete(e.running, execution.ID.String())
+		e.mu.Unlock()
+	}()
+
+	// The tenant's quota bounds how long the execution may run
+	maxDuration, quotaErr := e.quotas.MaxDuration(ctx, workflow.TenantID)
+	if quotaErr != nil {
+		e.logger.Warnf("Failed to load the quotas of execution %s: %v", execution.ID, quotaErr)
+	}
+	if maxDuration > 0 {
+		exceeded := &quota.ExceededError{Quota: quota.ExecutionDuration, Limit: int(maxDuration / time.Second)}
+		var stop context.CancelFunc
+		ctx, stop = context.WithTimeoutCause(ctx, maxDuration, exceeded)
+		defer stop()
+	}
 
 	// Execute workflow
-	result, err := executor.ExecuteWorkflow(ctx, workflow, executionCtx)
+	ctx = WithExecutionInfo(ctx, ExecutionInfo{
+		WorkflowID:  workflow.ID.String(),
+		ExecutionID: execution.ID.String(),
+		UserID:      workflow.UserID.String(),
+	})
+	if e.binaryData != nil {
+		ctx = binarydata.WithManager(ctx, e.binaryData)
+	}
+	if e.rateLimiter != nil {
+		ctx = ratelimit.WithLimiter(ctx, e.rateLimiter)
+	}
+	if e.networkPolicy != nil {
+		ctx = netpolicy.WithPolicy(ctx, e.networkPolicy)
+	}
+	meter := usage.NewMeter()
+	ctx = usage.WithMeter(ctx, meter)
+
+	event := Event{TenantID: tenant.IDOrDefault(ctx).String(), WorkflowID: workflow.ID.String(), ExecutionID: execution.ID.String()}
+	event.Type = EventExecutionStarted
+	e.events.publish(event)
+
+	var (
+		result map[string]interface{}
+		err    error
+	)
+	executor.variables, executor.secrets, err = e.resolveVariables(ctx, workflow, environment)
+	if err == nil {
+		err = e.useJournal(ctx, executor, execution, executionCtx)
+	}
+	if err == nil {
+		result, err = executor.ExecuteWorkflow(ctx, workflow, executionCtx)
+	}
+
+	if cause := context.Cause(ctx); err != nil && errors.Is(cause, errWorkerShutdown) {
+		// The worker requeues the execution and the next one resumes it
+		// from its journal, so it has not failed
+		return cause
+	}
 
 	// Update execution record
+	execution.Context = *executionCtx
 	execution.Status = models.ExecutionStatusCompleted
 	completedAt := time.Now()
 	execution.CompletedAt = &completedAt
+	executionUsage := meter.Usage()
+	execution.Usage = &executionUsage
 
 	if err != nil {
 		execution.Status = models.ExecutionStatusFailed
+		var exceeded *quota.ExceededError
+		switch cause := context.Cause(ctx); {
+		case errors.Is(cause, errExecutionReplaced):
+			execution.Status, err = models.ExecutionStatusCancelled, cause
+		case errors.As(cause, &exceeded):
+			err = cause
+		}
 		errStr := err.Error()
 		execution.Error = &errStr
+		event.Type, event.Error = EventExecutionFailed, errStr
 	} else {
-		execution.Output = result
+		execution.Output = e.offloadOutputs(ctx, execution.ID, result)
+		event.Type, event.Output = EventExecutionCompleted, execution.Output
 	}
+	e.events.publish(event)
+	return err
+}
 
-	if err := e.db.UpdateExecution(ctx, execution); err != nil {
-		e.logger.Errorf("Failed to update execution: %v", err)
+// useJournal makes executions claimed by a worker resumable: the outputs of
+// nodes completed by a previous owner are restored, and each node that
+// completes is journaled
+func (e *Engine) useJournal(ctx context.Context, executor *Executor, execution *models.Execution, executionCtx *models.ExecutionContext) error {
+	if execution.ClaimToken == 0 || e.db == nil {
+		return nil
 	}
 
-	// Clean up executor
-	e.mu.Lock()
-	delete(e.executors, execution.ID.String())
-	e.mu.Unlock()
+	completed, err := e.db.GetJournal(ctx, execution.ID)
+	if err != nil {
+		return err
+	}
+	if len(completed) > 0 {
+		e.logger.Infof("Resuming execution %s after %d completed nodes", execution.ID, len(completed))
+		executionCtx.NodeExecutions = make(map[string]models.NodeExecution, len(completed))
+		for nodeID, output := range completed {
+			outputMap, ports, variables := splitJournal(output)
+			executionCtx.NodeExecutions[nodeID] = models.NodeExecution{
+				NodeID: nodeID,
+				Status: models.ExecutionStatusCompleted,
+				Output: outputMap,
+				Ports:  ports,
+			}
+			if variables != nil {
+				if executor.restored == nil {
+					executor.restored = make(map[string]map[string]interface{})
+				}
+				executor.restored[nodeID] = variables
+			}
+		}
+	}
 
-	return execution, err
+	executor.journal = func(ctx context.Context, nodeID string, output NodeOutput) error {
+		journaled := output.Data
+		if output.Ports != nil || output.Variables != nil {
+			journaled = make(map[string]interface{}, len(output.Data)+2)
+			for key, value := range output.Data {
+				journaled[key] = value
+			}
+			if output.Ports != nil {
+				journaled[journalPortsKey] = output.Ports
+			}
+			if output.Variables != nil {
+				journaled[journalVariablesKey] = output.Variables
+			}
+		}
+		return e.db.AppendJournal(ctx, execution.ID, execution.ClaimToken, nodeID, journaled)
+	}
+	return nil
+}
+
+// journalPortsKey and journalVariablesKey hold the ports a node emitted on
+// and the variables it set in its journaled output, so a resumed execution
+// takes the same edges and sees the same variables
+const (
+	journalPortsKey     = "_f1ow_ports"
+	journalVariablesKey = "_f1ow_variables"
+)
+
+// splitJournal separates a journaled output from the ports and variables
+// stored with it
+func splitJournal(journaled interface{}) (map[string]interface{}, []string, map[string]interface{}) {
+	output, _ := journaled.(map[string]interface{})
+	variables, _ := output[journalVariablesKey].(map[string]interface{})
+	delete(output, journalVariablesKey)
+
+	stored, ok := output[journalPortsKey].([]interface{})
+	if !ok {
+		return output, nil, variables
+	}
+	delete(output, journalPortsKey)
+	ports := make([]string, 0, len(stored))
+	for _, port := range stored {
+		if name, ok := port.(string); ok {
+			ports = append(ports, name)
+		}
+	}
+	return output, ports, variables
+}
+
+// resolveVariables returns the variables the workflow's nodes see as vars,
+// and the values of those that are secret. Workflow variables override
+// those of the environment, which override global ones.
+func (e *Engine) resolveVariables(ctx context.Context, workflow *models.Workflow, environment string) (map[string]interface{}, []string, error) {
+	vars := make(map[string]interface{})
+	secret := make(map[string]bool)
+	if e.variables != nil {
+		tenantID := workflow.TenantID
+		if tenantID == uuid.Nil {
+			tenantID = tenant.DefaultID
+		}
+		stored, secrets, err := e.variables.ResolveWithSecrets(tenant.WithID(ctx, tenantID), environment)
+		if err != nil {
+			return nil, nil, err
+		}
+		for name, value := range stored {
+			vars[name] = value
+		}
+		secret = secrets
+	}
+
+	for name, value := range workflow.Definition.Settings.Variables {
+		vars[name], secret[name] = value, false
+	}
+	for name, value := range workflow.Definition.Variables {
+		vars[name], secret[name] = value, false
+	}
+
+	var secrets []string
+	for name, value := range vars {
+		if s, ok := value.(string); ok && s != "" && secret[name] {
+			secrets = append(secrets, s)
+		}
+	}
+	return vars, secrets, nil
+}
+
+// offloadOutputs moves node outputs larger than the max payload size to
+// binary data storage, leaving a handle with a truncated preview. Outputs
+// are kept inline when no storage is configured or offloading fails.
+func (e *Engine) offloadOutputs(ctx context.Context, executionID uuid.UUID, outputs map[string]interface{}) map[string]interface{} {
+	if e.config.MaxPayloadSize <= 0 || outputs == nil {
+		return outputs
+	}
+	if e.binaryData == nil {
+		e.logger.Warn("Max payload size is set but binary data storage is not configured; outputs are stored inline")
+		return outputs
+	}
+
+	result := make(map[string]interface{}, len(outputs))
+	for nodeID, output := range outputs {
+		offloaded, err := e.binaryData.Offload(ctx, executionID, "output-"+nodeID, output, e.config.MaxPayloadSize)
+		if err != nil {
+			e.logger.Errorf("Failed to offload output of node %s: %v", nodeID, err)
+			offloaded = output
+		}
+		result[nodeID] = offloaded
+	}
+	return result
+}
+
+// Enqueue queues a workflow execution to be processed by a worker
+func (e *Engine) Enqueue(ctx context.Context, workflowID string, input map[string]interface{}) (*Job, error) {
+	if _, err := uuid.Parse(workflowID); err != nil {
+		return nil, fmt.Errorf("invalid workflow ID: %w", err)
+	}
+
+	job := &Job{
+		ID:             uuid.New().String(),
+		WorkflowID:     workflowID,
+		ExecutionID:    uuid.New().String(),
+		Input:          input,
+		IdempotencyKey: idempotencyKeyFromContext(ctx),
+	}
+	if tenantID, ok := tenant.FromContext(ctx); ok {
+		job.TenantID = tenantID.String()
+	}
+
+	// Pin the job to workers with the labels the workflow requires, and
+	// queue it in its partition. Jobs queued without a tenant, such as
+	// those of triggers and webhooks, run in the workflow's tenant.
+	var workflow *models.Workflow
+	if e.db != nil {
+		var err error
+		if workflow, err = e.loadWorkflow(ctx, workflowID, ""); err != nil {
+			return nil, err
+		}
+		if job.TenantID == "" && workflow.TenantID != uuid.Nil {
+			job.TenantID = workflow.TenantID.String()
+		}
+		if job.Labels, err = WorkerSelector(workflow); err != nil {
+			return nil, err
+		}
+		job.Partition = JobPartition(workflow, input)
+	}
+
+	// A repeated idempotency key returns the job queued first, even once
+	// the tenant has reached its quota
+	existing, err := e.reserveIdempotencyKey(ctx, workflowID, idempotencyRecord{JobID: job.ID, ExecutionID: job.ExecutionID})
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return &Job{ID: existing.JobID, WorkflowID: workflowID, TenantID: job.TenantID, ExecutionID: existing.ExecutionID, IdempotencyKey: job.IdempotencyKey}, nil
+	}
+
+	if workflow != nil {
+		if err := e.quotas.CheckExecutions(ctx, workflow.TenantID, input); err != nil {
+			e.releaseIdempotencyKey(ctx, workflowID)
+			return nil, err
+		}
+	}
+
+	if err := e.queue.Enqueue(ctx, job); err != nil {
+		e.releaseIdempotencyKey(ctx, workflowID)
+		return nil, err
+	}
+
+	return job, nil
+}
+
+// Credentials returns the credentials manager, or nil when none is configured
+func (e *Engine) Credentials() *credentials.Manager {
+	return e.credentials
+}
+
+// Variables returns the variables manager, or nil when none is configured
+func (e *Engine) Variables() *variables.Manager {
+	return e.variables
+}
+
+// BinaryData returns the binary data manager, or nil when none is configured
+func (e *Engine) BinaryData() *binarydata.Manager {
+	return e.binaryData
 }
 
 // RegisterNode registers a node type with the engine
-func (e *Engine) RegisterNode(nodeType string, node NodeType) {
-	e.nodeRegistry.Register(nodeType, node)
+func (e *Engine) RegisterNode(nodeType string, node NodeType) error {
+	return e.nodeRegistry.Register(nodeType, node)
+}
+
+// Subscribe calls fn for every execution and node event of executions run
+// by this engine, until the returned function is called. fn runs on the
+// executing goroutine and must not block.
+func (e *Engine) Subscribe(fn func(Event)) (unsubscribe func()) {
+	return e.events.subscribe(fn)
+}
+
+// PublishEvent delivers an event that did not come from an execution, such
+// as a workflow change, to subscribers and the event bus. The event
+// belongs to the context's tenant unless it names one.
+func (e *Engine) PublishEvent(ctx context.Context, event Event) {
+	if event.TenantID == "" {
+		event.TenantID = tenant.IDOrDefault(ctx).String()
+	}
+	e.events.publish(event)
+}
+
+// EventBus returns the bus carrying every instance's events, or nil
+// without Redis
+func (e *Engine) EventBus() *EventBus {
+	return e.bus
 }
 
 // GetAvailableNodes returns all registered node types
@@ -202,40 +808,60 @@ func (e *Engine) GetNodeSchema(nodeType string) (interface{}, error) {
 	}, nil
 }
 
-// StartWorker starts the background worker for processing queued workflows
+// StartWorker runs jobs from the queue until ctx is cancelled or the
+// worker is told to drain or stop through the fleet API. It registers the
+// worker with the registry and waits for in-flight jobs before returning.
 func (e *Engine) StartWorker(ctx context.Context) error {
 	e.logger.Info("Starting workflow engine worker")
 
-	for {
-		select {
-		case <-ctx.Done():
-			e.logger.Info("Worker stopped")
-			return ctx.Err()
-		default:
-			// Process next job from queue
-			job, err := e.queue.Dequeue(ctx)
-			if err != nil {
-				e.logger.Errorf("Failed to dequeue job: %v", err)
-				time.Sleep(time.Second)
-				continue
-			}
-
-			if job != nil {
-				go e.processJob(ctx, job)
-			} else {
-				// No jobs available, wait a bit
-				time.Sleep(100 * time.Millisecond)
-			}
-		}
-	}
+	err := e.newWorker().run(ctx)
+	e.logger.Info("Worker stopped")
+	return err
 }
 
-// processJob processes a single workflow job
-func (e *Engine) processJob(ctx context.Context, job *Job) {
+// processJob processes a single workflow job on behalf of the worker owner
+func (e *Engine) processJob(ctx context.Context, job *Job, owner string) error {
 	e.logger.Infof("Processing job %s for workflow %s", job.ID, job.WorkflowID)
 
-	_, err := e.Execute(ctx, job.WorkflowID, job.Input)
+	if job.TenantID != "" {
+		tenantID, err := uuid.Parse(job.TenantID)
+		if err != nil {
+			e.logger.Errorf("Job %s has invalid tenant ID %q", job.ID, job.TenantID)
+			return err
+		}
+		ctx = tenant.WithID(ctx, tenantID)
+	}
+
+	err := e.runJob(ctx, job, owner)
+	if errors.Is(err, errWorkerShutdown) {
+		e.requeueInterrupted(ctx, job, owner)
+		return err
+	}
 	if err != nil {
 		e.logger.Errorf("Failed to execute workflow %s: %v", job.WorkflowID, err)
 	}
+	return err
+}
+
+// requeueInterrupted returns the job of an execution its worker stopped to
+// the queue. The execution stays claimed by owner until the job is queued,
+// so if queueing fails the reaper requeues it once the worker is gone.
+func (e *Engine) requeueInterrupted(ctx context.Context, job *Job, owner string) {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 5*time.Second)
+	defer cancel()
+
+	if err := e.queue.Enqueue(ctx, job); err != nil {
+		e.logger.Errorf("Failed to requeue execution %s: %v", job.ExecutionID, err)
+		return
+	}
+	e.metrics.ShutdownRequeued.Inc()
+	e.logger.Infof("Requeued execution %s interrupted by worker shutdown", job.ExecutionID)
+
+	// Release the execution so the reaper does not queue it a second time;
+	// a worker that already claimed it is left alone
+	if executionID, err := uuid.Parse(job.ExecutionID); err == nil && e.db != nil {
+		if _, err := e.db.RequeueExecution(ctx, executionID, owner); err != nil {
+			e.logger.Errorf("Failed to release execution %s: %v", job.ExecutionID, err)
+		}
+	}
 }
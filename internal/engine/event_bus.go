@@ -0,0 +1,172 @@
+package engine
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/nuumz/f1ow/internal/storage"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// eventBusStream holds the events of every instance
+	eventBusStream = "f1ow:eventbus"
+	// eventBusMaxLen caps the events kept for consumer groups to catch up on
+	eventBusMaxLen = 100000
+	// eventRedeliverAfter is how long a delivered event may stay
+	// unacknowledged before another consumer of the group takes it over
+	eventRedeliverAfter = time.Minute
+	// eventMaxDeliveries is how often an event is delivered to a group
+	// before it is dropped
+	eventMaxDeliveries = 5
+)
+
+// EventHandler handles an event delivered to a consumer group. Returning
+// an error leaves the event unacknowledged, so it is delivered again.
+type EventHandler func(ctx context.Context, event StreamedEvent) error
+
+// EventBus carries the execution, node, workflow, and trigger events of
+// every instance through a Redis stream. Each consumer group, such as
+// outbound webhooks or an audit log, receives every event published after
+// the group was created; the group's consumers share them, and events a
+// consumer fails to handle or acknowledge are redelivered.
+type EventBus struct {
+	redis  *storage.RedisClient
+	logger *logrus.Logger
+}
+
+// NewEventBus creates an event bus on redis
+func NewEventBus(redis *storage.RedisClient, logger *logrus.Logger) *EventBus {
+	return &EventBus{redis: redis, logger: logger}
+}
+
+// add queues the event on pipe
+func (b *EventBus) add(ctx context.Context, pipe redis.Pipeliner, data []byte) {
+	pipe.XAdd(ctx, &redis.XAddArgs{
+		Stream: eventBusStream,
+		MaxLen: eventBusMaxLen,
+		Approx: true,
+		Values: map[string]interface{}{"event": data},
+	})
+}
+
+// Consume delivers the bus's events to handle as consumer of group until
+// ctx is done. Consumers of one group share its events; give each a name
+// that is stable across restarts so its unacknowledged events are
+// redelivered to it.
+func (b *EventBus) Consume(ctx context.Context, group, consumer string, handle EventHandler) error {
+	client := b.redis.Client()
+	err := client.XGroupCreateMkStream(ctx, eventBusStream, group, "$").Err()
+	if err != nil && !strings.HasPrefix(err.Error(), "BUSYGROUP") {
+		return fmt.Errorf("failed to create consumer group %s: %w", group, err)
+	}
+
+	// Start with this consumer's own unacknowledged events
+	pending := "0"
+	lastClaim := time.Time{}
+	for ctx.Err() == nil {
+		if time.Since(lastClaim) >= eventRedeliverAfter/2 {
+			b.claimStale(ctx, group, consumer, handle)
+			lastClaim = time.Now()
+		}
+
+		streams, err := client.XReadGroup(ctx, &redis.XReadGroupArgs{
+			Group:    group,
+			Consumer: consumer,
+			Streams:  []string{eventBusStream, pending},
+			Count:    50,
+			Block:    5 * time.Second,
+		}).Result()
+		if errors.Is(err, redis.Nil) {
+			continue
+		}
+		if err != nil {
+			if ctx.Err() != nil {
+				break
+			}
+			b.logger.Errorf("Failed to read events for consumer group %s: %v", group, err)
+			time.Sleep(time.Second)
+			continue
+		}
+
+		delivered := 0
+		for _, stream := range streams {
+			delivered += len(stream.Messages)
+			for _, message := range stream.Messages {
+				b.deliver(ctx, group, message, handle)
+			}
+		}
+		if pending != ">" && delivered == 0 {
+			pending = ">"
+		}
+	}
+	return ctx.Err()
+}
+
+// claimStale takes over the group's events that other consumers received
+// but did not acknowledge in time, dropping those delivered too often
+func (b *EventBus) claimStale(ctx context.Context, group, consumer string, handle EventHandler) {
+	client := b.redis.Client()
+	pending, err := client.XPendingExt(ctx, &redis.XPendingExtArgs{
+		Stream: eventBusStream,
+		Group:  group,
+		Idle:   eventRedeliverAfter,
+		Start:  "-",
+		End:    "+",
+		Count:  50,
+	}).Result()
+	if err != nil {
+		b.logger.Errorf("Failed to list pending events of consumer group %s: %v", group, err)
+		return
+	}
+
+	var ids []string
+	for _, entry := range pending {
+		if entry.RetryCount >= eventMaxDeliveries {
+			b.logger.Errorf("Dropping event %s for consumer group %s after %d deliveries", entry.ID, group, entry.RetryCount)
+			client.XAck(ctx, eventBusStream, group, entry.ID)
+			continue
+		}
+		ids = append(ids, entry.ID)
+	}
+	if len(ids) == 0 {
+		return
+	}
+
+	messages, err := client.XClaim(ctx, &redis.XClaimArgs{
+		Stream:   eventBusStream,
+		Group:    group,
+		Consumer: consumer,
+		MinIdle:  eventRedeliverAfter,
+		Messages: ids,
+	}).Result()
+	if err != nil {
+		b.logger.Errorf("Failed to claim pending events of consumer group %s: %v", group, err)
+		return
+	}
+	for _, message := range messages {
+		b.deliver(ctx, group, message, handle)
+	}
+}
+
+// deliver hands a message to handle and acknowledges it once handled.
+// Messages that cannot be parsed are acknowledged so they are not retried.
+func (b *EventBus) deliver(ctx context.Context, group string, message redis.XMessage, handle EventHandler) {
+	streamed := StreamedEvent{ID: message.ID}
+	data, _ := message.Values["event"].(string)
+	if err := json.Unmarshal([]byte(data), &streamed.Event); err != nil {
+		b.logger.Errorf("Dropping unreadable event %s: %v", message.ID, err)
+	} else if err := handle(ctx, streamed); err != nil {
+		b.logger.Warnf("Consumer group %s failed to handle event %s: %v", group, message.ID, err)
+		return
+	}
+	if err := b.redis.Client().XAck(ctx, eventBusStream, group, message.ID).Err(); err != nil {
+		b.logger.Errorf("Failed to acknowledge event %s for consumer group %s: %v", message.ID, group, err)
+	}
+}
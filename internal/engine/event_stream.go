@@ -0,0 +1,103 @@
+package engine
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// eventStreamMaxLen caps the events kept per execution
+	eventStreamMaxLen = 1000
+	// eventStreamTTL is how long an execution's events stay replayable
+	// after its last event
+	eventStreamTTL = 24 * time.Hour
+)
+
+// ErrEventStreamUnavailable is returned when reading execution events
+// without Redis
+var ErrEventStreamUnavailable = errors.New("execution event streams need Redis")
+
+// StreamedEvent is an execution event and its position in the execution's
+// event stream
+type StreamedEvent struct {
+	ID    string
+	Event Event
+}
+
+func eventStreamKey(executionID string) string {
+	return "f1ow:events:" + executionID
+}
+
+// recordEvent appends the event to its execution's stream, so clients of
+// any instance can follow executions run by this one, and to the event
+// bus. It runs on the publishing goroutine, so a failure is logged rather
+// than failing the execution.
+func (e *Engine) recordEvent(event Event) {
+	data, err := json.Marshal(event)
+	if err != nil {
+		e.logger.Errorf("Failed to marshal %s event: %v", event.Type, err)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	_, err = e.redis.Client().TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		if event.ExecutionID != "" {
+			key := eventStreamKey(event.ExecutionID)
+			pipe.XAdd(ctx, &redis.XAddArgs{
+				Stream: key,
+				MaxLen: eventStreamMaxLen,
+				Approx: true,
+				Values: map[string]interface{}{"event": data},
+			})
+			pipe.Expire(ctx, key, eventStreamTTL)
+		}
+		e.bus.add(ctx, pipe, data)
+		return nil
+	})
+	if err != nil {
+		e.logger.Errorf("Failed to record %s event: %v", event.Type, err)
+	}
+}
+
+// ExecutionEvents returns the execution's events recorded after the event
+// with ID after, or from the start when after is empty. When there are
+// none it waits up to block for more, returning none if block passes.
+func (e *Engine) ExecutionEvents(ctx context.Context, executionID, after string, block time.Duration) ([]StreamedEvent, error) {
+	if e.redis == nil {
+		return nil, ErrEventStreamUnavailable
+	}
+	if after == "" {
+		after = "0"
+	}
+
+	streams, err := e.redis.Client().XRead(ctx, &redis.XReadArgs{
+		Streams: []string{eventStreamKey(executionID), after},
+		Count:   100,
+		Block:   block,
+	}).Result()
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read execution events: %w", err)
+	}
+
+	var events []StreamedEvent
+	for _, stream := range streams {
+		for _, message := range stream.Messages {
+			data, _ := message.Values["event"].(string)
+			streamed := StreamedEvent{ID: message.ID}
+			if err := json.Unmarshal([]byte(data), &streamed.Event); err != nil {
+				return nil, fmt.Errorf("failed to parse execution event %s: %w", message.ID, err)
+			}
+			events = append(events, streamed)
+		}
+	}
+	return events, nil
+}
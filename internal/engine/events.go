@@ -0,0 +1,83 @@
+package engine
+
+import (
+	"sync"
+	"time"
+)
+
+// EventType identifies what happened in an execution
+type EventType string
+
+const (
+	EventExecutionStarted   EventType = "execution.started"
+	EventExecutionCompleted EventType = "execution.completed"
+	EventExecutionFailed    EventType = "execution.failed"
+	EventNodeStarted        EventType = "node.started"
+	EventNodeCompleted      EventType = "node.completed"
+	EventNodeFailed         EventType = "node.failed"
+	EventNodePaused         EventType = "node.paused"
+
+	EventWorkflowCreated EventType = "workflow.created"
+	EventWorkflowUpdated EventType = "workflow.updated"
+	EventWorkflowDeleted EventType = "workflow.deleted"
+
+	// EventTriggerFired reports a trigger queueing an execution of its
+	// workflow
+	EventTriggerFired EventType = "trigger.fired"
+)
+
+// Event reports the progress of an execution or a change to a workflow
+type Event struct {
+	Type        EventType   `json:"type"`
+	TenantID    string      `json:"tenant_id,omitempty"`
+	WorkflowID  string      `json:"workflow_id"`
+	ExecutionID string      `json:"execution_id"`
+	NodeID      string      `json:"node_id,omitempty"`
+	Input       interface{} `json:"input,omitempty"`
+	Output      interface{} `json:"output,omitempty"`
+	Error       string      `json:"error,omitempty"`
+	Time        time.Time   `json:"time"`
+}
+
+// eventHub delivers events to in-process subscribers
+type eventHub struct {
+	mu          sync.RWMutex
+	nextID      int
+	subscribers map[int]func(Event)
+}
+
+func newEventHub() *eventHub {
+	return &eventHub{subscribers: make(map[int]func(Event))}
+}
+
+// subscribe registers fn and returns a function that removes it
+func (h *eventHub) subscribe(fn func(Event)) func() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	id := h.nextID
+	h.nextID++
+	h.subscribers[id] = fn
+
+	return func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		delete(h.subscribers, id)
+	}
+}
+
+// publish calls every subscriber synchronously in the executing goroutine
+func (h *eventHub) publish(event Event) {
+	if h == nil {
+		return
+	}
+	if event.Time.IsZero() {
+		event.Time = time.Now()
+	}
+
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	for _, fn := range h.subscribers {
+		fn(event)
+	}
+}
@@ -2,10 +2,16 @@ package engine
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"sort"
+	"strings"
 	"time"
 
+	"github.com/nuumz/f1ow/internal/credentials"
 	"github.com/nuumz/f1ow/internal/models"
+	"github.com/nuumz/f1ow/internal/tenant"
+	"github.com/nuumz/f1ow/internal/usage"
 
 	"github.com/sirupsen/logrus"
 )
@@ -15,25 +21,46 @@ type Executor struct {
 	nodeRegistry *NodeRegistry
 	metrics      *Metrics
 	logger       *logrus.Logger
+	credentials  *credentials.Manager
+	events       *eventHub // nil when nobody subscribes to node events
+	variables    map[string]interface{}
+	secrets      []string    // values of secret variables, redacted from snapshots
+	resultCache  ResultCache // nil caches no node outputs
+	journal      journalFunc // nil unless the execution can be resumed
+	record       recordFunc  // nil unless node runs are stored
+	limits       NodeLimits
+	// restored are the variables set by nodes completed before the
+	// execution resumed, applied when the executor passes those nodes
+	restored map[string]map[string]interface{}
 }
 
-// NewExecutor creates a new workflow executor
-func NewExecutor(nodeRegistry *NodeRegistry, metrics *Metrics, logger *logrus.Logger) *Executor {
+// journalFunc records a completed node's output, the ports it emitted on,
+// and the variables it set so a resumed execution can skip the node
+type journalFunc func(ctx context.Context, nodeID string, output NodeOutput) error
+
+// recordFunc stores a node run each time it starts, completes or fails
+type recordFunc func(ctx context.Context, run models.NodeExecution)
+
+// NewExecutor creates a new workflow executor. credentials may be nil when
+// no vault is configured.
+func NewExecutor(nodeRegistry *NodeRegistry, metrics *Metrics, logger *logrus.Logger, credentials *credentials.Manager) *Executor {
 	return &Executor{
 		nodeRegistry: nodeRegistry,
 		metrics:      metrics,
 		logger:       logger,
+		credentials:  credentials,
 	}
 }
 
 // ExecuteWorkflow executes a complete workflow
-func (e *Executor) ExecuteWorkflow(ctx context.Context, workflow *models.Workflow, executionCtx *models.ExecutionContext) (map[string]interface{}, error) {
+func (e *Executor) ExecuteWorkflow(ctx context.Context, workflow *models.Workflow, executionCtx *models.ExecutionContext) (result map[string]interface{}, err error) {
 	e.logger.Infof("Starting execution of workflow %s", workflow.ID)
 
 	startTime := time.Now()
+	e.metrics.ActiveWorkflows.Inc()
 	defer func() {
-		duration := time.Since(startTime)
-		e.metrics.RecordWorkflowExecution(duration, true) // TODO: pass actual success status
+		e.metrics.ActiveWorkflows.Dec()
+		e.metrics.RecordWorkflowExecution(time.Since(startTime), err == nil)
 	}()
 
 	// Initialize node outputs if not provided
@@ -45,7 +72,7 @@ func (e *Executor) ExecuteWorkflow(ctx context.Context, workflow *models.Workflo
 	workflowDef := workflow.Definition
 
 	// Execute nodes based on DAG order
-	result, err := e.executeDAG(ctx, &workflowDef, executionCtx)
+	result, err = e.executeDAG(ctx, &workflowDef, executionCtx)
 	if err != nil {
 		e.logger.Errorf("Workflow execution failed: %v", err)
 		return nil, err
@@ -73,6 +100,20 @@ func (e *Executor) executeDAG(ctx context.Context, workflowDef *models.WorkflowD
 			return nil, fmt.Errorf("node %s not found", nodeID)
 		}
 
+		// Nodes journaled before the execution was resumed keep their output
+		if _, done := executionCtx.NodeExecutions[nodeID]; done {
+			e.logger.Infof("Node %s completed before the execution resumed", nodeID)
+			setVariables(executionCtx, e.restored[nodeID])
+			continue
+		}
+
+		// Nodes only run when an edge into them is taken, or have none
+		incoming, taken := e.incomingEdges(nodeID, workflowDef.Edges, executionCtx)
+		if len(incoming) > 0 && len(taken) == 0 {
+			e.logger.Infof("Skipping node %s: no edge into it was taken", nodeID)
+			continue
+		}
+
 		// Check if node should be executed based on conditions
 		shouldExecute := e.evaluateNodeConditions(node, executionCtx)
 		if !shouldExecute {
@@ -80,23 +121,87 @@ func (e *Executor) executeDAG(ctx context.Context, workflowDef *models.WorkflowD
 			continue
 		}
 
+		info, _ := ExecutionInfoFromContext(ctx)
+		event := Event{TenantID: tenant.IDOrDefault(ctx).String(), WorkflowID: info.WorkflowID, ExecutionID: info.ExecutionID, NodeID: nodeID}
+		pinned := node.PinnedData != nil && usesPinnedData(ctx)
+
+		// In item mode, inputs are prepared for each item
+		itemMode := workflowDef.Settings.ExecutionMode == models.ExecutionModeItems
+		var input map[string]interface{}
+		if !itemMode {
+			// Prepare node input from previous node outputs and workflow variables
+			if input, err = e.prepareNodeInput(node, incoming, taken, executionCtx); err != nil {
+				return nil, fmt.Errorf("failed to map input of node %s: %w", nodeID, err)
+			}
+		}
+
+		// In debug executions, wait for a command before running the node
+		if session := debugSessionFromContext(ctx); session != nil && !pinned && !itemMode {
+			event.Type, event.Input = EventNodePaused, input
+			e.events.publish(event)
+			event.Input = nil
+
+			command, err := session.wait(ctx, node, input)
+			if err != nil {
+				return nil, err
+			}
+			switch command.Action {
+			case DebugSkip:
+				e.logger.Infof("Skipping node %s at debugger request", nodeID)
+				continue
+			case DebugModify:
+				input = command.Input
+			case DebugStop:
+				return nil, fmt.Errorf("execution stopped by debugger at node %s", nodeID)
+			}
+		}
+
 		// Execute the node
-		output, err := e.executeNode(ctx, node, executionCtx)
+		event.Type = EventNodeStarted
+		e.events.publish(event)
+		run := models.NodeExecution{NodeID: nodeID, Status: models.ExecutionStatusRunning, StartedAt: time.Now()}
+		run.Snapshot = e.snapshot(input, executionCtx)
+		e.recordRun(ctx, run)
+
+		var output NodeOutput
+		switch {
+		case itemMode:
+			output, err = e.executeItems(ctx, node, incoming, taken, executionCtx, event, pinned, run.Snapshot)
+		case pinned:
+			e.logger.Infof("Using pinned data for node %s", nodeID)
+			output = NodeOutput{Data: copyPinnedData(node.PinnedData)}
+		default:
+			output, err = e.executeNode(ctx, node, input, executionCtx.Variables)
+		}
+		completedAt := time.Now()
+		run.CompletedAt = &completedAt
+		if meter, ok := usage.FromContext(ctx); ok {
+			meter.AddNode(completedAt.Sub(run.StartedAt))
+		}
 		if err != nil {
+			event.Type, event.Error = EventNodeFailed, err.Error()
+			e.events.publish(event)
+			errStr := err.Error()
+			run.Status, run.Error = models.ExecutionStatusFailed, &errStr
+			e.recordRun(ctx, run)
 			return nil, fmt.Errorf("failed to execute node %s: %w", nodeID, err)
 		}
+		event.Type, event.Output = EventNodeCompleted, output.Data
+		e.events.publish(event)
 
 		// Store node output for subsequent nodes
-		if nodeExecution, exists := executionCtx.NodeExecutions[nodeID]; exists {
-			nodeExecution.Output = output.(map[string]interface{})
-			executionCtx.NodeExecutions[nodeID] = nodeExecution
-		} else {
-			executionCtx.NodeExecutions[nodeID] = models.NodeExecution{
-				NodeID: nodeID,
-				Output: output.(map[string]interface{}),
+		run.Status, run.Output, run.Ports = models.ExecutionStatusCompleted, output.Data, output.Ports
+		run.Cached, _ = output.Metadata["cached"].(bool)
+		executionCtx.NodeExecutions[nodeID] = run
+		executionCtx.CurrentNodeID = nodeID
+		setVariables(executionCtx, output.Variables)
+		e.recordRun(ctx, run)
+
+		if e.journal != nil {
+			if err := e.journal(ctx, nodeID, output); err != nil {
+				return nil, fmt.Errorf("failed to journal node %s: %w", nodeID, err)
 			}
 		}
-		executionCtx.CurrentNodeID = nodeID
 	}
 
 	// Return final outputs - convert node executions to outputs
@@ -107,8 +212,32 @@ func (e *Executor) executeDAG(ctx context.Context, workflowDef *models.WorkflowD
 	return outputs, nil
 }
 
-// executeNode executes a single workflow node
-func (e *Executor) executeNode(ctx context.Context, node *models.Node, executionCtx *models.ExecutionContext) (interface{}, error) {
+// recordRun passes a node run to the record hook, if any
+func (e *Executor) recordRun(ctx context.Context, run models.NodeExecution) {
+	if e.record != nil {
+		e.record(ctx, run)
+	}
+}
+
+// setVariables sets variables in the execution context. The map is copied
+// first, since it starts out as the execution's stored input.
+func setVariables(executionCtx *models.ExecutionContext, variables map[string]interface{}) {
+	if len(variables) == 0 {
+		return
+	}
+	updated := make(map[string]interface{}, len(executionCtx.Variables)+len(variables))
+	for name, value := range executionCtx.Variables {
+		updated[name] = value
+	}
+	for name, value := range variables {
+		updated[name] = value
+	}
+	executionCtx.Variables = updated
+}
+
+// executeNode executes a single workflow node with the execution's current
+// variables
+func (e *Executor) executeNode(ctx context.Context, node *models.Node, input, variables map[string]interface{}) (NodeOutput, error) {
 	e.logger.Infof("Executing node %s of type %s", node.ID, node.Type)
 
 	startTime := time.Now()
@@ -118,25 +247,121 @@ func (e *Executor) executeNode(ctx context.Context, node *models.Node, execution
 	}()
 
 	// Get node implementation
-	nodeImpl, err := e.nodeRegistry.Get(node.Type)
+	nodeImpl, err := e.nodeRegistry.Get(node.TypeRef())
 	if err != nil {
-		return nil, fmt.Errorf("node type %s not registered: %w", node.Type, err)
+		e.metrics.RecordNodeError(node.Type, NodeErrorNotRegistered)
+		return NodeOutput{}, fmt.Errorf("node type %s not registered: %w", node.TypeRef(), err)
 	}
 
-	// Prepare node input from previous node outputs and workflow variables
-	input := e.prepareNodeInput(node, executionCtx)
+	// Expose node identity to the node implementation
+	info, _ := ExecutionInfoFromContext(ctx)
+	info.NodeID = node.ID
+	ctx = WithExecutionInfo(ctx, info)
+
+	config, err := e.resolveCredentials(ctx, node.Config)
+	if err != nil {
+		e.metrics.RecordNodeError(node.Type, NodeErrorCredentials)
+		return NodeOutput{}, err
+	}
 
-	// Execute the node
-	output, err := nodeImpl.Execute(ctx, input, node.Config)
+	// Execute the node, or reuse its cached output
+	nodeConfig := NodeConfig(config)
+	nodeInput := NodeInput{
+		Data:   input,
+		Binary: binaryRefs(input),
+		Metadata: NodeMetadata{
+			NodeID:      node.ID,
+			NodeType:    node.Type,
+			WorkflowID:  info.WorkflowID,
+			ExecutionID: info.ExecutionID,
+		},
+		Variables: variables,
+	}
+	output, err := e.runCached(ctx, node, nodeConfig, nodeInput, func() (NodeOutput, error) {
+		return e.limits.run(ctx, input, e.metrics.AbandonedNodeRuns.WithLabelValues(node.Type), func(ctx context.Context) (NodeOutput, error) {
+			return AsTypedNode(nodeImpl).Run(ctx, nodeConfig, nodeInput)
+		})
+	})
 	if err != nil {
-		return nil, fmt.Errorf("node execution failed: %w", err)
+		e.metrics.RecordNodeError(node.Type, classifyNodeError(ctx, err))
+		return NodeOutput{}, fmt.Errorf("node execution failed: %w", err)
 	}
 
 	return output, nil
 }
 
-// prepareNodeInput prepares input data for a node execution
-func (e *Executor) prepareNodeInput(node *models.Node, executionCtx *models.ExecutionContext) map[string]interface{} {
+// resolveCredentials returns the node config with the fields of the
+// referenced credential filled in. Values set on the node take precedence.
+func (e *Executor) resolveCredentials(ctx context.Context, config map[string]interface{}) (map[string]interface{}, error) {
+	credentialID, _ := config["credential_id"].(string)
+	if credentialID == "" {
+		return config, nil
+	}
+	if e.credentials == nil {
+		return nil, fmt.Errorf("node references credential %s but no credentials vault is configured", credentialID)
+	}
+
+	fields, err := e.credentials.Resolve(ctx, credentialID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve credential %s: %w", credentialID, err)
+	}
+
+	resolved := make(map[string]interface{}, len(config)+len(fields))
+	for key, value := range fields {
+		resolved[key] = value
+	}
+	for key, value := range config {
+		if value == nil || value == "" {
+			continue
+		}
+		resolved[key] = value
+	}
+	return resolved, nil
+}
+
+// incomingEdges returns the edges into a node and those of them that are
+// taken: their source completed and, for an edge from a port, emitted on
+// that port
+func (e *Executor) incomingEdges(nodeID string, edges []models.Edge, executionCtx *models.ExecutionContext) (incoming, taken []models.Edge) {
+	for _, edge := range edges {
+		if edge.Target != nodeID {
+			continue
+		}
+		incoming = append(incoming, edge)
+
+		source, ran := executionCtx.NodeExecutions[edge.Source]
+		if !ran || source.Status != models.ExecutionStatusCompleted {
+			continue
+		}
+		if edge.SourcePort == "" || source.Ports == nil || containsString(source.Ports, edge.SourcePort) {
+			taken = append(taken, edge)
+		}
+	}
+	return incoming, taken
+}
+
+// prepareNodeInput prepares input data for a node execution: the fields of
+// its input mapping, if it has one, or else every variable and the outputs
+// of all previous nodes as nodeOutputs. Either way {{vars.NAME}} resolves.
+func (e *Executor) prepareNodeInput(node *models.Node, incoming, taken []models.Edge, executionCtx *models.ExecutionContext) (map[string]interface{}, error) {
+	nodeOutputs := make(map[string]interface{})
+	for nodeID, nodeExec := range executionCtx.NodeExecutions {
+		nodeOutputs[nodeID] = nodeExec.Output
+	}
+
+	if hasInputMapping(node, incoming) {
+		input, err := mapNodeInput(node, taken, mappingScope{
+			"nodes": nodeOutputs,
+			"vars":  e.variables,
+			"input": executionCtx.Variables,
+		})
+		if err != nil {
+			return nil, err
+		}
+		input["vars"] = e.variables
+		return input, nil
+	}
+
 	input := make(map[string]interface{})
 
 	// Add workflow variables
@@ -144,14 +369,81 @@ func (e *Executor) prepareNodeInput(node *models.Node, executionCtx *models.Exec
 		input[k] = v
 	}
 
+	// Add resolved global, environment, and workflow variables as {{vars.NAME}}
+	input["vars"] = e.variables
+
 	// Add outputs from previous nodes
-	nodeOutputs := make(map[string]interface{})
-	for nodeID, nodeExec := range executionCtx.NodeExecutions {
-		nodeOutputs[nodeID] = nodeExec.Output
-	}
 	input["nodeOutputs"] = nodeOutputs
 
-	return input
+	return input, nil
+}
+
+// copyPinnedData returns a deep copy of pinned data so nodes downstream
+// cannot modify the workflow definition through it
+func copyPinnedData(data map[string]interface{}) map[string]interface{} {
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return data
+	}
+	var copied map[string]interface{}
+	if err := json.Unmarshal(encoded, &copied); err != nil {
+		return data
+	}
+	return copied
+}
+
+// snapshot returns what a node run sees: a copy of its input without the
+// outputs of earlier nodes, which are named instead, and of the execution's
+// variables, with the values of secret variables redacted
+func (e *Executor) snapshot(input map[string]interface{}, executionCtx *models.ExecutionContext) *models.NodeSnapshot {
+	snapshot := &models.NodeSnapshot{
+		Input:     e.redactedCopy(input),
+		Variables: e.redactedCopy(executionCtx.Variables),
+	}
+	delete(snapshot.Input, "nodeOutputs")
+	for nodeID := range executionCtx.NodeExecutions {
+		snapshot.Upstream = append(snapshot.Upstream, nodeID)
+	}
+	sort.Strings(snapshot.Upstream)
+	return snapshot
+}
+
+// redactedValue replaces secrets in node snapshots
+const redactedValue = "[redacted]"
+
+// redactedCopy returns a deep copy of data in which every string holding
+// the value of a secret variable is replaced
+func (e *Executor) redactedCopy(data map[string]interface{}) map[string]interface{} {
+	copied := copyPinnedData(data)
+	if copied == nil {
+		return map[string]interface{}{}
+	}
+	if len(e.secrets) > 0 {
+		redactSecrets(copied, e.secrets)
+	}
+	return copied
+}
+
+// redactSecrets replaces, in place, the strings of decoded JSON that
+// contain any of the secrets
+func redactSecrets(value interface{}, secrets []string) interface{} {
+	switch v := value.(type) {
+	case string:
+		for _, secret := range secrets {
+			if strings.Contains(v, secret) {
+				return redactedValue
+			}
+		}
+	case map[string]interface{}:
+		for key, field := range v {
+			v[key] = redactSecrets(field, secrets)
+		}
+	case []interface{}:
+		for i, element := range v {
+			v[i] = redactSecrets(element, secrets)
+		}
+	}
+	return value
 }
 
 // buildDependencyGraph builds a dependency graph from workflow edges
@@ -196,6 +488,7 @@ func (e *Executor) topologicalSort(nodes []models.Node, dependencies map[string]
 
 		visiting[nodeID] = false
 		visited[nodeID] = true
+		// Every dependency is already in the result
 		result = append(result, nodeID)
 
 		return nil
@@ -209,11 +502,6 @@ func (e *Executor) topologicalSort(nodes []models.Node, dependencies map[string]
 		}
 	}
 
-	// Reverse the result to get correct execution order
-	for i, j := 0, len(result)-1; i < j; i, j = i+1, j-1 {
-		result[i], result[j] = result[j], result[i]
-	}
-
 	return result, nil
 }
 
@@ -0,0 +1,71 @@
+package engine
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ExpressionResult is an expression evaluated against a sample payload, for
+// previews in the designer. Error is set, and Result nil, when the
+// expression is malformed.
+type ExpressionResult struct {
+	Result interface{} `json:"result"`
+	Type   string      `json:"type"` // string, number, boolean, object, array, or null
+	// Missing lists the {{path}} references with no value in the payload,
+	// which resolve to null, or to "" inside a longer template
+	Missing []string `json:"missing"`
+	Error   string   `json:"error,omitempty"`
+}
+
+// EvaluateExpression evaluates a template such as {{nodes.fetch.body.id}}
+// against payload the way input mappings are: a template that is a single
+// {{path}} keeps the type of the value, and any other is rendered as a
+// string
+func EvaluateExpression(expression string, payload map[string]interface{}) ExpressionResult {
+	result := ExpressionResult{Type: "null", Missing: []string{}}
+	if err := checkExpression(expression); err != nil {
+		result.Error = err.Error()
+		return result
+	}
+
+	scope := mappingScope(payload)
+	for _, reference := range mappingExpression.FindAllStringSubmatch(expression, -1) {
+		if lookupPath(scope, reference[1]) == nil {
+			result.Missing = append(result.Missing, reference[1])
+		}
+	}
+	result.Result = evaluateMapping(expression, scope)
+	result.Type = jsonType(result.Result)
+	return result
+}
+
+// checkExpression returns why an expression cannot be evaluated, or nil
+func checkExpression(expression string) error {
+	if open, close := strings.Count(expression, "{{"), strings.Count(expression, "}}"); open != close {
+		return fmt.Errorf("unbalanced braces: %d {{ and %d }}", open, close)
+	}
+	for _, reference := range mappingExpression.FindAllStringSubmatch(expression, -1) {
+		if len(splitMappingPath(reference[1])) == 0 {
+			return fmt.Errorf("empty {{}} reference")
+		}
+	}
+	return nil
+}
+
+// jsonType names the JSON type of a decoded value
+func jsonType(value interface{}) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	case map[string]interface{}:
+		return "object"
+	case []interface{}:
+		return "array"
+	default:
+		return "number"
+	}
+}
@@ -0,0 +1,84 @@
+package engine
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Ways a queue shares dequeues between its jobs
+const (
+	FairnessTenant   = "tenant"   // between tenants
+	FairnessWorkflow = "workflow" // between the workflows of all tenants
+)
+
+// Fairness makes a queue share dequeues between tenants or workflows, so one
+// with thousands of queued jobs cannot starve the others. Each tenant or
+// workflow has a lane of the queue, with its own partitions. Dequeues take
+// turns between lanes with jobs waiting, in proportion to their weights,
+// and within a lane take the next job by score. The zero value dequeues
+// strictly by score.
+type Fairness struct {
+	By      string         // FairnessTenant, FairnessWorkflow, or "" for none
+	Weights map[string]int // by tenant ID, or workflow ID; 1 when unset
+}
+
+// WithFairness sets how the queue shares dequeues between tenants or
+// workflows
+func WithFairness(fairness Fairness) Option {
+	return func(e *Engine) {
+		e.fairness = fairness
+	}
+}
+
+// fairQueue is implemented by queues that share dequeues between lanes
+type fairQueue interface {
+	SetFairness(fairness Fairness)
+}
+
+// ParseWeights parses fairness weights written as "id=weight,id=weight"
+func ParseWeights(s string) (map[string]int, error) {
+	pairs, err := ParseLabels(s)
+	if err != nil {
+		return nil, err
+	}
+	weights := make(map[string]int, len(pairs))
+	for id, value := range pairs {
+		weight, err := strconv.Atoi(value)
+		if err != nil || weight < 1 {
+			return nil, fmt.Errorf("invalid weight %q for %s: expected a positive integer", value, id)
+		}
+		weights[id] = weight
+	}
+	return weights, nil
+}
+
+// lane returns the lane the job waits in: its tenant, or its tenant and
+// workflow. Jobs wait in the "" lane without fairness.
+func (f Fairness) lane(job *Job) string {
+	switch f.By {
+	case FairnessTenant:
+		return job.TenantID
+	case FairnessWorkflow:
+		return job.TenantID + "/" + job.WorkflowID
+	}
+	return ""
+}
+
+// stride returns how far a dequeue from the lane moves it back in turn:
+// the inverse of its weight, so a lane of weight 3 is served three times
+// as often as one of weight 1. Workflow lanes without a weight of their
+// own take their tenant's.
+func (f Fairness) stride(lane string) float64 {
+	ids := []string{lane}
+	if f.By == FairnessWorkflow {
+		tenantID, workflowID, _ := strings.Cut(lane, "/")
+		ids = []string{workflowID, tenantID}
+	}
+	for _, id := range ids {
+		if weight, ok := f.Weights[id]; ok && weight > 0 {
+			return 1 / float64(weight)
+		}
+	}
+	return 1
+}
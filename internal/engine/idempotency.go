@@ -0,0 +1,180 @@
+package engine
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/nuumz/f1ow/internal/models"
+	"github.com/nuumz/f1ow/internal/storage"
+	"github.com/nuumz/f1ow/internal/tenant"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+// idempotencyTTL is how long an idempotency key maps to its execution
+var idempotencyTTL = 24 * time.Hour
+
+// idempotencyRecord is what an idempotency key maps to
+type idempotencyRecord struct {
+	JobID       string `json:"job_id,omitempty"`
+	ExecutionID string `json:"execution_id"`
+}
+
+const idempotencyKeyKey contextKey = "idempotency_key"
+
+// WithIdempotencyKey returns a context whose execution requests are
+// deduplicated by key: repeating a key already used for the workflow in
+// the last 24 hours returns the original execution or job instead of
+// starting another. Engines without an idempotency store ignore keys.
+func WithIdempotencyKey(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, idempotencyKeyKey, key)
+}
+
+func idempotencyKeyFromContext(ctx context.Context) string {
+	key, _ := ctx.Value(idempotencyKeyKey).(string)
+	return key
+}
+
+// IdempotencyStore remembers what each idempotency key was first used for
+type IdempotencyStore interface {
+	// Reserve stores value under key for ttl unless key is already stored,
+	// and reports whether it stored it
+	Reserve(ctx context.Context, key string, value []byte, ttl time.Duration) (bool, error)
+
+	// Lookup returns the value stored under key and whether there is one
+	Lookup(ctx context.Context, key string) ([]byte, bool, error)
+
+	// Release removes key
+	Release(ctx context.Context, key string) error
+}
+
+// WithIdempotencyStore sets where idempotency keys are stored. Engines with
+// Redis use a RedisIdempotencyStore by default.
+func WithIdempotencyStore(store IdempotencyStore) Option {
+	return func(e *Engine) {
+		e.idempotency = store
+	}
+}
+
+// RedisIdempotencyStore keeps idempotency keys in Redis keys that expire
+type RedisIdempotencyStore struct {
+	redis *storage.RedisClient
+}
+
+// NewRedisIdempotencyStore creates an idempotency store backed by Redis
+func NewRedisIdempotencyStore(redis *storage.RedisClient) *RedisIdempotencyStore {
+	return &RedisIdempotencyStore{redis: redis}
+}
+
+// Reserve stores value under key unless it is already set
+func (s *RedisIdempotencyStore) Reserve(ctx context.Context, key string, value []byte, ttl time.Duration) (bool, error) {
+	return s.redis.Client().SetNX(ctx, key, value, ttl).Result()
+}
+
+// Lookup returns the value stored under key
+func (s *RedisIdempotencyStore) Lookup(ctx context.Context, key string) ([]byte, bool, error) {
+	data, err := s.redis.Client().Get(ctx, key).Bytes()
+	if err == redis.Nil {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	return data, true, nil
+}
+
+// Release removes key
+func (s *RedisIdempotencyStore) Release(ctx context.Context, key string) error {
+	return s.redis.Client().Del(ctx, key).Err()
+}
+
+func idempotencyStoreKey(ctx context.Context, workflowID, key string) string {
+	return fmt.Sprintf("f1ow:idempotency:%s:%s:%s", tenant.IDOrDefault(ctx), workflowID, key)
+}
+
+// reserveIdempotencyKey stores record under the context's idempotency key
+// unless the key was already used, in which case it returns the record
+// stored first. It returns nil, nil when there is no key.
+func (e *Engine) reserveIdempotencyKey(ctx context.Context, workflowID string, record idempotencyRecord) (*idempotencyRecord, error) {
+	key := idempotencyKeyFromContext(ctx)
+	if key == "" || e.idempotency == nil {
+		return nil, nil
+	}
+
+	data, err := json.Marshal(record)
+	if err != nil {
+		return nil, err
+	}
+	stored, err := e.idempotency.Reserve(ctx, idempotencyStoreKey(ctx, workflowID, key), data, idempotencyTTL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reserve idempotency key: %w", err)
+	}
+	if stored {
+		return nil, nil
+	}
+	return e.lookupIdempotencyKey(ctx, workflowID, key)
+}
+
+// releaseIdempotencyKey frees the context's idempotency key after the
+// request it was reserved for failed, so a retry can use it
+func (e *Engine) releaseIdempotencyKey(ctx context.Context, workflowID string) {
+	key := idempotencyKeyFromContext(ctx)
+	if key == "" || e.idempotency == nil {
+		return
+	}
+	if err := e.idempotency.Release(context.WithoutCancel(ctx), idempotencyStoreKey(ctx, workflowID, key)); err != nil {
+		e.logger.Errorf("Failed to release idempotency key: %v", err)
+	}
+}
+
+func (e *Engine) lookupIdempotencyKey(ctx context.Context, workflowID, key string) (*idempotencyRecord, error) {
+	data, found, err := e.idempotency.Lookup(ctx, idempotencyStoreKey(ctx, workflowID, key))
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up idempotency key: %w", err)
+	}
+	if !found {
+		return nil, nil
+	}
+
+	var record idempotencyRecord
+	if err := json.Unmarshal(data, &record); err != nil {
+		return nil, fmt.Errorf("failed to decode idempotency key: %w", err)
+	}
+	return &record, nil
+}
+
+// IdempotentExecution returns the execution an idempotency key was used
+// for with the workflow, or nil when the key is unused
+func (e *Engine) IdempotentExecution(ctx context.Context, workflowID, key string) (*models.Execution, error) {
+	if e.idempotency == nil {
+		return nil, nil
+	}
+	record, err := e.lookupIdempotencyKey(ctx, workflowID, key)
+	if err != nil || record == nil {
+		return nil, err
+	}
+	return e.replayExecution(ctx, workflowID, key, record)
+}
+
+// replayExecution returns the execution recorded for an idempotency key.
+// A queued execution a worker has not created yet is reported as pending.
+func (e *Engine) replayExecution(ctx context.Context, workflowID, key string, record *idempotencyRecord) (*models.Execution, error) {
+	executionID, err := uuid.Parse(record.ExecutionID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid execution ID for idempotency key: %w", err)
+	}
+	if execution, err := e.db.GetExecution(ctx, executionID); err == nil {
+		return execution, nil
+	}
+
+	wfID, _ := uuid.Parse(workflowID)
+	return &models.Execution{
+		ID:         executionID,
+		WorkflowID: wfID,
+		Status:     models.ExecutionStatusPending,
+		Metadata:   map[string]interface{}{"idempotency_key": key},
+	}, nil
+}
@@ -0,0 +1,214 @@
+package engine
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/nuumz/f1ow/internal/models"
+)
+
+// mappingExpression matches a {{path}} reference in an input mapping
+var mappingExpression = regexp.MustCompile(`\{\{\s*([^{}]*?)\s*\}\}`)
+
+// mappingScope is what input mapping expressions are evaluated against:
+// nodes.<id> is a completed node's output, vars the resolved variables,
+// input the execution input, and source, in edge mappings only, the output
+// of the edge's source node
+type mappingScope map[string]interface{}
+
+// hasInputMapping reports whether the node or an edge into it declares an
+// input mapping, in which case the node receives only the mapped fields
+func hasInputMapping(node *models.Node, incoming []models.Edge) bool {
+	if len(node.InputMapping) > 0 {
+		return true
+	}
+	for _, edge := range incoming {
+		if len(edge.InputMapping) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// mapNodeInput builds the input of a node with an input mapping. Mappings
+// of the taken edges into the node are applied in edge order, then the
+// node's own mapping, so the node can override what an edge provides.
+func mapNodeInput(node *models.Node, taken []models.Edge, scope mappingScope) (map[string]interface{}, error) {
+	input := make(map[string]interface{})
+	nodes, _ := scope["nodes"].(map[string]interface{})
+	for _, edge := range taken {
+		if len(edge.InputMapping) == 0 {
+			continue
+		}
+		output := nodes[edge.Source]
+		edgeScope := make(mappingScope, len(scope)+1)
+		for key, value := range scope {
+			edgeScope[key] = value
+		}
+		edgeScope["source"] = output
+		if err := applyInputMapping(input, edge.InputMapping, edgeScope); err != nil {
+			return nil, fmt.Errorf("edge from %s: %w", edge.Source, err)
+		}
+	}
+	if err := applyInputMapping(input, node.InputMapping, scope); err != nil {
+		return nil, err
+	}
+	return input, nil
+}
+
+// applyInputMapping sets each target field of input, a dot path, to the
+// value of its expression. Fields are set in sorted order so a mapping to
+// "a" is overridden by one to "a.b" rather than the other way round.
+func applyInputMapping(input map[string]interface{}, mapping map[string]string, scope mappingScope) error {
+	fields := make([]string, 0, len(mapping))
+	for field := range mapping {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	for _, field := range fields {
+		if err := setPath(input, field, evaluateMapping(mapping[field], scope)); err != nil {
+			return fmt.Errorf("input field %s: %w", field, err)
+		}
+	}
+	return nil
+}
+
+// evaluateMapping evaluates an input mapping expression. An expression that
+// is a single {{path}} keeps the type of the referenced value, nil when it is
+// missing; any other expression is rendered as a string, with missing
+// references left empty.
+func evaluateMapping(expression string, scope mappingScope) interface{} {
+	trimmed := strings.TrimSpace(expression)
+	if match := mappingExpression.FindStringSubmatchIndex(trimmed); match != nil && match[0] == 0 && match[1] == len(trimmed) {
+		return lookupPath(scope, trimmed[match[2]:match[3]])
+	}
+
+	return mappingExpression.ReplaceAllStringFunc(expression, func(reference string) string {
+		value := lookupPath(scope, mappingExpression.FindStringSubmatch(reference)[1])
+		if value == nil {
+			return ""
+		}
+		return fmt.Sprintf("%v", value)
+	})
+}
+
+// lookupPath returns the value at a dot path such as nodes.fetch.body.items[0].id,
+// or nil if any part of it is missing
+func lookupPath(scope mappingScope, path string) interface{} {
+	var current interface{} = map[string]interface{}(scope)
+	for _, part := range splitMappingPath(path) {
+		switch value := current.(type) {
+		case map[string]interface{}:
+			current = value[part]
+		case []interface{}:
+			index, err := strconv.Atoi(part)
+			if err != nil || index < 0 || index >= len(value) {
+				return nil
+			}
+			current = value[index]
+		default:
+			return nil
+		}
+	}
+	return current
+}
+
+// setPath sets the value at a dot path of input, creating the objects on
+// the way
+func setPath(input map[string]interface{}, path string, value interface{}) error {
+	parts := splitMappingPath(path)
+	if len(parts) == 0 {
+		return fmt.Errorf("empty field name")
+	}
+	current := input
+	for _, part := range parts[:len(parts)-1] {
+		next, ok := current[part].(map[string]interface{})
+		if !ok {
+			if current[part] != nil {
+				return fmt.Errorf("%s is not an object", part)
+			}
+			next = make(map[string]interface{})
+			current[part] = next
+		}
+		current = next
+	}
+	current[parts[len(parts)-1]] = value
+	return nil
+}
+
+// splitMappingPath splits a dot path, treating items[0] as items.0
+func splitMappingPath(path string) []string {
+	path = strings.NewReplacer("[", ".", "]", "").Replace(strings.TrimSpace(path))
+	var parts []string
+	for _, part := range strings.Split(path, ".") {
+		if part != "" {
+			parts = append(parts, part)
+		}
+	}
+	return parts
+}
+
+// validateInputMappings checks that mapped fields are named and that every
+// nodes.<id> reference names a node of the definition. Edge mappings may
+// also reference source.
+func validateInputMappings(definition *models.WorkflowDefinition) []FieldError {
+	nodeIDs := make(map[string]bool, len(definition.Nodes))
+	for _, node := range definition.Nodes {
+		nodeIDs[node.ID] = true
+	}
+
+	var fieldErrs []FieldError
+	check := func(nodeID, prefix string, mapping map[string]string, edge bool) {
+		fields := make([]string, 0, len(mapping))
+		for field := range mapping {
+			fields = append(fields, field)
+		}
+		sort.Strings(fields)
+		for _, field := range fields {
+			name := prefix + "." + field
+			if len(splitMappingPath(field)) == 0 {
+				fieldErrs = append(fieldErrs, FieldError{NodeID: nodeID, Field: prefix, Message: "maps to an empty field name"})
+				continue
+			}
+			for _, reference := range mappingExpression.FindAllStringSubmatch(mapping[field], -1) {
+				if msg := checkMappingReference(reference[1], nodeIDs, edge); msg != "" {
+					fieldErrs = append(fieldErrs, FieldError{NodeID: nodeID, Field: name, Message: msg})
+				}
+			}
+		}
+	}
+	for _, node := range definition.Nodes {
+		check(node.ID, "input_mapping", node.InputMapping, false)
+	}
+	for i, edge := range definition.Edges {
+		check(edge.Target, fmt.Sprintf("edges[%d].input_mapping", i), edge.InputMapping, true)
+	}
+	return fieldErrs
+}
+
+// checkMappingReference returns why a {{path}} of a mapping cannot resolve,
+// or ""
+func checkMappingReference(path string, nodeIDs map[string]bool, edge bool) string {
+	parts := splitMappingPath(path)
+	if len(parts) == 0 {
+		return "has an empty {{}} reference"
+	}
+	switch parts[0] {
+	case "vars", "input":
+	case "source":
+		if !edge {
+			return "references source, which only edge mappings have"
+		}
+	case "nodes":
+		if len(parts) < 2 || !nodeIDs[parts[1]] {
+			return fmt.Sprintf("references unknown node in {{%s}}", path)
+		}
+	default:
+		return fmt.Sprintf("{{%s}} must start with nodes, vars, input, or source", path)
+	}
+	return ""
+}
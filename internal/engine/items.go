@@ -0,0 +1,300 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/nuumz/f1ow/internal/models"
+)
+
+// rootItem is the paired item of the execution input, the first item of an
+// execution in item mode
+const rootItem = "0"
+
+// item is one item a node produced in item mode. PairedItem traces it back
+// to the execution input: the item 0.2 is the third item produced from the
+// root item 0, and is paired with 0 and its other descendants.
+type item struct {
+	JSON       map[string]interface{}
+	PairedItem string
+	Ports      []string // nil for every port
+}
+
+// itemError is the error of a node run for one item
+type itemError struct {
+	PairedItem string
+	Error      string
+}
+
+// itemsOutput is the output stored for a node in item mode: its items and
+// the errors of the item runs that failed
+func itemsOutput(items []item, errs []itemError) map[string]interface{} {
+	encodedItems := make([]interface{}, len(items))
+	for i, it := range items {
+		var ports interface{}
+		if it.Ports != nil {
+			encodedPorts := make([]interface{}, len(it.Ports))
+			for j, port := range it.Ports {
+				encodedPorts[j] = port
+			}
+			ports = encodedPorts
+		}
+		encodedItems[i] = map[string]interface{}{"json": it.JSON, "paired_item": it.PairedItem, "ports": ports}
+	}
+	encodedErrs := make([]interface{}, len(errs))
+	for i, err := range errs {
+		encodedErrs[i] = map[string]interface{}{"paired_item": err.PairedItem, "error": err.Error}
+	}
+	return map[string]interface{}{"items": encodedItems, "errors": encodedErrs}
+}
+
+// outputItems returns the items of a node output stored by itemsOutput
+func outputItems(output map[string]interface{}) []item {
+	encoded, _ := output["items"].([]interface{})
+	items := make([]item, 0, len(encoded))
+	for _, value := range encoded {
+		fields, ok := value.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		it := item{}
+		it.JSON, _ = fields["json"].(map[string]interface{})
+		it.PairedItem, _ = fields["paired_item"].(string)
+		if ports, ok := fields["ports"].([]interface{}); ok {
+			it.Ports = make([]string, 0, len(ports))
+			for _, port := range ports {
+				if name, ok := port.(string); ok {
+					it.Ports = append(it.Ports, name)
+				}
+			}
+		}
+		items = append(items, it)
+	}
+	return items
+}
+
+// toItems makes each element of a list an item, wrapping values other than
+// objects in a data field
+func toItems(list []interface{}) []map[string]interface{} {
+	items := make([]map[string]interface{}, len(list))
+	for i, element := range list {
+		if fields, ok := element.(map[string]interface{}); ok {
+			items[i] = fields
+		} else {
+			items[i] = map[string]interface{}{"data": element}
+		}
+	}
+	return items
+}
+
+// pairs reports whether an item is paired with the item run for: it is
+// that item or one it came from
+func pairs(pairedItem, runFor string) bool {
+	return pairedItem == runFor || strings.HasPrefix(runFor, pairedItem+".")
+}
+
+// pairedItem returns the item of items paired with runFor, the closest
+// ancestor if there are several
+func pairedItem(items []item, runFor string) *item {
+	var paired *item
+	for i := range items {
+		if pairs(items[i].PairedItem, runFor) && (paired == nil || len(items[i].PairedItem) > len(paired.PairedItem)) {
+			paired = &items[i]
+		}
+	}
+	return paired
+}
+
+// itemRuns returns the paired items a node runs for: those of the items
+// reaching it, leaving out items that others reaching it came from, in
+// order
+func itemRuns(reaching map[string]bool) []string {
+	var runs []string
+	for pairedItem := range reaching {
+		descendant := false
+		for other := range reaching {
+			if other != pairedItem && pairs(pairedItem, other) {
+				descendant = true
+				break
+			}
+		}
+		if !descendant {
+			runs = append(runs, pairedItem)
+		}
+	}
+	sort.Slice(runs, func(i, j int) bool { return lessPairedItem(runs[i], runs[j]) })
+	return runs
+}
+
+// lessPairedItem orders paired items numerically part by part
+func lessPairedItem(a, b string) bool {
+	partsA, partsB := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < len(partsA) && i < len(partsB); i++ {
+		if partsA[i] != partsB[i] {
+			numberA, _ := strconv.Atoi(partsA[i])
+			numberB, _ := strconv.Atoi(partsB[i])
+			return numberA < numberB
+		}
+	}
+	return len(partsA) < len(partsB)
+}
+
+// executeItems runs a node once per item reaching it along the taken edges,
+// or once for the execution input if nothing leads to it. An item whose run
+// fails is recorded in the output's errors and goes no further; the node
+// only fails when every run does. The input of each run is added to the
+// snapshot's items.
+func (e *Executor) executeItems(ctx context.Context, node *models.Node, incoming, taken []models.Edge, executionCtx *models.ExecutionContext, event Event, pinned bool, snapshot *models.NodeSnapshot) (NodeOutput, error) {
+	itemsByNode := make(map[string][]item, len(executionCtx.NodeExecutions))
+	for nodeID, run := range executionCtx.NodeExecutions {
+		itemsByNode[nodeID] = outputItems(run.Output)
+	}
+
+	reaching := make(map[string]bool)
+	if len(incoming) == 0 {
+		reaching[rootItem] = true
+	}
+	for _, edge := range taken {
+		for _, it := range itemsByNode[edge.Source] {
+			if edge.SourcePort == "" || it.Ports == nil || containsString(it.Ports, edge.SourcePort) {
+				reaching[it.PairedItem] = true
+			}
+		}
+	}
+
+	var items []item
+	var errs []itemError
+	var variables map[string]interface{}
+	runs := itemRuns(reaching)
+	for _, runFor := range runs {
+		input, err := e.itemInput(node, incoming, taken, runFor, itemsByNode, executionCtx)
+		if err != nil {
+			return NodeOutput{}, fmt.Errorf("failed to map input of item %s: %w", runFor, err)
+		}
+
+		// In debug executions, each item waits for a command
+		if session := debugSessionFromContext(ctx); session != nil && !pinned {
+			event.Type, event.Input = EventNodePaused, input
+			e.events.publish(event)
+
+			command, err := session.wait(ctx, node, input)
+			if err != nil {
+				return NodeOutput{}, err
+			}
+			switch command.Action {
+			case DebugSkip:
+				continue
+			case DebugModify:
+				input = command.Input
+			case DebugStop:
+				return NodeOutput{}, fmt.Errorf("execution stopped by debugger at node %s", node.ID)
+			}
+		}
+		itemSnapshot := e.redactedCopy(input)
+		delete(itemSnapshot, "nodeOutputs")
+		snapshot.Items = append(snapshot.Items, itemSnapshot)
+
+		var output NodeOutput
+		if pinned {
+			output = NodeOutput{Data: copyPinnedData(node.PinnedData)}
+		} else if output, err = e.executeNode(ctx, node, input, executionCtx.Variables); err != nil {
+			e.logger.Warnf("Node %s failed for item %s: %v", node.ID, runFor, err)
+			errs = append(errs, itemError{PairedItem: runFor, Error: err.Error()})
+			continue
+		}
+		items = append(items, splitItems(node, output, runFor)...)
+
+		// Variables set for an item are seen by the runs for later items
+		setVariables(executionCtx, output.Variables)
+		for name, value := range output.Variables {
+			if variables == nil {
+				variables = make(map[string]interface{})
+			}
+			variables[name] = value
+		}
+	}
+	if len(errs) > 0 && len(errs) == len(runs) {
+		return NodeOutput{}, fmt.Errorf("failed for all %d items: %s", len(runs), errs[0].Error)
+	}
+
+	// The node emits on the ports any of its items did
+	ports := []string{}
+	for _, it := range items {
+		if it.Ports == nil {
+			ports = nil
+			break
+		}
+		for _, port := range it.Ports {
+			if !containsString(ports, port) {
+				ports = append(ports, port)
+			}
+		}
+	}
+	return NodeOutput{Data: itemsOutput(items, errs), Ports: ports, Variables: variables}, nil
+}
+
+// splitItems returns the items of a node run for an item: the elements at
+// the node's items path, the items the node returned, or its data
+func splitItems(node *models.Node, output NodeOutput, runFor string) []item {
+	produced := output.Items
+	if node.ItemsPath != "" {
+		list, _ := lookupPath(mappingScope(output.Data), node.ItemsPath).([]interface{})
+		produced = toItems(list)
+	}
+	if produced == nil {
+		return []item{{JSON: output.Data, PairedItem: runFor, Ports: output.Ports}}
+	}
+
+	items := make([]item, len(produced))
+	for i, fields := range produced {
+		items[i] = item{JSON: fields, PairedItem: runFor + "." + strconv.Itoa(i), Ports: output.Ports}
+	}
+	return items
+}
+
+// itemInput prepares the input of a node run for an item. Previous nodes
+// are seen through their items paired with it: nodes.<id> in input
+// mappings, and nodeOutputs. Without a mapping, the input is the paired
+// items of the taken edges' sources merged in edge order, or the execution
+// input for nodes without edges into them.
+func (e *Executor) itemInput(node *models.Node, incoming, taken []models.Edge, runFor string, itemsByNode map[string][]item, executionCtx *models.ExecutionContext) (map[string]interface{}, error) {
+	nodeOutputs := make(map[string]interface{}, len(itemsByNode))
+	for nodeID, items := range itemsByNode {
+		if paired := pairedItem(items, runFor); paired != nil {
+			nodeOutputs[nodeID] = paired.JSON
+		}
+	}
+
+	if hasInputMapping(node, incoming) {
+		input, err := mapNodeInput(node, taken, mappingScope{
+			"nodes": nodeOutputs,
+			"vars":  e.variables,
+			"input": executionCtx.Variables,
+		})
+		if err != nil {
+			return nil, err
+		}
+		input["vars"] = e.variables
+		return input, nil
+	}
+
+	input := make(map[string]interface{})
+	if len(incoming) == 0 {
+		for key, value := range executionCtx.Variables {
+			input[key] = value
+		}
+	}
+	for _, edge := range taken {
+		if fields, ok := nodeOutputs[edge.Source].(map[string]interface{}); ok {
+			for key, value := range fields {
+				input[key] = value
+			}
+		}
+	}
+	input["vars"] = e.variables
+	input["nodeOutputs"] = nodeOutputs
+	return input, nil
+}
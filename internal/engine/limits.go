@@ -0,0 +1,131 @@
+package engine
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/nuumz/f1ow/internal/models"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// ErrNodeLimitExceeded is wrapped by the errors of node runs whose input or
+// output is larger than their NodeLimits allow
+var ErrNodeLimitExceeded = errors.New("node limit exceeded")
+
+// NodeLimits bound the wall time and the input and output sizes of a single
+// node run, so one pathological node cannot hold up an execution or pass
+// huge payloads along. They are not memory or CPU budgets: a node can still
+// allocate or compute as much as it likes until its timeout, and after it
+// when it ignores its context. Zero fields are unlimited.
+type NodeLimits struct {
+	// Timeout is the longest a run may take. Its context is cancelled then,
+	// which interrupts script nodes, and the run fails without waiting for
+	// a node that ignores the context. Such runs are counted by the
+	// node_runs_abandoned gauge until they return.
+	Timeout       time.Duration
+	MaxInputSize  int // bytes of the run's input as JSON
+	MaxOutputSize int // bytes of the run's output as JSON
+}
+
+// WithNodeLimits sets the limits of node runs. Workflows may lower them in
+// their settings but not raise them.
+func WithNodeLimits(limits NodeLimits) Option {
+	return func(e *Engine) {
+		e.nodeLimits = limits
+	}
+}
+
+// forWorkflow returns the limits lowered by the workflow's settings
+func (l NodeLimits) forWorkflow(settings models.WorkflowSettings) NodeLimits {
+	return NodeLimits{
+		Timeout:       time.Duration(tighter(int64(l.Timeout), int64(settings.NodeTimeout)*int64(time.Second))),
+		MaxInputSize:  int(tighter(int64(l.MaxInputSize), int64(settings.MaxNodeInputSize))),
+		MaxOutputSize: int(tighter(int64(l.MaxOutputSize), int64(settings.MaxNodeOutputSize))),
+	}
+}
+
+// tighter returns the lower of two limits where zero is unlimited
+func tighter(a, b int64) int64 {
+	if a <= 0 || (b > 0 && b < a) {
+		return b
+	}
+	return a
+}
+
+// run runs a node within the limits. abandoned, if not nil, counts runs
+// left running past the timeout.
+func (l NodeLimits) run(ctx context.Context, input map[string]interface{}, abandoned prometheus.Gauge, fn func(ctx context.Context) (NodeOutput, error)) (NodeOutput, error) {
+	if err := checkSize("input", input, l.MaxInputSize); err != nil {
+		return NodeOutput{}, err
+	}
+
+	var output NodeOutput
+	var err error
+	if l.Timeout > 0 {
+		output, err = runWithTimeout(ctx, l.Timeout, abandoned, fn)
+	} else {
+		output, err = fn(ctx)
+	}
+	if err != nil {
+		return NodeOutput{}, err
+	}
+
+	if err := checkSize("output", output.Data, l.MaxOutputSize); err != nil {
+		return NodeOutput{}, err
+	}
+	return output, nil
+}
+
+// runWithTimeout runs fn until it returns or timeout passes. A node that
+// ignores its context is left to finish in the background, holding no
+// worker slot, and counted by abandoned until it does.
+func runWithTimeout(ctx context.Context, timeout time.Duration, abandoned prometheus.Gauge, fn func(ctx context.Context) (NodeOutput, error)) (NodeOutput, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	type result struct {
+		output NodeOutput
+		err    error
+	}
+	done := make(chan result, 1)
+	go func() {
+		output, err := fn(ctx)
+		done <- result{output, err}
+	}()
+
+	select {
+	case result := <-done:
+		return result.output, result.err
+	case <-ctx.Done():
+		if abandoned != nil {
+			abandoned.Inc()
+			go func() {
+				<-done
+				abandoned.Dec()
+			}()
+		}
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return NodeOutput{}, fmt.Errorf("node did not finish within %s: %w", timeout, ctx.Err())
+		}
+		return NodeOutput{}, ctx.Err()
+	}
+}
+
+// checkSize fails when data is larger than limit bytes as JSON
+func checkSize(name string, data interface{}, limit int) error {
+	if limit <= 0 || data == nil {
+		return nil
+	}
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("failed to measure node %s: %w", name, err)
+	}
+	if len(encoded) > limit {
+		return fmt.Errorf("node %s of %d bytes is larger than the %d byte limit: %w", name, len(encoded), limit, ErrNodeLimitExceeded)
+	}
+	return nil
+}
@@ -0,0 +1,314 @@
+package engine
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/nuumz/f1ow/internal/models"
+)
+
+// LintSeverity ranks a lint issue. Issues with error severity block saving
+// the workflow; LintOff disables a rule.
+type LintSeverity string
+
+const (
+	LintError   LintSeverity = "error"
+	LintWarning LintSeverity = "warning"
+	LintInfo    LintSeverity = "info"
+	LintOff     LintSeverity = "off"
+)
+
+// Lint rules
+const (
+	LintHardcodedCredential = "hardcoded_credential" // a secret written into a node config
+	LintInsecureTLS         = "insecure_tls"         // ignore_ssl_issues in a production environment
+	LintUnusedVariable      = "unused_variable"      // a workflow variable no node reads
+	LintUnhandledBranch     = "unhandled_branch"     // an output port of a branching node with no edge
+	LintDefaultNodeName     = "default_node_name"    // a node still named after its type
+)
+
+// defaultLintSeverities are the severities of the rules unless LintOptions
+// override them
+var defaultLintSeverities = map[string]LintSeverity{
+	LintHardcodedCredential: LintWarning,
+	LintInsecureTLS:         LintError,
+	LintUnusedVariable:      LintInfo,
+	LintUnhandledBranch:     LintWarning,
+	LintDefaultNodeName:     LintInfo,
+}
+
+// LintIssue is a problem a lint rule found in a workflow. NodeID is empty
+// for issues with the workflow as a whole.
+type LintIssue struct {
+	Rule     string       `json:"rule"`
+	Severity LintSeverity `json:"severity"`
+	NodeID   string       `json:"node_id,omitempty"`
+	Field    string       `json:"field,omitempty"`
+	Message  string       `json:"message"`
+	Hint     string       `json:"hint"` // how to fix it
+}
+
+// LintOptions configure the lint rules
+type LintOptions struct {
+	// Severities override the default severity of rules by name
+	Severities map[string]LintSeverity
+	// ProductionEnvironments are the environments insecure_tls applies
+	// in; production and prod when empty
+	ProductionEnvironments []string
+}
+
+// WithLintOptions configures the rules LintWorkflow runs
+func WithLintOptions(options LintOptions) Option {
+	return func(e *Engine) {
+		e.lint = options
+	}
+}
+
+// ParseLintSeverities parses rule severities written as
+// "rule=severity,rule=severity"
+func ParseLintSeverities(s string) (map[string]LintSeverity, error) {
+	pairs, err := ParseLabels(s)
+	if err != nil {
+		return nil, err
+	}
+	severities := make(map[string]LintSeverity, len(pairs))
+	for rule, value := range pairs {
+		if _, ok := defaultLintSeverities[rule]; !ok {
+			return nil, fmt.Errorf("unknown lint rule %q", rule)
+		}
+		switch severity := LintSeverity(value); severity {
+		case LintError, LintWarning, LintInfo, LintOff:
+			severities[rule] = severity
+		default:
+			return nil, fmt.Errorf("invalid severity %q for %s: expected error, warning, info, or off", value, rule)
+		}
+	}
+	return severities, nil
+}
+
+// LintWorkflow runs the enabled lint rules on the workflow as it would run
+// in environment, or in the engine's environment when environment is
+// empty. Issues are ordered by severity, errors first, then by node and
+// field.
+func (e *Engine) LintWorkflow(workflow *models.Workflow, environment string) []LintIssue {
+	if environment == "" {
+		environment = e.environment
+	}
+	linter := &linter{registry: e.nodeRegistry, options: e.lint, definition: &workflow.Definition, issues: []LintIssue{}}
+	linter.hardcodedCredentials()
+	if linter.production(environment) {
+		linter.insecureTLS(environment)
+	}
+	linter.unusedVariables()
+	linter.unhandledBranches()
+	linter.defaultNodeNames()
+
+	rank := map[LintSeverity]int{LintError: 0, LintWarning: 1, LintInfo: 2}
+	sort.SliceStable(linter.issues, func(i, j int) bool {
+		a, b := linter.issues[i], linter.issues[j]
+		if a.Severity != b.Severity {
+			return rank[a.Severity] < rank[b.Severity]
+		}
+		if a.NodeID != b.NodeID {
+			return a.NodeID < b.NodeID
+		}
+		return a.Field < b.Field
+	})
+	return linter.issues
+}
+
+// linter collects the issues of one workflow definition
+type linter struct {
+	registry   *NodeRegistry
+	options    LintOptions
+	definition *models.WorkflowDefinition
+	issues     []LintIssue
+}
+
+// report adds an issue for rule unless the rule is off
+func (l *linter) report(rule, nodeID, field, message, hint string) {
+	severity, ok := l.options.Severities[rule]
+	if !ok {
+		severity = defaultLintSeverities[rule]
+	}
+	if severity == LintOff {
+		return
+	}
+	l.issues = append(l.issues, LintIssue{
+		Rule:     rule,
+		Severity: severity,
+		NodeID:   nodeID,
+		Field:    field,
+		Message:  message,
+		Hint:     hint,
+	})
+}
+
+// production reports whether the environment is a production one
+func (l *linter) production(environment string) bool {
+	environments := l.options.ProductionEnvironments
+	if len(environments) == 0 {
+		environments = []string{"production", "prod"}
+	}
+	for _, name := range environments {
+		if strings.EqualFold(name, environment) {
+			return true
+		}
+	}
+	return false
+}
+
+// secretKeySuffixes end the names of config fields, nested ones such as
+// headers included, that hold secrets
+var secretKeySuffixes = []string{"password", "passwd", "secret", "token", "api_key", "apikey", "private_key", "access_key", "authorization"}
+
+// hardcodedCredentials reports secrets set as literals in node configs:
+// fields the schema formats as passwords, and fields named like secrets.
+// Templates are resolved at execution time and pass.
+func (l *linter) hardcodedCredentials() {
+	for _, node := range l.definition.Nodes {
+		var properties map[string]Property
+		if nodeType, err := l.registry.Get(node.TypeRef()); err == nil {
+			properties = nodeType.GetSchema().Properties
+		}
+		var walk func(path string, config map[string]interface{}, top bool)
+		walk = func(path string, config map[string]interface{}, top bool) {
+			for key, value := range config {
+				field := key
+				if path != "" {
+					field = path + "." + key
+				}
+				if nested, ok := value.(map[string]interface{}); ok {
+					walk(field, nested, false)
+					continue
+				}
+				s, ok := value.(string)
+				if !ok || s == "" || strings.Contains(s, "{{") {
+					continue
+				}
+				if (top && properties[key].Format == "password") || secretKey(key) {
+					l.report(LintHardcodedCredential, node.ID, field,
+						fmt.Sprintf("%s is set to a literal secret", field),
+						"store the secret in a credential and reference it with credential_id, or read it from {{vars.NAME}}")
+				}
+			}
+		}
+		walk("", node.Config, true)
+	}
+}
+
+// secretKey reports whether a config field name is one of a secret
+func secretKey(key string) bool {
+	key = strings.ToLower(strings.ReplaceAll(key, "-", "_"))
+	for _, suffix := range secretKeySuffixes {
+		if key == suffix || strings.HasSuffix(key, "_"+suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// insecureTLS reports nodes that skip certificate verification
+func (l *linter) insecureTLS(environment string) {
+	for _, node := range l.definition.Nodes {
+		if ignore, _ := node.Config["ignore_ssl_issues"].(bool); ignore && !node.Disabled {
+			l.report(LintInsecureTLS, node.ID, "ignore_ssl_issues",
+				fmt.Sprintf("certificate verification is disabled in %s", environment),
+				"remove ignore_ssl_issues and trust the server's certificate authority with ca_cert instead")
+		}
+	}
+}
+
+// unusedVariables reports workflow variables that no node config, input
+// mapping, or edge reads as vars.NAME
+func (l *linter) unusedVariables() {
+	names := make(map[string]string)
+	for name := range l.definition.Variables {
+		names[name] = "variables." + name
+	}
+	for name := range l.definition.Settings.Variables {
+		names[name] = "settings.variables." + name
+	}
+	if len(names) == 0 {
+		return
+	}
+
+	references, _ := json.Marshal(struct {
+		Nodes []models.Node `json:"nodes"`
+		Edges []models.Edge `json:"edges"`
+	}{l.definition.Nodes, l.definition.Edges})
+	sorted := make([]string, 0, len(names))
+	for name := range names {
+		sorted = append(sorted, name)
+	}
+	sort.Strings(sorted)
+	for _, name := range sorted {
+		reference := regexp.MustCompile(`vars(\.` + regexp.QuoteMeta(name) + `\b|\[\\?["']` + regexp.QuoteMeta(name) + `\\?["']\])`)
+		if !reference.Match(references) {
+			l.report(LintUnusedVariable, "", names[name],
+				fmt.Sprintf("variable %s is never read", name),
+				fmt.Sprintf("read it as {{vars.%s}} or remove it", name))
+		}
+	}
+}
+
+// unhandledBranches reports output ports with no edge on nodes that branch,
+// those with an edge from one of their ports. Executions taking such a
+// port, such as a conditional's false, end there without an error.
+func (l *linter) unhandledBranches() {
+	connected := make(map[string]map[string]bool)
+	for _, edge := range l.definition.Edges {
+		if edge.SourcePort == "" {
+			continue
+		}
+		if connected[edge.Source] == nil {
+			connected[edge.Source] = make(map[string]bool)
+		}
+		connected[edge.Source][edge.SourcePort] = true
+	}
+
+	for _, node := range l.definition.Nodes {
+		ports := connected[node.ID]
+		if len(ports) == 0 || node.Disabled {
+			continue
+		}
+		nodeType, err := l.registry.Get(node.TypeRef())
+		if err != nil {
+			continue
+		}
+		for _, output := range nodeType.GetSchema().Outputs {
+			if !ports[output.Name] {
+				l.report(LintUnhandledBranch, node.ID, "",
+					fmt.Sprintf("no edge leaves port %s, so executions taking it stop at this node", output.Name),
+					fmt.Sprintf("connect port %s to a node that handles it", output.Name))
+			}
+		}
+	}
+}
+
+// defaultNameSuffix matches the number designers append to the default
+// name of a second node of a type
+var defaultNameSuffix = regexp.MustCompile(` \d+$`)
+
+// defaultNodeNames reports nodes without a name of their own
+func (l *linter) defaultNodeNames() {
+	for _, node := range l.definition.Nodes {
+		name := strings.TrimSpace(node.Name)
+		defaultName := node.Type
+		if nodeType, err := l.registry.Get(node.TypeRef()); err == nil {
+			defaultName = nodeType.Name()
+		}
+		base := defaultNameSuffix.ReplaceAllString(name, "")
+		if name != "" && !strings.EqualFold(base, defaultName) && !strings.EqualFold(base, node.Type) {
+			continue
+		}
+		message := fmt.Sprintf("node has the default name %q", node.Name)
+		if name == "" {
+			message = "node has no name"
+		}
+		l.report(LintDefaultNodeName, node.ID, "name", message, "name the node after what it does in this workflow")
+	}
+}
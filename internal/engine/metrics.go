@@ -1,6 +1,11 @@
 package engine
 
 import (
+	"context"
+	"database/sql"
+	"errors"
+	"net"
+	"sync"
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
@@ -20,16 +25,21 @@ type Metrics struct {
 	NodesExecuted         *prometheus.CounterVec
 	NodeExecutionDuration *prometheus.HistogramVec
 	NodeErrors            *prometheus.CounterVec
+	NodeCacheLookups      *prometheus.CounterVec
+	AbandonedNodeRuns     *prometheus.GaugeVec
 
 	// Queue metrics
 	QueueSize         prometheus.Gauge
 	JobsEnqueued      prometheus.Counter
 	JobsDequeued      prometheus.Counter
 	JobProcessingTime prometheus.Histogram
+	JobWaitTime       prometheus.Histogram
 
 	// Worker metrics
 	ActiveWorkers     prometheus.Gauge
 	WorkerUtilization prometheus.Gauge
+	DrainingJobs      prometheus.Gauge
+	ShutdownRequeued  prometheus.Counter
 
 	// System metrics
 	DatabaseConnections prometheus.Gauge
@@ -38,6 +48,21 @@ type Metrics struct {
 	APIRequestTotal     *prometheus.CounterVec
 }
 
+var (
+	sharedMetricsOnce sync.Once
+	sharedMetricsVal  *Metrics
+)
+
+// sharedMetrics returns the process-wide metrics. Collectors are registered
+// with the default Prometheus registry, which rejects duplicates, so every
+// engine in a process records to the same metrics.
+func sharedMetrics() *Metrics {
+	sharedMetricsOnce.Do(func() {
+		sharedMetricsVal = NewMetrics()
+	})
+	return sharedMetricsVal
+}
+
 // NewMetrics creates and registers all metrics
 func NewMetrics() *Metrics {
 	return &Metrics{
@@ -94,6 +119,22 @@ func NewMetrics() *Metrics {
 			[]string{"node_type", "error_type"},
 		),
 
+		AbandonedNodeRuns: promauto.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "node_runs_abandoned",
+				Help: "Node runs still running after their timeout by type",
+			},
+			[]string{"node_type"},
+		),
+
+		NodeCacheLookups: promauto.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "node_cache_lookups_total",
+				Help: "Total number of result cache lookups by node type and result (hit, miss)",
+			},
+			[]string{"node_type", "result"},
+		),
+
 		// Queue metrics
 		QueueSize: promauto.NewGauge(prometheus.GaugeOpts{
 			Name: "queue_size",
@@ -116,6 +157,12 @@ func NewMetrics() *Metrics {
 			Buckets: []float64{0.1, 0.5, 1, 2.5, 5, 10, 30, 60},
 		}),
 
+		JobWaitTime: promauto.NewHistogram(prometheus.HistogramOpts{
+			Name:    "job_wait_duration_seconds",
+			Help:    "Time jobs waited in the queue before a worker started them, in seconds",
+			Buckets: []float64{0.1, 0.5, 1, 5, 10, 30, 60, 300, 900, 3600},
+		}),
+
 		// Worker metrics
 		ActiveWorkers: promauto.NewGauge(prometheus.GaugeOpts{
 			Name: "workers_active",
@@ -127,6 +174,16 @@ func NewMetrics() *Metrics {
 			Help: "Worker utilization percentage",
 		}),
 
+		DrainingJobs: promauto.NewGauge(prometheus.GaugeOpts{
+			Name: "worker_drain_jobs_remaining",
+			Help: "In-flight jobs a shutting down worker is waiting for",
+		}),
+
+		ShutdownRequeued: promauto.NewCounter(prometheus.CounterOpts{
+			Name: "worker_shutdown_requeued_total",
+			Help: "Executions returned to the queue because their worker shut down before they finished",
+		}),
+
 		// System metrics
 		DatabaseConnections: promauto.NewGauge(prometheus.GaugeOpts{
 			Name: "database_connections_active",
@@ -175,8 +232,83 @@ func (m *Metrics) RecordNodeExecution(nodeType string, duration time.Duration) {
 	m.NodeExecutionDuration.WithLabelValues(nodeType).Observe(duration.Seconds())
 }
 
+// Error types of the node_errors_total metric
+const (
+	NodeErrorNotRegistered = "not_registered" // the node type has no implementation
+	NodeErrorCredentials   = "credentials"    // the node's credential could not be resolved
+	NodeErrorTimeout       = "timeout"        // the node or execution deadline passed
+	NodeErrorLimit         = "limit"          // the node's input or output exceeded its size limit
+	NodeErrorCancelled     = "cancelled"      // the execution was cancelled
+	NodeErrorExecution     = "execution"      // the node itself failed
+)
+
+// RecordNodeError records a failed node execution
+func (m *Metrics) RecordNodeError(nodeType, errorType string) {
+	m.NodeErrors.WithLabelValues(nodeType, errorType).Inc()
+}
+
+// RecordNodeCacheLookup records whether a node's output was found in the
+// result cache
+func (m *Metrics) RecordNodeCacheLookup(nodeType string, hit bool) {
+	result := "miss"
+	if hit {
+		result = "hit"
+	}
+	m.NodeCacheLookups.WithLabelValues(nodeType, result).Inc()
+}
+
+// classifyNodeError returns the error type of a node's failure
+func classifyNodeError(ctx context.Context, err error) string {
+	var netErr net.Error
+	switch {
+	case errors.Is(err, context.DeadlineExceeded), errors.As(err, &netErr) && netErr.Timeout():
+		return NodeErrorTimeout
+	case errors.Is(err, context.Canceled), ctx.Err() == context.Canceled:
+		return NodeErrorCancelled
+	case errors.Is(err, ErrNodeLimitExceeded):
+		return NodeErrorLimit
+	default:
+		return NodeErrorExecution
+	}
+}
+
 // RecordAPIRequest records API request metrics
 func (m *Metrics) RecordAPIRequest(method, endpoint, status string, duration time.Duration) {
 	m.APIRequestTotal.WithLabelValues(method, endpoint, status).Inc()
 	m.APIRequestDuration.WithLabelValues(method, endpoint, status).Observe(duration.Seconds())
 }
+
+// Metrics returns the metrics the engine records to, for instrumenting the
+// processes that embed it
+func (e *Engine) Metrics() *Metrics {
+	return e.metrics
+}
+
+// StartPoolMetrics updates the database and Redis connection gauges from
+// the pools' stats every interval until ctx is done
+func (e *Engine) StartPoolMetrics(ctx context.Context, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			e.recordPoolStats()
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+func (e *Engine) recordPoolStats() {
+	// Only a SQL repository has a connection pool
+	if pool, ok := e.db.(interface{ Stats() sql.DBStats }); ok {
+		e.metrics.DatabaseConnections.Set(float64(pool.Stats().InUse))
+	}
+	if e.redis != nil {
+		stats := e.redis.PoolStats()
+		e.metrics.RedisConnections.Set(float64(stats.TotalConns - stats.IdleConns))
+	}
+}
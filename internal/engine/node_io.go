@@ -0,0 +1,140 @@
+package engine
+
+import (
+	"context"
+
+	"github.com/nuumz/f1ow/internal/binarydata"
+
+	"github.com/google/uuid"
+)
+
+// NodeConfig is a node's configuration with credentials resolved. It is a
+// type of its own so it cannot be passed where a node's input belongs.
+type NodeConfig map[string]interface{}
+
+// NodeInput is what a node runs on
+type NodeInput struct {
+	Data     map[string]interface{} // the fields of the input mapping, or the variables and nodeOutputs
+	Binary   map[string]BinaryRef   // binary data handles among the top-level fields of Data
+	Metadata NodeMetadata
+	// Variables are the execution's variables as they are when the node
+	// runs, including those set by earlier nodes. Read only; a node sets
+	// variables through NodeOutput.Variables.
+	Variables map[string]interface{}
+}
+
+// NodeMetadata identifies the node run an input is for
+type NodeMetadata struct {
+	NodeID      string `json:"node_id"`
+	NodeType    string `json:"node_type"`
+	WorkflowID  string `json:"workflow_id"`
+	ExecutionID string `json:"execution_id"`
+}
+
+// NodeOutput is what a node produced. Data is what later nodes see as the
+// node's output.
+type NodeOutput struct {
+	Data     map[string]interface{}
+	Binary   map[string]BinaryRef   // binary data handles among the top-level fields of Data
+	Metadata map[string]interface{} // about the run, such as item counts; not passed downstream
+	// Ports are the output ports the node emitted on. Edges from another
+	// port of the node are not taken. Nil emits on every port, and an
+	// empty slice on none.
+	Ports []string
+	// Items are the items the run produced in item mode. Nil makes Data
+	// the only item.
+	Items []map[string]interface{}
+	// Variables are set in the execution's variables once the node
+	// completes, for the nodes after it
+	Variables map[string]interface{}
+}
+
+// NewNodeOutput returns the output of a node that produced result and
+// emitted on ports. A result other than an object becomes its data field,
+// and the elements of a list its items.
+func NewNodeOutput(result interface{}, ports ...string) NodeOutput {
+	var data map[string]interface{}
+	var items []map[string]interface{}
+	switch result := result.(type) {
+	case map[string]interface{}:
+		data = result
+	case nil:
+		data = map[string]interface{}{}
+	case []interface{}:
+		data = map[string]interface{}{"data": result}
+		items = toItems(result)
+	default:
+		data = map[string]interface{}{"data": result}
+	}
+	return NodeOutput{Data: data, Binary: binaryRefs(data), Ports: ports, Items: items}
+}
+
+// BinaryRef is a binary data handle, as produced by binarydata.RefMap
+type BinaryRef struct {
+	ID       uuid.UUID `json:"binary_id"`
+	FileName string    `json:"file_name"`
+	MimeType string    `json:"mime_type"`
+	Size     int64     `json:"size"`
+}
+
+// TypedNode is a node taking typed input and config, which the executor
+// runs through Run instead of Execute
+type TypedNode interface {
+	NodeType
+	Run(ctx context.Context, config NodeConfig, input NodeInput) (NodeOutput, error)
+}
+
+// legacyNode runs a node with only Execute as a TypedNode
+type legacyNode struct {
+	NodeType
+}
+
+var _ TypedNode = legacyNode{}
+
+// Run calls Execute with the config and the input data, in the order
+// NodeType declares. The node emits on every port.
+func (n legacyNode) Run(ctx context.Context, config NodeConfig, input NodeInput) (NodeOutput, error) {
+	result, err := n.Execute(ctx, map[string]interface{}(config), input.Data)
+	if err != nil {
+		return NodeOutput{}, err
+	}
+	return NewNodeOutput(result), nil
+}
+
+// AsTypedNode returns the node as a TypedNode, adapting its Execute if it
+// does not implement Run
+func AsTypedNode(node NodeType) TypedNode {
+	if typed, ok := node.(TypedNode); ok {
+		return typed
+	}
+	return legacyNode{node}
+}
+
+// binaryRefs returns the binary data handles among the fields of data, or
+// nil if there are none
+func binaryRefs(data map[string]interface{}) map[string]BinaryRef {
+	var refs map[string]BinaryRef
+	for field, value := range data {
+		id, ok := binarydata.IDFromValue(value)
+		if !ok {
+			continue
+		}
+		handle := value.(map[string]interface{})
+		ref := BinaryRef{ID: id}
+		ref.FileName, _ = handle["file_name"].(string)
+		ref.MimeType, _ = handle["mime_type"].(string)
+		switch size := handle["size"].(type) {
+		case int64:
+			ref.Size = size
+		case float64:
+			ref.Size = int64(size)
+		case int:
+			ref.Size = int64(size)
+		}
+		if refs == nil {
+			refs = make(map[string]BinaryRef)
+		}
+		refs[field] = ref
+	}
+	return refs
+}
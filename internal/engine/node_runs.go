@@ -0,0 +1,69 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/nuumz/f1ow/internal/models"
+	"github.com/nuumz/f1ow/internal/storage"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Node runs are written once this many are pending or this long after the
+// last write, whichever comes first
+const (
+	nodeRunBatchSize     = 20
+	nodeRunFlushInterval = time.Second
+)
+
+// nodeRuns collects the node runs of a stored execution and writes them to
+// the database in batches while it runs. Runs a write failed to save stay
+// pending and are saved with the execution when it finishes.
+type nodeRuns struct {
+	db        storage.ExecutionRepository
+	logger    *logrus.Logger
+	execution *models.Execution
+	pending   []models.NodeExecution
+	flushedAt time.Time
+}
+
+// nodeRuns returns the collector of execution's node runs, or nil when
+// there is no database to write them to
+func (e *Engine) nodeRuns(execution *models.Execution) *nodeRuns {
+	if e.db == nil {
+		return nil
+	}
+	return &nodeRuns{db: e.db, logger: e.logger, execution: execution, flushedAt: time.Now()}
+}
+
+// record adds a node run, writing the pending runs when the batch is full
+// or the interval has passed
+func (r *nodeRuns) record(ctx context.Context, run models.NodeExecution) {
+	r.pending = append(r.pending, run)
+	if len(r.pending) < nodeRunBatchSize && time.Since(r.flushedAt) < nodeRunFlushInterval {
+		return
+	}
+
+	err := r.db.SaveNodeExecutions(ctx, r.execution.ID, r.execution.ClaimToken, r.pending)
+	if errors.Is(err, storage.ErrExecutionFenced) {
+		// The final update is fenced off too; the journal write stops the run
+		return
+	}
+	if err != nil {
+		r.logger.Errorf("Failed to save node runs of execution %s: %v", r.execution.ID, err)
+		return
+	}
+	r.pending = nil
+	r.flushedAt = time.Now()
+}
+
+// unsaved returns the node runs not written yet. It is nil-safe so callers
+// can pass it to UpdateExecution whether or not runs are collected.
+func (r *nodeRuns) unsaved() []models.NodeExecution {
+	if r == nil {
+		return nil
+	}
+	return r.pending
+}
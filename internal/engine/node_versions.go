@@ -0,0 +1,62 @@
+package engine
+
+import (
+	"github.com/nuumz/f1ow/internal/models"
+
+	"github.com/google/uuid"
+)
+
+// DeprecatedNodeUsage is a node of a workflow that runs a deprecated
+// version of its type
+type DeprecatedNodeUsage struct {
+	WorkflowID    uuid.UUID `json:"workflow_id"`
+	WorkflowName  string    `json:"workflow_name"`
+	NodeID        string    `json:"node_id"`
+	Type          string    `json:"type"`
+	Version       int       `json:"version"`
+	LatestVersion int       `json:"latest_version"`
+	Message       string    `json:"message"` // how to migrate off the version
+}
+
+// DeprecateNode marks a version of a node type, named type@vN, as
+// deprecated. Workflows keep running it; DeprecatedNodeUsages reports them
+// so they can be migrated.
+func (e *Engine) DeprecateNode(nodeType string, message string) error {
+	return e.nodeRegistry.Deprecate(nodeType, message)
+}
+
+// NodeVersions returns the registered versions of a node type in order
+func (e *Engine) NodeVersions(nodeType string) []int {
+	return e.nodeRegistry.Versions(nodeType)
+}
+
+// NodeDeprecation returns the migration note of a deprecated version of a
+// node type, named type@vN, and whether it is deprecated
+func (e *Engine) NodeDeprecation(nodeType string) (string, bool) {
+	return e.nodeRegistry.Deprecation(nodeType)
+}
+
+// DeprecatedNodeUsages returns the nodes of the workflows that run a
+// deprecated version of their type, disabled nodes included
+func (e *Engine) DeprecatedNodeUsages(workflows []models.Workflow) []DeprecatedNodeUsage {
+	usages := []DeprecatedNodeUsage{}
+	for _, workflow := range workflows {
+		for _, node := range workflow.Definition.Nodes {
+			message, deprecated := e.nodeRegistry.Deprecation(node.TypeRef())
+			if !deprecated {
+				continue
+			}
+			versions := e.nodeRegistry.Versions(node.Type)
+			usages = append(usages, DeprecatedNodeUsage{
+				WorkflowID:    workflow.ID,
+				WorkflowName:  workflow.Name,
+				NodeID:        node.ID,
+				Type:          node.Type,
+				Version:       max(node.Version, 1),
+				LatestVersion: versions[len(versions)-1],
+				Message:       message,
+			})
+		}
+	}
+	return usages
+}
@@ -0,0 +1,29 @@
+package engine
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/nuumz/f1ow/internal/models"
+)
+
+// partitionHoldTTL is how long a worker holds a job's partition without
+// renewal. Holds are renewed at a third of it, and a partition whose
+// worker stopped renewing is freed for the next job.
+var partitionHoldTTL = 30 * time.Second
+
+// JobPartition returns the queue partition of a job running the workflow
+// with input: the workflow ID and the value at the workflow's
+// partition_key path, or "" when the workflow is not partitioned or the
+// input has no value there
+func JobPartition(workflow *models.Workflow, input map[string]interface{}) string {
+	path := workflow.Definition.Settings.PartitionKey
+	if path == "" {
+		return ""
+	}
+	value := lookupPath(mappingScope(input), path)
+	if value == nil {
+		return ""
+	}
+	return fmt.Sprintf("%s/%v", workflow.ID, value)
+}
@@ -0,0 +1,195 @@
+package engine
+
+import (
+	"sort"
+
+	"github.com/nuumz/f1ow/internal/models"
+)
+
+// ExecutionPlan is the static analysis of a workflow graph
+type ExecutionPlan struct {
+	// Order is an order the nodes can run in. Nodes on or after a cycle
+	// are left out, since they can never run.
+	Order []string `json:"order"`
+	// Groups are the nodes in Order by stage: every node of a group only
+	// depends on nodes of earlier groups, so a group's nodes could run in
+	// parallel
+	Groups [][]string `json:"groups"`
+	// Cycles are the node chains of the graph's cycles, each starting and
+	// ending with the same node
+	Cycles [][]string `json:"cycles"`
+	// CriticalPath is the chain of nodes with the longest estimated total
+	// duration, which bounds how fast an execution can complete
+	CriticalPath   []string `json:"critical_path"`
+	CriticalPathMs float64  `json:"critical_path_ms"`
+	// NodeDurationsMs are the average durations the estimate uses; nodes
+	// without completed runs count as 0 and are listed in Unestimated
+	NodeDurationsMs map[string]float64 `json:"node_durations_ms"`
+	Unestimated     []string           `json:"unestimated"`
+}
+
+// PlanWorkflow analyzes the graph of a workflow definition, estimating the
+// critical path from the average duration of each node by ID
+func PlanWorkflow(definition *models.WorkflowDefinition, durations map[string]float64) *ExecutionPlan {
+	plan := &ExecutionPlan{
+		Order:           []string{},
+		Groups:          [][]string{},
+		Cycles:          findCycles(definition),
+		CriticalPath:    []string{},
+		NodeDurationsMs: map[string]float64{},
+		Unestimated:     []string{},
+	}
+
+	successors := make(map[string][]string, len(definition.Nodes))
+	predecessors := make(map[string][]string, len(definition.Nodes))
+	indegree := make(map[string]int, len(definition.Nodes))
+	for _, node := range definition.Nodes {
+		indegree[node.ID] = 0
+		if duration, ok := durations[node.ID]; ok {
+			plan.NodeDurationsMs[node.ID] = duration
+		} else {
+			plan.Unestimated = append(plan.Unestimated, node.ID)
+		}
+	}
+	for _, edge := range definition.Edges {
+		if _, ok := indegree[edge.Source]; !ok {
+			continue
+		}
+		if _, ok := indegree[edge.Target]; !ok {
+			continue
+		}
+		successors[edge.Source] = append(successors[edge.Source], edge.Target)
+		predecessors[edge.Target] = append(predecessors[edge.Target], edge.Source)
+		indegree[edge.Target]++
+	}
+
+	// Kahn's algorithm by stage, in definition order within a stage
+	var stage []string
+	for _, node := range definition.Nodes {
+		if indegree[node.ID] == 0 {
+			stage = append(stage, node.ID)
+		}
+	}
+	position := make(map[string]int, len(definition.Nodes))
+	for i, node := range definition.Nodes {
+		position[node.ID] = i
+	}
+	for len(stage) > 0 {
+		plan.Groups = append(plan.Groups, stage)
+		plan.Order = append(plan.Order, stage...)
+		var next []string
+		for _, nodeID := range stage {
+			for _, successor := range successors[nodeID] {
+				if indegree[successor]--; indegree[successor] == 0 {
+					next = append(next, successor)
+				}
+			}
+		}
+		sort.Slice(next, func(i, j int) bool { return position[next[i]] < position[next[j]] })
+		stage = next
+	}
+
+	// Longest path by duration over the ordered nodes
+	finish := make(map[string]float64, len(plan.Order))
+	previous := make(map[string]string, len(plan.Order))
+	last := ""
+	for _, nodeID := range plan.Order {
+		start := 0.0
+		for _, predecessor := range predecessors[nodeID] {
+			if end, ok := finish[predecessor]; ok && (end > start || previous[nodeID] == "") {
+				start, previous[nodeID] = end, predecessor
+			}
+		}
+		finish[nodeID] = start + durations[nodeID]
+		if last == "" || finish[nodeID] > finish[last] {
+			last = nodeID
+		}
+	}
+	for nodeID := last; nodeID != ""; nodeID = previous[nodeID] {
+		plan.CriticalPath = append([]string{nodeID}, plan.CriticalPath...)
+	}
+	plan.CriticalPathMs = finish[last]
+	return plan
+}
+
+// findCycles returns one cycle through each strongly connected component
+// of the graph that has one, found with Tarjan's algorithm
+func findCycles(definition *models.WorkflowDefinition) [][]string {
+	successors := make(map[string][]string, len(definition.Nodes))
+	for _, edge := range definition.Edges {
+		successors[edge.Source] = append(successors[edge.Source], edge.Target)
+	}
+
+	index := 0
+	indices := make(map[string]int)
+	lowlink := make(map[string]int)
+	onStack := make(map[string]bool)
+	var stack []string
+	cycles := [][]string{}
+
+	var connect func(nodeID string)
+	connect = func(nodeID string) {
+		indices[nodeID], lowlink[nodeID] = index, index
+		index++
+		stack = append(stack, nodeID)
+		onStack[nodeID] = true
+
+		for _, successor := range successors[nodeID] {
+			if _, visited := indices[successor]; !visited {
+				connect(successor)
+				lowlink[nodeID] = min(lowlink[nodeID], lowlink[successor])
+			} else if onStack[successor] {
+				lowlink[nodeID] = min(lowlink[nodeID], indices[successor])
+			}
+		}
+		if lowlink[nodeID] != indices[nodeID] {
+			return
+		}
+
+		component := make(map[string]bool)
+		for {
+			member := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			onStack[member] = false
+			component[member] = true
+			if member == nodeID {
+				break
+			}
+		}
+		if cycle := cycleThrough(nodeID, component, successors); cycle != nil {
+			cycles = append(cycles, cycle)
+		}
+	}
+	for _, node := range definition.Nodes {
+		if _, visited := indices[node.ID]; !visited {
+			connect(node.ID)
+		}
+	}
+	return cycles
+}
+
+// cycleThrough returns a cycle from start back to itself within a strongly
+// connected component, or nil for a single node without a self loop
+func cycleThrough(start string, component map[string]bool, successors map[string][]string) []string {
+	// Breadth-first from start finds the shortest way back
+	previous := map[string]string{}
+	queue := []string{start}
+	for len(queue) > 0 {
+		nodeID := queue[0]
+		queue = queue[1:]
+		for _, successor := range successors[nodeID] {
+			if successor == start {
+				cycle := []string{start}
+				for at := nodeID; at != start; at = previous[at] {
+					cycle = append([]string{at}, cycle...)
+				}
+				return append([]string{start}, cycle...)
+			}
+			if _, seen := previous[successor]; !seen && component[successor] {
+				previous[successor] = nodeID
+				queue = append(queue, successor)
+			}
+		}
+	}
+	return nil
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"sort"
 	"time"
 
 	"github.com/nuumz/f1ow/internal/storage"
@@ -12,20 +13,99 @@ import (
 	"github.com/redis/go-redis/v9"
 )
 
-// WorkQueue manages workflow execution jobs
+// Queue holds the jobs waiting for workers. Jobs run in order of score:
+// their priority, or with no priority the time they were queued. Jobs with
+// labels wait in a queue per label selector, read only by workers with
+// matching labels. Jobs with a partition wait in a list per partition: a
+// dequeued job holds its partition until released, so the partition's jobs
+// run one at a time in the order they were queued. With Fairness, each
+// tenant or workflow has a lane of every queue, and dequeues take turns
+// between lanes.
+type Queue interface {
+	// Name identifies the queue in worker records
+	Name() string
+
+	// Enqueue adds a job
+	Enqueue(ctx context.Context, job *Job) error
+
+	// Dequeue removes and returns the next job without labels, or nil when
+	// there is none
+	Dequeue(ctx context.Context) (*Job, error)
+
+	// DequeueFor removes and returns the next job a worker with the labels
+	// may run, preferring jobs pinned to its labels
+	DequeueFor(ctx context.Context, labels map[string]string) (*Job, error)
+
+	// Peek returns the next job without labels without removing it
+	Peek(ctx context.Context) (*Job, error)
+
+	// Size returns the number of jobs without labels
+	Size(ctx context.Context) (int64, error)
+
+	// Clear removes the jobs without labels
+	Clear(ctx context.Context) error
+
+	// Stats returns the backlog; the worker fields are left zero
+	Stats(ctx context.Context) (*QueueStats, error)
+
+	// ScheduleJob holds a job until executeAt
+	ScheduleJob(ctx context.Context, job *Job, executeAt time.Time) error
+
+	// ProcessDelayedJobs queues the scheduled jobs that are due
+	ProcessDelayedJobs(ctx context.Context) error
+
+	// ExtendPartition keeps the partition of a dequeued job held for ttl
+	// from now
+	ExtendPartition(ctx context.Context, job *Job, ttl time.Duration) error
+
+	// ReleasePartition frees the partition of a dequeued job that is done,
+	// so the partition's next job can be dequeued
+	ReleasePartition(ctx context.Context, job *Job) error
+}
+
+// WithQueue sets the queue jobs wait in. Engines use a WorkQueue with
+// Redis and a MemoryQueue without.
+func WithQueue(queue Queue) Option {
+	return func(e *Engine) {
+		e.queue = queue
+	}
+}
+
+// metricsRecorder is implemented by queues that record queue metrics
+type metricsRecorder interface {
+	setMetrics(metrics *Metrics)
+}
+
+// jobScore returns the score that orders a job in its queue: its priority,
+// or the time it is queued when it has none
+func jobScore(job *Job) float64 {
+	if job.Priority == 0 {
+		return float64(time.Now().UnixNano())
+	}
+	return float64(job.Priority)
+}
+
+// WorkQueue is a Queue in Redis, shared by a fleet of workers
 type WorkQueue struct {
 	redis    *storage.RedisClient
 	queueKey string
+	fairness Fairness
+	metrics  *Metrics // nil records nothing
 }
 
 // Job represents a workflow execution job
 type Job struct {
-	ID         string                 `json:"id"`
-	WorkflowID string                 `json:"workflow_id"`
-	Input      map[string]interface{} `json:"input"`
-	Priority   int                    `json:"priority"`
-	CreatedAt  time.Time              `json:"created_at"`
-	Metadata   map[string]interface{} `json:"metadata"`
+	ID             string                 `json:"id"`
+	WorkflowID     string                 `json:"workflow_id"`
+	ExecutionID    string                 `json:"execution_id,omitempty"` // assigned when queued; the worker runs the execution under this ID
+	TenantID       string                 `json:"tenant_id,omitempty"`    // scopes the job to the tenant's workflows
+	Input          map[string]interface{} `json:"input"`
+	Priority       int                    `json:"priority"`
+	CreatedAt      time.Time              `json:"created_at"`
+	Metadata       map[string]interface{} `json:"metadata"`
+	Labels         map[string]string      `json:"labels,omitempty"`    // only workers with these labels take the job
+	Partition      string                 `json:"partition,omitempty"` // jobs of a partition run one at a time, in order
+	IdempotencyKey string                 `json:"idempotency_key,omitempty"`
 }
 
 // JobResult represents the result of a job execution
@@ -45,6 +125,21 @@ func NewWorkQueue(redis *storage.RedisClient) *WorkQueue {
 	}
 }
 
+// Name returns the Redis key of the queue
+func (q *WorkQueue) Name() string {
+	return q.queueKey
+}
+
+func (q *WorkQueue) setMetrics(metrics *Metrics) {
+	q.metrics = metrics
+}
+
+// SetFairness sets how dequeues are shared between tenants or workflows.
+// Jobs already queued stay in their lanes and are still dequeued.
+func (q *WorkQueue) SetFairness(fairness Fairness) {
+	q.fairness = fairness
+}
+
 // Enqueue adds a job to the queue
 func (q *WorkQueue) Enqueue(ctx context.Context, job *Job) error {
 	if job.ID == "" {
@@ -59,55 +154,106 @@ func (q *WorkQueue) Enqueue(ctx context.Context, job *Job) error {
 	}
 
 	// Add to Redis sorted set with priority as score
-	score := float64(job.Priority)
-	if job.Priority == 0 {
-		// Use timestamp for FIFO when no priority
-		score = float64(time.Now().UnixNano())
-	}
+	score := jobScore(job)
 
+	// Jobs with labels wait in a queue of their own that only matching
+	// workers read
 	client := q.redis.Client()
-	err = client.ZAdd(ctx, q.queueKey, redis.Z{
-		Score:  score,
-		Member: string(data),
-	}).Err()
+	baseKey := q.baseQueue(job)
+	queueKey := laneQueue(baseKey, q.fairness.lane(job))
+	if len(job.Labels) > 0 {
+		if err := client.SAdd(ctx, q.selectorsKey(), formatLabels(job.Labels)).Err(); err != nil {
+			return fmt.Errorf("failed to enqueue job: %w", err)
+		}
+	}
+	if job.Partition != "" {
+		err = enqueuePartitionScript.Run(ctx, client,
+			[]string{readyPartitions(queueKey), heldPartitions(queueKey), partitionList(queueKey, job.Partition)},
+			job.Partition, score, string(data)).Err()
+	} else {
+		err = client.ZAdd(ctx, queueKey, redis.Z{
+			Score:  score,
+			Member: string(data),
+		}).Err()
+	}
 
+	if err == nil && q.fairness.By != "" {
+		err = activateLaneScript.Run(ctx, client, []string{lanesKey(baseKey)}, q.fairness.lane(job)).Err()
+	}
 	if err != nil {
 		return fmt.Errorf("failed to enqueue job: %w", err)
 	}
-
-	// Publish notification for workers
-	err = client.Publish(ctx, "workflow:job:new", job.ID).Err()
-	if err != nil {
-		// Non-fatal, workers will still poll
-		return nil
+	if q.metrics != nil {
+		q.metrics.JobsEnqueued.Inc()
 	}
 
 	return nil
 }
 
-// Dequeue retrieves and removes the next job from the queue
+// Dequeue retrieves and removes the next job without labels from the queue
 func (q *WorkQueue) Dequeue(ctx context.Context) (*Job, error) {
+	return q.pop(ctx, q.queueKey)
+}
+
+// DequeueFor retrieves and removes the next job a worker with the given
+// labels may run. Jobs pinned to the worker's labels come before unlabeled
+// ones, so workers with scarce resources serve the jobs that need them.
+func (q *WorkQueue) DequeueFor(ctx context.Context, labels map[string]string) (*Job, error) {
+	if len(labels) > 0 {
+		selectors, err := q.redis.Client().SMembers(ctx, q.selectorsKey()).Result()
+		if err != nil {
+			return nil, fmt.Errorf("failed to dequeue job: %w", err)
+		}
+		sort.Strings(selectors)
+
+		for _, selector := range selectors {
+			required, err := ParseLabels(selector)
+			if err != nil || !matchesSelector(labels, required) {
+				continue
+			}
+			job, err := q.pop(ctx, q.labeledQueue(selector))
+			if err != nil || job != nil {
+				return job, err
+			}
+		}
+	}
+	return q.pop(ctx, q.queueKey)
+}
+
+// pop retrieves and removes the next job from the queue at key, from the
+// lane whose turn it is: the job with the lowest score, or the head of the
+// ready partition that came first, holding that partition
+func (q *WorkQueue) pop(ctx context.Context, key string) (*Job, error) {
 	client := q.redis.Client()
 
-	// Get highest priority job (lowest score)
-	result, err := client.ZPopMin(ctx, q.queueKey, 1).Result()
+	now := time.Now()
+	result, err := popJobScript.Run(ctx, client, []string{lanesKey(key)},
+		key, now.UnixMilli(), now.Add(partitionHoldTTL).UnixMilli(), float64(now.UnixNano())).StringSlice()
 	if err != nil {
 		if err == redis.Nil {
 			return nil, nil // Empty queue
 		}
 		return nil, fmt.Errorf("failed to dequeue job: %w", err)
 	}
+	lane, data := result[0], result[1]
 
-	if len(result) == 0 {
-		return nil, nil // Empty queue
-	}
+	// Move the lane back in turn. Losing this update to an error only
+	// skews the share of one dequeue, so the job is returned regardless.
+	client.ZAddArgsIncr(ctx, lanesKey(key), redis.ZAddArgs{
+		XX:      true,
+		Members: []redis.Z{{Score: q.fairness.stride(lane), Member: lane}},
+	})
 
 	// Deserialize job
 	var job Job
-	err = json.Unmarshal([]byte(result[0].Member.(string)), &job)
+	err = json.Unmarshal([]byte(data), &job)
 	if err != nil {
 		return nil, fmt.Errorf("failed to unmarshal job: %w", err)
 	}
+	if q.metrics != nil {
+		q.metrics.JobsDequeued.Inc()
+		q.metrics.JobWaitTime.Observe(time.Since(job.CreatedAt).Seconds())
+	}
 
 	return &job, nil
 }
@@ -115,37 +261,195 @@ func (q *WorkQueue) Dequeue(ctx context.Context) (*Job, error) {
 // Peek returns the next job without removing it
 func (q *WorkQueue) Peek(ctx context.Context) (*Job, error) {
 	client := q.redis.Client()
-
-	// Get highest priority job without removing
-	result, err := client.ZRangeWithScores(ctx, q.queueKey, 0, 0).Result()
+	lanes, err := q.laneQueues(ctx, q.queueKey)
 	if err != nil {
 		return nil, fmt.Errorf("failed to peek job: %w", err)
 	}
 
-	if len(result) == 0 {
-		return nil, nil // Empty queue
-	}
+	// Get the highest priority job of the first lane with one, without
+	// removing it
+	for _, key := range lanes {
+		result, err := client.ZRange(ctx, key, 0, 0).Result()
+		if err != nil {
+			return nil, fmt.Errorf("failed to peek job: %w", err)
+		}
+		if len(result) == 0 {
+			continue
+		}
 
-	// Deserialize job
-	var job Job
-	err = json.Unmarshal([]byte(result[0].Member.(string)), &job)
-	if err != nil {
-		return nil, fmt.Errorf("failed to unmarshal job: %w", err)
+		// Deserialize job
+		var job Job
+		if err := json.Unmarshal([]byte(result[0]), &job); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal job: %w", err)
+		}
+		return &job, nil
 	}
-
-	return &job, nil
+	return nil, nil // Empty queue
 }
 
 // Size returns the number of jobs in the queue
 func (q *WorkQueue) Size(ctx context.Context) (int64, error) {
-	client := q.redis.Client()
-	return client.ZCard(ctx, q.queueKey).Result()
+	size, _, err := q.backlog(ctx, q.queueKey)
+	return size, err
 }
 
 // Clear removes all jobs from the queue
 func (q *WorkQueue) Clear(ctx context.Context) error {
 	client := q.redis.Client()
-	return client.Del(ctx, q.queueKey).Err()
+	lanes, err := q.laneQueues(ctx, q.queueKey)
+	if err != nil {
+		return err
+	}
+	keys := []string{lanesKey(q.queueKey)}
+	for _, key := range lanes {
+		partitions, err := q.partitions(ctx, key)
+		if err != nil {
+			return err
+		}
+		keys = append(keys, key, readyPartitions(key), heldPartitions(key))
+		for _, partition := range partitions {
+			keys = append(keys, partitionList(key, partition))
+		}
+	}
+	return client.Del(ctx, keys...).Err()
+}
+
+// QueueStats describes the backlog of the queue and the fleet serving it,
+// for dashboards and worker autoscalers
+type QueueStats struct {
+	Pending      int64            `json:"pending"`                // jobs waiting in all queues
+	Delayed      int64            `json:"delayed"`                // scheduled jobs not yet due
+	Queues       map[string]int64 `json:"queues"`                 // waiting jobs by queue: "default" or a label selector
+	OldestJobAge float64          `json:"oldest_job_age_seconds"` // wait so far of the oldest job next in line
+	Workers      int              `json:"workers"`                // live workers
+	Capacity     int              `json:"capacity"`               // jobs the workers can run at once
+	ActiveJobs   int              `json:"active_jobs"`            // jobs the workers are running
+	Utilization  float64          `json:"utilization"`            // ActiveJobs / Capacity, 0 without workers
+}
+
+// Stats returns the queue's backlog; the worker fields are left zero
+func (q *WorkQueue) Stats(ctx context.Context) (*QueueStats, error) {
+	client := q.redis.Client()
+	selectors, err := client.SMembers(ctx, q.selectorsKey()).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read queue stats: %w", err)
+	}
+	sort.Strings(selectors)
+
+	stats := &QueueStats{Queues: make(map[string]int64, len(selectors)+1)}
+	keys := map[string]string{"default": q.queueKey}
+	for _, selector := range selectors {
+		keys[selector] = q.labeledQueue(selector)
+	}
+
+	now := time.Now()
+	for name, key := range keys {
+		size, oldest, err := q.backlog(ctx, key)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read queue stats: %w", err)
+		}
+		if !oldest.IsZero() {
+			if age := now.Sub(oldest).Seconds(); age > stats.OldestJobAge {
+				stats.OldestJobAge = age
+			}
+		}
+		if size == 0 && name != "default" {
+			continue
+		}
+		stats.Queues[name] = size
+		stats.Pending += size
+	}
+
+	if stats.Delayed, err = client.ZCard(ctx, q.GetDelayedQueue()).Result(); err != nil {
+		return nil, fmt.Errorf("failed to read queue stats: %w", err)
+	}
+	if q.metrics != nil {
+		q.metrics.QueueSize.Set(float64(stats.Pending))
+	}
+	return stats, nil
+}
+
+// backlog returns the number of jobs waiting in every lane of the queue at
+// key, and when the oldest job next in line of a lane or partition was
+// queued
+func (q *WorkQueue) backlog(ctx context.Context, key string) (int64, time.Time, error) {
+	client := q.redis.Client()
+	lanes, err := q.laneQueues(ctx, key)
+	if err != nil {
+		return 0, time.Time{}, err
+	}
+
+	var size int64
+	var oldest time.Time
+	for _, lane := range lanes {
+		queued, err := client.ZCard(ctx, lane).Result()
+		if err != nil {
+			return 0, time.Time{}, err
+		}
+		partitioned, head, err := q.partitionBacklog(ctx, lane)
+		if err != nil {
+			return 0, time.Time{}, err
+		}
+		size += queued + partitioned
+
+		jobs, err := client.ZRange(ctx, lane, 0, 0).Result()
+		if err != nil {
+			return 0, time.Time{}, err
+		}
+		var job Job
+		if len(jobs) > 0 && json.Unmarshal([]byte(jobs[0]), &job) == nil && (head.IsZero() || job.CreatedAt.Before(head)) {
+			head = job.CreatedAt
+		}
+		if !head.IsZero() && (oldest.IsZero() || head.Before(oldest)) {
+			oldest = head
+		}
+	}
+	return size, oldest, nil
+}
+
+// laneQueues returns the keys of the lanes of the queue at key, in turn
+// order, followed by the key itself when it is not a lane
+func (q *WorkQueue) laneQueues(ctx context.Context, key string) ([]string, error) {
+	lanes, err := q.redis.Client().ZRange(ctx, lanesKey(key), 0, -1).Result()
+	if err != nil {
+		return nil, err
+	}
+	keys := make([]string, 0, len(lanes)+1)
+	unlaned := true
+	for _, lane := range lanes {
+		keys = append(keys, laneQueue(key, lane))
+		unlaned = unlaned && lane != ""
+	}
+	if unlaned {
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+// baseQueue returns the key of the queue for the job's labels, whose lanes
+// the job waits in
+func (q *WorkQueue) baseQueue(job *Job) string {
+	if len(job.Labels) > 0 {
+		return q.labeledQueue(formatLabels(job.Labels))
+	}
+	return q.queueKey
+}
+
+// jobQueue returns the key of the queue the job waits in: its lane of the
+// queue for its labels
+func (q *WorkQueue) jobQueue(job *Job) string {
+	return laneQueue(q.baseQueue(job), q.fairness.lane(job))
+}
+
+// labeledQueue returns the key of the queue for jobs with the selector
+func (q *WorkQueue) labeledQueue(selector string) string {
+	return q.queueKey + ":labels:" + selector
+}
+
+// selectorsKey returns the key of the set of selectors that have labeled
+// queues
+func (q *WorkQueue) selectorsKey() string {
+	return q.queueKey + ":selectors"
 }
 
 // GetDelayedQueue returns the key for delayed jobs
@@ -201,14 +505,234 @@ func (q *WorkQueue) ProcessDelayedJobs(ctx context.Context) error {
 			continue
 		}
 
-		// Add to main queue
+		// Remove from the delayed queue first, so of the workers moving
+		// due jobs at once only one queues each
+		removed, err := client.ZRem(ctx, q.GetDelayedQueue(), data).Result()
+		if err != nil || removed == 0 {
+			continue
+		}
+
+		// Add to main queue, putting the job back when that fails
 		if err := q.Enqueue(ctx, &job); err != nil {
+			client.ZAdd(ctx, q.GetDelayedQueue(), redis.Z{Score: float64(now), Member: data})
 			continue
 		}
+	}
+
+	return nil
+}
 
-		// Remove from delayed queue
-		client.ZRem(ctx, q.GetDelayedQueue(), data)
+// ExtendPartition keeps the job's partition held for ttl from now
+func (q *WorkQueue) ExtendPartition(ctx context.Context, job *Job, ttl time.Duration) error {
+	if job.Partition == "" {
+		return nil
+	}
+	err := q.redis.Client().ZAddXX(ctx, heldPartitions(q.jobQueue(job)), redis.Z{
+		Score:  float64(time.Now().Add(ttl).UnixMilli()),
+		Member: job.Partition,
+	}).Err()
+	if err != nil {
+		return fmt.Errorf("failed to extend partition %s: %w", job.Partition, err)
 	}
+	return nil
+}
 
+// ReleasePartition frees the job's partition, making it ready again when
+// it has jobs waiting
+func (q *WorkQueue) ReleasePartition(ctx context.Context, job *Job) error {
+	if job.Partition == "" {
+		return nil
+	}
+	key := q.jobQueue(job)
+	err := releasePartitionScript.Run(ctx, q.redis.Client(),
+		[]string{readyPartitions(key), heldPartitions(key), partitionList(key, job.Partition)},
+		job.Partition, float64(time.Now().UnixNano())).Err()
+	if err != nil {
+		return fmt.Errorf("failed to release partition %s: %w", job.Partition, err)
+	}
 	return nil
 }
+
+// partitions returns the partitions of the queue at key that are ready or
+// held
+func (q *WorkQueue) partitions(ctx context.Context, key string) ([]string, error) {
+	client := q.redis.Client()
+	ready, err := client.ZRange(ctx, readyPartitions(key), 0, -1).Result()
+	if err != nil {
+		return nil, err
+	}
+	held, err := client.ZRange(ctx, heldPartitions(key), 0, -1).Result()
+	if err != nil {
+		return nil, err
+	}
+	return append(ready, held...), nil
+}
+
+// partitionBacklog returns the number of jobs waiting in the partitions of
+// the queue at key, and when the oldest partition head was queued
+func (q *WorkQueue) partitionBacklog(ctx context.Context, key string) (int64, time.Time, error) {
+	partitions, err := q.partitions(ctx, key)
+	if err != nil || len(partitions) == 0 {
+		return 0, time.Time{}, err
+	}
+
+	pipe := q.redis.Client().Pipeline()
+	lengths := make([]*redis.IntCmd, len(partitions))
+	heads := make([]*redis.StringCmd, len(partitions))
+	for i, partition := range partitions {
+		lengths[i] = pipe.LLen(ctx, partitionList(key, partition))
+		heads[i] = pipe.LIndex(ctx, partitionList(key, partition), 0)
+	}
+	if _, err := pipe.Exec(ctx); err != nil && err != redis.Nil {
+		return 0, time.Time{}, err
+	}
+
+	var size int64
+	var oldest time.Time
+	for i := range partitions {
+		size += lengths[i].Val()
+		var job Job
+		if heads[i].Err() == nil && json.Unmarshal([]byte(heads[i].Val()), &job) == nil {
+			if oldest.IsZero() || job.CreatedAt.Before(oldest) {
+				oldest = job.CreatedAt
+			}
+		}
+	}
+	return size, oldest, nil
+}
+
+// lanesKey returns the key of the sorted set of lanes of the queue at key
+// with jobs waiting, scored by their pass: lanes are served lowest pass
+// first, and each dequeue adds the lane's stride
+func lanesKey(key string) string {
+	return key + ":lanes"
+}
+
+// laneQueue returns the key of a lane of the queue at key. The "" lane is
+// the queue itself.
+func laneQueue(key, lane string) string {
+	if lane == "" {
+		return key
+	}
+	return key + ":lane:" + lane
+}
+
+// readyPartitions returns the key of the sorted set of partitions of the
+// queue at key whose next job may be dequeued, scored by when it became
+// ready or by its priority
+func readyPartitions(key string) string {
+	return key + ":partitions"
+}
+
+// heldPartitions returns the key of the sorted set of partitions of the
+// queue at key with a job running, scored by when their hold expires in
+// Unix milliseconds
+func heldPartitions(key string) string {
+	return key + ":partitions:held"
+}
+
+// partitionList returns the key of the list of jobs of a partition of the
+// queue at key
+func partitionList(key, partition string) string {
+	return key + ":partition:" + partition
+}
+
+// enqueuePartitionScript appends a job to its partition's list and makes
+// the partition ready unless it is held.
+// KEYS: ready partitions, held partitions, partition list.
+// ARGV: partition, score, job.
+var enqueuePartitionScript = redis.NewScript(`
+redis.call('RPUSH', KEYS[3], ARGV[3])
+if not redis.call('ZSCORE', KEYS[2], ARGV[1]) then
+	redis.call('ZADD', KEYS[1], 'NX', ARGV[2], ARGV[1])
+end
+return 1
+`)
+
+// popLua defines pop, which frees the partitions of the queue at key whose
+// hold expired, then removes and returns the next job: the queued job with
+// the lowest score, or the head of the first ready partition, which it
+// holds. Its partition keys are those of readyPartitions, heldPartitions,
+// and partitionList.
+// Arguments: queue key, now and hold expiry in Unix milliseconds, ready
+// score for freed partitions.
+const popLua = `
+local function pop(key, now, expiry, score)
+	local ready, held, list = key .. ':partitions', key .. ':partitions:held', key .. ':partition:'
+	for _, partition in ipairs(redis.call('ZRANGEBYSCORE', held, '-inf', now)) do
+		redis.call('ZREM', held, partition)
+		if redis.call('LLEN', list .. partition) > 0 then
+			redis.call('ZADD', ready, 'NX', score, partition)
+		end
+	end
+
+	local job = redis.call('ZRANGE', key, 0, 0, 'WITHSCORES')
+	local head = redis.call('ZRANGE', ready, 0, 0, 'WITHSCORES')
+	if #head > 0 and (#job == 0 or tonumber(head[2]) < tonumber(job[2])) then
+		redis.call('ZREM', ready, head[1])
+		local data = redis.call('LPOP', list .. head[1])
+		if data then
+			redis.call('ZADD', held, expiry, head[1])
+			return data
+		end
+	end
+	if #job > 0 then
+		redis.call('ZREM', key, job[1])
+		return job[1]
+	end
+	return false
+end
+`
+
+// popJobScript pops the next job of the lanes of a queue, lowest pass
+// first, then of the queue itself, and returns the lane and the job. Lanes
+// left without jobs or held partitions are removed.
+// KEYS: lanes.
+// ARGV: queue key, now and hold expiry in Unix milliseconds, ready score
+// for freed partitions.
+var popJobScript = redis.NewScript(popLua + `
+for _, lane in ipairs(redis.call('ZRANGE', KEYS[1], 0, -1)) do
+	local key = ARGV[1]
+	if lane ~= '' then
+		key = key .. ':lane:' .. lane
+	end
+	local data = pop(key, ARGV[2], ARGV[3], ARGV[4])
+	if data then
+		return {lane, data}
+	end
+	if redis.call('ZCARD', key) == 0 and redis.call('ZCARD', key .. ':partitions') == 0 and redis.call('ZCARD', key .. ':partitions:held') == 0 then
+		redis.call('ZREM', KEYS[1], lane)
+	end
+end
+
+local data = pop(ARGV[1], ARGV[2], ARGV[3], ARGV[4])
+if data then
+	return {'', data}
+end
+return false
+`)
+
+// activateLaneScript adds a lane to the lanes of a queue unless present,
+// at the pass of the lane next in turn, so a lane that was idle neither
+// jumps the others nor waits behind the passes they built up.
+// KEYS: lanes.
+// ARGV: lane.
+var activateLaneScript = redis.NewScript(`
+if not redis.call('ZSCORE', KEYS[1], ARGV[1]) then
+	local first = redis.call('ZRANGE', KEYS[1], 0, 0, 'WITHSCORES')
+	redis.call('ZADD', KEYS[1], first[2] or 0, ARGV[1])
+end
+return 1
+`)
+
+// releasePartitionScript ends the hold on a partition and makes it ready
+// when jobs are waiting in it.
+// KEYS: ready partitions, held partitions, partition list.
+// ARGV: partition, ready score.
+var releasePartitionScript = redis.NewScript(`
+redis.call('ZREM', KEYS[2], ARGV[1])
+if redis.call('LLEN', KEYS[3]) > 0 then
+	redis.call('ZADD', KEYS[1], 'NX', ARGV[2], ARGV[1])
+end
+return 1
+`)
@@ -0,0 +1,562 @@
+package engine
+
+import (
+	"container/heap"
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/nuumz/f1ow/internal/storage"
+
+	"github.com/google/uuid"
+)
+
+// Names of the persisted queues that are not label selectors, which always
+// contain "="
+const (
+	memoryDefaultQueue = "default"
+	memoryDelayedQueue = "delayed"
+)
+
+// QueueStore persists the jobs of a MemoryQueue so they survive restarts
+type QueueStore interface {
+	SaveQueuedJob(ctx context.Context, job storage.QueuedJob) error
+	DeleteQueuedJob(ctx context.Context, id string) error
+	ListQueuedJobs(ctx context.Context) ([]storage.QueuedJob, error)
+}
+
+// MemoryQueue is a Queue held in this process, for single-process
+// deployments without Redis. Only workers in the same process can take its
+// jobs. With a store, every queued job is also written to the database and
+// Load restores them after a restart.
+type MemoryQueue struct {
+	mu       sync.Mutex
+	queues   map[string]map[string]*jobLane // by queue name: memoryDefaultQueue or a label selector, then lane
+	delayed  *jobHeap
+	seq      uint64
+	fairness Fairness
+	store    QueueStore // nil keeps jobs in memory only
+	metrics  *Metrics   // nil records nothing
+}
+
+// NewMemoryQueue creates an in-process queue; store may be nil
+func NewMemoryQueue(store QueueStore) *MemoryQueue {
+	return &MemoryQueue{
+		queues:  map[string]map[string]*jobLane{},
+		delayed: &jobHeap{},
+		store:   store,
+	}
+}
+
+// Load restores the jobs persisted in the store
+func (q *MemoryQueue) Load(ctx context.Context) error {
+	if q.store == nil {
+		return nil
+	}
+	persisted, err := q.store.ListQueuedJobs(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to load queued jobs: %w", err)
+	}
+	// Partitions list their jobs in the order they were queued
+	sort.SliceStable(persisted, func(i, j int) bool { return persisted[i].CreatedAt.Before(persisted[j].CreatedAt) })
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for _, entry := range persisted {
+		var job Job
+		if err := json.Unmarshal(entry.Payload, &job); err != nil {
+			return fmt.Errorf("failed to unmarshal queued job %s: %w", entry.ID, err)
+		}
+		q.push(entry.Queue, &queuedJob{entryID: entry.ID, job: &job, score: entry.Score})
+	}
+	return nil
+}
+
+// Name returns "memory"
+func (q *MemoryQueue) Name() string {
+	return "memory"
+}
+
+func (q *MemoryQueue) setMetrics(metrics *Metrics) {
+	q.metrics = metrics
+}
+
+// SetFairness sets how dequeues are shared between tenants or workflows
+// and moves the queued jobs to their lanes. Set it before workers start
+// dequeuing: partitions held by running jobs are not carried over.
+func (q *MemoryQueue) SetFairness(fairness Fairness) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	queues := q.queues
+	q.queues = map[string]map[string]*jobLane{}
+	q.fairness = fairness
+	for name, lanes := range queues {
+		for _, lane := range lanes {
+			for _, entry := range lane.jobs {
+				q.place(name, entry)
+			}
+			for _, partition := range lane.partitions {
+				for _, entry := range partition.jobs {
+					q.place(name, entry)
+				}
+			}
+		}
+	}
+}
+
+// Enqueue adds a job to the queue
+func (q *MemoryQueue) Enqueue(ctx context.Context, job *Job) error {
+	if job.ID == "" {
+		job.ID = uuid.New().String()
+	}
+	job.CreatedAt = time.Now()
+
+	name := memoryDefaultQueue
+	if len(job.Labels) > 0 {
+		name = formatLabels(job.Labels)
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if err := q.add(ctx, name, job, jobScore(job)); err != nil {
+		return fmt.Errorf("failed to enqueue job: %w", err)
+	}
+	if q.metrics != nil {
+		q.metrics.JobsEnqueued.Inc()
+	}
+	return nil
+}
+
+// Dequeue retrieves and removes the next job without labels from the queue
+func (q *MemoryQueue) Dequeue(ctx context.Context) (*Job, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.pop(ctx, memoryDefaultQueue)
+}
+
+// DequeueFor retrieves and removes the next job a worker with the given
+// labels may run, taking jobs pinned to its labels first
+func (q *MemoryQueue) DequeueFor(ctx context.Context, labels map[string]string) (*Job, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if len(labels) > 0 {
+		for _, selector := range q.selectors() {
+			required, err := ParseLabels(selector)
+			if err != nil || !matchesSelector(labels, required) {
+				continue
+			}
+			job, err := q.pop(ctx, selector)
+			if err != nil || job != nil {
+				return job, err
+			}
+		}
+	}
+	return q.pop(ctx, memoryDefaultQueue)
+}
+
+// Peek returns the next job without labels without removing it
+func (q *MemoryQueue) Peek(ctx context.Context) (*Job, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for _, lane := range q.turns(memoryDefaultQueue) {
+		if entry, _ := lane.next(); entry != nil {
+			job := *entry.job
+			return &job, nil
+		}
+	}
+	return nil, nil
+}
+
+// Size returns the number of jobs without labels
+func (q *MemoryQueue) Size(ctx context.Context) (int64, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	var size int64
+	for _, lane := range q.queues[memoryDefaultQueue] {
+		size += lane.size()
+	}
+	return size, nil
+}
+
+// Clear removes all jobs without labels
+func (q *MemoryQueue) Clear(ctx context.Context) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	lanes := q.queues[memoryDefaultQueue]
+	for name, lane := range lanes {
+		for lane.jobs.Len() > 0 {
+			if err := q.unpersist(ctx, lane.jobs[0].entryID); err != nil {
+				return fmt.Errorf("failed to clear queue: %w", err)
+			}
+			heap.Pop(&lane.jobs)
+		}
+		for key, partition := range lane.partitions {
+			for len(partition.jobs) > 0 {
+				if err := q.unpersist(ctx, partition.jobs[0].entryID); err != nil {
+					return fmt.Errorf("failed to clear queue: %w", err)
+				}
+				partition.jobs = partition.jobs[1:]
+			}
+			if !partition.held {
+				delete(lane.partitions, key)
+			}
+		}
+		if lane.empty() {
+			delete(lanes, name)
+		}
+	}
+	return nil
+}
+
+// Stats returns the queue's backlog; the worker fields are left zero
+func (q *MemoryQueue) Stats(ctx context.Context) (*QueueStats, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	stats := &QueueStats{Queues: map[string]int64{memoryDefaultQueue: 0}, Delayed: int64(q.delayed.Len())}
+	now := time.Now()
+	oldest := func(job *Job) {
+		if age := now.Sub(job.CreatedAt).Seconds(); age > stats.OldestJobAge {
+			stats.OldestJobAge = age
+		}
+	}
+	for name, lanes := range q.queues {
+		for _, lane := range lanes {
+			if lane.jobs.Len() > 0 {
+				stats.Queues[name] += int64(lane.jobs.Len())
+				oldest(lane.jobs[0].job)
+			}
+			for _, partition := range lane.partitions {
+				if len(partition.jobs) > 0 {
+					stats.Queues[name] += int64(len(partition.jobs))
+					oldest(partition.jobs[0].job)
+				}
+			}
+		}
+	}
+	for _, size := range stats.Queues {
+		stats.Pending += size
+	}
+	if q.metrics != nil {
+		q.metrics.QueueSize.Set(float64(stats.Pending))
+	}
+	return stats, nil
+}
+
+// ScheduleJob holds a job until executeAt
+func (q *MemoryQueue) ScheduleJob(ctx context.Context, job *Job, executeAt time.Time) error {
+	if job.ID == "" {
+		job.ID = uuid.New().String()
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if err := q.add(ctx, memoryDelayedQueue, job, float64(executeAt.Unix())); err != nil {
+		return fmt.Errorf("failed to schedule job: %w", err)
+	}
+	return nil
+}
+
+// ProcessDelayedJobs moves the scheduled jobs that are due to the queue
+func (q *MemoryQueue) ProcessDelayedJobs(ctx context.Context) error {
+	now := float64(time.Now().Unix())
+	for {
+		q.mu.Lock()
+		if q.delayed.Len() == 0 || (*q.delayed)[0].score > now {
+			q.mu.Unlock()
+			return nil
+		}
+		entry := (*q.delayed)[0]
+		if err := q.unpersist(ctx, entry.entryID); err != nil {
+			q.mu.Unlock()
+			return fmt.Errorf("failed to move delayed job: %w", err)
+		}
+		heap.Pop(q.delayed)
+		q.mu.Unlock()
+
+		if err := q.Enqueue(ctx, entry.job); err != nil {
+			return err
+		}
+	}
+}
+
+// add queues job under name with score and persists it. q.mu must be held.
+func (q *MemoryQueue) add(ctx context.Context, name string, job *Job, score float64) error {
+	entry := &queuedJob{entryID: uuid.New().String(), job: job, score: score}
+	if q.store != nil {
+		payload, err := json.Marshal(job)
+		if err != nil {
+			return fmt.Errorf("failed to marshal job: %w", err)
+		}
+		err = q.store.SaveQueuedJob(ctx, storage.QueuedJob{
+			ID:        entry.entryID,
+			Queue:     name,
+			Score:     score,
+			Payload:   payload,
+			CreatedAt: time.Now(),
+		})
+		if err != nil {
+			return err
+		}
+	}
+	q.push(name, entry)
+	return nil
+}
+
+// push adds an entry to the named queue. q.mu must be held.
+func (q *MemoryQueue) push(name string, entry *queuedJob) {
+	q.seq++
+	entry.seq = q.seq
+	if name == memoryDelayedQueue {
+		heap.Push(q.delayed, entry)
+		return
+	}
+	q.place(name, entry)
+}
+
+// place adds an entry to its lane of the named queue. q.mu must be held.
+func (q *MemoryQueue) place(name string, entry *queuedJob) {
+	lane := q.lane(name, q.fairness.lane(entry.job), true)
+	if entry.job.Partition != "" {
+		partition := lane.partition(entry.job.Partition, true)
+		partition.jobs = append(partition.jobs, entry)
+		return
+	}
+	heap.Push(&lane.jobs, entry)
+}
+
+// pop removes the next job from the named queue, from the first lane in
+// turn that has one: the job with the lowest score, or the head of the
+// unheld partition with the lowest score, which it holds. The job stays
+// queued when it cannot be removed from the store. q.mu must be held.
+func (q *MemoryQueue) pop(ctx context.Context, name string) (*Job, error) {
+	for _, lane := range q.turns(name) {
+		entry, next := lane.next()
+		if entry == nil {
+			if lane.empty() {
+				delete(q.queues[name], lane.name)
+			}
+			continue
+		}
+		if err := q.unpersist(ctx, entry.entryID); err != nil {
+			return nil, fmt.Errorf("failed to dequeue job: %w", err)
+		}
+		if next != nil {
+			next.jobs = next.jobs[1:]
+			next.held = true
+		} else {
+			heap.Pop(&lane.jobs)
+		}
+		lane.pass += q.fairness.stride(lane.name)
+		if lane.empty() {
+			delete(q.queues[name], lane.name)
+		}
+
+		if q.metrics != nil {
+			q.metrics.JobsDequeued.Inc()
+			q.metrics.JobWaitTime.Observe(time.Since(entry.job.CreatedAt).Seconds())
+		}
+		return entry.job, nil
+	}
+	return nil, nil
+}
+
+// ExtendPartition does nothing: partitions of a MemoryQueue stay held until
+// released, since only workers in this process hold them
+func (q *MemoryQueue) ExtendPartition(ctx context.Context, job *Job, ttl time.Duration) error {
+	return nil
+}
+
+// ReleasePartition frees the job's partition for its next job
+func (q *MemoryQueue) ReleasePartition(ctx context.Context, job *Job) error {
+	if job.Partition == "" {
+		return nil
+	}
+	name := memoryDefaultQueue
+	if len(job.Labels) > 0 {
+		name = formatLabels(job.Labels)
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	lane := q.lane(name, q.fairness.lane(job), false)
+	if lane == nil {
+		return nil
+	}
+	if partition := lane.partition(job.Partition, false); partition != nil {
+		partition.held = false
+		if len(partition.jobs) == 0 {
+			delete(lane.partitions, job.Partition)
+		}
+	}
+	if lane.empty() {
+		delete(q.queues[name], lane.name)
+	}
+	return nil
+}
+
+// lane returns a lane of the named queue, creating it when create is set,
+// or nil. A new lane takes the pass of the lane next in turn, so a lane
+// that was idle neither jumps the others nor waits behind the passes they
+// built up. q.mu must be held.
+func (q *MemoryQueue) lane(name, key string, create bool) *jobLane {
+	lanes, ok := q.queues[name]
+	if !ok {
+		if !create {
+			return nil
+		}
+		lanes = map[string]*jobLane{}
+		q.queues[name] = lanes
+	}
+	lane, ok := lanes[key]
+	if !ok && create {
+		lane = &jobLane{name: key, partitions: map[string]*jobPartition{}}
+		if turns := q.turns(name); len(turns) > 0 {
+			lane.pass = turns[0].pass
+		}
+		lanes[key] = lane
+	}
+	return lane
+}
+
+// turns returns the lanes of the named queue in turn order: lowest pass
+// first. q.mu must be held.
+func (q *MemoryQueue) turns(name string) []*jobLane {
+	lanes := make([]*jobLane, 0, len(q.queues[name]))
+	for _, lane := range q.queues[name] {
+		lanes = append(lanes, lane)
+	}
+	sort.Slice(lanes, func(i, j int) bool {
+		if lanes[i].pass != lanes[j].pass {
+			return lanes[i].pass < lanes[j].pass
+		}
+		return lanes[i].name < lanes[j].name
+	})
+	return lanes
+}
+
+func (q *MemoryQueue) unpersist(ctx context.Context, entryID string) error {
+	if q.store == nil {
+		return nil
+	}
+	return q.store.DeleteQueuedJob(ctx, entryID)
+}
+
+// selectors returns the label selectors with queued jobs, sorted. q.mu
+// must be held.
+func (q *MemoryQueue) selectors() []string {
+	waiting := map[string]bool{}
+	for name, lanes := range q.queues {
+		for _, lane := range lanes {
+			waiting[name] = waiting[name] || lane.size() > 0
+		}
+	}
+
+	var selectors []string
+	for name, ok := range waiting {
+		if ok && name != memoryDefaultQueue {
+			selectors = append(selectors, name)
+		}
+	}
+	sort.Strings(selectors)
+	return selectors
+}
+
+// queuedJob is a job waiting in a MemoryQueue
+type queuedJob struct {
+	entryID string // key of the persisted entry
+	job     *Job
+	score   float64
+	seq     uint64 // breaks ties in score by arrival
+}
+
+// jobLane is a lane of a queue of a MemoryQueue: the jobs of a tenant or
+// workflow, or all jobs without fairness
+type jobLane struct {
+	name       string
+	jobs       jobHeap
+	partitions map[string]*jobPartition
+	pass       float64 // lanes with the lowest pass are served first
+}
+
+// next returns the lane's next job and, when it is the head of a
+// partition, the partition, or nil
+func (l *jobLane) next() (*queuedJob, *jobPartition) {
+	var entry *queuedJob
+	if l.jobs.Len() > 0 {
+		entry = l.jobs[0]
+	}
+	var next *jobPartition
+	for _, partition := range l.partitions {
+		if partition.held || len(partition.jobs) == 0 {
+			continue
+		}
+		if head := partition.jobs[0]; entry == nil || (jobHeap{head, entry}).Less(0, 1) {
+			entry, next = head, partition
+		}
+	}
+	return entry, next
+}
+
+// size returns the number of jobs waiting in the lane
+func (l *jobLane) size() int64 {
+	size := int64(l.jobs.Len())
+	for _, partition := range l.partitions {
+		size += int64(len(partition.jobs))
+	}
+	return size
+}
+
+// empty reports whether the lane has no jobs and no partitions
+func (l *jobLane) empty() bool {
+	return l.jobs.Len() == 0 && len(l.partitions) == 0
+}
+
+// partition returns a partition of the lane, creating it when create is
+// set, or nil
+func (l *jobLane) partition(key string, create bool) *jobPartition {
+	partition, ok := l.partitions[key]
+	if !ok && create {
+		partition = &jobPartition{}
+		l.partitions[key] = partition
+	}
+	return partition
+}
+
+// jobPartition is a partition's jobs waiting in a MemoryQueue, in the
+// order they were queued
+type jobPartition struct {
+	jobs []*queuedJob
+	held bool // a worker is running the partition's last dequeued job
+}
+
+// jobHeap orders queued jobs by score, lowest first
+type jobHeap []*queuedJob
+
+func (h jobHeap) Len() int { return len(h) }
+
+func (h jobHeap) Less(i, j int) bool {
+	if h[i].score != h[j].score {
+		return h[i].score < h[j].score
+	}
+	return h[i].seq < h[j].seq
+}
+
+func (h jobHeap) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
+
+func (h *jobHeap) Push(x interface{}) { *h = append(*h, x.(*queuedJob)) }
+
+func (h *jobHeap) Pop() interface{} {
+	old := *h
+	entry := old[len(old)-1]
+	old[len(old)-1] = nil
+	*h = old[:len(old)-1]
+	return entry
+}
@@ -0,0 +1,116 @@
+package engine
+
+import (
+	"context"
+	"time"
+
+	"github.com/nuumz/f1ow/internal/storage"
+	"github.com/nuumz/f1ow/internal/tenant"
+
+	"github.com/google/uuid"
+)
+
+// StartReaper resumes the executions of workers that stopped sending
+// heartbeats, checking every interval until ctx is cancelled. Every worker
+// may run a reaper; each stale execution is requeued once.
+func (e *Engine) StartReaper(ctx context.Context, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				requeued, err := e.ReapExecutions(ctx)
+				if err != nil {
+					e.logger.Errorf("Execution reaper failed: %v", err)
+				} else if requeued > 0 {
+					e.logger.Infof("Requeued %d executions of stopped workers", requeued)
+				}
+			}
+		}
+	}()
+}
+
+// ReapExecutions requeues running executions whose worker is no longer in
+// the registry and whose claim has lapsed, and returns how many it
+// requeued. The worker that picks one up resumes it from its journal.
+func (e *Engine) ReapExecutions(ctx context.Context) (int, error) {
+	if e.workers == nil || e.claimer == nil || e.db == nil {
+		return 0, nil
+	}
+
+	workers, err := e.workers.List(ctx)
+	if err != nil {
+		return 0, err
+	}
+	live := make(map[string]bool, len(workers))
+	for _, worker := range workers {
+		live[worker.ID] = true
+	}
+
+	claimed, err := e.db.ListClaimedExecutions(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	owner := "reaper-" + uuid.New().String()[:8]
+	requeued := 0
+	for _, execution := range claimed {
+		if live[execution.ClaimedBy] {
+			continue
+		}
+		ok, err := e.requeue(ctx, execution, owner)
+		if err != nil {
+			e.logger.Errorf("Failed to requeue execution %s: %v", execution.ID, err)
+			continue
+		}
+		if ok {
+			requeued++
+		}
+	}
+	return requeued, nil
+}
+
+// requeue returns a stale execution to the queue. Holding its claim while
+// doing so keeps a worker whose heartbeat merely lapsed from being
+// displaced while it still renews the claim.
+func (e *Engine) requeue(ctx context.Context, execution storage.ClaimedExecution, owner string) (bool, error) {
+	executionID := execution.ID.String()
+	held, err := e.claimer.Claim(ctx, executionID, owner, executionClaimTTL)
+	if err != nil || !held {
+		return false, err
+	}
+	defer e.claimer.Release(context.WithoutCancel(ctx), executionID, owner)
+
+	requeued, err := e.db.RequeueExecution(ctx, execution.ID, execution.ClaimedBy)
+	if err != nil || !requeued {
+		return false, err
+	}
+
+	ctx = tenant.WithID(ctx, execution.TenantID)
+	job := &Job{
+		WorkflowID:  execution.WorkflowID.String(),
+		ExecutionID: executionID,
+		TenantID:    execution.TenantID.String(),
+		Input:       execution.Input,
+		Metadata:    map[string]interface{}{},
+	}
+	environment, _ := execution.Metadata["environment"].(string)
+	if environment != "" {
+		job.Metadata["environment"] = environment
+	}
+	if pinned, _ := execution.Metadata["pinned_data"].(bool); pinned {
+		job.Metadata["pinned_data"] = true
+	}
+	// The worker fails the execution if the workflow cannot be loaded
+	if workflow, err := e.loadWorkflow(ctx, job.WorkflowID, environment); err == nil {
+		job.Labels, _ = WorkerSelector(workflow)
+		job.Partition = JobPartition(workflow, execution.Input)
+	}
+
+	e.logger.Infof("Requeueing execution %s of stopped worker %s", executionID, execution.ClaimedBy)
+	return true, e.queue.Enqueue(ctx, job)
+}
@@ -3,12 +3,16 @@ package engine
 import (
 	"context"
 	"fmt"
+	"sort"
 	"sync"
+
+	"github.com/nuumz/f1ow/internal/models"
 )
 
 // NodeType represents a node implementation
 type NodeType interface {
-	// Execute runs the node logic
+	// Execute runs the node logic. The executor calls it through
+	// AsTypedNode, unless the node implements TypedNode.
 	Execute(ctx context.Context, config interface{}, input interface{}) (interface{}, error)
 
 	// ValidateConfig validates the node configuration
@@ -63,38 +67,83 @@ type PortSchema struct {
 	Multiple    bool   `json:"multiple"` // Can accept multiple connections
 }
 
-// NodeRegistry manages available node types
+// NodeRegistry manages available node types. A type may have several
+// versions, registered as type@vN; plain type names register version 1.
 type NodeRegistry struct {
-	nodes map[string]NodeType
-	mu    sync.RWMutex
+	nodes        map[string]map[int]NodeType // by type, then version
+	deprecations map[string]string           // migration notes by type@vN
+	mu           sync.RWMutex
 }
 
 // NewNodeRegistry creates a new node registry
 func NewNodeRegistry() *NodeRegistry {
 	return &NodeRegistry{
-		nodes: make(map[string]NodeType),
+		nodes:        make(map[string]map[int]NodeType),
+		deprecations: make(map[string]string),
 	}
 }
 
-// Register adds a new node type to the registry
+// Register adds a node type, or a version of one named type@vN, to the
+// registry
 func (r *NodeRegistry) Register(nodeType string, node NodeType) error {
+	name, version, err := models.ParseNodeType(nodeType)
+	if err != nil {
+		return err
+	}
+	if version == 0 {
+		version = 1
+	}
+
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
-	if _, exists := r.nodes[nodeType]; exists {
-		return fmt.Errorf("node type %s already registered", nodeType)
+	if _, exists := r.nodes[name][version]; exists {
+		return fmt.Errorf("node type %s already registered", models.NodeTypeRef(name, version))
+	}
+	if r.nodes[name] == nil {
+		r.nodes[name] = make(map[int]NodeType)
+	}
+	r.nodes[name][version] = node
+	return nil
+}
+
+// Deprecate marks a version of a node type, named type@vN, as deprecated.
+// The message tells operators how to migrate off it.
+func (r *NodeRegistry) Deprecate(nodeType string, message string) error {
+	name, version, err := models.ParseNodeType(nodeType)
+	if err != nil {
+		return err
 	}
 
-	r.nodes[nodeType] = node
+	if version == 0 {
+		version = 1
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.nodes[name][version]; !exists {
+		return fmt.Errorf("node type %s not found", models.NodeTypeRef(name, version))
+	}
+	r.deprecations[models.NodeTypeRef(name, version)] = message
 	return nil
 }
 
-// Get retrieves a node type from the registry
+// Get retrieves a node type from the registry: the version a type@vN names,
+// or the latest version of a plain type name
 func (r *NodeRegistry) Get(nodeType string) (NodeType, error) {
+	name, version, err := models.ParseNodeType(nodeType)
+	if err != nil {
+		return nil, err
+	}
+
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
-	node, exists := r.nodes[nodeType]
+	if version == 0 {
+		version = latestVersion(r.nodes[name])
+	}
+	node, exists := r.nodes[name][version]
 	if !exists {
 		return nil, fmt.Errorf("node type %s not found", nodeType)
 	}
@@ -102,41 +151,79 @@ func (r *NodeRegistry) Get(nodeType string) (NodeType, error) {
 	return node, nil
 }
 
-// List returns all registered node types
+// List returns the latest version of each registered node type
 func (r *NodeRegistry) List() map[string]NodeType {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
 	// Create a copy to avoid race conditions
 	result := make(map[string]NodeType)
-	for k, v := range r.nodes {
-		result[k] = v
+	for name, versions := range r.nodes {
+		result[name] = versions[latestVersion(versions)]
 	}
 
 	return result
 }
 
-// GetSchema returns the schema for a specific node type
-func (r *NodeRegistry) GetSchema(nodeType string) (NodeSchema, error) {
+// Versions returns the registered versions of a node type in order
+func (r *NodeRegistry) Versions(nodeType string) []int {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
-	node, exists := r.nodes[nodeType]
-	if !exists {
-		return NodeSchema{}, fmt.Errorf("node type %s not found", nodeType)
+	versions := make([]int, 0, len(r.nodes[nodeType]))
+	for version := range r.nodes[nodeType] {
+		versions = append(versions, version)
+	}
+	sort.Ints(versions)
+	return versions
+}
+
+// Deprecation returns the migration note of a deprecated version of a node
+// type, named type@vN, and whether it is deprecated
+func (r *NodeRegistry) Deprecation(nodeType string) (string, bool) {
+	name, version, err := models.ParseNodeType(nodeType)
+	if err != nil {
+		return "", false
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	message, deprecated := r.deprecations[models.NodeTypeRef(name, version)]
+	return message, deprecated
+}
+
+// GetSchema returns the schema for a specific node type
+func (r *NodeRegistry) GetSchema(nodeType string) (NodeSchema, error) {
+	node, err := r.Get(nodeType)
+	if err != nil {
+		return NodeSchema{}, err
 	}
 
 	return node.GetSchema(), nil
 }
 
+// latestVersion returns the highest of versions, or 0 when there are none
+func latestVersion(versions map[int]NodeType) int {
+	latest := 0
+	for version := range versions {
+		if version > latest {
+			latest = version
+		}
+	}
+	return latest
+}
+
 // Categories returns all available node categories
 func (r *NodeRegistry) Categories() []string {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
 	categoryMap := make(map[string]bool)
-	for _, node := range r.nodes {
-		categoryMap[node.Category()] = true
+	for _, versions := range r.nodes {
+		for _, node := range versions {
+			categoryMap[node.Category()] = true
+		}
 	}
 
 	categories := make([]string, 0, len(categoryMap))
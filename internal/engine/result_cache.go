@@ -0,0 +1,157 @@
+package engine
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/nuumz/f1ow/internal/models"
+	"github.com/nuumz/f1ow/internal/tenant"
+)
+
+// resultCacheKeyPrefix namespaces cached node outputs among cache entries
+const resultCacheKeyPrefix = "node-result:"
+
+// ResultCache stores the outputs of nodes with a cache TTL so executions
+// can reuse them. *storage.RedisClient implements it.
+type ResultCache interface {
+	GetCache(ctx context.Context, key string) ([]byte, bool, error)
+	SetCache(ctx context.Context, key string, value []byte, ttl time.Duration) error
+}
+
+// WithResultCache sets where node outputs are cached. It defaults to Redis
+// when the engine has it, and to memory otherwise.
+func WithResultCache(cache ResultCache) Option {
+	return func(e *Engine) {
+		e.resultCache = cache
+	}
+}
+
+// MemoryResultCache is a ResultCache held in this process, for deployments
+// without Redis
+type MemoryResultCache struct {
+	mu      sync.Mutex
+	entries map[string]memoryCacheEntry
+}
+
+type memoryCacheEntry struct {
+	value     []byte
+	expiresAt time.Time
+}
+
+// NewMemoryResultCache creates an empty in-process result cache
+func NewMemoryResultCache() *MemoryResultCache {
+	return &MemoryResultCache{entries: make(map[string]memoryCacheEntry)}
+}
+
+// GetCache returns the entry for key and whether it was found unexpired
+func (c *MemoryResultCache) GetCache(ctx context.Context, key string) ([]byte, bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[key]
+	if !ok {
+		return nil, false, nil
+	}
+	if time.Now().After(entry.expiresAt) {
+		delete(c.entries, key)
+		return nil, false, nil
+	}
+	return entry.value, true, nil
+}
+
+// SetCache stores value under key for ttl, dropping expired entries
+func (c *MemoryResultCache) SetCache(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	for existing, entry := range c.entries {
+		if now.After(entry.expiresAt) {
+			delete(c.entries, existing)
+		}
+	}
+	c.entries[key] = memoryCacheEntry{value: value, expiresAt: now.Add(ttl)}
+	return nil
+}
+
+// cachedOutput is a node output as it is cached
+type cachedOutput struct {
+	Data      map[string]interface{}   `json:"data"`
+	Ports     []string                 `json:"ports"`
+	Items     []map[string]interface{} `json:"items,omitempty"`
+	Variables map[string]interface{}   `json:"variables,omitempty"`
+}
+
+// runCached runs a node with a cache TTL through the result cache: an
+// output cached for the same config and input within the TTL is reused
+// instead of running the node, and a successful run's output is cached.
+// Cache failures only cost a run.
+func (e *Executor) runCached(ctx context.Context, node *models.Node, config NodeConfig, input NodeInput, run func() (NodeOutput, error)) (NodeOutput, error) {
+	if node.CacheTTL <= 0 || e.resultCache == nil {
+		return run()
+	}
+
+	key, err := resultCacheKey(ctx, node, config, input)
+	if err != nil {
+		e.logger.Warnf("Not caching node %s: %v", node.ID, err)
+		return run()
+	}
+
+	if raw, found, err := e.resultCache.GetCache(ctx, key); err != nil {
+		e.logger.Warnf("Failed to read cached output of node %s: %v", node.ID, err)
+	} else if found {
+		var cached cachedOutput
+		if err := json.Unmarshal(raw, &cached); err == nil {
+			e.metrics.RecordNodeCacheLookup(node.Type, true)
+			return NodeOutput{
+				Data:      cached.Data,
+				Binary:    binaryRefs(cached.Data),
+				Metadata:  map[string]interface{}{"cached": true},
+				Ports:     cached.Ports,
+				Items:     cached.Items,
+				Variables: cached.Variables,
+			}, nil
+		}
+	}
+	e.metrics.RecordNodeCacheLookup(node.Type, false)
+
+	output, err := run()
+	if err != nil {
+		return output, err
+	}
+
+	encoded, err := json.Marshal(cachedOutput{Data: output.Data, Ports: output.Ports, Items: output.Items, Variables: output.Variables})
+	if err == nil {
+		err = e.resultCache.SetCache(ctx, key, encoded, time.Duration(node.CacheTTL)*time.Second)
+	}
+	if err != nil {
+		e.logger.Warnf("Failed to cache output of node %s: %v", node.ID, err)
+	}
+	return output, nil
+}
+
+// resultCacheKey returns the cache key of a node's output for its resolved
+// config and input. Outputs are only shared by the same node of the same
+// workflow, in the same tenant.
+func resultCacheKey(ctx context.Context, node *models.Node, config NodeConfig, input NodeInput) (string, error) {
+	// Maps marshal with sorted keys, so equal values hash the same
+	encoded, err := json.Marshal(map[string]interface{}{
+		"type":      node.Type,
+		"config":    config,
+		"input":     input.Data,
+		"variables": input.Variables,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to hash config and input: %w", err)
+	}
+	sum := sha256.Sum256(encoded)
+
+	info, _ := ExecutionInfoFromContext(ctx)
+	return fmt.Sprintf("%s%s:%s:%s:%s", resultCacheKeyPrefix, tenant.IDOrDefault(ctx), info.WorkflowID, node.ID,
+		hex.EncodeToString(sum[:])), nil
+}
@@ -0,0 +1,358 @@
+package engine
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/nuumz/f1ow/internal/models"
+	"github.com/nuumz/f1ow/internal/netpolicy"
+	"github.com/nuumz/f1ow/internal/tenant"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+// Trigger is a long-running event source bound to a trigger node of an
+// active workflow, such as a queue consumer or a poller
+type Trigger interface {
+	// Start begins listening for events and returns once the trigger is running
+	Start(ctx context.Context, emit EmitFunc) error
+
+	// Stop stops listening and releases resources
+	Stop() error
+}
+
+// EmitFunc starts a workflow execution with the given trigger payload
+type EmitFunc func(ctx context.Context, workflowID string, payload map[string]interface{}) error
+
+// TriggerFactory builds a trigger from a trigger node's configuration
+type TriggerFactory func(workflowID string, node models.Node) (Trigger, error)
+
+// WorkflowLoader returns the workflows whose triggers should be running
+type WorkflowLoader func(ctx context.Context) ([]models.Workflow, error)
+
+// TriggerLocker leases triggers to engine instances so each trigger runs
+// on exactly one of them
+type TriggerLocker interface {
+	// Acquire takes the lease on key for owner, or renews it if owner
+	// already holds it, and reports whether owner holds it
+	Acquire(ctx context.Context, key, owner string, ttl time.Duration) (bool, error)
+
+	// Release gives up owner's lease on key
+	Release(ctx context.Context, key, owner string) error
+
+	// Heartbeat records owner as alive and returns the number of live owners
+	Heartbeat(ctx context.Context, owner string, ttl time.Duration) (int, error)
+}
+
+// runningTrigger tracks a started trigger and the config it was built from
+type runningTrigger struct {
+	trigger    Trigger
+	configHash string
+}
+
+// desiredTrigger is a trigger node of an active workflow
+type desiredTrigger struct {
+	workflowID string
+	tenantID   uuid.UUID
+	node       models.Node
+	factory    TriggerFactory
+	configHash string
+}
+
+// TriggerManager starts and stops triggers for the trigger nodes of active
+// workflows. With a locker, triggers are spread evenly over the instances
+// sharing it: each instance holds at most its share of the leases, and
+// leases of instances that stop renewing them are picked up by the others.
+type TriggerManager struct {
+	factories map[string]TriggerFactory
+	running   map[string]*runningTrigger
+	emit      EmitFunc
+	logger    *logrus.Logger
+	locker    TriggerLocker
+	owner     string
+	leaseTTL  time.Duration
+	policy    *netpolicy.Policy
+	mu        sync.Mutex
+}
+
+// NewTriggerManager creates a trigger manager that starts executions through emit
+func NewTriggerManager(emit EmitFunc, logger *logrus.Logger) *TriggerManager {
+	return &TriggerManager{
+		factories: make(map[string]TriggerFactory),
+		running:   make(map[string]*runningTrigger),
+		emit:      emit,
+		logger:    logger,
+	}
+}
+
+// RegisterFactory registers the factory used for a trigger node type
+func (m *TriggerManager) RegisterFactory(nodeType string, factory TriggerFactory) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.factories[nodeType] = factory
+}
+
+// UseNetworkPolicy holds the connections triggers make to policy
+func (m *TriggerManager) UseNetworkPolicy(policy *netpolicy.Policy) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.policy = policy
+}
+
+// UseLocker shares triggers with the other instances using locker. owner
+// identifies this instance, and leases expire after ttl without a sync.
+func (m *TriggerManager) UseLocker(locker TriggerLocker, owner string, ttl time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.locker = locker
+	m.owner = owner
+	m.leaseTTL = ttl
+}
+
+// Running returns the keys ("<workflow ID>/<node ID>") of the triggers
+// running on this instance
+func (m *TriggerManager) Running() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	keys := make([]string, 0, len(m.running))
+	for key := range m.running {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Sync starts triggers for new or changed trigger nodes and stops the ones
+// that no longer belong to an active workflow or to this instance
+func (m *TriggerManager) Sync(ctx context.Context, workflows []models.Workflow) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	desired := make(map[string]desiredTrigger)
+	for _, workflow := range workflows {
+		if workflow.Status != models.WorkflowStatusActive {
+			continue
+		}
+		for _, node := range workflow.Definition.Nodes {
+			factory, ok := m.factories[node.Type]
+			if !ok || node.Disabled {
+				continue
+			}
+			desired[workflow.ID.String()+"/"+node.ID] = desiredTrigger{
+				workflowID: workflow.ID.String(),
+				tenantID:   workflow.TenantID,
+				node:       node,
+				factory:    factory,
+				configHash: triggerConfigHash(node.Config),
+			}
+		}
+	}
+
+	// Stop triggers that are no longer wanted or whose config changed
+	for key, current := range m.running {
+		if want, ok := desired[key]; !ok || want.configHash != current.configHash {
+			m.stopTrigger(ctx, key, current)
+		}
+	}
+
+	keys := make([]string, 0, len(desired))
+	for key := range desired {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	if m.locker == nil {
+		for _, key := range keys {
+			if _, ok := m.running[key]; !ok {
+				m.startTrigger(ctx, key, desired[key])
+			}
+		}
+		return
+	}
+	m.syncLeases(ctx, keys, desired)
+}
+
+// syncLeases renews the leases of running triggers, releases those above
+// this instance's share, and acquires free ones up to it; the caller must
+// hold the lock
+func (m *TriggerManager) syncLeases(ctx context.Context, keys []string, desired map[string]desiredTrigger) {
+	members, err := m.locker.Heartbeat(ctx, m.owner, m.leaseTTL)
+	if err != nil {
+		// Keep what is running rather than flapping while the locker is unavailable
+		m.logger.Errorf("Failed to record trigger heartbeat: %v", err)
+		return
+	}
+	if members < 1 {
+		members = 1
+	}
+	share := (len(keys) + members - 1) / members
+
+	owned := 0
+	for _, key := range keys {
+		current, ok := m.running[key]
+		if !ok {
+			continue
+		}
+		held, err := m.locker.Acquire(ctx, triggerLockKey(key), m.owner, m.leaseTTL)
+		if err != nil {
+			m.logger.Errorf("Failed to renew trigger lease %s: %v", key, err)
+			owned++
+			continue
+		}
+		if !held {
+			m.logger.Warnf("Lost trigger lease %s", key)
+			m.stopTrigger(ctx, key, current)
+			continue
+		}
+		if owned >= share {
+			// Hand the trigger over to an instance below its share
+			m.stopTrigger(ctx, key, current)
+			continue
+		}
+		owned++
+	}
+
+	for _, key := range keys {
+		if owned >= share {
+			return
+		}
+		if _, ok := m.running[key]; ok {
+			continue
+		}
+		held, err := m.locker.Acquire(ctx, triggerLockKey(key), m.owner, m.leaseTTL)
+		if err != nil {
+			m.logger.Errorf("Failed to acquire trigger lease %s: %v", key, err)
+			continue
+		}
+		if !held {
+			continue
+		}
+		if m.startTrigger(ctx, key, desired[key]) {
+			owned++
+		} else if err := m.locker.Release(ctx, triggerLockKey(key), m.owner); err != nil {
+			m.logger.Errorf("Failed to release trigger lease %s: %v", key, err)
+		}
+	}
+}
+
+// Run syncs triggers with the loaded workflows until the context is cancelled
+func (m *TriggerManager) Run(ctx context.Context, loader WorkflowLoader, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		workflows, err := loader(ctx)
+		if err != nil {
+			m.logger.Errorf("Failed to load workflows for triggers: %v", err)
+		} else {
+			m.Sync(ctx, workflows)
+		}
+
+		select {
+		case <-ctx.Done():
+			m.StopAll()
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// StopAll stops every running trigger and releases its lease
+func (m *TriggerManager) StopAll() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for key, current := range m.running {
+		// The run context is usually cancelled by now
+		m.stopTrigger(context.Background(), key, current)
+	}
+}
+
+// startTrigger builds and starts a trigger; the caller must hold the lock
+func (m *TriggerManager) startTrigger(ctx context.Context, key string, want desiredTrigger) bool {
+	trigger, err := want.factory(want.workflowID, want.node)
+	if err != nil {
+		m.logger.Errorf("Failed to create trigger %s: %v", key, err)
+		return false
+	}
+
+	// Triggers connect and start executions on behalf of the workflow's tenant
+	if want.tenantID != uuid.Nil {
+		ctx = tenant.WithID(ctx, want.tenantID)
+	}
+	if m.policy != nil {
+		ctx = netpolicy.WithPolicy(ctx, m.policy)
+	}
+	if err := trigger.Start(ctx, m.emit); err != nil {
+		m.logger.Errorf("Failed to start trigger %s: %v", key, err)
+		return false
+	}
+
+	m.running[key] = &runningTrigger{trigger: trigger, configHash: want.configHash}
+	m.logger.Infof("Started %s trigger %s", want.node.Type, key)
+	return true
+}
+
+// stopTrigger stops a trigger and releases its lease; the caller must hold the lock
+func (m *TriggerManager) stopTrigger(ctx context.Context, key string, current *runningTrigger) {
+	if err := current.trigger.Stop(); err != nil {
+		m.logger.Errorf("Failed to stop trigger %s: %v", key, err)
+	}
+	delete(m.running, key)
+	if m.locker != nil {
+		if err := m.locker.Release(ctx, triggerLockKey(key), m.owner); err != nil {
+			m.logger.Errorf("Failed to release trigger lease %s: %v", key, err)
+		}
+	}
+	m.logger.Infof("Stopped trigger %s", key)
+}
+
+// RegisterTrigger registers the factory that builds triggers for a trigger
+// node type. Triggers enqueue an execution per event.
+func (e *Engine) RegisterTrigger(nodeType string, factory TriggerFactory) {
+	e.triggers.RegisterFactory(nodeType, factory)
+}
+
+// Triggers returns the manager running the triggers of active workflows
+func (e *Engine) Triggers() *TriggerManager {
+	return e.triggers
+}
+
+// StartTriggers runs the triggers of active workflows, reloading workflows
+// every interval, until ctx is cancelled. With Redis, triggers are shared
+// with the other instances so each runs once.
+func (e *Engine) StartTriggers(ctx context.Context, interval time.Duration) {
+	if e.redis != nil {
+		hostname, _ := os.Hostname()
+		owner := fmt.Sprintf("%s-%s", hostname, uuid.New().String()[:8])
+		// Leases outlive a few missed syncs before other instances take over
+		e.triggers.UseLocker(NewRedisTriggerLocker(e.redis), owner, 3*interval)
+	}
+	go e.triggers.Run(ctx, e.db.GetWorkflows, interval)
+}
+
+func triggerLockKey(key string) string {
+	return "f1ow:triggers:lease:" + key
+}
+
+// triggerConfigHash returns a stable hash of a node configuration
+func triggerConfigHash(config map[string]interface{}) string {
+	data, err := json.Marshal(config)
+	if err != nil {
+		return fmt.Sprintf("%v", config)
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
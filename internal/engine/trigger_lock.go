@@ -0,0 +1,80 @@
+package engine
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/nuumz/f1ow/internal/storage"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// triggerMembersKey is a sorted set of trigger manager instances scored by
+// their last heartbeat in milliseconds
+const triggerMembersKey = "f1ow:triggers:members"
+
+// acquireLeaseScript sets the lease if it is free and extends it if the
+// caller already holds it
+var acquireLeaseScript = redis.NewScript(`
+local current = redis.call("GET", KEYS[1])
+if current == ARGV[1] then
+	redis.call("PEXPIRE", KEYS[1], ARGV[2])
+	return 1
+end
+if current then
+	return 0
+end
+redis.call("SET", KEYS[1], ARGV[1], "PX", ARGV[2])
+return 1
+`)
+
+// releaseLeaseScript deletes the lease only if the caller holds it
+var releaseLeaseScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0
+`)
+
+// RedisTriggerLocker leases triggers through Redis keys that expire unless
+// renewed
+type RedisTriggerLocker struct {
+	redis *storage.RedisClient
+}
+
+// NewRedisTriggerLocker creates a trigger locker backed by Redis
+func NewRedisTriggerLocker(redis *storage.RedisClient) *RedisTriggerLocker {
+	return &RedisTriggerLocker{redis: redis}
+}
+
+// Acquire takes or renews the lease on key for owner
+func (l *RedisTriggerLocker) Acquire(ctx context.Context, key, owner string, ttl time.Duration) (bool, error) {
+	held, err := acquireLeaseScript.Run(ctx, l.redis.Client(), []string{key}, owner, ttl.Milliseconds()).Int()
+	if err != nil {
+		return false, err
+	}
+	return held == 1, nil
+}
+
+// Release gives up owner's lease on key
+func (l *RedisTriggerLocker) Release(ctx context.Context, key, owner string) error {
+	return releaseLeaseScript.Run(ctx, l.redis.Client(), []string{key}, owner).Err()
+}
+
+// Heartbeat records owner as alive, drops owners not seen within ttl, and
+// returns the number of live owners
+func (l *RedisTriggerLocker) Heartbeat(ctx context.Context, owner string, ttl time.Duration) (int, error) {
+	client := l.redis.Client()
+	now := time.Now()
+
+	if err := client.ZAdd(ctx, triggerMembersKey, redis.Z{Score: float64(now.UnixMilli()), Member: owner}).Err(); err != nil {
+		return 0, err
+	}
+	expired := strconv.FormatInt(now.Add(-ttl).UnixMilli(), 10)
+	if err := client.ZRemRangeByScore(ctx, triggerMembersKey, "-inf", "("+expired).Err(); err != nil {
+		return 0, err
+	}
+	count, err := client.ZCard(ctx, triggerMembersKey).Result()
+	return int(count), err
+}
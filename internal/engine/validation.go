@@ -0,0 +1,220 @@
+package engine
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/nuumz/f1ow/internal/models"
+)
+
+// FieldError describes an invalid node configuration field. Field is empty
+// when the error applies to the configuration as a whole.
+type FieldError struct {
+	NodeID  string `json:"node_id,omitempty"`
+	Field   string `json:"field,omitempty"`
+	Message string `json:"message"`
+}
+
+func (e FieldError) Error() string {
+	if e.Field == "" {
+		return e.Message
+	}
+	return e.Field + ": " + e.Message
+}
+
+// ValidationError holds every field error found in a configuration. Nodes
+// may return it from ValidateConfig to report errors per field.
+type ValidationError struct {
+	Errors []FieldError
+}
+
+func (e *ValidationError) Error() string {
+	messages := make([]string, len(e.Errors))
+	for i, fieldErr := range e.Errors {
+		messages[i] = fieldErr.Error()
+		if fieldErr.NodeID != "" {
+			messages[i] = "node " + fieldErr.NodeID + ": " + messages[i]
+		}
+	}
+	return "invalid configuration: " + strings.Join(messages, "; ")
+}
+
+// Validate checks a node configuration against the node's schema and then
+// runs its ValidateConfig. It returns the field errors found, or an error
+// if the node type is not registered.
+//
+// Values set from a credential_id are only known at execution time, so when
+// the config references a credential the schema's required fields are not
+// enforced, and ValidateConfig only runs for nodes whose schema declares
+// credential_id and therefore handles it themselves.
+func (r *NodeRegistry) Validate(nodeType string, config map[string]interface{}) ([]FieldError, error) {
+	node, err := r.Get(nodeType)
+	if err != nil {
+		return nil, err
+	}
+	if config == nil {
+		config = map[string]interface{}{}
+	}
+
+	schema := node.GetSchema()
+	credentialID, _ := config["credential_id"].(string)
+	_, handlesCredentials := schema.Properties["credential_id"]
+
+	var fieldErrs []FieldError
+	if credentialID == "" {
+		for _, field := range schema.Required {
+			if isEmptyValue(config[field]) {
+				fieldErrs = append(fieldErrs, FieldError{Field: field, Message: "is required"})
+			}
+		}
+	}
+	for field, value := range config {
+		property, ok := schema.Properties[field]
+		if !ok || isEmptyValue(value) {
+			continue
+		}
+		if msg := checkProperty(property, value); msg != "" {
+			fieldErrs = append(fieldErrs, FieldError{Field: field, Message: msg})
+		}
+	}
+	if len(fieldErrs) > 0 {
+		// ValidateConfig would only repeat the first of these errors
+		sort.Slice(fieldErrs, func(i, j int) bool { return fieldErrs[i].Field < fieldErrs[j].Field })
+		return fieldErrs, nil
+	}
+
+	if credentialID != "" && !handlesCredentials {
+		return nil, nil
+	}
+	if err := node.ValidateConfig(config); err != nil {
+		var validationErr *ValidationError
+		var fieldErr FieldError
+		switch {
+		case errors.As(err, &validationErr):
+			return validationErr.Errors, nil
+		case errors.As(err, &fieldErr):
+			return []FieldError{fieldErr}, nil
+		default:
+			return []FieldError{{Message: err.Error()}}, nil
+		}
+	}
+	return nil, nil
+}
+
+// ValidateDefinition validates the config of every enabled node in a
+// workflow definition. Each error carries the ID of its node.
+func (r *NodeRegistry) ValidateDefinition(definition *models.WorkflowDefinition) []FieldError {
+	var fieldErrs []FieldError
+	for _, node := range definition.Nodes {
+		if node.Disabled {
+			continue
+		}
+		nodeErrs, err := r.Validate(node.TypeRef(), node.Config)
+		if err != nil {
+			fieldErrs = append(fieldErrs, FieldError{NodeID: node.ID, Field: "type", Message: err.Error()})
+			continue
+		}
+		for _, fieldErr := range nodeErrs {
+			fieldErr.NodeID = node.ID
+			fieldErrs = append(fieldErrs, fieldErr)
+		}
+	}
+	return fieldErrs
+}
+
+// ValidateNodeConfig validates a config for the node type
+func (e *Engine) ValidateNodeConfig(nodeType string, config map[string]interface{}) ([]FieldError, error) {
+	return e.nodeRegistry.Validate(nodeType, config)
+}
+
+// ValidateWorkflow validates the config, input mapping, and cache TTL of
+// every node in the workflow and returns a *ValidationError if any is invalid
+func (e *Engine) ValidateWorkflow(workflow *models.Workflow) error {
+	fieldErrs := e.nodeRegistry.ValidateDefinition(&workflow.Definition)
+	fieldErrs = append(fieldErrs, validateInputMappings(&workflow.Definition)...)
+	for _, node := range workflow.Definition.Nodes {
+		if node.CacheTTL < 0 {
+			fieldErrs = append(fieldErrs, FieldError{NodeID: node.ID, Field: "cache_ttl", Message: "must not be negative"})
+		}
+	}
+	if len(fieldErrs) > 0 {
+		return &ValidationError{Errors: fieldErrs}
+	}
+	if err := validateConcurrency(workflow.Definition.Settings); err != nil {
+		return err
+	}
+	if !workflow.Definition.Settings.ExecutionMode.Valid() {
+		return &ValidationError{Errors: []FieldError{{
+			Field:   "settings.execution_mode",
+			Message: "must be one of single, items",
+		}}}
+	}
+	_, err := WorkerSelector(workflow)
+	return err
+}
+
+// checkProperty returns why value does not match the property, or "".
+// Template strings are resolved at execution time and always pass.
+func checkProperty(property Property, value interface{}) string {
+	if s, ok := value.(string); ok && strings.Contains(s, "{{") {
+		return ""
+	}
+
+	switch property.Type {
+	case "string":
+		s, ok := value.(string)
+		if !ok {
+			return "must be a string"
+		}
+		if len(property.Enum) > 0 && !containsString(property.Enum, s) {
+			return fmt.Sprintf("must be one of %s", strings.Join(property.Enum, ", "))
+		}
+	case "number", "integer":
+		n, ok := value.(float64)
+		if !ok {
+			if i, isInt := value.(int); isInt {
+				n, ok = float64(i), true
+			}
+		}
+		if !ok {
+			return "must be a number"
+		}
+		if property.Type == "integer" && n != float64(int64(n)) {
+			return "must be an integer"
+		}
+		if property.Minimum != nil && n < *property.Minimum {
+			return fmt.Sprintf("must be at least %v", *property.Minimum)
+		}
+		if property.Maximum != nil && n > *property.Maximum {
+			return fmt.Sprintf("must be at most %v", *property.Maximum)
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			return "must be a boolean"
+		}
+	case "array":
+		if _, ok := value.([]interface{}); !ok {
+			return "must be an array"
+		}
+	case "object":
+		if _, ok := value.(map[string]interface{}); !ok {
+			return "must be an object"
+		}
+	}
+	return ""
+}
+
+func isEmptyValue(value interface{}) bool {
+	return value == nil || value == ""
+}
+
+func containsString(values []string, s string) bool {
+	for _, v := range values {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,467 @@
+package engine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ErrWorkerNotFound is returned for workers that are not registered or
+// have stopped sending heartbeats
+var ErrWorkerNotFound = errors.New("worker not found")
+
+// WorkerStatus is the lifecycle state a worker reports in its heartbeats
+type WorkerStatus string
+
+const (
+	WorkerRunning  WorkerStatus = "running"  // taking jobs
+	WorkerDraining WorkerStatus = "draining" // finishing in-flight jobs, taking no new ones
+	WorkerStopping WorkerStatus = "stopping" // cancelling in-flight jobs
+)
+
+// WorkerCommand asks a worker to change its lifecycle state
+type WorkerCommand string
+
+const (
+	WorkerDrain WorkerCommand = "drain" // stop taking jobs and exit once in-flight jobs finish
+	WorkerStop  WorkerCommand = "stop"  // stop in-flight jobs, requeue their executions, and exit
+)
+
+// Valid reports whether c is a known worker command
+func (c WorkerCommand) Valid() bool {
+	return c == WorkerDrain || c == WorkerStop
+}
+
+// WorkerInfo is the record a worker registers and refreshes with each
+// heartbeat
+type WorkerInfo struct {
+	ID            string            `json:"id"`
+	Hostname      string            `json:"hostname"`
+	Version       string            `json:"version,omitempty"`
+	Queues        []string          `json:"queues"`
+	Labels        map[string]string `json:"labels,omitempty"`
+	Concurrency   int               `json:"concurrency"`
+	ActiveJobs    int               `json:"active_jobs"`
+	ProcessedJobs int64             `json:"processed_jobs"`
+	FailedJobs    int64             `json:"failed_jobs"`
+	Status        WorkerStatus      `json:"status"`
+	StartedAt     time.Time         `json:"started_at"`
+	LastHeartbeat time.Time         `json:"last_heartbeat"`
+}
+
+// WorkerRegistry records the workers of the fleet and carries commands to
+// them
+type WorkerRegistry interface {
+	// Heartbeat stores the worker's record, which expires after ttl unless
+	// renewed, and returns the command sent to the worker since its last
+	// heartbeat, if any
+	Heartbeat(ctx context.Context, info WorkerInfo, ttl time.Duration) (WorkerCommand, error)
+
+	// Deregister removes a stopped worker's record
+	Deregister(ctx context.Context, id string) error
+
+	// List returns the live workers
+	List(ctx context.Context) ([]WorkerInfo, error)
+
+	// Get returns a live worker or ErrWorkerNotFound
+	Get(ctx context.Context, id string) (*WorkerInfo, error)
+
+	// SendCommand queues a command for the worker's next heartbeat
+	SendCommand(ctx context.Context, id string, command WorkerCommand) error
+}
+
+// WorkerOptions configures the worker started by StartWorker
+type WorkerOptions struct {
+	ID                string        // hostname with a random suffix when empty
+	Version           string        // build version reported to the fleet API
+	Concurrency       int           // jobs run at once; MaxConcurrentWorkflows when zero
+	HeartbeatInterval time.Duration // 10s when zero; records expire after three missed heartbeats
+	// DrainTimeout is how long a worker whose context is cancelled waits
+	// for in-flight jobs before stopping them; their executions are
+	// requeued and resume on another worker. Zero stops them at once.
+	DrainTimeout time.Duration
+	// Labels advertise the worker's resources; jobs whose workflows
+	// require labels only run on workers that have all of them
+	Labels map[string]string
+}
+
+// WithWorker configures the worker started by StartWorker
+func WithWorker(options WorkerOptions) Option {
+	return func(e *Engine) {
+		e.workerOptions = options
+	}
+}
+
+// WithWorkerRegistry sets the registry workers heartbeat to. Engines with
+// Redis use a RedisWorkerRegistry by default.
+func WithWorkerRegistry(registry WorkerRegistry) Option {
+	return func(e *Engine) {
+		e.workers = registry
+	}
+}
+
+// Workers returns the live workers of the fleet
+func (e *Engine) Workers(ctx context.Context) ([]WorkerInfo, error) {
+	if e.workers == nil {
+		return []WorkerInfo{}, nil
+	}
+	return e.workers.List(ctx)
+}
+
+// Worker returns a live worker or ErrWorkerNotFound
+func (e *Engine) Worker(ctx context.Context, id string) (*WorkerInfo, error) {
+	if e.workers == nil {
+		return nil, ErrWorkerNotFound
+	}
+	return e.workers.Get(ctx, id)
+}
+
+// SendWorkerCommand asks a live worker to drain or stop. The worker acts on
+// it at its next heartbeat.
+func (e *Engine) SendWorkerCommand(ctx context.Context, id string, command WorkerCommand) error {
+	if !command.Valid() {
+		return fmt.Errorf("invalid worker command: %s", command)
+	}
+	if _, err := e.Worker(ctx, id); err != nil {
+		return err
+	}
+	return e.workers.SendCommand(ctx, id, command)
+}
+
+// QueueStats returns the queue's backlog with the load of the live workers,
+// and refreshes the queue and worker gauges
+func (e *Engine) QueueStats(ctx context.Context) (*QueueStats, error) {
+	stats, err := e.queue.Stats(ctx)
+	if err != nil {
+		return nil, err
+	}
+	workers, err := e.Workers(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	stats.Workers = len(workers)
+	for _, worker := range workers {
+		stats.Capacity += worker.Concurrency
+		stats.ActiveJobs += worker.ActiveJobs
+	}
+	if stats.Capacity > 0 {
+		stats.Utilization = float64(stats.ActiveJobs) / float64(stats.Capacity)
+	}
+	e.metrics.ActiveWorkers.Set(float64(stats.Workers))
+	e.metrics.WorkerUtilization.Set(stats.Utilization * 100)
+	return stats, nil
+}
+
+// errWorkerShutdown is the cancellation cause of jobs a worker stops before
+// they finish; their executions are requeued rather than failed
+var errWorkerShutdown = errors.New("worker shut down")
+
+const (
+	// drainProgressInterval is how often a draining worker logs the jobs
+	// it is waiting for
+	drainProgressInterval = 5 * time.Second
+	// jobStopGrace is how long a worker waits for stopped jobs to return
+	jobStopGrace = 10 * time.Second
+)
+
+// worker pulls jobs from the queue with bounded concurrency and reports
+// itself to the registry
+type worker struct {
+	engine       *Engine
+	interval     time.Duration
+	drainTimeout time.Duration
+	slots        chan struct{}
+	jobs         sync.WaitGroup
+	draining     chan struct{}
+	drain        sync.Once
+
+	mu   sync.Mutex
+	info WorkerInfo
+}
+
+func (e *Engine) newWorker() *worker {
+	options := e.workerOptions
+	hostname, _ := os.Hostname()
+	if options.ID == "" {
+		options.ID = fmt.Sprintf("%s-%s", hostname, uuid.New().String()[:8])
+	}
+	if options.Concurrency <= 0 {
+		options.Concurrency = e.config.MaxConcurrentWorkflows
+	}
+	if options.HeartbeatInterval <= 0 {
+		options.HeartbeatInterval = 10 * time.Second
+	}
+
+	return &worker{
+		engine:       e,
+		interval:     options.HeartbeatInterval,
+		drainTimeout: options.DrainTimeout,
+		slots:        make(chan struct{}, options.Concurrency),
+		draining:     make(chan struct{}),
+		info: WorkerInfo{
+			ID:          options.ID,
+			Hostname:    hostname,
+			Version:     options.Version,
+			Queues:      []string{e.queue.Name()},
+			Labels:      options.Labels,
+			Concurrency: options.Concurrency,
+			Status:      WorkerRunning,
+			StartedAt:   time.Now(),
+		},
+	}
+}
+
+// run takes jobs until ctx is cancelled or the worker is told to drain or
+// stop, then waits for its in-flight jobs. Jobs outlive ctx: once it is
+// cancelled they have the drain timeout to finish.
+func (w *worker) run(ctx context.Context) error {
+	e := w.engine
+	jobCtx, cancelJobs := context.WithCancelCause(context.WithoutCancel(ctx))
+	defer cancelJobs(nil)
+	stopJobs := func() { cancelJobs(errWorkerShutdown) }
+
+	heartbeatCtx, stopHeartbeat := context.WithCancel(context.Background())
+	defer stopHeartbeat()
+	if e.workers != nil {
+		w.heartbeat(heartbeatCtx, stopJobs)
+		go w.heartbeatLoop(heartbeatCtx, stopJobs)
+	}
+	go w.promoteLoop(heartbeatCtx)
+
+	err := w.takeJobs(ctx, jobCtx)
+
+	if ctx.Err() != nil {
+		w.update(func(info *WorkerInfo) {
+			if info.Status == WorkerRunning {
+				info.Status = WorkerDraining
+			}
+		})
+		w.drainJobs(w.drainTimeout, stopJobs)
+	} else {
+		w.jobs.Wait()
+	}
+	if e.workers != nil {
+		stopHeartbeat()
+		deregisterCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		if err := e.workers.Deregister(deregisterCtx, w.info.ID); err != nil {
+			e.logger.Errorf("Failed to deregister worker %s: %v", w.info.ID, err)
+		}
+		cancel()
+	}
+	return err
+}
+
+// drainJobs waits up to timeout for in-flight jobs to finish, logging and
+// reporting how many remain, then stops the rest
+func (w *worker) drainJobs(timeout time.Duration, stopJobs context.CancelFunc) {
+	e := w.engine
+	done := make(chan struct{})
+	go func() {
+		w.jobs.Wait()
+		close(done)
+	}()
+	defer e.metrics.DrainingJobs.Set(0)
+
+	deadline := time.NewTimer(timeout)
+	defer deadline.Stop()
+	progress := time.NewTicker(drainProgressInterval)
+	defer progress.Stop()
+
+	e.logger.Infof("Worker %s draining %d jobs for up to %s", w.info.ID, w.activeJobs(), timeout)
+	for {
+		e.metrics.DrainingJobs.Set(float64(w.activeJobs()))
+		select {
+		case <-done:
+			e.logger.Infof("Worker %s drained", w.info.ID)
+			return
+		case <-progress.C:
+			e.logger.Infof("Worker %s draining: %d jobs in flight", w.info.ID, w.activeJobs())
+		case <-deadline.C:
+			remaining := w.activeJobs()
+			e.logger.Warnf("Worker %s drain timeout reached; stopping %d jobs to requeue them", w.info.ID, remaining)
+			stopJobs()
+			select {
+			case <-done:
+			case <-time.After(jobStopGrace):
+				// Nodes that ignore cancellation are abandoned; the reaper
+				// requeues their executions once this worker is gone
+				e.logger.Errorf("Worker %s gave up on %d jobs that did not stop", w.info.ID, w.activeJobs())
+			}
+			return
+		}
+	}
+}
+
+func (w *worker) activeJobs() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.info.ActiveJobs
+}
+
+func (w *worker) takeJobs(ctx, jobCtx context.Context) error {
+	e := w.engine
+	for {
+		// Wait for a free slot so at most Concurrency jobs run at once
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-w.draining:
+			return nil
+		case w.slots <- struct{}{}:
+		}
+
+		job, err := e.queue.DequeueFor(ctx, w.info.Labels)
+		if err != nil || job == nil {
+			<-w.slots
+			if err != nil {
+				e.logger.Errorf("Failed to dequeue job: %v", err)
+				w.sleep(ctx, time.Second)
+			} else {
+				// No jobs available, wait a bit
+				w.sleep(ctx, 100*time.Millisecond)
+			}
+			continue
+		}
+
+		w.update(func(info *WorkerInfo) { info.ActiveJobs++ })
+		w.jobs.Add(1)
+		go func() {
+			defer w.jobs.Done()
+			defer func() { <-w.slots }()
+			if job.Partition != "" {
+				holdCtx, release := context.WithCancel(jobCtx)
+				go w.holdPartition(holdCtx, job)
+				defer w.releasePartition(job)
+				defer release()
+			}
+
+			started := time.Now()
+			err := e.processJob(jobCtx, job, w.info.ID)
+			e.metrics.JobProcessingTime.Observe(time.Since(started).Seconds())
+			w.update(func(info *WorkerInfo) {
+				info.ActiveJobs--
+				info.ProcessedJobs++
+				if err != nil && !errors.Is(err, errWorkerShutdown) {
+					info.FailedJobs++
+				}
+			})
+		}()
+	}
+}
+
+func (w *worker) sleep(ctx context.Context, d time.Duration) {
+	select {
+	case <-ctx.Done():
+	case <-w.draining:
+	case <-time.After(d):
+	}
+}
+
+func (w *worker) heartbeatLoop(ctx context.Context, cancelJobs context.CancelFunc) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			w.heartbeat(ctx, cancelJobs)
+		}
+	}
+}
+
+// promoteLoop queues scheduled jobs as they come due
+func (w *worker) promoteLoop(ctx context.Context) {
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := w.engine.queue.ProcessDelayedJobs(ctx); err != nil {
+				w.engine.logger.Errorf("Failed to queue scheduled jobs: %v", err)
+			}
+		}
+	}
+}
+
+// holdPartition keeps the job's partition held until ctx is done, so no
+// other worker starts the partition's next job while this one runs
+func (w *worker) holdPartition(ctx context.Context, job *Job) {
+	ticker := time.NewTicker(partitionHoldTTL / 3)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := w.engine.queue.ExtendPartition(ctx, job, partitionHoldTTL); err != nil {
+				w.engine.logger.Errorf("Failed to extend hold on partition %s: %v", job.Partition, err)
+			}
+		}
+	}
+}
+
+// releasePartition lets the next job of the finished job's partition run
+func (w *worker) releasePartition(job *Job) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := w.engine.queue.ReleasePartition(ctx, job); err != nil {
+		w.engine.logger.Errorf("Failed to release partition %s: %v", job.Partition, err)
+	}
+}
+
+// heartbeat refreshes the worker's record and applies a pending command
+func (w *worker) heartbeat(ctx context.Context, cancelJobs context.CancelFunc) {
+	e := w.engine
+	w.mu.Lock()
+	w.info.LastHeartbeat = time.Now()
+	info := w.info
+	w.mu.Unlock()
+
+	command, err := e.workers.Heartbeat(ctx, info, 3*w.interval)
+	if err != nil {
+		e.logger.Errorf("Worker %s heartbeat failed: %v", info.ID, err)
+		return
+	}
+
+	// Keep the backlog gauges on the worker's /metrics current for
+	// autoscalers that scrape workers
+	if e.redis != nil {
+		if _, err := e.QueueStats(ctx); err != nil {
+			e.logger.Errorf("Failed to refresh queue metrics: %v", err)
+		}
+	}
+
+	switch command {
+	case WorkerDrain:
+		e.logger.Infof("Worker %s draining", info.ID)
+		w.update(func(info *WorkerInfo) {
+			if info.Status == WorkerRunning {
+				info.Status = WorkerDraining
+			}
+		})
+		w.drain.Do(func() { close(w.draining) })
+	case WorkerStop:
+		e.logger.Infof("Worker %s stopping", info.ID)
+		w.update(func(info *WorkerInfo) { info.Status = WorkerStopping })
+		w.drain.Do(func() { close(w.draining) })
+		cancelJobs()
+	}
+}
+
+func (w *worker) update(fn func(info *WorkerInfo)) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	fn(&w.info)
+}
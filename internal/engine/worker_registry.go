@@ -0,0 +1,144 @@
+package engine
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/nuumz/f1ow/internal/storage"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// workerMembersKey is a sorted set of worker IDs scored by their last
+// heartbeat in milliseconds
+const workerMembersKey = "f1ow:workers:members"
+
+func workerKey(id string) string {
+	return "f1ow:workers:" + id
+}
+
+func workerCommandKey(id string) string {
+	return "f1ow:workers:" + id + ":command"
+}
+
+// RedisWorkerRegistry keeps worker records in Redis keys that expire unless
+// renewed by heartbeats
+type RedisWorkerRegistry struct {
+	redis *storage.RedisClient
+}
+
+// NewRedisWorkerRegistry creates a worker registry backed by Redis
+func NewRedisWorkerRegistry(redis *storage.RedisClient) *RedisWorkerRegistry {
+	return &RedisWorkerRegistry{redis: redis}
+}
+
+// Heartbeat stores the worker's record and takes its pending command
+func (r *RedisWorkerRegistry) Heartbeat(ctx context.Context, info WorkerInfo, ttl time.Duration) (WorkerCommand, error) {
+	data, err := json.Marshal(info)
+	if err != nil {
+		return "", err
+	}
+
+	client := r.redis.Client()
+	if err := client.Set(ctx, workerKey(info.ID), data, ttl).Err(); err != nil {
+		return "", fmt.Errorf("failed to store worker: %w", err)
+	}
+	if err := client.ZAdd(ctx, workerMembersKey, redis.Z{Score: float64(info.LastHeartbeat.UnixMilli()), Member: info.ID}).Err(); err != nil {
+		return "", fmt.Errorf("failed to store worker: %w", err)
+	}
+
+	command, err := client.GetDel(ctx, workerCommandKey(info.ID)).Result()
+	if err == redis.Nil {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read worker command: %w", err)
+	}
+	return WorkerCommand(command), nil
+}
+
+// Deregister removes the worker's record and any pending command
+func (r *RedisWorkerRegistry) Deregister(ctx context.Context, id string) error {
+	client := r.redis.Client()
+	if err := client.Del(ctx, workerKey(id), workerCommandKey(id)).Err(); err != nil {
+		return err
+	}
+	return client.ZRem(ctx, workerMembersKey, id).Err()
+}
+
+// List returns the live workers, dropping members whose record expired
+func (r *RedisWorkerRegistry) List(ctx context.Context) ([]WorkerInfo, error) {
+	client := r.redis.Client()
+	ids, err := client.ZRange(ctx, workerMembersKey, 0, -1).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list workers: %w", err)
+	}
+
+	workers := make([]WorkerInfo, 0, len(ids))
+	if len(ids) == 0 {
+		return workers, nil
+	}
+
+	keys := make([]string, len(ids))
+	for i, id := range ids {
+		keys[i] = workerKey(id)
+	}
+	values, err := client.MGet(ctx, keys...).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list workers: %w", err)
+	}
+
+	var expired []interface{}
+	for i, value := range values {
+		data, ok := value.(string)
+		if !ok {
+			expired = append(expired, ids[i])
+			continue
+		}
+		var info WorkerInfo
+		if err := json.Unmarshal([]byte(data), &info); err != nil {
+			return nil, fmt.Errorf("failed to decode worker %s: %w", ids[i], err)
+		}
+		workers = append(workers, info)
+	}
+	if len(expired) > 0 {
+		client.ZRem(ctx, workerMembersKey, expired...)
+	}
+	return workers, nil
+}
+
+// Get returns a live worker
+func (r *RedisWorkerRegistry) Get(ctx context.Context, id string) (*WorkerInfo, error) {
+	data, err := r.redis.Client().Get(ctx, workerKey(id)).Bytes()
+	if err == redis.Nil {
+		return nil, ErrWorkerNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get worker: %w", err)
+	}
+
+	var info WorkerInfo
+	if err := json.Unmarshal(data, &info); err != nil {
+		return nil, fmt.Errorf("failed to decode worker %s: %w", id, err)
+	}
+	return &info, nil
+}
+
+// SendCommand queues a command for the worker. It expires if the worker
+// never picks it up, so a worker that restarts under the same ID is not
+// stopped by a stale command.
+func (r *RedisWorkerRegistry) SendCommand(ctx context.Context, id string, command WorkerCommand) error {
+	ttl, err := r.redis.Client().PTTL(ctx, workerKey(id)).Result()
+	if err != nil {
+		return fmt.Errorf("failed to send worker command: %w", err)
+	}
+	if ttl <= 0 {
+		return ErrWorkerNotFound
+	}
+	if err := r.redis.Client().Set(ctx, workerCommandKey(id), string(command), ttl).Err(); err != nil {
+		return fmt.Errorf("failed to send worker command: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AlertCondition is what an alert rule watches for
+type AlertCondition string
+
+const (
+	// AlertOnFailures fires when Threshold executions failed within the
+	// rule's window
+	AlertOnFailures AlertCondition = "failures"
+	// AlertOnDuration fires when an execution that is running or finished
+	// within the rule's window took longer than Threshold seconds
+	AlertOnDuration AlertCondition = "duration"
+)
+
+// AlertRule watches the executions of one workflow, or of all the tenant's
+// workflows, and sends an alert to its channels when its condition holds
+type AlertRule struct {
+	ID          uuid.UUID      `json:"id"`
+	TenantID    uuid.UUID      `json:"-"`
+	Name        string         `json:"name"`
+	WorkflowID  *uuid.UUID     `json:"workflow_id,omitempty"` // all workflows when nil
+	Condition   AlertCondition `json:"condition"`
+	Threshold   int            `json:"threshold"`        // failed executions, or seconds for duration
+	Window      int            `json:"window_seconds"`   // how far back executions count; 600 when zero
+	Cooldown    int            `json:"cooldown_seconds"` // quiet time after firing; the window when zero
+	ChannelIDs  []uuid.UUID    `json:"channel_ids"`
+	Enabled     bool           `json:"enabled"`
+	LastFiredAt *time.Time     `json:"last_fired_at,omitempty"`
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+}
+
+// AlertChannelType is how an alert channel delivers alerts
+type AlertChannelType string
+
+const (
+	AlertChannelEmail     AlertChannelType = "email"     // config: to (address or list)
+	AlertChannelSlack     AlertChannelType = "slack"     // config: webhook_url
+	AlertChannelWebhook   AlertChannelType = "webhook"   // config: url, headers
+	AlertChannelPagerDuty AlertChannelType = "pagerduty" // config: routing_key, severity
+)
+
+// AlertChannel is a destination for alerts
+type AlertChannel struct {
+	ID        uuid.UUID              `json:"id"`
+	TenantID  uuid.UUID              `json:"-"`
+	Name      string                 `json:"name"`
+	Type      AlertChannelType       `json:"type"`
+	Config    map[string]interface{} `json:"config"`
+	CreatedAt time.Time              `json:"created_at"`
+	UpdatedAt time.Time              `json:"updated_at"`
+}
+
+// Alert is a record of a rule firing and where it was sent
+type Alert struct {
+	ID          uuid.UUID       `json:"id"`
+	TenantID    uuid.UUID       `json:"-"`
+	RuleID      uuid.UUID       `json:"rule_id"`
+	RuleName    string          `json:"rule_name"`
+	WorkflowID  *uuid.UUID      `json:"workflow_id,omitempty"`
+	ExecutionID *uuid.UUID      `json:"execution_id,omitempty"` // the slow execution of a duration alert
+	Message     string          `json:"message"`
+	Value       float64         `json:"value"` // failures counted, or seconds the execution took
+	Deliveries  []AlertDelivery `json:"deliveries"`
+	FiredAt     time.Time       `json:"fired_at"`
+}
+
+// AlertDelivery is the outcome of sending an alert to one channel
+type AlertDelivery struct {
+	ChannelID uuid.UUID `json:"channel_id"`
+	Error     string    `json:"error,omitempty"`
+}
@@ -0,0 +1,25 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// APIKey authenticates a user or service account without a JWT. Only a hash
+// of the key is stored; the key itself is shown once at creation.
+type APIKey struct {
+	ID             uuid.UUID  `json:"id" db:"id"`
+	TenantID       uuid.UUID  `json:"tenant_id" db:"tenant_id"`
+	Name           string     `json:"name" db:"name"`
+	Prefix         string     `json:"prefix" db:"prefix"` // first characters of the key, for recognizing it
+	KeyHash        string     `json:"-" db:"key_hash"`
+	UserID         *uuid.UUID `json:"user_id,omitempty" db:"user_id"`
+	ServiceAccount string     `json:"service_account,omitempty" db:"service_account"`
+	Scopes         []string   `json:"scopes" db:"scopes"`
+	RateLimit      string     `json:"rate_limit,omitempty" db:"rate_limit"` // e.g. "100/m"
+	CreatedAt      time.Time  `json:"created_at" db:"created_at"`
+	ExpiresAt      *time.Time `json:"expires_at,omitempty" db:"expires_at"`
+	LastUsedAt     *time.Time `json:"last_used_at,omitempty" db:"last_used_at"`
+	RevokedAt      *time.Time `json:"revoked_at,omitempty" db:"revoked_at"`
+}
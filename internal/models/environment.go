@@ -0,0 +1,71 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Environment is a deployment target such as development, staging, or
+// production. Variables with the environment's name apply to executions in it.
+type Environment struct {
+	ID          uuid.UUID `json:"id" db:"id"`
+	Name        string    `json:"name" db:"name" binding:"required"`
+	Description string    `json:"description" db:"description"`
+	CreatedAt   time.Time `json:"created_at" db:"created_at"`
+}
+
+// WorkflowVersion is the workflow as it was saved at a version
+type WorkflowVersion struct {
+	WorkflowID  uuid.UUID          `json:"workflow_id" db:"workflow_id"`
+	Version     int                `json:"version" db:"version"`
+	Name        string             `json:"name" db:"name"`
+	Description string             `json:"description" db:"description"`
+	Definition  WorkflowDefinition `json:"definition" db:"definition"`
+	UserID      uuid.UUID          `json:"user_id" db:"user_id"`
+	CreatedAt   time.Time          `json:"created_at" db:"created_at"`
+}
+
+// Deployment pins a workflow version to an environment. Credentials maps
+// credential IDs used in the definition to the IDs used in the environment,
+// and Variables override the workflow's variables there.
+type Deployment struct {
+	WorkflowID  uuid.UUID              `json:"workflow_id" db:"workflow_id"`
+	Environment string                 `json:"environment" db:"environment"`
+	Version     int                    `json:"version" db:"version"`
+	Credentials map[string]string      `json:"credentials" db:"credentials"`
+	Variables   map[string]interface{} `json:"variables" db:"variables"`
+	DeployedBy  uuid.UUID              `json:"deployed_by" db:"deployed_by"`
+	DeployedAt  time.Time              `json:"deployed_at" db:"deployed_at"`
+}
+
+// Apply returns the version's definition as it runs in the deployment's
+// environment: credential IDs are remapped and the deployment's variables
+// override the workflow's.
+func (d *Deployment) Apply(version *WorkflowVersion) WorkflowDefinition {
+	definition := version.Definition
+
+	definition.Nodes = make([]Node, len(version.Definition.Nodes))
+	for i, node := range version.Definition.Nodes {
+		if credentialID, _ := node.Config["credential_id"].(string); d.Credentials[credentialID] != "" {
+			config := make(map[string]interface{}, len(node.Config))
+			for key, value := range node.Config {
+				config[key] = value
+			}
+			config["credential_id"] = d.Credentials[credentialID]
+			node.Config = config
+		}
+		definition.Nodes[i] = node
+	}
+
+	if len(d.Variables) > 0 {
+		definition.Variables = make(map[string]interface{}, len(version.Definition.Variables)+len(d.Variables))
+		for name, value := range version.Definition.Variables {
+			definition.Variables[name] = value
+		}
+		for name, value := range d.Variables {
+			definition.Variables[name] = value
+		}
+	}
+	return definition
+}
@@ -0,0 +1,50 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// EventSubscription is a URL called with the tenant's events of the types
+// it lists. Entries are event types such as "execution.failed", a prefix
+// such as "execution.*", or "*" for every event.
+type EventSubscription struct {
+	ID          uuid.UUID `json:"id"`
+	TenantID    uuid.UUID `json:"-"`
+	URL         string    `json:"url"`
+	Description string    `json:"description,omitempty"`
+	Events      []string  `json:"events"`
+	Secret      string    `json:"secret,omitempty"` // signs deliveries; only returned when created
+	Enabled     bool      `json:"enabled"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// EventDeliveryStatus is where an event delivery stands
+type EventDeliveryStatus string
+
+const (
+	EventDeliveryPending   EventDeliveryStatus = "pending" // waiting for its next attempt
+	EventDeliverySucceeded EventDeliveryStatus = "succeeded"
+	EventDeliveryFailed    EventDeliveryStatus = "failed" // out of attempts
+)
+
+// EventDelivery is an event sent, or being retried, to a subscription
+type EventDelivery struct {
+	ID             uuid.UUID           `json:"id"`
+	TenantID       uuid.UUID           `json:"-"`
+	SubscriptionID uuid.UUID           `json:"subscription_id"`
+	EventID        string              `json:"event_id"`
+	EventType      string              `json:"event_type"`
+	Payload        json.RawMessage     `json:"payload"` // the request body
+	Status         EventDeliveryStatus `json:"status"`
+	Attempts       int                 `json:"attempts"`
+	ResponseStatus *int                `json:"response_status,omitempty"` // of the last attempt
+	ResponseBody   string              `json:"response_body,omitempty"`   // of the last attempt, truncated
+	Error          string              `json:"error,omitempty"`
+	NextAttemptAt  *time.Time          `json:"next_attempt_at,omitempty"`
+	CreatedAt      time.Time           `json:"created_at"`
+	DeliveredAt    *time.Time          `json:"delivered_at,omitempty"`
+}
@@ -0,0 +1,28 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ExecutionBatch groups the executions of a workflow submitted together,
+// one per input, so they can be followed as one
+type ExecutionBatch struct {
+	ID          uuid.UUID `json:"id"`
+	WorkflowID  uuid.UUID `json:"workflow_id"`
+	Concurrency int       `json:"concurrency,omitempty"` // most of the batch's executions running at once, 0 for no limit
+	CreatedAt   time.Time `json:"created_at"`
+
+	// Total counts the batch's executions and Counts them by status. Done
+	// is set once every execution has finished.
+	Total  int                     `json:"total"`
+	Counts map[ExecutionStatus]int `json:"counts"`
+	Done   bool                    `json:"done"`
+}
+
+// CountDone sets Done from the batch's counts
+func (b *ExecutionBatch) CountDone() {
+	b.Done = b.Counts[ExecutionStatusPending] == 0 && b.Counts[ExecutionStatusRunning] == 0 &&
+		b.Counts[ExecutionStatusPaused] == 0
+}
@@ -0,0 +1,38 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ExternalTaskStatus is where an external task is in its lifecycle
+type ExternalTaskStatus string
+
+const (
+	ExternalTaskPending   ExternalTaskStatus = "pending"   // waiting for a worker, or for its retry
+	ExternalTaskLocked    ExternalTaskStatus = "locked"    // fetched by a worker until its lock expires
+	ExternalTaskCompleted ExternalTaskStatus = "completed" // done; the node outputs its result
+	ExternalTaskFailed    ExternalTaskStatus = "failed"    // failed with no retries left; the node fails
+)
+
+// ExternalTask is work an external_task node parks on a topic for a worker
+// outside the engine. Workers fetch and lock tasks of their topics, then
+// complete or fail them; the node waits for the outcome.
+type ExternalTask struct {
+	ID          uuid.UUID              `json:"id"`
+	Topic       string                 `json:"topic"`
+	WorkflowID  uuid.UUID              `json:"workflow_id"`
+	ExecutionID uuid.UUID              `json:"execution_id"`
+	NodeID      string                 `json:"node_id"`
+	Payload     map[string]interface{} `json:"payload"`
+	Status      ExternalTaskStatus     `json:"status"`
+	WorkerID    string                 `json:"worker_id,omitempty"`
+	LockedUntil *time.Time             `json:"locked_until,omitempty"` // lock expiry, or when a pending task may be retried
+	Attempts    int                    `json:"attempts"`
+	Result      map[string]interface{} `json:"result,omitempty"`
+	Error       *string                `json:"error,omitempty"`
+	TenantID    uuid.UUID              `json:"tenant_id"`
+	CreatedAt   time.Time              `json:"created_at"`
+	UpdatedAt   time.Time              `json:"updated_at"`
+}
@@ -0,0 +1,49 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ParseNodeType splits a node type reference such as http@v2 into the type
+// and version. The version is 0 when the reference has none.
+func ParseNodeType(ref string) (string, int, error) {
+	nodeType, version, ok := strings.Cut(ref, "@")
+	if !ok {
+		return ref, 0, nil
+	}
+	n, err := strconv.Atoi(strings.TrimPrefix(version, "v"))
+	if err != nil || !strings.HasPrefix(version, "v") || n < 1 {
+		return "", 0, fmt.Errorf("invalid node type %q: expected type@vN", ref)
+	}
+	return nodeType, n, nil
+}
+
+// NodeTypeRef returns the reference of a version of a node type, such as
+// http@v2. Version 0 is version 1.
+func NodeTypeRef(nodeType string, version int) string {
+	if version < 1 {
+		version = 1
+	}
+	return nodeType + "@v" + strconv.Itoa(version)
+}
+
+// TypeRef returns the reference of the type and version the node runs as
+func (n Node) TypeRef() string {
+	return NodeTypeRef(n.Type, n.Version)
+}
+
+// UnmarshalJSON accepts a version in the type, as in "type": "http@v2", and
+// stores it in Version
+func (n *Node) UnmarshalJSON(data []byte) error {
+	type node Node
+	if err := json.Unmarshal(data, (*node)(n)); err != nil {
+		return err
+	}
+	if nodeType, version, err := ParseNodeType(n.Type); err == nil && version > 0 {
+		n.Type, n.Version = nodeType, version
+	}
+	return nil
+}
@@ -0,0 +1,19 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Project groups workflows. Projects nest through ParentID, so they also
+// serve as folders.
+type Project struct {
+	ID          uuid.UUID  `json:"id" db:"id"`
+	Name        string     `json:"name" db:"name" binding:"required"`
+	Description string     `json:"description" db:"description"`
+	ParentID    *uuid.UUID `json:"parent_id,omitempty" db:"parent_id"`
+	UserID      uuid.UUID  `json:"user_id" db:"user_id"`
+	CreatedAt   time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at" db:"updated_at"`
+}
@@ -0,0 +1,63 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Quotas caps what a tenant may use. A nil field is inherited, from the
+// tenant's plan and then from the server; 0 is unlimited.
+type Quotas struct {
+	MaxActiveWorkflows  *int `json:"max_active_workflows,omitempty"`
+	MaxExecutionsPerDay *int `json:"max_executions_per_day,omitempty"` // per UTC day
+	MaxExecutionSeconds *int `json:"max_execution_seconds,omitempty"`
+	MaxPayloadSize      *int `json:"max_payload_size,omitempty"` // bytes of execution input
+}
+
+// Inherit returns the quotas with their unset fields taken from base
+func (q Quotas) Inherit(base Quotas) Quotas {
+	if q.MaxActiveWorkflows == nil {
+		q.MaxActiveWorkflows = base.MaxActiveWorkflows
+	}
+	if q.MaxExecutionsPerDay == nil {
+		q.MaxExecutionsPerDay = base.MaxExecutionsPerDay
+	}
+	if q.MaxExecutionSeconds == nil {
+		q.MaxExecutionSeconds = base.MaxExecutionSeconds
+	}
+	if q.MaxPayloadSize == nil {
+		q.MaxPayloadSize = base.MaxPayloadSize
+	}
+	return q
+}
+
+// Plan is a named set of quotas tenants can be put on
+type Plan struct {
+	Name        string    `json:"name" db:"name"`
+	Description string    `json:"description" db:"description"`
+	Quotas      Quotas    `json:"quotas" db:"quotas"`
+	CreatedAt   time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// TenantQuotas puts a tenant on a plan and overrides quotas of the plan
+type TenantQuotas struct {
+	Plan   string  `json:"plan"` // empty for no plan
+	Quotas *Quotas `json:"quotas,omitempty"`
+}
+
+// Limits are the quotas a tenant is held to and how much of them it uses
+type Limits struct {
+	TenantID uuid.UUID   `json:"tenant_id"`
+	Plan     string      `json:"plan,omitempty"`
+	Quotas   Quotas      `json:"quotas"` // every quota set; 0 is unlimited
+	Usage    LimitsUsage `json:"usage"`
+	ResetsAt time.Time   `json:"resets_at"` // when the daily execution count resets
+}
+
+// LimitsUsage is how much of its quotas a tenant uses
+type LimitsUsage struct {
+	ActiveWorkflows int `json:"active_workflows"`
+	ExecutionsToday int `json:"executions_today"`
+}
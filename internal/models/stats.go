@@ -0,0 +1,46 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// WorkflowStats aggregates a workflow's executions over a time window
+type WorkflowStats struct {
+	WorkflowID    uuid.UUID             `json:"workflow_id"`
+	Window        string                `json:"window"`
+	Since         time.Time             `json:"since"`
+	Total         int                   `json:"total"`
+	StatusCounts  map[string]int        `json:"status_counts"`
+	SuccessRate   float64               `json:"success_rate"` // completed / finished, 0-1
+	DurationP50Ms float64               `json:"duration_p50_ms"`
+	DurationP95Ms float64               `json:"duration_p95_ms"`
+	PerDay        []DailyExecutionCount `json:"per_day"`
+	NodeFailures  []NodeFailureCount    `json:"node_failures"`
+	LastFailure   *ExecutionFailure     `json:"last_failure,omitempty"`
+	GeneratedAt   time.Time             `json:"generated_at"`
+}
+
+// DailyExecutionCount counts the executions started on one day
+type DailyExecutionCount struct {
+	Date      string `json:"date"` // YYYY-MM-DD
+	Total     int    `json:"total"`
+	Completed int    `json:"completed"`
+	Failed    int    `json:"failed"`
+}
+
+// NodeFailureCount counts the failed runs of one node
+type NodeFailureCount struct {
+	NodeID   string `json:"node_id"`
+	Failures int    `json:"failures"`
+}
+
+// ExecutionFailure describes the most recent failed execution
+type ExecutionFailure struct {
+	ExecutionID uuid.UUID  `json:"execution_id"`
+	NodeID      string     `json:"node_id,omitempty"`
+	Error       string     `json:"error"`
+	StartedAt   time.Time  `json:"started_at"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+}
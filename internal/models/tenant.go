@@ -0,0 +1,26 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Tenant is an organization whose workflows, executions, credentials, and
+// projects are isolated from other tenants
+type Tenant struct {
+	ID            uuid.UUID      `json:"id" db:"id"`
+	Name          string         `json:"name" db:"name" binding:"required"`
+	NetworkPolicy *NetworkPolicy `json:"network_policy,omitempty" db:"network_policy"`
+	Plan          string         `json:"plan,omitempty" db:"plan"`
+	Quotas        *Quotas        `json:"quotas,omitempty" db:"quotas"` // overrides of the plan's quotas
+	CreatedAt     time.Time      `json:"created_at" db:"created_at"`
+}
+
+// NetworkPolicy lists the networks outbound requests may and may not reach.
+// Entries are CIDRs, IP addresses, or the named ranges loopback, private,
+// link-local, and metadata; allow entries override deny entries.
+type NetworkPolicy struct {
+	Allow []string `json:"allow,omitempty"`
+	Deny  []string `json:"deny,omitempty"`
+}
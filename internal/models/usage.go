@@ -0,0 +1,46 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ExecutionUsage is the resources an execution used, for chargeback and
+// quotas
+type ExecutionUsage struct {
+	Nodes               int   `json:"nodes"`                 // node runs, failed ones included
+	RuntimeMs           int64 `json:"runtime_ms"`            // time nodes spent running
+	HTTPBytesSent       int64 `json:"http_bytes_sent"`       // request bodies sent by nodes
+	HTTPBytesReceived   int64 `json:"http_bytes_received"`   // response bodies read by nodes
+	LLMPromptTokens     int64 `json:"llm_prompt_tokens"`     // tokens sent to language models
+	LLMCompletionTokens int64 `json:"llm_completion_tokens"` // tokens generated by language models
+}
+
+// Add adds other to the usage
+func (u *ExecutionUsage) Add(other ExecutionUsage) {
+	u.Nodes += other.Nodes
+	u.RuntimeMs += other.RuntimeMs
+	u.HTTPBytesSent += other.HTTPBytesSent
+	u.HTTPBytesReceived += other.HTTPBytesReceived
+	u.LLMPromptTokens += other.LLMPromptTokens
+	u.LLMCompletionTokens += other.LLMCompletionTokens
+}
+
+// UsageRollup totals the usage of a tenant's executions, or of one of its
+// workflows, that finished in a period
+type UsageRollup struct {
+	TenantID   uuid.UUID      `json:"tenant_id"`
+	WorkflowID *uuid.UUID     `json:"workflow_id,omitempty"` // set when grouped by workflow
+	Executions int            `json:"executions"`
+	Usage      ExecutionUsage `json:"usage"`
+}
+
+// UsageReport is the usage of the executions that finished in a period
+type UsageReport struct {
+	From       time.Time      `json:"from"`
+	To         time.Time      `json:"to"`
+	Rollups    []UsageRollup  `json:"rollups"`
+	Executions int            `json:"executions"` // of all rollups
+	Total      ExecutionUsage `json:"total"`      // of all rollups
+}
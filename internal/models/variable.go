@@ -0,0 +1,21 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Variable is a named value nodes read in templates as {{vars.NAME}}.
+// Variables without an environment are global; environment variables
+// override them when executions run in that environment.
+type Variable struct {
+	ID          uuid.UUID   `json:"id" db:"id"`
+	Name        string      `json:"name" db:"name" binding:"required"`
+	Value       interface{} `json:"value,omitempty" db:"-"` // omitted for secrets in responses
+	Secret      bool        `json:"secret" db:"secret"`
+	Environment string      `json:"environment,omitempty" db:"environment"`
+	Data        string      `json:"-" db:"data"` // JSON encoded value, encrypted for secrets
+	CreatedAt   time.Time   `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time   `json:"updated_at" db:"updated_at"`
+}
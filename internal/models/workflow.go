@@ -1,6 +1,9 @@
 package models
 
 import (
+	"encoding/json"
+	"fmt"
+	"regexp"
 	"time"
 
 	"github.com/google/uuid"
@@ -13,12 +16,149 @@ type Workflow struct {
 	Description string                 `json:"description" db:"description"`
 	Definition  WorkflowDefinition     `json:"definition" db:"definition"`
 	UserID      uuid.UUID              `json:"user_id" db:"user_id"`
-	IsActive    bool                   `json:"is_active" db:"is_active"`
+	IsActive    bool                   `json:"is_active" db:"is_active"` // Status == WorkflowStatusActive
+	Status      WorkflowStatus         `json:"status" db:"status"`
 	CreatedAt   time.Time              `json:"created_at" db:"created_at"`
 	UpdatedAt   time.Time              `json:"updated_at" db:"updated_at"`
 	Tags        []string               `json:"tags" db:"tags"`
 	Version     int                    `json:"version" db:"version"`
 	Metadata    map[string]interface{} `json:"metadata" db:"metadata"`
+	ProjectID   *uuid.UUID             `json:"project_id,omitempty" db:"project_id"`
+	TenantID    uuid.UUID              `json:"tenant_id" db:"tenant_id"`
+}
+
+// WorkflowStatus is the lifecycle state of a workflow. Only active workflows
+// run their triggers and accept webhooks; archived and deleted workflows are
+// left out of listings by default.
+type WorkflowStatus string
+
+const (
+	WorkflowStatusDraft    WorkflowStatus = "draft"
+	WorkflowStatusActive   WorkflowStatus = "active"
+	WorkflowStatusArchived WorkflowStatus = "archived"
+	WorkflowStatusDeleted  WorkflowStatus = "deleted"
+)
+
+// Valid reports whether s is a known status
+func (s WorkflowStatus) Valid() bool {
+	switch s {
+	case WorkflowStatusDraft, WorkflowStatusActive, WorkflowStatusArchived, WorkflowStatusDeleted:
+		return true
+	}
+	return false
+}
+
+// WorkflowSummary is the list view of a workflow without its definition
+type WorkflowSummary struct {
+	ID          uuid.UUID              `json:"id"`
+	Name        string                 `json:"name"`
+	Description string                 `json:"description"`
+	UserID      uuid.UUID              `json:"user_id"`
+	IsActive    bool                   `json:"is_active"`
+	Status      WorkflowStatus         `json:"status"`
+	CreatedAt   time.Time              `json:"created_at"`
+	UpdatedAt   time.Time              `json:"updated_at"`
+	Tags        []string               `json:"tags"`
+	Version     int                    `json:"version"`
+	Metadata    map[string]interface{} `json:"metadata"`
+	ProjectID   *uuid.UUID             `json:"project_id,omitempty"`
+}
+
+// Summary returns the workflow without its definition
+func (w *Workflow) Summary() WorkflowSummary {
+	return WorkflowSummary{
+		ID:          w.ID,
+		Name:        w.Name,
+		Description: w.Description,
+		UserID:      w.UserID,
+		IsActive:    w.IsActive,
+		Status:      w.Status,
+		CreatedAt:   w.CreatedAt,
+		UpdatedAt:   w.UpdatedAt,
+		Tags:        w.Tags,
+		Version:     w.Version,
+		Metadata:    w.Metadata,
+		ProjectID:   w.ProjectID,
+	}
+}
+
+// Duplicate returns a deep copy of the workflow named "Copy of <name>" with
+// new workflow, node, and edge IDs and no ID, version, or timestamps set.
+// Credentials maps credential IDs in node configs to the ones the copy
+// uses. Copies start as drafts so their triggers do not fire before review.
+func (w *Workflow) Duplicate(credentials map[string]string) (*Workflow, error) {
+	data, err := json.Marshal(w.Definition)
+	if err != nil {
+		return nil, fmt.Errorf("failed to copy definition: %w", err)
+	}
+	var definition WorkflowDefinition
+	if err := json.Unmarshal(data, &definition); err != nil {
+		return nil, fmt.Errorf("failed to copy definition: %w", err)
+	}
+
+	nodeIDs := make(map[string]string, len(definition.Nodes))
+	for i := range definition.Nodes {
+		node := &definition.Nodes[i]
+		nodeIDs[node.ID] = uuid.New().String()
+		node.ID = nodeIDs[node.ID]
+		if credentialID, _ := node.Config["credential_id"].(string); credentials[credentialID] != "" {
+			node.Config["credential_id"] = credentials[credentialID]
+		}
+	}
+	for i := range definition.Edges {
+		edge := &definition.Edges[i]
+		if edge.ID != "" {
+			edge.ID = uuid.New().String()
+		}
+		if id, ok := nodeIDs[edge.Source]; ok {
+			edge.Source = id
+		}
+		if id, ok := nodeIDs[edge.Target]; ok {
+			edge.Target = id
+		}
+	}
+	if id, ok := nodeIDs[definition.StartNodeID]; ok {
+		definition.StartNodeID = id
+	}
+	for i := range definition.Nodes {
+		renameMappedNodes(definition.Nodes[i].InputMapping, nodeIDs)
+	}
+	for i := range definition.Edges {
+		renameMappedNodes(definition.Edges[i].InputMapping, nodeIDs)
+	}
+
+	tags := append([]string(nil), w.Tags...)
+	metadata := make(map[string]interface{}, len(w.Metadata))
+	for key, value := range w.Metadata {
+		metadata[key] = value
+	}
+
+	return &Workflow{
+		Name:        "Copy of " + w.Name,
+		Description: w.Description,
+		Definition:  definition,
+		Tags:        tags,
+		Metadata:    metadata,
+		ProjectID:   w.ProjectID,
+	}, nil
+}
+
+// mappedNodeReference matches the node ID of a nodes.<id> reference in an
+// input mapping
+var mappedNodeReference = regexp.MustCompile(`(\{\{\s*nodes\.)([^.\[\s}]+)`)
+
+// renameMappedNodes points the nodes.<id> references of an input mapping at
+// the renamed nodes
+func renameMappedNodes(mapping map[string]string, nodeIDs map[string]string) {
+	for field, expression := range mapping {
+		mapping[field] = mappedNodeReference.ReplaceAllStringFunc(expression, func(reference string) string {
+			match := mappedNodeReference.FindStringSubmatch(reference)
+			if id, ok := nodeIDs[match[2]]; ok {
+				return match[1] + id
+			}
+			return reference
+		})
+	}
 }
 
 // WorkflowDefinition contains the workflow structure
@@ -41,6 +181,27 @@ type Node struct {
 	Outputs     []NodeOutput           `json:"outputs"`
 	Disabled    bool                   `json:"disabled"`
 	Description string                 `json:"description"`
+	// Version is the version of the node type the node was built with,
+	// which it keeps when newer versions change their config. 0 is version
+	// 1, the version of nodes saved before types had versions.
+	Version int `json:"version,omitempty"`
+	// PinnedData is sample output used instead of running the node in
+	// executions started with pinned data
+	PinnedData map[string]interface{} `json:"pinned_data,omitempty"`
+	// WorkerLabels pins executions of the workflow to workers with these
+	// labels, for nodes that need resources only some workers have
+	WorkerLabels map[string]string `json:"worker_labels,omitempty"`
+	// InputMapping declares the node's input: each field, a dot path, is set
+	// to an expression such as {{nodes.fetch.body.items}}. A node with a
+	// mapping, on itself or an incoming edge, receives only mapped fields.
+	InputMapping map[string]string `json:"input_mapping,omitempty"`
+	// ItemsPath is the path of the array in the node's output whose elements
+	// become its items in item mode
+	ItemsPath string `json:"items_path,omitempty"`
+	// CacheTTL is how many seconds the node's output is reused by runs with
+	// the same config and input instead of running it again; 0 disables
+	// caching
+	CacheTTL int `json:"cache_ttl,omitempty"`
 }
 
 // Position represents node position in the designer
@@ -71,6 +232,9 @@ type Edge struct {
 	TargetPort string            `json:"target_port"`
 	Condition  *EdgeCondition    `json:"condition,omitempty"`
 	Metadata   map[string]string `json:"metadata,omitempty"`
+	// InputMapping maps fields of the target node's input like
+	// Node.InputMapping, with {{source.*}} referring to the source's output
+	InputMapping map[string]string `json:"input_mapping,omitempty"`
 }
 
 // EdgeCondition represents conditional flow
@@ -86,11 +250,55 @@ type EdgeCondition struct {
 type WorkflowSettings struct {
 	Timeout          int                    `json:"timeout"` // in seconds
 	RetryCount       int                    `json:"retry_count"`
-	RetryDelay       int                    `json:"retry_delay"`    // in seconds
-	ErrorHandling    string                 `json:"error_handling"` // "stop", "continue", "retry"
-	MaxConcurrency   int                    `json:"max_concurrency"`
+	RetryDelay       int                    `json:"retry_delay"`     // in seconds
+	ErrorHandling    string                 `json:"error_handling"`  // "stop", "continue", "retry"
+	MaxConcurrency   int                    `json:"max_concurrency"` // executions in progress at once; 0 is unlimited, or 1 under forbid and replace
 	SaveExecutionLog bool                   `json:"save_execution_log"`
 	Variables        map[string]interface{} `json:"variables"`
+	WorkerLabels     map[string]string      `json:"worker_labels,omitempty"` // labels a worker needs to run the workflow
+	// ConcurrencyPolicy decides what happens to a new execution when
+	// MaxConcurrency executions are already in progress
+	ConcurrencyPolicy ConcurrencyPolicy `json:"concurrency_policy,omitempty"`
+	// ExecutionMode decides whether nodes run once per execution or once
+	// per item
+	ExecutionMode ExecutionMode `json:"execution_mode,omitempty"`
+	// PartitionKey is a dot path into the input, such as customer.id.
+	// Queued executions with the same value there run one at a time, in
+	// the order they were queued.
+	PartitionKey string `json:"partition_key,omitempty"`
+	// Node limits lower the engine's for the workflow's node runs; 0 keeps
+	// the engine's
+	NodeTimeout       int `json:"node_timeout,omitempty"`         // seconds
+	MaxNodeInputSize  int `json:"max_node_input_size,omitempty"`  // bytes
+	MaxNodeOutputSize int `json:"max_node_output_size,omitempty"` // bytes
+}
+
+// ExecutionMode is how a workflow's nodes consume their input
+type ExecutionMode string
+
+const (
+	ExecutionModeSingle ExecutionMode = "single" // each node runs once on the whole input
+	ExecutionModeItems  ExecutionMode = "items"  // each node runs once per item reaching it
+)
+
+// Valid reports whether m is a known mode; empty means single
+func (m ExecutionMode) Valid() bool {
+	return m == "" || m == ExecutionModeSingle || m == ExecutionModeItems
+}
+
+// ConcurrencyPolicy is what a workflow does with a new execution while
+// earlier ones are still in progress
+type ConcurrencyPolicy string
+
+const (
+	ConcurrencyAllow   ConcurrencyPolicy = "allow"   // run side by side, up to MaxConcurrency if set
+	ConcurrencyForbid  ConcurrencyPolicy = "forbid"  // skip the new execution
+	ConcurrencyReplace ConcurrencyPolicy = "replace" // cancel the oldest execution and run the new one
+)
+
+// Valid reports whether p is a known policy; empty means allow
+func (p ConcurrencyPolicy) Valid() bool {
+	return p == "" || p == ConcurrencyAllow || p == ConcurrencyForbid || p == ConcurrencyReplace
 }
 
 // Execution represents a workflow execution
@@ -105,6 +313,34 @@ type Execution struct {
 	CompletedAt *time.Time             `json:"completed_at,omitempty" db:"completed_at"`
 	Metadata    map[string]interface{} `json:"metadata" db:"metadata"`
 	Context     ExecutionContext       `json:"context" db:"context"`
+	TenantID    uuid.UUID              `json:"tenant_id" db:"tenant_id"`
+	Usage       *ExecutionUsage        `json:"usage,omitempty" db:"-"` // set once the execution finishes
+	ClaimToken  int64                  `json:"-" db:"claim_token"`     // fencing token of the worker running it, 0 when unclaimed
+}
+
+// ExecutionSummary is the list view of an execution without its input,
+// output, and context
+type ExecutionSummary struct {
+	ID          uuid.UUID              `json:"id"`
+	WorkflowID  uuid.UUID              `json:"workflow_id"`
+	Status      ExecutionStatus        `json:"status"`
+	Error       *string                `json:"error,omitempty"`
+	StartedAt   time.Time              `json:"started_at"`
+	CompletedAt *time.Time             `json:"completed_at,omitempty"`
+	Metadata    map[string]interface{} `json:"metadata"`
+}
+
+// Summary returns the execution without its input, output, and context
+func (e *Execution) Summary() ExecutionSummary {
+	return ExecutionSummary{
+		ID:          e.ID,
+		WorkflowID:  e.WorkflowID,
+		Status:      e.Status,
+		Error:       e.Error,
+		StartedAt:   e.StartedAt,
+		CompletedAt: e.CompletedAt,
+		Metadata:    e.Metadata,
+	}
 }
 
 // ExecutionStatus represents the status of an execution
@@ -138,6 +374,25 @@ type NodeExecution struct {
 	StartedAt   time.Time              `json:"started_at"`
 	CompletedAt *time.Time             `json:"completed_at,omitempty"`
 	RetryCount  int                    `json:"retry_count"`
+	// Ports are the output ports the node emitted on; null for every port
+	Ports []string `json:"ports"`
+	// Cached is set when the node's output was reused from the result cache
+	// instead of running it
+	Cached bool `json:"cached,omitempty"`
+	// Snapshot is what the node saw when it ran. Executions read back from
+	// storage leave it out; it is loaded per node.
+	Snapshot *NodeSnapshot `json:"snapshot,omitempty"`
+}
+
+// NodeSnapshot is the data a node run saw. The outputs of earlier nodes,
+// which the input holds under nodeOutputs, are not copied: Upstream names
+// those nodes, whose outputs are stored with their own runs. Values of
+// secret variables are redacted.
+type NodeSnapshot struct {
+	Input     map[string]interface{}   `json:"input"`
+	Items     []map[string]interface{} `json:"items,omitempty"` // in item mode, the input of the run for each item instead
+	Variables map[string]interface{}   `json:"variables"`       // the execution's variables
+	Upstream  []string                 `json:"upstream,omitempty"`
 }
 
 // LogEntry represents a log entry
@@ -160,9 +415,40 @@ type User struct {
 	UpdatedAt    time.Time `json:"updated_at" db:"updated_at"`
 }
 
-// Schedule represents a workflow schedule
+// User roles
+const (
+	RoleAdmin = "admin"
+	RoleUser  = "user"
+)
+
+// Permissions granted to roles
+const (
+	// PermissionExecNode allows a user's workflows to run local commands with the exec node
+	PermissionExecNode = "node:exec"
+)
+
+// RolePermissions maps each role to its permissions; "*" grants all permissions
+var RolePermissions = map[string][]string{
+	RoleAdmin: {"*"},
+	RoleUser:  {},
+}
+
+// HasPermission reports whether the user's role grants the permission
+func (u *User) HasPermission(permission string) bool {
+	for _, granted := range RolePermissions[u.Role] {
+		if granted == "*" || granted == permission {
+			return true
+		}
+	}
+	return false
+}
+
+// Schedule runs a workflow with an input whenever its cron expression
+// fires in its timezone. A paused schedule is not active and has no next
+// run.
 type Schedule struct {
 	ID         uuid.UUID              `json:"id" db:"id"`
+	TenantID   uuid.UUID              `json:"-" db:"tenant_id"`
 	WorkflowID uuid.UUID              `json:"workflow_id" db:"workflow_id"`
 	Name       string                 `json:"name" db:"name"`
 	CronExpr   string                 `json:"cron_expr" db:"cron_expr"`
@@ -174,3 +460,34 @@ type Schedule struct {
 	CreatedAt  time.Time              `json:"created_at" db:"created_at"`
 	UpdatedAt  time.Time              `json:"updated_at" db:"updated_at"`
 }
+
+// BinaryData represents binary content produced during an execution. The
+// content lives in a binary data store; node outputs reference it by ID.
+type BinaryData struct {
+	ID          uuid.UUID `json:"id" db:"id"`
+	ExecutionID uuid.UUID `json:"execution_id" db:"execution_id"`
+	Storage     string    `json:"storage" db:"storage"`
+	StorageKey  string    `json:"storage_key" db:"storage_key"`
+	FileName    string    `json:"file_name" db:"file_name"`
+	MimeType    string    `json:"mime_type" db:"mime_type"`
+	Size        int64     `json:"size" db:"size"`
+	CreatedAt   time.Time `json:"created_at" db:"created_at"`
+}
+
+// Credential types
+const (
+	CredentialTypeGeneric = "generic"
+	CredentialTypeOAuth2  = "oauth2"
+)
+
+// Credential is a named secret stored encrypted in the credentials vault.
+// Data holds the ciphertext and is never serialized.
+type Credential struct {
+	ID        uuid.UUID `json:"id" db:"id"`
+	Name      string    `json:"name" db:"name"`
+	Type      string    `json:"type" db:"type"`
+	UserID    uuid.UUID `json:"user_id" db:"user_id"`
+	Data      string    `json:"-" db:"data"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
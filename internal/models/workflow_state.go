@@ -0,0 +1,16 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// WorkflowState is a value a workflow keeps between its executions, such
+// as the timestamp it last processed
+type WorkflowState struct {
+	WorkflowID uuid.UUID   `json:"workflow_id"`
+	Key        string      `json:"key"`
+	Value      interface{} `json:"value"`
+	UpdatedAt  time.Time   `json:"updated_at"`
+}
@@ -0,0 +1,270 @@
+// Package netpolicy decides which addresses outbound connections made on
+// behalf of workflows may reach. Hosts are resolved before connecting, every
+// address is checked, and the connection is made to a checked address so a
+// second DNS answer cannot redirect it.
+package netpolicy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nuumz/f1ow/internal/models"
+	"github.com/nuumz/f1ow/internal/tenant"
+
+	"github.com/google/uuid"
+)
+
+// ErrBlocked is returned when a connection is refused by the policy
+var ErrBlocked = errors.New("blocked by network policy")
+
+// namedRanges are the ranges rules may refer to by name
+var namedRanges = map[string][]string{
+	"loopback":   {"127.0.0.0/8", "::1/128", "0.0.0.0/8", "::/128"},
+	"private":    {"10.0.0.0/8", "172.16.0.0/12", "192.168.0.0/16", "100.64.0.0/10", "fc00::/7"},
+	"link-local": {"169.254.0.0/16", "fe80::/10"},
+	"metadata":   {"169.254.169.254/32", "169.254.170.2/32", "100.100.100.200/32", "fd00:ec2::254/128"},
+}
+
+// tenantCacheTTL is how long a tenant's rules are used before they are read
+// again
+const tenantCacheTTL = 30 * time.Second
+
+// TenantRules looks up the network policy of a tenant. It returns nil when
+// the tenant has none.
+type TenantRules interface {
+	TenantNetworkPolicy(ctx context.Context, id uuid.UUID) (*models.NetworkPolicy, error)
+}
+
+// rules are parsed allow and deny entries; allow entries win
+type rules struct {
+	allow []netip.Prefix
+	deny  []netip.Prefix
+}
+
+// decide reports whether the rules allow or deny addr, or have no opinion
+func (r rules) decide(addr netip.Addr) (allowed, matched bool) {
+	for _, prefix := range r.allow {
+		if prefix.Contains(addr) {
+			return true, true
+		}
+	}
+	for _, prefix := range r.deny {
+		if prefix.Contains(addr) {
+			return false, true
+		}
+	}
+	return false, false
+}
+
+type cachedRules struct {
+	rules   rules
+	expires time.Time
+}
+
+// Policy checks outbound connections against the server's rules and the
+// rules of the tenant the connection is made for. The server's deny entries
+// always win, so a tenant's rules can only narrow what the server allows.
+type Policy struct {
+	server   rules
+	tenants  TenantRules
+	resolver *net.Resolver
+	dialer   net.Dialer
+
+	mu    sync.Mutex
+	cache map[uuid.UUID]cachedRules
+}
+
+// New creates a policy from the server's allow and deny entries
+func New(policy models.NetworkPolicy) (*Policy, error) {
+	server, err := parseRules(policy)
+	if err != nil {
+		return nil, err
+	}
+	return &Policy{
+		server:   server,
+		resolver: net.DefaultResolver,
+		dialer:   net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second},
+		cache:    map[uuid.UUID]cachedRules{},
+	}, nil
+}
+
+// SetTenantRules makes the policy apply the rules of the context's tenant
+func (p *Policy) SetTenantRules(tenants TenantRules) {
+	p.tenants = tenants
+}
+
+// Validate checks that every entry of the policy is a CIDR, an IP address,
+// or a named range
+func Validate(policy models.NetworkPolicy) error {
+	_, err := parseRules(policy)
+	return err
+}
+
+func parseRules(policy models.NetworkPolicy) (rules, error) {
+	allow, err := parsePrefixes(policy.Allow)
+	if err != nil {
+		return rules{}, err
+	}
+	deny, err := parsePrefixes(policy.Deny)
+	if err != nil {
+		return rules{}, err
+	}
+	return rules{allow: allow, deny: deny}, nil
+}
+
+func parsePrefixes(entries []string) ([]netip.Prefix, error) {
+	var prefixes []netip.Prefix
+	for _, entry := range entries {
+		entry = strings.ToLower(strings.TrimSpace(entry))
+		if entry == "" {
+			continue
+		}
+		if named, ok := namedRanges[entry]; ok {
+			for _, cidr := range named {
+				prefixes = append(prefixes, netip.MustParsePrefix(cidr))
+			}
+			continue
+		}
+		if strings.Contains(entry, "/") {
+			prefix, err := netip.ParsePrefix(entry)
+			if err != nil {
+				return nil, fmt.Errorf("invalid network %q: %w", entry, err)
+			}
+			prefixes = append(prefixes, prefix.Masked())
+			continue
+		}
+		addr, err := netip.ParseAddr(entry)
+		if err != nil {
+			return nil, fmt.Errorf("invalid network %q: expected a CIDR, an IP address, or one of loopback, private, link-local, metadata", entry)
+		}
+		prefixes = append(prefixes, netip.PrefixFrom(addr, addr.BitLen()))
+	}
+	return prefixes, nil
+}
+
+// Check returns ErrBlocked when the policy refuses connections to addr for
+// the context's tenant
+func (p *Policy) Check(ctx context.Context, addr netip.Addr) error {
+	addr = addr.Unmap()
+
+	if allowed, matched := p.server.decide(addr); matched && !allowed {
+		return fmt.Errorf("address %s is %w", addr, ErrBlocked)
+	}
+
+	tenantRules, err := p.tenantRules(ctx)
+	if err != nil {
+		return err
+	}
+	if allowed, matched := tenantRules.decide(addr); matched && !allowed {
+		return fmt.Errorf("address %s is %w", addr, ErrBlocked)
+	}
+	return nil
+}
+
+// tenantRules returns the rules of the context's tenant, if any
+func (p *Policy) tenantRules(ctx context.Context) (rules, error) {
+	id, ok := tenant.FromContext(ctx)
+	if !ok || p.tenants == nil {
+		return rules{}, nil
+	}
+
+	p.mu.Lock()
+	cached, ok := p.cache[id]
+	p.mu.Unlock()
+	if ok && time.Now().Before(cached.expires) {
+		return cached.rules, nil
+	}
+
+	policy, err := p.tenants.TenantNetworkPolicy(ctx, id)
+	if err != nil {
+		return rules{}, fmt.Errorf("failed to load tenant network policy: %w", err)
+	}
+	var r rules
+	if policy != nil {
+		if r, err = parseRules(*policy); err != nil {
+			return rules{}, fmt.Errorf("invalid tenant network policy: %w", err)
+		}
+	}
+
+	p.mu.Lock()
+	p.cache[id] = cachedRules{rules: r, expires: time.Now().Add(tenantCacheTTL)}
+	p.mu.Unlock()
+	return r, nil
+}
+
+// DialContext resolves the host of address, checks every address it
+// resolves to, and connects to the first one that accepts the connection
+func (p *Policy) DialContext(ctx context.Context, network, address string) (net.Conn, error) {
+	host, port, err := net.SplitHostPort(address)
+	if err != nil {
+		return nil, err
+	}
+
+	addrs, err := p.resolver.LookupNetIP(ctx, "ip", host)
+	if err != nil {
+		return nil, err
+	}
+	if len(addrs) == 0 {
+		return nil, fmt.Errorf("no addresses found for %s", host)
+	}
+	// Refuse the host when any address is blocked, so a host cannot mix
+	// an internal address in with public ones
+	for _, addr := range addrs {
+		if err := p.Check(ctx, addr); err != nil {
+			return nil, fmt.Errorf("connection to %s refused: %w", host, err)
+		}
+	}
+
+	var lastErr error
+	for _, addr := range addrs {
+		conn, err := p.dialer.DialContext(ctx, network, net.JoinHostPort(addr.Unmap().String(), port))
+		if err == nil {
+			return conn, nil
+		}
+		lastErr = err
+	}
+	return nil, lastErr
+}
+
+// Client returns a copy of client whose connections are checked by the
+// policy. The copy has its own connection pool, so connections made for one
+// tenant are never reused for another. Clients with a transport other than
+// *http.Transport are returned unchanged.
+func (p *Policy) Client(client *http.Client) *http.Client {
+	var transport *http.Transport
+	switch t := client.Transport.(type) {
+	case nil:
+		transport = http.DefaultTransport.(*http.Transport).Clone()
+	case *http.Transport:
+		transport = t.Clone()
+	default:
+		return client
+	}
+	transport.DialContext = p.DialContext
+
+	guarded := *client
+	guarded.Transport = transport
+	return &guarded
+}
+
+type contextKey string
+
+const policyKey contextKey = "network_policy"
+
+// WithPolicy returns a context carrying the policy
+func WithPolicy(ctx context.Context, policy *Policy) context.Context {
+	return context.WithValue(ctx, policyKey, policy)
+}
+
+// FromContext returns the policy stored in the context, if any
+func FromContext(ctx context.Context) (*Policy, bool) {
+	policy, ok := ctx.Value(policyKey).(*Policy)
+	return policy, ok && policy != nil
+}
@@ -49,11 +49,14 @@ func processTemplate(template string, data interface{}) string {
 		// Extract variable name
 		varName := strings.TrimSpace(match[2 : len(match)-2])
 
-		// Try to get value from data
+		// Try to get value from data, falling back to a dot notation lookup
 		if dataMap, ok := data.(map[string]interface{}); ok {
 			if value, exists := dataMap[varName]; exists {
 				return fmt.Sprintf("%v", value)
 			}
+			if value := getValueByPath(dataMap, varName); value != nil {
+				return fmt.Sprintf("%v", value)
+			}
 		}
 
 		// Return original if not found
@@ -61,6 +64,26 @@ func processTemplate(template string, data interface{}) string {
 	})
 }
 
+// resolveTemplateValue resolves a template against data, keeping the original
+// type when the whole string is a single {{path}} reference
+func resolveTemplateValue(template string, data interface{}) interface{} {
+	trimmed := strings.TrimSpace(template)
+	if strings.HasPrefix(trimmed, "{{") && strings.HasSuffix(trimmed, "}}") &&
+		strings.Count(trimmed, "{{") == 1 {
+		varName := strings.TrimSpace(trimmed[2 : len(trimmed)-2])
+		if dataMap, ok := data.(map[string]interface{}); ok {
+			if value, exists := dataMap[varName]; exists {
+				return value
+			}
+			if value := getValueByPath(dataMap, varName); value != nil {
+				return value
+			}
+		}
+	}
+
+	return processTemplate(template, data)
+}
+
 // interpolateValue processes template variables in various value types
 func interpolateValue(value interface{}, data interface{}) interface{} {
 	switch v := value.(type) {
@@ -0,0 +1,95 @@
+package nodes
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/nuumz/f1ow/internal/binarydata"
+	"github.com/nuumz/f1ow/internal/engine"
+
+	"github.com/google/uuid"
+)
+
+// writeBinary stores binary content and returns the value to place in the
+// node output. With a binary data manager in the context the content is
+// streamed to the store and a {binary_id, file_name, mime_type, size} handle
+// is returned; otherwise the content is inlined as a base64
+// {data, mime_type, file_name, size} payload.
+func writeBinary(ctx context.Context, fileName, mimeType string, r io.Reader, size int64) (map[string]interface{}, error) {
+	if manager, ok := binarydata.FromContext(ctx); ok {
+		if info, ok := engine.ExecutionInfoFromContext(ctx); ok {
+			executionID, err := uuid.Parse(info.ExecutionID)
+			if err != nil {
+				return nil, fmt.Errorf("invalid execution ID: %w", err)
+			}
+
+			data, err := manager.Write(ctx, executionID, fileName, mimeType, r, size)
+			if err != nil {
+				return nil, err
+			}
+			return binarydata.RefMap(data), nil
+		}
+	}
+
+	content, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read binary content: %w", err)
+	}
+
+	return map[string]interface{}{
+		"data":      base64.StdEncoding.EncodeToString(content),
+		"mime_type": mimeType,
+		"file_name": fileName,
+		"size":      len(content),
+	}, nil
+}
+
+// openBinary returns a reader for binary content given as a binary data
+// handle, a base64 {data, ...} payload, or a plain base64 string
+func openBinary(ctx context.Context, value interface{}) (io.ReadCloser, error) {
+	if id, ok := binarydata.IDFromValue(value); ok {
+		manager, ok := binarydata.FromContext(ctx)
+		if !ok {
+			return nil, fmt.Errorf("binary data %s cannot be read: no binary data storage configured", id)
+		}
+
+		_, reader, err := manager.Open(ctx, id)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open binary data %s: %w", id, err)
+		}
+		return reader, nil
+	}
+
+	if payload, ok := value.(map[string]interface{}); ok {
+		value = payload["data"]
+		if value == nil {
+			return nil, fmt.Errorf("binary payload has no data")
+		}
+	}
+
+	encoded, ok := value.(string)
+	if !ok {
+		return nil, fmt.Errorf("binary content must be a base64 string, binary payload, or binary data handle")
+	}
+
+	data, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+	if err != nil {
+		return nil, fmt.Errorf("invalid base64 content: %w", err)
+	}
+	return io.NopCloser(bytes.NewReader(data)), nil
+}
+
+// readBinary reads binary content accepted by openBinary into memory
+func readBinary(ctx context.Context, value interface{}) ([]byte, error) {
+	reader, err := openBinary(ctx, value)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	return io.ReadAll(reader)
+}
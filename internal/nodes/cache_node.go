@@ -0,0 +1,243 @@
+package nodes
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/nuumz/f1ow/internal/engine"
+	"github.com/nuumz/f1ow/internal/tenant"
+)
+
+// CacheStore is a shared cache used by the cache node and HTTP response caching
+type CacheStore interface {
+	// GetCache returns the cached value for key and whether it was found
+	GetCache(ctx context.Context, key string) ([]byte, bool, error)
+	// SetCache stores value under key; a ttl of zero never expires
+	SetCache(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	// DeleteCache removes key, or every key starting with it when prefix is set
+	DeleteCache(ctx context.Context, key string, prefix bool) (int64, error)
+}
+
+// Cache key namespaces
+const (
+	cacheNodeKeyPrefix = "node:"
+	cacheHTTPKeyPrefix = "http:"
+)
+
+// tenantKey namespaces key under prefix and the context's tenant, so
+// tenants sharing a store cannot read or clear each other's entries
+func tenantKey(ctx context.Context, prefix, key string) string {
+	return prefix + tenant.IDOrDefault(ctx).String() + ":" + key
+}
+
+// CacheNode reads, writes, and invalidates entries in the shared cache
+type CacheNode struct {
+	BaseNode
+	store CacheStore
+}
+
+// CacheConfig defines configuration for cache node
+type CacheConfig struct {
+	Operation string      `json:"operation"` // "get", "set", "invalidate"
+	Key       string      `json:"key"`
+	Value     interface{} `json:"value"`
+	TTL       int         `json:"ttl"`    // seconds, 0 = no expiration
+	Prefix    bool        `json:"prefix"` // invalidate every key starting with key
+}
+
+// NewCacheNode creates a new cache node backed by store
+func NewCacheNode(store CacheStore) engine.NodeType {
+	return &CacheNode{
+		BaseNode: BaseNode{
+			nodeType:    "cache",
+			name:        "Cache",
+			description: "Get, set, and invalidate values in a shared cache",
+			category:    "Data Storage",
+			icon:        "archive",
+		},
+		store: store,
+	}
+}
+
+// Execute runs the cache operation
+func (n *CacheNode) Execute(ctx context.Context, config interface{}, input interface{}) (interface{}, error) {
+	cacheConfig, err := n.parseConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	if n.store == nil {
+		return nil, fmt.Errorf("cache storage is not available")
+	}
+
+	key := processTemplate(cacheConfig.Key, input)
+	if key == "" {
+		return nil, fmt.Errorf("key is required")
+	}
+	storeKey := tenantKey(ctx, cacheNodeKeyPrefix, key)
+
+	switch cacheConfig.Operation {
+	case "get":
+		raw, found, err := n.store.GetCache(ctx, storeKey)
+		if err != nil {
+			return nil, err
+		}
+		var value interface{}
+		if found {
+			if err := json.Unmarshal(raw, &value); err != nil {
+				return nil, fmt.Errorf("failed to decode cached value: %w", err)
+			}
+		}
+		return map[string]interface{}{"key": key, "hit": found, "value": value}, nil
+
+	case "set":
+		value := resolveContent(cacheConfig.Value, input)
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode value: %w", err)
+		}
+		if err := n.store.SetCache(ctx, storeKey, encoded, time.Duration(cacheConfig.TTL)*time.Second); err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{"key": key, "value": value, "ttl": cacheConfig.TTL}, nil
+
+	case "invalidate":
+		deleted, err := n.store.DeleteCache(ctx, storeKey, cacheConfig.Prefix)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{"key": key, "deleted": deleted}, nil
+
+	default:
+		return nil, fmt.Errorf("unsupported operation: %s", cacheConfig.Operation)
+	}
+}
+
+// ValidateConfig validates the node configuration
+func (n *CacheNode) ValidateConfig(config interface{}) error {
+	cacheConfig, err := n.parseConfig(config)
+	if err != nil {
+		return err
+	}
+
+	switch cacheConfig.Operation {
+	case "get", "set", "invalidate":
+	default:
+		return fmt.Errorf("invalid operation: %s", cacheConfig.Operation)
+	}
+
+	// Prefix invalidation with an empty key would clear the whole cache
+	if cacheConfig.Key == "" {
+		return fmt.Errorf("key is required")
+	}
+
+	if cacheConfig.TTL < 0 {
+		return fmt.Errorf("ttl must not be negative")
+	}
+
+	return nil
+}
+
+// GetSchema returns the node configuration schema
+func (n *CacheNode) GetSchema() engine.NodeSchema {
+	return engine.NodeSchema{
+		Type: "object",
+		Properties: map[string]engine.Property{
+			"operation": {
+				Type:        "string",
+				Title:       "Operation",
+				Description: "Cache operation to perform",
+				Default:     "get",
+				Enum:        []string{"get", "set", "invalidate"},
+			},
+			"key": {
+				Type:        "string",
+				Title:       "Key",
+				Description: "Cache key. Supports template variables like {{variable}}",
+			},
+			"value": {
+				Type:        "object",
+				Title:       "Value",
+				Description: "Value to cache for set. Supports template variables",
+			},
+			"ttl": {
+				Type:        "number",
+				Title:       "TTL",
+				Description: "Expiration in seconds for set (0 = no expiration)",
+				Default:     0,
+			},
+			"prefix": {
+				Type:        "boolean",
+				Title:       "Prefix",
+				Description: "Invalidate every key starting with key",
+				Default:     false,
+			},
+		},
+		Required: []string{"operation"},
+		Inputs: []engine.PortSchema{
+			{
+				Name:        "input",
+				Type:        "any",
+				Description: "Input data available for template variables",
+				Required:    false,
+			},
+		},
+		Outputs: []engine.PortSchema{
+			{
+				Name:        "output",
+				Type:        "object",
+				Description: "Operation result; get returns hit and value",
+				Required:    true,
+			},
+		},
+	}
+}
+
+// parseConfig parses the node configuration
+func (n *CacheNode) parseConfig(config interface{}) (*CacheConfig, error) {
+	configMap, ok := config.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("invalid config type for cache node")
+	}
+
+	configJSON, err := json.Marshal(configMap)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal config: %w", err)
+	}
+
+	var cacheConfig CacheConfig
+	if err := json.Unmarshal(configJSON, &cacheConfig); err != nil {
+		return nil, fmt.Errorf("failed to parse cache config: %w", err)
+	}
+
+	cacheConfig.Operation = strings.ToLower(cacheConfig.Operation)
+	if cacheConfig.Operation == "" {
+		cacheConfig.Operation = "get"
+	}
+
+	return &cacheConfig, nil
+}
+
+// httpCacheKey derives the response cache key from the request method, URL,
+// and headers, including authentication headers, in the tenant of the
+// request's context
+func httpCacheKey(req *http.Request) string {
+	names := make([]string, 0, len(req.Header))
+	for name := range req.Header {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	hash := sha256.New()
+	fmt.Fprintf(hash, "%s %s\n", req.Method, req.URL.String())
+	for _, name := range names {
+		fmt.Fprintf(hash, "%s: %s\n", name, strings.Join(req.Header[name], ","))
+	}
+	return tenantKey(req.Context(), cacheHTTPKeyPrefix, hex.EncodeToString(hash.Sum(nil)))
+}
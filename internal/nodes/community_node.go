@@ -0,0 +1,407 @@
+package nodes
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/nuumz/f1ow/internal/engine"
+
+	"gopkg.in/yaml.v3"
+)
+
+// CommunityNodeDefinition describes a SaaS API as a node type, so
+// integrations can be added with a YAML or JSON file instead of Go code.
+// Requests are sent by the HTTP node.
+type CommunityNodeDefinition struct {
+	Type        string                        `json:"type"`
+	Name        string                        `json:"name"`
+	Description string                        `json:"description"`
+	Category    string                        `json:"category"`
+	Icon        string                        `json:"icon"`
+	BaseURL     string                        `json:"base_url"` // may be overridden by the node's base_url config
+	Headers     map[string]string             `json:"headers"`  // sent with every request
+	Auth        *CommunityNodeAuth            `json:"auth"`
+	Operations  map[string]CommunityOperation `json:"operations"`
+}
+
+// CommunityNodeAuth is how requests authenticate. Secrets come from the
+// node config or the credential it references.
+type CommunityNodeAuth struct {
+	Type           string `json:"type"`             // "basic", "bearer", "api_key"
+	APIKeyName     string `json:"api_key_name"`     // header or query parameter holding the key
+	APIKeyLocation string `json:"api_key_location"` // "header" (default), "query"
+}
+
+// CommunityOperation is one API call of a community node
+type CommunityOperation struct {
+	Description string               `json:"description"`
+	Method      string               `json:"method"`
+	Path        string               `json:"path"` // may contain {param} placeholders
+	Parameters  []CommunityParameter `json:"parameters"`
+}
+
+// CommunityParameter is a config field sent in the path, query, headers, or JSON body
+type CommunityParameter struct {
+	Name        string      `json:"name"`
+	In          string      `json:"in"` // "path", "query", "header", "body"
+	Type        string      `json:"type"`
+	Title       string      `json:"title"`
+	Description string      `json:"description"`
+	Required    bool        `json:"required"`
+	Default     interface{} `json:"default,omitempty"`
+	Enum        []string    `json:"enum,omitempty"`
+}
+
+// communityAuthFields are the config fields each auth type reads secrets from
+var communityAuthFields = map[string][]string{
+	"basic":   {"username", "password"},
+	"bearer":  {"token"},
+	"api_key": {"api_key"},
+}
+
+var validParameterLocations = map[string]bool{"path": true, "query": true, "header": true, "body": true}
+
+// CommunityNode calls an API described by a CommunityNodeDefinition
+type CommunityNode struct {
+	BaseNode
+	definition CommunityNodeDefinition
+	http       engine.NodeType
+}
+
+// NewCommunityNode creates a node from a validated definition. cache is
+// passed to the underlying HTTP node and may be nil.
+func NewCommunityNode(definition CommunityNodeDefinition, cache CacheStore) engine.NodeType {
+	return &CommunityNode{
+		BaseNode: BaseNode{
+			nodeType:    definition.Type,
+			name:        definition.Name,
+			description: definition.Description,
+			category:    definition.Category,
+			icon:        definition.Icon,
+		},
+		definition: definition,
+		http:       NewHTTPNode(cache),
+	}
+}
+
+// ParseCommunityNodeDefinition parses and validates a YAML or JSON definition
+func ParseCommunityNodeDefinition(data []byte) (*CommunityNodeDefinition, error) {
+	// YAML is a superset of JSON; decode generically so only JSON tags are needed
+	var raw interface{}
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("invalid community node definition: %w", err)
+	}
+	encoded, err := json.Marshal(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid community node definition: %w", err)
+	}
+
+	var definition CommunityNodeDefinition
+	if err := json.Unmarshal(encoded, &definition); err != nil {
+		return nil, fmt.Errorf("invalid community node definition: %w", err)
+	}
+	if err := definition.Validate(); err != nil {
+		return nil, err
+	}
+	return &definition, nil
+}
+
+// LoadCommunityNodeDefinitions reads every .yaml, .yml, and .json file in dir
+func LoadCommunityNodeDefinitions(dir string) ([]*CommunityNodeDefinition, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read community nodes: %w", err)
+	}
+
+	var definitions []*CommunityNodeDefinition
+	for _, entry := range entries {
+		switch strings.ToLower(filepath.Ext(entry.Name())) {
+		case ".yaml", ".yml", ".json":
+		default:
+			continue
+		}
+		if entry.IsDir() {
+			continue
+		}
+
+		data, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", entry.Name(), err)
+		}
+		definition, err := ParseCommunityNodeDefinition(data)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", entry.Name(), err)
+		}
+		definitions = append(definitions, definition)
+	}
+	return definitions, nil
+}
+
+// Validate checks the definition and fills in defaults
+func (d *CommunityNodeDefinition) Validate() error {
+	if d.Type == "" {
+		return fmt.Errorf("community node type is required")
+	}
+	if d.BaseURL == "" {
+		return fmt.Errorf("community node %s: base_url is required", d.Type)
+	}
+	if len(d.Operations) == 0 {
+		return fmt.Errorf("community node %s: at least one operation is required", d.Type)
+	}
+	if d.Name == "" {
+		d.Name = d.Type
+	}
+	if d.Category == "" {
+		d.Category = "Community"
+	}
+
+	if d.Auth != nil {
+		if _, ok := communityAuthFields[d.Auth.Type]; !ok {
+			return fmt.Errorf("community node %s: invalid auth type %q", d.Type, d.Auth.Type)
+		}
+		if d.Auth.Type == "api_key" && d.Auth.APIKeyName == "" {
+			return fmt.Errorf("community node %s: api_key auth requires api_key_name", d.Type)
+		}
+	}
+
+	for name, op := range d.Operations {
+		if op.Method == "" {
+			op.Method = "GET"
+		}
+		op.Method = strings.ToUpper(op.Method)
+		for i, param := range op.Parameters {
+			if param.Name == "" {
+				return fmt.Errorf("community node %s: operation %s has a parameter without a name", d.Type, name)
+			}
+			if param.In == "" {
+				param.In = "query"
+			}
+			if !validParameterLocations[param.In] {
+				return fmt.Errorf("community node %s: parameter %s has invalid location %q", d.Type, param.Name, param.In)
+			}
+			if param.In == "path" {
+				if !strings.Contains(op.Path, "{"+param.Name+"}") {
+					return fmt.Errorf("community node %s: path of operation %s has no {%s}", d.Type, name, param.Name)
+				}
+				param.Required = true
+			}
+			if param.Type == "" {
+				param.Type = "string"
+			}
+			op.Parameters[i] = param
+		}
+		d.Operations[name] = op
+	}
+	return nil
+}
+
+// Execute builds the operation's request and sends it with the HTTP node
+func (n *CommunityNode) Execute(ctx context.Context, config interface{}, input interface{}) (interface{}, error) {
+	configMap, ok := config.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("invalid config type for %s node", n.definition.Type)
+	}
+	if err := n.ValidateConfig(configMap); err != nil {
+		return nil, err
+	}
+
+	httpConfig, err := n.httpConfig(configMap, input)
+	if err != nil {
+		return nil, err
+	}
+	return n.http.Execute(ctx, httpConfig, input)
+}
+
+// httpConfig translates the node config into an HTTP node config
+func (n *CommunityNode) httpConfig(config map[string]interface{}, input interface{}) (map[string]interface{}, error) {
+	operationName, _ := config["operation"].(string)
+	op := n.definition.Operations[operationName]
+
+	baseURL := n.definition.BaseURL
+	if override, _ := config["base_url"].(string); override != "" {
+		baseURL = override
+	}
+
+	path := op.Path
+	headers := make(map[string]string, len(n.definition.Headers))
+	for key, value := range n.definition.Headers {
+		headers[key] = value
+	}
+	query := make(map[string]string)
+	body := make(map[string]interface{})
+
+	for _, param := range op.Parameters {
+		value, ok := config[param.Name]
+		if !ok || value == nil {
+			if param.Default == nil {
+				continue
+			}
+			value = param.Default
+		}
+		if s, isString := value.(string); isString {
+			value = resolveTemplateValue(s, input)
+		}
+
+		switch param.In {
+		case "path":
+			path = strings.ReplaceAll(path, "{"+param.Name+"}", url.PathEscape(fmt.Sprint(value)))
+		case "query":
+			query[param.Name] = fmt.Sprint(value)
+		case "header":
+			headers[param.Name] = fmt.Sprint(value)
+		case "body":
+			body[param.Name] = value
+		}
+	}
+
+	httpConfig := map[string]interface{}{
+		"url":          strings.TrimRight(baseURL, "/") + path,
+		"method":       op.Method,
+		"headers":      headers,
+		"query_params": query,
+	}
+	if len(body) > 0 {
+		httpConfig["body"] = body
+		httpConfig["body_type"] = "json"
+	}
+
+	// Request options shared with the HTTP node
+	for _, key := range []string{"timeout", "retry_count", "retry_delay", "response_type", "pagination", "rate_limit", "cache_ttl"} {
+		if value, ok := config[key]; ok {
+			httpConfig[key] = value
+		}
+	}
+
+	if auth := n.definition.Auth; auth != nil {
+		authConfig := map[string]interface{}{"type": auth.Type}
+		switch auth.Type {
+		case "basic":
+			authConfig["username"] = config["username"]
+			authConfig["password"] = config["password"]
+		case "bearer":
+			authConfig["token"] = config["token"]
+		case "api_key":
+			authConfig["api_key"] = config["api_key"]
+			authConfig["api_key_name"] = auth.APIKeyName
+			authConfig["api_key_location"] = auth.APIKeyLocation
+		}
+		httpConfig["authentication"] = authConfig
+	}
+
+	return httpConfig, nil
+}
+
+// ValidateConfig checks the operation, its required parameters, and the
+// auth secrets
+func (n *CommunityNode) ValidateConfig(config interface{}) error {
+	configMap, ok := config.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("invalid config type for %s node", n.definition.Type)
+	}
+
+	operationName, _ := configMap["operation"].(string)
+	op, ok := n.definition.Operations[operationName]
+	if !ok {
+		return fmt.Errorf("operation must be one of: %s", strings.Join(n.operationNames(), ", "))
+	}
+
+	for _, param := range op.Parameters {
+		if !param.Required || param.Default != nil {
+			continue
+		}
+		if value, ok := configMap[param.Name]; !ok || value == nil || value == "" {
+			return fmt.Errorf("%s is required for operation %s", param.Name, operationName)
+		}
+	}
+
+	if n.definition.Auth != nil {
+		if credentialID, _ := configMap["credential_id"].(string); credentialID == "" {
+			for _, field := range communityAuthFields[n.definition.Auth.Type] {
+				if value, _ := configMap[field].(string); value == "" {
+					return fmt.Errorf("%s or credential_id is required", field)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// GetSchema returns the operation selector, every operation's parameters,
+// and the auth fields
+func (n *CommunityNode) GetSchema() engine.NodeSchema {
+	properties := map[string]engine.Property{
+		"operation": {
+			Type:        "string",
+			Title:       "Operation",
+			Description: "API operation to call",
+			Enum:        n.operationNames(),
+		},
+		"base_url": {
+			Type:        "string",
+			Title:       "Base URL",
+			Description: "Overrides the API base URL",
+			Default:     n.definition.BaseURL,
+		},
+	}
+
+	for _, name := range n.operationNames() {
+		for _, param := range n.definition.Operations[name].Parameters {
+			if _, exists := properties[param.Name]; exists {
+				continue
+			}
+			title := param.Title
+			if title == "" {
+				title = param.Name
+			}
+			properties[param.Name] = engine.Property{
+				Type:        param.Type,
+				Title:       title,
+				Description: param.Description,
+				Default:     param.Default,
+				Enum:        param.Enum,
+			}
+		}
+	}
+
+	if n.definition.Auth != nil {
+		properties["credential_id"] = engine.Property{
+			Type:        "string",
+			Title:       "Credential",
+			Description: "Vault credential supplying " + strings.Join(communityAuthFields[n.definition.Auth.Type], " and "),
+		}
+		for _, field := range communityAuthFields[n.definition.Auth.Type] {
+			property := engine.Property{Type: "string", Title: field}
+			if field != "username" {
+				property.Format = "password"
+			}
+			properties[field] = property
+		}
+	}
+
+	return engine.NodeSchema{
+		Type:       "object",
+		Properties: properties,
+		Required:   []string{"operation"},
+		Inputs: []engine.PortSchema{
+			{Name: "input", Type: "any", Description: "Values for {{templates}} in parameters"},
+		},
+		Outputs: []engine.PortSchema{
+			{Name: "output", Type: "object", Description: "HTTP response"},
+		},
+	}
+}
+
+func (n *CommunityNode) operationNames() []string {
+	names := make([]string, 0, len(n.definition.Operations))
+	for name := range n.definition.Operations {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
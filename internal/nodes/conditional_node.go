@@ -28,6 +28,25 @@ type Condition struct {
 	Value      interface{} `json:"value"`
 	Output     interface{} `json:"output"`
 	Expression string      `json:"expression"` // Alternative to field/operator/value
+	Port       string      `json:"port"`       // Output port emitted on when the condition matches; "true" when empty
+}
+
+// Output ports of the conditional node
+const (
+	conditionalTruePort  = "true"  // a condition matched
+	conditionalFalsePort = "false" // no condition matched
+)
+
+// supportedOperators lists the operators understood by evaluateCondition
+var supportedOperators = map[string]bool{
+	"equals": true, "==": true, "eq": true,
+	"not_equals": true, "!=": true, "ne": true,
+	"greater_than": true, ">": true, "gt": true,
+	"greater_than_or_equal": true, ">=": true, "gte": true,
+	"less_than": true, "<": true, "lt": true,
+	"less_than_or_equal": true, "<=": true, "lte": true,
+	"contains": true, "starts_with": true, "ends_with": true,
+	"exists": true, "not_exists": true, "in": true, "not_in": true,
 }
 
 // NewConditionalNode creates a new conditional node
@@ -45,9 +64,26 @@ func NewConditionalNode() engine.NodeType {
 
 // Execute evaluates conditions and returns appropriate output
 func (n *ConditionalNode) Execute(ctx context.Context, config interface{}, input interface{}) (interface{}, error) {
+	output, _, err := n.evaluate(config, input)
+	return output, err
+}
+
+// Run evaluates conditions and emits the output on the port of the
+// condition that matched, or on false when none did
+func (n *ConditionalNode) Run(ctx context.Context, config engine.NodeConfig, input engine.NodeInput) (engine.NodeOutput, error) {
+	output, port, err := n.evaluate(map[string]interface{}(config), input.Data)
+	if err != nil {
+		return engine.NodeOutput{}, err
+	}
+	return engine.NewNodeOutput(output, port), nil
+}
+
+// evaluate returns the output for the first matching condition and the
+// port to emit it on
+func (n *ConditionalNode) evaluate(config interface{}, input interface{}) (interface{}, string, error) {
 	conditionalConfig, err := n.parseConfig(config)
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
 
 	inputData := make(map[string]interface{})
@@ -61,15 +97,19 @@ func (n *ConditionalNode) Execute(ctx context.Context, config interface{}, input
 	for _, condition := range conditionalConfig.Conditions {
 		matched, err := n.evaluateCondition(condition, inputData)
 		if err != nil {
-			return nil, fmt.Errorf("failed to evaluate condition: %w", err)
+			return nil, "", fmt.Errorf("failed to evaluate condition: %w", err)
 		}
 
 		if matched {
+			port := condition.Port
+			if port == "" {
+				port = conditionalTruePort
+			}
 			output := condition.Output
 			if conditionalConfig.OutputPath != "" {
-				return n.setOutputPath(inputData, conditionalConfig.OutputPath, output), nil
+				return n.setOutputPath(inputData, conditionalConfig.OutputPath, output), port, nil
 			}
-			return output, nil
+			return output, port, nil
 		}
 	}
 
@@ -77,13 +117,13 @@ func (n *ConditionalNode) Execute(ctx context.Context, config interface{}, input
 	if conditionalConfig.DefaultOutput != nil {
 		output := conditionalConfig.DefaultOutput
 		if conditionalConfig.OutputPath != "" {
-			return n.setOutputPath(inputData, conditionalConfig.OutputPath, output), nil
+			return n.setOutputPath(inputData, conditionalConfig.OutputPath, output), conditionalFalsePort, nil
 		}
-		return output, nil
+		return output, conditionalFalsePort, nil
 	}
 
 	// Return original input if no default specified
-	return input, nil
+	return input, conditionalFalsePort, nil
 }
 
 // ValidateConfig validates the node configuration
@@ -143,11 +183,17 @@ func (n *ConditionalNode) GetSchema() engine.NodeSchema {
 		},
 		Outputs: []engine.PortSchema{
 			{
-				Name:        "output",
+				Name:        conditionalTruePort,
 				Type:        "any",
-				Description: "Output based on matching condition",
+				Description: "Output of the matching condition; conditions with a port emit on it instead",
 				Required:    true,
 			},
+			{
+				Name:        conditionalFalsePort,
+				Type:        "any",
+				Description: "Default output when no condition matched",
+				Required:    false,
+			},
 		},
 	}
 }
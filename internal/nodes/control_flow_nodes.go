@@ -71,6 +71,11 @@ func (n *LoopNode) Execute(ctx context.Context, config interface{}, input interf
 		if i >= maxIter {
 			break
 		}
+		// Stop at the node timeout or cancellation rather than finishing a
+		// long array in the background
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
 
 		// Check break condition
 		if loopConfig.BreakCondition != nil {
@@ -0,0 +1,782 @@
+package nodes
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/nuumz/f1ow/internal/engine"
+)
+
+// CSVParseNode parses CSV or XLSX content into items
+type CSVParseNode struct {
+	BaseNode
+}
+
+// CSVParseConfig defines configuration for csv_parse node
+type CSVParseConfig struct {
+	Source         string            `json:"source"` // template resolving to CSV text or a binary payload
+	Format         string            `json:"format"` // "csv", "xlsx"
+	Delimiter      string            `json:"delimiter"`
+	Quote          string            `json:"quote"` // quote character; empty disables quoting
+	Header         *bool             `json:"header"`
+	Columns        []string          `json:"columns"`    // column names, replacing the header row when present
+	HeaderMap      map[string]string `json:"header_map"` // column -> output key; mapping to "" drops the column
+	InferTypes     *bool             `json:"infer_types"`
+	TrimSpace      bool              `json:"trim_space"`
+	SkipEmptyLines *bool             `json:"skip_empty_lines"`
+	Sheet          string            `json:"sheet"`
+}
+
+// CSVGenerateNode serializes items into CSV or XLSX files
+type CSVGenerateNode struct {
+	BaseNode
+}
+
+// CSVGenerateConfig defines configuration for csv_generate node
+type CSVGenerateConfig struct {
+	ItemsPath string            `json:"items_path"`
+	Format    string            `json:"format"` // "csv", "xlsx"
+	Delimiter string            `json:"delimiter"`
+	Quote     string            `json:"quote"`
+	QuoteAll  bool              `json:"quote_all"`
+	Header    *bool             `json:"header"`
+	Columns   []string          `json:"columns"`    // item keys in output order
+	HeaderMap map[string]string `json:"header_map"` // item key -> header label
+	Sheet     string            `json:"sheet"`
+	FileName  string            `json:"file_name"`
+	Output    string            `json:"output"` // "binary", "text"
+}
+
+// NewCSVParseNode creates a new csv_parse node
+func NewCSVParseNode() engine.NodeType {
+	return &CSVParseNode{
+		BaseNode: BaseNode{
+			nodeType:    "csv_parse",
+			name:        "Parse CSV",
+			description: "Parse CSV or XLSX content into items with header mapping and type inference",
+			category:    "Data Processing",
+			icon:        "table",
+		},
+	}
+}
+
+// NewCSVGenerateNode creates a new csv_generate node
+func NewCSVGenerateNode() engine.NodeType {
+	return &CSVGenerateNode{
+		BaseNode: BaseNode{
+			nodeType:    "csv_generate",
+			name:        "Generate CSV",
+			description: "Generate a CSV or XLSX file from items",
+			category:    "Data Processing",
+			icon:        "table",
+		},
+	}
+}
+
+// Execute parses the source content into items
+func (n *CSVParseNode) Execute(ctx context.Context, config interface{}, input interface{}) (interface{}, error) {
+	parseConfig, err := n.parseConfig(config)
+	if err != nil {
+		return nil, err
+	}
+
+	content, err := csvSourceContent(ctx, resolveTemplateValue(parseConfig.Source, input))
+	if err != nil {
+		return nil, err
+	}
+
+	var rows [][]string
+	if parseConfig.Format == "xlsx" {
+		rows, err = readXLSXRows(content, parseConfig.Sheet)
+	} else {
+		rows, err = newCSVDialect(parseConfig.Delimiter, parseConfig.Quote).parse(content)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if *parseConfig.SkipEmptyLines {
+		filtered := rows[:0]
+		for _, row := range rows {
+			if !isEmptyRow(row) {
+				filtered = append(filtered, row)
+			}
+		}
+		rows = filtered
+	}
+
+	columns := parseConfig.Columns
+	if *parseConfig.Header && len(rows) > 0 {
+		if columns == nil {
+			columns = rows[0]
+		}
+		rows = rows[1:]
+	}
+	if columns == nil && len(rows) > 0 {
+		// Without a header, columns are named by position
+		for i := range rows[0] {
+			columns = append(columns, fmt.Sprintf("column_%d", i+1))
+		}
+	}
+
+	keys := make([]string, len(columns))
+	outputColumns := []interface{}{}
+	for i, column := range columns {
+		column = strings.TrimSpace(column)
+		keys[i] = column
+		if mapped, ok := parseConfig.HeaderMap[column]; ok {
+			keys[i] = mapped
+		}
+		if keys[i] != "" {
+			outputColumns = append(outputColumns, keys[i])
+		}
+	}
+
+	items := make([]interface{}, 0, len(rows))
+	for _, row := range rows {
+		item := make(map[string]interface{}, len(keys))
+		for i, key := range keys {
+			if key == "" {
+				continue
+			}
+			value := ""
+			if i < len(row) {
+				value = row[i]
+			}
+			if parseConfig.TrimSpace {
+				value = strings.TrimSpace(value)
+			}
+			if *parseConfig.InferTypes {
+				item[key] = inferCSVValue(value)
+			} else {
+				item[key] = value
+			}
+		}
+		items = append(items, item)
+	}
+
+	return map[string]interface{}{
+		"items":   items,
+		"count":   len(items),
+		"columns": outputColumns,
+	}, nil
+}
+
+// ValidateConfig validates the node configuration
+func (n *CSVParseNode) ValidateConfig(config interface{}) error {
+	parseConfig, err := n.parseConfig(config)
+	if err != nil {
+		return err
+	}
+
+	if parseConfig.Format != "csv" && parseConfig.Format != "xlsx" {
+		return fmt.Errorf("invalid format: %s", parseConfig.Format)
+	}
+
+	return validateCSVDialect(parseConfig.Delimiter, parseConfig.Quote)
+}
+
+// GetSchema returns the node configuration schema
+func (n *CSVParseNode) GetSchema() engine.NodeSchema {
+	return engine.NodeSchema{
+		Type: "object",
+		Properties: map[string]engine.Property{
+			"source": {
+				Type:        "string",
+				Title:       "Source",
+				Description: "Template resolving to CSV text or a binary file payload, e.g. {{body}}",
+				Default:     "{{data}}",
+			},
+			"format": {
+				Type:        "string",
+				Title:       "Format",
+				Description: "Source format",
+				Default:     "csv",
+				Enum:        []string{"csv", "xlsx"},
+			},
+			"delimiter": {
+				Type:        "string",
+				Title:       "Delimiter",
+				Description: "Field delimiter",
+				Default:     ",",
+			},
+			"quote": {
+				Type:        "string",
+				Title:       "Quote Character",
+				Description: "Character used to quote fields; empty disables quoting",
+				Default:     "\"",
+			},
+			"header": {
+				Type:        "boolean",
+				Title:       "Header Row",
+				Description: "The first row holds column names",
+				Default:     true,
+			},
+			"columns": {
+				Type:        "array",
+				Title:       "Columns",
+				Description: "Column names to use instead of the header row",
+			},
+			"header_map": {
+				Type:        "object",
+				Title:       "Header Mapping",
+				Description: "Rename columns, e.g. {\"First Name\": \"first_name\"}; map to \"\" to drop a column",
+			},
+			"infer_types": {
+				Type:        "boolean",
+				Title:       "Infer Types",
+				Description: "Convert numbers, booleans, and empty cells to typed values",
+				Default:     true,
+			},
+			"trim_space": {
+				Type:        "boolean",
+				Title:       "Trim Whitespace",
+				Description: "Trim whitespace around values",
+				Default:     false,
+			},
+			"skip_empty_lines": {
+				Type:        "boolean",
+				Title:       "Skip Empty Lines",
+				Description: "Ignore rows without any values",
+				Default:     true,
+			},
+			"sheet": {
+				Type:        "string",
+				Title:       "Sheet",
+				Description: "XLSX sheet name; defaults to the first sheet",
+			},
+		},
+		Required: []string{},
+		Inputs: []engine.PortSchema{
+			{
+				Name:        "input",
+				Type:        "any",
+				Description: "Input data containing the content to parse",
+				Required:    true,
+			},
+		},
+		Outputs: []engine.PortSchema{
+			{
+				Name:        "output",
+				Type:        "object",
+				Description: "Parsed items and column names",
+				Required:    true,
+			},
+		},
+	}
+}
+
+// parseConfig parses the node configuration
+func (n *CSVParseNode) parseConfig(config interface{}) (*CSVParseConfig, error) {
+	configMap, ok := config.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("invalid config type for csv_parse node")
+	}
+
+	configJSON, err := json.Marshal(configMap)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal config: %w", err)
+	}
+
+	parseConfig := CSVParseConfig{Delimiter: ",", Quote: `"`}
+	if err := json.Unmarshal(configJSON, &parseConfig); err != nil {
+		return nil, fmt.Errorf("failed to parse csv_parse config: %w", err)
+	}
+
+	// Set defaults
+	if parseConfig.Source == "" {
+		parseConfig.Source = "{{data}}"
+	}
+	if parseConfig.Format == "" {
+		parseConfig.Format = "csv"
+	}
+	parseConfig.Header = boolDefault(parseConfig.Header, true)
+	parseConfig.InferTypes = boolDefault(parseConfig.InferTypes, true)
+	parseConfig.SkipEmptyLines = boolDefault(parseConfig.SkipEmptyLines, true)
+
+	return &parseConfig, nil
+}
+
+// Execute generates a file from the input items
+func (n *CSVGenerateNode) Execute(ctx context.Context, config interface{}, input interface{}) (interface{}, error) {
+	generateConfig, err := n.parseConfig(config)
+	if err != nil {
+		return nil, err
+	}
+
+	inputData, _ := input.(map[string]interface{})
+	items, ok := getValueByPath(inputData, generateConfig.ItemsPath).([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("value at path '%s' is not an array", generateConfig.ItemsPath)
+	}
+
+	columns := generateConfig.Columns
+	if columns == nil {
+		columns = collectColumns(items)
+	}
+
+	var rows [][]string
+	if *generateConfig.Header {
+		header := make([]string, len(columns))
+		for i, column := range columns {
+			header[i] = column
+			if label, ok := generateConfig.HeaderMap[column]; ok {
+				header[i] = label
+			}
+		}
+		rows = append(rows, header)
+	}
+	for _, item := range items {
+		noHeader := false
+		_, row := itemToRow(&columns, &noHeader, item)
+		rows = append(rows, row)
+	}
+
+	var buf bytes.Buffer
+	mimeType := "text/csv"
+	if generateConfig.Format == "xlsx" {
+		mimeType = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+		writer, err := newXLSXItemWriter(&buf, tabularOptions{Sheet: generateConfig.Sheet})
+		if err != nil {
+			return nil, err
+		}
+		for _, row := range rows {
+			if err := writer.writeRow(row); err != nil {
+				return nil, fmt.Errorf("failed to write XLSX row: %w", err)
+			}
+		}
+		if err := writer.Close(); err != nil {
+			return nil, fmt.Errorf("failed to write XLSX: %w", err)
+		}
+	} else {
+		dialect := newCSVDialect(generateConfig.Delimiter, generateConfig.Quote)
+		dialect.quoteAll = generateConfig.QuoteAll
+		if err := dialect.write(&buf, rows); err != nil {
+			return nil, err
+		}
+	}
+
+	if generateConfig.Output == "text" {
+		if generateConfig.Format == "xlsx" {
+			return nil, fmt.Errorf("xlsx output must be binary")
+		}
+		return map[string]interface{}{
+			"content": buf.String(),
+			"count":   len(items),
+		}, nil
+	}
+
+	result, err := writeBinary(ctx, processTemplate(generateConfig.FileName, input), mimeType, &buf, int64(buf.Len()))
+	if err != nil {
+		return nil, err
+	}
+	result["count"] = len(items)
+	return result, nil
+}
+
+// ValidateConfig validates the node configuration
+func (n *CSVGenerateNode) ValidateConfig(config interface{}) error {
+	generateConfig, err := n.parseConfig(config)
+	if err != nil {
+		return err
+	}
+
+	if generateConfig.Format != "csv" && generateConfig.Format != "xlsx" {
+		return fmt.Errorf("invalid format: %s", generateConfig.Format)
+	}
+
+	if generateConfig.Output != "binary" && generateConfig.Output != "text" {
+		return fmt.Errorf("invalid output: %s", generateConfig.Output)
+	}
+
+	if generateConfig.Format == "xlsx" && generateConfig.Output == "text" {
+		return fmt.Errorf("xlsx output must be binary")
+	}
+
+	return validateCSVDialect(generateConfig.Delimiter, generateConfig.Quote)
+}
+
+// GetSchema returns the node configuration schema
+func (n *CSVGenerateNode) GetSchema() engine.NodeSchema {
+	return engine.NodeSchema{
+		Type: "object",
+		Properties: map[string]engine.Property{
+			"items_path": {
+				Type:        "string",
+				Title:       "Items Path",
+				Description: "Path to the array of items in the input",
+				Default:     "items",
+			},
+			"format": {
+				Type:        "string",
+				Title:       "Format",
+				Description: "Output file format",
+				Default:     "csv",
+				Enum:        []string{"csv", "xlsx"},
+			},
+			"delimiter": {
+				Type:        "string",
+				Title:       "Delimiter",
+				Description: "Field delimiter",
+				Default:     ",",
+			},
+			"quote": {
+				Type:        "string",
+				Title:       "Quote Character",
+				Description: "Character used to quote fields; empty disables quoting",
+				Default:     "\"",
+			},
+			"quote_all": {
+				Type:        "boolean",
+				Title:       "Quote All Fields",
+				Description: "Quote every field instead of only fields that need it",
+				Default:     false,
+			},
+			"header": {
+				Type:        "boolean",
+				Title:       "Header Row",
+				Description: "Write a header row",
+				Default:     true,
+			},
+			"columns": {
+				Type:        "array",
+				Title:       "Columns",
+				Description: "Item keys to include, in order; defaults to all keys",
+			},
+			"header_map": {
+				Type:        "object",
+				Title:       "Header Labels",
+				Description: "Header label per item key, e.g. {\"first_name\": \"First Name\"}",
+			},
+			"file_name": {
+				Type:        "string",
+				Title:       "File Name",
+				Description: "File name of the generated file. Supports template variables",
+			},
+			"output": {
+				Type:        "string",
+				Title:       "Output",
+				Description: "Emit a binary file payload or CSV text",
+				Default:     "binary",
+				Enum:        []string{"binary", "text"},
+			},
+		},
+		Required: []string{},
+		Inputs: []engine.PortSchema{
+			{
+				Name:        "input",
+				Type:        "object",
+				Description: "Input data containing the items",
+				Required:    true,
+			},
+		},
+		Outputs: []engine.PortSchema{
+			{
+				Name:        "output",
+				Type:        "object",
+				Description: "Generated file as binary content {binary_id|data, mime_type, file_name, size}, or text",
+				Required:    true,
+			},
+		},
+	}
+}
+
+// parseConfig parses the node configuration
+func (n *CSVGenerateNode) parseConfig(config interface{}) (*CSVGenerateConfig, error) {
+	configMap, ok := config.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("invalid config type for csv_generate node")
+	}
+
+	configJSON, err := json.Marshal(configMap)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal config: %w", err)
+	}
+
+	generateConfig := CSVGenerateConfig{Delimiter: ",", Quote: `"`}
+	if err := json.Unmarshal(configJSON, &generateConfig); err != nil {
+		return nil, fmt.Errorf("failed to parse csv_generate config: %w", err)
+	}
+
+	// Set defaults
+	if generateConfig.ItemsPath == "" {
+		generateConfig.ItemsPath = "items"
+	}
+	if generateConfig.Format == "" {
+		generateConfig.Format = "csv"
+	}
+	if generateConfig.Output == "" {
+		generateConfig.Output = "binary"
+	}
+	if generateConfig.FileName == "" {
+		generateConfig.FileName = "data." + generateConfig.Format
+	}
+	generateConfig.Header = boolDefault(generateConfig.Header, true)
+
+	return &generateConfig, nil
+}
+
+// boolDefault returns value, or a pointer to def when value is unset
+func boolDefault(value *bool, def bool) *bool {
+	if value == nil {
+		return &def
+	}
+	return value
+}
+
+// csvSourceContent returns the raw bytes of CSV text or binary content
+func csvSourceContent(ctx context.Context, source interface{}) ([]byte, error) {
+	switch v := source.(type) {
+	case string:
+		return []byte(v), nil
+	case map[string]interface{}:
+		return readBinary(ctx, v)
+	case nil:
+		return nil, fmt.Errorf("source is empty")
+	default:
+		return nil, fmt.Errorf("source must be text or a binary payload, got %T", source)
+	}
+}
+
+// readXLSXRows reads all rows of a worksheet
+func readXLSXRows(content []byte, sheet string) ([][]string, error) {
+	reader, err := newXLSXItemReader(bytes.NewReader(content), tabularOptions{Sheet: sheet})
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	var rows [][]string
+	for {
+		item, err := reader.Next()
+		if err == io.EOF {
+			return rows, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read XLSX row: %w", err)
+		}
+
+		values := item.([]interface{})
+		row := make([]string, len(values))
+		for i, value := range values {
+			row[i] = value.(string)
+		}
+		rows = append(rows, row)
+	}
+}
+
+// collectColumns returns the keys of all map items in first-seen order
+func collectColumns(items []interface{}) []string {
+	var columns []string
+	seen := make(map[string]bool)
+	for _, item := range items {
+		itemMap, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		keys := make([]string, 0, len(itemMap))
+		for key := range itemMap {
+			if !seen[key] {
+				keys = append(keys, key)
+			}
+		}
+		// Map iteration order is random; keep new keys of an item sorted
+		sort.Strings(keys)
+		for _, key := range keys {
+			seen[key] = true
+			columns = append(columns, key)
+		}
+	}
+	return columns
+}
+
+// isEmptyRow reports whether every field in a row is blank
+func isEmptyRow(row []string) bool {
+	for _, value := range row {
+		if strings.TrimSpace(value) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// inferCSVValue converts a cell into a number, boolean, or nil where possible
+func inferCSVValue(value string) interface{} {
+	trimmed := strings.TrimSpace(value)
+	if trimmed == "" {
+		return nil
+	}
+
+	switch strings.ToLower(trimmed) {
+	case "true":
+		return true
+	case "false":
+		return false
+	}
+
+	// Keep identifiers with leading zeros, like zip codes, as strings
+	digits := strings.TrimPrefix(trimmed, "-")
+	if len(digits) > 1 && digits[0] == '0' && digits[1] != '.' {
+		return value
+	}
+
+	if i, err := strconv.ParseInt(trimmed, 10, 64); err == nil {
+		return i
+	}
+	if f, err := strconv.ParseFloat(trimmed, 64); err == nil && !strings.ContainsAny(trimmed, "xXnN") {
+		return f
+	}
+
+	return value
+}
+
+// validateCSVDialect validates delimiter and quote characters
+func validateCSVDialect(delimiter, quote string) error {
+	if utf8.RuneCountInString(delimiter) != 1 {
+		return fmt.Errorf("delimiter must be a single character")
+	}
+	if utf8.RuneCountInString(quote) > 1 {
+		return fmt.Errorf("quote must be a single character or empty")
+	}
+	if delimiter == quote {
+		return fmt.Errorf("delimiter and quote must differ")
+	}
+	if strings.ContainsAny(delimiter, "\r\n") || strings.ContainsAny(quote, "\r\n") {
+		return fmt.Errorf("delimiter and quote cannot be line breaks")
+	}
+	return nil
+}
+
+// csvDialect reads and writes CSV with a configurable delimiter and quote
+// character, which encoding/csv does not support
+type csvDialect struct {
+	delimiter rune
+	quote     rune // 0 disables quoting
+	quoteAll  bool
+}
+
+func newCSVDialect(delimiter, quote string) *csvDialect {
+	dialect := &csvDialect{delimiter: ','}
+	if r, _ := utf8.DecodeRuneInString(delimiter); r != utf8.RuneError {
+		dialect.delimiter = r
+	}
+	if r, _ := utf8.DecodeRuneInString(quote); r != utf8.RuneError {
+		dialect.quote = r
+	}
+	return dialect
+}
+
+// parse splits content into rows. Quoted fields may contain delimiters, line
+// breaks, and doubled quote characters.
+func (d *csvDialect) parse(content []byte) ([][]string, error) {
+	reader := bufio.NewReader(bytes.NewReader(bytes.TrimPrefix(content, []byte("\xef\xbb\xbf"))))
+
+	var rows [][]string
+	var row []string
+	var field strings.Builder
+	inQuotes := false
+	line := 1
+
+	endField := func() {
+		row = append(row, field.String())
+		field.Reset()
+	}
+	endRow := func() {
+		endField()
+		rows = append(rows, row)
+		row = nil
+	}
+
+	for {
+		r, _, err := reader.ReadRune()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if inQuotes {
+			if r == d.quote {
+				next, _, err := reader.ReadRune()
+				if err == nil && next == d.quote {
+					field.WriteRune(d.quote)
+					continue
+				}
+				if err == nil {
+					reader.UnreadRune()
+				}
+				inQuotes = false
+				continue
+			}
+			if r == '\n' {
+				line++
+			}
+			field.WriteRune(r)
+			continue
+		}
+
+		switch {
+		case d.quote != 0 && r == d.quote && field.Len() == 0:
+			inQuotes = true
+		case r == d.delimiter:
+			endField()
+		case r == '\r':
+			// Handled with the following \n; a lone \r also ends the row
+			if next, _, err := reader.ReadRune(); err == nil && next != '\n' {
+				reader.UnreadRune()
+			}
+			endRow()
+			line++
+		case r == '\n':
+			endRow()
+			line++
+		default:
+			field.WriteRune(r)
+		}
+	}
+
+	if inQuotes {
+		return nil, fmt.Errorf("unterminated quoted field starting before line %d", line)
+	}
+	if field.Len() > 0 || row != nil {
+		endRow()
+	}
+
+	return rows, nil
+}
+
+// write serializes rows, quoting fields that need it or all fields
+func (d *csvDialect) write(w io.Writer, rows [][]string) error {
+	bw := bufio.NewWriter(w)
+	for _, row := range rows {
+		for i, field := range row {
+			if i > 0 {
+				bw.WriteRune(d.delimiter)
+			}
+			if d.quote != 0 && (d.quoteAll || d.needsQuotes(field)) {
+				bw.WriteRune(d.quote)
+				bw.WriteString(strings.ReplaceAll(field, string(d.quote), string(d.quote)+string(d.quote)))
+				bw.WriteRune(d.quote)
+			} else {
+				bw.WriteString(field)
+			}
+		}
+		bw.WriteString("\n")
+	}
+	return bw.Flush()
+}
+
+// needsQuotes reports whether a field must be quoted
+func (d *csvDialect) needsQuotes(field string) bool {
+	return strings.ContainsRune(field, d.delimiter) || strings.ContainsRune(field, d.quote) ||
+		strings.ContainsAny(field, "\r\n") || strings.TrimSpace(field) != field
+}
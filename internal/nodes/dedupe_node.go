@@ -0,0 +1,260 @@
+package nodes
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/nuumz/f1ow/internal/engine"
+)
+
+// SeenStore persists keys that were processed by previous executions
+type SeenStore interface {
+	// MarkSeen records keys for a scope and reports which were already seen
+	MarkSeen(ctx context.Context, scope string, keys []string, ttl time.Duration) ([]bool, error)
+}
+
+// DedupeNode drops items already processed in previous executions
+type DedupeNode struct {
+	BaseNode
+	stores map[string]SeenStore
+}
+
+// DedupeConfig defines configuration for dedupe node
+type DedupeConfig struct {
+	ArrayPath string   `json:"array_path"`
+	KeyFields []string `json:"key_fields"` // Fields forming the dedupe key; whole item when empty
+	Store     string   `json:"store"`      // "redis", "database"
+	TTL       int      `json:"ttl"`        // seconds, 0 = keep forever
+	Scope     string   `json:"scope"`      // Overrides the default workflow/node scope
+}
+
+// NewDedupeNode creates a new dedupe node backed by the given seen-set stores.
+// Either store may be nil when that backend is not available.
+func NewDedupeNode(redisStore SeenStore, dbStore SeenStore) engine.NodeType {
+	stores := make(map[string]SeenStore)
+	if redisStore != nil {
+		stores["redis"] = redisStore
+	}
+	if dbStore != nil {
+		stores["database"] = dbStore
+	}
+
+	return &DedupeNode{
+		BaseNode: BaseNode{
+			nodeType:    "dedupe",
+			name:        "Dedupe",
+			description: "Drop items that were already processed by previous executions",
+			category:    "Data Processing",
+			icon:        "copy",
+		},
+		stores: stores,
+	}
+}
+
+// Execute filters out items whose key was already seen
+func (n *DedupeNode) Execute(ctx context.Context, config interface{}, input interface{}) (interface{}, error) {
+	dedupeConfig, err := n.parseConfig(config)
+	if err != nil {
+		return nil, err
+	}
+
+	store, ok := n.stores[dedupeConfig.Store]
+	if !ok {
+		return nil, fmt.Errorf("dedupe store %s is not available", dedupeConfig.Store)
+	}
+
+	inputData := make(map[string]interface{})
+	if inputMap, ok := input.(map[string]interface{}); ok {
+		inputData = inputMap
+	} else {
+		inputData["data"] = input
+	}
+
+	arrayValue := getValueByPath(inputData, dedupeConfig.ArrayPath)
+	array, ok := arrayValue.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("value at path '%s' is not an array", dedupeConfig.ArrayPath)
+	}
+
+	keys := make([]string, len(array))
+	for i, item := range array {
+		key, err := dedupeKey(item, dedupeConfig.KeyFields)
+		if err != nil {
+			return nil, fmt.Errorf("failed to compute key for item %d: %w", i, err)
+		}
+		keys[i] = key
+	}
+
+	scope, err := n.resolveScope(ctx, dedupeConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	seen, err := store.MarkSeen(ctx, scope, keys, time.Duration(dedupeConfig.TTL)*time.Second)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check seen items: %w", err)
+	}
+
+	items := make([]interface{}, 0)
+	duplicates := make([]interface{}, 0)
+	for i, item := range array {
+		if seen[i] {
+			duplicates = append(duplicates, item)
+		} else {
+			items = append(items, item)
+		}
+	}
+
+	return map[string]interface{}{
+		"items":           items,
+		"duplicates":      duplicates,
+		"new_count":       len(items),
+		"duplicate_count": len(duplicates),
+	}, nil
+}
+
+// ValidateConfig validates the node configuration
+func (n *DedupeNode) ValidateConfig(config interface{}) error {
+	dedupeConfig, err := n.parseConfig(config)
+	if err != nil {
+		return err
+	}
+
+	if dedupeConfig.ArrayPath == "" {
+		return fmt.Errorf("array_path is required")
+	}
+
+	if dedupeConfig.Store != "redis" && dedupeConfig.Store != "database" {
+		return fmt.Errorf("invalid store: %s", dedupeConfig.Store)
+	}
+
+	if dedupeConfig.TTL < 0 {
+		return fmt.Errorf("ttl must not be negative")
+	}
+
+	return nil
+}
+
+// GetSchema returns the node configuration schema
+func (n *DedupeNode) GetSchema() engine.NodeSchema {
+	return engine.NodeSchema{
+		Type: "object",
+		Properties: map[string]engine.Property{
+			"array_path": {
+				Type:        "string",
+				Title:       "Array Path",
+				Description: "Path to the array of items to dedupe (e.g., 'data.items')",
+			},
+			"key_fields": {
+				Type:        "array",
+				Title:       "Key Fields",
+				Description: "Fields that identify an item (e.g., ['id']). The whole item is used when empty",
+			},
+			"store": {
+				Type:        "string",
+				Title:       "Store",
+				Description: "Where seen keys are persisted",
+				Default:     "redis",
+				Enum:        []string{"redis", "database"},
+			},
+			"ttl": {
+				Type:        "number",
+				Title:       "TTL",
+				Description: "How long a key is remembered in seconds (0 = forever)",
+				Default:     0,
+			},
+			"scope": {
+				Type:        "string",
+				Title:       "Scope",
+				Description: "Seen-set name shared across nodes (defaults to the workflow and node ID)",
+			},
+		},
+		Required: []string{"array_path"},
+		Inputs: []engine.PortSchema{
+			{
+				Name:        "input",
+				Type:        "object",
+				Description: "Input data containing the array to dedupe",
+				Required:    true,
+			},
+		},
+		Outputs: []engine.PortSchema{
+			{
+				Name:        "output",
+				Type:        "object",
+				Description: "New items and dropped duplicates",
+				Required:    true,
+			},
+		},
+	}
+}
+
+// parseConfig parses the node configuration
+func (n *DedupeNode) parseConfig(config interface{}) (*DedupeConfig, error) {
+	configMap, ok := config.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("invalid config type for dedupe node")
+	}
+
+	configJSON, err := json.Marshal(configMap)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal config: %w", err)
+	}
+
+	var dedupeConfig DedupeConfig
+	if err := json.Unmarshal(configJSON, &dedupeConfig); err != nil {
+		return nil, fmt.Errorf("failed to parse dedupe config: %w", err)
+	}
+
+	// Set defaults
+	if dedupeConfig.Store == "" {
+		dedupeConfig.Store = "redis"
+	}
+
+	return &dedupeConfig, nil
+}
+
+// resolveScope returns the seen-set scope, defaulting to workflow and node
+// ID. Configured scopes are kept apart per tenant.
+func (n *DedupeNode) resolveScope(ctx context.Context, config *DedupeConfig) (string, error) {
+	if config.Scope != "" {
+		return tenantKey(ctx, "", config.Scope), nil
+	}
+
+	info, ok := engine.ExecutionInfoFromContext(ctx)
+	if !ok || info.WorkflowID == "" || info.NodeID == "" {
+		return "", fmt.Errorf("scope is required when running outside a workflow execution")
+	}
+
+	return info.WorkflowID + ":" + info.NodeID, nil
+}
+
+// dedupeKey hashes the key fields of an item into a stable identifier
+func dedupeKey(item interface{}, keyFields []string) (string, error) {
+	var keyValue interface{} = item
+
+	if len(keyFields) > 0 {
+		itemData, ok := item.(map[string]interface{})
+		if !ok {
+			return "", fmt.Errorf("key_fields require object items")
+		}
+		values := make([]interface{}, len(keyFields))
+		for i, field := range keyFields {
+			values[i] = getValueByPath(itemData, field)
+		}
+		keyValue = values
+	}
+
+	// json.Marshal sorts map keys, so equal items produce equal keys
+	keyJSON, err := json.Marshal(keyValue)
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256(keyJSON)
+	return hex.EncodeToString(sum[:]), nil
+}
@@ -0,0 +1,213 @@
+package nodes
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/nuumz/f1ow/internal/engine"
+)
+
+// EmailTriggerNode starts executions for messages received in an IMAP mailbox
+type EmailTriggerNode struct {
+	BaseNode
+}
+
+// EmailTriggerConfig defines configuration for email trigger node
+type EmailTriggerConfig struct {
+	Host                string `json:"host"`
+	Port                int    `json:"port"`
+	Security            string `json:"security"` // "tls", "starttls", "none"
+	Username            string `json:"username"`
+	Password            string `json:"password"`
+	Mailbox             string `json:"mailbox"`
+	Search              string `json:"search"`        // "unseen", "all"
+	PollInterval        int    `json:"poll_interval"` // seconds
+	MaxMessages         int    `json:"max_messages"`  // per poll
+	MarkSeen            bool   `json:"mark_seen"`     // Set the \Seen flag on the server after processing
+	DownloadAttachments bool   `json:"download_attachments"`
+	IgnoreSSLIssues     bool   `json:"ignore_ssl_issues"`
+	WebhookSecret       string `json:"webhook_secret"` // Token providers send to the inbound email webhook
+}
+
+// NewEmailTriggerNode creates a new email trigger node
+func NewEmailTriggerNode() engine.NodeType {
+	return &EmailTriggerNode{
+		BaseNode: BaseNode{
+			nodeType:    "email_trigger",
+			name:        "Email Received",
+			description: "Start the workflow when an email arrives in an IMAP mailbox or via provider webhook",
+			category:    "Triggers",
+			icon:        "mail",
+		},
+	}
+}
+
+// Execute passes the received message through to downstream nodes
+func (n *EmailTriggerNode) Execute(ctx context.Context, config interface{}, input interface{}) (interface{}, error) {
+	if inputMap, ok := input.(map[string]interface{}); ok {
+		return inputMap, nil
+	}
+	return map[string]interface{}{"data": input}, nil
+}
+
+// ValidateConfig validates the node configuration
+func (n *EmailTriggerNode) ValidateConfig(config interface{}) error {
+	emailConfig, err := ParseEmailTriggerConfig(config)
+	if err != nil {
+		return err
+	}
+
+	if emailConfig.Host == "" {
+		return fmt.Errorf("host is required")
+	}
+
+	if emailConfig.Username == "" {
+		return fmt.Errorf("username is required")
+	}
+
+	validSecurity := map[string]bool{"tls": true, "starttls": true, "none": true}
+	if !validSecurity[emailConfig.Security] {
+		return fmt.Errorf("invalid security: %s", emailConfig.Security)
+	}
+
+	if emailConfig.Search != "unseen" && emailConfig.Search != "all" {
+		return fmt.Errorf("invalid search: %s", emailConfig.Search)
+	}
+
+	return nil
+}
+
+// GetSchema returns the node configuration schema
+func (n *EmailTriggerNode) GetSchema() engine.NodeSchema {
+	return engine.NodeSchema{
+		Type: "object",
+		Properties: map[string]engine.Property{
+			"host": {
+				Type:        "string",
+				Title:       "IMAP Host",
+				Description: "IMAP server hostname",
+			},
+			"port": {
+				Type:        "number",
+				Title:       "Port",
+				Description: "IMAP server port",
+				Default:     993,
+			},
+			"security": {
+				Type:        "string",
+				Title:       "Security",
+				Description: "Connection security",
+				Default:     "tls",
+				Enum:        []string{"tls", "starttls", "none"},
+			},
+			"username": {
+				Type:        "string",
+				Title:       "Username",
+				Description: "Mailbox username",
+			},
+			"password": {
+				Type:        "string",
+				Title:       "Password",
+				Description: "Mailbox password or app password",
+				Format:      "password",
+			},
+			"mailbox": {
+				Type:        "string",
+				Title:       "Mailbox",
+				Description: "Mailbox to watch",
+				Default:     "INBOX",
+			},
+			"search": {
+				Type:        "string",
+				Title:       "Search",
+				Description: "Which messages to consider on each poll",
+				Default:     "unseen",
+				Enum:        []string{"unseen", "all"},
+			},
+			"poll_interval": {
+				Type:        "number",
+				Title:       "Poll Interval",
+				Description: "Seconds between mailbox polls",
+				Default:     60,
+			},
+			"max_messages": {
+				Type:        "number",
+				Title:       "Max Messages",
+				Description: "Maximum number of messages processed per poll",
+				Default:     50,
+			},
+			"mark_seen": {
+				Type:        "boolean",
+				Title:       "Mark as Seen",
+				Description: "Set the \\Seen flag on processed messages",
+				Default:     false,
+			},
+			"download_attachments": {
+				Type:        "boolean",
+				Title:       "Download Attachments",
+				Description: "Include base64 attachment content in the payload",
+				Default:     false,
+			},
+			"webhook_secret": {
+				Type:        "string",
+				Title:       "Webhook Secret",
+				Description: "Token a mail provider sends in the X-F1ow-Token header or token query parameter when posting to the inbound email webhook; the webhook rejects deliveries without it",
+				Format:      "password",
+			},
+		},
+		Required: []string{"host", "username"},
+		Inputs:   []engine.PortSchema{},
+		Outputs: []engine.PortSchema{
+			{
+				Name:        "output",
+				Type:        "object",
+				Description: "Parsed message with headers, text, html, and attachments",
+				Required:    true,
+			},
+		},
+	}
+}
+
+// ParseEmailTriggerConfig parses an email trigger configuration and applies defaults
+func ParseEmailTriggerConfig(config interface{}) (*EmailTriggerConfig, error) {
+	configMap, ok := config.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("invalid config type for email trigger node")
+	}
+
+	configJSON, err := json.Marshal(configMap)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal config: %w", err)
+	}
+
+	var emailConfig EmailTriggerConfig
+	if err := json.Unmarshal(configJSON, &emailConfig); err != nil {
+		return nil, fmt.Errorf("failed to parse email trigger config: %w", err)
+	}
+
+	// Set defaults
+	if emailConfig.Security == "" {
+		emailConfig.Security = "tls"
+	}
+	if emailConfig.Port == 0 {
+		emailConfig.Port = 993
+		if emailConfig.Security != "tls" {
+			emailConfig.Port = 143
+		}
+	}
+	if emailConfig.Mailbox == "" {
+		emailConfig.Mailbox = "INBOX"
+	}
+	if emailConfig.Search == "" {
+		emailConfig.Search = "unseen"
+	}
+	if emailConfig.PollInterval <= 0 {
+		emailConfig.PollInterval = 60
+	}
+	if emailConfig.MaxMessages <= 0 {
+		emailConfig.MaxMessages = 50
+	}
+
+	return &emailConfig, nil
+}
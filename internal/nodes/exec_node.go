@@ -0,0 +1,328 @@
+package nodes
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/nuumz/f1ow/internal/engine"
+	"github.com/nuumz/f1ow/internal/models"
+
+	"github.com/google/uuid"
+)
+
+// UserStore looks up workflow owners for permission checks
+type UserStore interface {
+	GetUser(ctx context.Context, id uuid.UUID) (*models.User, error)
+}
+
+// ExecNode runs a local command on the worker
+type ExecNode struct {
+	BaseNode
+	enabled bool
+	users   UserStore
+}
+
+// ExecConfig defines configuration for exec node
+type ExecConfig struct {
+	Command     string            `json:"command"`
+	Args        []string          `json:"args"`
+	Shell       bool              `json:"shell"` // run command through "sh -c"
+	WorkingDir  string            `json:"working_dir"`
+	Env         map[string]string `json:"env"`
+	InheritEnv  bool              `json:"inherit_env"` // pass the worker's environment to the command
+	Stdin       string            `json:"stdin"`
+	MaxOutput   int               `json:"max_output"` // bytes captured per stream
+	FailOnError bool              `json:"fail_on_error"`
+	Timeout     int               `json:"timeout"` // seconds
+}
+
+// NewExecNode creates a new exec node. The node refuses to run unless enabled,
+// and only runs for workflows whose owner has the exec permission.
+func NewExecNode(enabled bool, users UserStore) engine.NodeType {
+	return &ExecNode{
+		BaseNode: BaseNode{
+			nodeType:    "exec",
+			name:        "Execute Command",
+			description: "Run a local command on the worker",
+			category:    "Advanced",
+			icon:        "terminal",
+		},
+		enabled: enabled,
+		users:   users,
+	}
+}
+
+// Execute runs the command
+func (n *ExecNode) Execute(ctx context.Context, config interface{}, input interface{}) (interface{}, error) {
+	if !n.enabled {
+		return nil, fmt.Errorf("exec node is disabled; set ENABLE_EXEC_NODE=true to enable it")
+	}
+
+	if err := n.authorize(ctx); err != nil {
+		return nil, err
+	}
+
+	execConfig, err := n.parseConfig(config)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(execConfig.Timeout)*time.Second)
+	defer cancel()
+
+	command := processTemplate(execConfig.Command, input)
+	var cmd *exec.Cmd
+	if execConfig.Shell {
+		cmd = exec.CommandContext(ctx, "sh", "-c", command)
+	} else {
+		args := make([]string, len(execConfig.Args))
+		for i, arg := range execConfig.Args {
+			args[i] = processTemplate(arg, input)
+		}
+		cmd = exec.CommandContext(ctx, command, args...)
+	}
+
+	cmd.Dir = processTemplate(execConfig.WorkingDir, input)
+	cmd.Env = n.environment(execConfig, input)
+	cmd.WaitDelay = 5 * time.Second
+	if execConfig.Stdin != "" {
+		cmd.Stdin = strings.NewReader(processTemplate(execConfig.Stdin, input))
+	}
+
+	stdout := &limitedBuffer{limit: execConfig.MaxOutput}
+	stderr := &limitedBuffer{limit: execConfig.MaxOutput}
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+
+	runErr := cmd.Run()
+	if ctx.Err() == context.DeadlineExceeded {
+		return nil, fmt.Errorf("command timed out after %ds", execConfig.Timeout)
+	}
+
+	exitCode := 0
+	if runErr != nil {
+		var exitErr *exec.ExitError
+		if !errors.As(runErr, &exitErr) {
+			return nil, fmt.Errorf("failed to run command: %w", runErr)
+		}
+		exitCode = exitErr.ExitCode()
+	}
+
+	if exitCode != 0 && execConfig.FailOnError {
+		return nil, fmt.Errorf("command exited with code %d: %s", exitCode, strings.TrimSpace(stderr.String()))
+	}
+
+	return map[string]interface{}{
+		"stdout":    stdout.String(),
+		"stderr":    stderr.String(),
+		"exit_code": exitCode,
+		"truncated": stdout.truncated || stderr.truncated,
+	}, nil
+}
+
+// ValidateConfig validates the node configuration
+func (n *ExecNode) ValidateConfig(config interface{}) error {
+	if !n.enabled {
+		return fmt.Errorf("exec node is disabled; set ENABLE_EXEC_NODE=true to enable it")
+	}
+
+	execConfig, err := n.parseConfig(config)
+	if err != nil {
+		return err
+	}
+
+	if execConfig.Command == "" {
+		return fmt.Errorf("command is required")
+	}
+	if execConfig.Shell && len(execConfig.Args) > 0 {
+		return fmt.Errorf("args cannot be used with shell; include them in the command")
+	}
+	if execConfig.Timeout < 0 {
+		return fmt.Errorf("timeout must be positive")
+	}
+
+	return nil
+}
+
+// GetSchema returns the node configuration schema
+func (n *ExecNode) GetSchema() engine.NodeSchema {
+	return engine.NodeSchema{
+		Type: "object",
+		Properties: map[string]engine.Property{
+			"command": {
+				Type:        "string",
+				Title:       "Command",
+				Description: "Program to run, or a shell command line when shell is enabled",
+			},
+			"args": {
+				Type:        "array",
+				Title:       "Arguments",
+				Description: "Program arguments, passed without shell interpretation. Support template variables",
+			},
+			"shell": {
+				Type:        "boolean",
+				Title:       "Use Shell",
+				Description: "Run the command through sh -c. Template values are inserted as-is, so quote untrusted input",
+				Default:     false,
+			},
+			"working_dir": {
+				Type:        "string",
+				Title:       "Working Directory",
+				Description: "Directory to run the command in; the worker's directory when empty",
+			},
+			"env": {
+				Type:        "object",
+				Title:       "Environment",
+				Description: "Environment variables for the command. Values support template variables",
+			},
+			"inherit_env": {
+				Type:        "boolean",
+				Title:       "Inherit Environment",
+				Description: "Pass the worker's environment, including its secrets, to the command",
+				Default:     false,
+			},
+			"stdin": {
+				Type:        "string",
+				Title:       "Standard Input",
+				Description: "Data written to the command's stdin. Supports template variables",
+				Format:      "textarea",
+			},
+			"max_output": {
+				Type:        "number",
+				Title:       "Max Output",
+				Description: "Maximum bytes captured from stdout and from stderr",
+				Default:     1048576,
+			},
+			"fail_on_error": {
+				Type:        "boolean",
+				Title:       "Fail on Error",
+				Description: "Fail the node when the command exits with a non-zero code",
+				Default:     false,
+			},
+			"timeout": {
+				Type:        "number",
+				Title:       "Timeout",
+				Description: "Seconds before the command is killed",
+				Default:     60,
+			},
+		},
+		Required: []string{"command"},
+		Inputs: []engine.PortSchema{
+			{
+				Name:        "input",
+				Type:        "any",
+				Description: "Input data available for template variables",
+				Required:    false,
+			},
+		},
+		Outputs: []engine.PortSchema{
+			{
+				Name:        "output",
+				Type:        "object",
+				Description: "Command result {stdout, stderr, exit_code, truncated}",
+				Required:    true,
+			},
+		},
+	}
+}
+
+// parseConfig parses the node configuration
+func (n *ExecNode) parseConfig(config interface{}) (*ExecConfig, error) {
+	configMap, ok := config.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("invalid config type for exec node")
+	}
+
+	configJSON, err := json.Marshal(configMap)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal config: %w", err)
+	}
+
+	var execConfig ExecConfig
+	if err := json.Unmarshal(configJSON, &execConfig); err != nil {
+		return nil, fmt.Errorf("failed to parse exec config: %w", err)
+	}
+
+	// Set defaults
+	if execConfig.MaxOutput == 0 {
+		execConfig.MaxOutput = 1024 * 1024
+	}
+	if execConfig.Timeout == 0 {
+		execConfig.Timeout = 60
+	}
+
+	return &execConfig, nil
+}
+
+// authorize checks that the workflow owner may run local commands
+func (n *ExecNode) authorize(ctx context.Context) error {
+	info, ok := engine.ExecutionInfoFromContext(ctx)
+	if !ok || info.UserID == "" {
+		return fmt.Errorf("exec node requires a workflow owner with the %s permission", models.PermissionExecNode)
+	}
+	if n.users == nil {
+		return fmt.Errorf("exec node cannot verify permissions: no user store configured")
+	}
+
+	userID, err := uuid.Parse(info.UserID)
+	if err != nil {
+		return fmt.Errorf("invalid workflow owner ID: %w", err)
+	}
+
+	user, err := n.users.GetUser(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("failed to load workflow owner: %w", err)
+	}
+	if !user.HasPermission(models.PermissionExecNode) {
+		return fmt.Errorf("workflow owner lacks the %s permission", models.PermissionExecNode)
+	}
+
+	return nil
+}
+
+// environment builds the command environment. Without inherit_env only PATH
+// is passed so worker secrets do not leak into commands.
+func (n *ExecNode) environment(config *ExecConfig, input interface{}) []string {
+	var env []string
+	if config.InheritEnv {
+		env = os.Environ()
+	} else {
+		env = []string{"PATH=" + os.Getenv("PATH")}
+	}
+
+	for key, value := range config.Env {
+		env = append(env, key+"="+processTemplate(value, input))
+	}
+	return env
+}
+
+// limitedBuffer captures output up to a limit and discards the rest. The
+// buffer is not embedded so io.Copy cannot bypass Write through ReadFrom.
+type limitedBuffer struct {
+	buf       bytes.Buffer
+	limit     int
+	truncated bool
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	remaining := b.limit - b.buf.Len()
+	if len(p) > remaining {
+		if remaining > 0 {
+			b.buf.Write(p[:remaining])
+		}
+		b.truncated = true
+		return len(p), nil
+	}
+	return b.buf.Write(p)
+}
+
+func (b *limitedBuffer) String() string {
+	return b.buf.String()
+}
@@ -0,0 +1,232 @@
+package nodes
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/nuumz/f1ow/internal/engine"
+	"github.com/nuumz/f1ow/internal/models"
+	"github.com/nuumz/f1ow/internal/storage"
+
+	"github.com/google/uuid"
+)
+
+// externalTaskNamespace derives task IDs from the execution, node, and
+// payload, so a resumed execution waits on the task it already created
+var externalTaskNamespace = uuid.MustParse("5c1f7f2e-8a4b-4e39-9d0a-1f6c2b7e4a91")
+
+// externalTaskMaxPoll caps the interval between checks on a parked task
+const externalTaskMaxPoll = 2 * time.Second
+
+// ExternalTaskStore holds the tasks external_task nodes park for workers.
+// Get returns storage.ErrExternalTaskNotFound for unknown tasks.
+type ExternalTaskStore interface {
+	CreateExternalTask(ctx context.Context, task *models.ExternalTask) error
+	GetExternalTask(ctx context.Context, id uuid.UUID) (*models.ExternalTask, error)
+}
+
+// ExternalTaskNode parks work on a topic for workers outside the engine
+// and waits for one to complete or fail it
+type ExternalTaskNode struct {
+	BaseNode
+	store ExternalTaskStore
+}
+
+// ExternalTaskConfig defines configuration for external task node
+type ExternalTaskConfig struct {
+	Topic   string      `json:"topic"`
+	Payload interface{} `json:"payload"` // Data for the worker; the input when unset. Supports {{template}} variables
+	Timeout int         `json:"timeout"` // Seconds to wait for a worker to finish the task
+}
+
+// NewExternalTaskNode creates a new external task node backed by store
+func NewExternalTaskNode(store ExternalTaskStore) engine.NodeType {
+	return &ExternalTaskNode{
+		BaseNode: BaseNode{
+			nodeType:    "external_task",
+			name:        "External Task",
+			description: "Hand work to a worker outside the engine and wait for its result",
+			category:    "Integration",
+			icon:        "external-link",
+		},
+		store: store,
+	}
+}
+
+// Execute creates the task, or finds the one an earlier run of the
+// execution created, and waits for its outcome
+func (n *ExternalTaskNode) Execute(ctx context.Context, config interface{}, input interface{}) (interface{}, error) {
+	taskConfig, err := n.parseConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	if n.store == nil {
+		return nil, fmt.Errorf("external task storage is not available")
+	}
+
+	info, _ := engine.ExecutionInfoFromContext(ctx)
+	workflowID, err := uuid.Parse(info.WorkflowID)
+	if err != nil {
+		return nil, fmt.Errorf("external tasks are only available in workflow executions")
+	}
+	executionID, _ := uuid.Parse(info.ExecutionID)
+
+	payload, err := n.payload(taskConfig, input)
+	if err != nil {
+		return nil, err
+	}
+	payloadJSON, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal payload: %w", err)
+	}
+	taskID := uuid.NewSHA1(externalTaskNamespace, []byte(info.ExecutionID+"/"+info.NodeID+"/"+string(payloadJSON)))
+
+	task, err := n.store.GetExternalTask(ctx, taskID)
+	if errors.Is(err, storage.ErrExternalTaskNotFound) {
+		task = &models.ExternalTask{
+			ID:          taskID,
+			Topic:       taskConfig.Topic,
+			WorkflowID:  workflowID,
+			ExecutionID: executionID,
+			NodeID:      info.NodeID,
+			Payload:     payload,
+		}
+		err = n.store.CreateExternalTask(ctx, task)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	timeout := time.NewTimer(time.Duration(taskConfig.Timeout) * time.Second)
+	defer timeout.Stop()
+
+	poll := 100 * time.Millisecond
+	for {
+		switch task.Status {
+		case models.ExternalTaskCompleted:
+			return map[string]interface{}{"task_id": task.ID.String(), "result": task.Result}, nil
+		case models.ExternalTaskFailed:
+			message := "external task failed"
+			if task.Error != nil {
+				message = *task.Error
+			}
+			return nil, fmt.Errorf("external task %s failed: %s", task.ID, message)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-timeout.C:
+			return nil, fmt.Errorf("external task %s on topic %s was not finished within %ds", task.ID, task.Topic, taskConfig.Timeout)
+		case <-time.After(poll):
+		}
+		if poll *= 2; poll > externalTaskMaxPoll {
+			poll = externalTaskMaxPoll
+		}
+
+		if task, err = n.store.GetExternalTask(ctx, taskID); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// payload returns the data handed to the worker
+func (n *ExternalTaskNode) payload(config *ExternalTaskConfig, input interface{}) (map[string]interface{}, error) {
+	if config.Payload == nil {
+		data, _ := input.(map[string]interface{})
+		return deepCopyMap(data), nil
+	}
+	switch resolved := resolveContent(config.Payload, input).(type) {
+	case map[string]interface{}:
+		return resolved, nil
+	default:
+		return nil, fmt.Errorf("payload must be an object, got %T", resolved)
+	}
+}
+
+// ValidateConfig validates the node configuration
+func (n *ExternalTaskNode) ValidateConfig(config interface{}) error {
+	taskConfig, err := n.parseConfig(config)
+	if err != nil {
+		return err
+	}
+
+	if taskConfig.Topic == "" {
+		return fmt.Errorf("topic is required")
+	}
+	if taskConfig.Timeout <= 0 {
+		return fmt.Errorf("timeout must be positive")
+	}
+
+	return nil
+}
+
+// GetSchema returns the node configuration schema
+func (n *ExternalTaskNode) GetSchema() engine.NodeSchema {
+	return engine.NodeSchema{
+		Type: "object",
+		Properties: map[string]engine.Property{
+			"topic": {
+				Type:        "string",
+				Title:       "Topic",
+				Description: "Topic workers fetch the task from",
+			},
+			"payload": {
+				Type:        "object",
+				Title:       "Payload",
+				Description: "Data for the worker; defaults to the input. Supports {{template}} variables",
+			},
+			"timeout": {
+				Type:        "integer",
+				Title:       "Timeout",
+				Description: "Seconds to wait for a worker to complete or fail the task",
+				Default:     3600,
+			},
+		},
+		Required: []string{"topic"},
+		Inputs: []engine.PortSchema{
+			{
+				Name:        "input",
+				Type:        "any",
+				Description: "Data the payload is resolved against",
+				Required:    false,
+			},
+		},
+		Outputs: []engine.PortSchema{
+			{
+				Name:        "output",
+				Type:        "object",
+				Description: "The task ID and the result the worker completed it with",
+				Required:    true,
+			},
+		},
+	}
+}
+
+// parseConfig parses the node configuration
+func (n *ExternalTaskNode) parseConfig(config interface{}) (*ExternalTaskConfig, error) {
+	configMap, ok := config.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("invalid config type for external task node")
+	}
+
+	configJSON, err := json.Marshal(configMap)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal config: %w", err)
+	}
+
+	var taskConfig ExternalTaskConfig
+	if err := json.Unmarshal(configJSON, &taskConfig); err != nil {
+		return nil, fmt.Errorf("failed to parse external task config: %w", err)
+	}
+
+	// Set defaults
+	if _, ok := configMap["timeout"]; !ok {
+		taskConfig.Timeout = 3600
+	}
+
+	return &taskConfig, nil
+}
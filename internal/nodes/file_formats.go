@@ -0,0 +1,489 @@
+package nodes
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// itemReader reads items one at a time so large files never have to be held
+// in memory. Next returns io.EOF after the last item.
+type itemReader interface {
+	Next() (interface{}, error)
+	Close() error
+}
+
+// itemWriter writes items one at a time
+type itemWriter interface {
+	Write(item interface{}) error
+	Close() error
+}
+
+// tabularOptions configures CSV and XLSX encoding
+type tabularOptions struct {
+	Delimiter string   // CSV field delimiter
+	Header    bool     // First row holds column names
+	Columns   []string // Column order for writing; taken from the first item when empty
+	Sheet     string   // XLSX sheet name
+}
+
+// formatFromPath infers a file format from the file extension
+func formatFromPath(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		return "json"
+	case ".ndjson", ".jsonl":
+		return "ndjson"
+	case ".csv", ".tsv":
+		return "csv"
+	case ".xlsx":
+		return "xlsx"
+	case ".txt", ".md", ".log", ".xml", ".html":
+		return "text"
+	default:
+		return "binary"
+	}
+}
+
+// newItemReader returns a streaming reader for a structured format
+func newItemReader(format string, r io.Reader, opts tabularOptions) (itemReader, error) {
+	switch format {
+	case "csv":
+		return newCSVItemReader(r, opts)
+	case "ndjson":
+		return &ndjsonItemReader{decoder: json.NewDecoder(r)}, nil
+	case "json":
+		return newJSONItemReader(r)
+	case "xlsx":
+		return newXLSXItemReader(r, opts)
+	default:
+		return nil, fmt.Errorf("format %s does not contain items", format)
+	}
+}
+
+// newItemWriter returns a streaming writer for a structured format
+func newItemWriter(format string, w io.Writer, opts tabularOptions) (itemWriter, error) {
+	switch format {
+	case "csv":
+		return newCSVItemWriter(w, opts)
+	case "ndjson":
+		return &ndjsonItemWriter{encoder: json.NewEncoder(w)}, nil
+	case "json":
+		return &jsonItemWriter{w: bufio.NewWriter(w)}, nil
+	case "xlsx":
+		return newXLSXItemWriter(w, opts)
+	default:
+		return nil, fmt.Errorf("format %s does not contain items", format)
+	}
+}
+
+// csvItemReader reads CSV records as maps keyed by header, or as arrays
+type csvItemReader struct {
+	reader  *csv.Reader
+	columns []string
+}
+
+func newCSVItemReader(r io.Reader, opts tabularOptions) (*csvItemReader, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	if opts.Delimiter != "" {
+		reader.Comma = []rune(opts.Delimiter)[0]
+	}
+
+	itemReader := &csvItemReader{reader: reader}
+	if opts.Header {
+		header, err := reader.Read()
+		if err != nil && err != io.EOF {
+			return nil, fmt.Errorf("failed to read CSV header: %w", err)
+		}
+		itemReader.columns = header
+	}
+
+	return itemReader, nil
+}
+
+func (r *csvItemReader) Next() (interface{}, error) {
+	record, err := r.reader.Read()
+	if err != nil {
+		return nil, err
+	}
+	return rowToItem(r.columns, record), nil
+}
+
+func (r *csvItemReader) Close() error { return nil }
+
+// ndjsonItemReader reads one JSON value per line
+type ndjsonItemReader struct {
+	decoder *json.Decoder
+}
+
+func (r *ndjsonItemReader) Next() (interface{}, error) {
+	var item interface{}
+	if err := r.decoder.Decode(&item); err != nil {
+		return nil, err
+	}
+	return item, nil
+}
+
+func (r *ndjsonItemReader) Close() error { return nil }
+
+// jsonItemReader streams the elements of a top-level JSON array. Any other
+// top-level value is returned as a single item.
+type jsonItemReader struct {
+	decoder *json.Decoder
+	single  interface{}
+	isArray bool
+	done    bool
+}
+
+func newJSONItemReader(r io.Reader) (*jsonItemReader, error) {
+	buffered := bufio.NewReader(r)
+	first, err := peekNonSpace(buffered)
+	if err != nil {
+		return nil, err
+	}
+
+	reader := &jsonItemReader{decoder: json.NewDecoder(buffered)}
+	if first == '[' {
+		if _, err := reader.decoder.Token(); err != nil {
+			return nil, fmt.Errorf("invalid JSON: %w", err)
+		}
+		reader.isArray = true
+	} else {
+		if err := reader.decoder.Decode(&reader.single); err != nil {
+			return nil, fmt.Errorf("invalid JSON: %w", err)
+		}
+	}
+
+	return reader, nil
+}
+
+func (r *jsonItemReader) Next() (interface{}, error) {
+	if !r.isArray {
+		if r.done {
+			return nil, io.EOF
+		}
+		r.done = true
+		return r.single, nil
+	}
+
+	if !r.decoder.More() {
+		return nil, io.EOF
+	}
+
+	var item interface{}
+	if err := r.decoder.Decode(&item); err != nil {
+		return nil, fmt.Errorf("invalid JSON: %w", err)
+	}
+	return item, nil
+}
+
+func (r *jsonItemReader) Close() error { return nil }
+
+// peekNonSpace returns the first non-whitespace byte without consuming it
+func peekNonSpace(r *bufio.Reader) (byte, error) {
+	for {
+		b, err := r.Peek(1)
+		if err != nil {
+			if err == io.EOF {
+				return 0, fmt.Errorf("empty JSON document")
+			}
+			return 0, err
+		}
+		switch b[0] {
+		case ' ', '\t', '\r', '\n':
+			r.ReadByte()
+		default:
+			return b[0], nil
+		}
+	}
+}
+
+// xlsxItemReader iterates the rows of a worksheet
+type xlsxItemReader struct {
+	file    *excelize.File
+	rows    *excelize.Rows
+	columns []string
+}
+
+func newXLSXItemReader(r io.Reader, opts tabularOptions) (*xlsxItemReader, error) {
+	file, err := excelize.OpenReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open XLSX: %w", err)
+	}
+
+	sheet := opts.Sheet
+	if sheet == "" {
+		sheet = file.GetSheetName(0)
+	}
+
+	rows, err := file.Rows(sheet)
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to read sheet %s: %w", sheet, err)
+	}
+
+	reader := &xlsxItemReader{file: file, rows: rows}
+	if opts.Header && rows.Next() {
+		reader.columns, err = rows.Columns()
+		if err != nil {
+			reader.Close()
+			return nil, fmt.Errorf("failed to read XLSX header: %w", err)
+		}
+	}
+
+	return reader, nil
+}
+
+func (r *xlsxItemReader) Next() (interface{}, error) {
+	if !r.rows.Next() {
+		if err := r.rows.Error(); err != nil {
+			return nil, err
+		}
+		return nil, io.EOF
+	}
+
+	record, err := r.rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+	return rowToItem(r.columns, record), nil
+}
+
+func (r *xlsxItemReader) Close() error {
+	r.rows.Close()
+	return r.file.Close()
+}
+
+// rowToItem maps a record to its columns, or returns it as an array when there
+// is no header
+func rowToItem(columns []string, record []string) interface{} {
+	if columns == nil {
+		row := make([]interface{}, len(record))
+		for i, value := range record {
+			row[i] = value
+		}
+		return row
+	}
+
+	item := make(map[string]interface{}, len(columns))
+	for i, column := range columns {
+		if i < len(record) {
+			item[column] = record[i]
+		} else {
+			item[column] = ""
+		}
+	}
+	return item
+}
+
+// itemToRow flattens an item into a record. Columns are taken from the first
+// map item when none are configured.
+func itemToRow(columns *[]string, header *bool, item interface{}) ([]string, []string) {
+	switch v := item.(type) {
+	case map[string]interface{}:
+		if *columns == nil {
+			keys := make([]string, 0, len(v))
+			for key := range v {
+				keys = append(keys, key)
+			}
+			sort.Strings(keys)
+			*columns = keys
+		}
+
+		var headerRow []string
+		if *header {
+			headerRow = *columns
+			*header = false
+		}
+
+		row := make([]string, len(*columns))
+		for i, column := range *columns {
+			row[i] = formatCell(v[column])
+		}
+		return headerRow, row
+
+	case []interface{}:
+		*header = false
+		row := make([]string, len(v))
+		for i, value := range v {
+			row[i] = formatCell(value)
+		}
+		return nil, row
+
+	default:
+		*header = false
+		return nil, []string{formatCell(v)}
+	}
+}
+
+// formatCell renders a value as a CSV/XLSX cell
+func formatCell(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case map[string]interface{}, []interface{}:
+		data, _ := json.Marshal(v)
+		return string(data)
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+}
+
+// csvItemWriter writes items as CSV rows
+type csvItemWriter struct {
+	writer  *csv.Writer
+	columns []string
+	header  bool
+}
+
+func newCSVItemWriter(w io.Writer, opts tabularOptions) (*csvItemWriter, error) {
+	writer := csv.NewWriter(w)
+	if opts.Delimiter != "" {
+		writer.Comma = []rune(opts.Delimiter)[0]
+	}
+	return &csvItemWriter{writer: writer, columns: opts.Columns, header: opts.Header}, nil
+}
+
+func (w *csvItemWriter) Write(item interface{}) error {
+	header, row := itemToRow(&w.columns, &w.header, item)
+	if header != nil {
+		if err := w.writer.Write(header); err != nil {
+			return err
+		}
+	}
+	return w.writer.Write(row)
+}
+
+func (w *csvItemWriter) Close() error {
+	w.writer.Flush()
+	return w.writer.Error()
+}
+
+// ndjsonItemWriter writes one JSON value per line
+type ndjsonItemWriter struct {
+	encoder *json.Encoder
+}
+
+func (w *ndjsonItemWriter) Write(item interface{}) error {
+	return w.encoder.Encode(item)
+}
+
+func (w *ndjsonItemWriter) Close() error { return nil }
+
+// jsonItemWriter writes items as a JSON array
+type jsonItemWriter struct {
+	w     *bufio.Writer
+	count int
+}
+
+func (w *jsonItemWriter) Write(item interface{}) error {
+	data, err := json.Marshal(item)
+	if err != nil {
+		return err
+	}
+
+	separator := ","
+	if w.count == 0 {
+		separator = "["
+	}
+	w.count++
+
+	if _, err := w.w.WriteString(separator); err != nil {
+		return err
+	}
+	_, err = w.w.Write(data)
+	return err
+}
+
+func (w *jsonItemWriter) Close() error {
+	closing := "]"
+	if w.count == 0 {
+		closing = "[]"
+	}
+	if _, err := w.w.WriteString(closing); err != nil {
+		return err
+	}
+	return w.w.Flush()
+}
+
+// xlsxItemWriter writes items to a worksheet using the streaming writer
+type xlsxItemWriter struct {
+	out     io.Writer
+	file    *excelize.File
+	stream  *excelize.StreamWriter
+	columns []string
+	header  bool
+	row     int
+}
+
+func newXLSXItemWriter(w io.Writer, opts tabularOptions) (*xlsxItemWriter, error) {
+	file := excelize.NewFile()
+
+	sheet := opts.Sheet
+	if sheet == "" {
+		sheet = "Sheet1"
+	}
+	if sheet != "Sheet1" {
+		if err := file.SetSheetName("Sheet1", sheet); err != nil {
+			return nil, err
+		}
+	}
+
+	stream, err := file.NewStreamWriter(sheet)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create XLSX writer: %w", err)
+	}
+
+	return &xlsxItemWriter{
+		out:     w,
+		file:    file,
+		stream:  stream,
+		columns: opts.Columns,
+		header:  opts.Header,
+		row:     1,
+	}, nil
+}
+
+func (w *xlsxItemWriter) Write(item interface{}) error {
+	header, row := itemToRow(&w.columns, &w.header, item)
+	if header != nil {
+		if err := w.writeRow(header); err != nil {
+			return err
+		}
+	}
+	return w.writeRow(row)
+}
+
+func (w *xlsxItemWriter) writeRow(values []string) error {
+	cell, err := excelize.CoordinatesToCellName(1, w.row)
+	if err != nil {
+		return err
+	}
+	w.row++
+
+	row := make([]interface{}, len(values))
+	for i, value := range values {
+		row[i] = value
+	}
+	return w.stream.SetRow(cell, row)
+}
+
+func (w *xlsxItemWriter) Close() error {
+	defer w.file.Close()
+
+	if err := w.stream.Flush(); err != nil {
+		return err
+	}
+	_, err := w.file.WriteTo(w.out)
+	return err
+}
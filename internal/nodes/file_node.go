@@ -0,0 +1,469 @@
+package nodes
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/nuumz/f1ow/internal/engine"
+	"github.com/nuumz/f1ow/internal/tenant"
+)
+
+// FileNode reads and writes files under a root directory, converting
+// structured formats to and from item arrays
+type FileNode struct {
+	BaseNode
+	rootDir string
+}
+
+// FileConfig defines configuration for file node
+type FileConfig struct {
+	Operation string      `json:"operation"` // "read", "write", "list", "delete"
+	Path      string      `json:"path"`
+	Format    string      `json:"format"`     // "json", "ndjson", "csv", "xlsx", "text", "binary"; inferred from the extension when empty
+	ItemsPath string      `json:"items_path"` // write: path to the items array in the input
+	Content   interface{} `json:"content"`    // write: text, or base64 / binary payload / binary data handle for binary
+	Append    bool        `json:"append"`     // write: append to an existing csv, ndjson, or text file
+	Delimiter string      `json:"delimiter"`  // csv field delimiter
+	Header    *bool       `json:"header"`     // csv/xlsx: first row holds column names
+	Columns   []string    `json:"columns"`    // csv/xlsx: column order when writing
+	Sheet     string      `json:"sheet"`      // xlsx sheet name
+	Offset    int         `json:"offset"`     // read: items to skip
+	Limit     int         `json:"limit"`      // read: maximum items to return; 0 returns all
+}
+
+// NewFileNode creates a new file node restricted to rootDir
+func NewFileNode(rootDir string) engine.NodeType {
+	return &FileNode{
+		BaseNode: BaseNode{
+			nodeType:    "file",
+			name:        "File",
+			description: "Read and write CSV, JSON, NDJSON, XLSX, text, and binary files",
+			category:    "Data Storage",
+			icon:        "file",
+		},
+		rootDir: rootDir,
+	}
+}
+
+// Execute performs the file operation
+func (n *FileNode) Execute(ctx context.Context, config interface{}, input interface{}) (interface{}, error) {
+	fileConfig, err := n.parseConfig(config)
+	if err != nil {
+		return nil, err
+	}
+
+	relPath := processTemplate(fileConfig.Path, input)
+	path, err := n.resolvePath(ctx, relPath)
+	if err != nil {
+		return nil, err
+	}
+
+	format := fileConfig.Format
+	if format == "" {
+		format = formatFromPath(path)
+	}
+
+	switch fileConfig.Operation {
+	case "read":
+		return n.read(ctx, fileConfig, path, relPath, format)
+	case "write":
+		return n.write(ctx, fileConfig, path, relPath, format, input)
+	case "list":
+		return n.list(path, relPath)
+	case "delete":
+		if err := os.Remove(path); err != nil {
+			return nil, fmt.Errorf("failed to delete %s: %w", relPath, err)
+		}
+		return map[string]interface{}{"path": relPath, "deleted": true}, nil
+	default:
+		return nil, fmt.Errorf("unsupported operation: %s", fileConfig.Operation)
+	}
+}
+
+// ValidateConfig validates the node configuration
+func (n *FileNode) ValidateConfig(config interface{}) error {
+	fileConfig, err := n.parseConfig(config)
+	if err != nil {
+		return err
+	}
+
+	validOperations := map[string]bool{"read": true, "write": true, "list": true, "delete": true}
+	if !validOperations[fileConfig.Operation] {
+		return fmt.Errorf("invalid operation: %s", fileConfig.Operation)
+	}
+
+	if fileConfig.Path == "" && fileConfig.Operation != "list" {
+		return fmt.Errorf("path is required")
+	}
+
+	validFormats := map[string]bool{"": true, "json": true, "ndjson": true, "csv": true, "xlsx": true, "text": true, "binary": true}
+	if !validFormats[fileConfig.Format] {
+		return fmt.Errorf("invalid format: %s", fileConfig.Format)
+	}
+
+	if fileConfig.Append && (fileConfig.Format == "json" || fileConfig.Format == "xlsx") {
+		return fmt.Errorf("append is not supported for %s", fileConfig.Format)
+	}
+
+	if fileConfig.Offset < 0 || fileConfig.Limit < 0 {
+		return fmt.Errorf("offset and limit must not be negative")
+	}
+
+	return nil
+}
+
+// GetSchema returns the node configuration schema
+func (n *FileNode) GetSchema() engine.NodeSchema {
+	return engine.NodeSchema{
+		Type: "object",
+		Properties: map[string]engine.Property{
+			"operation": {
+				Type:        "string",
+				Title:       "Operation",
+				Description: "File operation to perform",
+				Default:     "read",
+				Enum:        []string{"read", "write", "list", "delete"},
+			},
+			"path": {
+				Type:        "string",
+				Title:       "Path",
+				Description: "File path relative to the file storage root. Supports template variables",
+			},
+			"format": {
+				Type:        "string",
+				Title:       "Format",
+				Description: "File format; inferred from the extension when empty",
+				Enum:        []string{"", "json", "ndjson", "csv", "xlsx", "text", "binary"},
+			},
+			"items_path": {
+				Type:        "string",
+				Title:       "Items Path",
+				Description: "Path to the array of items to write",
+				Default:     "items",
+			},
+			"content": {
+				Type:        "string",
+				Title:       "Content",
+				Description: "Content for text files, or a binary data handle, binary payload, or base64 string for binary files",
+			},
+			"append": {
+				Type:        "boolean",
+				Title:       "Append",
+				Description: "Append to an existing csv, ndjson, or text file",
+				Default:     false,
+			},
+			"delimiter": {
+				Type:        "string",
+				Title:       "Delimiter",
+				Description: "CSV field delimiter",
+				Default:     ",",
+			},
+			"header": {
+				Type:        "boolean",
+				Title:       "Header Row",
+				Description: "The first CSV/XLSX row holds column names",
+				Default:     true,
+			},
+			"sheet": {
+				Type:        "string",
+				Title:       "Sheet",
+				Description: "XLSX sheet name; defaults to the first sheet",
+			},
+			"offset": {
+				Type:        "number",
+				Title:       "Offset",
+				Description: "Number of items to skip when reading",
+				Default:     0,
+			},
+			"limit": {
+				Type:        "number",
+				Title:       "Limit",
+				Description: "Maximum items to read; use with offset to page through large files",
+				Default:     0,
+			},
+		},
+		Required: []string{"operation"},
+		Inputs: []engine.PortSchema{
+			{
+				Name:        "input",
+				Type:        "any",
+				Description: "Input data with items to write and template variables",
+				Required:    false,
+			},
+		},
+		Outputs: []engine.PortSchema{
+			{
+				Name:        "output",
+				Type:        "object",
+				Description: "Read items or content, or the result of the operation",
+				Required:    true,
+			},
+		},
+	}
+}
+
+// parseConfig parses the node configuration
+func (n *FileNode) parseConfig(config interface{}) (*FileConfig, error) {
+	configMap, ok := config.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("invalid config type for file node")
+	}
+
+	configJSON, err := json.Marshal(configMap)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal config: %w", err)
+	}
+
+	var fileConfig FileConfig
+	if err := json.Unmarshal(configJSON, &fileConfig); err != nil {
+		return nil, fmt.Errorf("failed to parse file config: %w", err)
+	}
+
+	// Set defaults
+	if fileConfig.Operation == "" {
+		fileConfig.Operation = "read"
+	}
+	if fileConfig.ItemsPath == "" {
+		fileConfig.ItemsPath = "items"
+	}
+	if fileConfig.Header == nil {
+		header := true
+		fileConfig.Header = &header
+	}
+
+	return &fileConfig, nil
+}
+
+// resolvePath maps a relative path into the tenant's directory of the root
+func (n *FileNode) resolvePath(ctx context.Context, relPath string) (string, error) {
+	if n.rootDir == "" {
+		return "", fmt.Errorf("file storage root is not configured")
+	}
+
+	// Cleaning against "/" removes any ".." that would escape the root
+	return filepath.Join(tenantDir(ctx, n.rootDir), filepath.Clean("/"+relPath)), nil
+}
+
+// tenantDir returns the subdirectory of root holding the files of the
+// context's tenant
+func tenantDir(ctx context.Context, root string) string {
+	return filepath.Join(root, tenant.IDOrDefault(ctx).String())
+}
+
+// tabularOptions returns the CSV/XLSX options from the config
+func (n *FileNode) tabularOptions(config *FileConfig) tabularOptions {
+	return tabularOptions{
+		Delimiter: config.Delimiter,
+		Header:    *config.Header,
+		Columns:   config.Columns,
+		Sheet:     config.Sheet,
+	}
+}
+
+// read reads a file as items, text, or a binary payload
+func (n *FileNode) read(ctx context.Context, config *FileConfig, path, relPath, format string) (interface{}, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", relPath, err)
+	}
+	defer file.Close()
+
+	switch format {
+	case "text":
+		content, err := io.ReadAll(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", relPath, err)
+		}
+		return map[string]interface{}{"path": relPath, "content": string(content), "size": len(content)}, nil
+
+	case "binary":
+		info, err := file.Stat()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", relPath, err)
+		}
+
+		mimeType := mime.TypeByExtension(filepath.Ext(path))
+		if mimeType == "" {
+			mimeType = "application/octet-stream"
+		}
+
+		result, err := writeBinary(ctx, filepath.Base(path), mimeType, file, info.Size())
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", relPath, err)
+		}
+		result["path"] = relPath
+		return result, nil
+	}
+
+	reader, err := newItemReader(format, bufio.NewReader(file), n.tabularOptions(config))
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	// Only the requested window is kept in memory
+	items := []interface{}{}
+	index := 0
+	hasMore := false
+	for {
+		if index%1000 == 0 && ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+
+		item, err := reader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s at item %d: %w", relPath, index, err)
+		}
+
+		if index >= config.Offset {
+			if config.Limit > 0 && len(items) >= config.Limit {
+				hasMore = true
+				break
+			}
+			items = append(items, item)
+		}
+		index++
+	}
+
+	return map[string]interface{}{
+		"path":        relPath,
+		"items":       items,
+		"count":       len(items),
+		"offset":      config.Offset,
+		"next_offset": config.Offset + len(items),
+		"has_more":    hasMore,
+	}, nil
+}
+
+// write writes items, text, or binary content to a file
+func (n *FileNode) write(ctx context.Context, config *FileConfig, path, relPath, format string, input interface{}) (interface{}, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	flags := os.O_CREATE | os.O_WRONLY | os.O_TRUNC
+	existingSize := int64(0)
+	if config.Append {
+		flags = os.O_CREATE | os.O_WRONLY | os.O_APPEND
+		if info, err := os.Stat(path); err == nil {
+			existingSize = info.Size()
+		}
+	}
+
+	file, err := os.OpenFile(path, flags, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", relPath, err)
+	}
+	defer file.Close()
+
+	result := map[string]interface{}{"path": relPath}
+
+	switch format {
+	case "text":
+		content := resolveContent(config.Content, input)
+		text, ok := content.(string)
+		if !ok {
+			text = fmt.Sprintf("%v", content)
+		}
+		if _, err := file.WriteString(text); err != nil {
+			return nil, fmt.Errorf("failed to write %s: %w", relPath, err)
+		}
+
+	case "binary":
+		reader, err := openBinary(ctx, resolveContent(config.Content, input))
+		if err != nil {
+			return nil, err
+		}
+		_, err = io.Copy(file, reader)
+		reader.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to write %s: %w", relPath, err)
+		}
+
+	default:
+		inputData, _ := input.(map[string]interface{})
+		items, ok := getValueByPath(inputData, config.ItemsPath).([]interface{})
+		if !ok {
+			return nil, fmt.Errorf("value at path '%s' is not an array", config.ItemsPath)
+		}
+
+		opts := n.tabularOptions(config)
+		if existingSize > 0 {
+			// The header row already exists when appending to a non-empty file
+			opts.Header = false
+		}
+
+		buffered := bufio.NewWriter(file)
+		writer, err := newItemWriter(format, buffered, opts)
+		if err != nil {
+			return nil, err
+		}
+		for i, item := range items {
+			if i%1000 == 0 && ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			if err := writer.Write(item); err != nil {
+				return nil, fmt.Errorf("failed to write item %d: %w", i, err)
+			}
+		}
+		if err := writer.Close(); err != nil {
+			return nil, fmt.Errorf("failed to write %s: %w", relPath, err)
+		}
+		if err := buffered.Flush(); err != nil {
+			return nil, fmt.Errorf("failed to write %s: %w", relPath, err)
+		}
+		result["count"] = len(items)
+	}
+
+	if err := file.Sync(); err != nil {
+		return nil, fmt.Errorf("failed to write %s: %w", relPath, err)
+	}
+	if info, err := file.Stat(); err == nil {
+		result["size"] = info.Size()
+	}
+
+	return result, nil
+}
+
+// list returns the entries of a directory
+func (n *FileNode) list(path, relPath string) (interface{}, error) {
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list %s: %w", relPath, err)
+	}
+
+	files := make([]interface{}, 0, len(entries))
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		files = append(files, map[string]interface{}{
+			"name":        entry.Name(),
+			"path":        filepath.ToSlash(filepath.Join(relPath, entry.Name())),
+			"size":        info.Size(),
+			"is_dir":      entry.IsDir(),
+			"modified_at": info.ModTime().Format(time.RFC3339),
+		})
+	}
+
+	return map[string]interface{}{"path": relPath, "files": files, "count": len(files)}, nil
+}
+
+// resolveContent resolves templates in the configured content
+func resolveContent(content interface{}, input interface{}) interface{} {
+	if s, ok := content.(string); ok {
+		return resolveTemplateValue(s, input)
+	}
+	return interpolateValue(content, input)
+}
@@ -0,0 +1,248 @@
+package nodes
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+	"github.com/pkg/sftp"
+	"golang.org/x/crypto/ssh"
+)
+
+// WatchedFile describes a file listed by a file source
+type WatchedFile struct {
+	Path    string    // relative to the local root, remote path, or object key
+	Size    int64     // bytes
+	ModTime time.Time // last modification
+}
+
+// FileSource lists and reads files in a local directory, over SFTP, or in
+// an S3 bucket
+type FileSource interface {
+	// List returns the files under dir, descending into subdirectories when
+	// recursive is set
+	List(ctx context.Context, dir string, recursive bool) ([]WatchedFile, error)
+
+	// Open returns a reader for a file returned by List
+	Open(ctx context.Context, path string) (io.ReadCloser, error)
+
+	// Close releases the connection, if any
+	Close() error
+}
+
+// FileSourceS3Config defines the bucket read by an s3 file source
+type FileSourceS3Config struct {
+	Endpoint  string `json:"endpoint"`
+	Bucket    string `json:"bucket"`
+	Region    string `json:"region"`
+	AccessKey string `json:"access_key"`
+	SecretKey string `json:"secret_key"`
+	UseSSL    bool   `json:"use_ssl"`
+}
+
+// OpenFileSource connects to the configured source. Local paths are
+// resolved inside the tenant's directory of rootDir.
+func OpenFileSource(ctx context.Context, source string, rootDir string, sftpConfig *SSHConfig, s3Config *FileSourceS3Config) (FileSource, error) {
+	switch source {
+	case "local":
+		if rootDir == "" {
+			return nil, fmt.Errorf("file storage root is not configured")
+		}
+		return &localFileSource{root: tenantDir(ctx, rootDir)}, nil
+
+	case "sftp":
+		if sftpConfig == nil {
+			return nil, fmt.Errorf("sftp settings are required")
+		}
+		clientConfig, err := (&SSHNode{}).clientConfig(sftpConfig, nil)
+		if err != nil {
+			return nil, err
+		}
+		// Unlike dialSSH, no deadline is left on the connection, which stays
+		// open for listing and reading
+		address := net.JoinHostPort(sftpConfig.Host, strconv.Itoa(sftpConfig.Port))
+		dialCtx := ctx
+		if clientConfig.Timeout > 0 {
+			var cancel context.CancelFunc
+			dialCtx, cancel = context.WithTimeout(ctx, clientConfig.Timeout)
+			defer cancel()
+		}
+		conn, err := Dialer(ctx)(dialCtx, "tcp", address)
+		if err != nil {
+			return nil, fmt.Errorf("failed to connect to %s: %w", address, err)
+		}
+		sshConn, chans, reqs, err := ssh.NewClientConn(conn, address, clientConfig)
+		if err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("ssh handshake with %s failed: %w", address, err)
+		}
+		sshClient := ssh.NewClient(sshConn, chans, reqs)
+		client, err := sftp.NewClient(sshClient)
+		if err != nil {
+			sshClient.Close()
+			return nil, fmt.Errorf("failed to start sftp session: %w", err)
+		}
+		return &sftpFileSource{client: client, closeSSH: sshClient.Close}, nil
+
+	case "s3":
+		if s3Config == nil || s3Config.Endpoint == "" || s3Config.Bucket == "" {
+			return nil, fmt.Errorf("s3 endpoint and bucket are required")
+		}
+		transport, err := minio.DefaultTransport(s3Config.UseSSL)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create s3 client: %w", err)
+		}
+		client, err := minio.New(s3Config.Endpoint, &minio.Options{
+			Creds:     credentials.NewStaticV4(s3Config.AccessKey, s3Config.SecretKey, ""),
+			Secure:    s3Config.UseSSL,
+			Region:    s3Config.Region,
+			Transport: guardTransport(transport, Dialer(ctx)),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to create s3 client: %w", err)
+		}
+		return &s3FileSource{client: client, bucket: s3Config.Bucket}, nil
+
+	default:
+		return nil, fmt.Errorf("unsupported file source: %s", source)
+	}
+}
+
+// localFileSource reads files inside a root directory
+type localFileSource struct {
+	root string
+}
+
+func (s *localFileSource) resolve(relPath string) string {
+	// Cleaning against "/" removes any ".." that would escape the root
+	return filepath.Join(s.root, filepath.Clean("/"+relPath))
+}
+
+func (s *localFileSource) List(ctx context.Context, dir string, recursive bool) ([]WatchedFile, error) {
+	base := s.resolve(dir)
+	var files []WatchedFile
+
+	err := filepath.WalkDir(base, func(filePath string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			if filePath != base && !recursive {
+				return filepath.SkipDir
+			}
+			return ctx.Err()
+		}
+		if !entry.Type().IsRegular() {
+			return nil
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			// Removed between listing and stat
+			return nil
+		}
+		relPath, err := filepath.Rel(s.root, filePath)
+		if err != nil {
+			return err
+		}
+		files = append(files, WatchedFile{Path: filepath.ToSlash(relPath), Size: info.Size(), ModTime: info.ModTime()})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list %s: %w", dir, err)
+	}
+	return files, nil
+}
+
+func (s *localFileSource) Open(ctx context.Context, relPath string) (io.ReadCloser, error) {
+	return os.Open(s.resolve(relPath))
+}
+
+func (s *localFileSource) Close() error {
+	return nil
+}
+
+// sftpFileSource reads files over an SFTP session
+type sftpFileSource struct {
+	client   *sftp.Client
+	closeSSH func() error
+}
+
+func (s *sftpFileSource) List(ctx context.Context, dir string, recursive bool) ([]WatchedFile, error) {
+	var files []WatchedFile
+
+	walker := s.client.Walk(dir)
+	for walker.Step() {
+		if err := walker.Err(); err != nil {
+			return nil, fmt.Errorf("failed to list %s: %w", walker.Path(), err)
+		}
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		info := walker.Stat()
+		if info.IsDir() {
+			if walker.Path() != dir && !recursive {
+				walker.SkipDir()
+			}
+			continue
+		}
+		if !info.Mode().IsRegular() {
+			continue
+		}
+		files = append(files, WatchedFile{Path: walker.Path(), Size: info.Size(), ModTime: info.ModTime()})
+	}
+	return files, nil
+}
+
+func (s *sftpFileSource) Open(ctx context.Context, filePath string) (io.ReadCloser, error) {
+	return s.client.Open(filePath)
+}
+
+func (s *sftpFileSource) Close() error {
+	err := s.client.Close()
+	if closeErr := s.closeSSH(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// s3FileSource reads objects under a key prefix
+type s3FileSource struct {
+	client *minio.Client
+	bucket string
+}
+
+func (s *s3FileSource) List(ctx context.Context, prefix string, recursive bool) ([]WatchedFile, error) {
+	var files []WatchedFile
+
+	options := minio.ListObjectsOptions{Prefix: prefix, Recursive: recursive}
+	for object := range s.client.ListObjects(ctx, s.bucket, options) {
+		if object.Err != nil {
+			return nil, fmt.Errorf("failed to list %s: %w", prefix, object.Err)
+		}
+		// Folder placeholders and common prefixes are not files
+		if strings.HasSuffix(object.Key, "/") {
+			continue
+		}
+		files = append(files, WatchedFile{Path: object.Key, Size: object.Size, ModTime: object.LastModified})
+	}
+	return files, nil
+}
+
+func (s *s3FileSource) Open(ctx context.Context, key string) (io.ReadCloser, error) {
+	return s.client.GetObject(ctx, s.bucket, key, minio.GetObjectOptions{})
+}
+
+func (s *s3FileSource) Close() error {
+	return nil
+}
@@ -0,0 +1,337 @@
+package nodes
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"path"
+	"strings"
+
+	"github.com/nuumz/f1ow/internal/engine"
+)
+
+// FileWatchTriggerNode starts an execution per new or modified file in a
+// watched directory and loads the file content as binary data
+type FileWatchTriggerNode struct {
+	BaseNode
+	rootDir string
+}
+
+// FileWatchTriggerConfig defines configuration for file watch trigger node
+type FileWatchTriggerConfig struct {
+	Source         string              `json:"source"`    // "local", "sftp", "s3"
+	Path           string              `json:"path"`      // directory, relative to the file storage root for local, or key prefix for s3
+	Pattern        string              `json:"pattern"`   // glob, e.g. "*.csv"
+	Recursive      bool                `json:"recursive"` // include subdirectories
+	Events         []string            `json:"events"`    // "created", "modified"
+	SFTP           *SSHConfig          `json:"sftp"`
+	S3             *FileSourceS3Config `json:"s3"`
+	MinAge         int                 `json:"min_age"`       // seconds since the last modification before a file is picked up
+	PollInterval   int                 `json:"poll_interval"` // seconds
+	MaxFiles       int                 `json:"max_files"`     // executions started per poll
+	EmitExisting   bool                `json:"emit_existing"` // start executions for the files found by the first poll
+	IncludeContent bool                `json:"include_content"`
+	MaxFileSize    int64               `json:"max_file_size"` // bytes, 0 = unlimited
+}
+
+// NewFileWatchTriggerNode creates a new file watch trigger node. Local
+// directories are resolved inside rootDir.
+func NewFileWatchTriggerNode(rootDir string) engine.NodeType {
+	return &FileWatchTriggerNode{
+		BaseNode: BaseNode{
+			nodeType:    "file_watch_trigger",
+			name:        "File Watch",
+			description: "Start the workflow for each new or modified file in a local, SFTP, or S3 directory",
+			category:    "Triggers",
+			icon:        "folder-search",
+		},
+		rootDir: rootDir,
+	}
+}
+
+// Execute loads the content of the file described by the trigger payload
+// into binary data, placing the handle under "file"
+func (n *FileWatchTriggerNode) Execute(ctx context.Context, config interface{}, input interface{}) (interface{}, error) {
+	watchConfig, err := ParseFileWatchTriggerConfig(config)
+	if err != nil {
+		return nil, err
+	}
+
+	event, ok := input.(map[string]interface{})
+	if !ok {
+		return map[string]interface{}{"data": input}, nil
+	}
+
+	result := make(map[string]interface{}, len(event)+1)
+	for key, value := range event {
+		result[key] = value
+	}
+
+	filePath, _ := event["path"].(string)
+	if !watchConfig.IncludeContent || filePath == "" {
+		return result, nil
+	}
+
+	if size, ok := toFloat64(event["size"]); ok && watchConfig.MaxFileSize > 0 && int64(size) > watchConfig.MaxFileSize {
+		return nil, fmt.Errorf("file %s exceeds max_file_size of %d bytes", filePath, watchConfig.MaxFileSize)
+	}
+
+	source, err := OpenFileSource(ctx, watchConfig.Source, n.rootDir, watchConfig.SFTP, watchConfig.S3)
+	if err != nil {
+		return nil, err
+	}
+	defer source.Close()
+
+	reader, err := source.Open(ctx, filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", filePath, err)
+	}
+	defer reader.Close()
+
+	var content io.Reader = reader
+	if watchConfig.MaxFileSize > 0 {
+		content = &limitedReader{reader: reader, remaining: watchConfig.MaxFileSize, path: filePath}
+	}
+
+	name := path.Base(filePath)
+	mimeType := mime.TypeByExtension(path.Ext(name))
+	if mimeType == "" {
+		mimeType = "application/octet-stream"
+	}
+
+	file, err := writeBinary(ctx, name, mimeType, content, -1)
+	if err != nil {
+		return nil, err
+	}
+	result["file"] = file
+
+	return result, nil
+}
+
+// ValidateConfig validates the node configuration
+func (n *FileWatchTriggerNode) ValidateConfig(config interface{}) error {
+	watchConfig, err := ParseFileWatchTriggerConfig(config)
+	if err != nil {
+		return err
+	}
+
+	switch watchConfig.Source {
+	case "local":
+	case "sftp":
+		if watchConfig.SFTP == nil || watchConfig.SFTP.Host == "" || watchConfig.SFTP.Username == "" {
+			return fmt.Errorf("sftp host and username are required")
+		}
+		if watchConfig.SFTP.PrivateKey == "" {
+			return fmt.Errorf("sftp private_key is required")
+		}
+		if watchConfig.SFTP.HostKey == "" && !watchConfig.SFTP.InsecureIgnoreHostKey {
+			return fmt.Errorf("sftp host_key is required unless insecure_ignore_host_key is set")
+		}
+		if watchConfig.Path == "" {
+			return fmt.Errorf("path is required for sftp")
+		}
+	case "s3":
+		if watchConfig.S3 == nil || watchConfig.S3.Endpoint == "" || watchConfig.S3.Bucket == "" {
+			return fmt.Errorf("s3 endpoint and bucket are required")
+		}
+	default:
+		return fmt.Errorf("invalid source: %s", watchConfig.Source)
+	}
+
+	if _, err := path.Match(watchConfig.Pattern, ""); err != nil {
+		return fmt.Errorf("invalid pattern: %w", err)
+	}
+
+	for _, event := range watchConfig.Events {
+		if event != "created" && event != "modified" {
+			return fmt.Errorf("invalid event: %s", event)
+		}
+	}
+
+	return nil
+}
+
+// GetSchema returns the node configuration schema
+func (n *FileWatchTriggerNode) GetSchema() engine.NodeSchema {
+	return engine.NodeSchema{
+		Type: "object",
+		Properties: map[string]engine.Property{
+			"source": {
+				Type:        "string",
+				Title:       "Source",
+				Description: "Where the watched directory is",
+				Default:     "local",
+				Enum:        []string{"local", "sftp", "s3"},
+			},
+			"path": {
+				Type:        "string",
+				Title:       "Path",
+				Description: "Directory to watch: relative to the file storage root for local, a remote path for SFTP, or a key prefix for S3",
+			},
+			"pattern": {
+				Type:        "string",
+				Title:       "Pattern",
+				Description: "Glob matched against file names, or against the path below the directory when it contains a slash",
+				Default:     "*",
+			},
+			"recursive": {
+				Type:        "boolean",
+				Title:       "Recursive",
+				Description: "Watch subdirectories",
+				Default:     false,
+			},
+			"events": {
+				Type:        "array",
+				Title:       "Events",
+				Description: "File changes that start the workflow: created, modified",
+				Default:     []string{"created", "modified"},
+			},
+			"sftp": {
+				Type:        "object",
+				Title:       "SFTP",
+				Description: "host, port, username, private_key, passphrase, host_key, insecure_ignore_host_key",
+			},
+			"s3": {
+				Type:        "object",
+				Title:       "S3",
+				Description: "endpoint, bucket, region, access_key, secret_key, use_ssl",
+			},
+			"min_age": {
+				Type:        "number",
+				Title:       "Minimum Age",
+				Description: "Seconds a file must stay unmodified before it is picked up, so files still being written are skipped",
+				Default:     0,
+			},
+			"poll_interval": {
+				Type:        "number",
+				Title:       "Poll Interval",
+				Description: "Seconds between directory listings",
+				Default:     30,
+			},
+			"max_files": {
+				Type:        "number",
+				Title:       "Max Files",
+				Description: "Maximum number of executions started per poll; the rest follow on later polls",
+				Default:     100,
+			},
+			"emit_existing": {
+				Type:        "boolean",
+				Title:       "Emit Existing Files",
+				Description: "Start executions for the files found by the first poll instead of only recording them",
+				Default:     false,
+			},
+			"include_content": {
+				Type:        "boolean",
+				Title:       "Include Content",
+				Description: "Load the file content as binary data",
+				Default:     true,
+			},
+			"max_file_size": {
+				Type:        "number",
+				Title:       "Max File Size",
+				Description: "Largest file in bytes whose content is loaded, 0 for no limit",
+				Default:     0,
+			},
+		},
+		Required: []string{"source"},
+		Inputs:   []engine.PortSchema{},
+		Outputs: []engine.PortSchema{
+			{
+				Name:        "output",
+				Type:        "object",
+				Description: "event, path, name, size, modified_at, and the file content under file",
+				Required:    true,
+			},
+		},
+	}
+}
+
+// Matches reports whether a listed file matches the pattern. Patterns with a
+// slash match the path below the watched directory; others match the name.
+func (c *FileWatchTriggerConfig) Matches(filePath string) bool {
+	if c.Pattern == "" {
+		return true
+	}
+	name := path.Base(filePath)
+	if strings.Contains(c.Pattern, "/") {
+		dir := strings.Trim(c.Path, "/")
+		name = strings.TrimPrefix(strings.TrimPrefix(strings.TrimPrefix(filePath, "/"), dir), "/")
+	}
+	matched, err := path.Match(c.Pattern, name)
+	return err == nil && matched
+}
+
+// WantsEvent reports whether the given change starts the workflow
+func (c *FileWatchTriggerConfig) WantsEvent(event string) bool {
+	for _, want := range c.Events {
+		if want == event {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseFileWatchTriggerConfig parses a file watch trigger configuration and
+// applies defaults
+func ParseFileWatchTriggerConfig(config interface{}) (*FileWatchTriggerConfig, error) {
+	configMap, ok := config.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("invalid config type for file watch trigger node")
+	}
+
+	configJSON, err := json.Marshal(configMap)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal config: %w", err)
+	}
+
+	watchConfig := FileWatchTriggerConfig{IncludeContent: true}
+	if err := json.Unmarshal(configJSON, &watchConfig); err != nil {
+		return nil, fmt.Errorf("failed to parse file watch trigger config: %w", err)
+	}
+
+	// Set defaults
+	if watchConfig.Source == "" {
+		watchConfig.Source = "local"
+	}
+	if watchConfig.Pattern == "" {
+		watchConfig.Pattern = "*"
+	}
+	if len(watchConfig.Events) == 0 {
+		watchConfig.Events = []string{"created", "modified"}
+	}
+	if watchConfig.PollInterval <= 0 {
+		watchConfig.PollInterval = 30
+	}
+	if watchConfig.MaxFiles <= 0 {
+		watchConfig.MaxFiles = 100
+	}
+	if watchConfig.SFTP != nil {
+		if watchConfig.SFTP.Port == 0 {
+			watchConfig.SFTP.Port = 22
+		}
+		if watchConfig.SFTP.Timeout == 0 {
+			watchConfig.SFTP.Timeout = 60
+		}
+	}
+
+	return &watchConfig, nil
+}
+
+// limitedReader fails once more than remaining bytes are read, so files
+// that grew past max_file_size after listing are rejected instead of cut off
+type limitedReader struct {
+	reader    io.Reader
+	remaining int64
+	path      string
+}
+
+func (r *limitedReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	r.remaining -= int64(n)
+	if r.remaining < 0 {
+		return n, fmt.Errorf("file %s exceeds max_file_size", r.path)
+	}
+	return n, err
+}
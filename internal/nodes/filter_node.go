@@ -0,0 +1,270 @@
+package nodes
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/nuumz/f1ow/internal/engine"
+)
+
+// FilterNode implements code-free filtering of item arrays
+type FilterNode struct {
+	BaseNode
+}
+
+// FilterConfig defines configuration for filter node
+type FilterConfig struct {
+	ArrayPath  string           `json:"array_path"`
+	Combinator string           `json:"combinator"` // How groups are combined: "and", "or"
+	Groups     []ConditionGroup `json:"groups"`
+}
+
+// ConditionGroup is a set of conditions combined with AND or OR
+type ConditionGroup struct {
+	Combinator string      `json:"combinator"` // "and", "or"
+	Conditions []Condition `json:"conditions"`
+}
+
+// NewFilterNode creates a new filter node
+func NewFilterNode() engine.NodeType {
+	return &FilterNode{
+		BaseNode: BaseNode{
+			nodeType:    "filter",
+			name:        "Filter",
+			description: "Filter array items by field conditions without writing code",
+			category:    "Data Processing",
+			icon:        "filter",
+		},
+	}
+}
+
+// Run splits the array like Execute and emits on matched and unmatched
+// when they have items
+func (n *FilterNode) Run(ctx context.Context, config engine.NodeConfig, input engine.NodeInput) (engine.NodeOutput, error) {
+	result, err := n.Execute(ctx, map[string]interface{}(config), input.Data)
+	if err != nil {
+		return engine.NodeOutput{}, err
+	}
+	output := result.(map[string]interface{})
+
+	ports := []string{}
+	for _, port := range []string{"matched", "unmatched"} {
+		if len(output[port].([]interface{})) > 0 {
+			ports = append(ports, port)
+		}
+	}
+	node := engine.NewNodeOutput(output)
+	node.Ports = ports
+	return node, nil
+}
+
+// Execute splits the array into matched and unmatched items
+func (n *FilterNode) Execute(ctx context.Context, config interface{}, input interface{}) (interface{}, error) {
+	filterConfig, err := n.parseConfig(config)
+	if err != nil {
+		return nil, err
+	}
+
+	inputData := make(map[string]interface{})
+	if inputMap, ok := input.(map[string]interface{}); ok {
+		inputData = inputMap
+	} else {
+		inputData["data"] = input
+	}
+
+	arrayValue := getValueByPath(inputData, filterConfig.ArrayPath)
+	array, ok := arrayValue.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("value at path '%s' is not an array", filterConfig.ArrayPath)
+	}
+
+	matched := make([]interface{}, 0)
+	unmatched := make([]interface{}, 0)
+
+	for _, item := range array {
+		if n.matchItem(filterConfig, item) {
+			matched = append(matched, item)
+		} else {
+			unmatched = append(unmatched, item)
+		}
+	}
+
+	return map[string]interface{}{
+		"matched":         matched,
+		"unmatched":       unmatched,
+		"matched_count":   len(matched),
+		"unmatched_count": len(unmatched),
+	}, nil
+}
+
+// ValidateConfig validates the node configuration
+func (n *FilterNode) ValidateConfig(config interface{}) error {
+	filterConfig, err := n.parseConfig(config)
+	if err != nil {
+		return err
+	}
+
+	if filterConfig.ArrayPath == "" {
+		return fmt.Errorf("array_path is required")
+	}
+
+	if !isValidCombinator(filterConfig.Combinator) {
+		return fmt.Errorf("invalid combinator: %s", filterConfig.Combinator)
+	}
+
+	if len(filterConfig.Groups) == 0 {
+		return fmt.Errorf("at least one condition group is required")
+	}
+
+	for i, group := range filterConfig.Groups {
+		if !isValidCombinator(group.Combinator) {
+			return fmt.Errorf("group %d: invalid combinator: %s", i, group.Combinator)
+		}
+		if len(group.Conditions) == 0 {
+			return fmt.Errorf("group %d: at least one condition is required", i)
+		}
+		for j, condition := range group.Conditions {
+			if condition.Field == "" {
+				return fmt.Errorf("group %d condition %d: field is required", i, j)
+			}
+			if !supportedOperators[strings.ToLower(condition.Operator)] {
+				return fmt.Errorf("group %d condition %d: unsupported operator: %s", i, j, condition.Operator)
+			}
+		}
+	}
+
+	return nil
+}
+
+// GetSchema returns the node configuration schema
+func (n *FilterNode) GetSchema() engine.NodeSchema {
+	return engine.NodeSchema{
+		Type: "object",
+		Properties: map[string]engine.Property{
+			"array_path": {
+				Type:        "string",
+				Title:       "Array Path",
+				Description: "Path to the array of items to filter (e.g., 'data.items')",
+			},
+			"combinator": {
+				Type:        "string",
+				Title:       "Group Combinator",
+				Description: "How condition groups are combined",
+				Default:     "and",
+				Enum:        []string{"and", "or"},
+			},
+			"groups": {
+				Type:        "array",
+				Title:       "Condition Groups",
+				Description: "Groups of field/operator/value conditions, each with its own combinator",
+			},
+		},
+		Required: []string{"array_path", "groups"},
+		Inputs: []engine.PortSchema{
+			{
+				Name:        "input",
+				Type:        "object",
+				Description: "Input data containing the array to filter",
+				Required:    true,
+			},
+		},
+		Outputs: []engine.PortSchema{
+			{
+				Name:        "matched",
+				Type:        "array",
+				Description: "Items matching the conditions",
+				Required:    true,
+			},
+			{
+				Name:        "unmatched",
+				Type:        "array",
+				Description: "Items not matching the conditions",
+				Required:    false,
+			},
+		},
+	}
+}
+
+// parseConfig parses the node configuration
+func (n *FilterNode) parseConfig(config interface{}) (*FilterConfig, error) {
+	configMap, ok := config.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("invalid config type for filter node")
+	}
+
+	configJSON, err := json.Marshal(configMap)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal config: %w", err)
+	}
+
+	var filterConfig FilterConfig
+	if err := json.Unmarshal(configJSON, &filterConfig); err != nil {
+		return nil, fmt.Errorf("failed to parse filter config: %w", err)
+	}
+
+	// Set defaults
+	if filterConfig.Combinator == "" {
+		filterConfig.Combinator = "and"
+	}
+	for i := range filterConfig.Groups {
+		if filterConfig.Groups[i].Combinator == "" {
+			filterConfig.Groups[i].Combinator = "and"
+		}
+	}
+
+	return &filterConfig, nil
+}
+
+// matchItem evaluates all condition groups against a single item
+func (n *FilterNode) matchItem(config *FilterConfig, item interface{}) bool {
+	itemData, ok := item.(map[string]interface{})
+	if !ok {
+		itemData = map[string]interface{}{"data": item}
+	}
+
+	conditionalNode := &ConditionalNode{}
+	isOr := strings.ToLower(config.Combinator) == "or"
+
+	for _, group := range config.Groups {
+		groupMatched := n.matchGroup(conditionalNode, group, itemData)
+		if isOr && groupMatched {
+			return true
+		}
+		if !isOr && !groupMatched {
+			return false
+		}
+	}
+
+	return !isOr
+}
+
+// matchGroup evaluates the conditions of a group; evaluation errors count as no match
+func (n *FilterNode) matchGroup(evaluator *ConditionalNode, group ConditionGroup, itemData map[string]interface{}) bool {
+	isOr := strings.ToLower(group.Combinator) == "or"
+
+	for _, condition := range group.Conditions {
+		matched, err := evaluator.evaluateCondition(condition, itemData)
+		if err != nil {
+			matched = false
+		}
+		if isOr && matched {
+			return true
+		}
+		if !isOr && !matched {
+			return false
+		}
+	}
+
+	return !isOr
+}
+
+func isValidCombinator(combinator string) bool {
+	switch strings.ToLower(combinator) {
+	case "and", "or":
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,741 @@
+package nodes
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nuumz/f1ow/internal/engine"
+	"github.com/nuumz/f1ow/internal/netpolicy"
+
+	"github.com/go-git/go-git/v5"
+	gitconfig "github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	gitclient "github.com/go-git/go-git/v5/plumbing/transport/client"
+	githttp "github.com/go-git/go-git/v5/plumbing/transport/http"
+	gitssh "github.com/go-git/go-git/v5/plumbing/transport/ssh"
+	"github.com/google/uuid"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/net/proxy"
+)
+
+// GitNode clones, commits, and pushes git repositories inside a workspace directory
+type GitNode struct {
+	BaseNode
+	rootDir string
+}
+
+// GitConfig defines configuration for git node
+type GitConfig struct {
+	Operation string `json:"operation"` // "clone", "pull", "commit", "push", "branch", "tag"
+	Path      string `json:"path"`      // repository directory, relative to the workspace root
+
+	// clone
+	URL   string `json:"url"`
+	Depth int    `json:"depth"`
+
+	// clone, pull, branch
+	Branch string `json:"branch"`
+
+	// Authentication
+	AuthType              string `json:"auth_type"` // "none", "token", "ssh"
+	Username              string `json:"username"`
+	Token                 string `json:"token"`
+	PrivateKey            string `json:"private_key"`
+	Passphrase            string `json:"passphrase"`
+	HostKey               string `json:"host_key"` // authorized_keys format
+	InsecureIgnoreHostKey bool   `json:"insecure_ignore_host_key"`
+
+	// commit
+	Message     string   `json:"message"`
+	Files       []string `json:"files"` // paths to stage; all changes when empty
+	AuthorName  string   `json:"author_name"`
+	AuthorEmail string   `json:"author_email"`
+	AllowEmpty  bool     `json:"allow_empty"`
+
+	// push
+	Remote string `json:"remote"`
+	Force  bool   `json:"force"`
+	Tags   bool   `json:"tags"` // also push tags
+
+	// tag
+	Tag string `json:"tag"`
+
+	Timeout int `json:"timeout"` // seconds
+}
+
+var validGitOperations = map[string]bool{
+	"clone": true, "pull": true, "commit": true, "push": true, "branch": true, "tag": true,
+}
+
+// NewGitNode creates a new git node. Repositories live under rootDir, which
+// is shared with the file node so workflows can edit files before committing.
+func NewGitNode(rootDir string) engine.NodeType {
+	installGitTransports()
+	return &GitNode{
+		BaseNode: BaseNode{
+			nodeType:    "git",
+			name:        "Git",
+			description: "Clone, pull, commit, push, branch, and tag git repositories",
+			category:    "Developer Tools",
+			icon:        "git-branch",
+		},
+		rootDir: rootDir,
+	}
+}
+
+// Execute runs the git operation
+func (n *GitNode) Execute(ctx context.Context, config interface{}, input interface{}) (interface{}, error) {
+	gitConfig, err := n.parseConfig(config)
+	if err != nil {
+		return nil, err
+	}
+
+	if n.rootDir == "" {
+		return nil, fmt.Errorf("git workspace root is not configured")
+	}
+	root := tenantDir(ctx, n.rootDir)
+	relPath := processTemplate(gitConfig.Path, input)
+	dir := filepath.Join(root, filepath.Clean("/"+relPath))
+
+	auth, err := n.auth(gitConfig, input)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(gitConfig.Timeout)*time.Second)
+	defer cancel()
+
+	if gitConfig.Operation == "clone" {
+		return n.clone(ctx, gitConfig, input, root, dir, relPath, auth)
+	}
+
+	repo, err := git.PlainOpen(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open repository %s: %w", relPath, err)
+	}
+
+	switch gitConfig.Operation {
+	case "pull":
+		return n.pull(ctx, gitConfig, input, repo, relPath, auth)
+	case "commit":
+		return n.commit(gitConfig, input, repo, relPath)
+	case "push":
+		return n.push(ctx, gitConfig, repo, relPath, auth)
+	case "branch":
+		return n.branch(gitConfig, input, repo, relPath)
+	case "tag":
+		return n.tag(gitConfig, input, repo, relPath)
+	default:
+		return nil, fmt.Errorf("unsupported operation: %s", gitConfig.Operation)
+	}
+}
+
+// ValidateConfig validates the node configuration
+func (n *GitNode) ValidateConfig(config interface{}) error {
+	gitConfig, err := n.parseConfig(config)
+	if err != nil {
+		return err
+	}
+
+	if !validGitOperations[gitConfig.Operation] {
+		return fmt.Errorf("invalid operation: %s", gitConfig.Operation)
+	}
+	if gitConfig.Path == "" {
+		return fmt.Errorf("path is required")
+	}
+
+	switch gitConfig.Operation {
+	case "clone":
+		if gitConfig.URL == "" {
+			return fmt.Errorf("url is required for clone")
+		}
+	case "commit":
+		if gitConfig.Message == "" {
+			return fmt.Errorf("message is required for commit")
+		}
+	case "branch":
+		if gitConfig.Branch == "" {
+			return fmt.Errorf("branch is required for branch")
+		}
+	case "tag":
+		if gitConfig.Tag == "" {
+			return fmt.Errorf("tag is required for tag")
+		}
+	}
+
+	switch gitConfig.AuthType {
+	case "none":
+	case "token":
+		if gitConfig.Token == "" {
+			return fmt.Errorf("token is required for token authentication")
+		}
+	case "ssh":
+		if gitConfig.PrivateKey == "" {
+			return fmt.Errorf("private_key is required for ssh authentication")
+		}
+	default:
+		return fmt.Errorf("invalid auth_type: %s", gitConfig.AuthType)
+	}
+
+	return nil
+}
+
+// GetSchema returns the node configuration schema
+func (n *GitNode) GetSchema() engine.NodeSchema {
+	return engine.NodeSchema{
+		Type: "object",
+		Properties: map[string]engine.Property{
+			"operation": {
+				Type:        "string",
+				Title:       "Operation",
+				Description: "Git operation to perform",
+				Enum:        []string{"clone", "pull", "commit", "push", "branch", "tag"},
+			},
+			"path": {
+				Type:        "string",
+				Title:       "Path",
+				Description: "Repository directory relative to the workspace root",
+			},
+			"url": {
+				Type:        "string",
+				Title:       "URL",
+				Description: "Remote URL to clone (HTTPS or SSH)",
+			},
+			"depth": {
+				Type:        "number",
+				Title:       "Depth",
+				Description: "Shallow clone depth; full history when 0",
+			},
+			"branch": {
+				Type:        "string",
+				Title:       "Branch",
+				Description: "Branch to clone or pull, or the branch to create and check out",
+			},
+			"auth_type": {
+				Type:        "string",
+				Title:       "Authentication",
+				Description: "Credentials for the remote",
+				Default:     "none",
+				Enum:        []string{"none", "token", "ssh"},
+			},
+			"username": {
+				Type:        "string",
+				Title:       "Username",
+				Description: "HTTPS username (defaults to x-access-token) or SSH user (defaults to git)",
+			},
+			"token": {
+				Type:        "string",
+				Title:       "Token",
+				Description: "Personal access token for HTTPS remotes",
+				Format:      "password",
+			},
+			"private_key": {
+				Type:        "string",
+				Title:       "Private Key",
+				Description: "PEM encoded SSH private key",
+				Format:      "password",
+			},
+			"passphrase": {
+				Type:        "string",
+				Title:       "Passphrase",
+				Description: "Passphrase for an encrypted private key",
+				Format:      "password",
+			},
+			"host_key": {
+				Type:        "string",
+				Title:       "Host Key",
+				Description: "Expected SSH host public key; known_hosts is used when empty",
+			},
+			"insecure_ignore_host_key": {
+				Type:        "boolean",
+				Title:       "Ignore Host Key",
+				Description: "Skip SSH host key verification (not recommended)",
+				Default:     false,
+			},
+			"message": {
+				Type:        "string",
+				Title:       "Message",
+				Description: "Commit or annotated tag message. Supports template variables like {{variable}}",
+			},
+			"files": {
+				Type:        "array",
+				Title:       "Files",
+				Description: "Paths to stage, relative to the repository; all changes when empty",
+			},
+			"author_name": {
+				Type:        "string",
+				Title:       "Author Name",
+				Description: "Commit and tag author name",
+				Default:     "f1ow",
+			},
+			"author_email": {
+				Type:        "string",
+				Title:       "Author Email",
+				Description: "Commit and tag author email",
+				Default:     "f1ow@localhost",
+			},
+			"allow_empty": {
+				Type:        "boolean",
+				Title:       "Allow Empty",
+				Description: "Create a commit even when nothing changed",
+				Default:     false,
+			},
+			"remote": {
+				Type:        "string",
+				Title:       "Remote",
+				Description: "Remote to pull from or push to",
+				Default:     "origin",
+			},
+			"force": {
+				Type:        "boolean",
+				Title:       "Force",
+				Description: "Force push",
+				Default:     false,
+			},
+			"tags": {
+				Type:        "boolean",
+				Title:       "Push Tags",
+				Description: "Also push tags",
+				Default:     false,
+			},
+			"tag": {
+				Type:        "string",
+				Title:       "Tag",
+				Description: "Tag name; annotated when a message is set",
+			},
+			"timeout": {
+				Type:        "number",
+				Title:       "Timeout",
+				Description: "Operation timeout in seconds",
+				Default:     120,
+			},
+		},
+		Required: []string{"operation", "path"},
+		Inputs: []engine.PortSchema{
+			{
+				Name:        "input",
+				Type:        "any",
+				Description: "Input data available for template variables",
+				Required:    false,
+			},
+		},
+		Outputs: []engine.PortSchema{
+			{
+				Name:        "output",
+				Type:        "object",
+				Description: "Repository path, branch, and resulting commit hash",
+				Required:    true,
+			},
+		},
+	}
+}
+
+// parseConfig parses the node configuration
+func (n *GitNode) parseConfig(config interface{}) (*GitConfig, error) {
+	configMap, ok := config.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("invalid config type for git node")
+	}
+
+	configJSON, err := json.Marshal(configMap)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal config: %w", err)
+	}
+
+	var gitConfig GitConfig
+	if err := json.Unmarshal(configJSON, &gitConfig); err != nil {
+		return nil, fmt.Errorf("failed to parse git config: %w", err)
+	}
+
+	// Set defaults
+	if gitConfig.AuthType == "" {
+		gitConfig.AuthType = "none"
+	}
+	if gitConfig.AuthorName == "" {
+		gitConfig.AuthorName = "f1ow"
+	}
+	if gitConfig.AuthorEmail == "" {
+		gitConfig.AuthorEmail = "f1ow@localhost"
+	}
+	if gitConfig.Remote == "" {
+		gitConfig.Remote = "origin"
+	}
+	if gitConfig.Timeout == 0 {
+		gitConfig.Timeout = 120
+	}
+
+	return &gitConfig, nil
+}
+
+// auth builds the transport credentials
+func (n *GitNode) auth(config *GitConfig, input interface{}) (transport.AuthMethod, error) {
+	switch config.AuthType {
+	case "token":
+		username := processTemplate(config.Username, input)
+		if username == "" {
+			username = "x-access-token"
+		}
+		return &githttp.BasicAuth{Username: username, Password: processTemplate(config.Token, input)}, nil
+
+	case "ssh":
+		username := processTemplate(config.Username, input)
+		if username == "" {
+			username = "git"
+		}
+		keys, err := gitssh.NewPublicKeys(username, []byte(processTemplate(config.PrivateKey, input)), processTemplate(config.Passphrase, input))
+		if err != nil {
+			return nil, fmt.Errorf("invalid private key: %w", err)
+		}
+
+		if config.HostKey != "" {
+			hostKey, _, _, _, err := ssh.ParseAuthorizedKey([]byte(processTemplate(config.HostKey, input)))
+			if err != nil {
+				return nil, fmt.Errorf("invalid host key: %w", err)
+			}
+			keys.HostKeyCallback = ssh.FixedHostKey(hostKey)
+		} else if config.InsecureIgnoreHostKey {
+			keys.HostKeyCallback = ssh.InsecureIgnoreHostKey()
+		}
+		return keys, nil
+
+	default:
+		return nil, nil
+	}
+}
+
+func (n *GitNode) clone(ctx context.Context, config *GitConfig, input interface{}, root, dir, relPath string, auth transport.AuthMethod) (interface{}, error) {
+	remoteURL := processTemplate(config.URL, input)
+	options := &git.CloneOptions{
+		URL:   remoteURL,
+		Auth:  auth,
+		Depth: config.Depth,
+	}
+
+	// Local remotes are resolved inside the workspace like repository paths
+	endpoint, err := transport.NewEndpoint(remoteURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid remoteURL %s: %w", remoteURL, err)
+	}
+	if endpoint.Protocol == "file" {
+		options.URL = filepath.Join(root, filepath.Clean("/"+endpoint.Path))
+	}
+
+	proxyOptions, release, err := gitProxyOptions(ctx, []string{options.URL})
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	options.ProxyOptions = proxyOptions
+	if branch := processTemplate(config.Branch, input); branch != "" {
+		options.ReferenceName = plumbing.NewBranchReferenceName(branch)
+		options.SingleBranch = true
+	}
+
+	repo, err := git.PlainCloneContext(ctx, dir, false, options)
+	if err != nil {
+		return nil, fmt.Errorf("failed to clone %s: %w", remoteURL, err)
+	}
+
+	return n.headResult(repo, relPath)
+}
+
+func (n *GitNode) pull(ctx context.Context, config *GitConfig, input interface{}, repo *git.Repository, relPath string, auth transport.AuthMethod) (interface{}, error) {
+	worktree, err := repo.Worktree()
+	if err != nil {
+		return nil, err
+	}
+
+	proxyOptions, release, err := gitRemoteProxyOptions(ctx, repo, config.Remote)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	options := &git.PullOptions{RemoteName: config.Remote, Auth: auth, ProxyOptions: proxyOptions}
+	if branch := processTemplate(config.Branch, input); branch != "" {
+		options.ReferenceName = plumbing.NewBranchReferenceName(branch)
+	}
+
+	before, err := repo.Head()
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve HEAD: %w", err)
+	}
+
+	if err := worktree.PullContext(ctx, options); err != nil && !errors.Is(err, git.NoErrAlreadyUpToDate) {
+		return nil, fmt.Errorf("failed to pull: %w", err)
+	}
+
+	result, err := n.headResult(repo, relPath)
+	if err != nil {
+		return nil, err
+	}
+	result["updated"] = result["head"] != before.Hash().String()
+	return result, nil
+}
+
+func (n *GitNode) commit(config *GitConfig, input interface{}, repo *git.Repository, relPath string) (interface{}, error) {
+	worktree, err := repo.Worktree()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(config.Files) == 0 {
+		if err := worktree.AddWithOptions(&git.AddOptions{All: true}); err != nil {
+			return nil, fmt.Errorf("failed to stage changes: %w", err)
+		}
+	} else {
+		for _, file := range config.Files {
+			if _, err := worktree.Add(processTemplate(file, input)); err != nil {
+				return nil, fmt.Errorf("failed to stage %s: %w", file, err)
+			}
+		}
+	}
+
+	status, err := worktree.Status()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read status: %w", err)
+	}
+	if status.IsClean() && !config.AllowEmpty {
+		result, err := n.headResult(repo, relPath)
+		if err != nil {
+			return nil, err
+		}
+		result["committed"] = false
+		return result, nil
+	}
+
+	hash, err := worktree.Commit(processTemplate(config.Message, input), &git.CommitOptions{
+		Author:            n.signature(config, input),
+		AllowEmptyCommits: config.AllowEmpty,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to commit: %w", err)
+	}
+
+	result, err := n.headResult(repo, relPath)
+	if err != nil {
+		return nil, err
+	}
+	result["hash"] = hash.String()
+	result["committed"] = true
+	return result, nil
+}
+
+func (n *GitNode) push(ctx context.Context, config *GitConfig, repo *git.Repository, relPath string, auth transport.AuthMethod) (interface{}, error) {
+	head, err := repo.Head()
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve HEAD: %w", err)
+	}
+	if !head.Name().IsBranch() {
+		return nil, fmt.Errorf("cannot push a detached HEAD")
+	}
+
+	refSpecs := []gitconfig.RefSpec{gitconfig.RefSpec(fmt.Sprintf("%s:%s", head.Name(), head.Name()))}
+	if config.Tags {
+		refSpecs = append(refSpecs, "refs/tags/*:refs/tags/*")
+	}
+
+	proxyOptions, release, err := gitRemoteProxyOptions(ctx, repo, config.Remote)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	pushed := true
+	err = repo.PushContext(ctx, &git.PushOptions{
+		RemoteName:   config.Remote,
+		RefSpecs:     refSpecs,
+		Auth:         auth,
+		Force:        config.Force,
+		ProxyOptions: proxyOptions,
+	})
+	if err != nil {
+		if !errors.Is(err, git.NoErrAlreadyUpToDate) {
+			return nil, fmt.Errorf("failed to push: %w", err)
+		}
+		pushed = false
+	}
+
+	result, err := n.headResult(repo, relPath)
+	if err != nil {
+		return nil, err
+	}
+	result["pushed"] = pushed
+	return result, nil
+}
+
+func (n *GitNode) branch(config *GitConfig, input interface{}, repo *git.Repository, relPath string) (interface{}, error) {
+	worktree, err := repo.Worktree()
+	if err != nil {
+		return nil, err
+	}
+
+	name := plumbing.NewBranchReferenceName(processTemplate(config.Branch, input))
+	_, err = repo.Reference(name, false)
+	exists := err == nil
+
+	// Keep local changes so files written before branching end up on the new branch
+	if err := worktree.Checkout(&git.CheckoutOptions{Branch: name, Create: !exists, Keep: true}); err != nil {
+		return nil, fmt.Errorf("failed to check out %s: %w", name.Short(), err)
+	}
+
+	result, err := n.headResult(repo, relPath)
+	if err != nil {
+		return nil, err
+	}
+	result["created"] = !exists
+	return result, nil
+}
+
+func (n *GitNode) tag(config *GitConfig, input interface{}, repo *git.Repository, relPath string) (interface{}, error) {
+	head, err := repo.Head()
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve HEAD: %w", err)
+	}
+
+	var options *git.CreateTagOptions
+	if message := processTemplate(config.Message, input); message != "" {
+		options = &git.CreateTagOptions{Tagger: n.signature(config, input), Message: message}
+	}
+
+	name := processTemplate(config.Tag, input)
+	if _, err := repo.CreateTag(name, head.Hash(), options); err != nil {
+		return nil, fmt.Errorf("failed to create tag %s: %w", name, err)
+	}
+
+	result, err := n.headResult(repo, relPath)
+	if err != nil {
+		return nil, err
+	}
+	result["tag"] = name
+	return result, nil
+}
+
+func (n *GitNode) signature(config *GitConfig, input interface{}) *object.Signature {
+	return &object.Signature{
+		Name:  processTemplate(config.AuthorName, input),
+		Email: processTemplate(config.AuthorEmail, input),
+		When:  time.Now(),
+	}
+}
+
+// headResult describes the repository's current branch and commit
+func (n *GitNode) headResult(repo *git.Repository, relPath string) (map[string]interface{}, error) {
+	head, err := repo.Head()
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve HEAD: %w", err)
+	}
+
+	result := map[string]interface{}{
+		"path": relPath,
+		"head": head.Hash().String(),
+	}
+	if head.Name().IsBranch() {
+		result["branch"] = strings.TrimPrefix(head.Name().String(), "refs/heads/")
+	}
+	return result, nil
+}
+
+// gitProxyScheme is the proxy scheme ssh remotes are dialed through. go-git
+// dials ssh remotes itself, with no hook but a proxy, so the node registers
+// a dialer per operation under a random token and hands go-git a proxy URL
+// naming it.
+const gitProxyScheme = "f1ow-policy"
+
+var (
+	gitTransportsOnce sync.Once
+	gitDialers        sync.Map // token -> DialFunc
+)
+
+// installGitTransports makes go-git's http and https remotes, and ssh
+// remotes given a gitProxyScheme proxy, connect through the network policy
+// of the operation's context
+func installGitTransports() {
+	gitTransportsOnce.Do(func() {
+		// Connections are not kept alive, so one tenant's requests never
+		// reuse a connection dialed for another's
+		transport := guardTransport(nil, func(ctx context.Context, network, address string) (net.Conn, error) {
+			return Dialer(ctx)(ctx, network, address)
+		})
+		transport.DisableKeepAlives = true
+		httpClient := githttp.NewClient(&http.Client{Transport: transport})
+		gitclient.InstallProtocol("http", httpClient)
+		gitclient.InstallProtocol("https", httpClient)
+
+		proxy.RegisterDialerType(gitProxyScheme, func(u *url.URL, _ proxy.Dialer) (proxy.Dialer, error) {
+			dial, ok := gitDialers.Load(u.Host)
+			if !ok {
+				return nil, fmt.Errorf("git dialer %s is not registered", u.Host)
+			}
+			return gitDialer(dial.(DialFunc)), nil
+		})
+	})
+}
+
+// gitRemoteProxyOptions returns gitProxyOptions for the URLs of a remote
+func gitRemoteProxyOptions(ctx context.Context, repo *git.Repository, name string) (transport.ProxyOptions, func(), error) {
+	remote, err := repo.Remote(name)
+	if err != nil {
+		return transport.ProxyOptions{}, nil, fmt.Errorf("failed to find remote %s: %w", name, err)
+	}
+	return gitProxyOptions(ctx, remote.Config().URLs)
+}
+
+// gitProxyOptions returns the proxy options that send ssh remotes through
+// the network policy in ctx, and a function releasing them. Remotes over
+// git://, which go-git dials directly, are refused when there is a policy.
+func gitProxyOptions(ctx context.Context, urls []string) (transport.ProxyOptions, func(), error) {
+	overSSH := false
+	for _, rawURL := range urls {
+		endpoint, err := transport.NewEndpoint(rawURL)
+		if err != nil {
+			return transport.ProxyOptions{}, nil, fmt.Errorf("invalid remote %s: %w", rawURL, err)
+		}
+		switch endpoint.Protocol {
+		case "ssh":
+			overSSH = true
+		case "git":
+			if _, ok := netpolicy.FromContext(ctx); ok {
+				return transport.ProxyOptions{}, nil, fmt.Errorf("git:// remotes are not supported under a network policy")
+			}
+		}
+	}
+	if !overSSH {
+		return transport.ProxyOptions{}, func() {}, nil
+	}
+
+	dial := Dialer(ctx)
+	token := uuid.NewString()
+	gitDialers.Store(token, DialFunc(func(dialCtx context.Context, network, address string) (net.Conn, error) {
+		// go-git dials with a context of its own; the operation's still
+		// bounds the connection attempt
+		dialCtx, cancel := context.WithCancel(dialCtx)
+		defer cancel()
+		stop := context.AfterFunc(ctx, cancel)
+		defer stop()
+		return dial(dialCtx, network, address)
+	}))
+	return transport.ProxyOptions{URL: gitProxyScheme + "://" + token}, func() { gitDialers.Delete(token) }, nil
+}
+
+// gitDialer adapts a DialFunc to the dialer interfaces of x/net/proxy
+type gitDialer DialFunc
+
+func (d gitDialer) Dial(network, address string) (net.Conn, error) {
+	return d(context.Background(), network, address)
+}
+
+func (d gitDialer) DialContext(ctx context.Context, network, address string) (net.Conn, error) {
+	return d(ctx, network, address)
+}
@@ -0,0 +1,415 @@
+package nodes
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/nuumz/f1ow/internal/engine"
+)
+
+// GitHubNode calls the GitHub REST API for issues, pull requests, releases,
+// comments, and workflow dispatch
+type GitHubNode struct {
+	BaseNode
+}
+
+// GitHubConfig defines configuration for github node
+type GitHubConfig struct {
+	Operation    string `json:"operation"`
+	Token        string `json:"token"`
+	CredentialID string `json:"credential_id"` // vault credential supplying token
+	BaseURL      string `json:"base_url"`      // GitHub Enterprise API URL
+	Owner        string `json:"owner"`
+	Repo         string `json:"repo"`
+	Number       string `json:"number"` // issue or pull request number
+
+	// Issues and pull requests
+	Title     string   `json:"title"`
+	Body      string   `json:"body"`
+	Labels    []string `json:"labels"`
+	Assignees []string `json:"assignees"`
+	State     string   `json:"state"` // "open", "closed"
+
+	// Pull requests
+	Head        string `json:"head"`
+	Base        string `json:"base"`
+	Draft       bool   `json:"draft"`
+	MergeMethod string `json:"merge_method"` // "merge", "squash", "rebase"
+
+	// Releases
+	TagName    string `json:"tag_name"`
+	Name       string `json:"name"`
+	Prerelease bool   `json:"prerelease"`
+
+	// Workflow dispatch
+	WorkflowID string                 `json:"workflow_id"` // workflow file name or ID
+	Ref        string                 `json:"ref"`
+	Inputs     map[string]interface{} `json:"inputs"`
+
+	Timeout int `json:"timeout"` // seconds
+}
+
+var validGitHubOperations = map[string]bool{
+	"create_issue": true, "update_issue": true, "get_issue": true, "create_comment": true,
+	"create_pull_request": true, "get_pull_request": true, "merge_pull_request": true,
+	"create_release": true, "dispatch_workflow": true,
+}
+
+// NewGitHubNode creates a new github node
+func NewGitHubNode() engine.NodeType {
+	return &GitHubNode{
+		BaseNode: BaseNode{
+			nodeType:    "github",
+			name:        "GitHub",
+			description: "Manage GitHub issues, pull requests, releases, comments, and workflow runs",
+			category:    "Developer Tools",
+			icon:        "github",
+		},
+	}
+}
+
+// Execute calls the GitHub API
+func (n *GitHubNode) Execute(ctx context.Context, config interface{}, input interface{}) (interface{}, error) {
+	githubConfig, err := n.parseConfig(config)
+	if err != nil {
+		return nil, err
+	}
+
+	repoPath := fmt.Sprintf("%s/repos/%s/%s", strings.TrimRight(processTemplate(githubConfig.BaseURL, input), "/"),
+		url.PathEscape(processTemplate(githubConfig.Owner, input)), url.PathEscape(processTemplate(githubConfig.Repo, input)))
+	number := processTemplate(githubConfig.Number, input)
+
+	var method, endpoint string
+	var body map[string]interface{}
+
+	switch githubConfig.Operation {
+	case "create_issue":
+		method, endpoint = http.MethodPost, repoPath+"/issues"
+		body = n.issueBody(githubConfig, input)
+	case "update_issue":
+		method, endpoint = http.MethodPatch, repoPath+"/issues/"+number
+		body = n.issueBody(githubConfig, input)
+		setIfPresent(body, "state", githubConfig.State, input)
+	case "get_issue":
+		method, endpoint = http.MethodGet, repoPath+"/issues/"+number
+	case "create_comment":
+		// Pull request conversation comments use the issues endpoint
+		method, endpoint = http.MethodPost, repoPath+"/issues/"+number+"/comments"
+		body = map[string]interface{}{"body": processTemplate(githubConfig.Body, input)}
+	case "create_pull_request":
+		method, endpoint = http.MethodPost, repoPath+"/pulls"
+		body = map[string]interface{}{
+			"title": processTemplate(githubConfig.Title, input),
+			"head":  processTemplate(githubConfig.Head, input),
+			"base":  processTemplate(githubConfig.Base, input),
+			"draft": githubConfig.Draft,
+		}
+		setIfPresent(body, "body", githubConfig.Body, input)
+	case "get_pull_request":
+		method, endpoint = http.MethodGet, repoPath+"/pulls/"+number
+	case "merge_pull_request":
+		method, endpoint = http.MethodPut, repoPath+"/pulls/"+number+"/merge"
+		body = map[string]interface{}{"merge_method": githubConfig.MergeMethod}
+		setIfPresent(body, "commit_title", githubConfig.Title, input)
+		setIfPresent(body, "commit_message", githubConfig.Body, input)
+	case "create_release":
+		method, endpoint = http.MethodPost, repoPath+"/releases"
+		body = map[string]interface{}{
+			"tag_name":   processTemplate(githubConfig.TagName, input),
+			"draft":      githubConfig.Draft,
+			"prerelease": githubConfig.Prerelease,
+		}
+		setIfPresent(body, "name", githubConfig.Name, input)
+		setIfPresent(body, "body", githubConfig.Body, input)
+		setIfPresent(body, "target_commitish", githubConfig.Ref, input)
+	case "dispatch_workflow":
+		method = http.MethodPost
+		endpoint = repoPath + "/actions/workflows/" + url.PathEscape(processTemplate(githubConfig.WorkflowID, input)) + "/dispatches"
+		body = map[string]interface{}{"ref": processTemplate(githubConfig.Ref, input)}
+		if len(githubConfig.Inputs) > 0 {
+			body["inputs"] = interpolateValue(githubConfig.Inputs, input)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported operation: %s", githubConfig.Operation)
+	}
+
+	headers := map[string]string{
+		"Accept":               "application/vnd.github+json",
+		"X-GitHub-Api-Version": "2022-11-28",
+		"Authorization":        "Bearer " + processTemplate(githubConfig.Token, input),
+	}
+
+	client := guardClient(ctx, &http.Client{Timeout: time.Duration(githubConfig.Timeout) * time.Second})
+	var requestBody interface{}
+	if body != nil {
+		requestBody = body
+	}
+
+	response, status, err := restRequest(ctx, client, method, endpoint, headers, requestBody)
+	if err != nil {
+		return nil, fmt.Errorf("github %s failed: %w", githubConfig.Operation, err)
+	}
+
+	return map[string]interface{}{
+		"operation":  githubConfig.Operation,
+		"statusCode": status,
+		"data":       response,
+	}, nil
+}
+
+// ValidateConfig validates the node configuration
+func (n *GitHubNode) ValidateConfig(config interface{}) error {
+	githubConfig, err := n.parseConfig(config)
+	if err != nil {
+		return err
+	}
+
+	if !validGitHubOperations[githubConfig.Operation] {
+		return fmt.Errorf("invalid operation: %s", githubConfig.Operation)
+	}
+	if githubConfig.Token == "" && githubConfig.CredentialID == "" {
+		return fmt.Errorf("token or credential_id is required")
+	}
+	if githubConfig.Owner == "" || githubConfig.Repo == "" {
+		return fmt.Errorf("owner and repo are required")
+	}
+
+	switch githubConfig.Operation {
+	case "update_issue", "get_issue", "create_comment", "get_pull_request", "merge_pull_request":
+		if githubConfig.Number == "" {
+			return fmt.Errorf("number is required for %s", githubConfig.Operation)
+		}
+	}
+
+	switch githubConfig.Operation {
+	case "create_issue":
+		if githubConfig.Title == "" {
+			return fmt.Errorf("title is required for create_issue")
+		}
+	case "create_comment":
+		if githubConfig.Body == "" {
+			return fmt.Errorf("body is required for create_comment")
+		}
+	case "create_pull_request":
+		if githubConfig.Title == "" || githubConfig.Head == "" || githubConfig.Base == "" {
+			return fmt.Errorf("title, head, and base are required for create_pull_request")
+		}
+	case "merge_pull_request":
+		if githubConfig.MergeMethod != "merge" && githubConfig.MergeMethod != "squash" && githubConfig.MergeMethod != "rebase" {
+			return fmt.Errorf("invalid merge_method: %s", githubConfig.MergeMethod)
+		}
+	case "create_release":
+		if githubConfig.TagName == "" {
+			return fmt.Errorf("tag_name is required for create_release")
+		}
+	case "dispatch_workflow":
+		if githubConfig.WorkflowID == "" || githubConfig.Ref == "" {
+			return fmt.Errorf("workflow_id and ref are required for dispatch_workflow")
+		}
+	}
+
+	return nil
+}
+
+// GetSchema returns the node configuration schema
+func (n *GitHubNode) GetSchema() engine.NodeSchema {
+	return engine.NodeSchema{
+		Type: "object",
+		Properties: map[string]engine.Property{
+			"operation": {
+				Type:        "string",
+				Title:       "Operation",
+				Description: "API call to make",
+				Enum: []string{"create_issue", "update_issue", "get_issue", "create_comment",
+					"create_pull_request", "get_pull_request", "merge_pull_request",
+					"create_release", "dispatch_workflow"},
+			},
+			"credential_id": {
+				Type:        "string",
+				Title:       "Credential",
+				Description: "Vault credential supplying the token; an OAuth2 credential is refreshed automatically",
+			},
+			"token": {
+				Type:        "string",
+				Title:       "Token",
+				Description: "Personal access token or GitHub App installation token",
+				Format:      "password",
+			},
+			"base_url": {
+				Type:        "string",
+				Title:       "API URL",
+				Description: "GitHub Enterprise API URL",
+				Default:     "https://api.github.com",
+			},
+			"owner": {
+				Type:        "string",
+				Title:       "Owner",
+				Description: "Repository owner (user or organization)",
+			},
+			"repo": {
+				Type:        "string",
+				Title:       "Repository",
+				Description: "Repository name",
+			},
+			"number": {
+				Type:        "string",
+				Title:       "Number",
+				Description: "Issue or pull request number, e.g. {{pull_request.number}}",
+			},
+			"title": {
+				Type:        "string",
+				Title:       "Title",
+				Description: "Issue or pull request title, or merge commit title",
+			},
+			"body": {
+				Type:        "string",
+				Title:       "Body",
+				Description: "Issue, pull request, comment, or release text. Supports template variables like {{variable}}",
+				Format:      "textarea",
+			},
+			"labels": {
+				Type:        "array",
+				Title:       "Labels",
+				Description: "Issue labels",
+			},
+			"assignees": {
+				Type:        "array",
+				Title:       "Assignees",
+				Description: "Issue assignee logins",
+			},
+			"state": {
+				Type:        "string",
+				Title:       "State",
+				Description: "Issue state for update_issue",
+				Enum:        []string{"", "open", "closed"},
+			},
+			"head": {
+				Type:        "string",
+				Title:       "Head Branch",
+				Description: "Branch containing the changes",
+			},
+			"base": {
+				Type:        "string",
+				Title:       "Base Branch",
+				Description: "Branch to merge into",
+			},
+			"draft": {
+				Type:        "boolean",
+				Title:       "Draft",
+				Description: "Create a draft pull request or release",
+				Default:     false,
+			},
+			"merge_method": {
+				Type:        "string",
+				Title:       "Merge Method",
+				Description: "How to merge the pull request",
+				Default:     "merge",
+				Enum:        []string{"merge", "squash", "rebase"},
+			},
+			"tag_name": {
+				Type:        "string",
+				Title:       "Tag",
+				Description: "Release tag",
+			},
+			"name": {
+				Type:        "string",
+				Title:       "Release Name",
+				Description: "Release title",
+			},
+			"prerelease": {
+				Type:        "boolean",
+				Title:       "Pre-release",
+				Description: "Mark the release as a pre-release",
+				Default:     false,
+			},
+			"workflow_id": {
+				Type:        "string",
+				Title:       "Workflow",
+				Description: "Actions workflow file name or ID to dispatch",
+			},
+			"ref": {
+				Type:        "string",
+				Title:       "Ref",
+				Description: "Branch or tag to run the workflow on, or the release target",
+			},
+			"inputs": {
+				Type:        "object",
+				Title:       "Inputs",
+				Description: "Workflow dispatch inputs. Values support template variables",
+			},
+			"timeout": {
+				Type:        "number",
+				Title:       "Timeout",
+				Description: "Request timeout in seconds",
+				Default:     30,
+			},
+		},
+		Required: []string{"operation", "owner", "repo"},
+		Inputs: []engine.PortSchema{
+			{
+				Name:        "input",
+				Type:        "any",
+				Description: "Input data available for template variables",
+				Required:    false,
+			},
+		},
+		Outputs: []engine.PortSchema{
+			{
+				Name:        "output",
+				Type:        "object",
+				Description: "API response {operation, statusCode, data}",
+				Required:    true,
+			},
+		},
+	}
+}
+
+// parseConfig parses the node configuration
+func (n *GitHubNode) parseConfig(config interface{}) (*GitHubConfig, error) {
+	configMap, ok := config.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("invalid config type for github node")
+	}
+
+	configJSON, err := json.Marshal(configMap)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal config: %w", err)
+	}
+
+	var githubConfig GitHubConfig
+	if err := json.Unmarshal(configJSON, &githubConfig); err != nil {
+		return nil, fmt.Errorf("failed to parse github config: %w", err)
+	}
+
+	// Set defaults
+	if githubConfig.BaseURL == "" {
+		githubConfig.BaseURL = "https://api.github.com"
+	}
+	if githubConfig.MergeMethod == "" {
+		githubConfig.MergeMethod = "merge"
+	}
+	if githubConfig.Timeout == 0 {
+		githubConfig.Timeout = 30
+	}
+
+	return &githubConfig, nil
+}
+
+// issueBody builds the fields shared by issue creation and updates
+func (n *GitHubNode) issueBody(config *GitHubConfig, input interface{}) map[string]interface{} {
+	body := map[string]interface{}{}
+	setIfPresent(body, "title", config.Title, input)
+	setIfPresent(body, "body", config.Body, input)
+	if len(config.Labels) > 0 {
+		body["labels"] = templateList(config.Labels, input)
+	}
+	if len(config.Assignees) > 0 {
+		body["assignees"] = templateList(config.Assignees, input)
+	}
+	return body
+}
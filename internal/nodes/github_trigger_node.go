@@ -0,0 +1,111 @@
+package nodes
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/nuumz/f1ow/internal/engine"
+)
+
+// GitHubTriggerNode starts executions for signed GitHub webhook deliveries
+type GitHubTriggerNode struct {
+	BaseNode
+}
+
+// GitHubTriggerConfig defines configuration for github trigger node
+type GitHubTriggerConfig struct {
+	Secret  string   `json:"secret"`  // webhook secret used for X-Hub-Signature-256
+	Events  []string `json:"events"`  // e.g. "push", "pull_request"; empty accepts all
+	Actions []string `json:"actions"` // e.g. "opened", "closed"; empty accepts all
+}
+
+// NewGitHubTriggerNode creates a new github trigger node
+func NewGitHubTriggerNode() engine.NodeType {
+	return &GitHubTriggerNode{
+		BaseNode: BaseNode{
+			nodeType:    "github_trigger",
+			name:        "GitHub Event",
+			description: "Start the workflow when GitHub delivers a signed webhook event",
+			category:    "Triggers",
+			icon:        "github",
+		},
+	}
+}
+
+// Execute passes the received event through to downstream nodes
+func (n *GitHubTriggerNode) Execute(ctx context.Context, config interface{}, input interface{}) (interface{}, error) {
+	if inputMap, ok := input.(map[string]interface{}); ok {
+		return inputMap, nil
+	}
+	return map[string]interface{}{"data": input}, nil
+}
+
+// ValidateConfig validates the node configuration
+func (n *GitHubTriggerNode) ValidateConfig(config interface{}) error {
+	githubConfig, err := ParseGitHubTriggerConfig(config)
+	if err != nil {
+		return err
+	}
+
+	if githubConfig.Secret == "" {
+		return fmt.Errorf("secret is required")
+	}
+
+	return nil
+}
+
+// GetSchema returns the node configuration schema
+func (n *GitHubTriggerNode) GetSchema() engine.NodeSchema {
+	return engine.NodeSchema{
+		Type: "object",
+		Properties: map[string]engine.Property{
+			"secret": {
+				Type:        "string",
+				Title:       "Webhook Secret",
+				Description: "Secret configured on the GitHub webhook; deliveries without a valid signature are rejected",
+				Format:      "password",
+			},
+			"events": {
+				Type:        "array",
+				Title:       "Events",
+				Description: "GitHub event names to accept, e.g. push, pull_request, issues, issue_comment, release, workflow_run. Empty accepts all",
+			},
+			"actions": {
+				Type:        "array",
+				Title:       "Actions",
+				Description: "Event actions to accept, e.g. opened, closed, published. Empty accepts all",
+			},
+		},
+		Required: []string{"secret"},
+		Inputs:   []engine.PortSchema{},
+		Outputs: []engine.PortSchema{
+			{
+				Name:        "output",
+				Type:        "object",
+				Description: "Event {provider, event, action, repository, sender, ..., payload}",
+				Required:    true,
+			},
+		},
+	}
+}
+
+// ParseGitHubTriggerConfig parses a github trigger configuration
+func ParseGitHubTriggerConfig(config interface{}) (*GitHubTriggerConfig, error) {
+	configMap, ok := config.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("invalid config type for github trigger node")
+	}
+
+	configJSON, err := json.Marshal(configMap)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal config: %w", err)
+	}
+
+	var githubConfig GitHubTriggerConfig
+	if err := json.Unmarshal(configJSON, &githubConfig); err != nil {
+		return nil, fmt.Errorf("failed to parse github trigger config: %w", err)
+	}
+
+	return &githubConfig, nil
+}
@@ -0,0 +1,408 @@
+package nodes
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/nuumz/f1ow/internal/engine"
+)
+
+// GitLabNode calls the GitLab REST API for issues, merge requests, releases,
+// comments, and pipeline runs
+type GitLabNode struct {
+	BaseNode
+}
+
+// GitLabConfig defines configuration for gitlab node
+type GitLabConfig struct {
+	Operation    string `json:"operation"`
+	Token        string `json:"token"`
+	CredentialID string `json:"credential_id"` // vault credential supplying token
+	BaseURL      string `json:"base_url"`      // self-managed API URL, e.g. https://gitlab.example.com/api/v4
+	Project      string `json:"project"`       // numeric ID or "group/project" path
+	IID          string `json:"iid"`           // issue or merge request IID
+
+	// Issues and merge requests
+	Title       string   `json:"title"`
+	Description string   `json:"description"`
+	Labels      []string `json:"labels"`
+	StateEvent  string   `json:"state_event"` // "close", "reopen"
+
+	// Comments
+	Target string `json:"target"` // "issue", "merge_request"
+	Body   string `json:"body"`
+
+	// Merge requests
+	SourceBranch       string `json:"source_branch"`
+	TargetBranch       string `json:"target_branch"`
+	Squash             bool   `json:"squash"`
+	RemoveSourceBranch bool   `json:"remove_source_branch"`
+
+	// Releases
+	TagName string `json:"tag_name"`
+	Name    string `json:"name"`
+
+	// Pipelines
+	Ref       string            `json:"ref"`
+	Variables map[string]string `json:"variables"`
+
+	Timeout int `json:"timeout"` // seconds
+}
+
+var validGitLabOperations = map[string]bool{
+	"create_issue": true, "update_issue": true, "get_issue": true, "create_comment": true,
+	"create_merge_request": true, "get_merge_request": true, "merge_merge_request": true,
+	"create_release": true, "trigger_pipeline": true,
+}
+
+// NewGitLabNode creates a new gitlab node
+func NewGitLabNode() engine.NodeType {
+	return &GitLabNode{
+		BaseNode: BaseNode{
+			nodeType:    "gitlab",
+			name:        "GitLab",
+			description: "Manage GitLab issues, merge requests, releases, comments, and pipelines",
+			category:    "Developer Tools",
+			icon:        "gitlab",
+		},
+	}
+}
+
+// Execute calls the GitLab API
+func (n *GitLabNode) Execute(ctx context.Context, config interface{}, input interface{}) (interface{}, error) {
+	gitlabConfig, err := n.parseConfig(config)
+	if err != nil {
+		return nil, err
+	}
+
+	projectPath := fmt.Sprintf("%s/projects/%s", strings.TrimRight(processTemplate(gitlabConfig.BaseURL, input), "/"),
+		url.PathEscape(processTemplate(gitlabConfig.Project, input)))
+	iid := processTemplate(gitlabConfig.IID, input)
+
+	var method, endpoint string
+	var body map[string]interface{}
+
+	switch gitlabConfig.Operation {
+	case "create_issue":
+		method, endpoint = http.MethodPost, projectPath+"/issues"
+		body = n.issueBody(gitlabConfig, input)
+	case "update_issue":
+		method, endpoint = http.MethodPut, projectPath+"/issues/"+iid
+		body = n.issueBody(gitlabConfig, input)
+		setIfPresent(body, "state_event", gitlabConfig.StateEvent, input)
+	case "get_issue":
+		method, endpoint = http.MethodGet, projectPath+"/issues/"+iid
+	case "create_comment":
+		resource := "issues"
+		if gitlabConfig.Target == "merge_request" {
+			resource = "merge_requests"
+		}
+		method, endpoint = http.MethodPost, projectPath+"/"+resource+"/"+iid+"/notes"
+		body = map[string]interface{}{"body": processTemplate(gitlabConfig.Body, input)}
+	case "create_merge_request":
+		method, endpoint = http.MethodPost, projectPath+"/merge_requests"
+		body = n.issueBody(gitlabConfig, input)
+		body["source_branch"] = processTemplate(gitlabConfig.SourceBranch, input)
+		body["target_branch"] = processTemplate(gitlabConfig.TargetBranch, input)
+		body["squash"] = gitlabConfig.Squash
+		body["remove_source_branch"] = gitlabConfig.RemoveSourceBranch
+	case "get_merge_request":
+		method, endpoint = http.MethodGet, projectPath+"/merge_requests/"+iid
+	case "merge_merge_request":
+		method, endpoint = http.MethodPut, projectPath+"/merge_requests/"+iid+"/merge"
+		body = map[string]interface{}{
+			"squash":                      gitlabConfig.Squash,
+			"should_remove_source_branch": gitlabConfig.RemoveSourceBranch,
+		}
+		setIfPresent(body, "merge_commit_message", gitlabConfig.Description, input)
+	case "create_release":
+		method, endpoint = http.MethodPost, projectPath+"/releases"
+		body = map[string]interface{}{"tag_name": processTemplate(gitlabConfig.TagName, input)}
+		setIfPresent(body, "name", gitlabConfig.Name, input)
+		setIfPresent(body, "description", gitlabConfig.Description, input)
+		setIfPresent(body, "ref", gitlabConfig.Ref, input)
+	case "trigger_pipeline":
+		method, endpoint = http.MethodPost, projectPath+"/pipeline"
+		body = map[string]interface{}{"ref": processTemplate(gitlabConfig.Ref, input)}
+		if len(gitlabConfig.Variables) > 0 {
+			variables := make([]map[string]string, 0, len(gitlabConfig.Variables))
+			for key, value := range gitlabConfig.Variables {
+				variables = append(variables, map[string]string{"key": key, "value": processTemplate(value, input)})
+			}
+			body["variables"] = variables
+		}
+	default:
+		return nil, fmt.Errorf("unsupported operation: %s", gitlabConfig.Operation)
+	}
+
+	// Access tokens and OAuth2 tokens are both accepted as bearer tokens
+	headers := map[string]string{
+		"Accept":        "application/json",
+		"Authorization": "Bearer " + processTemplate(gitlabConfig.Token, input),
+	}
+
+	client := guardClient(ctx, &http.Client{Timeout: time.Duration(gitlabConfig.Timeout) * time.Second})
+	var requestBody interface{}
+	if body != nil {
+		requestBody = body
+	}
+
+	response, status, err := restRequest(ctx, client, method, endpoint, headers, requestBody)
+	if err != nil {
+		return nil, fmt.Errorf("gitlab %s failed: %w", gitlabConfig.Operation, err)
+	}
+
+	return map[string]interface{}{
+		"operation":  gitlabConfig.Operation,
+		"statusCode": status,
+		"data":       response,
+	}, nil
+}
+
+// ValidateConfig validates the node configuration
+func (n *GitLabNode) ValidateConfig(config interface{}) error {
+	gitlabConfig, err := n.parseConfig(config)
+	if err != nil {
+		return err
+	}
+
+	if !validGitLabOperations[gitlabConfig.Operation] {
+		return fmt.Errorf("invalid operation: %s", gitlabConfig.Operation)
+	}
+	if gitlabConfig.Token == "" && gitlabConfig.CredentialID == "" {
+		return fmt.Errorf("token or credential_id is required")
+	}
+	if gitlabConfig.Project == "" {
+		return fmt.Errorf("project is required")
+	}
+
+	switch gitlabConfig.Operation {
+	case "update_issue", "get_issue", "create_comment", "get_merge_request", "merge_merge_request":
+		if gitlabConfig.IID == "" {
+			return fmt.Errorf("iid is required for %s", gitlabConfig.Operation)
+		}
+	}
+
+	switch gitlabConfig.Operation {
+	case "create_issue":
+		if gitlabConfig.Title == "" {
+			return fmt.Errorf("title is required for create_issue")
+		}
+	case "update_issue":
+		if gitlabConfig.StateEvent != "" && gitlabConfig.StateEvent != "close" && gitlabConfig.StateEvent != "reopen" {
+			return fmt.Errorf("invalid state_event: %s", gitlabConfig.StateEvent)
+		}
+	case "create_comment":
+		if gitlabConfig.Body == "" {
+			return fmt.Errorf("body is required for create_comment")
+		}
+		if gitlabConfig.Target != "issue" && gitlabConfig.Target != "merge_request" {
+			return fmt.Errorf("invalid target: %s", gitlabConfig.Target)
+		}
+	case "create_merge_request":
+		if gitlabConfig.Title == "" || gitlabConfig.SourceBranch == "" || gitlabConfig.TargetBranch == "" {
+			return fmt.Errorf("title, source_branch, and target_branch are required for create_merge_request")
+		}
+	case "create_release":
+		if gitlabConfig.TagName == "" {
+			return fmt.Errorf("tag_name is required for create_release")
+		}
+	case "trigger_pipeline":
+		if gitlabConfig.Ref == "" {
+			return fmt.Errorf("ref is required for trigger_pipeline")
+		}
+	}
+
+	return nil
+}
+
+// GetSchema returns the node configuration schema
+func (n *GitLabNode) GetSchema() engine.NodeSchema {
+	return engine.NodeSchema{
+		Type: "object",
+		Properties: map[string]engine.Property{
+			"operation": {
+				Type:        "string",
+				Title:       "Operation",
+				Description: "API call to make",
+				Enum: []string{"create_issue", "update_issue", "get_issue", "create_comment",
+					"create_merge_request", "get_merge_request", "merge_merge_request",
+					"create_release", "trigger_pipeline"},
+			},
+			"credential_id": {
+				Type:        "string",
+				Title:       "Credential",
+				Description: "Vault credential supplying the token; an OAuth2 credential is refreshed automatically",
+			},
+			"token": {
+				Type:        "string",
+				Title:       "Token",
+				Description: "Personal, project, or group access token",
+				Format:      "password",
+			},
+			"base_url": {
+				Type:        "string",
+				Title:       "API URL",
+				Description: "GitLab API URL for self-managed instances",
+				Default:     "https://gitlab.com/api/v4",
+			},
+			"project": {
+				Type:        "string",
+				Title:       "Project",
+				Description: "Project ID or full path, e.g. group/project",
+			},
+			"iid": {
+				Type:        "string",
+				Title:       "IID",
+				Description: "Issue or merge request IID, e.g. {{object_attributes.iid}}",
+			},
+			"title": {
+				Type:        "string",
+				Title:       "Title",
+				Description: "Issue or merge request title",
+			},
+			"description": {
+				Type:        "string",
+				Title:       "Description",
+				Description: "Issue, merge request, or release description, or merge commit message. Supports template variables like {{variable}}",
+				Format:      "textarea",
+			},
+			"labels": {
+				Type:        "array",
+				Title:       "Labels",
+				Description: "Issue or merge request labels",
+			},
+			"state_event": {
+				Type:        "string",
+				Title:       "State Event",
+				Description: "Close or reopen the issue on update_issue",
+				Enum:        []string{"", "close", "reopen"},
+			},
+			"target": {
+				Type:        "string",
+				Title:       "Comment On",
+				Description: "Whether iid refers to an issue or a merge request",
+				Default:     "issue",
+				Enum:        []string{"issue", "merge_request"},
+			},
+			"body": {
+				Type:        "string",
+				Title:       "Comment",
+				Description: "Comment text. Supports template variables",
+				Format:      "textarea",
+			},
+			"source_branch": {
+				Type:        "string",
+				Title:       "Source Branch",
+				Description: "Branch containing the changes",
+			},
+			"target_branch": {
+				Type:        "string",
+				Title:       "Target Branch",
+				Description: "Branch to merge into",
+			},
+			"squash": {
+				Type:        "boolean",
+				Title:       "Squash",
+				Description: "Squash commits when merging",
+				Default:     false,
+			},
+			"remove_source_branch": {
+				Type:        "boolean",
+				Title:       "Remove Source Branch",
+				Description: "Delete the source branch after merging",
+				Default:     false,
+			},
+			"tag_name": {
+				Type:        "string",
+				Title:       "Tag",
+				Description: "Release tag",
+			},
+			"name": {
+				Type:        "string",
+				Title:       "Release Name",
+				Description: "Release title",
+			},
+			"ref": {
+				Type:        "string",
+				Title:       "Ref",
+				Description: "Branch or tag to run the pipeline on, or the commit to tag for a new release",
+			},
+			"variables": {
+				Type:        "object",
+				Title:       "Variables",
+				Description: "Pipeline variables. Values support template variables",
+			},
+			"timeout": {
+				Type:        "number",
+				Title:       "Timeout",
+				Description: "Request timeout in seconds",
+				Default:     30,
+			},
+		},
+		Required: []string{"operation", "project"},
+		Inputs: []engine.PortSchema{
+			{
+				Name:        "input",
+				Type:        "any",
+				Description: "Input data available for template variables",
+				Required:    false,
+			},
+		},
+		Outputs: []engine.PortSchema{
+			{
+				Name:        "output",
+				Type:        "object",
+				Description: "API response {operation, statusCode, data}",
+				Required:    true,
+			},
+		},
+	}
+}
+
+// parseConfig parses the node configuration
+func (n *GitLabNode) parseConfig(config interface{}) (*GitLabConfig, error) {
+	configMap, ok := config.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("invalid config type for gitlab node")
+	}
+
+	configJSON, err := json.Marshal(configMap)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal config: %w", err)
+	}
+
+	var gitlabConfig GitLabConfig
+	if err := json.Unmarshal(configJSON, &gitlabConfig); err != nil {
+		return nil, fmt.Errorf("failed to parse gitlab config: %w", err)
+	}
+
+	// Set defaults
+	if gitlabConfig.BaseURL == "" {
+		gitlabConfig.BaseURL = "https://gitlab.com/api/v4"
+	}
+	if gitlabConfig.Target == "" {
+		gitlabConfig.Target = "issue"
+	}
+	if gitlabConfig.Timeout == 0 {
+		gitlabConfig.Timeout = 30
+	}
+
+	return &gitlabConfig, nil
+}
+
+// issueBody builds the fields shared by issues and merge requests
+func (n *GitLabNode) issueBody(config *GitLabConfig, input interface{}) map[string]interface{} {
+	body := map[string]interface{}{}
+	setIfPresent(body, "title", config.Title, input)
+	setIfPresent(body, "description", config.Description, input)
+	if len(config.Labels) > 0 {
+		body["labels"] = strings.Join(templateList(config.Labels, input), ",")
+	}
+	return body
+}
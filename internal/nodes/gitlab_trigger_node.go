@@ -0,0 +1,112 @@
+package nodes
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/nuumz/f1ow/internal/engine"
+)
+
+// GitLabTriggerNode starts executions for GitLab webhook deliveries that
+// carry the configured secret token
+type GitLabTriggerNode struct {
+	BaseNode
+}
+
+// GitLabTriggerConfig defines configuration for gitlab trigger node
+type GitLabTriggerConfig struct {
+	Secret  string   `json:"secret"`  // compared with the X-Gitlab-Token header
+	Events  []string `json:"events"`  // e.g. "push", "merge_request"; empty accepts all
+	Actions []string `json:"actions"` // e.g. "open", "merge"; empty accepts all
+}
+
+// NewGitLabTriggerNode creates a new gitlab trigger node
+func NewGitLabTriggerNode() engine.NodeType {
+	return &GitLabTriggerNode{
+		BaseNode: BaseNode{
+			nodeType:    "gitlab_trigger",
+			name:        "GitLab Event",
+			description: "Start the workflow when GitLab delivers a webhook event with the secret token",
+			category:    "Triggers",
+			icon:        "gitlab",
+		},
+	}
+}
+
+// Execute passes the received event through to downstream nodes
+func (n *GitLabTriggerNode) Execute(ctx context.Context, config interface{}, input interface{}) (interface{}, error) {
+	if inputMap, ok := input.(map[string]interface{}); ok {
+		return inputMap, nil
+	}
+	return map[string]interface{}{"data": input}, nil
+}
+
+// ValidateConfig validates the node configuration
+func (n *GitLabTriggerNode) ValidateConfig(config interface{}) error {
+	gitlabConfig, err := ParseGitLabTriggerConfig(config)
+	if err != nil {
+		return err
+	}
+
+	if gitlabConfig.Secret == "" {
+		return fmt.Errorf("secret is required")
+	}
+
+	return nil
+}
+
+// GetSchema returns the node configuration schema
+func (n *GitLabTriggerNode) GetSchema() engine.NodeSchema {
+	return engine.NodeSchema{
+		Type: "object",
+		Properties: map[string]engine.Property{
+			"secret": {
+				Type:        "string",
+				Title:       "Secret Token",
+				Description: "Secret token configured on the GitLab webhook; deliveries without it are rejected",
+				Format:      "password",
+			},
+			"events": {
+				Type:        "array",
+				Title:       "Events",
+				Description: "Events to accept: push, tag_push, merge_request, issue, note, release, pipeline. Empty accepts all",
+			},
+			"actions": {
+				Type:        "array",
+				Title:       "Actions",
+				Description: "Event actions to accept, e.g. open, merge, close. Empty accepts all",
+			},
+		},
+		Required: []string{"secret"},
+		Inputs:   []engine.PortSchema{},
+		Outputs: []engine.PortSchema{
+			{
+				Name:        "output",
+				Type:        "object",
+				Description: "Event {provider, event, action, repository, sender, ..., payload}",
+				Required:    true,
+			},
+		},
+	}
+}
+
+// ParseGitLabTriggerConfig parses a gitlab trigger configuration
+func ParseGitLabTriggerConfig(config interface{}) (*GitLabTriggerConfig, error) {
+	configMap, ok := config.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("invalid config type for gitlab trigger node")
+	}
+
+	configJSON, err := json.Marshal(configMap)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal config: %w", err)
+	}
+
+	var gitlabConfig GitLabTriggerConfig
+	if err := json.Unmarshal(configJSON, &gitlabConfig); err != nil {
+		return nil, fmt.Errorf("failed to parse gitlab trigger config: %w", err)
+	}
+
+	return &gitlabConfig, nil
+}
@@ -0,0 +1,431 @@
+package nodes
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/nuumz/f1ow/internal/engine"
+	"github.com/nuumz/f1ow/internal/netpolicy"
+
+	"github.com/jhump/protoreflect/desc"
+	"github.com/jhump/protoreflect/desc/protoparse"
+	"github.com/jhump/protoreflect/grpcreflect"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// GRPCNode calls unary gRPC methods using dynamic message descriptors
+type GRPCNode struct {
+	BaseNode
+}
+
+// GRPCConfig defines configuration for grpc node
+type GRPCConfig struct {
+	Address         string            `json:"address"` // host:port
+	Method          string            `json:"method"`  // package.Service/Method
+	Request         interface{}       `json:"request"`
+	Metadata        map[string]string `json:"metadata"`
+	DescriptorMode  string            `json:"descriptor_mode"` // "reflection", "proto", "descriptor_set"
+	ProtoFiles      map[string]string `json:"proto_files"`     // file name -> .proto source
+	DescriptorSet   string            `json:"descriptor_set"`  // base64 encoded FileDescriptorSet
+	UseTLS          bool              `json:"use_tls"`
+	ServerName      string            `json:"server_name"`
+	CACert          string            `json:"ca_cert"`
+	ClientCert      string            `json:"client_cert"`
+	ClientKey       string            `json:"client_key"`
+	IgnoreSSLIssues bool              `json:"ignore_ssl_issues"`
+	Deadline        int               `json:"deadline"` // seconds
+}
+
+// NewGRPCNode creates a new grpc node
+func NewGRPCNode() engine.NodeType {
+	return &GRPCNode{
+		BaseNode: BaseNode{
+			nodeType:    "grpc",
+			name:        "gRPC Request",
+			description: "Call unary gRPC methods using server reflection or stored .proto descriptors",
+			category:    "Network",
+			icon:        "server",
+		},
+	}
+}
+
+// Execute invokes the configured gRPC method
+func (n *GRPCNode) Execute(ctx context.Context, config interface{}, input interface{}) (interface{}, error) {
+	grpcConfig, err := n.parseConfig(config)
+	if err != nil {
+		return nil, err
+	}
+
+	serviceName, methodName, err := splitGRPCMethod(processTemplate(grpcConfig.Method, input))
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(grpcConfig.Deadline)*time.Second)
+	defer cancel()
+
+	creds, err := n.transportCredentials(grpcConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	options := []grpc.DialOption{grpc.WithTransportCredentials(creds)}
+	if _, ok := netpolicy.FromContext(ctx); ok {
+		dial := Dialer(ctx)
+		options = append(options, grpc.WithContextDialer(func(dialCtx context.Context, address string) (net.Conn, error) {
+			return dial(dialCtx, "tcp", address)
+		}))
+	}
+	conn, err := grpc.DialContext(ctx, processTemplate(grpcConfig.Address, input), options...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to %s: %w", grpcConfig.Address, err)
+	}
+	defer conn.Close()
+
+	method, err := n.resolveMethod(ctx, grpcConfig, conn, serviceName, methodName)
+	if err != nil {
+		return nil, err
+	}
+
+	if method.IsClientStreaming() || method.IsServerStreaming() {
+		return nil, fmt.Errorf("streaming method %s is not supported", method.GetFullyQualifiedName())
+	}
+
+	request := dynamicpb.NewMessage(method.GetInputType().UnwrapMessage())
+	requestJSON, err := json.Marshal(interpolateValue(grpcConfig.Request, input))
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+	if string(requestJSON) != "null" {
+		if err := protojson.Unmarshal(requestJSON, request); err != nil {
+			return nil, fmt.Errorf("request does not match %s: %w", method.GetInputType().GetFullyQualifiedName(), err)
+		}
+	}
+
+	if len(grpcConfig.Metadata) > 0 {
+		pairs := make([]string, 0, len(grpcConfig.Metadata)*2)
+		for key, value := range grpcConfig.Metadata {
+			pairs = append(pairs, strings.ToLower(key), processTemplate(value, input))
+		}
+		ctx = metadata.AppendToOutgoingContext(ctx, pairs...)
+	}
+
+	response := dynamicpb.NewMessage(method.GetOutputType().UnwrapMessage())
+	var header, trailer metadata.MD
+
+	fullMethod := "/" + serviceName + "/" + methodName
+	if err := conn.Invoke(ctx, fullMethod, request, response, grpc.Header(&header), grpc.Trailer(&trailer)); err != nil {
+		st := status.Convert(err)
+		return nil, fmt.Errorf("grpc call failed with %s: %s", st.Code(), st.Message())
+	}
+
+	responseJSON, err := protojson.MarshalOptions{UseProtoNames: true, EmitUnpopulated: true}.Marshal(response)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal response: %w", err)
+	}
+
+	var body interface{}
+	if err := json.Unmarshal(responseJSON, &body); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return map[string]interface{}{
+		"response": body,
+		"headers":  metadataToMap(header),
+		"trailers": metadataToMap(trailer),
+	}, nil
+}
+
+// ValidateConfig validates the node configuration
+func (n *GRPCNode) ValidateConfig(config interface{}) error {
+	grpcConfig, err := n.parseConfig(config)
+	if err != nil {
+		return err
+	}
+
+	if grpcConfig.Address == "" {
+		return fmt.Errorf("address is required")
+	}
+
+	if _, _, err := splitGRPCMethod(grpcConfig.Method); err != nil {
+		return err
+	}
+
+	switch grpcConfig.DescriptorMode {
+	case "reflection":
+	case "proto":
+		if len(grpcConfig.ProtoFiles) == 0 {
+			return fmt.Errorf("proto_files is required for proto descriptor mode")
+		}
+	case "descriptor_set":
+		if grpcConfig.DescriptorSet == "" {
+			return fmt.Errorf("descriptor_set is required for descriptor_set mode")
+		}
+	default:
+		return fmt.Errorf("invalid descriptor_mode: %s", grpcConfig.DescriptorMode)
+	}
+
+	if grpcConfig.UseTLS {
+		if _, err := buildTLSConfig(grpcConfig.CACert, grpcConfig.ClientCert, grpcConfig.ClientKey, grpcConfig.IgnoreSSLIssues); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// GetSchema returns the node configuration schema
+func (n *GRPCNode) GetSchema() engine.NodeSchema {
+	return engine.NodeSchema{
+		Type: "object",
+		Properties: map[string]engine.Property{
+			"address": {
+				Type:        "string",
+				Title:       "Address",
+				Description: "Server address as host:port",
+			},
+			"method": {
+				Type:        "string",
+				Title:       "Method",
+				Description: "Fully qualified method, e.g. helloworld.Greeter/SayHello",
+			},
+			"request": {
+				Type:        "object",
+				Title:       "Request",
+				Description: "Request message as JSON. Supports template variables like {{variable}}",
+			},
+			"metadata": {
+				Type:        "object",
+				Title:       "Metadata",
+				Description: "Request metadata headers. Supports template variables",
+			},
+			"descriptor_mode": {
+				Type:        "string",
+				Title:       "Descriptor Source",
+				Description: "Where service definitions come from",
+				Default:     "reflection",
+				Enum:        []string{"reflection", "proto", "descriptor_set"},
+			},
+			"proto_files": {
+				Type:        "object",
+				Title:       "Proto Files",
+				Description: "Map of file name to .proto source, including imported files",
+			},
+			"descriptor_set": {
+				Type:        "string",
+				Title:       "Descriptor Set",
+				Description: "Base64 encoded FileDescriptorSet (protoc --descriptor_set_out --include_imports)",
+				Format:      "textarea",
+			},
+			"use_tls": {
+				Type:        "boolean",
+				Title:       "Use TLS",
+				Description: "Connect over TLS",
+				Default:     false,
+			},
+			"ca_cert": {
+				Type:        "string",
+				Title:       "CA Certificate",
+				Description: "PEM encoded CA certificate used to verify the server",
+				Format:      "textarea",
+			},
+			"client_cert": {
+				Type:        "string",
+				Title:       "Client Certificate",
+				Description: "PEM encoded client certificate for mutual TLS",
+				Format:      "textarea",
+			},
+			"client_key": {
+				Type:        "string",
+				Title:       "Client Key",
+				Description: "PEM encoded client private key for mutual TLS",
+				Format:      "password",
+			},
+			"deadline": {
+				Type:        "number",
+				Title:       "Deadline",
+				Description: "Call deadline in seconds",
+				Default:     30,
+			},
+		},
+		Required: []string{"address", "method"},
+		Inputs: []engine.PortSchema{
+			{
+				Name:        "input",
+				Type:        "any",
+				Description: "Input data available for template variables",
+				Required:    false,
+			},
+		},
+		Outputs: []engine.PortSchema{
+			{
+				Name:        "output",
+				Type:        "object",
+				Description: "Response message with headers and trailers",
+				Required:    true,
+			},
+		},
+	}
+}
+
+// parseConfig parses the node configuration
+func (n *GRPCNode) parseConfig(config interface{}) (*GRPCConfig, error) {
+	configMap, ok := config.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("invalid config type for grpc node")
+	}
+
+	configJSON, err := json.Marshal(configMap)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal config: %w", err)
+	}
+
+	var grpcConfig GRPCConfig
+	if err := json.Unmarshal(configJSON, &grpcConfig); err != nil {
+		return nil, fmt.Errorf("failed to parse grpc config: %w", err)
+	}
+
+	// Set defaults
+	if grpcConfig.DescriptorMode == "" {
+		grpcConfig.DescriptorMode = "reflection"
+	}
+	if grpcConfig.Deadline == 0 {
+		grpcConfig.Deadline = 30
+	}
+
+	return &grpcConfig, nil
+}
+
+// transportCredentials returns TLS or plaintext credentials
+func (n *GRPCNode) transportCredentials(config *GRPCConfig) (credentials.TransportCredentials, error) {
+	if !config.UseTLS {
+		return insecure.NewCredentials(), nil
+	}
+
+	tlsConfig, err := buildTLSConfig(config.CACert, config.ClientCert, config.ClientKey, config.IgnoreSSLIssues)
+	if err != nil {
+		return nil, err
+	}
+	tlsConfig.ServerName = config.ServerName
+
+	return credentials.NewTLS(tlsConfig), nil
+}
+
+// resolveMethod finds the method descriptor from the configured source
+func (n *GRPCNode) resolveMethod(ctx context.Context, config *GRPCConfig, conn *grpc.ClientConn, serviceName, methodName string) (*desc.MethodDescriptor, error) {
+	var service *desc.ServiceDescriptor
+
+	switch config.DescriptorMode {
+	case "reflection":
+		client := grpcreflect.NewClientAuto(ctx, conn)
+		defer client.Reset()
+
+		var err error
+		service, err = client.ResolveService(serviceName)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve service %s via reflection: %w", serviceName, err)
+		}
+
+	case "proto", "descriptor_set":
+		files, err := n.loadDescriptors(config)
+		if err != nil {
+			return nil, err
+		}
+		for _, file := range files {
+			if sd, ok := file.FindSymbol(serviceName).(*desc.ServiceDescriptor); ok {
+				service = sd
+				break
+			}
+		}
+		if service == nil {
+			return nil, fmt.Errorf("service %s not found in descriptors", serviceName)
+		}
+
+	default:
+		return nil, fmt.Errorf("invalid descriptor_mode: %s", config.DescriptorMode)
+	}
+
+	method := service.FindMethodByName(methodName)
+	if method == nil {
+		return nil, fmt.Errorf("method %s not found in service %s", methodName, serviceName)
+	}
+
+	return method, nil
+}
+
+// loadDescriptors parses stored .proto sources or a FileDescriptorSet
+func (n *GRPCNode) loadDescriptors(config *GRPCConfig) ([]*desc.FileDescriptor, error) {
+	if config.DescriptorMode == "descriptor_set" {
+		data, err := base64.StdEncoding.DecodeString(config.DescriptorSet)
+		if err != nil {
+			return nil, fmt.Errorf("invalid descriptor_set encoding: %w", err)
+		}
+
+		var set descriptorpb.FileDescriptorSet
+		if err := proto.Unmarshal(data, &set); err != nil {
+			return nil, fmt.Errorf("invalid descriptor_set: %w", err)
+		}
+
+		files, err := desc.CreateFileDescriptorsFromSet(&set)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load descriptor_set: %w", err)
+		}
+
+		result := make([]*desc.FileDescriptor, 0, len(files))
+		for _, file := range files {
+			result = append(result, file)
+		}
+		return result, nil
+	}
+
+	names := make([]string, 0, len(config.ProtoFiles))
+	for name := range config.ProtoFiles {
+		names = append(names, name)
+	}
+
+	parser := protoparse.Parser{
+		Accessor: protoparse.FileContentsFromMap(config.ProtoFiles),
+	}
+	files, err := parser.ParseFiles(names...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse proto files: %w", err)
+	}
+
+	return files, nil
+}
+
+// splitGRPCMethod splits "package.Service/Method" into service and method
+func splitGRPCMethod(fullMethod string) (string, string, error) {
+	fullMethod = strings.TrimPrefix(fullMethod, "/")
+	idx := strings.LastIndex(fullMethod, "/")
+	if idx <= 0 || idx == len(fullMethod)-1 {
+		return "", "", fmt.Errorf("method must be in the form package.Service/Method")
+	}
+	return fullMethod[:idx], fullMethod[idx+1:], nil
+}
+
+// metadataToMap converts gRPC metadata into a plain map
+func metadataToMap(md metadata.MD) map[string]interface{} {
+	result := make(map[string]interface{}, len(md))
+	for key, values := range md {
+		if len(values) == 1 {
+			result[key] = values[0]
+		} else {
+			result[key] = values
+		}
+	}
+	return result
+}
@@ -0,0 +1,218 @@
+package nodes
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/textproto"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/nuumz/f1ow/internal/binarydata"
+)
+
+// HTTP body types
+const (
+	httpBodyJSON           = "json"
+	httpBodyFormURLEncoded = "form_urlencoded"
+	httpBodyMultipart      = "multipart"
+	httpBodyRaw            = "raw"
+	httpBodyBinary         = "binary"
+)
+
+// validHTTPBodyTypes lists the supported body_type values
+var validHTTPBodyTypes = []string{httpBodyJSON, httpBodyFormURLEncoded, httpBodyMultipart, httpBodyRaw, httpBodyBinary}
+
+// buildRequestBody encodes the configured body for the body type and returns
+// the reader together with the Content-Type to send
+func buildRequestBody(ctx context.Context, config *HTTPConfig, input interface{}) (io.Reader, string, error) {
+	switch config.BodyType {
+	case "", httpBodyJSON:
+		processedBody := interpolateValue(config.Body, input)
+		jsonBody, err := json.Marshal(processedBody)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to marshal body: %w", err)
+		}
+		return bytes.NewReader(jsonBody), "application/json", nil
+
+	case httpBodyFormURLEncoded:
+		fields, err := bodyFields(config.Body, input)
+		if err != nil {
+			return nil, "", err
+		}
+		values := url.Values{}
+		for key, value := range fields {
+			for _, v := range formValues(value) {
+				values.Add(key, v)
+			}
+		}
+		return strings.NewReader(values.Encode()), "application/x-www-form-urlencoded", nil
+
+	case httpBodyMultipart:
+		return buildMultipartBody(ctx, config.Body, input)
+
+	case httpBodyRaw:
+		var raw string
+		switch v := config.Body.(type) {
+		case string:
+			raw = processTemplate(v, input)
+		default:
+			encoded, err := json.Marshal(interpolateValue(v, input))
+			if err != nil {
+				return nil, "", fmt.Errorf("failed to marshal body: %w", err)
+			}
+			raw = string(encoded)
+		}
+		return strings.NewReader(raw), "text/plain; charset=utf-8", nil
+
+	case httpBodyBinary:
+		value := resolveContent(config.Body, input)
+		content, err := readBinary(ctx, value)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to read binary body: %w", err)
+		}
+		return bytes.NewReader(content), binaryMimeType(value), nil
+
+	default:
+		return nil, "", fmt.Errorf("unsupported body type: %s", config.BodyType)
+	}
+}
+
+// buildMultipartBody encodes body fields as multipart/form-data. Fields whose
+// value is binary content (a binary data handle or base64 payload) become
+// file parts; everything else becomes a form field.
+func buildMultipartBody(ctx context.Context, body interface{}, input interface{}) (io.Reader, string, error) {
+	fields, err := bodyFields(body, input)
+	if err != nil {
+		return nil, "", err
+	}
+
+	// Write fields in a stable order so retries send identical bodies
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var buf bytes.Buffer
+	writer := multipart.NewWriter(&buf)
+	for _, key := range keys {
+		value := fields[key]
+		if isBinaryValue(value) {
+			if err := writeFilePart(ctx, writer, key, value); err != nil {
+				return nil, "", err
+			}
+			continue
+		}
+		for _, v := range formValues(value) {
+			if err := writer.WriteField(key, v); err != nil {
+				return nil, "", fmt.Errorf("failed to write form field %s: %w", key, err)
+			}
+		}
+	}
+	if err := writer.Close(); err != nil {
+		return nil, "", fmt.Errorf("failed to finish multipart body: %w", err)
+	}
+
+	return &buf, writer.FormDataContentType(), nil
+}
+
+// writeFilePart copies binary content into a multipart file part
+func writeFilePart(ctx context.Context, writer *multipart.Writer, field string, value interface{}) error {
+	reader, err := openBinary(ctx, value)
+	if err != nil {
+		return fmt.Errorf("failed to read file for field %s: %w", field, err)
+	}
+	defer reader.Close()
+
+	fileName := field
+	if payload, ok := value.(map[string]interface{}); ok {
+		if name, ok := payload["file_name"].(string); ok && name != "" {
+			fileName = name
+		}
+	}
+
+	header := make(textproto.MIMEHeader)
+	header.Set("Content-Disposition", fmt.Sprintf(`form-data; name="%s"; filename="%s"`, escapeQuotes(field), escapeQuotes(fileName)))
+	header.Set("Content-Type", binaryMimeType(value))
+
+	part, err := writer.CreatePart(header)
+	if err != nil {
+		return fmt.Errorf("failed to create file part %s: %w", field, err)
+	}
+	if _, err := io.Copy(part, reader); err != nil {
+		return fmt.Errorf("failed to write file part %s: %w", field, err)
+	}
+	return nil
+}
+
+// bodyFields resolves templates in the body and requires it to be an object
+func bodyFields(body interface{}, input interface{}) (map[string]interface{}, error) {
+	fields, ok := body.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("form bodies must be an object of fields")
+	}
+
+	resolved := make(map[string]interface{}, len(fields))
+	for key, value := range fields {
+		resolved[key] = resolveContent(value, input)
+	}
+	return resolved, nil
+}
+
+// formValues converts a field value to its form representation; arrays
+// produce one value per element
+func formValues(value interface{}) []string {
+	switch v := value.(type) {
+	case nil:
+		return []string{""}
+	case string:
+		return []string{v}
+	case []interface{}:
+		values := make([]string, 0, len(v))
+		for _, item := range v {
+			values = append(values, formValues(item)...)
+		}
+		return values
+	case map[string]interface{}:
+		encoded, _ := json.Marshal(v)
+		return []string{string(encoded)}
+	default:
+		return []string{fmt.Sprintf("%v", v)}
+	}
+}
+
+// isBinaryValue reports whether a value is a binary data handle or a base64
+// {data, ...} payload
+func isBinaryValue(value interface{}) bool {
+	if _, ok := binarydata.IDFromValue(value); ok {
+		return true
+	}
+	payload, ok := value.(map[string]interface{})
+	if !ok {
+		return false
+	}
+	_, hasData := payload["data"].(string)
+	_, hasMime := payload["mime_type"]
+	return hasData && hasMime
+}
+
+// binaryMimeType returns the MIME type recorded on binary content
+func binaryMimeType(value interface{}) string {
+	if payload, ok := value.(map[string]interface{}); ok {
+		if mimeType, ok := payload["mime_type"].(string); ok && mimeType != "" {
+			return mimeType
+		}
+	}
+	return "application/octet-stream"
+}
+
+var quoteEscaper = strings.NewReplacer("\\", "\\\\", `"`, "\\\"")
+
+func escapeQuotes(s string) string {
+	return quoteEscaper.Replace(s)
+}
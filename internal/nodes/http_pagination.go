@@ -0,0 +1,237 @@
+package nodes
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// defaultMaxPages bounds pagination when max_pages is not configured
+const defaultMaxPages = 100
+
+// HTTPPagination defines how the HTTP node follows paginated responses
+type HTTPPagination struct {
+	Type          string `json:"type"`            // "cursor", "page", "link_header"
+	ItemsPath     string `json:"items_path"`      // dot path to the item array; empty means the body itself
+	CursorPath    string `json:"cursor_path"`     // dot path to the next cursor (cursor)
+	CursorParam   string `json:"cursor_param"`    // query parameter carrying the cursor (cursor)
+	PageParam     string `json:"page_param"`      // query parameter carrying the page number (page)
+	StartPage     int    `json:"start_page"`      // first page number (page)
+	PageSize      int    `json:"page_size"`       // items requested per page (page)
+	PageSizeParam string `json:"page_size_param"` // query parameter carrying the page size (page)
+	MaxPages      int    `json:"max_pages"`
+}
+
+// validate checks the pagination settings for the selected strategy
+func (p *HTTPPagination) validate() error {
+	switch p.Type {
+	case "", "none", "link_header":
+	case "cursor":
+		if p.CursorPath == "" || p.CursorParam == "" {
+			return fmt.Errorf("cursor pagination requires cursor_path and cursor_param")
+		}
+	case "page":
+		if p.PageSize > 0 && p.PageSizeParam == "" {
+			return fmt.Errorf("page pagination with page_size requires page_size_param")
+		}
+	default:
+		return fmt.Errorf("unsupported pagination type: %s", p.Type)
+	}
+	if p.MaxPages < 0 {
+		return fmt.Errorf("max_pages must not be negative")
+	}
+	return nil
+}
+
+// executePaginated requests pages until the API reports no further pages or
+// max_pages is reached, aggregating the items found at items_path
+func (n *HTTPNode) executePaginated(ctx context.Context, client *http.Client, config *HTTPConfig, requestURL string, input interface{}) (interface{}, error) {
+	pagination := config.Pagination
+	if err := pagination.validate(); err != nil {
+		return nil, err
+	}
+
+	maxPages := pagination.MaxPages
+	if maxPages == 0 {
+		maxPages = defaultMaxPages
+	}
+	page := pagination.StartPage
+	if page == 0 {
+		page = 1
+	}
+	pageParam := pagination.PageParam
+	if pageParam == "" {
+		pageParam = "page"
+	}
+
+	pageConfig := config
+	items := make([]interface{}, 0)
+	var cursor string
+	var lastResp *http.Response
+	pages := 0
+
+	for pages < maxPages {
+		extraQuery := map[string]string{}
+		switch pagination.Type {
+		case "cursor":
+			if cursor != "" {
+				extraQuery[pagination.CursorParam] = cursor
+			}
+		case "page":
+			extraQuery[pageParam] = strconv.Itoa(page)
+			if pagination.PageSize > 0 {
+				extraQuery[pagination.PageSizeParam] = strconv.Itoa(pagination.PageSize)
+			}
+		}
+
+		resp, _, err := n.sendRequest(ctx, client, pageConfig, requestURL, extraQuery, input)
+		if err != nil {
+			return nil, err
+		}
+		reader, _, err := responseBody(resp)
+		if err != nil {
+			resp.Body.Close()
+			return nil, err
+		}
+		body, err := io.ReadAll(reader)
+		reader.Close()
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read response body: %w", err)
+		}
+		pages++
+		lastResp = resp
+
+		if resp.StatusCode >= 300 {
+			return nil, fmt.Errorf("page %d returned status %d: %s", pages, resp.StatusCode, string(body))
+		}
+
+		var decoded interface{}
+		if err := json.Unmarshal(body, &decoded); err != nil {
+			return nil, fmt.Errorf("failed to parse page %d as JSON: %w", pages, err)
+		}
+
+		pageItems, err := paginationItems(decoded, pagination.ItemsPath)
+		if err != nil {
+			return nil, fmt.Errorf("page %d: %w", pages, err)
+		}
+		items = append(items, pageItems...)
+
+		switch pagination.Type {
+		case "cursor":
+			cursor = paginationCursor(decoded, pagination.CursorPath)
+			if cursor == "" || len(pageItems) == 0 {
+				return paginatedResult(lastResp, items, pages, false), nil
+			}
+		case "page":
+			if len(pageItems) == 0 || (pagination.PageSize > 0 && len(pageItems) < pagination.PageSize) {
+				return paginatedResult(lastResp, items, pages, false), nil
+			}
+			page++
+		case "link_header":
+			next := nextLink(resp.Header.Values("Link"))
+			if next == "" {
+				return paginatedResult(lastResp, items, pages, false), nil
+			}
+			resolved, err := resp.Request.URL.Parse(next)
+			if err != nil {
+				return nil, fmt.Errorf("invalid next link %q: %w", next, err)
+			}
+			requestURL = resolved.String()
+			// The next link already carries the query string
+			if pageConfig == config {
+				copied := *config
+				copied.QueryParams = nil
+				pageConfig = &copied
+			}
+		}
+	}
+
+	return paginatedResult(lastResp, items, pages, true), nil
+}
+
+// paginatedResult builds the node output for a paginated request from the
+// last page's response
+func paginatedResult(resp *http.Response, items []interface{}, pages int, truncated bool) map[string]interface{} {
+	return map[string]interface{}{
+		"statusCode": resp.StatusCode,
+		"status":     resp.Status,
+		"headers":    resp.Header,
+		"body":       items,
+		"bodyType":   "json",
+		"count":      len(items),
+		"pages":      pages,
+		"truncated":  truncated,
+	}
+}
+
+// paginationItems extracts the item array of one page
+func paginationItems(body interface{}, itemsPath string) ([]interface{}, error) {
+	value := body
+	if itemsPath != "" {
+		bodyMap, ok := body.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("response body is not an object, cannot read items_path %q", itemsPath)
+		}
+		value = getValueByPath(bodyMap, itemsPath)
+	}
+
+	switch v := value.(type) {
+	case nil:
+		return nil, nil
+	case []interface{}:
+		return v, nil
+	default:
+		return nil, fmt.Errorf("items at %q are not an array", itemsPath)
+	}
+}
+
+// paginationCursor reads the next cursor from a page, treating missing,
+// null, empty, and false values as the end of the results
+func paginationCursor(body interface{}, cursorPath string) string {
+	bodyMap, ok := body.(map[string]interface{})
+	if !ok {
+		return ""
+	}
+	switch v := getValueByPath(bodyMap, cursorPath).(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case bool:
+		return ""
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+}
+
+// nextLink returns the rel="next" target of RFC 8288 Link headers
+func nextLink(headers []string) string {
+	for _, header := range headers {
+		for _, link := range strings.Split(header, ",") {
+			segments := strings.Split(link, ";")
+			target := strings.TrimSpace(segments[0])
+			if !strings.HasPrefix(target, "<") || !strings.HasSuffix(target, ">") {
+				continue
+			}
+			for _, param := range segments[1:] {
+				key, value, found := strings.Cut(strings.TrimSpace(param), "=")
+				if !found || !strings.EqualFold(strings.TrimSpace(key), "rel") {
+					continue
+				}
+				for _, rel := range strings.Fields(strings.Trim(strings.TrimSpace(value), `"`)) {
+					if strings.EqualFold(rel, "next") {
+						return target[1 : len(target)-1]
+					}
+				}
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,181 @@
+package nodes
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"io"
+	"math"
+	"math/rand"
+	"net"
+	"net/http"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+// Network error classes accepted by retry_on_errors
+const (
+	retryErrorTimeout    = "timeout"
+	retryErrorConnection = "connection"
+	retryErrorDNS        = "dns"
+	retryErrorTLS        = "tls"
+	retryErrorAny        = "any"
+)
+
+var (
+	defaultRetryStatuses     = []int{408, 429, 500, 502, 503, 504}
+	defaultRetryErrorKinds   = []string{retryErrorTimeout, retryErrorConnection, retryErrorDNS}
+	validRetryErrorKinds     = []string{retryErrorTimeout, retryErrorConnection, retryErrorDNS, retryErrorTLS, retryErrorAny}
+	defaultRetryMaxDelay     = 60 * time.Second
+	defaultRetryInitialDelay = time.Second
+)
+
+// retryPolicy decides whether and when a failed attempt is retried
+type retryPolicy struct {
+	attempts     int
+	backoff      string // "exponential" or "fixed"
+	initialDelay time.Duration
+	maxDelay     time.Duration
+	statuses     map[int]bool
+	errorKinds   map[string]bool
+}
+
+// newRetryPolicy builds the retry policy from the HTTP node config
+func newRetryPolicy(config *HTTPConfig) *retryPolicy {
+	policy := &retryPolicy{
+		attempts:     config.RetryCount,
+		backoff:      config.RetryBackoff,
+		initialDelay: time.Duration(config.RetryDelay) * time.Second,
+		maxDelay:     time.Duration(config.RetryMaxDelay) * time.Second,
+		statuses:     make(map[int]bool),
+		errorKinds:   make(map[string]bool),
+	}
+	if policy.attempts <= 0 {
+		policy.attempts = 1
+	}
+	if policy.backoff == "" {
+		policy.backoff = "exponential"
+	}
+	if policy.initialDelay <= 0 {
+		policy.initialDelay = defaultRetryInitialDelay
+	}
+	if policy.maxDelay <= 0 {
+		policy.maxDelay = defaultRetryMaxDelay
+	}
+
+	statuses := config.RetryOnStatus
+	if len(statuses) == 0 {
+		statuses = defaultRetryStatuses
+	}
+	for _, status := range statuses {
+		policy.statuses[status] = true
+	}
+
+	kinds := config.RetryOnErrors
+	if len(kinds) == 0 {
+		kinds = defaultRetryErrorKinds
+	}
+	for _, kind := range kinds {
+		policy.errorKinds[kind] = true
+	}
+
+	return policy
+}
+
+// retryableError reports whether a transport error belongs to a retryable class
+func (p *retryPolicy) retryableError(err error) bool {
+	if p.errorKinds[retryErrorAny] {
+		return true
+	}
+	kind := classifyNetworkError(err)
+	return kind != "" && p.errorKinds[kind]
+}
+
+// retryableStatus reports whether a response status is retryable
+func (p *retryPolicy) retryableStatus(status int) bool {
+	return p.statuses[status]
+}
+
+// delay returns how long to wait before the next attempt. attempt is the
+// number of attempts made so far. A Retry-After header on the response takes
+// precedence over the backoff, capped at the max delay.
+func (p *retryPolicy) delay(attempt int, resp *http.Response) time.Duration {
+	if resp != nil {
+		if wait, ok := parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()); ok {
+			if wait > p.maxDelay {
+				return p.maxDelay
+			}
+			return wait
+		}
+	}
+
+	if p.backoff == "fixed" {
+		return p.initialDelay
+	}
+
+	// Exponential backoff with full jitter
+	backoff := float64(p.initialDelay) * math.Pow(2, float64(attempt-1))
+	if backoff > float64(p.maxDelay) {
+		backoff = float64(p.maxDelay)
+	}
+	return time.Duration(rand.Int63n(int64(backoff)) + 1)
+}
+
+// classifyNetworkError maps a transport error to a retry_on_errors class
+func classifyNetworkError(err error) string {
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		return retryErrorDNS
+	}
+
+	var certErr *tls.CertificateVerificationError
+	var recordErr tls.RecordHeaderError
+	if errors.As(err, &certErr) || errors.As(err, &recordErr) {
+		return retryErrorTLS
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() || errors.Is(err, context.DeadlineExceeded) {
+		return retryErrorTimeout
+	}
+
+	var opErr *net.OpError
+	if errors.As(err, &opErr) || errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, syscall.ECONNREFUSED) || errors.Is(err, syscall.ECONNRESET) {
+		return retryErrorConnection
+	}
+	return ""
+}
+
+// parseRetryAfter parses a Retry-After header given in seconds or as an
+// HTTP date
+func parseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		wait := date.Sub(now)
+		if wait < 0 {
+			wait = 0
+		}
+		return wait, true
+	}
+	return 0, false
+}
+
+// sleepContext waits for the duration or until the context is done
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,442 @@
+package nodes
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/nuumz/f1ow/internal/engine"
+)
+
+// JiraNode creates, updates, transitions, comments on, and searches Jira issues
+type JiraNode struct {
+	BaseNode
+}
+
+// JiraConfig defines configuration for jira node
+type JiraConfig struct {
+	Operation string `json:"operation"`
+	BaseURL   string `json:"base_url"`  // e.g. https://example.atlassian.net
+	AuthType  string `json:"auth_type"` // "basic" (email + API token), "bearer" (personal access token)
+	Email     string `json:"email"`
+	APIToken  string `json:"api_token"`
+
+	CredentialID string `json:"credential_id"` // vault credential supplying email and api_token
+
+	IssueKey    string                 `json:"issue_key"`
+	ProjectKey  string                 `json:"project_key"`
+	IssueType   string                 `json:"issue_type"`
+	Summary     string                 `json:"summary"`
+	Description string                 `json:"description"`
+	Priority    string                 `json:"priority"`
+	Labels      []string               `json:"labels"`
+	Assignee    string                 `json:"assignee"` // account ID
+	Fields      map[string]interface{} `json:"fields"`   // additional or custom fields
+
+	Transition string `json:"transition"` // transition name or ID
+	Comment    string `json:"comment"`
+
+	JQL          string   `json:"jql"`
+	MaxResults   int      `json:"max_results"`
+	StartAt      int      `json:"start_at"`
+	SearchFields []string `json:"search_fields"` // fields returned by search
+
+	Timeout int `json:"timeout"` // seconds
+}
+
+var validJiraOperations = map[string]bool{
+	"create_issue": true, "update_issue": true, "get_issue": true,
+	"transition_issue": true, "add_comment": true, "search": true,
+}
+
+// NewJiraNode creates a new jira node
+func NewJiraNode() engine.NodeType {
+	return &JiraNode{
+		BaseNode: BaseNode{
+			nodeType:    "jira",
+			name:        "Jira",
+			description: "Create, update, transition, comment on, and search Jira issues",
+			category:    "Ticketing",
+			icon:        "ticket",
+		},
+	}
+}
+
+// Execute calls the Jira REST API
+func (n *JiraNode) Execute(ctx context.Context, config interface{}, input interface{}) (interface{}, error) {
+	jiraConfig, err := n.parseConfig(config)
+	if err != nil {
+		return nil, err
+	}
+
+	apiURL := strings.TrimRight(processTemplate(jiraConfig.BaseURL, input), "/") + "/rest/api/2"
+	issuePath := apiURL + "/issue/" + url.PathEscape(processTemplate(jiraConfig.IssueKey, input))
+	headers := map[string]string{
+		"Accept":        "application/json",
+		"Authorization": n.authorization(jiraConfig, input),
+	}
+	client := guardClient(ctx, &http.Client{Timeout: time.Duration(jiraConfig.Timeout) * time.Second})
+
+	var response interface{}
+	var status int
+
+	switch jiraConfig.Operation {
+	case "create_issue":
+		fields := n.issueFields(jiraConfig, input)
+		fields["project"] = map[string]interface{}{"key": processTemplate(jiraConfig.ProjectKey, input)}
+		fields["issuetype"] = map[string]interface{}{"name": processTemplate(jiraConfig.IssueType, input)}
+		response, status, err = restRequest(ctx, client, http.MethodPost, apiURL+"/issue", headers,
+			map[string]interface{}{"fields": fields})
+	case "update_issue":
+		response, status, err = restRequest(ctx, client, http.MethodPut, issuePath, headers,
+			map[string]interface{}{"fields": n.issueFields(jiraConfig, input)})
+	case "get_issue":
+		response, status, err = restRequest(ctx, client, http.MethodGet, issuePath, headers, nil)
+	case "transition_issue":
+		response, status, err = n.transition(ctx, client, issuePath, headers, jiraConfig, input)
+	case "add_comment":
+		response, status, err = restRequest(ctx, client, http.MethodPost, issuePath+"/comment", headers,
+			map[string]interface{}{"body": processTemplate(jiraConfig.Comment, input)})
+	case "search":
+		body := map[string]interface{}{
+			"jql":        processTemplate(jiraConfig.JQL, input),
+			"maxResults": jiraConfig.MaxResults,
+			"startAt":    jiraConfig.StartAt,
+		}
+		if len(jiraConfig.SearchFields) > 0 {
+			body["fields"] = jiraConfig.SearchFields
+		}
+		response, status, err = restRequest(ctx, client, http.MethodPost, apiURL+"/search", headers, body)
+	default:
+		return nil, fmt.Errorf("unsupported operation: %s", jiraConfig.Operation)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("jira %s failed: %w", jiraConfig.Operation, err)
+	}
+
+	return map[string]interface{}{
+		"operation":  jiraConfig.Operation,
+		"statusCode": status,
+		"data":       response,
+	}, nil
+}
+
+// ValidateConfig validates the node configuration
+func (n *JiraNode) ValidateConfig(config interface{}) error {
+	jiraConfig, err := n.parseConfig(config)
+	if err != nil {
+		return err
+	}
+
+	if !validJiraOperations[jiraConfig.Operation] {
+		return fmt.Errorf("invalid operation: %s", jiraConfig.Operation)
+	}
+	if jiraConfig.BaseURL == "" {
+		return fmt.Errorf("base_url is required")
+	}
+	if jiraConfig.APIToken == "" && jiraConfig.CredentialID == "" {
+		return fmt.Errorf("api_token or credential_id is required")
+	}
+
+	switch jiraConfig.AuthType {
+	case "basic":
+		if jiraConfig.Email == "" && jiraConfig.CredentialID == "" {
+			return fmt.Errorf("email is required for basic auth")
+		}
+	case "bearer":
+	default:
+		return fmt.Errorf("invalid auth_type: %s", jiraConfig.AuthType)
+	}
+
+	switch jiraConfig.Operation {
+	case "create_issue":
+		if jiraConfig.ProjectKey == "" || jiraConfig.Summary == "" {
+			return fmt.Errorf("project_key and summary are required for create_issue")
+		}
+	case "update_issue", "get_issue":
+		if jiraConfig.IssueKey == "" {
+			return fmt.Errorf("issue_key is required for %s", jiraConfig.Operation)
+		}
+	case "transition_issue":
+		if jiraConfig.IssueKey == "" || jiraConfig.Transition == "" {
+			return fmt.Errorf("issue_key and transition are required for transition_issue")
+		}
+	case "add_comment":
+		if jiraConfig.IssueKey == "" || jiraConfig.Comment == "" {
+			return fmt.Errorf("issue_key and comment are required for add_comment")
+		}
+	case "search":
+		if jiraConfig.JQL == "" {
+			return fmt.Errorf("jql is required for search")
+		}
+	}
+
+	return nil
+}
+
+// GetSchema returns the node configuration schema
+func (n *JiraNode) GetSchema() engine.NodeSchema {
+	return engine.NodeSchema{
+		Type: "object",
+		Properties: map[string]engine.Property{
+			"operation": {
+				Type:        "string",
+				Title:       "Operation",
+				Description: "Action to perform",
+				Enum:        []string{"create_issue", "update_issue", "get_issue", "transition_issue", "add_comment", "search"},
+			},
+			"base_url": {
+				Type:        "string",
+				Title:       "Site URL",
+				Description: "Jira site URL, e.g. https://example.atlassian.net",
+			},
+			"auth_type": {
+				Type:        "string",
+				Title:       "Authentication",
+				Description: "Email and API token for Jira Cloud, or a personal access token for Jira Data Center",
+				Default:     "basic",
+				Enum:        []string{"basic", "bearer"},
+			},
+			"credential_id": {
+				Type:        "string",
+				Title:       "Credential",
+				Description: "Vault credential supplying email and api_token, or an OAuth2 credential",
+			},
+			"email": {
+				Type:        "string",
+				Title:       "Email",
+				Description: "Account email for basic auth",
+			},
+			"api_token": {
+				Type:        "string",
+				Title:       "API Token",
+				Description: "API token or personal access token",
+				Format:      "password",
+			},
+			"issue_key": {
+				Type:        "string",
+				Title:       "Issue Key",
+				Description: "Issue key or ID, e.g. OPS-123",
+			},
+			"project_key": {
+				Type:        "string",
+				Title:       "Project Key",
+				Description: "Project for new issues",
+			},
+			"issue_type": {
+				Type:        "string",
+				Title:       "Issue Type",
+				Description: "Issue type name for new issues",
+				Default:     "Task",
+			},
+			"summary": {
+				Type:        "string",
+				Title:       "Summary",
+				Description: "Issue summary. Supports template variables like {{variable}}",
+			},
+			"description": {
+				Type:        "string",
+				Title:       "Description",
+				Description: "Issue description in Jira wiki markup",
+				Format:      "textarea",
+			},
+			"priority": {
+				Type:        "string",
+				Title:       "Priority",
+				Description: "Priority name, e.g. High",
+			},
+			"labels": {
+				Type:        "array",
+				Title:       "Labels",
+				Description: "Issue labels",
+			},
+			"assignee": {
+				Type:        "string",
+				Title:       "Assignee",
+				Description: "Assignee account ID",
+			},
+			"fields": {
+				Type:        "object",
+				Title:       "Additional Fields",
+				Description: "Extra fields by ID, e.g. {\"customfield_10010\": \"{{team}}\"}",
+			},
+			"transition": {
+				Type:        "string",
+				Title:       "Transition",
+				Description: "Transition name or ID, e.g. Done",
+			},
+			"comment": {
+				Type:        "string",
+				Title:       "Comment",
+				Description: "Comment text; also added when transitioning",
+				Format:      "textarea",
+			},
+			"jql": {
+				Type:        "string",
+				Title:       "JQL",
+				Description: "Search query, e.g. project = OPS AND status = Open",
+			},
+			"max_results": {
+				Type:        "number",
+				Title:       "Max Results",
+				Description: "Maximum issues returned by search",
+				Default:     50,
+			},
+			"start_at": {
+				Type:        "number",
+				Title:       "Start At",
+				Description: "Offset of the first search result",
+				Default:     0,
+			},
+			"search_fields": {
+				Type:        "array",
+				Title:       "Fields",
+				Description: "Fields returned by search; all navigable fields when empty",
+			},
+			"timeout": {
+				Type:        "number",
+				Title:       "Timeout",
+				Description: "Request timeout in seconds",
+				Default:     30,
+			},
+		},
+		Required: []string{"operation", "base_url"},
+		Inputs: []engine.PortSchema{
+			{
+				Name:        "input",
+				Type:        "any",
+				Description: "Input data available for template variables",
+				Required:    false,
+			},
+		},
+		Outputs: []engine.PortSchema{
+			{
+				Name:        "output",
+				Type:        "object",
+				Description: "API response {operation, statusCode, data}",
+				Required:    true,
+			},
+		},
+	}
+}
+
+// parseConfig parses the node configuration
+func (n *JiraNode) parseConfig(config interface{}) (*JiraConfig, error) {
+	configMap, ok := config.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("invalid config type for jira node")
+	}
+
+	configJSON, err := json.Marshal(configMap)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal config: %w", err)
+	}
+
+	var jiraConfig JiraConfig
+	if err := json.Unmarshal(configJSON, &jiraConfig); err != nil {
+		return nil, fmt.Errorf("failed to parse jira config: %w", err)
+	}
+
+	// Set defaults
+	if jiraConfig.AuthType == "" {
+		jiraConfig.AuthType = "basic"
+	}
+	if jiraConfig.IssueType == "" {
+		jiraConfig.IssueType = "Task"
+	}
+	if jiraConfig.MaxResults == 0 {
+		jiraConfig.MaxResults = 50
+	}
+	if jiraConfig.Timeout == 0 {
+		jiraConfig.Timeout = 30
+	}
+
+	return &jiraConfig, nil
+}
+
+// authorization builds the Authorization header value
+func (n *JiraNode) authorization(config *JiraConfig, input interface{}) string {
+	token := processTemplate(config.APIToken, input)
+	if config.AuthType == "bearer" {
+		return "Bearer " + token
+	}
+	credentials := processTemplate(config.Email, input) + ":" + token
+	return "Basic " + base64.StdEncoding.EncodeToString([]byte(credentials))
+}
+
+// issueFields builds the fields set by create and update
+func (n *JiraNode) issueFields(config *JiraConfig, input interface{}) map[string]interface{} {
+	fields := map[string]interface{}{}
+	setIfPresent(fields, "summary", config.Summary, input)
+	setIfPresent(fields, "description", config.Description, input)
+	if config.Priority != "" {
+		fields["priority"] = map[string]interface{}{"name": processTemplate(config.Priority, input)}
+	}
+	if config.Assignee != "" {
+		fields["assignee"] = map[string]interface{}{"accountId": processTemplate(config.Assignee, input)}
+	}
+	if len(config.Labels) > 0 {
+		fields["labels"] = templateList(config.Labels, input)
+	}
+	for key, value := range config.Fields {
+		fields[key] = interpolateValue(value, input)
+	}
+	return fields
+}
+
+// transition resolves the configured transition by ID or name and applies it
+func (n *JiraNode) transition(ctx context.Context, client *http.Client, issuePath string, headers map[string]string, config *JiraConfig, input interface{}) (interface{}, int, error) {
+	available, _, err := restRequest(ctx, client, http.MethodGet, issuePath+"/transitions", headers, nil)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list transitions: %w", err)
+	}
+
+	wanted := processTemplate(config.Transition, input)
+	var match map[string]interface{}
+	var names []string
+	availableMap, _ := available.(map[string]interface{})
+	transitions, _ := availableMap["transitions"].([]interface{})
+	for _, item := range transitions {
+		transition, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		id := fmt.Sprint(transition["id"])
+		name, _ := transition["name"].(string)
+		names = append(names, name)
+		if id == wanted || strings.EqualFold(name, wanted) {
+			match = transition
+			break
+		}
+	}
+	if match == nil {
+		return nil, 0, fmt.Errorf("transition %q is not available; available: %s", wanted, strings.Join(names, ", "))
+	}
+
+	body := map[string]interface{}{"transition": map[string]interface{}{"id": fmt.Sprint(match["id"])}}
+	if config.Comment != "" {
+		body["update"] = map[string]interface{}{
+			"comment": []interface{}{map[string]interface{}{"add": map[string]interface{}{"body": processTemplate(config.Comment, input)}}},
+		}
+	}
+	if fields := n.issueFields(config, input); len(fields) > 0 {
+		body["fields"] = fields
+	}
+
+	_, status, err := restRequest(ctx, client, http.MethodPost, issuePath+"/transitions", headers, body)
+	if err != nil {
+		return nil, status, err
+	}
+
+	return map[string]interface{}{
+		"issue_key":  processTemplate(config.IssueKey, input),
+		"transition": map[string]interface{}{"id": fmt.Sprint(match["id"]), "name": match["name"]},
+		"to_status":  getValueByPath(match, "to.name"),
+	}, status, nil
+}
@@ -0,0 +1,479 @@
+package nodes
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/nuumz/f1ow/internal/engine"
+
+	"github.com/linkedin/goavro/v2"
+	"github.com/segmentio/kafka-go"
+	"github.com/segmentio/kafka-go/sasl"
+	"github.com/segmentio/kafka-go/sasl/plain"
+	"github.com/segmentio/kafka-go/sasl/scram"
+)
+
+// KafkaConnection holds broker addresses and security settings shared by the
+// Kafka node and trigger
+type KafkaConnection struct {
+	Brokers         []string `json:"brokers"`
+	SASLMechanism   string   `json:"sasl_mechanism"` // "", "plain", "scram-sha-256", "scram-sha-512"
+	Username        string   `json:"username"`
+	Password        string   `json:"password"`
+	UseTLS          bool     `json:"use_tls"`
+	IgnoreSSLIssues bool     `json:"ignore_ssl_issues"`
+}
+
+// KafkaPayload describes how message values are encoded
+type KafkaPayload struct {
+	Format     string `json:"format"`      // "json", "avro", "string"
+	AvroSchema string `json:"avro_schema"` // Avro schema JSON for the avro format
+	SchemaID   int    `json:"schema_id"`   // Confluent schema registry ID, adds the wire format header when set
+}
+
+// KafkaNode produces messages to a Kafka topic
+type KafkaNode struct {
+	BaseNode
+}
+
+// KafkaConfig defines configuration for kafka node
+type KafkaConfig struct {
+	KafkaConnection
+	KafkaPayload
+	Topic     string            `json:"topic"`
+	Key       string            `json:"key"`
+	Headers   map[string]string `json:"headers"`
+	Value     interface{}       `json:"value"`
+	ItemsPath string            `json:"items_path"` // Produce one message per item of this array instead of value
+	Timeout   int               `json:"timeout"`    // seconds
+}
+
+// NewKafkaNode creates a new kafka node
+func NewKafkaNode() engine.NodeType {
+	return &KafkaNode{
+		BaseNode: BaseNode{
+			nodeType:    "kafka",
+			name:        "Kafka Producer",
+			description: "Produce JSON, Avro, or string messages to a Kafka topic",
+			category:    "Messaging",
+			icon:        "send",
+		},
+	}
+}
+
+// Execute produces the configured messages
+func (n *KafkaNode) Execute(ctx context.Context, config interface{}, input interface{}) (interface{}, error) {
+	kafkaConfig, err := n.parseConfig(config)
+	if err != nil {
+		return nil, err
+	}
+
+	var codec *goavro.Codec
+	if kafkaConfig.Format == "avro" {
+		codec, err = goavro.NewCodec(kafkaConfig.AvroSchema)
+		if err != nil {
+			return nil, fmt.Errorf("invalid avro schema: %w", err)
+		}
+	}
+
+	values := []interface{}{interpolateValue(kafkaConfig.Value, input)}
+	if kafkaConfig.ItemsPath != "" {
+		inputData, _ := input.(map[string]interface{})
+		items, ok := getValueByPath(inputData, kafkaConfig.ItemsPath).([]interface{})
+		if !ok {
+			return nil, fmt.Errorf("value at path '%s' is not an array", kafkaConfig.ItemsPath)
+		}
+		values = items
+	}
+
+	topic := processTemplate(kafkaConfig.Topic, input)
+	messages := make([]kafka.Message, len(values))
+	for i, value := range values {
+		data, err := kafkaConfig.KafkaPayload.Encode(codec, value)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode message %d: %w", i, err)
+		}
+
+		message := kafka.Message{Value: data}
+		if kafkaConfig.Key != "" {
+			message.Key = []byte(processTemplate(kafkaConfig.Key, itemScope(input, value)))
+		}
+		for name, headerValue := range kafkaConfig.Headers {
+			message.Headers = append(message.Headers, kafka.Header{
+				Key:   name,
+				Value: []byte(processTemplate(headerValue, itemScope(input, value))),
+			})
+		}
+		messages[i] = message
+	}
+
+	transport, err := kafkaConfig.KafkaConnection.Transport(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	writer := &kafka.Writer{
+		Addr:         kafka.TCP(kafkaConfig.Brokers...),
+		Topic:        topic,
+		Balancer:     &kafka.Hash{},
+		RequiredAcks: kafka.RequireAll,
+		Transport:    transport,
+		WriteTimeout: time.Duration(kafkaConfig.Timeout) * time.Second,
+	}
+	defer writer.Close()
+
+	if err := writer.WriteMessages(ctx, messages...); err != nil {
+		return nil, fmt.Errorf("failed to produce messages: %w", err)
+	}
+
+	return map[string]interface{}{
+		"topic": topic,
+		"count": len(messages),
+	}, nil
+}
+
+// ValidateConfig validates the node configuration
+func (n *KafkaNode) ValidateConfig(config interface{}) error {
+	kafkaConfig, err := n.parseConfig(config)
+	if err != nil {
+		return err
+	}
+
+	if err := kafkaConfig.KafkaConnection.Validate(); err != nil {
+		return err
+	}
+
+	if kafkaConfig.Topic == "" {
+		return fmt.Errorf("topic is required")
+	}
+
+	return kafkaConfig.KafkaPayload.Validate()
+}
+
+// GetSchema returns the node configuration schema
+func (n *KafkaNode) GetSchema() engine.NodeSchema {
+	properties := kafkaConnectionProperties()
+	for name, property := range kafkaPayloadProperties() {
+		properties[name] = property
+	}
+	properties["topic"] = engine.Property{
+		Type:        "string",
+		Title:       "Topic",
+		Description: "Topic to produce to. Supports template variables like {{variable}}",
+	}
+	properties["key"] = engine.Property{
+		Type:        "string",
+		Title:       "Key",
+		Description: "Message key used for partitioning. Supports template variables",
+	}
+	properties["headers"] = engine.Property{
+		Type:        "object",
+		Title:       "Headers",
+		Description: "Message headers. Supports template variables",
+	}
+	properties["value"] = engine.Property{
+		Type:        "object",
+		Title:       "Value",
+		Description: "Message value. Supports template variables",
+	}
+	properties["items_path"] = engine.Property{
+		Type:        "string",
+		Title:       "Items Path",
+		Description: "Path to an array; one message is produced per item instead of value",
+	}
+	properties["timeout"] = engine.Property{
+		Type:        "number",
+		Title:       "Timeout",
+		Description: "Write timeout in seconds",
+		Default:     10,
+	}
+
+	return engine.NodeSchema{
+		Type:       "object",
+		Properties: properties,
+		Required:   []string{"brokers", "topic"},
+		Inputs: []engine.PortSchema{
+			{
+				Name:        "input",
+				Type:        "any",
+				Description: "Input data available for template variables",
+				Required:    false,
+			},
+		},
+		Outputs: []engine.PortSchema{
+			{
+				Name:        "output",
+				Type:        "object",
+				Description: "Topic and number of produced messages",
+				Required:    true,
+			},
+		},
+	}
+}
+
+// parseConfig parses the node configuration
+func (n *KafkaNode) parseConfig(config interface{}) (*KafkaConfig, error) {
+	configMap, ok := config.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("invalid config type for kafka node")
+	}
+
+	configJSON, err := json.Marshal(configMap)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal config: %w", err)
+	}
+
+	var kafkaConfig KafkaConfig
+	if err := json.Unmarshal(configJSON, &kafkaConfig); err != nil {
+		return nil, fmt.Errorf("failed to parse kafka config: %w", err)
+	}
+
+	// Set defaults
+	kafkaConfig.Brokers = parseKafkaBrokers(kafkaConfig.Brokers)
+	if kafkaConfig.Format == "" {
+		kafkaConfig.Format = "json"
+	}
+	if kafkaConfig.Timeout == 0 {
+		kafkaConfig.Timeout = 10
+	}
+
+	return &kafkaConfig, nil
+}
+
+// Validate validates broker and authentication settings
+func (c KafkaConnection) Validate() error {
+	if len(c.Brokers) == 0 {
+		return fmt.Errorf("at least one broker is required")
+	}
+
+	switch c.SASLMechanism {
+	case "":
+	case "plain", "scram-sha-256", "scram-sha-512":
+		if c.Username == "" {
+			return fmt.Errorf("username is required for SASL authentication")
+		}
+	default:
+		return fmt.Errorf("unsupported SASL mechanism: %s", c.SASLMechanism)
+	}
+
+	return nil
+}
+
+// Mechanism returns the configured SASL mechanism, or nil when disabled
+func (c KafkaConnection) Mechanism() (sasl.Mechanism, error) {
+	switch c.SASLMechanism {
+	case "":
+		return nil, nil
+	case "plain":
+		return plain.Mechanism{Username: c.Username, Password: c.Password}, nil
+	case "scram-sha-256":
+		return scram.Mechanism(scram.SHA256, c.Username, c.Password)
+	case "scram-sha-512":
+		return scram.Mechanism(scram.SHA512, c.Username, c.Password)
+	default:
+		return nil, fmt.Errorf("unsupported SASL mechanism: %s", c.SASLMechanism)
+	}
+}
+
+// TLSConfig returns the TLS configuration, or nil when TLS is disabled
+func (c KafkaConnection) TLSConfig() *tls.Config {
+	if !c.UseTLS {
+		return nil
+	}
+	return &tls.Config{InsecureSkipVerify: c.IgnoreSSLIssues}
+}
+
+// Transport returns a producer transport with SASL and TLS applied, which
+// connects through the network policy in ctx, if any
+func (c KafkaConnection) Transport(ctx context.Context) (*kafka.Transport, error) {
+	mechanism, err := c.Mechanism()
+	if err != nil {
+		return nil, err
+	}
+
+	return &kafka.Transport{
+		Dial: Dialer(ctx),
+		SASL: mechanism,
+		TLS:  c.TLSConfig(),
+	}, nil
+}
+
+// Dialer returns a consumer dialer with SASL and TLS applied, which
+// connects through the network policy in ctx, if any
+func (c KafkaConnection) Dialer(ctx context.Context) (*kafka.Dialer, error) {
+	mechanism, err := c.Mechanism()
+	if err != nil {
+		return nil, err
+	}
+
+	return &kafka.Dialer{
+		Timeout:       10 * time.Second,
+		DialFunc:      Dialer(ctx),
+		SASLMechanism: mechanism,
+		TLS:           c.TLSConfig(),
+	}, nil
+}
+
+// Validate validates the payload format settings
+func (p KafkaPayload) Validate() error {
+	switch p.Format {
+	case "json", "string":
+		return nil
+	case "avro":
+		if p.AvroSchema == "" {
+			return fmt.Errorf("avro_schema is required for avro format")
+		}
+		if _, err := goavro.NewCodec(p.AvroSchema); err != nil {
+			return fmt.Errorf("invalid avro schema: %w", err)
+		}
+		return nil
+	default:
+		return fmt.Errorf("invalid format: %s", p.Format)
+	}
+}
+
+// Encode serializes a message value according to the payload format
+func (p KafkaPayload) Encode(codec *goavro.Codec, value interface{}) ([]byte, error) {
+	switch p.Format {
+	case "string":
+		if s, ok := value.(string); ok {
+			return []byte(s), nil
+		}
+		return []byte(fmt.Sprintf("%v", value)), nil
+
+	case "avro":
+		textual, err := json.Marshal(value)
+		if err != nil {
+			return nil, err
+		}
+		native, _, err := codec.NativeFromTextual(textual)
+		if err != nil {
+			return nil, fmt.Errorf("value does not match avro schema: %w", err)
+		}
+		var header []byte
+		if p.SchemaID > 0 {
+			header = make([]byte, 5)
+			binary.BigEndian.PutUint32(header[1:], uint32(p.SchemaID))
+		}
+		return codec.BinaryFromNative(header, native)
+
+	default:
+		return json.Marshal(value)
+	}
+}
+
+// Decode deserializes a message value according to the payload format
+func (p KafkaPayload) Decode(codec *goavro.Codec, data []byte) (interface{}, error) {
+	switch p.Format {
+	case "string":
+		return string(data), nil
+
+	case "avro":
+		// Strip the Confluent wire format header (magic byte + schema ID)
+		if len(data) > 5 && data[0] == 0 {
+			data = data[5:]
+		}
+		native, _, err := codec.NativeFromBinary(data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode avro message: %w", err)
+		}
+		textual, err := codec.TextualFromNative(nil, native)
+		if err != nil {
+			return nil, err
+		}
+		var value interface{}
+		if err := json.Unmarshal(textual, &value); err != nil {
+			return nil, err
+		}
+		return value, nil
+
+	default:
+		var value interface{}
+		if err := json.Unmarshal(data, &value); err != nil {
+			// Fall back to the raw string for non-JSON payloads
+			return string(data), nil
+		}
+		return value, nil
+	}
+}
+
+// itemScope exposes the current item to templates alongside the input
+func itemScope(input interface{}, item interface{}) map[string]interface{} {
+	return mergeData(input, map[string]interface{}{"item": item})
+}
+
+// kafkaConnectionProperties returns schema properties for KafkaConnection
+func kafkaConnectionProperties() map[string]engine.Property {
+	return map[string]engine.Property{
+		"brokers": {
+			Type:        "array",
+			Title:       "Brokers",
+			Description: "Bootstrap broker addresses (host:port)",
+		},
+		"sasl_mechanism": {
+			Type:        "string",
+			Title:       "SASL Mechanism",
+			Description: "SASL authentication mechanism",
+			Enum:        []string{"", "plain", "scram-sha-256", "scram-sha-512"},
+		},
+		"username": {
+			Type:        "string",
+			Title:       "Username",
+			Description: "SASL username",
+		},
+		"password": {
+			Type:        "string",
+			Title:       "Password",
+			Description: "SASL password",
+			Format:      "password",
+		},
+		"use_tls": {
+			Type:        "boolean",
+			Title:       "Use TLS",
+			Description: "Connect to brokers over TLS",
+			Default:     false,
+		},
+	}
+}
+
+// kafkaPayloadProperties returns schema properties for KafkaPayload
+func kafkaPayloadProperties() map[string]engine.Property {
+	return map[string]engine.Property{
+		"format": {
+			Type:        "string",
+			Title:       "Format",
+			Description: "Message value encoding",
+			Default:     "json",
+			Enum:        []string{"json", "avro", "string"},
+		},
+		"avro_schema": {
+			Type:        "string",
+			Title:       "Avro Schema",
+			Description: "Avro schema JSON for the avro format",
+			Format:      "json",
+		},
+		"schema_id": {
+			Type:        "number",
+			Title:       "Schema ID",
+			Description: "Schema registry ID; adds the Confluent wire format header when set",
+		},
+	}
+}
+
+// parseKafkaBrokers splits a comma separated broker list
+func parseKafkaBrokers(brokers []string) []string {
+	var result []string
+	for _, broker := range brokers {
+		for _, b := range strings.Split(broker, ",") {
+			if b = strings.TrimSpace(b); b != "" {
+				result = append(result, b)
+			}
+		}
+	}
+	return result
+}
@@ -0,0 +1,172 @@
+package nodes
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/nuumz/f1ow/internal/engine"
+)
+
+// KafkaTriggerNode starts executions for messages consumed from a Kafka topic
+type KafkaTriggerNode struct {
+	BaseNode
+}
+
+// KafkaTriggerConfig defines configuration for kafka trigger node
+type KafkaTriggerConfig struct {
+	KafkaConnection
+	KafkaPayload
+	Topic        string `json:"topic"`
+	GroupID      string `json:"group_id"`      // Defaults to a group owned by the workflow node
+	StartOffset  string `json:"start_offset"`  // "latest", "earliest"; applies when the group has no committed offset
+	Mode         string `json:"mode"`          // "message", "batch"
+	BatchSize    int    `json:"batch_size"`    // max messages per execution in batch mode
+	BatchTimeout int    `json:"batch_timeout"` // milliseconds to wait for a batch to fill
+}
+
+// NewKafkaTriggerNode creates a new kafka trigger node
+func NewKafkaTriggerNode() engine.NodeType {
+	return &KafkaTriggerNode{
+		BaseNode: BaseNode{
+			nodeType:    "kafka_trigger",
+			name:        "Kafka Consumer",
+			description: "Start the workflow for each message or batch consumed from a Kafka topic",
+			category:    "Triggers",
+			icon:        "inbox",
+		},
+	}
+}
+
+// Execute passes the consumed message through to downstream nodes
+func (n *KafkaTriggerNode) Execute(ctx context.Context, config interface{}, input interface{}) (interface{}, error) {
+	if inputMap, ok := input.(map[string]interface{}); ok {
+		return inputMap, nil
+	}
+	return map[string]interface{}{"data": input}, nil
+}
+
+// ValidateConfig validates the node configuration
+func (n *KafkaTriggerNode) ValidateConfig(config interface{}) error {
+	kafkaConfig, err := ParseKafkaTriggerConfig(config)
+	if err != nil {
+		return err
+	}
+
+	if err := kafkaConfig.KafkaConnection.Validate(); err != nil {
+		return err
+	}
+
+	if kafkaConfig.Topic == "" {
+		return fmt.Errorf("topic is required")
+	}
+
+	if kafkaConfig.Mode != "message" && kafkaConfig.Mode != "batch" {
+		return fmt.Errorf("invalid mode: %s", kafkaConfig.Mode)
+	}
+
+	if kafkaConfig.StartOffset != "latest" && kafkaConfig.StartOffset != "earliest" {
+		return fmt.Errorf("invalid start_offset: %s", kafkaConfig.StartOffset)
+	}
+
+	return kafkaConfig.KafkaPayload.Validate()
+}
+
+// GetSchema returns the node configuration schema
+func (n *KafkaTriggerNode) GetSchema() engine.NodeSchema {
+	properties := kafkaConnectionProperties()
+	for name, property := range kafkaPayloadProperties() {
+		properties[name] = property
+	}
+	properties["topic"] = engine.Property{
+		Type:        "string",
+		Title:       "Topic",
+		Description: "Topic to consume from",
+	}
+	properties["group_id"] = engine.Property{
+		Type:        "string",
+		Title:       "Group ID",
+		Description: "Consumer group; defaults to a group dedicated to this workflow node",
+	}
+	properties["start_offset"] = engine.Property{
+		Type:        "string",
+		Title:       "Start Offset",
+		Description: "Where a new consumer group starts reading",
+		Default:     "latest",
+		Enum:        []string{"latest", "earliest"},
+	}
+	properties["mode"] = engine.Property{
+		Type:        "string",
+		Title:       "Mode",
+		Description: "Start one execution per message or per batch of messages",
+		Default:     "message",
+		Enum:        []string{"message", "batch"},
+	}
+	properties["batch_size"] = engine.Property{
+		Type:        "number",
+		Title:       "Batch Size",
+		Description: "Maximum messages per execution in batch mode",
+		Default:     100,
+	}
+	properties["batch_timeout"] = engine.Property{
+		Type:        "number",
+		Title:       "Batch Timeout",
+		Description: "Milliseconds to wait for a batch to fill",
+		Default:     1000,
+	}
+
+	return engine.NodeSchema{
+		Type:       "object",
+		Properties: properties,
+		Required:   []string{"brokers", "topic"},
+		Inputs:     []engine.PortSchema{},
+		Outputs: []engine.PortSchema{
+			{
+				Name:        "output",
+				Type:        "object",
+				Description: "Consumed message, or {messages, count} in batch mode",
+				Required:    true,
+			},
+		},
+	}
+}
+
+// ParseKafkaTriggerConfig parses a kafka trigger configuration and applies defaults
+func ParseKafkaTriggerConfig(config interface{}) (*KafkaTriggerConfig, error) {
+	configMap, ok := config.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("invalid config type for kafka trigger node")
+	}
+
+	configJSON, err := json.Marshal(configMap)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal config: %w", err)
+	}
+
+	var kafkaConfig KafkaTriggerConfig
+	if err := json.Unmarshal(configJSON, &kafkaConfig); err != nil {
+		return nil, fmt.Errorf("failed to parse kafka trigger config: %w", err)
+	}
+
+	// Set defaults
+	kafkaConfig.Brokers = parseKafkaBrokers(kafkaConfig.Brokers)
+	if kafkaConfig.Format == "" {
+		kafkaConfig.Format = "json"
+	}
+	kafkaConfig.StartOffset = strings.ToLower(kafkaConfig.StartOffset)
+	if kafkaConfig.StartOffset == "" {
+		kafkaConfig.StartOffset = "latest"
+	}
+	if kafkaConfig.Mode == "" {
+		kafkaConfig.Mode = "message"
+	}
+	if kafkaConfig.BatchSize <= 0 {
+		kafkaConfig.BatchSize = 100
+	}
+	if kafkaConfig.BatchTimeout <= 0 {
+		kafkaConfig.BatchTimeout = 1000
+	}
+
+	return &kafkaConfig, nil
+}
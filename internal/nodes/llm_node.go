@@ -0,0 +1,474 @@
+package nodes
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/nuumz/f1ow/internal/engine"
+	"github.com/nuumz/f1ow/internal/usage"
+)
+
+// LLMNode calls chat, completion, and embedding APIs of LLM providers
+type LLMNode struct {
+	BaseNode
+}
+
+// LLMConfig defines configuration for llm node
+type LLMConfig struct {
+	Provider     string       `json:"provider"`  // "openai", "azure_openai", "anthropic", "ollama"
+	Operation    string       `json:"operation"` // "chat", "completion", "embedding"
+	Model        string       `json:"model"`
+	APIKey       string       `json:"api_key"`     // falls back to the provider's API key environment variable
+	BaseURL      string       `json:"base_url"`    // API base URL; the resource endpoint for Azure OpenAI
+	Deployment   string       `json:"deployment"`  // Azure OpenAI deployment name
+	APIVersion   string       `json:"api_version"` // Azure OpenAI API version
+	SystemPrompt string       `json:"system_prompt"`
+	Prompt       string       `json:"prompt"`
+	Messages     []LLMMessage `json:"messages"` // chat history; prompt is appended as the last user message
+	Input        string       `json:"input"`    // embedding: template resolving to a string or array of strings
+	Temperature  *float64     `json:"temperature"`
+	MaxTokens    int          `json:"max_tokens"`
+	JSONMode     bool         `json:"json_mode"` // request JSON output and parse it into the "json" field
+	Timeout      int          `json:"timeout"`   // seconds
+}
+
+// LLMMessage is a single chat message
+type LLMMessage struct {
+	Role    string `json:"role"` // "system", "user", "assistant"
+	Content string `json:"content"`
+}
+
+// llmRequest is a provider-independent generation request
+type llmRequest struct {
+	Model       string
+	System      string
+	Messages    []LLMMessage
+	Prompt      string // completion prompt
+	Temperature *float64
+	MaxTokens   int
+	JSONMode    bool
+}
+
+// llmResponse is a provider-independent generation result
+type llmResponse struct {
+	Content      string
+	Model        string
+	FinishReason string
+	Usage        llmUsage
+}
+
+// llmUsage reports token usage for a call
+type llmUsage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+	TotalTokens      int `json:"total_tokens"`
+}
+
+// llmProvider is implemented by each supported LLM API
+type llmProvider interface {
+	Chat(ctx context.Context, req *llmRequest) (*llmResponse, error)
+	Complete(ctx context.Context, req *llmRequest) (*llmResponse, error)
+	Embed(ctx context.Context, model string, inputs []string) ([][]float64, llmUsage, error)
+}
+
+// llmAPIKeyEnv maps providers to the environment variable holding their API key
+var llmAPIKeyEnv = map[string]string{
+	"openai":       "OPENAI_API_KEY",
+	"azure_openai": "AZURE_OPENAI_API_KEY",
+	"anthropic":    "ANTHROPIC_API_KEY",
+}
+
+// NewLLMNode creates a new llm node
+func NewLLMNode() engine.NodeType {
+	return &LLMNode{
+		BaseNode: BaseNode{
+			nodeType:    "llm",
+			name:        "LLM",
+			description: "Generate text and embeddings with OpenAI, Azure OpenAI, Anthropic, or Ollama",
+			category:    "AI",
+			icon:        "sparkles",
+		},
+	}
+}
+
+// Execute calls the configured provider
+func (n *LLMNode) Execute(ctx context.Context, config interface{}, input interface{}) (interface{}, error) {
+	llmConfig, err := n.parseConfig(config)
+	if err != nil {
+		return nil, err
+	}
+
+	provider, err := n.provider(ctx, llmConfig, input)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(llmConfig.Timeout)*time.Second)
+	defer cancel()
+
+	model := processTemplate(llmConfig.Model, input)
+
+	if llmConfig.Operation == "embedding" {
+		inputs, err := embeddingInputs(resolveTemplateValue(llmConfig.Input, input))
+		if err != nil {
+			return nil, err
+		}
+
+		embeddings, tokens, err := provider.Embed(ctx, model, inputs)
+		if err != nil {
+			return nil, err
+		}
+		if tokens.PromptTokens == 0 {
+			tokens.PromptTokens = tokens.TotalTokens
+		}
+		usage.AddTokens(ctx, tokens.PromptTokens, 0)
+
+		result := map[string]interface{}{
+			"provider":   llmConfig.Provider,
+			"model":      model,
+			"embeddings": embeddings,
+			"usage":      usageMap(tokens),
+		}
+		if len(embeddings) > 0 {
+			result["embedding"] = embeddings[0]
+		}
+		return result, nil
+	}
+
+	req := &llmRequest{
+		Model:       model,
+		System:      processTemplate(llmConfig.SystemPrompt, input),
+		Temperature: llmConfig.Temperature,
+		MaxTokens:   llmConfig.MaxTokens,
+		JSONMode:    llmConfig.JSONMode,
+	}
+
+	var resp *llmResponse
+	if llmConfig.Operation == "completion" {
+		req.Prompt = processTemplate(llmConfig.Prompt, input)
+		resp, err = provider.Complete(ctx, req)
+	} else {
+		for _, message := range llmConfig.Messages {
+			req.Messages = append(req.Messages, LLMMessage{
+				Role:    message.Role,
+				Content: processTemplate(message.Content, input),
+			})
+		}
+		if llmConfig.Prompt != "" {
+			req.Messages = append(req.Messages, LLMMessage{Role: "user", Content: processTemplate(llmConfig.Prompt, input)})
+		}
+		resp, err = provider.Chat(ctx, req)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.Usage.TotalTokens == 0 {
+		resp.Usage.TotalTokens = resp.Usage.PromptTokens + resp.Usage.CompletionTokens
+	}
+	usage.AddTokens(ctx, resp.Usage.PromptTokens, resp.Usage.CompletionTokens)
+
+	result := map[string]interface{}{
+		"provider":      llmConfig.Provider,
+		"model":         resp.Model,
+		"content":       resp.Content,
+		"finish_reason": resp.FinishReason,
+		"usage":         usageMap(resp.Usage),
+	}
+
+	if llmConfig.JSONMode {
+		parsed, err := parseLLMJSON(resp.Content)
+		if err != nil {
+			return nil, err
+		}
+		result["json"] = parsed
+	}
+
+	return result, nil
+}
+
+// ValidateConfig validates the node configuration
+func (n *LLMNode) ValidateConfig(config interface{}) error {
+	llmConfig, err := n.parseConfig(config)
+	if err != nil {
+		return err
+	}
+
+	switch llmConfig.Provider {
+	case "openai", "anthropic", "ollama":
+	case "azure_openai":
+		if llmConfig.BaseURL == "" {
+			return fmt.Errorf("base_url is required for azure_openai")
+		}
+		if llmConfig.Deployment == "" {
+			return fmt.Errorf("deployment is required for azure_openai")
+		}
+	default:
+		return fmt.Errorf("invalid provider: %s", llmConfig.Provider)
+	}
+
+	if llmConfig.Model == "" && llmConfig.Provider != "azure_openai" {
+		return fmt.Errorf("model is required")
+	}
+
+	switch llmConfig.Operation {
+	case "chat":
+		if llmConfig.Prompt == "" && len(llmConfig.Messages) == 0 {
+			return fmt.Errorf("prompt or messages is required")
+		}
+		for _, message := range llmConfig.Messages {
+			if message.Role != "system" && message.Role != "user" && message.Role != "assistant" {
+				return fmt.Errorf("invalid message role: %s", message.Role)
+			}
+		}
+	case "completion":
+		if llmConfig.Prompt == "" {
+			return fmt.Errorf("prompt is required")
+		}
+	case "embedding":
+		if llmConfig.Input == "" {
+			return fmt.Errorf("input is required for embedding")
+		}
+		if llmConfig.Provider == "anthropic" {
+			return fmt.Errorf("anthropic does not support embeddings")
+		}
+	default:
+		return fmt.Errorf("invalid operation: %s", llmConfig.Operation)
+	}
+
+	return nil
+}
+
+// GetSchema returns the node configuration schema
+func (n *LLMNode) GetSchema() engine.NodeSchema {
+	return engine.NodeSchema{
+		Type: "object",
+		Properties: map[string]engine.Property{
+			"provider": {
+				Type:        "string",
+				Title:       "Provider",
+				Description: "LLM provider",
+				Enum:        []string{"openai", "azure_openai", "anthropic", "ollama"},
+			},
+			"operation": {
+				Type:        "string",
+				Title:       "Operation",
+				Description: "Call to make",
+				Default:     "chat",
+				Enum:        []string{"chat", "completion", "embedding"},
+			},
+			"model": {
+				Type:        "string",
+				Title:       "Model",
+				Description: "Model name, e.g. gpt-4o-mini, claude-3-5-sonnet-latest, llama3",
+			},
+			"api_key": {
+				Type:        "string",
+				Title:       "API Key",
+				Description: "Provider API key; defaults to the provider's API key environment variable",
+				Format:      "password",
+			},
+			"base_url": {
+				Type:        "string",
+				Title:       "Base URL",
+				Description: "API base URL override; the resource endpoint for Azure OpenAI",
+			},
+			"deployment": {
+				Type:        "string",
+				Title:       "Deployment",
+				Description: "Azure OpenAI deployment name",
+			},
+			"api_version": {
+				Type:        "string",
+				Title:       "API Version",
+				Description: "Azure OpenAI API version",
+				Default:     "2024-02-01",
+			},
+			"system_prompt": {
+				Type:        "string",
+				Title:       "System Prompt",
+				Description: "System instructions. Supports template variables like {{variable}}",
+				Format:      "textarea",
+			},
+			"prompt": {
+				Type:        "string",
+				Title:       "Prompt",
+				Description: "User prompt. Supports template variables like {{variable}}",
+				Format:      "textarea",
+			},
+			"messages": {
+				Type:        "array",
+				Title:       "Messages",
+				Description: "Chat history as {role, content} messages; content supports template variables",
+			},
+			"input": {
+				Type:        "string",
+				Title:       "Embedding Input",
+				Description: "Template resolving to the text or array of texts to embed, e.g. {{chunks}}",
+			},
+			"temperature": {
+				Type:        "number",
+				Title:       "Temperature",
+				Description: "Sampling temperature",
+			},
+			"max_tokens": {
+				Type:        "number",
+				Title:       "Max Tokens",
+				Description: "Maximum tokens to generate",
+			},
+			"json_mode": {
+				Type:        "boolean",
+				Title:       "JSON Mode",
+				Description: "Request JSON output and parse it into the json field",
+				Default:     false,
+			},
+			"timeout": {
+				Type:        "number",
+				Title:       "Timeout",
+				Description: "Request timeout in seconds",
+				Default:     60,
+			},
+		},
+		Required: []string{"provider", "operation"},
+		Inputs: []engine.PortSchema{
+			{
+				Name:        "input",
+				Type:        "any",
+				Description: "Input data available for template variables",
+				Required:    false,
+			},
+		},
+		Outputs: []engine.PortSchema{
+			{
+				Name:        "output",
+				Type:        "object",
+				Description: "Generated content or embeddings with token usage",
+				Required:    true,
+			},
+		},
+	}
+}
+
+// parseConfig parses the node configuration
+func (n *LLMNode) parseConfig(config interface{}) (*LLMConfig, error) {
+	configMap, ok := config.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("invalid config type for llm node")
+	}
+
+	configJSON, err := json.Marshal(configMap)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal config: %w", err)
+	}
+
+	var llmConfig LLMConfig
+	if err := json.Unmarshal(configJSON, &llmConfig); err != nil {
+		return nil, fmt.Errorf("failed to parse llm config: %w", err)
+	}
+
+	// Set defaults
+	llmConfig.Provider = strings.ToLower(llmConfig.Provider)
+	if llmConfig.Operation == "" {
+		llmConfig.Operation = "chat"
+	}
+	if llmConfig.APIVersion == "" {
+		llmConfig.APIVersion = "2024-02-01"
+	}
+	if llmConfig.Timeout == 0 {
+		llmConfig.Timeout = 60
+	}
+
+	return &llmConfig, nil
+}
+
+// provider creates the API client for the configured provider
+func (n *LLMNode) provider(ctx context.Context, config *LLMConfig, input interface{}) (llmProvider, error) {
+	apiKey := processTemplate(config.APIKey, input)
+	if apiKey == "" {
+		apiKey = os.Getenv(llmAPIKeyEnv[config.Provider])
+	}
+	baseURL := strings.TrimRight(processTemplate(config.BaseURL, input), "/")
+	client := guardClient(ctx, &http.Client{})
+
+	switch config.Provider {
+	case "openai":
+		if baseURL == "" {
+			baseURL = "https://api.openai.com/v1"
+		}
+		return &openAIProvider{client: client, baseURL: baseURL, apiKey: apiKey}, nil
+	case "azure_openai":
+		return &openAIProvider{
+			client:     client,
+			baseURL:    fmt.Sprintf("%s/openai/deployments/%s", baseURL, processTemplate(config.Deployment, input)),
+			apiKey:     apiKey,
+			azure:      true,
+			apiVersion: config.APIVersion,
+		}, nil
+	case "anthropic":
+		if baseURL == "" {
+			baseURL = "https://api.anthropic.com"
+		}
+		return &anthropicProvider{client: client, baseURL: baseURL, apiKey: apiKey}, nil
+	case "ollama":
+		if baseURL == "" {
+			baseURL = "http://localhost:11434"
+		}
+		return &ollamaProvider{client: client, baseURL: baseURL}, nil
+	default:
+		return nil, fmt.Errorf("unsupported provider: %s", config.Provider)
+	}
+}
+
+// embeddingInputs converts a resolved embedding input into a list of texts
+func embeddingInputs(value interface{}) ([]string, error) {
+	switch v := value.(type) {
+	case string:
+		if v == "" {
+			return nil, fmt.Errorf("embedding input is empty")
+		}
+		return []string{v}, nil
+	case []interface{}:
+		inputs := make([]string, len(v))
+		for i, item := range v {
+			s, ok := item.(string)
+			if !ok {
+				s = fmt.Sprintf("%v", item)
+			}
+			inputs[i] = s
+		}
+		return inputs, nil
+	case []string:
+		return v, nil
+	default:
+		return nil, fmt.Errorf("embedding input must be a string or array of strings, got %T", value)
+	}
+}
+
+// parseLLMJSON parses JSON-mode output, tolerating a surrounding markdown code fence
+func parseLLMJSON(content string) (interface{}, error) {
+	trimmed := strings.TrimSpace(content)
+	if strings.HasPrefix(trimmed, "```") {
+		trimmed = strings.TrimPrefix(trimmed, "```json")
+		trimmed = strings.TrimPrefix(trimmed, "```")
+		trimmed = strings.TrimSuffix(strings.TrimSpace(trimmed), "```")
+	}
+
+	var parsed interface{}
+	if err := json.Unmarshal([]byte(trimmed), &parsed); err != nil {
+		return nil, fmt.Errorf("model output is not valid JSON: %w", err)
+	}
+	return parsed, nil
+}
+
+func usageMap(usage llmUsage) map[string]interface{} {
+	return map[string]interface{}{
+		"prompt_tokens":     usage.PromptTokens,
+		"completion_tokens": usage.CompletionTokens,
+		"total_tokens":      usage.TotalTokens,
+	}
+}
@@ -0,0 +1,376 @@
+package nodes
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// postLLMJSON sends a JSON request and decodes the JSON response into out
+func postLLMJSON(ctx context.Context, client *http.Client, url string, headers map[string]string, body, out interface{}) error {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("LLM request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("LLM API returned status %d: %s", resp.StatusCode, string(respBody))
+	}
+
+	if err := json.Unmarshal(respBody, out); err != nil {
+		return fmt.Errorf("failed to parse LLM response: %w", err)
+	}
+	return nil
+}
+
+// openAIProvider calls the OpenAI API, or Azure OpenAI which shares its wire format
+type openAIProvider struct {
+	client     *http.Client
+	baseURL    string
+	apiKey     string
+	azure      bool
+	apiVersion string
+}
+
+type openAIUsage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+	TotalTokens      int `json:"total_tokens"`
+}
+
+func (p *openAIProvider) post(ctx context.Context, path string, body, out interface{}) error {
+	url := p.baseURL + path
+	headers := map[string]string{}
+	if p.azure {
+		url += "?api-version=" + p.apiVersion
+		headers["api-key"] = p.apiKey
+	} else if p.apiKey != "" {
+		headers["Authorization"] = "Bearer " + p.apiKey
+	}
+	return postLLMJSON(ctx, p.client, url, headers, body, out)
+}
+
+// generationParams adds the optional sampling parameters shared by chat and completion
+func (p *openAIProvider) generationParams(body map[string]interface{}, req *llmRequest) {
+	if req.Model != "" {
+		body["model"] = req.Model
+	}
+	if req.Temperature != nil {
+		body["temperature"] = *req.Temperature
+	}
+	if req.MaxTokens > 0 {
+		body["max_tokens"] = req.MaxTokens
+	}
+}
+
+func (p *openAIProvider) Chat(ctx context.Context, req *llmRequest) (*llmResponse, error) {
+	messages := []LLMMessage{}
+	if req.System != "" {
+		messages = append(messages, LLMMessage{Role: "system", Content: req.System})
+	}
+	messages = append(messages, req.Messages...)
+
+	body := map[string]interface{}{"messages": messages}
+	p.generationParams(body, req)
+	if req.JSONMode {
+		body["response_format"] = map[string]interface{}{"type": "json_object"}
+	}
+
+	var resp struct {
+		Model   string `json:"model"`
+		Choices []struct {
+			Message struct {
+				Content string `json:"content"`
+			} `json:"message"`
+			FinishReason string `json:"finish_reason"`
+		} `json:"choices"`
+		Usage openAIUsage `json:"usage"`
+	}
+	if err := p.post(ctx, "/chat/completions", body, &resp); err != nil {
+		return nil, err
+	}
+	if len(resp.Choices) == 0 {
+		return nil, fmt.Errorf("LLM response has no choices")
+	}
+
+	return &llmResponse{
+		Content:      resp.Choices[0].Message.Content,
+		Model:        resp.Model,
+		FinishReason: resp.Choices[0].FinishReason,
+		Usage:        llmUsage(resp.Usage),
+	}, nil
+}
+
+func (p *openAIProvider) Complete(ctx context.Context, req *llmRequest) (*llmResponse, error) {
+	prompt := req.Prompt
+	if req.System != "" {
+		prompt = req.System + "\n\n" + prompt
+	}
+
+	body := map[string]interface{}{"prompt": prompt}
+	p.generationParams(body, req)
+
+	var resp struct {
+		Model   string `json:"model"`
+		Choices []struct {
+			Text         string `json:"text"`
+			FinishReason string `json:"finish_reason"`
+		} `json:"choices"`
+		Usage openAIUsage `json:"usage"`
+	}
+	if err := p.post(ctx, "/completions", body, &resp); err != nil {
+		return nil, err
+	}
+	if len(resp.Choices) == 0 {
+		return nil, fmt.Errorf("LLM response has no choices")
+	}
+
+	return &llmResponse{
+		Content:      resp.Choices[0].Text,
+		Model:        resp.Model,
+		FinishReason: resp.Choices[0].FinishReason,
+		Usage:        llmUsage(resp.Usage),
+	}, nil
+}
+
+func (p *openAIProvider) Embed(ctx context.Context, model string, inputs []string) ([][]float64, llmUsage, error) {
+	body := map[string]interface{}{"input": inputs}
+	if model != "" {
+		body["model"] = model
+	}
+
+	var resp struct {
+		Data []struct {
+			Index     int       `json:"index"`
+			Embedding []float64 `json:"embedding"`
+		} `json:"data"`
+		Usage openAIUsage `json:"usage"`
+	}
+	if err := p.post(ctx, "/embeddings", body, &resp); err != nil {
+		return nil, llmUsage{}, err
+	}
+
+	embeddings := make([][]float64, len(inputs))
+	for _, item := range resp.Data {
+		if item.Index >= 0 && item.Index < len(embeddings) {
+			embeddings[item.Index] = item.Embedding
+		}
+	}
+
+	return embeddings, llmUsage(resp.Usage), nil
+}
+
+// anthropicProvider calls the Anthropic Messages API
+type anthropicProvider struct {
+	client  *http.Client
+	baseURL string
+	apiKey  string
+}
+
+func (p *anthropicProvider) Chat(ctx context.Context, req *llmRequest) (*llmResponse, error) {
+	system := req.System
+	messages := []LLMMessage{}
+	for _, message := range req.Messages {
+		// System messages are passed separately from the conversation
+		if message.Role == "system" {
+			system = strings.TrimSpace(system + "\n\n" + message.Content)
+			continue
+		}
+		messages = append(messages, message)
+	}
+	if req.JSONMode {
+		system = strings.TrimSpace(system + "\n\nRespond only with a valid JSON value.")
+	}
+
+	maxTokens := req.MaxTokens
+	if maxTokens == 0 {
+		maxTokens = 1024
+	}
+
+	body := map[string]interface{}{
+		"model":      req.Model,
+		"messages":   messages,
+		"max_tokens": maxTokens,
+	}
+	if system != "" {
+		body["system"] = system
+	}
+	if req.Temperature != nil {
+		body["temperature"] = *req.Temperature
+	}
+
+	var resp struct {
+		Model   string `json:"model"`
+		Content []struct {
+			Type string `json:"type"`
+			Text string `json:"text"`
+		} `json:"content"`
+		StopReason string `json:"stop_reason"`
+		Usage      struct {
+			InputTokens  int `json:"input_tokens"`
+			OutputTokens int `json:"output_tokens"`
+		} `json:"usage"`
+	}
+	headers := map[string]string{
+		"x-api-key":         p.apiKey,
+		"anthropic-version": "2023-06-01",
+	}
+	if err := postLLMJSON(ctx, p.client, p.baseURL+"/v1/messages", headers, body, &resp); err != nil {
+		return nil, err
+	}
+
+	var content strings.Builder
+	for _, block := range resp.Content {
+		if block.Type == "text" {
+			content.WriteString(block.Text)
+		}
+	}
+
+	return &llmResponse{
+		Content:      content.String(),
+		Model:        resp.Model,
+		FinishReason: resp.StopReason,
+		Usage: llmUsage{
+			PromptTokens:     resp.Usage.InputTokens,
+			CompletionTokens: resp.Usage.OutputTokens,
+		},
+	}, nil
+}
+
+// Complete sends the prompt as a single user message; the legacy completion API is retired
+func (p *anthropicProvider) Complete(ctx context.Context, req *llmRequest) (*llmResponse, error) {
+	chat := *req
+	chat.Messages = []LLMMessage{{Role: "user", Content: req.Prompt}}
+	return p.Chat(ctx, &chat)
+}
+
+func (p *anthropicProvider) Embed(ctx context.Context, model string, inputs []string) ([][]float64, llmUsage, error) {
+	return nil, llmUsage{}, fmt.Errorf("anthropic does not support embeddings")
+}
+
+// ollamaProvider calls a local Ollama server
+type ollamaProvider struct {
+	client  *http.Client
+	baseURL string
+}
+
+type ollamaGeneration struct {
+	Model           string `json:"model"`
+	DoneReason      string `json:"done_reason"`
+	PromptEvalCount int    `json:"prompt_eval_count"`
+	EvalCount       int    `json:"eval_count"`
+}
+
+func (p *ollamaProvider) request(req *llmRequest) map[string]interface{} {
+	body := map[string]interface{}{
+		"model":  req.Model,
+		"stream": false,
+	}
+	if req.JSONMode {
+		body["format"] = "json"
+	}
+
+	options := map[string]interface{}{}
+	if req.Temperature != nil {
+		options["temperature"] = *req.Temperature
+	}
+	if req.MaxTokens > 0 {
+		options["num_predict"] = req.MaxTokens
+	}
+	if len(options) > 0 {
+		body["options"] = options
+	}
+
+	return body
+}
+
+func (p *ollamaProvider) response(content string, generation ollamaGeneration) *llmResponse {
+	return &llmResponse{
+		Content:      content,
+		Model:        generation.Model,
+		FinishReason: generation.DoneReason,
+		Usage: llmUsage{
+			PromptTokens:     generation.PromptEvalCount,
+			CompletionTokens: generation.EvalCount,
+		},
+	}
+}
+
+func (p *ollamaProvider) Chat(ctx context.Context, req *llmRequest) (*llmResponse, error) {
+	messages := []LLMMessage{}
+	if req.System != "" {
+		messages = append(messages, LLMMessage{Role: "system", Content: req.System})
+	}
+	messages = append(messages, req.Messages...)
+
+	body := p.request(req)
+	body["messages"] = messages
+
+	var resp struct {
+		ollamaGeneration
+		Message struct {
+			Content string `json:"content"`
+		} `json:"message"`
+	}
+	if err := postLLMJSON(ctx, p.client, p.baseURL+"/api/chat", nil, body, &resp); err != nil {
+		return nil, err
+	}
+
+	return p.response(resp.Message.Content, resp.ollamaGeneration), nil
+}
+
+func (p *ollamaProvider) Complete(ctx context.Context, req *llmRequest) (*llmResponse, error) {
+	body := p.request(req)
+	body["prompt"] = req.Prompt
+	if req.System != "" {
+		body["system"] = req.System
+	}
+
+	var resp struct {
+		ollamaGeneration
+		Response string `json:"response"`
+	}
+	if err := postLLMJSON(ctx, p.client, p.baseURL+"/api/generate", nil, body, &resp); err != nil {
+		return nil, err
+	}
+
+	return p.response(resp.Response, resp.ollamaGeneration), nil
+}
+
+func (p *ollamaProvider) Embed(ctx context.Context, model string, inputs []string) ([][]float64, llmUsage, error) {
+	var resp struct {
+		Embeddings      [][]float64 `json:"embeddings"`
+		PromptEvalCount int         `json:"prompt_eval_count"`
+	}
+	body := map[string]interface{}{"model": model, "input": inputs}
+	if err := postLLMJSON(ctx, p.client, p.baseURL+"/api/embed", nil, body, &resp); err != nil {
+		return nil, llmUsage{}, err
+	}
+
+	usage := llmUsage{PromptTokens: resp.PromptEvalCount, TotalTokens: resp.PromptEvalCount}
+	return resp.Embeddings, usage, nil
+}
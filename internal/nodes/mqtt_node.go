@@ -0,0 +1,427 @@
+package nodes
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/nuumz/f1ow/internal/engine"
+
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+)
+
+// MQTTConnection holds broker and TLS settings shared by the MQTT node and trigger
+type MQTTConnection struct {
+	Broker          string `json:"broker"` // tcp://host:1883, ssl://host:8883, ws://, wss://
+	ClientID        string `json:"client_id"`
+	Username        string `json:"username"`
+	Password        string `json:"password"`
+	CACert          string `json:"ca_cert"`     // PEM encoded CA certificate
+	ClientCert      string `json:"client_cert"` // PEM encoded client certificate
+	ClientKey       string `json:"client_key"`  // PEM encoded client private key
+	IgnoreSSLIssues bool   `json:"ignore_ssl_issues"`
+}
+
+// MQTTNode publishes messages to an MQTT topic
+type MQTTNode struct {
+	BaseNode
+}
+
+// MQTTConfig defines configuration for mqtt node
+type MQTTConfig struct {
+	MQTTConnection
+	Topic   string      `json:"topic"`
+	Message interface{} `json:"message"`
+	Format  string      `json:"format"` // "json", "string"
+	QoS     int         `json:"qos"`
+	Retain  bool        `json:"retain"`
+	Timeout int         `json:"timeout"` // seconds
+}
+
+// NewMQTTNode creates a new mqtt node
+func NewMQTTNode() engine.NodeType {
+	return &MQTTNode{
+		BaseNode: BaseNode{
+			nodeType:    "mqtt",
+			name:        "MQTT Publish",
+			description: "Publish messages to an MQTT topic, e.g. to send commands to devices",
+			category:    "Messaging",
+			icon:        "radio",
+		},
+	}
+}
+
+// Execute publishes the configured message
+func (n *MQTTNode) Execute(ctx context.Context, config interface{}, input interface{}) (interface{}, error) {
+	mqttConfig, err := n.parseConfig(config)
+	if err != nil {
+		return nil, err
+	}
+
+	payload, err := encodeMQTTMessage(mqttConfig.Format, interpolateValue(mqttConfig.Message, input))
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode message: %w", err)
+	}
+
+	timeout := time.Duration(mqttConfig.Timeout) * time.Second
+	opts, err := mqttConfig.MQTTConnection.ClientOptions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	opts.SetConnectTimeout(timeout)
+
+	client := mqtt.NewClient(opts)
+	if err := waitMQTTToken(ctx, client.Connect(), timeout); err != nil {
+		return nil, fmt.Errorf("failed to connect to broker: %w", err)
+	}
+	defer client.Disconnect(250)
+
+	topic := processTemplate(mqttConfig.Topic, input)
+	token := client.Publish(topic, byte(mqttConfig.QoS), mqttConfig.Retain, payload)
+	if err := waitMQTTToken(ctx, token, timeout); err != nil {
+		return nil, fmt.Errorf("failed to publish message: %w", err)
+	}
+
+	return map[string]interface{}{
+		"topic":  topic,
+		"qos":    mqttConfig.QoS,
+		"retain": mqttConfig.Retain,
+	}, nil
+}
+
+// ValidateConfig validates the node configuration
+func (n *MQTTNode) ValidateConfig(config interface{}) error {
+	mqttConfig, err := n.parseConfig(config)
+	if err != nil {
+		return err
+	}
+
+	if err := mqttConfig.MQTTConnection.Validate(); err != nil {
+		return err
+	}
+
+	if mqttConfig.Topic == "" {
+		return fmt.Errorf("topic is required")
+	}
+
+	if mqttConfig.QoS < 0 || mqttConfig.QoS > 2 {
+		return fmt.Errorf("qos must be 0, 1, or 2")
+	}
+
+	if mqttConfig.Format != "json" && mqttConfig.Format != "string" {
+		return fmt.Errorf("invalid format: %s", mqttConfig.Format)
+	}
+
+	return nil
+}
+
+// GetSchema returns the node configuration schema
+func (n *MQTTNode) GetSchema() engine.NodeSchema {
+	properties := mqttConnectionProperties()
+	properties["topic"] = engine.Property{
+		Type:        "string",
+		Title:       "Topic",
+		Description: "Topic to publish to. Supports template variables like {{variable}}",
+	}
+	properties["message"] = engine.Property{
+		Type:        "object",
+		Title:       "Message",
+		Description: "Message payload. Supports template variables",
+	}
+	properties["format"] = engine.Property{
+		Type:        "string",
+		Title:       "Format",
+		Description: "Payload encoding",
+		Default:     "json",
+		Enum:        []string{"json", "string"},
+	}
+	properties["qos"] = engine.Property{
+		Type:        "number",
+		Title:       "QoS",
+		Description: "Delivery guarantee: 0 at most once, 1 at least once, 2 exactly once",
+		Default:     0,
+	}
+	properties["retain"] = engine.Property{
+		Type:        "boolean",
+		Title:       "Retain",
+		Description: "Keep the message as the topic's last known value for new subscribers",
+		Default:     false,
+	}
+	properties["timeout"] = engine.Property{
+		Type:        "number",
+		Title:       "Timeout",
+		Description: "Connect and publish timeout in seconds",
+		Default:     10,
+	}
+
+	return engine.NodeSchema{
+		Type:       "object",
+		Properties: properties,
+		Required:   []string{"broker", "topic"},
+		Inputs: []engine.PortSchema{
+			{
+				Name:        "input",
+				Type:        "any",
+				Description: "Input data available for template variables",
+				Required:    false,
+			},
+		},
+		Outputs: []engine.PortSchema{
+			{
+				Name:        "output",
+				Type:        "object",
+				Description: "Published topic and delivery settings",
+				Required:    true,
+			},
+		},
+	}
+}
+
+// parseConfig parses the node configuration
+func (n *MQTTNode) parseConfig(config interface{}) (*MQTTConfig, error) {
+	configMap, ok := config.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("invalid config type for mqtt node")
+	}
+
+	configJSON, err := json.Marshal(configMap)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal config: %w", err)
+	}
+
+	var mqttConfig MQTTConfig
+	if err := json.Unmarshal(configJSON, &mqttConfig); err != nil {
+		return nil, fmt.Errorf("failed to parse mqtt config: %w", err)
+	}
+
+	// Set defaults
+	if mqttConfig.Format == "" {
+		mqttConfig.Format = "json"
+	}
+	if mqttConfig.Timeout == 0 {
+		mqttConfig.Timeout = 10
+	}
+
+	return &mqttConfig, nil
+}
+
+// Validate validates broker and TLS settings
+func (c MQTTConnection) Validate() error {
+	if c.Broker == "" {
+		return fmt.Errorf("broker is required")
+	}
+
+	_, err := c.TLSConfig()
+	return err
+}
+
+// TLSConfig builds the TLS configuration from the PEM settings
+func (c MQTTConnection) TLSConfig() (*tls.Config, error) {
+	return buildTLSConfig(c.CACert, c.ClientCert, c.ClientKey, c.IgnoreSSLIssues)
+}
+
+// ClientOptions returns client options for the connection, which connects,
+// and reconnects, through the network policy in ctx, if any. A random
+// client ID is used when none is configured.
+func (c MQTTConnection) ClientOptions(ctx context.Context) (*mqtt.ClientOptions, error) {
+	tlsConfig, err := c.TLSConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	clientID := c.ClientID
+	if clientID == "" {
+		clientID = "f1ow-" + uuid.New().String()
+	}
+
+	dial := Dialer(ctx)
+	opts := mqtt.NewClientOptions().
+		AddBroker(c.Broker).
+		SetClientID(clientID).
+		SetTLSConfig(tlsConfig).
+		SetCustomOpenConnectionFn(func(broker *url.URL, options mqtt.ClientOptions) (net.Conn, error) {
+			return openMQTTConnection(ctx, dial, broker, options)
+		})
+	if c.Username != "" {
+		opts.SetUsername(c.Username)
+		opts.SetPassword(c.Password)
+	}
+
+	return opts, nil
+}
+
+// openMQTTConnection connects to the broker with dial, for the schemes the
+// MQTT client supports over the network
+func openMQTTConnection(ctx context.Context, dial DialFunc, broker *url.URL, options mqtt.ClientOptions) (net.Conn, error) {
+	timeout := options.ConnectTimeout
+	if timeout == 0 {
+		timeout = 30 * time.Second
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	switch broker.Scheme {
+	case "mqtt", "tcp":
+		return dial(ctx, "tcp", broker.Host)
+	case "ssl", "tls", "mqtts", "mqtt+ssl", "tcps":
+		return dialTLS(ctx, dial, "tcp", broker.Host, options.TLSConfig)
+	case "ws", "wss":
+		dialer := &websocket.Dialer{
+			NetDialContext:   dial,
+			HandshakeTimeout: timeout,
+			TLSClientConfig:  options.TLSConfig,
+			Subprotocols:     []string{"mqtt"},
+		}
+		// The client connects with credentials, not URL user info
+		target := *broker
+		target.User = nil
+		ws, _, err := dialer.DialContext(ctx, target.String(), options.HTTPHeaders)
+		if err != nil {
+			return nil, err
+		}
+		return &mqttWebsocketConn{Conn: ws}, nil
+	default:
+		return nil, fmt.Errorf("unsupported broker scheme: %s", broker.Scheme)
+	}
+}
+
+// mqttWebsocketConn carries an MQTT stream in binary websocket messages
+type mqttWebsocketConn struct {
+	*websocket.Conn
+	reader io.Reader
+	readMu sync.Mutex
+	sendMu sync.Mutex
+}
+
+func (c *mqttWebsocketConn) Read(p []byte) (int, error) {
+	c.readMu.Lock()
+	defer c.readMu.Unlock()
+
+	for {
+		if c.reader == nil {
+			_, reader, err := c.NextReader()
+			if err != nil {
+				return 0, err
+			}
+			c.reader = reader
+		}
+		n, err := c.reader.Read(p)
+		if err == io.EOF {
+			// The message is done; continue with the next one
+			c.reader = nil
+			if n > 0 {
+				return n, nil
+			}
+			continue
+		}
+		return n, err
+	}
+}
+
+func (c *mqttWebsocketConn) Write(p []byte) (int, error) {
+	c.sendMu.Lock()
+	defer c.sendMu.Unlock()
+
+	if err := c.WriteMessage(websocket.BinaryMessage, p); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+func (c *mqttWebsocketConn) SetDeadline(t time.Time) error {
+	if err := c.SetReadDeadline(t); err != nil {
+		return err
+	}
+	return c.SetWriteDeadline(t)
+}
+
+// waitMQTTToken waits for a token to complete, the timeout, or ctx
+func waitMQTTToken(ctx context.Context, token mqtt.Token, timeout time.Duration) error {
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case <-token.Done():
+		return token.Error()
+	case <-timer.C:
+		return fmt.Errorf("timed out after %s", timeout)
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// encodeMQTTMessage serializes a message payload
+func encodeMQTTMessage(format string, value interface{}) ([]byte, error) {
+	if format == "string" {
+		if s, ok := value.(string); ok {
+			return []byte(s), nil
+		}
+		return []byte(fmt.Sprintf("%v", value)), nil
+	}
+	return json.Marshal(value)
+}
+
+// DecodeMQTTMessage deserializes a message payload, falling back to a string
+// for payloads that are not JSON
+func DecodeMQTTMessage(format string, payload []byte) interface{} {
+	if format != "string" {
+		var value interface{}
+		if err := json.Unmarshal(payload, &value); err == nil {
+			return value
+		}
+	}
+	return string(payload)
+}
+
+// mqttConnectionProperties returns schema properties for MQTTConnection
+func mqttConnectionProperties() map[string]engine.Property {
+	return map[string]engine.Property{
+		"broker": {
+			Type:        "string",
+			Title:       "Broker",
+			Description: "Broker URL, e.g. tcp://localhost:1883 or ssl://broker:8883",
+		},
+		"client_id": {
+			Type:        "string",
+			Title:       "Client ID",
+			Description: "MQTT client identifier; generated when empty",
+		},
+		"username": {
+			Type:        "string",
+			Title:       "Username",
+			Description: "Broker username",
+		},
+		"password": {
+			Type:        "string",
+			Title:       "Password",
+			Description: "Broker password",
+			Format:      "password",
+		},
+		"ca_cert": {
+			Type:        "string",
+			Title:       "CA Certificate",
+			Description: "PEM encoded CA certificate used to verify the broker",
+			Format:      "textarea",
+		},
+		"client_cert": {
+			Type:        "string",
+			Title:       "Client Certificate",
+			Description: "PEM encoded client certificate for mutual TLS",
+			Format:      "textarea",
+		},
+		"client_key": {
+			Type:        "string",
+			Title:       "Client Key",
+			Description: "PEM encoded client private key for mutual TLS",
+			Format:      "password",
+		},
+	}
+}
@@ -0,0 +1,147 @@
+package nodes
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/nuumz/f1ow/internal/engine"
+)
+
+// MQTTTriggerNode starts executions for messages received on MQTT topics
+type MQTTTriggerNode struct {
+	BaseNode
+}
+
+// MQTTTriggerConfig defines configuration for mqtt trigger node
+type MQTTTriggerConfig struct {
+	MQTTConnection
+	Topics       []string `json:"topics"` // supports + and # wildcards
+	Format       string   `json:"format"` // "json", "string"
+	QoS          int      `json:"qos"`
+	CleanSession bool     `json:"clean_session"`
+	SkipRetained bool     `json:"skip_retained"` // Ignore retained messages delivered on subscribe
+}
+
+// NewMQTTTriggerNode creates a new mqtt trigger node
+func NewMQTTTriggerNode() engine.NodeType {
+	return &MQTTTriggerNode{
+		BaseNode: BaseNode{
+			nodeType:    "mqtt_trigger",
+			name:        "MQTT Subscribe",
+			description: "Start the workflow for each message published to subscribed MQTT topics",
+			category:    "Triggers",
+			icon:        "radio",
+		},
+	}
+}
+
+// Execute passes the received message through to downstream nodes
+func (n *MQTTTriggerNode) Execute(ctx context.Context, config interface{}, input interface{}) (interface{}, error) {
+	if inputMap, ok := input.(map[string]interface{}); ok {
+		return inputMap, nil
+	}
+	return map[string]interface{}{"data": input}, nil
+}
+
+// ValidateConfig validates the node configuration
+func (n *MQTTTriggerNode) ValidateConfig(config interface{}) error {
+	mqttConfig, err := ParseMQTTTriggerConfig(config)
+	if err != nil {
+		return err
+	}
+
+	if err := mqttConfig.MQTTConnection.Validate(); err != nil {
+		return err
+	}
+
+	if len(mqttConfig.Topics) == 0 {
+		return fmt.Errorf("at least one topic is required")
+	}
+
+	if mqttConfig.QoS < 0 || mqttConfig.QoS > 2 {
+		return fmt.Errorf("qos must be 0, 1, or 2")
+	}
+
+	if mqttConfig.Format != "json" && mqttConfig.Format != "string" {
+		return fmt.Errorf("invalid format: %s", mqttConfig.Format)
+	}
+
+	return nil
+}
+
+// GetSchema returns the node configuration schema
+func (n *MQTTTriggerNode) GetSchema() engine.NodeSchema {
+	properties := mqttConnectionProperties()
+	properties["topics"] = engine.Property{
+		Type:        "array",
+		Title:       "Topics",
+		Description: "Topic filters to subscribe to; + and # wildcards are supported",
+	}
+	properties["format"] = engine.Property{
+		Type:        "string",
+		Title:       "Format",
+		Description: "Payload encoding",
+		Default:     "json",
+		Enum:        []string{"json", "string"},
+	}
+	properties["qos"] = engine.Property{
+		Type:        "number",
+		Title:       "QoS",
+		Description: "Subscription QoS level",
+		Default:     1,
+	}
+	properties["clean_session"] = engine.Property{
+		Type:        "boolean",
+		Title:       "Clean Session",
+		Description: "Discard queued messages from previous sessions; requires a fixed client ID when disabled",
+		Default:     true,
+	}
+	properties["skip_retained"] = engine.Property{
+		Type:        "boolean",
+		Title:       "Skip Retained",
+		Description: "Ignore retained messages delivered when subscribing",
+		Default:     false,
+	}
+
+	return engine.NodeSchema{
+		Type:       "object",
+		Properties: properties,
+		Required:   []string{"broker", "topics"},
+		Inputs:     []engine.PortSchema{},
+		Outputs: []engine.PortSchema{
+			{
+				Name:        "output",
+				Type:        "object",
+				Description: "Received message with topic, payload, and delivery flags",
+				Required:    true,
+			},
+		},
+	}
+}
+
+// ParseMQTTTriggerConfig parses an mqtt trigger configuration and applies defaults
+func ParseMQTTTriggerConfig(config interface{}) (*MQTTTriggerConfig, error) {
+	configMap, ok := config.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("invalid config type for mqtt trigger node")
+	}
+
+	configJSON, err := json.Marshal(configMap)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal config: %w", err)
+	}
+
+	// Subscriptions default to QoS 1 and clean sessions
+	mqttConfig := MQTTTriggerConfig{QoS: 1, CleanSession: true}
+	if err := json.Unmarshal(configJSON, &mqttConfig); err != nil {
+		return nil, fmt.Errorf("failed to parse mqtt trigger config: %w", err)
+	}
+
+	// Set defaults
+	if mqttConfig.Format == "" {
+		mqttConfig.Format = "json"
+	}
+
+	return &mqttConfig, nil
+}
@@ -0,0 +1,133 @@
+package nodes
+
+import (
+	"context"
+	"crypto/tls"
+	"database/sql"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/nuumz/f1ow/internal/netpolicy"
+	"github.com/nuumz/f1ow/internal/tenant"
+	"github.com/nuumz/f1ow/internal/usage"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/lib/pq"
+)
+
+// DialFunc opens a network connection
+type DialFunc func(ctx context.Context, network, address string) (net.Conn, error)
+
+// guardClient returns client held to the network policy in the context, if
+// any, so requests cannot reach addresses the policy blocks, and counting
+// the bytes it transfers on the context's usage meter
+func guardClient(ctx context.Context, client *http.Client) *http.Client {
+	if policy, ok := netpolicy.FromContext(ctx); ok {
+		client = policy.Client(client)
+	}
+	if meter, ok := usage.FromContext(ctx); ok {
+		client = meter.Client(client)
+	}
+	return client
+}
+
+// Dialer returns the function nodes and triggers that open their own
+// connections dial with: through the network policy in the context, if
+// any. Connections are checked for the context's tenant even when the
+// caller dials with a context of its own.
+func Dialer(ctx context.Context) DialFunc {
+	policy, ok := netpolicy.FromContext(ctx)
+	if !ok {
+		var d net.Dialer
+		return d.DialContext
+	}
+	id, scoped := tenant.FromContext(ctx)
+	return func(dialCtx context.Context, network, address string) (net.Conn, error) {
+		if scoped {
+			dialCtx = tenant.WithID(dialCtx, id)
+		}
+		return policy.DialContext(dialCtx, network, address)
+	}
+}
+
+// dialTLS dials address with dial and completes a TLS handshake. The
+// server name defaults to the host of address.
+func dialTLS(ctx context.Context, dial DialFunc, network, address string, config *tls.Config) (net.Conn, error) {
+	conn, err := dial(ctx, network, address)
+	if err != nil {
+		return nil, err
+	}
+
+	if config == nil {
+		config = &tls.Config{}
+	}
+	if config.ServerName == "" {
+		config = config.Clone()
+		config.ServerName, _, _ = net.SplitHostPort(address)
+	}
+	tlsConn := tls.Client(conn, config)
+	if err := tlsConn.HandshakeContext(ctx); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return tlsConn, nil
+}
+
+// guardTransport returns a copy of transport, or of the default transport
+// when nil, whose connections are made with dial
+func guardTransport(transport *http.Transport, dial DialFunc) *http.Transport {
+	if transport == nil {
+		transport = http.DefaultTransport.(*http.Transport)
+	}
+	transport = transport.Clone()
+	transport.DialContext = dial
+	return transport
+}
+
+// OpenDatabase opens a postgres or mysql database whose connections are
+// made through the network policy in ctx, if any
+func OpenDatabase(ctx context.Context, driver, dsn string) (*sql.DB, error) {
+	dial := Dialer(ctx)
+
+	switch driver {
+	case "postgres":
+		connector, err := pq.NewConnector(dsn)
+		if err != nil {
+			return nil, err
+		}
+		connector.Dialer(pqDialer(dial))
+		return sql.OpenDB(connector), nil
+	case "mysql":
+		config, err := mysql.ParseDSN(dsn)
+		if err != nil {
+			return nil, err
+		}
+		config.DialFunc = dial
+		connector, err := mysql.NewConnector(config)
+		if err != nil {
+			return nil, err
+		}
+		return sql.OpenDB(connector), nil
+	default:
+		return nil, fmt.Errorf("unsupported database driver: %s", driver)
+	}
+}
+
+// pqDialer adapts a DialFunc to the dialer interfaces of lib/pq
+type pqDialer DialFunc
+
+func (d pqDialer) Dial(network, address string) (net.Conn, error) {
+	return d(context.Background(), network, address)
+}
+
+func (d pqDialer) DialTimeout(network, address string, timeout time.Duration) (net.Conn, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	return d(ctx, network, address)
+}
+
+func (d pqDialer) DialContext(ctx context.Context, network, address string) (net.Conn, error) {
+	return d(ctx, network, address)
+}
@@ -0,0 +1,402 @@
+package nodes
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/nuumz/f1ow/internal/engine"
+)
+
+const defaultTelegramAPIURL = "https://api.telegram.org"
+
+// NotifyNode sends chat notifications to Discord, Microsoft Teams, or Telegram
+type NotifyNode struct {
+	BaseNode
+}
+
+// NotifyConfig defines configuration for notify node
+type NotifyConfig struct {
+	Provider   string        `json:"provider"` // "discord", "teams", "telegram"
+	WebhookURL string        `json:"webhook_url"`
+	BotToken   string        `json:"bot_token"`
+	ChatID     string        `json:"chat_id"`
+	Title      string        `json:"title"`
+	Message    string        `json:"message"`
+	Fields     []NotifyField `json:"fields"`
+	Color      string        `json:"color"` // hex color, e.g. "#ff0000"
+	Username   string        `json:"username"`
+	AvatarURL  string        `json:"avatar_url"`
+	ParseMode  string        `json:"parse_mode"` // Telegram: "HTML", "MarkdownV2"
+	Silent     bool          `json:"silent"`
+	APIURL     string        `json:"api_url"` // Telegram Bot API base URL override
+	Timeout    int           `json:"timeout"` // seconds
+}
+
+// NotifyField is a name/value pair rendered as an embed field or card fact
+type NotifyField struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// NewNotifyNode creates a new notify node
+func NewNotifyNode() engine.NodeType {
+	return &NotifyNode{
+		BaseNode: BaseNode{
+			nodeType:    "notify",
+			name:        "Chat Notification",
+			description: "Send templated messages to Discord, Microsoft Teams, or Telegram",
+			category:    "Communication",
+			icon:        "message-square",
+		},
+	}
+}
+
+// Execute sends the notification
+func (n *NotifyNode) Execute(ctx context.Context, config interface{}, input interface{}) (interface{}, error) {
+	notifyConfig, err := n.parseConfig(config)
+	if err != nil {
+		return nil, err
+	}
+
+	var url string
+	var payload map[string]interface{}
+
+	switch notifyConfig.Provider {
+	case "discord":
+		url = processTemplate(notifyConfig.WebhookURL, input)
+		payload = n.discordPayload(notifyConfig, input)
+	case "teams":
+		url = processTemplate(notifyConfig.WebhookURL, input)
+		payload = n.teamsPayload(notifyConfig, input)
+	case "telegram":
+		url = fmt.Sprintf("%s/bot%s/sendMessage", strings.TrimRight(notifyConfig.APIURL, "/"),
+			processTemplate(notifyConfig.BotToken, input))
+		payload = n.telegramPayload(notifyConfig, input)
+	default:
+		return nil, fmt.Errorf("unsupported provider: %s", notifyConfig.Provider)
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal notification: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	client := guardClient(ctx, &http.Client{Timeout: time.Duration(notifyConfig.Timeout) * time.Second})
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send notification: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	if resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("%s returned status %d: %s", notifyConfig.Provider, resp.StatusCode, string(respBody))
+	}
+
+	result := map[string]interface{}{
+		"provider":   notifyConfig.Provider,
+		"statusCode": resp.StatusCode,
+	}
+
+	var jsonBody interface{}
+	if err := json.Unmarshal(respBody, &jsonBody); err == nil {
+		result["response"] = jsonBody
+	} else if len(respBody) > 0 {
+		result["response"] = string(respBody)
+	}
+
+	return result, nil
+}
+
+// ValidateConfig validates the node configuration
+func (n *NotifyNode) ValidateConfig(config interface{}) error {
+	notifyConfig, err := n.parseConfig(config)
+	if err != nil {
+		return err
+	}
+
+	switch notifyConfig.Provider {
+	case "discord", "teams":
+		if notifyConfig.WebhookURL == "" {
+			return fmt.Errorf("webhook_url is required for %s", notifyConfig.Provider)
+		}
+	case "telegram":
+		if notifyConfig.BotToken == "" {
+			return fmt.Errorf("bot_token is required for telegram")
+		}
+		if notifyConfig.ChatID == "" {
+			return fmt.Errorf("chat_id is required for telegram")
+		}
+	default:
+		return fmt.Errorf("invalid provider: %s", notifyConfig.Provider)
+	}
+
+	if notifyConfig.Message == "" && notifyConfig.Title == "" {
+		return fmt.Errorf("message or title is required")
+	}
+
+	if notifyConfig.Color != "" {
+		if _, err := parseHexColor(notifyConfig.Color); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// GetSchema returns the node configuration schema
+func (n *NotifyNode) GetSchema() engine.NodeSchema {
+	return engine.NodeSchema{
+		Type: "object",
+		Properties: map[string]engine.Property{
+			"provider": {
+				Type:        "string",
+				Title:       "Provider",
+				Description: "Chat service to notify",
+				Enum:        []string{"discord", "teams", "telegram"},
+			},
+			"webhook_url": {
+				Type:        "string",
+				Title:       "Webhook URL",
+				Description: "Incoming webhook URL (Discord and Teams)",
+				Format:      "password",
+			},
+			"bot_token": {
+				Type:        "string",
+				Title:       "Bot Token",
+				Description: "Telegram bot token",
+				Format:      "password",
+			},
+			"chat_id": {
+				Type:        "string",
+				Title:       "Chat ID",
+				Description: "Telegram chat, group, or channel ID",
+			},
+			"title": {
+				Type:        "string",
+				Title:       "Title",
+				Description: "Message title. Supports template variables like {{variable}}",
+			},
+			"message": {
+				Type:        "string",
+				Title:       "Message",
+				Description: "Message text. Supports template variables like {{variable}}",
+				Format:      "textarea",
+			},
+			"fields": {
+				Type:        "array",
+				Title:       "Fields",
+				Description: "Name/value pairs shown as embed fields (Discord) or facts (Teams)",
+			},
+			"color": {
+				Type:        "string",
+				Title:       "Color",
+				Description: "Discord embed accent color as hex (e.g., #36a64f)",
+			},
+			"parse_mode": {
+				Type:        "string",
+				Title:       "Parse Mode",
+				Description: "Telegram message formatting",
+				Enum:        []string{"", "HTML", "MarkdownV2"},
+			},
+			"silent": {
+				Type:        "boolean",
+				Title:       "Silent",
+				Description: "Send without notification sound (Telegram)",
+				Default:     false,
+			},
+			"timeout": {
+				Type:        "number",
+				Title:       "Timeout",
+				Description: "Request timeout in seconds",
+				Default:     30,
+			},
+		},
+		Required: []string{"provider"},
+		Inputs: []engine.PortSchema{
+			{
+				Name:        "input",
+				Type:        "any",
+				Description: "Input data available for template variables",
+				Required:    false,
+			},
+		},
+		Outputs: []engine.PortSchema{
+			{
+				Name:        "output",
+				Type:        "object",
+				Description: "Delivery result from the provider",
+				Required:    true,
+			},
+		},
+	}
+}
+
+// parseConfig parses the node configuration
+func (n *NotifyNode) parseConfig(config interface{}) (*NotifyConfig, error) {
+	configMap, ok := config.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("invalid config type for notify node")
+	}
+
+	configJSON, err := json.Marshal(configMap)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal config: %w", err)
+	}
+
+	var notifyConfig NotifyConfig
+	if err := json.Unmarshal(configJSON, &notifyConfig); err != nil {
+		return nil, fmt.Errorf("failed to parse notify config: %w", err)
+	}
+
+	// Set defaults
+	notifyConfig.Provider = strings.ToLower(notifyConfig.Provider)
+	if notifyConfig.APIURL == "" {
+		notifyConfig.APIURL = defaultTelegramAPIURL
+	}
+	if notifyConfig.Timeout == 0 {
+		notifyConfig.Timeout = 30
+	}
+
+	return &notifyConfig, nil
+}
+
+// discordPayload builds a Discord webhook message with an embed
+func (n *NotifyNode) discordPayload(config *NotifyConfig, input interface{}) map[string]interface{} {
+	payload := map[string]interface{}{}
+	if config.Username != "" {
+		payload["username"] = processTemplate(config.Username, input)
+	}
+	if config.AvatarURL != "" {
+		payload["avatar_url"] = processTemplate(config.AvatarURL, input)
+	}
+
+	// Plain messages without title or fields are sent as content
+	if config.Title == "" && len(config.Fields) == 0 && config.Color == "" {
+		payload["content"] = processTemplate(config.Message, input)
+		return payload
+	}
+
+	embed := map[string]interface{}{
+		"title":       processTemplate(config.Title, input),
+		"description": processTemplate(config.Message, input),
+	}
+	if color, err := parseHexColor(config.Color); err == nil && config.Color != "" {
+		embed["color"] = color
+	}
+	if len(config.Fields) > 0 {
+		fields := make([]interface{}, len(config.Fields))
+		for i, field := range config.Fields {
+			fields[i] = map[string]interface{}{
+				"name":   processTemplate(field.Name, input),
+				"value":  processTemplate(field.Value, input),
+				"inline": true,
+			}
+		}
+		embed["fields"] = fields
+	}
+	payload["embeds"] = []interface{}{embed}
+
+	return payload
+}
+
+// teamsPayload builds a Microsoft Teams adaptive card message
+func (n *NotifyNode) teamsPayload(config *NotifyConfig, input interface{}) map[string]interface{} {
+	body := []interface{}{}
+	if config.Title != "" {
+		body = append(body, map[string]interface{}{
+			"type":   "TextBlock",
+			"text":   processTemplate(config.Title, input),
+			"weight": "Bolder",
+			"size":   "Medium",
+			"wrap":   true,
+		})
+	}
+	if config.Message != "" {
+		body = append(body, map[string]interface{}{
+			"type": "TextBlock",
+			"text": processTemplate(config.Message, input),
+			"wrap": true,
+		})
+	}
+	if len(config.Fields) > 0 {
+		facts := make([]interface{}, len(config.Fields))
+		for i, field := range config.Fields {
+			facts[i] = map[string]interface{}{
+				"title": processTemplate(field.Name, input),
+				"value": processTemplate(field.Value, input),
+			}
+		}
+		body = append(body, map[string]interface{}{
+			"type":  "FactSet",
+			"facts": facts,
+		})
+	}
+
+	return map[string]interface{}{
+		"type": "message",
+		"attachments": []interface{}{
+			map[string]interface{}{
+				"contentType": "application/vnd.microsoft.card.adaptive",
+				"content": map[string]interface{}{
+					"$schema": "http://adaptivecards.io/schemas/adaptive-card.json",
+					"type":    "AdaptiveCard",
+					"version": "1.4",
+					"body":    body,
+				},
+			},
+		},
+	}
+}
+
+// telegramPayload builds a Telegram sendMessage request
+func (n *NotifyNode) telegramPayload(config *NotifyConfig, input interface{}) map[string]interface{} {
+	text := processTemplate(config.Message, input)
+	if config.Title != "" {
+		title := processTemplate(config.Title, input)
+		if config.ParseMode == "HTML" {
+			title = "<b>" + title + "</b>"
+		}
+		text = title + "\n\n" + text
+	}
+	for _, field := range config.Fields {
+		text += fmt.Sprintf("\n%s: %s", processTemplate(field.Name, input), processTemplate(field.Value, input))
+	}
+
+	payload := map[string]interface{}{
+		"chat_id":              processTemplate(config.ChatID, input),
+		"text":                 strings.TrimSpace(text),
+		"disable_notification": config.Silent,
+	}
+	if config.ParseMode != "" {
+		payload["parse_mode"] = config.ParseMode
+	}
+
+	return payload
+}
+
+// parseHexColor converts "#rrggbb" into its integer value
+func parseHexColor(color string) (int64, error) {
+	value, err := strconv.ParseInt(strings.TrimPrefix(color, "#"), 16, 32)
+	if err != nil || len(strings.TrimPrefix(color, "#")) != 6 {
+		return 0, fmt.Errorf("invalid color: %s", color)
+	}
+	return value, nil
+}
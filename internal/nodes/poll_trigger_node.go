@@ -0,0 +1,263 @@
+package nodes
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/nuumz/f1ow/internal/engine"
+)
+
+// PollTriggerNode starts executions for new items found by polling an HTTP
+// endpoint or a database query
+type PollTriggerNode struct {
+	BaseNode
+}
+
+// PollTriggerConfig defines configuration for poll trigger node
+type PollTriggerConfig struct {
+	Source        string                 `json:"source"`         // "http", "database"
+	Request       map[string]interface{} `json:"request"`        // HTTP node config (http)
+	ItemsPath     string                 `json:"items_path"`     // dot path to the item array in the response body (http); empty means the body itself
+	Driver        string                 `json:"driver"`         // "postgres", "mysql" (database)
+	DSN           string                 `json:"dsn"`            // connection string (database)
+	Query         string                 `json:"query"`          // SELECT returning one row per item (database)
+	PassCursor    bool                   `json:"pass_cursor"`    // pass the stored cursor as the query's only parameter (database, cursor)
+	Dedupe        string                 `json:"dedupe"`         // "cursor", "id", "hash"
+	CursorField   string                 `json:"cursor_field"`   // dot path to an increasing value such as updated_at (cursor)
+	InitialCursor string                 `json:"initial_cursor"` // cursor before the first poll
+	IDField       string                 `json:"id_field"`       // dot path to a unique item ID (id)
+	PollInterval  int                    `json:"poll_interval"`  // seconds
+	MaxItems      int                    `json:"max_items"`      // executions started per poll
+	EmitExisting  bool                   `json:"emit_existing"`  // start executions for the items found by the first poll
+}
+
+// NewPollTriggerNode creates a new poll trigger node
+func NewPollTriggerNode() engine.NodeType {
+	return &PollTriggerNode{
+		BaseNode: BaseNode{
+			nodeType:    "poll_trigger",
+			name:        "Poll for Changes",
+			description: "Poll an HTTP endpoint or database query and start the workflow for each new item",
+			category:    "Triggers",
+			icon:        "refresh-cw",
+		},
+	}
+}
+
+// Execute passes the new item through to downstream nodes
+func (n *PollTriggerNode) Execute(ctx context.Context, config interface{}, input interface{}) (interface{}, error) {
+	if inputMap, ok := input.(map[string]interface{}); ok {
+		return inputMap, nil
+	}
+	return map[string]interface{}{"data": input}, nil
+}
+
+// ValidateConfig validates the node configuration
+func (n *PollTriggerNode) ValidateConfig(config interface{}) error {
+	pollConfig, err := ParsePollTriggerConfig(config)
+	if err != nil {
+		return err
+	}
+
+	switch pollConfig.Source {
+	case "http":
+		if len(pollConfig.Request) == 0 {
+			return fmt.Errorf("request is required for http polling")
+		}
+		if err := NewHTTPNode(nil).ValidateConfig(pollConfig.Request); err != nil {
+			return fmt.Errorf("invalid request: %w", err)
+		}
+	case "database":
+		if pollConfig.Driver != "postgres" && pollConfig.Driver != "mysql" {
+			return fmt.Errorf("invalid driver: %s", pollConfig.Driver)
+		}
+		if pollConfig.DSN == "" || pollConfig.Query == "" {
+			return fmt.Errorf("dsn and query are required for database polling")
+		}
+		if pollConfig.PassCursor && (pollConfig.Dedupe != "cursor" || pollConfig.InitialCursor == "") {
+			return fmt.Errorf("pass_cursor requires cursor dedupe and an initial_cursor")
+		}
+	default:
+		return fmt.Errorf("invalid source: %s", pollConfig.Source)
+	}
+
+	switch pollConfig.Dedupe {
+	case "cursor":
+		if pollConfig.CursorField == "" {
+			return fmt.Errorf("cursor_field is required for cursor dedupe")
+		}
+	case "id":
+		if pollConfig.IDField == "" {
+			return fmt.Errorf("id_field is required for id dedupe")
+		}
+	case "hash":
+	default:
+		return fmt.Errorf("invalid dedupe: %s", pollConfig.Dedupe)
+	}
+
+	return nil
+}
+
+// GetSchema returns the node configuration schema
+func (n *PollTriggerNode) GetSchema() engine.NodeSchema {
+	return engine.NodeSchema{
+		Type: "object",
+		Properties: map[string]engine.Property{
+			"source": {
+				Type:        "string",
+				Title:       "Source",
+				Description: "What to poll",
+				Default:     "http",
+				Enum:        []string{"http", "database"},
+			},
+			"request": {
+				Type:        "object",
+				Title:       "Request",
+				Description: "HTTP request to send, configured like an HTTP Request node. ETag and Last-Modified responses are revalidated.",
+			},
+			"items_path": {
+				Type:        "string",
+				Title:       "Items Path",
+				Description: "Dot path to the item array in the response body; empty when the body is the array",
+			},
+			"driver": {
+				Type:        "string",
+				Title:       "Driver",
+				Description: "Database driver",
+				Enum:        []string{"postgres", "mysql"},
+			},
+			"dsn": {
+				Type:        "string",
+				Title:       "Connection String",
+				Description: "Database connection string",
+				Format:      "password",
+			},
+			"query": {
+				Type:        "string",
+				Title:       "Query",
+				Description: "SELECT returning one row per item",
+			},
+			"pass_cursor": {
+				Type:        "boolean",
+				Title:       "Pass Cursor",
+				Description: "Pass the stored cursor as the query's only parameter, e.g. WHERE updated_at > $1",
+				Default:     false,
+			},
+			"dedupe": {
+				Type:        "string",
+				Title:       "New Items",
+				Description: "How new items are recognised: a cursor field newer than the last seen value, an unseen ID, or an unseen item hash",
+				Default:     "cursor",
+				Enum:        []string{"cursor", "id", "hash"},
+			},
+			"cursor_field": {
+				Type:        "string",
+				Title:       "Cursor Field",
+				Description: "Dot path to an increasing value such as an ID or updated_at",
+			},
+			"initial_cursor": {
+				Type:        "string",
+				Title:       "Initial Cursor",
+				Description: "Cursor value before the first poll",
+			},
+			"id_field": {
+				Type:        "string",
+				Title:       "ID Field",
+				Description: "Dot path to a unique item ID",
+			},
+			"poll_interval": {
+				Type:        "number",
+				Title:       "Poll Interval",
+				Description: "Seconds between polls",
+				Default:     60,
+			},
+			"max_items": {
+				Type:        "number",
+				Title:       "Max Items",
+				Description: "Maximum number of executions started per poll; the rest follow on later polls",
+				Default:     100,
+			},
+			"emit_existing": {
+				Type:        "boolean",
+				Title:       "Emit Existing Items",
+				Description: "Start executions for the items found by the first poll instead of only recording them",
+				Default:     false,
+			},
+		},
+		Required: []string{"source"},
+		Inputs:   []engine.PortSchema{},
+		Outputs: []engine.PortSchema{
+			{
+				Name:        "output",
+				Type:        "object",
+				Description: "The new item",
+				Required:    true,
+			},
+		},
+	}
+}
+
+// ItemField returns the value at a dot path in a polled item
+func (c *PollTriggerConfig) ItemField(item interface{}, path string) interface{} {
+	itemMap, ok := item.(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	return getValueByPath(itemMap, path)
+}
+
+// Items returns the item array found at items_path in a response body
+func (c *PollTriggerConfig) Items(body interface{}) ([]interface{}, error) {
+	value := body
+	if c.ItemsPath != "" {
+		bodyMap, ok := body.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("response body is not an object")
+		}
+		value = getValueByPath(bodyMap, c.ItemsPath)
+	}
+
+	switch items := value.(type) {
+	case []interface{}:
+		return items, nil
+	case nil:
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("items at %q are not an array", c.ItemsPath)
+	}
+}
+
+// ParsePollTriggerConfig parses a poll trigger configuration and applies defaults
+func ParsePollTriggerConfig(config interface{}) (*PollTriggerConfig, error) {
+	configMap, ok := config.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("invalid config type for poll trigger node")
+	}
+
+	configJSON, err := json.Marshal(configMap)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal config: %w", err)
+	}
+
+	var pollConfig PollTriggerConfig
+	if err := json.Unmarshal(configJSON, &pollConfig); err != nil {
+		return nil, fmt.Errorf("failed to parse poll trigger config: %w", err)
+	}
+
+	// Set defaults
+	if pollConfig.Source == "" {
+		pollConfig.Source = "http"
+	}
+	if pollConfig.Dedupe == "" {
+		pollConfig.Dedupe = "cursor"
+	}
+	if pollConfig.PollInterval <= 0 {
+		pollConfig.PollInterval = 60
+	}
+	if pollConfig.MaxItems <= 0 {
+		pollConfig.MaxItems = 100
+	}
+
+	return &pollConfig, nil
+}
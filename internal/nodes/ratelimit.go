@@ -0,0 +1,89 @@
+package nodes
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/nuumz/f1ow/internal/engine"
+	"github.com/nuumz/f1ow/internal/ratelimit"
+)
+
+// RateLimitConfig throttles a node's outbound requests in addition to the
+// host limits configured on the server
+type RateLimitConfig struct {
+	Limit string `json:"limit"` // e.g. "10/s", "5000/h"
+	Burst int    `json:"burst"`
+	Scope string `json:"scope"` // "host", "credential", "workflow"
+}
+
+// validate checks the rate limit settings
+func (c *RateLimitConfig) validate() error {
+	if _, err := ratelimit.ParseRule(c.Limit); err != nil {
+		return err
+	}
+	switch c.Scope {
+	case "", "host", "credential", "workflow":
+	default:
+		return fmt.Errorf("invalid rate limit scope: %s", c.Scope)
+	}
+	if c.Burst < 0 {
+		return fmt.Errorf("rate limit burst must not be negative")
+	}
+	return nil
+}
+
+// waitRateLimit blocks until the request may be sent under the server's host
+// limits and the node's own limit, if configured
+func waitRateLimit(ctx context.Context, requestURL string, config *RateLimitConfig, credentialID string) error {
+	limiter, ok := ratelimit.FromContext(ctx)
+	if !ok {
+		if config != nil {
+			return fmt.Errorf("rate_limit is configured but no rate limiter is available")
+		}
+		return nil
+	}
+
+	if err := limiter.WaitURL(ctx, requestURL); err != nil {
+		return err
+	}
+	if config == nil {
+		return nil
+	}
+
+	rule, err := ratelimit.ParseRule(config.Limit)
+	if err != nil {
+		return err
+	}
+	rule.Burst = config.Burst
+
+	key, err := rateLimitKey(ctx, requestURL, config.Scope, credentialID)
+	if err != nil {
+		return err
+	}
+	return limiter.Wait(ctx, key, rule)
+}
+
+// rateLimitKey returns the bucket key for the node rate limit scope
+func rateLimitKey(ctx context.Context, requestURL, scope, credentialID string) (string, error) {
+	switch scope {
+	case "credential":
+		if credentialID == "" {
+			return "", fmt.Errorf("rate limit scope credential requires a credential_id")
+		}
+		return "node:credential:" + credentialID, nil
+	case "workflow":
+		info, ok := engine.ExecutionInfoFromContext(ctx)
+		if !ok || info.WorkflowID == "" {
+			return "", fmt.Errorf("rate limit scope workflow requires a workflow execution")
+		}
+		return "node:workflow:" + info.WorkflowID, nil
+	default:
+		parsed, err := url.Parse(requestURL)
+		if err != nil {
+			return "", fmt.Errorf("invalid URL: %w", err)
+		}
+		return "node:host:" + strings.ToLower(parsed.Hostname()), nil
+	}
+}
@@ -0,0 +1,315 @@
+package nodes
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/nuumz/f1ow/internal/engine"
+	"github.com/nuumz/f1ow/internal/storage"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// engineRedisKeyPrefix namespaces keys written to the engine's own Redis
+// so workflows cannot clobber queue or cache keys. Keys are further
+// namespaced by tenant.
+const engineRedisKeyPrefix = "wf:kv:"
+
+// RedisNode implements key/value, counter, list, and sorted set operations
+type RedisNode struct {
+	BaseNode
+	client redis.Cmdable
+}
+
+// RedisNodeConfig defines configuration for redis node
+type RedisNodeConfig struct {
+	Operation     string        `json:"operation"` // "get", "set", "del", "incr", "expire", "lpush", "zadd"
+	Key           string        `json:"key"`
+	Value         interface{}   `json:"value"`
+	Values        []interface{} `json:"values"`    // lpush
+	TTL           int           `json:"ttl"`       // seconds, for set and expire
+	Increment     int64         `json:"increment"` // incr
+	Score         float64       `json:"score"`     // zadd
+	ParseJSON     bool          `json:"parse_json"`
+	ConnectionURL string        `json:"connection_url"` // External instance, uses the engine's Redis when empty
+}
+
+var validRedisOperations = map[string]bool{
+	"get": true, "set": true, "del": true, "incr": true,
+	"expire": true, "lpush": true, "zadd": true,
+}
+
+// NewRedisNode creates a new redis node using the engine's Redis by default.
+// The client may be nil, in which case only external connections are available.
+func NewRedisNode(client redis.Cmdable) engine.NodeType {
+	return &RedisNode{
+		BaseNode: BaseNode{
+			nodeType:    "redis",
+			name:        "Redis",
+			description: "Read and write keys, counters, lists, and sorted sets in Redis",
+			category:    "Data Storage",
+			icon:        "database",
+		},
+		client: client,
+	}
+}
+
+// Execute runs the Redis operation
+func (n *RedisNode) Execute(ctx context.Context, config interface{}, input interface{}) (interface{}, error) {
+	redisConfig, err := n.parseConfig(config)
+	if err != nil {
+		return nil, err
+	}
+
+	client := n.client
+	key := processTemplate(redisConfig.Key, input)
+
+	if redisConfig.ConnectionURL != "" {
+		external, err := storage.NewRedisClientWithDialer(ctx, processTemplate(redisConfig.ConnectionURL, input), Dialer(ctx))
+		if err != nil {
+			return nil, fmt.Errorf("failed to connect to redis: %w", err)
+		}
+		defer external.Close()
+		client = external.Client()
+	} else {
+		if client == nil {
+			return nil, fmt.Errorf("engine redis is not available, connection_url is required")
+		}
+		key = tenantKey(ctx, engineRedisKeyPrefix, key)
+	}
+
+	result, err := n.runOperation(ctx, client, redisConfig, key, input)
+	if err != nil {
+		return nil, fmt.Errorf("redis %s failed: %w", redisConfig.Operation, err)
+	}
+
+	return map[string]interface{}{
+		"operation": redisConfig.Operation,
+		"key":       processTemplate(redisConfig.Key, input),
+		"result":    result,
+	}, nil
+}
+
+// ValidateConfig validates the node configuration
+func (n *RedisNode) ValidateConfig(config interface{}) error {
+	redisConfig, err := n.parseConfig(config)
+	if err != nil {
+		return err
+	}
+
+	if !validRedisOperations[redisConfig.Operation] {
+		return fmt.Errorf("invalid operation: %s", redisConfig.Operation)
+	}
+
+	if redisConfig.Key == "" {
+		return fmt.Errorf("key is required")
+	}
+
+	if redisConfig.Operation == "expire" && redisConfig.TTL <= 0 {
+		return fmt.Errorf("ttl must be positive for expire")
+	}
+
+	if redisConfig.Operation == "lpush" && len(redisConfig.Values) == 0 && redisConfig.Value == nil {
+		return fmt.Errorf("value or values is required for lpush")
+	}
+
+	return nil
+}
+
+// GetSchema returns the node configuration schema
+func (n *RedisNode) GetSchema() engine.NodeSchema {
+	return engine.NodeSchema{
+		Type: "object",
+		Properties: map[string]engine.Property{
+			"operation": {
+				Type:        "string",
+				Title:       "Operation",
+				Description: "Redis operation to perform",
+				Default:     "get",
+				Enum:        []string{"get", "set", "del", "incr", "expire", "lpush", "zadd"},
+			},
+			"key": {
+				Type:        "string",
+				Title:       "Key",
+				Description: "Key to operate on. Supports template variables like {{variable}}",
+			},
+			"value": {
+				Type:        "object",
+				Title:       "Value",
+				Description: "Value for set, member for zadd, or single value for lpush. Objects are stored as JSON",
+			},
+			"values": {
+				Type:        "array",
+				Title:       "Values",
+				Description: "Values to push for lpush",
+			},
+			"ttl": {
+				Type:        "number",
+				Title:       "TTL",
+				Description: "Expiration in seconds for set and expire (0 = no expiration)",
+				Default:     0,
+			},
+			"increment": {
+				Type:        "number",
+				Title:       "Increment",
+				Description: "Amount to add for incr",
+				Default:     1,
+			},
+			"score": {
+				Type:        "number",
+				Title:       "Score",
+				Description: "Score for zadd",
+			},
+			"parse_json": {
+				Type:        "boolean",
+				Title:       "Parse JSON",
+				Description: "Decode the value returned by get as JSON",
+				Default:     false,
+			},
+			"connection_url": {
+				Type:        "string",
+				Title:       "Connection URL",
+				Description: "External Redis URL (redis:// or redis-sentinel://). Uses the engine's Redis when empty",
+				Format:      "password",
+			},
+		},
+		Required: []string{"operation", "key"},
+		Inputs: []engine.PortSchema{
+			{
+				Name:        "input",
+				Type:        "any",
+				Description: "Input data available for template variables",
+				Required:    false,
+			},
+		},
+		Outputs: []engine.PortSchema{
+			{
+				Name:        "output",
+				Type:        "object",
+				Description: "Operation result",
+				Required:    true,
+			},
+		},
+	}
+}
+
+// parseConfig parses the node configuration
+func (n *RedisNode) parseConfig(config interface{}) (*RedisNodeConfig, error) {
+	configMap, ok := config.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("invalid config type for redis node")
+	}
+
+	configJSON, err := json.Marshal(configMap)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal config: %w", err)
+	}
+
+	var redisConfig RedisNodeConfig
+	if err := json.Unmarshal(configJSON, &redisConfig); err != nil {
+		return nil, fmt.Errorf("failed to parse redis config: %w", err)
+	}
+
+	// Set defaults
+	redisConfig.Operation = strings.ToLower(redisConfig.Operation)
+	if redisConfig.Operation == "" {
+		redisConfig.Operation = "get"
+	}
+	if redisConfig.Increment == 0 {
+		redisConfig.Increment = 1
+	}
+
+	return &redisConfig, nil
+}
+
+// runOperation executes the configured operation against the client
+func (n *RedisNode) runOperation(ctx context.Context, client redis.Cmdable, config *RedisNodeConfig, key string, input interface{}) (interface{}, error) {
+	ttl := time.Duration(config.TTL) * time.Second
+
+	switch config.Operation {
+	case "get":
+		value, err := client.Get(ctx, key).Result()
+		if err == redis.Nil {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if config.ParseJSON {
+			var parsed interface{}
+			if err := json.Unmarshal([]byte(value), &parsed); err == nil {
+				return parsed, nil
+			}
+		}
+		return value, nil
+
+	case "set":
+		value, err := redisValue(config.Value, input)
+		if err != nil {
+			return nil, err
+		}
+		return client.Set(ctx, key, value, ttl).Result()
+
+	case "del":
+		return client.Del(ctx, key).Result()
+
+	case "incr":
+		count, err := client.IncrBy(ctx, key, config.Increment).Result()
+		if err != nil {
+			return nil, err
+		}
+		if ttl > 0 {
+			if err := client.Expire(ctx, key, ttl).Err(); err != nil {
+				return nil, err
+			}
+		}
+		return count, nil
+
+	case "expire":
+		return client.Expire(ctx, key, ttl).Result()
+
+	case "lpush":
+		values := config.Values
+		if len(values) == 0 {
+			values = []interface{}{config.Value}
+		}
+		args := make([]interface{}, len(values))
+		for i, v := range values {
+			value, err := redisValue(v, input)
+			if err != nil {
+				return nil, err
+			}
+			args[i] = value
+		}
+		return client.LPush(ctx, key, args...).Result()
+
+	case "zadd":
+		member, err := redisValue(config.Value, input)
+		if err != nil {
+			return nil, err
+		}
+		return client.ZAdd(ctx, key, redis.Z{Score: config.Score, Member: member}).Result()
+
+	default:
+		return nil, fmt.Errorf("unsupported operation: %s", config.Operation)
+	}
+}
+
+// redisValue interpolates a value and encodes non-scalar values as JSON
+func redisValue(value interface{}, input interface{}) (interface{}, error) {
+	switch v := interpolateValue(value, input).(type) {
+	case string, int, int64, float64, bool:
+		return v, nil
+	case nil:
+		return "", nil
+	default:
+		encoded, err := json.Marshal(v)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode value: %w", err)
+		}
+		return string(encoded), nil
+	}
+}
@@ -0,0 +1,79 @@
+package nodes
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// restRequest calls a JSON REST endpoint and returns the decoded
+// JSON response, or nil for empty responses
+func restRequest(ctx context.Context, client *http.Client, method, url string, headers map[string]string, body interface{}) (interface{}, int, error) {
+	var reader io.Reader
+	if body != nil {
+		payload, err := json.Marshal(body)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to marshal request: %w", err)
+		}
+		reader = bytes.NewReader(payload)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, url, reader)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to create request: %w", err)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+
+	if err := waitRateLimit(ctx, url, nil, ""); err != nil {
+		return nil, 0, err
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, 0, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, resp.StatusCode, fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	if resp.StatusCode >= 300 {
+		return nil, resp.StatusCode, fmt.Errorf("API returned status %d: %s", resp.StatusCode, string(respBody))
+	}
+
+	if len(bytes.TrimSpace(respBody)) == 0 {
+		return nil, resp.StatusCode, nil
+	}
+
+	var result interface{}
+	if err := json.Unmarshal(respBody, &result); err != nil {
+		return nil, resp.StatusCode, fmt.Errorf("failed to parse response: %w", err)
+	}
+	return result, resp.StatusCode, nil
+}
+
+// setIfPresent adds a templated string field to a request body when configured
+func setIfPresent(body map[string]interface{}, key, template string, input interface{}) {
+	if template != "" {
+		body[key] = processTemplate(template, input)
+	}
+}
+
+// templateList resolves each entry of a list of templates
+func templateList(templates []string, input interface{}) []string {
+	values := make([]string, len(templates))
+	for i, template := range templates {
+		values[i] = processTemplate(template, input)
+	}
+	return values
+}
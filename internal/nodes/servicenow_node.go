@@ -0,0 +1,435 @@
+package nodes
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/nuumz/f1ow/internal/engine"
+)
+
+// ServiceNowNode creates, updates, transitions, comments on, and searches
+// ServiceNow incidents and other table records
+type ServiceNowNode struct {
+	BaseNode
+}
+
+// ServiceNowConfig defines configuration for servicenow node
+type ServiceNowConfig struct {
+	Operation   string `json:"operation"`
+	InstanceURL string `json:"instance_url"` // e.g. https://example.service-now.com
+	AuthType    string `json:"auth_type"`    // "basic", "bearer"
+	Username    string `json:"username"`
+	Password    string `json:"password"`
+	Token       string `json:"token"`
+
+	CredentialID string `json:"credential_id"` // vault credential supplying username/password or token
+	Table        string `json:"table"`
+
+	SysID            string                 `json:"sys_id"`
+	ShortDescription string                 `json:"short_description"`
+	Description      string                 `json:"description"`
+	Urgency          string                 `json:"urgency"`
+	Impact           string                 `json:"impact"`
+	AssignmentGroup  string                 `json:"assignment_group"`
+	Fields           map[string]interface{} `json:"fields"` // additional fields
+
+	State       string `json:"state"`        // target state for transition, e.g. "6" (Resolved)
+	CloseCode   string `json:"close_code"`   // resolution code when resolving
+	Comment     string `json:"comment"`      // customer-visible comment or work note
+	CommentType string `json:"comment_type"` // "comments", "work_notes"
+
+	Query        string   `json:"query"` // encoded query, e.g. active=true^priority=1
+	Limit        int      `json:"limit"`
+	Offset       int      `json:"offset"`
+	DisplayValue bool     `json:"display_value"` // return display values for reference fields
+	ReturnFields []string `json:"return_fields"`
+
+	Timeout int `json:"timeout"` // seconds
+}
+
+var validServiceNowOperations = map[string]bool{
+	"create": true, "update": true, "get": true, "transition": true, "add_comment": true, "search": true,
+}
+
+// NewServiceNowNode creates a new servicenow node
+func NewServiceNowNode() engine.NodeType {
+	return &ServiceNowNode{
+		BaseNode: BaseNode{
+			nodeType:    "servicenow",
+			name:        "ServiceNow",
+			description: "Create, update, transition, comment on, and search ServiceNow incidents",
+			category:    "Ticketing",
+			icon:        "ticket",
+		},
+	}
+}
+
+// Execute calls the ServiceNow Table API
+func (n *ServiceNowNode) Execute(ctx context.Context, config interface{}, input interface{}) (interface{}, error) {
+	snConfig, err := n.parseConfig(config)
+	if err != nil {
+		return nil, err
+	}
+
+	tableURL := fmt.Sprintf("%s/api/now/table/%s", strings.TrimRight(processTemplate(snConfig.InstanceURL, input), "/"),
+		url.PathEscape(processTemplate(snConfig.Table, input)))
+	recordURL := tableURL + "/" + url.PathEscape(processTemplate(snConfig.SysID, input))
+
+	query := url.Values{}
+	if snConfig.DisplayValue {
+		query.Set("sysparm_display_value", "true")
+	}
+	if len(snConfig.ReturnFields) > 0 {
+		query.Set("sysparm_fields", strings.Join(snConfig.ReturnFields, ","))
+	}
+
+	var method, endpoint string
+	var body map[string]interface{}
+
+	switch snConfig.Operation {
+	case "create":
+		method, endpoint = http.MethodPost, tableURL
+		body = n.recordFields(snConfig, input)
+	case "update":
+		method, endpoint = http.MethodPatch, recordURL
+		body = n.recordFields(snConfig, input)
+	case "get":
+		method, endpoint = http.MethodGet, recordURL
+	case "transition":
+		method, endpoint = http.MethodPatch, recordURL
+		body = n.recordFields(snConfig, input)
+		body["state"] = processTemplate(snConfig.State, input)
+		setIfPresent(body, "close_code", snConfig.CloseCode, input)
+		setIfPresent(body, "close_notes", snConfig.Comment, input)
+	case "add_comment":
+		// Journal fields append an entry rather than replacing the value
+		method, endpoint = http.MethodPatch, recordURL
+		body = map[string]interface{}{snConfig.CommentType: processTemplate(snConfig.Comment, input)}
+	case "search":
+		method, endpoint = http.MethodGet, tableURL
+		query.Set("sysparm_query", processTemplate(snConfig.Query, input))
+		query.Set("sysparm_limit", strconv.Itoa(snConfig.Limit))
+		query.Set("sysparm_offset", strconv.Itoa(snConfig.Offset))
+	default:
+		return nil, fmt.Errorf("unsupported operation: %s", snConfig.Operation)
+	}
+
+	if encoded := query.Encode(); encoded != "" {
+		endpoint += "?" + encoded
+	}
+
+	headers := map[string]string{
+		"Accept":        "application/json",
+		"Authorization": n.authorization(snConfig, input),
+	}
+
+	client := guardClient(ctx, &http.Client{Timeout: time.Duration(snConfig.Timeout) * time.Second})
+	var requestBody interface{}
+	if body != nil {
+		requestBody = body
+	}
+
+	response, status, err := restRequest(ctx, client, method, endpoint, headers, requestBody)
+	if err != nil {
+		return nil, fmt.Errorf("servicenow %s failed: %w", snConfig.Operation, err)
+	}
+
+	// The Table API wraps every response in {"result": ...}
+	if responseMap, ok := response.(map[string]interface{}); ok {
+		if result, ok := responseMap["result"]; ok {
+			response = result
+		}
+	}
+
+	return map[string]interface{}{
+		"operation":  snConfig.Operation,
+		"statusCode": status,
+		"data":       response,
+	}, nil
+}
+
+// ValidateConfig validates the node configuration
+func (n *ServiceNowNode) ValidateConfig(config interface{}) error {
+	snConfig, err := n.parseConfig(config)
+	if err != nil {
+		return err
+	}
+
+	if !validServiceNowOperations[snConfig.Operation] {
+		return fmt.Errorf("invalid operation: %s", snConfig.Operation)
+	}
+	if snConfig.InstanceURL == "" {
+		return fmt.Errorf("instance_url is required")
+	}
+
+	switch snConfig.AuthType {
+	case "basic":
+		if (snConfig.Username == "" || snConfig.Password == "") && snConfig.CredentialID == "" {
+			return fmt.Errorf("username and password are required for basic auth")
+		}
+	case "bearer":
+		if snConfig.Token == "" && snConfig.CredentialID == "" {
+			return fmt.Errorf("token is required for bearer auth")
+		}
+	default:
+		return fmt.Errorf("invalid auth_type: %s", snConfig.AuthType)
+	}
+
+	switch snConfig.Operation {
+	case "create":
+		if snConfig.ShortDescription == "" && len(snConfig.Fields) == 0 {
+			return fmt.Errorf("short_description or fields is required for create")
+		}
+	case "update", "get":
+		if snConfig.SysID == "" {
+			return fmt.Errorf("sys_id is required for %s", snConfig.Operation)
+		}
+	case "transition":
+		if snConfig.SysID == "" || snConfig.State == "" {
+			return fmt.Errorf("sys_id and state are required for transition")
+		}
+	case "add_comment":
+		if snConfig.SysID == "" || snConfig.Comment == "" {
+			return fmt.Errorf("sys_id and comment are required for add_comment")
+		}
+		if snConfig.CommentType != "comments" && snConfig.CommentType != "work_notes" {
+			return fmt.Errorf("invalid comment_type: %s", snConfig.CommentType)
+		}
+	}
+
+	return nil
+}
+
+// GetSchema returns the node configuration schema
+func (n *ServiceNowNode) GetSchema() engine.NodeSchema {
+	return engine.NodeSchema{
+		Type: "object",
+		Properties: map[string]engine.Property{
+			"operation": {
+				Type:        "string",
+				Title:       "Operation",
+				Description: "Action to perform",
+				Enum:        []string{"create", "update", "get", "transition", "add_comment", "search"},
+			},
+			"instance_url": {
+				Type:        "string",
+				Title:       "Instance URL",
+				Description: "ServiceNow instance URL, e.g. https://example.service-now.com",
+			},
+			"auth_type": {
+				Type:        "string",
+				Title:       "Authentication",
+				Description: "Basic auth with an integration user, or an OAuth bearer token",
+				Default:     "basic",
+				Enum:        []string{"basic", "bearer"},
+			},
+			"credential_id": {
+				Type:        "string",
+				Title:       "Credential",
+				Description: "Vault credential supplying username and password, or an OAuth2 credential",
+			},
+			"username": {
+				Type:        "string",
+				Title:       "Username",
+				Description: "Integration user for basic auth",
+			},
+			"password": {
+				Type:        "string",
+				Title:       "Password",
+				Description: "Password for basic auth",
+				Format:      "password",
+			},
+			"token": {
+				Type:        "string",
+				Title:       "Token",
+				Description: "OAuth access token for bearer auth",
+				Format:      "password",
+			},
+			"table": {
+				Type:        "string",
+				Title:       "Table",
+				Description: "Table to operate on",
+				Default:     "incident",
+			},
+			"sys_id": {
+				Type:        "string",
+				Title:       "Record sys_id",
+				Description: "sys_id of the record, e.g. {{data.sys_id}}",
+			},
+			"short_description": {
+				Type:        "string",
+				Title:       "Short Description",
+				Description: "Incident summary. Supports template variables like {{variable}}",
+			},
+			"description": {
+				Type:        "string",
+				Title:       "Description",
+				Description: "Incident details",
+				Format:      "textarea",
+			},
+			"urgency": {
+				Type:        "string",
+				Title:       "Urgency",
+				Description: "1 (High), 2 (Medium), or 3 (Low)",
+				Enum:        []string{"", "1", "2", "3"},
+			},
+			"impact": {
+				Type:        "string",
+				Title:       "Impact",
+				Description: "1 (High), 2 (Medium), or 3 (Low)",
+				Enum:        []string{"", "1", "2", "3"},
+			},
+			"assignment_group": {
+				Type:        "string",
+				Title:       "Assignment Group",
+				Description: "Assignment group sys_id or name",
+			},
+			"fields": {
+				Type:        "object",
+				Title:       "Additional Fields",
+				Description: "Extra record fields, e.g. {\"category\": \"network\"}",
+			},
+			"state": {
+				Type:        "string",
+				Title:       "State",
+				Description: "Target state value for transition, e.g. 2 (In Progress), 6 (Resolved), 7 (Closed)",
+			},
+			"close_code": {
+				Type:        "string",
+				Title:       "Resolution Code",
+				Description: "Resolution code when resolving or closing",
+			},
+			"comment": {
+				Type:        "string",
+				Title:       "Comment",
+				Description: "Comment or work note; used as resolution notes on transition",
+				Format:      "textarea",
+			},
+			"comment_type": {
+				Type:        "string",
+				Title:       "Comment Type",
+				Description: "Customer-visible comment or internal work note",
+				Default:     "comments",
+				Enum:        []string{"comments", "work_notes"},
+			},
+			"query": {
+				Type:        "string",
+				Title:       "Query",
+				Description: "Encoded query for search, e.g. active=true^priority=1",
+			},
+			"limit": {
+				Type:        "number",
+				Title:       "Limit",
+				Description: "Maximum records returned by search",
+				Default:     50,
+			},
+			"offset": {
+				Type:        "number",
+				Title:       "Offset",
+				Description: "Offset of the first search result",
+				Default:     0,
+			},
+			"display_value": {
+				Type:        "boolean",
+				Title:       "Display Values",
+				Description: "Return display values instead of sys_ids for reference fields",
+				Default:     false,
+			},
+			"return_fields": {
+				Type:        "array",
+				Title:       "Fields to Return",
+				Description: "Fields included in responses; all fields when empty",
+			},
+			"timeout": {
+				Type:        "number",
+				Title:       "Timeout",
+				Description: "Request timeout in seconds",
+				Default:     30,
+			},
+		},
+		Required: []string{"operation", "instance_url"},
+		Inputs: []engine.PortSchema{
+			{
+				Name:        "input",
+				Type:        "any",
+				Description: "Input data available for template variables",
+				Required:    false,
+			},
+		},
+		Outputs: []engine.PortSchema{
+			{
+				Name:        "output",
+				Type:        "object",
+				Description: "API response {operation, statusCode, data}",
+				Required:    true,
+			},
+		},
+	}
+}
+
+// parseConfig parses the node configuration
+func (n *ServiceNowNode) parseConfig(config interface{}) (*ServiceNowConfig, error) {
+	configMap, ok := config.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("invalid config type for servicenow node")
+	}
+
+	configJSON, err := json.Marshal(configMap)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal config: %w", err)
+	}
+
+	var snConfig ServiceNowConfig
+	if err := json.Unmarshal(configJSON, &snConfig); err != nil {
+		return nil, fmt.Errorf("failed to parse servicenow config: %w", err)
+	}
+
+	// Set defaults
+	if snConfig.AuthType == "" {
+		snConfig.AuthType = "basic"
+	}
+	if snConfig.Table == "" {
+		snConfig.Table = "incident"
+	}
+	if snConfig.CommentType == "" {
+		snConfig.CommentType = "comments"
+	}
+	if snConfig.Limit == 0 {
+		snConfig.Limit = 50
+	}
+	if snConfig.Timeout == 0 {
+		snConfig.Timeout = 30
+	}
+
+	return &snConfig, nil
+}
+
+// authorization builds the Authorization header value
+func (n *ServiceNowNode) authorization(config *ServiceNowConfig, input interface{}) string {
+	if config.AuthType == "bearer" {
+		return "Bearer " + processTemplate(config.Token, input)
+	}
+	credentials := processTemplate(config.Username, input) + ":" + processTemplate(config.Password, input)
+	return "Basic " + base64.StdEncoding.EncodeToString([]byte(credentials))
+}
+
+// recordFields builds the fields set by create, update, and transition
+func (n *ServiceNowNode) recordFields(config *ServiceNowConfig, input interface{}) map[string]interface{} {
+	fields := map[string]interface{}{}
+	setIfPresent(fields, "short_description", config.ShortDescription, input)
+	setIfPresent(fields, "description", config.Description, input)
+	setIfPresent(fields, "urgency", config.Urgency, input)
+	setIfPresent(fields, "impact", config.Impact, input)
+	setIfPresent(fields, "assignment_group", config.AssignmentGroup, input)
+	for key, value := range config.Fields {
+		fields[key] = interpolateValue(value, input)
+	}
+	return fields
+}
@@ -0,0 +1,403 @@
+package nodes
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/nuumz/f1ow/internal/engine"
+)
+
+// SetNode implements declarative field mapping (set, rename, remove, cast)
+type SetNode struct {
+	BaseNode
+}
+
+// SetConfig defines configuration for set node
+type SetConfig struct {
+	Operations  []SetOperation `json:"operations"`
+	KeepOnlySet bool           `json:"keep_only_set"` // Start from an empty object instead of the input
+}
+
+// SetOperation represents a single field operation, applied in order
+type SetOperation struct {
+	Operation string      `json:"operation"` // "set", "rename", "remove", "cast"
+	Path      string      `json:"path"`
+	Value     interface{} `json:"value"` // Value to set, supports {{template}} variables
+	To        string      `json:"to"`    // Target path for rename
+	Type      string      `json:"type"`  // Target type for set/cast: "string", "number", "integer", "boolean", "json", "array"
+}
+
+var validSetOperations = map[string]bool{
+	"set": true, "rename": true, "remove": true, "cast": true,
+}
+
+var validSetTypes = map[string]bool{
+	"": true, "string": true, "number": true, "integer": true,
+	"boolean": true, "json": true, "array": true,
+}
+
+// NewSetNode creates a new set node
+func NewSetNode() engine.NodeType {
+	return &SetNode{
+		BaseNode: BaseNode{
+			nodeType:    "set",
+			name:        "Set Fields",
+			description: "Set, rename, remove, and type-cast fields without writing code",
+			category:    "Data Processing",
+			icon:        "edit",
+		},
+	}
+}
+
+// Execute applies the field operations to the input data
+func (n *SetNode) Execute(ctx context.Context, config interface{}, input interface{}) (interface{}, error) {
+	setConfig, err := n.parseConfig(config)
+	if err != nil {
+		return nil, err
+	}
+
+	inputData := make(map[string]interface{})
+	if inputMap, ok := input.(map[string]interface{}); ok {
+		inputData = inputMap
+	} else if input != nil {
+		inputData["data"] = input
+	}
+
+	output := make(map[string]interface{})
+	if !setConfig.KeepOnlySet {
+		output = deepCopyMap(inputData)
+	}
+
+	for i, op := range setConfig.Operations {
+		if err := n.applyOperation(op, inputData, output); err != nil {
+			return nil, fmt.Errorf("operation %d (%s %s): %w", i, op.Operation, op.Path, err)
+		}
+	}
+
+	return output, nil
+}
+
+// ValidateConfig validates the node configuration
+func (n *SetNode) ValidateConfig(config interface{}) error {
+	setConfig, err := n.parseConfig(config)
+	if err != nil {
+		return err
+	}
+
+	if len(setConfig.Operations) == 0 {
+		return fmt.Errorf("at least one operation is required")
+	}
+
+	for i, op := range setConfig.Operations {
+		if !validSetOperations[op.Operation] {
+			return fmt.Errorf("operation %d: invalid operation: %s", i, op.Operation)
+		}
+		if op.Path == "" {
+			return fmt.Errorf("operation %d: path is required", i)
+		}
+		if op.Operation == "rename" && op.To == "" {
+			return fmt.Errorf("operation %d: to is required for rename", i)
+		}
+		if op.Operation == "cast" && op.Type == "" {
+			return fmt.Errorf("operation %d: type is required for cast", i)
+		}
+		if !validSetTypes[op.Type] {
+			return fmt.Errorf("operation %d: invalid type: %s", i, op.Type)
+		}
+	}
+
+	return nil
+}
+
+// GetSchema returns the node configuration schema
+func (n *SetNode) GetSchema() engine.NodeSchema {
+	return engine.NodeSchema{
+		Type: "object",
+		Properties: map[string]engine.Property{
+			"operations": {
+				Type:        "array",
+				Title:       "Operations",
+				Description: "Ordered list of operations (set, rename, remove, cast) using dot notation paths",
+			},
+			"keep_only_set": {
+				Type:        "boolean",
+				Title:       "Keep Only Set Fields",
+				Description: "Output only the fields written by operations instead of the modified input",
+				Default:     false,
+			},
+		},
+		Required: []string{"operations"},
+		Inputs: []engine.PortSchema{
+			{
+				Name:        "input",
+				Type:        "any",
+				Description: "Input data to reshape",
+				Required:    false,
+			},
+		},
+		Outputs: []engine.PortSchema{
+			{
+				Name:        "output",
+				Type:        "object",
+				Description: "Reshaped data",
+				Required:    true,
+			},
+		},
+	}
+}
+
+// parseConfig parses the node configuration
+func (n *SetNode) parseConfig(config interface{}) (*SetConfig, error) {
+	configMap, ok := config.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("invalid config type for set node")
+	}
+
+	configJSON, err := json.Marshal(configMap)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal config: %w", err)
+	}
+
+	var setConfig SetConfig
+	if err := json.Unmarshal(configJSON, &setConfig); err != nil {
+		return nil, fmt.Errorf("failed to parse set config: %w", err)
+	}
+
+	for i := range setConfig.Operations {
+		if setConfig.Operations[i].Operation == "" {
+			setConfig.Operations[i].Operation = "set"
+		}
+	}
+
+	return &setConfig, nil
+}
+
+// applyOperation applies a single operation to the output
+func (n *SetNode) applyOperation(op SetOperation, input, output map[string]interface{}) error {
+	switch op.Operation {
+	case "set":
+		value := op.Value
+		if str, ok := value.(string); ok {
+			value = resolveTemplateValue(str, input)
+		} else {
+			value = interpolateValue(value, input)
+		}
+		if op.Type != "" {
+			casted, err := castValue(value, op.Type)
+			if err != nil {
+				return err
+			}
+			value = casted
+		}
+		setValueByPath(output, op.Path, value)
+
+	case "rename":
+		value, exists := lookupPath(output, op.Path)
+		if !exists {
+			return nil
+		}
+		deleteValueByPath(output, op.Path)
+		setValueByPath(output, op.To, value)
+
+	case "remove":
+		deleteValueByPath(output, op.Path)
+
+	case "cast":
+		value, exists := lookupPath(output, op.Path)
+		if !exists {
+			return nil
+		}
+		casted, err := castValue(value, op.Type)
+		if err != nil {
+			return err
+		}
+		setValueByPath(output, op.Path, casted)
+
+	default:
+		return fmt.Errorf("unsupported operation: %s", op.Operation)
+	}
+
+	return nil
+}
+
+// castValue converts a value to the requested type
+func castValue(value interface{}, targetType string) (interface{}, error) {
+	switch targetType {
+	case "", "any":
+		return value, nil
+
+	case "string":
+		switch v := value.(type) {
+		case nil:
+			return "", nil
+		case string:
+			return v, nil
+		case map[string]interface{}, []interface{}:
+			bytes, err := json.Marshal(v)
+			if err != nil {
+				return nil, err
+			}
+			return string(bytes), nil
+		case float64:
+			return strconv.FormatFloat(v, 'f', -1, 64), nil
+		default:
+			return fmt.Sprintf("%v", v), nil
+		}
+
+	case "number":
+		if f, ok := toFloat64(value); ok {
+			return f, nil
+		}
+		if s, ok := value.(string); ok {
+			f, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+			if err != nil {
+				return nil, fmt.Errorf("cannot cast %q to number", s)
+			}
+			return f, nil
+		}
+		if b, ok := value.(bool); ok {
+			if b {
+				return float64(1), nil
+			}
+			return float64(0), nil
+		}
+		return nil, fmt.Errorf("cannot cast %T to number", value)
+
+	case "integer":
+		number, err := castValue(value, "number")
+		if err != nil {
+			return nil, err
+		}
+		return int64(number.(float64)), nil
+
+	case "boolean":
+		switch v := value.(type) {
+		case bool:
+			return v, nil
+		case string:
+			b, err := strconv.ParseBool(strings.TrimSpace(v))
+			if err != nil {
+				return nil, fmt.Errorf("cannot cast %q to boolean", v)
+			}
+			return b, nil
+		case nil:
+			return false, nil
+		default:
+			if f, ok := toFloat64(v); ok {
+				return f != 0, nil
+			}
+			return nil, fmt.Errorf("cannot cast %T to boolean", value)
+		}
+
+	case "json":
+		s, ok := value.(string)
+		if !ok {
+			return value, nil
+		}
+		var parsed interface{}
+		if err := json.Unmarshal([]byte(s), &parsed); err != nil {
+			return nil, fmt.Errorf("cannot parse value as JSON: %w", err)
+		}
+		return parsed, nil
+
+	case "array":
+		switch v := value.(type) {
+		case nil:
+			return []interface{}{}, nil
+		case []interface{}:
+			return v, nil
+		default:
+			return []interface{}{v}, nil
+		}
+
+	default:
+		return nil, fmt.Errorf("unsupported type: %s", targetType)
+	}
+}
+
+// lookupPath retrieves a value by dot notation path and reports whether it exists
+func lookupPath(data map[string]interface{}, path string) (interface{}, bool) {
+	parts := splitPath(path)
+	current := data
+
+	for i, part := range parts {
+		value, exists := current[part]
+		if !exists {
+			return nil, false
+		}
+		if i == len(parts)-1 {
+			return value, true
+		}
+		next, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		current = next
+	}
+
+	return nil, false
+}
+
+// setValueByPath sets a value using dot notation path, creating intermediate objects
+func setValueByPath(data map[string]interface{}, path string, value interface{}) {
+	parts := splitPath(path)
+	current := data
+
+	for i, part := range parts {
+		if i == len(parts)-1 {
+			current[part] = value
+			return
+		}
+		next, ok := current[part].(map[string]interface{})
+		if !ok {
+			next = make(map[string]interface{})
+			current[part] = next
+		}
+		current = next
+	}
+}
+
+// deleteValueByPath removes a value using dot notation path
+func deleteValueByPath(data map[string]interface{}, path string) {
+	parts := splitPath(path)
+	current := data
+
+	for i, part := range parts {
+		if i == len(parts)-1 {
+			delete(current, part)
+			return
+		}
+		next, ok := current[part].(map[string]interface{})
+		if !ok {
+			return
+		}
+		current = next
+	}
+}
+
+// deepCopyMap copies nested maps and slices so mutations don't leak into the input
+func deepCopyMap(data map[string]interface{}) map[string]interface{} {
+	result := make(map[string]interface{}, len(data))
+	for k, v := range data {
+		result[k] = deepCopyValue(v)
+	}
+	return result
+}
+
+func deepCopyValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		return deepCopyMap(v)
+	case []interface{}:
+		result := make([]interface{}, len(v))
+		for i, item := range v {
+			result[i] = deepCopyValue(item)
+		}
+		return result
+	default:
+		return v
+	}
+}
@@ -0,0 +1,210 @@
+package nodes
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/nuumz/f1ow/internal/engine"
+)
+
+// SetVariableNode writes values into the execution's variables, so later
+// nodes can read state accumulated during the run
+type SetVariableNode struct {
+	BaseNode
+}
+
+// SetVariableConfig defines configuration for set variable node
+type SetVariableConfig struct {
+	Variables []VariableOperation `json:"variables"`
+}
+
+// VariableOperation changes one variable, applied in order
+type VariableOperation struct {
+	Name      string      `json:"name"`
+	Operation string      `json:"operation"` // "set", "increment", "append"
+	Value     interface{} `json:"value"`     // Value to set, add, or append; supports {{template}} variables
+}
+
+var validVariableOperations = map[string]bool{
+	"set": true, "increment": true, "append": true,
+}
+
+// NewSetVariableNode creates a new set variable node
+func NewSetVariableNode() engine.NodeType {
+	return &SetVariableNode{
+		BaseNode: BaseNode{
+			nodeType:    "set_variable",
+			name:        "Set Variable",
+			description: "Set, increment, or append to execution variables that later nodes can read",
+			category:    "Data Processing",
+			icon:        "variable",
+		},
+	}
+}
+
+// Execute applies the operations to the variables in the input and returns
+// the variables it set. Run it through Run to change the execution's
+// variables.
+func (n *SetVariableNode) Execute(ctx context.Context, config interface{}, input interface{}) (interface{}, error) {
+	inputData, _ := input.(map[string]interface{})
+	return n.apply(config, inputData, inputData)
+}
+
+// Run applies the operations to the execution's variables and sets the
+// results for the nodes after it
+func (n *SetVariableNode) Run(ctx context.Context, config engine.NodeConfig, input engine.NodeInput) (engine.NodeOutput, error) {
+	set, err := n.apply(map[string]interface{}(config), input.Data, input.Variables)
+	if err != nil {
+		return engine.NodeOutput{}, err
+	}
+	output := engine.NewNodeOutput(deepCopyMap(set))
+	output.Variables = set
+	return output, nil
+}
+
+// apply returns the variables the operations set, starting from current.
+// Values are resolved against the input.
+func (n *SetVariableNode) apply(config interface{}, input, current map[string]interface{}) (map[string]interface{}, error) {
+	variableConfig, err := n.parseConfig(config)
+	if err != nil {
+		return nil, err
+	}
+
+	set := make(map[string]interface{}, len(variableConfig.Variables))
+	valueOf := func(name string) interface{} {
+		if value, ok := set[name]; ok {
+			return value
+		}
+		return current[name]
+	}
+
+	for i, op := range variableConfig.Variables {
+		value := op.Value
+		if str, ok := value.(string); ok {
+			value = resolveTemplateValue(str, input)
+		} else {
+			value = interpolateValue(value, input)
+		}
+
+		switch op.Operation {
+		case "set":
+			set[op.Name] = deepCopyValue(value)
+
+		case "increment":
+			by := 1.0
+			if value != nil {
+				number, err := castValue(value, "number")
+				if err != nil {
+					return nil, fmt.Errorf("variable %d (%s): %w", i, op.Name, err)
+				}
+				by = number.(float64)
+			}
+			total := 0.0
+			if existing := valueOf(op.Name); existing != nil {
+				number, ok := toFloat64(existing)
+				if !ok {
+					return nil, fmt.Errorf("variable %d (%s): cannot increment %T", i, op.Name, existing)
+				}
+				total = number
+			}
+			set[op.Name] = total + by
+
+		case "append":
+			var list []interface{}
+			switch existing := valueOf(op.Name).(type) {
+			case nil:
+			case []interface{}:
+				list = append(list, existing...)
+			default:
+				list = append(list, existing)
+			}
+			set[op.Name] = append(list, deepCopyValue(value))
+
+		default:
+			return nil, fmt.Errorf("variable %d (%s): unsupported operation: %s", i, op.Name, op.Operation)
+		}
+	}
+
+	return set, nil
+}
+
+// ValidateConfig validates the node configuration
+func (n *SetVariableNode) ValidateConfig(config interface{}) error {
+	variableConfig, err := n.parseConfig(config)
+	if err != nil {
+		return err
+	}
+
+	if len(variableConfig.Variables) == 0 {
+		return fmt.Errorf("at least one variable is required")
+	}
+
+	for i, op := range variableConfig.Variables {
+		if op.Name == "" {
+			return fmt.Errorf("variable %d: name is required", i)
+		}
+		if !validVariableOperations[op.Operation] {
+			return fmt.Errorf("variable %d: invalid operation: %s", i, op.Operation)
+		}
+	}
+
+	return nil
+}
+
+// GetSchema returns the node configuration schema
+func (n *SetVariableNode) GetSchema() engine.NodeSchema {
+	return engine.NodeSchema{
+		Type: "object",
+		Properties: map[string]engine.Property{
+			"variables": {
+				Type:        "array",
+				Title:       "Variables",
+				Description: "Ordered list of {name, operation, value}; operation is set, increment (by value, default 1), or append",
+			},
+		},
+		Required: []string{"variables"},
+		Inputs: []engine.PortSchema{
+			{
+				Name:        "input",
+				Type:        "any",
+				Description: "Data that values are resolved against",
+				Required:    false,
+			},
+		},
+		Outputs: []engine.PortSchema{
+			{
+				Name:        "output",
+				Type:        "object",
+				Description: "The variables set, with their new values",
+				Required:    true,
+			},
+		},
+	}
+}
+
+// parseConfig parses the node configuration
+func (n *SetVariableNode) parseConfig(config interface{}) (*SetVariableConfig, error) {
+	configMap, ok := config.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("invalid config type for set variable node")
+	}
+
+	configJSON, err := json.Marshal(configMap)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal config: %w", err)
+	}
+
+	var variableConfig SetVariableConfig
+	if err := json.Unmarshal(configJSON, &variableConfig); err != nil {
+		return nil, fmt.Errorf("failed to parse set variable config: %w", err)
+	}
+
+	for i := range variableConfig.Variables {
+		if variableConfig.Variables[i].Operation == "" {
+			variableConfig.Variables[i].Operation = "set"
+		}
+	}
+
+	return &variableConfig, nil
+}
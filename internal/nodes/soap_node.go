@@ -0,0 +1,350 @@
+package nodes
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/nuumz/f1ow/internal/engine"
+
+	"github.com/antchfx/xmlquery"
+	"github.com/antchfx/xpath"
+)
+
+const (
+	soap11Namespace = "http://schemas.xmlsoap.org/soap/envelope/"
+	soap12Namespace = "http://www.w3.org/2003/05/soap-envelope"
+)
+
+// SOAPNode sends SOAP requests built from XML templates and parses the response
+type SOAPNode struct {
+	BaseNode
+}
+
+// SOAPConfig defines configuration for soap node
+type SOAPConfig struct {
+	URL             string            `json:"url"`
+	SOAPAction      string            `json:"soap_action"`
+	Version         string            `json:"version"`    // "1.1", "1.2"
+	Namespaces      map[string]string `json:"namespaces"` // prefix -> URI declared on the envelope
+	Header          string            `json:"header"`     // XML placed inside soap:Header
+	Body            string            `json:"body"`       // XML placed inside soap:Body
+	Envelope        string            `json:"envelope"`   // Complete envelope template; overrides header and body
+	Headers         map[string]string `json:"headers"`
+	Authentication  *HTTPAuth         `json:"authentication"`
+	Extract         map[string]string `json:"extract"` // output name -> XPath expression
+	Timeout         int               `json:"timeout"` // seconds
+	IgnoreSSLIssues bool              `json:"ignore_ssl_issues"`
+}
+
+// NewSOAPNode creates a new soap node
+func NewSOAPNode() engine.NodeType {
+	return &SOAPNode{
+		BaseNode: BaseNode{
+			nodeType:    "soap",
+			name:        "SOAP Request",
+			description: "Call SOAP web services and parse XML responses with XPath extraction",
+			category:    "Network",
+			icon:        "globe",
+		},
+	}
+}
+
+// Execute sends the SOAP request
+func (n *SOAPNode) Execute(ctx context.Context, config interface{}, input interface{}) (interface{}, error) {
+	soapConfig, err := n.parseConfig(config)
+	if err != nil {
+		return nil, err
+	}
+
+	envelope := n.buildEnvelope(soapConfig, input)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, processTemplate(soapConfig.URL, input), strings.NewReader(envelope))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	action := processTemplate(soapConfig.SOAPAction, input)
+	if soapConfig.Version == "1.2" {
+		contentType := "application/soap+xml; charset=utf-8"
+		if action != "" {
+			contentType += fmt.Sprintf("; action=%q", action)
+		}
+		req.Header.Set("Content-Type", contentType)
+	} else {
+		req.Header.Set("Content-Type", "text/xml; charset=utf-8")
+		req.Header.Set("SOAPAction", fmt.Sprintf("%q", action))
+	}
+	for key, value := range soapConfig.Headers {
+		req.Header.Set(key, processTemplate(value, input))
+	}
+
+	if err := (&HTTPNode{}).applyAuthentication(req, soapConfig.Authentication, input); err != nil {
+		return nil, err
+	}
+
+	client := &http.Client{Timeout: time.Duration(soapConfig.Timeout) * time.Second}
+	if soapConfig.IgnoreSSLIssues {
+		client.Transport = &http.Transport{
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+		}
+	}
+
+	resp, err := guardClient(ctx, client).Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	doc, err := xmlquery.Parse(bytes.NewReader(respBody))
+	if err != nil {
+		return nil, fmt.Errorf("status %d with invalid XML response: %w", resp.StatusCode, err)
+	}
+
+	if fault := soapFault(doc); fault != nil {
+		return nil, fmt.Errorf("SOAP fault %v: %v", fault["code"], fault["message"])
+	}
+
+	if resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("SOAP request returned status %d", resp.StatusCode)
+	}
+
+	result := map[string]interface{}{
+		"statusCode": resp.StatusCode,
+		"body":       map[string]interface{}{},
+	}
+
+	if body := xmlquery.FindOne(doc, "/*[local-name()='Envelope']/*[local-name()='Body']"); body != nil {
+		if content, ok := xmlNodeToValue(body).(map[string]interface{}); ok {
+			result["body"] = content
+		}
+	}
+
+	if len(soapConfig.Extract) > 0 {
+		extracted := make(map[string]interface{}, len(soapConfig.Extract))
+		for name, expr := range soapConfig.Extract {
+			value, err := evaluateXPath(doc, expr)
+			if err != nil {
+				return nil, err
+			}
+			extracted[name] = value
+		}
+		result["extracted"] = extracted
+	}
+
+	return result, nil
+}
+
+// ValidateConfig validates the node configuration
+func (n *SOAPNode) ValidateConfig(config interface{}) error {
+	soapConfig, err := n.parseConfig(config)
+	if err != nil {
+		return err
+	}
+
+	if soapConfig.URL == "" {
+		return fmt.Errorf("url is required")
+	}
+
+	if soapConfig.Version != "1.1" && soapConfig.Version != "1.2" {
+		return fmt.Errorf("invalid version: %s", soapConfig.Version)
+	}
+
+	if soapConfig.Body == "" && soapConfig.Envelope == "" {
+		return fmt.Errorf("body or envelope is required")
+	}
+
+	for name, expr := range soapConfig.Extract {
+		if _, err := xpath.Compile(expr); err != nil {
+			return fmt.Errorf("invalid xpath for %s: %w", name, err)
+		}
+	}
+
+	return nil
+}
+
+// GetSchema returns the node configuration schema
+func (n *SOAPNode) GetSchema() engine.NodeSchema {
+	return engine.NodeSchema{
+		Type: "object",
+		Properties: map[string]engine.Property{
+			"url": {
+				Type:        "string",
+				Title:       "Endpoint URL",
+				Description: "SOAP service endpoint",
+			},
+			"soap_action": {
+				Type:        "string",
+				Title:       "SOAP Action",
+				Description: "SOAPAction of the operation",
+			},
+			"version": {
+				Type:        "string",
+				Title:       "SOAP Version",
+				Description: "SOAP protocol version",
+				Default:     "1.1",
+				Enum:        []string{"1.1", "1.2"},
+			},
+			"namespaces": {
+				Type:        "object",
+				Title:       "Namespaces",
+				Description: "Namespace prefixes declared on the envelope, e.g. {\"tns\": \"http://example.com/\"}",
+			},
+			"header": {
+				Type:        "string",
+				Title:       "Header",
+				Description: "XML for the SOAP header. Supports template variables; values are XML-escaped",
+				Format:      "xml",
+			},
+			"body": {
+				Type:        "string",
+				Title:       "Body",
+				Description: "XML for the SOAP body. Supports template variables; values are XML-escaped",
+				Format:      "xml",
+			},
+			"envelope": {
+				Type:        "string",
+				Title:       "Envelope",
+				Description: "Complete envelope template, used instead of header and body",
+				Format:      "xml",
+			},
+			"headers": {
+				Type:        "object",
+				Title:       "HTTP Headers",
+				Description: "Additional HTTP headers",
+			},
+			"authentication": {
+				Type:        "object",
+				Title:       "Authentication",
+				Description: "Authentication configuration",
+			},
+			"extract": {
+				Type:        "object",
+				Title:       "Extract",
+				Description: "Output name to XPath expression, e.g. {\"total\": \"//*[local-name()='Total']\"}",
+			},
+			"timeout": {
+				Type:        "number",
+				Title:       "Timeout",
+				Description: "Request timeout in seconds",
+				Default:     30,
+			},
+		},
+		Required: []string{"url"},
+		Inputs: []engine.PortSchema{
+			{
+				Name:        "input",
+				Type:        "any",
+				Description: "Input data available for template variables",
+				Required:    false,
+			},
+		},
+		Outputs: []engine.PortSchema{
+			{
+				Name:        "output",
+				Type:        "object",
+				Description: "Parsed SOAP body and extracted values",
+				Required:    true,
+			},
+		},
+	}
+}
+
+// parseConfig parses the node configuration
+func (n *SOAPNode) parseConfig(config interface{}) (*SOAPConfig, error) {
+	configMap, ok := config.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("invalid config type for soap node")
+	}
+
+	configJSON, err := json.Marshal(configMap)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal config: %w", err)
+	}
+
+	var soapConfig SOAPConfig
+	if err := json.Unmarshal(configJSON, &soapConfig); err != nil {
+		return nil, fmt.Errorf("failed to parse soap config: %w", err)
+	}
+
+	// Set defaults
+	if soapConfig.Version == "" {
+		soapConfig.Version = "1.1"
+	}
+	if soapConfig.Timeout == 0 {
+		soapConfig.Timeout = 30
+	}
+
+	return &soapConfig, nil
+}
+
+// buildEnvelope renders the request envelope
+func (n *SOAPNode) buildEnvelope(config *SOAPConfig, input interface{}) string {
+	if config.Envelope != "" {
+		return processXMLTemplate(config.Envelope, input)
+	}
+
+	namespace := soap11Namespace
+	if config.Version == "1.2" {
+		namespace = soap12Namespace
+	}
+
+	prefixes := make([]string, 0, len(config.Namespaces))
+	for prefix := range config.Namespaces {
+		prefixes = append(prefixes, prefix)
+	}
+	sort.Strings(prefixes)
+
+	var b strings.Builder
+	b.WriteString(`<?xml version="1.0" encoding="utf-8"?>`)
+	fmt.Fprintf(&b, `<soap:Envelope xmlns:soap="%s"`, namespace)
+	for _, prefix := range prefixes {
+		fmt.Fprintf(&b, ` xmlns:%s="%s"`, prefix, config.Namespaces[prefix])
+	}
+	b.WriteString(">")
+	if config.Header != "" {
+		b.WriteString("<soap:Header>" + processXMLTemplate(config.Header, input) + "</soap:Header>")
+	}
+	b.WriteString("<soap:Body>" + processXMLTemplate(config.Body, input) + "</soap:Body>")
+	b.WriteString("</soap:Envelope>")
+
+	return b.String()
+}
+
+// soapFault returns the code and message of a SOAP 1.1 or 1.2 fault, or nil
+func soapFault(doc *xmlquery.Node) map[string]interface{} {
+	fault := xmlquery.FindOne(doc, "//*[local-name()='Body']/*[local-name()='Fault']")
+	if fault == nil {
+		return nil
+	}
+
+	text := func(expr string) string {
+		if node := xmlquery.FindOne(fault, expr); node != nil {
+			return strings.TrimSpace(node.InnerText())
+		}
+		return ""
+	}
+
+	code := text("faultcode")
+	if code == "" {
+		code = text("*[local-name()='Code']/*[local-name()='Value']")
+	}
+	message := text("faultstring")
+	if message == "" {
+		message = text("*[local-name()='Reason']/*[local-name()='Text']")
+	}
+
+	return map[string]interface{}{"code": code, "message": message}
+}
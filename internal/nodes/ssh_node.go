@@ -0,0 +1,378 @@
+package nodes
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/nuumz/f1ow/internal/engine"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// SSHNode runs commands and scripts on remote hosts over SSH
+type SSHNode struct {
+	BaseNode
+	allowedCommands []*regexp.Regexp
+}
+
+// SSHConfig defines configuration for ssh node
+type SSHConfig struct {
+	Host                  string `json:"host"`
+	Port                  int    `json:"port"`
+	Username              string `json:"username"`
+	PrivateKey            string `json:"private_key"` // PEM encoded
+	Passphrase            string `json:"passphrase"`
+	HostKey               string `json:"host_key"` // authorized_keys format, e.g. "ssh-ed25519 AAAA..."
+	InsecureIgnoreHostKey bool   `json:"insecure_ignore_host_key"`
+	Command               string `json:"command"`
+	Script                string `json:"script"` // sent to the remote shell on stdin
+	Shell                 string `json:"shell"`
+	FailOnError           bool   `json:"fail_on_error"` // fail the node on a non-zero exit code
+	Timeout               int    `json:"timeout"`       // seconds
+}
+
+// NewSSHNode creates a new ssh node. When allowedCommands is not empty, only
+// commands matching one of the patterns may run; "*" matches any characters,
+// and scripts and commands with shell metacharacters are rejected.
+func NewSSHNode(allowedCommands []string) engine.NodeType {
+	node := &SSHNode{
+		BaseNode: BaseNode{
+			nodeType:    "ssh",
+			name:        "SSH",
+			description: "Run commands or scripts on remote hosts over SSH",
+			category:    "Network",
+			icon:        "terminal",
+		},
+	}
+
+	for _, pattern := range allowedCommands {
+		pattern = strings.TrimSpace(pattern)
+		if pattern == "" {
+			continue
+		}
+		expr := "^" + strings.ReplaceAll(regexp.QuoteMeta(pattern), `\*`, ".*") + "$"
+		node.allowedCommands = append(node.allowedCommands, regexp.MustCompile(expr))
+	}
+
+	return node
+}
+
+// Execute runs the command on the remote host
+func (n *SSHNode) Execute(ctx context.Context, config interface{}, input interface{}) (interface{}, error) {
+	sshConfig, err := n.parseConfig(config)
+	if err != nil {
+		return nil, err
+	}
+
+	command := processTemplate(sshConfig.Command, input)
+	script := processTemplate(sshConfig.Script, input)
+	if script != "" {
+		if len(n.allowedCommands) > 0 {
+			return nil, fmt.Errorf("scripts are not allowed when an SSH command allowlist is configured")
+		}
+		command = sshConfig.Shell
+	} else if !n.commandAllowed(command) {
+		return nil, fmt.Errorf("command is not in the SSH command allowlist: %s", command)
+	}
+
+	clientConfig, err := n.clientConfig(sshConfig, input)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(sshConfig.Timeout)*time.Second)
+	defer cancel()
+
+	address := net.JoinHostPort(processTemplate(sshConfig.Host, input), strconv.Itoa(sshConfig.Port))
+	client, err := dialSSH(ctx, address, clientConfig)
+	if err != nil {
+		return nil, err
+	}
+	defer client.Close()
+
+	session, err := client.NewSession()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open ssh session: %w", err)
+	}
+	defer session.Close()
+
+	var stdout, stderr bytes.Buffer
+	session.Stdout = &stdout
+	session.Stderr = &stderr
+	if script != "" {
+		session.Stdin = strings.NewReader(script)
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- session.Run(command)
+	}()
+
+	var runErr error
+	select {
+	case runErr = <-done:
+	case <-ctx.Done():
+		session.Signal(ssh.SIGKILL)
+		client.Close()
+		return nil, fmt.Errorf("ssh command timed out after %ds", sshConfig.Timeout)
+	}
+
+	exitCode := 0
+	if runErr != nil {
+		var exitErr *ssh.ExitError
+		if !errors.As(runErr, &exitErr) {
+			return nil, fmt.Errorf("ssh command failed: %w", runErr)
+		}
+		exitCode = exitErr.ExitStatus()
+	}
+
+	if exitCode != 0 && sshConfig.FailOnError {
+		return nil, fmt.Errorf("ssh command exited with code %d: %s", exitCode, strings.TrimSpace(stderr.String()))
+	}
+
+	return map[string]interface{}{
+		"stdout":    stdout.String(),
+		"stderr":    stderr.String(),
+		"exit_code": exitCode,
+	}, nil
+}
+
+// ValidateConfig validates the node configuration
+func (n *SSHNode) ValidateConfig(config interface{}) error {
+	sshConfig, err := n.parseConfig(config)
+	if err != nil {
+		return err
+	}
+
+	if sshConfig.Host == "" {
+		return fmt.Errorf("host is required")
+	}
+	if sshConfig.Username == "" {
+		return fmt.Errorf("username is required")
+	}
+	if sshConfig.PrivateKey == "" {
+		return fmt.Errorf("private_key is required")
+	}
+	if sshConfig.HostKey == "" && !sshConfig.InsecureIgnoreHostKey {
+		return fmt.Errorf("host_key is required unless insecure_ignore_host_key is set")
+	}
+	if (sshConfig.Command == "") == (sshConfig.Script == "") {
+		return fmt.Errorf("exactly one of command or script is required")
+	}
+	if sshConfig.Script != "" && len(n.allowedCommands) > 0 {
+		return fmt.Errorf("scripts are not allowed when an SSH command allowlist is configured")
+	}
+	if sshConfig.Port < 1 || sshConfig.Port > 65535 {
+		return fmt.Errorf("invalid port: %d", sshConfig.Port)
+	}
+
+	return nil
+}
+
+// GetSchema returns the node configuration schema
+func (n *SSHNode) GetSchema() engine.NodeSchema {
+	return engine.NodeSchema{
+		Type: "object",
+		Properties: map[string]engine.Property{
+			"host": {
+				Type:        "string",
+				Title:       "Host",
+				Description: "Remote host name or address",
+			},
+			"port": {
+				Type:        "number",
+				Title:       "Port",
+				Description: "SSH port",
+				Default:     22,
+			},
+			"username": {
+				Type:        "string",
+				Title:       "Username",
+				Description: "Remote user",
+			},
+			"private_key": {
+				Type:        "string",
+				Title:       "Private Key",
+				Description: "PEM encoded private key",
+				Format:      "password",
+			},
+			"passphrase": {
+				Type:        "string",
+				Title:       "Passphrase",
+				Description: "Passphrase for an encrypted private key",
+				Format:      "password",
+			},
+			"host_key": {
+				Type:        "string",
+				Title:       "Host Key",
+				Description: "Expected host public key in authorized_keys format",
+			},
+			"insecure_ignore_host_key": {
+				Type:        "boolean",
+				Title:       "Ignore Host Key",
+				Description: "Skip host key verification (not recommended)",
+				Default:     false,
+			},
+			"command": {
+				Type:        "string",
+				Title:       "Command",
+				Description: "Command to run. Template values are inserted as-is, so quote untrusted input",
+			},
+			"script": {
+				Type:        "string",
+				Title:       "Script",
+				Description: "Script sent to the remote shell on stdin. Supports template variables like {{variable}}",
+				Format:      "textarea",
+			},
+			"shell": {
+				Type:        "string",
+				Title:       "Shell",
+				Description: "Remote shell used to run scripts",
+				Default:     "sh -s",
+			},
+			"fail_on_error": {
+				Type:        "boolean",
+				Title:       "Fail on Error",
+				Description: "Fail the node when the command exits with a non-zero code",
+				Default:     false,
+			},
+			"timeout": {
+				Type:        "number",
+				Title:       "Timeout",
+				Description: "Connection and command timeout in seconds",
+				Default:     60,
+			},
+		},
+		Required: []string{"host", "username", "private_key"},
+		Inputs: []engine.PortSchema{
+			{
+				Name:        "input",
+				Type:        "any",
+				Description: "Input data available for template variables",
+				Required:    false,
+			},
+		},
+		Outputs: []engine.PortSchema{
+			{
+				Name:        "output",
+				Type:        "object",
+				Description: "Command result {stdout, stderr, exit_code}",
+				Required:    true,
+			},
+		},
+	}
+}
+
+// parseConfig parses the node configuration
+func (n *SSHNode) parseConfig(config interface{}) (*SSHConfig, error) {
+	configMap, ok := config.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("invalid config type for ssh node")
+	}
+
+	configJSON, err := json.Marshal(configMap)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal config: %w", err)
+	}
+
+	var sshConfig SSHConfig
+	if err := json.Unmarshal(configJSON, &sshConfig); err != nil {
+		return nil, fmt.Errorf("failed to parse ssh config: %w", err)
+	}
+
+	// Set defaults
+	if sshConfig.Port == 0 {
+		sshConfig.Port = 22
+	}
+	if sshConfig.Shell == "" {
+		sshConfig.Shell = "sh -s"
+	}
+	if sshConfig.Timeout == 0 {
+		sshConfig.Timeout = 60
+	}
+
+	return &sshConfig, nil
+}
+
+// sshShellMetacharacters chain, substitute, or redirect commands in the
+// remote shell, so a command with any of them could run more than the
+// allowlist pattern it matches
+const sshShellMetacharacters = ";&|`$()<>\\\n\r"
+
+// commandAllowed reports whether the command matches the operator allowlist
+func (n *SSHNode) commandAllowed(command string) bool {
+	if len(n.allowedCommands) == 0 {
+		return true
+	}
+	if strings.ContainsAny(command, sshShellMetacharacters) {
+		return false
+	}
+	for _, pattern := range n.allowedCommands {
+		if pattern.MatchString(command) {
+			return true
+		}
+	}
+	return false
+}
+
+// clientConfig builds the SSH client configuration with key authentication
+func (n *SSHNode) clientConfig(config *SSHConfig, input interface{}) (*ssh.ClientConfig, error) {
+	key := []byte(processTemplate(config.PrivateKey, input))
+
+	var signer ssh.Signer
+	var err error
+	if config.Passphrase != "" {
+		signer, err = ssh.ParsePrivateKeyWithPassphrase(key, []byte(processTemplate(config.Passphrase, input)))
+	} else {
+		signer, err = ssh.ParsePrivateKey(key)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid private key: %w", err)
+	}
+
+	hostKeyCallback := ssh.InsecureIgnoreHostKey()
+	if config.HostKey != "" {
+		hostKey, _, _, _, err := ssh.ParseAuthorizedKey([]byte(processTemplate(config.HostKey, input)))
+		if err != nil {
+			return nil, fmt.Errorf("invalid host key: %w", err)
+		}
+		hostKeyCallback = ssh.FixedHostKey(hostKey)
+	} else if !config.InsecureIgnoreHostKey {
+		return nil, fmt.Errorf("host_key is required unless insecure_ignore_host_key is set")
+	}
+
+	return &ssh.ClientConfig{
+		User:            processTemplate(config.Username, input),
+		Auth:            []ssh.AuthMethod{ssh.PublicKeys(signer)},
+		HostKeyCallback: hostKeyCallback,
+		Timeout:         time.Duration(config.Timeout) * time.Second,
+	}, nil
+}
+
+// dialSSH connects to address, honoring context cancellation during the handshake
+func dialSSH(ctx context.Context, address string, config *ssh.ClientConfig) (*ssh.Client, error) {
+	conn, err := Dialer(ctx)(ctx, "tcp", address)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to %s: %w", address, err)
+	}
+
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	sshConn, chans, reqs, err := ssh.NewClientConn(conn, address, config)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("ssh handshake with %s failed: %w", address, err)
+	}
+
+	return ssh.NewClient(sshConn, chans, reqs), nil
+}
@@ -0,0 +1,221 @@
+package nodes
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/nuumz/f1ow/internal/engine"
+	"github.com/nuumz/f1ow/internal/models"
+	"github.com/nuumz/f1ow/internal/storage"
+
+	"github.com/google/uuid"
+)
+
+// StateStore persists the values workflows keep between executions. Get
+// and delete return storage.ErrStateNotFound for keys without a value.
+type StateStore interface {
+	GetWorkflowState(ctx context.Context, workflowID uuid.UUID, key string) (*models.WorkflowState, error)
+	SetWorkflowState(ctx context.Context, state *models.WorkflowState) error
+	DeleteWorkflowState(ctx context.Context, workflowID uuid.UUID, key string) error
+}
+
+// StateNode reads and writes the running workflow's persistent state
+type StateNode struct {
+	BaseNode
+	store StateStore
+}
+
+// StateConfig defines configuration for state node
+type StateConfig struct {
+	Operation string      `json:"operation"` // "get", "set", "increment", "delete"
+	Key       string      `json:"key"`
+	Value     interface{} `json:"value"`   // Value to set or add; supports {{template}} variables
+	Default   interface{} `json:"default"` // Value get returns for a key without one
+}
+
+// NewStateNode creates a new state node backed by store
+func NewStateNode(store StateStore) engine.NodeType {
+	return &StateNode{
+		BaseNode: BaseNode{
+			nodeType:    "state",
+			name:        "Workflow State",
+			description: "Remember values between executions, such as cursors and counters",
+			category:    "Data Storage",
+			icon:        "save",
+		},
+		store: store,
+	}
+}
+
+// Execute runs the state operation for the running workflow
+func (n *StateNode) Execute(ctx context.Context, config interface{}, input interface{}) (interface{}, error) {
+	stateConfig, err := n.parseConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	if n.store == nil {
+		return nil, fmt.Errorf("workflow state storage is not available")
+	}
+
+	info, _ := engine.ExecutionInfoFromContext(ctx)
+	workflowID, err := uuid.Parse(info.WorkflowID)
+	if err != nil {
+		return nil, fmt.Errorf("state is only available in workflow executions")
+	}
+
+	key := processTemplate(stateConfig.Key, input)
+
+	switch stateConfig.Operation {
+	case "get":
+		state, err := n.store.GetWorkflowState(ctx, workflowID, key)
+		if errors.Is(err, storage.ErrStateNotFound) {
+			return map[string]interface{}{"key": key, "found": false, "value": stateConfig.Default}, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{"key": key, "found": true, "value": state.Value}, nil
+
+	case "set":
+		value := resolveContent(stateConfig.Value, input)
+		if err := n.store.SetWorkflowState(ctx, &models.WorkflowState{WorkflowID: workflowID, Key: key, Value: value}); err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{"key": key, "value": value}, nil
+
+	case "increment":
+		// Read and write are separate, so concurrent executions of the
+		// workflow can lose increments
+		by := 1.0
+		if stateConfig.Value != nil {
+			number, err := castValue(resolveContent(stateConfig.Value, input), "number")
+			if err != nil {
+				return nil, err
+			}
+			by = number.(float64)
+		}
+		total := 0.0
+		state, err := n.store.GetWorkflowState(ctx, workflowID, key)
+		switch {
+		case errors.Is(err, storage.ErrStateNotFound):
+		case err != nil:
+			return nil, err
+		default:
+			number, ok := toFloat64(state.Value)
+			if !ok {
+				return nil, fmt.Errorf("cannot increment %T in state %s", state.Value, key)
+			}
+			total = number
+		}
+		total += by
+		if err := n.store.SetWorkflowState(ctx, &models.WorkflowState{WorkflowID: workflowID, Key: key, Value: total}); err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{"key": key, "value": total}, nil
+
+	case "delete":
+		err := n.store.DeleteWorkflowState(ctx, workflowID, key)
+		if err != nil && !errors.Is(err, storage.ErrStateNotFound) {
+			return nil, err
+		}
+		return map[string]interface{}{"key": key, "deleted": err == nil}, nil
+
+	default:
+		return nil, fmt.Errorf("unsupported operation: %s", stateConfig.Operation)
+	}
+}
+
+// ValidateConfig validates the node configuration
+func (n *StateNode) ValidateConfig(config interface{}) error {
+	stateConfig, err := n.parseConfig(config)
+	if err != nil {
+		return err
+	}
+
+	switch stateConfig.Operation {
+	case "get", "set", "increment", "delete":
+	default:
+		return fmt.Errorf("invalid operation: %s", stateConfig.Operation)
+	}
+
+	if stateConfig.Key == "" {
+		return fmt.Errorf("key is required")
+	}
+
+	return nil
+}
+
+// GetSchema returns the node configuration schema
+func (n *StateNode) GetSchema() engine.NodeSchema {
+	return engine.NodeSchema{
+		Type: "object",
+		Properties: map[string]engine.Property{
+			"operation": {
+				Type:        "string",
+				Title:       "Operation",
+				Description: "What to do with the value under key",
+				Default:     "get",
+				Enum:        []string{"get", "set", "increment", "delete"},
+			},
+			"key": {
+				Type:        "string",
+				Title:       "Key",
+				Description: "State key, unique within the workflow; supports {{template}} variables",
+			},
+			"value": {
+				Type:        "any",
+				Title:       "Value",
+				Description: "Value to set, or amount to increment by (default 1)",
+			},
+			"default": {
+				Type:        "any",
+				Title:       "Default",
+				Description: "Value returned by get when the key has none",
+			},
+		},
+		Required: []string{"key"},
+		Inputs: []engine.PortSchema{
+			{
+				Name:        "input",
+				Type:        "any",
+				Description: "Data that the key and value are resolved against",
+				Required:    false,
+			},
+		},
+		Outputs: []engine.PortSchema{
+			{
+				Name:        "output",
+				Type:        "object",
+				Description: "The key and its value",
+				Required:    true,
+			},
+		},
+	}
+}
+
+// parseConfig parses the node configuration
+func (n *StateNode) parseConfig(config interface{}) (*StateConfig, error) {
+	configMap, ok := config.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("invalid config type for state node")
+	}
+
+	configJSON, err := json.Marshal(configMap)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal config: %w", err)
+	}
+
+	var stateConfig StateConfig
+	if err := json.Unmarshal(configJSON, &stateConfig); err != nil {
+		return nil, fmt.Errorf("failed to parse state config: %w", err)
+	}
+
+	// Set defaults
+	if stateConfig.Operation == "" {
+		stateConfig.Operation = "get"
+	}
+
+	return &stateConfig, nil
+}
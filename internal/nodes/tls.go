@@ -0,0 +1,36 @@
+package nodes
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+)
+
+// buildTLSConfig builds a client TLS configuration from PEM encoded
+// certificates. Empty values fall back to the system roots and no client
+// certificate.
+func buildTLSConfig(caCert, clientCert, clientKey string, insecureSkipVerify bool) (*tls.Config, error) {
+	tlsConfig := &tls.Config{InsecureSkipVerify: insecureSkipVerify}
+
+	if caCert != "" {
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM([]byte(caCert)) {
+			return nil, fmt.Errorf("invalid ca_cert")
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if (clientCert == "") != (clientKey == "") {
+		return nil, fmt.Errorf("client_cert and client_key must be provided together")
+	}
+
+	if clientCert != "" {
+		cert, err := tls.X509KeyPair([]byte(clientCert), []byte(clientKey))
+		if err != nil {
+			return nil, fmt.Errorf("invalid client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	return tlsConfig, nil
+}
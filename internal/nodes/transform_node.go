@@ -3,14 +3,20 @@ package nodes
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/nuumz/f1ow/internal/engine"
 
 	"github.com/dop251/goja"
 )
 
+// transformMaxCallStackSize caps the call depth of transform scripts, so
+// runaway recursion fails the node instead of growing without bound
+const transformMaxCallStackSize = 1024
+
 // TransformNode implements JavaScript code execution
 type TransformNode struct {
 	BaseNode
@@ -45,8 +51,14 @@ func (n *TransformNode) Execute(ctx context.Context, config interface{}, input i
 		return nil, err
 	}
 
-	// Create JavaScript VM
+	// Create JavaScript VM, interrupted when the timeout passes or the node
+	// run is cancelled
 	vm := goja.New()
+	vm.SetMaxCallStackSize(transformMaxCallStackSize)
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(transformConfig.Timeout)*time.Second)
+	defer cancel()
+	stop := context.AfterFunc(ctx, func() { vm.Interrupt(ctx.Err()) })
+	defer stop()
 
 	// Add console.log support
 	console := vm.NewObject()
@@ -87,6 +99,10 @@ func (n *TransformNode) Execute(ctx context.Context, config interface{}, input i
 
 	// Execute code
 	result, err := vm.RunString(transformConfig.Code)
+	var interrupted *goja.InterruptedError
+	if errors.As(err, &interrupted) {
+		return nil, fmt.Errorf("JavaScript execution interrupted: %w", ctx.Err())
+	}
 	if err != nil {
 		return nil, fmt.Errorf("JavaScript execution error: %w", err)
 	}
@@ -128,10 +144,12 @@ func (n *TransformNode) ValidateConfig(config interface{}) error {
 		return fmt.Errorf("code is required")
 	}
 
-	// Try to parse the code to check for syntax errors
-	vm := goja.New()
-	_, err = vm.RunString(transformConfig.Code)
-	if err != nil {
+	if transformConfig.Timeout <= 0 {
+		return fmt.Errorf("timeout must be positive")
+	}
+
+	// Compile without running the code, which may not terminate
+	if _, err := goja.Compile("transform", transformConfig.Code, false); err != nil {
 		return fmt.Errorf("invalid JavaScript code: %w", err)
 	}
 
@@ -203,6 +221,11 @@ func (n *TransformNode) parseConfig(config interface{}) (*TransformConfig, error
 		return nil, fmt.Errorf("failed to parse transform config: %w", err)
 	}
 
+	// Set defaults
+	if _, ok := configMap["timeout"]; !ok {
+		transformConfig.Timeout = 30
+	}
+
 	return &transformConfig, nil
 }
 
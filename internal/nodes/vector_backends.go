@@ -0,0 +1,377 @@
+package nodes
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// pgvectorBackend stores embeddings in a PostgreSQL table using the pgvector
+// extension. The table has id TEXT, embedding VECTOR, and metadata JSONB columns.
+type pgvectorBackend struct {
+	db       *sql.DB
+	table    string
+	distance string
+}
+
+func newPGVectorBackend(ctx context.Context, dsn, table, distance string) (*pgvectorBackend, error) {
+	db, err := OpenDatabase(ctx, "postgres", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to postgres: %w", err)
+	}
+	return &pgvectorBackend{db: db, table: table, distance: distance}, nil
+}
+
+// operator returns the pgvector distance operator for the configured metric
+func (b *pgvectorBackend) operator() string {
+	switch b.distance {
+	case "euclidean":
+		return "<->"
+	case "dot":
+		return "<#>"
+	default:
+		return "<=>"
+	}
+}
+
+// score converts a pgvector distance into a score where higher is more similar
+func (b *pgvectorBackend) score(distance float64) float64 {
+	switch b.distance {
+	case "euclidean":
+		return -distance
+	case "dot":
+		// <#> returns the negative inner product
+		return -distance
+	default:
+		return 1 - distance
+	}
+}
+
+func (b *pgvectorBackend) Ensure(ctx context.Context, dimensions int) error {
+	if _, err := b.db.ExecContext(ctx, `CREATE EXTENSION IF NOT EXISTS vector`); err != nil {
+		return fmt.Errorf("failed to enable pgvector: %w", err)
+	}
+
+	query := fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+        id TEXT PRIMARY KEY,
+        embedding VECTOR(%d) NOT NULL,
+        metadata JSONB NOT NULL DEFAULT '{}'
+    )`, b.table, dimensions)
+	if _, err := b.db.ExecContext(ctx, query); err != nil {
+		return fmt.Errorf("failed to create table %s: %w", b.table, err)
+	}
+	return nil
+}
+
+func (b *pgvectorBackend) Upsert(ctx context.Context, records []vectorRecord) (int, error) {
+	tx, err := b.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := fmt.Sprintf(`
+        INSERT INTO %s (id, embedding, metadata) VALUES ($1, $2::vector, $3::jsonb)
+        ON CONFLICT (id) DO UPDATE SET embedding = EXCLUDED.embedding, metadata = EXCLUDED.metadata
+    `, b.table)
+
+	for _, record := range records {
+		metadata, err := json.Marshal(record.Metadata)
+		if err != nil {
+			return 0, fmt.Errorf("failed to marshal metadata: %w", err)
+		}
+		if _, err := tx.ExecContext(ctx, query, record.ID, vectorLiteral(record.Vector), string(metadata)); err != nil {
+			return 0, fmt.Errorf("failed to upsert %s: %w", record.ID, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit upsert: %w", err)
+	}
+	return len(records), nil
+}
+
+func (b *pgvectorBackend) Query(ctx context.Context, vector []float64, topK int, filter map[string]interface{}) ([]vectorMatch, error) {
+	args := []interface{}{vectorLiteral(vector)}
+	where := ""
+	if len(filter) > 0 {
+		filterJSON, err := json.Marshal(filter)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal filter: %w", err)
+		}
+		args = append(args, string(filterJSON))
+		where = "WHERE metadata @> $2::jsonb"
+	}
+	args = append(args, topK)
+
+	query := fmt.Sprintf(`
+        SELECT id, metadata, embedding %s $1::vector AS distance
+        FROM %s %s
+        ORDER BY distance
+        LIMIT $%d
+    `, b.operator(), b.table, where, len(args))
+
+	rows, err := b.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query %s: %w", b.table, err)
+	}
+	defer rows.Close()
+
+	var matches []vectorMatch
+	for rows.Next() {
+		var match vectorMatch
+		var metadata []byte
+		var distance float64
+		if err := rows.Scan(&match.ID, &metadata, &distance); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(metadata, &match.Metadata); err != nil {
+			return nil, fmt.Errorf("failed to parse metadata: %w", err)
+		}
+		match.Score = b.score(distance)
+		matches = append(matches, match)
+	}
+
+	return matches, rows.Err()
+}
+
+func (b *pgvectorBackend) Delete(ctx context.Context, ids []string, filter map[string]interface{}) (int, error) {
+	var result sql.Result
+	var err error
+	if len(ids) > 0 {
+		result, err = b.db.ExecContext(ctx, fmt.Sprintf(`DELETE FROM %s WHERE id = ANY($1)`, b.table), pq.Array(ids))
+	} else {
+		filterJSON, marshalErr := json.Marshal(filter)
+		if marshalErr != nil {
+			return 0, fmt.Errorf("failed to marshal filter: %w", marshalErr)
+		}
+		result, err = b.db.ExecContext(ctx, fmt.Sprintf(`DELETE FROM %s WHERE metadata @> $1::jsonb`, b.table), string(filterJSON))
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete from %s: %w", b.table, err)
+	}
+
+	deleted, err := result.RowsAffected()
+	return int(deleted), err
+}
+
+func (b *pgvectorBackend) Close() error {
+	return b.db.Close()
+}
+
+// vectorLiteral formats a vector in pgvector's text representation
+func vectorLiteral(vector []float64) string {
+	parts := make([]string, len(vector))
+	for i, v := range vector {
+		parts[i] = strconv.FormatFloat(v, 'g', -1, 64)
+	}
+	return "[" + strings.Join(parts, ",") + "]"
+}
+
+// qdrantIDKey is the payload field holding IDs that are not valid Qdrant point IDs
+const qdrantIDKey = "_id"
+
+// qdrantBackend stores embeddings in a Qdrant collection over its REST API
+type qdrantBackend struct {
+	client     *http.Client
+	baseURL    string
+	apiKey     string
+	collection string
+	distance   string
+}
+
+func newQdrantBackend(baseURL, apiKey, collection, distance string) *qdrantBackend {
+	return &qdrantBackend{
+		client:     &http.Client{},
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		apiKey:     apiKey,
+		collection: collection,
+		distance:   distance,
+	}
+}
+
+// do sends a request to Qdrant and decodes the result field into out
+func (b *qdrantBackend) do(ctx context.Context, method, path string, body, out interface{}) (int, error) {
+	var reader io.Reader
+	if body != nil {
+		payload, err := json.Marshal(body)
+		if err != nil {
+			return 0, fmt.Errorf("failed to marshal request: %w", err)
+		}
+		reader = bytes.NewReader(payload)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, b.baseURL+path, reader)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if b.apiKey != "" {
+		req.Header.Set("api-key", b.apiKey)
+	}
+
+	resp, err := b.client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("qdrant request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return resp.StatusCode, fmt.Errorf("failed to read response body: %w", err)
+	}
+	if resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("qdrant returned status %d: %s", resp.StatusCode, string(respBody))
+	}
+
+	if out != nil {
+		envelope := struct {
+			Result interface{} `json:"result"`
+		}{Result: out}
+		if err := json.Unmarshal(respBody, &envelope); err != nil {
+			return resp.StatusCode, fmt.Errorf("failed to parse qdrant response: %w", err)
+		}
+	}
+	return resp.StatusCode, nil
+}
+
+func (b *qdrantBackend) collectionPath() string {
+	return "/collections/" + url.PathEscape(b.collection)
+}
+
+func (b *qdrantBackend) Ensure(ctx context.Context, dimensions int) error {
+	status, err := b.do(ctx, http.MethodGet, b.collectionPath(), nil, nil)
+	if err == nil {
+		return nil
+	}
+	if status != http.StatusNotFound {
+		return err
+	}
+
+	distance := map[string]string{"cosine": "Cosine", "euclidean": "Euclid", "dot": "Dot"}[b.distance]
+	_, err = b.do(ctx, http.MethodPut, b.collectionPath(), map[string]interface{}{
+		"vectors": map[string]interface{}{"size": dimensions, "distance": distance},
+	}, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create collection %s: %w", b.collection, err)
+	}
+	return nil
+}
+
+func (b *qdrantBackend) Upsert(ctx context.Context, records []vectorRecord) (int, error) {
+	points := make([]interface{}, len(records))
+	for i, record := range records {
+		payload := make(map[string]interface{}, len(record.Metadata)+1)
+		for key, value := range record.Metadata {
+			payload[key] = value
+		}
+
+		pointID, native := qdrantPointID(record.ID)
+		if !native {
+			payload[qdrantIDKey] = record.ID
+		}
+
+		points[i] = map[string]interface{}{
+			"id":      pointID,
+			"vector":  record.Vector,
+			"payload": payload,
+		}
+	}
+
+	if _, err := b.do(ctx, http.MethodPut, b.collectionPath()+"/points?wait=true", map[string]interface{}{"points": points}, nil); err != nil {
+		return 0, err
+	}
+	return len(records), nil
+}
+
+func (b *qdrantBackend) Query(ctx context.Context, vector []float64, topK int, filter map[string]interface{}) ([]vectorMatch, error) {
+	body := map[string]interface{}{
+		"vector":       vector,
+		"limit":        topK,
+		"with_payload": true,
+	}
+	if len(filter) > 0 {
+		body["filter"] = qdrantFilter(filter)
+	}
+
+	var points []struct {
+		ID      interface{}            `json:"id"`
+		Score   float64                `json:"score"`
+		Payload map[string]interface{} `json:"payload"`
+	}
+	if _, err := b.do(ctx, http.MethodPost, b.collectionPath()+"/points/search", body, &points); err != nil {
+		return nil, err
+	}
+
+	matches := make([]vectorMatch, len(points))
+	for i, point := range points {
+		id := fmt.Sprintf("%v", point.ID)
+		if n, ok := point.ID.(float64); ok {
+			id = strconv.FormatUint(uint64(n), 10)
+		}
+		if original, ok := point.Payload[qdrantIDKey].(string); ok {
+			id = original
+			delete(point.Payload, qdrantIDKey)
+		}
+		matches[i] = vectorMatch{ID: id, Score: point.Score, Metadata: point.Payload}
+	}
+
+	return matches, nil
+}
+
+func (b *qdrantBackend) Delete(ctx context.Context, ids []string, filter map[string]interface{}) (int, error) {
+	body := map[string]interface{}{}
+	if len(ids) > 0 {
+		points := make([]interface{}, len(ids))
+		for i, id := range ids {
+			points[i], _ = qdrantPointID(id)
+		}
+		body["points"] = points
+	} else {
+		body["filter"] = qdrantFilter(filter)
+	}
+
+	if _, err := b.do(ctx, http.MethodPost, b.collectionPath()+"/points/delete?wait=true", body, nil); err != nil {
+		return 0, err
+	}
+	// Qdrant does not report how many points matched a filter
+	return len(ids), nil
+}
+
+func (b *qdrantBackend) Close() error {
+	return nil
+}
+
+// qdrantPointID maps an ID onto a Qdrant point ID, which must be an unsigned
+// integer or UUID. Other IDs are mapped to a stable name-based UUID.
+func qdrantPointID(id string) (interface{}, bool) {
+	if n, err := strconv.ParseUint(id, 10, 64); err == nil {
+		return n, true
+	}
+	if parsed, err := uuid.Parse(id); err == nil {
+		return parsed.String(), true
+	}
+	return uuid.NewSHA1(uuid.NameSpaceOID, []byte(id)).String(), false
+}
+
+// qdrantFilter builds a filter requiring each payload key to equal its value
+func qdrantFilter(filter map[string]interface{}) map[string]interface{} {
+	must := make([]interface{}, 0, len(filter))
+	for key, value := range filter {
+		must = append(must, map[string]interface{}{
+			"key":   key,
+			"match": map[string]interface{}{"value": value},
+		})
+	}
+	return map[string]interface{}{"must": must}
+}
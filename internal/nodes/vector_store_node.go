@@ -0,0 +1,540 @@
+package nodes
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/nuumz/f1ow/internal/engine"
+
+	"github.com/google/uuid"
+)
+
+// VectorStoreNode upserts, queries, and deletes embeddings in pgvector or Qdrant
+type VectorStoreNode struct {
+	BaseNode
+}
+
+// VectorStoreConfig defines configuration for vector_store node
+type VectorStoreConfig struct {
+	Backend   string `json:"backend"`   // "pgvector", "qdrant"
+	Operation string `json:"operation"` // "upsert", "query", "delete"
+
+	// pgvector
+	ConnectionString string `json:"connection_string"`
+	Table            string `json:"table"`
+
+	// Qdrant
+	URL        string `json:"url"`
+	APIKey     string `json:"api_key"`
+	Collection string `json:"collection"`
+
+	// Collection/table creation on upsert
+	CreateIfMissing bool   `json:"create_if_missing"`
+	Dimensions      int    `json:"dimensions"`
+	Distance        string `json:"distance"` // "cosine", "euclidean", "dot"
+
+	// upsert
+	ItemsPath     string `json:"items_path"`
+	IDField       string `json:"id_field"`
+	VectorField   string `json:"vector_field"`
+	MetadataField string `json:"metadata_field"`
+
+	// query
+	Vector   string                 `json:"vector"` // template resolving to the query embedding, e.g. {{embedding}}
+	TopK     int                    `json:"top_k"`
+	MinScore *float64               `json:"min_score"`
+	Filter   map[string]interface{} `json:"filter"` // metadata equality filter for query and delete
+
+	// delete
+	IDs string `json:"ids"` // template resolving to an ID or array of IDs
+
+	Timeout int `json:"timeout"` // seconds
+}
+
+// vectorRecord is a single embedding with its ID and metadata
+type vectorRecord struct {
+	ID       string
+	Vector   []float64
+	Metadata map[string]interface{}
+}
+
+// vectorMatch is a query result
+type vectorMatch struct {
+	ID       string
+	Score    float64
+	Metadata map[string]interface{}
+}
+
+// vectorBackend is implemented by each supported vector database
+type vectorBackend interface {
+	Ensure(ctx context.Context, dimensions int) error
+	Upsert(ctx context.Context, records []vectorRecord) (int, error)
+	Query(ctx context.Context, vector []float64, topK int, filter map[string]interface{}) ([]vectorMatch, error)
+	Delete(ctx context.Context, ids []string, filter map[string]interface{}) (int, error)
+	Close() error
+}
+
+// sqlIdentifierPattern restricts table names to plain, optionally schema-qualified identifiers
+var sqlIdentifierPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*(\.[A-Za-z_][A-Za-z0-9_]*)?$`)
+
+// NewVectorStoreNode creates a new vector_store node
+func NewVectorStoreNode() engine.NodeType {
+	return &VectorStoreNode{
+		BaseNode: BaseNode{
+			nodeType:    "vector_store",
+			name:        "Vector Store",
+			description: "Upsert, search, and delete embeddings in pgvector or Qdrant",
+			category:    "AI",
+			icon:        "database",
+		},
+	}
+}
+
+// Execute runs the vector store operation
+func (n *VectorStoreNode) Execute(ctx context.Context, config interface{}, input interface{}) (interface{}, error) {
+	vectorConfig, err := n.parseConfig(config)
+	if err != nil {
+		return nil, err
+	}
+
+	backend, err := n.backend(ctx, vectorConfig, input)
+	if err != nil {
+		return nil, err
+	}
+	defer backend.Close()
+
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(vectorConfig.Timeout)*time.Second)
+	defer cancel()
+
+	filter, _ := interpolateValue(vectorConfig.Filter, input).(map[string]interface{})
+
+	switch vectorConfig.Operation {
+	case "upsert":
+		records, err := n.records(vectorConfig, input)
+		if err != nil {
+			return nil, err
+		}
+		if len(records) == 0 {
+			return map[string]interface{}{"count": 0}, nil
+		}
+
+		if vectorConfig.CreateIfMissing {
+			dimensions := vectorConfig.Dimensions
+			if dimensions == 0 {
+				dimensions = len(records[0].Vector)
+			}
+			if err := backend.Ensure(ctx, dimensions); err != nil {
+				return nil, err
+			}
+		}
+
+		count, err := backend.Upsert(ctx, records)
+		if err != nil {
+			return nil, err
+		}
+
+		ids := make([]interface{}, len(records))
+		for i, record := range records {
+			ids[i] = record.ID
+		}
+		return map[string]interface{}{"count": count, "ids": ids}, nil
+
+	case "query":
+		vector, err := toVector(resolveTemplateValue(vectorConfig.Vector, input))
+		if err != nil {
+			return nil, fmt.Errorf("invalid query vector: %w", err)
+		}
+
+		found, err := backend.Query(ctx, vector, vectorConfig.TopK, filter)
+		if err != nil {
+			return nil, err
+		}
+
+		matches := make([]interface{}, 0, len(found))
+		for _, match := range found {
+			if vectorConfig.MinScore != nil && match.Score < *vectorConfig.MinScore {
+				continue
+			}
+			matches = append(matches, map[string]interface{}{
+				"id":       match.ID,
+				"score":    match.Score,
+				"metadata": match.Metadata,
+			})
+		}
+		return map[string]interface{}{"matches": matches, "count": len(matches)}, nil
+
+	case "delete":
+		var ids []string
+		if vectorConfig.IDs != "" {
+			ids, err = toIDList(resolveTemplateValue(vectorConfig.IDs, input))
+			if err != nil {
+				return nil, err
+			}
+		}
+		if len(ids) == 0 && len(filter) == 0 {
+			return nil, fmt.Errorf("ids or filter is required for delete")
+		}
+
+		count, err := backend.Delete(ctx, ids, filter)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{"count": count}, nil
+
+	default:
+		return nil, fmt.Errorf("unsupported operation: %s", vectorConfig.Operation)
+	}
+}
+
+// ValidateConfig validates the node configuration
+func (n *VectorStoreNode) ValidateConfig(config interface{}) error {
+	vectorConfig, err := n.parseConfig(config)
+	if err != nil {
+		return err
+	}
+
+	switch vectorConfig.Backend {
+	case "pgvector":
+		if vectorConfig.ConnectionString == "" {
+			return fmt.Errorf("connection_string is required for pgvector")
+		}
+		if !sqlIdentifierPattern.MatchString(vectorConfig.Table) {
+			return fmt.Errorf("invalid table name: %s", vectorConfig.Table)
+		}
+	case "qdrant":
+		if vectorConfig.URL == "" {
+			return fmt.Errorf("url is required for qdrant")
+		}
+		if vectorConfig.Collection == "" {
+			return fmt.Errorf("collection is required for qdrant")
+		}
+	default:
+		return fmt.Errorf("invalid backend: %s", vectorConfig.Backend)
+	}
+
+	switch vectorConfig.Operation {
+	case "upsert":
+	case "query":
+		if vectorConfig.Vector == "" {
+			return fmt.Errorf("vector is required for query")
+		}
+		if vectorConfig.TopK < 0 {
+			return fmt.Errorf("top_k must be positive")
+		}
+	case "delete":
+		if vectorConfig.IDs == "" && len(vectorConfig.Filter) == 0 {
+			return fmt.Errorf("ids or filter is required for delete")
+		}
+	default:
+		return fmt.Errorf("invalid operation: %s", vectorConfig.Operation)
+	}
+
+	switch vectorConfig.Distance {
+	case "cosine", "euclidean", "dot":
+	default:
+		return fmt.Errorf("invalid distance: %s", vectorConfig.Distance)
+	}
+
+	return nil
+}
+
+// GetSchema returns the node configuration schema
+func (n *VectorStoreNode) GetSchema() engine.NodeSchema {
+	return engine.NodeSchema{
+		Type: "object",
+		Properties: map[string]engine.Property{
+			"backend": {
+				Type:        "string",
+				Title:       "Backend",
+				Description: "Vector database",
+				Enum:        []string{"pgvector", "qdrant"},
+			},
+			"operation": {
+				Type:        "string",
+				Title:       "Operation",
+				Description: "Operation to perform",
+				Enum:        []string{"upsert", "query", "delete"},
+			},
+			"connection_string": {
+				Type:        "string",
+				Title:       "Connection String",
+				Description: "PostgreSQL connection string (pgvector)",
+				Format:      "password",
+			},
+			"table": {
+				Type:        "string",
+				Title:       "Table",
+				Description: "Table with id, embedding, and metadata columns (pgvector)",
+				Default:     "embeddings",
+			},
+			"url": {
+				Type:        "string",
+				Title:       "URL",
+				Description: "Qdrant REST URL, e.g. http://localhost:6333",
+			},
+			"api_key": {
+				Type:        "string",
+				Title:       "API Key",
+				Description: "Qdrant API key",
+				Format:      "password",
+			},
+			"collection": {
+				Type:        "string",
+				Title:       "Collection",
+				Description: "Qdrant collection",
+			},
+			"create_if_missing": {
+				Type:        "boolean",
+				Title:       "Create If Missing",
+				Description: "Create the table or collection on upsert",
+				Default:     false,
+			},
+			"dimensions": {
+				Type:        "number",
+				Title:       "Dimensions",
+				Description: "Embedding size used when creating; inferred from the first vector when empty",
+			},
+			"distance": {
+				Type:        "string",
+				Title:       "Distance",
+				Description: "Similarity metric",
+				Default:     "cosine",
+				Enum:        []string{"cosine", "euclidean", "dot"},
+			},
+			"items_path": {
+				Type:        "string",
+				Title:       "Items Path",
+				Description: "Path to the records to upsert in the input",
+				Default:     "items",
+			},
+			"id_field": {
+				Type:        "string",
+				Title:       "ID Field",
+				Description: "Record field holding the ID; a UUID is generated when missing",
+				Default:     "id",
+			},
+			"vector_field": {
+				Type:        "string",
+				Title:       "Vector Field",
+				Description: "Record field holding the embedding",
+				Default:     "embedding",
+			},
+			"metadata_field": {
+				Type:        "string",
+				Title:       "Metadata Field",
+				Description: "Record field holding metadata; the remaining fields are used when empty",
+				Default:     "metadata",
+			},
+			"vector": {
+				Type:        "string",
+				Title:       "Query Vector",
+				Description: "Template resolving to the query embedding, e.g. {{embedding}}",
+			},
+			"top_k": {
+				Type:        "number",
+				Title:       "Top K",
+				Description: "Number of matches to return",
+				Default:     5,
+			},
+			"min_score": {
+				Type:        "number",
+				Title:       "Minimum Score",
+				Description: "Drop matches scoring below this value",
+			},
+			"filter": {
+				Type:        "object",
+				Title:       "Filter",
+				Description: "Metadata values matches must equal; supports template variables",
+			},
+			"ids": {
+				Type:        "string",
+				Title:       "IDs",
+				Description: "Template resolving to an ID or array of IDs to delete",
+			},
+			"timeout": {
+				Type:        "number",
+				Title:       "Timeout",
+				Description: "Operation timeout in seconds",
+				Default:     30,
+			},
+		},
+		Required: []string{"backend", "operation"},
+		Inputs: []engine.PortSchema{
+			{
+				Name:        "input",
+				Type:        "any",
+				Description: "Records to upsert and data for template variables",
+				Required:    false,
+			},
+		},
+		Outputs: []engine.PortSchema{
+			{
+				Name:        "output",
+				Type:        "object",
+				Description: "Upserted IDs, query matches {id, score, metadata}, or deleted count",
+				Required:    true,
+			},
+		},
+	}
+}
+
+// parseConfig parses the node configuration
+func (n *VectorStoreNode) parseConfig(config interface{}) (*VectorStoreConfig, error) {
+	configMap, ok := config.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("invalid config type for vector_store node")
+	}
+
+	configJSON, err := json.Marshal(configMap)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal config: %w", err)
+	}
+
+	var vectorConfig VectorStoreConfig
+	if err := json.Unmarshal(configJSON, &vectorConfig); err != nil {
+		return nil, fmt.Errorf("failed to parse vector_store config: %w", err)
+	}
+
+	// Set defaults
+	vectorConfig.Backend = strings.ToLower(vectorConfig.Backend)
+	if vectorConfig.Table == "" {
+		vectorConfig.Table = "embeddings"
+	}
+	if vectorConfig.Distance == "" {
+		vectorConfig.Distance = "cosine"
+	}
+	if vectorConfig.ItemsPath == "" {
+		vectorConfig.ItemsPath = "items"
+	}
+	if vectorConfig.IDField == "" {
+		vectorConfig.IDField = "id"
+	}
+	if vectorConfig.VectorField == "" {
+		vectorConfig.VectorField = "embedding"
+	}
+	if vectorConfig.MetadataField == "" {
+		vectorConfig.MetadataField = "metadata"
+	}
+	if vectorConfig.TopK == 0 {
+		vectorConfig.TopK = 5
+	}
+	if vectorConfig.Timeout == 0 {
+		vectorConfig.Timeout = 30
+	}
+
+	return &vectorConfig, nil
+}
+
+// backend connects to the configured vector database
+func (n *VectorStoreNode) backend(ctx context.Context, config *VectorStoreConfig, input interface{}) (vectorBackend, error) {
+	switch config.Backend {
+	case "pgvector":
+		if !sqlIdentifierPattern.MatchString(config.Table) {
+			return nil, fmt.Errorf("invalid table name: %s", config.Table)
+		}
+		return newPGVectorBackend(ctx, processTemplate(config.ConnectionString, input), config.Table, config.Distance)
+	case "qdrant":
+		backend := newQdrantBackend(
+			processTemplate(config.URL, input),
+			processTemplate(config.APIKey, input),
+			processTemplate(config.Collection, input),
+			config.Distance,
+		)
+		backend.client = guardClient(ctx, backend.client)
+		return backend, nil
+	default:
+		return nil, fmt.Errorf("unsupported backend: %s", config.Backend)
+	}
+}
+
+// records extracts the records to upsert from the input
+func (n *VectorStoreNode) records(config *VectorStoreConfig, input interface{}) ([]vectorRecord, error) {
+	inputData, _ := input.(map[string]interface{})
+	items, ok := getValueByPath(inputData, config.ItemsPath).([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("value at path '%s' is not an array", config.ItemsPath)
+	}
+
+	records := make([]vectorRecord, 0, len(items))
+	for i, item := range items {
+		itemMap, ok := item.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("item %d is not an object", i)
+		}
+
+		vector, err := toVector(itemMap[config.VectorField])
+		if err != nil {
+			return nil, fmt.Errorf("item %d: invalid %s: %w", i, config.VectorField, err)
+		}
+
+		record := vectorRecord{Vector: vector}
+		if id, ok := itemMap[config.IDField]; ok && id != nil {
+			record.ID = fmt.Sprintf("%v", id)
+		} else {
+			record.ID = uuid.New().String()
+		}
+
+		if metadata, ok := itemMap[config.MetadataField].(map[string]interface{}); ok {
+			record.Metadata = metadata
+		} else {
+			record.Metadata = make(map[string]interface{})
+			for key, value := range itemMap {
+				if key != config.IDField && key != config.VectorField {
+					record.Metadata[key] = value
+				}
+			}
+		}
+
+		records = append(records, record)
+	}
+
+	return records, nil
+}
+
+// toVector converts a JSON array of numbers into a vector
+func toVector(value interface{}) ([]float64, error) {
+	switch v := value.(type) {
+	case []float64:
+		return v, nil
+	case []interface{}:
+		vector := make([]float64, len(v))
+		for i, item := range v {
+			f, ok := toFloat64(item)
+			if !ok {
+				return nil, fmt.Errorf("element %d is not a number", i)
+			}
+			vector[i] = f
+		}
+		if len(vector) == 0 {
+			return nil, fmt.Errorf("vector is empty")
+		}
+		return vector, nil
+	default:
+		return nil, fmt.Errorf("expected an array of numbers, got %T", value)
+	}
+}
+
+// toIDList converts a resolved ID template into a list of IDs
+func toIDList(value interface{}) ([]string, error) {
+	switch v := value.(type) {
+	case []interface{}:
+		ids := make([]string, len(v))
+		for i, id := range v {
+			ids[i] = fmt.Sprintf("%v", id)
+		}
+		return ids, nil
+	case string:
+		if v == "" {
+			return nil, nil
+		}
+		return []string{v}, nil
+	case nil:
+		return nil, nil
+	default:
+		return []string{fmt.Sprintf("%v", v)}, nil
+	}
+}
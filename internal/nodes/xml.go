@@ -0,0 +1,118 @@
+package nodes
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+
+	"github.com/antchfx/xmlquery"
+	"github.com/antchfx/xpath"
+)
+
+var xmlTemplatePattern = regexp.MustCompile(`\{\{([^}]+)\}\}`)
+
+// processXMLTemplate replaces template variables like processTemplate, escaping
+// substituted values so they cannot break the surrounding XML
+func processXMLTemplate(template string, data interface{}) string {
+	return xmlTemplatePattern.ReplaceAllStringFunc(template, func(match string) string {
+		resolved := processTemplate(match, data)
+		if resolved == match {
+			return match
+		}
+
+		var buf bytes.Buffer
+		xml.EscapeText(&buf, []byte(resolved))
+		return buf.String()
+	})
+}
+
+// ParseXML parses an XML document into a map keyed by the root element name.
+// Attributes are stored as "@name", text next to attributes or child elements
+// as "#text", and repeated elements become arrays. Namespace prefixes are
+// dropped from keys.
+func ParseXML(r io.Reader) (map[string]interface{}, error) {
+	doc, err := xmlquery.Parse(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse XML: %w", err)
+	}
+
+	root := xmlquery.FindOne(doc, "/*")
+	if root == nil {
+		return nil, fmt.Errorf("XML document has no root element")
+	}
+
+	return map[string]interface{}{root.Data: xmlNodeToValue(root)}, nil
+}
+
+// xmlNodeToValue converts an element into a string or map
+func xmlNodeToValue(node *xmlquery.Node) interface{} {
+	result := make(map[string]interface{})
+	var text strings.Builder
+
+	for _, attr := range node.Attr {
+		// Namespace declarations are not data
+		if attr.Name.Space == "xmlns" || attr.Name.Local == "xmlns" {
+			continue
+		}
+		result["@"+attr.Name.Local] = attr.Value
+	}
+
+	for child := node.FirstChild; child != nil; child = child.NextSibling {
+		switch child.Type {
+		case xmlquery.ElementNode:
+			value := xmlNodeToValue(child)
+			if existing, ok := result[child.Data]; ok {
+				if list, ok := existing.([]interface{}); ok {
+					result[child.Data] = append(list, value)
+				} else {
+					result[child.Data] = []interface{}{existing, value}
+				}
+			} else {
+				result[child.Data] = value
+			}
+		case xmlquery.TextNode, xmlquery.CharDataNode:
+			text.WriteString(child.Data)
+		}
+	}
+
+	content := strings.TrimSpace(text.String())
+	if len(result) == 0 {
+		return content
+	}
+	if content != "" {
+		result["#text"] = content
+	}
+
+	return result
+}
+
+// evaluateXPath evaluates an XPath expression against a document. Node-set
+// results return the text of a single node or a list of texts; numeric,
+// string, and boolean expressions return their value.
+func evaluateXPath(doc *xmlquery.Node, expr string) (interface{}, error) {
+	compiled, err := xpath.Compile(expr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid xpath %q: %w", expr, err)
+	}
+
+	switch value := compiled.Evaluate(xmlquery.CreateXPathNavigator(doc)).(type) {
+	case *xpath.NodeIterator:
+		var values []interface{}
+		for value.MoveNext() {
+			values = append(values, value.Current().Value())
+		}
+		switch len(values) {
+		case 0:
+			return nil, nil
+		case 1:
+			return values[0], nil
+		default:
+			return values, nil
+		}
+	default:
+		return value, nil
+	}
+}
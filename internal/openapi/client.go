@@ -0,0 +1,370 @@
+package openapi
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"go/token"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// GenerateClient returns Go source for the document's authenticated
+// operations and every component schema. Public operations such as webhooks
+// are skipped. The generated methods call do and doRaw on a hand-written
+// Client type in the same package.
+func GenerateClient(doc *Document, pkg string) ([]byte, error) {
+	g := &clientGenerator{doc: doc, imports: make(map[string]bool)}
+
+	names := make([]string, 0, len(doc.Components.Schemas))
+	for name := range doc.Components.Schemas {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		g.writeType(name, doc.Components.Schemas[name])
+	}
+
+	for _, op := range g.operations() {
+		if err := g.writeOperation(op); err != nil {
+			return nil, err
+		}
+	}
+
+	var out bytes.Buffer
+	fmt.Fprintf(&out, "// Code generated by cmd/openapi from the OpenAPI spec; DO NOT EDIT.\n\npackage %s\n\n", pkg)
+	if len(g.imports) > 0 {
+		// Standard library first, then third-party packages
+		var std, external []string
+		for path := range g.imports {
+			if strings.Contains(strings.Split(path, "/")[0], ".") {
+				external = append(external, strconv.Quote(path))
+			} else {
+				std = append(std, strconv.Quote(path))
+			}
+		}
+		sort.Strings(std)
+		sort.Strings(external)
+		groups := strings.Join(std, "\n")
+		if len(external) > 0 {
+			groups += "\n\n" + strings.Join(external, "\n")
+		}
+		fmt.Fprintf(&out, "import (\n%s\n)\n\n", groups)
+	}
+	out.Write(g.body.Bytes())
+
+	src, err := format.Source(out.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("failed to format generated client: %w", err)
+	}
+	return src, nil
+}
+
+type clientGenerator struct {
+	doc     *Document
+	body    bytes.Buffer
+	imports map[string]bool
+}
+
+type clientOperation struct {
+	path   string
+	method string
+	*Operation
+}
+
+// operations returns the authenticated operations sorted by ID
+func (g *clientGenerator) operations() []clientOperation {
+	var ops []clientOperation
+	for path, item := range g.doc.Paths {
+		for method, op := range *item {
+			if len(op.Security) > 0 {
+				ops = append(ops, clientOperation{path: path, method: strings.ToUpper(method), Operation: op})
+			}
+		}
+	}
+	sort.Slice(ops, func(i, j int) bool { return ops[i].OperationID < ops[j].OperationID })
+	return ops
+}
+
+func (g *clientGenerator) printf(format string, args ...interface{}) {
+	fmt.Fprintf(&g.body, format, args...)
+}
+
+func (g *clientGenerator) writeType(name string, schema *Schema) {
+	if schema.Type != "object" || schema.Properties == nil {
+		g.printf("// %s is a %s schema\ntype %s %s\n\n", name, name, name, g.goType(schema))
+		return
+	}
+
+	props := make([]string, 0, len(schema.Properties))
+	for prop := range schema.Properties {
+		props = append(props, prop)
+	}
+	sort.Strings(props)
+
+	g.printf("// %s is the %s schema\ntype %s struct {\n", name, name, name)
+	for _, prop := range props {
+		s := schema.Properties[prop]
+		tag := prop
+		if s.Nullable {
+			tag += ",omitempty"
+		}
+		g.printf("\t%s %s `json:%q`\n", goName(prop), g.goType(s), tag)
+	}
+	g.printf("}\n\n")
+}
+
+func (g *clientGenerator) writeOperation(op clientOperation) error {
+	var (
+		args       = []string{"ctx context.Context"}
+		pathParams []Parameter
+		query      []Parameter
+	)
+	g.imports["context"] = true
+
+	for _, p := range op.Parameters {
+		switch p.In {
+		case "path":
+			pathParams = append(pathParams, p)
+			args = append(args, paramName(p.Name)+" string")
+		case "query":
+			query = append(query, p)
+		}
+	}
+
+	body := "nil"
+	if op.RequestBody != nil {
+		media, ok := op.RequestBody.Content["application/json"]
+		if !ok {
+			return fmt.Errorf("operation %s: only JSON request bodies are supported", op.OperationID)
+		}
+		args = append(args, "body "+g.valueType(media.Schema))
+		body = "body"
+	}
+
+	queryExpr := "nil"
+	if len(query) > 0 {
+		g.writeParams(op.OperationID, query)
+		args = append(args, "params *"+op.OperationID+"Params")
+		queryExpr = "query"
+	}
+
+	// The result is the body of the first successful response
+	var success *Response
+	codes := make([]string, 0, len(op.Responses))
+	for code := range op.Responses {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	for _, code := range codes {
+		if strings.HasPrefix(code, "2") {
+			success = op.Responses[code]
+			break
+		}
+	}
+
+	result, raw := "", false
+	if success != nil && len(success.Content) > 0 {
+		if media, ok := success.Content["application/json"]; ok {
+			result = g.valueType(media.Schema)
+		} else {
+			result, raw = "[]byte", true
+		}
+	}
+
+	g.printf("// %s calls %s %s.\n", op.OperationID, op.method, op.path)
+	if op.Summary != "" {
+		g.printf("//\n// %s.\n", strings.TrimSuffix(op.Summary, "."))
+	}
+	returns := "error"
+	if result != "" {
+		returns = "(" + result + ", error)"
+	}
+	g.printf("func (c *Client) %s(%s) %s {\n", op.OperationID, strings.Join(args, ", "), returns)
+
+	g.imports["net/url"] = true
+	g.printf("\tpath := %s\n", pathExpr(op.path))
+	if len(query) > 0 {
+		g.printf("\tvar query url.Values\n\tif params != nil {\n\t\tquery = params.values()\n\t}\n")
+	}
+
+	switch {
+	case result == "":
+		g.printf("\treturn c.do(ctx, %q, path, %s, %s, nil)\n", op.method, queryExpr, body)
+	case raw:
+		g.printf("\treturn c.doRaw(ctx, %q, path, %s, %s)\n", op.method, queryExpr, body)
+	case strings.HasPrefix(result, "*"):
+		g.printf("\tvar out %s\n", strings.TrimPrefix(result, "*"))
+		g.printf("\tif err := c.do(ctx, %q, path, %s, %s, &out); err != nil {\n\t\treturn nil, err\n\t}\n", op.method, queryExpr, body)
+		g.printf("\treturn &out, nil\n")
+	default:
+		g.printf("\tvar out %s\n", result)
+		g.printf("\tif err := c.do(ctx, %q, path, %s, %s, &out); err != nil {\n\t\treturn nil, err\n\t}\n", op.method, queryExpr, body)
+		g.printf("\treturn out, nil\n")
+	}
+	g.printf("}\n\n")
+
+	if strings.Contains(op.path, "{") && len(pathParams) == 0 {
+		return fmt.Errorf("operation %s: path parameters are not declared", op.OperationID)
+	}
+	return nil
+}
+
+// writeParams writes the query parameter struct of an operation
+func (g *clientGenerator) writeParams(operationID string, params []Parameter) {
+	name := operationID + "Params"
+	g.printf("// %s holds the query parameters of %s\ntype %s struct {\n", name, operationID, name)
+	for _, p := range params {
+		if p.Description != "" {
+			g.printf("\t// %s\n", p.Description)
+		}
+		g.printf("\t%s %s\n", goName(p.Name), g.goType(p.Schema))
+	}
+	g.printf("}\n\n")
+
+	g.printf("func (p *%s) values() url.Values {\n\tquery := url.Values{}\n", name)
+	for _, p := range params {
+		field := "p." + goName(p.Name)
+		switch g.goType(p.Schema) {
+		case "int":
+			g.imports["strconv"] = true
+			g.printf("\tif %s != 0 {\n\t\tquery.Set(%q, strconv.Itoa(%s))\n\t}\n", field, p.Name, field)
+		case "int64":
+			g.imports["strconv"] = true
+			g.printf("\tif %s != 0 {\n\t\tquery.Set(%q, strconv.FormatInt(%s, 10))\n\t}\n", field, p.Name, field)
+		case "bool":
+			g.printf("\tif %s {\n\t\tquery.Set(%q, \"true\")\n\t}\n", field, p.Name)
+		case "[]string":
+			g.printf("\tfor _, v := range %s {\n\t\tquery.Add(%q, v)\n\t}\n", field, p.Name)
+		case "time.Time":
+			g.printf("\tif !%s.IsZero() {\n\t\tquery.Set(%q, %s.Format(time.RFC3339))\n\t}\n", field, p.Name, field)
+		case "uuid.UUID":
+			g.printf("\tif %s != uuid.Nil {\n\t\tquery.Set(%q, %s.String())\n\t}\n", field, p.Name, field)
+		default:
+			g.printf("\tif %s != \"\" {\n\t\tquery.Set(%q, %s)\n\t}\n", field, p.Name, field)
+		}
+	}
+	g.printf("\treturn query\n}\n\n")
+}
+
+// valueType is the Go type of a request or response body; referenced
+// structs are passed by pointer
+func (g *clientGenerator) valueType(s *Schema) string {
+	if s != nil && s.Ref != "" {
+		return "*" + RefName(s)
+	}
+	return g.goType(s)
+}
+
+func (g *clientGenerator) goType(s *Schema) string {
+	if s == nil {
+		return "interface{}"
+	}
+	if s.Ref != "" {
+		return RefName(s)
+	}
+	if len(s.AllOf) == 1 {
+		t := g.goType(s.AllOf[0])
+		if s.Nullable {
+			t = "*" + t
+		}
+		return t
+	}
+
+	var t string
+	switch s.Type {
+	case "string":
+		switch s.Format {
+		case "date-time":
+			g.imports["time"] = true
+			t = "time.Time"
+		case "uuid":
+			g.imports["github.com/google/uuid"] = true
+			t = "uuid.UUID"
+		case "byte":
+			return "[]byte"
+		default:
+			t = "string"
+		}
+	case "integer":
+		t = "int"
+		if s.Format == "int64" {
+			t = "int64"
+		}
+	case "number":
+		t = "float64"
+	case "boolean":
+		t = "bool"
+	case "array":
+		return "[]" + g.goType(s.Items)
+	case "object":
+		if s.AdditionalProperties != nil {
+			return "map[string]" + g.goType(s.AdditionalProperties)
+		}
+		return "map[string]interface{}"
+	default:
+		return "interface{}"
+	}
+
+	if s.Nullable {
+		t = "*" + t
+	}
+	return t
+}
+
+// pathExpr returns a Go expression building the path with escaped parameters
+func pathExpr(path string) string {
+	var parts []string
+	for path != "" {
+		start := strings.Index(path, "{")
+		if start < 0 {
+			parts = append(parts, strconv.Quote(path))
+			break
+		}
+		end := strings.Index(path[start:], "}") + start
+		if start > 0 {
+			parts = append(parts, strconv.Quote(path[:start]))
+		}
+		parts = append(parts, "url.PathEscape("+paramName(path[start+1:end])+")")
+		path = path[end+1:]
+	}
+	return strings.Join(parts, " + ")
+}
+
+// initialisms are written in upper case in Go identifiers
+var initialisms = map[string]bool{
+	"api": true, "http": true, "id": true, "ip": true, "json": true, "url": true, "uuid": true,
+}
+
+// goName converts a JSON or parameter name to an exported Go identifier
+func goName(name string) string {
+	var b strings.Builder
+	for _, part := range splitName(name) {
+		if initialisms[strings.ToLower(part)] {
+			b.WriteString(strings.ToUpper(part))
+		} else {
+			b.WriteString(exportedName(part))
+		}
+	}
+	return b.String()
+}
+
+// paramName converts a parameter name to a Go argument name
+func paramName(name string) string {
+	parts := splitName(name)
+	if len(parts) == 0 {
+		return "param"
+	}
+	result := strings.ToLower(parts[0]) + goName(strings.Join(parts[1:], "_"))
+	if token.IsKeyword(result) {
+		result += "Name"
+	}
+	return result
+}
+
+func splitName(name string) []string {
+	return strings.FieldsFunc(name, func(r rune) bool {
+		return r == '_' || r == '-' || r == '.' || r == ' '
+	})
+}
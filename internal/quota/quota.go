@@ -0,0 +1,251 @@
+// Package quota enforces the quotas of tenants: how many workflows they may
+// have active, how many executions they may start a day, how long an
+// execution may run, and how large its input may be. A tenant's quotas are
+// its own, then its plan's, then the server's.
+package quota
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/nuumz/f1ow/internal/models"
+
+	"github.com/google/uuid"
+)
+
+// Names of the quotas, as in models.Quotas
+const (
+	ActiveWorkflows   = "max_active_workflows"
+	ExecutionsPerDay  = "max_executions_per_day"
+	ExecutionDuration = "max_execution_seconds"
+	PayloadSize       = "max_payload_size"
+)
+
+// cacheTTL is how long a tenant's quotas are used before they are read
+// again
+const cacheTTL = 30 * time.Second
+
+// ExceededError is returned when a tenant is over one of its quotas
+type ExceededError struct {
+	Quota string
+	Limit int
+	Used  int // including what was refused
+	// RetryAfter is when the quota allows more again, or 0 when waiting
+	// does not help
+	RetryAfter time.Duration
+}
+
+func (e *ExceededError) Error() string {
+	switch e.Quota {
+	case ActiveWorkflows:
+		return fmt.Sprintf("quota exceeded: at most %d workflows may be active", e.Limit)
+	case ExecutionsPerDay:
+		return fmt.Sprintf("quota exceeded: at most %d executions may start per day", e.Limit)
+	case ExecutionDuration:
+		return fmt.Sprintf("quota exceeded: executions may run for at most %ds", e.Limit)
+	case PayloadSize:
+		return fmt.Sprintf("quota exceeded: execution input is %d bytes; at most %d are allowed", e.Used, e.Limit)
+	}
+	return fmt.Sprintf("quota %s exceeded: limit %d", e.Quota, e.Limit)
+}
+
+// Store reads the quotas of tenants and what they use
+type Store interface {
+	// TenantQuotas returns the plan of a tenant and the quotas it and its
+	// plan set
+	TenantQuotas(ctx context.Context, id uuid.UUID) (string, models.Quotas, error)
+	CountActiveWorkflows(ctx context.Context, tenantID uuid.UUID) (int, error)
+	CountExecutionsSince(ctx context.Context, tenantID uuid.UUID, since time.Time) (int, error)
+}
+
+type cachedQuotas struct {
+	plan    string
+	quotas  models.Quotas
+	expires time.Time
+}
+
+// Enforcer checks what tenants do against their quotas. A nil Enforcer
+// allows everything.
+type Enforcer struct {
+	defaults models.Quotas
+	store    Store
+
+	mu    sync.Mutex
+	cache map[uuid.UUID]cachedQuotas
+}
+
+// New creates an enforcer holding tenants to the quotas the store has for
+// them, and to defaults for the quotas they leave unset
+func New(defaults models.Quotas, store Store) *Enforcer {
+	return &Enforcer{
+		defaults: defaults.Inherit(unlimited()),
+		store:    store,
+		cache:    make(map[uuid.UUID]cachedQuotas),
+	}
+}
+
+// unlimited sets every quota to 0
+func unlimited() models.Quotas {
+	zero := 0
+	return models.Quotas{
+		MaxActiveWorkflows:  &zero,
+		MaxExecutionsPerDay: &zero,
+		MaxExecutionSeconds: &zero,
+		MaxPayloadSize:      &zero,
+	}
+}
+
+// Quotas returns the plan of a tenant and every quota it is held to
+func (e *Enforcer) Quotas(ctx context.Context, tenantID uuid.UUID) (string, models.Quotas, error) {
+	if e == nil {
+		return "", unlimited(), nil
+	}
+
+	e.mu.Lock()
+	cached, ok := e.cache[tenantID]
+	e.mu.Unlock()
+	if ok && time.Now().Before(cached.expires) {
+		return cached.plan, cached.quotas, nil
+	}
+
+	plan, quotas, err := e.store.TenantQuotas(ctx, tenantID)
+	if err != nil {
+		return "", models.Quotas{}, fmt.Errorf("failed to load tenant quotas: %w", err)
+	}
+	quotas = quotas.Inherit(e.defaults)
+
+	e.mu.Lock()
+	e.cache[tenantID] = cachedQuotas{plan: plan, quotas: quotas, expires: time.Now().Add(cacheTTL)}
+	e.mu.Unlock()
+	return plan, quotas, nil
+}
+
+// Forget drops the cached quotas of a tenant, so a change applies at once
+// on this process
+func (e *Enforcer) Forget(tenantID uuid.UUID) {
+	if e == nil {
+		return
+	}
+	e.mu.Lock()
+	delete(e.cache, tenantID)
+	e.mu.Unlock()
+}
+
+// CheckActivation returns an ExceededError when the tenant may not have
+// count more workflows active
+func (e *Enforcer) CheckActivation(ctx context.Context, tenantID uuid.UUID, count int) error {
+	if e == nil {
+		return nil
+	}
+	_, quotas, err := e.Quotas(ctx, tenantID)
+	if err != nil || *quotas.MaxActiveWorkflows == 0 {
+		return err
+	}
+
+	active, err := e.store.CountActiveWorkflows(ctx, tenantID)
+	if err != nil {
+		return err
+	}
+	if limit := *quotas.MaxActiveWorkflows; active+count > limit {
+		return &ExceededError{Quota: ActiveWorkflows, Limit: limit, Used: active + count}
+	}
+	return nil
+}
+
+// Validate checks that no quota is negative
+func Validate(quotas models.Quotas) error {
+	fields := []struct {
+		name  string
+		value *int
+	}{
+		{ActiveWorkflows, quotas.MaxActiveWorkflows},
+		{ExecutionsPerDay, quotas.MaxExecutionsPerDay},
+		{ExecutionDuration, quotas.MaxExecutionSeconds},
+		{PayloadSize, quotas.MaxPayloadSize},
+	}
+	for _, field := range fields {
+		if field.value != nil && *field.value < 0 {
+			return fmt.Errorf("%s must not be negative", field.name)
+		}
+	}
+	return nil
+}
+
+// CheckExecutions returns an ExceededError when the tenant may not start
+// an execution for each of the inputs today, or one of them is too large
+func (e *Enforcer) CheckExecutions(ctx context.Context, tenantID uuid.UUID, inputs ...map[string]interface{}) error {
+	if e == nil {
+		return nil
+	}
+	_, quotas, err := e.Quotas(ctx, tenantID)
+	if err != nil {
+		return err
+	}
+
+	if limit := *quotas.MaxPayloadSize; limit > 0 {
+		for _, input := range inputs {
+			data, err := json.Marshal(input)
+			if err != nil {
+				return fmt.Errorf("failed to measure execution input: %w", err)
+			}
+			if len(data) > limit {
+				return &ExceededError{Quota: PayloadSize, Limit: limit, Used: len(data)}
+			}
+		}
+	}
+
+	if limit := *quotas.MaxExecutionsPerDay; limit > 0 {
+		today, tomorrow := day()
+		started, err := e.store.CountExecutionsSince(ctx, tenantID, today)
+		if err != nil {
+			return err
+		}
+		if started+len(inputs) > limit {
+			return &ExceededError{Quota: ExecutionsPerDay, Limit: limit, Used: started + len(inputs), RetryAfter: tomorrow.Sub(time.Now())}
+		}
+	}
+	return nil
+}
+
+// MaxDuration returns how long the tenant's executions may run, or 0 when
+// they may run as long as they like
+func (e *Enforcer) MaxDuration(ctx context.Context, tenantID uuid.UUID) (time.Duration, error) {
+	if e == nil {
+		return 0, nil
+	}
+	_, quotas, err := e.Quotas(ctx, tenantID)
+	if err != nil {
+		return 0, err
+	}
+	return time.Duration(*quotas.MaxExecutionSeconds) * time.Second, nil
+}
+
+// Limits returns the quotas of a tenant and how much of them it uses
+func (e *Enforcer) Limits(ctx context.Context, tenantID uuid.UUID) (*models.Limits, error) {
+	plan, quotas, err := e.Quotas(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	today, tomorrow := day()
+	limits := &models.Limits{TenantID: tenantID, Plan: plan, Quotas: quotas, ResetsAt: tomorrow}
+	if e == nil {
+		return limits, nil
+	}
+
+	if limits.Usage.ActiveWorkflows, err = e.store.CountActiveWorkflows(ctx, tenantID); err != nil {
+		return nil, err
+	}
+	if limits.Usage.ExecutionsToday, err = e.store.CountExecutionsSince(ctx, tenantID, today); err != nil {
+		return nil, err
+	}
+	return limits, nil
+}
+
+// day returns the start of the current UTC day and of the next
+func day() (time.Time, time.Time) {
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	return today, today.Add(24 * time.Hour)
+}
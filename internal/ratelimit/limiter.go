@@ -0,0 +1,192 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Rule is a token bucket: Limit requests per Period with up to Burst
+// requests at once
+type Rule struct {
+	Limit  float64
+	Period time.Duration
+	Burst  int
+}
+
+// rate returns the refill rate in tokens per millisecond
+func (r Rule) rate() float64 {
+	return r.Limit / float64(r.Period.Milliseconds())
+}
+
+// burst returns the bucket capacity, defaulting to the limit
+func (r Rule) burst() int {
+	if r.Burst > 0 {
+		return r.Burst
+	}
+	if r.Limit < 1 {
+		return 1
+	}
+	return int(r.Limit)
+}
+
+// Backend stores token buckets. Take removes a token from the bucket at key
+// and returns zero, or returns how long to wait until a token is available
+// without removing one.
+type Backend interface {
+	Take(ctx context.Context, key string, rule Rule) (time.Duration, error)
+}
+
+// hostRule applies a rule to hosts matching a pattern such as
+// "api.github.com" or "*.slack.com"
+type hostRule struct {
+	pattern string
+	rule    Rule
+}
+
+// Limiter throttles outbound requests. Host rules apply to every request to
+// a matching host; callers may also wait on their own keys and rules.
+type Limiter struct {
+	backend     Backend
+	hosts       []hostRule
+	defaultRule *Rule
+}
+
+// NewLimiter creates a limiter. defaultRule, when not nil, applies to hosts
+// without a host rule.
+func NewLimiter(backend Backend, defaultRule *Rule) *Limiter {
+	return &Limiter{backend: backend, defaultRule: defaultRule}
+}
+
+// SetHostRule limits requests to hosts matching pattern
+func (l *Limiter) SetHostRule(pattern string, rule Rule) {
+	l.hosts = append(l.hosts, hostRule{pattern: strings.ToLower(pattern), rule: rule})
+}
+
+// Wait blocks until the bucket at key has a token or the context is done
+func (l *Limiter) Wait(ctx context.Context, key string, rule Rule) error {
+	for {
+		wait, err := l.backend.Take(ctx, "ratelimit:"+key, rule)
+		if err != nil {
+			return fmt.Errorf("rate limiter unavailable: %w", err)
+		}
+		if wait <= 0 {
+			return nil
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// Allow takes a token from the bucket at key without waiting. When the
+// bucket is empty it returns false and how long until a token is available.
+func (l *Limiter) Allow(ctx context.Context, key string, rule Rule) (bool, time.Duration, error) {
+	wait, err := l.backend.Take(ctx, "ratelimit:"+key, rule)
+	if err != nil {
+		return false, 0, fmt.Errorf("rate limiter unavailable: %w", err)
+	}
+	return wait <= 0, wait, nil
+}
+
+// WaitURL applies the host rule matching the URL's host, if any
+func (l *Limiter) WaitURL(ctx context.Context, rawURL string) error {
+	parsed, err := url.Parse(rawURL)
+	if err != nil || parsed.Hostname() == "" {
+		return nil
+	}
+	host := strings.ToLower(parsed.Hostname())
+
+	rule, ok := l.hostRule(host)
+	if !ok {
+		return nil
+	}
+	return l.Wait(ctx, "host:"+host, rule)
+}
+
+// hostRule returns the first host rule matching host, falling back to the
+// default rule
+func (l *Limiter) hostRule(host string) (Rule, bool) {
+	for _, h := range l.hosts {
+		if matched, _ := path.Match(h.pattern, host); matched {
+			return h.rule, true
+		}
+	}
+	if l.defaultRule != nil {
+		return *l.defaultRule, true
+	}
+	return Rule{}, false
+}
+
+// ParseRule parses a rule written as "<limit>/<period>", e.g. "10/s",
+// "5000/h", or "100/30s"
+func ParseRule(spec string) (Rule, error) {
+	limitStr, periodStr, found := strings.Cut(strings.TrimSpace(spec), "/")
+	if !found {
+		return Rule{}, fmt.Errorf("invalid rate limit %q: expected <limit>/<period>", spec)
+	}
+
+	limit, err := strconv.ParseFloat(strings.TrimSpace(limitStr), 64)
+	if err != nil || limit <= 0 {
+		return Rule{}, fmt.Errorf("invalid rate limit %q: limit must be a positive number", spec)
+	}
+
+	periodStr = strings.TrimSpace(periodStr)
+	switch periodStr {
+	case "s", "m", "h":
+		periodStr = "1" + periodStr
+	case "d":
+		periodStr = "24h"
+	}
+	period, err := time.ParseDuration(periodStr)
+	if err != nil || period < time.Millisecond {
+		return Rule{}, fmt.Errorf("invalid rate limit %q: invalid period", spec)
+	}
+
+	return Rule{Limit: limit, Period: period}, nil
+}
+
+// ConfigureHosts adds host rules given as a comma-separated list of
+// host=rule entries, e.g. "api.github.com=5000/h,*.slack.com=1/s"
+func (l *Limiter) ConfigureHosts(spec string) error {
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		host, ruleSpec, found := strings.Cut(entry, "=")
+		if !found {
+			return fmt.Errorf("invalid host rate limit %q: expected host=<limit>/<period>", entry)
+		}
+		rule, err := ParseRule(ruleSpec)
+		if err != nil {
+			return err
+		}
+		l.SetHostRule(strings.TrimSpace(host), rule)
+	}
+	return nil
+}
+
+type contextKey string
+
+const limiterKey contextKey = "rate_limiter"
+
+// WithLimiter returns a context carrying the limiter
+func WithLimiter(ctx context.Context, limiter *Limiter) context.Context {
+	return context.WithValue(ctx, limiterKey, limiter)
+}
+
+// FromContext returns the limiter stored in the context, if any
+func FromContext(ctx context.Context) (*Limiter, bool) {
+	limiter, ok := ctx.Value(limiterKey).(*Limiter)
+	return limiter, ok && limiter != nil
+}
@@ -0,0 +1,54 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// MemoryBackend keeps token buckets and sliding windows in process memory.
+// Limits only apply within a single process.
+type MemoryBackend struct {
+	mu      sync.Mutex
+	buckets map[string]*memoryBucket
+	windows map[string][]time.Time
+}
+
+type memoryBucket struct {
+	tokens float64
+	ts     time.Time
+}
+
+// NewMemoryBackend creates an in-process token bucket backend
+func NewMemoryBackend() *MemoryBackend {
+	return &MemoryBackend{
+		buckets: make(map[string]*memoryBucket),
+		windows: make(map[string][]time.Time),
+	}
+}
+
+// Take implements Backend
+func (b *MemoryBackend) Take(ctx context.Context, key string, rule Rule) (time.Duration, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := time.Now()
+	burst := float64(rule.burst())
+	bucket, ok := b.buckets[key]
+	if !ok {
+		bucket = &memoryBucket{tokens: burst, ts: now}
+		b.buckets[key] = bucket
+	}
+
+	elapsed := float64(now.Sub(bucket.ts)) / float64(time.Millisecond)
+	bucket.tokens = math.Min(burst, bucket.tokens+math.Max(0, elapsed)*rule.rate())
+	bucket.ts = now
+
+	if bucket.tokens >= 1 {
+		bucket.tokens--
+		return 0, nil
+	}
+	wait := math.Ceil((1 - bucket.tokens) / rule.rate())
+	return time.Duration(wait) * time.Millisecond, nil
+}
@@ -0,0 +1,101 @@
+package ratelimit
+
+import (
+	"context"
+	"math/rand"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// takeScript refills the bucket for the elapsed time, then takes a token or
+// returns the milliseconds until one is available
+var takeScript = redis.NewScript(`
+local rate = tonumber(ARGV[1])
+local burst = tonumber(ARGV[2])
+local now = tonumber(ARGV[3])
+
+local bucket = redis.call('HMGET', KEYS[1], 'tokens', 'ts')
+local tokens = tonumber(bucket[1]) or burst
+local ts = tonumber(bucket[2]) or now
+tokens = math.min(burst, tokens + math.max(0, now - ts) * rate)
+
+local wait = 0
+if tokens >= 1 then
+	tokens = tokens - 1
+else
+	wait = math.ceil((1 - tokens) / rate)
+end
+
+redis.call('HSET', KEYS[1], 'tokens', tostring(tokens), 'ts', now)
+redis.call('PEXPIRE', KEYS[1], math.ceil(burst / rate) + 1000)
+return wait
+`)
+
+// RedisBackend keeps token buckets in Redis so limits hold across all API
+// servers and workers
+type RedisBackend struct {
+	client redis.Cmdable
+}
+
+// NewRedisBackend creates a Redis token bucket backend
+func NewRedisBackend(client redis.Cmdable) *RedisBackend {
+	return &RedisBackend{client: client}
+}
+
+// Take implements Backend
+func (b *RedisBackend) Take(ctx context.Context, key string, rule Rule) (time.Duration, error) {
+	wait, err := takeScript.Run(ctx, b.client, []string{key},
+		rule.rate(), rule.burst(), time.Now().UnixMilli()).Int64()
+	if err != nil {
+		return 0, err
+	}
+	return time.Duration(wait) * time.Millisecond, nil
+}
+
+// hitScript drops requests older than the window from the key's sorted set,
+// records the request when under the limit, and returns whether it was
+// allowed, the remaining capacity, and the milliseconds until the oldest
+// request leaves the window
+var hitScript = redis.NewScript(`
+local now = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+local limit = tonumber(ARGV[3])
+
+redis.call('ZREMRANGEBYSCORE', KEYS[1], '-inf', now - window)
+local count = redis.call('ZCARD', KEYS[1])
+
+local allowed = 0
+if count < limit then
+	redis.call('ZADD', KEYS[1], now, ARGV[4])
+	count = count + 1
+	allowed = 1
+end
+redis.call('PEXPIRE', KEYS[1], window)
+
+local reset = 0
+local oldest = redis.call('ZRANGE', KEYS[1], 0, 0, 'WITHSCORES')
+if oldest[2] then
+	reset = tonumber(oldest[2]) + window - now
+end
+return {allowed, limit - count, reset}
+`)
+
+// Hit implements WindowBackend with a sorted set of request timestamps
+func (b *RedisBackend) Hit(ctx context.Context, key string, limit int, window time.Duration) (WindowResult, error) {
+	now := time.Now()
+	member := strconv.FormatInt(now.UnixNano(), 36) + "-" + strconv.FormatInt(rand.Int63(), 36)
+
+	values, err := hitScript.Run(ctx, b.client, []string{key},
+		now.UnixMilli(), window.Milliseconds(), limit, member).Int64Slice()
+	if err != nil {
+		return WindowResult{}, err
+	}
+	return WindowResult{
+		Allowed:   values[0] == 1,
+		Limit:     limit,
+		Remaining: int(values[1]),
+		Reset:     time.Duration(values[2]) * time.Millisecond,
+	}, nil
+}
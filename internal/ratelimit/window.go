@@ -0,0 +1,50 @@
+package ratelimit
+
+import (
+	"context"
+	"time"
+)
+
+// WindowResult is the outcome of counting a request against a sliding window
+type WindowResult struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	// Reset is how long until the oldest request leaves the window and
+	// frees capacity
+	Reset time.Duration
+}
+
+// WindowBackend counts requests in a sliding window. Hit records a request
+// at key when fewer than limit requests were made in the last window.
+type WindowBackend interface {
+	Hit(ctx context.Context, key string, limit int, window time.Duration) (WindowResult, error)
+}
+
+// Hit implements WindowBackend with an in-process request log
+func (b *MemoryBackend) Hit(ctx context.Context, key string, limit int, window time.Duration) (WindowResult, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := time.Now()
+	log := b.windows[key]
+	kept := log[:0]
+	for _, ts := range log {
+		if now.Sub(ts) < window {
+			kept = append(kept, ts)
+		}
+	}
+
+	result := WindowResult{Limit: limit}
+	if len(kept) < limit {
+		kept = append(kept, now)
+		result.Allowed = true
+	}
+	b.windows[key] = kept
+
+	result.Remaining = limit - len(kept)
+	if len(kept) > 0 {
+		result.Reset = window - now.Sub(kept[0])
+	}
+	return result, nil
+}
@@ -0,0 +1,249 @@
+// Package schedules runs workflows on cron schedules and manages those
+// schedules.
+package schedules
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/nuumz/f1ow/internal/models"
+	"github.com/nuumz/f1ow/internal/tenant"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+var (
+	// ErrNotFound is returned when a schedule does not exist
+	ErrNotFound = errors.New("schedule not found")
+	// ErrInvalid is returned for schedules with a bad cron expression or
+	// timezone
+	ErrInvalid = errors.New("invalid schedule")
+)
+
+// Store persists schedules; it is implemented by storage.DB
+type Store interface {
+	CreateSchedule(ctx context.Context, schedule *models.Schedule) error
+	GetSchedule(ctx context.Context, id uuid.UUID) (*models.Schedule, error)
+	// ListSchedules returns the schedules of the context's tenant, only
+	// those of workflowID unless it is uuid.Nil
+	ListSchedules(ctx context.Context, workflowID uuid.UUID) ([]models.Schedule, error)
+	UpdateSchedule(ctx context.Context, schedule *models.Schedule) error
+	DeleteSchedule(ctx context.Context, id uuid.UUID) error
+
+	// ListDueSchedules returns active schedules of every tenant whose next
+	// run is at or before now, earliest first
+	ListDueSchedules(ctx context.Context, now time.Time, limit int) ([]models.Schedule, error)
+	// AdvanceSchedule records a run of a due schedule and its next run,
+	// unless its next run is no longer due, the one it was listed with.
+	// It reports whether it did, so only one instance runs each due time.
+	AdvanceSchedule(ctx context.Context, schedule *models.Schedule, due time.Time) (bool, error)
+}
+
+// RunFunc starts an execution of a workflow with input
+type RunFunc func(ctx context.Context, workflowID string, input map[string]interface{}) (*models.Execution, error)
+
+// Manager stores schedules and runs their workflows when they are due
+type Manager struct {
+	store  Store
+	run    RunFunc
+	logger *logrus.Logger
+}
+
+// NewManager creates a schedules manager that starts executions with run
+func NewManager(store Store, run RunFunc, logger *logrus.Logger) *Manager {
+	return &Manager{store: store, run: run, logger: logger}
+}
+
+// Create stores a new schedule in the context's tenant
+func (m *Manager) Create(ctx context.Context, schedule *models.Schedule) (*models.Schedule, error) {
+	if err := prepare(schedule, time.Now()); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	schedule.ID = uuid.New()
+	schedule.CreatedAt = now
+	schedule.UpdatedAt = now
+	if err := m.store.CreateSchedule(ctx, schedule); err != nil {
+		return nil, fmt.Errorf("failed to save schedule: %w", err)
+	}
+	return schedule, nil
+}
+
+// Get returns a schedule
+func (m *Manager) Get(ctx context.Context, id uuid.UUID) (*models.Schedule, error) {
+	return m.store.GetSchedule(ctx, id)
+}
+
+// List returns the context's tenant's schedules, only those of workflowID
+// unless it is uuid.Nil
+func (m *Manager) List(ctx context.Context, workflowID uuid.UUID) ([]models.Schedule, error) {
+	return m.store.ListSchedules(ctx, workflowID)
+}
+
+// Update replaces a schedule's name, cron expression, timezone, input, and
+// active flag, and computes its next run again
+func (m *Manager) Update(ctx context.Context, schedule *models.Schedule) (*models.Schedule, error) {
+	existing, err := m.store.GetSchedule(ctx, schedule.ID)
+	if err != nil {
+		return nil, err
+	}
+	schedule.WorkflowID = existing.WorkflowID
+	schedule.LastRunAt = existing.LastRunAt
+	schedule.CreatedAt = existing.CreatedAt
+	if err := prepare(schedule, time.Now()); err != nil {
+		return nil, err
+	}
+
+	schedule.UpdatedAt = time.Now()
+	if err := m.store.UpdateSchedule(ctx, schedule); err != nil {
+		return nil, err
+	}
+	return schedule, nil
+}
+
+// SetActive pauses or resumes a schedule. A resumed schedule next runs at
+// its next time from now; the runs missed while paused are skipped.
+func (m *Manager) SetActive(ctx context.Context, id uuid.UUID, active bool) (*models.Schedule, error) {
+	schedule, err := m.store.GetSchedule(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	schedule.IsActive = active
+	return m.Update(ctx, schedule)
+}
+
+// Delete removes a schedule
+func (m *Manager) Delete(ctx context.Context, id uuid.UUID) error {
+	return m.store.DeleteSchedule(ctx, id)
+}
+
+// RunNow starts an execution of the schedule's workflow with its input,
+// whether or not it is paused, and leaves its next run as it is
+func (m *Manager) RunNow(ctx context.Context, id uuid.UUID) (*models.Execution, error) {
+	schedule, err := m.store.GetSchedule(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return m.run(ctx, schedule.WorkflowID.String(), schedule.Input)
+}
+
+// Preview returns the next count times, after from, that a cron expression
+// fires in timezone, UTC when empty
+func Preview(expr, timezone string, from time.Time, count int) ([]time.Time, error) {
+	cron, loc, err := parse(expr, timezone)
+	if err != nil {
+		return nil, err
+	}
+
+	times := []time.Time{}
+	next := from.In(loc)
+	for len(times) < count {
+		if next = cron.Next(next); next.IsZero() {
+			break
+		}
+		times = append(times, next)
+	}
+	return times, nil
+}
+
+// Run starts the executions of due schedules every interval until ctx is
+// done. Any number of instances can run it; each due time runs once.
+func (m *Manager) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if _, err := m.RunDue(ctx); err != nil {
+			m.logger.Errorf("Failed to run due schedules: %v", err)
+		}
+	}
+}
+
+// RunDue starts an execution of every schedule that is due and returns how
+// many it started. A schedule that fell behind runs once, not once for
+// every time it missed.
+func (m *Manager) RunDue(ctx context.Context) (int, error) {
+	now := time.Now()
+	due, err := m.store.ListDueSchedules(ctx, now, 100)
+	if err != nil {
+		return 0, err
+	}
+
+	started := 0
+	for i := range due {
+		schedule := &due[i]
+		scheduleCtx := tenant.WithID(ctx, schedule.TenantID)
+		dueAt := *schedule.NextRunAt
+
+		if err := prepare(schedule, now); err != nil {
+			// Stored schedules are validated, so this only happens when
+			// the timezone database changed
+			m.logger.Errorf("Pausing schedule %s: %v", schedule.ID, err)
+			schedule.IsActive, schedule.NextRunAt = false, nil
+		}
+		schedule.LastRunAt = &now
+		advanced, err := m.store.AdvanceSchedule(scheduleCtx, schedule, dueAt)
+		if err != nil {
+			return started, err
+		}
+		if !advanced || !schedule.IsActive {
+			continue
+		}
+
+		if _, err := m.run(scheduleCtx, schedule.WorkflowID.String(), schedule.Input); err != nil {
+			m.logger.Errorf("Failed to run workflow %s of schedule %s: %v", schedule.WorkflowID, schedule.ID, err)
+			continue
+		}
+		started++
+	}
+	return started, nil
+}
+
+// prepare validates a schedule and sets its next run after now, or clears
+// it while the schedule is paused
+func prepare(schedule *models.Schedule, now time.Time) error {
+	if schedule.Timezone == "" {
+		schedule.Timezone = "UTC"
+	}
+	cron, loc, err := parse(schedule.CronExpr, schedule.Timezone)
+	if err != nil {
+		return err
+	}
+
+	schedule.NextRunAt = nil
+	if schedule.IsActive {
+		next := cron.Next(now.In(loc))
+		if next.IsZero() {
+			return fmt.Errorf("%w: %s never fires", ErrInvalid, schedule.CronExpr)
+		}
+		next = next.UTC()
+		schedule.NextRunAt = &next
+	}
+	return nil
+}
+
+// parse parses a cron expression and loads the timezone it fires in
+func parse(expr, timezone string) (*Cron, *time.Location, error) {
+	cron, err := ParseCron(expr)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %v", ErrInvalid, err)
+	}
+	if timezone == "" {
+		timezone = "UTC"
+	}
+	loc, err := time.LoadLocation(timezone)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: unknown timezone %q", ErrInvalid, timezone)
+	}
+	return cron, loc, nil
+}
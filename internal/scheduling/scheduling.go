@@ -0,0 +1,104 @@
+// Package scheduling runs the time-driven work of a fleet: the triggers of
+// active workflows, execution retention and binary data cleanup, and the
+// reaper that resumes executions of workers that stopped sending
+// heartbeats.
+package scheduling
+
+import (
+	"context"
+	"time"
+
+	"github.com/nuumz/f1ow/internal/binarydata"
+	"github.com/nuumz/f1ow/internal/config"
+	"github.com/nuumz/f1ow/internal/engine"
+	"github.com/nuumz/f1ow/internal/nodes"
+	"github.com/nuumz/f1ow/internal/storage"
+	"github.com/nuumz/f1ow/internal/triggers"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Start starts the scheduling work until ctx is done. Triggers are leased
+// in Redis and the reaper requeues each stale execution once, so any number
+// of processes can run it.
+func Start(ctx context.Context, eng *engine.Engine, db *storage.DB, redis *storage.RedisClient, binaryData *binarydata.Manager, cfg *config.Config) {
+	startTriggers(ctx, eng, db, redis, cfg.Nodes.FileStoragePath)
+	startRetention(ctx, db, binaryData, cfg.Execution.Retention, cfg.BinaryData.CleanupInterval)
+	eng.StartReaper(ctx, cfg.Execution.ReaperInterval)
+}
+
+// RunInProcess starts the scheduling work and a worker in this process, for
+// servers without Redis, and returns a channel closed when the worker
+// stops. Without Redis there is no worker registry, so the reaper has
+// nothing to do.
+func RunInProcess(ctx context.Context, eng *engine.Engine, db *storage.DB, binaryData *binarydata.Manager, cfg *config.Config) <-chan struct{} {
+	Start(ctx, eng, db, nil, binaryData, cfg)
+
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		if err := eng.StartWorker(ctx); err != nil && err != context.Canceled {
+			logrus.Errorf("Worker error: %v", err)
+		}
+	}()
+	return stopped
+}
+
+// startTriggers registers the trigger factories and runs the triggers of
+// active workflows. Without Redis, seen messages and items are tracked in
+// the database, and poll and file watch triggers, which keep their state in
+// Redis, do not run.
+func startTriggers(ctx context.Context, eng *engine.Engine, db *storage.DB, redis *storage.RedisClient, fileStoragePath string) {
+	logger := logrus.StandardLogger()
+
+	// A nil *RedisClient must not become a non-nil store interface
+	var seen nodes.SeenStore = db
+	var state nodes.CacheStore
+	if redis != nil {
+		seen, state = redis, redis
+	}
+
+	eng.RegisterTrigger("email_trigger", triggers.NewEmailTriggerFactory(seen, logger))
+	eng.RegisterTrigger("kafka_trigger", triggers.NewKafkaTriggerFactory(logger))
+	eng.RegisterTrigger("amqp_trigger", triggers.NewAMQPTriggerFactory(func(ctx context.Context, workflowID string, payload map[string]interface{}) error {
+		_, err := eng.Execute(ctx, workflowID, payload)
+		return err
+	}, logger))
+	eng.RegisterTrigger("mqtt_trigger", triggers.NewMQTTTriggerFactory(logger))
+	eng.RegisterTrigger("poll_trigger", triggers.NewPollTriggerFactory(state, seen, logger))
+	eng.RegisterTrigger("file_watch_trigger", triggers.NewFileWatchTriggerFactory(fileStoragePath, state, logger))
+
+	eng.StartTriggers(ctx, 30*time.Second)
+
+	logger.Info("Trigger manager started")
+}
+
+// startRetention deletes executions older than period, unless it is zero,
+// and cleans up binary data every interval
+func startRetention(ctx context.Context, db *storage.DB, binaryData *binarydata.Manager, period, interval time.Duration) {
+	// Executions are kept forever unless a retention period is configured
+	if period > 0 {
+		go func() {
+			ticker := time.NewTicker(interval)
+			defer ticker.Stop()
+
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case <-ticker.C:
+					deleted, err := db.DeleteExecutionsBefore(ctx, time.Now().Add(-period))
+					if err != nil {
+						logrus.Errorf("Execution retention failed: %v", err)
+					} else if deleted > 0 {
+						logrus.Infof("Deleted %d executions older than %s", deleted, period)
+					}
+				}
+			}
+		}()
+	}
+
+	go binaryData.Run(ctx, interval)
+
+	logrus.Info("Retention cleanup started")
+}
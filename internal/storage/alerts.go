@@ -0,0 +1,365 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/nuumz/f1ow/internal/alerting"
+	"github.com/nuumz/f1ow/internal/models"
+	"github.com/nuumz/f1ow/internal/tenant"
+
+	"github.com/google/uuid"
+)
+
+const alertRuleColumns = `id, tenant_id, name, workflow_id, condition_type, threshold, window_seconds,
+               cooldown_seconds, channel_ids, enabled, last_fired_at, created_at, updated_at`
+
+// CreateAlertRule stores a new alert rule in the context's tenant
+func (db *DB) CreateAlertRule(ctx context.Context, rule *models.AlertRule) error {
+	channelsJSON, err := json.Marshal(rule.ChannelIDs)
+	if err != nil {
+		return fmt.Errorf("failed to marshal channel IDs: %w", err)
+	}
+
+	rule.TenantID = tenant.IDOrDefault(ctx)
+	query := fmt.Sprintf(`
+        INSERT INTO alert_rules (id, tenant_id, name, workflow_id, condition_type, threshold, window_seconds,
+                                 cooldown_seconds, channel_ids, enabled, created_at, updated_at)
+        VALUES (%s, %s, %s, %s, %s, %s, %s, %s, %s, %s, %s, %s)
+    `, db.placeholder(1), db.placeholder(2), db.placeholder(3), db.placeholder(4), db.placeholder(5), db.placeholder(6),
+		db.placeholder(7), db.placeholder(8), db.placeholder(9), db.placeholder(10), db.placeholder(11), db.placeholder(12))
+
+	_, err = db.ExecContext(ctx, query, rule.ID, rule.TenantID, rule.Name, nullableUUID(rule.WorkflowID), rule.Condition,
+		rule.Threshold, rule.Window, rule.Cooldown, channelsJSON, rule.Enabled, rule.CreatedAt, rule.UpdatedAt)
+	return err
+}
+
+// GetAlertRule retrieves an alert rule by ID
+func (db *DB) GetAlertRule(ctx context.Context, id uuid.UUID) (*models.AlertRule, error) {
+	query := fmt.Sprintf(`SELECT %s FROM alert_rules WHERE id = %s`, alertRuleColumns, db.placeholder(1))
+	query, args := db.scopeToTenant(ctx, query, []interface{}{id}, "tenant_id")
+
+	rule, err := scanAlertRule(db.QueryRowxContext(ctx, query, args...))
+	if err == sql.ErrNoRows {
+		return nil, alerting.ErrRuleNotFound
+	}
+	return rule, err
+}
+
+// ListAlertRules returns the alert rules of the context's tenant ordered by
+// name, or of every tenant when the context has none
+func (db *DB) ListAlertRules(ctx context.Context) ([]models.AlertRule, error) {
+	query, args := db.scopeToTenant(ctx, fmt.Sprintf(`SELECT %s FROM alert_rules WHERE 1=1`, alertRuleColumns), nil, "tenant_id")
+
+	rows, err := db.QueryxContext(ctx, query+" ORDER BY name", args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list alert rules: %w", err)
+	}
+	defer rows.Close()
+
+	rules := []models.AlertRule{}
+	for rows.Next() {
+		rule, err := scanAlertRule(rows)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, *rule)
+	}
+	return rules, rows.Err()
+}
+
+// UpdateAlertRule replaces an alert rule's settings
+func (db *DB) UpdateAlertRule(ctx context.Context, rule *models.AlertRule) error {
+	channelsJSON, err := json.Marshal(rule.ChannelIDs)
+	if err != nil {
+		return fmt.Errorf("failed to marshal channel IDs: %w", err)
+	}
+
+	query := fmt.Sprintf(`
+        UPDATE alert_rules
+        SET name = %s, workflow_id = %s, condition_type = %s, threshold = %s, window_seconds = %s,
+            cooldown_seconds = %s, channel_ids = %s, enabled = %s, updated_at = %s
+        WHERE id = %s`,
+		db.placeholder(1), db.placeholder(2), db.placeholder(3), db.placeholder(4), db.placeholder(5),
+		db.placeholder(6), db.placeholder(7), db.placeholder(8), db.placeholder(9), db.placeholder(10))
+	query, args := db.scopeToTenant(ctx, query, []interface{}{rule.Name, nullableUUID(rule.WorkflowID), rule.Condition,
+		rule.Threshold, rule.Window, rule.Cooldown, channelsJSON, rule.Enabled, rule.UpdatedAt, rule.ID}, "tenant_id")
+
+	result, err := db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to update alert rule: %w", err)
+	}
+	if affected, err := result.RowsAffected(); err == nil && affected == 0 {
+		return alerting.ErrRuleNotFound
+	}
+	return nil
+}
+
+// DeleteAlertRule removes an alert rule and its alert history
+func (db *DB) DeleteAlertRule(ctx context.Context, id uuid.UUID) error {
+	query, args := db.scopeToTenant(ctx, fmt.Sprintf(`DELETE FROM alert_rules WHERE id = %s`, db.placeholder(1)),
+		[]interface{}{id}, "tenant_id")
+
+	result, err := db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	if affected, err := result.RowsAffected(); err == nil && affected == 0 {
+		return alerting.ErrRuleNotFound
+	}
+	return nil
+}
+
+// MarkAlertRuleFired records when an alert rule last fired
+func (db *DB) MarkAlertRuleFired(ctx context.Context, id uuid.UUID, firedAt time.Time) error {
+	query := fmt.Sprintf(`UPDATE alert_rules SET last_fired_at = %s WHERE id = %s`, db.placeholder(1), db.placeholder(2))
+	_, err := db.ExecContext(ctx, query, firedAt, id)
+	return err
+}
+
+// CreateAlertChannel stores a new alert channel in the context's tenant
+func (db *DB) CreateAlertChannel(ctx context.Context, channel *models.AlertChannel) error {
+	configJSON, err := json.Marshal(channel.Config)
+	if err != nil {
+		return fmt.Errorf("failed to marshal channel config: %w", err)
+	}
+
+	channel.TenantID = tenant.IDOrDefault(ctx)
+	query := fmt.Sprintf(`
+        INSERT INTO alert_channels (id, tenant_id, name, type, config, created_at, updated_at)
+        VALUES (%s, %s, %s, %s, %s, %s, %s)
+    `, db.placeholder(1), db.placeholder(2), db.placeholder(3), db.placeholder(4), db.placeholder(5),
+		db.placeholder(6), db.placeholder(7))
+
+	_, err = db.ExecContext(ctx, query, channel.ID, channel.TenantID, channel.Name, channel.Type, configJSON,
+		channel.CreatedAt, channel.UpdatedAt)
+	return err
+}
+
+// GetAlertChannel retrieves an alert channel by ID
+func (db *DB) GetAlertChannel(ctx context.Context, id uuid.UUID) (*models.AlertChannel, error) {
+	query := fmt.Sprintf(`
+        SELECT id, tenant_id, name, type, config, created_at, updated_at
+        FROM alert_channels
+        WHERE id = %s`, db.placeholder(1))
+	query, args := db.scopeToTenant(ctx, query, []interface{}{id}, "tenant_id")
+
+	channel, err := scanAlertChannel(db.QueryRowxContext(ctx, query, args...))
+	if err == sql.ErrNoRows {
+		return nil, alerting.ErrChannelNotFound
+	}
+	return channel, err
+}
+
+// ListAlertChannels returns the alert channels of the context's tenant
+// ordered by name
+func (db *DB) ListAlertChannels(ctx context.Context) ([]models.AlertChannel, error) {
+	query, args := db.scopeToTenant(ctx, `
+        SELECT id, tenant_id, name, type, config, created_at, updated_at
+        FROM alert_channels
+        WHERE 1=1`, nil, "tenant_id")
+
+	rows, err := db.QueryxContext(ctx, query+" ORDER BY name", args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list alert channels: %w", err)
+	}
+	defer rows.Close()
+
+	channels := []models.AlertChannel{}
+	for rows.Next() {
+		channel, err := scanAlertChannel(rows)
+		if err != nil {
+			return nil, err
+		}
+		channels = append(channels, *channel)
+	}
+	return channels, rows.Err()
+}
+
+// UpdateAlertChannel replaces an alert channel's name, type, and config
+func (db *DB) UpdateAlertChannel(ctx context.Context, channel *models.AlertChannel) error {
+	configJSON, err := json.Marshal(channel.Config)
+	if err != nil {
+		return fmt.Errorf("failed to marshal channel config: %w", err)
+	}
+
+	query := fmt.Sprintf(`
+        UPDATE alert_channels
+        SET name = %s, type = %s, config = %s, updated_at = %s
+        WHERE id = %s`,
+		db.placeholder(1), db.placeholder(2), db.placeholder(3), db.placeholder(4), db.placeholder(5))
+	query, args := db.scopeToTenant(ctx, query, []interface{}{channel.Name, channel.Type, configJSON,
+		channel.UpdatedAt, channel.ID}, "tenant_id")
+
+	result, err := db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to update alert channel: %w", err)
+	}
+	if affected, err := result.RowsAffected(); err == nil && affected == 0 {
+		return alerting.ErrChannelNotFound
+	}
+	return nil
+}
+
+// DeleteAlertChannel removes an alert channel
+func (db *DB) DeleteAlertChannel(ctx context.Context, id uuid.UUID) error {
+	query, args := db.scopeToTenant(ctx, fmt.Sprintf(`DELETE FROM alert_channels WHERE id = %s`, db.placeholder(1)),
+		[]interface{}{id}, "tenant_id")
+
+	result, err := db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	if affected, err := result.RowsAffected(); err == nil && affected == 0 {
+		return alerting.ErrChannelNotFound
+	}
+	return nil
+}
+
+// CreateAlert records a fired alert
+func (db *DB) CreateAlert(ctx context.Context, alert *models.Alert) error {
+	deliveriesJSON, err := json.Marshal(alert.Deliveries)
+	if err != nil {
+		return fmt.Errorf("failed to marshal deliveries: %w", err)
+	}
+
+	query := fmt.Sprintf(`
+        INSERT INTO alerts (id, tenant_id, rule_id, rule_name, workflow_id, execution_id, message, value,
+                            deliveries, fired_at)
+        VALUES (%s, %s, %s, %s, %s, %s, %s, %s, %s, %s)
+    `, db.placeholder(1), db.placeholder(2), db.placeholder(3), db.placeholder(4), db.placeholder(5),
+		db.placeholder(6), db.placeholder(7), db.placeholder(8), db.placeholder(9), db.placeholder(10))
+
+	_, err = db.ExecContext(ctx, query, alert.ID, tenant.IDOrDefault(ctx), alert.RuleID, alert.RuleName,
+		nullableUUID(alert.WorkflowID), nullableUUID(alert.ExecutionID), alert.Message, alert.Value,
+		deliveriesJSON, alert.FiredAt)
+	return err
+}
+
+// ListAlerts returns the context's tenant's most recent alerts, of one rule
+// when ruleID is set
+func (db *DB) ListAlerts(ctx context.Context, ruleID *uuid.UUID, limit int) ([]models.Alert, error) {
+	query := `
+        SELECT id, tenant_id, rule_id, rule_name, workflow_id, execution_id, message, value, deliveries, fired_at
+        FROM alerts
+        WHERE 1=1`
+	var args []interface{}
+	if ruleID != nil {
+		args = append(args, *ruleID)
+		query += fmt.Sprintf(" AND rule_id = %s", db.placeholder(len(args)))
+	}
+	query, args = db.scopeToTenant(ctx, query, args, "tenant_id")
+	args = append(args, limit)
+	query += fmt.Sprintf(" ORDER BY fired_at DESC LIMIT %s", db.placeholder(len(args)))
+
+	rows, err := db.QueryxContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list alerts: %w", err)
+	}
+	defer rows.Close()
+
+	alerts := []models.Alert{}
+	for rows.Next() {
+		var alert models.Alert
+		var workflowID, executionID uuid.NullUUID
+		var deliveriesJSON []byte
+		if err := rows.Scan(&alert.ID, &alert.TenantID, &alert.RuleID, &alert.RuleName, &workflowID, &executionID,
+			&alert.Message, &alert.Value, &deliveriesJSON, &alert.FiredAt); err != nil {
+			return nil, err
+		}
+		alert.WorkflowID = uuidPointer(workflowID)
+		alert.ExecutionID = uuidPointer(executionID)
+		if err := json.Unmarshal(deliveriesJSON, &alert.Deliveries); err != nil {
+			return nil, fmt.Errorf("failed to parse alert deliveries: %w", err)
+		}
+		alerts = append(alerts, alert)
+	}
+	return alerts, rows.Err()
+}
+
+// ListRecentExecutions returns the executions of the workflow, or of all
+// the context's tenant's workflows when workflowID is nil, that are still
+// running or finished at or after since
+func (db *DB) ListRecentExecutions(ctx context.Context, workflowID *uuid.UUID, since time.Time) ([]models.ExecutionSummary, error) {
+	query := fmt.Sprintf(`
+        SELECT id, workflow_id, status, started_at, completed_at
+        FROM executions
+        WHERE (status IN (%s, %s) OR completed_at >= %s)`,
+		db.placeholder(1), db.placeholder(2), db.placeholder(3))
+	args := []interface{}{models.ExecutionStatusPending, models.ExecutionStatusRunning, since}
+	if workflowID != nil {
+		args = append(args, *workflowID)
+		query += fmt.Sprintf(" AND workflow_id = %s", db.placeholder(len(args)))
+	}
+	query, args = db.scopeToTenant(ctx, query, args, "tenant_id")
+
+	rows, err := db.QueryxContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list recent executions: %w", err)
+	}
+	defer rows.Close()
+
+	var executions []models.ExecutionSummary
+	for rows.Next() {
+		var execution models.ExecutionSummary
+		var completedAt sql.NullTime
+		if err := rows.Scan(&execution.ID, &execution.WorkflowID, &execution.Status, &execution.StartedAt, &completedAt); err != nil {
+			return nil, err
+		}
+		if completedAt.Valid {
+			execution.CompletedAt = &completedAt.Time
+		}
+		executions = append(executions, execution)
+	}
+	return executions, rows.Err()
+}
+
+func scanAlertRule(row rowScanner) (*models.AlertRule, error) {
+	var rule models.AlertRule
+	var workflowID uuid.NullUUID
+	var lastFiredAt sql.NullTime
+	var channelsJSON []byte
+	if err := row.Scan(&rule.ID, &rule.TenantID, &rule.Name, &workflowID, &rule.Condition, &rule.Threshold,
+		&rule.Window, &rule.Cooldown, &channelsJSON, &rule.Enabled, &lastFiredAt, &rule.CreatedAt, &rule.UpdatedAt); err != nil {
+		return nil, err
+	}
+	rule.WorkflowID = uuidPointer(workflowID)
+	if lastFiredAt.Valid {
+		rule.LastFiredAt = &lastFiredAt.Time
+	}
+	if err := json.Unmarshal(channelsJSON, &rule.ChannelIDs); err != nil {
+		return nil, fmt.Errorf("failed to parse alert rule channels: %w", err)
+	}
+	return &rule, nil
+}
+
+func scanAlertChannel(row rowScanner) (*models.AlertChannel, error) {
+	var channel models.AlertChannel
+	var configJSON []byte
+	if err := row.Scan(&channel.ID, &channel.TenantID, &channel.Name, &channel.Type, &configJSON,
+		&channel.CreatedAt, &channel.UpdatedAt); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(configJSON, &channel.Config); err != nil {
+		return nil, fmt.Errorf("failed to parse alert channel config: %w", err)
+	}
+	return &channel, nil
+}
+
+// nullableUUID returns id for a nullable UUID column
+func nullableUUID(id *uuid.UUID) interface{} {
+	if id == nil {
+		return nil
+	}
+	return *id
+}
+
+func uuidPointer(id uuid.NullUUID) *uuid.UUID {
+	if !id.Valid {
+		return nil
+	}
+	return &id.UUID
+}
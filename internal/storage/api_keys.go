@@ -0,0 +1,117 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/nuumz/f1ow/internal/models"
+	"github.com/nuumz/f1ow/internal/tenant"
+
+	"github.com/google/uuid"
+)
+
+// ErrAPIKeyNotFound is returned when an API key does not exist
+var ErrAPIKeyNotFound = errors.New("API key not found")
+
+const apiKeyColumns = `id, tenant_id, name, prefix, key_hash, user_id, COALESCE(service_account, ''),
+               scopes, COALESCE(rate_limit, ''), created_at, expires_at, last_used_at, revoked_at`
+
+// CreateAPIKey stores a new API key in the context's tenant
+func (db *DB) CreateAPIKey(ctx context.Context, key *models.APIKey) error {
+	if key.ID == uuid.Nil {
+		key.ID = uuid.New()
+	}
+	key.TenantID = tenant.IDOrDefault(ctx)
+	key.CreatedAt = time.Now()
+
+	query := fmt.Sprintf(`
+        INSERT INTO api_keys (id, tenant_id, name, prefix, key_hash, user_id, service_account,
+                              scopes, rate_limit, created_at, expires_at)
+        VALUES (%s, %s, %s, %s, %s, %s, %s, %s, %s, %s, %s)
+    `, db.placeholder(1), db.placeholder(2), db.placeholder(3), db.placeholder(4),
+		db.placeholder(5), db.placeholder(6), db.placeholder(7), db.placeholder(8),
+		db.placeholder(9), db.placeholder(10), db.placeholder(11))
+
+	_, err := db.ExecContext(ctx, query, key.ID, key.TenantID, key.Name, key.Prefix, key.KeyHash,
+		key.UserID, key.ServiceAccount, strings.Join(key.Scopes, ","), key.RateLimit,
+		key.CreatedAt, key.ExpiresAt)
+	return err
+}
+
+// GetAPIKeyByHash finds a key by the hash of its secret. It is used to
+// authenticate requests, so it is not scoped to a tenant.
+func (db *DB) GetAPIKeyByHash(ctx context.Context, hash string) (*models.APIKey, error) {
+	query := fmt.Sprintf(`SELECT %s FROM api_keys WHERE key_hash = %s`, apiKeyColumns, db.placeholder(1))
+
+	key, err := db.scanAPIKey(db.QueryRowxContext(ctx, query, hash))
+	if err == sql.ErrNoRows {
+		return nil, ErrAPIKeyNotFound
+	}
+	return key, err
+}
+
+// ListAPIKeys returns the keys of the context's tenant, newest first
+func (db *DB) ListAPIKeys(ctx context.Context) ([]models.APIKey, error) {
+	query, args := db.scopeToTenant(ctx, fmt.Sprintf(`SELECT %s FROM api_keys WHERE 1=1`, apiKeyColumns), nil, "tenant_id")
+
+	rows, err := db.QueryxContext(ctx, query+" ORDER BY created_at DESC", args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	keys := []models.APIKey{}
+	for rows.Next() {
+		key, err := db.scanAPIKey(rows)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, *key)
+	}
+	return keys, rows.Err()
+}
+
+// RevokeAPIKey marks a key revoked; revoked keys no longer authenticate
+func (db *DB) RevokeAPIKey(ctx context.Context, id uuid.UUID) error {
+	query := fmt.Sprintf(`UPDATE api_keys SET revoked_at = %s WHERE id = %s AND revoked_at IS NULL`,
+		db.placeholder(1), db.placeholder(2))
+	query, args := db.scopeToTenant(ctx, query, []interface{}{time.Now(), id}, "tenant_id")
+
+	result, err := db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	if affected, err := result.RowsAffected(); err == nil && affected == 0 {
+		return ErrAPIKeyNotFound
+	}
+	return nil
+}
+
+// TouchAPIKey records that a key was used
+func (db *DB) TouchAPIKey(ctx context.Context, id uuid.UUID, usedAt time.Time) error {
+	query := fmt.Sprintf(`UPDATE api_keys SET last_used_at = %s WHERE id = %s`, db.placeholder(1), db.placeholder(2))
+	_, err := db.ExecContext(ctx, query, usedAt, id)
+	return err
+}
+
+func (db *DB) scanAPIKey(row rowScanner) (*models.APIKey, error) {
+	var key models.APIKey
+	var scopes string
+
+	err := row.Scan(&key.ID, &key.TenantID, &key.Name, &key.Prefix, &key.KeyHash, &key.UserID,
+		&key.ServiceAccount, &scopes, &key.RateLimit, &key.CreatedAt, &key.ExpiresAt,
+		&key.LastUsedAt, &key.RevokedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	key.Scopes = []string{}
+	if scopes != "" {
+		key.Scopes = strings.Split(scopes, ",")
+	}
+	return &key, nil
+}
@@ -0,0 +1,117 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/nuumz/f1ow/internal/binarydata"
+	"github.com/nuumz/f1ow/internal/models"
+
+	"github.com/google/uuid"
+)
+
+// SaveBinaryData records binary data metadata
+func (db *DB) SaveBinaryData(ctx context.Context, data *models.BinaryData) error {
+	query := fmt.Sprintf(`
+        INSERT INTO binary_data (id, execution_id, storage, storage_key, file_name, mime_type, size, created_at)
+        VALUES (%s, %s, %s, %s, %s, %s, %s, %s)
+    `, db.placeholder(1), db.placeholder(2), db.placeholder(3), db.placeholder(4),
+		db.placeholder(5), db.placeholder(6), db.placeholder(7), db.placeholder(8))
+
+	_, err := db.ExecContext(ctx, query, data.ID.String(), data.ExecutionID.String(), data.Storage,
+		data.StorageKey, data.FileName, data.MimeType, data.Size, data.CreatedAt)
+	return err
+}
+
+// GetBinaryData retrieves binary data metadata by ID. Binary data belongs to
+// the tenant of its execution.
+func (db *DB) GetBinaryData(ctx context.Context, id uuid.UUID) (*models.BinaryData, error) {
+	query := fmt.Sprintf(`
+        SELECT b.id, b.execution_id, b.storage, b.storage_key, b.file_name, b.mime_type, b.size, b.created_at
+        FROM binary_data b
+        JOIN executions e ON e.id = b.execution_id
+        WHERE b.id = %s`, db.placeholder(1))
+	query, args := db.scopeToTenant(ctx, query, []interface{}{id.String()}, "e.tenant_id")
+
+	data, err := db.scanBinaryData(db.QueryRowxContext(ctx, query, args...))
+	if err == sql.ErrNoRows {
+		return nil, binarydata.ErrNotFound
+	}
+	return data, err
+}
+
+// ListOrphanedBinaryData returns binary data whose execution no longer exists
+func (db *DB) ListOrphanedBinaryData(ctx context.Context, limit int) ([]models.BinaryData, error) {
+	query := fmt.Sprintf(`
+        SELECT b.id, b.execution_id, b.storage, b.storage_key, b.file_name, b.mime_type, b.size, b.created_at
+        FROM binary_data b
+        LEFT JOIN executions e ON e.id = b.execution_id
+        WHERE e.id IS NULL
+        ORDER BY b.created_at
+        LIMIT %s
+    `, db.placeholder(1))
+
+	rows, err := db.QueryxContext(ctx, query, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []models.BinaryData
+	for rows.Next() {
+		data, err := db.scanBinaryData(rows)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, *data)
+	}
+
+	return result, rows.Err()
+}
+
+// DeleteBinaryData removes binary data metadata
+func (db *DB) DeleteBinaryData(ctx context.Context, id uuid.UUID) error {
+	query := fmt.Sprintf(`DELETE FROM binary_data WHERE id = %s`, db.placeholder(1))
+	_, err := db.ExecContext(ctx, query, id.String())
+	return err
+}
+
+// DeleteExecutionsBefore removes finished executions that completed before
+// cutoff and returns the number removed. Their binary data becomes orphaned
+// and is removed by the binary data cleanup.
+func (db *DB) DeleteExecutionsBefore(ctx context.Context, cutoff time.Time) (int64, error) {
+	query := fmt.Sprintf(`DELETE FROM executions WHERE completed_at IS NOT NULL AND completed_at < %s`,
+		db.placeholder(1))
+
+	result, err := db.ExecContext(ctx, query, cutoff)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func (db *DB) scanBinaryData(row rowScanner) (*models.BinaryData, error) {
+	var data models.BinaryData
+	var id, executionID string
+
+	if err := row.Scan(&id, &executionID, &data.Storage, &data.StorageKey,
+		&data.FileName, &data.MimeType, &data.Size, &data.CreatedAt); err != nil {
+		return nil, err
+	}
+
+	var err error
+	if data.ID, err = uuid.Parse(id); err != nil {
+		return nil, fmt.Errorf("invalid binary data ID: %w", err)
+	}
+	if data.ExecutionID, err = uuid.Parse(executionID); err != nil {
+		return nil, fmt.Errorf("invalid execution ID: %w", err)
+	}
+
+	return &data, nil
+}
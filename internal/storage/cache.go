@@ -0,0 +1,77 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// cacheKeyPrefix namespaces response and cache node entries
+const cacheKeyPrefix = "cache:"
+
+// GetCache returns the cached value for key and whether it was found
+func (r *RedisClient) GetCache(ctx context.Context, key string) ([]byte, bool, error) {
+	value, err := r.client.Get(ctx, cacheKeyPrefix+key).Bytes()
+	if err == redis.Nil {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to read cache: %w", err)
+	}
+	return value, true, nil
+}
+
+// SetCache stores value under key. A ttl of zero keeps the entry until it is
+// invalidated.
+func (r *RedisClient) SetCache(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	if err := r.client.Set(ctx, cacheKeyPrefix+key, value, ttl).Err(); err != nil {
+		return fmt.Errorf("failed to write cache: %w", err)
+	}
+	return nil
+}
+
+// DeleteCache removes the entry for key, or every entry starting with key
+// when prefix is set, and returns the number of entries removed
+func (r *RedisClient) DeleteCache(ctx context.Context, key string, prefix bool) (int64, error) {
+	if !prefix {
+		deleted, err := r.client.Del(ctx, cacheKeyPrefix+key).Result()
+		if err != nil {
+			return 0, fmt.Errorf("failed to invalidate cache: %w", err)
+		}
+		return deleted, nil
+	}
+
+	var deleted int64
+	iter := r.client.Scan(ctx, 0, cacheKeyPrefix+key+"*", 100).Iterator()
+	batch := make([]string, 0, 100)
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		n, err := r.client.Del(ctx, batch...).Result()
+		if err != nil {
+			return fmt.Errorf("failed to invalidate cache: %w", err)
+		}
+		deleted += n
+		batch = batch[:0]
+		return nil
+	}
+
+	for iter.Next(ctx) {
+		batch = append(batch, iter.Val())
+		if len(batch) == cap(batch) {
+			if err := flush(); err != nil {
+				return deleted, err
+			}
+		}
+	}
+	if err := iter.Err(); err != nil {
+		return deleted, fmt.Errorf("failed to scan cache: %w", err)
+	}
+	if err := flush(); err != nil {
+		return deleted, err
+	}
+	return deleted, nil
+}
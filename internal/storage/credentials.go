@@ -0,0 +1,112 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/nuumz/f1ow/internal/credentials"
+	"github.com/nuumz/f1ow/internal/models"
+	"github.com/nuumz/f1ow/internal/tenant"
+
+	"github.com/google/uuid"
+)
+
+// CreateCredential stores a new credential
+func (db *DB) CreateCredential(ctx context.Context, credential *models.Credential) error {
+	query := fmt.Sprintf(`
+        INSERT INTO credentials (id, name, type, user_id, data, created_at, updated_at, tenant_id)
+        VALUES (%s, %s, %s, %s, %s, %s, %s, %s)
+    `, db.placeholder(1), db.placeholder(2), db.placeholder(3), db.placeholder(4),
+		db.placeholder(5), db.placeholder(6), db.placeholder(7), db.placeholder(8))
+
+	_, err := db.ExecContext(ctx, query, credential.ID.String(), credential.Name, credential.Type,
+		credential.UserID.String(), credential.Data, credential.CreatedAt, credential.UpdatedAt,
+		tenant.IDOrDefault(ctx).String())
+	return err
+}
+
+// GetCredential retrieves a credential by ID, including its encrypted data
+func (db *DB) GetCredential(ctx context.Context, id uuid.UUID) (*models.Credential, error) {
+	query := fmt.Sprintf(`
+        SELECT id, name, type, user_id, data, created_at, updated_at
+        FROM credentials
+        WHERE id = %s`, db.placeholder(1))
+	query, args := db.scopeToTenant(ctx, query, []interface{}{id.String()}, "tenant_id")
+
+	credential, err := db.scanCredential(db.QueryRowxContext(ctx, query, args...))
+	if err == sql.ErrNoRows {
+		return nil, credentials.ErrNotFound
+	}
+	return credential, err
+}
+
+// ListCredentials returns all credentials ordered by name
+func (db *DB) ListCredentials(ctx context.Context) ([]models.Credential, error) {
+	query, args := db.scopeToTenant(ctx, `
+        SELECT id, name, type, user_id, data, created_at, updated_at
+        FROM credentials
+        WHERE 1=1`, nil, "tenant_id")
+
+	rows, err := db.QueryxContext(ctx, query+" ORDER BY name", args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []models.Credential
+	for rows.Next() {
+		credential, err := db.scanCredential(rows)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, *credential)
+	}
+
+	return result, rows.Err()
+}
+
+// UpdateCredentialData replaces the encrypted data of a credential
+func (db *DB) UpdateCredentialData(ctx context.Context, id uuid.UUID, data string) error {
+	query := fmt.Sprintf(`UPDATE credentials SET data = %s, updated_at = %s WHERE id = %s`,
+		db.placeholder(1), db.placeholder(2), db.placeholder(3))
+	query, args := db.scopeToTenant(ctx, query, []interface{}{data, time.Now(), id.String()}, "tenant_id")
+
+	result, err := db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	if affected, err := result.RowsAffected(); err == nil && affected == 0 {
+		return credentials.ErrNotFound
+	}
+	return nil
+}
+
+// DeleteCredential removes a credential
+func (db *DB) DeleteCredential(ctx context.Context, id uuid.UUID) error {
+	query, args := db.scopeToTenant(ctx, fmt.Sprintf(`DELETE FROM credentials WHERE id = %s`, db.placeholder(1)),
+		[]interface{}{id.String()}, "tenant_id")
+	_, err := db.ExecContext(ctx, query, args...)
+	return err
+}
+
+func (db *DB) scanCredential(row rowScanner) (*models.Credential, error) {
+	var credential models.Credential
+	var id, userID string
+
+	if err := row.Scan(&id, &credential.Name, &credential.Type, &userID, &credential.Data,
+		&credential.CreatedAt, &credential.UpdatedAt); err != nil {
+		return nil, err
+	}
+
+	var err error
+	if credential.ID, err = uuid.Parse(id); err != nil {
+		return nil, fmt.Errorf("invalid credential ID: %w", err)
+	}
+	if credential.UserID, err = uuid.Parse(userID); err != nil {
+		return nil, fmt.Errorf("invalid user ID: %w", err)
+	}
+
+	return &credential, nil
+}
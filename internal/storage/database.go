@@ -9,8 +9,8 @@ import (
 	"time"
 
 	"github.com/nuumz/f1ow/internal/models"
+	"github.com/nuumz/f1ow/internal/tenant"
 
-	_ "github.com/go-sql-driver/mysql"
 	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
 	_ "github.com/lib/pq"
@@ -19,16 +19,29 @@ import (
 type DB struct {
 	*sqlx.DB
 	driverName string
+	replicas   *replicaSet // nil without read replicas
 }
 
 func NewDB(dsn string) (*DB, error) {
+	db, driverName, err := connect(dsn)
+	if err != nil {
+		return nil, err
+	}
+	return &DB{DB: db, driverName: driverName}, nil
+}
+
+// connect opens a connection pool to dsn, detecting the driver from it
+func connect(dsn string) (*sqlx.DB, string, error) {
 	var driverName string
 
 	// Auto-detect database driver based on DSN
-	if strings.Contains(dsn, "postgres://") || strings.Contains(dsn, "postgresql://") {
+	if isSQLiteDSN(dsn) {
+		driverName = sqliteDriverName
+		dsn = sqliteDSN(dsn)
+	} else if strings.Contains(dsn, "postgres://") || strings.Contains(dsn, "postgresql://") {
 		driverName = "postgres"
 	} else if strings.Contains(dsn, "mysql://") || strings.Contains(dsn, "@tcp(") {
-		driverName = "mysql"
+		driverName = mysqlDriverName
 		// Convert mysql:// format to go-sql-driver format
 		if after, ok := strings.CutPrefix(dsn, "mysql://"); ok {
 			dsn = after
@@ -40,13 +53,19 @@ func NewDB(dsn string) (*DB, error) {
 
 	db, err := sqlx.Connect(driverName, dsn)
 	if err != nil {
-		return nil, fmt.Errorf("failed to connect to database: %w", err)
+		return nil, "", fmt.Errorf("failed to connect to database: %w", err)
 	}
 
-	db.SetMaxOpenConns(25)
-	db.SetMaxIdleConns(5)
+	if driverName == sqliteDriverName {
+		// SQLite allows one writer at a time; a single connection queues
+		// writers here instead of failing them with SQLITE_BUSY
+		db.SetMaxOpenConns(1)
+	} else {
+		db.SetMaxOpenConns(25)
+		db.SetMaxIdleConns(5)
+	}
 
-	return &DB{db, driverName}, nil
+	return db, driverName, nil
 }
 
 func (db *DB) Ping() error {
@@ -54,23 +73,27 @@ func (db *DB) Ping() error {
 }
 
 func (db *DB) Close() error {
+	db.replicas.close()
 	return db.DB.Close()
 }
 
 // Helper functions for database-specific operations
 func (db *DB) isMySQL() bool {
-	return db.driverName == "mysql"
+	return db.driverName == mysqlDriverName
 }
 
 func (db *DB) isPostgreSQL() bool {
 	return db.driverName == "postgres"
 }
 
-// Returns appropriate placeholder for parameter binding
+func (db *DB) isSQLite() bool {
+	return db.driverName == sqliteDriverName
+}
+
+// placeholder returns the nth bind parameter. Queries are written with
+// PostgreSQL's $N on every database; the MySQL and SQLite driver wrappers
+// rewrite them.
 func (db *DB) placeholder(n int) string {
-	if db.isMySQL() {
-		return "?"
-	}
 	return fmt.Sprintf("$%d", n)
 }
 
@@ -88,14 +111,14 @@ func (db *DB) generateUUID() string {
 func (db *DB) GetWorkflows(ctx context.Context) ([]models.Workflow, error) {
 	var workflows []models.Workflow
 	query := `
-        SELECT id, name, description, definition, user_id, is_active, 
-               created_at, updated_at, COALESCE(tags, '[]'), version, COALESCE(metadata, '{}')
+        SELECT id, name, description, definition, user_id, is_active, status,
+               created_at, updated_at, COALESCE(tags, '[]'), version, COALESCE(metadata, '{}'), project_id, tenant_id
         FROM workflows
-        WHERE is_active = true
-        ORDER BY created_at DESC
-    `
+        WHERE status = 'active'`
+	query, args := db.scopeToTenant(ctx, query, nil, "tenant_id")
+	query += " ORDER BY created_at DESC"
 
-	rows, err := db.QueryxContext(ctx, query)
+	rows, err := db.readQueryx(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -108,9 +131,9 @@ func (db *DB) GetWorkflows(ctx context.Context) ([]models.Workflow, error) {
 		var metadataJSON []byte
 
 		err := rows.Scan(&workflow.ID, &workflow.Name, &workflow.Description,
-			&definitionJSON, &workflow.UserID, &workflow.IsActive,
+			&definitionJSON, &workflow.UserID, &workflow.IsActive, &workflow.Status,
 			&workflow.CreatedAt, &workflow.UpdatedAt, &tagsJSON,
-			&workflow.Version, &metadataJSON)
+			&workflow.Version, &metadataJSON, &workflow.ProjectID, &workflow.TenantID)
 		if err != nil {
 			return nil, err
 		}
@@ -145,21 +168,21 @@ func (db *DB) GetWorkflow(ctx context.Context, id uuid.UUID) (*models.Workflow,
 	var metadataJSON []byte
 
 	query := `
-        SELECT id, name, description, definition, user_id, is_active, 
-               created_at, updated_at, COALESCE(tags, '[]'), version, COALESCE(metadata, '{}')
+        SELECT id, name, description, definition, user_id, is_active, status,
+               created_at, updated_at, COALESCE(tags, '[]'), version, COALESCE(metadata, '{}'), project_id, tenant_id
         FROM workflows
-        WHERE id = $1
-    `
+        WHERE id = $1`
+	query, args := db.scopeToTenant(ctx, query, []interface{}{id}, "tenant_id")
 
-	err := db.QueryRowxContext(ctx, query, id).Scan(
+	err := db.QueryRowxContext(ctx, query, args...).Scan(
 		&workflow.ID, &workflow.Name, &workflow.Description,
-		&definitionJSON, &workflow.UserID, &workflow.IsActive,
+		&definitionJSON, &workflow.UserID, &workflow.IsActive, &workflow.Status,
 		&workflow.CreatedAt, &workflow.UpdatedAt, &tagsJSON,
-		&workflow.Version, &metadataJSON)
+		&workflow.Version, &metadataJSON, &workflow.ProjectID, &workflow.TenantID)
 
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("workflow not found")
+			return nil, ErrWorkflowNotFound
 		}
 		return nil, err
 	}
@@ -195,6 +218,14 @@ func (db *DB) CreateWorkflow(ctx context.Context, workflow *models.Workflow) err
 	workflow.CreatedAt = now
 	workflow.UpdatedAt = now
 	workflow.Version = 1
+	workflow.TenantID = tenant.IDOrDefault(ctx)
+	if workflow.Status == "" {
+		workflow.Status = models.WorkflowStatusDraft
+		if workflow.IsActive {
+			workflow.Status = models.WorkflowStatusActive
+		}
+	}
+	workflow.IsActive = workflow.Status == models.WorkflowStatusActive
 
 	// Marshal JSON fields
 	definitionJSON, err := json.Marshal(workflow.Definition)
@@ -213,22 +244,35 @@ func (db *DB) CreateWorkflow(ctx context.Context, workflow *models.Workflow) err
 	}
 
 	query := `
-        INSERT INTO workflows (id, name, description, definition, user_id, is_active, 
-                              created_at, updated_at, tags, version, metadata)
-        VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+        INSERT INTO workflows (id, name, description, definition, user_id, is_active, status,
+                              created_at, updated_at, tags, version, metadata, project_id, tenant_id)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
     `
 
-	_, err = db.ExecContext(ctx, query, workflow.ID, workflow.Name, workflow.Description,
-		definitionJSON, workflow.UserID, workflow.IsActive,
+	tx, err := db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, query, workflow.ID, workflow.Name, workflow.Description,
+		definitionJSON, workflow.UserID, workflow.IsActive, workflow.Status,
 		workflow.CreatedAt, workflow.UpdatedAt, tagsJSON,
-		workflow.Version, metadataJSON)
+		workflow.Version, metadataJSON, workflow.ProjectID, workflow.TenantID)
+	if err != nil {
+		return err
+	}
 
-	return err
+	if err := db.insertWorkflowVersion(ctx, tx, workflow, definitionJSON); err != nil {
+		return err
+	}
+	return tx.Commit()
 }
 
+// UpdateWorkflow saves the workflow as its next version and records the
+// version in the workflow's history
 func (db *DB) UpdateWorkflow(ctx context.Context, workflow *models.Workflow) error {
 	workflow.UpdatedAt = time.Now()
-	workflow.Version++
 
 	// Marshal JSON fields
 	definitionJSON, err := json.Marshal(workflow.Definition)
@@ -246,16 +290,35 @@ func (db *DB) UpdateWorkflow(ctx context.Context, workflow *models.Workflow) err
 		return fmt.Errorf("failed to marshal metadata: %w", err)
 	}
 
+	tx, err := db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Number the version from the stored workflow, not the request body. The
+	// status only changes through SetWorkflowStatus.
+	versionQuery, versionArgs := db.scopeToTenant(ctx, `SELECT version, tenant_id, status, is_active FROM workflows WHERE id = $1`,
+		[]interface{}{workflow.ID}, "tenant_id")
+	if err := tx.QueryRowxContext(ctx, versionQuery, versionArgs...).Scan(&workflow.Version, &workflow.TenantID,
+		&workflow.Status, &workflow.IsActive); err != nil {
+		if err == sql.ErrNoRows {
+			return ErrWorkflowNotFound
+		}
+		return err
+	}
+	workflow.Version++
+
 	query := `
         UPDATE workflows 
-        SET name = $2, description = $3, definition = $4, is_active = $5,
-            updated_at = $6, tags = $7, version = $8, metadata = $9
-        WHERE id = $1
-    `
-
-	result, err := db.ExecContext(ctx, query, workflow.ID, workflow.Name, workflow.Description,
-		definitionJSON, workflow.IsActive, workflow.UpdatedAt,
-		tagsJSON, workflow.Version, metadataJSON)
+        SET name = $2, description = $3, definition = $4,
+            updated_at = $5, tags = $6, version = $7, metadata = $8, project_id = $9
+        WHERE id = $1`
+	query, args := db.scopeToTenant(ctx, query, []interface{}{workflow.ID, workflow.Name, workflow.Description,
+		definitionJSON, workflow.UpdatedAt,
+		tagsJSON, workflow.Version, metadataJSON, workflow.ProjectID}, "tenant_id")
+
+	result, err := tx.ExecContext(ctx, query, args...)
 	if err != nil {
 		return err
 	}
@@ -266,16 +329,28 @@ func (db *DB) UpdateWorkflow(ctx context.Context, workflow *models.Workflow) err
 	}
 
 	if rowsAffected == 0 {
-		return fmt.Errorf("workflow not found")
+		return ErrWorkflowNotFound
 	}
 
-	return nil
+	if err := db.insertWorkflowVersion(ctx, tx, workflow, definitionJSON); err != nil {
+		return err
+	}
+	return tx.Commit()
 }
 
+// DeleteWorkflow soft-deletes a workflow
 func (db *DB) DeleteWorkflow(ctx context.Context, id uuid.UUID) error {
-	query := `UPDATE workflows SET is_active = false WHERE id = $1`
+	return db.SetWorkflowStatus(ctx, id, models.WorkflowStatusDeleted)
+}
+
+// SetWorkflowStatus moves a workflow to status and keeps is_active in sync
+func (db *DB) SetWorkflowStatus(ctx context.Context, id uuid.UUID, status models.WorkflowStatus) error {
+	query := fmt.Sprintf(`UPDATE workflows SET status = %s, is_active = %s, updated_at = %s WHERE id = %s`,
+		db.placeholder(1), db.placeholder(2), db.placeholder(3), db.placeholder(4))
+	query, args := db.scopeToTenant(ctx, query,
+		[]interface{}{status, status == models.WorkflowStatusActive, time.Now(), id}, "tenant_id")
 
-	result, err := db.ExecContext(ctx, query, id)
+	result, err := db.ExecContext(ctx, query, args...)
 	if err != nil {
 		return err
 	}
@@ -286,19 +361,23 @@ func (db *DB) DeleteWorkflow(ctx context.Context, id uuid.UUID) error {
 	}
 
 	if rowsAffected == 0 {
-		return fmt.Errorf("workflow not found")
+		return ErrWorkflowNotFound
 	}
 
 	return nil
 }
 
-// Execution operations
+// CreateExecution stores a new execution started now, with the node runs
+// in its context, in one transaction
 func (db *DB) CreateExecution(ctx context.Context, execution *models.Execution) error {
 	if execution.ID == uuid.Nil {
 		execution.ID = uuid.New()
 	}
 
 	execution.StartedAt = time.Now()
+	if execution.TenantID == uuid.Nil {
+		execution.TenantID = tenant.IDOrDefault(ctx)
+	}
 
 	// Marshal JSON fields
 	inputJSON, err := json.Marshal(execution.Input)
@@ -316,25 +395,42 @@ func (db *DB) CreateExecution(ctx context.Context, execution *models.Execution)
 		return fmt.Errorf("failed to marshal metadata: %w", err)
 	}
 
-	contextJSON, err := json.Marshal(execution.Context)
+	// Node runs are stored in node_executions rather than the context
+	runs, storedCtx := nodeExecutionsOf(execution.Context)
+	contextJSON, err := json.Marshal(storedCtx)
 	if err != nil {
 		return fmt.Errorf("failed to marshal context: %w", err)
 	}
 
 	query := `
         INSERT INTO executions (id, workflow_id, status, input, output, error,
-                               started_at, completed_at, metadata, context)
-        VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+                               started_at, completed_at, metadata, context, tenant_id)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
     `
 
-	_, err = db.ExecContext(ctx, query, execution.ID, execution.WorkflowID, execution.Status,
+	tx, err := db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, query, execution.ID, execution.WorkflowID, execution.Status,
 		inputJSON, outputJSON, execution.Error, execution.StartedAt,
-		execution.CompletedAt, metadataJSON, contextJSON)
+		execution.CompletedAt, metadataJSON, contextJSON, execution.TenantID)
+	if err != nil {
+		return err
+	}
 
-	return err
+	if err := db.saveNodeExecutions(ctx, tx, execution.ID, runs); err != nil {
+		return err
+	}
+	return tx.Commit()
 }
 
-func (db *DB) UpdateExecution(ctx context.Context, execution *models.Execution) error {
+// UpdateExecution saves the execution's progress together with runs, the
+// node runs not yet saved, in one transaction. The node runs already in
+// node_executions are kept.
+func (db *DB) UpdateExecution(ctx context.Context, execution *models.Execution, runs ...models.NodeExecution) error {
 	// Marshal JSON fields
 	outputJSON, err := json.Marshal(execution.Output)
 	if err != nil {
@@ -346,7 +442,8 @@ func (db *DB) UpdateExecution(ctx context.Context, execution *models.Execution)
 		return fmt.Errorf("failed to marshal metadata: %w", err)
 	}
 
-	contextJSON, err := json.Marshal(execution.Context)
+	_, storedCtx := nodeExecutionsOf(execution.Context)
+	contextJSON, err := json.Marshal(storedCtx)
 	if err != nil {
 		return fmt.Errorf("failed to marshal context: %w", err)
 	}
@@ -355,13 +452,40 @@ func (db *DB) UpdateExecution(ctx context.Context, execution *models.Execution)
         UPDATE executions 
         SET status = $2, output = $3, error = $4, completed_at = $5, 
             metadata = $6, context = $7
-        WHERE id = $1
-    `
+        WHERE id = $1`
+	args := []interface{}{execution.ID, execution.Status, outputJSON,
+		execution.Error, execution.CompletedAt, metadataJSON, contextJSON}
+
+	// A claimed execution is only written by the holder of the latest claim
+	if execution.ClaimToken > 0 {
+		args = append(args, execution.ClaimToken)
+		query += " AND claim_token = " + db.placeholder(len(args))
+	}
+	query, args = db.scopeToTenant(ctx, query, args, "tenant_id")
 
-	_, err = db.ExecContext(ctx, query, execution.ID, execution.Status, outputJSON,
-		execution.Error, execution.CompletedAt, metadataJSON, contextJSON)
+	tx, err := db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	if execution.ClaimToken > 0 {
+		if affected, err := result.RowsAffected(); err == nil && affected == 0 {
+			return ErrExecutionFenced
+		}
+	}
 
-	return err
+	if err := db.saveNodeExecutions(ctx, tx, execution.ID, runs); err != nil {
+		return err
+	}
+	if err := db.saveExecutionUsage(ctx, tx, execution); err != nil {
+		return err
+	}
+	return tx.Commit()
 }
 
 func (db *DB) GetExecution(ctx context.Context, id uuid.UUID) (*models.Execution, error) {
@@ -370,20 +494,20 @@ func (db *DB) GetExecution(ctx context.Context, id uuid.UUID) (*models.Execution
 
 	query := `
         SELECT id, workflow_id, status, input, output, error,
-               started_at, completed_at, metadata, context
+               started_at, completed_at, metadata, context, tenant_id
         FROM executions
-        WHERE id = $1
-    `
+        WHERE id = $1`
+	query, args := db.scopeToTenant(ctx, query, []interface{}{id}, "tenant_id")
 
-	err := db.QueryRowxContext(ctx, query, id).Scan(
+	err := db.QueryRowxContext(ctx, query, args...).Scan(
 		&execution.ID, &execution.WorkflowID, &execution.Status,
 		&inputJSON, &outputJSON, &execution.Error,
 		&execution.StartedAt, &execution.CompletedAt,
-		&metadataJSON, &contextJSON)
+		&metadataJSON, &contextJSON, &execution.TenantID)
 
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("execution not found")
+			return nil, ErrExecutionNotFound
 		}
 		return nil, err
 	}
@@ -413,6 +537,21 @@ func (db *DB) GetExecution(ctx context.Context, id uuid.UUID) (*models.Execution
 		}
 	}
 
+	// Executions stored before node_executions keep their runs in the context
+	runs, err := db.ListNodeExecutions(ctx, execution.ID)
+	if err != nil {
+		return nil, err
+	}
+	if len(runs) > 0 {
+		execution.Context.NodeExecutions = make(map[string]models.NodeExecution, len(runs))
+		for _, run := range runs {
+			execution.Context.NodeExecutions[run.NodeID] = run
+		}
+	}
+	if execution.Usage, err = db.executionUsage(ctx, execution.ID); err != nil {
+		return nil, err
+	}
+
 	return &execution, nil
 }
 
@@ -439,6 +578,9 @@ func (db *DB) GetExecutions(ctx context.Context, workflowID *uuid.UUID, status *
 		argIndex++
 	}
 
+	query, args = db.scopeToTenant(ctx, query, args, "tenant_id")
+	argIndex = len(args) + 1
+
 	query += " ORDER BY started_at DESC"
 
 	if limit > 0 {
@@ -446,7 +588,7 @@ func (db *DB) GetExecutions(ctx context.Context, workflowID *uuid.UUID, status *
 		args = append(args, limit)
 	}
 
-	rows, err := db.QueryxContext(ctx, query, args...)
+	rows, err := db.readQueryx(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
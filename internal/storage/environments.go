@@ -0,0 +1,223 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/nuumz/f1ow/internal/models"
+	"github.com/nuumz/f1ow/internal/tenant"
+
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrEnvironmentNotFound is returned when an environment does not exist
+	ErrEnvironmentNotFound = errors.New("environment not found")
+	// ErrEnvironmentExists is returned when an environment name is taken
+	ErrEnvironmentExists = errors.New("environment already exists")
+	// ErrDeploymentNotFound is returned when a workflow is not deployed to an environment
+	ErrDeploymentNotFound = errors.New("workflow is not deployed to the environment")
+)
+
+// CreateEnvironment stores a new environment in the context's tenant
+func (db *DB) CreateEnvironment(ctx context.Context, environment *models.Environment) error {
+	if _, err := db.GetEnvironmentByName(ctx, environment.Name); err == nil {
+		return ErrEnvironmentExists
+	} else if !errors.Is(err, ErrEnvironmentNotFound) {
+		return err
+	}
+
+	environment.ID = uuid.New()
+	environment.CreatedAt = time.Now()
+
+	query := fmt.Sprintf(`
+        INSERT INTO environments (id, tenant_id, name, description, created_at)
+        VALUES (%s, %s, %s, %s, %s)
+    `, db.placeholder(1), db.placeholder(2), db.placeholder(3), db.placeholder(4), db.placeholder(5))
+
+	_, err := db.ExecContext(ctx, query, environment.ID, tenant.IDOrDefault(ctx), environment.Name,
+		environment.Description, environment.CreatedAt)
+	return err
+}
+
+// GetEnvironmentByName retrieves an environment by name
+func (db *DB) GetEnvironmentByName(ctx context.Context, name string) (*models.Environment, error) {
+	query := fmt.Sprintf(`
+        SELECT id, name, COALESCE(description, ''), created_at
+        FROM environments
+        WHERE name = %s`, db.placeholder(1))
+	query, args := db.scopeToTenant(ctx, query, []interface{}{name}, "tenant_id")
+
+	var environment models.Environment
+	err := db.QueryRowxContext(ctx, query, args...).Scan(&environment.ID, &environment.Name,
+		&environment.Description, &environment.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, ErrEnvironmentNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &environment, nil
+}
+
+// ListEnvironments returns the environments of the context's tenant ordered by name
+func (db *DB) ListEnvironments(ctx context.Context) ([]models.Environment, error) {
+	query, args := db.scopeToTenant(ctx, `
+        SELECT id, name, COALESCE(description, ''), created_at
+        FROM environments
+        WHERE 1=1`, nil, "tenant_id")
+
+	rows, err := db.QueryxContext(ctx, query+" ORDER BY name", args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	environments := []models.Environment{}
+	for rows.Next() {
+		var environment models.Environment
+		if err := rows.Scan(&environment.ID, &environment.Name, &environment.Description, &environment.CreatedAt); err != nil {
+			return nil, err
+		}
+		environments = append(environments, environment)
+	}
+	return environments, rows.Err()
+}
+
+// DeleteEnvironment removes an environment and the deployments to it
+func (db *DB) DeleteEnvironment(ctx context.Context, name string) error {
+	query, args := db.scopeToTenant(ctx, fmt.Sprintf(`DELETE FROM environments WHERE name = %s`, db.placeholder(1)),
+		[]interface{}{name}, "tenant_id")
+
+	result, err := db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	if affected, err := result.RowsAffected(); err == nil && affected == 0 {
+		return ErrEnvironmentNotFound
+	}
+	return nil
+}
+
+// SaveDeployment deploys a workflow version to an environment, replacing
+// the previous deployment there
+func (db *DB) SaveDeployment(ctx context.Context, deployment *models.Deployment) error {
+	environment, err := db.GetEnvironmentByName(ctx, deployment.Environment)
+	if err != nil {
+		return err
+	}
+
+	credentialsJSON, err := json.Marshal(deployment.Credentials)
+	if err != nil {
+		return fmt.Errorf("failed to marshal credentials: %w", err)
+	}
+	variablesJSON, err := json.Marshal(deployment.Variables)
+	if err != nil {
+		return fmt.Errorf("failed to marshal variables: %w", err)
+	}
+
+	tx, err := db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	deleteQuery := fmt.Sprintf(`DELETE FROM workflow_deployments WHERE workflow_id = %s AND environment_id = %s`,
+		db.placeholder(1), db.placeholder(2))
+	if _, err := tx.ExecContext(ctx, deleteQuery, deployment.WorkflowID, environment.ID); err != nil {
+		return err
+	}
+
+	insertQuery := fmt.Sprintf(`
+        INSERT INTO workflow_deployments (workflow_id, environment_id, tenant_id, version, credentials,
+                                          variables, deployed_by, deployed_at)
+        VALUES (%s, %s, %s, %s, %s, %s, %s, %s)
+    `, db.placeholder(1), db.placeholder(2), db.placeholder(3), db.placeholder(4),
+		db.placeholder(5), db.placeholder(6), db.placeholder(7), db.placeholder(8))
+	if _, err := tx.ExecContext(ctx, insertQuery, deployment.WorkflowID, environment.ID, tenant.IDOrDefault(ctx),
+		deployment.Version, credentialsJSON, variablesJSON, deployment.DeployedBy, deployment.DeployedAt); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+const deploymentQuery = `
+        SELECT d.workflow_id, e.name, d.version, d.credentials, d.variables, d.deployed_by, d.deployed_at
+        FROM workflow_deployments d
+        JOIN environments e ON e.id = d.environment_id
+        WHERE d.workflow_id = %s`
+
+// GetDeployment returns the deployment of a workflow to an environment
+func (db *DB) GetDeployment(ctx context.Context, workflowID uuid.UUID, environment string) (*models.Deployment, error) {
+	query := fmt.Sprintf(deploymentQuery+" AND e.name = %s", db.placeholder(1), db.placeholder(2))
+	query, args := db.scopeToTenant(ctx, query, []interface{}{workflowID, environment}, "d.tenant_id")
+
+	deployment, err := db.scanDeployment(db.QueryRowxContext(ctx, query, args...))
+	if err == sql.ErrNoRows {
+		return nil, ErrDeploymentNotFound
+	}
+	return deployment, err
+}
+
+// ListDeployments returns the deployments of a workflow ordered by environment
+func (db *DB) ListDeployments(ctx context.Context, workflowID uuid.UUID) ([]models.Deployment, error) {
+	query, args := db.scopeToTenant(ctx, fmt.Sprintf(deploymentQuery, db.placeholder(1)),
+		[]interface{}{workflowID}, "d.tenant_id")
+
+	rows, err := db.QueryxContext(ctx, query+" ORDER BY e.name", args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	deployments := []models.Deployment{}
+	for rows.Next() {
+		deployment, err := db.scanDeployment(rows)
+		if err != nil {
+			return nil, err
+		}
+		deployments = append(deployments, *deployment)
+	}
+	return deployments, rows.Err()
+}
+
+// DeleteDeployment removes a workflow from an environment
+func (db *DB) DeleteDeployment(ctx context.Context, workflowID uuid.UUID, environment string) error {
+	env, err := db.GetEnvironmentByName(ctx, environment)
+	if err != nil {
+		return err
+	}
+
+	query := fmt.Sprintf(`DELETE FROM workflow_deployments WHERE workflow_id = %s AND environment_id = %s`,
+		db.placeholder(1), db.placeholder(2))
+	result, err := db.ExecContext(ctx, query, workflowID, env.ID)
+	if err != nil {
+		return err
+	}
+	if affected, err := result.RowsAffected(); err == nil && affected == 0 {
+		return ErrDeploymentNotFound
+	}
+	return nil
+}
+
+func (db *DB) scanDeployment(row rowScanner) (*models.Deployment, error) {
+	var deployment models.Deployment
+	var credentialsJSON, variablesJSON []byte
+
+	if err := row.Scan(&deployment.WorkflowID, &deployment.Environment, &deployment.Version, &credentialsJSON,
+		&variablesJSON, &deployment.DeployedBy, &deployment.DeployedAt); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(credentialsJSON, &deployment.Credentials); err != nil {
+		return nil, fmt.Errorf("failed to parse deployment credentials: %w", err)
+	}
+	if err := json.Unmarshal(variablesJSON, &deployment.Variables); err != nil {
+		return nil, fmt.Errorf("failed to parse deployment variables: %w", err)
+	}
+	return &deployment, nil
+}
@@ -0,0 +1,238 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/nuumz/f1ow/internal/models"
+	"github.com/nuumz/f1ow/internal/subscriptions"
+	"github.com/nuumz/f1ow/internal/tenant"
+
+	"github.com/google/uuid"
+)
+
+const eventDeliveryColumns = `id, tenant_id, subscription_id, event_id, event_type, payload, status, attempts,
+               response_status, response_body, error, next_attempt_at, created_at, delivered_at`
+
+// CreateEventSubscription stores a new event subscription in the context's tenant
+func (db *DB) CreateEventSubscription(ctx context.Context, subscription *models.EventSubscription) error {
+	eventsJSON, err := json.Marshal(subscription.Events)
+	if err != nil {
+		return fmt.Errorf("failed to marshal events: %w", err)
+	}
+
+	subscription.TenantID = tenant.IDOrDefault(ctx)
+	query := fmt.Sprintf(`
+        INSERT INTO event_subscriptions (id, tenant_id, url, description, events, secret, enabled, created_at, updated_at)
+        VALUES (%s, %s, %s, %s, %s, %s, %s, %s, %s)
+    `, db.placeholder(1), db.placeholder(2), db.placeholder(3), db.placeholder(4), db.placeholder(5),
+		db.placeholder(6), db.placeholder(7), db.placeholder(8), db.placeholder(9))
+
+	_, err = db.ExecContext(ctx, query, subscription.ID, subscription.TenantID, subscription.URL, subscription.Description,
+		eventsJSON, subscription.Secret, subscription.Enabled, subscription.CreatedAt, subscription.UpdatedAt)
+	return err
+}
+
+// GetEventSubscription retrieves an event subscription, with its secret, by ID
+func (db *DB) GetEventSubscription(ctx context.Context, id uuid.UUID) (*models.EventSubscription, error) {
+	query := fmt.Sprintf(`
+        SELECT id, tenant_id, url, COALESCE(description, ''), events, secret, enabled, created_at, updated_at
+        FROM event_subscriptions
+        WHERE id = %s`, db.placeholder(1))
+	query, args := db.scopeToTenant(ctx, query, []interface{}{id}, "tenant_id")
+
+	subscription, err := scanEventSubscription(db.QueryRowxContext(ctx, query, args...))
+	if err == sql.ErrNoRows {
+		return nil, subscriptions.ErrNotFound
+	}
+	return subscription, err
+}
+
+// ListEventSubscriptions returns the event subscriptions, with their
+// secrets, of the context's tenant, oldest first
+func (db *DB) ListEventSubscriptions(ctx context.Context) ([]models.EventSubscription, error) {
+	query, args := db.scopeToTenant(ctx, `
+        SELECT id, tenant_id, url, COALESCE(description, ''), events, secret, enabled, created_at, updated_at
+        FROM event_subscriptions
+        WHERE 1=1`, nil, "tenant_id")
+
+	rows, err := db.QueryxContext(ctx, query+" ORDER BY created_at", args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list event subscriptions: %w", err)
+	}
+	defer rows.Close()
+
+	list := []models.EventSubscription{}
+	for rows.Next() {
+		subscription, err := scanEventSubscription(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, *subscription)
+	}
+	return list, rows.Err()
+}
+
+// UpdateEventSubscription replaces an event subscription's settings and secret
+func (db *DB) UpdateEventSubscription(ctx context.Context, subscription *models.EventSubscription) error {
+	eventsJSON, err := json.Marshal(subscription.Events)
+	if err != nil {
+		return fmt.Errorf("failed to marshal events: %w", err)
+	}
+
+	query := fmt.Sprintf(`
+        UPDATE event_subscriptions
+        SET url = %s, description = %s, events = %s, secret = %s, enabled = %s, updated_at = %s
+        WHERE id = %s`,
+		db.placeholder(1), db.placeholder(2), db.placeholder(3), db.placeholder(4), db.placeholder(5),
+		db.placeholder(6), db.placeholder(7))
+	query, args := db.scopeToTenant(ctx, query, []interface{}{subscription.URL, subscription.Description, eventsJSON,
+		subscription.Secret, subscription.Enabled, subscription.UpdatedAt, subscription.ID}, "tenant_id")
+
+	result, err := db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to update event subscription: %w", err)
+	}
+	if affected, err := result.RowsAffected(); err == nil && affected == 0 {
+		return subscriptions.ErrNotFound
+	}
+	return nil
+}
+
+// DeleteEventSubscription removes an event subscription and its deliveries
+func (db *DB) DeleteEventSubscription(ctx context.Context, id uuid.UUID) error {
+	query, args := db.scopeToTenant(ctx, fmt.Sprintf(`DELETE FROM event_subscriptions WHERE id = %s`, db.placeholder(1)),
+		[]interface{}{id}, "tenant_id")
+
+	result, err := db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	if affected, err := result.RowsAffected(); err == nil && affected == 0 {
+		return subscriptions.ErrNotFound
+	}
+	return nil
+}
+
+// CreateEventDelivery records a delivery of an event to a subscription
+func (db *DB) CreateEventDelivery(ctx context.Context, delivery *models.EventDelivery) error {
+	query := fmt.Sprintf(`
+        INSERT INTO event_deliveries (id, tenant_id, subscription_id, event_id, event_type, payload, status,
+                                      attempts, created_at)
+        VALUES (%s, %s, %s, %s, %s, %s, %s, %s, %s)
+    `, db.placeholder(1), db.placeholder(2), db.placeholder(3), db.placeholder(4), db.placeholder(5),
+		db.placeholder(6), db.placeholder(7), db.placeholder(8), db.placeholder(9))
+
+	_, err := db.ExecContext(ctx, query, delivery.ID, delivery.TenantID, delivery.SubscriptionID, delivery.EventID,
+		delivery.EventType, []byte(delivery.Payload), delivery.Status, delivery.Attempts, delivery.CreatedAt)
+	return err
+}
+
+// UpdateEventDelivery records the outcome of an attempt to deliver an event
+func (db *DB) UpdateEventDelivery(ctx context.Context, delivery *models.EventDelivery) error {
+	query := fmt.Sprintf(`
+        UPDATE event_deliveries
+        SET status = %s, attempts = %s, response_status = %s, response_body = %s, error = %s,
+            next_attempt_at = %s, delivered_at = %s
+        WHERE id = %s`,
+		db.placeholder(1), db.placeholder(2), db.placeholder(3), db.placeholder(4), db.placeholder(5),
+		db.placeholder(6), db.placeholder(7), db.placeholder(8))
+
+	_, err := db.ExecContext(ctx, query, delivery.Status, delivery.Attempts, delivery.ResponseStatus,
+		delivery.ResponseBody, delivery.Error, delivery.NextAttemptAt, delivery.DeliveredAt, delivery.ID)
+	return err
+}
+
+// GetEventDelivery retrieves an event delivery by ID
+func (db *DB) GetEventDelivery(ctx context.Context, id uuid.UUID) (*models.EventDelivery, error) {
+	query := fmt.Sprintf(`SELECT %s FROM event_deliveries WHERE id = %s`, eventDeliveryColumns, db.placeholder(1))
+	query, args := db.scopeToTenant(ctx, query, []interface{}{id}, "tenant_id")
+
+	delivery, err := scanEventDelivery(db.QueryRowxContext(ctx, query, args...))
+	if err == sql.ErrNoRows {
+		return nil, subscriptions.ErrDeliveryNotFound
+	}
+	return delivery, err
+}
+
+// ListEventDeliveries returns the subscription's most recent deliveries
+func (db *DB) ListEventDeliveries(ctx context.Context, subscriptionID uuid.UUID, limit int) ([]models.EventDelivery, error) {
+	query := fmt.Sprintf(`SELECT %s FROM event_deliveries WHERE subscription_id = %s`, eventDeliveryColumns, db.placeholder(1))
+	query, args := db.scopeToTenant(ctx, query, []interface{}{subscriptionID}, "tenant_id")
+	args = append(args, limit)
+	query += fmt.Sprintf(" ORDER BY created_at DESC LIMIT %s", db.placeholder(len(args)))
+
+	return db.queryEventDeliveries(ctx, query, args...)
+}
+
+// ListDueEventDeliveries returns pending deliveries of every tenant whose
+// next attempt is at or before now, oldest first
+func (db *DB) ListDueEventDeliveries(ctx context.Context, now time.Time, limit int) ([]models.EventDelivery, error) {
+	query := fmt.Sprintf(`
+        SELECT %s FROM event_deliveries
+        WHERE status = %s AND next_attempt_at <= %s
+        ORDER BY next_attempt_at LIMIT %s`,
+		eventDeliveryColumns, db.placeholder(1), db.placeholder(2), db.placeholder(3))
+
+	return db.queryEventDeliveries(ctx, query, models.EventDeliveryPending, now, limit)
+}
+
+func (db *DB) queryEventDeliveries(ctx context.Context, query string, args ...interface{}) ([]models.EventDelivery, error) {
+	rows, err := db.QueryxContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list event deliveries: %w", err)
+	}
+	defer rows.Close()
+
+	deliveries := []models.EventDelivery{}
+	for rows.Next() {
+		delivery, err := scanEventDelivery(rows)
+		if err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, *delivery)
+	}
+	return deliveries, rows.Err()
+}
+
+func scanEventSubscription(row rowScanner) (*models.EventSubscription, error) {
+	var subscription models.EventSubscription
+	var eventsJSON []byte
+	if err := row.Scan(&subscription.ID, &subscription.TenantID, &subscription.URL, &subscription.Description,
+		&eventsJSON, &subscription.Secret, &subscription.Enabled, &subscription.CreatedAt, &subscription.UpdatedAt); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(eventsJSON, &subscription.Events); err != nil {
+		return nil, fmt.Errorf("failed to parse subscription events: %w", err)
+	}
+	return &subscription, nil
+}
+
+func scanEventDelivery(row rowScanner) (*models.EventDelivery, error) {
+	var delivery models.EventDelivery
+	var payload []byte
+	var responseStatus sql.NullInt64
+	var responseBody, errorMessage sql.NullString
+	var nextAttemptAt, deliveredAt sql.NullTime
+	if err := row.Scan(&delivery.ID, &delivery.TenantID, &delivery.SubscriptionID, &delivery.EventID, &delivery.EventType,
+		&payload, &delivery.Status, &delivery.Attempts, &responseStatus, &responseBody, &errorMessage,
+		&nextAttemptAt, &delivery.CreatedAt, &deliveredAt); err != nil {
+		return nil, err
+	}
+	delivery.Payload = payload
+	if responseStatus.Valid {
+		status := int(responseStatus.Int64)
+		delivery.ResponseStatus = &status
+	}
+	delivery.ResponseBody, delivery.Error = responseBody.String, errorMessage.String
+	if nextAttemptAt.Valid {
+		delivery.NextAttemptAt = &nextAttemptAt.Time
+	}
+	if deliveredAt.Valid {
+		delivery.DeliveredAt = &deliveredAt.Time
+	}
+	return &delivery, nil
+}
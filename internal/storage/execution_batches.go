@@ -0,0 +1,105 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/nuumz/f1ow/internal/models"
+
+	"github.com/google/uuid"
+)
+
+// ErrBatchNotFound is returned when an execution batch does not exist in
+// the context's tenant
+var ErrBatchNotFound = errors.New("batch not found")
+
+// CreateExecutionBatch stores a new batch of the workflow, assigning its ID
+// when unset, and sets its CreatedAt
+func (db *DB) CreateExecutionBatch(ctx context.Context, batch *models.ExecutionBatch) error {
+	// Batches have no tenant of their own; the workflow's scopes them
+	if _, err := db.GetWorkflow(ctx, batch.WorkflowID); err != nil {
+		return err
+	}
+
+	if batch.ID == uuid.Nil {
+		batch.ID = uuid.New()
+	}
+	batch.CreatedAt = time.Now().UTC()
+
+	query := fmt.Sprintf(`INSERT INTO execution_batches (id, workflow_id, concurrency, created_at) VALUES (%s, %s, %s, %s)`,
+		db.placeholder(1), db.placeholder(2), db.placeholder(3), db.placeholder(4))
+	if _, err := db.ExecContext(ctx, query, batch.ID, batch.WorkflowID, batch.Concurrency, batch.CreatedAt); err != nil {
+		return fmt.Errorf("failed to create execution batch: %w", err)
+	}
+	return nil
+}
+
+// GetExecutionBatch returns a batch with its executions counted by status.
+// Counts are read from the primary, since workers gate batch concurrency
+// on them.
+func (db *DB) GetExecutionBatch(ctx context.Context, id uuid.UUID) (*models.ExecutionBatch, error) {
+	query := fmt.Sprintf(`SELECT workflow_id, concurrency, created_at FROM execution_batches WHERE id = %s`, db.placeholder(1))
+
+	batch := models.ExecutionBatch{ID: id, Counts: make(map[models.ExecutionStatus]int)}
+	err := db.QueryRowxContext(ctx, query, id).Scan(&batch.WorkflowID, &batch.Concurrency, &batch.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, ErrBatchNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get execution batch: %w", err)
+	}
+	if _, err := db.GetWorkflow(ctx, batch.WorkflowID); err != nil {
+		if errors.Is(err, ErrWorkflowNotFound) {
+			return nil, ErrBatchNotFound
+		}
+		return nil, err
+	}
+
+	countsQuery := fmt.Sprintf(`
+        SELECT e.status, COUNT(*)
+        FROM execution_batch_items i
+        JOIN executions e ON e.id = i.execution_id
+        WHERE i.batch_id = %s
+        GROUP BY e.status`, db.placeholder(1))
+	rows, err := db.QueryxContext(ctx, countsQuery, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count batch executions: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var status models.ExecutionStatus
+		var count int
+		if err := rows.Scan(&status, &count); err != nil {
+			return nil, fmt.Errorf("failed to scan batch count: %w", err)
+		}
+		batch.Counts[status] = count
+		batch.Total += count
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to count batch executions: %w", err)
+	}
+	batch.CountDone()
+	return &batch, nil
+}
+
+// AddBatchExecutions adds existing executions to a batch
+func (db *DB) AddBatchExecutions(ctx context.Context, batchID uuid.UUID, executionIDs []uuid.UUID) error {
+	tx, err := db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := fmt.Sprintf(`INSERT INTO execution_batch_items (batch_id, execution_id) VALUES (%s, %s)`,
+		db.placeholder(1), db.placeholder(2))
+	for _, executionID := range executionIDs {
+		if _, err := tx.ExecContext(ctx, query, batchID, executionID); err != nil {
+			return fmt.Errorf("failed to add execution %s to batch: %w", executionID, err)
+		}
+	}
+	return tx.Commit()
+}
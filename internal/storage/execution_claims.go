@@ -0,0 +1,237 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/nuumz/f1ow/internal/models"
+
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrExecutionNotClaimable is returned when claiming an execution that
+	// has finished or does not exist
+	ErrExecutionNotClaimable = errors.New("execution is finished or does not exist")
+
+	// ErrExecutionFenced is returned when updating an execution that has
+	// since been claimed by another owner
+	ErrExecutionFenced = errors.New("execution was claimed by another owner")
+)
+
+// ClaimedExecution is a running execution and the worker that claimed it
+type ClaimedExecution struct {
+	ID         uuid.UUID
+	WorkflowID uuid.UUID
+	TenantID   uuid.UUID
+	ClaimedBy  string
+	Input      map[string]interface{}
+	Metadata   map[string]interface{}
+}
+
+// ClaimExecution takes ownership of a pending or running execution for
+// owner, marks it running, and returns its new fencing token. Updates made
+// with an older token fail with ErrExecutionFenced.
+func (db *DB) ClaimExecution(ctx context.Context, id uuid.UUID, owner string) (int64, error) {
+	tx, err := db.BeginTxx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := fmt.Sprintf(`
+        UPDATE executions
+        SET claim_token = claim_token + 1, status = %s, claimed_by = %s
+        WHERE id = %s AND status IN (%s, %s)`,
+		db.placeholder(1), db.placeholder(2), db.placeholder(3), db.placeholder(4), db.placeholder(5))
+	query, args := db.scopeToTenant(ctx, query, []interface{}{models.ExecutionStatusRunning, owner, id,
+		models.ExecutionStatusPending, models.ExecutionStatusRunning}, "tenant_id")
+
+	result, err := tx.ExecContext(ctx, query, args...)
+	if err != nil {
+		return 0, fmt.Errorf("failed to claim execution: %w", err)
+	}
+	if affected, err := result.RowsAffected(); err == nil && affected == 0 {
+		return 0, ErrExecutionNotClaimable
+	}
+
+	var token int64
+	query = fmt.Sprintf(`SELECT claim_token FROM executions WHERE id = %s`, db.placeholder(1))
+	if err := tx.QueryRowxContext(ctx, query, id).Scan(&token); err != nil {
+		return 0, fmt.Errorf("failed to claim execution: %w", err)
+	}
+	return token, tx.Commit()
+}
+
+// AppendJournal records the output of a completed node of an execution
+// claimed with token, failing with ErrExecutionFenced if the execution has
+// been claimed again since
+func (db *DB) AppendJournal(ctx context.Context, executionID uuid.UUID, token int64, nodeID string, output interface{}) error {
+	outputJSON, err := json.Marshal(output)
+	if err != nil {
+		return fmt.Errorf("failed to marshal output: %w", err)
+	}
+
+	// Selecting from executions makes the insert conditional on the token
+	query := fmt.Sprintf(`
+        INSERT INTO execution_journal (execution_id, node_id, output, completed_at)
+        SELECT id, %s, %s, %s FROM executions WHERE id = %s AND claim_token = %s`,
+		db.placeholder(1), db.placeholder(2), db.placeholder(3), db.placeholder(4), db.placeholder(5))
+
+	result, err := db.ExecContext(ctx, query, nodeID, outputJSON, time.Now(), executionID, token)
+	if err != nil {
+		return fmt.Errorf("failed to append to journal: %w", err)
+	}
+	if affected, err := result.RowsAffected(); err == nil && affected == 0 {
+		return ErrExecutionFenced
+	}
+	return nil
+}
+
+// GetJournal returns the outputs of the execution's completed nodes by
+// node ID
+func (db *DB) GetJournal(ctx context.Context, executionID uuid.UUID) (map[string]interface{}, error) {
+	query := fmt.Sprintf(`SELECT node_id, output FROM execution_journal WHERE execution_id = %s`, db.placeholder(1))
+	rows, err := db.QueryxContext(ctx, query, executionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get journal: %w", err)
+	}
+	defer rows.Close()
+
+	outputs := make(map[string]interface{})
+	for rows.Next() {
+		var nodeID string
+		var outputJSON []byte
+		if err := rows.Scan(&nodeID, &outputJSON); err != nil {
+			return nil, err
+		}
+		var output interface{}
+		if len(outputJSON) > 0 {
+			if err := json.Unmarshal(outputJSON, &output); err != nil {
+				return nil, fmt.Errorf("failed to parse journal output of node %s: %w", nodeID, err)
+			}
+		}
+		outputs[nodeID] = output
+	}
+	return outputs, rows.Err()
+}
+
+// ListClaimedExecutions returns the running executions claimed by workers,
+// across tenants
+func (db *DB) ListClaimedExecutions(ctx context.Context) ([]ClaimedExecution, error) {
+	query := fmt.Sprintf(`
+        SELECT id, workflow_id, tenant_id, claimed_by, input, metadata
+        FROM executions
+        WHERE status = %s AND claimed_by IS NOT NULL`, db.placeholder(1))
+
+	rows, err := db.QueryxContext(ctx, query, models.ExecutionStatusRunning)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list claimed executions: %w", err)
+	}
+	defer rows.Close()
+
+	var executions []ClaimedExecution
+	for rows.Next() {
+		var execution ClaimedExecution
+		var id, workflowID, tenantID string
+		var inputJSON, metadataJSON []byte
+		if err := rows.Scan(&id, &workflowID, &tenantID, &execution.ClaimedBy, &inputJSON, &metadataJSON); err != nil {
+			return nil, err
+		}
+		if execution.ID, err = uuid.Parse(id); err != nil {
+			return nil, err
+		}
+		if execution.WorkflowID, err = uuid.Parse(workflowID); err != nil {
+			return nil, err
+		}
+		if execution.TenantID, err = uuid.Parse(tenantID); err != nil {
+			return nil, err
+		}
+		if len(inputJSON) > 0 {
+			if err := json.Unmarshal(inputJSON, &execution.Input); err != nil {
+				return nil, fmt.Errorf("failed to parse input: %w", err)
+			}
+		}
+		if len(metadataJSON) > 0 {
+			if err := json.Unmarshal(metadataJSON, &execution.Metadata); err != nil {
+				return nil, fmt.Errorf("failed to parse metadata: %w", err)
+			}
+		}
+		executions = append(executions, execution)
+	}
+	return executions, rows.Err()
+}
+
+// RequeueExecution returns a running execution claimed by owner to pending
+// so another worker can claim it, and reports whether it did. Only one of
+// several callers racing to requeue the same execution succeeds.
+func (db *DB) RequeueExecution(ctx context.Context, id uuid.UUID, owner string) (bool, error) {
+	query := fmt.Sprintf(`
+        UPDATE executions
+        SET status = %s, claimed_by = NULL
+        WHERE id = %s AND status = %s AND claimed_by = %s`,
+		db.placeholder(1), db.placeholder(2), db.placeholder(3), db.placeholder(4))
+
+	result, err := db.ExecContext(ctx, query, models.ExecutionStatusPending, id, models.ExecutionStatusRunning, owner)
+	if err != nil {
+		return false, fmt.Errorf("failed to requeue execution: %w", err)
+	}
+	affected, err := result.RowsAffected()
+	return affected > 0, err
+}
+
+// ListActiveExecutions returns the pending and running executions of the
+// workflow, oldest first
+func (db *DB) ListActiveExecutions(ctx context.Context, workflowID uuid.UUID) ([]models.ExecutionSummary, error) {
+	query := fmt.Sprintf(`
+        SELECT id, status, started_at
+        FROM executions
+        WHERE workflow_id = %s AND status IN (%s, %s)`,
+		db.placeholder(1), db.placeholder(2), db.placeholder(3))
+	query, args := db.scopeToTenant(ctx, query, []interface{}{workflowID,
+		models.ExecutionStatusPending, models.ExecutionStatusRunning}, "tenant_id")
+	query += " ORDER BY started_at ASC"
+
+	rows, err := db.QueryxContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list active executions: %w", err)
+	}
+	defer rows.Close()
+
+	var executions []models.ExecutionSummary
+	for rows.Next() {
+		execution := models.ExecutionSummary{WorkflowID: workflowID}
+		var id string
+		if err := rows.Scan(&id, &execution.Status, &execution.StartedAt); err != nil {
+			return nil, err
+		}
+		if execution.ID, err = uuid.Parse(id); err != nil {
+			return nil, err
+		}
+		executions = append(executions, execution)
+	}
+	return executions, rows.Err()
+}
+
+// CancelExecution marks a pending or running execution cancelled and
+// reports whether it did. The claim token moves on, so the worker running
+// it is fenced off and stops at its next journal write.
+func (db *DB) CancelExecution(ctx context.Context, id uuid.UUID, reason string) (bool, error) {
+	query := fmt.Sprintf(`
+        UPDATE executions
+        SET status = %s, error = %s, completed_at = %s, claim_token = claim_token + 1, claimed_by = NULL
+        WHERE id = %s AND status IN (%s, %s)`,
+		db.placeholder(1), db.placeholder(2), db.placeholder(3), db.placeholder(4), db.placeholder(5), db.placeholder(6))
+	query, args := db.scopeToTenant(ctx, query, []interface{}{models.ExecutionStatusCancelled, reason, time.Now(), id,
+		models.ExecutionStatusPending, models.ExecutionStatusRunning}, "tenant_id")
+
+	result, err := db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return false, fmt.Errorf("failed to cancel execution: %w", err)
+	}
+	affected, err := result.RowsAffected()
+	return affected > 0, err
+}
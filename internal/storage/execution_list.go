@@ -0,0 +1,187 @@
+package storage
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/nuumz/f1ow/internal/models"
+	"github.com/nuumz/f1ow/internal/tenant"
+
+	"github.com/google/uuid"
+)
+
+// ExecutionListOptions filters and pages the execution list
+type ExecutionListOptions struct {
+	WorkflowID    *uuid.UUID
+	Statuses      []models.ExecutionStatus
+	StartedAfter  *time.Time
+	StartedBefore *time.Time
+	Cursor        string // opaque cursor from a previous page
+	Limit         int
+	Summary       bool // omit input, output, and context
+}
+
+// ExecutionPage is one page of executions
+type ExecutionPage struct {
+	Executions []models.Execution
+	Total      int
+	NextCursor string // empty on the last page
+}
+
+// ListExecutions returns executions newest first using keyset pagination on
+// (started_at, id), so deep pages cost the same as the first one
+func (db *DB) ListExecutions(ctx context.Context, opts ExecutionListOptions) (*ExecutionPage, error) {
+	where := []string{"1=1"}
+	args := []interface{}{}
+
+	if id, ok := tenant.FromContext(ctx); ok {
+		args = append(args, id)
+		where = append(where, fmt.Sprintf("tenant_id = %s", db.placeholder(len(args))))
+	}
+
+	if opts.WorkflowID != nil {
+		args = append(args, *opts.WorkflowID)
+		where = append(where, fmt.Sprintf("workflow_id = %s", db.placeholder(len(args))))
+	}
+
+	if len(opts.Statuses) > 0 {
+		placeholders := make([]string, len(opts.Statuses))
+		for i, status := range opts.Statuses {
+			args = append(args, status)
+			placeholders[i] = db.placeholder(len(args))
+		}
+		where = append(where, fmt.Sprintf("status IN (%s)", strings.Join(placeholders, ", ")))
+	}
+
+	if opts.StartedAfter != nil {
+		args = append(args, *opts.StartedAfter)
+		where = append(where, fmt.Sprintf("started_at >= %s", db.placeholder(len(args))))
+	}
+
+	if opts.StartedBefore != nil {
+		args = append(args, *opts.StartedBefore)
+		where = append(where, fmt.Sprintf("started_at < %s", db.placeholder(len(args))))
+	}
+
+	// The total covers every page, so it ignores the cursor
+	var total int
+	countQuery := "SELECT COUNT(*) FROM executions WHERE " + strings.Join(where, " AND ")
+	if err := db.readQueryRowx(ctx, countQuery, args...).Scan(&total); err != nil {
+		return nil, err
+	}
+
+	if opts.Cursor != "" {
+		startedAt, id, err := DecodeExecutionCursor(opts.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, startedAt)
+		before := db.placeholder(len(args))
+		args = append(args, startedAt)
+		same := db.placeholder(len(args))
+		args = append(args, id)
+		where = append(where, fmt.Sprintf("(started_at < %s OR (started_at = %s AND id < %s))",
+			before, same, db.placeholder(len(args))))
+	}
+
+	dataColumns := "input, output, context"
+	if opts.Summary {
+		dataColumns = "NULL, NULL, NULL"
+	}
+
+	query := fmt.Sprintf(`
+        SELECT id, workflow_id, status, %s, error,
+               started_at, completed_at, metadata, tenant_id
+        FROM executions
+        WHERE %s
+        ORDER BY started_at DESC, id DESC
+    `, dataColumns, strings.Join(where, " AND "))
+
+	// Fetch one extra row to learn whether another page exists
+	if opts.Limit > 0 {
+		args = append(args, opts.Limit+1)
+		query += fmt.Sprintf(" LIMIT %s", db.placeholder(len(args)))
+	}
+
+	rows, err := db.readQueryx(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	executions := []models.Execution{}
+	for rows.Next() {
+		var execution models.Execution
+		var inputJSON, outputJSON, contextJSON, metadataJSON []byte
+
+		err := rows.Scan(
+			&execution.ID, &execution.WorkflowID, &execution.Status,
+			&inputJSON, &outputJSON, &contextJSON, &execution.Error,
+			&execution.StartedAt, &execution.CompletedAt, &metadataJSON, &execution.TenantID)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(inputJSON) > 0 {
+			json.Unmarshal(inputJSON, &execution.Input)
+		}
+		if len(outputJSON) > 0 {
+			json.Unmarshal(outputJSON, &execution.Output)
+		}
+		if len(contextJSON) > 0 {
+			json.Unmarshal(contextJSON, &execution.Context)
+		}
+		if len(metadataJSON) > 0 {
+			json.Unmarshal(metadataJSON, &execution.Metadata)
+		}
+
+		executions = append(executions, execution)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	page := &ExecutionPage{Executions: executions, Total: total}
+	if opts.Limit > 0 && len(executions) > opts.Limit {
+		page.Executions = executions[:opts.Limit]
+		last := page.Executions[opts.Limit-1]
+		page.NextCursor = EncodeExecutionCursor(last.StartedAt, last.ID)
+	}
+
+	return page, nil
+}
+
+// EncodeExecutionCursor builds the opaque cursor for the page after the
+// given execution
+func EncodeExecutionCursor(startedAt time.Time, id uuid.UUID) string {
+	raw := startedAt.UTC().Format(time.RFC3339Nano) + "|" + id.String()
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// DecodeExecutionCursor parses a cursor produced by EncodeExecutionCursor
+func DecodeExecutionCursor(cursor string) (time.Time, uuid.UUID, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return time.Time{}, uuid.Nil, fmt.Errorf("invalid cursor")
+	}
+
+	timestamp, idStr, found := strings.Cut(string(raw), "|")
+	if !found {
+		return time.Time{}, uuid.Nil, fmt.Errorf("invalid cursor")
+	}
+
+	startedAt, err := time.Parse(time.RFC3339Nano, timestamp)
+	if err != nil {
+		return time.Time{}, uuid.Nil, fmt.Errorf("invalid cursor")
+	}
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		return time.Time{}, uuid.Nil, fmt.Errorf("invalid cursor")
+	}
+
+	return startedAt, id, nil
+}
@@ -0,0 +1,206 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/nuumz/f1ow/internal/models"
+	"github.com/nuumz/f1ow/internal/tenant"
+
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrExternalTaskNotFound is returned when an external task does not
+	// exist in the context's tenant
+	ErrExternalTaskNotFound = errors.New("external task not found")
+
+	// ErrExternalTaskNotLocked is returned when a worker completes, fails,
+	// or extends a task it does not hold the lock on
+	ErrExternalTaskNotLocked = errors.New("external task is not locked by this worker")
+)
+
+const externalTaskColumns = `id, topic, workflow_id, execution_id, node_id, payload, status, worker_id,
+               locked_until, attempts, result, error, tenant_id, created_at, updated_at`
+
+// CreateExternalTask stores a new pending task, assigning its ID when unset
+func (db *DB) CreateExternalTask(ctx context.Context, task *models.ExternalTask) error {
+	if task.ID == uuid.Nil {
+		task.ID = uuid.New()
+	}
+	if task.TenantID == uuid.Nil {
+		task.TenantID = tenant.IDOrDefault(ctx)
+	}
+	task.Status = models.ExternalTaskPending
+	task.CreatedAt = time.Now().UTC()
+	task.UpdatedAt = task.CreatedAt
+
+	payloadJSON, err := json.Marshal(task.Payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal payload: %w", err)
+	}
+
+	query := fmt.Sprintf(`
+        INSERT INTO external_tasks (id, topic, workflow_id, execution_id, node_id, payload, status,
+                                    attempts, tenant_id, created_at, updated_at)
+        VALUES (%s, %s, %s, %s, %s, %s, %s, 0, %s, %s, %s)`,
+		db.placeholder(1), db.placeholder(2), db.placeholder(3), db.placeholder(4), db.placeholder(5),
+		db.placeholder(6), db.placeholder(7), db.placeholder(8), db.placeholder(9), db.placeholder(10))
+	_, err = db.ExecContext(ctx, query, task.ID, task.Topic, task.WorkflowID, task.ExecutionID, task.NodeID,
+		payloadJSON, task.Status, task.TenantID, task.CreatedAt, task.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create external task: %w", err)
+	}
+	return nil
+}
+
+// GetExternalTask returns an external task
+func (db *DB) GetExternalTask(ctx context.Context, id uuid.UUID) (*models.ExternalTask, error) {
+	query := fmt.Sprintf(`SELECT %s FROM external_tasks WHERE id = %s`, externalTaskColumns, db.placeholder(1))
+	query, args := db.scopeToTenant(ctx, query, []interface{}{id}, "tenant_id")
+
+	task, err := scanExternalTask(db.QueryRowxContext(ctx, query, args...))
+	if err == sql.ErrNoRows {
+		return nil, ErrExternalTaskNotFound
+	}
+	return task, err
+}
+
+// LockExternalTasks locks up to max of the oldest available tasks of the
+// topics for workerID until lockFor from now, and returns them. Tasks are
+// available while pending, or locked by a worker whose lock has expired,
+// once any retry delay has passed.
+func (db *DB) LockExternalTasks(ctx context.Context, workerID string, topics []string, max int, lockFor time.Duration) ([]models.ExternalTask, error) {
+	if len(topics) == 0 || max <= 0 {
+		return []models.ExternalTask{}, nil
+	}
+	now := time.Now().UTC()
+	lockedUntil := now.Add(lockFor)
+
+	args := []interface{}{models.ExternalTaskPending, models.ExternalTaskLocked, now}
+	topicPlaceholders := make([]string, len(topics))
+	for i, topic := range topics {
+		args = append(args, topic)
+		topicPlaceholders[i] = db.placeholder(len(args))
+	}
+	query := fmt.Sprintf(`
+        SELECT id FROM external_tasks
+        WHERE status IN (%s, %s) AND (locked_until IS NULL OR locked_until < %s) AND topic IN (%s)`,
+		db.placeholder(1), db.placeholder(2), db.placeholder(3), strings.Join(topicPlaceholders, ", "))
+	query, args = db.scopeToTenant(ctx, query, args, "tenant_id")
+	query += fmt.Sprintf(" ORDER BY created_at LIMIT %d", max)
+
+	var candidates []uuid.UUID
+	if err := db.SelectContext(ctx, &candidates, query, args...); err != nil {
+		return nil, fmt.Errorf("failed to find external tasks: %w", err)
+	}
+
+	// Each lock re-checks availability, so of the workers racing for a
+	// task only one gets it
+	lockQuery := fmt.Sprintf(`
+        UPDATE external_tasks
+        SET status = %s, worker_id = %s, locked_until = %s, attempts = attempts + 1, updated_at = %s
+        WHERE id = %s AND status IN (%s, %s) AND (locked_until IS NULL OR locked_until < %s)`,
+		db.placeholder(1), db.placeholder(2), db.placeholder(3), db.placeholder(4),
+		db.placeholder(5), db.placeholder(6), db.placeholder(7), db.placeholder(8))
+
+	tasks := []models.ExternalTask{}
+	for _, id := range candidates {
+		result, err := db.ExecContext(ctx, lockQuery, models.ExternalTaskLocked, workerID, lockedUntil, now,
+			id, models.ExternalTaskPending, models.ExternalTaskLocked, now)
+		if err != nil {
+			return nil, fmt.Errorf("failed to lock external task %s: %w", id, err)
+		}
+		if affected, err := result.RowsAffected(); err != nil || affected == 0 {
+			continue
+		}
+		task, err := db.GetExternalTask(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		tasks = append(tasks, *task)
+	}
+	return tasks, nil
+}
+
+// CompleteExternalTask records the result of a task workerID has locked
+func (db *DB) CompleteExternalTask(ctx context.Context, id uuid.UUID, workerID string, result map[string]interface{}) error {
+	resultJSON, err := json.Marshal(result)
+	if err != nil {
+		return fmt.Errorf("failed to marshal result: %w", err)
+	}
+	return db.updateLockedExternalTask(ctx, id, workerID,
+		[]string{"status", "result", "locked_until"}, models.ExternalTaskCompleted, resultJSON, nil)
+}
+
+// FailExternalTask records the failure of a task workerID has locked. With
+// retryAt set the task is available again from then; otherwise it fails.
+func (db *DB) FailExternalTask(ctx context.Context, id uuid.UUID, workerID string, message string, retryAt *time.Time) error {
+	if retryAt != nil {
+		return db.updateLockedExternalTask(ctx, id, workerID,
+			[]string{"status", "error", "locked_until"}, models.ExternalTaskPending, message, retryAt.UTC())
+	}
+	return db.updateLockedExternalTask(ctx, id, workerID,
+		[]string{"status", "error", "locked_until"}, models.ExternalTaskFailed, message, nil)
+}
+
+// ExtendExternalTaskLock moves the expiry of workerID's lock on a task
+func (db *DB) ExtendExternalTaskLock(ctx context.Context, id uuid.UUID, workerID string, until time.Time) error {
+	return db.updateLockedExternalTask(ctx, id, workerID, []string{"locked_until"}, until.UTC())
+}
+
+// updateLockedExternalTask sets columns to values on a task workerID has
+// locked
+func (db *DB) updateLockedExternalTask(ctx context.Context, id uuid.UUID, workerID string, columns []string, values ...interface{}) error {
+	sets := make([]string, len(columns))
+	for i, column := range columns {
+		sets[i] = fmt.Sprintf("%s = %s", column, db.placeholder(i+1))
+	}
+	n := len(columns)
+	query := fmt.Sprintf(`UPDATE external_tasks SET %s, updated_at = %s WHERE id = %s AND status = %s AND worker_id = %s`,
+		strings.Join(sets, ", "), db.placeholder(n+1), db.placeholder(n+2), db.placeholder(n+3), db.placeholder(n+4))
+	args := append(values, time.Now().UTC(), id, models.ExternalTaskLocked, workerID)
+	query, args = db.scopeToTenant(ctx, query, args, "tenant_id")
+
+	result, err := db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to update external task: %w", err)
+	}
+	if affected, err := result.RowsAffected(); err == nil && affected == 0 {
+		if _, err := db.GetExternalTask(ctx, id); err != nil {
+			return err
+		}
+		return ErrExternalTaskNotLocked
+	}
+	return nil
+}
+
+func scanExternalTask(row interface{ Scan(...interface{}) error }) (*models.ExternalTask, error) {
+	var task models.ExternalTask
+	var payloadJSON, resultJSON []byte
+	var workerID sql.NullString
+	err := row.Scan(&task.ID, &task.Topic, &task.WorkflowID, &task.ExecutionID, &task.NodeID, &payloadJSON,
+		&task.Status, &workerID, &task.LockedUntil, &task.Attempts, &resultJSON, &task.Error, &task.TenantID,
+		&task.CreatedAt, &task.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	task.WorkerID = workerID.String
+
+	if len(payloadJSON) > 0 {
+		if err := json.Unmarshal(payloadJSON, &task.Payload); err != nil {
+			return nil, fmt.Errorf("failed to parse payload: %w", err)
+		}
+	}
+	if len(resultJSON) > 0 {
+		if err := json.Unmarshal(resultJSON, &task.Result); err != nil {
+			return nil, fmt.Errorf("failed to parse result: %w", err)
+		}
+	}
+	return &task, nil
+}
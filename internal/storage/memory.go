@@ -0,0 +1,993 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nuumz/f1ow/internal/models"
+	"github.com/nuumz/f1ow/internal/tenant"
+
+	"github.com/google/uuid"
+)
+
+// MemoryRepository is a Repository held in process memory for unit tests
+// and embedders that run the engine without a database. Records are copied
+// through JSON on the way in and out, so callers get back the same types
+// they would read from SQL and never share maps with the store.
+type MemoryRepository struct {
+	mu           sync.RWMutex
+	workflows    map[uuid.UUID]*models.Workflow
+	versions     map[uuid.UUID][]models.WorkflowVersion // by workflow, oldest first
+	executions   map[uuid.UUID]*memoryExecution
+	environments []memoryEnvironment
+	deployments  []memoryDeployment
+	batches      map[uuid.UUID]*memoryBatch
+}
+
+type memoryExecution struct {
+	execution  models.Execution
+	claimToken int64
+	claimedBy  string
+	journal    map[string]interface{}
+}
+
+type memoryBatch struct {
+	batch      models.ExecutionBatch
+	executions []uuid.UUID
+}
+
+type memoryEnvironment struct {
+	tenantID    uuid.UUID
+	environment models.Environment
+}
+
+type memoryDeployment struct {
+	tenantID      uuid.UUID
+	environmentID uuid.UUID
+	deployment    models.Deployment
+}
+
+// NewMemoryRepository creates an empty in-memory repository
+func NewMemoryRepository() *MemoryRepository {
+	return &MemoryRepository{
+		workflows:  make(map[uuid.UUID]*models.Workflow),
+		versions:   make(map[uuid.UUID][]models.WorkflowVersion),
+		executions: make(map[uuid.UUID]*memoryExecution),
+		batches:    make(map[uuid.UUID]*memoryBatch),
+	}
+}
+
+// inTenant reports whether a record of tenantID is visible to the context,
+// matching the tenant_id filter scopeToTenant adds to queries
+func inTenant(ctx context.Context, tenantID uuid.UUID) bool {
+	id, ok := tenant.FromContext(ctx)
+	return !ok || id == tenantID
+}
+
+// copyJSON copies src into dst the way a JSON column round trip would
+func copyJSON(src, dst interface{}) error {
+	data, err := json.Marshal(src)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, dst)
+}
+
+func (r *MemoryRepository) workflow(ctx context.Context, id uuid.UUID) (*models.Workflow, bool) {
+	workflow, ok := r.workflows[id]
+	if !ok || !inTenant(ctx, workflow.TenantID) {
+		return nil, false
+	}
+	return workflow, true
+}
+
+func (r *MemoryRepository) copyWorkflow(workflow *models.Workflow) (models.Workflow, error) {
+	var copied models.Workflow
+	if err := copyJSON(workflow, &copied); err != nil {
+		return models.Workflow{}, fmt.Errorf("failed to copy workflow: %w", err)
+	}
+	return copied, nil
+}
+
+// saveWorkflow stores a copy of the workflow and records its version
+func (r *MemoryRepository) saveWorkflow(workflow *models.Workflow, createdAt time.Time) error {
+	stored, err := r.copyWorkflow(workflow)
+	if err != nil {
+		return err
+	}
+	stored.CreatedAt = createdAt
+	r.workflows[workflow.ID] = &stored
+
+	version := models.WorkflowVersion{
+		WorkflowID:  stored.ID,
+		Version:     stored.Version,
+		Name:        stored.Name,
+		Description: stored.Description,
+		Definition:  stored.Definition,
+		UserID:      stored.UserID,
+		CreatedAt:   stored.UpdatedAt,
+	}
+	r.versions[stored.ID] = append(r.versions[stored.ID], version)
+	return nil
+}
+
+// GetWorkflows returns the active workflows, newest first
+func (r *MemoryRepository) GetWorkflows(ctx context.Context) ([]models.Workflow, error) {
+	opts := WorkflowListOptions{Statuses: []models.WorkflowStatus{models.WorkflowStatusActive}, Desc: true}
+	workflows, _, err := r.ListWorkflows(ctx, opts)
+	return workflows, err
+}
+
+// GetWorkflow returns a workflow by ID
+func (r *MemoryRepository) GetWorkflow(ctx context.Context, id uuid.UUID) (*models.Workflow, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	workflow, ok := r.workflow(ctx, id)
+	if !ok {
+		return nil, ErrWorkflowNotFound
+	}
+	copied, err := r.copyWorkflow(workflow)
+	if err != nil {
+		return nil, err
+	}
+	return &copied, nil
+}
+
+// ListWorkflows returns one page of workflows and the total number of
+// workflows matching the filters
+func (r *MemoryRepository) ListWorkflows(ctx context.Context, opts WorkflowListOptions) ([]models.Workflow, int, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	statuses := opts.Statuses
+	if len(statuses) == 0 {
+		statuses = []models.WorkflowStatus{models.WorkflowStatusDraft, models.WorkflowStatusActive}
+	}
+	query := strings.ToLower(opts.Query)
+
+	matches := []*models.Workflow{}
+	for _, workflow := range r.workflows {
+		if !inTenant(ctx, workflow.TenantID) || !hasStatus(statuses, workflow.Status) {
+			continue
+		}
+		if query != "" && !strings.Contains(strings.ToLower(workflow.Name), query) {
+			continue
+		}
+		if opts.ProjectID != nil && (workflow.ProjectID == nil || *workflow.ProjectID != *opts.ProjectID) {
+			continue
+		}
+		if !hasTags(workflow.Tags, opts.Tags) {
+			continue
+		}
+		matches = append(matches, workflow)
+	}
+
+	sort.Slice(matches, func(i, j int) bool {
+		a, b := matches[i], matches[j]
+		var cmp int
+		switch opts.Sort {
+		case "name":
+			cmp = strings.Compare(a.Name, b.Name)
+		case "updated_at":
+			cmp = a.UpdatedAt.Compare(b.UpdatedAt)
+		default:
+			cmp = a.CreatedAt.Compare(b.CreatedAt)
+		}
+		if opts.Desc {
+			cmp = -cmp
+		}
+		if cmp == 0 {
+			return a.ID.String() < b.ID.String()
+		}
+		return cmp < 0
+	})
+
+	total := len(matches)
+	if opts.Offset > 0 {
+		matches = matches[min(opts.Offset, len(matches)):]
+	}
+	if opts.Limit > 0 && len(matches) > opts.Limit {
+		matches = matches[:opts.Limit]
+	}
+
+	workflows := make([]models.Workflow, 0, len(matches))
+	for _, workflow := range matches {
+		copied, err := r.copyWorkflow(workflow)
+		if err != nil {
+			return nil, 0, err
+		}
+		if opts.Summary {
+			copied.Definition = models.WorkflowDefinition{}
+		}
+		workflows = append(workflows, copied)
+	}
+	return workflows, total, nil
+}
+
+func hasStatus(statuses []models.WorkflowStatus, status models.WorkflowStatus) bool {
+	for _, s := range statuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
+// hasTags reports whether tags includes every wanted tag
+func hasTags(tags, wanted []string) bool {
+	for _, want := range wanted {
+		found := false
+		for _, tag := range tags {
+			if tag == want {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// CreateWorkflow stores a new workflow at version 1
+func (r *MemoryRepository) CreateWorkflow(ctx context.Context, workflow *models.Workflow) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if workflow.ID == uuid.Nil {
+		workflow.ID = uuid.New()
+	}
+	if _, exists := r.workflows[workflow.ID]; exists {
+		return fmt.Errorf("workflow %s already exists", workflow.ID)
+	}
+
+	now := time.Now()
+	workflow.CreatedAt = now
+	workflow.UpdatedAt = now
+	workflow.Version = 1
+	workflow.TenantID = tenant.IDOrDefault(ctx)
+	if workflow.Status == "" {
+		workflow.Status = models.WorkflowStatusDraft
+		if workflow.IsActive {
+			workflow.Status = models.WorkflowStatusActive
+		}
+	}
+	workflow.IsActive = workflow.Status == models.WorkflowStatusActive
+
+	return r.saveWorkflow(workflow, now)
+}
+
+// UpdateWorkflow saves the workflow as its next version and records the
+// version in the workflow's history
+func (r *MemoryRepository) UpdateWorkflow(ctx context.Context, workflow *models.Workflow) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.workflow(ctx, workflow.ID)
+	if !ok {
+		return ErrWorkflowNotFound
+	}
+
+	// As with DB, the version comes from the store and the status only
+	// changes through SetWorkflowStatus
+	workflow.UpdatedAt = time.Now()
+	workflow.Version = stored.Version + 1
+	workflow.TenantID = stored.TenantID
+	workflow.Status = stored.Status
+	workflow.IsActive = stored.IsActive
+
+	return r.saveWorkflow(workflow, stored.CreatedAt)
+}
+
+// DeleteWorkflow soft-deletes a workflow
+func (r *MemoryRepository) DeleteWorkflow(ctx context.Context, id uuid.UUID) error {
+	return r.SetWorkflowStatus(ctx, id, models.WorkflowStatusDeleted)
+}
+
+// SetWorkflowStatus moves a workflow to status and keeps IsActive in sync
+func (r *MemoryRepository) SetWorkflowStatus(ctx context.Context, id uuid.UUID, status models.WorkflowStatus) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	workflow, ok := r.workflow(ctx, id)
+	if !ok {
+		return ErrWorkflowNotFound
+	}
+	workflow.Status = status
+	workflow.IsActive = status == models.WorkflowStatusActive
+	workflow.UpdatedAt = time.Now()
+	return nil
+}
+
+// ListWorkflowVersions returns the recorded versions of a workflow, newest
+// first, without their definitions
+func (r *MemoryRepository) ListWorkflowVersions(ctx context.Context, workflowID uuid.UUID) ([]models.WorkflowVersion, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	versions := []models.WorkflowVersion{}
+	if _, ok := r.workflow(ctx, workflowID); !ok {
+		return versions, nil
+	}
+	history := r.versions[workflowID]
+	for i := len(history) - 1; i >= 0; i-- {
+		version := history[i]
+		version.Definition = models.WorkflowDefinition{}
+		versions = append(versions, version)
+	}
+	return versions, nil
+}
+
+// GetWorkflowVersion returns a version of a workflow
+func (r *MemoryRepository) GetWorkflowVersion(ctx context.Context, workflowID uuid.UUID, version int) (*models.WorkflowVersion, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if _, ok := r.workflow(ctx, workflowID); !ok {
+		return nil, ErrWorkflowVersionNotFound
+	}
+	for _, recorded := range r.versions[workflowID] {
+		if recorded.Version == version {
+			var copied models.WorkflowVersion
+			if err := copyJSON(recorded, &copied); err != nil {
+				return nil, fmt.Errorf("failed to copy workflow version: %w", err)
+			}
+			return &copied, nil
+		}
+	}
+	return nil, ErrWorkflowVersionNotFound
+}
+
+func (r *MemoryRepository) execution(ctx context.Context, id uuid.UUID) (*memoryExecution, bool) {
+	stored, ok := r.executions[id]
+	if !ok || !inTenant(ctx, stored.execution.TenantID) {
+		return nil, false
+	}
+	return stored, true
+}
+
+func copyExecution(execution *models.Execution) (models.Execution, error) {
+	var copied models.Execution
+	if err := copyJSON(execution, &copied); err != nil {
+		return models.Execution{}, fmt.Errorf("failed to copy execution: %w", err)
+	}
+	// Like the database, snapshots are only read through GetNodeSnapshot
+	for nodeID, run := range copied.Context.NodeExecutions {
+		run.Snapshot = nil
+		copied.Context.NodeExecutions[nodeID] = run
+	}
+	return copied, nil
+}
+
+// CreateExecution stores a new execution started now
+func (r *MemoryRepository) CreateExecution(ctx context.Context, execution *models.Execution) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if execution.ID == uuid.Nil {
+		execution.ID = uuid.New()
+	}
+	if _, exists := r.executions[execution.ID]; exists {
+		return fmt.Errorf("execution %s already exists", execution.ID)
+	}
+
+	execution.StartedAt = time.Now()
+	if execution.TenantID == uuid.Nil {
+		execution.TenantID = tenant.IDOrDefault(ctx)
+	}
+
+	stored, err := copyExecution(execution)
+	if err != nil {
+		return err
+	}
+	r.executions[execution.ID] = &memoryExecution{execution: stored, journal: make(map[string]interface{})}
+	return nil
+}
+
+// UpdateExecution saves the execution's progress and runs, the node runs
+// not yet saved. An execution carrying a claim token is only written while
+// that token is the latest one.
+func (r *MemoryRepository) UpdateExecution(ctx context.Context, execution *models.Execution, runs ...models.NodeExecution) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.execution(ctx, execution.ID)
+	if execution.ClaimToken > 0 && (!ok || stored.claimToken != execution.ClaimToken) {
+		return ErrExecutionFenced
+	}
+	if !ok {
+		return nil
+	}
+
+	copied, err := copyExecution(execution)
+	if err != nil {
+		return err
+	}
+	stored.execution.Status = copied.Status
+	stored.execution.Output = copied.Output
+	stored.execution.Error = copied.Error
+	stored.execution.CompletedAt = copied.CompletedAt
+	stored.execution.Metadata = copied.Metadata
+
+	// As in node_executions, runs saved earlier are kept
+	saved := stored.execution.Context.NodeExecutions
+	stored.execution.Context = copied.Context
+	stored.execution.Context.NodeExecutions = saved
+	return stored.saveNodeExecutions(runs)
+}
+
+// saveNodeExecutions records copies of node runs on the execution
+func (s *memoryExecution) saveNodeExecutions(runs []models.NodeExecution) error {
+	if len(runs) == 0 {
+		return nil
+	}
+	if s.execution.Context.NodeExecutions == nil {
+		s.execution.Context.NodeExecutions = make(map[string]models.NodeExecution)
+	}
+	for _, run := range runs {
+		var copied models.NodeExecution
+		if err := copyJSON(run, &copied); err != nil {
+			return fmt.Errorf("failed to copy node execution: %w", err)
+		}
+		s.execution.Context.NodeExecutions[run.NodeID] = copied
+	}
+	return nil
+}
+
+// SaveNodeExecutions records node runs of an execution, replacing earlier
+// records of the same nodes
+func (r *MemoryRepository) SaveNodeExecutions(ctx context.Context, executionID uuid.UUID, token int64, runs []models.NodeExecution) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.executions[executionID]
+	if !ok {
+		return ErrExecutionNotFound
+	}
+	if token > 0 && stored.claimToken != token {
+		return ErrExecutionFenced
+	}
+	return stored.saveNodeExecutions(runs)
+}
+
+// ListNodeExecutions returns the recorded node runs of an execution in the
+// order they started
+func (r *MemoryRepository) ListNodeExecutions(ctx context.Context, executionID uuid.UUID) ([]models.NodeExecution, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	runs := []models.NodeExecution{}
+	stored, ok := r.execution(ctx, executionID)
+	if !ok {
+		return runs, nil
+	}
+	for _, run := range stored.execution.Context.NodeExecutions {
+		var copied models.NodeExecution
+		if err := copyJSON(run, &copied); err != nil {
+			return nil, fmt.Errorf("failed to copy node execution: %w", err)
+		}
+		copied.Snapshot = nil
+		runs = append(runs, copied)
+	}
+	sort.Slice(runs, func(i, j int) bool {
+		if !runs[i].StartedAt.Equal(runs[j].StartedAt) {
+			return runs[i].StartedAt.Before(runs[j].StartedAt)
+		}
+		return runs[i].NodeID < runs[j].NodeID
+	})
+	return runs, nil
+}
+
+// GetNodeSnapshot returns what a node of an execution saw when it last ran
+func (r *MemoryRepository) GetNodeSnapshot(ctx context.Context, executionID uuid.UUID, nodeID string) (*models.NodeSnapshot, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	stored, ok := r.execution(ctx, executionID)
+	if !ok {
+		return nil, ErrNodeSnapshotNotFound
+	}
+	run, ok := stored.execution.Context.NodeExecutions[nodeID]
+	if !ok || run.Snapshot == nil {
+		return nil, ErrNodeSnapshotNotFound
+	}
+	var snapshot models.NodeSnapshot
+	if err := copyJSON(run.Snapshot, &snapshot); err != nil {
+		return nil, fmt.Errorf("failed to copy node snapshot: %w", err)
+	}
+	return &snapshot, nil
+}
+
+// GetExecution returns an execution by ID
+func (r *MemoryRepository) GetExecution(ctx context.Context, id uuid.UUID) (*models.Execution, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	stored, ok := r.execution(ctx, id)
+	if !ok {
+		return nil, ErrExecutionNotFound
+	}
+	copied, err := copyExecution(&stored.execution)
+	if err != nil {
+		return nil, err
+	}
+	return &copied, nil
+}
+
+// sortedExecutions returns the visible executions accepted by keep, newest
+// first
+func (r *MemoryRepository) sortedExecutions(ctx context.Context, keep func(*models.Execution) bool) []*models.Execution {
+	executions := []*models.Execution{}
+	for _, stored := range r.executions {
+		if inTenant(ctx, stored.execution.TenantID) && keep(&stored.execution) {
+			executions = append(executions, &stored.execution)
+		}
+	}
+	sort.Slice(executions, func(i, j int) bool {
+		a, b := executions[i], executions[j]
+		if !a.StartedAt.Equal(b.StartedAt) {
+			return a.StartedAt.After(b.StartedAt)
+		}
+		return a.ID.String() > b.ID.String()
+	})
+	return executions
+}
+
+// GetExecutions retrieves executions with optional filtering
+func (r *MemoryRepository) GetExecutions(ctx context.Context, workflowID *uuid.UUID, status *models.ExecutionStatus, limit int) ([]models.Execution, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	matches := r.sortedExecutions(ctx, func(execution *models.Execution) bool {
+		return (workflowID == nil || execution.WorkflowID == *workflowID) &&
+			(status == nil || execution.Status == *status)
+	})
+	if limit > 0 && len(matches) > limit {
+		matches = matches[:limit]
+	}
+
+	var executions []models.Execution
+	for _, execution := range matches {
+		copied, err := copyExecution(execution)
+		if err != nil {
+			return nil, err
+		}
+		executions = append(executions, copied)
+	}
+	return executions, nil
+}
+
+// ListExecutions returns executions newest first, paged with the same
+// cursors as DB
+func (r *MemoryRepository) ListExecutions(ctx context.Context, opts ExecutionListOptions) (*ExecutionPage, error) {
+	var cursorTime time.Time
+	var cursorID uuid.UUID
+	if opts.Cursor != "" {
+		var err error
+		if cursorTime, cursorID, err = DecodeExecutionCursor(opts.Cursor); err != nil {
+			return nil, err
+		}
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	matches := r.sortedExecutions(ctx, func(execution *models.Execution) bool {
+		if opts.WorkflowID != nil && execution.WorkflowID != *opts.WorkflowID {
+			return false
+		}
+		if len(opts.Statuses) > 0 && !hasExecutionStatus(opts.Statuses, execution.Status) {
+			return false
+		}
+		if opts.StartedAfter != nil && execution.StartedAt.Before(*opts.StartedAfter) {
+			return false
+		}
+		return opts.StartedBefore == nil || execution.StartedAt.Before(*opts.StartedBefore)
+	})
+
+	// The total covers every page, so it ignores the cursor
+	page := &ExecutionPage{Executions: []models.Execution{}, Total: len(matches)}
+	for _, execution := range matches {
+		if opts.Cursor != "" && !execution.StartedAt.Before(cursorTime) &&
+			!(execution.StartedAt.Equal(cursorTime) && execution.ID.String() < cursorID.String()) {
+			continue
+		}
+		if opts.Limit > 0 && len(page.Executions) == opts.Limit {
+			last := page.Executions[opts.Limit-1]
+			page.NextCursor = EncodeExecutionCursor(last.StartedAt, last.ID)
+			break
+		}
+
+		copied, err := copyExecution(execution)
+		if err != nil {
+			return nil, err
+		}
+		if opts.Summary {
+			copied.Input, copied.Output, copied.Context = nil, nil, models.ExecutionContext{}
+		}
+		page.Executions = append(page.Executions, copied)
+	}
+	return page, nil
+}
+
+func hasExecutionStatus(statuses []models.ExecutionStatus, status models.ExecutionStatus) bool {
+	for _, s := range statuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
+// activeExecution reports whether an execution with status is pending or
+// running
+func activeExecution(status models.ExecutionStatus) bool {
+	return status == models.ExecutionStatusPending || status == models.ExecutionStatusRunning
+}
+
+// ListActiveExecutions returns the pending and running executions of the
+// workflow, oldest first
+func (r *MemoryRepository) ListActiveExecutions(ctx context.Context, workflowID uuid.UUID) ([]models.ExecutionSummary, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	matches := r.sortedExecutions(ctx, func(execution *models.Execution) bool {
+		return execution.WorkflowID == workflowID && activeExecution(execution.Status)
+	})
+
+	var executions []models.ExecutionSummary
+	for i := len(matches) - 1; i >= 0; i-- {
+		executions = append(executions, models.ExecutionSummary{
+			ID:         matches[i].ID,
+			WorkflowID: workflowID,
+			Status:     matches[i].Status,
+			StartedAt:  matches[i].StartedAt,
+		})
+	}
+	return executions, nil
+}
+
+// CancelExecution marks a pending or running execution cancelled and
+// reports whether it did, fencing off the worker running it
+func (r *MemoryRepository) CancelExecution(ctx context.Context, id uuid.UUID, reason string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.execution(ctx, id)
+	if !ok || !activeExecution(stored.execution.Status) {
+		return false, nil
+	}
+	now := time.Now()
+	stored.execution.Status = models.ExecutionStatusCancelled
+	stored.execution.Error = &reason
+	stored.execution.CompletedAt = &now
+	stored.claimToken++
+	stored.claimedBy = ""
+	return true, nil
+}
+
+// ClaimExecution takes ownership of a pending or running execution for
+// owner, marks it running, and returns its new fencing token
+func (r *MemoryRepository) ClaimExecution(ctx context.Context, id uuid.UUID, owner string) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.execution(ctx, id)
+	if !ok || !activeExecution(stored.execution.Status) {
+		return 0, ErrExecutionNotClaimable
+	}
+	stored.claimToken++
+	stored.claimedBy = owner
+	stored.execution.Status = models.ExecutionStatusRunning
+	return stored.claimToken, nil
+}
+
+// RequeueExecution returns a running execution claimed by owner to pending
+// and reports whether it did
+func (r *MemoryRepository) RequeueExecution(ctx context.Context, id uuid.UUID, owner string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.executions[id]
+	if !ok || stored.execution.Status != models.ExecutionStatusRunning || stored.claimedBy != owner {
+		return false, nil
+	}
+	stored.execution.Status = models.ExecutionStatusPending
+	stored.claimedBy = ""
+	return true, nil
+}
+
+// ListClaimedExecutions returns the running executions claimed by workers,
+// across tenants
+func (r *MemoryRepository) ListClaimedExecutions(ctx context.Context) ([]ClaimedExecution, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var executions []ClaimedExecution
+	for _, stored := range r.executions {
+		if stored.execution.Status != models.ExecutionStatusRunning || stored.claimedBy == "" {
+			continue
+		}
+		claimed := ClaimedExecution{
+			ID:         stored.execution.ID,
+			WorkflowID: stored.execution.WorkflowID,
+			TenantID:   stored.execution.TenantID,
+			ClaimedBy:  stored.claimedBy,
+		}
+		if err := copyJSON(stored.execution.Input, &claimed.Input); err != nil {
+			return nil, fmt.Errorf("failed to copy input: %w", err)
+		}
+		if err := copyJSON(stored.execution.Metadata, &claimed.Metadata); err != nil {
+			return nil, fmt.Errorf("failed to copy metadata: %w", err)
+		}
+		executions = append(executions, claimed)
+	}
+	return executions, nil
+}
+
+// AppendJournal records the output of a completed node of an execution
+// claimed with token, failing with ErrExecutionFenced if the execution has
+// been claimed again since
+func (r *MemoryRepository) AppendJournal(ctx context.Context, executionID uuid.UUID, token int64, nodeID string, output interface{}) error {
+	var copied interface{}
+	if err := copyJSON(output, &copied); err != nil {
+		return fmt.Errorf("failed to marshal output: %w", err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.executions[executionID]
+	if !ok || stored.claimToken != token {
+		return ErrExecutionFenced
+	}
+	stored.journal[nodeID] = copied
+	return nil
+}
+
+// GetJournal returns the outputs of the execution's completed nodes by
+// node ID
+func (r *MemoryRepository) GetJournal(ctx context.Context, executionID uuid.UUID) (map[string]interface{}, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	outputs := make(map[string]interface{})
+	if stored, ok := r.executions[executionID]; ok {
+		if err := copyJSON(stored.journal, &outputs); err != nil {
+			return nil, fmt.Errorf("failed to get journal: %w", err)
+		}
+	}
+	return outputs, nil
+}
+
+func (r *MemoryRepository) environment(ctx context.Context, name string) (*memoryEnvironment, bool) {
+	for i := range r.environments {
+		if r.environments[i].environment.Name == name && inTenant(ctx, r.environments[i].tenantID) {
+			return &r.environments[i], true
+		}
+	}
+	return nil, false
+}
+
+// CreateEnvironment stores a new environment in the context's tenant
+func (r *MemoryRepository) CreateEnvironment(ctx context.Context, environment *models.Environment) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.environment(ctx, environment.Name); exists {
+		return ErrEnvironmentExists
+	}
+	environment.ID = uuid.New()
+	environment.CreatedAt = time.Now()
+	r.environments = append(r.environments, memoryEnvironment{tenantID: tenant.IDOrDefault(ctx), environment: *environment})
+	return nil
+}
+
+// GetEnvironmentByName retrieves an environment by name
+func (r *MemoryRepository) GetEnvironmentByName(ctx context.Context, name string) (*models.Environment, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	stored, ok := r.environment(ctx, name)
+	if !ok {
+		return nil, ErrEnvironmentNotFound
+	}
+	environment := stored.environment
+	return &environment, nil
+}
+
+// ListEnvironments returns the environments of the context's tenant ordered
+// by name
+func (r *MemoryRepository) ListEnvironments(ctx context.Context) ([]models.Environment, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	environments := []models.Environment{}
+	for _, stored := range r.environments {
+		if inTenant(ctx, stored.tenantID) {
+			environments = append(environments, stored.environment)
+		}
+	}
+	sort.Slice(environments, func(i, j int) bool { return environments[i].Name < environments[j].Name })
+	return environments, nil
+}
+
+// DeleteEnvironment removes an environment and the deployments to it
+func (r *MemoryRepository) DeleteEnvironment(ctx context.Context, name string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.environment(ctx, name)
+	if !ok {
+		return ErrEnvironmentNotFound
+	}
+	id := stored.environment.ID
+
+	environments := r.environments[:0]
+	for _, environment := range r.environments {
+		if environment.environment.ID != id {
+			environments = append(environments, environment)
+		}
+	}
+	r.environments = environments
+
+	deployments := r.deployments[:0]
+	for _, deployment := range r.deployments {
+		if deployment.environmentID != id {
+			deployments = append(deployments, deployment)
+		}
+	}
+	r.deployments = deployments
+	return nil
+}
+
+// SaveDeployment deploys a workflow version to an environment, replacing
+// the previous deployment there
+func (r *MemoryRepository) SaveDeployment(ctx context.Context, deployment *models.Deployment) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	environment, ok := r.environment(ctx, deployment.Environment)
+	if !ok {
+		return ErrEnvironmentNotFound
+	}
+
+	stored := memoryDeployment{tenantID: tenant.IDOrDefault(ctx), environmentID: environment.environment.ID}
+	if err := copyJSON(deployment, &stored.deployment); err != nil {
+		return fmt.Errorf("failed to copy deployment: %w", err)
+	}
+	for i, existing := range r.deployments {
+		if existing.deployment.WorkflowID == deployment.WorkflowID && existing.environmentID == stored.environmentID {
+			r.deployments[i] = stored
+			return nil
+		}
+	}
+	r.deployments = append(r.deployments, stored)
+	return nil
+}
+
+// GetDeployment returns the deployment of a workflow to an environment
+func (r *MemoryRepository) GetDeployment(ctx context.Context, workflowID uuid.UUID, environment string) (*models.Deployment, error) {
+	deployments, err := r.ListDeployments(ctx, workflowID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range deployments {
+		if deployments[i].Environment == environment {
+			return &deployments[i], nil
+		}
+	}
+	return nil, ErrDeploymentNotFound
+}
+
+// ListDeployments returns the deployments of a workflow ordered by
+// environment
+func (r *MemoryRepository) ListDeployments(ctx context.Context, workflowID uuid.UUID) ([]models.Deployment, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	deployments := []models.Deployment{}
+	for _, stored := range r.deployments {
+		if stored.deployment.WorkflowID != workflowID || !inTenant(ctx, stored.tenantID) {
+			continue
+		}
+		var deployment models.Deployment
+		if err := copyJSON(stored.deployment, &deployment); err != nil {
+			return nil, fmt.Errorf("failed to copy deployment: %w", err)
+		}
+		deployments = append(deployments, deployment)
+	}
+	sort.Slice(deployments, func(i, j int) bool { return deployments[i].Environment < deployments[j].Environment })
+	return deployments, nil
+}
+
+// DeleteDeployment removes a workflow from an environment
+func (r *MemoryRepository) DeleteDeployment(ctx context.Context, workflowID uuid.UUID, environment string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	env, ok := r.environment(ctx, environment)
+	if !ok {
+		return ErrEnvironmentNotFound
+	}
+	for i, stored := range r.deployments {
+		if stored.deployment.WorkflowID == workflowID && stored.environmentID == env.environment.ID {
+			r.deployments = append(r.deployments[:i], r.deployments[i+1:]...)
+			return nil
+		}
+	}
+	return ErrDeploymentNotFound
+}
+
+// CreateExecutionBatch stores a new batch of the workflow, assigning its ID
+// when unset, and sets its CreatedAt
+func (r *MemoryRepository) CreateExecutionBatch(ctx context.Context, batch *models.ExecutionBatch) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.workflow(ctx, batch.WorkflowID); !ok {
+		return ErrWorkflowNotFound
+	}
+	if batch.ID == uuid.Nil {
+		batch.ID = uuid.New()
+	}
+	if _, exists := r.batches[batch.ID]; exists {
+		return fmt.Errorf("execution batch %s already exists", batch.ID)
+	}
+	batch.CreatedAt = time.Now().UTC()
+
+	stored := *batch
+	stored.Total, stored.Counts, stored.Done = 0, nil, false
+	r.batches[batch.ID] = &memoryBatch{batch: stored}
+	return nil
+}
+
+// GetExecutionBatch returns a batch with its executions counted by status
+func (r *MemoryRepository) GetExecutionBatch(ctx context.Context, id uuid.UUID) (*models.ExecutionBatch, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	stored, ok := r.batches[id]
+	if !ok {
+		return nil, ErrBatchNotFound
+	}
+	if _, ok := r.workflow(ctx, stored.batch.WorkflowID); !ok {
+		return nil, ErrBatchNotFound
+	}
+
+	batch := stored.batch
+	batch.Counts = make(map[models.ExecutionStatus]int)
+	for _, executionID := range stored.executions {
+		if execution, ok := r.executions[executionID]; ok {
+			batch.Counts[execution.execution.Status]++
+			batch.Total++
+		}
+	}
+	batch.CountDone()
+	return &batch, nil
+}
+
+// AddBatchExecutions adds existing executions to a batch
+func (r *MemoryRepository) AddBatchExecutions(ctx context.Context, batchID uuid.UUID, executionIDs []uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.batches[batchID]
+	if !ok {
+		return ErrBatchNotFound
+	}
+	for _, executionID := range executionIDs {
+		if _, ok := r.executions[executionID]; !ok {
+			return fmt.Errorf("failed to add execution %s to batch: %w", executionID, ErrExecutionNotFound)
+		}
+	}
+	stored.executions = append(stored.executions, executionIDs...)
+	return nil
+}
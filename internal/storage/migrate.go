@@ -0,0 +1,332 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/nuumz/f1ow/migrations"
+)
+
+// ErrUntrackedSchema is returned when migrating a database whose tables
+// were created without recording migrations, such as by an init script
+var ErrUntrackedSchema = errors.New("database has tables but no migration history; record the applied migrations with `migrate baseline <version>`")
+
+// migrationLockID keys the PostgreSQL advisory lock held while migrating
+const migrationLockID = 0x66316f77 // "f1ow"
+
+var migrationFile = regexp.MustCompile(`^(\d+)_([a-z0-9_]+)\.sql$`)
+
+// Migration is a versioned schema change
+type Migration struct {
+	Version int
+	Name    string
+	SQL     string
+}
+
+// MigrationStatus reports whether a migration has been applied
+type MigrationStatus struct {
+	Version   int        `json:"version"`
+	Name      string     `json:"name"`
+	AppliedAt *time.Time `json:"applied_at,omitempty"`
+}
+
+// LoadMigrations reads the NNN_name.sql files in dir of fsys, ordered by
+// version
+func LoadMigrations(fsys fs.FS, dir string) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migrations: %w", err)
+	}
+
+	var list []Migration
+	seen := make(map[int]string)
+	for _, entry := range entries {
+		match := migrationFile.FindStringSubmatch(entry.Name())
+		if entry.IsDir() || match == nil {
+			continue
+		}
+		version, _ := strconv.Atoi(match[1])
+		if other, ok := seen[version]; ok {
+			return nil, fmt.Errorf("migrations %s and %s share version %d", other, entry.Name(), version)
+		}
+		seen[version] = entry.Name()
+
+		data, err := fs.ReadFile(fsys, path.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read migration %s: %w", entry.Name(), err)
+		}
+		list = append(list, Migration{Version: version, Name: match[2], SQL: string(data)})
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Version < list[j].Version })
+	return list, nil
+}
+
+// SplitStatements splits a migration into its statements, which end with
+// a semicolon at the end of a line. Comment lines are dropped.
+func SplitStatements(script string) []string {
+	var statements []string
+	var current strings.Builder
+	for _, line := range strings.Split(script, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "--") {
+			continue
+		}
+		current.WriteString(line)
+		current.WriteString("\n")
+		if strings.HasSuffix(trimmed, ";") {
+			statements = append(statements, strings.TrimSpace(current.String()))
+			current.Reset()
+		}
+	}
+	if rest := strings.TrimSpace(current.String()); rest != "" {
+		statements = append(statements, rest)
+	}
+	return statements
+}
+
+// migrations returns the embedded migrations for the database's driver
+func (db *DB) migrations() ([]Migration, error) {
+	dir := "."
+	if db.isMySQL() {
+		dir = "mysql"
+	} else if db.isSQLite() {
+		dir = "sqlite"
+	}
+	return LoadMigrations(migrations.FS, dir)
+}
+
+// Migrate applies the migrations not yet recorded in schema_migrations, in
+// version order, and returns them. Instances starting together take turns
+// through a database lock. PostgreSQL and SQLite migrations run in a
+// transaction each; MySQL commits DDL as it goes, so a failed MySQL
+// migration may leave part of it applied.
+func (db *DB) Migrate(ctx context.Context) ([]Migration, error) {
+	list, err := db.migrations()
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	unlock, err := db.lockMigrations(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	created, err := db.ensureMigrationTable(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+	applied, err := db.appliedMigrations(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+	if created || len(applied) == 0 {
+		if exists, err := db.tableExists(ctx, conn, "workflows"); err != nil {
+			return nil, err
+		} else if exists {
+			return nil, ErrUntrackedSchema
+		}
+	}
+
+	var ran []Migration
+	for _, migration := range list {
+		if _, ok := applied[migration.Version]; ok {
+			continue
+		}
+		if err := db.applyMigration(ctx, conn, migration); err != nil {
+			return ran, fmt.Errorf("migration %03d_%s failed: %w", migration.Version, migration.Name, err)
+		}
+		ran = append(ran, migration)
+	}
+	return ran, nil
+}
+
+// MigrationStatus lists the embedded migrations and when each was applied
+func (db *DB) MigrationStatus(ctx context.Context) ([]MigrationStatus, error) {
+	list, err := db.migrations()
+	if err != nil {
+		return nil, err
+	}
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	if _, err := db.ensureMigrationTable(ctx, conn); err != nil {
+		return nil, err
+	}
+	applied, err := db.appliedMigrations(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]MigrationStatus, 0, len(list))
+	for _, migration := range list {
+		status := MigrationStatus{Version: migration.Version, Name: migration.Name}
+		if at, ok := applied[migration.Version]; ok {
+			status.AppliedAt = &at
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
+
+// BaselineMigrations records the migrations up to version as applied
+// without running them, for databases whose schema was created another
+// way
+func (db *DB) BaselineMigrations(ctx context.Context, version int) error {
+	list, err := db.migrations()
+	if err != nil {
+		return err
+	}
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if _, err := db.ensureMigrationTable(ctx, conn); err != nil {
+		return err
+	}
+	applied, err := db.appliedMigrations(ctx, conn)
+	if err != nil {
+		return err
+	}
+	for _, migration := range list {
+		if _, ok := applied[migration.Version]; ok || migration.Version > version {
+			continue
+		}
+		if err := db.recordMigration(ctx, conn, migration); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (db *DB) applyMigration(ctx context.Context, conn *sql.Conn, migration Migration) error {
+	if db.isMySQL() {
+		for _, statement := range SplitStatements(migration.SQL) {
+			if _, err := conn.ExecContext(ctx, statement); err != nil {
+				return err
+			}
+		}
+		return db.recordMigration(ctx, conn, migration)
+	}
+
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if _, err := tx.ExecContext(ctx, migration.SQL); err != nil {
+		return err
+	}
+	query := fmt.Sprintf(`INSERT INTO schema_migrations (version, name, applied_at) VALUES (%s, %s, %s)`,
+		db.placeholder(1), db.placeholder(2), db.placeholder(3))
+	if _, err := tx.ExecContext(ctx, query, migration.Version, migration.Name, time.Now()); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (db *DB) recordMigration(ctx context.Context, conn *sql.Conn, migration Migration) error {
+	query := fmt.Sprintf(`INSERT INTO schema_migrations (version, name, applied_at) VALUES (%s, %s, %s)`,
+		db.placeholder(1), db.placeholder(2), db.placeholder(3))
+	_, err := conn.ExecContext(ctx, query, migration.Version, migration.Name, time.Now())
+	return err
+}
+
+// ensureMigrationTable creates schema_migrations if it is missing and
+// reports whether it did
+func (db *DB) ensureMigrationTable(ctx context.Context, conn *sql.Conn) (bool, error) {
+	exists, err := db.tableExists(ctx, conn, "schema_migrations")
+	if err != nil || exists {
+		return false, err
+	}
+	_, err = conn.ExecContext(ctx, `
+        CREATE TABLE IF NOT EXISTS schema_migrations (
+            version INTEGER PRIMARY KEY,
+            name VARCHAR(255) NOT NULL,
+            applied_at TIMESTAMP NOT NULL
+        )`)
+	if err != nil {
+		return false, fmt.Errorf("failed to create schema_migrations: %w", err)
+	}
+	return true, nil
+}
+
+func (db *DB) appliedMigrations(ctx context.Context, conn *sql.Conn) (map[int]time.Time, error) {
+	rows, err := conn.QueryContext(ctx, `SELECT version, applied_at FROM schema_migrations`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read schema_migrations: %w", err)
+	}
+	defer rows.Close()
+
+	applied := make(map[int]time.Time)
+	for rows.Next() {
+		var version int
+		var at time.Time
+		if err := rows.Scan(&version, &at); err != nil {
+			return nil, err
+		}
+		applied[version] = at
+	}
+	return applied, rows.Err()
+}
+
+func (db *DB) tableExists(ctx context.Context, conn *sql.Conn, table string) (bool, error) {
+	query := fmt.Sprintf(`SELECT COUNT(*) FROM information_schema.tables WHERE table_name = %s`, db.placeholder(1))
+	if db.isSQLite() {
+		query = fmt.Sprintf(`SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = %s`, db.placeholder(1))
+	} else if db.isMySQL() {
+		query += " AND table_schema = DATABASE()"
+	} else {
+		query += " AND table_schema = current_schema()"
+	}
+
+	var count int
+	if err := conn.QueryRowContext(ctx, query, table).Scan(&count); err != nil {
+		return false, fmt.Errorf("failed to look up table %s: %w", table, err)
+	}
+	return count > 0, nil
+}
+
+// lockMigrations holds a database lock until the returned function is
+// called, so only one instance migrates at a time
+func (db *DB) lockMigrations(ctx context.Context, conn *sql.Conn) (func(), error) {
+	if db.isSQLite() {
+		// A SQLite database belongs to a single process
+		return func() {}, nil
+	}
+	if db.isMySQL() {
+		var acquired sql.NullInt64
+		if err := conn.QueryRowContext(ctx, `SELECT GET_LOCK('f1ow_migrations', 300)`).Scan(&acquired); err != nil {
+			return nil, fmt.Errorf("failed to lock migrations: %w", err)
+		}
+		if acquired.Int64 != 1 {
+			return nil, fmt.Errorf("timed out waiting for another instance to finish migrating")
+		}
+		return func() { conn.ExecContext(context.Background(), `SELECT RELEASE_LOCK('f1ow_migrations')`) }, nil
+	}
+
+	if _, err := conn.ExecContext(ctx, `SELECT pg_advisory_lock($1)`, migrationLockID); err != nil {
+		return nil, fmt.Errorf("failed to lock migrations: %w", err)
+	}
+	return func() { conn.ExecContext(context.Background(), `SELECT pg_advisory_unlock($1)`, migrationLockID) }, nil
+}
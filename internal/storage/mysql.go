@@ -0,0 +1,187 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/jmoiron/sqlx"
+)
+
+// mysqlDriverName is the wrapped MySQL driver registered by this package
+const mysqlDriverName = "f1ow-mysql"
+
+func init() {
+	sql.Register(mysqlDriverName, &mysqlDriver{})
+	sqlx.BindDriver(mysqlDriverName, sqlx.QUESTION)
+}
+
+// rewriteMySQL replaces the $N placeholders in query with ?, which MySQL
+// binds in order of appearance, and returns the argument ordinal each ?
+// binds. $N inside quoted literals is left alone. The ordinals are nil when
+// the query has no $N placeholders, so queries written with ? pass through.
+func rewriteMySQL(query string) (string, []int) {
+	if !strings.Contains(query, "$") {
+		return query, nil
+	}
+
+	var b strings.Builder
+	var ordinals []int
+	var quote byte
+	for i := 0; i < len(query); i++ {
+		c := query[i]
+		switch {
+		case quote != 0:
+			if c == '\\' && i+1 < len(query) {
+				b.WriteByte(c)
+				i++
+				c = query[i]
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"' || c == '`':
+			quote = c
+		case c == '$' && i+1 < len(query) && isDigit(query[i+1]):
+			end := i + 1
+			for end < len(query) && isDigit(query[end]) {
+				end++
+			}
+			n, _ := strconv.Atoi(query[i+1 : end])
+			ordinals = append(ordinals, n)
+			b.WriteByte('?')
+			i = end - 1
+			continue
+		}
+		b.WriteByte(c)
+	}
+	return b.String(), ordinals
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+// bindMySQL orders args for the ? placeholders rewriteMySQL produced,
+// repeating arguments whose $N appeared more than once
+func bindMySQL(args []driver.NamedValue, ordinals []int) ([]driver.NamedValue, error) {
+	if ordinals == nil {
+		return args, nil
+	}
+
+	bound := make([]driver.NamedValue, len(ordinals))
+	for i, n := range ordinals {
+		if n < 1 || n > len(args) {
+			return nil, fmt.Errorf("query uses $%d but has %d arguments", n, len(args))
+		}
+		bound[i] = driver.NamedValue{Ordinal: i + 1, Value: args[n-1].Value}
+	}
+	return bound, nil
+}
+
+// mysqlDriver wraps the MySQL driver so queries written with PostgreSQL's
+// $N placeholders run unchanged. The MySQL driver is held rather than
+// embedded: embedding would promote its OpenConnector, and database/sql
+// would open connections through it instead of Open.
+type mysqlDriver struct {
+	base mysql.MySQLDriver
+}
+
+func (d *mysqlDriver) Open(dsn string) (driver.Conn, error) {
+	conn, err := d.base.Open(dsn)
+	if err != nil {
+		return nil, err
+	}
+	return &mysqlConn{conn}, nil
+}
+
+// mysqlConn forwards to the MySQL connection, rewriting queries on the way
+type mysqlConn struct {
+	driver.Conn
+}
+
+func (c *mysqlConn) Prepare(query string) (driver.Stmt, error) {
+	return c.PrepareContext(context.Background(), query)
+}
+
+func (c *mysqlConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	rewritten, ordinals := rewriteMySQL(query)
+	stmt, err := c.Conn.(driver.ConnPrepareContext).PrepareContext(ctx, rewritten)
+	if err != nil {
+		return nil, err
+	}
+	return &mysqlStmt{Stmt: stmt, ordinals: ordinals}, nil
+}
+
+func (c *mysqlConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	rewritten, ordinals := rewriteMySQL(query)
+	bound, err := bindMySQL(args, ordinals)
+	if err != nil {
+		return nil, err
+	}
+	return c.Conn.(driver.ExecerContext).ExecContext(ctx, rewritten, bound)
+}
+
+func (c *mysqlConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	rewritten, ordinals := rewriteMySQL(query)
+	bound, err := bindMySQL(args, ordinals)
+	if err != nil {
+		return nil, err
+	}
+	return c.Conn.(driver.QueryerContext).QueryContext(ctx, rewritten, bound)
+}
+
+func (c *mysqlConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	return c.Conn.(driver.ConnBeginTx).BeginTx(ctx, opts)
+}
+
+func (c *mysqlConn) Ping(ctx context.Context) error {
+	return c.Conn.(driver.Pinger).Ping(ctx)
+}
+
+func (c *mysqlConn) ResetSession(ctx context.Context) error {
+	return c.Conn.(driver.SessionResetter).ResetSession(ctx)
+}
+
+func (c *mysqlConn) IsValid() bool {
+	return c.Conn.(driver.Validator).IsValid()
+}
+
+func (c *mysqlConn) CheckNamedValue(nv *driver.NamedValue) error {
+	return c.Conn.(driver.NamedValueChecker).CheckNamedValue(nv)
+}
+
+// mysqlStmt binds the arguments of a prepared statement in the order its
+// rewritten placeholders expect
+type mysqlStmt struct {
+	driver.Stmt
+	ordinals []int
+}
+
+// NumInput returns -1 so database/sql leaves the argument count to
+// bindMySQL, since a repeated $N takes one argument but two placeholders
+func (s *mysqlStmt) NumInput() int {
+	if s.ordinals == nil {
+		return s.Stmt.NumInput()
+	}
+	return -1
+}
+
+func (s *mysqlStmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	bound, err := bindMySQL(args, s.ordinals)
+	if err != nil {
+		return nil, err
+	}
+	return s.Stmt.(driver.StmtExecContext).ExecContext(ctx, bound)
+}
+
+func (s *mysqlStmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	bound, err := bindMySQL(args, s.ordinals)
+	if err != nil {
+		return nil, err
+	}
+	return s.Stmt.(driver.StmtQueryContext).QueryContext(ctx, bound)
+}
@@ -0,0 +1,196 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/nuumz/f1ow/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+// nodeExecutionBatch is the most node runs written by one INSERT
+const nodeExecutionBatch = 100
+
+// SaveNodeExecutions records node runs of an execution in one transaction,
+// replacing earlier records of the same nodes. With a claim token the runs
+// are only written while the token is the execution's latest; otherwise
+// ErrExecutionFenced is returned.
+func (db *DB) SaveNodeExecutions(ctx context.Context, executionID uuid.UUID, token int64, runs []models.NodeExecution) error {
+	if len(runs) == 0 {
+		return nil
+	}
+
+	tx, err := db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if token > 0 {
+		// Lock the execution so a claim cannot slip in before the commit
+		query := fmt.Sprintf(`SELECT claim_token FROM executions WHERE id = %s`, db.placeholder(1))
+		if !db.isSQLite() {
+			query += " FOR UPDATE"
+		}
+		var current int64
+		if err := tx.QueryRowxContext(ctx, query, executionID).Scan(&current); err != nil {
+			return fmt.Errorf("failed to save node executions: %w", err)
+		}
+		if current != token {
+			return ErrExecutionFenced
+		}
+	}
+
+	if err := db.saveNodeExecutions(ctx, tx, executionID, runs); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// saveNodeExecutions writes node runs within tx, a delete and a multi-row
+// insert per batch. A node listed twice keeps its last run.
+func (db *DB) saveNodeExecutions(ctx context.Context, tx *sqlx.Tx, executionID uuid.UUID, runs []models.NodeExecution) error {
+	latest := make(map[string]models.NodeExecution, len(runs))
+	for _, run := range runs {
+		latest[run.NodeID] = run
+	}
+	runs = make([]models.NodeExecution, 0, len(latest))
+	for _, run := range latest {
+		runs = append(runs, run)
+	}
+	sort.Slice(runs, func(i, j int) bool { return runs[i].NodeID < runs[j].NodeID })
+
+	for start := 0; start < len(runs); start += nodeExecutionBatch {
+		batch := runs[start:min(start+nodeExecutionBatch, len(runs))]
+
+		args := []interface{}{executionID}
+		nodes := make([]string, len(batch))
+		for i, run := range batch {
+			args = append(args, run.NodeID)
+			nodes[i] = db.placeholder(len(args))
+		}
+		deleteQuery := fmt.Sprintf(`DELETE FROM node_executions WHERE execution_id = %s AND node_id IN (%s)`,
+			db.placeholder(1), strings.Join(nodes, ", "))
+		if _, err := tx.ExecContext(ctx, deleteQuery, args...); err != nil {
+			return fmt.Errorf("failed to save node executions: %w", err)
+		}
+
+		args = args[:0]
+		rows := make([]string, len(batch))
+		for i, run := range batch {
+			outputJSON, err := json.Marshal(run.Output)
+			if err != nil {
+				return fmt.Errorf("failed to marshal output of node %s: %w", run.NodeID, err)
+			}
+			// MySQL cannot store the zero time in a TIMESTAMP column
+			startedAt := run.StartedAt
+			if startedAt.IsZero() {
+				startedAt = time.Now()
+			}
+
+			var snapshotJSON []byte
+			if run.Snapshot != nil {
+				if snapshotJSON, err = json.Marshal(run.Snapshot); err != nil {
+					return fmt.Errorf("failed to marshal snapshot of node %s: %w", run.NodeID, err)
+				}
+			}
+
+			values := []interface{}{executionID, run.NodeID, run.Status, outputJSON, run.Error,
+				startedAt, run.CompletedAt, run.RetryCount, snapshotJSON}
+			placeholders := make([]string, len(values))
+			for j, value := range values {
+				args = append(args, value)
+				placeholders[j] = db.placeholder(len(args))
+			}
+			rows[i] = "(" + strings.Join(placeholders, ", ") + ")"
+		}
+		insertQuery := `
+        INSERT INTO node_executions (execution_id, node_id, status, output, error, started_at, completed_at, retry_count, snapshot)
+        VALUES ` + strings.Join(rows, ", ")
+		if _, err := tx.ExecContext(ctx, insertQuery, args...); err != nil {
+			return fmt.Errorf("failed to save node executions: %w", err)
+		}
+	}
+	return nil
+}
+
+// ListNodeExecutions returns the recorded node runs of an execution in the
+// order they started
+func (db *DB) ListNodeExecutions(ctx context.Context, executionID uuid.UUID) ([]models.NodeExecution, error) {
+	query := fmt.Sprintf(`
+        SELECT n.node_id, n.status, n.output, n.error, n.started_at, n.completed_at, n.retry_count
+        FROM node_executions n
+        JOIN executions e ON e.id = n.execution_id
+        WHERE n.execution_id = %s`, db.placeholder(1))
+	query, args := db.scopeToTenant(ctx, query, []interface{}{executionID}, "e.tenant_id")
+
+	rows, err := db.QueryxContext(ctx, query+" ORDER BY n.started_at, n.node_id", args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list node executions: %w", err)
+	}
+	defer rows.Close()
+
+	runs := []models.NodeExecution{}
+	for rows.Next() {
+		var run models.NodeExecution
+		var outputJSON []byte
+		if err := rows.Scan(&run.NodeID, &run.Status, &outputJSON, &run.Error, &run.StartedAt,
+			&run.CompletedAt, &run.RetryCount); err != nil {
+			return nil, err
+		}
+		if len(outputJSON) > 0 {
+			if err := json.Unmarshal(outputJSON, &run.Output); err != nil {
+				return nil, fmt.Errorf("failed to parse output of node %s: %w", run.NodeID, err)
+			}
+		}
+		runs = append(runs, run)
+	}
+	return runs, rows.Err()
+}
+
+// GetNodeSnapshot returns what a node of an execution saw when it last ran
+func (db *DB) GetNodeSnapshot(ctx context.Context, executionID uuid.UUID, nodeID string) (*models.NodeSnapshot, error) {
+	query := fmt.Sprintf(`
+        SELECT n.snapshot
+        FROM node_executions n
+        JOIN executions e ON e.id = n.execution_id
+        WHERE n.execution_id = %s AND n.node_id = %s`, db.placeholder(1), db.placeholder(2))
+	query, args := db.scopeToTenant(ctx, query, []interface{}{executionID, nodeID}, "e.tenant_id")
+
+	var snapshotJSON []byte
+	err := db.QueryRowxContext(ctx, query, args...).Scan(&snapshotJSON)
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && len(snapshotJSON) == 0) {
+		return nil, ErrNodeSnapshotNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get node snapshot: %w", err)
+	}
+
+	var snapshot models.NodeSnapshot
+	if err := json.Unmarshal(snapshotJSON, &snapshot); err != nil {
+		return nil, fmt.Errorf("failed to parse snapshot of node %s: %w", nodeID, err)
+	}
+	return &snapshot, nil
+}
+
+// nodeExecutionsOf returns the node runs held in an execution context, and
+// the context without them as it is stored in the executions table
+func nodeExecutionsOf(executionCtx models.ExecutionContext) ([]models.NodeExecution, models.ExecutionContext) {
+	runs := make([]models.NodeExecution, 0, len(executionCtx.NodeExecutions))
+	for nodeID, run := range executionCtx.NodeExecutions {
+		if run.NodeID == "" {
+			run.NodeID = nodeID
+		}
+		runs = append(runs, run)
+	}
+	executionCtx.NodeExecutions = nil
+	return runs, executionCtx
+}
@@ -0,0 +1,174 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/nuumz/f1ow/internal/models"
+
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrPlanNotFound is returned when a plan does not exist
+	ErrPlanNotFound = errors.New("plan not found")
+	// ErrPlanInUse is returned when deleting a plan tenants are on
+	ErrPlanInUse = errors.New("plan is in use by tenants")
+)
+
+// ListPlans returns all plans ordered by name
+func (db *DB) ListPlans(ctx context.Context) ([]models.Plan, error) {
+	rows, err := db.readQueryx(ctx, `SELECT name, description, quotas, created_at, updated_at FROM plans ORDER BY name`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list plans: %w", err)
+	}
+	defer rows.Close()
+
+	plans := []models.Plan{}
+	for rows.Next() {
+		plan, err := scanPlan(rows)
+		if err != nil {
+			return nil, err
+		}
+		plans = append(plans, *plan)
+	}
+	return plans, rows.Err()
+}
+
+// GetPlan returns a plan by name
+func (db *DB) GetPlan(ctx context.Context, name string) (*models.Plan, error) {
+	plan, err := scanPlan(db.QueryRowxContext(ctx, `
+        SELECT name, description, quotas, created_at, updated_at
+        FROM plans
+        WHERE name = $1`, name))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrPlanNotFound
+	}
+	return plan, err
+}
+
+// SavePlan creates a plan, or replaces the description and quotas of the
+// plan with its name
+func (db *DB) SavePlan(ctx context.Context, plan *models.Plan) error {
+	data, err := json.Marshal(plan.Quotas)
+	if err != nil {
+		return err
+	}
+	plan.UpdatedAt = time.Now()
+
+	existing, err := db.GetPlan(ctx, plan.Name)
+	switch {
+	case errors.Is(err, ErrPlanNotFound):
+		plan.CreatedAt = plan.UpdatedAt
+		_, err = db.ExecContext(ctx, `
+            INSERT INTO plans (name, description, quotas, created_at, updated_at)
+            VALUES ($1, $2, $3, $4, $5)`,
+			plan.Name, plan.Description, data, plan.CreatedAt, plan.UpdatedAt)
+	case err == nil:
+		plan.CreatedAt = existing.CreatedAt
+		_, err = db.ExecContext(ctx, `
+            UPDATE plans SET description = $1, quotas = $2, updated_at = $3
+            WHERE name = $4`,
+			plan.Description, data, plan.UpdatedAt, plan.Name)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to save plan: %w", err)
+	}
+	return nil
+}
+
+// DeletePlan deletes a plan no tenant is on
+func (db *DB) DeletePlan(ctx context.Context, name string) error {
+	var tenants int
+	if err := db.QueryRowxContext(ctx, `SELECT COUNT(*) FROM tenants WHERE plan = $1`, name).Scan(&tenants); err != nil {
+		return fmt.Errorf("failed to delete plan: %w", err)
+	}
+	if tenants > 0 {
+		return ErrPlanInUse
+	}
+
+	result, err := db.ExecContext(ctx, `DELETE FROM plans WHERE name = $1`, name)
+	if err != nil {
+		return fmt.Errorf("failed to delete plan: %w", err)
+	}
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		return ErrPlanNotFound
+	}
+	return nil
+}
+
+// CountActiveWorkflows returns how many workflows of a tenant are active.
+// Counts enforce quotas, so they read the primary rather than a replica.
+func (db *DB) CountActiveWorkflows(ctx context.Context, tenantID uuid.UUID) (int, error) {
+	var count int
+	err := db.QueryRowxContext(ctx, `SELECT COUNT(*) FROM workflows WHERE tenant_id = $1 AND status = $2`,
+		tenantID, models.WorkflowStatusActive).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count active workflows: %w", err)
+	}
+	return count, nil
+}
+
+// CountExecutionsSince returns how many executions of a tenant started at
+// or after since
+func (db *DB) CountExecutionsSince(ctx context.Context, tenantID uuid.UUID, since time.Time) (int, error) {
+	var count int
+	err := db.QueryRowxContext(ctx, `SELECT COUNT(*) FROM executions WHERE tenant_id = $1 AND started_at >= $2`,
+		tenantID, since).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count executions: %w", err)
+	}
+	return count, nil
+}
+
+// checkPlan returns ErrPlanNotFound unless name is empty or a plan
+func (db *DB) checkPlan(ctx context.Context, name string) error {
+	if name == "" {
+		return nil
+	}
+	_, err := db.GetPlan(ctx, name)
+	return err
+}
+
+func scanPlan(row interface{ Scan(...interface{}) error }) (*models.Plan, error) {
+	var plan models.Plan
+	var data []byte
+	if err := row.Scan(&plan.Name, &plan.Description, &data, &plan.CreatedAt, &plan.UpdatedAt); err != nil {
+		return nil, err
+	}
+	quotas, err := parseQuotas(data)
+	if err != nil {
+		return nil, err
+	}
+	if quotas != nil {
+		plan.Quotas = *quotas
+	}
+	return &plan, nil
+}
+
+// quotasJSON encodes quotas for their column, or returns nil for none
+func quotasJSON(quotas *models.Quotas) (interface{}, error) {
+	if quotas == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(quotas)
+	if err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+func parseQuotas(data []byte) (*models.Quotas, error) {
+	if len(data) == 0 {
+		return nil, nil
+	}
+	var quotas models.Quotas
+	if err := json.Unmarshal(data, &quotas); err != nil {
+		return nil, fmt.Errorf("failed to parse quotas: %w", err)
+	}
+	return &quotas, nil
+}
@@ -0,0 +1,183 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/nuumz/f1ow/internal/models"
+	"github.com/nuumz/f1ow/internal/tenant"
+
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrProjectNotFound is returned when a project does not exist
+	ErrProjectNotFound = errors.New("project not found")
+	// ErrParentProjectNotFound is returned when parent_id names a missing project
+	ErrParentProjectNotFound = errors.New("parent project not found")
+	// ErrProjectNotEmpty is returned when deleting a project with sub-projects
+	ErrProjectNotEmpty = errors.New("project has sub-projects")
+	// ErrProjectCycle is returned when a parent would make a project its own ancestor
+	ErrProjectCycle = errors.New("project cannot be nested inside itself")
+)
+
+// CreateProject stores a new project
+func (db *DB) CreateProject(ctx context.Context, project *models.Project) error {
+	if project.ID == uuid.Nil {
+		project.ID = uuid.New()
+	}
+	now := time.Now()
+	project.CreatedAt = now
+	project.UpdatedAt = now
+
+	if project.ParentID != nil {
+		if _, err := db.GetProject(ctx, *project.ParentID); err != nil {
+			if errors.Is(err, ErrProjectNotFound) {
+				return ErrParentProjectNotFound
+			}
+			return err
+		}
+	}
+
+	query := fmt.Sprintf(`
+        INSERT INTO projects (id, name, description, parent_id, user_id, created_at, updated_at, tenant_id)
+        VALUES (%s, %s, %s, %s, %s, %s, %s, %s)
+    `, db.placeholder(1), db.placeholder(2), db.placeholder(3), db.placeholder(4),
+		db.placeholder(5), db.placeholder(6), db.placeholder(7), db.placeholder(8))
+
+	_, err := db.ExecContext(ctx, query, project.ID, project.Name, project.Description,
+		project.ParentID, project.UserID, project.CreatedAt, project.UpdatedAt, tenant.IDOrDefault(ctx))
+	return err
+}
+
+// GetProject retrieves a project by ID
+func (db *DB) GetProject(ctx context.Context, id uuid.UUID) (*models.Project, error) {
+	query := fmt.Sprintf(`
+        SELECT id, name, COALESCE(description, ''), parent_id, user_id, created_at, updated_at
+        FROM projects
+        WHERE id = %s`, db.placeholder(1))
+	query, args := db.scopeToTenant(ctx, query, []interface{}{id}, "tenant_id")
+
+	project, err := db.scanProject(db.QueryRowxContext(ctx, query, args...))
+	if err == sql.ErrNoRows {
+		return nil, ErrProjectNotFound
+	}
+	return project, err
+}
+
+// ListProjects returns the projects directly under parentID, or the top-level
+// projects when parentID is nil, ordered by name
+func (db *DB) ListProjects(ctx context.Context, parentID *uuid.UUID) ([]models.Project, error) {
+	query := `
+        SELECT id, name, COALESCE(description, ''), parent_id, user_id, created_at, updated_at
+        FROM projects
+        WHERE parent_id IS NULL`
+	args := []interface{}{}
+	if parentID != nil {
+		query = fmt.Sprintf(`
+        SELECT id, name, COALESCE(description, ''), parent_id, user_id, created_at, updated_at
+        FROM projects
+        WHERE parent_id = %s`, db.placeholder(1))
+		args = append(args, *parentID)
+	}
+	query, args = db.scopeToTenant(ctx, query, args, "tenant_id")
+
+	rows, err := db.QueryxContext(ctx, query+" ORDER BY name", args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	projects := []models.Project{}
+	for rows.Next() {
+		project, err := db.scanProject(rows)
+		if err != nil {
+			return nil, err
+		}
+		projects = append(projects, *project)
+	}
+	return projects, rows.Err()
+}
+
+// UpdateProject renames, describes, or moves a project
+func (db *DB) UpdateProject(ctx context.Context, project *models.Project) error {
+	if project.ParentID != nil {
+		if err := db.checkProjectParent(ctx, project.ID, *project.ParentID); err != nil {
+			return err
+		}
+	}
+
+	project.UpdatedAt = time.Now()
+	query := fmt.Sprintf(`
+        UPDATE projects
+        SET name = %s, description = %s, parent_id = %s, updated_at = %s
+        WHERE id = %s`, db.placeholder(1), db.placeholder(2), db.placeholder(3), db.placeholder(4), db.placeholder(5))
+	query, args := db.scopeToTenant(ctx, query, []interface{}{project.Name, project.Description,
+		project.ParentID, project.UpdatedAt, project.ID}, "tenant_id")
+
+	result, err := db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	if affected, err := result.RowsAffected(); err == nil && affected == 0 {
+		return ErrProjectNotFound
+	}
+	return nil
+}
+
+// DeleteProject removes an empty project. Its workflows become unassigned.
+func (db *DB) DeleteProject(ctx context.Context, id uuid.UUID) error {
+	var children int
+	countQuery, args := db.scopeToTenant(ctx, fmt.Sprintf(`SELECT COUNT(*) FROM projects WHERE parent_id = %s`, db.placeholder(1)),
+		[]interface{}{id}, "tenant_id")
+	if err := db.QueryRowxContext(ctx, countQuery, args...).Scan(&children); err != nil {
+		return err
+	}
+	if children > 0 {
+		return ErrProjectNotEmpty
+	}
+
+	query, args := db.scopeToTenant(ctx, fmt.Sprintf(`DELETE FROM projects WHERE id = %s`, db.placeholder(1)),
+		[]interface{}{id}, "tenant_id")
+	result, err := db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	if affected, err := result.RowsAffected(); err == nil && affected == 0 {
+		return ErrProjectNotFound
+	}
+	return nil
+}
+
+// checkProjectParent verifies the parent exists and is not the project or
+// one of its descendants
+func (db *DB) checkProjectParent(ctx context.Context, projectID, parentID uuid.UUID) error {
+	current := &parentID
+	for current != nil {
+		if *current == projectID {
+			return ErrProjectCycle
+		}
+		parent, err := db.GetProject(ctx, *current)
+		if errors.Is(err, ErrProjectNotFound) {
+			return ErrParentProjectNotFound
+		}
+		if err != nil {
+			return err
+		}
+		current = parent.ParentID
+	}
+	return nil
+}
+
+func (db *DB) scanProject(row rowScanner) (*models.Project, error) {
+	var project models.Project
+	err := row.Scan(&project.ID, &project.Name, &project.Description, &project.ParentID,
+		&project.UserID, &project.CreatedAt, &project.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &project, nil
+}
@@ -0,0 +1,53 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// QueuedJob is a job persisted by the in-process queue so it survives a
+// restart. Payload is the JSON-encoded job.
+type QueuedJob struct {
+	ID        string
+	Queue     string
+	Score     float64
+	Payload   []byte
+	CreatedAt time.Time
+}
+
+// SaveQueuedJob persists a queued job
+func (db *DB) SaveQueuedJob(ctx context.Context, job QueuedJob) error {
+	query := fmt.Sprintf(`
+        INSERT INTO queue_jobs (id, queue, score, payload, created_at)
+        VALUES (%s, %s, %s, %s, %s)
+    `, db.placeholder(1), db.placeholder(2), db.placeholder(3), db.placeholder(4), db.placeholder(5))
+	_, err := db.ExecContext(ctx, query, job.ID, job.Queue, job.Score, job.Payload, job.CreatedAt)
+	return err
+}
+
+// DeleteQueuedJob removes a persisted job once it leaves the queue
+func (db *DB) DeleteQueuedJob(ctx context.Context, id string) error {
+	query := fmt.Sprintf(`DELETE FROM queue_jobs WHERE id = %s`, db.placeholder(1))
+	_, err := db.ExecContext(ctx, query, id)
+	return err
+}
+
+// ListQueuedJobs returns every persisted job
+func (db *DB) ListQueuedJobs(ctx context.Context) ([]QueuedJob, error) {
+	rows, err := db.QueryxContext(ctx, `SELECT id, queue, score, payload, created_at FROM queue_jobs ORDER BY created_at`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	jobs := []QueuedJob{}
+	for rows.Next() {
+		var job QueuedJob
+		if err := rows.Scan(&job.ID, &job.Queue, &job.Score, &job.Payload, &job.CreatedAt); err != nil {
+			return nil, err
+		}
+		jobs = append(jobs, job)
+	}
+	return jobs, rows.Err()
+}
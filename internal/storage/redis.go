@@ -2,7 +2,9 @@ package storage
 
 import (
 	"context"
+	"crypto/tls"
 	"fmt"
+	"net"
 	"net/url"
 	"strconv"
 	"strings"
@@ -34,6 +36,12 @@ type RedisConfig struct {
 
 // NewRedisClient creates a new Redis client with optional Sentinel support
 func NewRedisClient(url string) (*RedisClient, error) {
+	return NewRedisClientWithDialer(context.Background(), url, nil)
+}
+
+// NewRedisClientWithDialer creates a Redis client whose connections are
+// opened with dial, or the default dialer when nil, and pings it with ctx
+func NewRedisClientWithDialer(ctx context.Context, url string, dial func(ctx context.Context, network, addr string) (net.Conn, error)) (*RedisClient, error) {
 	config, err := ParseRedisURL(url)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse redis URL: %w", err)
@@ -50,6 +58,7 @@ func NewRedisClient(url string) (*RedisClient, error) {
 			SentinelPassword: config.SentinelPassword,
 			Password:         config.Password,
 			DB:               config.DB,
+			Dialer:           dial,
 		}
 		failoverClient := redis.NewFailoverClient(sentinelOpt)
 		client = failoverClient
@@ -60,14 +69,17 @@ func NewRedisClient(url string) (*RedisClient, error) {
 		if err != nil {
 			return nil, fmt.Errorf("failed to parse redis URL: %w", err)
 		}
+		if dial != nil {
+			opt.Dialer = tlsDialer(dial, opt.TLSConfig)
+		}
 		redisClient := redis.NewClient(opt)
 		client = redisClient
 		realClient = redisClient
 	}
 
 	// Test connection
-	ctx := context.Background()
 	if err := client.Ping(ctx).Err(); err != nil {
+		realClient.Close()
 		return nil, fmt.Errorf("failed to connect to redis: %w", err)
 	}
 
@@ -77,6 +89,26 @@ func NewRedisClient(url string) (*RedisClient, error) {
 	}, nil
 }
 
+// tlsDialer wraps dial in the TLS handshake the default dialer performs
+// for rediss:// URLs
+func tlsDialer(dial func(ctx context.Context, network, addr string) (net.Conn, error), config *tls.Config) func(ctx context.Context, network, addr string) (net.Conn, error) {
+	if config == nil {
+		return dial
+	}
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		conn, err := dial(ctx, network, addr)
+		if err != nil {
+			return nil, err
+		}
+		tlsConn := tls.Client(conn, config)
+		if err := tlsConn.HandshakeContext(ctx); err != nil {
+			conn.Close()
+			return nil, err
+		}
+		return tlsConn, nil
+	}
+}
+
 // parseRedisURL parses Redis URL and extracts configuration
 func ParseRedisURL(redisURL string) (*RedisConfig, error) {
 	config := &RedisConfig{
@@ -146,10 +178,20 @@ func ParseSentinelURL(redisURL string) (*RedisConfig, error) {
 	return config, nil
 }
 
+// Client returns the underlying client, or nil on a nil RedisClient so
+// callers without Redis can pass it where a client is optional
 func (r *RedisClient) Client() redis.Cmdable {
+	if r == nil {
+		return nil
+	}
 	return r.client
 }
 
+// PoolStats returns the connection pool stats
+func (r *RedisClient) PoolStats() *redis.PoolStats {
+	return r.realClient.PoolStats()
+}
+
 func (r *RedisClient) Ping() error {
 	return r.client.Ping(context.Background()).Err()
 }
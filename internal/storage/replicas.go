@@ -0,0 +1,163 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// replicaPingTimeout bounds each replica health check
+const replicaPingTimeout = 5 * time.Second
+
+// replica is a read-only copy of the primary database
+type replica struct {
+	db      *sqlx.DB
+	healthy atomic.Bool
+}
+
+// replicaSet spreads reads over the healthy replicas in turn
+type replicaSet struct {
+	replicas []*replica
+	next     atomic.Uint64
+}
+
+// ConnectReplicas connects to read replicas of the database. Workflow and
+// execution listings and workflow stats are then read from a healthy
+// replica, and from the primary when none is healthy or the replica fails;
+// every other query runs on the primary. Replicas must run the same
+// database as the primary. Call it before the DB is used.
+func (db *DB) ConnectReplicas(dsns []string) error {
+	set := &replicaSet{}
+	for i, dsn := range dsns {
+		conn, driverName, err := connect(dsn)
+		if err != nil {
+			set.close()
+			return fmt.Errorf("replica %d: %w", i+1, err)
+		}
+		if driverName != db.driverName {
+			conn.Close()
+			set.close()
+			return fmt.Errorf("replica %d uses %s but the primary uses %s", i+1, driverName, db.driverName)
+		}
+		r := &replica{db: conn}
+		r.healthy.Store(true)
+		set.replicas = append(set.replicas, r)
+	}
+
+	db.replicas.close()
+	db.replicas = set
+	if len(set.replicas) == 0 {
+		db.replicas = nil
+	}
+	return nil
+}
+
+// CheckReplicas pings every replica. Replicas that fail are taken out of
+// rotation until a later check reaches them; their errors are returned.
+func (db *DB) CheckReplicas(ctx context.Context) error {
+	if db.replicas == nil {
+		return nil
+	}
+
+	var errs []error
+	for i, r := range db.replicas.replicas {
+		pingCtx, cancel := context.WithTimeout(ctx, replicaPingTimeout)
+		err := r.db.PingContext(pingCtx)
+		cancel()
+		r.healthy.Store(err == nil)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("replica %d: %w", i+1, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// StartReplicaHealthChecks checks the replicas every interval until ctx is
+// done, passing the errors of each failed check to onError
+func (db *DB) StartReplicaHealthChecks(ctx context.Context, interval time.Duration, onError func(error)) {
+	if db.replicas == nil {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := db.CheckReplicas(ctx); err != nil && onError != nil {
+					onError(err)
+				}
+			}
+		}
+	}()
+}
+
+// pick returns the next healthy replica, or nil when there is none
+func (s *replicaSet) pick() *replica {
+	if s == nil {
+		return nil
+	}
+	start := s.next.Add(1)
+	for i := range s.replicas {
+		r := s.replicas[(start+uint64(i))%uint64(len(s.replicas))]
+		if r.healthy.Load() {
+			return r
+		}
+	}
+	return nil
+}
+
+func (s *replicaSet) close() {
+	if s == nil {
+		return
+	}
+	for _, r := range s.replicas {
+		r.db.Close()
+	}
+}
+
+// readQueryx runs a read-only query on a replica, or on the primary when
+// no replica is healthy or the replica's query fails
+func (db *DB) readQueryx(ctx context.Context, query string, args ...interface{}) (*sqlx.Rows, error) {
+	if r := db.replicas.pick(); r != nil {
+		rows, err := r.db.QueryxContext(ctx, query, args...)
+		if err == nil {
+			return rows, nil
+		}
+		r.failed(ctx)
+	}
+	return db.QueryxContext(ctx, query, args...)
+}
+
+// readQueryRowx is readQueryx for queries returning one row
+func (db *DB) readQueryRowx(ctx context.Context, query string, args ...interface{}) *sqlx.Row {
+	if r := db.replicas.pick(); r != nil {
+		row := r.db.QueryRowxContext(ctx, query, args...)
+		if row.Err() == nil {
+			return row
+		}
+		r.failed(ctx)
+	}
+	return db.QueryRowxContext(ctx, query, args...)
+}
+
+// failed takes the replica out of rotation after a failed query if it no
+// longer answers; a query error alone may be the query's fault
+func (r *replica) failed(ctx context.Context) {
+	if ctx.Err() != nil {
+		return
+	}
+	pingCtx, cancel := context.WithTimeout(ctx, replicaPingTimeout)
+	defer cancel()
+	if r.db.PingContext(pingCtx) != nil {
+		r.healthy.Store(false)
+	}
+}
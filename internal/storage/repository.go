@@ -0,0 +1,91 @@
+package storage
+
+import (
+	"context"
+	"errors"
+
+	"github.com/nuumz/f1ow/internal/models"
+
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrWorkflowNotFound is returned when a workflow does not exist in the
+	// context's tenant
+	ErrWorkflowNotFound = errors.New("workflow not found")
+
+	// ErrExecutionNotFound is returned when an execution does not exist in
+	// the context's tenant
+	ErrExecutionNotFound = errors.New("execution not found")
+
+	// ErrNodeSnapshotNotFound is returned when a node of an execution has
+	// not run, or ran before snapshots were recorded
+	ErrNodeSnapshotNotFound = errors.New("node snapshot not found")
+)
+
+// WorkflowRepository stores workflows and their version history
+type WorkflowRepository interface {
+	GetWorkflows(ctx context.Context) ([]models.Workflow, error)
+	GetWorkflow(ctx context.Context, id uuid.UUID) (*models.Workflow, error)
+	ListWorkflows(ctx context.Context, opts WorkflowListOptions) ([]models.Workflow, int, error)
+	CreateWorkflow(ctx context.Context, workflow *models.Workflow) error
+	UpdateWorkflow(ctx context.Context, workflow *models.Workflow) error
+	DeleteWorkflow(ctx context.Context, id uuid.UUID) error
+	SetWorkflowStatus(ctx context.Context, id uuid.UUID, status models.WorkflowStatus) error
+	ListWorkflowVersions(ctx context.Context, workflowID uuid.UUID) ([]models.WorkflowVersion, error)
+	GetWorkflowVersion(ctx context.Context, workflowID uuid.UUID, version int) (*models.WorkflowVersion, error)
+}
+
+// ExecutionRepository stores executions, the claims workers hold on them,
+// the journal of their completed nodes, and their node runs
+type ExecutionRepository interface {
+	CreateExecution(ctx context.Context, execution *models.Execution) error
+	UpdateExecution(ctx context.Context, execution *models.Execution, runs ...models.NodeExecution) error
+	GetExecution(ctx context.Context, id uuid.UUID) (*models.Execution, error)
+	GetExecutions(ctx context.Context, workflowID *uuid.UUID, status *models.ExecutionStatus, limit int) ([]models.Execution, error)
+	ListExecutions(ctx context.Context, opts ExecutionListOptions) (*ExecutionPage, error)
+	ListActiveExecutions(ctx context.Context, workflowID uuid.UUID) ([]models.ExecutionSummary, error)
+	CancelExecution(ctx context.Context, id uuid.UUID, reason string) (bool, error)
+	ClaimExecution(ctx context.Context, id uuid.UUID, owner string) (int64, error)
+	RequeueExecution(ctx context.Context, id uuid.UUID, owner string) (bool, error)
+	ListClaimedExecutions(ctx context.Context) ([]ClaimedExecution, error)
+	AppendJournal(ctx context.Context, executionID uuid.UUID, token int64, nodeID string, output interface{}) error
+	GetJournal(ctx context.Context, executionID uuid.UUID) (map[string]interface{}, error)
+	SaveNodeExecutions(ctx context.Context, executionID uuid.UUID, token int64, runs []models.NodeExecution) error
+	ListNodeExecutions(ctx context.Context, executionID uuid.UUID) ([]models.NodeExecution, error)
+	GetNodeSnapshot(ctx context.Context, executionID uuid.UUID, nodeID string) (*models.NodeSnapshot, error)
+}
+
+// DeploymentRepository stores environments and the workflow versions
+// deployed to them
+type DeploymentRepository interface {
+	CreateEnvironment(ctx context.Context, environment *models.Environment) error
+	GetEnvironmentByName(ctx context.Context, name string) (*models.Environment, error)
+	ListEnvironments(ctx context.Context) ([]models.Environment, error)
+	DeleteEnvironment(ctx context.Context, name string) error
+	SaveDeployment(ctx context.Context, deployment *models.Deployment) error
+	GetDeployment(ctx context.Context, workflowID uuid.UUID, environment string) (*models.Deployment, error)
+	ListDeployments(ctx context.Context, workflowID uuid.UUID) ([]models.Deployment, error)
+	DeleteDeployment(ctx context.Context, workflowID uuid.UUID, environment string) error
+}
+
+// BatchRepository stores batches of executions submitted together
+type BatchRepository interface {
+	CreateExecutionBatch(ctx context.Context, batch *models.ExecutionBatch) error
+	GetExecutionBatch(ctx context.Context, id uuid.UUID) (*models.ExecutionBatch, error)
+	AddBatchExecutions(ctx context.Context, batchID uuid.UUID, executionIDs []uuid.UUID) error
+}
+
+// Repository is the storage the engine runs workflows against. DB
+// implements it over SQL and MemoryRepository in process.
+type Repository interface {
+	WorkflowRepository
+	ExecutionRepository
+	DeploymentRepository
+	BatchRepository
+}
+
+var (
+	_ Repository = (*DB)(nil)
+	_ Repository = (*MemoryRepository)(nil)
+)
@@ -0,0 +1,185 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/nuumz/f1ow/internal/models"
+	"github.com/nuumz/f1ow/internal/schedules"
+	"github.com/nuumz/f1ow/internal/tenant"
+
+	"github.com/google/uuid"
+)
+
+const scheduleColumns = `id, tenant_id, workflow_id, name, cron_expr, timezone, is_active, next_run_at, last_run_at,
+               input, created_at, updated_at`
+
+// CreateSchedule stores a new schedule in the context's tenant
+func (db *DB) CreateSchedule(ctx context.Context, schedule *models.Schedule) error {
+	inputJSON, err := json.Marshal(schedule.Input)
+	if err != nil {
+		return fmt.Errorf("failed to marshal input: %w", err)
+	}
+
+	schedule.TenantID = tenant.IDOrDefault(ctx)
+	query := fmt.Sprintf(`
+        INSERT INTO schedules (id, tenant_id, workflow_id, name, cron_expr, timezone, is_active, next_run_at,
+                               last_run_at, input, created_at, updated_at)
+        VALUES (%s, %s, %s, %s, %s, %s, %s, %s, %s, %s, %s, %s)
+    `, db.placeholder(1), db.placeholder(2), db.placeholder(3), db.placeholder(4), db.placeholder(5),
+		db.placeholder(6), db.placeholder(7), db.placeholder(8), db.placeholder(9), db.placeholder(10),
+		db.placeholder(11), db.placeholder(12))
+
+	_, err = db.ExecContext(ctx, query, schedule.ID, schedule.TenantID, schedule.WorkflowID, schedule.Name,
+		schedule.CronExpr, schedule.Timezone, schedule.IsActive, schedule.NextRunAt, schedule.LastRunAt,
+		inputJSON, schedule.CreatedAt, schedule.UpdatedAt)
+	return err
+}
+
+// GetSchedule retrieves a schedule by ID
+func (db *DB) GetSchedule(ctx context.Context, id uuid.UUID) (*models.Schedule, error) {
+	query := fmt.Sprintf(`SELECT %s FROM schedules WHERE id = %s`, scheduleColumns, db.placeholder(1))
+	query, args := db.scopeToTenant(ctx, query, []interface{}{id}, "tenant_id")
+
+	schedule, err := scanSchedule(db.QueryRowxContext(ctx, query, args...))
+	if err == sql.ErrNoRows {
+		return nil, schedules.ErrNotFound
+	}
+	return schedule, err
+}
+
+// ListSchedules returns the schedules of the context's tenant, only those
+// of workflowID unless it is uuid.Nil, oldest first
+func (db *DB) ListSchedules(ctx context.Context, workflowID uuid.UUID) ([]models.Schedule, error) {
+	query := fmt.Sprintf(`SELECT %s FROM schedules WHERE 1=1`, scheduleColumns)
+	var args []interface{}
+	if workflowID != uuid.Nil {
+		args = append(args, workflowID)
+		query += " AND workflow_id = " + db.placeholder(len(args))
+	}
+	query, args = db.scopeToTenant(ctx, query, args, "tenant_id")
+
+	return db.querySchedules(ctx, query+" ORDER BY created_at", args...)
+}
+
+// UpdateSchedule replaces a schedule's settings and run times
+func (db *DB) UpdateSchedule(ctx context.Context, schedule *models.Schedule) error {
+	inputJSON, err := json.Marshal(schedule.Input)
+	if err != nil {
+		return fmt.Errorf("failed to marshal input: %w", err)
+	}
+
+	query := fmt.Sprintf(`
+        UPDATE schedules
+        SET name = %s, cron_expr = %s, timezone = %s, is_active = %s, next_run_at = %s, last_run_at = %s,
+            input = %s, updated_at = %s
+        WHERE id = %s`,
+		db.placeholder(1), db.placeholder(2), db.placeholder(3), db.placeholder(4), db.placeholder(5),
+		db.placeholder(6), db.placeholder(7), db.placeholder(8), db.placeholder(9))
+	query, args := db.scopeToTenant(ctx, query, []interface{}{schedule.Name, schedule.CronExpr, schedule.Timezone,
+		schedule.IsActive, schedule.NextRunAt, schedule.LastRunAt, inputJSON, schedule.UpdatedAt, schedule.ID}, "tenant_id")
+
+	result, err := db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to update schedule: %w", err)
+	}
+	if affected, err := result.RowsAffected(); err == nil && affected == 0 {
+		return schedules.ErrNotFound
+	}
+	return nil
+}
+
+// DeleteSchedule removes a schedule
+func (db *DB) DeleteSchedule(ctx context.Context, id uuid.UUID) error {
+	query, args := db.scopeToTenant(ctx, fmt.Sprintf(`DELETE FROM schedules WHERE id = %s`, db.placeholder(1)),
+		[]interface{}{id}, "tenant_id")
+
+	result, err := db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	if affected, err := result.RowsAffected(); err == nil && affected == 0 {
+		return schedules.ErrNotFound
+	}
+	return nil
+}
+
+// ListDueSchedules returns active schedules of every tenant whose next run
+// is at or before now, earliest first
+func (db *DB) ListDueSchedules(ctx context.Context, now time.Time, limit int) ([]models.Schedule, error) {
+	query := fmt.Sprintf(`
+        SELECT %s FROM schedules
+        WHERE is_active = %s AND next_run_at <= %s
+        ORDER BY next_run_at LIMIT %s`,
+		scheduleColumns, db.placeholder(1), db.placeholder(2), db.placeholder(3))
+
+	return db.querySchedules(ctx, query, true, now, limit)
+}
+
+// AdvanceSchedule records a run of a schedule that was due at due and sets
+// its next run, unless another instance already has. It reports whether
+// the schedule was advanced.
+func (db *DB) AdvanceSchedule(ctx context.Context, schedule *models.Schedule, due time.Time) (bool, error) {
+	query := fmt.Sprintf(`
+        UPDATE schedules
+        SET is_active = %s, next_run_at = %s, last_run_at = %s, updated_at = %s
+        WHERE id = %s AND next_run_at = %s`,
+		db.placeholder(1), db.placeholder(2), db.placeholder(3), db.placeholder(4), db.placeholder(5),
+		db.placeholder(6))
+	query, args := db.scopeToTenant(ctx, query, []interface{}{schedule.IsActive, schedule.NextRunAt,
+		schedule.LastRunAt, time.Now(), schedule.ID, due}, "tenant_id")
+
+	result, err := db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return false, fmt.Errorf("failed to advance schedule: %w", err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected > 0, nil
+}
+
+func (db *DB) querySchedules(ctx context.Context, query string, args ...interface{}) ([]models.Schedule, error) {
+	rows, err := db.QueryxContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list schedules: %w", err)
+	}
+	defer rows.Close()
+
+	list := []models.Schedule{}
+	for rows.Next() {
+		schedule, err := scanSchedule(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, *schedule)
+	}
+	return list, rows.Err()
+}
+
+func scanSchedule(row rowScanner) (*models.Schedule, error) {
+	var schedule models.Schedule
+	var nextRunAt, lastRunAt sql.NullTime
+	var inputJSON []byte
+	if err := row.Scan(&schedule.ID, &schedule.TenantID, &schedule.WorkflowID, &schedule.Name, &schedule.CronExpr,
+		&schedule.Timezone, &schedule.IsActive, &nextRunAt, &lastRunAt, &inputJSON,
+		&schedule.CreatedAt, &schedule.UpdatedAt); err != nil {
+		return nil, err
+	}
+	if nextRunAt.Valid {
+		schedule.NextRunAt = &nextRunAt.Time
+	}
+	if lastRunAt.Valid {
+		schedule.LastRunAt = &lastRunAt.Time
+	}
+	if len(inputJSON) > 0 {
+		if err := json.Unmarshal(inputJSON, &schedule.Input); err != nil {
+			return nil, fmt.Errorf("failed to parse schedule input: %w", err)
+		}
+	}
+	return &schedule, nil
+}
@@ -0,0 +1,123 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// MarkSeen records keys in the Redis seen-set for scope and reports, per key,
+// whether it had already been seen. A ttl of zero keeps keys forever.
+func (r *RedisClient) MarkSeen(ctx context.Context, scope string, keys []string, ttl time.Duration) ([]bool, error) {
+	setKey := "dedupe:" + scope
+	now := time.Now()
+
+	expiresAt := math.Inf(1)
+	if ttl > 0 {
+		expiresAt = float64(now.Add(ttl).Unix())
+	}
+
+	cmds, err := r.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		// Drop expired keys before checking membership
+		pipe.ZRemRangeByScore(ctx, setKey, "-inf", fmt.Sprintf("%d", now.Unix()))
+		for _, key := range keys {
+			pipe.ZAddNX(ctx, setKey, redis.Z{Score: expiresAt, Member: key})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to update seen-set: %w", err)
+	}
+
+	seen := make([]bool, len(keys))
+	for i := range keys {
+		added, err := cmds[i+1].(*redis.IntCmd).Result()
+		if err != nil {
+			return nil, fmt.Errorf("failed to update seen-set: %w", err)
+		}
+		seen[i] = added == 0
+	}
+
+	return seen, nil
+}
+
+// MarkSeen records keys in the dedupe_keys table for scope and reports, per key,
+// whether it had already been seen. A ttl of zero keeps keys forever.
+func (db *DB) MarkSeen(ctx context.Context, scope string, keys []string, ttl time.Duration) ([]bool, error) {
+	if len(keys) == 0 {
+		return []bool{}, nil
+	}
+
+	now := time.Now()
+	var expiresAt *time.Time
+	if ttl > 0 {
+		t := now.Add(ttl)
+		expiresAt = &t
+	}
+
+	tx, err := db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Drop expired keys before checking membership
+	deleteQuery := fmt.Sprintf(`DELETE FROM dedupe_keys WHERE scope = %s AND expires_at IS NOT NULL AND expires_at < %s`,
+		db.placeholder(1), db.placeholder(2))
+	if _, err := tx.ExecContext(ctx, deleteQuery, scope, now); err != nil {
+		return nil, fmt.Errorf("failed to expire seen keys: %w", err)
+	}
+
+	placeholders := make([]string, len(keys))
+	args := []interface{}{scope}
+	for i, key := range keys {
+		placeholders[i] = db.placeholder(i + 2)
+		args = append(args, key)
+	}
+
+	selectQuery := fmt.Sprintf(`SELECT key_hash FROM dedupe_keys WHERE scope = %s AND key_hash IN (%s)`,
+		db.placeholder(1), strings.Join(placeholders, ", "))
+	rows, err := tx.QueryxContext(ctx, selectQuery, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query seen keys: %w", err)
+	}
+
+	existing := make(map[string]bool)
+	for rows.Next() {
+		var key string
+		if err := rows.Scan(&key); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		existing[key] = true
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	insertQuery := fmt.Sprintf(`INSERT INTO dedupe_keys (scope, key_hash, expires_at, created_at) VALUES (%s, %s, %s, %s)`,
+		db.placeholder(1), db.placeholder(2), db.placeholder(3), db.placeholder(4))
+
+	seen := make([]bool, len(keys))
+	for i, key := range keys {
+		if existing[key] {
+			seen[i] = true
+			continue
+		}
+		if _, err := tx.ExecContext(ctx, insertQuery, scope, key, expiresAt, now); err != nil {
+			return nil, fmt.Errorf("failed to record seen key: %w", err)
+		}
+		existing[key] = true
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit seen keys: %w", err)
+	}
+
+	return seen, nil
+}
@@ -0,0 +1,112 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"regexp"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/mattn/go-sqlite3"
+)
+
+// sqliteDriverName is the wrapped SQLite driver registered by this package
+const sqliteDriverName = "f1ow-sqlite3"
+
+func init() {
+	sql.Register(sqliteDriverName, &sqliteDriver{})
+	sqlx.BindDriver(sqliteDriverName, sqlx.QUESTION)
+}
+
+// dollarPlaceholder matches the PostgreSQL-style $N placeholders queries are
+// written with
+var dollarPlaceholder = regexp.MustCompile(`\$(\d+)`)
+
+// isSQLiteDSN reports whether dsn names a SQLite database: a sqlite:// or
+// sqlite: URL, a file: URI, or a path ending in .db or .sqlite
+func isSQLiteDSN(dsn string) bool {
+	if strings.HasPrefix(dsn, "sqlite:") || strings.HasPrefix(dsn, "file:") {
+		return true
+	}
+	path, _, _ := strings.Cut(dsn, "?")
+	return strings.HasSuffix(path, ".db") || strings.HasSuffix(path, ".sqlite")
+}
+
+// sqliteDSN converts a SQLite URL to a file name for the driver, turning on
+// foreign keys, WAL, and a busy timeout unless the DSN sets them
+func sqliteDSN(dsn string) string {
+	if after, ok := strings.CutPrefix(dsn, "sqlite://"); ok {
+		dsn = after
+	} else if after, ok := strings.CutPrefix(dsn, "sqlite:"); ok {
+		dsn = after
+	}
+
+	defaults := []string{"_foreign_keys=on", "_journal_mode=WAL", "_busy_timeout=5000"}
+	for _, option := range defaults {
+		name, _, _ := strings.Cut(option, "=")
+		if strings.Contains(dsn, name+"=") {
+			continue
+		}
+		if strings.Contains(dsn, "?") {
+			dsn += "&" + option
+		} else {
+			dsn += "?" + option
+		}
+	}
+	return dsn
+}
+
+// sqliteDriver wraps the SQLite driver so queries written for PostgreSQL run
+// unchanged: $N placeholders become ?N, which SQLite binds by position
+// wherever they appear, and JSON arguments are bound as text so the json_*
+// functions can read the columns they are stored in.
+type sqliteDriver struct {
+	sqlite3.SQLiteDriver
+}
+
+func (d *sqliteDriver) Open(dsn string) (driver.Conn, error) {
+	conn, err := d.SQLiteDriver.Open(dsn)
+	if err != nil {
+		return nil, err
+	}
+	return &sqliteConn{conn.(*sqlite3.SQLiteConn)}, nil
+}
+
+type sqliteConn struct {
+	*sqlite3.SQLiteConn
+}
+
+func rewriteSQLite(query string) string {
+	return dollarPlaceholder.ReplaceAllString(query, "?$1")
+}
+
+func (c *sqliteConn) Prepare(query string) (driver.Stmt, error) {
+	return c.SQLiteConn.Prepare(rewriteSQLite(query))
+}
+
+func (c *sqliteConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	return c.SQLiteConn.PrepareContext(ctx, rewriteSQLite(query))
+}
+
+func (c *sqliteConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	return c.SQLiteConn.ExecContext(ctx, rewriteSQLite(query), args)
+}
+
+func (c *sqliteConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	return c.SQLiteConn.QueryContext(ctx, rewriteSQLite(query), args)
+}
+
+// CheckNamedValue binds JSON documents as text. Other values take the
+// default conversion.
+func (c *sqliteConn) CheckNamedValue(nv *driver.NamedValue) error {
+	switch v := nv.Value.(type) {
+	case json.RawMessage:
+		nv.Value = []byte(v)
+	}
+	if b, ok := nv.Value.([]byte); ok && json.Valid(b) {
+		nv.Value = string(b)
+	}
+	return driver.ErrSkip
+}
@@ -0,0 +1,197 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/nuumz/f1ow/internal/models"
+	"github.com/nuumz/f1ow/internal/tenant"
+
+	"github.com/google/uuid"
+)
+
+// ErrTenantNotFound is returned when a tenant does not exist
+var ErrTenantNotFound = errors.New("tenant not found")
+
+// CreateTenant stores a new tenant
+func (db *DB) CreateTenant(ctx context.Context, t *models.Tenant) error {
+	if t.ID == uuid.Nil {
+		t.ID = uuid.New()
+	}
+	t.CreatedAt = time.Now()
+
+	policyJSON, err := networkPolicyJSON(t.NetworkPolicy)
+	if err != nil {
+		return err
+	}
+	quotasJSON, err := quotasJSON(t.Quotas)
+	if err != nil {
+		return err
+	}
+	if err := db.checkPlan(ctx, t.Plan); err != nil {
+		return err
+	}
+
+	query := fmt.Sprintf(`INSERT INTO tenants (id, name, network_policy, plan, quotas, created_at) VALUES (%s, %s, %s, %s, %s, %s)`,
+		db.placeholder(1), db.placeholder(2), db.placeholder(3), db.placeholder(4), db.placeholder(5), db.placeholder(6))
+	_, err = db.ExecContext(ctx, query, t.ID, t.Name, policyJSON, t.Plan, quotasJSON, t.CreatedAt)
+	return err
+}
+
+// ListTenants returns all tenants ordered by name
+func (db *DB) ListTenants(ctx context.Context) ([]models.Tenant, error) {
+	rows, err := db.QueryxContext(ctx, `SELECT id, name, network_policy, plan, quotas, created_at FROM tenants ORDER BY name`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tenants := []models.Tenant{}
+	for rows.Next() {
+		var t models.Tenant
+		var policyJSON, quotasJSON []byte
+		if err := rows.Scan(&t.ID, &t.Name, &policyJSON, &t.Plan, &quotasJSON, &t.CreatedAt); err != nil {
+			return nil, err
+		}
+		if t.NetworkPolicy, err = parseNetworkPolicy(policyJSON); err != nil {
+			return nil, err
+		}
+		if t.Quotas, err = parseQuotas(quotasJSON); err != nil {
+			return nil, err
+		}
+		tenants = append(tenants, t)
+	}
+	return tenants, rows.Err()
+}
+
+// TenantNetworkPolicy returns the network policy of a tenant, or nil when
+// it has none
+func (db *DB) TenantNetworkPolicy(ctx context.Context, id uuid.UUID) (*models.NetworkPolicy, error) {
+	query := fmt.Sprintf(`SELECT network_policy FROM tenants WHERE id = %s`, db.placeholder(1))
+	var policyJSON []byte
+	err := db.QueryRowxContext(ctx, query, id).Scan(&policyJSON)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return parseNetworkPolicy(policyJSON)
+}
+
+// UpdateTenantNetworkPolicy replaces the network policy of a tenant; nil
+// removes it
+func (db *DB) UpdateTenantNetworkPolicy(ctx context.Context, id uuid.UUID, policy *models.NetworkPolicy) error {
+	policyJSON, err := networkPolicyJSON(policy)
+	if err != nil {
+		return err
+	}
+
+	query := fmt.Sprintf(`UPDATE tenants SET network_policy = %s WHERE id = %s`, db.placeholder(1), db.placeholder(2))
+	result, err := db.ExecContext(ctx, query, policyJSON, id)
+	if err != nil {
+		return err
+	}
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		return ErrTenantNotFound
+	}
+	return nil
+}
+
+// TenantQuotas returns the plan of a tenant and its quotas, with those it
+// leaves unset taken from the plan. A tenant that does not exist has no
+// plan or quotas.
+func (db *DB) TenantQuotas(ctx context.Context, id uuid.UUID) (string, models.Quotas, error) {
+	query := fmt.Sprintf(`
+        SELECT t.plan, t.quotas, p.quotas
+        FROM tenants t
+        LEFT JOIN plans p ON p.name = t.plan
+        WHERE t.id = %s`, db.placeholder(1))
+	var plan string
+	var tenantJSON, planJSON []byte
+	err := db.QueryRowxContext(ctx, query, id).Scan(&plan, &tenantJSON, &planJSON)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", models.Quotas{}, nil
+	}
+	if err != nil {
+		return "", models.Quotas{}, err
+	}
+
+	quotas, err := parseQuotas(tenantJSON)
+	if err != nil {
+		return "", models.Quotas{}, err
+	}
+	planQuotas, err := parseQuotas(planJSON)
+	if err != nil {
+		return "", models.Quotas{}, err
+	}
+	if quotas == nil {
+		quotas = &models.Quotas{}
+	}
+	if planQuotas != nil {
+		*quotas = quotas.Inherit(*planQuotas)
+	}
+	return plan, *quotas, nil
+}
+
+// UpdateTenantQuotas puts a tenant on a plan and replaces the quotas it
+// overrides
+func (db *DB) UpdateTenantQuotas(ctx context.Context, id uuid.UUID, quotas models.TenantQuotas) error {
+	data, err := quotasJSON(quotas.Quotas)
+	if err != nil {
+		return err
+	}
+	if err := db.checkPlan(ctx, quotas.Plan); err != nil {
+		return err
+	}
+
+	query := fmt.Sprintf(`UPDATE tenants SET plan = %s, quotas = %s WHERE id = %s`,
+		db.placeholder(1), db.placeholder(2), db.placeholder(3))
+	result, err := db.ExecContext(ctx, query, quotas.Plan, data, id)
+	if err != nil {
+		return err
+	}
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		return ErrTenantNotFound
+	}
+	return nil
+}
+
+// networkPolicyJSON encodes a network policy for its column, or returns nil
+// for no policy
+func networkPolicyJSON(policy *models.NetworkPolicy) (interface{}, error) {
+	if policy == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(policy)
+	if err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+func parseNetworkPolicy(data []byte) (*models.NetworkPolicy, error) {
+	if len(data) == 0 {
+		return nil, nil
+	}
+	var policy models.NetworkPolicy
+	if err := json.Unmarshal(data, &policy); err != nil {
+		return nil, fmt.Errorf("failed to parse network policy: %w", err)
+	}
+	return &policy, nil
+}
+
+// scopeToTenant appends a filter on the tenant column to a query ending in a
+// WHERE clause when the context is scoped to a tenant
+func (db *DB) scopeToTenant(ctx context.Context, query string, args []interface{}, column string) (string, []interface{}) {
+	id, ok := tenant.FromContext(ctx)
+	if !ok {
+		return query, args
+	}
+	args = append(args, id)
+	return query + fmt.Sprintf(" AND %s = %s", column, db.placeholder(len(args))), args
+}
@@ -0,0 +1,115 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/nuumz/f1ow/internal/models"
+	"github.com/nuumz/f1ow/internal/tenant"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+// UsageReportOptions selects the executions a usage report totals
+type UsageReportOptions struct {
+	From       time.Time // finished at or after
+	To         time.Time // finished before
+	ByWorkflow bool      // one rollup per workflow instead of per tenant
+}
+
+// saveExecutionUsage replaces the usage row of a finished execution
+func (db *DB) saveExecutionUsage(ctx context.Context, tx *sqlx.Tx, execution *models.Execution) error {
+	if execution.Usage == nil || execution.CompletedAt == nil {
+		return nil
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM execution_usage WHERE execution_id = $1`, execution.ID); err != nil {
+		return fmt.Errorf("failed to save execution usage: %w", err)
+	}
+
+	tenantID := execution.TenantID
+	if tenantID == uuid.Nil {
+		tenantID = tenant.IDOrDefault(ctx)
+	}
+	usage := execution.Usage
+	_, err := tx.ExecContext(ctx, `
+        INSERT INTO execution_usage (execution_id, tenant_id, workflow_id, nodes, runtime_ms,
+            http_bytes_sent, http_bytes_received, llm_prompt_tokens, llm_completion_tokens, completed_at)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)`,
+		execution.ID, tenantID, execution.WorkflowID, usage.Nodes, usage.RuntimeMs,
+		usage.HTTPBytesSent, usage.HTTPBytesReceived, usage.LLMPromptTokens, usage.LLMCompletionTokens, *execution.CompletedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save execution usage: %w", err)
+	}
+	return nil
+}
+
+// executionUsage returns the usage of a finished execution, or nil
+func (db *DB) executionUsage(ctx context.Context, id uuid.UUID) (*models.ExecutionUsage, error) {
+	var usage models.ExecutionUsage
+	err := db.readQueryRowx(ctx, `
+        SELECT nodes, runtime_ms, http_bytes_sent, http_bytes_received, llm_prompt_tokens, llm_completion_tokens
+        FROM execution_usage
+        WHERE execution_id = $1`, id).Scan(
+		&usage.Nodes, &usage.RuntimeMs, &usage.HTTPBytesSent, &usage.HTTPBytesReceived,
+		&usage.LLMPromptTokens, &usage.LLMCompletionTokens)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read execution usage: %w", err)
+	}
+	return &usage, nil
+}
+
+// GetUsageReport totals the usage of the executions that finished between
+// opts.From and opts.To, per tenant or per workflow. Callers scoped to a
+// tenant only see their own.
+func (db *DB) GetUsageReport(ctx context.Context, opts UsageReportOptions) (*models.UsageReport, error) {
+	groups := "tenant_id"
+	if opts.ByWorkflow {
+		groups = "tenant_id, workflow_id"
+	}
+
+	query := `
+        SELECT ` + groups + `, COUNT(*),
+               COALESCE(SUM(nodes), 0), COALESCE(SUM(runtime_ms), 0),
+               COALESCE(SUM(http_bytes_sent), 0), COALESCE(SUM(http_bytes_received), 0),
+               COALESCE(SUM(llm_prompt_tokens), 0), COALESCE(SUM(llm_completion_tokens), 0)
+        FROM execution_usage
+        WHERE completed_at >= $1 AND completed_at < $2`
+	query, args := db.scopeToTenant(ctx, query, []interface{}{opts.From, opts.To}, "tenant_id")
+	query += " GROUP BY " + groups + " ORDER BY " + groups
+
+	rows, err := db.readQueryx(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to total usage: %w", err)
+	}
+	defer rows.Close()
+
+	report := &models.UsageReport{From: opts.From, To: opts.To, Rollups: []models.UsageRollup{}}
+	for rows.Next() {
+		var rollup models.UsageRollup
+		dest := []interface{}{&rollup.TenantID}
+		if opts.ByWorkflow {
+			rollup.WorkflowID = &uuid.UUID{}
+			dest = append(dest, rollup.WorkflowID)
+		}
+		usage := &rollup.Usage
+		dest = append(dest, &rollup.Executions, &usage.Nodes, &usage.RuntimeMs,
+			&usage.HTTPBytesSent, &usage.HTTPBytesReceived, &usage.LLMPromptTokens, &usage.LLMCompletionTokens)
+		if err := rows.Scan(dest...); err != nil {
+			return nil, fmt.Errorf("failed to total usage: %w", err)
+		}
+
+		report.Rollups = append(report.Rollups, rollup)
+		report.Executions += rollup.Executions
+		report.Total.Add(rollup.Usage)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to total usage: %w", err)
+	}
+	return report, nil
+}
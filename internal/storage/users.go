@@ -0,0 +1,38 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/nuumz/f1ow/internal/models"
+
+	"github.com/google/uuid"
+)
+
+// GetUser retrieves a user by ID
+func (db *DB) GetUser(ctx context.Context, id uuid.UUID) (*models.User, error) {
+	var user models.User
+	var userID string
+
+	query := fmt.Sprintf(`
+        SELECT id, email, COALESCE(name, ''), COALESCE(role, ''), created_at, updated_at
+        FROM users
+        WHERE id = %s
+    `, db.placeholder(1))
+
+	err := db.QueryRowxContext(ctx, query, id.String()).Scan(
+		&userID, &user.Email, &user.Name, &user.Role, &user.CreatedAt, &user.UpdatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("user not found")
+		}
+		return nil, err
+	}
+
+	if user.ID, err = uuid.Parse(userID); err != nil {
+		return nil, fmt.Errorf("invalid user ID: %w", err)
+	}
+
+	return &user, nil
+}
@@ -0,0 +1,105 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/nuumz/f1ow/internal/models"
+	"github.com/nuumz/f1ow/internal/tenant"
+	"github.com/nuumz/f1ow/internal/variables"
+
+	"github.com/google/uuid"
+)
+
+const variableColumns = `id, name, environment, secret, data, created_at, updated_at`
+
+// CreateVariable stores a new variable in the context's tenant
+func (db *DB) CreateVariable(ctx context.Context, variable *models.Variable) error {
+	query := fmt.Sprintf(`
+        INSERT INTO variables (id, tenant_id, name, environment, secret, data, created_at, updated_at)
+        VALUES (%s, %s, %s, %s, %s, %s, %s, %s)
+    `, db.placeholder(1), db.placeholder(2), db.placeholder(3), db.placeholder(4),
+		db.placeholder(5), db.placeholder(6), db.placeholder(7), db.placeholder(8))
+
+	_, err := db.ExecContext(ctx, query, variable.ID, tenant.IDOrDefault(ctx), variable.Name,
+		variable.Environment, variable.Secret, variable.Data, variable.CreatedAt, variable.UpdatedAt)
+	return err
+}
+
+// GetVariable retrieves a variable by ID, including its stored data
+func (db *DB) GetVariable(ctx context.Context, id uuid.UUID) (*models.Variable, error) {
+	query := fmt.Sprintf(`SELECT %s FROM variables WHERE id = %s`, variableColumns, db.placeholder(1))
+	query, args := db.scopeToTenant(ctx, query, []interface{}{id}, "tenant_id")
+
+	variable, err := db.scanVariable(db.QueryRowxContext(ctx, query, args...))
+	if err == sql.ErrNoRows {
+		return nil, variables.ErrNotFound
+	}
+	return variable, err
+}
+
+// ListVariables returns the variables of the context's tenant ordered by
+// environment and name
+func (db *DB) ListVariables(ctx context.Context) ([]models.Variable, error) {
+	query, args := db.scopeToTenant(ctx, fmt.Sprintf(`SELECT %s FROM variables WHERE 1=1`, variableColumns), nil, "tenant_id")
+
+	rows, err := db.QueryxContext(ctx, query+" ORDER BY environment, name", args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := []models.Variable{}
+	for rows.Next() {
+		variable, err := db.scanVariable(rows)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, *variable)
+	}
+	return result, rows.Err()
+}
+
+// UpdateVariable replaces the name, environment, secret flag, and data of a variable
+func (db *DB) UpdateVariable(ctx context.Context, variable *models.Variable) error {
+	query := fmt.Sprintf(`
+        UPDATE variables SET name = %s, environment = %s, secret = %s, data = %s, updated_at = %s
+        WHERE id = %s`, db.placeholder(1), db.placeholder(2), db.placeholder(3), db.placeholder(4),
+		db.placeholder(5), db.placeholder(6))
+	query, args := db.scopeToTenant(ctx, query, []interface{}{variable.Name, variable.Environment,
+		variable.Secret, variable.Data, variable.UpdatedAt, variable.ID}, "tenant_id")
+
+	result, err := db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	if affected, err := result.RowsAffected(); err == nil && affected == 0 {
+		return variables.ErrNotFound
+	}
+	return nil
+}
+
+// DeleteVariable removes a variable
+func (db *DB) DeleteVariable(ctx context.Context, id uuid.UUID) error {
+	query, args := db.scopeToTenant(ctx, fmt.Sprintf(`DELETE FROM variables WHERE id = %s`, db.placeholder(1)),
+		[]interface{}{id}, "tenant_id")
+
+	result, err := db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	if affected, err := result.RowsAffected(); err == nil && affected == 0 {
+		return variables.ErrNotFound
+	}
+	return nil
+}
+
+func (db *DB) scanVariable(row rowScanner) (*models.Variable, error) {
+	var variable models.Variable
+	if err := row.Scan(&variable.ID, &variable.Name, &variable.Environment, &variable.Secret,
+		&variable.Data, &variable.CreatedAt, &variable.UpdatedAt); err != nil {
+		return nil, err
+	}
+	return &variable, nil
+}
@@ -0,0 +1,158 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/nuumz/f1ow/internal/models"
+	"github.com/nuumz/f1ow/internal/tenant"
+
+	"github.com/google/uuid"
+)
+
+// WorkflowSortFields maps accepted sort values to columns
+var WorkflowSortFields = map[string]string{
+	"name":       "name",
+	"created_at": "created_at",
+	"updated_at": "updated_at",
+}
+
+// WorkflowListOptions filters, sorts, and pages the workflow list
+type WorkflowListOptions struct {
+	Limit     int
+	Offset    int
+	Query     string   // case-insensitive name search
+	Tags      []string // workflows must have every tag
+	Sort      string   // key of WorkflowSortFields, default created_at
+	Desc      bool
+	Summary   bool // omit the definition
+	ProjectID *uuid.UUID
+	Statuses  []models.WorkflowStatus // draft and active when empty
+}
+
+// ListWorkflows returns one page of workflows and the total number of
+// workflows matching the filters
+func (db *DB) ListWorkflows(ctx context.Context, opts WorkflowListOptions) ([]models.Workflow, int, error) {
+	where := []string{}
+	args := []interface{}{}
+
+	statuses := opts.Statuses
+	if len(statuses) == 0 {
+		statuses = []models.WorkflowStatus{models.WorkflowStatusDraft, models.WorkflowStatusActive}
+	}
+	placeholders := make([]string, len(statuses))
+	for i, status := range statuses {
+		args = append(args, status)
+		placeholders[i] = db.placeholder(len(args))
+	}
+	where = append(where, fmt.Sprintf("status IN (%s)", strings.Join(placeholders, ", ")))
+
+	if opts.Query != "" {
+		args = append(args, "%"+strings.ToLower(opts.Query)+"%")
+		where = append(where, fmt.Sprintf("LOWER(name) LIKE %s", db.placeholder(len(args))))
+	}
+
+	if id, ok := tenant.FromContext(ctx); ok {
+		args = append(args, id)
+		where = append(where, fmt.Sprintf("tenant_id = %s", db.placeholder(len(args))))
+	}
+
+	if opts.ProjectID != nil {
+		args = append(args, *opts.ProjectID)
+		where = append(where, fmt.Sprintf("project_id = %s", db.placeholder(len(args))))
+	}
+
+	for _, tag := range opts.Tags {
+		if db.isMySQL() {
+			encoded, _ := json.Marshal(tag)
+			args = append(args, string(encoded))
+			where = append(where, fmt.Sprintf("JSON_CONTAINS(tags, %s)", db.placeholder(len(args))))
+		} else if db.isSQLite() {
+			args = append(args, tag)
+			where = append(where, fmt.Sprintf("EXISTS (SELECT 1 FROM json_each(tags) WHERE value = %s)", db.placeholder(len(args))))
+		} else {
+			encoded, _ := json.Marshal([]string{tag})
+			args = append(args, string(encoded))
+			where = append(where, fmt.Sprintf("tags @> %s::jsonb", db.placeholder(len(args))))
+		}
+	}
+
+	whereClause := strings.Join(where, " AND ")
+
+	var total int
+	countQuery := "SELECT COUNT(*) FROM workflows WHERE " + whereClause
+	if err := db.readQueryRowx(ctx, countQuery, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	sortColumn, ok := WorkflowSortFields[opts.Sort]
+	if !ok {
+		sortColumn = "created_at"
+	}
+	direction := "ASC"
+	if opts.Desc {
+		direction = "DESC"
+	}
+
+	definitionColumn := "definition"
+	if opts.Summary {
+		definitionColumn = "NULL"
+	}
+
+	query := fmt.Sprintf(`
+        SELECT id, name, description, %s, user_id, is_active, status,
+               created_at, updated_at, COALESCE(tags, '[]'), version, COALESCE(metadata, '{}'), project_id, tenant_id
+        FROM workflows
+        WHERE %s
+        ORDER BY %s %s, id
+    `, definitionColumn, whereClause, sortColumn, direction)
+
+	if opts.Limit > 0 {
+		args = append(args, opts.Limit)
+		query += fmt.Sprintf(" LIMIT %s", db.placeholder(len(args)))
+		args = append(args, opts.Offset)
+		query += fmt.Sprintf(" OFFSET %s", db.placeholder(len(args)))
+	}
+
+	rows, err := db.readQueryx(ctx, query, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	workflows := []models.Workflow{}
+	for rows.Next() {
+		var workflow models.Workflow
+		var definitionJSON, tagsJSON, metadataJSON []byte
+
+		err := rows.Scan(&workflow.ID, &workflow.Name, &workflow.Description,
+			&definitionJSON, &workflow.UserID, &workflow.IsActive, &workflow.Status,
+			&workflow.CreatedAt, &workflow.UpdatedAt, &tagsJSON,
+			&workflow.Version, &metadataJSON, &workflow.ProjectID, &workflow.TenantID)
+		if err != nil {
+			return nil, 0, err
+		}
+
+		if len(definitionJSON) > 0 {
+			if err := json.Unmarshal(definitionJSON, &workflow.Definition); err != nil {
+				return nil, 0, fmt.Errorf("failed to parse workflow definition: %w", err)
+			}
+		}
+		if len(tagsJSON) > 0 {
+			if err := json.Unmarshal(tagsJSON, &workflow.Tags); err != nil {
+				return nil, 0, fmt.Errorf("failed to parse tags: %w", err)
+			}
+		}
+		if len(metadataJSON) > 0 {
+			if err := json.Unmarshal(metadataJSON, &workflow.Metadata); err != nil {
+				return nil, 0, fmt.Errorf("failed to parse metadata: %w", err)
+			}
+		}
+
+		workflows = append(workflows, workflow)
+	}
+
+	return workflows, total, rows.Err()
+}
@@ -0,0 +1,105 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/nuumz/f1ow/internal/models"
+
+	"github.com/google/uuid"
+)
+
+// ErrStateNotFound is returned when a workflow has no state under a key
+var ErrStateNotFound = errors.New("state not found")
+
+// GetWorkflowState returns the state a workflow keeps under key
+func (db *DB) GetWorkflowState(ctx context.Context, workflowID uuid.UUID, key string) (*models.WorkflowState, error) {
+	// State rows have no tenant of their own; the workflow's scopes them
+	if _, err := db.GetWorkflow(ctx, workflowID); err != nil {
+		return nil, err
+	}
+
+	query := fmt.Sprintf(`SELECT value, updated_at FROM workflow_state WHERE workflow_id = %s AND state_key = %s`,
+		db.placeholder(1), db.placeholder(2))
+
+	state := models.WorkflowState{WorkflowID: workflowID, Key: key}
+	var valueJSON []byte
+	err := db.QueryRowxContext(ctx, query, workflowID, key).Scan(&valueJSON, &state.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return nil, ErrStateNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get workflow state: %w", err)
+	}
+	if len(valueJSON) > 0 {
+		if err := json.Unmarshal(valueJSON, &state.Value); err != nil {
+			return nil, fmt.Errorf("failed to parse workflow state %s: %w", key, err)
+		}
+	}
+	return &state, nil
+}
+
+// SetWorkflowState stores the state's value under its key, replacing any
+// value there, and sets its UpdatedAt
+func (db *DB) SetWorkflowState(ctx context.Context, state *models.WorkflowState) error {
+	if _, err := db.GetWorkflow(ctx, state.WorkflowID); err != nil {
+		return err
+	}
+
+	valueJSON, err := json.Marshal(state.Value)
+	if err != nil {
+		return fmt.Errorf("failed to marshal value: %w", err)
+	}
+	state.UpdatedAt = time.Now().UTC()
+
+	tx, err := db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// MySQL counts unchanged rows as unaffected, so check for the key
+	// rather than relying on the update's row count
+	var exists int
+	existsQuery := fmt.Sprintf(`SELECT COUNT(*) FROM workflow_state WHERE workflow_id = %s AND state_key = %s`,
+		db.placeholder(1), db.placeholder(2))
+	if err := tx.QueryRowxContext(ctx, existsQuery, state.WorkflowID, state.Key).Scan(&exists); err != nil {
+		return fmt.Errorf("failed to check workflow state: %w", err)
+	}
+
+	if exists > 0 {
+		query := fmt.Sprintf(`UPDATE workflow_state SET value = %s, updated_at = %s WHERE workflow_id = %s AND state_key = %s`,
+			db.placeholder(1), db.placeholder(2), db.placeholder(3), db.placeholder(4))
+		_, err = tx.ExecContext(ctx, query, valueJSON, state.UpdatedAt, state.WorkflowID, state.Key)
+	} else {
+		query := fmt.Sprintf(`INSERT INTO workflow_state (workflow_id, state_key, value, updated_at) VALUES (%s, %s, %s, %s)`,
+			db.placeholder(1), db.placeholder(2), db.placeholder(3), db.placeholder(4))
+		_, err = tx.ExecContext(ctx, query, state.WorkflowID, state.Key, valueJSON, state.UpdatedAt)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to set workflow state: %w", err)
+	}
+	return tx.Commit()
+}
+
+// DeleteWorkflowState removes the state a workflow keeps under key
+func (db *DB) DeleteWorkflowState(ctx context.Context, workflowID uuid.UUID, key string) error {
+	if _, err := db.GetWorkflow(ctx, workflowID); err != nil {
+		return err
+	}
+
+	query := fmt.Sprintf(`DELETE FROM workflow_state WHERE workflow_id = %s AND state_key = %s`,
+		db.placeholder(1), db.placeholder(2))
+	result, err := db.ExecContext(ctx, query, workflowID, key)
+	if err != nil {
+		return fmt.Errorf("failed to delete workflow state: %w", err)
+	}
+	if affected, err := result.RowsAffected(); err == nil && affected == 0 {
+		return ErrStateNotFound
+	}
+	return nil
+}
@@ -0,0 +1,288 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/nuumz/f1ow/internal/models"
+
+	"github.com/google/uuid"
+)
+
+// durationMsExpr returns the SQL expression for an execution's duration in
+// milliseconds
+func (db *DB) durationMsExpr() string {
+	if db.isMySQL() {
+		return "TIMESTAMPDIFF(MICROSECOND, started_at, completed_at) / 1000.0"
+	}
+	if db.isSQLite() {
+		return "(julianday(completed_at) - julianday(started_at)) * 86400000.0"
+	}
+	return "EXTRACT(EPOCH FROM (completed_at - started_at)) * 1000"
+}
+
+// nearestRank returns the SQL expression for the rank of the percent
+// percentile among cnt ranked rows
+func (db *DB) nearestRank(percent int) string {
+	if db.isSQLite() {
+		// SQLite may be built without CEIL; integer division rounds up here
+		return fmt.Sprintf("(cnt * %d + 99) / 100", percent)
+	}
+	return fmt.Sprintf("CEIL(%d * cnt / 100)", percent)
+}
+
+// GetWorkflowStats aggregates the executions of a workflow started at or
+// after since
+func (db *DB) GetWorkflowStats(ctx context.Context, workflowID uuid.UUID, since time.Time) (*models.WorkflowStats, error) {
+	// The aggregates filter by workflow, so checking the workflow belongs to
+	// the context's tenant scopes them all
+	if _, err := db.GetWorkflow(ctx, workflowID); err != nil {
+		return nil, err
+	}
+
+	stats := &models.WorkflowStats{
+		WorkflowID:   workflowID,
+		Since:        since,
+		StatusCounts: map[string]int{},
+		PerDay:       []models.DailyExecutionCount{},
+		NodeFailures: []models.NodeFailureCount{},
+		GeneratedAt:  time.Now().UTC(),
+	}
+
+	if err := db.statusCounts(ctx, workflowID, since, stats); err != nil {
+		return nil, fmt.Errorf("failed to count executions: %w", err)
+	}
+	if err := db.durationPercentiles(ctx, workflowID, since, stats); err != nil {
+		return nil, fmt.Errorf("failed to compute durations: %w", err)
+	}
+	if err := db.executionsPerDay(ctx, workflowID, since, stats); err != nil {
+		return nil, fmt.Errorf("failed to count executions per day: %w", err)
+	}
+	if err := db.nodeFailures(ctx, workflowID, since, stats); err != nil {
+		return nil, fmt.Errorf("failed to count node failures: %w", err)
+	}
+	if err := db.lastFailure(ctx, workflowID, since, stats); err != nil {
+		return nil, fmt.Errorf("failed to load last failure: %w", err)
+	}
+
+	return stats, nil
+}
+
+func (db *DB) statusCounts(ctx context.Context, workflowID uuid.UUID, since time.Time, stats *models.WorkflowStats) error {
+	query := fmt.Sprintf(`
+        SELECT status, COUNT(*)
+        FROM executions
+        WHERE workflow_id = %s AND started_at >= %s
+        GROUP BY status
+    `, db.placeholder(1), db.placeholder(2))
+
+	rows, err := db.readQueryx(ctx, query, workflowID, since)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var status string
+		var count int
+		if err := rows.Scan(&status, &count); err != nil {
+			return err
+		}
+		stats.StatusCounts[status] = count
+		stats.Total += count
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	completed := stats.StatusCounts[string(models.ExecutionStatusCompleted)]
+	finished := completed +
+		stats.StatusCounts[string(models.ExecutionStatusFailed)] +
+		stats.StatusCounts[string(models.ExecutionStatusCancelled)]
+	if finished > 0 {
+		stats.SuccessRate = math.Round(float64(completed)/float64(finished)*10000) / 10000
+	}
+	return nil
+}
+
+func (db *DB) durationPercentiles(ctx context.Context, workflowID uuid.UUID, since time.Time, stats *models.WorkflowStats) error {
+	var p50, p95 sql.NullFloat64
+
+	if !db.isPostgreSQL() {
+		// MySQL and SQLite have no percentile aggregate, so pick nearest
+		// ranks with window functions
+		query := fmt.Sprintf(`
+            SELECT
+                MAX(CASE WHEN rn = %s THEN duration END),
+                MAX(CASE WHEN rn = %s THEN duration END)
+            FROM (
+                SELECT %s AS duration,
+                       ROW_NUMBER() OVER (ORDER BY %s) AS rn,
+                       COUNT(*) OVER () AS cnt
+                FROM executions
+                WHERE workflow_id = $1 AND started_at >= $2 AND completed_at IS NOT NULL
+            ) ranked
+        `, db.nearestRank(50), db.nearestRank(95), db.durationMsExpr(), db.durationMsExpr())
+		if err := db.readQueryRowx(ctx, query, workflowID, since).Scan(&p50, &p95); err != nil {
+			return err
+		}
+	} else {
+		query := fmt.Sprintf(`
+            SELECT
+                percentile_cont(0.50) WITHIN GROUP (ORDER BY %s),
+                percentile_cont(0.95) WITHIN GROUP (ORDER BY %s)
+            FROM executions
+            WHERE workflow_id = $1 AND started_at >= $2 AND completed_at IS NOT NULL
+        `, db.durationMsExpr(), db.durationMsExpr())
+		if err := db.readQueryRowx(ctx, query, workflowID, since).Scan(&p50, &p95); err != nil {
+			return err
+		}
+	}
+
+	stats.DurationP50Ms = math.Round(p50.Float64)
+	stats.DurationP95Ms = math.Round(p95.Float64)
+	return nil
+}
+
+func (db *DB) executionsPerDay(ctx context.Context, workflowID uuid.UUID, since time.Time, stats *models.WorkflowStats) error {
+	query := fmt.Sprintf(`
+        SELECT CAST(DATE(started_at) AS CHAR(10)) AS day,
+               COUNT(*),
+               SUM(CASE WHEN status = 'completed' THEN 1 ELSE 0 END),
+               SUM(CASE WHEN status = 'failed' THEN 1 ELSE 0 END)
+        FROM executions
+        WHERE workflow_id = %s AND started_at >= %s
+        GROUP BY CAST(DATE(started_at) AS CHAR(10))
+        ORDER BY day
+    `, db.placeholder(1), db.placeholder(2))
+
+	rows, err := db.readQueryx(ctx, query, workflowID, since)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var day models.DailyExecutionCount
+		if err := rows.Scan(&day.Date, &day.Total, &day.Completed, &day.Failed); err != nil {
+			return err
+		}
+		stats.PerDay = append(stats.PerDay, day)
+	}
+	return rows.Err()
+}
+
+func (db *DB) nodeFailures(ctx context.Context, workflowID uuid.UUID, since time.Time, stats *models.WorkflowStats) error {
+	query := `
+        SELECT n.node_id, COUNT(*)
+        FROM node_executions n
+        JOIN executions e ON e.id = n.execution_id
+        WHERE e.workflow_id = $1 AND e.started_at >= $2 AND n.status = 'failed'
+        GROUP BY n.node_id
+        ORDER BY COUNT(*) DESC, n.node_id
+    `
+
+	rows, err := db.readQueryx(ctx, query, workflowID, since)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var failure models.NodeFailureCount
+		if err := rows.Scan(&failure.NodeID, &failure.Failures); err != nil {
+			return err
+		}
+		stats.NodeFailures = append(stats.NodeFailures, failure)
+	}
+	return rows.Err()
+}
+
+func (db *DB) lastFailure(ctx context.Context, workflowID uuid.UUID, since time.Time, stats *models.WorkflowStats) error {
+	query := fmt.Sprintf(`
+        SELECT id, COALESCE(error, ''), started_at, completed_at, context
+        FROM executions
+        WHERE workflow_id = %s AND started_at >= %s AND status = 'failed'
+        ORDER BY started_at DESC
+        LIMIT 1
+    `, db.placeholder(1), db.placeholder(2))
+
+	var failure models.ExecutionFailure
+	var contextJSON []byte
+	err := db.readQueryRowx(ctx, query, workflowID, since).Scan(
+		&failure.ExecutionID, &failure.Error, &failure.StartedAt, &failure.CompletedAt, &contextJSON)
+	if err == sql.ErrNoRows {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	// Runs are in node_executions; executions saved before it have them in
+	// their context
+	nodeQuery := fmt.Sprintf(`
+        SELECT node_id FROM node_executions
+        WHERE execution_id = %s AND status = 'failed'
+        ORDER BY started_at
+        LIMIT 1
+    `, db.placeholder(1))
+	err = db.readQueryRowx(ctx, nodeQuery, failure.ExecutionID).Scan(&failure.NodeID)
+	if err != nil && err != sql.ErrNoRows {
+		return err
+	}
+
+	if failure.NodeID == "" && len(contextJSON) > 0 {
+		var execCtx models.ExecutionContext
+		if json.Unmarshal(contextJSON, &execCtx) == nil {
+			for nodeID, nodeExec := range execCtx.NodeExecutions {
+				if nodeExec.Status == models.ExecutionStatusFailed {
+					failure.NodeID = nodeID
+					break
+				}
+			}
+		}
+	}
+
+	stats.LastFailure = &failure
+	return nil
+}
+
+// GetNodeDurations returns the average duration in milliseconds of the
+// completed runs of each node of a workflow, over executions started at or
+// after since
+func (db *DB) GetNodeDurations(ctx context.Context, workflowID uuid.UUID, since time.Time) (map[string]float64, error) {
+	if _, err := db.GetWorkflow(ctx, workflowID); err != nil {
+		return nil, err
+	}
+
+	query := fmt.Sprintf(`
+        SELECT node_id, AVG(%s)
+        FROM node_executions
+        WHERE status = 'completed' AND completed_at IS NOT NULL
+          AND execution_id IN (
+              SELECT id FROM executions WHERE workflow_id = %s AND started_at >= %s
+          )
+        GROUP BY node_id
+    `, db.durationMsExpr(), db.placeholder(1), db.placeholder(2))
+
+	rows, err := db.readQueryx(ctx, query, workflowID, since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute node durations: %w", err)
+	}
+	defer rows.Close()
+
+	durations := make(map[string]float64)
+	for rows.Next() {
+		var nodeID string
+		var avg sql.NullFloat64
+		if err := rows.Scan(&nodeID, &avg); err != nil {
+			return nil, err
+		}
+		durations[nodeID] = math.Round(avg.Float64)
+	}
+	return durations, rows.Err()
+}
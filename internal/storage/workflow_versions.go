@@ -0,0 +1,97 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/nuumz/f1ow/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+// ErrWorkflowVersionNotFound is returned when a workflow has no such version
+var ErrWorkflowVersionNotFound = errors.New("workflow version not found")
+
+// insertWorkflowVersion records the saved workflow in its version history
+func (db *DB) insertWorkflowVersion(ctx context.Context, tx *sqlx.Tx, workflow *models.Workflow, definitionJSON []byte) error {
+	query := fmt.Sprintf(`
+        INSERT INTO workflow_versions (workflow_id, version, tenant_id, name, description, definition, user_id, created_at)
+        VALUES (%s, %s, %s, %s, %s, %s, %s, %s)
+    `, db.placeholder(1), db.placeholder(2), db.placeholder(3), db.placeholder(4),
+		db.placeholder(5), db.placeholder(6), db.placeholder(7), db.placeholder(8))
+
+	if _, err := tx.ExecContext(ctx, query, workflow.ID, workflow.Version, workflow.TenantID, workflow.Name,
+		workflow.Description, definitionJSON, workflow.UserID, workflow.UpdatedAt); err != nil {
+		return fmt.Errorf("failed to record workflow version: %w", err)
+	}
+	return nil
+}
+
+// ListWorkflowVersions returns the recorded versions of a workflow, newest
+// first, without their definitions
+func (db *DB) ListWorkflowVersions(ctx context.Context, workflowID uuid.UUID) ([]models.WorkflowVersion, error) {
+	query := fmt.Sprintf(`
+        SELECT workflow_id, version, name, COALESCE(description, ''), user_id, created_at
+        FROM workflow_versions
+        WHERE workflow_id = %s`, db.placeholder(1))
+	query, args := db.scopeToTenant(ctx, query, []interface{}{workflowID}, "tenant_id")
+
+	rows, err := db.QueryxContext(ctx, query+" ORDER BY version DESC", args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	versions := []models.WorkflowVersion{}
+	for rows.Next() {
+		var version models.WorkflowVersion
+		if err := rows.Scan(&version.WorkflowID, &version.Version, &version.Name, &version.Description,
+			&version.UserID, &version.CreatedAt); err != nil {
+			return nil, err
+		}
+		versions = append(versions, version)
+	}
+	return versions, rows.Err()
+}
+
+// GetWorkflowVersion returns a version of a workflow. The current version of
+// a workflow saved before versions were recorded is read from the workflow.
+func (db *DB) GetWorkflowVersion(ctx context.Context, workflowID uuid.UUID, version int) (*models.WorkflowVersion, error) {
+	query := fmt.Sprintf(`
+        SELECT workflow_id, version, name, COALESCE(description, ''), definition, user_id, created_at
+        FROM workflow_versions
+        WHERE workflow_id = %s AND version = %s`, db.placeholder(1), db.placeholder(2))
+	query, args := db.scopeToTenant(ctx, query, []interface{}{workflowID, version}, "tenant_id")
+
+	var result models.WorkflowVersion
+	var definitionJSON []byte
+	err := db.QueryRowxContext(ctx, query, args...).Scan(&result.WorkflowID, &result.Version, &result.Name,
+		&result.Description, &definitionJSON, &result.UserID, &result.CreatedAt)
+	if err == sql.ErrNoRows {
+		workflow, err := db.GetWorkflow(ctx, workflowID)
+		if err != nil || workflow.Version != version {
+			return nil, ErrWorkflowVersionNotFound
+		}
+		return &models.WorkflowVersion{
+			WorkflowID:  workflow.ID,
+			Version:     workflow.Version,
+			Name:        workflow.Name,
+			Description: workflow.Description,
+			Definition:  workflow.Definition,
+			UserID:      workflow.UserID,
+			CreatedAt:   workflow.UpdatedAt,
+		}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(definitionJSON, &result.Definition); err != nil {
+		return nil, fmt.Errorf("failed to parse workflow definition: %w", err)
+	}
+	return &result, nil
+}
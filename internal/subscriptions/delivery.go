@@ -0,0 +1,274 @@
+package subscriptions
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/nuumz/f1ow/internal/models"
+	"github.com/nuumz/f1ow/internal/tenant"
+
+	"github.com/google/uuid"
+)
+
+// Request headers of every delivery
+const (
+	EventHeader     = "X-F1ow-Event"
+	DeliveryHeader  = "X-F1ow-Delivery"
+	TimestampHeader = "X-F1ow-Timestamp"
+	SignatureHeader = "X-F1ow-Signature"
+)
+
+const (
+	// retryLeaseKey is the lease held by the instance that retries deliveries
+	retryLeaseKey = "f1ow:subscriptions:retry"
+	// retryParallelism bounds the retries sent at once
+	retryParallelism = 10
+	// maxResponseBody is how much of a response is kept in the delivery log
+	maxResponseBody = 1024
+)
+
+// retryDelays are the waits before each retry of a failed delivery; a
+// delivery that fails once more after the last is given up
+var retryDelays = []time.Duration{time.Minute, 5 * time.Minute, 30 * time.Minute, 2 * time.Hour, 6 * time.Hour}
+
+// Event is an engine event to deliver to subscriptions
+type Event struct {
+	ID       string // the event's ID on the event bus, the same for every delivery of it
+	Type     string
+	TenantID uuid.UUID
+	Data     interface{}
+}
+
+// Locker leases retries to one instance at a time; it is implemented by
+// engine.RedisTriggerLocker
+type Locker interface {
+	Acquire(ctx context.Context, key, owner string, ttl time.Duration) (bool, error)
+}
+
+// Sign returns the signature of a delivery body sent at timestamp, Unix
+// seconds: "sha256=" and the hex HMAC-SHA256 of "<timestamp>.<body>"
+// keyed with the subscription's secret. Receivers compute it from the
+// timestamp header and the raw body, and reject old timestamps.
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "%d.", timestamp)
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// HandleEvent records a delivery of the event to each enabled subscription
+// of its tenant that wants it, and makes the first attempts. Failed
+// attempts are retried by Run.
+func (m *Manager) HandleEvent(ctx context.Context, event Event) error {
+	ctx = tenant.WithID(ctx, event.TenantID)
+	subscriptions, err := m.store.ListEventSubscriptions(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list event subscriptions: %w", err)
+	}
+
+	var payload []byte
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	for i := range subscriptions {
+		subscription := &subscriptions[i]
+		if !subscription.Enabled || !matches(subscription, event.Type) {
+			continue
+		}
+		if payload == nil {
+			if payload, err = json.Marshal(map[string]interface{}{"id": event.ID, "type": event.Type, "data": event.Data}); err != nil {
+				return fmt.Errorf("failed to marshal event %s: %w", event.ID, err)
+			}
+		}
+
+		delivery := &models.EventDelivery{
+			ID:             uuid.New(),
+			TenantID:       event.TenantID,
+			SubscriptionID: subscription.ID,
+			EventID:        event.ID,
+			EventType:      event.Type,
+			Payload:        payload,
+			Status:         models.EventDeliveryPending,
+			CreatedAt:      time.Now(),
+		}
+		if err := m.store.CreateEventDelivery(ctx, delivery); err != nil {
+			return fmt.Errorf("failed to record delivery of event %s: %w", event.ID, err)
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			m.attempt(ctx, subscription, delivery)
+		}()
+	}
+	return nil
+}
+
+// Redeliver sends a logged delivery's payload to its subscription again as
+// a new delivery, and returns the new delivery after its first attempt
+func (m *Manager) Redeliver(ctx context.Context, subscriptionID, deliveryID uuid.UUID) (*models.EventDelivery, error) {
+	subscription, err := m.store.GetEventSubscription(ctx, subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+	original, err := m.store.GetEventDelivery(ctx, deliveryID)
+	if err != nil {
+		return nil, err
+	}
+	if original.SubscriptionID != subscription.ID {
+		return nil, ErrDeliveryNotFound
+	}
+
+	delivery := &models.EventDelivery{
+		ID:             uuid.New(),
+		TenantID:       subscription.TenantID,
+		SubscriptionID: subscription.ID,
+		EventID:        original.EventID,
+		EventType:      original.EventType,
+		Payload:        original.Payload,
+		Status:         models.EventDeliveryPending,
+		CreatedAt:      time.Now(),
+	}
+	if err := m.store.CreateEventDelivery(ctx, delivery); err != nil {
+		return nil, fmt.Errorf("failed to record delivery: %w", err)
+	}
+	m.attempt(ctx, subscription, delivery)
+	return delivery, nil
+}
+
+// Run retries due deliveries every interval until ctx is done. With a
+// locker, only the instance holding the lease retries.
+func (m *Manager) Run(ctx context.Context, interval time.Duration, locker Locker) {
+	owner := uuid.New().String()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if locker != nil {
+			held, err := locker.Acquire(ctx, retryLeaseKey, owner, 2*interval)
+			if err != nil {
+				m.logger.Errorf("Failed to take the event delivery retry lease: %v", err)
+				continue
+			}
+			if !held {
+				continue
+			}
+		}
+		if _, err := m.RetryDue(ctx); err != nil {
+			m.logger.Errorf("Failed to retry event deliveries: %v", err)
+		}
+	}
+}
+
+// RetryDue makes the next attempt of every delivery whose retry is due and
+// returns how many it attempted
+func (m *Manager) RetryDue(ctx context.Context) (int, error) {
+	deliveries, err := m.store.ListDueEventDeliveries(ctx, time.Now(), 100)
+	if err != nil {
+		return 0, err
+	}
+
+	slots := make(chan struct{}, retryParallelism)
+	var wg sync.WaitGroup
+	for i := range deliveries {
+		delivery := &deliveries[i]
+		deliveryCtx := tenant.WithID(ctx, delivery.TenantID)
+		subscription, err := m.store.GetEventSubscription(deliveryCtx, delivery.SubscriptionID)
+		if errors.Is(err, ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return 0, err
+		}
+		if !subscription.Enabled {
+			delivery.Status, delivery.NextAttemptAt, delivery.Error = models.EventDeliveryFailed, nil, "subscription is disabled"
+			if err := m.store.UpdateEventDelivery(deliveryCtx, delivery); err != nil {
+				m.logger.Errorf("Failed to update event delivery %s: %v", delivery.ID, err)
+			}
+			continue
+		}
+
+		slots <- struct{}{}
+		wg.Add(1)
+		go func() {
+			defer func() { <-slots; wg.Done() }()
+			m.attempt(deliveryCtx, subscription, delivery)
+		}()
+	}
+	wg.Wait()
+	return len(deliveries), nil
+}
+
+// attempt sends the delivery once and records the outcome, scheduling the
+// next retry or giving up after the last
+func (m *Manager) attempt(ctx context.Context, subscription *models.EventSubscription, delivery *models.EventDelivery) {
+	status, body, err := m.send(ctx, subscription, delivery)
+
+	now := time.Now()
+	delivery.Attempts++
+	delivery.ResponseStatus, delivery.ResponseBody = nil, body
+	if status != 0 {
+		delivery.ResponseStatus = &status
+	}
+	switch {
+	case err == nil:
+		delivery.Status, delivery.Error = models.EventDeliverySucceeded, ""
+		delivery.DeliveredAt, delivery.NextAttemptAt = &now, nil
+	case delivery.Attempts > len(retryDelays):
+		delivery.Status, delivery.Error, delivery.NextAttemptAt = models.EventDeliveryFailed, err.Error(), nil
+	default:
+		next := now.Add(retryDelays[delivery.Attempts-1])
+		delivery.Status, delivery.Error, delivery.NextAttemptAt = models.EventDeliveryPending, err.Error(), &next
+	}
+
+	if err := m.store.UpdateEventDelivery(context.WithoutCancel(ctx), delivery); err != nil {
+		m.logger.Errorf("Failed to update event delivery %s: %v", delivery.ID, err)
+	}
+}
+
+// send posts the delivery's payload to the subscription's URL and returns
+// the response status and the start of its body
+func (m *Manager) send(ctx context.Context, subscription *models.EventSubscription, delivery *models.EventDelivery) (int, string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, subscription.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, "", fmt.Errorf("failed to create request: %w", err)
+	}
+	timestamp := time.Now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "f1ow-webhooks")
+	req.Header.Set(EventHeader, delivery.EventType)
+	req.Header.Set(DeliveryHeader, delivery.ID.String())
+	req.Header.Set(TimestampHeader, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(SignatureHeader, Sign(subscription.Secret, timestamp, delivery.Payload))
+
+	client := m.client
+	if m.network != nil {
+		client = m.network.Client(client)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, "", err
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxResponseBody))
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, string(body), fmt.Errorf("endpoint returned status %d", resp.StatusCode)
+	}
+	return resp.StatusCode, string(body), nil
+}
@@ -0,0 +1,205 @@
+// Package subscriptions calls user-registered URLs with engine events,
+// signing each request and retrying failed deliveries with backoff.
+package subscriptions
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/nuumz/f1ow/internal/models"
+	"github.com/nuumz/f1ow/internal/netpolicy"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+var (
+	// ErrNotFound is returned when a subscription does not exist
+	ErrNotFound = errors.New("event subscription not found")
+	// ErrDeliveryNotFound is returned when a delivery does not exist
+	ErrDeliveryNotFound = errors.New("event delivery not found")
+	// ErrInvalid is returned for subscriptions with a bad URL or event list
+	ErrInvalid = errors.New("invalid event subscription")
+)
+
+// SecretPrefix starts every generated signing secret
+const SecretPrefix = "whsec_"
+
+// Store persists subscriptions and their deliveries; it is implemented by
+// storage.DB
+type Store interface {
+	CreateEventSubscription(ctx context.Context, subscription *models.EventSubscription) error
+	GetEventSubscription(ctx context.Context, id uuid.UUID) (*models.EventSubscription, error)
+	ListEventSubscriptions(ctx context.Context) ([]models.EventSubscription, error)
+	UpdateEventSubscription(ctx context.Context, subscription *models.EventSubscription) error
+	DeleteEventSubscription(ctx context.Context, id uuid.UUID) error
+
+	CreateEventDelivery(ctx context.Context, delivery *models.EventDelivery) error
+	UpdateEventDelivery(ctx context.Context, delivery *models.EventDelivery) error
+	GetEventDelivery(ctx context.Context, id uuid.UUID) (*models.EventDelivery, error)
+	ListEventDeliveries(ctx context.Context, subscriptionID uuid.UUID, limit int) ([]models.EventDelivery, error)
+
+	// ListDueEventDeliveries returns pending deliveries of every tenant
+	// whose next attempt is at or before now, oldest first
+	ListDueEventDeliveries(ctx context.Context, now time.Time, limit int) ([]models.EventDelivery, error)
+}
+
+// Manager stores event subscriptions and delivers events to them
+type Manager struct {
+	store   Store
+	client  *http.Client
+	network *netpolicy.Policy
+	logger  *logrus.Logger
+}
+
+// NewManager creates a subscriptions manager
+func NewManager(store Store, logger *logrus.Logger) *Manager {
+	return &Manager{
+		store:  store,
+		client: &http.Client{Timeout: 10 * time.Second},
+		logger: logger,
+	}
+}
+
+// SetNetworkPolicy checks the addresses deliveries connect to against policy
+func (m *Manager) SetNetworkPolicy(policy *netpolicy.Policy) {
+	m.network = policy
+}
+
+// Create stores a new subscription in the context's tenant. Without a
+// secret one is generated; the returned subscription is the only place
+// it is shown.
+func (m *Manager) Create(ctx context.Context, subscription *models.EventSubscription) (*models.EventSubscription, error) {
+	if err := validate(subscription); err != nil {
+		return nil, err
+	}
+	if subscription.Secret == "" {
+		secret, err := generateSecret()
+		if err != nil {
+			return nil, err
+		}
+		subscription.Secret = secret
+	}
+
+	now := time.Now()
+	subscription.ID = uuid.New()
+	subscription.CreatedAt = now
+	subscription.UpdatedAt = now
+	if err := m.store.CreateEventSubscription(ctx, subscription); err != nil {
+		return nil, fmt.Errorf("failed to save event subscription: %w", err)
+	}
+	return subscription, nil
+}
+
+// Get returns a subscription without its secret
+func (m *Manager) Get(ctx context.Context, id uuid.UUID) (*models.EventSubscription, error) {
+	subscription, err := m.store.GetEventSubscription(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	subscription.Secret = ""
+	return subscription, nil
+}
+
+// List returns the context's tenant's subscriptions without their secrets
+func (m *Manager) List(ctx context.Context) ([]models.EventSubscription, error) {
+	list, err := m.store.ListEventSubscriptions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for i := range list {
+		list[i].Secret = ""
+	}
+	return list, nil
+}
+
+// Update replaces a subscription's URL, description, events, and enabled
+// flag. The secret is replaced only when one is given.
+func (m *Manager) Update(ctx context.Context, subscription *models.EventSubscription) (*models.EventSubscription, error) {
+	existing, err := m.store.GetEventSubscription(ctx, subscription.ID)
+	if err != nil {
+		return nil, err
+	}
+	if err := validate(subscription); err != nil {
+		return nil, err
+	}
+	if subscription.Secret == "" {
+		subscription.Secret = existing.Secret
+	}
+	subscription.CreatedAt = existing.CreatedAt
+	subscription.UpdatedAt = time.Now()
+	if err := m.store.UpdateEventSubscription(ctx, subscription); err != nil {
+		return nil, err
+	}
+
+	updated := *subscription
+	updated.Secret = ""
+	return &updated, nil
+}
+
+// Delete removes a subscription and its delivery log
+func (m *Manager) Delete(ctx context.Context, id uuid.UUID) error {
+	return m.store.DeleteEventSubscription(ctx, id)
+}
+
+// Deliveries returns the subscription's most recent deliveries
+func (m *Manager) Deliveries(ctx context.Context, subscriptionID uuid.UUID, limit int) ([]models.EventDelivery, error) {
+	if _, err := m.store.GetEventSubscription(ctx, subscriptionID); err != nil {
+		return nil, err
+	}
+	if limit <= 0 {
+		limit = 50
+	}
+	if limit > 500 {
+		limit = 500
+	}
+	return m.store.ListEventDeliveries(ctx, subscriptionID, limit)
+}
+
+func validate(subscription *models.EventSubscription) error {
+	target, err := url.Parse(subscription.URL)
+	if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+		return fmt.Errorf("%w: url must be an absolute http or https URL", ErrInvalid)
+	}
+	if len(subscription.Events) == 0 {
+		return fmt.Errorf("%w: at least one event is required", ErrInvalid)
+	}
+	for _, event := range subscription.Events {
+		if event == "*" {
+			continue
+		}
+		category, name, ok := strings.Cut(event, ".")
+		if !ok || category == "" || name == "" || strings.ContainsAny(event, " /") {
+			return fmt.Errorf("%w: %q is not an event type, a prefix such as execution.*, or *", ErrInvalid, event)
+		}
+	}
+	return nil
+}
+
+// matches reports whether the subscription wants events of eventType
+func matches(subscription *models.EventSubscription, eventType string) bool {
+	for _, pattern := range subscription.Events {
+		switch {
+		case pattern == "*", pattern == eventType:
+			return true
+		case strings.HasSuffix(pattern, ".*") && strings.HasPrefix(eventType, strings.TrimSuffix(pattern, "*")):
+			return true
+		}
+	}
+	return false
+}
+
+func generateSecret() (string, error) {
+	secret := make([]byte, 24)
+	if _, err := rand.Read(secret); err != nil {
+		return "", fmt.Errorf("failed to generate signing secret: %w", err)
+	}
+	return SecretPrefix + hex.EncodeToString(secret), nil
+}
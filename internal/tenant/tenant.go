@@ -0,0 +1,37 @@
+// Package tenant carries the tenant an operation is scoped to. Storage
+// filters every tenant-owned query by the tenant in the context; contexts
+// without a tenant belong to trusted system callers such as workers and
+// trigger managers and are not filtered.
+package tenant
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// DefaultID is the tenant that owns data created before multi-tenancy and
+// requests made without a tenant claim
+var DefaultID = uuid.MustParse("00000000-0000-0000-0000-000000000001")
+
+type contextKey struct{}
+
+// WithID returns a context scoped to the tenant
+func WithID(ctx context.Context, id uuid.UUID) context.Context {
+	return context.WithValue(ctx, contextKey{}, id)
+}
+
+// FromContext returns the tenant the context is scoped to, if any
+func FromContext(ctx context.Context) (uuid.UUID, bool) {
+	id, ok := ctx.Value(contextKey{}).(uuid.UUID)
+	return id, ok
+}
+
+// IDOrDefault returns the context's tenant, or DefaultID when unscoped. Use
+// it when writing new tenant-owned rows.
+func IDOrDefault(ctx context.Context) uuid.UUID {
+	if id, ok := FromContext(ctx); ok {
+		return id
+	}
+	return DefaultID
+}
@@ -0,0 +1,178 @@
+package triggers
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/nuumz/f1ow/internal/models"
+	"github.com/nuumz/f1ow/internal/nodes"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+	"github.com/sirupsen/logrus"
+)
+
+// AMQPTrigger consumes a RabbitMQ queue and starts one execution per message.
+// In "execution" ack mode the message is acknowledged when the execution
+// succeeds and rejected when it fails; in "receipt" mode it is acknowledged
+// once the execution has been queued.
+type AMQPTrigger struct {
+	workflowID string
+	nodeID     string
+	config     *nodes.AMQPTriggerConfig
+	execute    ExecuteFunc
+	logger     *logrus.Logger
+	cancel     context.CancelFunc
+	wg         sync.WaitGroup
+}
+
+// NewAMQPTriggerFactory returns a factory for amqp triggers. execute runs an
+// execution to completion and is used in "execution" ack mode.
+func NewAMQPTriggerFactory(execute ExecuteFunc, logger *logrus.Logger) Factory {
+	return func(workflowID string, node models.Node) (Trigger, error) {
+		config, err := nodes.ParseAMQPTriggerConfig(node.Config)
+		if err != nil {
+			return nil, err
+		}
+
+		return &AMQPTrigger{
+			workflowID: workflowID,
+			nodeID:     node.ID,
+			config:     config,
+			execute:    execute,
+			logger:     logger,
+		}, nil
+	}
+}
+
+// Start begins consuming in the background, reconnecting after failures
+func (t *AMQPTrigger) Start(ctx context.Context, emit EmitFunc) error {
+	ctx, t.cancel = context.WithCancel(ctx)
+
+	t.wg.Add(1)
+	go func() {
+		defer t.wg.Done()
+
+		for ctx.Err() == nil {
+			if err := t.consume(ctx, emit); err != nil && ctx.Err() == nil {
+				t.logger.Errorf("AMQP trigger %s/%s consume failed: %v", t.workflowID, t.nodeID, err)
+			}
+
+			select {
+			case <-ctx.Done():
+			case <-time.After(5 * time.Second):
+			}
+		}
+	}()
+
+	return nil
+}
+
+// Stop cancels consumption and waits for in-flight executions
+func (t *AMQPTrigger) Stop() error {
+	if t.cancel != nil {
+		t.cancel()
+	}
+	t.wg.Wait()
+	return nil
+}
+
+// consume runs a single connection session until it fails or ctx is done
+func (t *AMQPTrigger) consume(ctx context.Context, emit EmitFunc) error {
+	conn, err := t.config.AMQPConnection.Dial(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	ch, err := conn.Channel()
+	if err != nil {
+		return fmt.Errorf("failed to open channel: %w", err)
+	}
+	defer ch.Close()
+
+	if err := ch.Qos(t.config.Prefetch, 0, false); err != nil {
+		return fmt.Errorf("failed to set prefetch: %w", err)
+	}
+
+	consumerTag := "f1ow-" + t.workflowID + "-" + t.nodeID
+	deliveries, err := ch.Consume(t.config.Queue, consumerTag, false, false, false, false, nil)
+	if err != nil {
+		return fmt.Errorf("failed to consume queue %s: %w", t.config.Queue, err)
+	}
+
+	// Process up to prefetch deliveries concurrently
+	var inFlight sync.WaitGroup
+	defer inFlight.Wait()
+	slots := make(chan struct{}, t.config.Prefetch)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case delivery, ok := <-deliveries:
+			if !ok {
+				return fmt.Errorf("delivery channel closed")
+			}
+
+			slots <- struct{}{}
+			inFlight.Add(1)
+			go func(delivery amqp.Delivery) {
+				defer func() {
+					<-slots
+					inFlight.Done()
+				}()
+				t.handle(ctx, emit, delivery)
+			}(delivery)
+		}
+	}
+}
+
+// handle starts an execution for a delivery and acknowledges it
+func (t *AMQPTrigger) handle(ctx context.Context, emit EmitFunc, delivery amqp.Delivery) {
+	payload := t.payload(delivery)
+
+	var err error
+	if t.config.AckMode == "execution" && t.execute != nil {
+		err = t.execute(ctx, t.workflowID, payload)
+	} else {
+		err = emit(ctx, t.workflowID, payload)
+	}
+
+	if err != nil {
+		t.logger.Errorf("AMQP trigger %s/%s execution failed for message %s: %v",
+			t.workflowID, t.nodeID, delivery.MessageId, err)
+		// Messages interrupted by shutdown are always requeued
+		requeue := t.config.RequeueOnFailure || ctx.Err() != nil
+		if nackErr := delivery.Nack(false, requeue); nackErr != nil {
+			t.logger.Errorf("Failed to reject message %s: %v", delivery.MessageId, nackErr)
+		}
+		return
+	}
+
+	if ackErr := delivery.Ack(false); ackErr != nil {
+		t.logger.Errorf("Failed to acknowledge message %s: %v", delivery.MessageId, ackErr)
+	}
+}
+
+// payload converts a delivery into an execution payload
+func (t *AMQPTrigger) payload(delivery amqp.Delivery) map[string]interface{} {
+	headers := make(map[string]interface{}, len(delivery.Headers))
+	for key, value := range delivery.Headers {
+		headers[key] = value
+	}
+
+	return map[string]interface{}{
+		"body":           nodes.DecodeAMQPMessage(t.config.Format, delivery.Body),
+		"headers":        headers,
+		"exchange":       delivery.Exchange,
+		"routing_key":    delivery.RoutingKey,
+		"message_id":     delivery.MessageId,
+		"correlation_id": delivery.CorrelationId,
+		"reply_to":       delivery.ReplyTo,
+		"content_type":   delivery.ContentType,
+		"redelivered":    delivery.Redelivered,
+		"timestamp":      delivery.Timestamp.Format(time.RFC3339),
+	}
+}
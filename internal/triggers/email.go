@@ -0,0 +1,388 @@
+package triggers
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nuumz/f1ow/internal/models"
+	"github.com/nuumz/f1ow/internal/nodes"
+
+	"github.com/emersion/go-imap"
+	"github.com/emersion/go-imap/client"
+	"github.com/sirupsen/logrus"
+)
+
+// emailSeenTTL bounds how long processed message IDs are remembered
+const emailSeenTTL = 90 * 24 * time.Hour
+
+// emailDialTimeout bounds connecting to the IMAP server and reading its greeting
+const emailDialTimeout = 30 * time.Second
+
+// EmailTrigger polls an IMAP mailbox and emits one execution per new message
+type EmailTrigger struct {
+	workflowID string
+	nodeID     string
+	config     *nodes.EmailTriggerConfig
+	seen       nodes.SeenStore
+	logger     *logrus.Logger
+	cancel     context.CancelFunc
+	wg         sync.WaitGroup
+}
+
+// NewEmailTriggerFactory returns a factory for email triggers that track
+// processed messages in the given seen store
+func NewEmailTriggerFactory(seen nodes.SeenStore, logger *logrus.Logger) Factory {
+	return func(workflowID string, node models.Node) (Trigger, error) {
+		config, err := nodes.ParseEmailTriggerConfig(node.Config)
+		if err != nil {
+			return nil, err
+		}
+
+		return &EmailTrigger{
+			workflowID: workflowID,
+			nodeID:     node.ID,
+			config:     config,
+			seen:       seen,
+			logger:     logger,
+		}, nil
+	}
+}
+
+// Start begins polling the mailbox in the background
+func (t *EmailTrigger) Start(ctx context.Context, emit EmitFunc) error {
+	ctx, t.cancel = context.WithCancel(ctx)
+
+	t.wg.Add(1)
+	go func() {
+		defer t.wg.Done()
+
+		ticker := time.NewTicker(time.Duration(t.config.PollInterval) * time.Second)
+		defer ticker.Stop()
+
+		for {
+			if err := t.poll(ctx, emit); err != nil {
+				t.logger.Errorf("Email trigger %s/%s poll failed: %v", t.workflowID, t.nodeID, err)
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+
+	return nil
+}
+
+// Stop stops polling and waits for an in-flight poll to finish
+func (t *EmailTrigger) Stop() error {
+	if t.cancel != nil {
+		t.cancel()
+	}
+	t.wg.Wait()
+	return nil
+}
+
+// poll fetches new messages and emits an execution for each
+func (t *EmailTrigger) poll(ctx context.Context, emit EmitFunc) error {
+	c, err := t.connect(ctx)
+	if err != nil {
+		return err
+	}
+	defer c.Logout()
+
+	mbox, err := c.Select(t.config.Mailbox, false)
+	if err != nil {
+		return fmt.Errorf("failed to select mailbox %s: %w", t.config.Mailbox, err)
+	}
+
+	criteria := imap.NewSearchCriteria()
+	if t.config.Search == "unseen" {
+		criteria.WithoutFlags = []string{imap.SeenFlag}
+	}
+
+	uids, err := c.UidSearch(criteria)
+	if err != nil {
+		return fmt.Errorf("failed to search mailbox: %w", err)
+	}
+	if len(uids) == 0 {
+		return nil
+	}
+
+	// Claim messages in the seen store so other pollers skip them
+	keys := make([]string, len(uids))
+	for i, uid := range uids {
+		keys[i] = fmt.Sprintf("%d:%d", mbox.UidValidity, uid)
+	}
+
+	scope := "email:" + t.workflowID + ":" + t.nodeID
+	seen, err := t.seen.MarkSeen(ctx, scope, keys, emailSeenTTL)
+	if err != nil {
+		return fmt.Errorf("failed to check seen messages: %w", err)
+	}
+
+	seqset := new(imap.SeqSet)
+	count := 0
+	for i, uid := range uids {
+		if seen[i] || count >= t.config.MaxMessages {
+			continue
+		}
+		seqset.AddNum(uid)
+		count++
+	}
+	if count == 0 {
+		return nil
+	}
+
+	section := &imap.BodySectionName{Peek: true}
+	items := []imap.FetchItem{section.FetchItem(), imap.FetchUid}
+
+	messages := make(chan *imap.Message, 10)
+	done := make(chan error, 1)
+	go func() {
+		done <- c.UidFetch(seqset, items, messages)
+	}()
+
+	for msg := range messages {
+		body := msg.GetBody(section)
+		if body == nil {
+			continue
+		}
+
+		payload, err := ParseEmailMessage(body, t.config.DownloadAttachments)
+		if err != nil {
+			t.logger.Errorf("Failed to parse message %d: %v", msg.Uid, err)
+			continue
+		}
+		payload["uid"] = msg.Uid
+		payload["mailbox"] = t.config.Mailbox
+
+		if err := emit(ctx, t.workflowID, payload); err != nil {
+			t.logger.Errorf("Failed to start execution for message %d: %v", msg.Uid, err)
+		}
+	}
+
+	if err := <-done; err != nil {
+		return fmt.Errorf("failed to fetch messages: %w", err)
+	}
+
+	if t.config.MarkSeen {
+		flags := []interface{}{imap.SeenFlag}
+		if err := c.UidStore(seqset, imap.FormatFlagsOp(imap.AddFlags, true), flags, nil); err != nil {
+			return fmt.Errorf("failed to mark messages as seen: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// connect dials and authenticates against the IMAP server, through the
+// network policy in ctx, if any
+func (t *EmailTrigger) connect(ctx context.Context) (*client.Client, error) {
+	addr := fmt.Sprintf("%s:%d", t.config.Host, t.config.Port)
+	tlsConfig := &tls.Config{
+		ServerName:         t.config.Host,
+		InsecureSkipVerify: t.config.IgnoreSSLIssues,
+	}
+
+	dialCtx, cancel := context.WithTimeout(ctx, emailDialTimeout)
+	defer cancel()
+	conn, err := nodes.Dialer(ctx)(dialCtx, "tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to %s: %w", addr, err)
+	}
+	if t.config.Security == "tls" {
+		tlsConn := tls.Client(conn, tlsConfig)
+		if err := tlsConn.HandshakeContext(dialCtx); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("failed to connect to %s: %w", addr, err)
+		}
+		conn = tlsConn
+	}
+
+	// The greeting is read before New returns
+	conn.SetDeadline(time.Now().Add(emailDialTimeout))
+	c, err := client.New(conn)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to connect to %s: %w", addr, err)
+	}
+	conn.SetDeadline(time.Time{})
+
+	if t.config.Security == "starttls" {
+		if err := c.StartTLS(tlsConfig); err != nil {
+			c.Logout()
+			return nil, fmt.Errorf("failed to start TLS: %w", err)
+		}
+	}
+
+	if err := c.Login(t.config.Username, t.config.Password); err != nil {
+		c.Logout()
+		return nil, fmt.Errorf("failed to login: %w", err)
+	}
+
+	return c, nil
+}
+
+// ParseEmailMessage parses a raw RFC 5322 message into a trigger payload with
+// headers, text and html bodies, and attachments
+func ParseEmailMessage(r io.Reader, includeAttachments bool) (map[string]interface{}, error) {
+	msg, err := mail.ReadMessage(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read message: %w", err)
+	}
+
+	decoder := new(mime.WordDecoder)
+	decodeHeader := func(value string) string {
+		if decoded, err := decoder.DecodeHeader(value); err == nil {
+			return decoded
+		}
+		return value
+	}
+
+	headers := make(map[string]interface{})
+	for key, values := range msg.Header {
+		if len(values) == 1 {
+			headers[key] = decodeHeader(values[0])
+		} else {
+			decoded := make([]string, len(values))
+			for i, v := range values {
+				decoded[i] = decodeHeader(v)
+			}
+			headers[key] = decoded
+		}
+	}
+
+	payload := map[string]interface{}{
+		"message_id":  strings.Trim(msg.Header.Get("Message-Id"), "<>"),
+		"subject":     decodeHeader(msg.Header.Get("Subject")),
+		"from":        addressList(msg.Header, "From"),
+		"to":          addressList(msg.Header, "To"),
+		"cc":          addressList(msg.Header, "Cc"),
+		"headers":     headers,
+		"text":        "",
+		"html":        "",
+		"attachments": []interface{}{},
+	}
+
+	if date, err := msg.Header.Date(); err == nil {
+		payload["date"] = date.Format(time.RFC3339)
+	}
+
+	parts := &messageParts{includeAttachments: includeAttachments, attachments: []interface{}{}}
+	if err := parts.walk(msg.Header.Get("Content-Type"), msg.Header.Get("Content-Transfer-Encoding"), "", msg.Body); err != nil {
+		return nil, err
+	}
+
+	payload["text"] = parts.text
+	payload["html"] = parts.html
+	payload["attachments"] = parts.attachments
+
+	return payload, nil
+}
+
+// addressList parses an address header into a list of {name, address} objects
+func addressList(header mail.Header, key string) []interface{} {
+	result := []interface{}{}
+	addresses, err := header.AddressList(key)
+	if err != nil {
+		return result
+	}
+
+	for _, addr := range addresses {
+		result = append(result, map[string]interface{}{
+			"name":    addr.Name,
+			"address": addr.Address,
+		})
+	}
+
+	return result
+}
+
+// messageParts accumulates bodies and attachments while walking a MIME tree
+type messageParts struct {
+	includeAttachments bool
+	text               string
+	html               string
+	attachments        []interface{}
+}
+
+// walk processes a MIME entity, recursing into multipart bodies
+func (p *messageParts) walk(contentType, transferEncoding, disposition string, body io.Reader) error {
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		mediaType = "text/plain"
+		params = map[string]string{}
+	}
+
+	if strings.HasPrefix(mediaType, "multipart/") {
+		reader := multipart.NewReader(body, params["boundary"])
+		for {
+			part, err := reader.NextRawPart()
+			if err == io.EOF {
+				return nil
+			}
+			if err != nil {
+				return fmt.Errorf("failed to read multipart body: %w", err)
+			}
+			if err := p.walk(part.Header.Get("Content-Type"), part.Header.Get("Content-Transfer-Encoding"),
+				part.Header.Get("Content-Disposition"), part); err != nil {
+				return err
+			}
+		}
+	}
+
+	content, err := io.ReadAll(decodeTransferEncoding(transferEncoding, body))
+	if err != nil {
+		return fmt.Errorf("failed to read message part: %w", err)
+	}
+
+	dispositionType, dispositionParams, _ := mime.ParseMediaType(disposition)
+	fileName := dispositionParams["filename"]
+	if fileName == "" {
+		fileName = params["name"]
+	}
+
+	isAttachment := dispositionType == "attachment" || fileName != ""
+	switch {
+	case !isAttachment && mediaType == "text/plain":
+		p.text += string(content)
+	case !isAttachment && mediaType == "text/html":
+		p.html += string(content)
+	default:
+		attachment := map[string]interface{}{
+			"file_name": fileName,
+			"mime_type": mediaType,
+			"size":      len(content),
+		}
+		if p.includeAttachments {
+			attachment["data"] = base64.StdEncoding.EncodeToString(content)
+		}
+		p.attachments = append(p.attachments, attachment)
+	}
+
+	return nil
+}
+
+// decodeTransferEncoding wraps a reader according to Content-Transfer-Encoding
+func decodeTransferEncoding(encoding string, r io.Reader) io.Reader {
+	switch strings.ToLower(strings.TrimSpace(encoding)) {
+	case "base64":
+		return base64.NewDecoder(base64.StdEncoding, r)
+	case "quoted-printable":
+		return quotedprintable.NewReader(r)
+	default:
+		return r
+	}
+}
@@ -0,0 +1,232 @@
+package triggers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/nuumz/f1ow/internal/models"
+	"github.com/nuumz/f1ow/internal/nodes"
+
+	"github.com/sirupsen/logrus"
+)
+
+// fileWatchState is the persisted listing of a file watch trigger: the
+// size and modification time of each file already handled, by path
+type fileWatchState struct {
+	Files       map[string]string `json:"files"`
+	Initialized bool              `json:"initialized"`
+}
+
+// FileWatchTrigger lists a local, SFTP, or S3 directory on an interval and
+// emits one execution per new or modified file. The node loads the file
+// content when the execution runs.
+type FileWatchTrigger struct {
+	workflowID string
+	nodeID     string
+	rootDir    string
+	config     *nodes.FileWatchTriggerConfig
+	state      nodes.CacheStore
+	logger     *logrus.Logger
+	cancel     context.CancelFunc
+	wg         sync.WaitGroup
+}
+
+// NewFileWatchTriggerFactory returns a factory for file watch triggers that
+// resolve local directories inside rootDir and persist their listing in the
+// state store
+func NewFileWatchTriggerFactory(rootDir string, state nodes.CacheStore, logger *logrus.Logger) Factory {
+	return func(workflowID string, node models.Node) (Trigger, error) {
+		if state == nil {
+			return nil, fmt.Errorf("file watch triggers need Redis to keep their state")
+		}
+		if err := nodes.NewFileWatchTriggerNode(rootDir).ValidateConfig(node.Config); err != nil {
+			return nil, err
+		}
+		config, err := nodes.ParseFileWatchTriggerConfig(node.Config)
+		if err != nil {
+			return nil, err
+		}
+
+		return &FileWatchTrigger{
+			workflowID: workflowID,
+			nodeID:     node.ID,
+			rootDir:    rootDir,
+			config:     config,
+			state:      state,
+			logger:     logger,
+		}, nil
+	}
+}
+
+// Start begins listing the directory in the background
+func (t *FileWatchTrigger) Start(ctx context.Context, emit EmitFunc) error {
+	ctx, t.cancel = context.WithCancel(ctx)
+
+	t.wg.Add(1)
+	go func() {
+		defer t.wg.Done()
+
+		ticker := time.NewTicker(time.Duration(t.config.PollInterval) * time.Second)
+		defer ticker.Stop()
+
+		for {
+			if err := t.Poll(ctx, emit); err != nil {
+				t.logger.Errorf("File watch trigger %s/%s poll failed: %v", t.workflowID, t.nodeID, err)
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+
+	return nil
+}
+
+// Stop stops listing and waits for an in-flight poll to finish
+func (t *FileWatchTrigger) Stop() error {
+	if t.cancel != nil {
+		t.cancel()
+	}
+	t.wg.Wait()
+	return nil
+}
+
+// Poll lists the directory once and emits an execution for each new or
+// modified file, oldest first. The first poll only records the existing
+// files unless emit_existing is set.
+func (t *FileWatchTrigger) Poll(ctx context.Context, emit EmitFunc) error {
+	state, err := t.loadState(ctx)
+	if err != nil {
+		return err
+	}
+
+	source, err := nodes.OpenFileSource(ctx, t.config.Source, t.rootDir, t.config.SFTP, t.config.S3)
+	if err != nil {
+		return err
+	}
+	files, err := source.List(ctx, t.config.Path, t.config.Recursive)
+	source.Close()
+	if err != nil {
+		return err
+	}
+
+	// Files that disappeared are forgotten, so they count as created if
+	// they come back
+	handled := make(map[string]string, len(files))
+	var changed []nodes.WatchedFile
+	now := time.Now()
+	for _, file := range files {
+		if !t.config.Matches(file.Path) {
+			continue
+		}
+		previous, seen := state.Files[file.Path]
+		if seen {
+			handled[file.Path] = previous
+		}
+		if seen && previous == fileSignature(file) {
+			continue
+		}
+		// Skip files that may still be being written until they settle
+		if t.config.MinAge > 0 && now.Sub(file.ModTime) < time.Duration(t.config.MinAge)*time.Second {
+			continue
+		}
+		changed = append(changed, file)
+	}
+
+	sort.Slice(changed, func(i, j int) bool {
+		if !changed[i].ModTime.Equal(changed[j].ModTime) {
+			return changed[i].ModTime.Before(changed[j].ModTime)
+		}
+		return changed[i].Path < changed[j].Path
+	})
+
+	skip := !state.Initialized && !t.config.EmitExisting
+	emitted := 0
+	for _, file := range changed {
+		if skip {
+			handled[file.Path] = fileSignature(file)
+			continue
+		}
+		if emitted >= t.config.MaxFiles {
+			break
+		}
+
+		event := "modified"
+		if _, seen := state.Files[file.Path]; !seen {
+			event = "created"
+		}
+		if t.config.WantsEvent(event) {
+			if err = emit(ctx, t.workflowID, t.payload(event, file)); err != nil {
+				err = fmt.Errorf("failed to start execution: %w", err)
+				break
+			}
+			emitted++
+		}
+		handled[file.Path] = fileSignature(file)
+	}
+
+	// Keep the progress made before an error so emitted files are not repeated
+	state.Files = handled
+	state.Initialized = true
+	if saveErr := t.saveState(ctx, state); saveErr != nil && err == nil {
+		err = saveErr
+	}
+	return err
+}
+
+// payload describes a changed file; the node reads its content
+func (t *FileWatchTrigger) payload(event string, file nodes.WatchedFile) map[string]interface{} {
+	return map[string]interface{}{
+		"event":       event,
+		"source":      t.config.Source,
+		"path":        file.Path,
+		"name":        path.Base(file.Path),
+		"size":        file.Size,
+		"modified_at": file.ModTime.UTC().Format(time.RFC3339),
+	}
+}
+
+func (t *FileWatchTrigger) stateKey() string {
+	return "trigger:filewatch:" + t.workflowID + ":" + t.nodeID
+}
+
+func (t *FileWatchTrigger) loadState(ctx context.Context) (*fileWatchState, error) {
+	state := &fileWatchState{}
+	data, found, err := t.state.GetCache(ctx, t.stateKey())
+	if err != nil {
+		return nil, fmt.Errorf("failed to load file watch state: %w", err)
+	}
+	if found {
+		if err := json.Unmarshal(data, state); err != nil {
+			return nil, fmt.Errorf("failed to decode file watch state: %w", err)
+		}
+	}
+	if state.Files == nil {
+		state.Files = make(map[string]string)
+	}
+	return state, nil
+}
+
+func (t *FileWatchTrigger) saveState(ctx context.Context, state *fileWatchState) error {
+	data, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	if err := t.state.SetCache(ctx, t.stateKey(), data, 0); err != nil {
+		return fmt.Errorf("failed to save file watch state: %w", err)
+	}
+	return nil
+}
+
+// fileSignature changes whenever a file is rewritten
+func fileSignature(file nodes.WatchedFile) string {
+	return fmt.Sprintf("%d:%d", file.Size, file.ModTime.UnixNano())
+}
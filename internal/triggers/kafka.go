@@ -0,0 +1,205 @@
+package triggers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/nuumz/f1ow/internal/models"
+	"github.com/nuumz/f1ow/internal/nodes"
+
+	"github.com/linkedin/goavro/v2"
+	"github.com/segmentio/kafka-go"
+	"github.com/sirupsen/logrus"
+)
+
+// KafkaTrigger consumes a Kafka topic in a consumer group and emits one
+// execution per message or per batch. Offsets are committed only after the
+// execution has been queued, giving at-least-once delivery.
+type KafkaTrigger struct {
+	workflowID string
+	nodeID     string
+	config     *nodes.KafkaTriggerConfig
+	codec      *goavro.Codec
+	logger     *logrus.Logger
+	reader     *kafka.Reader
+	cancel     context.CancelFunc
+	wg         sync.WaitGroup
+}
+
+// NewKafkaTriggerFactory returns a factory for kafka triggers
+func NewKafkaTriggerFactory(logger *logrus.Logger) Factory {
+	return func(workflowID string, node models.Node) (Trigger, error) {
+		config, err := nodes.ParseKafkaTriggerConfig(node.Config)
+		if err != nil {
+			return nil, err
+		}
+
+		trigger := &KafkaTrigger{
+			workflowID: workflowID,
+			nodeID:     node.ID,
+			config:     config,
+			logger:     logger,
+		}
+
+		if config.Format == "avro" {
+			trigger.codec, err = goavro.NewCodec(config.AvroSchema)
+			if err != nil {
+				return nil, fmt.Errorf("invalid avro schema: %w", err)
+			}
+		}
+
+		return trigger, nil
+	}
+}
+
+// Start joins the consumer group and begins consuming in the background
+func (t *KafkaTrigger) Start(ctx context.Context, emit EmitFunc) error {
+	dialer, err := t.config.KafkaConnection.Dialer(ctx)
+	if err != nil {
+		return err
+	}
+
+	groupID := t.config.GroupID
+	if groupID == "" {
+		groupID = "f1ow-" + t.workflowID + "-" + t.nodeID
+	}
+
+	startOffset := kafka.LastOffset
+	if t.config.StartOffset == "earliest" {
+		startOffset = kafka.FirstOffset
+	}
+
+	t.reader = kafka.NewReader(kafka.ReaderConfig{
+		Brokers:     t.config.Brokers,
+		Topic:       t.config.Topic,
+		GroupID:     groupID,
+		Dialer:      dialer,
+		StartOffset: startOffset,
+	})
+
+	ctx, t.cancel = context.WithCancel(ctx)
+
+	t.wg.Add(1)
+	go func() {
+		defer t.wg.Done()
+
+		for ctx.Err() == nil {
+			if err := t.consume(ctx, emit); err != nil && ctx.Err() == nil {
+				t.logger.Errorf("Kafka trigger %s/%s consume failed: %v", t.workflowID, t.nodeID, err)
+
+				select {
+				case <-ctx.Done():
+				case <-time.After(5 * time.Second):
+				}
+			}
+		}
+	}()
+
+	return nil
+}
+
+// Stop leaves the consumer group and waits for in-flight messages
+func (t *KafkaTrigger) Stop() error {
+	if t.cancel != nil {
+		t.cancel()
+	}
+	t.wg.Wait()
+
+	if t.reader != nil {
+		return t.reader.Close()
+	}
+	return nil
+}
+
+// consume reads the next message or batch, emits it, and commits offsets
+func (t *KafkaTrigger) consume(ctx context.Context, emit EmitFunc) error {
+	messages, err := t.fetch(ctx)
+	if err != nil {
+		return err
+	}
+	if len(messages) == 0 {
+		return nil
+	}
+
+	if t.config.Mode == "batch" {
+		items := make([]interface{}, len(messages))
+		for i, msg := range messages {
+			items[i] = t.payload(msg)
+		}
+		payload := map[string]interface{}{
+			"messages": items,
+			"count":    len(items),
+		}
+		if err := emit(ctx, t.workflowID, payload); err != nil {
+			return fmt.Errorf("failed to start execution: %w", err)
+		}
+	} else {
+		for _, msg := range messages {
+			if err := emit(ctx, t.workflowID, t.payload(msg)); err != nil {
+				return fmt.Errorf("failed to start execution: %w", err)
+			}
+		}
+	}
+
+	if err := t.reader.CommitMessages(ctx, messages...); err != nil {
+		return fmt.Errorf("failed to commit offsets: %w", err)
+	}
+
+	return nil
+}
+
+// fetch returns a single message, or up to batch_size messages in batch mode
+func (t *KafkaTrigger) fetch(ctx context.Context) ([]kafka.Message, error) {
+	msg, err := t.reader.FetchMessage(ctx)
+	if err != nil {
+		return nil, err
+	}
+	messages := []kafka.Message{msg}
+
+	if t.config.Mode != "batch" {
+		return messages, nil
+	}
+
+	batchCtx, cancel := context.WithTimeout(ctx, time.Duration(t.config.BatchTimeout)*time.Millisecond)
+	defer cancel()
+
+	for len(messages) < t.config.BatchSize {
+		msg, err := t.reader.FetchMessage(batchCtx)
+		if err != nil {
+			if errors.Is(err, context.DeadlineExceeded) {
+				break
+			}
+			return nil, err
+		}
+		messages = append(messages, msg)
+	}
+
+	return messages, nil
+}
+
+// payload converts a Kafka message into an execution payload
+func (t *KafkaTrigger) payload(msg kafka.Message) map[string]interface{} {
+	headers := make(map[string]interface{}, len(msg.Headers))
+	for _, header := range msg.Headers {
+		headers[header.Key] = string(header.Value)
+	}
+
+	value, err := t.config.KafkaPayload.Decode(t.codec, msg.Value)
+	if err != nil {
+		t.logger.Warnf("Kafka trigger %s/%s: %v", t.workflowID, t.nodeID, err)
+		value = string(msg.Value)
+	}
+
+	return map[string]interface{}{
+		"topic":     msg.Topic,
+		"partition": msg.Partition,
+		"offset":    msg.Offset,
+		"key":       string(msg.Key),
+		"headers":   headers,
+		"value":     value,
+		"timestamp": msg.Time.Format(time.RFC3339Nano),
+	}
+}
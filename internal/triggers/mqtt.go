@@ -0,0 +1,114 @@
+package triggers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/nuumz/f1ow/internal/models"
+	"github.com/nuumz/f1ow/internal/nodes"
+
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+	"github.com/sirupsen/logrus"
+)
+
+// mqttConnectTimeout bounds the initial broker connection
+const mqttConnectTimeout = 30 * time.Second
+
+// MQTTTrigger subscribes to MQTT topics and emits one execution per message.
+// The client reconnects and resubscribes automatically.
+type MQTTTrigger struct {
+	workflowID string
+	nodeID     string
+	config     *nodes.MQTTTriggerConfig
+	logger     *logrus.Logger
+	client     mqtt.Client
+	cancel     context.CancelFunc
+}
+
+// NewMQTTTriggerFactory returns a factory for mqtt triggers
+func NewMQTTTriggerFactory(logger *logrus.Logger) Factory {
+	return func(workflowID string, node models.Node) (Trigger, error) {
+		config, err := nodes.ParseMQTTTriggerConfig(node.Config)
+		if err != nil {
+			return nil, err
+		}
+
+		return &MQTTTrigger{
+			workflowID: workflowID,
+			nodeID:     node.ID,
+			config:     config,
+			logger:     logger,
+		}, nil
+	}
+}
+
+// Start connects to the broker and subscribes to the configured topics
+func (t *MQTTTrigger) Start(ctx context.Context, emit EmitFunc) error {
+	opts, err := t.config.MQTTConnection.ClientOptions(ctx)
+	if err != nil {
+		return err
+	}
+
+	ctx, t.cancel = context.WithCancel(ctx)
+
+	filters := make(map[string]byte, len(t.config.Topics))
+	for _, topic := range t.config.Topics {
+		filters[topic] = byte(t.config.QoS)
+	}
+
+	handler := func(_ mqtt.Client, msg mqtt.Message) {
+		if t.config.SkipRetained && msg.Retained() {
+			return
+		}
+		if err := emit(ctx, t.workflowID, t.payload(msg)); err != nil {
+			t.logger.Errorf("MQTT trigger %s/%s failed to start execution: %v", t.workflowID, t.nodeID, err)
+		}
+	}
+
+	opts.SetCleanSession(t.config.CleanSession).
+		SetAutoReconnect(true).
+		SetConnectRetry(true).
+		SetConnectTimeout(mqttConnectTimeout).
+		SetOnConnectHandler(func(client mqtt.Client) {
+			// Subscribe on every (re)connect so subscriptions survive broker restarts
+			token := client.SubscribeMultiple(filters, handler)
+			if token.WaitTimeout(mqttConnectTimeout) && token.Error() != nil {
+				t.logger.Errorf("MQTT trigger %s/%s failed to subscribe: %v", t.workflowID, t.nodeID, token.Error())
+			}
+		}).
+		SetConnectionLostHandler(func(_ mqtt.Client, err error) {
+			t.logger.Warnf("MQTT trigger %s/%s lost connection: %v", t.workflowID, t.nodeID, err)
+		})
+
+	t.client = mqtt.NewClient(opts)
+
+	// With connect retry enabled the token completes once connected; failures
+	// are retried in the background
+	t.client.Connect()
+
+	return nil
+}
+
+// Stop disconnects from the broker
+func (t *MQTTTrigger) Stop() error {
+	if t.cancel != nil {
+		t.cancel()
+	}
+	if t.client != nil {
+		t.client.Disconnect(250)
+	}
+	return nil
+}
+
+// payload converts an MQTT message into an execution payload
+func (t *MQTTTrigger) payload(msg mqtt.Message) map[string]interface{} {
+	return map[string]interface{}{
+		"topic":      msg.Topic(),
+		"payload":    nodes.DecodeMQTTMessage(t.config.Format, msg.Payload()),
+		"qos":        int(msg.Qos()),
+		"retained":   msg.Retained(),
+		"duplicate":  msg.Duplicate(),
+		"message_id": fmt.Sprintf("%d", msg.MessageID()),
+	}
+}
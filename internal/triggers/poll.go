@@ -0,0 +1,464 @@
+package triggers
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nuumz/f1ow/internal/models"
+	"github.com/nuumz/f1ow/internal/nodes"
+
+	"github.com/sirupsen/logrus"
+)
+
+// pollSeenTTL bounds how long polled item IDs and hashes are remembered
+const pollSeenTTL = 90 * 24 * time.Hour
+
+// pollState is the persisted position of a poll trigger between polls
+type pollState struct {
+	Cursor       string `json:"cursor,omitempty"`
+	ETag         string `json:"etag,omitempty"`
+	LastModified string `json:"last_modified,omitempty"`
+	Initialized  bool   `json:"initialized"`
+}
+
+// PollTrigger polls an HTTP endpoint or database query and emits one
+// execution per new item. New items are those past the stored cursor, or
+// whose ID or hash has not been seen before.
+type PollTrigger struct {
+	workflowID string
+	nodeID     string
+	config     *nodes.PollTriggerConfig
+	state      nodes.CacheStore
+	seen       nodes.SeenStore
+	logger     *logrus.Logger
+	db         *sql.DB
+	cancel     context.CancelFunc
+	wg         sync.WaitGroup
+}
+
+// NewPollTriggerFactory returns a factory for poll triggers that persist
+// their cursor in the state store and track seen items in the seen store
+func NewPollTriggerFactory(state nodes.CacheStore, seen nodes.SeenStore, logger *logrus.Logger) Factory {
+	return func(workflowID string, node models.Node) (Trigger, error) {
+		if state == nil {
+			return nil, fmt.Errorf("poll triggers need Redis to keep their state")
+		}
+		if err := nodes.NewPollTriggerNode().ValidateConfig(node.Config); err != nil {
+			return nil, err
+		}
+		config, err := nodes.ParsePollTriggerConfig(node.Config)
+		if err != nil {
+			return nil, err
+		}
+
+		return &PollTrigger{
+			workflowID: workflowID,
+			nodeID:     node.ID,
+			config:     config,
+			state:      state,
+			seen:       seen,
+			logger:     logger,
+		}, nil
+	}
+}
+
+// Start opens the database, if any, and begins polling in the background
+func (t *PollTrigger) Start(ctx context.Context, emit EmitFunc) error {
+	if t.config.Source == "database" {
+		db, err := nodes.OpenDatabase(ctx, t.config.Driver, t.config.DSN)
+		if err != nil {
+			return fmt.Errorf("failed to open database: %w", err)
+		}
+		t.db = db
+	}
+
+	ctx, t.cancel = context.WithCancel(ctx)
+
+	t.wg.Add(1)
+	go func() {
+		defer t.wg.Done()
+
+		ticker := time.NewTicker(time.Duration(t.config.PollInterval) * time.Second)
+		defer ticker.Stop()
+
+		for {
+			if err := t.Poll(ctx, emit); err != nil {
+				t.logger.Errorf("Poll trigger %s/%s poll failed: %v", t.workflowID, t.nodeID, err)
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+
+	return nil
+}
+
+// Stop stops polling, waits for an in-flight poll to finish, and closes the
+// database
+func (t *PollTrigger) Stop() error {
+	if t.cancel != nil {
+		t.cancel()
+	}
+	t.wg.Wait()
+
+	if t.db != nil {
+		return t.db.Close()
+	}
+	return nil
+}
+
+// Poll fetches items once and emits an execution for each new one. The
+// first poll only records the current items unless emit_existing is set.
+func (t *PollTrigger) Poll(ctx context.Context, emit EmitFunc) error {
+	state, err := t.loadState(ctx)
+	if err != nil {
+		return err
+	}
+
+	var items []interface{}
+	var validators pollState
+	switch t.config.Source {
+	case "database":
+		items, err = t.queryItems(ctx, state)
+	default:
+		items, validators, err = t.fetchItems(ctx, state)
+	}
+	if err != nil {
+		return err
+	}
+	if items == nil {
+		// Not modified since the last poll
+		return nil
+	}
+
+	skip := !state.Initialized && !t.config.EmitExisting
+	var complete bool
+	if t.config.Dedupe == "cursor" {
+		complete, err = t.emitAfterCursor(ctx, emit, state, items, skip)
+	} else {
+		complete, err = t.emitUnseen(ctx, emit, items, skip)
+	}
+
+	// Revalidate only once every new item was emitted; otherwise a not
+	// modified response would hide the items left for the next poll
+	state.ETag, state.LastModified = "", ""
+	if complete && err == nil {
+		state.ETag, state.LastModified = validators.ETag, validators.LastModified
+	}
+
+	// Keep the progress made before an error so emitted items are not repeated
+	state.Initialized = true
+	if saveErr := t.saveState(ctx, state); saveErr != nil && err == nil {
+		err = saveErr
+	}
+	return err
+}
+
+// fetchItems sends the configured request, revalidating the stored ETag and
+// Last-Modified. It returns nil items when the response is not modified,
+// and the response's validators otherwise.
+func (t *PollTrigger) fetchItems(ctx context.Context, state *pollState) ([]interface{}, pollState, error) {
+	var validators pollState
+
+	request := make(map[string]interface{}, len(t.config.Request))
+	for key, value := range t.config.Request {
+		request[key] = value
+	}
+	headers := make(map[string]interface{})
+	if configured, ok := t.config.Request["headers"].(map[string]interface{}); ok {
+		for key, value := range configured {
+			headers[key] = value
+		}
+	}
+	if state.ETag != "" {
+		headers["If-None-Match"] = state.ETag
+	}
+	if state.LastModified != "" {
+		headers["If-Modified-Since"] = state.LastModified
+	}
+	request["headers"] = headers
+
+	output, err := nodes.NewHTTPNode(nil).Execute(ctx, request, map[string]interface{}{})
+	if err != nil {
+		return nil, validators, err
+	}
+	response, ok := output.(map[string]interface{})
+	if !ok {
+		return nil, validators, fmt.Errorf("unexpected HTTP response type %T", output)
+	}
+
+	status, _ := response["statusCode"].(int)
+	if status == http.StatusNotModified {
+		return nil, validators, nil
+	}
+	if status >= 300 {
+		return nil, validators, fmt.Errorf("poll request returned status %d", status)
+	}
+
+	if responseHeaders, ok := response["headers"].(http.Header); ok {
+		validators.ETag = responseHeaders.Get("ETag")
+		validators.LastModified = responseHeaders.Get("Last-Modified")
+	}
+
+	items, err := t.config.Items(response["body"])
+	if err != nil {
+		return nil, validators, err
+	}
+	if items == nil {
+		items = []interface{}{}
+	}
+	return items, validators, nil
+}
+
+// queryItems runs the configured query and returns one item per row
+func (t *PollTrigger) queryItems(ctx context.Context, state *pollState) ([]interface{}, error) {
+	var args []interface{}
+	if t.config.PassCursor {
+		cursor := state.Cursor
+		if cursor == "" {
+			cursor = t.config.InitialCursor
+		}
+		args = append(args, cursor)
+	}
+
+	rows, err := t.db.QueryContext(ctx, t.config.Query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("poll query failed: %w", err)
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+
+	items := []interface{}{}
+	for rows.Next() {
+		values := make([]interface{}, len(columns))
+		pointers := make([]interface{}, len(columns))
+		for i := range values {
+			pointers[i] = &values[i]
+		}
+		if err := rows.Scan(pointers...); err != nil {
+			return nil, fmt.Errorf("failed to scan row: %w", err)
+		}
+
+		item := make(map[string]interface{}, len(columns))
+		for i, column := range columns {
+			if raw, ok := values[i].([]byte); ok {
+				item[column] = string(raw)
+			} else {
+				item[column] = values[i]
+			}
+		}
+		items = append(items, item)
+	}
+	return items, rows.Err()
+}
+
+// emitAfterCursor emits items whose cursor field is past the stored cursor
+// in ascending order, advancing the cursor after each emitted item. It
+// reports whether every new item was emitted.
+func (t *PollTrigger) emitAfterCursor(ctx context.Context, emit EmitFunc, state *pollState, items []interface{}, skip bool) (bool, error) {
+	if state.Cursor == "" {
+		state.Cursor = t.config.InitialCursor
+	}
+
+	type cursorItem struct {
+		cursor string
+		item   interface{}
+	}
+	var newItems []cursorItem
+	for _, item := range items {
+		value := t.config.ItemField(item, t.config.CursorField)
+		if value == nil {
+			continue
+		}
+		cursor := cursorString(value)
+		if state.Cursor == "" || compareCursors(cursor, state.Cursor) > 0 {
+			newItems = append(newItems, cursorItem{cursor: cursor, item: item})
+		}
+	}
+	sort.SliceStable(newItems, func(i, j int) bool {
+		return compareCursors(newItems[i].cursor, newItems[j].cursor) < 0
+	})
+
+	if skip {
+		if len(newItems) > 0 {
+			state.Cursor = newItems[len(newItems)-1].cursor
+		}
+		return true, nil
+	}
+
+	for i, newItem := range newItems {
+		if i >= t.config.MaxItems {
+			return false, nil
+		}
+		if err := emit(ctx, t.workflowID, pollPayload(newItem.item)); err != nil {
+			return false, fmt.Errorf("failed to start execution: %w", err)
+		}
+		state.Cursor = newItem.cursor
+	}
+	return true, nil
+}
+
+// emitUnseen claims items in the seen store, by ID or content hash, and
+// emits the ones not seen before. Items are claimed in batches no larger
+// than the remaining max_items so items past the limit wait for a later poll.
+// It reports whether every item was claimed.
+func (t *PollTrigger) emitUnseen(ctx context.Context, emit EmitFunc, items []interface{}, skip bool) (bool, error) {
+	keys := make([]string, 0, len(items))
+	keyed := make([]interface{}, 0, len(items))
+	for _, item := range items {
+		key, err := t.itemKey(item)
+		if err != nil {
+			return false, err
+		}
+		if key == "" {
+			continue
+		}
+		keys = append(keys, key)
+		keyed = append(keyed, item)
+	}
+
+	scope := "poll:" + t.workflowID + ":" + t.nodeID
+	if skip {
+		if len(keys) == 0 {
+			return true, nil
+		}
+		_, err := t.seen.MarkSeen(ctx, scope, keys, pollSeenTTL)
+		return err == nil, err
+	}
+
+	remaining := t.config.MaxItems
+	start := 0
+	for start < len(keys) && remaining > 0 {
+		end := start + remaining
+		if end > len(keys) {
+			end = len(keys)
+		}
+
+		seen, err := t.seen.MarkSeen(ctx, scope, keys[start:end], pollSeenTTL)
+		if err != nil {
+			return false, fmt.Errorf("failed to check seen items: %w", err)
+		}
+		for i, wasSeen := range seen {
+			if wasSeen {
+				continue
+			}
+			if err := emit(ctx, t.workflowID, pollPayload(keyed[start+i])); err != nil {
+				return false, fmt.Errorf("failed to start execution: %w", err)
+			}
+			remaining--
+		}
+		start = end
+	}
+	return start >= len(keys), nil
+}
+
+// itemKey returns the seen-store key of an item: its ID field, or a hash of
+// the whole item
+func (t *PollTrigger) itemKey(item interface{}) (string, error) {
+	if t.config.Dedupe == "id" {
+		value := t.config.ItemField(item, t.config.IDField)
+		if value == nil {
+			return "", nil
+		}
+		return cursorString(value), nil
+	}
+
+	// json.Marshal sorts map keys, so equal items produce equal hashes
+	data, err := json.Marshal(item)
+	if err != nil {
+		return "", fmt.Errorf("failed to hash item: %w", err)
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+func (t *PollTrigger) stateKey() string {
+	return "trigger:poll:" + t.workflowID + ":" + t.nodeID
+}
+
+func (t *PollTrigger) loadState(ctx context.Context) (*pollState, error) {
+	state := &pollState{}
+	data, found, err := t.state.GetCache(ctx, t.stateKey())
+	if err != nil {
+		return nil, fmt.Errorf("failed to load poll state: %w", err)
+	}
+	if found {
+		if err := json.Unmarshal(data, state); err != nil {
+			return nil, fmt.Errorf("failed to decode poll state: %w", err)
+		}
+	}
+	return state, nil
+}
+
+func (t *PollTrigger) saveState(ctx context.Context, state *pollState) error {
+	data, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	if err := t.state.SetCache(ctx, t.stateKey(), data, 0); err != nil {
+		return fmt.Errorf("failed to save poll state: %w", err)
+	}
+	return nil
+}
+
+// pollPayload is the trigger payload for an item
+func pollPayload(item interface{}) map[string]interface{} {
+	if itemMap, ok := item.(map[string]interface{}); ok {
+		return itemMap
+	}
+	return map[string]interface{}{"data": item}
+}
+
+// cursorString formats a cursor or ID value for storage
+func cursorString(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case time.Time:
+		return v.UTC().Format(time.RFC3339Nano)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// compareCursors orders cursors numerically, then as RFC 3339 timestamps,
+// then lexically
+func compareCursors(a, b string) int {
+	if x, err := strconv.ParseFloat(a, 64); err == nil {
+		if y, err := strconv.ParseFloat(b, 64); err == nil {
+			switch {
+			case x < y:
+				return -1
+			case x > y:
+				return 1
+			}
+			return 0
+		}
+	}
+	if x, err := time.Parse(time.RFC3339Nano, a); err == nil {
+		if y, err := time.Parse(time.RFC3339Nano, b); err == nil {
+			return x.Compare(y)
+		}
+	}
+	return strings.Compare(a, b)
+}
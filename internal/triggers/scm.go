@@ -0,0 +1,287 @@
+package triggers
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"strings"
+)
+
+// VerifyGitHubSignature checks an X-Hub-Signature-256 header against the
+// HMAC-SHA256 of body keyed with secret
+func VerifyGitHubSignature(secret string, body []byte, signature string) bool {
+	if secret == "" || !strings.HasPrefix(signature, "sha256=") {
+		return false
+	}
+	expected, err := hex.DecodeString(strings.TrimPrefix(signature, "sha256="))
+	if err != nil {
+		return false
+	}
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hmac.Equal(mac.Sum(nil), expected)
+}
+
+// VerifyGitLabToken checks an X-Gitlab-Token header against secret in
+// constant time
+func VerifyGitLabToken(secret, token string) bool {
+	if secret == "" {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(secret), []byte(token)) == 1
+}
+
+// EventAccepted reports whether an event passes the trigger's event and
+// action filters; empty filters accept everything
+func EventAccepted(events, actions []string, event, action string) bool {
+	return filterMatches(events, event) && filterMatches(actions, action)
+}
+
+func filterMatches(filter []string, value string) bool {
+	if len(filter) == 0 {
+		return true
+	}
+	for _, allowed := range filter {
+		if strings.EqualFold(strings.TrimSpace(allowed), value) {
+			return true
+		}
+	}
+	return false
+}
+
+// GitHubEventPayload maps a GitHub webhook delivery to a structured trigger
+// payload. Common fields are lifted to the top level and the original body
+// is kept under "payload".
+func GitHubEventPayload(event string, body map[string]interface{}) map[string]interface{} {
+	payload := map[string]interface{}{
+		"provider":   "github",
+		"event":      event,
+		"action":     stringAt(body, "action"),
+		"repository": stringAt(body, "repository", "full_name"),
+		"sender":     stringAt(body, "sender", "login"),
+		"payload":    body,
+	}
+
+	switch event {
+	case "push":
+		ref := stringAt(body, "ref")
+		payload["ref"] = ref
+		payload["branch"] = strings.TrimPrefix(ref, "refs/heads/")
+		payload["before"] = stringAt(body, "before")
+		payload["after"] = stringAt(body, "after")
+		payload["compare_url"] = stringAt(body, "compare")
+		payload["commits"] = mapCommits(body["commits"])
+		if strings.HasPrefix(ref, "refs/tags/") {
+			payload["tag"] = strings.TrimPrefix(ref, "refs/tags/")
+			delete(payload, "branch")
+		}
+
+	case "pull_request":
+		pr := mapAt(body, "pull_request")
+		payload["number"] = valueAt(pr, "number")
+		payload["title"] = stringAt(pr, "title")
+		payload["state"] = stringAt(pr, "state")
+		payload["merged"] = valueAt(pr, "merged")
+		payload["draft"] = valueAt(pr, "draft")
+		payload["url"] = stringAt(pr, "html_url")
+		payload["author"] = stringAt(pr, "user", "login")
+		payload["head_branch"] = stringAt(pr, "head", "ref")
+		payload["base_branch"] = stringAt(pr, "base", "ref")
+		payload["head_sha"] = stringAt(pr, "head", "sha")
+
+	case "issues":
+		issue := mapAt(body, "issue")
+		payload["number"] = valueAt(issue, "number")
+		payload["title"] = stringAt(issue, "title")
+		payload["state"] = stringAt(issue, "state")
+		payload["url"] = stringAt(issue, "html_url")
+		payload["author"] = stringAt(issue, "user", "login")
+		payload["labels"] = labelNames(issue["labels"])
+
+	case "issue_comment":
+		issue := mapAt(body, "issue")
+		payload["number"] = valueAt(issue, "number")
+		payload["title"] = stringAt(issue, "title")
+		payload["is_pull_request"] = issue["pull_request"] != nil
+		payload["comment"] = stringAt(body, "comment", "body")
+		payload["author"] = stringAt(body, "comment", "user", "login")
+		payload["url"] = stringAt(body, "comment", "html_url")
+
+	case "release":
+		release := mapAt(body, "release")
+		payload["tag"] = stringAt(release, "tag_name")
+		payload["name"] = stringAt(release, "name")
+		payload["prerelease"] = valueAt(release, "prerelease")
+		payload["url"] = stringAt(release, "html_url")
+		payload["author"] = stringAt(release, "author", "login")
+
+	case "workflow_run":
+		run := mapAt(body, "workflow_run")
+		payload["name"] = stringAt(run, "name")
+		payload["status"] = stringAt(run, "status")
+		payload["conclusion"] = stringAt(run, "conclusion")
+		payload["branch"] = stringAt(run, "head_branch")
+		payload["head_sha"] = stringAt(run, "head_sha")
+		payload["url"] = stringAt(run, "html_url")
+	}
+
+	return payload
+}
+
+// gitlabEvents maps X-Gitlab-Event header values to trigger event names
+var gitlabEvents = map[string]string{
+	"Push Hook":               "push",
+	"Tag Push Hook":           "tag_push",
+	"Merge Request Hook":      "merge_request",
+	"Issue Hook":              "issue",
+	"Confidential Issue Hook": "issue",
+	"Note Hook":               "note",
+	"Confidential Note Hook":  "note",
+	"Release Hook":            "release",
+	"Pipeline Hook":           "pipeline",
+}
+
+// GitLabEventName maps an X-Gitlab-Event header to a trigger event name.
+// Unknown hooks are lowercased with the " Hook" suffix removed.
+func GitLabEventName(header string) string {
+	if event, ok := gitlabEvents[header]; ok {
+		return event
+	}
+	return strings.ReplaceAll(strings.ToLower(strings.TrimSuffix(header, " Hook")), " ", "_")
+}
+
+// GitLabEventPayload maps a GitLab webhook delivery to a structured trigger
+// payload in the same shape as GitHubEventPayload
+func GitLabEventPayload(event string, body map[string]interface{}) map[string]interface{} {
+	attrs := mapAt(body, "object_attributes")
+	payload := map[string]interface{}{
+		"provider":   "gitlab",
+		"event":      event,
+		"action":     stringAt(attrs, "action"),
+		"repository": stringAt(body, "project", "path_with_namespace"),
+		"sender":     stringAt(body, "user", "username"),
+		"payload":    body,
+	}
+
+	switch event {
+	case "push", "tag_push":
+		ref := stringAt(body, "ref")
+		payload["ref"] = ref
+		payload["before"] = stringAt(body, "before")
+		payload["after"] = stringAt(body, "after")
+		payload["sender"] = stringAt(body, "user_username")
+		payload["commits"] = mapCommits(body["commits"])
+		if event == "tag_push" {
+			payload["tag"] = strings.TrimPrefix(ref, "refs/tags/")
+		} else {
+			payload["branch"] = strings.TrimPrefix(ref, "refs/heads/")
+		}
+
+	case "merge_request":
+		payload["number"] = valueAt(attrs, "iid")
+		payload["title"] = stringAt(attrs, "title")
+		payload["state"] = stringAt(attrs, "state")
+		payload["merged"] = stringAt(attrs, "state") == "merged"
+		payload["draft"] = valueAt(attrs, "draft")
+		payload["url"] = stringAt(attrs, "url")
+		payload["head_branch"] = stringAt(attrs, "source_branch")
+		payload["base_branch"] = stringAt(attrs, "target_branch")
+		payload["head_sha"] = stringAt(attrs, "last_commit", "id")
+
+	case "issue":
+		payload["number"] = valueAt(attrs, "iid")
+		payload["title"] = stringAt(attrs, "title")
+		payload["state"] = stringAt(attrs, "state")
+		payload["url"] = stringAt(attrs, "url")
+		payload["labels"] = labelNames(body["labels"])
+
+	case "note":
+		payload["action"] = "created"
+		payload["comment"] = stringAt(attrs, "note")
+		payload["url"] = stringAt(attrs, "url")
+		payload["noteable_type"] = stringAt(attrs, "noteable_type")
+		if mr := mapAt(body, "merge_request"); mr != nil {
+			payload["number"] = valueAt(mr, "iid")
+			payload["title"] = stringAt(mr, "title")
+			payload["is_pull_request"] = true
+		} else if issue := mapAt(body, "issue"); issue != nil {
+			payload["number"] = valueAt(issue, "iid")
+			payload["title"] = stringAt(issue, "title")
+			payload["is_pull_request"] = false
+		}
+
+	case "release":
+		payload["action"] = stringAt(body, "action")
+		payload["tag"] = stringAt(body, "tag")
+		payload["name"] = stringAt(body, "name")
+		payload["url"] = stringAt(body, "url")
+
+	case "pipeline":
+		payload["action"] = stringAt(attrs, "status")
+		payload["status"] = stringAt(attrs, "status")
+		payload["branch"] = stringAt(attrs, "ref")
+		payload["head_sha"] = stringAt(attrs, "sha")
+		payload["url"] = stringAt(attrs, "url")
+	}
+
+	return payload
+}
+
+// mapCommits reduces webhook commit lists to {id, message, author, url}
+func mapCommits(value interface{}) []map[string]interface{} {
+	list, _ := value.([]interface{})
+	commits := make([]map[string]interface{}, 0, len(list))
+	for _, item := range list {
+		commit, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		commits = append(commits, map[string]interface{}{
+			"id":      stringAt(commit, "id"),
+			"message": stringAt(commit, "message"),
+			"author":  stringAt(commit, "author", "name"),
+			"url":     stringAt(commit, "url"),
+		})
+	}
+	return commits
+}
+
+// labelNames extracts label names from GitHub or GitLab label objects
+func labelNames(value interface{}) []string {
+	list, _ := value.([]interface{})
+	names := make([]string, 0, len(list))
+	for _, item := range list {
+		if name := stringAt(item, "name"); name != "" {
+			names = append(names, name)
+		} else if name := stringAt(item, "title"); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// valueAt walks nested JSON objects and returns the value at path
+func valueAt(value interface{}, path ...string) interface{} {
+	for _, key := range path {
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		value = object[key]
+	}
+	return value
+}
+
+// stringAt returns the string at path, or "" when missing
+func stringAt(value interface{}, path ...string) string {
+	s, _ := valueAt(value, path...).(string)
+	return s
+}
+
+// mapAt returns the object at path, or nil when missing
+func mapAt(value interface{}, path ...string) map[string]interface{} {
+	object, _ := valueAt(value, path...).(map[string]interface{})
+	return object
+}
@@ -0,0 +1,37 @@
+// Package triggers implements the event sources that start workflows, such
+// as queue consumers and mailbox pollers. The engine's TriggerManager runs
+// them for the trigger nodes of active workflows.
+package triggers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/nuumz/f1ow/internal/engine"
+)
+
+// Trigger, EmitFunc, and Factory are defined by the engine, which runs triggers
+type (
+	Trigger  = engine.Trigger
+	EmitFunc = engine.EmitFunc
+	Factory  = engine.TriggerFactory
+)
+
+// ExecuteFunc runs a workflow execution to completion and returns its error,
+// for triggers that acknowledge events based on the execution outcome
+type ExecuteFunc func(ctx context.Context, workflowID string, payload map[string]interface{}) error
+
+// decodeConfig decodes a node configuration into a typed struct
+func decodeConfig(config map[string]interface{}, target interface{}) error {
+	configJSON, err := json.Marshal(config)
+	if err != nil {
+		return fmt.Errorf("failed to marshal config: %w", err)
+	}
+
+	if err := json.Unmarshal(configJSON, target); err != nil {
+		return fmt.Errorf("failed to parse trigger config: %w", err)
+	}
+
+	return nil
+}
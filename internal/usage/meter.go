@@ -0,0 +1,126 @@
+// Package usage meters the resources an execution uses: the nodes it runs
+// and how long they run, the bytes its nodes transfer over HTTP, and the
+// language model tokens they consume.
+package usage
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/nuumz/f1ow/internal/models"
+)
+
+// Meter counts the usage of one execution. It is safe for concurrent use
+// by the execution's nodes.
+type Meter struct {
+	nodes               atomic.Int64
+	runtime             atomic.Int64 // nanoseconds
+	httpBytesSent       atomic.Int64
+	httpBytesReceived   atomic.Int64
+	llmPromptTokens     atomic.Int64
+	llmCompletionTokens atomic.Int64
+}
+
+// NewMeter creates a meter with nothing counted
+func NewMeter() *Meter {
+	return &Meter{}
+}
+
+// AddNode counts a node run that took d
+func (m *Meter) AddNode(d time.Duration) {
+	m.nodes.Add(1)
+	m.runtime.Add(int64(d))
+}
+
+// AddHTTP counts request and response body bytes
+func (m *Meter) AddHTTP(sent, received int64) {
+	m.httpBytesSent.Add(sent)
+	m.httpBytesReceived.Add(received)
+}
+
+// AddTokens counts the prompt and completion tokens of a language model
+// call
+func (m *Meter) AddTokens(prompt, completion int) {
+	m.llmPromptTokens.Add(int64(prompt))
+	m.llmCompletionTokens.Add(int64(completion))
+}
+
+// Usage returns what the meter has counted so far
+func (m *Meter) Usage() models.ExecutionUsage {
+	return models.ExecutionUsage{
+		Nodes:               int(m.nodes.Load()),
+		RuntimeMs:           time.Duration(m.runtime.Load()).Milliseconds(),
+		HTTPBytesSent:       m.httpBytesSent.Load(),
+		HTTPBytesReceived:   m.httpBytesReceived.Load(),
+		LLMPromptTokens:     m.llmPromptTokens.Load(),
+		LLMCompletionTokens: m.llmCompletionTokens.Load(),
+	}
+}
+
+// Client returns a copy of client that counts the body bytes of its
+// requests and responses. Response bodies are counted as they are read.
+func (m *Meter) Client(client *http.Client) *http.Client {
+	transport := client.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	metered := *client
+	metered.Transport = &meteredTransport{meter: m, next: transport}
+	return &metered
+}
+
+type meteredTransport struct {
+	meter *Meter
+	next  http.RoundTripper
+}
+
+func (t *meteredTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Body != nil && req.Body != http.NoBody {
+		// Round trippers must not modify the caller's request
+		req = req.Clone(req.Context())
+		req.Body = &countingBody{ReadCloser: req.Body, count: &t.meter.httpBytesSent}
+	}
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = &countingBody{ReadCloser: resp.Body, count: &t.meter.httpBytesReceived}
+	return resp, nil
+}
+
+// countingBody adds the bytes read through it to count
+type countingBody struct {
+	io.ReadCloser
+	count *atomic.Int64
+}
+
+func (b *countingBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.count.Add(int64(n))
+	return n, err
+}
+
+type contextKey string
+
+const meterKey contextKey = "usage_meter"
+
+// WithMeter returns a context carrying the meter
+func WithMeter(ctx context.Context, meter *Meter) context.Context {
+	return context.WithValue(ctx, meterKey, meter)
+}
+
+// FromContext returns the meter stored in the context, if any
+func FromContext(ctx context.Context) (*Meter, bool) {
+	meter, ok := ctx.Value(meterKey).(*Meter)
+	return meter, ok && meter != nil
+}
+
+// AddTokens counts language model tokens on the context's meter, if any
+func AddTokens(ctx context.Context, prompt, completion int) {
+	if meter, ok := FromContext(ctx); ok {
+		meter.AddTokens(prompt, completion)
+	}
+}
@@ -0,0 +1,227 @@
+// Package variables stores global and environment variables that workflows
+// read in templates as {{vars.NAME}}.
+package variables
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/nuumz/f1ow/internal/credentials"
+	"github.com/nuumz/f1ow/internal/models"
+
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrNotFound is returned when a variable does not exist
+	ErrNotFound = errors.New("variable not found")
+	// ErrExists is returned when a variable with the same name exists in the environment
+	ErrExists = errors.New("variable already exists")
+	// ErrInvalidName is returned for names that cannot be used in templates
+	ErrInvalidName = errors.New("variable names must start with a letter or underscore and contain only letters, digits, and underscores")
+	// ErrSecretsDisabled is returned when storing a secret without an encryption key
+	ErrSecretsDisabled = errors.New("secret variables require an encryption key; set CREDENTIALS_ENCRYPTION_KEY")
+)
+
+var namePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// Store persists variables; it is implemented by storage.DB
+type Store interface {
+	CreateVariable(ctx context.Context, variable *models.Variable) error
+	GetVariable(ctx context.Context, id uuid.UUID) (*models.Variable, error)
+	ListVariables(ctx context.Context) ([]models.Variable, error)
+	UpdateVariable(ctx context.Context, variable *models.Variable) error
+	DeleteVariable(ctx context.Context, id uuid.UUID) error
+}
+
+// Manager validates, encrypts, and resolves variables
+type Manager struct {
+	store  Store
+	cipher *credentials.Cipher
+}
+
+// NewManager creates a variables manager. cipher encrypts secret values and
+// may be nil, in which case secret variables are rejected.
+func NewManager(store Store, cipher *credentials.Cipher) *Manager {
+	return &Manager{store: store, cipher: cipher}
+}
+
+// Create stores a new variable. Secret values are never returned.
+func (m *Manager) Create(ctx context.Context, variable *models.Variable) (*models.Variable, error) {
+	now := time.Now()
+	variable.ID = uuid.New()
+	variable.CreatedAt = now
+	variable.UpdatedAt = now
+
+	if err := m.prepare(ctx, variable); err != nil {
+		return nil, err
+	}
+	if err := m.store.CreateVariable(ctx, variable); err != nil {
+		return nil, fmt.Errorf("failed to save variable: %w", err)
+	}
+	return m.present(variable)
+}
+
+// Get returns a variable; secret values are omitted
+func (m *Manager) Get(ctx context.Context, id uuid.UUID) (*models.Variable, error) {
+	variable, err := m.store.GetVariable(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return m.present(variable)
+}
+
+// List returns the variables in environment, or all variables when
+// environment is nil. Pass a pointer to "" for global variables only.
+func (m *Manager) List(ctx context.Context, environment *string) ([]models.Variable, error) {
+	all, err := m.store.ListVariables(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := []models.Variable{}
+	for i := range all {
+		if environment != nil && all[i].Environment != *environment {
+			continue
+		}
+		variable, err := m.present(&all[i])
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, *variable)
+	}
+	return result, nil
+}
+
+// Update replaces a variable's name, environment, value, and secret flag
+func (m *Manager) Update(ctx context.Context, variable *models.Variable) (*models.Variable, error) {
+	existing, err := m.store.GetVariable(ctx, variable.ID)
+	if err != nil {
+		return nil, err
+	}
+	variable.CreatedAt = existing.CreatedAt
+	variable.UpdatedAt = time.Now()
+
+	if err := m.prepare(ctx, variable); err != nil {
+		return nil, err
+	}
+	if err := m.store.UpdateVariable(ctx, variable); err != nil {
+		return nil, err
+	}
+	return m.present(variable)
+}
+
+// Delete removes a variable
+func (m *Manager) Delete(ctx context.Context, id uuid.UUID) error {
+	return m.store.DeleteVariable(ctx, id)
+}
+
+// Resolve returns the values nodes see as vars: the global variables,
+// overridden by those of environment when it is not empty. Secret values
+// are decrypted.
+func (m *Manager) Resolve(ctx context.Context, environment string) (map[string]interface{}, error) {
+	values, _, err := m.ResolveWithSecrets(ctx, environment)
+	return values, err
+}
+
+// ResolveWithSecrets returns the values like Resolve and the names of
+// those that are secret, so they can be kept out of what is recorded
+func (m *Manager) ResolveWithSecrets(ctx context.Context, environment string) (map[string]interface{}, map[string]bool, error) {
+	all, err := m.store.ListVariables(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load variables: %w", err)
+	}
+
+	scopes := []string{""}
+	if environment != "" {
+		scopes = append(scopes, environment)
+	}
+
+	values := make(map[string]interface{}, len(all))
+	secrets := make(map[string]bool)
+	for _, scope := range scopes {
+		for i := range all {
+			if all[i].Environment != scope {
+				continue
+			}
+			value, err := m.decode(&all[i])
+			if err != nil {
+				return nil, nil, fmt.Errorf("failed to decode variable %s: %w", all[i].Name, err)
+			}
+			values[all[i].Name] = value
+			secrets[all[i].Name] = all[i].Secret
+		}
+	}
+	return values, secrets, nil
+}
+
+// prepare validates a variable and encodes its value into Data
+func (m *Manager) prepare(ctx context.Context, variable *models.Variable) error {
+	if !namePattern.MatchString(variable.Name) {
+		return ErrInvalidName
+	}
+	if variable.Secret && m.cipher == nil {
+		return ErrSecretsDisabled
+	}
+
+	all, err := m.store.ListVariables(ctx)
+	if err != nil {
+		return err
+	}
+	for _, other := range all {
+		if other.ID != variable.ID && other.Name == variable.Name && other.Environment == variable.Environment {
+			return ErrExists
+		}
+	}
+
+	encoded, err := json.Marshal(variable.Value)
+	if err != nil {
+		return fmt.Errorf("failed to encode variable value: %w", err)
+	}
+	variable.Data = string(encoded)
+	if variable.Secret {
+		if variable.Data, err = m.cipher.Encrypt(encoded); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// present returns the variable as API clients see it, without secret values
+func (m *Manager) present(variable *models.Variable) (*models.Variable, error) {
+	result := *variable
+	result.Value = nil
+	if variable.Secret {
+		return &result, nil
+	}
+
+	value, err := m.decode(variable)
+	if err != nil {
+		return nil, err
+	}
+	result.Value = value
+	return &result, nil
+}
+
+func (m *Manager) decode(variable *models.Variable) (interface{}, error) {
+	encoded := []byte(variable.Data)
+	if variable.Secret {
+		if m.cipher == nil {
+			return nil, ErrSecretsDisabled
+		}
+		var err error
+		if encoded, err = m.cipher.Decrypt(variable.Data); err != nil {
+			return nil, err
+		}
+	}
+
+	var value interface{}
+	if err := json.Unmarshal(encoded, &value); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
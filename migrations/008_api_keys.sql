@@ -0,0 +1,18 @@
+-- API keys for users and service accounts; only a SHA-256 hash of the key is stored
+CREATE TABLE IF NOT EXISTS api_keys (
+    id UUID PRIMARY KEY,
+    tenant_id UUID NOT NULL REFERENCES tenants(id),
+    name VARCHAR(255) NOT NULL,
+    prefix VARCHAR(20) NOT NULL,
+    key_hash CHAR(64) NOT NULL UNIQUE,
+    user_id UUID,
+    service_account VARCHAR(255),
+    scopes VARCHAR(255) NOT NULL,
+    rate_limit VARCHAR(50),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    expires_at TIMESTAMP,
+    last_used_at TIMESTAMP,
+    revoked_at TIMESTAMP
+);
+
+CREATE INDEX idx_api_keys_tenant_id ON api_keys(tenant_id);
@@ -0,0 +1,13 @@
+-- Global and environment variables resolvable in templates as {{vars.NAME}}.
+-- An empty environment marks a global variable; secret values are encrypted.
+CREATE TABLE IF NOT EXISTS variables (
+    id UUID PRIMARY KEY,
+    tenant_id UUID NOT NULL REFERENCES tenants(id),
+    name VARCHAR(255) NOT NULL,
+    environment VARCHAR(255) NOT NULL DEFAULT '',
+    secret BOOLEAN NOT NULL DEFAULT FALSE,
+    data TEXT NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (tenant_id, environment, name)
+);
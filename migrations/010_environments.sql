@@ -0,0 +1,35 @@
+-- Saved workflow versions, deployment environments, and the workflow version
+-- deployed to each environment. Versions saved before this migration are not
+-- recorded; the current version is read from workflows.
+CREATE TABLE IF NOT EXISTS workflow_versions (
+    workflow_id UUID NOT NULL REFERENCES workflows(id) ON DELETE CASCADE,
+    version INTEGER NOT NULL,
+    tenant_id UUID NOT NULL REFERENCES tenants(id),
+    name VARCHAR(255) NOT NULL,
+    description TEXT,
+    definition JSONB NOT NULL,
+    user_id UUID NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (workflow_id, version)
+);
+
+CREATE TABLE IF NOT EXISTS environments (
+    id UUID PRIMARY KEY,
+    tenant_id UUID NOT NULL REFERENCES tenants(id),
+    name VARCHAR(255) NOT NULL,
+    description TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (tenant_id, name)
+);
+
+CREATE TABLE IF NOT EXISTS workflow_deployments (
+    workflow_id UUID NOT NULL REFERENCES workflows(id) ON DELETE CASCADE,
+    environment_id UUID NOT NULL REFERENCES environments(id) ON DELETE CASCADE,
+    tenant_id UUID NOT NULL REFERENCES tenants(id),
+    version INTEGER NOT NULL,
+    credentials JSONB NOT NULL DEFAULT '{}',
+    variables JSONB NOT NULL DEFAULT '{}',
+    deployed_by UUID NOT NULL,
+    deployed_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (workflow_id, environment_id)
+);
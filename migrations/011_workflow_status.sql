@@ -0,0 +1,7 @@
+-- Workflow lifecycle status. is_active is kept in sync (true only for active
+-- workflows); it was previously cleared to soft-delete a workflow.
+ALTER TABLE workflows ADD COLUMN status VARCHAR(20) NOT NULL DEFAULT 'draft';
+
+UPDATE workflows SET status = CASE WHEN is_active THEN 'active' ELSE 'deleted' END;
+
+CREATE INDEX idx_workflows_status ON workflows(status);
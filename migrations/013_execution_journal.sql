@@ -0,0 +1,15 @@
+-- Worker running a claimed execution, so the executions of workers that
+-- stop sending heartbeats can be found and resumed
+ALTER TABLE executions ADD COLUMN claimed_by VARCHAR(255);
+
+CREATE INDEX idx_executions_claimed_by ON executions(status, claimed_by);
+
+-- Outputs of completed nodes, written as the execution runs so a resumed
+-- execution skips them
+CREATE TABLE IF NOT EXISTS execution_journal (
+    execution_id UUID NOT NULL REFERENCES executions(id) ON DELETE CASCADE,
+    node_id VARCHAR(255) NOT NULL,
+    output JSONB,
+    completed_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (execution_id, node_id)
+);
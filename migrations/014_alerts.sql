@@ -0,0 +1,43 @@
+-- Alert rules on execution outcomes, the channels alerts are sent to, and
+-- the history of fired alerts.
+CREATE TABLE IF NOT EXISTS alert_channels (
+    id UUID PRIMARY KEY,
+    tenant_id UUID NOT NULL REFERENCES tenants(id),
+    name VARCHAR(255) NOT NULL,
+    type VARCHAR(50) NOT NULL,
+    config JSONB NOT NULL DEFAULT '{}',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS alert_rules (
+    id UUID PRIMARY KEY,
+    tenant_id UUID NOT NULL REFERENCES tenants(id),
+    name VARCHAR(255) NOT NULL,
+    workflow_id UUID REFERENCES workflows(id) ON DELETE CASCADE,
+    condition_type VARCHAR(50) NOT NULL,
+    threshold INTEGER NOT NULL,
+    window_seconds INTEGER NOT NULL,
+    cooldown_seconds INTEGER NOT NULL,
+    channel_ids JSONB NOT NULL DEFAULT '[]',
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    last_fired_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS alerts (
+    id UUID PRIMARY KEY,
+    tenant_id UUID NOT NULL REFERENCES tenants(id),
+    rule_id UUID NOT NULL REFERENCES alert_rules(id) ON DELETE CASCADE,
+    rule_name VARCHAR(255) NOT NULL,
+    workflow_id UUID,
+    execution_id UUID,
+    message TEXT NOT NULL,
+    value DOUBLE PRECISION NOT NULL,
+    deliveries JSONB NOT NULL DEFAULT '[]',
+    fired_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX idx_alerts_fired_at ON alerts(tenant_id, fired_at DESC);
+CREATE INDEX idx_executions_completed_at ON executions(workflow_id, completed_at);
@@ -0,0 +1,32 @@
+-- URLs called on engine events, and the log of each delivery to them.
+CREATE TABLE IF NOT EXISTS event_subscriptions (
+    id UUID PRIMARY KEY,
+    tenant_id UUID NOT NULL REFERENCES tenants(id),
+    url TEXT NOT NULL,
+    description TEXT,
+    events JSONB NOT NULL DEFAULT '[]',
+    secret VARCHAR(255) NOT NULL,
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS event_deliveries (
+    id UUID PRIMARY KEY,
+    tenant_id UUID NOT NULL REFERENCES tenants(id),
+    subscription_id UUID NOT NULL REFERENCES event_subscriptions(id) ON DELETE CASCADE,
+    event_id VARCHAR(64) NOT NULL,
+    event_type VARCHAR(100) NOT NULL,
+    payload JSONB NOT NULL,
+    status VARCHAR(20) NOT NULL,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    response_status INTEGER,
+    response_body TEXT,
+    error TEXT,
+    next_attempt_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL,
+    delivered_at TIMESTAMP
+);
+
+CREATE INDEX idx_event_deliveries_subscription ON event_deliveries(subscription_id, created_at DESC);
+CREATE INDEX idx_event_deliveries_due ON event_deliveries(status, next_attempt_at);
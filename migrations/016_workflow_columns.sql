@@ -0,0 +1,8 @@
+-- Columns the storage layer reads and writes that earlier migrations never
+-- created: workflow tags, version, and metadata, and the execution context
+ALTER TABLE workflows ADD COLUMN IF NOT EXISTS tags JSONB DEFAULT '[]'::jsonb;
+ALTER TABLE workflows ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE workflows ADD COLUMN IF NOT EXISTS metadata JSONB DEFAULT '{}'::jsonb;
+ALTER TABLE executions ADD COLUMN IF NOT EXISTS context JSONB;
+
+CREATE INDEX IF NOT EXISTS idx_workflows_tags ON workflows USING gin(tags);
@@ -0,0 +1,9 @@
+-- Key/value state workflows keep between executions, such as cursors and
+-- counters. Values are JSON.
+CREATE TABLE IF NOT EXISTS workflow_state (
+    workflow_id UUID NOT NULL REFERENCES workflows(id) ON DELETE CASCADE,
+    state_key VARCHAR(255) NOT NULL,
+    value JSONB,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (workflow_id, state_key)
+);
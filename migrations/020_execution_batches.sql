@@ -0,0 +1,14 @@
+-- Executions submitted together through the batch execute API, and the
+-- executions in each batch
+CREATE TABLE IF NOT EXISTS execution_batches (
+    id UUID PRIMARY KEY,
+    workflow_id UUID NOT NULL REFERENCES workflows(id) ON DELETE CASCADE,
+    concurrency INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS execution_batch_items (
+    batch_id UUID NOT NULL REFERENCES execution_batches(id) ON DELETE CASCADE,
+    execution_id UUID NOT NULL REFERENCES executions(id) ON DELETE CASCADE,
+    PRIMARY KEY (batch_id, execution_id)
+);
@@ -0,0 +1,21 @@
+-- Work that external_task nodes park on a topic for workers outside the
+-- engine to fetch, lock, and complete or fail
+CREATE TABLE IF NOT EXISTS external_tasks (
+    id UUID PRIMARY KEY,
+    topic VARCHAR(255) NOT NULL,
+    workflow_id UUID NOT NULL REFERENCES workflows(id) ON DELETE CASCADE,
+    execution_id UUID NOT NULL,
+    node_id VARCHAR(255) NOT NULL,
+    payload JSONB,
+    status VARCHAR(50) NOT NULL,
+    worker_id VARCHAR(255),
+    locked_until TIMESTAMP,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    result JSONB,
+    error TEXT,
+    tenant_id UUID NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_external_tasks_topic ON external_tasks(topic, status, created_at);
@@ -0,0 +1,12 @@
+-- Named plans of quotas, and the plan each tenant is on with the quotas it
+-- overrides. Quotas the plan and tenant leave unset come from the server.
+CREATE TABLE IF NOT EXISTS plans (
+    name VARCHAR(100) PRIMARY KEY,
+    description TEXT NOT NULL DEFAULT '',
+    quotas JSONB NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL
+);
+
+ALTER TABLE tenants ADD COLUMN IF NOT EXISTS plan VARCHAR(100) NOT NULL DEFAULT '';
+ALTER TABLE tenants ADD COLUMN IF NOT EXISTS quotas JSONB;
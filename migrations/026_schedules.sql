@@ -0,0 +1,19 @@
+-- Cron schedules that run a workflow with a fixed input. next_run_at is
+-- NULL while a schedule is paused.
+CREATE TABLE IF NOT EXISTS schedules (
+    id UUID PRIMARY KEY,
+    tenant_id UUID NOT NULL REFERENCES tenants(id),
+    workflow_id UUID NOT NULL REFERENCES workflows(id) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL DEFAULT '',
+    cron_expr VARCHAR(255) NOT NULL,
+    timezone VARCHAR(100) NOT NULL DEFAULT 'UTC',
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+    next_run_at TIMESTAMP NULL,
+    last_run_at TIMESTAMP NULL,
+    input JSONB,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX idx_schedules_workflow ON schedules(workflow_id);
+CREATE INDEX idx_schedules_due ON schedules(is_active, next_run_at);
@@ -0,0 +1,11 @@
+// Package migrations embeds the versioned schema migrations, NNN_name.sql
+// for PostgreSQL, mysql/NNN_name.sql for MySQL, and sqlite/NNN_name.sql for
+// SQLite, so binaries can apply them without the files on disk.
+package migrations
+
+import "embed"
+
+// FS holds the migration files
+//
+//go:embed *.sql mysql/*.sql sqlite/*.sql
+var FS embed.FS
@@ -0,0 +1,10 @@
+-- Seen-set used by the dedupe node
+CREATE TABLE IF NOT EXISTS dedupe_keys (
+    scope VARCHAR(255) NOT NULL,
+    key_hash VARCHAR(64) NOT NULL,
+    expires_at TIMESTAMP NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (scope, key_hash)
+);
+
+CREATE INDEX idx_dedupe_keys_expires_at ON dedupe_keys(expires_at);
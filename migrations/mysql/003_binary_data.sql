@@ -0,0 +1,13 @@
+-- Binary content produced by executions; content lives in the binary data store
+CREATE TABLE IF NOT EXISTS binary_data (
+    id CHAR(36) PRIMARY KEY,
+    execution_id CHAR(36) NOT NULL,
+    storage VARCHAR(50) NOT NULL,
+    storage_key VARCHAR(512) NOT NULL,
+    file_name VARCHAR(255),
+    mime_type VARCHAR(255),
+    size BIGINT NOT NULL DEFAULT 0,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_binary_data_execution_id ON binary_data(execution_id);
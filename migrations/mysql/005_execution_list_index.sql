@@ -0,0 +1,2 @@
+-- Keyset pagination index for the execution list, newest first
+CREATE INDEX idx_executions_started_at_id ON executions(started_at DESC, id DESC);
@@ -0,0 +1,28 @@
+-- Tenants isolate workflows, executions, credentials, and projects. Existing
+-- rows belong to the default tenant.
+CREATE TABLE IF NOT EXISTS tenants (
+    id VARCHAR(36) PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+INSERT IGNORE INTO tenants (id, name) VALUES ('00000000-0000-0000-0000-000000000001', 'Default');
+
+ALTER TABLE workflows ADD COLUMN tenant_id VARCHAR(36) NOT NULL
+    DEFAULT '00000000-0000-0000-0000-000000000001';
+ALTER TABLE executions ADD COLUMN tenant_id VARCHAR(36) NOT NULL
+    DEFAULT '00000000-0000-0000-0000-000000000001';
+ALTER TABLE credentials ADD COLUMN tenant_id VARCHAR(36) NOT NULL
+    DEFAULT '00000000-0000-0000-0000-000000000001';
+ALTER TABLE projects ADD COLUMN tenant_id VARCHAR(36) NOT NULL
+    DEFAULT '00000000-0000-0000-0000-000000000001';
+
+ALTER TABLE workflows ADD CONSTRAINT fk_workflows_tenant FOREIGN KEY (tenant_id) REFERENCES tenants(id);
+ALTER TABLE executions ADD CONSTRAINT fk_executions_tenant FOREIGN KEY (tenant_id) REFERENCES tenants(id);
+ALTER TABLE credentials ADD CONSTRAINT fk_credentials_tenant FOREIGN KEY (tenant_id) REFERENCES tenants(id);
+ALTER TABLE projects ADD CONSTRAINT fk_projects_tenant FOREIGN KEY (tenant_id) REFERENCES tenants(id);
+
+CREATE INDEX idx_workflows_tenant_id ON workflows(tenant_id);
+CREATE INDEX idx_executions_tenant_started_at ON executions(tenant_id, started_at DESC);
+CREATE INDEX idx_credentials_tenant_id ON credentials(tenant_id);
+CREATE INDEX idx_projects_tenant_id ON projects(tenant_id);
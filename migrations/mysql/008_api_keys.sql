@@ -0,0 +1,19 @@
+-- API keys for users and service accounts; only a SHA-256 hash of the key is stored
+CREATE TABLE IF NOT EXISTS api_keys (
+    id VARCHAR(36) PRIMARY KEY,
+    tenant_id VARCHAR(36) NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    prefix VARCHAR(20) NOT NULL,
+    key_hash CHAR(64) NOT NULL UNIQUE,
+    user_id VARCHAR(36) NULL,
+    service_account VARCHAR(255) NULL,
+    scopes VARCHAR(255) NOT NULL,
+    rate_limit VARCHAR(50) NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    expires_at TIMESTAMP NULL,
+    last_used_at TIMESTAMP NULL,
+    revoked_at TIMESTAMP NULL,
+    FOREIGN KEY (tenant_id) REFERENCES tenants(id)
+);
+
+CREATE INDEX idx_api_keys_tenant_id ON api_keys(tenant_id);
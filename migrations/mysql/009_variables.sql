@@ -0,0 +1,14 @@
+-- Global and environment variables resolvable in templates as {{vars.NAME}}.
+-- An empty environment marks a global variable; secret values are encrypted.
+CREATE TABLE IF NOT EXISTS variables (
+    id VARCHAR(36) PRIMARY KEY,
+    tenant_id VARCHAR(36) NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    environment VARCHAR(255) NOT NULL DEFAULT '',
+    secret BOOLEAN NOT NULL DEFAULT FALSE,
+    data TEXT NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE KEY uq_variables_name (tenant_id, environment, name),
+    FOREIGN KEY (tenant_id) REFERENCES tenants(id)
+);
@@ -0,0 +1,41 @@
+-- Saved workflow versions, deployment environments, and the workflow version
+-- deployed to each environment. Versions saved before this migration are not
+-- recorded; the current version is read from workflows.
+CREATE TABLE IF NOT EXISTS workflow_versions (
+    workflow_id VARCHAR(36) NOT NULL,
+    version INT NOT NULL,
+    tenant_id VARCHAR(36) NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    description TEXT,
+    definition JSON NOT NULL,
+    user_id VARCHAR(36) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (workflow_id, version),
+    FOREIGN KEY (workflow_id) REFERENCES workflows(id) ON DELETE CASCADE,
+    FOREIGN KEY (tenant_id) REFERENCES tenants(id)
+);
+
+CREATE TABLE IF NOT EXISTS environments (
+    id VARCHAR(36) PRIMARY KEY,
+    tenant_id VARCHAR(36) NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    description TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE KEY uq_environments_name (tenant_id, name),
+    FOREIGN KEY (tenant_id) REFERENCES tenants(id)
+);
+
+CREATE TABLE IF NOT EXISTS workflow_deployments (
+    workflow_id VARCHAR(36) NOT NULL,
+    environment_id VARCHAR(36) NOT NULL,
+    tenant_id VARCHAR(36) NOT NULL,
+    version INT NOT NULL,
+    credentials JSON NOT NULL,
+    variables JSON NOT NULL,
+    deployed_by VARCHAR(36) NOT NULL,
+    deployed_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (workflow_id, environment_id),
+    FOREIGN KEY (workflow_id) REFERENCES workflows(id) ON DELETE CASCADE,
+    FOREIGN KEY (environment_id) REFERENCES environments(id) ON DELETE CASCADE,
+    FOREIGN KEY (tenant_id) REFERENCES tenants(id)
+);
@@ -0,0 +1,48 @@
+-- Alert rules on execution outcomes, the channels alerts are sent to, and
+-- the history of fired alerts.
+CREATE TABLE IF NOT EXISTS alert_channels (
+    id VARCHAR(36) PRIMARY KEY,
+    tenant_id VARCHAR(36) NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    type VARCHAR(50) NOT NULL,
+    config JSON NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (tenant_id) REFERENCES tenants(id)
+);
+
+CREATE TABLE IF NOT EXISTS alert_rules (
+    id VARCHAR(36) PRIMARY KEY,
+    tenant_id VARCHAR(36) NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    workflow_id VARCHAR(36),
+    condition_type VARCHAR(50) NOT NULL,
+    threshold INT NOT NULL,
+    window_seconds INT NOT NULL,
+    cooldown_seconds INT NOT NULL,
+    channel_ids JSON NOT NULL,
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    last_fired_at TIMESTAMP NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (tenant_id) REFERENCES tenants(id),
+    FOREIGN KEY (workflow_id) REFERENCES workflows(id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS alerts (
+    id VARCHAR(36) PRIMARY KEY,
+    tenant_id VARCHAR(36) NOT NULL,
+    rule_id VARCHAR(36) NOT NULL,
+    rule_name VARCHAR(255) NOT NULL,
+    workflow_id VARCHAR(36),
+    execution_id VARCHAR(36),
+    message TEXT NOT NULL,
+    value DOUBLE NOT NULL,
+    deliveries JSON NOT NULL,
+    fired_at TIMESTAMP NOT NULL,
+    INDEX idx_alerts_fired_at (tenant_id, fired_at),
+    FOREIGN KEY (tenant_id) REFERENCES tenants(id),
+    FOREIGN KEY (rule_id) REFERENCES alert_rules(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_executions_completed_at ON executions(workflow_id, completed_at);
@@ -0,0 +1,6 @@
+-- Columns the storage layer reads and writes that earlier migrations never
+-- created: workflow tags, version, and metadata, and the execution context
+ALTER TABLE workflows ADD COLUMN tags JSON;
+ALTER TABLE workflows ADD COLUMN version INT NOT NULL DEFAULT 1;
+ALTER TABLE workflows ADD COLUMN metadata JSON;
+ALTER TABLE executions ADD COLUMN context JSON;
@@ -0,0 +1,10 @@
+-- Key/value state workflows keep between executions, such as cursors and
+-- counters. Values are JSON.
+CREATE TABLE IF NOT EXISTS workflow_state (
+    workflow_id VARCHAR(36) NOT NULL,
+    state_key VARCHAR(255) NOT NULL,
+    value JSON,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (workflow_id, state_key),
+    FOREIGN KEY (workflow_id) REFERENCES workflows(id) ON DELETE CASCADE
+);
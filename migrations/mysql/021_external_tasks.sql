@@ -0,0 +1,22 @@
+-- Work that external_task nodes park on a topic for workers outside the
+-- engine to fetch, lock, and complete or fail
+CREATE TABLE IF NOT EXISTS external_tasks (
+    id VARCHAR(36) PRIMARY KEY,
+    topic VARCHAR(255) NOT NULL,
+    workflow_id VARCHAR(36) NOT NULL,
+    execution_id VARCHAR(36) NOT NULL,
+    node_id VARCHAR(255) NOT NULL,
+    payload JSON,
+    status VARCHAR(50) NOT NULL,
+    worker_id VARCHAR(255),
+    locked_until TIMESTAMP NULL,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    result JSON,
+    error TEXT,
+    tenant_id VARCHAR(36) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (workflow_id) REFERENCES workflows(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_external_tasks_topic ON external_tasks(topic, status, created_at);
@@ -0,0 +1,16 @@
+-- The resources each finished execution used, kept in columns of their own
+-- so usage can be totalled by tenant and workflow for chargeback. Rows
+-- outlive the executions pruned by the retention period.
+CREATE TABLE IF NOT EXISTS execution_usage (
+    execution_id VARCHAR(36) PRIMARY KEY,
+    tenant_id VARCHAR(36) NOT NULL,
+    workflow_id VARCHAR(36) NOT NULL,
+    nodes INT NOT NULL DEFAULT 0,
+    runtime_ms BIGINT NOT NULL DEFAULT 0,
+    http_bytes_sent BIGINT NOT NULL DEFAULT 0,
+    http_bytes_received BIGINT NOT NULL DEFAULT 0,
+    llm_prompt_tokens BIGINT NOT NULL DEFAULT 0,
+    llm_completion_tokens BIGINT NOT NULL DEFAULT 0,
+    completed_at TIMESTAMP NOT NULL,
+    INDEX idx_execution_usage_tenant_completed_at (tenant_id, completed_at)
+);
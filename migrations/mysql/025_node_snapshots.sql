@@ -0,0 +1,3 @@
+-- What each node saw when it ran: its input, without the outputs of earlier
+-- nodes, and the execution's variables
+ALTER TABLE node_executions ADD COLUMN snapshot JSON;
@@ -0,0 +1,59 @@
+-- SQLite Schema for Workflow Engine. JSON columns are TEXT and UUIDs are
+-- generated by the application.
+
+-- Users table
+CREATE TABLE IF NOT EXISTS users (
+    id VARCHAR(36) PRIMARY KEY,
+    email VARCHAR(255) UNIQUE NOT NULL,
+    name VARCHAR(255),
+    password_hash VARCHAR(255),
+    role VARCHAR(50) DEFAULT 'user',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Workflows table
+CREATE TABLE IF NOT EXISTS workflows (
+    id VARCHAR(36) PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    description TEXT,
+    definition TEXT NOT NULL,
+    user_id VARCHAR(36) NOT NULL REFERENCES users(id),
+    is_active BOOLEAN DEFAULT TRUE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Executions table
+CREATE TABLE IF NOT EXISTS executions (
+    id VARCHAR(36) PRIMARY KEY,
+    workflow_id VARCHAR(36) NOT NULL REFERENCES workflows(id),
+    status VARCHAR(50) NOT NULL,
+    input TEXT,
+    output TEXT,
+    error TEXT,
+    started_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    completed_at TIMESTAMP,
+    metadata TEXT DEFAULT '{}'
+);
+
+-- Sub-workflow relationships
+CREATE TABLE IF NOT EXISTS workflow_relationships (
+    id VARCHAR(36) PRIMARY KEY,
+    parent_workflow_id VARCHAR(36) NOT NULL REFERENCES workflows(id) ON DELETE CASCADE,
+    child_workflow_id VARCHAR(36) NOT NULL REFERENCES workflows(id) ON DELETE CASCADE,
+    node_id VARCHAR(255) NOT NULL,
+    node_name VARCHAR(255),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    last_used TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (parent_workflow_id, child_workflow_id, node_id)
+);
+
+-- Indexes for better performance
+CREATE INDEX idx_workflows_user_id ON workflows(user_id);
+CREATE INDEX idx_workflows_is_active ON workflows(is_active);
+CREATE INDEX idx_executions_workflow_id ON executions(workflow_id);
+CREATE INDEX idx_executions_status ON executions(status);
+CREATE INDEX idx_executions_started_at ON executions(started_at);
+CREATE INDEX idx_workflow_relationships_parent ON workflow_relationships(parent_workflow_id);
+CREATE INDEX idx_workflow_relationships_child ON workflow_relationships(child_workflow_id);
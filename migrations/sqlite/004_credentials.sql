@@ -0,0 +1,12 @@
+-- Encrypted credentials referenced by nodes through credential_id
+CREATE TABLE IF NOT EXISTS credentials (
+    id VARCHAR(36) PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    type VARCHAR(50) NOT NULL,
+    user_id VARCHAR(36) NOT NULL,
+    data TEXT NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_credentials_user_id ON credentials(user_id);
@@ -0,0 +1,16 @@
+-- Projects group workflows and nest as folders through parent_id
+CREATE TABLE IF NOT EXISTS projects (
+    id VARCHAR(36) PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    description TEXT,
+    parent_id VARCHAR(36) REFERENCES projects(id),
+    user_id VARCHAR(36) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_projects_parent_id ON projects(parent_id);
+
+ALTER TABLE workflows ADD COLUMN project_id VARCHAR(36) REFERENCES projects(id) ON DELETE SET NULL;
+
+CREATE INDEX idx_workflows_project_id ON workflows(project_id);
@@ -0,0 +1,24 @@
+-- Tenants isolate workflows, executions, credentials, and projects. Existing
+-- rows belong to the default tenant. SQLite cannot add a foreign key to an
+-- existing table, so tenant_id is only constrained on tables created later.
+CREATE TABLE IF NOT EXISTS tenants (
+    id VARCHAR(36) PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+INSERT OR IGNORE INTO tenants (id, name) VALUES ('00000000-0000-0000-0000-000000000001', 'Default');
+
+ALTER TABLE workflows ADD COLUMN tenant_id VARCHAR(36) NOT NULL
+    DEFAULT '00000000-0000-0000-0000-000000000001';
+ALTER TABLE executions ADD COLUMN tenant_id VARCHAR(36) NOT NULL
+    DEFAULT '00000000-0000-0000-0000-000000000001';
+ALTER TABLE credentials ADD COLUMN tenant_id VARCHAR(36) NOT NULL
+    DEFAULT '00000000-0000-0000-0000-000000000001';
+ALTER TABLE projects ADD COLUMN tenant_id VARCHAR(36) NOT NULL
+    DEFAULT '00000000-0000-0000-0000-000000000001';
+
+CREATE INDEX idx_workflows_tenant_id ON workflows(tenant_id);
+CREATE INDEX idx_executions_tenant_started_at ON executions(tenant_id, started_at DESC);
+CREATE INDEX idx_credentials_tenant_id ON credentials(tenant_id);
+CREATE INDEX idx_projects_tenant_id ON projects(tenant_id);
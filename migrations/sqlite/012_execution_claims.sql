@@ -0,0 +1,3 @@
+-- Fencing token of the worker that owns an execution. Each claim increments
+-- it, and updates carrying an older token are rejected.
+ALTER TABLE executions ADD COLUMN claim_token BIGINT NOT NULL DEFAULT 0;
@@ -0,0 +1,35 @@
+-- URLs called on engine events, and the log of each delivery to them.
+CREATE TABLE IF NOT EXISTS event_subscriptions (
+    id VARCHAR(36) PRIMARY KEY,
+    tenant_id VARCHAR(36) NOT NULL,
+    url TEXT NOT NULL,
+    description TEXT,
+    events TEXT NOT NULL,
+    secret VARCHAR(255) NOT NULL,
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (tenant_id) REFERENCES tenants(id)
+);
+
+CREATE TABLE IF NOT EXISTS event_deliveries (
+    id VARCHAR(36) PRIMARY KEY,
+    tenant_id VARCHAR(36) NOT NULL,
+    subscription_id VARCHAR(36) NOT NULL,
+    event_id VARCHAR(64) NOT NULL,
+    event_type VARCHAR(100) NOT NULL,
+    payload TEXT NOT NULL,
+    status VARCHAR(20) NOT NULL,
+    attempts INT NOT NULL DEFAULT 0,
+    response_status INT,
+    response_body TEXT,
+    error TEXT,
+    next_attempt_at TIMESTAMP NULL,
+    created_at TIMESTAMP NOT NULL,
+    delivered_at TIMESTAMP NULL,
+    FOREIGN KEY (tenant_id) REFERENCES tenants(id),
+    FOREIGN KEY (subscription_id) REFERENCES event_subscriptions(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_event_deliveries_subscription ON event_deliveries(subscription_id, created_at);
+CREATE INDEX idx_event_deliveries_due ON event_deliveries(status, next_attempt_at);
@@ -0,0 +1,6 @@
+-- Columns the storage layer reads and writes that earlier migrations never
+-- created: workflow tags, version, and metadata, and the execution context
+ALTER TABLE workflows ADD COLUMN tags TEXT DEFAULT '[]';
+ALTER TABLE workflows ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE workflows ADD COLUMN metadata TEXT DEFAULT '{}';
+ALTER TABLE executions ADD COLUMN context TEXT;
@@ -0,0 +1,9 @@
+-- Jobs waiting in the in-process queue used without Redis, kept so they
+-- survive a restart
+CREATE TABLE IF NOT EXISTS queue_jobs (
+    id VARCHAR(36) PRIMARY KEY,
+    queue VARCHAR(255) NOT NULL,
+    score DOUBLE NOT NULL,
+    payload TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL
+);
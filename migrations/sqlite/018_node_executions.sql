@@ -0,0 +1,15 @@
+-- Node runs of an execution, written in batches while it runs so progress
+-- can be queried without rewriting the execution's context
+CREATE TABLE IF NOT EXISTS node_executions (
+    execution_id VARCHAR(36) NOT NULL REFERENCES executions(id) ON DELETE CASCADE,
+    node_id VARCHAR(255) NOT NULL,
+    status VARCHAR(50) NOT NULL,
+    output TEXT,
+    error TEXT,
+    started_at TIMESTAMP NOT NULL,
+    completed_at TIMESTAMP,
+    retry_count INTEGER NOT NULL DEFAULT 0,
+    PRIMARY KEY (execution_id, node_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_node_executions_status ON node_executions(status, node_id);
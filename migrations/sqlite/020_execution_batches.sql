@@ -0,0 +1,17 @@
+-- Executions submitted together through the batch execute API, and the
+-- executions in each batch
+CREATE TABLE IF NOT EXISTS execution_batches (
+    id VARCHAR(36) PRIMARY KEY,
+    workflow_id VARCHAR(36) NOT NULL,
+    concurrency INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (workflow_id) REFERENCES workflows(id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS execution_batch_items (
+    batch_id VARCHAR(36) NOT NULL,
+    execution_id VARCHAR(36) NOT NULL,
+    PRIMARY KEY (batch_id, execution_id),
+    FOREIGN KEY (batch_id) REFERENCES execution_batches(id) ON DELETE CASCADE,
+    FOREIGN KEY (execution_id) REFERENCES executions(id) ON DELETE CASCADE
+);
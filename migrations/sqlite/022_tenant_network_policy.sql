@@ -0,0 +1,3 @@
+-- The networks a tenant's nodes and webhooks may and may not reach, on top
+-- of the server's network policy
+ALTER TABLE tenants ADD COLUMN network_policy TEXT;
@@ -0,0 +1,21 @@
+-- Cron schedules that run a workflow with a fixed input. next_run_at is
+-- NULL while a schedule is paused.
+CREATE TABLE IF NOT EXISTS schedules (
+    id VARCHAR(36) PRIMARY KEY,
+    tenant_id VARCHAR(36) NOT NULL,
+    workflow_id VARCHAR(36) NOT NULL,
+    name VARCHAR(255) NOT NULL DEFAULT '',
+    cron_expr VARCHAR(255) NOT NULL,
+    timezone VARCHAR(100) NOT NULL DEFAULT 'UTC',
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+    next_run_at TIMESTAMP NULL,
+    last_run_at TIMESTAMP NULL,
+    input TEXT,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    FOREIGN KEY (tenant_id) REFERENCES tenants(id),
+    FOREIGN KEY (workflow_id) REFERENCES workflows(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_schedules_workflow ON schedules(workflow_id);
+CREATE INDEX idx_schedules_due ON schedules(is_active, next_run_at);
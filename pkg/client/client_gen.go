@@ -0,0 +1,2735 @@
+// Code generated by cmd/openapi from the OpenAPI spec; DO NOT EDIT.
+
+package client
+
+import (
+	"context"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// APIKey is the APIKey schema
+type APIKey struct {
+	CreatedAt      time.Time  `json:"created_at"`
+	ExpiresAt      *time.Time `json:"expires_at,omitempty"`
+	ID             uuid.UUID  `json:"id"`
+	LastUsedAt     *time.Time `json:"last_used_at,omitempty"`
+	Name           string     `json:"name"`
+	Prefix         string     `json:"prefix"`
+	RateLimit      string     `json:"rate_limit"`
+	RevokedAt      *time.Time `json:"revoked_at,omitempty"`
+	Scopes         []string   `json:"scopes"`
+	ServiceAccount string     `json:"service_account"`
+	TenantID       uuid.UUID  `json:"tenant_id"`
+	UserID         *uuid.UUID `json:"user_id,omitempty"`
+}
+
+// Alert is the Alert schema
+type Alert struct {
+	Deliveries  []AlertDelivery `json:"deliveries"`
+	ExecutionID *uuid.UUID      `json:"execution_id,omitempty"`
+	FiredAt     time.Time       `json:"fired_at"`
+	ID          uuid.UUID       `json:"id"`
+	Message     string          `json:"message"`
+	RuleID      uuid.UUID       `json:"rule_id"`
+	RuleName    string          `json:"rule_name"`
+	Value       float64         `json:"value"`
+	WorkflowID  *uuid.UUID      `json:"workflow_id,omitempty"`
+}
+
+// AlertChannel is the AlertChannel schema
+type AlertChannel struct {
+	Config    map[string]interface{} `json:"config"`
+	CreatedAt time.Time              `json:"created_at"`
+	ID        uuid.UUID              `json:"id"`
+	Name      string                 `json:"name"`
+	Type      string                 `json:"type"`
+	UpdatedAt time.Time              `json:"updated_at"`
+}
+
+// AlertChannelRequest is the AlertChannelRequest schema
+type AlertChannelRequest struct {
+	Config map[string]interface{} `json:"config"`
+	Name   string                 `json:"name"`
+	Type   string                 `json:"type"`
+}
+
+// AlertDelivery is the AlertDelivery schema
+type AlertDelivery struct {
+	ChannelID uuid.UUID `json:"channel_id"`
+	Error     string    `json:"error"`
+}
+
+// AlertRule is the AlertRule schema
+type AlertRule struct {
+	ChannelIds      []uuid.UUID `json:"channel_ids"`
+	Condition       string      `json:"condition"`
+	CooldownSeconds int         `json:"cooldown_seconds"`
+	CreatedAt       time.Time   `json:"created_at"`
+	Enabled         bool        `json:"enabled"`
+	ID              uuid.UUID   `json:"id"`
+	LastFiredAt     *time.Time  `json:"last_fired_at,omitempty"`
+	Name            string      `json:"name"`
+	Threshold       int         `json:"threshold"`
+	UpdatedAt       time.Time   `json:"updated_at"`
+	WindowSeconds   int         `json:"window_seconds"`
+	WorkflowID      *uuid.UUID  `json:"workflow_id,omitempty"`
+}
+
+// AlertRuleRequest is the AlertRuleRequest schema
+type AlertRuleRequest struct {
+	ChannelIds      []uuid.UUID `json:"channel_ids"`
+	Condition       string      `json:"condition"`
+	CooldownSeconds int         `json:"cooldown_seconds"`
+	Enabled         *bool       `json:"enabled,omitempty"`
+	Name            string      `json:"name"`
+	Threshold       int         `json:"threshold"`
+	WindowSeconds   int         `json:"window_seconds"`
+	WorkflowID      *uuid.UUID  `json:"workflow_id,omitempty"`
+}
+
+// BatchRequest is the BatchRequest schema
+type BatchRequest struct {
+	BatchID     string                   `json:"batch_id"`
+	Concurrency int                      `json:"concurrency"`
+	Inputs      []map[string]interface{} `json:"inputs"`
+}
+
+// BatchResponse is the BatchResponse schema
+type BatchResponse struct {
+	Batch        *ExecutionBatch `json:"batch,omitempty"`
+	ExecutionIds []uuid.UUID     `json:"execution_ids"`
+}
+
+// CaptureRequest is the CaptureRequest schema
+type CaptureRequest struct {
+	ExecutionID string `json:"execution_id"`
+}
+
+// Change is the Change schema
+type Change struct {
+	From interface{} `json:"from"`
+	Path string      `json:"path"`
+	To   interface{} `json:"to"`
+}
+
+// CompleteTaskRequest is the CompleteTaskRequest schema
+type CompleteTaskRequest struct {
+	Result   map[string]interface{} `json:"result"`
+	WorkerID string                 `json:"worker_id"`
+}
+
+// CreateAPIKeyRequest is the CreateAPIKeyRequest schema
+type CreateAPIKeyRequest struct {
+	ExpiresAt      *time.Time `json:"expires_at,omitempty"`
+	Name           string     `json:"name"`
+	RateLimit      string     `json:"rate_limit"`
+	Scopes         []string   `json:"scopes"`
+	ServiceAccount string     `json:"service_account"`
+}
+
+// CreateAPIKeyResponse is the CreateAPIKeyResponse schema
+type CreateAPIKeyResponse struct {
+	CreatedAt      time.Time  `json:"created_at"`
+	ExpiresAt      *time.Time `json:"expires_at,omitempty"`
+	ID             uuid.UUID  `json:"id"`
+	Key            string     `json:"key"`
+	LastUsedAt     *time.Time `json:"last_used_at,omitempty"`
+	Name           string     `json:"name"`
+	Prefix         string     `json:"prefix"`
+	RateLimit      string     `json:"rate_limit"`
+	RevokedAt      *time.Time `json:"revoked_at,omitempty"`
+	Scopes         []string   `json:"scopes"`
+	ServiceAccount string     `json:"service_account"`
+	TenantID       uuid.UUID  `json:"tenant_id"`
+	UserID         *uuid.UUID `json:"user_id,omitempty"`
+}
+
+// CreateCredentialRequest is the CreateCredentialRequest schema
+type CreateCredentialRequest struct {
+	Data map[string]interface{} `json:"data"`
+	Name string                 `json:"name"`
+	Type string                 `json:"type"`
+}
+
+// Credential is the Credential schema
+type Credential struct {
+	CreatedAt time.Time `json:"created_at"`
+	ID        uuid.UUID `json:"id"`
+	Name      string    `json:"name"`
+	Type      string    `json:"type"`
+	UpdatedAt time.Time `json:"updated_at"`
+	UserID    uuid.UUID `json:"user_id"`
+}
+
+// DailyExecutionCount is the DailyExecutionCount schema
+type DailyExecutionCount struct {
+	Completed int    `json:"completed"`
+	Date      string `json:"date"`
+	Failed    int    `json:"failed"`
+	Total     int    `json:"total"`
+}
+
+// DebugCommand is the DebugCommand schema
+type DebugCommand struct {
+	Action string                 `json:"action"`
+	Input  map[string]interface{} `json:"input"`
+}
+
+// DebugState is the DebugState schema
+type DebugState struct {
+	ExecutionID string                 `json:"execution_id"`
+	Input       map[string]interface{} `json:"input"`
+	NodeID      string                 `json:"node_id"`
+	NodeType    string                 `json:"node_type"`
+	Paused      bool                   `json:"paused"`
+	PausedAt    *time.Time             `json:"paused_at,omitempty"`
+}
+
+// DependencyCheck is the DependencyCheck schema
+type DependencyCheck struct {
+	Detail    interface{} `json:"detail"`
+	Error     string      `json:"error"`
+	LatencyMs float64     `json:"latency_ms"`
+	Status    string      `json:"status"`
+}
+
+// DeployRequest is the DeployRequest schema
+type DeployRequest struct {
+	Credentials map[string]string      `json:"credentials"`
+	Variables   map[string]interface{} `json:"variables"`
+	Version     int                    `json:"version"`
+}
+
+// Deployment is the Deployment schema
+type Deployment struct {
+	Credentials map[string]string      `json:"credentials"`
+	DeployedAt  time.Time              `json:"deployed_at"`
+	DeployedBy  uuid.UUID              `json:"deployed_by"`
+	Environment string                 `json:"environment"`
+	Variables   map[string]interface{} `json:"variables"`
+	Version     int                    `json:"version"`
+	WorkflowID  uuid.UUID              `json:"workflow_id"`
+}
+
+// DeprecatedNodeUsage is the DeprecatedNodeUsage schema
+type DeprecatedNodeUsage struct {
+	LatestVersion int       `json:"latest_version"`
+	Message       string    `json:"message"`
+	NodeID        string    `json:"node_id"`
+	Type          string    `json:"type"`
+	Version       int       `json:"version"`
+	WorkflowID    uuid.UUID `json:"workflow_id"`
+	WorkflowName  string    `json:"workflow_name"`
+}
+
+// DuplicateRequest is the DuplicateRequest schema
+type DuplicateRequest struct {
+	Credentials map[string]string `json:"credentials"`
+	Name        string            `json:"name"`
+}
+
+// Edge is the Edge schema
+type Edge struct {
+	Condition    *EdgeCondition    `json:"condition,omitempty"`
+	ID           string            `json:"id"`
+	InputMapping map[string]string `json:"input_mapping"`
+	Metadata     map[string]string `json:"metadata"`
+	Source       string            `json:"source"`
+	SourcePort   string            `json:"source_port"`
+	Target       string            `json:"target"`
+	TargetPort   string            `json:"target_port"`
+}
+
+// EdgeCondition is the EdgeCondition schema
+type EdgeCondition struct {
+	Expression string      `json:"expression"`
+	Field      string      `json:"field"`
+	Operator   string      `json:"operator"`
+	Type       string      `json:"type"`
+	Value      interface{} `json:"value"`
+}
+
+// ElementChange is the ElementChange schema
+type ElementChange struct {
+	Changes []Change `json:"changes"`
+	ID      string   `json:"id"`
+}
+
+// Environment is the Environment schema
+type Environment struct {
+	CreatedAt   time.Time `json:"created_at"`
+	Description string    `json:"description"`
+	ID          uuid.UUID `json:"id"`
+	Name        string    `json:"name"`
+}
+
+// ErrorResponse is the ErrorResponse schema
+type ErrorResponse struct {
+	Error string `json:"error"`
+}
+
+// EvaluateRequest is the EvaluateRequest schema
+type EvaluateRequest struct {
+	ExecutionID string                 `json:"execution_id"`
+	Expression  string                 `json:"expression"`
+	NodeID      string                 `json:"node_id"`
+	Payload     map[string]interface{} `json:"payload"`
+}
+
+// EventDelivery is the EventDelivery schema
+type EventDelivery struct {
+	Attempts       int         `json:"attempts"`
+	CreatedAt      time.Time   `json:"created_at"`
+	DeliveredAt    *time.Time  `json:"delivered_at,omitempty"`
+	Error          string      `json:"error"`
+	EventID        string      `json:"event_id"`
+	EventType      string      `json:"event_type"`
+	ID             uuid.UUID   `json:"id"`
+	NextAttemptAt  *time.Time  `json:"next_attempt_at,omitempty"`
+	Payload        interface{} `json:"payload"`
+	ResponseBody   string      `json:"response_body"`
+	ResponseStatus *int        `json:"response_status,omitempty"`
+	Status         string      `json:"status"`
+	SubscriptionID uuid.UUID   `json:"subscription_id"`
+}
+
+// EventSubscription is the EventSubscription schema
+type EventSubscription struct {
+	CreatedAt   time.Time `json:"created_at"`
+	Description string    `json:"description"`
+	Enabled     bool      `json:"enabled"`
+	Events      []string  `json:"events"`
+	ID          uuid.UUID `json:"id"`
+	Secret      string    `json:"secret"`
+	UpdatedAt   time.Time `json:"updated_at"`
+	URL         string    `json:"url"`
+}
+
+// EventSubscriptionRequest is the EventSubscriptionRequest schema
+type EventSubscriptionRequest struct {
+	Description string   `json:"description"`
+	Enabled     *bool    `json:"enabled,omitempty"`
+	Events      []string `json:"events"`
+	Secret      string   `json:"secret"`
+	URL         string   `json:"url"`
+}
+
+// Execution is the Execution schema
+type Execution struct {
+	CompletedAt *time.Time             `json:"completed_at,omitempty"`
+	Context     ExecutionContext       `json:"context"`
+	Error       *string                `json:"error,omitempty"`
+	ID          uuid.UUID              `json:"id"`
+	Input       map[string]interface{} `json:"input"`
+	Metadata    map[string]interface{} `json:"metadata"`
+	Output      map[string]interface{} `json:"output"`
+	StartedAt   time.Time              `json:"started_at"`
+	Status      string                 `json:"status"`
+	TenantID    uuid.UUID              `json:"tenant_id"`
+	Usage       *ExecutionUsage        `json:"usage,omitempty"`
+	WorkflowID  uuid.UUID              `json:"workflow_id"`
+}
+
+// ExecutionBatch is the ExecutionBatch schema
+type ExecutionBatch struct {
+	Concurrency int            `json:"concurrency"`
+	Counts      map[string]int `json:"counts"`
+	CreatedAt   time.Time      `json:"created_at"`
+	Done        bool           `json:"done"`
+	ID          uuid.UUID      `json:"id"`
+	Total       int            `json:"total"`
+	WorkflowID  uuid.UUID      `json:"workflow_id"`
+}
+
+// ExecutionContext is the ExecutionContext schema
+type ExecutionContext struct {
+	CurrentNodeID  string                   `json:"current_node_id"`
+	Logs           []LogEntry               `json:"logs"`
+	NodeExecutions map[string]NodeExecution `json:"node_executions"`
+	Stack          []string                 `json:"stack"`
+	Variables      map[string]interface{}   `json:"variables"`
+}
+
+// ExecutionDiff is the ExecutionDiff schema
+type ExecutionDiff struct {
+	A               ExecutionRun `json:"a"`
+	B               ExecutionRun `json:"b"`
+	FirstDivergence string       `json:"first_divergence"`
+	InputChanges    []Change     `json:"input_changes"`
+	Nodes           []NodeDiff   `json:"nodes"`
+}
+
+// ExecutionFailure is the ExecutionFailure schema
+type ExecutionFailure struct {
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+	Error       string     `json:"error"`
+	ExecutionID uuid.UUID  `json:"execution_id"`
+	NodeID      string     `json:"node_id"`
+	StartedAt   time.Time  `json:"started_at"`
+}
+
+// ExecutionPlan is the ExecutionPlan schema
+type ExecutionPlan struct {
+	CriticalPath    []string           `json:"critical_path"`
+	CriticalPathMs  float64            `json:"critical_path_ms"`
+	Cycles          [][]string         `json:"cycles"`
+	Groups          [][]string         `json:"groups"`
+	NodeDurationsMs map[string]float64 `json:"node_durations_ms"`
+	Order           []string           `json:"order"`
+	Unestimated     []string           `json:"unestimated"`
+}
+
+// ExecutionRun is the ExecutionRun schema
+type ExecutionRun struct {
+	DurationMs *int64    `json:"duration_ms,omitempty"`
+	Error      *string   `json:"error,omitempty"`
+	ID         string    `json:"id"`
+	StartedAt  time.Time `json:"started_at"`
+	Status     string    `json:"status"`
+}
+
+// ExecutionUsage is the ExecutionUsage schema
+type ExecutionUsage struct {
+	HTTPBytesReceived   int64 `json:"http_bytes_received"`
+	HTTPBytesSent       int64 `json:"http_bytes_sent"`
+	LlmCompletionTokens int64 `json:"llm_completion_tokens"`
+	LlmPromptTokens     int64 `json:"llm_prompt_tokens"`
+	Nodes               int   `json:"nodes"`
+	RuntimeMs           int64 `json:"runtime_ms"`
+}
+
+// ExpressionResult is the ExpressionResult schema
+type ExpressionResult struct {
+	Error   string      `json:"error"`
+	Missing []string    `json:"missing"`
+	Result  interface{} `json:"result"`
+	Type    string      `json:"type"`
+}
+
+// ExtendLockRequest is the ExtendLockRequest schema
+type ExtendLockRequest struct {
+	LockDurationMs int    `json:"lock_duration_ms"`
+	WorkerID       string `json:"worker_id"`
+}
+
+// ExternalTask is the ExternalTask schema
+type ExternalTask struct {
+	Attempts    int                    `json:"attempts"`
+	CreatedAt   time.Time              `json:"created_at"`
+	Error       *string                `json:"error,omitempty"`
+	ExecutionID uuid.UUID              `json:"execution_id"`
+	ID          uuid.UUID              `json:"id"`
+	LockedUntil *time.Time             `json:"locked_until,omitempty"`
+	NodeID      string                 `json:"node_id"`
+	Payload     map[string]interface{} `json:"payload"`
+	Result      map[string]interface{} `json:"result"`
+	Status      string                 `json:"status"`
+	TenantID    uuid.UUID              `json:"tenant_id"`
+	Topic       string                 `json:"topic"`
+	UpdatedAt   time.Time              `json:"updated_at"`
+	WorkerID    string                 `json:"worker_id"`
+	WorkflowID  uuid.UUID              `json:"workflow_id"`
+}
+
+// FailTaskRequest is the FailTaskRequest schema
+type FailTaskRequest struct {
+	ErrorMessage   string `json:"error_message"`
+	Retries        int    `json:"retries"`
+	RetryTimeoutMs int    `json:"retry_timeout_ms"`
+	WorkerID       string `json:"worker_id"`
+}
+
+// FetchAndLockRequest is the FetchAndLockRequest schema
+type FetchAndLockRequest struct {
+	LockDurationMs int      `json:"lock_duration_ms"`
+	MaxTasks       int      `json:"max_tasks"`
+	Topics         []string `json:"topics"`
+	WaitMs         int      `json:"wait_ms"`
+	WorkerID       string   `json:"worker_id"`
+}
+
+// FieldError is the FieldError schema
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+	NodeID  string `json:"node_id"`
+}
+
+// HealthResponse is the HealthResponse schema
+type HealthResponse struct {
+	Services map[string]bool `json:"services"`
+	Status   string          `json:"status"`
+}
+
+// JobResponse is the JobResponse schema
+type JobResponse struct {
+	JobID string `json:"job_id"`
+}
+
+// Limits is the Limits schema
+type Limits struct {
+	Plan     string      `json:"plan"`
+	Quotas   Quotas      `json:"quotas"`
+	ResetsAt time.Time   `json:"resets_at"`
+	TenantID uuid.UUID   `json:"tenant_id"`
+	Usage    LimitsUsage `json:"usage"`
+}
+
+// LimitsUsage is the LimitsUsage schema
+type LimitsUsage struct {
+	ActiveWorkflows int `json:"active_workflows"`
+	ExecutionsToday int `json:"executions_today"`
+}
+
+// LintIssue is the LintIssue schema
+type LintIssue struct {
+	Field    string `json:"field"`
+	Hint     string `json:"hint"`
+	Message  string `json:"message"`
+	NodeID   string `json:"node_id"`
+	Rule     string `json:"rule"`
+	Severity string `json:"severity"`
+}
+
+// LintResponse is the LintResponse schema
+type LintResponse struct {
+	Issues []LintIssue `json:"issues"`
+	Valid  bool        `json:"valid"`
+}
+
+// LogEntry is the LogEntry schema
+type LogEntry struct {
+	Data      map[string]interface{} `json:"data"`
+	Level     string                 `json:"level"`
+	Message   string                 `json:"message"`
+	NodeID    string                 `json:"node_id"`
+	Timestamp time.Time              `json:"timestamp"`
+}
+
+// MessageResponse is the MessageResponse schema
+type MessageResponse struct {
+	Message string `json:"message"`
+}
+
+// NetworkPolicy is the NetworkPolicy schema
+type NetworkPolicy struct {
+	Allow []string `json:"allow"`
+	Deny  []string `json:"deny"`
+}
+
+// Node is the Node schema
+type Node struct {
+	CacheTtl     int                    `json:"cache_ttl"`
+	Config       map[string]interface{} `json:"config"`
+	Description  string                 `json:"description"`
+	Disabled     bool                   `json:"disabled"`
+	ID           string                 `json:"id"`
+	InputMapping map[string]string      `json:"input_mapping"`
+	Inputs       []NodeInput            `json:"inputs"`
+	ItemsPath    string                 `json:"items_path"`
+	Name         string                 `json:"name"`
+	Outputs      []NodeOutput           `json:"outputs"`
+	PinnedData   map[string]interface{} `json:"pinned_data"`
+	Position     Position               `json:"position"`
+	Type         string                 `json:"type"`
+	Version      int                    `json:"version"`
+	WorkerLabels map[string]string      `json:"worker_labels"`
+}
+
+// NodeContextResponse is the NodeContextResponse schema
+type NodeContextResponse struct {
+	ExecutionID string                   `json:"execution_id"`
+	Input       map[string]interface{}   `json:"input"`
+	Items       []map[string]interface{} `json:"items"`
+	NodeID      string                   `json:"node_id"`
+	NodeOutputs map[string]interface{}   `json:"node_outputs"`
+	StartedAt   time.Time                `json:"started_at"`
+	Status      string                   `json:"status"`
+	Variables   map[string]interface{}   `json:"variables"`
+}
+
+// NodeDiff is the NodeDiff schema
+type NodeDiff struct {
+	A               *NodeRun `json:"a,omitempty"`
+	B               *NodeRun `json:"b,omitempty"`
+	Changed         bool     `json:"changed"`
+	DurationDeltaMs *int64   `json:"duration_delta_ms,omitempty"`
+	NodeID          string   `json:"node_id"`
+	OutputChanges   []Change `json:"output_changes"`
+}
+
+// NodeExecution is the NodeExecution schema
+type NodeExecution struct {
+	Cached      bool                   `json:"cached"`
+	CompletedAt *time.Time             `json:"completed_at,omitempty"`
+	Error       *string                `json:"error,omitempty"`
+	Input       map[string]interface{} `json:"input"`
+	NodeID      string                 `json:"node_id"`
+	Output      map[string]interface{} `json:"output"`
+	Ports       []string               `json:"ports"`
+	RetryCount  int                    `json:"retry_count"`
+	Snapshot    *NodeSnapshot          `json:"snapshot,omitempty"`
+	StartedAt   time.Time              `json:"started_at"`
+	Status      string                 `json:"status"`
+}
+
+// NodeFailureCount is the NodeFailureCount schema
+type NodeFailureCount struct {
+	Failures int    `json:"failures"`
+	NodeID   string `json:"node_id"`
+}
+
+// NodeInfo is the NodeInfo schema
+type NodeInfo struct {
+	Category    string            `json:"category"`
+	Deprecated  map[string]string `json:"deprecated"`
+	Description string            `json:"description"`
+	Icon        string            `json:"icon"`
+	Name        string            `json:"name"`
+	Type        string            `json:"type"`
+	Version     int               `json:"version"`
+	Versions    []int             `json:"versions"`
+}
+
+// NodeInput is the NodeInput schema
+type NodeInput struct {
+	Name     string `json:"name"`
+	Required bool   `json:"required"`
+	Type     string `json:"type"`
+}
+
+// NodeListResponse is the NodeListResponse schema
+type NodeListResponse struct {
+	Nodes []NodeInfo `json:"nodes"`
+}
+
+// NodeOutput is the NodeOutput schema
+type NodeOutput struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+// NodeRun is the NodeRun schema
+type NodeRun struct {
+	Cached     bool    `json:"cached"`
+	DurationMs *int64  `json:"duration_ms,omitempty"`
+	Error      *string `json:"error,omitempty"`
+	Status     string  `json:"status"`
+}
+
+// NodeSnapshot is the NodeSnapshot schema
+type NodeSnapshot struct {
+	Input     map[string]interface{}   `json:"input"`
+	Items     []map[string]interface{} `json:"items"`
+	Upstream  []string                 `json:"upstream"`
+	Variables map[string]interface{}   `json:"variables"`
+}
+
+// PinnedDataRequest is the PinnedDataRequest schema
+type PinnedDataRequest struct {
+	Data map[string]interface{} `json:"data"`
+}
+
+// Plan is the Plan schema
+type Plan struct {
+	CreatedAt   time.Time `json:"created_at"`
+	Description string    `json:"description"`
+	Name        string    `json:"name"`
+	Quotas      Quotas    `json:"quotas"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// PlanRequest is the PlanRequest schema
+type PlanRequest struct {
+	Description string `json:"description"`
+	Quotas      Quotas `json:"quotas"`
+}
+
+// Position is the Position schema
+type Position struct {
+	X float64 `json:"x"`
+	Y float64 `json:"y"`
+}
+
+// Project is the Project schema
+type Project struct {
+	CreatedAt   time.Time  `json:"created_at"`
+	Description string     `json:"description"`
+	ID          uuid.UUID  `json:"id"`
+	Name        string     `json:"name"`
+	ParentID    *uuid.UUID `json:"parent_id,omitempty"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+	UserID      uuid.UUID  `json:"user_id"`
+}
+
+// PromoteRequest is the PromoteRequest schema
+type PromoteRequest struct {
+	DryRun  bool   `json:"dry_run"`
+	From    string `json:"from"`
+	To      string `json:"to"`
+	Version int    `json:"version"`
+}
+
+// PromoteResponse is the PromoteResponse schema
+type PromoteResponse struct {
+	Deployment      *Deployment   `json:"deployment,omitempty"`
+	Diff            *WorkflowDiff `json:"diff,omitempty"`
+	From            string        `json:"from"`
+	PreviousVersion *int          `json:"previous_version,omitempty"`
+	To              string        `json:"to"`
+	Version         int           `json:"version"`
+}
+
+// QueueStats is the QueueStats schema
+type QueueStats struct {
+	ActiveJobs          int              `json:"active_jobs"`
+	Capacity            int              `json:"capacity"`
+	Delayed             int64            `json:"delayed"`
+	OldestJobAgeSeconds float64          `json:"oldest_job_age_seconds"`
+	Pending             int64            `json:"pending"`
+	Queues              map[string]int64 `json:"queues"`
+	Utilization         float64          `json:"utilization"`
+	Workers             int              `json:"workers"`
+}
+
+// Quotas is the Quotas schema
+type Quotas struct {
+	MaxActiveWorkflows  *int `json:"max_active_workflows,omitempty"`
+	MaxExecutionSeconds *int `json:"max_execution_seconds,omitempty"`
+	MaxExecutionsPerDay *int `json:"max_executions_per_day,omitempty"`
+	MaxPayloadSize      *int `json:"max_payload_size,omitempty"`
+}
+
+// ReadinessResponse is the ReadinessResponse schema
+type ReadinessResponse struct {
+	Checks map[string]DependencyCheck `json:"checks"`
+	Status string                     `json:"status"`
+}
+
+// Schedule is the Schedule schema
+type Schedule struct {
+	CreatedAt  time.Time              `json:"created_at"`
+	CronExpr   string                 `json:"cron_expr"`
+	ID         uuid.UUID              `json:"id"`
+	Input      map[string]interface{} `json:"input"`
+	IsActive   bool                   `json:"is_active"`
+	LastRunAt  *time.Time             `json:"last_run_at,omitempty"`
+	Name       string                 `json:"name"`
+	NextRunAt  *time.Time             `json:"next_run_at,omitempty"`
+	Timezone   string                 `json:"timezone"`
+	UpdatedAt  time.Time              `json:"updated_at"`
+	WorkflowID uuid.UUID              `json:"workflow_id"`
+}
+
+// SchedulePreview is the SchedulePreview schema
+type SchedulePreview struct {
+	CronExpr string      `json:"cron_expr"`
+	Next     []time.Time `json:"next"`
+	Timezone string      `json:"timezone"`
+}
+
+// ScheduleRequest is the ScheduleRequest schema
+type ScheduleRequest struct {
+	CronExpr   string                 `json:"cron_expr"`
+	Input      map[string]interface{} `json:"input"`
+	IsActive   *bool                  `json:"is_active,omitempty"`
+	Name       string                 `json:"name"`
+	Timezone   string                 `json:"timezone"`
+	WorkflowID string                 `json:"workflow_id"`
+}
+
+// StateRequest is the StateRequest schema
+type StateRequest struct {
+	Value interface{} `json:"value"`
+}
+
+// SyncChange is the SyncChange schema
+type SyncChange struct {
+	Action  string   `json:"action"`
+	Changes []Change `json:"changes"`
+	ID      string   `json:"id"`
+	Name    string   `json:"name"`
+}
+
+// SyncRequest is the SyncRequest schema
+type SyncRequest struct {
+	DryRun    bool       `json:"dry_run"`
+	Source    string     `json:"source"`
+	Workflows []Workflow `json:"workflows"`
+}
+
+// SyncResponse is the SyncResponse schema
+type SyncResponse struct {
+	Changes []SyncChange `json:"changes"`
+	DryRun  bool         `json:"dry_run"`
+}
+
+// Tenant is the Tenant schema
+type Tenant struct {
+	CreatedAt     time.Time      `json:"created_at"`
+	ID            uuid.UUID      `json:"id"`
+	Name          string         `json:"name"`
+	NetworkPolicy *NetworkPolicy `json:"network_policy,omitempty"`
+	Plan          string         `json:"plan"`
+	Quotas        *Quotas        `json:"quotas,omitempty"`
+}
+
+// TenantQuotas is the TenantQuotas schema
+type TenantQuotas struct {
+	Plan   string  `json:"plan"`
+	Quotas *Quotas `json:"quotas,omitempty"`
+}
+
+// UsageReport is the UsageReport schema
+type UsageReport struct {
+	Executions int            `json:"executions"`
+	From       time.Time      `json:"from"`
+	Rollups    []UsageRollup  `json:"rollups"`
+	To         time.Time      `json:"to"`
+	Total      ExecutionUsage `json:"total"`
+}
+
+// UsageRollup is the UsageRollup schema
+type UsageRollup struct {
+	Executions int            `json:"executions"`
+	TenantID   uuid.UUID      `json:"tenant_id"`
+	Usage      ExecutionUsage `json:"usage"`
+	WorkflowID *uuid.UUID     `json:"workflow_id,omitempty"`
+}
+
+// ValidationResponse is the ValidationResponse schema
+type ValidationResponse struct {
+	Errors []FieldError `json:"errors"`
+	Valid  bool         `json:"valid"`
+}
+
+// Variable is the Variable schema
+type Variable struct {
+	CreatedAt   time.Time   `json:"created_at"`
+	Environment string      `json:"environment"`
+	ID          uuid.UUID   `json:"id"`
+	Name        string      `json:"name"`
+	Secret      bool        `json:"secret"`
+	UpdatedAt   time.Time   `json:"updated_at"`
+	Value       interface{} `json:"value"`
+}
+
+// VariableRequest is the VariableRequest schema
+type VariableRequest struct {
+	Environment string      `json:"environment"`
+	Name        string      `json:"name"`
+	Secret      bool        `json:"secret"`
+	Value       interface{} `json:"value"`
+}
+
+// VersionDiff is the VersionDiff schema
+type VersionDiff struct {
+	Diff *WorkflowDiff `json:"diff,omitempty"`
+	From int           `json:"from"`
+	To   int           `json:"to"`
+}
+
+// WorkerInfo is the WorkerInfo schema
+type WorkerInfo struct {
+	ActiveJobs    int               `json:"active_jobs"`
+	Concurrency   int               `json:"concurrency"`
+	FailedJobs    int64             `json:"failed_jobs"`
+	Hostname      string            `json:"hostname"`
+	ID            string            `json:"id"`
+	Labels        map[string]string `json:"labels"`
+	LastHeartbeat time.Time         `json:"last_heartbeat"`
+	ProcessedJobs int64             `json:"processed_jobs"`
+	Queues        []string          `json:"queues"`
+	StartedAt     time.Time         `json:"started_at"`
+	Status        string            `json:"status"`
+	Version       string            `json:"version"`
+}
+
+// Workflow is the Workflow schema
+type Workflow struct {
+	CreatedAt   time.Time              `json:"created_at"`
+	Definition  WorkflowDefinition     `json:"definition"`
+	Description string                 `json:"description"`
+	ID          uuid.UUID              `json:"id"`
+	IsActive    bool                   `json:"is_active"`
+	Metadata    map[string]interface{} `json:"metadata"`
+	Name        string                 `json:"name"`
+	ProjectID   *uuid.UUID             `json:"project_id,omitempty"`
+	Status      string                 `json:"status"`
+	Tags        []string               `json:"tags"`
+	TenantID    uuid.UUID              `json:"tenant_id"`
+	UpdatedAt   time.Time              `json:"updated_at"`
+	UserID      uuid.UUID              `json:"user_id"`
+	Version     int                    `json:"version"`
+}
+
+// WorkflowDefinition is the WorkflowDefinition schema
+type WorkflowDefinition struct {
+	Edges       []Edge                 `json:"edges"`
+	Nodes       []Node                 `json:"nodes"`
+	Settings    WorkflowSettings       `json:"settings"`
+	StartNodeID string                 `json:"start_node_id"`
+	Variables   map[string]interface{} `json:"variables"`
+}
+
+// WorkflowDiff is the WorkflowDiff schema
+type WorkflowDiff struct {
+	Changes      []Change        `json:"changes"`
+	EdgesAdded   []Edge          `json:"edges_added"`
+	EdgesChanged []ElementChange `json:"edges_changed"`
+	EdgesRemoved []Edge          `json:"edges_removed"`
+	NodesAdded   []Node          `json:"nodes_added"`
+	NodesChanged []ElementChange `json:"nodes_changed"`
+	NodesRemoved []Node          `json:"nodes_removed"`
+}
+
+// WorkflowSettings is the WorkflowSettings schema
+type WorkflowSettings struct {
+	ConcurrencyPolicy string                 `json:"concurrency_policy"`
+	ErrorHandling     string                 `json:"error_handling"`
+	ExecutionMode     string                 `json:"execution_mode"`
+	MaxConcurrency    int                    `json:"max_concurrency"`
+	MaxNodeInputSize  int                    `json:"max_node_input_size"`
+	MaxNodeOutputSize int                    `json:"max_node_output_size"`
+	NodeTimeout       int                    `json:"node_timeout"`
+	PartitionKey      string                 `json:"partition_key"`
+	RetryCount        int                    `json:"retry_count"`
+	RetryDelay        int                    `json:"retry_delay"`
+	SaveExecutionLog  bool                   `json:"save_execution_log"`
+	Timeout           int                    `json:"timeout"`
+	Variables         map[string]interface{} `json:"variables"`
+	WorkerLabels      map[string]string      `json:"worker_labels"`
+}
+
+// WorkflowState is the WorkflowState schema
+type WorkflowState struct {
+	Key        string      `json:"key"`
+	UpdatedAt  time.Time   `json:"updated_at"`
+	Value      interface{} `json:"value"`
+	WorkflowID uuid.UUID   `json:"workflow_id"`
+}
+
+// WorkflowStats is the WorkflowStats schema
+type WorkflowStats struct {
+	DurationP50Ms float64               `json:"duration_p50_ms"`
+	DurationP95Ms float64               `json:"duration_p95_ms"`
+	GeneratedAt   time.Time             `json:"generated_at"`
+	LastFailure   *ExecutionFailure     `json:"last_failure,omitempty"`
+	NodeFailures  []NodeFailureCount    `json:"node_failures"`
+	PerDay        []DailyExecutionCount `json:"per_day"`
+	Since         time.Time             `json:"since"`
+	StatusCounts  map[string]int        `json:"status_counts"`
+	SuccessRate   float64               `json:"success_rate"`
+	Total         int                   `json:"total"`
+	Window        string                `json:"window"`
+	WorkflowID    uuid.UUID             `json:"workflow_id"`
+}
+
+// WorkflowVersion is the WorkflowVersion schema
+type WorkflowVersion struct {
+	CreatedAt   time.Time          `json:"created_at"`
+	Definition  WorkflowDefinition `json:"definition"`
+	Description string             `json:"description"`
+	Name        string             `json:"name"`
+	UserID      uuid.UUID          `json:"user_id"`
+	Version     int                `json:"version"`
+	WorkflowID  uuid.UUID          `json:"workflow_id"`
+}
+
+// ActivateWorkflow calls POST /api/v1/workflows/{id}/activate.
+//
+// Activate a workflow so its triggers and webhooks run.
+func (c *Client) ActivateWorkflow(ctx context.Context, id string) (*Workflow, error) {
+	path := "/api/v1/workflows/" + url.PathEscape(id) + "/activate"
+	var out Workflow
+	if err := c.do(ctx, "POST", path, nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ArchiveWorkflow calls POST /api/v1/workflows/{id}/archive.
+//
+// Archive a workflow.
+func (c *Client) ArchiveWorkflow(ctx context.Context, id string) (*Workflow, error) {
+	path := "/api/v1/workflows/" + url.PathEscape(id) + "/archive"
+	var out Workflow
+	if err := c.do(ctx, "POST", path, nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// AuthorizeOAuth2Params holds the query parameters of AuthorizeOAuth2
+type AuthorizeOAuth2Params struct {
+	// Credential to authorize
+	CredentialID string
+}
+
+func (p *AuthorizeOAuth2Params) values() url.Values {
+	query := url.Values{}
+	if p.CredentialID != "" {
+		query.Set("credential_id", p.CredentialID)
+	}
+	return query
+}
+
+// AuthorizeOAuth2 calls GET /api/v1/oauth2/authorize.
+//
+// Redirect to the provider to authorize an OAuth2 credential.
+func (c *Client) AuthorizeOAuth2(ctx context.Context, params *AuthorizeOAuth2Params) error {
+	path := "/api/v1/oauth2/authorize"
+	var query url.Values
+	if params != nil {
+		query = params.values()
+	}
+	return c.do(ctx, "GET", path, query, nil, nil)
+}
+
+// CapturePinnedData calls POST /api/v1/workflows/{id}/nodes/{node}/pinned-data/capture.
+//
+// Pin a node's output from a previous execution.
+func (c *Client) CapturePinnedData(ctx context.Context, id string, node string, body *CaptureRequest) (*Workflow, error) {
+	path := "/api/v1/workflows/" + url.PathEscape(id) + "/nodes/" + url.PathEscape(node) + "/pinned-data/capture"
+	var out Workflow
+	if err := c.do(ctx, "POST", path, nil, body, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// CompareExecutionsParams holds the query parameters of CompareExecutions
+type CompareExecutionsParams struct {
+	// ID of the execution to compare from, such as the last successful one
+	A string
+	// ID of the execution to compare to
+	B string
+}
+
+func (p *CompareExecutionsParams) values() url.Values {
+	query := url.Values{}
+	if p.A != "" {
+		query.Set("a", p.A)
+	}
+	if p.B != "" {
+		query.Set("b", p.B)
+	}
+	return query
+}
+
+// CompareExecutions calls GET /api/v1/executions/compare.
+//
+// Diff two executions of a workflow node by node: status, duration, and output.
+func (c *Client) CompareExecutions(ctx context.Context, params *CompareExecutionsParams) (*ExecutionDiff, error) {
+	path := "/api/v1/executions/compare"
+	var query url.Values
+	if params != nil {
+		query = params.values()
+	}
+	var out ExecutionDiff
+	if err := c.do(ctx, "GET", path, query, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// CompleteExternalTask calls POST /api/v1/external-tasks/{id}/complete.
+//
+// Complete an external task the worker has locked.
+func (c *Client) CompleteExternalTask(ctx context.Context, id string, body *CompleteTaskRequest) (*MessageResponse, error) {
+	path := "/api/v1/external-tasks/" + url.PathEscape(id) + "/complete"
+	var out MessageResponse
+	if err := c.do(ctx, "POST", path, nil, body, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// CreateAPIKey calls POST /api/v1/api-keys.
+//
+// Create an API key; the key is only returned once.
+func (c *Client) CreateAPIKey(ctx context.Context, body *CreateAPIKeyRequest) (*CreateAPIKeyResponse, error) {
+	path := "/api/v1/api-keys"
+	var out CreateAPIKeyResponse
+	if err := c.do(ctx, "POST", path, nil, body, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// CreateAlertChannel calls POST /api/v1/alerts/channels.
+//
+// Create an email, Slack, webhook, or PagerDuty alert channel.
+func (c *Client) CreateAlertChannel(ctx context.Context, body *AlertChannelRequest) (*AlertChannel, error) {
+	path := "/api/v1/alerts/channels"
+	var out AlertChannel
+	if err := c.do(ctx, "POST", path, nil, body, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// CreateAlertRule calls POST /api/v1/alerts/rules.
+//
+// Create an alert rule.
+func (c *Client) CreateAlertRule(ctx context.Context, body *AlertRuleRequest) (*AlertRule, error) {
+	path := "/api/v1/alerts/rules"
+	var out AlertRule
+	if err := c.do(ctx, "POST", path, nil, body, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// CreateCredential calls POST /api/v1/credentials.
+//
+// Create a credential.
+func (c *Client) CreateCredential(ctx context.Context, body *CreateCredentialRequest) (*Credential, error) {
+	path := "/api/v1/credentials"
+	var out Credential
+	if err := c.do(ctx, "POST", path, nil, body, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// CreateEnvironment calls POST /api/v1/environments.
+//
+// Create an environment.
+func (c *Client) CreateEnvironment(ctx context.Context, body *Environment) (*Environment, error) {
+	path := "/api/v1/environments"
+	var out Environment
+	if err := c.do(ctx, "POST", path, nil, body, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// CreateEventSubscription calls POST /api/v1/event-subscriptions.
+//
+// Register a URL to call on events; the signing secret is only returned once.
+func (c *Client) CreateEventSubscription(ctx context.Context, body *EventSubscriptionRequest) (*EventSubscription, error) {
+	path := "/api/v1/event-subscriptions"
+	var out EventSubscription
+	if err := c.do(ctx, "POST", path, nil, body, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// CreateProject calls POST /api/v1/projects.
+//
+// Create a project.
+func (c *Client) CreateProject(ctx context.Context, body *Project) (*Project, error) {
+	path := "/api/v1/projects"
+	var out Project
+	if err := c.do(ctx, "POST", path, nil, body, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// CreateSchedule calls POST /api/v1/schedules.
+//
+// Schedule a workflow to run with an input on a cron expression.
+func (c *Client) CreateSchedule(ctx context.Context, body *ScheduleRequest) (*Schedule, error) {
+	path := "/api/v1/schedules"
+	var out Schedule
+	if err := c.do(ctx, "POST", path, nil, body, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// CreateTenant calls POST /api/v1/tenants.
+//
+// Create a tenant.
+func (c *Client) CreateTenant(ctx context.Context, body *Tenant) (*Tenant, error) {
+	path := "/api/v1/tenants"
+	var out Tenant
+	if err := c.do(ctx, "POST", path, nil, body, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// CreateVariable calls POST /api/v1/variables.
+//
+// Create a global or environment variable.
+func (c *Client) CreateVariable(ctx context.Context, body *VariableRequest) (*Variable, error) {
+	path := "/api/v1/variables"
+	var out Variable
+	if err := c.do(ctx, "POST", path, nil, body, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// CreateWorkflow calls POST /api/v1/workflows.
+//
+// Create a workflow.
+func (c *Client) CreateWorkflow(ctx context.Context, body *Workflow) (*Workflow, error) {
+	path := "/api/v1/workflows"
+	var out Workflow
+	if err := c.do(ctx, "POST", path, nil, body, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// DeactivateWorkflow calls POST /api/v1/workflows/{id}/deactivate.
+//
+// Return a workflow to draft.
+func (c *Client) DeactivateWorkflow(ctx context.Context, id string) (*Workflow, error) {
+	path := "/api/v1/workflows/" + url.PathEscape(id) + "/deactivate"
+	var out Workflow
+	if err := c.do(ctx, "POST", path, nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// DebugWebSocket calls GET /api/v1/executions/{id}/debug/ws.
+//
+// WebSocket streaming debug events and accepting debug commands.
+func (c *Client) DebugWebSocket(ctx context.Context, id string) (interface{}, error) {
+	path := "/api/v1/executions/" + url.PathEscape(id) + "/debug/ws"
+	var out interface{}
+	if err := c.do(ctx, "GET", path, nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// DebugWorkflowParams holds the query parameters of DebugWorkflow
+type DebugWorkflowParams struct {
+	// true to use the pinned data of nodes that have it instead of running them
+	PinnedData string
+}
+
+func (p *DebugWorkflowParams) values() url.Values {
+	query := url.Values{}
+	if p.PinnedData != "" {
+		query.Set("pinned_data", p.PinnedData)
+	}
+	return query
+}
+
+// DebugWorkflow calls POST /api/v1/workflows/{id}/debug.
+//
+// Start a debug execution that pauses before each node.
+func (c *Client) DebugWorkflow(ctx context.Context, id string, body map[string]interface{}, params *DebugWorkflowParams) (*Execution, error) {
+	path := "/api/v1/workflows/" + url.PathEscape(id) + "/debug"
+	var query url.Values
+	if params != nil {
+		query = params.values()
+	}
+	var out Execution
+	if err := c.do(ctx, "POST", path, query, body, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// DeleteAlertChannel calls DELETE /api/v1/alerts/channels/{id}.
+//
+// Delete an alert channel.
+func (c *Client) DeleteAlertChannel(ctx context.Context, id string) (*MessageResponse, error) {
+	path := "/api/v1/alerts/channels/" + url.PathEscape(id)
+	var out MessageResponse
+	if err := c.do(ctx, "DELETE", path, nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// DeleteAlertRule calls DELETE /api/v1/alerts/rules/{id}.
+//
+// Delete an alert rule and its alert history.
+func (c *Client) DeleteAlertRule(ctx context.Context, id string) (*MessageResponse, error) {
+	path := "/api/v1/alerts/rules/" + url.PathEscape(id)
+	var out MessageResponse
+	if err := c.do(ctx, "DELETE", path, nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// DeleteCredential calls DELETE /api/v1/credentials/{id}.
+//
+// Delete a credential.
+func (c *Client) DeleteCredential(ctx context.Context, id string) (*MessageResponse, error) {
+	path := "/api/v1/credentials/" + url.PathEscape(id)
+	var out MessageResponse
+	if err := c.do(ctx, "DELETE", path, nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// DeleteEnvironment calls DELETE /api/v1/environments/{name}.
+//
+// Delete an environment and its deployments.
+func (c *Client) DeleteEnvironment(ctx context.Context, name string) (*MessageResponse, error) {
+	path := "/api/v1/environments/" + url.PathEscape(name)
+	var out MessageResponse
+	if err := c.do(ctx, "DELETE", path, nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// DeleteEventSubscription calls DELETE /api/v1/event-subscriptions/{id}.
+//
+// Delete an event subscription and its deliveries.
+func (c *Client) DeleteEventSubscription(ctx context.Context, id string) (*MessageResponse, error) {
+	path := "/api/v1/event-subscriptions/" + url.PathEscape(id)
+	var out MessageResponse
+	if err := c.do(ctx, "DELETE", path, nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// DeletePinnedData calls DELETE /api/v1/workflows/{id}/nodes/{node}/pinned-data.
+//
+// Remove a node's pinned data.
+func (c *Client) DeletePinnedData(ctx context.Context, id string, node string) (*Workflow, error) {
+	path := "/api/v1/workflows/" + url.PathEscape(id) + "/nodes/" + url.PathEscape(node) + "/pinned-data"
+	var out Workflow
+	if err := c.do(ctx, "DELETE", path, nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// DeletePlan calls DELETE /api/v1/plans/{name}.
+//
+// Delete a quota plan no tenant is on.
+func (c *Client) DeletePlan(ctx context.Context, name string) (*MessageResponse, error) {
+	path := "/api/v1/plans/" + url.PathEscape(name)
+	var out MessageResponse
+	if err := c.do(ctx, "DELETE", path, nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// DeleteProject calls DELETE /api/v1/projects/{id}.
+//
+// Delete an empty project.
+func (c *Client) DeleteProject(ctx context.Context, id string) (*MessageResponse, error) {
+	path := "/api/v1/projects/" + url.PathEscape(id)
+	var out MessageResponse
+	if err := c.do(ctx, "DELETE", path, nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// DeleteSchedule calls DELETE /api/v1/schedules/{id}.
+//
+// Delete a schedule.
+func (c *Client) DeleteSchedule(ctx context.Context, id string) (*MessageResponse, error) {
+	path := "/api/v1/schedules/" + url.PathEscape(id)
+	var out MessageResponse
+	if err := c.do(ctx, "DELETE", path, nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// DeleteVariable calls DELETE /api/v1/variables/{id}.
+//
+// Delete a variable.
+func (c *Client) DeleteVariable(ctx context.Context, id string) (*MessageResponse, error) {
+	path := "/api/v1/variables/" + url.PathEscape(id)
+	var out MessageResponse
+	if err := c.do(ctx, "DELETE", path, nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// DeleteWorkflow calls DELETE /api/v1/workflows/{id}.
+//
+// Delete a workflow.
+func (c *Client) DeleteWorkflow(ctx context.Context, id string) (*MessageResponse, error) {
+	path := "/api/v1/workflows/" + url.PathEscape(id)
+	var out MessageResponse
+	if err := c.do(ctx, "DELETE", path, nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// DeleteWorkflowState calls DELETE /api/v1/workflows/{id}/state/{key}.
+//
+// Remove a value the workflow keeps between executions.
+func (c *Client) DeleteWorkflowState(ctx context.Context, id string, key string) (*MessageResponse, error) {
+	path := "/api/v1/workflows/" + url.PathEscape(id) + "/state/" + url.PathEscape(key)
+	var out MessageResponse
+	if err := c.do(ctx, "DELETE", path, nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// DeployWorkflow calls PUT /api/v1/workflows/{id}/deployments/{environment}.
+//
+// Deploy a workflow version to an environment.
+func (c *Client) DeployWorkflow(ctx context.Context, id string, environment string, body *DeployRequest) (*Deployment, error) {
+	path := "/api/v1/workflows/" + url.PathEscape(id) + "/deployments/" + url.PathEscape(environment)
+	var out Deployment
+	if err := c.do(ctx, "PUT", path, nil, body, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// DiffWorkflowVersionsParams holds the query parameters of DiffWorkflowVersions
+type DiffWorkflowVersionsParams struct {
+	// Version to compare from
+	From int
+	// Version to compare to; defaults to the current version
+	To int
+}
+
+func (p *DiffWorkflowVersionsParams) values() url.Values {
+	query := url.Values{}
+	if p.From != 0 {
+		query.Set("from", strconv.Itoa(p.From))
+	}
+	if p.To != 0 {
+		query.Set("to", strconv.Itoa(p.To))
+	}
+	return query
+}
+
+// DiffWorkflowVersions calls GET /api/v1/workflows/{id}/diff.
+//
+// Compare two saved versions of a workflow.
+func (c *Client) DiffWorkflowVersions(ctx context.Context, id string, params *DiffWorkflowVersionsParams) (*VersionDiff, error) {
+	path := "/api/v1/workflows/" + url.PathEscape(id) + "/diff"
+	var query url.Values
+	if params != nil {
+		query = params.values()
+	}
+	var out VersionDiff
+	if err := c.do(ctx, "GET", path, query, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// DownloadBinaryData calls GET /api/v1/binary/{id}.
+//
+// Download binary data.
+func (c *Client) DownloadBinaryData(ctx context.Context, id string) ([]byte, error) {
+	path := "/api/v1/binary/" + url.PathEscape(id)
+	return c.doRaw(ctx, "GET", path, nil, nil)
+}
+
+// DrainWorker calls POST /api/v1/workers/{id}/drain.
+//
+// Stop a worker taking jobs and exit once in-flight jobs finish.
+func (c *Client) DrainWorker(ctx context.Context, id string) (*MessageResponse, error) {
+	path := "/api/v1/workers/" + url.PathEscape(id) + "/drain"
+	var out MessageResponse
+	if err := c.do(ctx, "POST", path, nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// DuplicateWorkflow calls POST /api/v1/workflows/{id}/duplicate.
+//
+// Copy a workflow with new node and edge IDs.
+func (c *Client) DuplicateWorkflow(ctx context.Context, id string, body *DuplicateRequest) (*Workflow, error) {
+	path := "/api/v1/workflows/" + url.PathEscape(id) + "/duplicate"
+	var out Workflow
+	if err := c.do(ctx, "POST", path, nil, body, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// EvaluateExpression calls POST /api/v1/expressions/evaluate.
+//
+// Preview an expression against a sample payload or a previous execution.
+func (c *Client) EvaluateExpression(ctx context.Context, body *EvaluateRequest) (*ExpressionResult, error) {
+	path := "/api/v1/expressions/evaluate"
+	var out ExpressionResult
+	if err := c.do(ctx, "POST", path, nil, body, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ExecuteWorkflowParams holds the query parameters of ExecuteWorkflow
+type ExecuteWorkflowParams struct {
+	// Run the version deployed to this environment
+	Environment string
+	// true to use the pinned data of nodes that have it instead of running them
+	PinnedData string
+}
+
+func (p *ExecuteWorkflowParams) values() url.Values {
+	query := url.Values{}
+	if p.Environment != "" {
+		query.Set("environment", p.Environment)
+	}
+	if p.PinnedData != "" {
+		query.Set("pinned_data", p.PinnedData)
+	}
+	return query
+}
+
+// ExecuteWorkflow calls POST /api/v1/workflows/{id}/execute.
+//
+// Queue a workflow execution for the workers, or run it inline when the server is configured to.
+func (c *Client) ExecuteWorkflow(ctx context.Context, id string, body map[string]interface{}, params *ExecuteWorkflowParams) (*Execution, error) {
+	path := "/api/v1/workflows/" + url.PathEscape(id) + "/execute"
+	var query url.Values
+	if params != nil {
+		query = params.values()
+	}
+	var out Execution
+	if err := c.do(ctx, "POST", path, query, body, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ExecuteWorkflowBatchParams holds the query parameters of ExecuteWorkflowBatch
+type ExecuteWorkflowBatchParams struct {
+	// Run the version deployed to this environment
+	Environment string
+	// true to use the pinned data of nodes that have it instead of running them
+	PinnedData string
+}
+
+func (p *ExecuteWorkflowBatchParams) values() url.Values {
+	query := url.Values{}
+	if p.Environment != "" {
+		query.Set("environment", p.Environment)
+	}
+	if p.PinnedData != "" {
+		query.Set("pinned_data", p.PinnedData)
+	}
+	return query
+}
+
+// ExecuteWorkflowBatch calls POST /api/v1/workflows/{id}/execute/batch.
+//
+// Queue one workflow execution per input, grouped in a batch.
+func (c *Client) ExecuteWorkflowBatch(ctx context.Context, id string, body *BatchRequest, params *ExecuteWorkflowBatchParams) (*BatchResponse, error) {
+	path := "/api/v1/workflows/" + url.PathEscape(id) + "/execute/batch"
+	var query url.Values
+	if params != nil {
+		query = params.values()
+	}
+	var out BatchResponse
+	if err := c.do(ctx, "POST", path, query, body, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ExtendExternalTaskLock calls POST /api/v1/external-tasks/{id}/extend-lock.
+//
+// Keep an external task locked for the worker for longer.
+func (c *Client) ExtendExternalTaskLock(ctx context.Context, id string, body *ExtendLockRequest) (*MessageResponse, error) {
+	path := "/api/v1/external-tasks/" + url.PathEscape(id) + "/extend-lock"
+	var out MessageResponse
+	if err := c.do(ctx, "POST", path, nil, body, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// FetchAndLockExternalTasks calls POST /api/v1/external-tasks/fetch-and-lock.
+//
+// Lock available external tasks of the topics for a worker, long-polling up to wait_ms.
+func (c *Client) FetchAndLockExternalTasks(ctx context.Context, body *FetchAndLockRequest) ([]ExternalTask, error) {
+	path := "/api/v1/external-tasks/fetch-and-lock"
+	var out []ExternalTask
+	if err := c.do(ctx, "POST", path, nil, body, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// GetAlertChannel calls GET /api/v1/alerts/channels/{id}.
+//
+// Get an alert channel.
+func (c *Client) GetAlertChannel(ctx context.Context, id string) (*AlertChannel, error) {
+	path := "/api/v1/alerts/channels/" + url.PathEscape(id)
+	var out AlertChannel
+	if err := c.do(ctx, "GET", path, nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetAlertRule calls GET /api/v1/alerts/rules/{id}.
+//
+// Get an alert rule.
+func (c *Client) GetAlertRule(ctx context.Context, id string) (*AlertRule, error) {
+	path := "/api/v1/alerts/rules/" + url.PathEscape(id)
+	var out AlertRule
+	if err := c.do(ctx, "GET", path, nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetDebugState calls GET /api/v1/executions/{id}/debug.
+//
+// Get the node a debug execution is paused at and its input.
+func (c *Client) GetDebugState(ctx context.Context, id string) (*DebugState, error) {
+	path := "/api/v1/executions/" + url.PathEscape(id) + "/debug"
+	var out DebugState
+	if err := c.do(ctx, "GET", path, nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetEventSubscription calls GET /api/v1/event-subscriptions/{id}.
+//
+// Get an event subscription.
+func (c *Client) GetEventSubscription(ctx context.Context, id string) (*EventSubscription, error) {
+	path := "/api/v1/event-subscriptions/" + url.PathEscape(id)
+	var out EventSubscription
+	if err := c.do(ctx, "GET", path, nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetExecution calls GET /api/v1/executions/{id}.
+//
+// Get an execution.
+func (c *Client) GetExecution(ctx context.Context, id string) (*Execution, error) {
+	path := "/api/v1/executions/" + url.PathEscape(id)
+	var out Execution
+	if err := c.do(ctx, "GET", path, nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetExecutionBatch calls GET /api/v1/batches/{id}.
+//
+// Get a batch of executions with their counts by status.
+func (c *Client) GetExecutionBatch(ctx context.Context, id string) (*ExecutionBatch, error) {
+	path := "/api/v1/batches/" + url.PathEscape(id)
+	var out ExecutionBatch
+	if err := c.do(ctx, "GET", path, nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetExecutionNodeOutput calls GET /api/v1/executions/{id}/outputs/{node}.
+//
+// Get a node's output with offloaded payloads loaded.
+func (c *Client) GetExecutionNodeOutput(ctx context.Context, id string, node string) (interface{}, error) {
+	path := "/api/v1/executions/" + url.PathEscape(id) + "/outputs/" + url.PathEscape(node)
+	var out interface{}
+	if err := c.do(ctx, "GET", path, nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// GetExternalTask calls GET /api/v1/external-tasks/{id}.
+//
+// Get an external task.
+func (c *Client) GetExternalTask(ctx context.Context, id string) (*ExternalTask, error) {
+	path := "/api/v1/external-tasks/" + url.PathEscape(id)
+	var out ExternalTask
+	if err := c.do(ctx, "GET", path, nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetLimits calls GET /api/v1/limits.
+//
+// Get the caller's tenant quotas and how much of them it uses.
+func (c *Client) GetLimits(ctx context.Context) (*Limits, error) {
+	path := "/api/v1/limits"
+	var out Limits
+	if err := c.do(ctx, "GET", path, nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetNodeContext calls GET /api/v1/executions/{id}/nodes/{node_id}/context.
+//
+// Get the input, variables, and upstream outputs a node ran with, secrets redacted.
+func (c *Client) GetNodeContext(ctx context.Context, id string, nodeID string) (*NodeContextResponse, error) {
+	path := "/api/v1/executions/" + url.PathEscape(id) + "/nodes/" + url.PathEscape(nodeID) + "/context"
+	var out NodeContextResponse
+	if err := c.do(ctx, "GET", path, nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetNodeSchema calls GET /api/v1/nodes/{type}/schema.
+//
+// Get the schema of a node type.
+func (c *Client) GetNodeSchema(ctx context.Context, typeName string) (interface{}, error) {
+	path := "/api/v1/nodes/" + url.PathEscape(typeName) + "/schema"
+	var out interface{}
+	if err := c.do(ctx, "GET", path, nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// GetProject calls GET /api/v1/projects/{id}.
+//
+// Get a project.
+func (c *Client) GetProject(ctx context.Context, id string) (*Project, error) {
+	path := "/api/v1/projects/" + url.PathEscape(id)
+	var out Project
+	if err := c.do(ctx, "GET", path, nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetQueueStats calls GET /api/v1/queue/stats.
+//
+// Get the queue backlog and worker load, for autoscaling workers.
+func (c *Client) GetQueueStats(ctx context.Context) (*QueueStats, error) {
+	path := "/api/v1/queue/stats"
+	var out QueueStats
+	if err := c.do(ctx, "GET", path, nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetSchedule calls GET /api/v1/schedules/{id}.
+//
+// Get a schedule.
+func (c *Client) GetSchedule(ctx context.Context, id string) (*Schedule, error) {
+	path := "/api/v1/schedules/" + url.PathEscape(id)
+	var out Schedule
+	if err := c.do(ctx, "GET", path, nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetUsageParams holds the query parameters of GetUsage
+type GetUsageParams struct {
+	// Start of the period, RFC 3339; the start of this month by default
+	From string
+	// End of the period, RFC 3339; now by default
+	To string
+	// tenant or workflow
+	GroupBy string
+}
+
+func (p *GetUsageParams) values() url.Values {
+	query := url.Values{}
+	if p.From != "" {
+		query.Set("from", p.From)
+	}
+	if p.To != "" {
+		query.Set("to", p.To)
+	}
+	if p.GroupBy != "" {
+		query.Set("group_by", p.GroupBy)
+	}
+	return query
+}
+
+// GetUsage calls GET /api/v1/usage.
+//
+// Total the resources used by finished executions, per tenant or workflow.
+func (c *Client) GetUsage(ctx context.Context, params *GetUsageParams) (*UsageReport, error) {
+	path := "/api/v1/usage"
+	var query url.Values
+	if params != nil {
+		query = params.values()
+	}
+	var out UsageReport
+	if err := c.do(ctx, "GET", path, query, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetVariable calls GET /api/v1/variables/{id}.
+//
+// Get a variable.
+func (c *Client) GetVariable(ctx context.Context, id string) (*Variable, error) {
+	path := "/api/v1/variables/" + url.PathEscape(id)
+	var out Variable
+	if err := c.do(ctx, "GET", path, nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetWorker calls GET /api/v1/workers/{id}.
+//
+// Get a worker.
+func (c *Client) GetWorker(ctx context.Context, id string) (*WorkerInfo, error) {
+	path := "/api/v1/workers/" + url.PathEscape(id)
+	var out WorkerInfo
+	if err := c.do(ctx, "GET", path, nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetWorkflow calls GET /api/v1/workflows/{id}.
+//
+// Get a workflow.
+func (c *Client) GetWorkflow(ctx context.Context, id string) (*Workflow, error) {
+	path := "/api/v1/workflows/" + url.PathEscape(id)
+	var out Workflow
+	if err := c.do(ctx, "GET", path, nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetWorkflowPlanParams holds the query parameters of GetWorkflowPlan
+type GetWorkflowPlanParams struct {
+	// Time window of the node durations the critical path is estimated from, such as 24h or 7d
+	Window string
+}
+
+func (p *GetWorkflowPlanParams) values() url.Values {
+	query := url.Values{}
+	if p.Window != "" {
+		query.Set("window", p.Window)
+	}
+	return query
+}
+
+// GetWorkflowPlan calls GET /api/v1/workflows/{id}/plan.
+//
+// Get the execution order, parallel groups, cycles, and estimated critical path of a workflow.
+func (c *Client) GetWorkflowPlan(ctx context.Context, id string, params *GetWorkflowPlanParams) (*ExecutionPlan, error) {
+	path := "/api/v1/workflows/" + url.PathEscape(id) + "/plan"
+	var query url.Values
+	if params != nil {
+		query = params.values()
+	}
+	var out ExecutionPlan
+	if err := c.do(ctx, "GET", path, query, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetWorkflowState calls GET /api/v1/workflows/{id}/state/{key}.
+//
+// Get a value the workflow keeps between executions.
+func (c *Client) GetWorkflowState(ctx context.Context, id string, key string) (*WorkflowState, error) {
+	path := "/api/v1/workflows/" + url.PathEscape(id) + "/state/" + url.PathEscape(key)
+	var out WorkflowState
+	if err := c.do(ctx, "GET", path, nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetWorkflowStatsParams holds the query parameters of GetWorkflowStats
+type GetWorkflowStatsParams struct {
+	// Time window such as 24h or 7d
+	Window string
+	// Bypass the cache
+	Refresh bool
+}
+
+func (p *GetWorkflowStatsParams) values() url.Values {
+	query := url.Values{}
+	if p.Window != "" {
+		query.Set("window", p.Window)
+	}
+	if p.Refresh {
+		query.Set("refresh", "true")
+	}
+	return query
+}
+
+// GetWorkflowStats calls GET /api/v1/workflows/{id}/stats.
+//
+// Get execution statistics for a workflow.
+func (c *Client) GetWorkflowStats(ctx context.Context, id string, params *GetWorkflowStatsParams) (*WorkflowStats, error) {
+	path := "/api/v1/workflows/" + url.PathEscape(id) + "/stats"
+	var query url.Values
+	if params != nil {
+		query = params.values()
+	}
+	var out WorkflowStats
+	if err := c.do(ctx, "GET", path, query, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetWorkflowVersion calls GET /api/v1/workflows/{id}/versions/{version}.
+//
+// Get a saved workflow version.
+func (c *Client) GetWorkflowVersion(ctx context.Context, id string, version string) (*WorkflowVersion, error) {
+	path := "/api/v1/workflows/" + url.PathEscape(id) + "/versions/" + url.PathEscape(version)
+	var out WorkflowVersion
+	if err := c.do(ctx, "GET", path, nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// LintWorkflowParams holds the query parameters of LintWorkflow
+type LintWorkflowParams struct {
+	// Lint the workflow as it would run in this environment instead of the server's
+	Environment string
+}
+
+func (p *LintWorkflowParams) values() url.Values {
+	query := url.Values{}
+	if p.Environment != "" {
+		query.Set("environment", p.Environment)
+	}
+	return query
+}
+
+// LintWorkflow calls POST /api/v1/workflows/{id}/lint.
+//
+// Check a workflow against the lint rules and report issues with severities and fix hints.
+func (c *Client) LintWorkflow(ctx context.Context, id string, params *LintWorkflowParams) (*LintResponse, error) {
+	path := "/api/v1/workflows/" + url.PathEscape(id) + "/lint"
+	var query url.Values
+	if params != nil {
+		query = params.values()
+	}
+	var out LintResponse
+	if err := c.do(ctx, "POST", path, query, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ListAPIKeys calls GET /api/v1/api-keys.
+//
+// List the tenant's API keys.
+func (c *Client) ListAPIKeys(ctx context.Context) ([]APIKey, error) {
+	path := "/api/v1/api-keys"
+	var out []APIKey
+	if err := c.do(ctx, "GET", path, nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ListAlertChannels calls GET /api/v1/alerts/channels.
+//
+// List alert channels.
+func (c *Client) ListAlertChannels(ctx context.Context) ([]AlertChannel, error) {
+	path := "/api/v1/alerts/channels"
+	var out []AlertChannel
+	if err := c.do(ctx, "GET", path, nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ListAlertRules calls GET /api/v1/alerts/rules.
+//
+// List alert rules.
+func (c *Client) ListAlertRules(ctx context.Context) ([]AlertRule, error) {
+	path := "/api/v1/alerts/rules"
+	var out []AlertRule
+	if err := c.do(ctx, "GET", path, nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ListAlertsParams holds the query parameters of ListAlerts
+type ListAlertsParams struct {
+	// Only alerts of this rule
+	RuleID string
+	// Maximum number of alerts, up to 500; 100 when omitted
+	Limit int
+}
+
+func (p *ListAlertsParams) values() url.Values {
+	query := url.Values{}
+	if p.RuleID != "" {
+		query.Set("rule_id", p.RuleID)
+	}
+	if p.Limit != 0 {
+		query.Set("limit", strconv.Itoa(p.Limit))
+	}
+	return query
+}
+
+// ListAlerts calls GET /api/v1/alerts.
+//
+// List fired alerts, most recent first.
+func (c *Client) ListAlerts(ctx context.Context, params *ListAlertsParams) ([]Alert, error) {
+	path := "/api/v1/alerts"
+	var query url.Values
+	if params != nil {
+		query = params.values()
+	}
+	var out []Alert
+	if err := c.do(ctx, "GET", path, query, nil, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ListCredentials calls GET /api/v1/credentials.
+//
+// List credentials without their secrets.
+func (c *Client) ListCredentials(ctx context.Context) ([]Credential, error) {
+	path := "/api/v1/credentials"
+	var out []Credential
+	if err := c.do(ctx, "GET", path, nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ListDeployments calls GET /api/v1/workflows/{id}/deployments.
+//
+// List the environments a workflow is deployed to.
+func (c *Client) ListDeployments(ctx context.Context, id string) ([]Deployment, error) {
+	path := "/api/v1/workflows/" + url.PathEscape(id) + "/deployments"
+	var out []Deployment
+	if err := c.do(ctx, "GET", path, nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ListDeprecatedNodeUsages calls GET /api/v1/nodes/deprecations.
+//
+// List workflow nodes that run a deprecated node version.
+func (c *Client) ListDeprecatedNodeUsages(ctx context.Context) ([]DeprecatedNodeUsage, error) {
+	path := "/api/v1/nodes/deprecations"
+	var out []DeprecatedNodeUsage
+	if err := c.do(ctx, "GET", path, nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ListEnvironments calls GET /api/v1/environments.
+//
+// List environments.
+func (c *Client) ListEnvironments(ctx context.Context) ([]Environment, error) {
+	path := "/api/v1/environments"
+	var out []Environment
+	if err := c.do(ctx, "GET", path, nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ListEventDeliveriesParams holds the query parameters of ListEventDeliveries
+type ListEventDeliveriesParams struct {
+	// Maximum number of deliveries, up to 500; 50 when omitted
+	Limit int
+}
+
+func (p *ListEventDeliveriesParams) values() url.Values {
+	query := url.Values{}
+	if p.Limit != 0 {
+		query.Set("limit", strconv.Itoa(p.Limit))
+	}
+	return query
+}
+
+// ListEventDeliveries calls GET /api/v1/event-subscriptions/{id}/deliveries.
+//
+// List a subscription's most recent deliveries.
+func (c *Client) ListEventDeliveries(ctx context.Context, id string, params *ListEventDeliveriesParams) ([]EventDelivery, error) {
+	path := "/api/v1/event-subscriptions/" + url.PathEscape(id) + "/deliveries"
+	var query url.Values
+	if params != nil {
+		query = params.values()
+	}
+	var out []EventDelivery
+	if err := c.do(ctx, "GET", path, query, nil, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ListEventSubscriptions calls GET /api/v1/event-subscriptions.
+//
+// List event subscriptions without their secrets.
+func (c *Client) ListEventSubscriptions(ctx context.Context) ([]EventSubscription, error) {
+	path := "/api/v1/event-subscriptions"
+	var out []EventSubscription
+	if err := c.do(ctx, "GET", path, nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ListExecutionsParams holds the query parameters of ListExecutions
+type ListExecutionsParams struct {
+	// Only executions of this workflow
+	WorkflowID string
+	// Only executions with these statuses
+	Status []string
+	// Only executions started at or after this time
+	StartedAfter time.Time
+	// Only executions started before this time
+	StartedBefore time.Time
+	// X-Next-Cursor of the previous page
+	Cursor string
+	// Maximum number of executions, up to 1000
+	Limit int
+	// summary omits input, output, and context
+	Fields string
+}
+
+func (p *ListExecutionsParams) values() url.Values {
+	query := url.Values{}
+	if p.WorkflowID != "" {
+		query.Set("workflow_id", p.WorkflowID)
+	}
+	for _, v := range p.Status {
+		query.Add("status", v)
+	}
+	if !p.StartedAfter.IsZero() {
+		query.Set("started_after", p.StartedAfter.Format(time.RFC3339))
+	}
+	if !p.StartedBefore.IsZero() {
+		query.Set("started_before", p.StartedBefore.Format(time.RFC3339))
+	}
+	if p.Cursor != "" {
+		query.Set("cursor", p.Cursor)
+	}
+	if p.Limit != 0 {
+		query.Set("limit", strconv.Itoa(p.Limit))
+	}
+	if p.Fields != "" {
+		query.Set("fields", p.Fields)
+	}
+	return query
+}
+
+// ListExecutions calls GET /api/v1/executions.
+//
+// List executions, newest first.
+func (c *Client) ListExecutions(ctx context.Context, params *ListExecutionsParams) ([]Execution, error) {
+	path := "/api/v1/executions"
+	var query url.Values
+	if params != nil {
+		query = params.values()
+	}
+	var out []Execution
+	if err := c.do(ctx, "GET", path, query, nil, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ListNodes calls GET /api/v1/nodes.
+//
+// List available node types.
+func (c *Client) ListNodes(ctx context.Context) (*NodeListResponse, error) {
+	path := "/api/v1/nodes"
+	var out NodeListResponse
+	if err := c.do(ctx, "GET", path, nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ListPlans calls GET /api/v1/plans.
+//
+// List quota plans.
+func (c *Client) ListPlans(ctx context.Context) ([]Plan, error) {
+	path := "/api/v1/plans"
+	var out []Plan
+	if err := c.do(ctx, "GET", path, nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ListProjectsParams holds the query parameters of ListProjects
+type ListProjectsParams struct {
+	// Only sub-projects of this project
+	ParentID string
+}
+
+func (p *ListProjectsParams) values() url.Values {
+	query := url.Values{}
+	if p.ParentID != "" {
+		query.Set("parent_id", p.ParentID)
+	}
+	return query
+}
+
+// ListProjects calls GET /api/v1/projects.
+//
+// List projects.
+func (c *Client) ListProjects(ctx context.Context, params *ListProjectsParams) ([]Project, error) {
+	path := "/api/v1/projects"
+	var query url.Values
+	if params != nil {
+		query = params.values()
+	}
+	var out []Project
+	if err := c.do(ctx, "GET", path, query, nil, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ListSchedulesParams holds the query parameters of ListSchedules
+type ListSchedulesParams struct {
+	// Only the schedules of this workflow
+	WorkflowID string
+}
+
+func (p *ListSchedulesParams) values() url.Values {
+	query := url.Values{}
+	if p.WorkflowID != "" {
+		query.Set("workflow_id", p.WorkflowID)
+	}
+	return query
+}
+
+// ListSchedules calls GET /api/v1/schedules.
+//
+// List cron schedules.
+func (c *Client) ListSchedules(ctx context.Context, params *ListSchedulesParams) ([]Schedule, error) {
+	path := "/api/v1/schedules"
+	var query url.Values
+	if params != nil {
+		query = params.values()
+	}
+	var out []Schedule
+	if err := c.do(ctx, "GET", path, query, nil, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ListTenants calls GET /api/v1/tenants.
+//
+// List tenants.
+func (c *Client) ListTenants(ctx context.Context) ([]Tenant, error) {
+	path := "/api/v1/tenants"
+	var out []Tenant
+	if err := c.do(ctx, "GET", path, nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ListVariablesParams holds the query parameters of ListVariables
+type ListVariablesParams struct {
+	// Only variables of this environment; empty for global variables
+	Environment string
+}
+
+func (p *ListVariablesParams) values() url.Values {
+	query := url.Values{}
+	if p.Environment != "" {
+		query.Set("environment", p.Environment)
+	}
+	return query
+}
+
+// ListVariables calls GET /api/v1/variables.
+//
+// List variables without secret values.
+func (c *Client) ListVariables(ctx context.Context, params *ListVariablesParams) ([]Variable, error) {
+	path := "/api/v1/variables"
+	var query url.Values
+	if params != nil {
+		query = params.values()
+	}
+	var out []Variable
+	if err := c.do(ctx, "GET", path, query, nil, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ListWorkers calls GET /api/v1/workers.
+//
+// List live workers with their capabilities and load.
+func (c *Client) ListWorkers(ctx context.Context) ([]WorkerInfo, error) {
+	path := "/api/v1/workers"
+	var out []WorkerInfo
+	if err := c.do(ctx, "GET", path, nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ListWorkflowSchedules calls GET /api/v1/workflows/{id}/schedules.
+//
+// List a workflow's cron schedules.
+func (c *Client) ListWorkflowSchedules(ctx context.Context, id string) ([]Schedule, error) {
+	path := "/api/v1/workflows/" + url.PathEscape(id) + "/schedules"
+	var out []Schedule
+	if err := c.do(ctx, "GET", path, nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ListWorkflowVersions calls GET /api/v1/workflows/{id}/versions.
+//
+// List a workflow's saved versions, newest first.
+func (c *Client) ListWorkflowVersions(ctx context.Context, id string) ([]WorkflowVersion, error) {
+	path := "/api/v1/workflows/" + url.PathEscape(id) + "/versions"
+	var out []WorkflowVersion
+	if err := c.do(ctx, "GET", path, nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ListWorkflowsParams holds the query parameters of ListWorkflows
+type ListWorkflowsParams struct {
+	// Maximum number of workflows, up to 1000
+	Limit int
+	// Number of workflows to skip
+	Offset int
+	// Case-insensitive search in the name
+	Q string
+	// Only workflows with all these tags
+	Tag []string
+	// Sort field: name, created_at, or updated_at
+	Sort string
+	// Sort order: asc or desc
+	Order string
+	// summary omits definitions
+	Fields string
+	// Only workflows in this project
+	ProjectID string
+	// Only workflows with these statuses; draft and active by default
+	Status []string
+}
+
+func (p *ListWorkflowsParams) values() url.Values {
+	query := url.Values{}
+	if p.Limit != 0 {
+		query.Set("limit", strconv.Itoa(p.Limit))
+	}
+	if p.Offset != 0 {
+		query.Set("offset", strconv.Itoa(p.Offset))
+	}
+	if p.Q != "" {
+		query.Set("q", p.Q)
+	}
+	for _, v := range p.Tag {
+		query.Add("tag", v)
+	}
+	if p.Sort != "" {
+		query.Set("sort", p.Sort)
+	}
+	if p.Order != "" {
+		query.Set("order", p.Order)
+	}
+	if p.Fields != "" {
+		query.Set("fields", p.Fields)
+	}
+	if p.ProjectID != "" {
+		query.Set("project_id", p.ProjectID)
+	}
+	for _, v := range p.Status {
+		query.Add("status", v)
+	}
+	return query
+}
+
+// ListWorkflows calls GET /api/v1/workflows.
+//
+// List workflows.
+func (c *Client) ListWorkflows(ctx context.Context, params *ListWorkflowsParams) ([]Workflow, error) {
+	path := "/api/v1/workflows"
+	var query url.Values
+	if params != nil {
+		query = params.values()
+	}
+	var out []Workflow
+	if err := c.do(ctx, "GET", path, query, nil, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// PauseSchedule calls POST /api/v1/schedules/{id}/pause.
+//
+// Pause a schedule.
+func (c *Client) PauseSchedule(ctx context.Context, id string) (*Schedule, error) {
+	path := "/api/v1/schedules/" + url.PathEscape(id) + "/pause"
+	var out Schedule
+	if err := c.do(ctx, "POST", path, nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// PreviewScheduleParams holds the query parameters of PreviewSchedule
+type PreviewScheduleParams struct {
+	// Cron expression, such as */15 9-17 * * MON-FRI or @daily
+	CronExpr string
+	// IANA timezone the expression fires in; UTC when omitted
+	Timezone string
+	// Number of times, up to 100; 5 when omitted
+	Count int
+}
+
+func (p *PreviewScheduleParams) values() url.Values {
+	query := url.Values{}
+	if p.CronExpr != "" {
+		query.Set("cron_expr", p.CronExpr)
+	}
+	if p.Timezone != "" {
+		query.Set("timezone", p.Timezone)
+	}
+	if p.Count != 0 {
+		query.Set("count", strconv.Itoa(p.Count))
+	}
+	return query
+}
+
+// PreviewSchedule calls GET /api/v1/schedules/preview.
+//
+// List the next times a cron expression fires.
+func (c *Client) PreviewSchedule(ctx context.Context, params *PreviewScheduleParams) (*SchedulePreview, error) {
+	path := "/api/v1/schedules/preview"
+	var query url.Values
+	if params != nil {
+		query = params.values()
+	}
+	var out SchedulePreview
+	if err := c.do(ctx, "GET", path, query, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// PromoteWorkflow calls POST /api/v1/workflows/{id}/promote.
+//
+// Promote a workflow version from one environment to another.
+func (c *Client) PromoteWorkflow(ctx context.Context, id string, body *PromoteRequest) (*PromoteResponse, error) {
+	path := "/api/v1/workflows/" + url.PathEscape(id) + "/promote"
+	var out PromoteResponse
+	if err := c.do(ctx, "POST", path, nil, body, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// RedeliverEvent calls POST /api/v1/event-subscriptions/{id}/deliveries/{delivery}/redeliver.
+//
+// Send a delivery's payload again as a new delivery.
+func (c *Client) RedeliverEvent(ctx context.Context, id string, delivery string) (*EventDelivery, error) {
+	path := "/api/v1/event-subscriptions/" + url.PathEscape(id) + "/deliveries/" + url.PathEscape(delivery) + "/redeliver"
+	var out EventDelivery
+	if err := c.do(ctx, "POST", path, nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ReportExternalTaskFailure calls POST /api/v1/external-tasks/{id}/failure.
+//
+// Report a failed attempt at an external task, retrying it while retries are left.
+func (c *Client) ReportExternalTaskFailure(ctx context.Context, id string, body *FailTaskRequest) (*MessageResponse, error) {
+	path := "/api/v1/external-tasks/" + url.PathEscape(id) + "/failure"
+	var out MessageResponse
+	if err := c.do(ctx, "POST", path, nil, body, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ResumeSchedule calls POST /api/v1/schedules/{id}/resume.
+//
+// Resume a schedule from its next time, skipping the runs missed while paused.
+func (c *Client) ResumeSchedule(ctx context.Context, id string) (*Schedule, error) {
+	path := "/api/v1/schedules/" + url.PathEscape(id) + "/resume"
+	var out Schedule
+	if err := c.do(ctx, "POST", path, nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// RevokeAPIKey calls DELETE /api/v1/api-keys/{id}.
+//
+// Revoke an API key.
+func (c *Client) RevokeAPIKey(ctx context.Context, id string) (*MessageResponse, error) {
+	path := "/api/v1/api-keys/" + url.PathEscape(id)
+	var out MessageResponse
+	if err := c.do(ctx, "DELETE", path, nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// RunScheduleNow calls POST /api/v1/schedules/{id}/run-now.
+//
+// Queue an execution of a schedule's workflow with its input now.
+func (c *Client) RunScheduleNow(ctx context.Context, id string) (*Execution, error) {
+	path := "/api/v1/schedules/" + url.PathEscape(id) + "/run-now"
+	var out Execution
+	if err := c.do(ctx, "POST", path, nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// SavePlan calls PUT /api/v1/plans/{name}.
+//
+// Create or replace a quota plan.
+func (c *Client) SavePlan(ctx context.Context, name string, body *PlanRequest) (*Plan, error) {
+	path := "/api/v1/plans/" + url.PathEscape(name)
+	var out Plan
+	if err := c.do(ctx, "PUT", path, nil, body, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// SendDebugCommand calls POST /api/v1/executions/{id}/debug.
+//
+// Continue, skip, or modify the input of the paused node.
+func (c *Client) SendDebugCommand(ctx context.Context, id string, body *DebugCommand) (*MessageResponse, error) {
+	path := "/api/v1/executions/" + url.PathEscape(id) + "/debug"
+	var out MessageResponse
+	if err := c.do(ctx, "POST", path, nil, body, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// SetPinnedData calls PUT /api/v1/workflows/{id}/nodes/{node}/pinned-data.
+//
+// Pin sample output data to a node.
+func (c *Client) SetPinnedData(ctx context.Context, id string, node string, body *PinnedDataRequest) (*Workflow, error) {
+	path := "/api/v1/workflows/" + url.PathEscape(id) + "/nodes/" + url.PathEscape(node) + "/pinned-data"
+	var out Workflow
+	if err := c.do(ctx, "PUT", path, nil, body, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// SetWorkflowState calls PUT /api/v1/workflows/{id}/state/{key}.
+//
+// Set a value the workflow keeps between executions.
+func (c *Client) SetWorkflowState(ctx context.Context, id string, key string, body *StateRequest) (*WorkflowState, error) {
+	path := "/api/v1/workflows/" + url.PathEscape(id) + "/state/" + url.PathEscape(key)
+	var out WorkflowState
+	if err := c.do(ctx, "PUT", path, nil, body, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// StopWorker calls POST /api/v1/workers/{id}/stop.
+//
+// Cancel a worker's in-flight jobs and exit.
+func (c *Client) StopWorker(ctx context.Context, id string) (*MessageResponse, error) {
+	path := "/api/v1/workers/" + url.PathEscape(id) + "/stop"
+	var out MessageResponse
+	if err := c.do(ctx, "POST", path, nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// StreamExecutionEventsParams holds the query parameters of StreamExecutionEvents
+type StreamExecutionEventsParams struct {
+	// Resume after this event, for clients that cannot set the Last-Event-ID header
+	LastEventID string
+}
+
+func (p *StreamExecutionEventsParams) values() url.Values {
+	query := url.Values{}
+	if p.LastEventID != "" {
+		query.Set("last_event_id", p.LastEventID)
+	}
+	return query
+}
+
+// StreamExecutionEvents calls GET /api/v1/executions/{id}/events.
+//
+// Stream an execution's events as Server-Sent Events, resuming after Last-Event-ID.
+func (c *Client) StreamExecutionEvents(ctx context.Context, id string, params *StreamExecutionEventsParams) ([]byte, error) {
+	path := "/api/v1/executions/" + url.PathEscape(id) + "/events"
+	var query url.Values
+	if params != nil {
+		query = params.values()
+	}
+	return c.doRaw(ctx, "GET", path, query, nil)
+}
+
+// SyncWorkflows calls POST /api/v1/workflows/sync.
+//
+// Create, update, and deactivate a source's workflows to match the given definitions.
+func (c *Client) SyncWorkflows(ctx context.Context, body *SyncRequest) (*SyncResponse, error) {
+	path := "/api/v1/workflows/sync"
+	var out SyncResponse
+	if err := c.do(ctx, "POST", path, nil, body, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// UndeployWorkflow calls DELETE /api/v1/workflows/{id}/deployments/{environment}.
+//
+// Remove a workflow from an environment.
+func (c *Client) UndeployWorkflow(ctx context.Context, id string, environment string) (*MessageResponse, error) {
+	path := "/api/v1/workflows/" + url.PathEscape(id) + "/deployments/" + url.PathEscape(environment)
+	var out MessageResponse
+	if err := c.do(ctx, "DELETE", path, nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// UpdateAlertChannel calls PUT /api/v1/alerts/channels/{id}.
+//
+// Update an alert channel.
+func (c *Client) UpdateAlertChannel(ctx context.Context, id string, body *AlertChannelRequest) (*AlertChannel, error) {
+	path := "/api/v1/alerts/channels/" + url.PathEscape(id)
+	var out AlertChannel
+	if err := c.do(ctx, "PUT", path, nil, body, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// UpdateAlertRule calls PUT /api/v1/alerts/rules/{id}.
+//
+// Update an alert rule.
+func (c *Client) UpdateAlertRule(ctx context.Context, id string, body *AlertRuleRequest) (*AlertRule, error) {
+	path := "/api/v1/alerts/rules/" + url.PathEscape(id)
+	var out AlertRule
+	if err := c.do(ctx, "PUT", path, nil, body, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// UpdateEventSubscription calls PUT /api/v1/event-subscriptions/{id}.
+//
+// Update an event subscription.
+func (c *Client) UpdateEventSubscription(ctx context.Context, id string, body *EventSubscriptionRequest) (*EventSubscription, error) {
+	path := "/api/v1/event-subscriptions/" + url.PathEscape(id)
+	var out EventSubscription
+	if err := c.do(ctx, "PUT", path, nil, body, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// UpdateProject calls PUT /api/v1/projects/{id}.
+//
+// Update a project.
+func (c *Client) UpdateProject(ctx context.Context, id string, body *Project) (*Project, error) {
+	path := "/api/v1/projects/" + url.PathEscape(id)
+	var out Project
+	if err := c.do(ctx, "PUT", path, nil, body, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// UpdateSchedule calls PUT /api/v1/schedules/{id}.
+//
+// Update a schedule.
+func (c *Client) UpdateSchedule(ctx context.Context, id string, body *ScheduleRequest) (*Schedule, error) {
+	path := "/api/v1/schedules/" + url.PathEscape(id)
+	var out Schedule
+	if err := c.do(ctx, "PUT", path, nil, body, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// UpdateTenantNetworkPolicy calls PUT /api/v1/tenants/{id}/network-policy.
+//
+// Replace the networks a tenant's nodes and webhooks may reach.
+func (c *Client) UpdateTenantNetworkPolicy(ctx context.Context, id string, body *NetworkPolicy) (*NetworkPolicy, error) {
+	path := "/api/v1/tenants/" + url.PathEscape(id) + "/network-policy"
+	var out NetworkPolicy
+	if err := c.do(ctx, "PUT", path, nil, body, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// UpdateTenantQuotas calls PUT /api/v1/tenants/{id}/quotas.
+//
+// Put a tenant on a plan and override the plan's quotas.
+func (c *Client) UpdateTenantQuotas(ctx context.Context, id string, body *TenantQuotas) (*TenantQuotas, error) {
+	path := "/api/v1/tenants/" + url.PathEscape(id) + "/quotas"
+	var out TenantQuotas
+	if err := c.do(ctx, "PUT", path, nil, body, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// UpdateVariable calls PUT /api/v1/variables/{id}.
+//
+// Update a variable.
+func (c *Client) UpdateVariable(ctx context.Context, id string, body *VariableRequest) (*Variable, error) {
+	path := "/api/v1/variables/" + url.PathEscape(id)
+	var out Variable
+	if err := c.do(ctx, "PUT", path, nil, body, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// UpdateWorkflow calls PUT /api/v1/workflows/{id}.
+//
+// Update a workflow.
+func (c *Client) UpdateWorkflow(ctx context.Context, id string, body *Workflow) (*Workflow, error) {
+	path := "/api/v1/workflows/" + url.PathEscape(id)
+	var out Workflow
+	if err := c.do(ctx, "PUT", path, nil, body, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ValidateNodeConfig calls POST /api/v1/nodes/{type}/validate.
+//
+// Validate a node config and report field-level errors.
+func (c *Client) ValidateNodeConfig(ctx context.Context, typeName string, body map[string]interface{}) (*ValidationResponse, error) {
+	path := "/api/v1/nodes/" + url.PathEscape(typeName) + "/validate"
+	var out ValidationResponse
+	if err := c.do(ctx, "POST", path, nil, body, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
@@ -0,0 +1,157 @@
+package client
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// followRetryDelay is how long FollowExecution waits before reconnecting
+// to the event stream, or polling the execution after its final event
+const followRetryDelay = time.Second
+
+// ExecutionEvent is an event of an execution's stream
+type ExecutionEvent struct {
+	ID          string      `json:"-"` // stream ID, empty for servers without Redis
+	Type        string      `json:"type"`
+	WorkflowID  string      `json:"workflow_id"`
+	ExecutionID string      `json:"execution_id"`
+	NodeID      string      `json:"node_id,omitempty"`
+	Input       interface{} `json:"input,omitempty"`
+	Output      interface{} `json:"output,omitempty"`
+	Error       string      `json:"error,omitempty"`
+	Time        time.Time   `json:"time"`
+}
+
+// Final reports whether the event ends its execution
+func (e *ExecutionEvent) Final() bool {
+	return e.Type == "execution.completed" || e.Type == "execution.failed"
+}
+
+// ExecutionFinished reports whether an execution with status has stopped
+// running
+func ExecutionFinished(status string) bool {
+	return status == "completed" || status == "failed" || status == "cancelled"
+}
+
+// FollowExecution calls fn with each event of the execution as it happens
+// and returns the execution once it has finished. A dropped stream is
+// resumed after the last event received. An error from fn stops following
+// and is returned.
+func (c *Client) FollowExecution(ctx context.Context, id string, fn func(ExecutionEvent) error) (*Execution, error) {
+	lastID, final := "", false
+	for {
+		if !final {
+			err := c.streamEvents(ctx, id, lastID, func(event ExecutionEvent) error {
+				if event.ID != "" {
+					lastID = event.ID
+				}
+				final = final || event.Final()
+				return fn(event)
+			})
+			if err != nil && !errors.Is(err, errStreamDropped) {
+				return nil, err
+			}
+		}
+
+		// The final event is published just before the execution is saved
+		execution, err := c.GetExecution(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if ExecutionFinished(execution.Status) {
+			return execution, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(followRetryDelay):
+		}
+	}
+}
+
+// errStreamDropped is returned by streamEvents when the connection fails
+// mid-stream, so following can resume
+var errStreamDropped = errors.New("event stream dropped")
+
+// streamEvents reads an execution's Server-Sent Events after lastID until
+// the server ends the stream
+func (c *Client) streamEvents(ctx context.Context, id, lastID string, fn func(ExecutionEvent) error) error {
+	var query url.Values
+	if lastID != "" {
+		query = url.Values{"last_event_id": {lastID}}
+	}
+	resp, err := c.send(ctx, "GET", "/api/v1/executions/"+url.PathEscape(id)+"/events", query, nil)
+	if err != nil {
+		var apiErr *Error
+		if errors.As(err, &apiErr) || ctx.Err() != nil {
+			return err
+		}
+		return fmt.Errorf("%w: %v", errStreamDropped, err)
+	}
+	defer resp.Body.Close()
+
+	reader := bufio.NewReader(resp.Body)
+	var eventID, eventType string
+	var data strings.Builder
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			if err == io.EOF && line == "" {
+				return nil
+			}
+			return fmt.Errorf("%w: %v", errStreamDropped, err)
+		}
+		line = strings.TrimRight(line, "\r\n")
+
+		field, value, _ := strings.Cut(line, ":")
+		value = strings.TrimPrefix(value, " ")
+		switch {
+		case line == "":
+			// A blank line dispatches the event read so far
+			if data.Len() > 0 {
+				if err := dispatchEvent(eventID, eventType, data.String(), fn); err != nil {
+					return err
+				}
+			}
+			eventID, eventType = "", ""
+			data.Reset()
+		case field == "":
+			// A comment, such as a keep-alive
+		case field == "id":
+			eventID = value
+		case field == "event":
+			eventType = value
+		case field == "data":
+			if data.Len() > 0 {
+				data.WriteByte('\n')
+			}
+			data.WriteString(value)
+		}
+	}
+}
+
+func dispatchEvent(id, eventType, data string, fn func(ExecutionEvent) error) error {
+	if eventType == "error" {
+		message, err := strconv.Unquote(data)
+		if err != nil {
+			message = data
+		}
+		return fmt.Errorf("event stream failed: %s", message)
+	}
+
+	var event ExecutionEvent
+	if err := json.Unmarshal([]byte(data), &event); err != nil {
+		return fmt.Errorf("failed to decode event: %w", err)
+	}
+	event.ID = id
+	return fn(event)
+}
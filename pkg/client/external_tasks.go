@@ -0,0 +1,127 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// externalTaskLongPoll is how long a fetch waits on the server for tasks
+const externalTaskLongPoll = 20 * time.Second
+
+// ExternalTaskHandler does the work of an external task and returns the
+// result the waiting node outputs. An error fails the attempt.
+type ExternalTaskHandler func(ctx context.Context, task ExternalTask) (map[string]interface{}, error)
+
+// ExternalTaskWorker runs the external tasks of its topics in this process:
+// it long-polls the server for tasks, locks them, runs each through
+// Handler, and completes or fails it. Locks are extended while Handler
+// runs.
+type ExternalTaskWorker struct {
+	Client  *Client
+	Topics  []string
+	Handler ExternalTaskHandler
+
+	ID           string        // names the worker's locks; hostname with a random suffix when empty
+	MaxTasks     int           // tasks run at once; 1 when zero
+	LockDuration time.Duration // how long fetched tasks stay locked; one minute when zero
+	Retries      int           // attempts after a failed one before the task fails for good
+	RetryTimeout time.Duration // delay before a failed task is fetched again
+}
+
+// Run fetches and runs tasks until ctx is cancelled, then waits for the
+// tasks in hand and returns ctx's error
+func (w *ExternalTaskWorker) Run(ctx context.Context) error {
+	if w.Client == nil || w.Handler == nil || len(w.Topics) == 0 {
+		return fmt.Errorf("external task worker needs a client, a handler, and topics")
+	}
+	if w.ID == "" {
+		hostname, _ := os.Hostname()
+		w.ID = fmt.Sprintf("%s-%s", hostname, uuid.New().String()[:8])
+	}
+	if w.MaxTasks <= 0 {
+		w.MaxTasks = 1
+	}
+	if w.LockDuration <= 0 {
+		w.LockDuration = time.Minute
+	}
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		tasks, err := w.Client.FetchAndLockExternalTasks(ctx, &FetchAndLockRequest{
+			WorkerID:       w.ID,
+			Topics:         w.Topics,
+			MaxTasks:       w.MaxTasks,
+			LockDurationMs: int(w.LockDuration.Milliseconds()),
+			WaitMs:         int(externalTaskLongPoll.Milliseconds()),
+		})
+		if err != nil {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(followRetryDelay):
+			}
+			continue
+		}
+
+		var running sync.WaitGroup
+		for _, task := range tasks {
+			running.Add(1)
+			go func(task ExternalTask) {
+				defer running.Done()
+				w.handle(ctx, task)
+			}(task)
+		}
+		running.Wait()
+	}
+}
+
+// handle runs one locked task and reports its outcome
+func (w *ExternalTaskWorker) handle(ctx context.Context, task ExternalTask) {
+	id := task.ID.String()
+	handlerCtx, stop := context.WithCancel(ctx)
+	defer stop()
+	go w.extendLock(handlerCtx, id)
+
+	result, err := w.Handler(handlerCtx, task)
+	stop()
+
+	// Report even when ctx is done, so the task is not left locked
+	reportCtx := context.WithoutCancel(ctx)
+	if err == nil {
+		w.Client.CompleteExternalTask(reportCtx, id, &CompleteTaskRequest{WorkerID: w.ID, Result: result})
+		return
+	}
+	failure := &FailTaskRequest{WorkerID: w.ID, ErrorMessage: err.Error()}
+	if remaining := w.Retries - task.Attempts + 1; remaining > 0 {
+		failure.Retries = remaining
+		failure.RetryTimeoutMs = int(w.RetryTimeout.Milliseconds())
+	}
+	w.Client.ReportExternalTaskFailure(reportCtx, id, failure)
+}
+
+// extendLock renews the lock on a task at half its duration until ctx is
+// done
+func (w *ExternalTaskWorker) extendLock(ctx context.Context, id string) {
+	ticker := time.NewTicker(w.LockDuration / 2)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			w.Client.ExtendExternalTaskLock(ctx, id, &ExtendLockRequest{
+				WorkerID:       w.ID,
+				LockDurationMs: int(w.LockDuration.Milliseconds()),
+			})
+		}
+	}
+}
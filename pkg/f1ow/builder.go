@@ -0,0 +1,123 @@
+package f1ow
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// WorkflowBuilder assembles a workflow definition node by node
+type WorkflowBuilder struct {
+	workflow Workflow
+	nodeIDs  map[string]bool
+	errs     []error
+}
+
+// NewWorkflow starts building a workflow
+func NewWorkflow(name string) *WorkflowBuilder {
+	return &WorkflowBuilder{
+		workflow: Workflow{
+			ID:       uuid.New(),
+			Name:     name,
+			IsActive: true,
+			Status:   WorkflowStatusActive,
+			Version:  1,
+			Definition: WorkflowDefinition{
+				Variables: map[string]interface{}{},
+			},
+		},
+		nodeIDs: make(map[string]bool),
+	}
+}
+
+// Description sets the workflow description
+func (b *WorkflowBuilder) Description(description string) *WorkflowBuilder {
+	b.workflow.Description = description
+	return b
+}
+
+// Tags sets the workflow tags
+func (b *WorkflowBuilder) Tags(tags ...string) *WorkflowBuilder {
+	b.workflow.Tags = tags
+	return b
+}
+
+// Node adds a node of the given type. The first node added is the start node.
+func (b *WorkflowBuilder) Node(id, nodeType string, config map[string]interface{}) *WorkflowBuilder {
+	if id == "" {
+		b.errs = append(b.errs, fmt.Errorf("node of type %s has no ID", nodeType))
+		return b
+	}
+	if b.nodeIDs[id] {
+		b.errs = append(b.errs, fmt.Errorf("duplicate node ID %s", id))
+		return b
+	}
+	b.nodeIDs[id] = true
+
+	if config == nil {
+		config = map[string]interface{}{}
+	}
+	b.workflow.Definition.Nodes = append(b.workflow.Definition.Nodes, Node{
+		ID:     id,
+		Type:   nodeType,
+		Name:   id,
+		Config: config,
+	})
+	if b.workflow.Definition.StartNodeID == "" {
+		b.workflow.Definition.StartNodeID = id
+	}
+	return b
+}
+
+// Connect runs target after source, which must both have been added
+func (b *WorkflowBuilder) Connect(source, target string) *WorkflowBuilder {
+	for _, id := range []string{source, target} {
+		if !b.nodeIDs[id] {
+			b.errs = append(b.errs, fmt.Errorf("edge %s -> %s references unknown node %s", source, target, id))
+			return b
+		}
+	}
+	b.workflow.Definition.Edges = append(b.workflow.Definition.Edges, Edge{
+		ID:     fmt.Sprintf("%s-%s", source, target),
+		Source: source,
+		Target: target,
+	})
+	return b
+}
+
+// Chain connects the nodes one after another
+func (b *WorkflowBuilder) Chain(ids ...string) *WorkflowBuilder {
+	for i := 1; i < len(ids); i++ {
+		b.Connect(ids[i-1], ids[i])
+	}
+	return b
+}
+
+// Variable sets a workflow variable, available to every node's input
+func (b *WorkflowBuilder) Variable(name string, value interface{}) *WorkflowBuilder {
+	b.workflow.Definition.Variables[name] = value
+	return b
+}
+
+// Timeout limits how long an execution may run
+func (b *WorkflowBuilder) Timeout(timeout time.Duration) *WorkflowBuilder {
+	b.workflow.Definition.Settings.Timeout = int(timeout.Seconds())
+	return b
+}
+
+// Build returns the workflow, or the first mistake made while building it
+func (b *WorkflowBuilder) Build() (*Workflow, error) {
+	if len(b.errs) > 0 {
+		return nil, b.errs[0]
+	}
+	if b.workflow.Name == "" {
+		return nil, fmt.Errorf("workflow has no name")
+	}
+	if len(b.workflow.Definition.Nodes) == 0 {
+		return nil, fmt.Errorf("workflow %s has no nodes", b.workflow.Name)
+	}
+
+	workflow := b.workflow
+	return &workflow, nil
+}
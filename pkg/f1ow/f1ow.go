@@ -0,0 +1,255 @@
+// Package f1ow embeds the workflow engine in other Go programs. Workflows
+// built with NewWorkflow run in-process with Run and need no database;
+// configure storage with WithDatabase and WithRedis to execute stored
+// workflows and queue jobs for workers.
+package f1ow
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/nuumz/f1ow/internal/engine"
+	"github.com/nuumz/f1ow/internal/models"
+	"github.com/nuumz/f1ow/internal/nodes"
+	"github.com/nuumz/f1ow/internal/storage"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Types shared with the engine
+type (
+	Workflow           = models.Workflow
+	WorkflowStatus     = models.WorkflowStatus
+	WorkflowDefinition = models.WorkflowDefinition
+	Node               = models.Node
+	Edge               = models.Edge
+	Execution          = models.Execution
+	ExecutionStatus    = models.ExecutionStatus
+
+	NodeType   = engine.NodeType
+	NodeSchema = engine.NodeSchema
+	Property   = engine.Property
+	PortSchema = engine.PortSchema
+
+	Event     = engine.Event
+	EventType = engine.EventType
+)
+
+// Workflow statuses
+const (
+	WorkflowStatusDraft    = models.WorkflowStatusDraft
+	WorkflowStatusActive   = models.WorkflowStatusActive
+	WorkflowStatusArchived = models.WorkflowStatusArchived
+)
+
+// Execution statuses
+const (
+	ExecutionStatusRunning   = models.ExecutionStatusRunning
+	ExecutionStatusCompleted = models.ExecutionStatusCompleted
+	ExecutionStatusFailed    = models.ExecutionStatusFailed
+)
+
+// Event types
+const (
+	EventExecutionStarted   = engine.EventExecutionStarted
+	EventExecutionCompleted = engine.EventExecutionCompleted
+	EventExecutionFailed    = engine.EventExecutionFailed
+	EventNodeStarted        = engine.EventNodeStarted
+	EventNodeCompleted      = engine.EventNodeCompleted
+	EventNodeFailed         = engine.EventNodeFailed
+)
+
+// ErrNoStorage is returned by operations that need a database or Redis
+// when the engine was created without them
+var ErrNoStorage = errors.New("f1ow: engine has no storage configured")
+
+// Engine runs workflows in-process
+type Engine struct {
+	engine   *engine.Engine
+	db       *storage.DB
+	redis    *storage.RedisClient
+	logger   *logrus.Logger
+	builtins bool
+}
+
+// Option configures an Engine
+type Option func(*Engine) error
+
+// WithDatabase connects to a Postgres or MySQL database with the engine's
+// schema, so stored workflows can be executed by ID
+func WithDatabase(dsn string) Option {
+	return func(e *Engine) error {
+		db, err := storage.NewDB(dsn)
+		if err != nil {
+			return fmt.Errorf("failed to connect to database: %w", err)
+		}
+		e.db = db
+		return nil
+	}
+}
+
+// WithRedis connects to Redis, used for the job queue and caching nodes
+func WithRedis(url string) Option {
+	return func(e *Engine) error {
+		redis, err := storage.NewRedisClient(url)
+		if err != nil {
+			return fmt.Errorf("failed to connect to redis: %w", err)
+		}
+		e.redis = redis
+		return nil
+	}
+}
+
+// WithLogger sets the logger the engine logs executions to
+func WithLogger(logger *logrus.Logger) Option {
+	return func(e *Engine) error {
+		e.logger = logger
+		return nil
+	}
+}
+
+// WithBuiltinNodes registers the built-in node types that need no
+// configuration beyond the engine's storage, such as http, transform, set,
+// and filter. Nodes that need Redis or a database are only registered when
+// they are configured.
+func WithBuiltinNodes() Option {
+	return func(e *Engine) error {
+		e.builtins = true
+		return nil
+	}
+}
+
+// New creates an engine
+func New(opts ...Option) (*Engine, error) {
+	e := &Engine{}
+	for _, opt := range opts {
+		if err := opt(e); err != nil {
+			e.Close()
+			return nil, err
+		}
+	}
+
+	var engineOpts []engine.Option
+	if e.logger != nil {
+		engineOpts = append(engineOpts, engine.WithLogger(e.logger))
+	}
+	e.engine = engine.NewEngine(e.db, e.redis, engineOpts...)
+
+	if e.builtins {
+		if err := e.registerBuiltinNodes(); err != nil {
+			e.Close()
+			return nil, err
+		}
+	}
+	return e, nil
+}
+
+func (e *Engine) registerBuiltinNodes() error {
+	var cache nodes.CacheStore
+	if e.redis != nil {
+		cache = e.redis
+	}
+
+	builtins := map[string]NodeType{
+		"http":         nodes.NewHTTPNode(cache),
+		"transform":    &nodes.TransformNode{},
+		"conditional":  &nodes.ConditionalNode{},
+		"loop":         &nodes.LoopNode{},
+		"parallel":     &nodes.ParallelNode{},
+		"set":          nodes.NewSetNode(),
+		"set_variable": nodes.NewSetVariableNode(),
+		"filter":       nodes.NewFilterNode(),
+		"notify":       nodes.NewNotifyNode(),
+		"kafka":        nodes.NewKafkaNode(),
+		"amqp":         nodes.NewAMQPNode(),
+		"mqtt":         nodes.NewMQTTNode(),
+		"grpc":         nodes.NewGRPCNode(),
+		"soap":         nodes.NewSOAPNode(),
+		"csv_parse":    nodes.NewCSVParseNode(),
+		"csv_generate": nodes.NewCSVGenerateNode(),
+		"llm":          nodes.NewLLMNode(),
+		"vector_store": nodes.NewVectorStoreNode(),
+		"github":       nodes.NewGitHubNode(),
+		"gitlab":       nodes.NewGitLabNode(),
+		"jira":         nodes.NewJiraNode(),
+		"servicenow":   nodes.NewServiceNowNode(),
+	}
+	if e.db != nil {
+		builtins["state"] = nodes.NewStateNode(e.db)
+		builtins["external_task"] = nodes.NewExternalTaskNode(e.db)
+	}
+	if e.redis != nil {
+		builtins["cache"] = nodes.NewCacheNode(e.redis)
+		builtins["redis"] = nodes.NewRedisNode(e.redis.Client())
+		if e.db != nil {
+			builtins["dedupe"] = nodes.NewDedupeNode(e.redis, e.db)
+		}
+	}
+
+	for nodeType, node := range builtins {
+		if err := e.engine.RegisterNode(nodeType, node); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RegisterNode makes a node type available to workflows under node.Type()
+func (e *Engine) RegisterNode(node NodeType) error {
+	return e.engine.RegisterNode(node.Type(), node)
+}
+
+// Run executes a workflow in-process without storing it or its execution.
+// The returned execution holds each node's output; it is also returned,
+// marked failed, together with the error when a node fails.
+func (e *Engine) Run(ctx context.Context, workflow *Workflow, input map[string]interface{}) (*Execution, error) {
+	return e.engine.Run(ctx, workflow, input)
+}
+
+// Execute runs a workflow stored in the database and records the execution
+func (e *Engine) Execute(ctx context.Context, workflowID string, input map[string]interface{}) (*Execution, error) {
+	if e.db == nil {
+		return nil, ErrNoStorage
+	}
+	return e.engine.Execute(ctx, workflowID, input)
+}
+
+// Enqueue queues a stored workflow for execution by a worker and returns
+// the job ID
+func (e *Engine) Enqueue(ctx context.Context, workflowID string, input map[string]interface{}) (string, error) {
+	if e.redis == nil {
+		return "", ErrNoStorage
+	}
+	job, err := e.engine.Enqueue(ctx, workflowID, input)
+	if err != nil {
+		return "", err
+	}
+	return job.ID, nil
+}
+
+// StartWorker processes queued jobs until ctx is cancelled
+func (e *Engine) StartWorker(ctx context.Context) error {
+	if e.db == nil || e.redis == nil {
+		return ErrNoStorage
+	}
+	return e.engine.StartWorker(ctx)
+}
+
+// Subscribe calls fn for every execution and node event until the returned
+// function is called. fn runs on the executing goroutine and must not block.
+func (e *Engine) Subscribe(fn func(Event)) (unsubscribe func()) {
+	return e.engine.Subscribe(fn)
+}
+
+// Close releases the engine's database and Redis connections
+func (e *Engine) Close() error {
+	var errs []error
+	if e.db != nil {
+		errs = append(errs, e.db.Close())
+	}
+	if e.redis != nil {
+		errs = append(errs, e.redis.Close())
+	}
+	return errors.Join(errs...)
+}
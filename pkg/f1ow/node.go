@@ -0,0 +1,92 @@
+package f1ow
+
+import (
+	"context"
+	"fmt"
+)
+
+// NodeFunc implements a node. config is the node's configuration from the
+// workflow definition and input holds the workflow variables plus the
+// outputs of earlier nodes under "nodeOutputs".
+type NodeFunc func(ctx context.Context, config, input map[string]interface{}) (map[string]interface{}, error)
+
+// NodeInfo describes a node type created with NewNode
+type NodeInfo struct {
+	Type        string
+	Name        string
+	Description string
+	Category    string
+	Icon        string
+	Schema      NodeSchema
+}
+
+// NewNode returns a node type that calls fn, for custom nodes that do not
+// need to implement NodeType themselves
+func NewNode(info NodeInfo, fn NodeFunc) NodeType {
+	if info.Name == "" {
+		info.Name = info.Type
+	}
+	if info.Category == "" {
+		info.Category = "Custom"
+	}
+	if info.Schema.Type == "" {
+		info.Schema.Type = "object"
+	}
+	return &funcNode{info: info, fn: fn}
+}
+
+type funcNode struct {
+	info NodeInfo
+	fn   NodeFunc
+}
+
+func (n *funcNode) Execute(ctx context.Context, config interface{}, input interface{}) (interface{}, error) {
+	configMap, err := toMap(config)
+	if err != nil {
+		return nil, fmt.Errorf("invalid config: %w", err)
+	}
+	inputMap, err := toMap(input)
+	if err != nil {
+		return nil, fmt.Errorf("invalid input: %w", err)
+	}
+
+	output, err := n.fn(ctx, configMap, inputMap)
+	if err != nil {
+		return nil, err
+	}
+	if output == nil {
+		output = map[string]interface{}{}
+	}
+	return output, nil
+}
+
+func (n *funcNode) ValidateConfig(config interface{}) error {
+	configMap, err := toMap(config)
+	if err != nil {
+		return err
+	}
+	for _, field := range n.info.Schema.Required {
+		if _, ok := configMap[field]; !ok {
+			return fmt.Errorf("%s is required", field)
+		}
+	}
+	return nil
+}
+
+func (n *funcNode) GetSchema() NodeSchema { return n.info.Schema }
+func (n *funcNode) Type() string          { return n.info.Type }
+func (n *funcNode) Name() string          { return n.info.Name }
+func (n *funcNode) Description() string   { return n.info.Description }
+func (n *funcNode) Category() string      { return n.info.Category }
+func (n *funcNode) Icon() string          { return n.info.Icon }
+
+func toMap(value interface{}) (map[string]interface{}, error) {
+	switch v := value.(type) {
+	case nil:
+		return map[string]interface{}{}, nil
+	case map[string]interface{}:
+		return v, nil
+	default:
+		return nil, fmt.Errorf("expected an object, got %T", value)
+	}
+}
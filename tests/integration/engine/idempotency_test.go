@@ -0,0 +1,76 @@
+//go:build integration
+
+// Package engine_test runs the engine against real services. Set
+// TEST_POSTGRES_URL to a migrated PostgreSQL database and TEST_REDIS_URL to
+// a Redis server to run it.
+package engine_test
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/nuumz/f1ow/internal/engine"
+	"github.com/nuumz/f1ow/internal/models"
+	"github.com/nuumz/f1ow/internal/storage"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// services connects to the database and Redis, with a workflow to run
+func services(t *testing.T) (*storage.DB, *storage.RedisClient, *models.Workflow) {
+	t.Helper()
+	dbURL, redisURL := os.Getenv("TEST_POSTGRES_URL"), os.Getenv("TEST_REDIS_URL")
+	if dbURL == "" || redisURL == "" {
+		t.Skip("TEST_POSTGRES_URL and TEST_REDIS_URL are not set")
+	}
+	db, err := storage.NewDB(dbURL)
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	redis, err := storage.NewRedisClient(redisURL)
+	require.NoError(t, err)
+	t.Cleanup(func() { redis.Close() })
+
+	userID := uuid.New()
+	_, err = db.Exec(`INSERT INTO users (id, email) VALUES ($1, $2)`, userID, userID.String()+"@example.com")
+	require.NoError(t, err)
+	workflow := &models.Workflow{Name: "orders", UserID: userID, Status: models.WorkflowStatusActive}
+	require.NoError(t, db.CreateWorkflow(context.Background(), workflow))
+	return db, redis, workflow
+}
+
+func TestSubmit_ReplaysIdempotencyKey(t *testing.T) {
+	db, redis, workflow := services(t)
+	eng := engine.NewEngine(db, redis)
+	ctx := engine.WithIdempotencyKey(context.Background(), uuid.NewString())
+
+	first, err := eng.Submit(ctx, workflow.ID.String(), "", map[string]interface{}{"n": 1})
+	require.NoError(t, err)
+	second, err := eng.Submit(ctx, workflow.ID.String(), "", map[string]interface{}{"n": 2})
+	require.NoError(t, err)
+	assert.Equal(t, first.ID, second.ID, "a repeated key returns the original execution")
+
+	var count int
+	require.NoError(t, db.Get(&count, `SELECT COUNT(*) FROM executions WHERE workflow_id = $1`, workflow.ID))
+	assert.Equal(t, 1, count, "no second execution is created")
+}
+
+func TestEnqueue_ReplaysIdempotencyKey(t *testing.T) {
+	db, redis, workflow := services(t)
+	eng := engine.NewEngine(db, redis)
+	ctx := engine.WithIdempotencyKey(context.Background(), uuid.NewString())
+
+	first, err := eng.Enqueue(ctx, workflow.ID.String(), nil)
+	require.NoError(t, err)
+	second, err := eng.Enqueue(ctx, workflow.ID.String(), nil)
+	require.NoError(t, err)
+	assert.Equal(t, first.ID, second.ID, "a repeated key returns the job queued first")
+	assert.Equal(t, first.ExecutionID, second.ExecutionID)
+
+	replayed, err := eng.IdempotentExecution(ctx, workflow.ID.String(), first.IdempotencyKey)
+	require.NoError(t, err)
+	require.NotNil(t, replayed)
+	assert.Equal(t, first.ExecutionID, replayed.ID.String())
+}
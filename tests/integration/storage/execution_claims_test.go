@@ -0,0 +1,122 @@
+//go:build integration
+
+// Package storage_test runs the storage layer against a real database. Set
+// TEST_POSTGRES_URL to a migrated PostgreSQL database to run it.
+package storage_test
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/nuumz/f1ow/internal/models"
+	"github.com/nuumz/f1ow/internal/storage"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func postgresDB(t *testing.T) *storage.DB {
+	t.Helper()
+	url := os.Getenv("TEST_POSTGRES_URL")
+	if url == "" {
+		t.Skip("TEST_POSTGRES_URL is not set")
+	}
+	db, err := storage.NewDB(url)
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+// claimsWorkflow stores a workflow to run executions of
+func claimsWorkflow(t *testing.T, db *storage.DB) *models.Workflow {
+	t.Helper()
+	userID := uuid.New()
+	_, err := db.Exec(`INSERT INTO users (id, email) VALUES ($1, $2)`, userID, userID.String()+"@example.com")
+	require.NoError(t, err)
+	workflow := &models.Workflow{Name: "claims", UserID: userID}
+	require.NoError(t, db.CreateWorkflow(context.Background(), workflow))
+	return workflow
+}
+
+func pendingExecution(t *testing.T, db *storage.DB, workflow *models.Workflow) *models.Execution {
+	t.Helper()
+	execution := &models.Execution{WorkflowID: workflow.ID, Status: models.ExecutionStatusPending, Input: map[string]interface{}{}}
+	require.NoError(t, db.CreateExecution(context.Background(), execution))
+	return execution
+}
+
+func TestClaimExecution_FencesStaleTokens(t *testing.T) {
+	db := postgresDB(t)
+	ctx := context.Background()
+	workflow := claimsWorkflow(t, db)
+	execution := pendingExecution(t, db, workflow)
+
+	stale, err := db.ClaimExecution(ctx, execution.ID, "worker-1")
+	require.NoError(t, err)
+	current, err := db.ClaimExecution(ctx, execution.ID, "worker-2")
+	require.NoError(t, err)
+	assert.Greater(t, current, stale, "each claim gets a newer token")
+
+	// The worker that lost the execution cannot record its result
+	execution.Status = models.ExecutionStatusFailed
+	execution.ClaimToken = stale
+	assert.ErrorIs(t, db.UpdateExecution(ctx, execution), storage.ErrExecutionFenced)
+	assert.ErrorIs(t, db.AppendJournal(ctx, execution.ID, stale, "fetch", nil), storage.ErrExecutionFenced)
+	stored, err := db.GetExecution(ctx, execution.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.ExecutionStatusRunning, stored.Status)
+	journal, err := db.GetJournal(ctx, execution.ID)
+	require.NoError(t, err)
+	assert.Empty(t, journal)
+
+	execution.Status = models.ExecutionStatusCompleted
+	execution.ClaimToken = current
+	require.NoError(t, db.UpdateExecution(ctx, execution))
+
+	_, err = db.ClaimExecution(ctx, execution.ID, "worker-3")
+	assert.ErrorIs(t, err, storage.ErrExecutionNotClaimable, "finished executions cannot be claimed")
+}
+
+func TestRequeueExecution_OnceAndKeepsJournal(t *testing.T) {
+	db := postgresDB(t)
+	ctx := context.Background()
+	workflow := claimsWorkflow(t, db)
+	execution := pendingExecution(t, db, workflow)
+
+	token, err := db.ClaimExecution(ctx, execution.ID, "worker-dead")
+	require.NoError(t, err)
+	require.NoError(t, db.AppendJournal(ctx, execution.ID, token, "fetch", map[string]interface{}{"rows": 3}))
+
+	claimed, err := db.ListClaimedExecutions(ctx)
+	require.NoError(t, err)
+	var owner string
+	for _, c := range claimed {
+		if c.ID == execution.ID {
+			owner = c.ClaimedBy
+		}
+	}
+	assert.Equal(t, "worker-dead", owner)
+
+	// Reapers racing to requeue the execution requeue it once
+	requeued, err := db.RequeueExecution(ctx, execution.ID, "worker-dead")
+	require.NoError(t, err)
+	assert.True(t, requeued)
+	requeued, err = db.RequeueExecution(ctx, execution.ID, "worker-dead")
+	require.NoError(t, err)
+	assert.False(t, requeued)
+
+	stored, err := db.GetExecution(ctx, execution.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.ExecutionStatusPending, stored.Status)
+
+	// The worker that claims it next resumes from the journal, and the dead
+	// worker can no longer add to it
+	_, err = db.ClaimExecution(ctx, execution.ID, "worker-new")
+	require.NoError(t, err)
+	journal, err := db.GetJournal(ctx, execution.ID)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"fetch": map[string]interface{}{"rows": float64(3)}}, journal)
+	assert.ErrorIs(t, db.AppendJournal(ctx, execution.ID, token, "store", nil), storage.ErrExecutionFenced)
+}
@@ -0,0 +1,238 @@
+//go:build integration
+
+// Package storage_test runs the storage layer against real databases. SQLite
+// always runs; PostgreSQL and MySQL run when TEST_POSTGRES_URL and
+// TEST_MYSQL_URL point at servers, as `make test-integration` arranges.
+package storage_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/nuumz/f1ow/internal/models"
+	"github.com/nuumz/f1ow/internal/storage"
+	"github.com/nuumz/f1ow/internal/tenant"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// backends returns a migrated database for each configured backend
+func backends(t *testing.T) map[string]*storage.DB {
+	t.Helper()
+	dsns := map[string]string{
+		"sqlite":   "sqlite://" + filepath.Join(t.TempDir(), "f1ow.db"),
+		"postgres": os.Getenv("TEST_POSTGRES_URL"),
+		"mysql":    os.Getenv("TEST_MYSQL_URL"),
+	}
+
+	dbs := make(map[string]*storage.DB)
+	for name, dsn := range dsns {
+		if dsn == "" {
+			t.Logf("skipping %s: no database URL", name)
+			continue
+		}
+		db, err := storage.NewDB(dsn)
+		require.NoError(t, err, name)
+		t.Cleanup(func() { db.Close() })
+
+		_, err = db.Migrate(context.Background())
+		require.NoError(t, err, name)
+		dbs[name] = db
+	}
+	return dbs
+}
+
+// forEachBackend runs test against every backend in a tenant of its own, so
+// runs against a long-lived server do not see each other's rows
+func forEachBackend(t *testing.T, test func(t *testing.T, ctx context.Context, db *storage.DB)) {
+	for name, db := range backends(t) {
+		db := db
+		t.Run(name, func(t *testing.T) {
+			owner := &models.Tenant{Name: "integration-" + uuid.NewString()}
+			require.NoError(t, db.CreateTenant(context.Background(), owner))
+			test(t, tenant.WithID(context.Background(), owner.ID), db)
+		})
+	}
+}
+
+func createUser(t *testing.T, db *storage.DB) uuid.UUID {
+	t.Helper()
+	id := uuid.New()
+	_, err := db.Exec(`INSERT INTO users (id, email) VALUES ($1, $2)`, id, id.String()+"@example.com")
+	require.NoError(t, err)
+	return id
+}
+
+func TestWorkflowCRUD(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, ctx context.Context, db *storage.DB) {
+		workflow := &models.Workflow{
+			Name:     "Sync orders",
+			UserID:   createUser(t, db),
+			Tags:     []string{"billing", "nightly"},
+			Metadata: map[string]interface{}{"owner": "ops"},
+			Definition: models.WorkflowDefinition{
+				Nodes: []models.Node{{ID: "fetch", Type: "http"}},
+			},
+		}
+		require.NoError(t, db.CreateWorkflow(ctx, workflow))
+
+		workflow.Description = "every night"
+		require.NoError(t, db.UpdateWorkflow(ctx, workflow))
+		assert.Equal(t, 2, workflow.Version)
+
+		stored, err := db.GetWorkflow(ctx, workflow.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "every night", stored.Description)
+		assert.Equal(t, []string{"billing", "nightly"}, stored.Tags)
+		assert.Equal(t, "ops", stored.Metadata["owner"])
+
+		versions, err := db.ListWorkflowVersions(ctx, workflow.ID)
+		require.NoError(t, err)
+		require.Len(t, versions, 2)
+		first, err := db.GetWorkflowVersion(ctx, workflow.ID, 1)
+		require.NoError(t, err)
+		assert.Len(t, first.Definition.Nodes, 1)
+
+		listed, total, err := db.ListWorkflows(ctx, storage.WorkflowListOptions{Query: "ORDERS", Tags: []string{"billing"}, Limit: 10})
+		require.NoError(t, err)
+		assert.Equal(t, 1, total)
+		require.Len(t, listed, 1)
+		assert.Equal(t, workflow.ID, listed[0].ID)
+
+		require.NoError(t, db.SetWorkflowStatus(ctx, workflow.ID, models.WorkflowStatusActive))
+		active, err := db.GetWorkflows(ctx)
+		require.NoError(t, err)
+		require.Len(t, active, 1)
+
+		require.NoError(t, db.DeleteWorkflow(ctx, workflow.ID))
+		_, total, err = db.ListWorkflows(ctx, storage.WorkflowListOptions{})
+		require.NoError(t, err)
+		assert.Zero(t, total)
+
+		_, err = db.GetWorkflow(ctx, uuid.New())
+		assert.ErrorIs(t, err, storage.ErrWorkflowNotFound)
+	})
+}
+
+func TestExecutionLifecycle(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, ctx context.Context, db *storage.DB) {
+		workflow := &models.Workflow{Name: "lifecycle", UserID: createUser(t, db)}
+		require.NoError(t, db.CreateWorkflow(ctx, workflow))
+
+		execution := &models.Execution{
+			WorkflowID: workflow.ID,
+			Status:     models.ExecutionStatusPending,
+			Input:      map[string]interface{}{"order": "A-1"},
+		}
+		require.NoError(t, db.CreateExecution(ctx, execution))
+
+		token, err := db.ClaimExecution(ctx, execution.ID, "worker-1")
+		require.NoError(t, err)
+		require.NoError(t, db.AppendJournal(ctx, execution.ID, token, "fetch", map[string]interface{}{"rows": 3}))
+
+		journal, err := db.GetJournal(ctx, execution.ID)
+		require.NoError(t, err)
+		assert.Equal(t, map[string]interface{}{"rows": float64(3)}, journal["fetch"])
+
+		claimed, err := db.ListClaimedExecutions(ctx)
+		require.NoError(t, err)
+		assert.Contains(t, claimedIDs(claimed), execution.ID)
+
+		// A stale token cannot write once the execution is claimed again
+		_, err = db.ClaimExecution(ctx, execution.ID, "worker-2")
+		require.NoError(t, err)
+		assert.ErrorIs(t, db.AppendJournal(ctx, execution.ID, token, "store", nil), storage.ErrExecutionFenced)
+		assert.ErrorIs(t, db.SaveNodeExecutions(ctx, execution.ID, token, []models.NodeExecution{
+			{NodeID: "store", Status: models.ExecutionStatusRunning},
+		}), storage.ErrExecutionFenced)
+
+		completed := time.Now()
+		execution.Status = models.ExecutionStatusCompleted
+		execution.CompletedAt = &completed
+		execution.Output = map[string]interface{}{"ok": true}
+		require.NoError(t, db.UpdateExecution(ctx, execution, models.NodeExecution{
+			NodeID: "fetch", Status: models.ExecutionStatusCompleted, Output: map[string]interface{}{"rows": 3},
+		}))
+
+		stored, err := db.GetExecution(ctx, execution.ID)
+		require.NoError(t, err)
+		assert.Equal(t, models.ExecutionStatusCompleted, stored.Status)
+		assert.Equal(t, true, stored.Output["ok"])
+		require.Contains(t, stored.Context.NodeExecutions, "fetch")
+		assert.Equal(t, float64(3), stored.Context.NodeExecutions["fetch"].Output["rows"])
+
+		active, err := db.ListActiveExecutions(ctx, workflow.ID)
+		require.NoError(t, err)
+		assert.Empty(t, active)
+	})
+}
+
+func claimedIDs(executions []storage.ClaimedExecution) []uuid.UUID {
+	ids := make([]uuid.UUID, len(executions))
+	for i, execution := range executions {
+		ids[i] = execution.ID
+	}
+	return ids
+}
+
+func TestListExecutionsPages(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, ctx context.Context, db *storage.DB) {
+		workflow := &models.Workflow{Name: "paged", UserID: createUser(t, db)}
+		require.NoError(t, db.CreateWorkflow(ctx, workflow))
+		for i := 0; i < 5; i++ {
+			require.NoError(t, db.CreateExecution(ctx, &models.Execution{WorkflowID: workflow.ID, Status: models.ExecutionStatusCompleted}))
+		}
+
+		seen := map[uuid.UUID]bool{}
+		opts := storage.ExecutionListOptions{
+			WorkflowID: &workflow.ID,
+			Statuses:   []models.ExecutionStatus{models.ExecutionStatusCompleted},
+			Limit:      2,
+		}
+		for {
+			page, err := db.ListExecutions(ctx, opts)
+			require.NoError(t, err)
+			assert.Equal(t, 5, page.Total)
+			for _, execution := range page.Executions {
+				assert.False(t, seen[execution.ID], "execution listed twice")
+				seen[execution.ID] = true
+			}
+			if page.NextCursor == "" {
+				break
+			}
+			opts.Cursor = page.NextCursor
+		}
+		assert.Len(t, seen, 5)
+	})
+}
+
+func TestDeployments(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, ctx context.Context, db *storage.DB) {
+		userID := createUser(t, db)
+		workflow := &models.Workflow{Name: "deployed", UserID: userID}
+		require.NoError(t, db.CreateWorkflow(ctx, workflow))
+		require.NoError(t, db.CreateEnvironment(ctx, &models.Environment{Name: "production"}))
+
+		require.NoError(t, db.SaveDeployment(ctx, &models.Deployment{
+			WorkflowID:  workflow.ID,
+			Environment: "production",
+			Version:     1,
+			Credentials: map[string]string{"dev-db": "prod-db"},
+			DeployedBy:  userID,
+			DeployedAt:  time.Now(),
+		}))
+
+		deployment, err := db.GetDeployment(ctx, workflow.ID, "production")
+		require.NoError(t, err)
+		assert.Equal(t, "prod-db", deployment.Credentials["dev-db"])
+
+		require.NoError(t, db.DeleteDeployment(ctx, workflow.ID, "production"))
+		_, err = db.GetDeployment(ctx, workflow.ID, "production")
+		assert.ErrorIs(t, err, storage.ErrDeploymentNotFound)
+	})
+}
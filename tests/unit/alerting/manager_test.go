@@ -0,0 +1,341 @@
+package alerting_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/nuumz/f1ow/internal/alerting"
+	"github.com/nuumz/f1ow/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type memoryStore struct {
+	mu         sync.Mutex
+	rules      map[uuid.UUID]models.AlertRule
+	channels   map[uuid.UUID]models.AlertChannel
+	alerts     []models.Alert
+	executions []models.ExecutionSummary
+}
+
+func newMemoryStore() *memoryStore {
+	return &memoryStore{
+		rules:    make(map[uuid.UUID]models.AlertRule),
+		channels: make(map[uuid.UUID]models.AlertChannel),
+	}
+}
+
+func (s *memoryStore) CreateAlertRule(ctx context.Context, rule *models.AlertRule) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.rules[rule.ID] = *rule
+	return nil
+}
+
+func (s *memoryStore) GetAlertRule(ctx context.Context, id uuid.UUID) (*models.AlertRule, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	rule, ok := s.rules[id]
+	if !ok {
+		return nil, alerting.ErrRuleNotFound
+	}
+	return &rule, nil
+}
+
+func (s *memoryStore) ListAlertRules(ctx context.Context) ([]models.AlertRule, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	list := []models.AlertRule{}
+	for _, rule := range s.rules {
+		list = append(list, rule)
+	}
+	return list, nil
+}
+
+func (s *memoryStore) UpdateAlertRule(ctx context.Context, rule *models.AlertRule) error {
+	return s.CreateAlertRule(ctx, rule)
+}
+
+func (s *memoryStore) DeleteAlertRule(ctx context.Context, id uuid.UUID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.rules, id)
+	return nil
+}
+
+func (s *memoryStore) MarkAlertRuleFired(ctx context.Context, id uuid.UUID, firedAt time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	rule := s.rules[id]
+	rule.LastFiredAt = &firedAt
+	s.rules[id] = rule
+	return nil
+}
+
+func (s *memoryStore) CreateAlertChannel(ctx context.Context, channel *models.AlertChannel) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.channels[channel.ID] = *channel
+	return nil
+}
+
+func (s *memoryStore) GetAlertChannel(ctx context.Context, id uuid.UUID) (*models.AlertChannel, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	channel, ok := s.channels[id]
+	if !ok {
+		return nil, alerting.ErrChannelNotFound
+	}
+	return &channel, nil
+}
+
+func (s *memoryStore) ListAlertChannels(ctx context.Context) ([]models.AlertChannel, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	list := []models.AlertChannel{}
+	for _, channel := range s.channels {
+		list = append(list, channel)
+	}
+	return list, nil
+}
+
+func (s *memoryStore) UpdateAlertChannel(ctx context.Context, channel *models.AlertChannel) error {
+	return s.CreateAlertChannel(ctx, channel)
+}
+
+func (s *memoryStore) DeleteAlertChannel(ctx context.Context, id uuid.UUID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.channels, id)
+	return nil
+}
+
+func (s *memoryStore) CreateAlert(ctx context.Context, alert *models.Alert) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.alerts = append(s.alerts, *alert)
+	return nil
+}
+
+func (s *memoryStore) ListAlerts(ctx context.Context, ruleID *uuid.UUID, limit int) ([]models.Alert, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]models.Alert{}, s.alerts...), nil
+}
+
+func (s *memoryStore) ListRecentExecutions(ctx context.Context, workflowID *uuid.UUID, since time.Time) ([]models.ExecutionSummary, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var list []models.ExecutionSummary
+	for _, execution := range s.executions {
+		if workflowID != nil && execution.WorkflowID != *workflowID {
+			continue
+		}
+		if execution.CompletedAt != nil && execution.CompletedAt.Before(since) {
+			continue
+		}
+		list = append(list, execution)
+	}
+	return list, nil
+}
+
+// recordingSender records the alerts sent and fails for channels in fail
+type recordingSender struct {
+	mu   sync.Mutex
+	sent []uuid.UUID
+	fail map[uuid.UUID]bool
+}
+
+func (s *recordingSender) Send(ctx context.Context, channel *models.AlertChannel, alert *models.Alert) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.fail[channel.ID] {
+		return errors.New("channel unavailable")
+	}
+	s.sent = append(s.sent, channel.ID)
+	return nil
+}
+
+func newManager(t *testing.T) (*alerting.Manager, *memoryStore, *recordingSender, *models.AlertChannel) {
+	store := newMemoryStore()
+	sender := &recordingSender{fail: map[uuid.UUID]bool{}}
+	manager := alerting.NewManager(store, sender, logrus.New())
+
+	channel, err := manager.CreateChannel(context.Background(), &models.AlertChannel{
+		Name: "ops", Type: models.AlertChannelSlack, Config: map[string]interface{}{"webhook_url": "https://hooks.example.com/x"},
+	})
+	require.NoError(t, err)
+	return manager, store, sender, channel
+}
+
+func execution(workflowID uuid.UUID, status models.ExecutionStatus, took time.Duration, ago time.Duration) models.ExecutionSummary {
+	completed := time.Now().Add(-ago)
+	return models.ExecutionSummary{
+		ID:          uuid.New(),
+		WorkflowID:  workflowID,
+		Status:      status,
+		StartedAt:   completed.Add(-took),
+		CompletedAt: &completed,
+	}
+}
+
+func TestManager_ValidatesRulesAndChannels(t *testing.T) {
+	manager, _, _, channel := newManager(t)
+	ctx := context.Background()
+
+	_, err := manager.CreateChannel(ctx, &models.AlertChannel{Name: "mail", Type: models.AlertChannelEmail, Config: map[string]interface{}{}})
+	assert.ErrorIs(t, err, alerting.ErrInvalid)
+	_, err = manager.CreateChannel(ctx, &models.AlertChannel{Name: "sms", Type: "sms"})
+	assert.ErrorIs(t, err, alerting.ErrInvalid)
+	_, err = manager.CreateChannel(ctx, &models.AlertChannel{
+		Name: "mail", Type: models.AlertChannelEmail, Config: map[string]interface{}{"to": "a@example.com, b@example.com"},
+	})
+	assert.NoError(t, err)
+
+	_, err = manager.CreateRule(ctx, &models.AlertRule{Name: "r", Condition: "latency", Threshold: 1, ChannelIDs: []uuid.UUID{channel.ID}})
+	assert.ErrorIs(t, err, alerting.ErrInvalid)
+	_, err = manager.CreateRule(ctx, &models.AlertRule{Name: "r", Condition: models.AlertOnFailures, Threshold: 0, ChannelIDs: []uuid.UUID{channel.ID}})
+	assert.ErrorIs(t, err, alerting.ErrInvalid)
+	_, err = manager.CreateRule(ctx, &models.AlertRule{Name: "r", Condition: models.AlertOnFailures, Threshold: 3, ChannelIDs: []uuid.UUID{uuid.New()}})
+	assert.ErrorIs(t, err, alerting.ErrInvalid)
+
+	rule, err := manager.CreateRule(ctx, &models.AlertRule{Name: "r", Condition: models.AlertOnFailures, Threshold: 3, ChannelIDs: []uuid.UUID{channel.ID}})
+	require.NoError(t, err)
+	assert.NotEqual(t, uuid.Nil, rule.ID)
+}
+
+func TestManager_FiresOnFailuresWithinWindow(t *testing.T) {
+	manager, store, sender, channel := newManager(t)
+	ctx := context.Background()
+	workflowID := uuid.New()
+
+	rule, err := manager.CreateRule(ctx, &models.AlertRule{
+		Name: "failing", WorkflowID: &workflowID, Condition: models.AlertOnFailures,
+		Threshold: 3, Window: 600, ChannelIDs: []uuid.UUID{channel.ID}, Enabled: true,
+	})
+	require.NoError(t, err)
+
+	store.executions = []models.ExecutionSummary{
+		execution(workflowID, models.ExecutionStatusFailed, time.Second, time.Minute),
+		execution(workflowID, models.ExecutionStatusFailed, time.Second, 2*time.Minute),
+		execution(workflowID, models.ExecutionStatusCompleted, time.Second, 3*time.Minute),
+		execution(workflowID, models.ExecutionStatusFailed, time.Second, time.Hour), // outside the window
+		execution(uuid.New(), models.ExecutionStatusFailed, time.Second, time.Minute),
+	}
+	fired, err := manager.Evaluate(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, fired, "two failures are under the threshold")
+
+	store.executions = append(store.executions, execution(workflowID, models.ExecutionStatusFailed, time.Second, 0))
+	fired, err = manager.Evaluate(ctx)
+	require.NoError(t, err)
+	require.Len(t, fired, 1)
+	assert.Equal(t, rule.ID, fired[0].RuleID)
+	assert.Equal(t, float64(3), fired[0].Value)
+	assert.Equal(t, []uuid.UUID{channel.ID}, sender.sent)
+
+	history, err := manager.ListAlerts(ctx, nil, 0)
+	require.NoError(t, err)
+	assert.Len(t, history, 1)
+
+	// The rule stays quiet until its cooldown passes
+	fired, err = manager.Evaluate(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, fired)
+}
+
+func TestManager_FiresOnSlowExecution(t *testing.T) {
+	manager, store, _, channel := newManager(t)
+	ctx := context.Background()
+	workflowID := uuid.New()
+
+	_, err := manager.CreateRule(ctx, &models.AlertRule{
+		Name: "slow", Condition: models.AlertOnDuration, Threshold: 300,
+		ChannelIDs: []uuid.UUID{channel.ID}, Enabled: true,
+	})
+	require.NoError(t, err)
+
+	slow := execution(workflowID, models.ExecutionStatusCompleted, 8*time.Minute, time.Minute)
+	store.executions = []models.ExecutionSummary{
+		execution(workflowID, models.ExecutionStatusCompleted, time.Minute, time.Minute),
+		execution(workflowID, models.ExecutionStatusCompleted, 6*time.Minute, time.Minute),
+		slow,
+	}
+	fired, err := manager.Evaluate(ctx)
+	require.NoError(t, err)
+	require.Len(t, fired, 1)
+	assert.Equal(t, &slow.ID, fired[0].ExecutionID)
+	assert.Equal(t, float64(480), fired[0].Value)
+}
+
+func TestManager_RecordsFailedDeliveries(t *testing.T) {
+	manager, store, sender, channel := newManager(t)
+	ctx := context.Background()
+	sender.fail[channel.ID] = true
+
+	_, err := manager.CreateRule(ctx, &models.AlertRule{
+		Name: "any failure", Condition: models.AlertOnFailures, Threshold: 1,
+		ChannelIDs: []uuid.UUID{channel.ID}, Enabled: true,
+	})
+	require.NoError(t, err)
+	store.executions = []models.ExecutionSummary{execution(uuid.New(), models.ExecutionStatusFailed, time.Second, 0)}
+
+	fired, err := manager.Evaluate(ctx)
+	require.NoError(t, err)
+	require.Len(t, fired, 1)
+	require.Len(t, fired[0].Deliveries, 1)
+	assert.Equal(t, "channel unavailable", fired[0].Deliveries[0].Error)
+}
+
+func TestManager_SkipsDisabledRules(t *testing.T) {
+	manager, store, _, channel := newManager(t)
+	ctx := context.Background()
+
+	_, err := manager.CreateRule(ctx, &models.AlertRule{
+		Name: "off", Condition: models.AlertOnFailures, Threshold: 1, ChannelIDs: []uuid.UUID{channel.ID},
+	})
+	require.NoError(t, err)
+	store.executions = []models.ExecutionSummary{execution(uuid.New(), models.ExecutionStatusFailed, time.Second, 0)}
+
+	fired, err := manager.Evaluate(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, fired)
+}
+
+func TestNotifier_PostsToWebhook(t *testing.T) {
+	var body string
+	var header string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		body, header = string(data), r.Header.Get("X-Token")
+	}))
+	defer server.Close()
+
+	notifier := alerting.NewNotifier(alerting.SMTPConfig{})
+	channel := &models.AlertChannel{Type: models.AlertChannelWebhook, Config: map[string]interface{}{
+		"url": server.URL, "headers": map[string]interface{}{"X-Token": "secret"},
+	}}
+	err := notifier.Send(context.Background(), channel, &models.Alert{RuleName: "failing", Message: "3 failed executions"})
+	require.NoError(t, err)
+	assert.Contains(t, body, `"message":"3 failed executions"`)
+	assert.Equal(t, "secret", header)
+
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer failing.Close()
+	channel.Config["url"] = failing.URL
+	assert.Error(t, notifier.Send(context.Background(), channel, &models.Alert{}))
+
+	email := &models.AlertChannel{Type: models.AlertChannelEmail, Config: map[string]interface{}{"to": "ops@example.com"}}
+	assert.Error(t, notifier.Send(context.Background(), email, &models.Alert{}), "no SMTP server is configured")
+}
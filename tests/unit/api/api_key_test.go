@@ -0,0 +1,110 @@
+package api_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/nuumz/f1ow/internal/api"
+	"github.com/nuumz/f1ow/internal/auth"
+	"github.com/nuumz/f1ow/internal/models"
+	"github.com/nuumz/f1ow/internal/ratelimit"
+	"github.com/nuumz/f1ow/internal/storage"
+	"github.com/nuumz/f1ow/internal/tenant"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type memoryAPIKeys map[string]*models.APIKey
+
+func (m memoryAPIKeys) GetAPIKeyByHash(ctx context.Context, hash string) (*models.APIKey, error) {
+	if key, ok := m[hash]; ok {
+		return key, nil
+	}
+	return nil, storage.ErrAPIKeyNotFound
+}
+
+func (m memoryAPIKeys) TouchAPIKey(ctx context.Context, id uuid.UUID, usedAt time.Time) error {
+	return nil
+}
+
+func newAPIKey(t *testing.T, store memoryAPIKeys, key models.APIKey) string {
+	secret, prefix, hash, err := auth.GenerateAPIKey()
+	require.NoError(t, err)
+	key.ID, key.Prefix, key.KeyHash = uuid.New(), prefix, hash
+	store[hash] = &key
+	return secret
+}
+
+func apiKeyRouter(store memoryAPIKeys) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	group := router.Group("/api/v1", api.Authenticate(api.AuthConfig{
+		APIKeys:     store,
+		RateLimiter: ratelimit.NewLimiter(ratelimit.NewMemoryBackend(), nil),
+	}), api.AuthorizeScopes())
+	group.GET("/workflows", func(c *gin.Context) {
+		id, _ := tenant.FromContext(c.Request.Context())
+		c.String(200, id.String())
+	})
+	group.POST("/workflows/:id/execute", func(c *gin.Context) { c.Status(202) })
+	group.POST("/workflows/:id/execute/batch", func(c *gin.Context) { c.Status(202) })
+	group.POST("/api-keys", api.RequireUser(), func(c *gin.Context) { c.Status(201) })
+	return router
+}
+
+func request(router *gin.Engine, method, path, key string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, nil)
+	if key != "" {
+		req.Header.Set("X-API-Key", key)
+	}
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestAPIKey_ScopesTenantAndPermissions(t *testing.T) {
+	store := memoryAPIKeys{}
+	tenantID := uuid.New()
+	key := newAPIKey(t, store, models.APIKey{TenantID: tenantID, Scopes: []string{auth.ScopeExecute}})
+	router := apiKeyRouter(store)
+
+	assert.Equal(t, 202, request(router, http.MethodPost, "/api/v1/workflows/"+uuid.NewString()+"/execute", key).Code)
+	assert.Equal(t, 202, request(router, http.MethodPost, "/api/v1/workflows/"+uuid.NewString()+"/execute/batch", key).Code)
+	assert.Equal(t, 403, request(router, http.MethodGet, "/api/v1/workflows", key).Code)
+	assert.Equal(t, 403, request(router, http.MethodPost, "/api/v1/api-keys", key).Code)
+
+	readKey := newAPIKey(t, store, models.APIKey{TenantID: tenantID, Scopes: []string{auth.ScopeRead}})
+	rec := request(router, http.MethodGet, "/api/v1/workflows", readKey)
+	assert.Equal(t, 200, rec.Code)
+	assert.Equal(t, tenantID.String(), rec.Body.String())
+}
+
+func TestAPIKey_RejectsUnknownRevokedAndExpiredKeys(t *testing.T) {
+	store := memoryAPIKeys{}
+	past := time.Now().Add(-time.Hour)
+	revoked := newAPIKey(t, store, models.APIKey{Scopes: []string{auth.ScopeRead}, RevokedAt: &past})
+	expired := newAPIKey(t, store, models.APIKey{Scopes: []string{auth.ScopeRead}, ExpiresAt: &past})
+	router := apiKeyRouter(store)
+
+	assert.Equal(t, 401, request(router, http.MethodGet, "/api/v1/workflows", auth.APIKeyPrefix+"unknown").Code)
+	assert.Equal(t, 401, request(router, http.MethodGet, "/api/v1/workflows", revoked).Code)
+	assert.Equal(t, 401, request(router, http.MethodGet, "/api/v1/workflows", expired).Code)
+}
+
+func TestAPIKey_RateLimit(t *testing.T) {
+	store := memoryAPIKeys{}
+	key := newAPIKey(t, store, models.APIKey{Scopes: []string{auth.ScopeRead}, RateLimit: "2/m"})
+	router := apiKeyRouter(store)
+
+	assert.Equal(t, 200, request(router, http.MethodGet, "/api/v1/workflows", key).Code)
+	assert.Equal(t, 200, request(router, http.MethodGet, "/api/v1/workflows", key).Code)
+	rec := request(router, http.MethodGet, "/api/v1/workflows", key)
+	assert.Equal(t, 429, rec.Code)
+	assert.NotEmpty(t, rec.Header().Get("Retry-After"))
+}
@@ -0,0 +1,77 @@
+package api_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/nuumz/f1ow/internal/api"
+	"github.com/nuumz/f1ow/internal/auth"
+	"github.com/nuumz/f1ow/internal/tenant"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func tenantRouter(config api.AuthConfig) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/tenant", api.Authenticate(config), func(c *gin.Context) {
+		id, _ := tenant.FromContext(c.Request.Context())
+		c.String(200, id.String())
+	})
+	return router
+}
+
+func get(router *gin.Engine, token string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/tenant", nil)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestAuthenticate_TenantFromToken(t *testing.T) {
+	verifier := auth.NewVerifier("secret")
+	tenantID := uuid.New()
+	token, err := verifier.Sign(&auth.Claims{
+		TenantID: tenantID.String(),
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   uuid.New().String(),
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+		},
+	})
+	require.NoError(t, err)
+
+	rec := get(tenantRouter(api.AuthConfig{Verifier: verifier}), token)
+	assert.Equal(t, 200, rec.Code)
+	assert.Equal(t, tenantID.String(), rec.Body.String())
+}
+
+func TestAuthenticate_DefaultTenantWithoutToken(t *testing.T) {
+	rec := get(tenantRouter(api.AuthConfig{Verifier: auth.NewVerifier("secret")}), "")
+	assert.Equal(t, 200, rec.Code)
+	assert.Equal(t, tenant.DefaultID.String(), rec.Body.String())
+}
+
+func TestAuthenticate_RejectsInvalidTokens(t *testing.T) {
+	router := tenantRouter(api.AuthConfig{Verifier: auth.NewVerifier("secret"), Required: true})
+
+	assert.Equal(t, 401, get(router, "").Code)
+
+	forged, err := auth.NewVerifier("other").Sign(&auth.Claims{TenantID: uuid.New().String()})
+	require.NoError(t, err)
+	assert.Equal(t, 401, get(router, forged).Code)
+
+	expired, err := auth.NewVerifier("secret").Sign(&auth.Claims{
+		RegisteredClaims: jwt.RegisteredClaims{ExpiresAt: jwt.NewNumericDate(time.Now().Add(-time.Minute))},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 401, get(router, expired).Code)
+}
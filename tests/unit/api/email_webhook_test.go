@@ -0,0 +1,81 @@
+package api_test
+
+import (
+	"context"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/nuumz/f1ow/internal/api"
+	"github.com/nuumz/f1ow/internal/engine"
+	"github.com/nuumz/f1ow/internal/models"
+	"github.com/nuumz/f1ow/internal/storage"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func emailWebhookRouter(t *testing.T) (*gin.Engine, *storage.DB, *engine.MemoryQueue, uuid.UUID) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	db, err := storage.NewDB("sqlite://" + filepath.Join(t.TempDir(), "f1ow.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	_, err = db.Migrate(context.Background())
+	require.NoError(t, err)
+
+	userID := uuid.New()
+	_, err = db.Exec(`INSERT INTO users (id, email) VALUES ($1, $2)`, userID, "inbound@example.com")
+	require.NoError(t, err)
+
+	queue := engine.NewMemoryQueue(nil)
+	router := gin.New()
+	router.POST("/api/v1/webhooks/email/:id", api.ReceiveEmailWebhook(engine.NewEngine(db, nil, engine.WithQueue(queue)), db))
+	return router, db, queue, userID
+}
+
+func postEmail(router *gin.Engine, path, token string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest("POST", path, strings.NewReader(`{"from": "a@example.com", "subject": "Hello"}`))
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("X-F1ow-Token", token)
+	}
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestReceiveEmailWebhook_RequiresTriggerSecret(t *testing.T) {
+	router, db, queue, userID := emailWebhookRouter(t)
+	ctx := context.Background()
+
+	trigger := &models.Workflow{Name: "inbound", UserID: userID, Status: models.WorkflowStatusActive,
+		Definition: models.WorkflowDefinition{Nodes: []models.Node{
+			{ID: "unsecured", Type: "email_trigger", Config: map[string]interface{}{"host": "imap.example.com", "username": "bot"}},
+			{ID: "inbound", Type: "email_trigger", Config: map[string]interface{}{"host": "imap.example.com", "username": "bot", "webhook_secret": "s3cret"}},
+		}}}
+	require.NoError(t, db.CreateWorkflow(ctx, trigger))
+	plain := &models.Workflow{Name: "manual", UserID: userID, Status: models.WorkflowStatusActive,
+		Definition: models.WorkflowDefinition{Nodes: []models.Node{{ID: "start", Type: "manual_trigger"}}}}
+	require.NoError(t, db.CreateWorkflow(ctx, plain))
+
+	path := "/api/v1/webhooks/email/" + trigger.ID.String()
+	assert.Equal(t, 404, postEmail(router, "/api/v1/webhooks/email/"+plain.ID.String(), "s3cret").Code, "no email trigger")
+	assert.Equal(t, 401, postEmail(router, path, "").Code, "nodes without a secret accept nothing")
+	assert.Equal(t, 401, postEmail(router, path, "wrong").Code)
+
+	size, err := queue.Size(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, size)
+
+	rec := postEmail(router, path, "s3cret")
+	require.Equal(t, 202, rec.Code, rec.Body.String())
+	assert.Equal(t, 202, postEmail(router, path+"?token=s3cret", "").Code)
+	size, err = queue.Size(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), size)
+}
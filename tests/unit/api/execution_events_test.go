@@ -0,0 +1,144 @@
+package api_test
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nuumz/f1ow/internal/api"
+	"github.com/nuumz/f1ow/internal/engine"
+	"github.com/nuumz/f1ow/internal/models"
+	"github.com/nuumz/f1ow/internal/storage"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func executionEventsServer(t *testing.T) (*httptest.Server, *engine.Engine, *storage.DB, uuid.UUID) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	db, err := storage.NewDB("sqlite://" + filepath.Join(t.TempDir(), "f1ow.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	_, err = db.Migrate(context.Background())
+	require.NoError(t, err)
+
+	userID := uuid.New()
+	_, err = db.Exec(`INSERT INTO users (id, email) VALUES ($1, $2)`, userID, "events@example.com")
+	require.NoError(t, err)
+	workflow := &models.Workflow{Name: "events", UserID: userID, Status: models.WorkflowStatusActive}
+	require.NoError(t, db.CreateWorkflow(context.Background(), workflow))
+
+	eng := engine.NewEngine(db, nil)
+	router := gin.New()
+	router.GET("/api/v1/executions/:id/events", api.StreamExecutionEvents(eng, db))
+	server := httptest.NewServer(router)
+	t.Cleanup(server.Close)
+	return server, eng, db, workflow.ID
+}
+
+func createExecution(t *testing.T, db *storage.DB, workflowID uuid.UUID, status models.ExecutionStatus) *models.Execution {
+	execution := &models.Execution{WorkflowID: workflowID, Status: status}
+	require.NoError(t, db.CreateExecution(context.Background(), execution))
+	return execution
+}
+
+func TestStreamExecutionEvents_StreamsLocalEventsUntilFinished(t *testing.T) {
+	server, eng, db, workflowID := executionEventsServer(t)
+	execution := createExecution(t, db, workflowID, models.ExecutionStatusRunning)
+
+	resp, err := http.Get(server.URL + "/api/v1/executions/" + execution.ID.String() + "/events")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, 200, resp.StatusCode)
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+	events := make(chan string, 16)
+	go func() {
+		defer close(events)
+		scanner := bufio.NewScanner(resp.Body)
+		for scanner.Scan() {
+			if line := scanner.Text(); strings.HasPrefix(line, "event: ") {
+				events <- strings.TrimPrefix(line, "event: ")
+			}
+		}
+	}()
+
+	publish := func(eventType engine.EventType, executionID string) {
+		eng.PublishEvent(context.Background(), engine.Event{
+			Type: eventType, WorkflowID: workflowID.String(), ExecutionID: executionID, NodeID: "fetch", Time: time.Now(),
+		})
+	}
+
+	// Events published before the stream subscribes are not replayed, so
+	// publish until one arrives
+	var first string
+	require.Eventually(t, func() bool {
+		publish(engine.EventNodeStarted, execution.ID.String())
+		select {
+		case first = <-events:
+			return true
+		case <-time.After(10 * time.Millisecond):
+			return false
+		}
+	}, 5*time.Second, time.Millisecond)
+	assert.Equal(t, string(engine.EventNodeStarted), first)
+
+	// Other executions' events are filtered out and the final event ends
+	// the stream
+	publish(engine.EventNodeCompleted, uuid.NewString())
+	publish(engine.EventExecutionCompleted, execution.ID.String())
+
+	var rest []string
+	timeout := time.After(5 * time.Second)
+	for done := false; !done; {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				done = true
+				break
+			}
+			rest = append(rest, event)
+		case <-timeout:
+			t.Fatal("the stream did not end after the final event")
+		}
+	}
+	assert.Equal(t, string(engine.EventExecutionCompleted), rest[len(rest)-1])
+	assert.NotContains(t, rest, string(engine.EventNodeCompleted))
+}
+
+func TestStreamExecutionEvents_EndsForFinishedExecutions(t *testing.T) {
+	server, _, db, workflowID := executionEventsServer(t)
+	execution := createExecution(t, db, workflowID, models.ExecutionStatusCompleted)
+
+	client := &http.Client{Timeout: 5 * time.Second}
+	resp, err := client.Get(server.URL + "/api/v1/executions/" + execution.ID.String() + "/events")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, 200, resp.StatusCode)
+
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		assert.False(t, strings.HasPrefix(scanner.Text(), "event: "), scanner.Text())
+	}
+	assert.NoError(t, scanner.Err())
+}
+
+func TestStreamExecutionEvents_UnknownExecution(t *testing.T) {
+	server, _, _, _ := executionEventsServer(t)
+
+	for path, status := range map[string]int{uuid.NewString(): 404, "not-a-uuid": 400} {
+		resp, err := http.Get(server.URL + "/api/v1/executions/" + path + "/events")
+		require.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, status, resp.StatusCode, path)
+	}
+}
@@ -0,0 +1,93 @@
+package api_test
+
+import (
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/nuumz/f1ow/internal/api"
+	"github.com/nuumz/f1ow/internal/engine"
+	"github.com/nuumz/f1ow/internal/storage"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type readiness struct {
+	Status string `json:"status"`
+	Checks map[string]struct {
+		Status string                 `json:"status"`
+		Error  string                 `json:"error"`
+		Detail map[string]interface{} `json:"detail"`
+	} `json:"checks"`
+}
+
+func healthRouter(t *testing.T, migrate bool, config api.HealthConfig) (*gin.Engine, *engine.MemoryQueue) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	db, err := storage.NewDB("sqlite://" + filepath.Join(t.TempDir(), "f1ow.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	if migrate {
+		_, err = db.Migrate(context.Background())
+		require.NoError(t, err)
+	}
+
+	queue := engine.NewMemoryQueue(nil)
+	eng := engine.NewEngine(db, nil, engine.WithQueue(queue))
+	router := gin.New()
+	router.GET("/healthz", api.Liveness())
+	router.GET("/readyz", api.Readiness(db, nil, eng, config))
+	return router, queue
+}
+
+func readReadiness(t *testing.T, router *gin.Engine) (int, readiness) {
+	t.Helper()
+	w := request(router, "GET", "/readyz", "")
+	var body readiness
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	return w.Code, body
+}
+
+func TestReadiness_Ready(t *testing.T) {
+	router, _ := healthRouter(t, true, api.HealthConfig{})
+
+	assert.Equal(t, 200, request(router, "GET", "/healthz", "").Code)
+
+	code, body := readReadiness(t, router)
+	assert.Equal(t, 200, code)
+	assert.Equal(t, "ready", body.Status)
+	for _, name := range []string{"database", "migrations", "queue"} {
+		assert.Equal(t, "ok", body.Checks[name].Status, name)
+	}
+	assert.Equal(t, float64(0), body.Checks["migrations"].Detail["pending"])
+}
+
+func TestReadiness_PendingMigrations(t *testing.T) {
+	router, _ := healthRouter(t, false, api.HealthConfig{})
+
+	code, body := readReadiness(t, router)
+	assert.Equal(t, 503, code)
+	assert.Equal(t, "not_ready", body.Status)
+	assert.Equal(t, "ok", body.Checks["database"].Status)
+	assert.Equal(t, "fail", body.Checks["migrations"].Status)
+	assert.Contains(t, body.Checks["migrations"].Error, "migrations not applied")
+
+	// Liveness does not depend on the database
+	assert.Equal(t, 200, request(router, "GET", "/healthz", "").Code)
+}
+
+func TestReadiness_QueueLag(t *testing.T) {
+	router, queue := healthRouter(t, true, api.HealthConfig{MaxQueueLag: time.Millisecond})
+	require.NoError(t, queue.Enqueue(context.Background(), &engine.Job{ID: "waiting"}))
+	time.Sleep(20 * time.Millisecond)
+
+	code, body := readReadiness(t, router)
+	assert.Equal(t, 503, code)
+	assert.Equal(t, "fail", body.Checks["queue"].Status)
+	assert.Equal(t, float64(1), body.Checks["queue"].Detail["pending"])
+}
@@ -0,0 +1,31 @@
+package api_test
+
+import (
+	"testing"
+
+	"github.com/nuumz/f1ow/internal/api"
+	"github.com/nuumz/f1ow/internal/engine"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecordMetrics_LabelsByRoutePattern(t *testing.T) {
+	metrics := engine.NewEngine(nil, nil).Metrics()
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(api.RecordMetrics(metrics))
+	router.GET("/api/v1/workflows/:id", func(c *gin.Context) { c.Status(204) })
+
+	matched := metrics.APIRequestTotal.WithLabelValues("GET", "/api/v1/workflows/:id", "204")
+	unmatched := metrics.APIRequestTotal.WithLabelValues("GET", "unmatched", "404")
+	before, beforeUnmatched := testutil.ToFloat64(matched), testutil.ToFloat64(unmatched)
+
+	request(router, "GET", "/api/v1/workflows/1", "")
+	request(router, "GET", "/api/v1/workflows/2", "")
+	request(router, "GET", "/wp-admin", "")
+
+	assert.Equal(t, before+2, testutil.ToFloat64(matched))
+	assert.Equal(t, beforeUnmatched+1, testutil.ToFloat64(unmatched))
+}
@@ -0,0 +1,62 @@
+package api_test
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+
+	"github.com/nuumz/f1ow/internal/api"
+	"github.com/nuumz/f1ow/internal/openapi"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func openAPIDocument() *openapi.Document {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	api.SetupRoutes(router, nil, nil, nil, api.RouterConfig{})
+	return api.OpenAPISpec(router.Routes())
+}
+
+func TestOpenAPISpec_DescribesRoutes(t *testing.T) {
+	doc := openAPIDocument()
+
+	get := (*doc.Paths["/api/v1/workflows/{id}"])["get"]
+	require.NotNil(t, get)
+	assert.Equal(t, "GetWorkflow", get.OperationID)
+	assert.Equal(t, "path", get.Parameters[0].In)
+	assert.Equal(t, "#/components/schemas/Workflow", get.Responses["200"].Content["application/json"].Schema.Ref)
+	assert.NotEmpty(t, get.Security)
+
+	workflow := doc.Components.Schemas["Workflow"]
+	require.NotNil(t, workflow)
+	assert.Equal(t, "uuid", workflow.Properties["id"].Format)
+	assert.Equal(t, "date-time", workflow.Properties["created_at"].Format)
+	assert.True(t, workflow.Properties["project_id"].Nullable)
+
+	webhook := (*doc.Paths["/api/v1/webhooks/github/{id}"])["post"]
+	require.NotNil(t, webhook)
+	assert.Empty(t, webhook.Security)
+
+	assert.NotContains(t, doc.Paths, "/api/v1/openapi.json")
+}
+
+// The checked-in spec and client must match the routes; run
+// go generate ./pkg/client to refresh them
+func TestOpenAPISpec_GeneratedFilesAreCurrent(t *testing.T) {
+	doc := openAPIDocument()
+
+	spec, err := json.MarshalIndent(doc, "", "  ")
+	require.NoError(t, err)
+	current, err := os.ReadFile("../../../api/openapi.json")
+	require.NoError(t, err)
+	assert.Equal(t, string(append(spec, '\n')), string(current))
+
+	client, err := openapi.GenerateClient(doc, "client")
+	require.NoError(t, err)
+	current, err = os.ReadFile("../../../pkg/client/client_gen.go")
+	require.NoError(t, err)
+	assert.Equal(t, string(client), string(current))
+}
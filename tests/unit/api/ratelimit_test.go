@@ -0,0 +1,107 @@
+package api_test
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/nuumz/f1ow/internal/api"
+	"github.com/nuumz/f1ow/internal/models"
+	"github.com/nuumz/f1ow/internal/ratelimit"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func rateLimitRouter(store memoryAPIKeys) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(api.Authenticate(api.AuthConfig{APIKeys: store}), api.RateLimit(api.RateLimitConfig{
+		Backend: ratelimit.NewMemoryBackend(),
+		Limit:   2,
+		Window:  time.Minute,
+		Exclude: api.DefaultRateLimitExclude,
+	}))
+	router.GET("/health", func(c *gin.Context) { c.Status(200) })
+	router.GET("/api/v1/workflows", func(c *gin.Context) { c.Status(200) })
+	return router
+}
+
+// forwardedRouter limits anonymous requests to one a minute on the router
+// the server builds
+func forwardedRouter(t *testing.T, trustedProxies []string) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router, err := api.NewRouter(trustedProxies)
+	require.NoError(t, err)
+	router.Use(api.Authenticate(api.AuthConfig{APIKeys: memoryAPIKeys{}}), api.RateLimit(api.RateLimitConfig{
+		Backend: ratelimit.NewMemoryBackend(),
+		Limit:   1,
+		Window:  time.Minute,
+	}))
+	router.GET("/api/v1/workflows", func(c *gin.Context) { c.Status(200) })
+	return router
+}
+
+func forwardedRequest(router *gin.Engine, forwardedFor string) int {
+	req := httptest.NewRequest("GET", "/api/v1/workflows", nil)
+	req.Header.Set("X-Forwarded-For", forwardedFor)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	return rec.Code
+}
+
+func TestRateLimit_IgnoresSpoofedForwardedFor(t *testing.T) {
+	router := forwardedRouter(t, nil)
+
+	// Without trusted proxies a new X-Forwarded-For does not buy a new limit
+	assert.Equal(t, 200, forwardedRequest(router, "203.0.113.1"))
+	assert.Equal(t, 429, forwardedRequest(router, "203.0.113.2"))
+}
+
+func TestRateLimit_TrustedProxyForwardsClientAddress(t *testing.T) {
+	// httptest requests come from 192.0.2.1
+	router := forwardedRouter(t, []string{"192.0.2.0/24"})
+
+	assert.Equal(t, 200, forwardedRequest(router, "203.0.113.1"))
+	assert.Equal(t, 429, forwardedRequest(router, "203.0.113.1"))
+	assert.Equal(t, 200, forwardedRequest(router, "203.0.113.2"))
+}
+
+func TestRateLimit_HeadersAndRejection(t *testing.T) {
+	router := rateLimitRouter(memoryAPIKeys{})
+
+	w := request(router, "GET", "/api/v1/workflows", "")
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, "2", w.Header().Get("X-RateLimit-Limit"))
+	assert.Equal(t, "1", w.Header().Get("X-RateLimit-Remaining"))
+	assert.NotEmpty(t, w.Header().Get("X-RateLimit-Reset"))
+
+	assert.Equal(t, 200, request(router, "GET", "/api/v1/workflows", "").Code)
+
+	w = request(router, "GET", "/api/v1/workflows", "")
+	assert.Equal(t, 429, w.Code)
+	assert.Equal(t, "0", w.Header().Get("X-RateLimit-Remaining"))
+	assert.NotEmpty(t, w.Header().Get("Retry-After"))
+}
+
+func TestRateLimit_KeysAreLimitedSeparately(t *testing.T) {
+	store := memoryAPIKeys{}
+	router := rateLimitRouter(store)
+	key := newAPIKey(t, store, models.APIKey{Name: "ci"})
+
+	request(router, "GET", "/api/v1/workflows", "")
+	request(router, "GET", "/api/v1/workflows", "")
+	assert.Equal(t, 429, request(router, "GET", "/api/v1/workflows", "").Code)
+	assert.Equal(t, 200, request(router, "GET", "/api/v1/workflows", key).Code)
+}
+
+func TestRateLimit_ExcludedPaths(t *testing.T) {
+	router := rateLimitRouter(memoryAPIKeys{})
+
+	for i := 0; i < 5; i++ {
+		w := request(router, "GET", "/health", "")
+		assert.Equal(t, 200, w.Code)
+		assert.Empty(t, w.Header().Get("X-RateLimit-Limit"))
+	}
+}
@@ -0,0 +1,108 @@
+package api_test
+
+import (
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+
+	"github.com/nuumz/f1ow/internal/api"
+	"github.com/nuumz/f1ow/internal/models"
+	"github.com/nuumz/f1ow/internal/schedules"
+	"github.com/nuumz/f1ow/internal/storage"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func scheduleRouter(t *testing.T) (*gin.Engine, uuid.UUID) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	db, err := storage.NewDB("sqlite://" + filepath.Join(t.TempDir(), "f1ow.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	_, err = db.Migrate(context.Background())
+	require.NoError(t, err)
+
+	userID := uuid.New()
+	_, err = db.Exec(`INSERT INTO users (id, email) VALUES ($1, $2)`, userID, "schedules@example.com")
+	require.NoError(t, err)
+	workflow := &models.Workflow{Name: "nightly", UserID: userID, Status: models.WorkflowStatusActive}
+	require.NoError(t, db.CreateWorkflow(context.Background(), workflow))
+
+	manager := schedules.NewManager(db, func(ctx context.Context, workflowID string, input map[string]interface{}) (*models.Execution, error) {
+		return &models.Execution{ID: uuid.New(), WorkflowID: uuid.MustParse(workflowID), Status: models.ExecutionStatusPending, Input: input}, nil
+	}, nil)
+
+	router := gin.New()
+	router.GET("/api/v1/workflows/:id/schedules", api.GetWorkflowSchedules(manager))
+	router.GET("/api/v1/schedules", api.GetSchedules(manager))
+	router.POST("/api/v1/schedules", api.CreateSchedule(manager, db))
+	router.GET("/api/v1/schedules/preview", api.PreviewSchedule())
+	router.POST("/api/v1/schedules/:id/pause", api.SetScheduleActive(manager, false))
+	router.POST("/api/v1/schedules/:id/run-now", api.RunScheduleNow(manager))
+	return router, workflow.ID
+}
+
+func TestSchedules_CreatePauseRunNow(t *testing.T) {
+	router, workflowID := scheduleRouter(t)
+
+	rec := postJSON(router, "/api/v1/schedules", `{"workflow_id": "`+uuid.NewString()+`", "cron_expr": "@daily"}`)
+	assert.Equal(t, 404, rec.Code, "unknown workflow")
+	rec = postJSON(router, "/api/v1/schedules", `{"workflow_id": "`+workflowID.String()+`", "cron_expr": "61 * * * *"}`)
+	assert.Equal(t, 400, rec.Code, rec.Body.String())
+
+	rec = postJSON(router, "/api/v1/schedules", `{"workflow_id": "`+workflowID.String()+`", "name": "Nightly",
+		"cron_expr": "0 2 * * *", "timezone": "UTC", "input": {"full": true}}`)
+	require.Equal(t, 201, rec.Code, rec.Body.String())
+	var created models.Schedule
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &created))
+	assert.True(t, created.IsActive)
+	require.NotNil(t, created.NextRunAt)
+
+	rec = postJSON(router, "/api/v1/schedules/"+created.ID.String()+"/pause", `{}`)
+	require.Equal(t, 200, rec.Code)
+	var paused models.Schedule
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &paused))
+	assert.False(t, paused.IsActive)
+	assert.Nil(t, paused.NextRunAt)
+
+	rec = postJSON(router, "/api/v1/schedules/"+created.ID.String()+"/run-now", `{}`)
+	require.Equal(t, 202, rec.Code)
+	var execution models.Execution
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &execution))
+	assert.Equal(t, workflowID, execution.WorkflowID)
+	assert.Equal(t, map[string]interface{}{"full": true}, execution.Input)
+
+	rec = getPath(router, "/api/v1/workflows/"+workflowID.String()+"/schedules")
+	require.Equal(t, 200, rec.Code)
+	var list []models.Schedule
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &list))
+	require.Len(t, list, 1)
+	assert.Equal(t, "Nightly", list[0].Name)
+	assert.Equal(t, 200, getPath(router, "/api/v1/schedules?workflow_id="+workflowID.String()).Code)
+	assert.Equal(t, 400, getPath(router, "/api/v1/schedules?workflow_id=nope").Code)
+	assert.Equal(t, 404, postJSON(router, "/api/v1/schedules/"+uuid.NewString()+"/run-now", `{}`).Code)
+}
+
+func TestPreviewSchedule(t *testing.T) {
+	router, _ := scheduleRouter(t)
+
+	rec := getPath(router, "/api/v1/schedules/preview?cron_expr=*/10+*+*+*+*")
+	require.Equal(t, 200, rec.Code, rec.Body.String())
+	var preview struct {
+		Timezone string   `json:"timezone"`
+		Next     []string `json:"next"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &preview))
+	assert.Equal(t, "UTC", preview.Timezone)
+	assert.Len(t, preview.Next, 5)
+
+	assert.Equal(t, 200, getPath(router, "/api/v1/schedules/preview?cron_expr=@hourly&count=3").Code)
+	assert.Equal(t, 400, getPath(router, "/api/v1/schedules/preview").Code)
+	assert.Equal(t, 400, getPath(router, "/api/v1/schedules/preview?cron_expr=@hourly&count=0").Code)
+	assert.Equal(t, 400, getPath(router, "/api/v1/schedules/preview?cron_expr=@never").Code)
+}
@@ -0,0 +1,94 @@
+package api_test
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/nuumz/f1ow/internal/api"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func corsRouter(config api.CORSConfig) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(api.CORS(config))
+	router.GET("/api/v1/workflows", func(c *gin.Context) { c.Status(200) })
+	return router
+}
+
+func corsRequest(router *gin.Engine, method, origin string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, "/api/v1/workflows", nil)
+	if origin != "" {
+		req.Header.Set("Origin", origin)
+	}
+	if method == "OPTIONS" {
+		req.Header.Set("Access-Control-Request-Method", "GET")
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestCORS_ListedOrigins(t *testing.T) {
+	router := corsRouter(api.CORSConfig{
+		AllowedOrigins:   []string{"https://app.example.com", "https://*.example.org"},
+		AllowedMethods:   []string{"GET", "POST"},
+		AllowedHeaders:   []string{"Authorization"},
+		ExposedHeaders:   []string{"Retry-After"},
+		AllowCredentials: true,
+		MaxAge:           10 * time.Minute,
+	})
+
+	w := corsRequest(router, "GET", "https://app.example.com")
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, "https://app.example.com", w.Header().Get("Access-Control-Allow-Origin"))
+	assert.Equal(t, "true", w.Header().Get("Access-Control-Allow-Credentials"))
+	assert.Equal(t, "Retry-After", w.Header().Get("Access-Control-Expose-Headers"))
+	assert.Equal(t, "Origin", w.Header().Get("Vary"))
+
+	w = corsRequest(router, "OPTIONS", "https://ci.example.org")
+	assert.Equal(t, 204, w.Code)
+	assert.Equal(t, "https://ci.example.org", w.Header().Get("Access-Control-Allow-Origin"))
+	assert.Equal(t, "GET, POST", w.Header().Get("Access-Control-Allow-Methods"))
+	assert.Equal(t, "Authorization", w.Header().Get("Access-Control-Allow-Headers"))
+	assert.Equal(t, "600", w.Header().Get("Access-Control-Max-Age"))
+
+	w = corsRequest(router, "GET", "https://evil.example.net")
+	assert.Equal(t, 200, w.Code)
+	assert.Empty(t, w.Header().Get("Access-Control-Allow-Origin"))
+	assert.Equal(t, 403, corsRequest(router, "OPTIONS", "https://evil.example.net").Code)
+}
+
+func TestCORS_AnyOrigin(t *testing.T) {
+	router := corsRouter(api.CORSConfig{AllowedOrigins: []string{"*"}, AllowedMethods: []string{"GET"}})
+
+	w := corsRequest(router, "GET", "https://anywhere.test")
+	assert.Equal(t, "*", w.Header().Get("Access-Control-Allow-Origin"))
+	assert.Empty(t, w.Header().Get("Access-Control-Allow-Credentials"))
+
+	w = corsRequest(router, "GET", "")
+	assert.Equal(t, 200, w.Code)
+	assert.Empty(t, w.Header().Get("Access-Control-Allow-Origin"))
+}
+
+func TestSecurityHeaders(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(api.SecurityHeaders(api.SecurityHeadersConfig{
+		HSTSMaxAge:            365 * 24 * time.Hour,
+		HSTSIncludeSubdomains: true,
+		ContentTypeNosniff:    true,
+		FrameOptions:          "DENY",
+	}))
+	router.GET("/health", func(c *gin.Context) { c.Status(200) })
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/health", nil))
+	assert.Equal(t, "max-age=31536000; includeSubDomains", w.Header().Get("Strict-Transport-Security"))
+	assert.Equal(t, "nosniff", w.Header().Get("X-Content-Type-Options"))
+	assert.Equal(t, "DENY", w.Header().Get("X-Frame-Options"))
+	assert.Empty(t, w.Header().Get("Referrer-Policy"))
+}
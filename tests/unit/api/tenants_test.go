@@ -0,0 +1,84 @@
+package api_test
+
+import (
+	"context"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nuumz/f1ow/internal/api"
+	"github.com/nuumz/f1ow/internal/auth"
+	"github.com/nuumz/f1ow/internal/models"
+	"github.com/nuumz/f1ow/internal/storage"
+	"github.com/nuumz/f1ow/internal/tenant"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func adminToken(t *testing.T, verifier *auth.Verifier, tenantID uuid.UUID) string {
+	t.Helper()
+	token, err := verifier.Sign(&auth.Claims{
+		TenantID: tenantID.String(),
+		Role:     models.RoleAdmin,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   uuid.New().String(),
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+		},
+	})
+	require.NoError(t, err)
+	return token
+}
+
+func putJSON(router *gin.Engine, path, token, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest("PUT", path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestTenantRoutes_RequireOperator(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db, err := storage.NewDB("sqlite://" + filepath.Join(t.TempDir(), "f1ow.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	_, err = db.Migrate(context.Background())
+	require.NoError(t, err)
+
+	acme := &models.Tenant{Name: "acme"}
+	require.NoError(t, db.CreateTenant(context.Background(), acme))
+	other := &models.Tenant{Name: "other"}
+	require.NoError(t, db.CreateTenant(context.Background(), other))
+
+	verifier := auth.NewVerifier("secret")
+	router := gin.New()
+	api.SetupRoutes(router, nil, db, nil, api.RouterConfig{Auth: api.AuthConfig{Verifier: verifier}})
+
+	policy := `{"allow": ["10.0.0.0/8"]}`
+	acmeAdmin := adminToken(t, verifier, acme.ID)
+
+	// A tenant admin can change neither another tenant nor its own
+	rec := putJSON(router, "/api/v1/tenants/"+other.ID.String()+"/network-policy", acmeAdmin, policy)
+	assert.Equal(t, 403, rec.Code)
+	rec = putJSON(router, "/api/v1/tenants/"+other.ID.String()+"/quotas", acmeAdmin, `{"quotas": {"max_active_workflows": 1000}}`)
+	assert.Equal(t, 403, rec.Code)
+	rec = putJSON(router, "/api/v1/tenants/"+acme.ID.String()+"/quotas", acmeAdmin, `{"quotas": {"max_active_workflows": 1000}}`)
+	assert.Equal(t, 403, rec.Code)
+	rec = putJSON(router, "/api/v1/plans/team", acmeAdmin, `{"quotas": {}}`)
+	assert.Equal(t, 403, rec.Code)
+
+	stored, err := db.TenantNetworkPolicy(context.Background(), other.ID)
+	require.NoError(t, err)
+	assert.Nil(t, stored)
+
+	// Admins of the default tenant operate the platform
+	rec = putJSON(router, "/api/v1/tenants/"+other.ID.String()+"/network-policy", adminToken(t, verifier, tenant.DefaultID), policy)
+	assert.Equal(t, 200, rec.Code, rec.Body.String())
+}
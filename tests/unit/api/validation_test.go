@@ -0,0 +1,173 @@
+package api_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/nuumz/f1ow/internal/api"
+	"github.com/nuumz/f1ow/internal/engine"
+	"github.com/nuumz/f1ow/internal/models"
+	"github.com/nuumz/f1ow/internal/nodes"
+	"github.com/nuumz/f1ow/internal/storage"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func validationRouter(t *testing.T) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	eng := engine.NewEngine(nil, nil)
+	require.NoError(t, eng.RegisterNode("http", nodes.NewHTTPNode(nil)))
+
+	router := gin.New()
+	router.POST("/api/v1/nodes/:type/validate", api.ValidateNodeConfig(eng))
+	router.POST("/api/v1/workflows", api.CreateWorkflow(eng, nil))
+	return router
+}
+
+func postJSON(router *gin.Engine, path, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest("POST", path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	return rec
+}
+
+type validationBody struct {
+	Valid  bool                `json:"valid"`
+	Error  string              `json:"error"`
+	Errors []engine.FieldError `json:"errors"`
+}
+
+func TestValidateNodeConfig(t *testing.T) {
+	router := validationRouter(t)
+
+	w := postJSON(router, "/api/v1/nodes/http/validate", `{"url": "https://example.com", "method": "GET"}`)
+	require.Equal(t, 200, w.Code)
+	var body validationBody
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.True(t, body.Valid)
+	assert.Empty(t, body.Errors)
+
+	w = postJSON(router, "/api/v1/nodes/http/validate", `{"timeout": "soon"}`)
+	require.Equal(t, 200, w.Code)
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.False(t, body.Valid)
+	assert.Contains(t, body.Errors, engine.FieldError{Field: "url", Message: "is required"})
+
+	assert.Equal(t, 404, postJSON(router, "/api/v1/nodes/missing/validate", `{}`).Code)
+	assert.Equal(t, 400, postJSON(router, "/api/v1/nodes/http/validate", `[]`).Code)
+}
+
+func TestCreateWorkflow_RejectsInvalidNodeConfig(t *testing.T) {
+	router := validationRouter(t)
+
+	w := postJSON(router, "/api/v1/workflows", `{
+		"name": "broken",
+		"definition": {"nodes": [{"id": "fetch", "type": "http", "config": {}}]}
+	}`)
+	require.Equal(t, 422, w.Code)
+	var body validationBody
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Equal(t, "invalid workflow definition", body.Error)
+	assert.Equal(t, []engine.FieldError{{NodeID: "fetch", Field: "url", Message: "is required"}}, body.Errors)
+}
+
+func TestCreateWorkflow_RejectsLintErrors(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	eng := engine.NewEngine(nil, nil, engine.WithEnvironment("production"))
+	require.NoError(t, eng.RegisterNode("http", nodes.NewHTTPNode(nil)))
+	router := gin.New()
+	router.POST("/api/v1/workflows", api.CreateWorkflow(eng, nil))
+
+	w := postJSON(router, "/api/v1/workflows", `{
+		"name": "insecure",
+		"definition": {"nodes": [{"id": "fetch", "type": "http", "name": "Fetch", "config": {"url": "https://example.com", "ignore_ssl_issues": true}}]}
+	}`)
+	require.Equal(t, 422, w.Code)
+	var body struct {
+		Error  string             `json:"error"`
+		Issues []engine.LintIssue `json:"issues"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Equal(t, "workflow has lint errors", body.Error)
+	require.Len(t, body.Issues, 1)
+	assert.Equal(t, engine.LintInsecureTLS, body.Issues[0].Rule)
+	assert.Equal(t, "fetch", body.Issues[0].NodeID)
+}
+
+func TestLintWorkflow(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	eng := engine.NewEngine(nil, nil)
+	require.NoError(t, eng.RegisterNode("http", nodes.NewHTTPNode(nil)))
+	repo := storage.NewMemoryRepository()
+	workflow := &models.Workflow{Name: "fetch", Definition: models.WorkflowDefinition{Nodes: []models.Node{
+		{ID: "fetch", Type: "http", Name: "Fetch", Config: map[string]interface{}{"url": "https://example.com", "ignore_ssl_issues": true}},
+	}}}
+	require.NoError(t, repo.CreateWorkflow(context.Background(), workflow))
+	router := gin.New()
+	router.POST("/api/v1/workflows/:id/lint", api.LintWorkflow(eng, repo))
+
+	var body struct {
+		Valid  bool               `json:"valid"`
+		Issues []engine.LintIssue `json:"issues"`
+	}
+	w := postJSON(router, "/api/v1/workflows/"+workflow.ID.String()+"/lint", "")
+	require.Equal(t, 200, w.Code)
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.True(t, body.Valid)
+	assert.Empty(t, body.Issues)
+
+	w = postJSON(router, "/api/v1/workflows/"+workflow.ID.String()+"/lint?environment=production", "")
+	require.Equal(t, 200, w.Code)
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.False(t, body.Valid)
+	require.Len(t, body.Issues, 1)
+	assert.Equal(t, engine.LintInsecureTLS, body.Issues[0].Rule)
+
+	assert.Equal(t, 404, postJSON(router, "/api/v1/workflows/"+uuid.NewString()+"/lint", "").Code)
+}
+
+func TestEvaluateExpression(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	repo := storage.NewMemoryRepository()
+	execution := &models.Execution{
+		Input: map[string]interface{}{"customer": "acme"},
+		Context: models.ExecutionContext{NodeExecutions: map[string]models.NodeExecution{
+			"fetch": {NodeID: "fetch", Output: map[string]interface{}{"total": 42.0}},
+		}},
+	}
+	require.NoError(t, repo.CreateExecution(context.Background(), execution))
+	router := gin.New()
+	router.POST("/api/v1/expressions/evaluate", api.EvaluateExpression(engine.NewEngine(nil, nil), repo))
+
+	var result engine.ExpressionResult
+	w := postJSON(router, "/api/v1/expressions/evaluate", `{"expression": "{{body.name}}", "payload": {"body": {"name": "Ada"}}}`)
+	require.Equal(t, 200, w.Code)
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &result))
+	assert.Equal(t, "Ada", result.Result)
+
+	w = postJSON(router, "/api/v1/expressions/evaluate", `{"expression": "{{input.customer}}: {{nodes.fetch.total}}", "execution_id": "`+execution.ID.String()+`"}`)
+	require.Equal(t, 200, w.Code)
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &result))
+	assert.Equal(t, "acme: 42", result.Result)
+
+	w = postJSON(router, "/api/v1/expressions/evaluate", `{"expression": "{{total}}", "execution_id": "`+execution.ID.String()+`", "node_id": "fetch"}`)
+	require.Equal(t, 200, w.Code)
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &result))
+	assert.Equal(t, 42.0, result.Result)
+
+	w = postJSON(router, "/api/v1/expressions/evaluate", `{"expression": "{{total"}`)
+	require.Equal(t, 200, w.Code)
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &result))
+	assert.NotEmpty(t, result.Error)
+
+	assert.Equal(t, 400, postJSON(router, "/api/v1/expressions/evaluate", `{}`).Code)
+	assert.Equal(t, 404, postJSON(router, "/api/v1/expressions/evaluate", `{"expression": "{{a}}", "execution_id": "`+uuid.NewString()+`"}`).Code)
+	assert.Equal(t, 404, postJSON(router, "/api/v1/expressions/evaluate", `{"expression": "{{a}}", "execution_id": "`+execution.ID.String()+`", "node_id": "missing"}`).Code)
+}
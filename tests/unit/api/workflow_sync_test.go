@@ -0,0 +1,137 @@
+package api_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/nuumz/f1ow/internal/api"
+	"github.com/nuumz/f1ow/internal/auth"
+	"github.com/nuumz/f1ow/internal/engine"
+	"github.com/nuumz/f1ow/internal/models"
+	"github.com/nuumz/f1ow/internal/nodes"
+	"github.com/nuumz/f1ow/internal/storage"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type syncBody struct {
+	DryRun  bool `json:"dry_run"`
+	Changes []struct {
+		Name    string `json:"name"`
+		ID      string `json:"id"`
+		Action  string `json:"action"`
+		Changes []struct {
+			Path string `json:"path"`
+		} `json:"changes"`
+	} `json:"changes"`
+}
+
+func syncRouter(t *testing.T) (*gin.Engine, *storage.DB) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	db, err := storage.NewDB("sqlite://" + filepath.Join(t.TempDir(), "f1ow.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	_, err = db.Migrate(context.Background())
+	require.NoError(t, err)
+
+	// Workflows reference their creator
+	userID := uuid.New()
+	_, err = db.Exec(`INSERT INTO users (id, email) VALUES ($1, $2)`, userID, "sync@example.com")
+	require.NoError(t, err)
+
+	eng := engine.NewEngine(db, nil)
+	require.NoError(t, eng.RegisterNode("set", nodes.NewSetNode()))
+
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		claims := &auth.Claims{RegisteredClaims: jwt.RegisteredClaims{Subject: userID.String()}}
+		c.Request = c.Request.WithContext(auth.WithClaims(c.Request.Context(), claims))
+	})
+	router.POST("/api/v1/workflows/sync", api.SyncWorkflows(eng, db))
+	return router, db
+}
+
+func syncWorkflows(t *testing.T, router *gin.Engine, body string) syncBody {
+	t.Helper()
+	rec := postJSON(router, "/api/v1/workflows/sync", body)
+	require.Equal(t, 200, rec.Code, rec.Body.String())
+	var result syncBody
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &result))
+	return result
+}
+
+const syncDefinition = `{"start_node_id": "s", "nodes": [{"id": "s", "type": "set", "config": {"operations": [{"operation": "set", "path": "x", "value": %s}]}}]}`
+
+func syncWorkflow(name, status, value string) string {
+	return `{"name": "` + name + `", "status": "` + status + `", "definition": ` + fmt.Sprintf(syncDefinition, value) + `}`
+}
+
+func TestSyncWorkflows_CreatesUpdatesAndDeactivates(t *testing.T) {
+	router, db := syncRouter(t)
+	ctx := context.Background()
+
+	result := syncWorkflows(t, router, `{"source": "repo", "dry_run": true, "workflows": [`+syncWorkflow("a", "active", "1")+`]}`)
+	require.Len(t, result.Changes, 1)
+	assert.Equal(t, "create", result.Changes[0].Action)
+	workflows, _, err := db.ListWorkflows(ctx, storage.WorkflowListOptions{})
+	require.NoError(t, err)
+	assert.Empty(t, workflows, "a dry run changes nothing")
+
+	result = syncWorkflows(t, router, `{"source": "repo", "workflows": [`+syncWorkflow("a", "active", "1")+`, `+syncWorkflow("b", "active", "1")+`]}`)
+	require.Len(t, result.Changes, 2)
+	idA := result.Changes[0].ID
+	require.NotEmpty(t, idA)
+
+	// Unchanged workflows stay as they are
+	result = syncWorkflows(t, router, `{"source": "repo", "workflows": [`+syncWorkflow("a", "active", "1")+`, `+syncWorkflow("b", "active", "1")+`]}`)
+	assert.Equal(t, "unchanged", result.Changes[0].Action)
+	assert.Equal(t, "unchanged", result.Changes[1].Action)
+
+	// Changing a and dropping b updates a and deactivates b
+	result = syncWorkflows(t, router, `{"source": "repo", "workflows": [`+syncWorkflow("a", "active", "2")+`]}`)
+	require.Len(t, result.Changes, 2)
+	assert.Equal(t, "update", result.Changes[0].Action)
+	assert.Equal(t, idA, result.Changes[0].ID)
+	require.Len(t, result.Changes[0].Changes, 1)
+	assert.Equal(t, "definition.nodes[0].config.operations[0].value", result.Changes[0].Changes[0].Path)
+	assert.Equal(t, "deactivate", result.Changes[1].Action)
+	assert.Equal(t, "b", result.Changes[1].Name)
+
+	a, err := db.GetWorkflow(ctx, uuid.MustParse(idA))
+	require.NoError(t, err)
+	assert.Equal(t, 2, a.Version)
+	assert.Equal(t, "repo", a.Metadata["sync_source"])
+	b, err := db.GetWorkflow(ctx, uuid.MustParse(result.Changes[1].ID))
+	require.NoError(t, err)
+	assert.Equal(t, models.WorkflowStatusDraft, b.Status)
+}
+
+func TestSyncWorkflows_LeavesOtherSourcesAlone(t *testing.T) {
+	router, _ := syncRouter(t)
+
+	syncWorkflows(t, router, `{"source": "one", "workflows": [`+syncWorkflow("a", "active", "1")+`]}`)
+	result := syncWorkflows(t, router, `{"source": "two", "workflows": [`+syncWorkflow("b", "active", "1")+`]}`)
+	require.Len(t, result.Changes, 1)
+	assert.Equal(t, "create", result.Changes[0].Action)
+}
+
+func TestSyncWorkflows_RejectsInvalidRequests(t *testing.T) {
+	router, _ := syncRouter(t)
+
+	duplicate := `{"workflows": [` + syncWorkflow("a", "", "1") + `, ` + syncWorkflow("a", "", "2") + `]}`
+	assert.Equal(t, 400, postJSON(router, "/api/v1/workflows/sync", duplicate).Code)
+
+	invalid := `{"workflows": [{"name": "a", "definition": {"start_node_id": "s", "nodes": [{"id": "s", "type": "set"}]}}]}`
+	rec := postJSON(router, "/api/v1/workflows/sync", invalid)
+	assert.Equal(t, 422, rec.Code)
+	assert.Contains(t, rec.Body.String(), `invalid workflow definition of \"a\"`)
+}
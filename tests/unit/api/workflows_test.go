@@ -0,0 +1,125 @@
+package api_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/nuumz/f1ow/internal/api"
+	"github.com/nuumz/f1ow/internal/diff"
+	"github.com/nuumz/f1ow/internal/engine"
+	"github.com/nuumz/f1ow/internal/models"
+	"github.com/nuumz/f1ow/internal/storage"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func workflowRouter(repo *storage.MemoryRepository) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/api/v1/workflows", api.GetWorkflows(repo))
+	router.GET("/api/v1/workflows/:id", api.GetWorkflow(repo))
+	router.GET("/api/v1/executions/compare", api.CompareExecutions(engine.NewEngine(nil, nil), repo))
+	router.GET("/api/v1/executions/:id", api.GetExecution(repo))
+	router.GET("/api/v1/executions/:id/nodes/:node_id/context", api.GetNodeContext(engine.NewEngine(nil, nil), repo))
+	return router
+}
+
+func getPath(router *gin.Engine, path string) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest("GET", path, nil))
+	return rec
+}
+
+func TestGetWorkflows_Filters(t *testing.T) {
+	repo := storage.NewMemoryRepository()
+	ctx := context.Background()
+	require.NoError(t, repo.CreateWorkflow(ctx, &models.Workflow{Name: "Sync orders", Tags: []string{"billing"}}))
+	require.NoError(t, repo.CreateWorkflow(ctx, &models.Workflow{Name: "Sync users"}))
+
+	rec := getPath(workflowRouter(repo), "/api/v1/workflows?q=orders&tag=billing")
+	require.Equal(t, 200, rec.Code)
+	assert.Equal(t, "1", rec.Header().Get("X-Total-Count"))
+
+	var workflows []models.Workflow
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &workflows))
+	require.Len(t, workflows, 1)
+	assert.Equal(t, "Sync orders", workflows[0].Name)
+}
+
+func TestGetWorkflow_NotFound(t *testing.T) {
+	router := workflowRouter(storage.NewMemoryRepository())
+
+	assert.Equal(t, 404, getPath(router, "/api/v1/workflows/"+uuid.NewString()).Code)
+	assert.Equal(t, 404, getPath(router, "/api/v1/executions/"+uuid.NewString()).Code)
+	assert.Equal(t, 400, getPath(router, "/api/v1/workflows/not-a-uuid").Code)
+}
+
+func TestCompareExecutions(t *testing.T) {
+	repo := storage.NewMemoryRepository()
+	ctx := context.Background()
+	workflowID := uuid.New()
+	executions := make([]*models.Execution, 3)
+	for i, status := range []models.ExecutionStatus{models.ExecutionStatusCompleted, models.ExecutionStatusFailed, models.ExecutionStatusCompleted} {
+		executions[i] = &models.Execution{WorkflowID: workflowID, Status: status, Context: models.ExecutionContext{
+			NodeExecutions: map[string]models.NodeExecution{"fetch": {NodeID: "fetch", Status: status}},
+		}}
+	}
+	executions[2].WorkflowID = uuid.New()
+	for _, execution := range executions {
+		require.NoError(t, repo.CreateExecution(ctx, execution))
+	}
+	router := workflowRouter(repo)
+
+	rec := getPath(router, "/api/v1/executions/compare?a="+executions[0].ID.String()+"&b="+executions[1].ID.String())
+	require.Equal(t, 200, rec.Code)
+	var result diff.ExecutionDiff
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &result))
+	assert.Equal(t, "fetch", result.FirstDivergence)
+	require.Len(t, result.Nodes, 1)
+	assert.Equal(t, models.ExecutionStatusFailed, result.Nodes[0].B.Status)
+
+	rec = getPath(router, "/api/v1/executions/compare?a="+executions[0].ID.String()+"&b="+executions[2].ID.String())
+	assert.Equal(t, 400, rec.Code, "executions of different workflows")
+	assert.Equal(t, 400, getPath(router, "/api/v1/executions/compare?a="+executions[0].ID.String()).Code)
+	assert.Equal(t, 404, getPath(router, "/api/v1/executions/compare?a="+executions[0].ID.String()+"&b="+uuid.NewString()).Code)
+}
+
+func TestGetNodeContext(t *testing.T) {
+	repo := storage.NewMemoryRepository()
+	ctx := context.Background()
+	execution := &models.Execution{WorkflowID: uuid.New(), Status: models.ExecutionStatusCompleted}
+	require.NoError(t, repo.CreateExecution(ctx, execution))
+	require.NoError(t, repo.SaveNodeExecutions(ctx, execution.ID, 0, []models.NodeExecution{
+		{NodeID: "fetch", Status: models.ExecutionStatusCompleted, Output: map[string]interface{}{"rows": float64(3)}},
+		{NodeID: "store", Status: models.ExecutionStatusCompleted, Snapshot: &models.NodeSnapshot{
+			Input:     map[string]interface{}{"table": "orders"},
+			Variables: map[string]interface{}{"token": "[redacted]"},
+			Upstream:  []string{"fetch"},
+		}},
+	}))
+	router := workflowRouter(repo)
+
+	rec := getPath(router, "/api/v1/executions/"+execution.ID.String()+"/nodes/store/context")
+	require.Equal(t, 200, rec.Code)
+	assert.JSONEq(t, `{"table":"orders"}`, responseField(t, rec, "input"))
+	assert.JSONEq(t, `{"token":"[redacted]"}`, responseField(t, rec, "variables"))
+	assert.JSONEq(t, `{"fetch":{"rows":3}}`, responseField(t, rec, "node_outputs"))
+	assert.JSONEq(t, `"completed"`, responseField(t, rec, "status"))
+
+	assert.Equal(t, 404, getPath(router, "/api/v1/executions/"+execution.ID.String()+"/nodes/fetch/context").Code)
+	assert.Equal(t, 404, getPath(router, "/api/v1/executions/"+uuid.NewString()+"/nodes/store/context").Code)
+	assert.Equal(t, 400, getPath(router, "/api/v1/executions/nope/nodes/store/context").Code)
+}
+
+// responseField returns the JSON of a top-level field of a response body
+func responseField(t *testing.T, rec *httptest.ResponseRecorder, field string) string {
+	t.Helper()
+	var body map[string]json.RawMessage
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+	return string(body[field])
+}
@@ -0,0 +1,163 @@
+package binarydata_test
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/nuumz/f1ow/internal/binarydata"
+	"github.com/nuumz/f1ow/internal/engine"
+	"github.com/nuumz/f1ow/internal/models"
+	"github.com/nuumz/f1ow/internal/nodes"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryIndex treats every execution not in live as deleted
+type memoryIndex struct {
+	entries map[uuid.UUID]models.BinaryData
+	live    map[uuid.UUID]bool
+}
+
+func newMemoryIndex() *memoryIndex {
+	return &memoryIndex{entries: map[uuid.UUID]models.BinaryData{}, live: map[uuid.UUID]bool{}}
+}
+
+func (m *memoryIndex) SaveBinaryData(ctx context.Context, data *models.BinaryData) error {
+	m.entries[data.ID] = *data
+	return nil
+}
+
+func (m *memoryIndex) GetBinaryData(ctx context.Context, id uuid.UUID) (*models.BinaryData, error) {
+	data, ok := m.entries[id]
+	if !ok {
+		return nil, binarydata.ErrNotFound
+	}
+	return &data, nil
+}
+
+func (m *memoryIndex) ListOrphanedBinaryData(ctx context.Context, limit int) ([]models.BinaryData, error) {
+	var result []models.BinaryData
+	for _, data := range m.entries {
+		if !m.live[data.ExecutionID] && len(result) < limit {
+			result = append(result, data)
+		}
+	}
+	return result, nil
+}
+
+func (m *memoryIndex) DeleteBinaryData(ctx context.Context, id uuid.UUID) error {
+	delete(m.entries, id)
+	return nil
+}
+
+func newManager(t *testing.T) (*binarydata.Manager, *memoryIndex) {
+	store, err := binarydata.NewFilesystemStore(t.TempDir())
+	require.NoError(t, err)
+
+	index := newMemoryIndex()
+	return binarydata.NewManager(store, index, nil), index
+}
+
+func TestManager_WriteOpenCleanup(t *testing.T) {
+	manager, index := newManager(t)
+	ctx := context.Background()
+
+	kept, removed := uuid.New(), uuid.New()
+	index.live[kept] = true
+
+	keptData, err := manager.Write(ctx, kept, "a.txt", "text/plain", strings.NewReader("hello"), -1)
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), keptData.Size)
+	assert.Equal(t, "filesystem", keptData.Storage)
+
+	removedData, err := manager.Write(ctx, removed, "b.txt", "text/plain", strings.NewReader("bye"), 3)
+	require.NoError(t, err)
+
+	data, reader, err := manager.Open(ctx, keptData.ID)
+	require.NoError(t, err)
+	content, err := io.ReadAll(reader)
+	reader.Close()
+	require.NoError(t, err)
+	assert.Equal(t, "hello", string(content))
+	assert.Equal(t, "a.txt", data.FileName)
+
+	count, err := manager.Cleanup(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+
+	_, _, err = manager.Open(ctx, removedData.ID)
+	assert.ErrorIs(t, err, binarydata.ErrNotFound)
+	_, _, err = manager.Open(ctx, keptData.ID)
+	assert.NoError(t, err)
+}
+
+func TestFileNode_BinaryHandles(t *testing.T) {
+	manager, _ := newManager(t)
+	node := nodes.NewFileNode(t.TempDir())
+
+	ctx := binarydata.WithManager(context.Background(), manager)
+	ctx = engine.WithExecutionInfo(ctx, engine.ExecutionInfo{ExecutionID: uuid.New().String()})
+
+	_, err := node.Execute(ctx, map[string]interface{}{
+		"operation": "write",
+		"path":      "image.png",
+		"content":   "{{file}}",
+	}, map[string]interface{}{"file": map[string]interface{}{"data": "iVBORw=="}})
+	require.NoError(t, err)
+
+	result, err := node.Execute(ctx, map[string]interface{}{
+		"operation": "read",
+		"path":      "image.png",
+	}, map[string]interface{}{})
+	require.NoError(t, err)
+
+	ref := result.(map[string]interface{})
+	assert.NotContains(t, ref, "data")
+	assert.Equal(t, "image.png", ref["file_name"])
+	assert.Equal(t, "image/png", ref["mime_type"])
+	assert.Equal(t, int64(4), ref["size"])
+
+	// The handle can be written back out through the node
+	_, err = node.Execute(ctx, map[string]interface{}{
+		"operation": "write",
+		"path":      "copy.png",
+		"content":   "{{file}}",
+	}, map[string]interface{}{"file": ref})
+	require.NoError(t, err)
+
+	id, ok := binarydata.IDFromValue(ref)
+	require.True(t, ok)
+	_, reader, err := manager.Open(ctx, id)
+	require.NoError(t, err)
+	content, _ := io.ReadAll(reader)
+	reader.Close()
+	assert.Equal(t, []byte{0x89, 'P', 'N', 'G'}, content)
+}
+
+func TestManager_OffloadLargeValues(t *testing.T) {
+	manager, _ := newManager(t)
+	ctx := context.Background()
+	executionID := uuid.New()
+
+	small := map[string]interface{}{"ok": true}
+	kept, err := manager.Offload(ctx, executionID, "output-a", small, 1024)
+	require.NoError(t, err)
+	assert.Equal(t, small, kept)
+
+	large := map[string]interface{}{"text": strings.Repeat("x", 4096)}
+	offloaded, err := manager.Offload(ctx, executionID, "output-b", large, 1024)
+	require.NoError(t, err)
+	require.True(t, binarydata.IsOffloaded(offloaded))
+
+	ref := offloaded.(map[string]interface{})
+	assert.Equal(t, "application/json", ref["mime_type"])
+	assert.Less(t, len(ref["preview"].(string)), 1100)
+
+	loaded, err := manager.Load(ctx, offloaded)
+	require.NoError(t, err)
+	assert.Equal(t, large, loaded)
+}
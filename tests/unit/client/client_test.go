@@ -0,0 +1,89 @@
+package client_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/nuumz/f1ow/pkg/client"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_ListWorkflows(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/v1/workflows", r.URL.Path)
+		assert.Equal(t, "f1ow_secret", r.Header.Get("X-API-Key"))
+		assert.Equal(t, "10", r.URL.Query().Get("limit"))
+		assert.Equal(t, []string{"a", "b"}, r.URL.Query()["tag"])
+		json.NewEncoder(w).Encode([]map[string]interface{}{{"name": "deploy"}})
+	}))
+	defer server.Close()
+
+	c := client.New(server.URL, client.WithAPIKey("f1ow_secret"))
+	workflows, err := c.ListWorkflows(context.Background(), &client.ListWorkflowsParams{Limit: 10, Tag: []string{"a", "b"}})
+	require.NoError(t, err)
+	require.Len(t, workflows, 1)
+	assert.Equal(t, "deploy", workflows[0].Name)
+}
+
+func TestClient_ReturnsAPIErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/v1/workflows/a%2Fb", r.URL.EscapedPath())
+		w.WriteHeader(404)
+		w.Write([]byte(`{"error":"workflow not found"}`))
+	}))
+	defer server.Close()
+
+	_, err := client.New(server.URL).GetWorkflow(context.Background(), "a/b")
+	var apiErr *client.Error
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, 404, apiErr.StatusCode)
+	assert.Equal(t, "workflow not found", apiErr.Message)
+}
+
+func TestClient_FollowExecutionResumesAfterLastEvent(t *testing.T) {
+	connections := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v1/executions/e1/events":
+			connections++
+			w.Header().Set("Content-Type", "text/event-stream")
+			if connections == 1 {
+				assert.Empty(t, r.URL.Query().Get("last_event_id"))
+				w.Write([]byte("retry: 3000\n\nid: 1-0\nevent: node.started\ndata: {\"type\":\"node.started\",\"node_id\":\"a\"}\n\n: keep-alive\n\n"))
+				return
+			}
+			assert.Equal(t, "1-0", r.URL.Query().Get("last_event_id"))
+			w.Write([]byte("id: 2-0\nevent: node.failed\ndata: {\"type\":\"node.failed\",\"node_id\":\"a\",\"error\":\"boom\"}\n\n" +
+				"id: 3-0\nevent: execution.failed\ndata: {\"type\":\"execution.failed\",\"error\":\"boom\"}\n\n"))
+		case "/api/v1/executions/e1":
+			// Running after the first stream ends, failed after the second
+			status := "running"
+			if connections > 1 {
+				status = "failed"
+			}
+			json.NewEncoder(w).Encode(map[string]interface{}{"status": status, "error": "boom"})
+		default:
+			t.Errorf("unexpected request %s", r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	var events []client.ExecutionEvent
+	execution, err := client.New(server.URL).FollowExecution(context.Background(), "e1", func(event client.ExecutionEvent) error {
+		events = append(events, event)
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "failed", execution.Status)
+	assert.Equal(t, 2, connections)
+	require.Len(t, events, 3)
+	assert.Equal(t, "1-0", events[0].ID)
+	assert.Equal(t, "a", events[1].NodeID)
+	assert.Equal(t, "boom", events[1].Error)
+	assert.True(t, events[2].Final())
+}
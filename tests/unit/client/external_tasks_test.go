@@ -0,0 +1,84 @@
+package client_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/nuumz/f1ow/pkg/client"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExternalTaskWorker_CompletesAndFailsTasks(t *testing.T) {
+	charge, refund := uuid.New(), uuid.New()
+	var mu sync.Mutex
+	fetches := 0
+	reports := map[string]map[string]interface{}{}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&body)
+		assert.Equal(t, "worker-1", body["worker_id"])
+
+		mu.Lock()
+		defer mu.Unlock()
+		switch r.URL.Path {
+		case "/api/v1/external-tasks/fetch-and-lock":
+			assert.Equal(t, []interface{}{"payments"}, body["topics"])
+			assert.Equal(t, float64(2), body["max_tasks"])
+			fetches++
+			if fetches > 1 {
+				json.NewEncoder(w).Encode([]interface{}{})
+				return
+			}
+			json.NewEncoder(w).Encode([]client.ExternalTask{
+				{ID: charge, Topic: "payments", Payload: map[string]interface{}{"action": "charge"}, Attempts: 1},
+				{ID: refund, Topic: "payments", Payload: map[string]interface{}{"action": "refund"}, Attempts: 2},
+			})
+		default:
+			reports[r.URL.Path] = body
+			if len(reports) == 2 {
+				cancel()
+			}
+			w.Write([]byte(`{"message":"ok"}`))
+		}
+	}))
+	defer server.Close()
+
+	worker := &client.ExternalTaskWorker{
+		Client: client.New(server.URL),
+		Topics: []string{"payments"},
+		Handler: func(ctx context.Context, task client.ExternalTask) (map[string]interface{}, error) {
+			if task.Payload["action"] == "refund" {
+				return nil, errors.New("refund window closed")
+			}
+			return map[string]interface{}{"charged": true}, nil
+		},
+		ID:           "worker-1",
+		MaxTasks:     2,
+		Retries:      3,
+		RetryTimeout: 5 * time.Second,
+	}
+	require.ErrorIs(t, worker.Run(ctx), context.Canceled)
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, map[string]interface{}{"charged": true},
+		reports["/api/v1/external-tasks/"+charge.String()+"/complete"]["result"])
+
+	failure := reports["/api/v1/external-tasks/"+refund.String()+"/failure"]
+	require.NotNil(t, failure)
+	assert.Equal(t, "refund window closed", failure["error_message"])
+	assert.Equal(t, float64(2), failure["retries"], "one of three retries is used up")
+	assert.Equal(t, float64(5000), failure["retry_timeout_ms"])
+}
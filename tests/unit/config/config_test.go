@@ -0,0 +1,136 @@
+package config_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/nuumz/f1ow/internal/config"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func TestLoad_Defaults(t *testing.T) {
+	t.Setenv("PORT", "")
+	t.Setenv("EXECUTION_REAPER_INTERVAL", "")
+
+	cfg, err := config.Load("")
+	require.NoError(t, err)
+	assert.Equal(t, "8080", cfg.Server.Port)
+	assert.True(t, cfg.Server.MigrateOnStart)
+	assert.Equal(t, time.Minute, cfg.Execution.ReaperInterval)
+	assert.True(t, cfg.BinaryData.S3.UseSSL)
+	assert.Equal(t, []string{"production", "prod"}, cfg.Lint.ProductionEnvironments)
+}
+
+func TestLoad_YAMLWithEnvOverrides(t *testing.T) {
+	path := writeFile(t, "f1ow.yaml", `
+server:
+  port: 9000
+  execution_mode: inline
+database:
+  url: postgres://file/f1ow
+  replica_urls:
+    - postgres://replica-1/f1ow
+    - postgres://replica-2/f1ow
+execution:
+  retention: 720h
+nodes:
+  ssh_allowed_commands: [uptime, "df -h"]
+`)
+	t.Setenv("DATABASE_URL", "postgres://env/f1ow")
+	t.Setenv("PORT", "")
+
+	cfg, err := config.Load(path)
+	require.NoError(t, err)
+	assert.Equal(t, "9000", cfg.Server.Port, "an empty variable keeps the file's setting")
+	assert.Equal(t, "inline", cfg.Server.ExecutionMode)
+	assert.Equal(t, "postgres://env/f1ow", cfg.Database.URL)
+	assert.Equal(t, []string{"postgres://replica-1/f1ow", "postgres://replica-2/f1ow"}, cfg.Database.ReplicaURLs)
+	assert.Equal(t, 720*time.Hour, cfg.Execution.Retention)
+	assert.Equal(t, []string{"uptime", "df -h"}, cfg.Nodes.SSHAllowedCommands)
+}
+
+func TestLoad_TOML(t *testing.T) {
+	path := writeFile(t, "f1ow.toml", `
+[worker]
+concurrency = 8
+labels = "region=eu"
+
+[binary_data.s3]
+use_ssl = false
+`)
+
+	cfg, err := config.Load(path)
+	require.NoError(t, err)
+	assert.Equal(t, 8, cfg.Worker.Concurrency)
+	assert.Equal(t, "region=eu", cfg.Worker.Labels)
+	assert.False(t, cfg.BinaryData.S3.UseSSL)
+}
+
+func TestLoad_EmptyRedisURLDisablesRedis(t *testing.T) {
+	t.Setenv("REDIS_URL", "")
+
+	cfg, err := config.Load("")
+	require.NoError(t, err)
+	assert.Empty(t, cfg.Redis.URL)
+}
+
+func TestLoad_RejectsUnknownSettings(t *testing.T) {
+	path := writeFile(t, "f1ow.yaml", "database:\n  uri: postgres://file/f1ow\n")
+
+	_, err := config.Load(path)
+	assert.ErrorContains(t, err, "unknown setting database.uri")
+}
+
+func TestLoad_RejectsInvalidValues(t *testing.T) {
+	t.Setenv("WORKER_CONCURRENCY", "many")
+
+	_, err := config.Load("")
+	assert.ErrorContains(t, err, "invalid WORKER_CONCURRENCY")
+}
+
+func TestLoad_ValidatesSettings(t *testing.T) {
+	t.Setenv("CREDENTIALS_ENABLED", "true")
+	t.Setenv("CREDENTIALS_ENCRYPTION_KEY", "")
+	t.Setenv("EXECUTION_MODE", "batch")
+	t.Setenv("QUEUE_FAIRNESS", "round-robin")
+	t.Setenv("QUOTA_MAX_EXECUTIONS_PER_DAY", "-1")
+	t.Setenv("TRUSTED_PROXIES", "10.0.0.0/8,load-balancer")
+
+	_, err := config.Load("")
+	require.Error(t, err)
+	assert.ErrorContains(t, err, "credentials.encryption_key (CREDENTIALS_ENCRYPTION_KEY) is required")
+	assert.ErrorContains(t, err, "server.execution_mode (EXECUTION_MODE) must be queue or inline")
+	assert.ErrorContains(t, err, "queue.fairness (QUEUE_FAIRNESS) must be tenant, workflow, or empty")
+	assert.ErrorContains(t, err, "quotas.max_executions_per_day (QUOTA_MAX_EXECUTIONS_PER_DAY) must not be negative")
+	assert.ErrorContains(t, err, `server.trusted_proxies (TRUSTED_PROXIES) entries must be IPs or CIDRs, got "load-balancer"`)
+}
+
+func TestLoad_ExampleFile(t *testing.T) {
+	cfg, err := config.Load(filepath.Join("..", "..", "..", "config.example.yaml"))
+	require.NoError(t, err)
+	assert.Equal(t, "filesystem", cfg.BinaryData.Storage)
+	assert.Equal(t, []string{"production", "prod"}, cfg.Lint.ProductionEnvironments)
+}
+
+func TestLoad_RejectsCredentialedCORSForAnyOrigin(t *testing.T) {
+	t.Setenv("CORS_ALLOW_CREDENTIALS", "true")
+
+	_, err := config.Load("")
+	assert.ErrorContains(t, err, "server.cors.origins (CORS_ORIGINS) must list origins")
+
+	t.Setenv("CORS_ORIGINS", "https://app.example.com")
+	cfg, err := config.Load("")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"https://app.example.com"}, cfg.Server.CORS.Origins)
+}
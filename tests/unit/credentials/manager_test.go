@@ -0,0 +1,220 @@
+package credentials_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+
+	"github.com/nuumz/f1ow/internal/credentials"
+	"github.com/nuumz/f1ow/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type memoryStore struct {
+	mu    sync.Mutex
+	items map[uuid.UUID]models.Credential
+}
+
+func newMemoryStore() *memoryStore {
+	return &memoryStore{items: make(map[uuid.UUID]models.Credential)}
+}
+
+func (s *memoryStore) CreateCredential(ctx context.Context, credential *models.Credential) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.items[credential.ID] = *credential
+	return nil
+}
+
+func (s *memoryStore) GetCredential(ctx context.Context, id uuid.UUID) (*models.Credential, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	credential, ok := s.items[id]
+	if !ok {
+		return nil, credentials.ErrNotFound
+	}
+	return &credential, nil
+}
+
+func (s *memoryStore) ListCredentials(ctx context.Context) ([]models.Credential, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var list []models.Credential
+	for _, credential := range s.items {
+		list = append(list, credential)
+	}
+	return list, nil
+}
+
+func (s *memoryStore) UpdateCredentialData(ctx context.Context, id uuid.UUID, data string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	credential, ok := s.items[id]
+	if !ok {
+		return credentials.ErrNotFound
+	}
+	credential.Data = data
+	s.items[id] = credential
+	return nil
+}
+
+func (s *memoryStore) DeleteCredential(ctx context.Context, id uuid.UUID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.items, id)
+	return nil
+}
+
+func newManager(t *testing.T, store credentials.Store) *credentials.Manager {
+	cipher, err := credentials.NewCipher("test-key")
+	require.NoError(t, err)
+	return credentials.NewManager(store, cipher, "https://f1ow.example.com/api/v1/oauth2/callback", nil)
+}
+
+func TestCipherRoundTrip(t *testing.T) {
+	cipher, err := credentials.NewCipher("test-key")
+	require.NoError(t, err)
+
+	encrypted, err := cipher.Encrypt([]byte("s3cret"))
+	require.NoError(t, err)
+	assert.NotContains(t, encrypted, "s3cret")
+
+	plaintext, err := cipher.Decrypt(encrypted)
+	require.NoError(t, err)
+	assert.Equal(t, "s3cret", string(plaintext))
+
+	other, err := credentials.NewCipher("other-key")
+	require.NoError(t, err)
+	_, err = other.Decrypt(encrypted)
+	assert.Error(t, err)
+}
+
+func TestManager_GenericCredential(t *testing.T) {
+	store := newMemoryStore()
+	manager := newManager(t, store)
+	ctx := context.Background()
+
+	credential, err := manager.Create(ctx, "github", models.CredentialTypeGeneric, uuid.New(),
+		map[string]interface{}{"token": "ghp_secret"})
+	require.NoError(t, err)
+	assert.NotContains(t, store.items[credential.ID].Data, "ghp_secret")
+
+	fields, err := manager.Resolve(ctx, credential.ID.String())
+	require.NoError(t, err)
+	assert.Equal(t, "ghp_secret", fields["token"])
+}
+
+func TestManager_OAuth2AuthorizeAndRefresh(t *testing.T) {
+	var grants []string
+	provider := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseForm())
+		grants = append(grants, r.PostForm.Get("grant_type"))
+		w.Header().Set("Content-Type", "application/json")
+
+		switch r.PostForm.Get("grant_type") {
+		case "authorization_code":
+			assert.Equal(t, "the-code", r.PostForm.Get("code"))
+			// Expires immediately so the next resolve refreshes it
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"access_token": "access-1", "refresh_token": "refresh-1", "token_type": "Bearer", "expires_in": 1,
+			})
+		case "refresh_token":
+			assert.Equal(t, "refresh-1", r.PostForm.Get("refresh_token"))
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"access_token": "access-2", "token_type": "Bearer", "expires_in": 3600,
+			})
+		}
+	}))
+	defer provider.Close()
+
+	store := newMemoryStore()
+	manager := newManager(t, store)
+	ctx := context.Background()
+
+	credential, err := manager.Create(ctx, "crm", models.CredentialTypeOAuth2, uuid.New(), map[string]interface{}{
+		"client_id":     "client",
+		"client_secret": "secret",
+		"auth_url":      provider.URL + "/authorize",
+		"token_url":     provider.URL + "/token",
+		"scopes":        []string{"read"},
+	})
+	require.NoError(t, err)
+
+	_, err = manager.Resolve(ctx, credential.ID.String())
+	assert.ErrorContains(t, err, "has not been authorized")
+
+	authURL, session, err := manager.AuthorizeURL(ctx, credential.ID, "user-1")
+	require.NoError(t, err)
+	parsed, err := url.Parse(authURL)
+	require.NoError(t, err)
+	assert.Equal(t, "https://f1ow.example.com/api/v1/oauth2/callback", parsed.Query().Get("redirect_uri"))
+	state := parsed.Query().Get("state")
+
+	_, err = manager.CompleteAuthorization(ctx, state+"x", session, "the-code")
+	assert.Error(t, err)
+
+	_, err = manager.CompleteAuthorization(ctx, state, session, "the-code")
+	require.NoError(t, err)
+
+	fields, err := manager.Resolve(ctx, credential.ID.String())
+	require.NoError(t, err)
+	assert.Equal(t, "access-2", fields["access_token"])
+	assert.Equal(t, "access-2", fields["token"])
+	assert.Equal(t, map[string]interface{}{"type": "bearer", "token": "access-2"}, fields["authentication"])
+
+	// The rotated token is persisted and the original refresh token kept
+	fields, err = manager.Resolve(ctx, credential.ID.String())
+	require.NoError(t, err)
+	assert.Equal(t, "access-2", fields["access_token"])
+	assert.Equal(t, []string{"authorization_code", "refresh_token"}, grants)
+}
+
+func TestManager_OAuth2StateIsOneTimeAndBoundToSession(t *testing.T) {
+	var exchanges int
+	provider := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		exchanges++
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{"access_token": "access", "token_type": "Bearer"})
+	}))
+	defer provider.Close()
+
+	manager := newManager(t, newMemoryStore())
+	ctx := context.Background()
+
+	credential, err := manager.Create(ctx, "crm", models.CredentialTypeOAuth2, uuid.New(), map[string]interface{}{
+		"client_id": "client", "auth_url": provider.URL + "/authorize", "token_url": provider.URL + "/token",
+	})
+	require.NoError(t, err)
+
+	authorize := func() (string, string) {
+		authURL, session, err := manager.AuthorizeURL(ctx, credential.ID, "user-1")
+		require.NoError(t, err)
+		parsed, err := url.Parse(authURL)
+		require.NoError(t, err)
+		return parsed.Query().Get("state"), session
+	}
+
+	// A state started in another browser is rejected and cannot be retried
+	state, _ := authorize()
+	_, attackerSession := authorize()
+	_, err = manager.CompleteAuthorization(ctx, state, attackerSession, "the-code")
+	assert.ErrorContains(t, err, "another session")
+	_, err = manager.CompleteAuthorization(ctx, state, "", "the-code")
+	assert.ErrorContains(t, err, "already used")
+
+	// A completed state cannot be replayed
+	state, session := authorize()
+	_, err = manager.CompleteAuthorization(ctx, state, session, "the-code")
+	require.NoError(t, err)
+	_, err = manager.CompleteAuthorization(ctx, state, session, "the-code")
+	assert.ErrorContains(t, err, "already used")
+
+	assert.Equal(t, 1, exchanges)
+}
@@ -0,0 +1,131 @@
+package diff_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/nuumz/f1ow/internal/diff"
+	"github.com/nuumz/f1ow/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func definition() models.WorkflowDefinition {
+	return models.WorkflowDefinition{
+		Nodes: []models.Node{
+			{ID: "fetch", Type: "http", Config: map[string]interface{}{"url": "https://dev.example.com", "credential_id": "dev-key"}},
+			{ID: "log", Type: "transform", Config: map[string]interface{}{"fields": []interface{}{"a"}}},
+		},
+		Edges:     []models.Edge{{Source: "fetch", Target: "log"}},
+		Variables: map[string]interface{}{"region": "eu"},
+	}
+}
+
+func TestWorkflowsIdentical(t *testing.T) {
+	from, to := definition(), definition()
+	assert.True(t, diff.Workflows(&from, &to).Empty())
+}
+
+func TestWorkflowsChanges(t *testing.T) {
+	from, to := definition(), definition()
+	to.Nodes[1].Config = map[string]interface{}{"fields": []interface{}{"a", "b"}}
+	to.Nodes = append(to.Nodes, models.Node{ID: "notify", Type: "http"})
+	to.Edges = []models.Edge{{Source: "fetch", Target: "notify"}}
+	to.Variables = map[string]interface{}{"region": "us"}
+
+	result := diff.Workflows(&from, &to)
+	require.False(t, result.Empty())
+
+	require.Len(t, result.NodesAdded, 1)
+	assert.Equal(t, "notify", result.NodesAdded[0].ID)
+	assert.Empty(t, result.NodesRemoved)
+	require.Len(t, result.NodesChanged, 1)
+	assert.Equal(t, "log", result.NodesChanged[0].ID)
+	assert.Equal(t, []diff.Change{{Path: "config.fields[1]", To: "b"}}, result.NodesChanged[0].Changes)
+
+	require.Len(t, result.EdgesAdded, 1)
+	assert.Equal(t, "notify", result.EdgesAdded[0].Target)
+	require.Len(t, result.EdgesRemoved, 1)
+	assert.Equal(t, "log", result.EdgesRemoved[0].Target)
+
+	assert.Equal(t, []diff.Change{{Path: "variables.region", From: "eu", To: "us"}}, result.Changes)
+}
+
+func TestValuesTreatsMissingAsEmpty(t *testing.T) {
+	assert.Empty(t, diff.Values(map[string]interface{}{"a": nil}, map[string]interface{}{"a": []interface{}{}}))
+	assert.Equal(t, []diff.Change{{Path: "a.b", From: 1.0}}, diff.Values(
+		map[string]interface{}{"a": map[string]interface{}{"b": 1}},
+		map[string]interface{}{"a": map[string]interface{}{}},
+	))
+}
+
+func TestDeploymentApply(t *testing.T) {
+	version := &models.WorkflowVersion{Version: 3, Definition: definition()}
+	deployment := &models.Deployment{
+		Environment: "prod",
+		Version:     3,
+		Credentials: map[string]string{"dev-key": "prod-key"},
+		Variables:   map[string]interface{}{"region": "us", "tier": "gold"},
+	}
+
+	applied := deployment.Apply(version)
+	assert.Equal(t, "prod-key", applied.Nodes[0].Config["credential_id"])
+	assert.Equal(t, map[string]interface{}{"region": "us", "tier": "gold"}, applied.Variables)
+
+	// The version itself is left untouched
+	assert.Equal(t, "dev-key", version.Definition.Nodes[0].Config["credential_id"])
+	assert.Equal(t, "eu", version.Definition.Variables["region"])
+
+	changes := diff.Workflows(&version.Definition, &applied)
+	require.Len(t, changes.NodesChanged, 1)
+	assert.Equal(t, []diff.Change{{Path: "config.credential_id", From: "dev-key", To: "prod-key"}}, changes.NodesChanged[0].Changes)
+}
+
+func nodeExecution(status models.ExecutionStatus, start time.Time, ms int, output map[string]interface{}) models.NodeExecution {
+	end := start.Add(time.Duration(ms) * time.Millisecond)
+	return models.NodeExecution{Status: status, StartedAt: start, CompletedAt: &end, Output: output}
+}
+
+func TestExecutions(t *testing.T) {
+	start := time.Date(2024, 5, 1, 9, 0, 0, 0, time.UTC)
+	failure := "upstream returned 500"
+	a := &models.Execution{ID: uuid.New(), Status: models.ExecutionStatusCompleted, StartedAt: start,
+		Input: map[string]interface{}{"day": "mon"},
+		Context: models.ExecutionContext{NodeExecutions: map[string]models.NodeExecution{
+			"fetch":  nodeExecution(models.ExecutionStatusCompleted, start, 120, map[string]interface{}{"count": 3.0}),
+			"parse":  nodeExecution(models.ExecutionStatusCompleted, start.Add(time.Second), 10, map[string]interface{}{"ok": true}),
+			"notify": nodeExecution(models.ExecutionStatusCompleted, start.Add(2*time.Second), 5, nil),
+		}},
+	}
+	b := &models.Execution{ID: uuid.New(), Status: models.ExecutionStatusFailed, StartedAt: start, Error: &failure,
+		Input: map[string]interface{}{"day": "tue"},
+		Context: models.ExecutionContext{NodeExecutions: map[string]models.NodeExecution{
+			"fetch": nodeExecution(models.ExecutionStatusCompleted, start, 150, map[string]interface{}{"count": 3.0}),
+			"parse": nodeExecution(models.ExecutionStatusFailed, start.Add(time.Second), 30, nil),
+		}},
+	}
+
+	result := diff.Executions(a, b)
+	assert.Equal(t, []diff.Change{{Path: "day", From: "mon", To: "tue"}}, result.InputChanges)
+	assert.Equal(t, models.ExecutionStatusFailed, result.B.Status)
+	assert.Equal(t, "parse", result.FirstDivergence)
+	require.Len(t, result.Nodes, 3)
+
+	fetch := result.Nodes[0]
+	assert.Equal(t, "fetch", fetch.NodeID)
+	assert.False(t, fetch.Changed)
+	require.NotNil(t, fetch.DurationDeltaMS)
+	assert.Equal(t, int64(30), *fetch.DurationDeltaMS)
+
+	parse := result.Nodes[1]
+	assert.True(t, parse.Changed)
+	assert.Equal(t, models.ExecutionStatusFailed, parse.B.Status)
+	assert.Equal(t, []diff.Change{{Path: "ok", From: true}}, parse.OutputChanges)
+
+	notify := result.Nodes[2]
+	assert.True(t, notify.Changed)
+	assert.Nil(t, notify.B, "the node did not run in b")
+	assert.Nil(t, notify.DurationDeltaMS)
+}
@@ -0,0 +1,47 @@
+package engine_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/nuumz/f1ow/internal/engine"
+	"github.com/nuumz/f1ow/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWorkerSelector(t *testing.T) {
+	workflow := &models.Workflow{Definition: models.WorkflowDefinition{
+		Settings: models.WorkflowSettings{WorkerLabels: map[string]string{"region": "eu"}},
+		Nodes: []models.Node{
+			{ID: "run", Type: "exec", WorkerLabels: map[string]string{"exec": "true"}},
+			{ID: "old", Type: "file", Disabled: true, WorkerLabels: map[string]string{"region": "us"}},
+		},
+	}}
+
+	selector, err := engine.WorkerSelector(workflow)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"region": "eu", "exec": "true"}, selector)
+
+	// Enabling the node pins the workflow to two regions at once
+	workflow.Definition.Nodes[1].Disabled = false
+	_, err = engine.WorkerSelector(workflow)
+	var validationErr *engine.ValidationError
+	require.True(t, errors.As(err, &validationErr))
+	assert.Equal(t, "old", validationErr.Errors[0].NodeID)
+	assert.Equal(t, "worker_labels", validationErr.Errors[0].Field)
+}
+
+func TestParseLabels(t *testing.T) {
+	labels, err := engine.ParseLabels(" region=eu, gpu=true ,")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"region": "eu", "gpu": "true"}, labels)
+
+	labels, err = engine.ParseLabels("")
+	require.NoError(t, err)
+	assert.Empty(t, labels)
+
+	_, err = engine.ParseLabels("region")
+	assert.Error(t, err)
+}
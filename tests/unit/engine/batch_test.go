@@ -0,0 +1,117 @@
+package engine_test
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/nuumz/f1ow/internal/engine"
+	"github.com/nuumz/f1ow/internal/models"
+	"github.com/nuumz/f1ow/internal/storage"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// overlapNode records the most runs in progress at once
+type overlapNode struct {
+	upstreamNode
+	running, most atomic.Int32
+}
+
+func (n *overlapNode) Execute(ctx context.Context, config interface{}, input interface{}) (interface{}, error) {
+	running := n.running.Add(1)
+	defer n.running.Add(-1)
+	for {
+		most := n.most.Load()
+		if running <= most || n.most.CompareAndSwap(most, running) {
+			break
+		}
+	}
+	time.Sleep(50 * time.Millisecond)
+	return map[string]interface{}{"ok": true}, nil
+}
+
+func newBatchEngine(t *testing.T, node engine.NodeType) (*engine.Engine, *storage.MemoryRepository, *models.Workflow) {
+	t.Helper()
+	repo := storage.NewMemoryRepository()
+	eng := engine.NewEngine(repo, nil, engine.WithQueue(engine.NewMemoryQueue(nil)))
+	require.NoError(t, eng.RegisterNode("overlap", node))
+
+	workflow := &models.Workflow{Name: "import", Definition: models.WorkflowDefinition{
+		Nodes: []models.Node{{ID: "run", Type: "overlap"}},
+	}}
+	require.NoError(t, repo.CreateWorkflow(context.Background(), workflow))
+	return eng, repo, workflow
+}
+
+func TestSubmitBatch_RunsWithinConcurrency(t *testing.T) {
+	node := &overlapNode{}
+	eng, repo, workflow := newBatchEngine(t, node)
+	ctx := context.Background()
+
+	inputs := []map[string]interface{}{{"row": 1}, {"row": 2}, {"row": 3}}
+	batch, executions, err := eng.SubmitBatch(ctx, workflow.ID.String(), "", inputs, engine.BatchOptions{Concurrency: 1})
+	require.NoError(t, err)
+	require.Len(t, executions, 3)
+	assert.Equal(t, 3, batch.Total)
+	assert.Equal(t, 3, batch.Counts[models.ExecutionStatusPending])
+	for i, execution := range executions {
+		assert.Equal(t, inputs[i], execution.Input)
+		assert.Equal(t, batch.ID.String(), execution.Metadata["batch_id"])
+	}
+
+	workerCtx, stop := context.WithTimeout(ctx, 15*time.Second)
+	defer stop()
+	go eng.StartWorker(workerCtx)
+
+	require.Eventually(t, func() bool {
+		batch, err := eng.ExecutionBatch(ctx, batch.ID)
+		return err == nil && batch.Done
+	}, 15*time.Second, 50*time.Millisecond)
+
+	batch, err = eng.ExecutionBatch(ctx, batch.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 3, batch.Counts[models.ExecutionStatusCompleted])
+	assert.Equal(t, int32(1), node.most.Load())
+
+	stored, err := repo.GetExecution(ctx, executions[0].ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.ExecutionStatusCompleted, stored.Status)
+}
+
+func TestSubmitBatch_SharedBatchID(t *testing.T) {
+	eng, repo, workflow := newBatchEngine(t, &overlapNode{})
+	ctx := context.Background()
+	batchID := uuid.New()
+
+	_, _, err := eng.SubmitBatch(ctx, workflow.ID.String(), "", []map[string]interface{}{{"row": 1}}, engine.BatchOptions{ID: batchID})
+	require.NoError(t, err)
+	batch, _, err := eng.SubmitBatch(ctx, workflow.ID.String(), "", []map[string]interface{}{{"row": 2}, {"row": 3}}, engine.BatchOptions{ID: batchID})
+	require.NoError(t, err)
+	assert.Equal(t, batchID, batch.ID)
+	assert.Equal(t, 3, batch.Total)
+
+	// A batch only holds executions of one workflow
+	other := &models.Workflow{Name: "other", Definition: workflow.Definition}
+	require.NoError(t, repo.CreateWorkflow(ctx, other))
+	_, _, err = eng.SubmitBatch(ctx, other.ID.String(), "", []map[string]interface{}{{"row": 4}}, engine.BatchOptions{ID: batchID})
+	assert.ErrorIs(t, err, engine.ErrBatchWorkflowMismatch)
+}
+
+func TestSubmitBatch_CappedByWorkflowConcurrency(t *testing.T) {
+	eng, repo, workflow := newBatchEngine(t, &overlapNode{})
+	ctx := context.Background()
+	workflow.Definition.Settings.MaxConcurrency = 2
+	require.NoError(t, repo.UpdateWorkflow(ctx, workflow))
+
+	batch, _, err := eng.SubmitBatch(ctx, workflow.ID.String(), "", []map[string]interface{}{{"row": 1}}, engine.BatchOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, 2, batch.Concurrency)
+
+	batch, _, err = eng.SubmitBatch(ctx, workflow.ID.String(), "", []map[string]interface{}{{"row": 1}}, engine.BatchOptions{Concurrency: 5})
+	require.NoError(t, err)
+	assert.Equal(t, 2, batch.Concurrency)
+}
@@ -0,0 +1,33 @@
+package engine_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/nuumz/f1ow/internal/engine"
+	"github.com/nuumz/f1ow/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateWorkflow_ConcurrencySettings(t *testing.T) {
+	eng := engine.NewEngine(nil, nil)
+
+	workflow := &models.Workflow{Definition: models.WorkflowDefinition{
+		Settings: models.WorkflowSettings{ConcurrencyPolicy: models.ConcurrencyReplace, MaxConcurrency: 2},
+	}}
+	require.NoError(t, eng.ValidateWorkflow(workflow))
+
+	workflow.Definition.Settings.ConcurrencyPolicy = "queue"
+	err := eng.ValidateWorkflow(workflow)
+	var validationErr *engine.ValidationError
+	require.True(t, errors.As(err, &validationErr))
+	assert.Equal(t, "settings.concurrency_policy", validationErr.Errors[0].Field)
+
+	workflow.Definition.Settings.ConcurrencyPolicy = models.ConcurrencyForbid
+	workflow.Definition.Settings.MaxConcurrency = -1
+	err = eng.ValidateWorkflow(workflow)
+	require.True(t, errors.As(err, &validationErr))
+	assert.Equal(t, "settings.max_concurrency", validationErr.Errors[0].Field)
+}
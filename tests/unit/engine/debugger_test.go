@@ -0,0 +1,25 @@
+package engine_test
+
+import (
+	"testing"
+
+	"github.com/nuumz/f1ow/internal/engine"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSendDebugCommand_Validation(t *testing.T) {
+	eng := engine.NewEngine(nil, nil)
+
+	err := eng.SendDebugCommand("exec-1", engine.DebugCommand{Action: "rewind"})
+	assert.ErrorContains(t, err, "invalid debug action")
+
+	err = eng.SendDebugCommand("exec-1", engine.DebugCommand{Action: engine.DebugModify})
+	assert.ErrorContains(t, err, "modify requires an input")
+
+	err = eng.SendDebugCommand("exec-1", engine.DebugCommand{Action: engine.DebugContinue})
+	assert.ErrorIs(t, err, engine.ErrNotDebugging)
+
+	_, err = eng.DebugState("exec-1")
+	assert.ErrorIs(t, err, engine.ErrNotDebugging)
+}
@@ -0,0 +1,50 @@
+package engine_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/nuumz/f1ow/internal/engine"
+	"github.com/nuumz/f1ow/internal/models"
+	"github.com/nuumz/f1ow/internal/tenant"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEngine_EventsCarryTheTenant(t *testing.T) {
+	eng := engine.NewEngine(nil, nil)
+	assert.Nil(t, eng.EventBus(), "the bus needs Redis")
+
+	node := &MockNode{}
+	node.On("Execute", mock.Anything, mock.Anything, mock.Anything).Return(map[string]interface{}{"ok": true}, nil)
+	require.NoError(t, eng.RegisterNode("events_ok", node))
+
+	var events []engine.Event
+	unsubscribe := eng.Subscribe(func(event engine.Event) { events = append(events, event) })
+	defer unsubscribe()
+
+	tenantID := uuid.New()
+	ctx := tenant.WithID(context.Background(), tenantID)
+	workflowID := uuid.New()
+	eng.PublishEvent(ctx, engine.Event{Type: engine.EventWorkflowUpdated, WorkflowID: workflowID.String()})
+	_, err := eng.Run(ctx, &models.Workflow{ID: workflowID, TenantID: tenantID, Definition: models.WorkflowDefinition{
+		Nodes: []models.Node{{ID: "node", Type: "events_ok"}},
+	}}, nil)
+	require.NoError(t, err)
+
+	var types []engine.EventType
+	for _, event := range events {
+		types = append(types, event.Type)
+		assert.Equal(t, tenantID.String(), event.TenantID, "%s event", event.Type)
+		assert.Equal(t, workflowID.String(), event.WorkflowID, "%s event", event.Type)
+	}
+	assert.Equal(t, []engine.EventType{engine.EventWorkflowUpdated, engine.EventExecutionStarted,
+		engine.EventNodeStarted, engine.EventNodeCompleted, engine.EventExecutionCompleted}, types)
+
+	// Without a tenant in the context, events belong to the default tenant
+	eng.PublishEvent(context.Background(), engine.Event{Type: engine.EventWorkflowDeleted})
+	assert.Equal(t, tenant.DefaultID.String(), events[len(events)-1].TenantID)
+}
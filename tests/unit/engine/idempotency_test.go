@@ -0,0 +1,140 @@
+package engine_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/nuumz/f1ow/internal/engine"
+	"github.com/nuumz/f1ow/internal/models"
+	"github.com/nuumz/f1ow/internal/quota"
+	"github.com/nuumz/f1ow/internal/storage"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryIdempotencyStore keeps idempotency keys in memory; keys never expire
+type memoryIdempotencyStore struct {
+	mu     sync.Mutex
+	values map[string][]byte
+}
+
+func newMemoryIdempotencyStore() *memoryIdempotencyStore {
+	return &memoryIdempotencyStore{values: map[string][]byte{}}
+}
+
+func (s *memoryIdempotencyStore) Reserve(ctx context.Context, key string, value []byte, ttl time.Duration) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.values[key]; ok {
+		return false, nil
+	}
+	s.values[key] = value
+	return true, nil
+}
+
+func (s *memoryIdempotencyStore) Lookup(ctx context.Context, key string) ([]byte, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	value, ok := s.values[key]
+	return value, ok, nil
+}
+
+func (s *memoryIdempotencyStore) Release(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.values, key)
+	return nil
+}
+
+// dailyQuotaStore allows a number of executions a day and counts those
+// started
+type dailyQuotaStore struct {
+	limit   int
+	started *int
+}
+
+func (s dailyQuotaStore) TenantQuotas(context.Context, uuid.UUID) (string, models.Quotas, error) {
+	return "", models.Quotas{MaxExecutionsPerDay: &s.limit}, nil
+}
+func (s dailyQuotaStore) CountActiveWorkflows(context.Context, uuid.UUID) (int, error) { return 0, nil }
+func (s dailyQuotaStore) CountExecutionsSince(context.Context, uuid.UUID, time.Time) (int, error) {
+	return *s.started, nil
+}
+
+func idempotentEngine(t *testing.T, opts ...engine.Option) (*engine.Engine, *storage.MemoryRepository, *engine.MemoryQueue, string) {
+	t.Helper()
+	repo := storage.NewMemoryRepository()
+	queue := engine.NewMemoryQueue(nil)
+	opts = append(opts, engine.WithQueue(queue), engine.WithIdempotencyStore(newMemoryIdempotencyStore()))
+	eng := engine.NewEngine(repo, nil, opts...)
+
+	workflow := &models.Workflow{Name: "orders", Status: models.WorkflowStatusActive, Definition: models.WorkflowDefinition{
+		Nodes: []models.Node{{ID: "start", Type: "manual_trigger"}},
+	}}
+	require.NoError(t, repo.CreateWorkflow(context.Background(), workflow))
+	return eng, repo, queue, workflow.ID.String()
+}
+
+func TestSubmit_ReplaysIdempotencyKey(t *testing.T) {
+	eng, repo, queue, workflowID := idempotentEngine(t)
+	ctx := engine.WithIdempotencyKey(context.Background(), "order-1")
+
+	first, err := eng.Submit(ctx, workflowID, "", map[string]interface{}{"n": 1})
+	require.NoError(t, err)
+	second, err := eng.Submit(ctx, workflowID, "", map[string]interface{}{"n": 2})
+	require.NoError(t, err)
+	assert.Equal(t, first.ID, second.ID, "a repeated key returns the original execution")
+
+	wfID := uuid.MustParse(workflowID)
+	page, err := repo.ListExecutions(context.Background(), storage.ExecutionListOptions{WorkflowID: &wfID, Limit: 10})
+	require.NoError(t, err)
+	assert.Len(t, page.Executions, 1, "no second execution is created")
+	size, err := queue.Size(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), size)
+
+	replayed, err := eng.IdempotentExecution(context.Background(), workflowID, "order-1")
+	require.NoError(t, err)
+	require.NotNil(t, replayed)
+	assert.Equal(t, first.ID, replayed.ID)
+
+	other, err := eng.Submit(engine.WithIdempotencyKey(context.Background(), "order-2"), workflowID, "", nil)
+	require.NoError(t, err)
+	assert.NotEqual(t, first.ID, other.ID, "another key starts another execution")
+}
+
+func TestEnqueue_ReplaysIdempotencyKeyBeforeQuota(t *testing.T) {
+	started := 0
+	enforcer := quota.New(models.Quotas{}, dailyQuotaStore{limit: 1, started: &started})
+	eng, _, queue, workflowID := idempotentEngine(t, engine.WithQuotas(enforcer))
+	ctx := engine.WithIdempotencyKey(context.Background(), "order-1")
+
+	first, err := eng.Enqueue(ctx, workflowID, map[string]interface{}{"n": 1})
+	require.NoError(t, err)
+	started = 1
+
+	// The tenant has now used its quota, but the repeated key still returns
+	// the job queued first
+	second, err := eng.Enqueue(ctx, workflowID, map[string]interface{}{"n": 2})
+	require.NoError(t, err)
+	assert.Equal(t, first.ID, second.ID)
+	assert.Equal(t, first.ExecutionID, second.ExecutionID)
+	size, err := queue.Size(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), size)
+
+	// A new key is held to the quota, and is free to use once it is lifted
+	other := engine.WithIdempotencyKey(context.Background(), "order-2")
+	_, err = eng.Enqueue(other, workflowID, nil)
+	var exceeded *quota.ExceededError
+	require.True(t, errors.As(err, &exceeded), "got %v", err)
+	started = 0
+	job, err := eng.Enqueue(other, workflowID, nil)
+	require.NoError(t, err)
+	assert.NotEqual(t, first.ID, job.ID, "a rejected key is not kept")
+}
@@ -0,0 +1,149 @@
+package engine_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/nuumz/f1ow/internal/engine"
+	"github.com/nuumz/f1ow/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mirrorNode returns its config's output field, or else its input, so
+// tests can see what a node received
+type mirrorNode struct{ upstreamNode }
+
+func (n *mirrorNode) Execute(ctx context.Context, config interface{}, input interface{}) (interface{}, error) {
+	if output, ok := config.(map[string]interface{})["output"]; ok {
+		return output, nil
+	}
+	return input, nil
+}
+
+func mirrorEngine(t *testing.T) *engine.Engine {
+	t.Helper()
+	eng := engine.NewEngine(nil, nil)
+	require.NoError(t, eng.RegisterNode("mirror", &mirrorNode{}))
+	return eng
+}
+
+func TestEngineRun_InputMapping(t *testing.T) {
+	eng := mirrorEngine(t)
+	user := map[string]interface{}{"id": float64(7), "name": "Ada", "roles": []interface{}{"admin", "dev"}}
+	workflow := &models.Workflow{Definition: models.WorkflowDefinition{
+		Nodes: []models.Node{
+			{ID: "fetch", Type: "mirror", Config: map[string]interface{}{"output": map[string]interface{}{"user": user}}},
+			{ID: "greet", Type: "mirror", InputMapping: map[string]string{
+				"user.id":  "{{nodes.fetch.user.id}}",
+				"role":     "{{ nodes.fetch.user.roles[1] }}",
+				"greeting": "Hello {{nodes.fetch.user.name}} from {{input.team}}",
+				"missing":  "{{nodes.fetch.user.email}}",
+			}},
+		},
+		Edges: []models.Edge{{Source: "fetch", Target: "greet"}},
+	}}
+
+	execution, err := eng.Run(context.Background(), workflow, map[string]interface{}{"team": "core"})
+	require.NoError(t, err)
+
+	greet := execution.Output["greet"].(map[string]interface{})
+	assert.Equal(t, map[string]interface{}{"id": float64(7)}, greet["user"], "a single reference keeps its type")
+	assert.Equal(t, "dev", greet["role"])
+	assert.Equal(t, "Hello Ada from core", greet["greeting"])
+	assert.Contains(t, greet, "missing")
+	assert.Nil(t, greet["missing"])
+	assert.Contains(t, greet, "vars")
+	assert.NotContains(t, greet, "nodeOutputs", "mapped nodes get only what they declare")
+	assert.NotContains(t, greet, "team")
+}
+
+func TestEngineRun_EdgeInputMapping(t *testing.T) {
+	eng := mirrorEngine(t)
+	workflow := &models.Workflow{Definition: models.WorkflowDefinition{
+		Nodes: []models.Node{
+			{ID: "a", Type: "mirror", Config: map[string]interface{}{"output": map[string]interface{}{"value": "from a"}}},
+			{ID: "b", Type: "mirror", Config: map[string]interface{}{"output": map[string]interface{}{"value": "from b"}}},
+			{ID: "join", Type: "mirror", InputMapping: map[string]string{"second": "{{nodes.a.value}}"}},
+		},
+		Edges: []models.Edge{
+			{Source: "a", Target: "join", InputMapping: map[string]string{"first": "{{source.value}}"}},
+			{Source: "b", Target: "join", InputMapping: map[string]string{"second": "{{source.value}}"}},
+		},
+	}}
+
+	execution, err := eng.Run(context.Background(), workflow, nil)
+	require.NoError(t, err)
+
+	join := execution.Output["join"].(map[string]interface{})
+	assert.Equal(t, "from a", join["first"])
+	assert.Equal(t, "from a", join["second"], "the node's mapping overrides its edges'")
+}
+
+func TestEngineRun_WithoutInputMapping(t *testing.T) {
+	eng := mirrorEngine(t)
+	workflow := &models.Workflow{Definition: models.WorkflowDefinition{
+		Nodes: []models.Node{
+			{ID: "a", Type: "mirror", Config: map[string]interface{}{"output": map[string]interface{}{"value": 1}}},
+			{ID: "b", Type: "mirror"},
+		},
+		Edges: []models.Edge{{Source: "a", Target: "b"}},
+	}}
+
+	execution, err := eng.Run(context.Background(), workflow, map[string]interface{}{"team": "core"})
+	require.NoError(t, err)
+
+	b := execution.Output["b"].(map[string]interface{})
+	assert.Equal(t, "core", b["team"])
+	assert.Equal(t, map[string]interface{}{"value": 1}, b["nodeOutputs"].(map[string]interface{})["a"])
+}
+
+func TestValidateWorkflow_InputMapping(t *testing.T) {
+	eng := mirrorEngine(t)
+	workflow := &models.Workflow{Definition: models.WorkflowDefinition{
+		Nodes: []models.Node{
+			{ID: "a", Type: "mirror"},
+			{ID: "b", Type: "mirror", InputMapping: map[string]string{
+				"ok":      "{{nodes.a.value}} {{vars.region}} {{input.id}}",
+				"unknown": "{{nodes.c.value}}",
+				"source":  "{{source.value}}",
+				"other":   "{{env.HOME}}",
+			}},
+		},
+		Edges: []models.Edge{{Source: "a", Target: "b", InputMapping: map[string]string{"from": "{{source.value}}"}}},
+	}}
+
+	err := eng.ValidateWorkflow(workflow)
+	var validationErr *engine.ValidationError
+	require.ErrorAs(t, err, &validationErr)
+
+	fields := make([]string, len(validationErr.Errors))
+	for i, fieldErr := range validationErr.Errors {
+		assert.Equal(t, "b", fieldErr.NodeID)
+		fields[i] = fieldErr.Field
+	}
+	assert.Equal(t, []string{"input_mapping.other", "input_mapping.source", "input_mapping.unknown"}, fields)
+}
+
+func TestEvaluateExpression(t *testing.T) {
+	payload := map[string]interface{}{
+		"nodes": map[string]interface{}{"fetch": map[string]interface{}{"items": []interface{}{map[string]interface{}{"id": 7.0}}}},
+		"vars":  map[string]interface{}{"region": "eu"},
+	}
+
+	result := engine.EvaluateExpression("{{nodes.fetch.items[0].id}}", payload)
+	assert.Equal(t, 7.0, result.Result)
+	assert.Equal(t, "number", result.Type)
+	assert.Empty(t, result.Missing)
+
+	result = engine.EvaluateExpression("{{vars.region}}/{{vars.zone}}", payload)
+	assert.Equal(t, "eu/", result.Result)
+	assert.Equal(t, "string", result.Type)
+	assert.Equal(t, []string{"vars.zone"}, result.Missing)
+
+	result = engine.EvaluateExpression("{{nodes.fetch.items", payload)
+	assert.Nil(t, result.Result)
+	assert.Contains(t, result.Error, "unbalanced braces")
+	assert.Contains(t, engine.EvaluateExpression("{{ }}", payload).Error, "empty")
+}
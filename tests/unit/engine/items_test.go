@@ -0,0 +1,163 @@
+package engine_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/nuumz/f1ow/internal/engine"
+	"github.com/nuumz/f1ow/internal/models"
+	"github.com/nuumz/f1ow/internal/nodes"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// checkNode fails for inputs with fail set and returns the rest
+type checkNode struct{ upstreamNode }
+
+func (n *checkNode) Execute(ctx context.Context, config interface{}, input interface{}) (interface{}, error) {
+	if fail, _ := input.(map[string]interface{})["fail"].(bool); fail {
+		return nil, errors.New("bad item")
+	}
+	return map[string]interface{}{"id": input.(map[string]interface{})["id"]}, nil
+}
+
+func itemsEngine(t *testing.T) *engine.Engine {
+	t.Helper()
+	eng := engine.NewEngine(nil, nil)
+	require.NoError(t, eng.RegisterNode("mirror", &mirrorNode{}))
+	require.NoError(t, eng.RegisterNode("check", &checkNode{}))
+	require.NoError(t, eng.RegisterNode("set", nodes.NewSetNode()))
+	return eng
+}
+
+func itemsWorkflow(nodes []models.Node, edges []models.Edge) *models.Workflow {
+	return &models.Workflow{Definition: models.WorkflowDefinition{
+		Nodes:    nodes,
+		Edges:    edges,
+		Settings: models.WorkflowSettings{ExecutionMode: models.ExecutionModeItems},
+	}}
+}
+
+// outputItems returns the JSON and paired item of each item a node produced
+func outputItems(t *testing.T, execution *models.Execution, nodeID string) ([]map[string]interface{}, []string) {
+	t.Helper()
+	output, ok := execution.Output[nodeID].(map[string]interface{})
+	require.True(t, ok, "node %s has no output", nodeID)
+	var items []map[string]interface{}
+	var paired []string
+	for _, value := range output["items"].([]interface{}) {
+		it := value.(map[string]interface{})
+		items = append(items, it["json"].(map[string]interface{}))
+		paired = append(paired, it["paired_item"].(string))
+	}
+	return items, paired
+}
+
+func orders(list ...map[string]interface{}) []interface{} {
+	values := make([]interface{}, len(list))
+	for i, order := range list {
+		values[i] = order
+	}
+	return values
+}
+
+func TestEngineRun_ItemsFanOut(t *testing.T) {
+	eng := itemsEngine(t)
+	workflow := itemsWorkflow([]models.Node{
+		{ID: "orders", Type: "mirror", Config: map[string]interface{}{"output": orders(
+			map[string]interface{}{"id": "A-1"},
+			map[string]interface{}{"id": "A-2"},
+		)}},
+		{ID: "tag", Type: "set", Config: map[string]interface{}{"operations": []interface{}{
+			map[string]interface{}{"operation": "set", "path": "label", "value": "order {{id}}"},
+		}}},
+	}, []models.Edge{{Source: "orders", Target: "tag"}})
+
+	execution, err := eng.Run(context.Background(), workflow, map[string]interface{}{"shop": "eu"})
+	require.NoError(t, err)
+
+	items, paired := outputItems(t, execution, "tag")
+	assert.Equal(t, []string{"0.0", "0.1"}, paired)
+	require.Len(t, items, 2)
+	assert.Equal(t, "order A-1", items[0]["label"])
+	assert.Equal(t, "order A-2", items[1]["label"])
+}
+
+func TestEngineRun_ItemsIsolateErrors(t *testing.T) {
+	eng := itemsEngine(t)
+	workflow := itemsWorkflow([]models.Node{
+		{ID: "orders", Type: "mirror", Config: map[string]interface{}{"output": orders(
+			map[string]interface{}{"id": "A-1"},
+			map[string]interface{}{"id": "A-2", "fail": true},
+			map[string]interface{}{"id": "A-3"},
+		)}},
+		{ID: "check", Type: "check"},
+		{ID: "after", Type: "mirror"},
+	}, []models.Edge{{Source: "orders", Target: "check"}, {Source: "check", Target: "after"}})
+
+	execution, err := eng.Run(context.Background(), workflow, nil)
+	require.NoError(t, err)
+
+	_, paired := outputItems(t, execution, "check")
+	assert.Equal(t, []string{"0.0", "0.2"}, paired)
+	errs := execution.Output["check"].(map[string]interface{})["errors"].([]interface{})
+	require.Len(t, errs, 1)
+	assert.Equal(t, "0.1", errs[0].(map[string]interface{})["paired_item"])
+	assert.Contains(t, errs[0].(map[string]interface{})["error"], "bad item")
+
+	_, paired = outputItems(t, execution, "after")
+	assert.Equal(t, []string{"0.0", "0.2"}, paired, "failed items go no further")
+}
+
+func TestEngineRun_ItemsFailWhenEveryItemFails(t *testing.T) {
+	eng := itemsEngine(t)
+	workflow := itemsWorkflow([]models.Node{{ID: "check", Type: "check"}}, nil)
+
+	_, err := eng.Run(context.Background(), workflow, map[string]interface{}{"fail": true})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed for all 1 items")
+}
+
+func TestEngineRun_ItemsPairAcrossMerge(t *testing.T) {
+	eng := itemsEngine(t)
+	workflow := itemsWorkflow([]models.Node{
+		{ID: "customer", Type: "mirror", Config: map[string]interface{}{"output": map[string]interface{}{"customer": "Ada"}}},
+		{ID: "orders", Type: "mirror", ItemsPath: "body.rows", Config: map[string]interface{}{"output": map[string]interface{}{
+			"body": map[string]interface{}{"rows": orders(map[string]interface{}{"id": "A-1"}, map[string]interface{}{"id": "A-2"})},
+		}}},
+		{ID: "merge", Type: "mirror"},
+		{ID: "label", Type: "mirror", InputMapping: map[string]string{"text": "{{nodes.customer.customer}} ordered {{nodes.orders.id}}"}},
+	}, []models.Edge{
+		{Source: "customer", Target: "orders"},
+		{Source: "customer", Target: "merge"},
+		{Source: "orders", Target: "merge"},
+		{Source: "merge", Target: "label"},
+	})
+
+	execution, err := eng.Run(context.Background(), workflow, nil)
+	require.NoError(t, err)
+
+	items, paired := outputItems(t, execution, "merge")
+	assert.Equal(t, []string{"0.0", "0.1"}, paired, "the merge runs once per order, paired with the customer")
+	require.Len(t, items, 2)
+	assert.Equal(t, "Ada", items[0]["customer"])
+	assert.Equal(t, "A-1", items[0]["id"])
+	assert.Equal(t, "A-2", items[1]["id"])
+
+	items, _ = outputItems(t, execution, "label")
+	require.Len(t, items, 2)
+	assert.Equal(t, "Ada ordered A-2", items[1]["text"])
+}
+
+func TestValidateWorkflow_ExecutionMode(t *testing.T) {
+	eng := itemsEngine(t)
+	workflow := itemsWorkflow([]models.Node{{ID: "a", Type: "mirror"}}, nil)
+	require.NoError(t, eng.ValidateWorkflow(workflow))
+
+	workflow.Definition.Settings.ExecutionMode = "batch"
+	var validationErr *engine.ValidationError
+	require.ErrorAs(t, eng.ValidateWorkflow(workflow), &validationErr)
+	assert.Equal(t, "settings.execution_mode", validationErr.Errors[0].Field)
+}
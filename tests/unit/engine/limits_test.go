@@ -0,0 +1,106 @@
+package engine_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nuumz/f1ow/internal/engine"
+	"github.com/nuumz/f1ow/internal/models"
+	"github.com/nuumz/f1ow/internal/nodes"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stuckNode ignores its context and never returns on its own
+type stuckNode struct {
+	upstreamNode
+	release chan struct{}
+}
+
+func (n *stuckNode) Execute(ctx context.Context, config interface{}, input interface{}) (interface{}, error) {
+	<-n.release
+	return nil, nil
+}
+
+func TestEngineRun_NodeTimeoutFailsStuckNode(t *testing.T) {
+	node := &stuckNode{release: make(chan struct{})}
+	defer close(node.release)
+	eng := engine.NewEngine(nil, nil, engine.WithNodeLimits(engine.NodeLimits{Timeout: 100 * time.Millisecond}))
+	require.NoError(t, eng.RegisterNode("stuck", node))
+
+	// A workflow cannot raise the engine's limit
+	workflow := &models.Workflow{Definition: models.WorkflowDefinition{
+		Nodes:    []models.Node{{ID: "stuck", Type: "stuck"}},
+		Settings: models.WorkflowSettings{NodeTimeout: 60},
+	}}
+
+	started := time.Now()
+	_, err := eng.Run(context.Background(), workflow, nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "did not finish within 100ms")
+	assert.Less(t, time.Since(started), 5*time.Second)
+}
+
+func TestEngineRun_CountsAbandonedNodeRuns(t *testing.T) {
+	node := &stuckNode{release: make(chan struct{})}
+	eng := engine.NewEngine(nil, nil, engine.WithNodeLimits(engine.NodeLimits{Timeout: 50 * time.Millisecond}))
+	require.NoError(t, eng.RegisterNode("abandoned", node))
+	gauge := eng.Metrics().AbandonedNodeRuns.WithLabelValues("abandoned")
+
+	workflow := &models.Workflow{Definition: models.WorkflowDefinition{
+		Nodes: []models.Node{{ID: "stuck", Type: "abandoned"}},
+	}}
+	_, err := eng.Run(context.Background(), workflow, nil)
+	require.Error(t, err)
+	assert.Equal(t, float64(1), testutil.ToFloat64(gauge))
+
+	// The run stops being counted once the node returns
+	close(node.release)
+	assert.Eventually(t, func() bool { return testutil.ToFloat64(gauge) == 0 }, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestEngineRun_WorkflowLowersOutputLimit(t *testing.T) {
+	eng := engine.NewEngine(nil, nil)
+	require.NoError(t, eng.RegisterNode("mirror", &mirrorNode{}))
+	workflow := &models.Workflow{Definition: models.WorkflowDefinition{
+		Nodes: []models.Node{{ID: "big", Type: "mirror", Config: map[string]interface{}{
+			"output": map[string]interface{}{"blob": strings.Repeat("x", 200)},
+		}}},
+		Settings: models.WorkflowSettings{MaxNodeOutputSize: 100},
+	}}
+
+	_, err := eng.Run(context.Background(), workflow, nil)
+	require.Error(t, err)
+	assert.ErrorIs(t, err, engine.ErrNodeLimitExceeded)
+
+	workflow.Definition.Settings.MaxNodeOutputSize = 1000
+	_, err = eng.Run(context.Background(), workflow, nil)
+	require.NoError(t, err)
+}
+
+func TestEngineRun_InputLimit(t *testing.T) {
+	eng := engine.NewEngine(nil, nil, engine.WithNodeLimits(engine.NodeLimits{MaxInputSize: 100}))
+	require.NoError(t, eng.RegisterNode("mirror", &mirrorNode{}))
+	workflow := &models.Workflow{Definition: models.WorkflowDefinition{
+		Nodes: []models.Node{{ID: "echo", Type: "mirror"}},
+	}}
+
+	_, err := eng.Run(context.Background(), workflow, map[string]interface{}{"blob": strings.Repeat("x", 200)})
+	assert.ErrorIs(t, err, engine.ErrNodeLimitExceeded)
+}
+
+func TestEngineRun_NodeTimeoutInterruptsScript(t *testing.T) {
+	eng := engine.NewEngine(nil, nil, engine.WithNodeLimits(engine.NodeLimits{Timeout: 100 * time.Millisecond}))
+	require.NoError(t, eng.RegisterNode("transform", nodes.NewTransformNode()))
+	workflow := &models.Workflow{Definition: models.WorkflowDefinition{
+		Nodes: []models.Node{{ID: "spin", Type: "transform", Config: map[string]interface{}{"code": "while (true) {}"}}},
+	}}
+
+	_, err := eng.Run(context.Background(), workflow, nil)
+	require.Error(t, err)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
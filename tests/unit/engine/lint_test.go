@@ -0,0 +1,103 @@
+package engine_test
+
+import (
+	"testing"
+
+	"github.com/nuumz/f1ow/internal/engine"
+	"github.com/nuumz/f1ow/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// lintEngine returns an engine with a request node, whose token field is a
+// password, and a branch node emitting on yes and no
+func lintEngine(t *testing.T, opts ...engine.Option) *engine.Engine {
+	request := &MockNode{}
+	request.On("Name").Return("HTTP Request")
+	request.On("GetSchema").Return(engine.NodeSchema{Properties: map[string]engine.Property{
+		"url":   {Type: "string"},
+		"token": {Type: "string", Format: "password"},
+	}})
+	branch := &MockNode{}
+	branch.On("Name").Return("Branch")
+	branch.On("GetSchema").Return(engine.NodeSchema{Outputs: []engine.PortSchema{{Name: "yes"}, {Name: "no"}}})
+
+	eng := engine.NewEngine(nil, nil, opts...)
+	require.NoError(t, eng.RegisterNode("request", request))
+	require.NoError(t, eng.RegisterNode("branch", branch))
+	return eng
+}
+
+func lintRules(issues []engine.LintIssue) map[string][]engine.LintIssue {
+	rules := make(map[string][]engine.LintIssue)
+	for _, issue := range issues {
+		rules[issue.Rule] = append(rules[issue.Rule], issue)
+	}
+	return rules
+}
+
+func TestLintWorkflow_Rules(t *testing.T) {
+	eng := lintEngine(t)
+	workflow := &models.Workflow{Definition: models.WorkflowDefinition{
+		Nodes: []models.Node{
+			{ID: "fetch", Type: "request", Name: "Fetch orders", Config: map[string]interface{}{
+				"url":               "https://api.example.com/{{vars.region}}",
+				"token":             "s3cr3t",
+				"headers":           map[string]interface{}{"X-API-Key": "abc", "Accept": "application/json"},
+				"ignore_ssl_issues": true,
+			}},
+			{ID: "check", Type: "branch", Name: "Branch 2"},
+			{ID: "notify", Type: "request", Name: "Notify", Config: map[string]interface{}{"token": "{{vars.token}}"}},
+		},
+		Edges:     []models.Edge{{Source: "fetch", Target: "check"}, {Source: "check", SourcePort: "yes", Target: "notify"}},
+		Variables: map[string]interface{}{"region": "eu", "retries": 3},
+	}}
+
+	rules := lintRules(eng.LintWorkflow(workflow, ""))
+
+	credentials := rules[engine.LintHardcodedCredential]
+	require.Len(t, credentials, 2, "templated secrets and non-secret headers pass")
+	assert.Equal(t, "headers.X-API-Key", credentials[0].Field)
+	assert.Equal(t, "token", credentials[1].Field)
+	assert.Equal(t, engine.LintWarning, credentials[0].Severity)
+	assert.NotEmpty(t, credentials[0].Hint)
+
+	require.Len(t, rules[engine.LintUnusedVariable], 1)
+	assert.Equal(t, "variables.retries", rules[engine.LintUnusedVariable][0].Field)
+
+	require.Len(t, rules[engine.LintUnhandledBranch], 1)
+	assert.Equal(t, "check", rules[engine.LintUnhandledBranch][0].NodeID)
+	assert.Contains(t, rules[engine.LintUnhandledBranch][0].Message, "port no")
+
+	require.Len(t, rules[engine.LintDefaultNodeName], 1)
+	assert.Equal(t, "check", rules[engine.LintDefaultNodeName][0].NodeID)
+
+	assert.Empty(t, rules[engine.LintInsecureTLS], "insecure TLS is only an issue in production")
+	issues := eng.LintWorkflow(workflow, "production")
+	assert.Equal(t, engine.LintInsecureTLS, issues[0].Rule, "errors come first")
+	assert.Equal(t, engine.LintError, issues[0].Severity)
+	assert.Equal(t, "fetch", issues[0].NodeID)
+}
+
+func TestLintWorkflow_Options(t *testing.T) {
+	severities, err := engine.ParseLintSeverities("hardcoded_credential=error, default_node_name=off")
+	require.NoError(t, err)
+	eng := lintEngine(t,
+		engine.WithEnvironment("live"),
+		engine.WithLintOptions(engine.LintOptions{Severities: severities, ProductionEnvironments: []string{"live"}}),
+	)
+
+	workflow := &models.Workflow{Definition: models.WorkflowDefinition{Nodes: []models.Node{
+		{ID: "fetch", Type: "request", Config: map[string]interface{}{"token": "s3cr3t", "ignore_ssl_issues": true}},
+	}}}
+	rules := lintRules(eng.LintWorkflow(workflow, ""))
+	assert.Equal(t, engine.LintError, rules[engine.LintHardcodedCredential][0].Severity)
+	assert.Len(t, rules[engine.LintInsecureTLS], 1, "the engine's environment is a production one")
+	assert.Empty(t, rules[engine.LintDefaultNodeName])
+
+	_, err = engine.ParseLintSeverities("spelling=error")
+	assert.Error(t, err)
+	_, err = engine.ParseLintSeverities("insecure_tls=fatal")
+	assert.Error(t, err)
+}
@@ -0,0 +1,44 @@
+package engine_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/nuumz/f1ow/internal/engine"
+	"github.com/nuumz/f1ow/internal/models"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEngineRun_RecordsOutcomeMetrics(t *testing.T) {
+	eng := engine.NewEngine(nil, nil)
+	metrics := eng.Metrics()
+	require.NoError(t, eng.RegisterNode("upstream", &upstreamNode{}))
+	ok := &MockNode{}
+	ok.On("Execute", mock.Anything, mock.Anything, mock.Anything).Return(map[string]interface{}{"ok": true}, nil)
+	require.NoError(t, eng.RegisterNode("metrics_ok", ok))
+
+	succeeded := testutil.ToFloat64(metrics.WorkflowsSucceeded)
+	failed := testutil.ToFloat64(metrics.WorkflowsFailed)
+	nodeErrors := testutil.ToFloat64(metrics.NodeErrors.WithLabelValues("upstream", engine.NodeErrorExecution))
+	missing := testutil.ToFloat64(metrics.NodeErrors.WithLabelValues("absent", engine.NodeErrorNotRegistered))
+
+	run := func(nodeType string) error {
+		_, err := eng.Run(context.Background(), &models.Workflow{Definition: models.WorkflowDefinition{
+			Nodes: []models.Node{{ID: "node", Type: nodeType}},
+		}}, nil)
+		return err
+	}
+	require.NoError(t, run("metrics_ok"))
+	assert.Error(t, run("upstream"))
+	assert.Error(t, run("absent"))
+
+	assert.Equal(t, succeeded+1, testutil.ToFloat64(metrics.WorkflowsSucceeded))
+	assert.Equal(t, failed+2, testutil.ToFloat64(metrics.WorkflowsFailed))
+	assert.Equal(t, nodeErrors+1, testutil.ToFloat64(metrics.NodeErrors.WithLabelValues("upstream", engine.NodeErrorExecution)))
+	assert.Equal(t, missing+1, testutil.ToFloat64(metrics.NodeErrors.WithLabelValues("absent", engine.NodeErrorNotRegistered)))
+	assert.Equal(t, float64(0), testutil.ToFloat64(metrics.ActiveWorkflows))
+}
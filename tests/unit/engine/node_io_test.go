@@ -0,0 +1,78 @@
+package engine_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/nuumz/f1ow/internal/engine"
+	"github.com/nuumz/f1ow/internal/models"
+	"github.com/nuumz/f1ow/internal/nodes"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEngineRun_PassesConfigAndInputInOrder(t *testing.T) {
+	eng := engine.NewEngine(nil, nil)
+	require.NoError(t, eng.RegisterNode("set", nodes.NewSetNode()))
+
+	workflow := &models.Workflow{Definition: models.WorkflowDefinition{
+		Nodes: []models.Node{{ID: "greet", Type: "set", Config: map[string]interface{}{
+			"keep_only_set": true,
+			"operations": []interface{}{
+				map[string]interface{}{"operation": "set", "path": "greeting", "value": "Hello {{name}}"},
+			},
+		}}},
+	}}
+
+	execution, err := eng.Run(context.Background(), workflow, map[string]interface{}{"name": "Ada"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"greeting": "Hello Ada"}, execution.Output["greet"])
+}
+
+// typedNode records what it runs with
+type typedNode struct {
+	upstreamNode
+	config engine.NodeConfig
+	input  engine.NodeInput
+}
+
+func (n *typedNode) Run(ctx context.Context, config engine.NodeConfig, input engine.NodeInput) (engine.NodeOutput, error) {
+	n.config, n.input = config, input
+	return engine.NodeOutput{Data: map[string]interface{}{"ok": true}}, nil
+}
+
+func TestEngineRun_TypedNode(t *testing.T) {
+	eng := engine.NewEngine(nil, nil)
+	node := &typedNode{}
+	require.NoError(t, eng.RegisterNode("typed", node))
+
+	file := map[string]interface{}{"binary_id": "8d0c3b6e-3f7a-4a52-9f43-6f1f5ad2b1c4", "file_name": "a.csv", "mime_type": "text/csv", "size": float64(12)}
+	workflow := &models.Workflow{Definition: models.WorkflowDefinition{
+		Nodes: []models.Node{{ID: "t", Type: "typed", Config: map[string]interface{}{"mode": "fast"}}},
+	}}
+	execution, err := eng.Run(context.Background(), workflow, map[string]interface{}{"file": file})
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]interface{}{"ok": true}, execution.Output["t"])
+	assert.Equal(t, engine.NodeConfig{"mode": "fast"}, node.config)
+	assert.Equal(t, file, node.input.Data["file"])
+	assert.Equal(t, "t", node.input.Metadata.NodeID)
+	assert.Equal(t, "typed", node.input.Metadata.NodeType)
+	require.Contains(t, node.input.Binary, "file")
+	assert.Equal(t, "a.csv", node.input.Binary["file"].FileName)
+	assert.Equal(t, int64(12), node.input.Binary["file"].Size)
+}
+
+// listNode returns a list instead of an object
+type listNode struct{ upstreamNode }
+
+func (n *listNode) Execute(ctx context.Context, config interface{}, input interface{}) (interface{}, error) {
+	return []interface{}{"a", "b"}, nil
+}
+
+func TestAsTypedNode_WrapsNonObjectOutput(t *testing.T) {
+	output, err := engine.AsTypedNode(&listNode{}).Run(context.Background(), nil, engine.NodeInput{})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"data": []interface{}{"a", "b"}}, output.Data)
+}
@@ -0,0 +1,60 @@
+package engine_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/nuumz/f1ow/internal/engine"
+	"github.com/nuumz/f1ow/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// versionNode reports the version of its type it is
+type versionNode struct {
+	promptNode
+	version int
+}
+
+func (n *versionNode) Execute(ctx context.Context, config interface{}, input interface{}) (interface{}, error) {
+	return map[string]interface{}{"version": n.version}, nil
+}
+
+func TestEngineRun_NodesRunTheirPinnedVersion(t *testing.T) {
+	eng := engine.NewEngine(nil, nil)
+	require.NoError(t, eng.RegisterNode("greet", &versionNode{version: 1}))
+	require.NoError(t, eng.RegisterNode("greet@v2", &versionNode{version: 2}))
+
+	workflow := &models.Workflow{Definition: models.WorkflowDefinition{
+		Nodes: []models.Node{{ID: "legacy", Type: "greet"}, {ID: "current", Type: "greet", Version: 2}},
+	}}
+	execution, err := eng.Run(context.Background(), workflow, nil)
+	require.NoError(t, err)
+
+	nodes := execution.Context.NodeExecutions
+	assert.Equal(t, 1, nodes["legacy"].Output["version"], "nodes without a version run version 1")
+	assert.Equal(t, 2, nodes["current"].Output["version"])
+}
+
+func TestEngine_DeprecatedNodeUsages(t *testing.T) {
+	eng := engine.NewEngine(nil, nil)
+	require.NoError(t, eng.RegisterNode("greet", &versionNode{version: 1}))
+	require.NoError(t, eng.RegisterNode("greet@v2", &versionNode{version: 2}))
+	require.NoError(t, eng.DeprecateNode("greet@v1", "greet@v2 takes the name from config.name"))
+
+	legacy := models.Workflow{ID: uuid.New(), Name: "legacy", Definition: models.WorkflowDefinition{
+		Nodes: []models.Node{{ID: "hello", Type: "greet"}, {ID: "bye", Type: "greet", Version: 2}},
+	}}
+	current := models.Workflow{ID: uuid.New(), Name: "current", Definition: models.WorkflowDefinition{
+		Nodes: []models.Node{{ID: "hello", Type: "greet", Version: 2}},
+	}}
+
+	usages := eng.DeprecatedNodeUsages([]models.Workflow{legacy, current})
+	require.Len(t, usages, 1)
+	assert.Equal(t, engine.DeprecatedNodeUsage{
+		WorkflowID: legacy.ID, WorkflowName: "legacy", NodeID: "hello", Type: "greet",
+		Version: 1, LatestVersion: 2, Message: "greet@v2 takes the name from config.name",
+	}, usages[0])
+}
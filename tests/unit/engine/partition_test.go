@@ -0,0 +1,165 @@
+package engine_test
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/nuumz/f1ow/internal/engine"
+	"github.com/nuumz/f1ow/internal/models"
+	"github.com/nuumz/f1ow/internal/storage"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryQueue_PartitionsRunOneAtATime(t *testing.T) {
+	ctx := context.Background()
+	queue := engine.NewMemoryQueue(nil)
+
+	for _, job := range []*engine.Job{
+		{ID: "acme-1", Partition: "acme"},
+		{ID: "acme-2", Partition: "acme"},
+		{ID: "globex-1", Partition: "globex"},
+		{ID: "unpartitioned"},
+	} {
+		require.NoError(t, queue.Enqueue(ctx, job))
+		time.Sleep(time.Millisecond)
+	}
+	size, err := queue.Size(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(4), size)
+
+	// acme-2 waits while acme-1 holds the partition
+	var taken []*engine.Job
+	for {
+		job, err := queue.Dequeue(ctx)
+		require.NoError(t, err)
+		if job == nil {
+			break
+		}
+		taken = append(taken, job)
+	}
+	require.Len(t, taken, 3)
+	assert.Equal(t, "acme-1", taken[0].ID)
+	assert.Equal(t, "globex-1", taken[1].ID)
+	assert.Equal(t, "unpartitioned", taken[2].ID)
+
+	require.NoError(t, queue.ReleasePartition(ctx, taken[0]))
+	job, err := queue.Dequeue(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, job)
+	assert.Equal(t, "acme-2", job.ID)
+}
+
+func TestMemoryQueue_RestoresPartitionOrder(t *testing.T) {
+	ctx := context.Background()
+	store := newMapQueueStore()
+
+	queue := engine.NewMemoryQueue(store)
+	for i := 1; i <= 3; i++ {
+		require.NoError(t, queue.Enqueue(ctx, &engine.Job{ID: fmt.Sprintf("acme-%d", i), Partition: "acme"}))
+		time.Sleep(time.Millisecond)
+	}
+
+	restarted := engine.NewMemoryQueue(store)
+	require.NoError(t, restarted.Load(ctx))
+	for i := 1; i <= 3; i++ {
+		job, err := restarted.Dequeue(ctx)
+		require.NoError(t, err)
+		require.NotNil(t, job)
+		assert.Equal(t, fmt.Sprintf("acme-%d", i), job.ID)
+		require.NoError(t, restarted.ReleasePartition(ctx, job))
+	}
+}
+
+func TestJobPartition(t *testing.T) {
+	workflow := &models.Workflow{ID: uuid.New()}
+	input := map[string]interface{}{"customer": map[string]interface{}{"id": 42}}
+	assert.Empty(t, engine.JobPartition(workflow, input))
+
+	workflow.Definition.Settings.PartitionKey = "customer.id"
+	assert.Equal(t, workflow.ID.String()+"/42", engine.JobPartition(workflow, input))
+	assert.Empty(t, engine.JobPartition(workflow, map[string]interface{}{}))
+}
+
+// sequenceNode records the order each customer's runs start in and the
+// most of a customer's runs in progress at once
+type sequenceNode struct {
+	upstreamNode
+	mu              sync.Mutex
+	order           map[string][]float64
+	running         map[string]int
+	most, mostTotal int
+	total           int
+}
+
+func (n *sequenceNode) Execute(ctx context.Context, config interface{}, input interface{}) (interface{}, error) {
+	data := input.(map[string]interface{})
+	customer := data["customer"].(string)
+
+	n.mu.Lock()
+	n.order[customer] = append(n.order[customer], data["seq"].(float64))
+	n.running[customer]++
+	n.total++
+	n.most = max(n.most, n.running[customer])
+	n.mostTotal = max(n.mostTotal, n.total)
+	n.mu.Unlock()
+
+	time.Sleep(30 * time.Millisecond)
+
+	n.mu.Lock()
+	n.running[customer]--
+	n.total--
+	n.mu.Unlock()
+	return map[string]interface{}{"ok": true}, nil
+}
+
+func TestWorker_RunsPartitionsSeriallyInOrder(t *testing.T) {
+	node := &sequenceNode{order: map[string][]float64{}, running: map[string]int{}}
+	repo := storage.NewMemoryRepository()
+	eng := engine.NewEngine(repo, nil,
+		engine.WithQueue(engine.NewMemoryQueue(nil)),
+		engine.WithWorker(engine.WorkerOptions{Concurrency: 4}))
+	require.NoError(t, eng.RegisterNode("sequence", node))
+	ctx := context.Background()
+
+	workflow := &models.Workflow{Name: "sync", Definition: models.WorkflowDefinition{
+		Nodes:    []models.Node{{ID: "run", Type: "sequence"}},
+		Settings: models.WorkflowSettings{PartitionKey: "customer"},
+	}}
+	require.NoError(t, repo.CreateWorkflow(ctx, workflow))
+
+	var executions []*models.Execution
+	for seq := 1; seq <= 4; seq++ {
+		for _, customer := range []string{"acme", "globex"} {
+			execution, err := eng.Submit(ctx, workflow.ID.String(), "", map[string]interface{}{"customer": customer, "seq": float64(seq)})
+			require.NoError(t, err)
+			executions = append(executions, execution)
+		}
+	}
+
+	workerCtx, stop := context.WithTimeout(ctx, 15*time.Second)
+	defer stop()
+	go eng.StartWorker(workerCtx)
+
+	require.Eventually(t, func() bool {
+		for _, execution := range executions {
+			stored, err := repo.GetExecution(ctx, execution.ID)
+			if err != nil || stored.Status != models.ExecutionStatusCompleted {
+				return false
+			}
+		}
+		return true
+	}, 15*time.Second, 50*time.Millisecond)
+
+	node.mu.Lock()
+	defer node.mu.Unlock()
+	assert.Equal(t, []float64{1, 2, 3, 4}, node.order["acme"])
+	assert.Equal(t, []float64{1, 2, 3, 4}, node.order["globex"])
+	assert.Equal(t, 1, node.most, "a customer's executions never overlap")
+	assert.Equal(t, 2, node.mostTotal, "different customers run in parallel")
+}
@@ -0,0 +1,51 @@
+package engine_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/nuumz/f1ow/internal/engine"
+	"github.com/nuumz/f1ow/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// upstreamNode stands in for a node that calls an external API
+type upstreamNode struct {
+	calls int
+}
+
+func (n *upstreamNode) Execute(ctx context.Context, config interface{}, input interface{}) (interface{}, error) {
+	n.calls++
+	return nil, errors.New("upstream unavailable")
+}
+func (n *upstreamNode) ValidateConfig(config interface{}) error { return nil }
+func (n *upstreamNode) GetSchema() engine.NodeSchema            { return engine.NodeSchema{} }
+func (n *upstreamNode) Type() string                            { return "upstream" }
+func (n *upstreamNode) Name() string                            { return "Upstream" }
+func (n *upstreamNode) Description() string                     { return "" }
+func (n *upstreamNode) Category() string                        { return "" }
+func (n *upstreamNode) Icon() string                            { return "" }
+
+func TestEngineRun_PinnedData(t *testing.T) {
+	eng := engine.NewEngine(nil, nil)
+	node := &upstreamNode{}
+	require.NoError(t, eng.RegisterNode("upstream", node))
+
+	pinned := map[string]interface{}{"orders": []interface{}{map[string]interface{}{"id": "A-1"}}}
+	workflow := &models.Workflow{Definition: models.WorkflowDefinition{
+		Nodes: []models.Node{{ID: "fetch", Type: "upstream", PinnedData: pinned}},
+	}}
+
+	// Pinned data only replaces the node in runs that ask for it
+	_, err := eng.Run(context.Background(), workflow, nil)
+	assert.Error(t, err)
+	assert.Equal(t, 1, node.calls)
+
+	execution, err := eng.Run(engine.WithPinnedData(context.Background()), workflow, nil)
+	require.NoError(t, err)
+	assert.Equal(t, 1, node.calls)
+	assert.Equal(t, pinned, execution.Output["fetch"])
+}
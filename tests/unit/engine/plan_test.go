@@ -0,0 +1,70 @@
+package engine_test
+
+import (
+	"testing"
+
+	"github.com/nuumz/f1ow/internal/engine"
+	"github.com/nuumz/f1ow/internal/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// planDefinition builds a definition of nodes in the given order, with an
+// edge for each source/target pair
+func planDefinition(nodeIDs []string, edges ...[2]string) *models.WorkflowDefinition {
+	definition := &models.WorkflowDefinition{}
+	for _, id := range nodeIDs {
+		definition.Nodes = append(definition.Nodes, models.Node{ID: id, Type: "mirror"})
+	}
+	for _, edge := range edges {
+		definition.Edges = append(definition.Edges, models.Edge{Source: edge[0], Target: edge[1]})
+	}
+	return definition
+}
+
+func TestPlanWorkflow_OrderAndGroups(t *testing.T) {
+	// start fans out to a and b, which both feed join
+	definition := planDefinition([]string{"join", "b", "a", "start"},
+		[2]string{"start", "a"}, [2]string{"start", "b"}, [2]string{"a", "join"}, [2]string{"b", "join"})
+
+	plan := engine.PlanWorkflow(definition, nil)
+
+	assert.Equal(t, []string{"start", "b", "a", "join"}, plan.Order)
+	assert.Equal(t, [][]string{{"start"}, {"b", "a"}, {"join"}}, plan.Groups)
+	assert.Empty(t, plan.Cycles)
+	assert.ElementsMatch(t, []string{"join", "b", "a", "start"}, plan.Unestimated)
+}
+
+func TestPlanWorkflow_CriticalPath(t *testing.T) {
+	definition := planDefinition([]string{"start", "fast", "slow", "join"},
+		[2]string{"start", "fast"}, [2]string{"start", "slow"}, [2]string{"fast", "join"}, [2]string{"slow", "join"})
+	durations := map[string]float64{"start": 10, "fast": 5, "slow": 200, "join": 20}
+
+	plan := engine.PlanWorkflow(definition, durations)
+
+	assert.Equal(t, []string{"start", "slow", "join"}, plan.CriticalPath)
+	assert.Equal(t, 230.0, plan.CriticalPathMs)
+	assert.Equal(t, durations, plan.NodeDurationsMs)
+	assert.Empty(t, plan.Unestimated)
+}
+
+func TestPlanWorkflow_ReportsCycleChains(t *testing.T) {
+	definition := planDefinition([]string{"start", "a", "b", "c", "after", "loop"},
+		[2]string{"start", "a"}, [2]string{"a", "b"}, [2]string{"b", "c"}, [2]string{"c", "a"},
+		[2]string{"c", "after"}, [2]string{"loop", "loop"})
+
+	plan := engine.PlanWorkflow(definition, nil)
+
+	assert.ElementsMatch(t, [][]string{{"a", "b", "c", "a"}, {"loop", "loop"}}, plan.Cycles)
+	// Nodes on or behind a cycle never become ready
+	assert.Equal(t, []string{"start"}, plan.Order)
+	assert.Equal(t, []string{"start"}, plan.CriticalPath)
+}
+
+func TestPlanWorkflow_Empty(t *testing.T) {
+	plan := engine.PlanWorkflow(&models.WorkflowDefinition{}, nil)
+
+	assert.Empty(t, plan.Order)
+	assert.Empty(t, plan.CriticalPath)
+	assert.Zero(t, plan.CriticalPathMs)
+}
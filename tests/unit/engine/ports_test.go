@@ -0,0 +1,127 @@
+package engine_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/nuumz/f1ow/internal/engine"
+	"github.com/nuumz/f1ow/internal/models"
+	"github.com/nuumz/f1ow/internal/nodes"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func routingEngine(t *testing.T) *engine.Engine {
+	t.Helper()
+	eng := engine.NewEngine(nil, nil)
+	require.NoError(t, eng.RegisterNode("conditional", nodes.NewConditionalNode()))
+	require.NoError(t, eng.RegisterNode("filter", nodes.NewFilterNode()))
+	require.NoError(t, eng.RegisterNode("mirror", &mirrorNode{}))
+	return eng
+}
+
+// branchWorkflow checks the input's amount and has yes on the true port,
+// no and after-no on the false port, and always after an edge without one
+func branchWorkflow() *models.Workflow {
+	return &models.Workflow{Definition: models.WorkflowDefinition{
+		Nodes: []models.Node{
+			{ID: "check", Type: "conditional", Config: map[string]interface{}{
+				"conditions": []interface{}{
+					map[string]interface{}{"field": "amount", "operator": ">", "value": 100, "output": map[string]interface{}{"large": true}},
+				},
+			}},
+			{ID: "yes", Type: "mirror"},
+			{ID: "no", Type: "mirror"},
+			{ID: "after-no", Type: "mirror"},
+			{ID: "always", Type: "mirror"},
+		},
+		Edges: []models.Edge{
+			{Source: "check", SourcePort: "true", Target: "yes"},
+			{Source: "check", SourcePort: "false", Target: "no"},
+			{Source: "no", Target: "after-no"},
+			{Source: "check", Target: "always"},
+		},
+	}}
+}
+
+func TestEngineRun_RoutesByOutputPort(t *testing.T) {
+	eng := routingEngine(t)
+
+	execution, err := eng.Run(context.Background(), branchWorkflow(), map[string]interface{}{"amount": 250})
+	require.NoError(t, err)
+	assert.Contains(t, execution.Output, "yes")
+	assert.Contains(t, execution.Output, "always")
+	assert.NotContains(t, execution.Output, "no")
+	assert.NotContains(t, execution.Output, "after-no", "nodes after a skipped one are skipped too")
+
+	execution, err = eng.Run(context.Background(), branchWorkflow(), map[string]interface{}{"amount": 5})
+	require.NoError(t, err)
+	assert.NotContains(t, execution.Output, "yes")
+	assert.Contains(t, execution.Output, "no")
+	assert.Contains(t, execution.Output, "after-no")
+}
+
+func TestEngineRun_ConditionPorts(t *testing.T) {
+	eng := routingEngine(t)
+	workflow := &models.Workflow{Definition: models.WorkflowDefinition{
+		Nodes: []models.Node{
+			{ID: "route", Type: "conditional", Config: map[string]interface{}{
+				"conditions": []interface{}{
+					map[string]interface{}{"field": "region", "operator": "equals", "value": "eu", "port": "eu"},
+					map[string]interface{}{"field": "region", "operator": "equals", "value": "us", "port": "us"},
+				},
+			}},
+			{ID: "eu", Type: "mirror"},
+			{ID: "us", Type: "mirror"},
+			{ID: "merge", Type: "mirror"},
+		},
+		Edges: []models.Edge{
+			{Source: "route", SourcePort: "eu", Target: "eu"},
+			{Source: "route", SourcePort: "us", Target: "us"},
+			{Source: "eu", Target: "merge"},
+			{Source: "us", Target: "merge"},
+		},
+	}}
+
+	execution, err := eng.Run(context.Background(), workflow, map[string]interface{}{"region": "us"})
+	require.NoError(t, err)
+	assert.NotContains(t, execution.Output, "eu")
+	assert.Contains(t, execution.Output, "us")
+	assert.Contains(t, execution.Output, "merge", "a node runs when any edge into it is taken")
+}
+
+func TestEngineRun_FilterPorts(t *testing.T) {
+	eng := routingEngine(t)
+	workflow := func(items ...interface{}) *models.Workflow {
+		return &models.Workflow{Definition: models.WorkflowDefinition{
+			Nodes: []models.Node{
+				{ID: "split", Type: "filter", Config: map[string]interface{}{
+					"array_path": "orders",
+					"groups": []interface{}{map[string]interface{}{"conditions": []interface{}{
+						map[string]interface{}{"field": "paid", "operator": "equals", "value": true},
+					}}},
+				}},
+				{ID: "ship", Type: "mirror", InputMapping: map[string]string{"orders": "{{nodes.split.matched}}"}},
+				{ID: "remind", Type: "mirror"},
+			},
+			Edges: []models.Edge{
+				{Source: "split", SourcePort: "matched", Target: "ship"},
+				{Source: "split", SourcePort: "unmatched", Target: "remind"},
+			},
+		}}
+	}
+
+	paid := map[string]interface{}{"id": "A-1", "paid": true}
+	execution, err := eng.Run(context.Background(), workflow(paid), map[string]interface{}{"orders": []interface{}{paid}})
+	require.NoError(t, err)
+	assert.Equal(t, []interface{}{paid}, execution.Output["ship"].(map[string]interface{})["orders"])
+	assert.NotContains(t, execution.Output, "remind")
+
+	// Neither port has items, so neither branch runs
+	execution, err = eng.Run(context.Background(), workflow(), map[string]interface{}{"orders": []interface{}{}})
+	require.NoError(t, err)
+	assert.Contains(t, execution.Output, "split")
+	assert.NotContains(t, execution.Output, "ship")
+	assert.NotContains(t, execution.Output, "remind")
+}
@@ -0,0 +1,206 @@
+package engine_test
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/nuumz/f1ow/internal/engine"
+	"github.com/nuumz/f1ow/internal/storage"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mapQueueStore persists queued jobs in a map
+type mapQueueStore struct {
+	mu   sync.Mutex
+	jobs map[string]storage.QueuedJob
+}
+
+func newMapQueueStore() *mapQueueStore {
+	return &mapQueueStore{jobs: map[string]storage.QueuedJob{}}
+}
+
+func (s *mapQueueStore) SaveQueuedJob(ctx context.Context, job storage.QueuedJob) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.jobs[job.ID] = job
+	return nil
+}
+
+func (s *mapQueueStore) DeleteQueuedJob(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.jobs, id)
+	return nil
+}
+
+func (s *mapQueueStore) ListQueuedJobs(ctx context.Context) ([]storage.QueuedJob, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	jobs := []storage.QueuedJob{}
+	for _, job := range s.jobs {
+		jobs = append(jobs, job)
+	}
+	return jobs, nil
+}
+
+func TestMemoryQueue_OrdersByScore(t *testing.T) {
+	ctx := context.Background()
+	queue := engine.NewMemoryQueue(nil)
+
+	require.NoError(t, queue.Enqueue(ctx, &engine.Job{ID: "low", Priority: 5}))
+	require.NoError(t, queue.Enqueue(ctx, &engine.Job{ID: "high", Priority: 1}))
+	require.NoError(t, queue.Enqueue(ctx, &engine.Job{ID: "also-high", Priority: 1}))
+
+	size, err := queue.Size(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), size)
+
+	peeked, err := queue.Peek(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "high", peeked.ID)
+
+	for _, want := range []string{"high", "also-high", "low"} {
+		job, err := queue.Dequeue(ctx)
+		require.NoError(t, err)
+		require.NotNil(t, job)
+		assert.Equal(t, want, job.ID)
+	}
+	job, err := queue.Dequeue(ctx)
+	require.NoError(t, err)
+	assert.Nil(t, job)
+}
+
+func TestMemoryQueue_DequeueForPrefersMatchingLabels(t *testing.T) {
+	ctx := context.Background()
+	queue := engine.NewMemoryQueue(nil)
+
+	require.NoError(t, queue.Enqueue(ctx, &engine.Job{ID: "plain"}))
+	require.NoError(t, queue.Enqueue(ctx, &engine.Job{ID: "gpu", Labels: map[string]string{"gpu": "true"}}))
+
+	// Workers without the label never see the pinned job
+	job, err := queue.DequeueFor(ctx, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "plain", job.ID)
+	job, err = queue.DequeueFor(ctx, map[string]string{"region": "eu"})
+	require.NoError(t, err)
+	assert.Nil(t, job)
+
+	job, err = queue.DequeueFor(ctx, map[string]string{"gpu": "true", "region": "eu"})
+	require.NoError(t, err)
+	require.NotNil(t, job)
+	assert.Equal(t, "gpu", job.ID)
+}
+
+func TestMemoryQueue_DelayedJobs(t *testing.T) {
+	ctx := context.Background()
+	queue := engine.NewMemoryQueue(nil)
+
+	require.NoError(t, queue.ScheduleJob(ctx, &engine.Job{ID: "due"}, time.Now().Add(-time.Second)))
+	require.NoError(t, queue.ScheduleJob(ctx, &engine.Job{ID: "later"}, time.Now().Add(time.Hour)))
+
+	stats, err := queue.Stats(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), stats.Delayed)
+	assert.Equal(t, int64(0), stats.Pending)
+
+	require.NoError(t, queue.ProcessDelayedJobs(ctx))
+	job, err := queue.Dequeue(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, job)
+	assert.Equal(t, "due", job.ID)
+
+	stats, err = queue.Stats(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), stats.Delayed)
+}
+
+func TestMemoryQueue_RestoresPersistedJobs(t *testing.T) {
+	ctx := context.Background()
+	store := newMapQueueStore()
+
+	queue := engine.NewMemoryQueue(store)
+	require.NoError(t, queue.Enqueue(ctx, &engine.Job{ID: "first", WorkflowID: "wf"}))
+	require.NoError(t, queue.Enqueue(ctx, &engine.Job{ID: "second", WorkflowID: "wf"}))
+	job, err := queue.Dequeue(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "first", job.ID)
+
+	restarted := engine.NewMemoryQueue(store)
+	require.NoError(t, restarted.Load(ctx))
+	job, err = restarted.Dequeue(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, job)
+	assert.Equal(t, "second", job.ID)
+	assert.Equal(t, "wf", job.WorkflowID)
+	assert.Empty(t, store.jobs)
+}
+
+func TestMemoryQueue_FairnessTakesTurnsBetweenTenants(t *testing.T) {
+	ctx := context.Background()
+	queue := engine.NewMemoryQueue(nil)
+
+	// Jobs queued before fairness is set move to their tenants' lanes
+	for i := 0; i < 4; i++ {
+		require.NoError(t, queue.Enqueue(ctx, &engine.Job{ID: fmt.Sprintf("busy-%d", i), TenantID: "busy"}))
+	}
+	queue.SetFairness(engine.Fairness{By: engine.FairnessTenant})
+	require.NoError(t, queue.Enqueue(ctx, &engine.Job{ID: "quiet-0", TenantID: "quiet"}))
+	require.NoError(t, queue.Enqueue(ctx, &engine.Job{ID: "quiet-1", TenantID: "quiet"}))
+
+	var order []string
+	for {
+		job, err := queue.Dequeue(ctx)
+		require.NoError(t, err)
+		if job == nil {
+			break
+		}
+		order = append(order, job.ID)
+	}
+	assert.Equal(t, []string{"busy-0", "quiet-0", "busy-1", "quiet-1", "busy-2", "busy-3"}, order)
+}
+
+func TestMemoryQueue_FairnessWeights(t *testing.T) {
+	ctx := context.Background()
+	queue := engine.NewMemoryQueue(nil)
+	weights, err := engine.ParseWeights("wf-heavy=3")
+	require.NoError(t, err)
+	queue.SetFairness(engine.Fairness{By: engine.FairnessWorkflow, Weights: weights})
+
+	for i := 0; i < 10; i++ {
+		require.NoError(t, queue.Enqueue(ctx, &engine.Job{WorkflowID: "wf-heavy"}))
+		require.NoError(t, queue.Enqueue(ctx, &engine.Job{WorkflowID: "wf-light"}))
+	}
+
+	served := map[string]int{}
+	for i := 0; i < 8; i++ {
+		job, err := queue.Dequeue(ctx)
+		require.NoError(t, err)
+		require.NotNil(t, job)
+		served[job.WorkflowID]++
+	}
+	assert.Equal(t, map[string]int{"wf-heavy": 6, "wf-light": 2}, served)
+
+	size, err := queue.Size(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(12), size)
+
+	_, err = engine.ParseWeights("wf-heavy=0")
+	assert.Error(t, err)
+}
+
+func TestEngine_EnqueueWithoutRedisUsesMemoryQueue(t *testing.T) {
+	eng := engine.NewEngine(nil, nil)
+
+	job, err := eng.Enqueue(context.Background(), "6f1c2d4e-8a3b-4c5d-9e7f-0a1b2c3d4e5f", nil)
+	require.NoError(t, err)
+
+	stats, err := eng.QueueStats(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), stats.Pending)
+	assert.NotEmpty(t, job.ExecutionID)
+}
@@ -0,0 +1,56 @@
+package engine_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/nuumz/f1ow/internal/engine"
+	"github.com/nuumz/f1ow/internal/models"
+	"github.com/nuumz/f1ow/internal/quota"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// hangNode runs until its context is done
+type hangNode struct{ promptNode }
+
+func (n *hangNode) Execute(ctx context.Context, config interface{}, input interface{}) (interface{}, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+// quotaStore holds every tenant to the same quotas
+type quotaStore struct{ quotas models.Quotas }
+
+func (s quotaStore) TenantQuotas(context.Context, uuid.UUID) (string, models.Quotas, error) {
+	return "", s.quotas, nil
+}
+func (s quotaStore) CountActiveWorkflows(context.Context, uuid.UUID) (int, error) { return 0, nil }
+func (s quotaStore) CountExecutionsSince(context.Context, uuid.UUID, time.Time) (int, error) {
+	return 0, nil
+}
+
+func TestEngineRun_StopsAtMaxDuration(t *testing.T) {
+	second := 1
+	enforcer := quota.New(models.Quotas{}, quotaStore{models.Quotas{MaxExecutionSeconds: &second}})
+	eng := engine.NewEngine(nil, nil, engine.WithQuotas(enforcer))
+	require.NoError(t, eng.RegisterNode("hang", &hangNode{}))
+
+	workflow := &models.Workflow{Definition: models.WorkflowDefinition{
+		Nodes: []models.Node{{ID: "wait", Type: "hang"}},
+	}}
+	started := time.Now()
+	execution, err := eng.Run(context.Background(), workflow, nil)
+
+	var exceeded *quota.ExceededError
+	require.True(t, errors.As(err, &exceeded), "got %v", err)
+	assert.Equal(t, quota.ExecutionDuration, exceeded.Quota)
+	assert.Less(t, time.Since(started), 5*time.Second)
+	assert.Equal(t, models.ExecutionStatusFailed, execution.Status)
+	require.NotNil(t, execution.Error)
+	assert.Equal(t, "quota exceeded: executions may run for at most 1s", *execution.Error)
+}
@@ -0,0 +1,131 @@
+package engine_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/nuumz/f1ow/internal/engine"
+	"github.com/nuumz/f1ow/internal/models"
+	"github.com/nuumz/f1ow/internal/storage"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryClaimer leases executions in memory; leases never expire
+type memoryClaimer struct {
+	mu     sync.Mutex
+	owners map[string]string
+}
+
+func newMemoryClaimer() *memoryClaimer {
+	return &memoryClaimer{owners: map[string]string{}}
+}
+
+func (c *memoryClaimer) Claim(ctx context.Context, executionID, owner string, ttl time.Duration) (bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if current, ok := c.owners[executionID]; ok && current != owner {
+		return false, nil
+	}
+	c.owners[executionID] = owner
+	return true, nil
+}
+
+func (c *memoryClaimer) Release(ctx context.Context, executionID, owner string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.owners[executionID] == owner {
+		delete(c.owners, executionID)
+	}
+	return nil
+}
+
+// recordingNode records the input of each of its runs
+type recordingNode struct {
+	upstreamNode
+	mu     sync.Mutex
+	inputs []interface{}
+}
+
+func (n *recordingNode) Execute(ctx context.Context, config interface{}, input interface{}) (interface{}, error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.inputs = append(n.inputs, input)
+	return map[string]interface{}{"ran": true}, nil
+}
+
+func (n *recordingNode) runs() []interface{} {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return append([]interface{}(nil), n.inputs...)
+}
+
+func TestReapExecutions_RequeuesOnceAndResumesFromJournal(t *testing.T) {
+	ctx := context.Background()
+	repo := storage.NewMemoryRepository()
+	queue := engine.NewMemoryQueue(nil)
+	registry := newMemoryWorkerRegistry()
+	claimer := newMemoryClaimer()
+	eng := engine.NewEngine(repo, nil,
+		engine.WithQueue(queue),
+		engine.WithWorkerRegistry(registry),
+		engine.WithExecutionClaimer(claimer),
+		engine.WithWorker(engine.WorkerOptions{ID: "worker-new", Concurrency: 1}))
+	fetch, store := &recordingNode{}, &recordingNode{}
+	require.NoError(t, eng.RegisterNode("fetch", fetch))
+	require.NoError(t, eng.RegisterNode("store", store))
+
+	workflow := &models.Workflow{Name: "etl", Status: models.WorkflowStatusActive, Definition: models.WorkflowDefinition{
+		Nodes: []models.Node{{ID: "fetch", Type: "fetch"}, {ID: "store", Type: "store"}},
+		Edges: []models.Edge{{ID: "e1", Source: "fetch", Target: "store"}},
+	}}
+	require.NoError(t, repo.CreateWorkflow(ctx, workflow))
+
+	// A worker completed fetch, then stopped without finishing
+	stale := &models.Execution{WorkflowID: workflow.ID, Status: models.ExecutionStatusPending, Input: map[string]interface{}{}}
+	require.NoError(t, repo.CreateExecution(ctx, stale))
+	token, err := repo.ClaimExecution(ctx, stale.ID, "worker-dead")
+	require.NoError(t, err)
+	require.NoError(t, repo.AppendJournal(ctx, stale.ID, token, "fetch", map[string]interface{}{"rows": float64(3)}))
+
+	// Another is running on a live worker
+	_, err = registry.Heartbeat(ctx, engine.WorkerInfo{ID: "worker-live", Status: engine.WorkerRunning}, time.Minute)
+	require.NoError(t, err)
+	live := &models.Execution{WorkflowID: workflow.ID, Status: models.ExecutionStatusPending, Input: map[string]interface{}{}}
+	require.NoError(t, repo.CreateExecution(ctx, live))
+	_, err = repo.ClaimExecution(ctx, live.ID, "worker-live")
+	require.NoError(t, err)
+
+	requeued, err := eng.ReapExecutions(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, requeued, "only the dead worker's execution is requeued")
+	requeued, err = eng.ReapExecutions(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, requeued, "an execution is requeued once")
+	size, err := queue.Size(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), size)
+
+	stored, err := repo.GetExecution(ctx, stale.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.ExecutionStatusPending, stored.Status)
+
+	// The worker that picks it up replays fetch from the journal and only
+	// runs store
+	workerCtx, stop := context.WithTimeout(ctx, 10*time.Second)
+	defer stop()
+	go eng.StartWorker(workerCtx)
+	require.Eventually(t, func() bool {
+		stored, err := repo.GetExecution(ctx, stale.ID)
+		return err == nil && stored.Status == models.ExecutionStatusCompleted
+	}, 10*time.Second, 20*time.Millisecond)
+
+	assert.Empty(t, fetch.runs(), "the journaled node does not run again")
+	require.Len(t, store.runs(), 1)
+	input, _ := store.runs()[0].(map[string]interface{})
+	assert.Equal(t, map[string]interface{}{"fetch": map[string]interface{}{"rows": float64(3)}}, input["nodeOutputs"],
+		"store sees the journaled output of fetch")
+}
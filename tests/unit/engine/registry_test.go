@@ -105,3 +105,40 @@ func TestNodeRegistry_Categories(t *testing.T) {
 	mockNode1.AssertExpectations(t)
 	mockNode2.AssertExpectations(t)
 }
+
+func TestNodeRegistry_Versions(t *testing.T) {
+	registry := engine.NewNodeRegistry()
+	v1, v2 := &MockNode{}, &MockNode{}
+
+	assert.NoError(t, registry.Register("test", v1))
+	assert.NoError(t, registry.Register("test@v2", v2))
+	assert.Error(t, registry.Register("test@v1", &MockNode{}), "test is version 1")
+	assert.Error(t, registry.Register("test@2", &MockNode{}))
+
+	latest, err := registry.Get("test")
+	assert.NoError(t, err)
+	assert.Same(t, v2, latest)
+	pinned, err := registry.Get("test@v1")
+	assert.NoError(t, err)
+	assert.Same(t, v1, pinned)
+	_, err = registry.Get("test@v3")
+	assert.Error(t, err)
+
+	assert.Equal(t, []int{1, 2}, registry.Versions("test"))
+	assert.Same(t, v2, registry.List()["test"])
+}
+
+func TestNodeRegistry_Deprecate(t *testing.T) {
+	registry := engine.NewNodeRegistry()
+	assert.NoError(t, registry.Register("test", &MockNode{}))
+	assert.NoError(t, registry.Register("test@v2", &MockNode{}))
+
+	assert.NoError(t, registry.Deprecate("test@v1", "use test@v2"))
+	assert.Error(t, registry.Deprecate("test@v3", "no such version"))
+
+	message, deprecated := registry.Deprecation("test@v1")
+	assert.True(t, deprecated)
+	assert.Equal(t, "use test@v2", message)
+	_, deprecated = registry.Deprecation("test@v2")
+	assert.False(t, deprecated)
+}
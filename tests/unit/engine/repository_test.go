@@ -0,0 +1,80 @@
+package engine_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/nuumz/f1ow/internal/engine"
+	"github.com/nuumz/f1ow/internal/models"
+	"github.com/nuumz/f1ow/internal/storage"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// echoNode succeeds with a fixed output
+type echoNode struct{ upstreamNode }
+
+func (n *echoNode) Execute(ctx context.Context, config interface{}, input interface{}) (interface{}, error) {
+	return map[string]interface{}{"echo": true}, nil
+}
+
+func TestEngineExecute_MemoryRepository(t *testing.T) {
+	repo := storage.NewMemoryRepository()
+	eng := engine.NewEngine(repo, nil)
+	require.NoError(t, eng.RegisterNode("echo", &echoNode{}))
+
+	ctx := context.Background()
+	workflow := &models.Workflow{Name: "echo", Definition: models.WorkflowDefinition{
+		Nodes: []models.Node{{ID: "echo", Type: "echo"}},
+	}}
+	require.NoError(t, repo.CreateWorkflow(ctx, workflow))
+
+	execution, err := eng.Execute(ctx, workflow.ID.String(), map[string]interface{}{"order": "A-1"})
+	require.NoError(t, err)
+	assert.Equal(t, models.ExecutionStatusCompleted, execution.Status)
+
+	stored, err := repo.GetExecution(ctx, execution.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.ExecutionStatusCompleted, stored.Status)
+	assert.Equal(t, workflow.ID, stored.WorkflowID)
+	assert.Equal(t, map[string]interface{}{"order": "A-1"}, stored.Input)
+
+	runs, err := repo.ListNodeExecutions(ctx, execution.ID)
+	require.NoError(t, err)
+	require.Len(t, runs, 1)
+	assert.Equal(t, "echo", runs[0].NodeID)
+	assert.Equal(t, models.ExecutionStatusCompleted, runs[0].Status)
+	assert.Equal(t, map[string]interface{}{"echo": true}, runs[0].Output)
+	assert.NotNil(t, runs[0].CompletedAt)
+}
+
+// failingNode always fails
+type failingNode struct{ upstreamNode }
+
+func (n *failingNode) Execute(ctx context.Context, config interface{}, input interface{}) (interface{}, error) {
+	return nil, errors.New("upstream unavailable")
+}
+
+func TestEngineExecute_RecordsFailedNodeRun(t *testing.T) {
+	repo := storage.NewMemoryRepository()
+	eng := engine.NewEngine(repo, nil)
+	require.NoError(t, eng.RegisterNode("failing", &failingNode{}))
+
+	ctx := context.Background()
+	workflow := &models.Workflow{Name: "failing", Definition: models.WorkflowDefinition{
+		Nodes: []models.Node{{ID: "fail", Type: "failing"}},
+	}}
+	require.NoError(t, repo.CreateWorkflow(ctx, workflow))
+
+	execution, err := eng.Execute(ctx, workflow.ID.String(), nil)
+	require.Error(t, err)
+
+	runs, err := repo.ListNodeExecutions(ctx, execution.ID)
+	require.NoError(t, err)
+	require.Len(t, runs, 1)
+	assert.Equal(t, models.ExecutionStatusFailed, runs[0].Status)
+	require.NotNil(t, runs[0].Error)
+	assert.Contains(t, *runs[0].Error, "upstream unavailable")
+}
@@ -0,0 +1,104 @@
+package engine_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/nuumz/f1ow/internal/engine"
+	"github.com/nuumz/f1ow/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// lookupNode counts its runs and enriches its input's id, failing for
+// ids starting with "bad"
+type lookupNode struct{ upstreamNode }
+
+func (n *lookupNode) Execute(ctx context.Context, config interface{}, input interface{}) (interface{}, error) {
+	n.calls++
+	id, _ := input.(map[string]interface{})["id"].(string)
+	if len(id) >= 3 && id[:3] == "bad" {
+		return nil, errors.New("lookup failed")
+	}
+	return map[string]interface{}{"id": id, "name": "customer " + id}, nil
+}
+
+func lookupWorkflow(cacheTTL int) *models.Workflow {
+	return &models.Workflow{ID: uuid.New(), Definition: models.WorkflowDefinition{
+		Nodes: []models.Node{{ID: "enrich", Type: "lookup", CacheTTL: cacheTTL, InputMapping: map[string]string{"id": "{{input.id}}"}}},
+	}}
+}
+
+func TestEngineRun_ReusesCachedNodeOutput(t *testing.T) {
+	eng := engine.NewEngine(nil, nil)
+	node := &lookupNode{}
+	require.NoError(t, eng.RegisterNode("lookup", node))
+	workflow := lookupWorkflow(60)
+
+	first, err := eng.Run(context.Background(), workflow, map[string]interface{}{"id": "c1"})
+	require.NoError(t, err)
+	second, err := eng.Run(context.Background(), workflow, map[string]interface{}{"id": "c1"})
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, node.calls, "the second run reuses the first's output")
+	assert.Equal(t, first.Output["enrich"], second.Output["enrich"])
+	assert.False(t, first.Context.NodeExecutions["enrich"].Cached)
+	assert.True(t, second.Context.NodeExecutions["enrich"].Cached)
+
+	_, err = eng.Run(context.Background(), workflow, map[string]interface{}{"id": "c2"})
+	require.NoError(t, err)
+	assert.Equal(t, 2, node.calls, "other input runs the node")
+}
+
+func TestEngineRun_CachesOnlyWhenEnabledAndSuccessful(t *testing.T) {
+	eng := engine.NewEngine(nil, nil)
+	node := &lookupNode{}
+	require.NoError(t, eng.RegisterNode("lookup", node))
+
+	uncached := lookupWorkflow(0)
+	for i := 0; i < 2; i++ {
+		_, err := eng.Run(context.Background(), uncached, map[string]interface{}{"id": "c1"})
+		require.NoError(t, err)
+	}
+	assert.Equal(t, 2, node.calls)
+
+	cached := lookupWorkflow(60)
+	for i := 0; i < 2; i++ {
+		_, err := eng.Run(context.Background(), cached, map[string]interface{}{"id": "bad"})
+		require.Error(t, err)
+	}
+	assert.Equal(t, 4, node.calls, "failures are not cached")
+}
+
+func TestValidateWorkflow_NegativeCacheTTL(t *testing.T) {
+	eng := engine.NewEngine(nil, nil)
+	require.NoError(t, eng.RegisterNode("lookup", &lookupNode{}))
+
+	err := eng.ValidateWorkflow(lookupWorkflow(-1))
+
+	var validationErr *engine.ValidationError
+	require.ErrorAs(t, err, &validationErr)
+	assert.Equal(t, "cache_ttl", validationErr.Errors[0].Field)
+	assert.Equal(t, "enrich", validationErr.Errors[0].NodeID)
+}
+
+func TestMemoryResultCache_Expires(t *testing.T) {
+	cache := engine.NewMemoryResultCache()
+	ctx := context.Background()
+
+	require.NoError(t, cache.SetCache(ctx, "short", []byte("1"), time.Millisecond))
+	require.NoError(t, cache.SetCache(ctx, "long", []byte("2"), time.Minute))
+	time.Sleep(5 * time.Millisecond)
+
+	_, found, err := cache.GetCache(ctx, "short")
+	require.NoError(t, err)
+	assert.False(t, found)
+	value, found, err := cache.GetCache(ctx, "long")
+	require.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, []byte("2"), value)
+}
@@ -0,0 +1,214 @@
+package engine_test
+
+import (
+	"context"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/nuumz/f1ow/internal/engine"
+	"github.com/nuumz/f1ow/internal/models"
+	"github.com/nuumz/f1ow/internal/netpolicy"
+	"github.com/nuumz/f1ow/internal/storage"
+	"github.com/nuumz/f1ow/internal/tenant"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeTrigger struct{}
+
+func (t *fakeTrigger) Start(ctx context.Context, emit engine.EmitFunc) error { return nil }
+func (t *fakeTrigger) Stop() error                                           { return nil }
+
+// memoryLocker leases keys in memory; every owner that heartbeats is alive
+type memoryLocker struct {
+	mu      sync.Mutex
+	leases  map[string]string
+	members map[string]bool
+}
+
+func newMemoryLocker() *memoryLocker {
+	return &memoryLocker{leases: map[string]string{}, members: map[string]bool{}}
+}
+
+func (l *memoryLocker) Acquire(ctx context.Context, key, owner string, ttl time.Duration) (bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if current, ok := l.leases[key]; ok && current != owner {
+		return false, nil
+	}
+	l.leases[key] = owner
+	return true, nil
+}
+
+func (l *memoryLocker) Release(ctx context.Context, key, owner string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.leases[key] == owner {
+		delete(l.leases, key)
+	}
+	return nil
+}
+
+func (l *memoryLocker) Heartbeat(ctx context.Context, owner string, ttl time.Duration) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.members[owner] = true
+	return len(l.members), nil
+}
+
+func newTriggerManager() *engine.TriggerManager {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+
+	manager := engine.NewTriggerManager(nil, logger)
+	manager.RegisterFactory("fake_trigger", func(workflowID string, node models.Node) (engine.Trigger, error) {
+		return &fakeTrigger{}, nil
+	})
+	return manager
+}
+
+func triggerWorkflow(status models.WorkflowStatus) models.Workflow {
+	return models.Workflow{
+		ID:         uuid.New(),
+		Status:     status,
+		Definition: models.WorkflowDefinition{Nodes: []models.Node{{ID: "trigger", Type: "fake_trigger"}}},
+	}
+}
+
+func TestTriggerManagerSync_OnlyActiveWorkflows(t *testing.T) {
+	manager := newTriggerManager()
+
+	active := triggerWorkflow(models.WorkflowStatusActive)
+	draft := triggerWorkflow(models.WorkflowStatusDraft)
+	archived := triggerWorkflow(models.WorkflowStatusArchived)
+
+	manager.Sync(context.Background(), []models.Workflow{active, draft, archived})
+	assert.Equal(t, []string{active.ID.String() + "/trigger"}, manager.Running())
+
+	// Deactivating stops the trigger on the next sync
+	active.Status = models.WorkflowStatusDraft
+	manager.Sync(context.Background(), []models.Workflow{active})
+	assert.Empty(t, manager.Running())
+}
+
+func TestTriggerManagerSync_SharesTriggers(t *testing.T) {
+	ctx := context.Background()
+	locker := newMemoryLocker()
+
+	var workflows []models.Workflow
+	for i := 0; i < 4; i++ {
+		workflows = append(workflows, triggerWorkflow(models.WorkflowStatusActive))
+	}
+
+	first := newTriggerManager()
+	first.UseLocker(locker, "first", time.Minute)
+	first.Sync(ctx, workflows)
+	assert.Len(t, first.Running(), 4)
+
+	// A second instance joins: the first hands over triggers above its share
+	second := newTriggerManager()
+	second.UseLocker(locker, "second", time.Minute)
+	second.Sync(ctx, workflows)
+	assert.Empty(t, second.Running(), "all leases are still held")
+
+	first.Sync(ctx, workflows)
+	second.Sync(ctx, workflows)
+	assert.Len(t, first.Running(), 2)
+	assert.Len(t, second.Running(), 2)
+	assert.NotSubset(t, first.Running(), second.Running())
+
+	// Stopping an instance releases its leases to the other
+	first.StopAll()
+	delete(locker.members, "first")
+	second.Sync(ctx, workflows)
+	assert.Len(t, second.Running(), 4)
+}
+
+// contextTrigger records the context it is started with
+type contextTrigger struct {
+	started *context.Context
+}
+
+func (t *contextTrigger) Start(ctx context.Context, emit engine.EmitFunc) error {
+	*t.started = ctx
+	return nil
+}
+func (t *contextTrigger) Stop() error { return nil }
+
+func TestTriggerManagerSync_StartsInWorkflowTenantUnderNetworkPolicy(t *testing.T) {
+	manager := newTriggerManager()
+	policy, err := netpolicy.New(models.NetworkPolicy{Deny: []string{"loopback"}})
+	require.NoError(t, err)
+	manager.UseNetworkPolicy(policy)
+
+	var started context.Context
+	manager.RegisterFactory("context_trigger", func(workflowID string, node models.Node) (engine.Trigger, error) {
+		return &contextTrigger{started: &started}, nil
+	})
+
+	workflow := models.Workflow{ID: uuid.New(), TenantID: uuid.New(), Status: models.WorkflowStatusActive,
+		Definition: models.WorkflowDefinition{Nodes: []models.Node{{ID: "trigger", Type: "context_trigger"}}}}
+	manager.Sync(context.Background(), []models.Workflow{workflow})
+	require.NotNil(t, started)
+
+	id, ok := tenant.FromContext(started)
+	assert.True(t, ok)
+	assert.Equal(t, workflow.TenantID, id)
+	scoped, ok := netpolicy.FromContext(started)
+	assert.True(t, ok)
+	assert.Same(t, policy, scoped)
+}
+
+// emittingTrigger fires once when it starts
+type emittingTrigger struct {
+	workflowID string
+}
+
+func (t *emittingTrigger) Start(ctx context.Context, emit engine.EmitFunc) error {
+	return emit(ctx, t.workflowID, map[string]interface{}{"n": 1})
+}
+func (t *emittingTrigger) Stop() error { return nil }
+
+func TestTriggerFired_RunsInWorkflowTenant(t *testing.T) {
+	repo := storage.NewMemoryRepository()
+	queue := engine.NewMemoryQueue(nil)
+	eng := engine.NewEngine(repo, nil, engine.WithQueue(queue))
+
+	tenantID := uuid.New()
+	workflow := &models.Workflow{Name: "inbox", Status: models.WorkflowStatusActive, Definition: models.WorkflowDefinition{
+		Nodes: []models.Node{{ID: "trigger", Type: "emitting_trigger"}},
+	}}
+	require.NoError(t, repo.CreateWorkflow(tenant.WithID(context.Background(), tenantID), workflow))
+
+	var mu sync.Mutex
+	var fired []engine.Event
+	eng.Subscribe(func(event engine.Event) {
+		mu.Lock()
+		defer mu.Unlock()
+		if event.Type == engine.EventTriggerFired {
+			fired = append(fired, event)
+		}
+	})
+	eng.RegisterTrigger("emitting_trigger", func(workflowID string, node models.Node) (engine.Trigger, error) {
+		return &emittingTrigger{workflowID: workflowID}, nil
+	})
+
+	// Triggers are synced without a tenant in the context
+	eng.Triggers().Sync(context.Background(), []models.Workflow{*workflow})
+
+	job, err := queue.Dequeue(context.Background())
+	require.NoError(t, err)
+	require.NotNil(t, job)
+	assert.Equal(t, tenantID.String(), job.TenantID)
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.Len(t, fired, 1)
+	assert.Equal(t, tenantID.String(), fired[0].TenantID)
+	assert.Equal(t, job.ExecutionID, fired[0].ExecutionID)
+}
@@ -0,0 +1,45 @@
+package engine_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/nuumz/f1ow/internal/engine"
+	"github.com/nuumz/f1ow/internal/models"
+	"github.com/nuumz/f1ow/internal/usage"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// promptNode stands in for a node that calls a language model
+type promptNode struct{}
+
+func (n *promptNode) Execute(ctx context.Context, config interface{}, input interface{}) (interface{}, error) {
+	usage.AddTokens(ctx, 40, 12)
+	return map[string]interface{}{"answer": "42"}, nil
+}
+func (n *promptNode) ValidateConfig(config interface{}) error { return nil }
+func (n *promptNode) GetSchema() engine.NodeSchema            { return engine.NodeSchema{} }
+func (n *promptNode) Type() string                            { return "prompt" }
+func (n *promptNode) Name() string                            { return "Prompt" }
+func (n *promptNode) Description() string                     { return "" }
+func (n *promptNode) Category() string                        { return "" }
+func (n *promptNode) Icon() string                            { return "" }
+
+func TestEngineRun_RecordsUsage(t *testing.T) {
+	eng := engine.NewEngine(nil, nil)
+	require.NoError(t, eng.RegisterNode("prompt", &promptNode{}))
+
+	workflow := &models.Workflow{Definition: models.WorkflowDefinition{
+		Nodes: []models.Node{{ID: "ask", Type: "prompt"}, {ID: "follow-up", Type: "prompt"}},
+		Edges: []models.Edge{{Source: "ask", Target: "follow-up"}},
+	}}
+	execution, err := eng.Run(context.Background(), workflow, nil)
+	require.NoError(t, err)
+
+	require.NotNil(t, execution.Usage)
+	assert.Equal(t, 2, execution.Usage.Nodes)
+	assert.Equal(t, int64(80), execution.Usage.LLMPromptTokens)
+	assert.Equal(t, int64(24), execution.Usage.LLMCompletionTokens)
+}
@@ -0,0 +1,106 @@
+package engine_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/nuumz/f1ow/internal/engine"
+	"github.com/nuumz/f1ow/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func validationRegistry(t *testing.T, validateErr error, credentials bool) (*engine.NodeRegistry, *MockNode) {
+	node := &MockNode{}
+	properties := map[string]engine.Property{
+		"url":     {Type: "string"},
+		"method":  {Type: "string", Enum: []string{"GET", "POST"}},
+		"retries": {Type: "integer"},
+	}
+	if credentials {
+		properties["credential_id"] = engine.Property{Type: "string"}
+	}
+	node.On("GetSchema").Return(engine.NodeSchema{Properties: properties, Required: []string{"url"}})
+	node.On("ValidateConfig", mock.Anything).Return(validateErr)
+
+	registry := engine.NewNodeRegistry()
+	require.NoError(t, registry.Register("request", node))
+	return registry, node
+}
+
+func TestValidate_SchemaErrors(t *testing.T) {
+	registry, node := validationRegistry(t, nil, false)
+
+	fieldErrs, err := registry.Validate("request", map[string]interface{}{
+		"method":  "DELETE",
+		"retries": 1.5,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []engine.FieldError{
+		{Field: "method", Message: "must be one of GET, POST"},
+		{Field: "retries", Message: "must be an integer"},
+		{Field: "url", Message: "is required"},
+	}, fieldErrs)
+	node.AssertNotCalled(t, "ValidateConfig", mock.Anything)
+
+	fieldErrs, err = registry.Validate("request", map[string]interface{}{
+		"url":    "https://example.com",
+		"method": "{{ $vars.method }}",
+	})
+	require.NoError(t, err)
+	assert.Empty(t, fieldErrs)
+	node.AssertCalled(t, "ValidateConfig", mock.Anything)
+
+	_, err = registry.Validate("missing", nil)
+	assert.Error(t, err)
+}
+
+func TestValidate_ValidateConfigErrors(t *testing.T) {
+	registry, _ := validationRegistry(t, errors.New("timeout must be positive"), false)
+	fieldErrs, err := registry.Validate("request", map[string]interface{}{"url": "https://example.com"})
+	require.NoError(t, err)
+	assert.Equal(t, []engine.FieldError{{Message: "timeout must be positive"}}, fieldErrs)
+
+	structured := &engine.ValidationError{Errors: []engine.FieldError{{Field: "url", Message: "must use https"}}}
+	registry, _ = validationRegistry(t, structured, false)
+	fieldErrs, err = registry.Validate("request", map[string]interface{}{"url": "http://example.com"})
+	require.NoError(t, err)
+	assert.Equal(t, structured.Errors, fieldErrs)
+}
+
+func TestValidate_Credentials(t *testing.T) {
+	// Fields may come from the credential, so nothing is required
+	registry, node := validationRegistry(t, errors.New("url is required"), false)
+	fieldErrs, err := registry.Validate("request", map[string]interface{}{"credential_id": "cred-1"})
+	require.NoError(t, err)
+	assert.Empty(t, fieldErrs)
+	node.AssertNotCalled(t, "ValidateConfig", mock.Anything)
+
+	// Nodes that declare credential_id validate it themselves
+	registry, _ = validationRegistry(t, errors.New("token or credential_id is required"), true)
+	fieldErrs, err = registry.Validate("request", map[string]interface{}{"credential_id": "cred-1"})
+	require.NoError(t, err)
+	assert.Len(t, fieldErrs, 1)
+}
+
+func TestValidateDefinition(t *testing.T) {
+	registry, _ := validationRegistry(t, nil, false)
+
+	fieldErrs := registry.ValidateDefinition(&models.WorkflowDefinition{
+		Nodes: []models.Node{
+			{ID: "ok", Type: "request", Config: map[string]interface{}{"url": "https://example.com"}},
+			{ID: "no-url", Type: "request"},
+			{ID: "skipped", Type: "request", Disabled: true},
+			{ID: "unknown", Type: "missing"},
+		},
+	})
+	require.Len(t, fieldErrs, 2)
+	assert.Equal(t, engine.FieldError{NodeID: "no-url", Field: "url", Message: "is required"}, fieldErrs[0])
+	assert.Equal(t, "unknown", fieldErrs[1].NodeID)
+	assert.Equal(t, "type", fieldErrs[1].Field)
+
+	err := &engine.ValidationError{Errors: fieldErrs[:1]}
+	assert.Equal(t, "invalid configuration: node no-url: url: is required", err.Error())
+}
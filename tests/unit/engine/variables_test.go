@@ -0,0 +1,70 @@
+package engine_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/nuumz/f1ow/internal/engine"
+	"github.com/nuumz/f1ow/internal/models"
+	"github.com/nuumz/f1ow/internal/nodes"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func variablesEngine(t *testing.T) *engine.Engine {
+	t.Helper()
+	eng := engine.NewEngine(nil, nil)
+	require.NoError(t, eng.RegisterNode("mirror", &mirrorNode{}))
+	require.NoError(t, eng.RegisterNode("set_variable", nodes.NewSetVariableNode()))
+	return eng
+}
+
+func countVariable() map[string]interface{} {
+	return map[string]interface{}{"variables": []interface{}{
+		map[string]interface{}{"name": "count", "operation": "increment"},
+	}}
+}
+
+func TestEngineRun_SetVariable(t *testing.T) {
+	eng := variablesEngine(t)
+	workflow := &models.Workflow{Definition: models.WorkflowDefinition{
+		Nodes: []models.Node{
+			{ID: "first", Type: "set_variable", Config: countVariable()},
+			{ID: "second", Type: "set_variable", Config: countVariable()},
+			{ID: "read", Type: "mirror", InputMapping: map[string]string{"count": "{{input.count}}"}},
+			{ID: "plain", Type: "mirror"},
+		},
+		Edges: []models.Edge{
+			{Source: "first", Target: "second"},
+			{Source: "second", Target: "read"},
+			{Source: "second", Target: "plain"},
+		},
+	}}
+	input := map[string]interface{}{"count": float64(10)}
+
+	execution, err := eng.Run(context.Background(), workflow, input)
+	require.NoError(t, err)
+
+	assert.Equal(t, float64(12), execution.Output["read"].(map[string]interface{})["count"])
+	assert.Equal(t, float64(12), execution.Output["plain"].(map[string]interface{})["count"])
+	assert.Equal(t, float64(12), execution.Context.Variables["count"])
+	assert.Equal(t, float64(10), input["count"], "the execution input is not modified")
+}
+
+func TestEngineRun_SetVariablePerItem(t *testing.T) {
+	eng := variablesEngine(t)
+	workflow := itemsWorkflow([]models.Node{
+		{ID: "orders", Type: "mirror", Config: map[string]interface{}{"output": orders(
+			map[string]interface{}{"id": "a"}, map[string]interface{}{"id": "b"}, map[string]interface{}{"id": "c"},
+		)}},
+		{ID: "collect", Type: "set_variable", Config: map[string]interface{}{"variables": []interface{}{
+			map[string]interface{}{"name": "ids", "operation": "append", "value": "{{id}}"},
+		}}},
+	}, []models.Edge{{Source: "orders", Target: "collect"}})
+
+	execution, err := eng.Run(context.Background(), workflow, nil)
+	require.NoError(t, err)
+
+	assert.Equal(t, []interface{}{"a", "b", "c"}, execution.Context.Variables["ids"], "each item sees the ones before it")
+}
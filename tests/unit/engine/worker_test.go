@@ -0,0 +1,251 @@
+package engine_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/nuumz/f1ow/internal/engine"
+	"github.com/nuumz/f1ow/internal/models"
+	"github.com/nuumz/f1ow/internal/storage"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryWorkerRegistry keeps worker records in memory; records never expire
+type memoryWorkerRegistry struct {
+	mu       sync.Mutex
+	workers  map[string]engine.WorkerInfo
+	commands map[string]engine.WorkerCommand
+}
+
+func newMemoryWorkerRegistry() *memoryWorkerRegistry {
+	return &memoryWorkerRegistry{workers: map[string]engine.WorkerInfo{}, commands: map[string]engine.WorkerCommand{}}
+}
+
+func (r *memoryWorkerRegistry) Heartbeat(ctx context.Context, info engine.WorkerInfo, ttl time.Duration) (engine.WorkerCommand, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.workers[info.ID] = info
+	command := r.commands[info.ID]
+	delete(r.commands, info.ID)
+	return command, nil
+}
+
+func (r *memoryWorkerRegistry) Deregister(ctx context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.workers, id)
+	return nil
+}
+
+func (r *memoryWorkerRegistry) List(ctx context.Context) ([]engine.WorkerInfo, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	workers := []engine.WorkerInfo{}
+	for _, info := range r.workers {
+		workers = append(workers, info)
+	}
+	return workers, nil
+}
+
+func (r *memoryWorkerRegistry) Get(ctx context.Context, id string) (*engine.WorkerInfo, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	info, ok := r.workers[id]
+	if !ok {
+		return nil, engine.ErrWorkerNotFound
+	}
+	return &info, nil
+}
+
+func (r *memoryWorkerRegistry) SendCommand(ctx context.Context, id string, command engine.WorkerCommand) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.commands[id] = command
+	return nil
+}
+
+func TestWorkers_WithoutRegistry(t *testing.T) {
+	eng := engine.NewEngine(nil, nil)
+
+	workers, err := eng.Workers(context.Background())
+	require.NoError(t, err)
+	assert.Empty(t, workers)
+
+	err = eng.SendWorkerCommand(context.Background(), "worker-1", engine.WorkerDrain)
+	assert.ErrorIs(t, err, engine.ErrWorkerNotFound)
+}
+
+func TestSendWorkerCommand(t *testing.T) {
+	ctx := context.Background()
+	registry := newMemoryWorkerRegistry()
+	eng := engine.NewEngine(nil, nil, engine.WithWorkerRegistry(registry))
+
+	assert.ErrorIs(t, eng.SendWorkerCommand(ctx, "worker-1", engine.WorkerDrain), engine.ErrWorkerNotFound)
+
+	_, err := registry.Heartbeat(ctx, engine.WorkerInfo{ID: "worker-1", Status: engine.WorkerRunning}, time.Minute)
+	require.NoError(t, err)
+
+	workers, err := eng.Workers(ctx)
+	require.NoError(t, err)
+	require.Len(t, workers, 1)
+	assert.Equal(t, "worker-1", workers[0].ID)
+
+	assert.ErrorContains(t, eng.SendWorkerCommand(ctx, "worker-1", "restart"), "invalid worker command")
+	require.NoError(t, eng.SendWorkerCommand(ctx, "worker-1", engine.WorkerDrain))
+
+	assert.Equal(t, engine.WorkerDrain, registry.commands["worker-1"])
+}
+
+// drainNode blocks its first run until released or cancelled; later runs
+// complete at once
+type drainNode struct {
+	upstreamNode
+	started  chan struct{}
+	release  chan struct{}
+	mu       sync.Mutex
+	runs     int
+	canceled bool
+}
+
+func newDrainNode() *drainNode {
+	return &drainNode{started: make(chan struct{}), release: make(chan struct{})}
+}
+
+func (n *drainNode) Execute(ctx context.Context, config interface{}, input interface{}) (interface{}, error) {
+	n.mu.Lock()
+	n.runs++
+	first := n.runs == 1
+	n.mu.Unlock()
+	if !first {
+		return map[string]interface{}{"ok": true}, nil
+	}
+
+	close(n.started)
+	select {
+	case <-n.release:
+		return map[string]interface{}{"ok": true}, nil
+	case <-ctx.Done():
+		n.mu.Lock()
+		n.canceled = true
+		n.mu.Unlock()
+		return nil, ctx.Err()
+	}
+}
+
+func startDrainWorkflow(t *testing.T, drainTimeout time.Duration) (*engine.Engine, *storage.MemoryRepository, *drainNode, *models.Execution) {
+	node := newDrainNode()
+	repo := storage.NewMemoryRepository()
+	eng := engine.NewEngine(repo, nil,
+		engine.WithQueue(engine.NewMemoryQueue(nil)),
+		engine.WithWorker(engine.WorkerOptions{Concurrency: 2, DrainTimeout: drainTimeout}))
+	require.NoError(t, eng.RegisterNode("drain", node))
+	ctx := context.Background()
+
+	workflow := &models.Workflow{Name: "drain", Definition: models.WorkflowDefinition{
+		Nodes: []models.Node{{ID: "run", Type: "drain"}},
+	}}
+	require.NoError(t, repo.CreateWorkflow(ctx, workflow))
+	execution, err := eng.Submit(ctx, workflow.ID.String(), "", map[string]interface{}{})
+	require.NoError(t, err)
+	return eng, repo, node, execution
+}
+
+func TestWorker_DrainWaitsForRunningExecutions(t *testing.T) {
+	eng, repo, node, execution := startDrainWorkflow(t, 5*time.Second)
+
+	workerCtx, stop := context.WithCancel(context.Background())
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		eng.StartWorker(workerCtx)
+	}()
+	<-node.started
+	stop()
+
+	select {
+	case <-stopped:
+		t.Fatal("worker stopped before its execution finished")
+	case <-time.After(100 * time.Millisecond):
+	}
+	close(node.release)
+	<-stopped
+
+	stored, err := repo.GetExecution(context.Background(), execution.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.ExecutionStatusCompleted, stored.Status)
+}
+
+func TestWorker_RequeuesExecutionsAfterDrainTimeout(t *testing.T) {
+	eng, repo, node, execution := startDrainWorkflow(t, 50*time.Millisecond)
+	ctx := context.Background()
+
+	workerCtx, stop := context.WithCancel(ctx)
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		eng.StartWorker(workerCtx)
+	}()
+	<-node.started
+	stop()
+	<-stopped
+
+	node.mu.Lock()
+	assert.True(t, node.canceled)
+	node.mu.Unlock()
+	stored, err := repo.GetExecution(ctx, execution.ID)
+	require.NoError(t, err)
+	assert.NotEqual(t, models.ExecutionStatusFailed, stored.Status, "an interrupted execution is not failed")
+
+	// The next worker picks up the requeued execution
+	workerCtx, stop = context.WithTimeout(ctx, 10*time.Second)
+	defer stop()
+	go eng.StartWorker(workerCtx)
+	require.Eventually(t, func() bool {
+		stored, err := repo.GetExecution(ctx, execution.ID)
+		return err == nil && stored.Status == models.ExecutionStatusCompleted
+	}, 10*time.Second, 20*time.Millisecond)
+}
+
+func TestQueueStats_ReportsBacklogAndFleetLoad(t *testing.T) {
+	ctx := context.Background()
+	registry := newMemoryWorkerRegistry()
+	queue := engine.NewMemoryQueue(nil)
+	eng := engine.NewEngine(nil, nil, engine.WithQueue(queue), engine.WithWorkerRegistry(registry))
+	metrics := eng.Metrics()
+
+	for id, active := range map[string]int{"worker-1": 1, "worker-2": 3} {
+		_, err := registry.Heartbeat(ctx, engine.WorkerInfo{ID: id, Concurrency: 4, ActiveJobs: active}, time.Minute)
+		require.NoError(t, err)
+	}
+
+	enqueued := testutil.ToFloat64(metrics.JobsEnqueued)
+	dequeued := testutil.ToFloat64(metrics.JobsDequeued)
+	for i := 0; i < 3; i++ {
+		_, err := eng.Enqueue(ctx, "6f1c2d4e-8a3b-4c5d-9e7f-0a1b2c3d4e5f", nil)
+		require.NoError(t, err)
+	}
+	job, err := queue.Dequeue(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, job)
+
+	stats, err := eng.QueueStats(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), stats.Pending)
+	assert.Equal(t, int64(2), stats.Queues["default"])
+	assert.Equal(t, 2, stats.Workers)
+	assert.Equal(t, 8, stats.Capacity)
+	assert.Equal(t, 4, stats.ActiveJobs)
+	assert.Equal(t, 0.5, stats.Utilization)
+	assert.GreaterOrEqual(t, stats.OldestJobAge, float64(0))
+
+	assert.Equal(t, enqueued+3, testutil.ToFloat64(metrics.JobsEnqueued))
+	assert.Equal(t, dequeued+1, testutil.ToFloat64(metrics.JobsDequeued))
+	assert.Equal(t, float64(2), testutil.ToFloat64(metrics.QueueSize))
+	assert.Equal(t, float64(2), testutil.ToFloat64(metrics.ActiveWorkers))
+	assert.Equal(t, float64(50), testutil.ToFloat64(metrics.WorkerUtilization))
+}
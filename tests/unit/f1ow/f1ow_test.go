@@ -0,0 +1,108 @@
+package f1ow_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/nuumz/f1ow/pkg/f1ow"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func constantNode(nodeType string, output map[string]interface{}, err error) f1ow.NodeType {
+	return f1ow.NewNode(f1ow.NodeInfo{Type: nodeType}, func(ctx context.Context, config, input map[string]interface{}) (map[string]interface{}, error) {
+		return output, err
+	})
+}
+
+func TestEngine_RunsWorkflowInProcess(t *testing.T) {
+	engine, err := f1ow.New()
+	require.NoError(t, err)
+	require.NoError(t, engine.RegisterNode(constantNode("fetch", map[string]interface{}{"items": 3}, nil)))
+	require.NoError(t, engine.RegisterNode(constantNode("store", map[string]interface{}{"stored": true}, nil)))
+
+	workflow, err := f1ow.NewWorkflow("sync").
+		Node("fetch", "fetch", nil).
+		Node("store", "store", nil).
+		Chain("fetch", "store").
+		Build()
+	require.NoError(t, err)
+
+	var (
+		mu     sync.Mutex
+		events []f1ow.EventType
+	)
+	unsubscribe := engine.Subscribe(func(event f1ow.Event) {
+		mu.Lock()
+		defer mu.Unlock()
+		events = append(events, event.Type)
+	})
+	defer unsubscribe()
+
+	execution, err := engine.Run(context.Background(), workflow, map[string]interface{}{"since": "yesterday"})
+	require.NoError(t, err)
+	assert.Equal(t, f1ow.ExecutionStatusCompleted, execution.Status)
+	assert.Equal(t, map[string]interface{}{"items": 3}, execution.Output["fetch"])
+	assert.Equal(t, map[string]interface{}{"stored": true}, execution.Output["store"])
+
+	assert.Equal(t, []f1ow.EventType{
+		f1ow.EventExecutionStarted,
+		f1ow.EventNodeStarted, f1ow.EventNodeCompleted,
+		f1ow.EventNodeStarted, f1ow.EventNodeCompleted,
+		f1ow.EventExecutionCompleted,
+	}, events)
+}
+
+func TestEngine_RunReportsNodeFailures(t *testing.T) {
+	engine, err := f1ow.New()
+	require.NoError(t, err)
+	require.NoError(t, engine.RegisterNode(constantNode("broken", nil, errors.New("boom"))))
+
+	workflow, err := f1ow.NewWorkflow("failing").Node("broken", "broken", nil).Build()
+	require.NoError(t, err)
+
+	var failed []string
+	engine.Subscribe(func(event f1ow.Event) {
+		if event.Type == f1ow.EventNodeFailed {
+			failed = append(failed, event.NodeID)
+		}
+	})
+
+	execution, err := engine.Run(context.Background(), workflow, nil)
+	require.Error(t, err)
+	assert.Equal(t, f1ow.ExecutionStatusFailed, execution.Status)
+	assert.Contains(t, *execution.Error, "boom")
+	assert.Equal(t, []string{"broken"}, failed)
+}
+
+func TestEngine_StoredWorkflowsNeedStorage(t *testing.T) {
+	engine, err := f1ow.New(f1ow.WithBuiltinNodes())
+	require.NoError(t, err)
+
+	_, err = engine.Execute(context.Background(), "00000000-0000-0000-0000-000000000001", nil)
+	assert.ErrorIs(t, err, f1ow.ErrNoStorage)
+	_, err = engine.Enqueue(context.Background(), "00000000-0000-0000-0000-000000000001", nil)
+	assert.ErrorIs(t, err, f1ow.ErrNoStorage)
+
+	assert.Error(t, engine.RegisterNode(constantNode("http", nil, nil)), "built-in http node is already registered")
+}
+
+func TestWorkflowBuilder_Validates(t *testing.T) {
+	_, err := f1ow.NewWorkflow("dup").Node("a", "set", nil).Node("a", "set", nil).Build()
+	assert.ErrorContains(t, err, "duplicate node ID a")
+
+	_, err = f1ow.NewWorkflow("edge").Node("a", "set", nil).Connect("a", "b").Build()
+	assert.ErrorContains(t, err, "unknown node b")
+
+	_, err = f1ow.NewWorkflow("empty").Build()
+	assert.ErrorContains(t, err, "no nodes")
+
+	workflow, err := f1ow.NewWorkflow("ok").Node("a", "set", nil).Node("b", "set", nil).Connect("a", "b").Variable("env", "prod").Build()
+	require.NoError(t, err)
+	assert.Equal(t, "a", workflow.Definition.StartNodeID)
+	assert.Len(t, workflow.Definition.Edges, 1)
+	assert.Equal(t, "prod", workflow.Definition.Variables["env"])
+}
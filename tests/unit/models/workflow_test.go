@@ -0,0 +1,75 @@
+package models_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/nuumz/f1ow/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWorkflowDuplicate(t *testing.T) {
+	source := &models.Workflow{
+		ID:       uuid.New(),
+		Name:     "Order sync",
+		IsActive: true,
+		Version:  7,
+		Tags:     []string{"orders"},
+		Definition: models.WorkflowDefinition{
+			Nodes: []models.Node{
+				{ID: "fetch", Type: "http", Config: map[string]interface{}{"credential_id": "old-key"}},
+				{ID: "store", Type: "database", Config: map[string]interface{}{"table": "orders"},
+					InputMapping: map[string]string{"order": "{{ nodes.fetch.body }}", "note": "from {{nodes.fetcher.id}}"}},
+			},
+			Edges:       []models.Edge{{ID: "e1", Source: "fetch", Target: "store"}},
+			StartNodeID: "fetch",
+		},
+	}
+
+	copied, err := source.Duplicate(map[string]string{"old-key": "new-key"})
+	require.NoError(t, err)
+
+	assert.Equal(t, "Copy of Order sync", copied.Name)
+	assert.Equal(t, uuid.Nil, copied.ID)
+	assert.Zero(t, copied.Version)
+	assert.False(t, copied.IsActive)
+	assert.Equal(t, []string{"orders"}, copied.Tags)
+
+	fetch, store := copied.Definition.Nodes[0], copied.Definition.Nodes[1]
+	assert.NotEqual(t, "fetch", fetch.ID)
+	assert.NotEqual(t, "store", store.ID)
+	assert.Equal(t, "new-key", fetch.Config["credential_id"])
+	assert.Equal(t, fetch.ID, copied.Definition.StartNodeID)
+	assert.Equal(t, map[string]string{"order": "{{ nodes." + fetch.ID + ".body }}", "note": "from {{nodes.fetcher.id}}"}, store.InputMapping)
+
+	edge := copied.Definition.Edges[0]
+	assert.NotEqual(t, "e1", edge.ID)
+	assert.Equal(t, fetch.ID, edge.Source)
+	assert.Equal(t, store.ID, edge.Target)
+
+	// The source is left untouched
+	assert.Equal(t, "old-key", source.Definition.Nodes[0].Config["credential_id"])
+	assert.Equal(t, "fetch", source.Definition.Edges[0].Source)
+	assert.Equal(t, "{{ nodes.fetch.body }}", source.Definition.Nodes[1].InputMapping["order"])
+}
+
+func TestNode_VersionedType(t *testing.T) {
+	var definition models.WorkflowDefinition
+	require.NoError(t, json.Unmarshal([]byte(`{"nodes": [
+		{"id": "a", "type": "http@v2"},
+		{"id": "b", "type": "http", "version": 3},
+		{"id": "c", "type": "http"}
+	]}`), &definition))
+
+	require.Len(t, definition.Nodes, 3)
+	assert.Equal(t, "http", definition.Nodes[0].Type)
+	assert.Equal(t, 2, definition.Nodes[0].Version)
+	assert.Equal(t, "http@v3", definition.Nodes[1].TypeRef())
+	assert.Equal(t, "http@v1", definition.Nodes[2].TypeRef(), "nodes without a version are version 1")
+
+	_, _, err := models.ParseNodeType("http@latest")
+	assert.Error(t, err)
+}
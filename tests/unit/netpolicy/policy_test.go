@@ -0,0 +1,89 @@
+package netpolicy_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+
+	"github.com/nuumz/f1ow/internal/models"
+	"github.com/nuumz/f1ow/internal/netpolicy"
+	"github.com/nuumz/f1ow/internal/tenant"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type tenantRules map[uuid.UUID]*models.NetworkPolicy
+
+func (r tenantRules) TenantNetworkPolicy(_ context.Context, id uuid.UUID) (*models.NetworkPolicy, error) {
+	return r[id], nil
+}
+
+func TestPolicy_Check(t *testing.T) {
+	policy, err := netpolicy.New(models.NetworkPolicy{
+		Deny:  []string{"private", "loopback", "metadata"},
+		Allow: []string{"10.1.2.0/24"},
+	})
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	for _, blocked := range []string{"127.0.0.1", "10.0.0.5", "192.168.1.1", "169.254.169.254", "::1", "::ffff:127.0.0.1", "0.0.0.0"} {
+		err := policy.Check(ctx, netip.MustParseAddr(blocked))
+		assert.ErrorIs(t, err, netpolicy.ErrBlocked, blocked)
+	}
+	for _, allowed := range []string{"10.1.2.3", "93.184.216.34", "2606:4700::1111"} {
+		assert.NoError(t, policy.Check(ctx, netip.MustParseAddr(allowed)), allowed)
+	}
+}
+
+func TestPolicy_TenantRulesNarrowServerRules(t *testing.T) {
+	policy, err := netpolicy.New(models.NetworkPolicy{Deny: []string{"private"}, Allow: []string{"10.1.2.0/24"}})
+	require.NoError(t, err)
+
+	trusted, restricted := uuid.New(), uuid.New()
+	policy.SetTenantRules(tenantRules{
+		trusted:    {Allow: []string{"10.0.0.0/8"}},
+		restricted: {Deny: []string{"93.184.216.0/24", "10.1.2.0/24"}},
+	})
+	internal := netip.MustParseAddr("10.0.0.5")
+	allowedInternal := netip.MustParseAddr("10.1.2.3")
+	public := netip.MustParseAddr("93.184.216.34")
+
+	// A tenant cannot be allowed a range the server denies
+	assert.ErrorIs(t, policy.Check(tenant.WithID(context.Background(), trusted), internal), netpolicy.ErrBlocked)
+	assert.ErrorIs(t, policy.Check(tenant.WithID(context.Background(), restricted), internal), netpolicy.ErrBlocked)
+
+	// but can be denied what the server allows
+	assert.NoError(t, policy.Check(tenant.WithID(context.Background(), trusted), allowedInternal))
+	assert.ErrorIs(t, policy.Check(tenant.WithID(context.Background(), restricted), allowedInternal), netpolicy.ErrBlocked)
+	assert.ErrorIs(t, policy.Check(tenant.WithID(context.Background(), restricted), public), netpolicy.ErrBlocked)
+	assert.NoError(t, policy.Check(tenant.WithID(context.Background(), trusted), public))
+}
+
+func TestPolicy_ClientRefusesBlockedAddresses(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	policy, err := netpolicy.New(models.NetworkPolicy{Deny: []string{"loopback"}})
+	require.NoError(t, err)
+	_, err = policy.Client(&http.Client{}).Get(server.URL)
+	assert.ErrorIs(t, err, netpolicy.ErrBlocked)
+
+	policy, err = netpolicy.New(models.NetworkPolicy{Deny: []string{"loopback"}, Allow: []string{"127.0.0.1"}})
+	require.NoError(t, err)
+	resp, err := policy.Client(&http.Client{}).Get(server.URL)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+}
+
+func TestValidate(t *testing.T) {
+	assert.NoError(t, netpolicy.Validate(models.NetworkPolicy{Allow: []string{"10.0.0.0/8", "::1", "Link-Local"}}))
+	assert.Error(t, netpolicy.Validate(models.NetworkPolicy{Deny: []string{"intranet"}}))
+	assert.Error(t, netpolicy.Validate(models.NetworkPolicy{Deny: []string{"10.0.0.0/33"}}))
+}
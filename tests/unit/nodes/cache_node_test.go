@@ -0,0 +1,139 @@
+package nodes_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nuumz/f1ow/internal/nodes"
+	"github.com/nuumz/f1ow/internal/tenant"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryCacheStore is an in-memory CacheStore for tests
+type memoryCacheStore struct {
+	entries map[string][]byte
+}
+
+func newMemoryCacheStore() *memoryCacheStore {
+	return &memoryCacheStore{entries: make(map[string][]byte)}
+}
+
+func (s *memoryCacheStore) GetCache(ctx context.Context, key string) ([]byte, bool, error) {
+	value, ok := s.entries[key]
+	return value, ok, nil
+}
+
+func (s *memoryCacheStore) SetCache(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	s.entries[key] = value
+	return nil
+}
+
+func (s *memoryCacheStore) DeleteCache(ctx context.Context, key string, prefix bool) (int64, error) {
+	var deleted int64
+	for k := range s.entries {
+		if k == key || (prefix && strings.HasPrefix(k, key)) {
+			delete(s.entries, k)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+func TestCacheNode_SetGetInvalidate(t *testing.T) {
+	node := nodes.NewCacheNode(newMemoryCacheStore())
+	ctx := context.Background()
+	input := map[string]interface{}{"id": "42", "user": map[string]interface{}{"name": "alice"}}
+
+	_, err := node.Execute(ctx, map[string]interface{}{
+		"operation": "set",
+		"key":       "user:{{id}}",
+		"value":     "{{user}}",
+	}, input)
+	require.NoError(t, err)
+
+	result, err := node.Execute(ctx, map[string]interface{}{"operation": "get", "key": "user:42"}, nil)
+	require.NoError(t, err)
+	output := result.(map[string]interface{})
+	assert.Equal(t, true, output["hit"])
+	assert.Equal(t, map[string]interface{}{"name": "alice"}, output["value"])
+
+	result, err = node.Execute(ctx, map[string]interface{}{"operation": "invalidate", "key": "user:", "prefix": true}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), result.(map[string]interface{})["deleted"])
+
+	result, err = node.Execute(ctx, map[string]interface{}{"operation": "get", "key": "user:42"}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, false, result.(map[string]interface{})["hit"])
+}
+
+func TestCacheNode_KeysAreScopedToTenant(t *testing.T) {
+	store := newMemoryCacheStore()
+	node := nodes.NewCacheNode(store)
+	first := tenant.WithID(context.Background(), uuid.New())
+	second := tenant.WithID(context.Background(), uuid.New())
+
+	_, err := node.Execute(first, map[string]interface{}{"operation": "set", "key": "token", "value": "secret"}, nil)
+	require.NoError(t, err)
+
+	result, err := node.Execute(second, map[string]interface{}{"operation": "get", "key": "token"}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, false, result.(map[string]interface{})["hit"])
+
+	// Another tenant's prefix invalidation leaves the entry alone
+	result, err = node.Execute(second, map[string]interface{}{"operation": "invalidate", "key": "t", "prefix": true}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), result.(map[string]interface{})["deleted"])
+
+	result, err = node.Execute(first, map[string]interface{}{"operation": "get", "key": "token"}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, true, result.(map[string]interface{})["hit"])
+}
+
+func TestCacheNode_RejectsEmptyPrefixInvalidation(t *testing.T) {
+	store := newMemoryCacheStore()
+	node := nodes.NewCacheNode(store)
+	config := map[string]interface{}{"operation": "invalidate", "key": "{{prefix}}", "prefix": true}
+
+	assert.Error(t, node.ValidateConfig(map[string]interface{}{"operation": "invalidate", "prefix": true}))
+
+	_, err := node.Execute(context.Background(), map[string]interface{}{"operation": "set", "key": "a", "value": 1}, nil)
+	require.NoError(t, err)
+	_, err = node.Execute(context.Background(), config, map[string]interface{}{"prefix": ""})
+	assert.ErrorContains(t, err, "key is required")
+	assert.Len(t, store.entries, 1)
+}
+
+func TestHTTPNode_CacheTTLServesRepeatedGets(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		fmt.Fprintf(w, `{"call":%d}`, calls)
+	}))
+	defer server.Close()
+
+	node := nodes.NewHTTPNode(newMemoryCacheStore())
+	config := map[string]interface{}{"url": server.URL, "cache_ttl": 60}
+
+	first, err := node.Execute(context.Background(), config, nil)
+	require.NoError(t, err)
+	second, err := node.Execute(context.Background(), config, nil)
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, calls)
+	assert.Equal(t, first.(map[string]interface{})["body"], second.(map[string]interface{})["body"])
+	assert.Equal(t, true, second.(map[string]interface{})["cached"])
+
+	// A different header is a different cache entry
+	config["headers"] = map[string]interface{}{"Authorization": "Bearer other"}
+	_, err = node.Execute(context.Background(), config, nil)
+	require.NoError(t, err)
+	assert.Equal(t, 2, calls)
+}
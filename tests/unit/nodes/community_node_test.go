@@ -0,0 +1,126 @@
+package nodes_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/nuumz/f1ow/internal/nodes"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const ticketsDefinition = `
+type: tickets
+name: Tickets
+base_url: https://tickets.invalid/api
+headers:
+  Accept: application/json
+auth:
+  type: bearer
+operations:
+  get_ticket:
+    method: get
+    path: /tickets/{id}
+    parameters:
+      - name: id
+        in: path
+      - name: expand
+  create_ticket:
+    method: post
+    path: /projects/{project}/tickets
+    parameters:
+      - name: project
+        in: path
+      - name: title
+        in: body
+        required: true
+      - name: priority
+        in: body
+        default: low
+`
+
+func TestCommunityNode_ParsesDefinitions(t *testing.T) {
+	definition, err := nodes.ParseCommunityNodeDefinition([]byte(ticketsDefinition))
+	require.NoError(t, err)
+	assert.Equal(t, "Community", definition.Category)
+	assert.Equal(t, "GET", definition.Operations["get_ticket"].Method)
+	assert.Equal(t, "query", definition.Operations["get_ticket"].Parameters[1].In)
+	assert.True(t, definition.Operations["get_ticket"].Parameters[0].Required)
+
+	_, err = nodes.ParseCommunityNodeDefinition([]byte(`{"type": "x", "base_url": "https://x.invalid", "operations": {"get": {"path": "/a", "parameters": [{"name": "id", "in": "path"}]}}}`))
+	assert.ErrorContains(t, err, "has no {id}")
+
+	_, err = nodes.ParseCommunityNodeDefinition([]byte(`type: x`))
+	assert.ErrorContains(t, err, "base_url is required")
+}
+
+func TestCommunityNode_LoadsDirectory(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "tickets.yaml"), []byte(ticketsDefinition), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "README.md"), []byte("ignored"), 0644))
+
+	definitions, err := nodes.LoadCommunityNodeDefinitions(dir)
+	require.NoError(t, err)
+	require.Len(t, definitions, 1)
+	assert.Equal(t, "tickets", definitions[0].Type)
+
+	examples, err := nodes.LoadCommunityNodeDefinitions("../../../examples/community-nodes")
+	require.NoError(t, err)
+	assert.NotEmpty(t, examples)
+}
+
+func TestCommunityNode_SendsOperationRequest(t *testing.T) {
+	var (
+		gotPath, gotAuth string
+		gotBody          map[string]interface{}
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.Method + " " + r.URL.Path
+		gotAuth = r.Header.Get("Authorization")
+		data, _ := io.ReadAll(r.Body)
+		json.Unmarshal(data, &gotBody)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id": 7}`))
+	}))
+	defer server.Close()
+
+	definition, err := nodes.ParseCommunityNodeDefinition([]byte(ticketsDefinition))
+	require.NoError(t, err)
+	node := nodes.NewCommunityNode(*definition, nil)
+
+	result, err := node.Execute(context.Background(), map[string]interface{}{
+		"operation": "create_ticket",
+		"base_url":  server.URL,
+		"project":   "ops team",
+		"title":     "{{summary}}",
+		"token":     "secret",
+	}, map[string]interface{}{"summary": "Disk full"})
+	require.NoError(t, err)
+
+	assert.Equal(t, "POST /projects/ops team/tickets", gotPath)
+	assert.Equal(t, "Bearer secret", gotAuth)
+	assert.Equal(t, map[string]interface{}{"title": "Disk full", "priority": "low"}, gotBody)
+	assert.Equal(t, 200, result.(map[string]interface{})["statusCode"])
+}
+
+func TestCommunityNode_ValidatesConfig(t *testing.T) {
+	definition, err := nodes.ParseCommunityNodeDefinition([]byte(ticketsDefinition))
+	require.NoError(t, err)
+	node := nodes.NewCommunityNode(*definition, nil)
+
+	assert.ErrorContains(t, node.ValidateConfig(map[string]interface{}{"operation": "delete"}), "operation must be one of: create_ticket, get_ticket")
+	assert.ErrorContains(t, node.ValidateConfig(map[string]interface{}{"operation": "get_ticket", "token": "t"}), "id is required")
+	assert.ErrorContains(t, node.ValidateConfig(map[string]interface{}{"operation": "get_ticket", "id": "1"}), "token or credential_id is required")
+	assert.NoError(t, node.ValidateConfig(map[string]interface{}{"operation": "get_ticket", "id": "1", "credential_id": "c"}))
+
+	schema := node.GetSchema()
+	assert.Equal(t, []string{"create_ticket", "get_ticket"}, schema.Properties["operation"].Enum)
+	assert.Equal(t, "password", schema.Properties["token"].Format)
+}
@@ -0,0 +1,105 @@
+package nodes_test
+
+import (
+	"context"
+	"encoding/base64"
+	"testing"
+
+	"github.com/nuumz/f1ow/internal/nodes"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCSVParseNode_Execute(t *testing.T) {
+	node := nodes.NewCSVParseNode()
+
+	config := map[string]interface{}{
+		"source":     "{{body}}",
+		"delimiter":  ";",
+		"quote":      "'",
+		"header_map": map[string]interface{}{"First Name": "first_name", "Notes": ""},
+	}
+	require.NoError(t, node.ValidateConfig(config))
+
+	body := "First Name;Age;Active;Zip;Notes\n'Smith; John';42;true;01234;x\n\n'O''Brien';3.5;false;;y\n"
+	result, err := node.Execute(context.Background(), config, map[string]interface{}{"body": body})
+	require.NoError(t, err)
+
+	output := result.(map[string]interface{})
+	assert.Equal(t, []interface{}{"first_name", "Age", "Active", "Zip"}, output["columns"])
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"first_name": "Smith; John", "Age": int64(42), "Active": true, "Zip": "01234"},
+		map[string]interface{}{"first_name": "O'Brien", "Age": 3.5, "Active": false, "Zip": nil},
+	}, output["items"])
+}
+
+func TestCSVParseNode_BinaryPayloadWithoutHeader(t *testing.T) {
+	node := nodes.NewCSVParseNode()
+
+	payload := map[string]interface{}{
+		"data":      base64.StdEncoding.EncodeToString([]byte("a,\"multi\nline\"\r\nb,c")),
+		"mime_type": "text/csv",
+	}
+	result, err := node.Execute(context.Background(), map[string]interface{}{
+		"source":      "{{file}}",
+		"header":      false,
+		"infer_types": false,
+	}, map[string]interface{}{"file": payload})
+	require.NoError(t, err)
+
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"column_1": "a", "column_2": "multi\nline"},
+		map[string]interface{}{"column_1": "b", "column_2": "c"},
+	}, result.(map[string]interface{})["items"])
+}
+
+func TestCSVGenerateNode_RoundTrip(t *testing.T) {
+	generate := nodes.NewCSVGenerateNode()
+	parse := nodes.NewCSVParseNode()
+
+	input := map[string]interface{}{
+		"items": []interface{}{
+			map[string]interface{}{"name": "Ann, Jr.", "score": 10},
+			map[string]interface{}{"name": "Bob", "score": 7, "team": "red"},
+		},
+	}
+
+	result, err := generate.Execute(context.Background(), map[string]interface{}{
+		"output":     "text",
+		"header_map": map[string]interface{}{"name": "Name"},
+	}, input)
+	require.NoError(t, err)
+	assert.Equal(t, "Name,score,team\n\"Ann, Jr.\",10,\nBob,7,red\n", result.(map[string]interface{})["content"])
+
+	for _, format := range []string{"csv", "xlsx"} {
+		t.Run(format, func(t *testing.T) {
+			result, err := generate.Execute(context.Background(), map[string]interface{}{
+				"format":    format,
+				"file_name": "report-{{id}}." + format,
+			}, mergeInput(input, "id", "7"))
+			require.NoError(t, err)
+
+			file := result.(map[string]interface{})
+			assert.Equal(t, "report-7."+format, file["file_name"])
+			assert.Equal(t, 2, file["count"])
+
+			parsed, err := parse.Execute(context.Background(), map[string]interface{}{
+				"source": "{{file}}",
+				"format": format,
+			}, map[string]interface{}{"file": file})
+			require.NoError(t, err)
+
+			items := parsed.(map[string]interface{})["items"].([]interface{})
+			assert.Equal(t, map[string]interface{}{"name": "Ann, Jr.", "score": int64(10), "team": nil}, items[0])
+		})
+	}
+}
+
+func mergeInput(input map[string]interface{}, key string, value interface{}) map[string]interface{} {
+	result := map[string]interface{}{key: value}
+	for k, v := range input {
+		result[k] = v
+	}
+	return result
+}
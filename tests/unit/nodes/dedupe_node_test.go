@@ -0,0 +1,79 @@
+package nodes_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/nuumz/f1ow/internal/engine"
+	"github.com/nuumz/f1ow/internal/nodes"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memorySeenStore is an in-memory SeenStore for tests
+type memorySeenStore struct {
+	seen map[string]bool
+}
+
+func (s *memorySeenStore) MarkSeen(ctx context.Context, scope string, keys []string, ttl time.Duration) ([]bool, error) {
+	result := make([]bool, len(keys))
+	for i, key := range keys {
+		result[i] = s.seen[scope+"/"+key]
+		s.seen[scope+"/"+key] = true
+	}
+	return result, nil
+}
+
+func TestDedupeNode_Execute(t *testing.T) {
+	store := &memorySeenStore{seen: make(map[string]bool)}
+	node := nodes.NewDedupeNode(store, nil)
+
+	ctx := engine.WithExecutionInfo(context.Background(), engine.ExecutionInfo{
+		WorkflowID: "wf-1",
+		NodeID:     "dedupe-1",
+	})
+
+	config := map[string]interface{}{
+		"array_path": "records",
+		"key_fields": []interface{}{"id"},
+	}
+
+	first := map[string]interface{}{
+		"records": []interface{}{
+			map[string]interface{}{"id": float64(1), "v": "a"},
+			map[string]interface{}{"id": float64(2), "v": "b"},
+		},
+	}
+
+	result, err := node.Execute(ctx, config, first)
+	require.NoError(t, err)
+	assert.Equal(t, 2, result.(map[string]interface{})["new_count"])
+
+	second := map[string]interface{}{
+		"records": []interface{}{
+			map[string]interface{}{"id": float64(2), "v": "changed"},
+			map[string]interface{}{"id": float64(3), "v": "c"},
+		},
+	}
+
+	result, err = node.Execute(ctx, config, second)
+	require.NoError(t, err)
+
+	resultMap := result.(map[string]interface{})
+	assert.Equal(t, 1, resultMap["new_count"])
+	assert.Equal(t, 1, resultMap["duplicate_count"])
+	assert.Equal(t, float64(3), resultMap["items"].([]interface{})[0].(map[string]interface{})["id"])
+}
+
+func TestDedupeNode_UnavailableStore(t *testing.T) {
+	node := nodes.NewDedupeNode(nil, nil)
+
+	_, err := node.Execute(context.Background(), map[string]interface{}{
+		"array_path": "records",
+		"scope":      "shared",
+	}, map[string]interface{}{"records": []interface{}{}})
+
+	assert.Error(t, err)
+}
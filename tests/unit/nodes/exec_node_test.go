@@ -0,0 +1,86 @@
+package nodes_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/nuumz/f1ow/internal/engine"
+	"github.com/nuumz/f1ow/internal/models"
+	"github.com/nuumz/f1ow/internal/nodes"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type stubUserStore map[uuid.UUID]*models.User
+
+func (s stubUserStore) GetUser(ctx context.Context, id uuid.UUID) (*models.User, error) {
+	return s[id], nil
+}
+
+func TestExecNode_Permissions(t *testing.T) {
+	admin := &models.User{ID: uuid.New(), Role: models.RoleAdmin}
+	user := &models.User{ID: uuid.New(), Role: models.RoleUser}
+	users := stubUserStore{admin.ID: admin, user.ID: user}
+	config := map[string]interface{}{"command": "echo", "args": []interface{}{"hi"}}
+
+	disabled := nodes.NewExecNode(false, users)
+	assert.ErrorContains(t, disabled.ValidateConfig(config), "ENABLE_EXEC_NODE")
+	_, err := disabled.Execute(ownerContext(admin.ID), config, nil)
+	assert.ErrorContains(t, err, "disabled")
+
+	node := nodes.NewExecNode(true, users)
+	require.NoError(t, node.ValidateConfig(config))
+
+	_, err = node.Execute(ownerContext(user.ID), config, nil)
+	assert.ErrorContains(t, err, models.PermissionExecNode)
+
+	_, err = node.Execute(context.Background(), config, nil)
+	assert.ErrorContains(t, err, models.PermissionExecNode)
+
+	result, err := node.Execute(ownerContext(admin.ID), config, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "hi\n", result.(map[string]interface{})["stdout"])
+}
+
+func TestExecNode_Execute(t *testing.T) {
+	admin := &models.User{ID: uuid.New(), Role: models.RoleAdmin}
+	node := nodes.NewExecNode(true, stubUserStore{admin.ID: admin})
+	ctx := ownerContext(admin.ID)
+
+	t.Setenv("EXEC_NODE_TEST_SECRET", "leaked")
+	result, err := node.Execute(ctx, map[string]interface{}{
+		"command":    `cat; echo "[$GREETING][$EXEC_NODE_TEST_SECRET]"; echo oops >&2; exit 3`,
+		"shell":      true,
+		"stdin":      "{{name}} ",
+		"env":        map[string]interface{}{"GREETING": "hello {{name}}"},
+		"max_output": 20,
+	}, map[string]interface{}{"name": "ann"})
+	require.NoError(t, err)
+
+	output := result.(map[string]interface{})
+	assert.Equal(t, "ann [hello ann][]\n", output["stdout"])
+	assert.Equal(t, "oops\n", output["stderr"])
+	assert.Equal(t, 3, output["exit_code"])
+	assert.Equal(t, false, output["truncated"])
+
+	result, err = node.Execute(ctx, map[string]interface{}{
+		"command":    "yes | head -c 100",
+		"shell":      true,
+		"max_output": 10,
+	}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "y\ny\ny\ny\ny\n", result.(map[string]interface{})["stdout"])
+	assert.Equal(t, true, result.(map[string]interface{})["truncated"])
+
+	_, err = node.Execute(ctx, map[string]interface{}{"command": "false", "fail_on_error": true}, nil)
+	assert.ErrorContains(t, err, "exited with code 1")
+
+	_, err = node.Execute(ctx, map[string]interface{}{"command": "sleep", "args": []interface{}{"5"}, "timeout": 1}, nil)
+	assert.ErrorContains(t, err, "timed out")
+}
+
+func ownerContext(userID uuid.UUID) context.Context {
+	return engine.WithExecutionInfo(context.Background(), engine.ExecutionInfo{UserID: userID.String()})
+}
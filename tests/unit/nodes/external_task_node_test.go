@@ -0,0 +1,108 @@
+package nodes_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/nuumz/f1ow/internal/engine"
+	"github.com/nuumz/f1ow/internal/models"
+	"github.com/nuumz/f1ow/internal/nodes"
+	"github.com/nuumz/f1ow/internal/storage"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryTaskStore keeps external tasks in a map; finish plays the worker
+type memoryTaskStore struct {
+	mu    sync.Mutex
+	tasks map[uuid.UUID]models.ExternalTask
+}
+
+func (s *memoryTaskStore) CreateExternalTask(ctx context.Context, task *models.ExternalTask) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	task.Status = models.ExternalTaskPending
+	s.tasks[task.ID] = *task
+	return nil
+}
+
+func (s *memoryTaskStore) GetExternalTask(ctx context.Context, id uuid.UUID) (*models.ExternalTask, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	task, ok := s.tasks[id]
+	if !ok {
+		return nil, storage.ErrExternalTaskNotFound
+	}
+	return &task, nil
+}
+
+// finish completes the only task once it exists, or fails it with message
+func (s *memoryTaskStore) finish(t *testing.T, result map[string]interface{}, message string) {
+	require.Eventually(t, func() bool {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		for id, task := range s.tasks {
+			if message != "" {
+				task.Status, task.Error = models.ExternalTaskFailed, &message
+			} else {
+				task.Status, task.Result = models.ExternalTaskCompleted, result
+			}
+			s.tasks[id] = task
+			return true
+		}
+		return false
+	}, time.Second, 10*time.Millisecond)
+}
+
+func externalTaskContext() context.Context {
+	return engine.WithExecutionInfo(context.Background(), engine.ExecutionInfo{
+		WorkflowID: uuid.NewString(), ExecutionID: uuid.NewString(), NodeID: "charge",
+	})
+}
+
+func TestExternalTaskNode_WaitsForWorkerResult(t *testing.T) {
+	store := &memoryTaskStore{tasks: map[uuid.UUID]models.ExternalTask{}}
+	node := nodes.NewExternalTaskNode(store)
+	ctx := externalTaskContext()
+	config := map[string]interface{}{"topic": "charge-card", "payload": map[string]interface{}{"amount": "{{total}}"}}
+
+	go store.finish(t, map[string]interface{}{"charge_id": "ch_1"}, "")
+	result, err := node.Execute(ctx, config, map[string]interface{}{"total": 42})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"charge_id": "ch_1"}, result.(map[string]interface{})["result"])
+
+	require.Len(t, store.tasks, 1)
+	for _, task := range store.tasks {
+		assert.Equal(t, "charge-card", task.Topic)
+		assert.Equal(t, "charge", task.NodeID)
+		assert.Equal(t, map[string]interface{}{"amount": "42"}, task.Payload)
+	}
+
+	// Running the node again for the same execution reuses the finished task
+	result, err = node.Execute(ctx, config, map[string]interface{}{"total": 42})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"charge_id": "ch_1"}, result.(map[string]interface{})["result"])
+	assert.Len(t, store.tasks, 1)
+}
+
+func TestExternalTaskNode_FailsWithWorkerError(t *testing.T) {
+	store := &memoryTaskStore{tasks: map[uuid.UUID]models.ExternalTask{}}
+	node := nodes.NewExternalTaskNode(store)
+
+	go store.finish(t, nil, "card declined")
+	_, err := node.Execute(externalTaskContext(), map[string]interface{}{"topic": "charge-card"}, map[string]interface{}{"total": 42})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "card declined")
+}
+
+func TestExternalTaskNode_ValidateConfig(t *testing.T) {
+	node := nodes.NewExternalTaskNode(nil)
+
+	assert.NoError(t, node.ValidateConfig(map[string]interface{}{"topic": "charge-card"}))
+	assert.Error(t, node.ValidateConfig(map[string]interface{}{}))
+	assert.Error(t, node.ValidateConfig(map[string]interface{}{"topic": "charge-card", "timeout": 0}))
+}
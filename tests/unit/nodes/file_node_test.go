@@ -0,0 +1,109 @@
+package nodes_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/nuumz/f1ow/internal/nodes"
+	"github.com/nuumz/f1ow/internal/tenant"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileNode_RoundTrip(t *testing.T) {
+	node := nodes.NewFileNode(t.TempDir())
+	ctx := context.Background()
+
+	input := map[string]interface{}{
+		"items": []interface{}{
+			map[string]interface{}{"name": "alice", "city": "Paris"},
+			map[string]interface{}{"name": "bob", "city": "Oslo"},
+			map[string]interface{}{"name": "carol", "city": "Rome"},
+		},
+	}
+
+	for _, file := range []string{"people.csv", "people.json", "people.ndjson", "people.xlsx"} {
+		t.Run(file, func(t *testing.T) {
+			writeConfig := map[string]interface{}{"operation": "write", "path": "exports/" + file}
+			require.NoError(t, node.ValidateConfig(writeConfig))
+
+			result, err := node.Execute(ctx, writeConfig, input)
+			require.NoError(t, err)
+			assert.Equal(t, 3, result.(map[string]interface{})["count"])
+
+			result, err = node.Execute(ctx, map[string]interface{}{
+				"operation": "read",
+				"path":      "exports/" + file,
+				"offset":    1,
+				"limit":     1,
+			}, map[string]interface{}{})
+			require.NoError(t, err)
+
+			output := result.(map[string]interface{})
+			assert.Equal(t, []interface{}{map[string]interface{}{"name": "bob", "city": "Oslo"}}, output["items"])
+			assert.Equal(t, 2, output["next_offset"])
+			assert.Equal(t, true, output["has_more"])
+		})
+	}
+}
+
+func TestFileNode_AppendCSV(t *testing.T) {
+	root := t.TempDir()
+	node := nodes.NewFileNode(root)
+	ctx := context.Background()
+
+	config := map[string]interface{}{"operation": "write", "path": "log.csv", "append": true, "columns": []interface{}{"id"}}
+	for _, id := range []string{"1", "2"} {
+		_, err := node.Execute(ctx, config, map[string]interface{}{
+			"items": []interface{}{map[string]interface{}{"id": id}},
+		})
+		require.NoError(t, err)
+	}
+
+	content, err := os.ReadFile(filepath.Join(root, tenant.DefaultID.String(), "log.csv"))
+	require.NoError(t, err)
+	assert.Equal(t, "id\n1\n2\n", string(content))
+}
+
+func TestFileNode_PathStaysInRoot(t *testing.T) {
+	root := t.TempDir()
+	node := nodes.NewFileNode(filepath.Join(root, "files"))
+
+	_, err := node.Execute(context.Background(), map[string]interface{}{
+		"operation": "write",
+		"path":      "../../escape.txt",
+		"content":   "{{text}}",
+	}, map[string]interface{}{"text": "hello"})
+	require.NoError(t, err)
+
+	content, err := os.ReadFile(filepath.Join(root, "files", tenant.DefaultID.String(), "escape.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, "hello", string(content))
+}
+
+func TestFileNode_TenantsHaveSeparateDirectories(t *testing.T) {
+	root := t.TempDir()
+	node := nodes.NewFileNode(root)
+	first, second := uuid.New(), uuid.New()
+
+	_, err := node.Execute(tenant.WithID(context.Background(), first), map[string]interface{}{
+		"operation": "write", "path": "secret.txt", "content": "first",
+	}, map[string]interface{}{})
+	require.NoError(t, err)
+
+	content, err := os.ReadFile(filepath.Join(root, first.String(), "secret.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, "first", string(content))
+
+	// Another tenant cannot read it, even by climbing out of its directory
+	for _, path := range []string{"secret.txt", "../" + first.String() + "/secret.txt"} {
+		_, err = node.Execute(tenant.WithID(context.Background(), second), map[string]interface{}{
+			"operation": "read", "path": path, "format": "text",
+		}, map[string]interface{}{})
+		assert.Error(t, err, path)
+	}
+}
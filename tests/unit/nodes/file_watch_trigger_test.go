@@ -0,0 +1,51 @@
+package nodes_test
+
+import (
+	"context"
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/nuumz/f1ow/internal/nodes"
+	"github.com/nuumz/f1ow/internal/tenant"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileWatchTriggerNode_LoadsContent(t *testing.T) {
+	root := t.TempDir()
+	inbox := filepath.Join(root, tenant.DefaultID.String(), "inbox")
+	require.NoError(t, os.MkdirAll(inbox, 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(inbox, "orders.json"), []byte(`[{"id":1}]`), 0o644))
+
+	node := nodes.NewFileWatchTriggerNode(root)
+	config := map[string]interface{}{"path": "inbox", "max_file_size": 1024}
+	require.NoError(t, node.ValidateConfig(config))
+
+	event := map[string]interface{}{"event": "created", "path": "inbox/orders.json", "name": "orders.json", "size": float64(10)}
+	result, err := node.Execute(context.Background(), config, event)
+	require.NoError(t, err)
+
+	output := result.(map[string]interface{})
+	assert.Equal(t, "created", output["event"])
+	file := output["file"].(map[string]interface{})
+	assert.Equal(t, "orders.json", file["file_name"])
+	assert.Equal(t, "application/json", file["mime_type"])
+	assert.Equal(t, base64.StdEncoding.EncodeToString([]byte(`[{"id":1}]`)), file["data"])
+
+	// Files larger than max_file_size are rejected
+	_, err = node.Execute(context.Background(), map[string]interface{}{"path": "inbox", "max_file_size": 2}, event)
+	assert.Error(t, err)
+}
+
+func TestFileWatchTriggerNode_ValidateConfig(t *testing.T) {
+	node := nodes.NewFileWatchTriggerNode(t.TempDir())
+
+	assert.Error(t, node.ValidateConfig(map[string]interface{}{"source": "ftp"}))
+	assert.Error(t, node.ValidateConfig(map[string]interface{}{"pattern": "[bad"}))
+	assert.Error(t, node.ValidateConfig(map[string]interface{}{"events": []interface{}{"deleted"}}))
+	assert.Error(t, node.ValidateConfig(map[string]interface{}{"source": "sftp", "path": "/in", "sftp": map[string]interface{}{"host": "sftp.example.com", "username": "etl"}}))
+	assert.NoError(t, node.ValidateConfig(map[string]interface{}{"source": "s3", "path": "incoming/", "s3": map[string]interface{}{"endpoint": "s3.amazonaws.com", "bucket": "drops"}}))
+}
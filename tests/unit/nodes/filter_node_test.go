@@ -0,0 +1,89 @@
+package nodes_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/nuumz/f1ow/internal/nodes"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFilterNode_Execute(t *testing.T) {
+	node := nodes.NewFilterNode()
+
+	config := map[string]interface{}{
+		"array_path": "orders",
+		"combinator": "or",
+		"groups": []interface{}{
+			map[string]interface{}{
+				"combinator": "and",
+				"conditions": []interface{}{
+					map[string]interface{}{"field": "status", "operator": "equals", "value": "paid"},
+					map[string]interface{}{"field": "total", "operator": "gt", "value": 100},
+				},
+			},
+			map[string]interface{}{
+				"conditions": []interface{}{
+					map[string]interface{}{"field": "priority", "operator": "exists"},
+				},
+			},
+		},
+	}
+
+	input := map[string]interface{}{
+		"orders": []interface{}{
+			map[string]interface{}{"id": "a", "status": "paid", "total": float64(150)},
+			map[string]interface{}{"id": "b", "status": "paid", "total": float64(50)},
+			map[string]interface{}{"id": "c", "status": "open", "priority": true},
+			map[string]interface{}{"id": "d", "status": "open"},
+		},
+	}
+
+	result, err := node.Execute(context.Background(), config, input)
+	require.NoError(t, err)
+
+	resultMap, ok := result.(map[string]interface{})
+	require.True(t, ok)
+
+	ids := func(items interface{}) []string {
+		var out []string
+		for _, item := range items.([]interface{}) {
+			out = append(out, item.(map[string]interface{})["id"].(string))
+		}
+		return out
+	}
+
+	assert.Equal(t, []string{"a", "c"}, ids(resultMap["matched"]))
+	assert.Equal(t, []string{"b", "d"}, ids(resultMap["unmatched"]))
+	assert.Equal(t, 2, resultMap["matched_count"])
+}
+
+func TestFilterNode_ValidateConfig(t *testing.T) {
+	node := nodes.NewFilterNode()
+
+	err := node.ValidateConfig(map[string]interface{}{
+		"array_path": "items",
+		"groups": []interface{}{
+			map[string]interface{}{
+				"conditions": []interface{}{
+					map[string]interface{}{"field": "x", "operator": "matches"},
+				},
+			},
+		},
+	})
+	assert.Error(t, err)
+
+	err = node.ValidateConfig(map[string]interface{}{
+		"array_path": "items",
+		"groups": []interface{}{
+			map[string]interface{}{
+				"conditions": []interface{}{
+					map[string]interface{}{"field": "x", "operator": "in", "value": []interface{}{1, 2}},
+				},
+			},
+		},
+	})
+	assert.NoError(t, err)
+}
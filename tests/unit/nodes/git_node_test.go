@@ -0,0 +1,138 @@
+package nodes_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/nuumz/f1ow/internal/nodes"
+	"github.com/nuumz/f1ow/internal/tenant"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// initRepository creates a repository at dir with one committed file
+func initRepository(t *testing.T, dir string) string {
+	_, err := git.PlainInit(dir, false)
+	require.NoError(t, err)
+	return commitFile(t, dir, "README.md", "hello\n")
+}
+
+// commitFile writes and commits a file in the repository at dir
+func commitFile(t *testing.T, dir, name, content string) string {
+	repo, err := git.PlainOpen(dir)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644))
+
+	worktree, err := repo.Worktree()
+	require.NoError(t, err)
+	_, err = worktree.Add(name)
+	require.NoError(t, err)
+	hash, err := worktree.Commit("update "+name, &git.CommitOptions{
+		Author: &object.Signature{Name: "test", Email: "test@example.com", When: time.Now()},
+	})
+	require.NoError(t, err)
+	return hash.String()
+}
+
+func TestGitNode_WorkspacesAreScopedToTenant(t *testing.T) {
+	root := t.TempDir()
+	node := nodes.NewGitNode(root)
+	first, second := uuid.New(), uuid.New()
+	head := initRepository(t, filepath.Join(root, first.String(), "origin"))
+
+	// Local remotes resolve inside the tenant's workspace
+	result, err := node.Execute(tenant.WithID(context.Background(), first), map[string]interface{}{
+		"operation": "clone", "path": "checkout", "url": "/origin",
+	}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, head, result.(map[string]interface{})["head"])
+	assert.FileExists(t, filepath.Join(root, first.String(), "checkout", "README.md"))
+
+	secondCtx := tenant.WithID(context.Background(), second)
+	for _, path := range []string{"checkout", "../" + first.String() + "/checkout"} {
+		_, err = node.Execute(secondCtx, map[string]interface{}{"operation": "pull", "path": path}, nil)
+		assert.ErrorContains(t, err, "failed to open repository", path)
+	}
+	_, err = node.Execute(secondCtx, map[string]interface{}{
+		"operation": "clone", "path": "stolen", "url": "file://../" + first.String() + "/origin",
+	}, nil)
+	assert.Error(t, err)
+	assert.NoDirExists(t, filepath.Join(root, second.String(), "stolen", ".git"))
+}
+
+func TestGitNode_Operations(t *testing.T) {
+	root := t.TempDir()
+	node := nodes.NewGitNode(root)
+	ctx := context.Background()
+	workspace := filepath.Join(root, tenant.DefaultID.String())
+	origin := filepath.Join(workspace, "origin")
+	initRepository(t, origin)
+
+	run := func(config map[string]interface{}, input map[string]interface{}) map[string]interface{} {
+		t.Helper()
+		require.NoError(t, node.ValidateConfig(config))
+		result, err := node.Execute(ctx, config, input)
+		require.NoError(t, err)
+		return result.(map[string]interface{})
+	}
+
+	run(map[string]interface{}{"operation": "clone", "path": "checkout", "url": "/origin"}, nil)
+
+	// Pulling reports whether the checkout moved
+	head := commitFile(t, origin, "CHANGELOG.md", "v1\n")
+	result := run(map[string]interface{}{"operation": "pull", "path": "checkout"}, nil)
+	assert.Equal(t, head, result["head"])
+	assert.Equal(t, true, result["updated"])
+	assert.Equal(t, false, run(map[string]interface{}{"operation": "pull", "path": "checkout"}, nil)["updated"])
+
+	// Work on a new branch, commit, and tag it
+	result = run(map[string]interface{}{"operation": "branch", "path": "checkout", "branch": "release-{{version}}"}, map[string]interface{}{"version": "1"})
+	assert.Equal(t, "release-1", result["branch"])
+	assert.Equal(t, true, result["created"])
+
+	require.NoError(t, os.WriteFile(filepath.Join(workspace, "checkout", "VERSION"), []byte("1\n"), 0o644))
+	result = run(map[string]interface{}{"operation": "commit", "path": "checkout", "message": "Release {{version}}", "author_name": "bot"}, map[string]interface{}{"version": "1"})
+	assert.Equal(t, true, result["committed"])
+	commitHash := result["hash"].(string)
+
+	result = run(map[string]interface{}{"operation": "commit", "path": "checkout", "message": "nothing"}, nil)
+	assert.Equal(t, false, result["committed"])
+
+	run(map[string]interface{}{"operation": "tag", "path": "checkout", "tag": "v1", "message": "Version 1"}, nil)
+	assert.Equal(t, true, run(map[string]interface{}{"operation": "push", "path": "checkout", "tags": true}, nil)["pushed"])
+	assert.Equal(t, false, run(map[string]interface{}{"operation": "push", "path": "checkout"}, nil)["pushed"])
+
+	repo, err := git.PlainOpen(origin)
+	require.NoError(t, err)
+	branch, err := repo.Reference(plumbing.NewBranchReferenceName("release-1"), false)
+	require.NoError(t, err)
+	assert.Equal(t, commitHash, branch.Hash().String())
+	commit, err := repo.CommitObject(branch.Hash())
+	require.NoError(t, err)
+	assert.Equal(t, "Release 1", commit.Message)
+	assert.Equal(t, "bot", commit.Author.Name)
+	_, err = repo.Tag("v1")
+	assert.NoError(t, err)
+}
+
+func TestGitNode_ValidateConfig(t *testing.T) {
+	node := nodes.NewGitNode(t.TempDir())
+
+	assert.ErrorContains(t, node.ValidateConfig(map[string]interface{}{"operation": "rebase", "path": "repo"}), "invalid operation")
+	assert.ErrorContains(t, node.ValidateConfig(map[string]interface{}{"operation": "pull"}), "path is required")
+	assert.ErrorContains(t, node.ValidateConfig(map[string]interface{}{"operation": "clone", "path": "repo"}), "url is required")
+	assert.ErrorContains(t, node.ValidateConfig(map[string]interface{}{"operation": "commit", "path": "repo"}), "message is required")
+	assert.ErrorContains(t, node.ValidateConfig(map[string]interface{}{"operation": "pull", "path": "repo", "auth_type": "token"}), "token is required")
+	assert.ErrorContains(t, node.ValidateConfig(map[string]interface{}{"operation": "pull", "path": "repo", "auth_type": "ssh"}), "private_key is required")
+
+	_, err := nodes.NewGitNode("").Execute(context.Background(), map[string]interface{}{"operation": "pull", "path": "repo"}, nil)
+	assert.ErrorContains(t, err, "workspace root is not configured")
+}
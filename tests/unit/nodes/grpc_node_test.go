@@ -0,0 +1,93 @@
+package nodes_test
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/nuumz/f1ow/internal/nodes"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
+)
+
+const healthProto = `syntax = "proto3";
+package grpc.health.v1;
+
+message HealthCheckRequest { string service = 1; }
+message HealthCheckResponse {
+  enum ServingStatus { UNKNOWN = 0; SERVING = 1; NOT_SERVING = 2; SERVICE_UNKNOWN = 3; }
+  ServingStatus status = 1;
+}
+service Health {
+  rpc Check(HealthCheckRequest) returns (HealthCheckResponse);
+}
+`
+
+func startHealthServer(t *testing.T) string {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	healthServer := health.NewServer()
+	healthServer.SetServingStatus("orders", healthpb.HealthCheckResponse_SERVING)
+
+	server := grpc.NewServer()
+	healthpb.RegisterHealthServer(server, healthServer)
+	reflection.Register(server)
+
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	return listener.Addr().String()
+}
+
+func TestGRPCNode_Reflection(t *testing.T) {
+	address := startHealthServer(t)
+	node := nodes.NewGRPCNode()
+
+	config := map[string]interface{}{
+		"address": address,
+		"method":  "grpc.health.v1.Health/Check",
+		"request": map[string]interface{}{"service": "{{service}}"},
+	}
+	require.NoError(t, node.ValidateConfig(config))
+
+	result, err := node.Execute(context.Background(), config, map[string]interface{}{"service": "orders"})
+	require.NoError(t, err)
+
+	response := result.(map[string]interface{})["response"].(map[string]interface{})
+	assert.Equal(t, "SERVING", response["status"])
+}
+
+func TestGRPCNode_ProtoFiles(t *testing.T) {
+	address := startHealthServer(t)
+	node := nodes.NewGRPCNode()
+
+	config := map[string]interface{}{
+		"address":         address,
+		"method":          "/grpc.health.v1.Health/Check",
+		"descriptor_mode": "proto",
+		"proto_files":     map[string]interface{}{"health.proto": healthProto},
+		"request":         map[string]interface{}{"service": "missing"},
+	}
+	require.NoError(t, node.ValidateConfig(config))
+
+	_, err := node.Execute(context.Background(), config, map[string]interface{}{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "NotFound")
+}
+
+func TestGRPCNode_ValidateConfig(t *testing.T) {
+	node := nodes.NewGRPCNode()
+
+	assert.Error(t, node.ValidateConfig(map[string]interface{}{"address": "localhost:50051", "method": "Check"}))
+	assert.Error(t, node.ValidateConfig(map[string]interface{}{
+		"address":         "localhost:50051",
+		"method":          "pkg.Service/Method",
+		"descriptor_mode": "proto",
+	}))
+}
@@ -0,0 +1,318 @@
+package nodes_test
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/nuumz/f1ow/internal/models"
+	"github.com/nuumz/f1ow/internal/netpolicy"
+	"github.com/nuumz/f1ow/internal/nodes"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHTTPNode_CursorPagination(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Query().Get("cursor") {
+		case "":
+			fmt.Fprint(w, `{"data":{"items":[1,2]},"next":"abc"}`)
+		case "abc":
+			fmt.Fprint(w, `{"data":{"items":[3]},"next":null}`)
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	defer server.Close()
+
+	node := nodes.NewHTTPNode(nil)
+	result, err := node.Execute(context.Background(), map[string]interface{}{
+		"url": server.URL,
+		"pagination": map[string]interface{}{
+			"type":         "cursor",
+			"items_path":   "data.items",
+			"cursor_path":  "next",
+			"cursor_param": "cursor",
+		},
+	}, nil)
+	require.NoError(t, err)
+
+	output := result.(map[string]interface{})
+	assert.Equal(t, []interface{}{float64(1), float64(2), float64(3)}, output["body"])
+	assert.Equal(t, 2, output["pages"])
+	assert.Equal(t, false, output["truncated"])
+}
+
+func TestHTTPNode_PagePagination(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "2", r.URL.Query().Get("per_page"))
+		switch r.URL.Query().Get("page") {
+		case "1":
+			fmt.Fprint(w, `[{"id":1},{"id":2}]`)
+		case "2":
+			fmt.Fprint(w, `[{"id":3}]`)
+		default:
+			fmt.Fprint(w, `[]`)
+		}
+	}))
+	defer server.Close()
+
+	node := nodes.NewHTTPNode(nil)
+	result, err := node.Execute(context.Background(), map[string]interface{}{
+		"url": server.URL,
+		"pagination": map[string]interface{}{
+			"type":            "page",
+			"page_size":       2,
+			"page_size_param": "per_page",
+		},
+	}, nil)
+	require.NoError(t, err)
+
+	output := result.(map[string]interface{})
+	assert.Equal(t, 3, output["count"])
+	assert.Equal(t, 2, output["pages"])
+}
+
+func TestHTTPNode_LinkHeaderPaginationStopsAtMaxPages(t *testing.T) {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Link", fmt.Sprintf(`<%s/items?after=%s>; rel="next", <%s/items>; rel="first"`, server.URL, r.URL.Query().Get("after")+"x", server.URL))
+		fmt.Fprint(w, `{"results":["item"]}`)
+	}))
+	defer server.Close()
+
+	node := nodes.NewHTTPNode(nil)
+	result, err := node.Execute(context.Background(), map[string]interface{}{
+		"url": server.URL + "/items",
+		"pagination": map[string]interface{}{
+			"type":       "link_header",
+			"items_path": "results",
+			"max_pages":  3,
+		},
+	}, nil)
+	require.NoError(t, err)
+
+	output := result.(map[string]interface{})
+	assert.Equal(t, 3, output["pages"])
+	assert.Equal(t, 3, output["count"])
+	assert.Equal(t, true, output["truncated"])
+}
+
+func TestHTTPNode_ValidatePagination(t *testing.T) {
+	node := nodes.NewHTTPNode(nil)
+
+	err := node.ValidateConfig(map[string]interface{}{
+		"url":        "https://api.example.com",
+		"pagination": map[string]interface{}{"type": "cursor"},
+	})
+	assert.Error(t, err)
+
+	err = node.ValidateConfig(map[string]interface{}{
+		"url":        "https://api.example.com",
+		"pagination": map[string]interface{}{"type": "offset"},
+	})
+	assert.Error(t, err)
+}
+
+func TestHTTPNode_DecompressesGzipResponse(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Encoding", "gzip")
+		gz := gzip.NewWriter(w)
+		fmt.Fprint(gz, `{"ok":true}`)
+		gz.Close()
+	}))
+	defer server.Close()
+
+	node := nodes.NewHTTPNode(nil)
+	result, err := node.Execute(context.Background(), map[string]interface{}{
+		"url":     server.URL,
+		"headers": map[string]interface{}{"Accept-Encoding": "gzip"},
+	}, nil)
+	require.NoError(t, err)
+
+	output := result.(map[string]interface{})
+	assert.Equal(t, map[string]interface{}{"ok": true}, output["body"])
+}
+
+func TestHTTPNode_MaxMemorySize(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "0123456789")
+	}))
+	defer server.Close()
+
+	node := nodes.NewHTTPNode(nil)
+	_, err := node.Execute(context.Background(), map[string]interface{}{
+		"url":             server.URL,
+		"response_type":   "text",
+		"max_memory_size": 5,
+	}, nil)
+	assert.ErrorContains(t, err, "max_memory_size")
+
+	_, err = node.Execute(context.Background(), map[string]interface{}{
+		"url":    server.URL,
+		"stream": true,
+	}, nil)
+	assert.ErrorContains(t, err, "binary data storage")
+}
+
+func TestHTTPNode_FormURLEncodedBody(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "application/x-www-form-urlencoded", r.Header.Get("Content-Type"))
+		require.NoError(t, r.ParseForm())
+		assert.Equal(t, "alice", r.PostForm.Get("name"))
+		assert.Equal(t, []string{"a", "b"}, r.PostForm["tags"])
+	}))
+	defer server.Close()
+
+	node := nodes.NewHTTPNode(nil)
+	_, err := node.Execute(context.Background(), map[string]interface{}{
+		"url":       server.URL,
+		"method":    "POST",
+		"body_type": "form_urlencoded",
+		"body": map[string]interface{}{
+			"name": "{{user}}",
+			"tags": []interface{}{"a", "b"},
+		},
+	}, map[string]interface{}{"user": "alice"})
+	require.NoError(t, err)
+}
+
+func TestHTTPNode_MultipartBodyWithFile(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseMultipartForm(1<<20))
+		assert.Equal(t, "report", r.FormValue("title"))
+
+		file, header, err := r.FormFile("attachment")
+		require.NoError(t, err)
+		defer file.Close()
+		assert.Equal(t, "hello.txt", header.Filename)
+		assert.Equal(t, "text/plain", header.Header.Get("Content-Type"))
+	}))
+	defer server.Close()
+
+	node := nodes.NewHTTPNode(nil)
+	_, err := node.Execute(context.Background(), map[string]interface{}{
+		"url":       server.URL,
+		"method":    "POST",
+		"body_type": "multipart",
+		"body": map[string]interface{}{
+			"title":      "report",
+			"attachment": "{{file}}",
+		},
+	}, map[string]interface{}{
+		"file": map[string]interface{}{
+			"data":      "aGVsbG8=",
+			"mime_type": "text/plain",
+			"file_name": "hello.txt",
+		},
+	})
+	require.NoError(t, err)
+}
+
+func TestHTTPNode_CustomCACertificate(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"ok":true}`)
+	}))
+	defer server.Close()
+
+	caCert := string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw}))
+
+	node := nodes.NewHTTPNode(nil)
+	_, err := node.Execute(context.Background(), map[string]interface{}{"url": server.URL}, nil)
+	assert.Error(t, err)
+
+	_, err = node.Execute(context.Background(), map[string]interface{}{
+		"url":     server.URL,
+		"ca_cert": caCert,
+	}, nil)
+	assert.NoError(t, err)
+}
+
+func TestHTTPNode_Proxy(t *testing.T) {
+	proxied := false
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied = true
+		assert.Equal(t, "http://upstream.invalid/items", r.URL.String())
+		fmt.Fprint(w, `[]`)
+	}))
+	defer proxy.Close()
+
+	node := nodes.NewHTTPNode(nil)
+	_, err := node.Execute(context.Background(), map[string]interface{}{
+		"url":       "http://upstream.invalid/items",
+		"proxy_url": proxy.URL,
+	}, nil)
+	require.NoError(t, err)
+	assert.True(t, proxied)
+
+	err = node.ValidateConfig(map[string]interface{}{
+		"url":       "https://api.example.com",
+		"proxy_url": "ftp://proxy.example.com",
+	})
+	assert.Error(t, err)
+}
+
+func TestHTTPNode_RetriesRetryableStatus(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls < 3 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		fmt.Fprint(w, `{"ok":true}`)
+	}))
+	defer server.Close()
+
+	node := nodes.NewHTTPNode(nil)
+	result, err := node.Execute(context.Background(), map[string]interface{}{
+		"url":         server.URL,
+		"retry_count": 5,
+	}, nil)
+	require.NoError(t, err)
+
+	output := result.(map[string]interface{})
+	assert.Equal(t, 200, output["statusCode"])
+	assert.Equal(t, 3, calls)
+	assert.Len(t, output["attempts"], 3)
+}
+
+func TestHTTPNode_DoesNotRetryOtherStatus(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	node := nodes.NewHTTPNode(nil)
+	result, err := node.Execute(context.Background(), map[string]interface{}{
+		"url":             server.URL,
+		"retry_count":     3,
+		"retry_on_status": []interface{}{503},
+	}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, 404, result.(map[string]interface{})["statusCode"])
+	assert.Equal(t, 1, calls)
+}
+
+func TestHTTPNode_RefusesAddressesBlockedByNetworkPolicy(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("request reached a blocked address")
+	}))
+	defer server.Close()
+
+	policy, err := netpolicy.New(models.NetworkPolicy{Deny: []string{"loopback"}})
+	require.NoError(t, err)
+	ctx := netpolicy.WithPolicy(context.Background(), policy)
+
+	_, err = nodes.NewHTTPNode(nil).Execute(ctx, map[string]interface{}{"url": server.URL}, nil)
+	assert.ErrorIs(t, err, netpolicy.ErrBlocked)
+}
@@ -0,0 +1,97 @@
+package nodes_test
+
+import (
+	"testing"
+
+	"github.com/nuumz/f1ow/internal/nodes"
+
+	"github.com/linkedin/goavro/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testAvroSchema = `{
+	"type": "record",
+	"name": "Order",
+	"fields": [
+		{"name": "id", "type": "string"},
+		{"name": "amount", "type": "double"}
+	]
+}`
+
+func TestKafkaPayload_AvroRoundTrip(t *testing.T) {
+	codec, err := goavro.NewCodec(testAvroSchema)
+	require.NoError(t, err)
+
+	payload := nodes.KafkaPayload{Format: "avro", AvroSchema: testAvroSchema, SchemaID: 42}
+	value := map[string]interface{}{"id": "A-1", "amount": 12.5}
+
+	data, err := payload.Encode(codec, value)
+	require.NoError(t, err)
+
+	// Confluent wire format: magic byte followed by a big-endian schema ID
+	assert.Equal(t, []byte{0, 0, 0, 0, 42}, data[:5])
+
+	decoded, err := payload.Decode(codec, data)
+	require.NoError(t, err)
+	assert.Equal(t, value, decoded)
+}
+
+func TestKafkaPayload_AvroRejectsInvalidValue(t *testing.T) {
+	codec, err := goavro.NewCodec(testAvroSchema)
+	require.NoError(t, err)
+
+	payload := nodes.KafkaPayload{Format: "avro", AvroSchema: testAvroSchema}
+	_, err = payload.Encode(codec, map[string]interface{}{"id": 1})
+	assert.Error(t, err)
+}
+
+func TestKafkaNode_ValidateConfig(t *testing.T) {
+	node := nodes.NewKafkaNode()
+
+	tests := []struct {
+		name    string
+		config  map[string]interface{}
+		wantErr bool
+	}{
+		{
+			name:   "valid json producer",
+			config: map[string]interface{}{"brokers": []interface{}{"localhost:9092"}, "topic": "orders"},
+		},
+		{
+			name:    "missing brokers",
+			config:  map[string]interface{}{"topic": "orders"},
+			wantErr: true,
+		},
+		{
+			name: "avro without schema",
+			config: map[string]interface{}{
+				"brokers": []interface{}{"localhost:9092"},
+				"topic":   "orders",
+				"format":  "avro",
+			},
+			wantErr: true,
+		},
+		{
+			name: "unsupported sasl mechanism",
+			config: map[string]interface{}{
+				"brokers":        []interface{}{"localhost:9092"},
+				"topic":          "orders",
+				"sasl_mechanism": "gssapi",
+				"username":       "user",
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := node.ValidateConfig(tt.config)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,204 @@
+package nodes_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/nuumz/f1ow/internal/nodes"
+	"github.com/nuumz/f1ow/internal/usage"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// llmServer records the last request it received and replies with response
+type llmServer struct {
+	*httptest.Server
+	request  *http.Request
+	body     map[string]interface{}
+	response interface{}
+}
+
+func newLLMServer(t *testing.T, response interface{}) *llmServer {
+	s := &llmServer{response: response}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.request = r
+		s.body = nil
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&s.body))
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(s.response)
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func TestLLMNode_OpenAIChat(t *testing.T) {
+	server := newLLMServer(t, map[string]interface{}{
+		"model": "gpt-4o-mini-2024",
+		"choices": []interface{}{map[string]interface{}{
+			"message":       map[string]interface{}{"content": "```json\n{\"sentiment\": \"positive\"}\n```"},
+			"finish_reason": "stop",
+		}},
+		"usage": map[string]interface{}{"prompt_tokens": 12, "completion_tokens": 5},
+	})
+
+	meter := usage.NewMeter()
+	ctx := usage.WithMeter(context.Background(), meter)
+	result, err := nodes.NewLLMNode().Execute(ctx, map[string]interface{}{
+		"provider":      "openai",
+		"base_url":      server.URL,
+		"api_key":       "sk-test",
+		"model":         "gpt-4o-mini",
+		"system_prompt": "Classify sentiment.",
+		"messages":      []interface{}{map[string]interface{}{"role": "assistant", "content": "Send me a review."}},
+		"prompt":        "Review: {{review}}",
+		"temperature":   0.2,
+		"json_mode":     true,
+	}, map[string]interface{}{"review": "Great product"})
+	require.NoError(t, err)
+
+	assert.Equal(t, "/chat/completions", server.request.URL.Path)
+	assert.Equal(t, "Bearer sk-test", server.request.Header.Get("Authorization"))
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"role": "system", "content": "Classify sentiment."},
+		map[string]interface{}{"role": "assistant", "content": "Send me a review."},
+		map[string]interface{}{"role": "user", "content": "Review: Great product"},
+	}, server.body["messages"])
+	assert.Equal(t, 0.2, server.body["temperature"])
+	assert.Equal(t, map[string]interface{}{"type": "json_object"}, server.body["response_format"])
+
+	output := result.(map[string]interface{})
+	assert.Equal(t, map[string]interface{}{"sentiment": "positive"}, output["json"])
+	assert.Equal(t, "stop", output["finish_reason"])
+	assert.Equal(t, 17, output["usage"].(map[string]interface{})["total_tokens"])
+	assert.Equal(t, int64(12), meter.Usage().LLMPromptTokens)
+	assert.Equal(t, int64(5), meter.Usage().LLMCompletionTokens)
+}
+
+func TestLLMNode_AzureOpenAIEmbedding(t *testing.T) {
+	server := newLLMServer(t, map[string]interface{}{
+		"data": []interface{}{
+			map[string]interface{}{"index": 1, "embedding": []float64{0.3, 0.4}},
+			map[string]interface{}{"index": 0, "embedding": []float64{0.1, 0.2}},
+		},
+		"usage": map[string]interface{}{"prompt_tokens": 4, "total_tokens": 4},
+	})
+
+	result, err := nodes.NewLLMNode().Execute(context.Background(), map[string]interface{}{
+		"provider":   "azure_openai",
+		"base_url":   server.URL,
+		"deployment": "embedder",
+		"api_key":    "azure-key",
+		"operation":  "embedding",
+		"input":      "{{texts}}",
+	}, map[string]interface{}{"texts": []interface{}{"first", "second"}})
+	require.NoError(t, err)
+
+	assert.Equal(t, "/openai/deployments/embedder/embeddings", server.request.URL.Path)
+	assert.Equal(t, "2024-02-01", server.request.URL.Query().Get("api-version"))
+	assert.Equal(t, "azure-key", server.request.Header.Get("api-key"))
+	assert.Equal(t, []interface{}{"first", "second"}, server.body["input"])
+
+	output := result.(map[string]interface{})
+	assert.Equal(t, [][]float64{{0.1, 0.2}, {0.3, 0.4}}, output["embeddings"])
+	assert.Equal(t, []float64{0.1, 0.2}, output["embedding"])
+}
+
+func TestLLMNode_AnthropicChat(t *testing.T) {
+	server := newLLMServer(t, map[string]interface{}{
+		"model":       "claude-test",
+		"content":     []interface{}{map[string]interface{}{"type": "text", "text": "Hello"}},
+		"stop_reason": "end_turn",
+		"usage":       map[string]interface{}{"input_tokens": 8, "output_tokens": 2},
+	})
+
+	result, err := nodes.NewLLMNode().Execute(context.Background(), map[string]interface{}{
+		"provider":      "anthropic",
+		"base_url":      server.URL,
+		"api_key":       "anthropic-key",
+		"model":         "claude-test",
+		"system_prompt": "Be brief.",
+		"messages":      []interface{}{map[string]interface{}{"role": "system", "content": "Greet the user."}},
+		"prompt":        "Hi",
+	}, nil)
+	require.NoError(t, err)
+
+	assert.Equal(t, "/v1/messages", server.request.URL.Path)
+	assert.Equal(t, "anthropic-key", server.request.Header.Get("x-api-key"))
+	assert.NotEmpty(t, server.request.Header.Get("anthropic-version"))
+	// System messages are sent separately from the conversation
+	assert.Equal(t, "Be brief.\n\nGreet the user.", server.body["system"])
+	assert.Equal(t, []interface{}{map[string]interface{}{"role": "user", "content": "Hi"}}, server.body["messages"])
+	assert.Equal(t, float64(1024), server.body["max_tokens"])
+
+	output := result.(map[string]interface{})
+	assert.Equal(t, "Hello", output["content"])
+	assert.Equal(t, "end_turn", output["finish_reason"])
+	assert.Equal(t, 10, output["usage"].(map[string]interface{})["total_tokens"])
+}
+
+func TestLLMNode_OllamaCompletion(t *testing.T) {
+	server := newLLMServer(t, map[string]interface{}{
+		"model": "llama3", "response": "42", "done_reason": "stop", "prompt_eval_count": 6, "eval_count": 1,
+	})
+
+	result, err := nodes.NewLLMNode().Execute(context.Background(), map[string]interface{}{
+		"provider":   "ollama",
+		"base_url":   server.URL,
+		"model":      "llama3",
+		"operation":  "completion",
+		"prompt":     "What is 6 x 7?",
+		"max_tokens": 10,
+	}, nil)
+	require.NoError(t, err)
+
+	assert.Equal(t, "/api/generate", server.request.URL.Path)
+	assert.Equal(t, false, server.body["stream"])
+	assert.Equal(t, map[string]interface{}{"num_predict": float64(10)}, server.body["options"])
+	assert.Equal(t, "42", result.(map[string]interface{})["content"])
+}
+
+func TestLLMNode_ReportsAPIErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"error":"invalid api key"}`, http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	_, err := nodes.NewLLMNode().Execute(context.Background(), map[string]interface{}{
+		"provider": "openai", "base_url": server.URL, "model": "gpt-4o-mini", "prompt": "Hi",
+	}, nil)
+	assert.ErrorContains(t, err, "status 401")
+}
+
+func TestLLMNode_ValidateConfig(t *testing.T) {
+	node := nodes.NewLLMNode()
+
+	tests := []struct {
+		name   string
+		config map[string]interface{}
+		err    string
+	}{
+		{"chat", map[string]interface{}{"provider": "openai", "model": "gpt-4o-mini", "prompt": "Hi"}, ""},
+		{"azure without model", map[string]interface{}{"provider": "azure_openai", "base_url": "https://example.openai.azure.com", "deployment": "chat", "prompt": "Hi"}, ""},
+		{"invalid provider", map[string]interface{}{"provider": "other", "model": "m", "prompt": "Hi"}, "invalid provider"},
+		{"azure without deployment", map[string]interface{}{"provider": "azure_openai", "base_url": "https://example.openai.azure.com", "prompt": "Hi"}, "deployment is required"},
+		{"missing model", map[string]interface{}{"provider": "openai", "prompt": "Hi"}, "model is required"},
+		{"missing prompt", map[string]interface{}{"provider": "openai", "model": "m"}, "prompt or messages"},
+		{"invalid role", map[string]interface{}{"provider": "openai", "model": "m", "messages": []interface{}{map[string]interface{}{"role": "tool", "content": "x"}}}, "invalid message role"},
+		{"anthropic embedding", map[string]interface{}{"provider": "anthropic", "model": "m", "operation": "embedding", "input": "x"}, "does not support embeddings"},
+		{"invalid operation", map[string]interface{}{"provider": "openai", "model": "m", "operation": "image"}, "invalid operation"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := node.ValidateConfig(tt.config)
+			if tt.err == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorContains(t, err, tt.err)
+			}
+		})
+	}
+}
@@ -0,0 +1,60 @@
+package nodes_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/nuumz/f1ow/internal/nodes"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMQTTNode_ValidateConfig(t *testing.T) {
+	node := nodes.NewMQTTNode()
+
+	tests := []struct {
+		name   string
+		config map[string]interface{}
+		err    string
+	}{
+		{"valid", map[string]interface{}{"broker": "tcp://localhost:1883", "topic": "devices/1", "qos": 2}, ""},
+		{"missing broker", map[string]interface{}{"topic": "devices/1"}, "broker is required"},
+		{"missing topic", map[string]interface{}{"broker": "tcp://localhost:1883"}, "topic is required"},
+		{"invalid qos", map[string]interface{}{"broker": "tcp://localhost:1883", "topic": "devices/1", "qos": 3}, "qos must be"},
+		{"invalid format", map[string]interface{}{"broker": "tcp://localhost:1883", "topic": "devices/1", "format": "xml"}, "invalid format"},
+		{"invalid ca cert", map[string]interface{}{"broker": "ssl://localhost:8883", "topic": "devices/1", "ca_cert": "not a certificate"}, "invalid ca_cert"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := node.ValidateConfig(tt.config)
+			if tt.err == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorContains(t, err, tt.err)
+			}
+		})
+	}
+}
+
+func TestMQTTNode_ReportsUnreachableBroker(t *testing.T) {
+	_, err := nodes.NewMQTTNode().Execute(context.Background(), map[string]interface{}{
+		"broker": "tcp://127.0.0.1:1", "topic": "devices/1", "message": "hi", "timeout": 5,
+	}, nil)
+	assert.ErrorContains(t, err, "failed to connect to broker")
+}
+
+func TestMQTTTriggerConfig(t *testing.T) {
+	config, err := nodes.ParseMQTTTriggerConfig(map[string]interface{}{"broker": "tcp://localhost:1883", "topics": []interface{}{"devices/#"}})
+	require.NoError(t, err)
+	assert.Equal(t, 1, config.QoS)
+	assert.True(t, config.CleanSession)
+	assert.Equal(t, "json", config.Format)
+
+	node := nodes.NewMQTTTriggerNode()
+	assert.NoError(t, node.ValidateConfig(map[string]interface{}{"broker": "tcp://localhost:1883", "topics": []interface{}{"devices/+"}}))
+	assert.ErrorContains(t, node.ValidateConfig(map[string]interface{}{"broker": "tcp://localhost:1883"}), "at least one topic")
+
+	assert.Equal(t, map[string]interface{}{"on": true}, nodes.DecodeMQTTMessage("json", []byte(`{"on":true}`)))
+	assert.Equal(t, `{"on":true}`, nodes.DecodeMQTTMessage("string", []byte(`{"on":true}`)))
+}
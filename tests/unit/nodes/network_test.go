@@ -0,0 +1,113 @@
+package nodes_test
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/pem"
+	"net"
+	"testing"
+
+	"github.com/nuumz/f1ow/internal/engine"
+	"github.com/nuumz/f1ow/internal/models"
+	"github.com/nuumz/f1ow/internal/netpolicy"
+	"github.com/nuumz/f1ow/internal/nodes"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ssh"
+)
+
+// blockedListener returns the address of a loopback listener that fails the
+// test if anything connects to it
+func blockedListener(t *testing.T) string {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		if conn, err := listener.Accept(); err == nil {
+			conn.Close()
+			t.Error("connection reached a blocked address")
+		}
+	}()
+	return listener.Addr().String()
+}
+
+func loopbackDenied(t *testing.T) context.Context {
+	policy, err := netpolicy.New(models.NetworkPolicy{Deny: []string{"loopback"}})
+	require.NoError(t, err)
+	return netpolicy.WithPolicy(context.Background(), policy)
+}
+
+func TestNodes_NonHTTPConnectionsFollowNetworkPolicy(t *testing.T) {
+	addr := blockedListener(t)
+
+	tests := []struct {
+		name   string
+		node   engine.NodeType
+		config map[string]interface{}
+	}{
+		{"amqp", nodes.NewAMQPNode(), map[string]interface{}{
+			"url": "amqp://guest:guest@" + addr + "/", "routing_key": "orders", "message": "hi", "timeout": 5,
+		}},
+		{"mqtt", nodes.NewMQTTNode(), map[string]interface{}{
+			"broker": "tcp://" + addr, "topic": "orders", "message": "hi", "timeout": 5,
+		}},
+		{"kafka", nodes.NewKafkaNode(), map[string]interface{}{
+			"brokers": []interface{}{addr}, "topic": "orders", "value": "hi", "timeout": 5,
+		}},
+		{"redis", nodes.NewRedisNode(nil), map[string]interface{}{
+			"operation": "get", "key": "orders", "connection_url": "redis://" + addr,
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// The MQTT client flattens the dial error into its own
+			_, err := tt.node.Execute(loopbackDenied(t), tt.config, nil)
+			assert.ErrorContains(t, err, netpolicy.ErrBlocked.Error())
+		})
+	}
+}
+
+func TestOpenDatabase_FollowsNetworkPolicy(t *testing.T) {
+	host, port, err := net.SplitHostPort(blockedListener(t))
+	require.NoError(t, err)
+	ctx := loopbackDenied(t)
+
+	db, err := nodes.OpenDatabase(ctx, "postgres", "postgres://app@"+host+":"+port+"/app?sslmode=disable")
+	require.NoError(t, err)
+	defer db.Close()
+	assert.ErrorIs(t, db.PingContext(ctx), netpolicy.ErrBlocked)
+
+	db, err = nodes.OpenDatabase(ctx, "mysql", "app@tcp("+host+":"+port+")/app")
+	require.NoError(t, err)
+	defer db.Close()
+	assert.ErrorIs(t, db.PingContext(ctx), netpolicy.ErrBlocked)
+}
+
+func TestGitNode_RemotesFollowNetworkPolicy(t *testing.T) {
+	addr := blockedListener(t)
+	node := nodes.NewGitNode(t.TempDir())
+
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	block, err := ssh.MarshalPrivateKey(key, "")
+	require.NoError(t, err)
+
+	remotes := []map[string]interface{}{
+		{"operation": "clone", "path": "repo", "url": "http://" + addr + "/repo.git"},
+		{"operation": "clone", "path": "repo", "url": "ssh://git@" + addr + "/repo.git",
+			"auth_type": "ssh", "private_key": string(pem.EncodeToMemory(block)), "insecure_ignore_host_key": true},
+	}
+	for _, config := range remotes {
+		_, err := node.Execute(loopbackDenied(t), config, nil)
+		assert.ErrorContains(t, err, netpolicy.ErrBlocked.Error(), config["url"])
+	}
+
+	// go-git dials git:// remotes itself
+	_, err = node.Execute(loopbackDenied(t), map[string]interface{}{
+		"operation": "clone", "path": "repo", "url": "git://" + addr + "/repo.git",
+	}, nil)
+	assert.ErrorContains(t, err, "not supported under a network policy")
+}
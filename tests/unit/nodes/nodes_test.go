@@ -142,3 +142,34 @@ func TestTransformNode_Execute(t *testing.T) {
 	assert.Contains(t, resultMap, "result")
 	assert.Equal(t, int64(42), resultMap["result"])
 }
+
+func TestTransformNode_InterruptsAtTimeout(t *testing.T) {
+	node := nodes.NewTransformNode()
+	config := map[string]interface{}{"code": "while (true) {}", "timeout": 1}
+
+	// Validation compiles the code without running it
+	require.NoError(t, node.ValidateConfig(config))
+
+	_, err := node.Execute(context.Background(), config, nil)
+	require.Error(t, err)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestTransformNode_LimitsRecursion(t *testing.T) {
+	node := nodes.NewTransformNode()
+	_, err := node.Execute(context.Background(), map[string]interface{}{"code": "function f(n) { return f(n + 1) } f(0)"}, nil)
+	require.Error(t, err)
+
+	result, err := node.Execute(context.Background(), map[string]interface{}{"code": "({total: 1 + 2})"}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), result.(map[string]interface{})["total"])
+}
+
+func TestLoopNode_StopsWhenContextIsDone(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := nodes.NewLoopNode().Execute(ctx, map[string]interface{}{"array_path": "items"},
+		map[string]interface{}{"items": []interface{}{1, 2, 3}})
+	assert.ErrorIs(t, err, context.Canceled)
+}
@@ -0,0 +1,75 @@
+package nodes_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/nuumz/f1ow/internal/nodes"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNotifyNode_Telegram(t *testing.T) {
+	var received map[string]interface{}
+	var path string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		json.NewDecoder(r.Body).Decode(&received)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"ok": true}`))
+	}))
+	defer server.Close()
+
+	node := nodes.NewNotifyNode()
+
+	config := map[string]interface{}{
+		"provider":  "telegram",
+		"api_url":   server.URL,
+		"bot_token": "123:abc",
+		"chat_id":   "42",
+		"message":   "Order {{order.id}} failed",
+	}
+
+	input := map[string]interface{}{
+		"order": map[string]interface{}{"id": "A-1"},
+	}
+
+	result, err := node.Execute(context.Background(), config, input)
+	require.NoError(t, err)
+
+	assert.Equal(t, "/bot123:abc/sendMessage", path)
+	assert.Equal(t, "42", received["chat_id"])
+	assert.Equal(t, "Order A-1 failed", received["text"])
+	assert.Equal(t, 200, result.(map[string]interface{})["statusCode"])
+}
+
+func TestNotifyNode_DiscordError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	node := nodes.NewNotifyNode()
+
+	_, err := node.Execute(context.Background(), map[string]interface{}{
+		"provider":    "discord",
+		"webhook_url": server.URL,
+		"title":       "Alert",
+		"color":       "#ff0000",
+	}, nil)
+
+	assert.Error(t, err)
+}
+
+func TestNotifyNode_ValidateConfig(t *testing.T) {
+	node := nodes.NewNotifyNode()
+
+	assert.Error(t, node.ValidateConfig(map[string]interface{}{"provider": "slack", "message": "hi"}))
+	assert.Error(t, node.ValidateConfig(map[string]interface{}{"provider": "telegram", "bot_token": "t", "message": "hi"}))
+	assert.NoError(t, node.ValidateConfig(map[string]interface{}{"provider": "teams", "webhook_url": "https://x", "message": "hi"}))
+}
@@ -0,0 +1,156 @@
+package nodes_test
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/nuumz/f1ow/internal/nodes"
+	"github.com/nuumz/f1ow/internal/tenant"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryRedis implements the commands the redis node uses in memory;
+// other commands panic
+type memoryRedis struct {
+	redis.Cmdable
+	values map[string]string
+	lists  map[string][]interface{}
+	sets   map[string][]redis.Z
+	ttls   map[string]time.Duration
+}
+
+func newMemoryRedis() *memoryRedis {
+	return &memoryRedis{
+		values: make(map[string]string),
+		lists:  make(map[string][]interface{}),
+		sets:   make(map[string][]redis.Z),
+		ttls:   make(map[string]time.Duration),
+	}
+}
+
+func (r *memoryRedis) Get(ctx context.Context, key string) *redis.StringCmd {
+	value, ok := r.values[key]
+	if !ok {
+		return redis.NewStringResult("", redis.Nil)
+	}
+	return redis.NewStringResult(value, nil)
+}
+
+func (r *memoryRedis) Set(ctx context.Context, key string, value interface{}, expiration time.Duration) *redis.StatusCmd {
+	r.values[key] = fmt.Sprint(value)
+	if expiration > 0 {
+		r.ttls[key] = expiration
+	}
+	return redis.NewStatusResult("OK", nil)
+}
+
+func (r *memoryRedis) Del(ctx context.Context, keys ...string) *redis.IntCmd {
+	var deleted int64
+	for _, key := range keys {
+		if _, ok := r.values[key]; ok {
+			delete(r.values, key)
+			deleted++
+		}
+	}
+	return redis.NewIntResult(deleted, nil)
+}
+
+func (r *memoryRedis) IncrBy(ctx context.Context, key string, value int64) *redis.IntCmd {
+	count, _ := strconv.ParseInt(r.values[key], 10, 64)
+	count += value
+	r.values[key] = strconv.FormatInt(count, 10)
+	return redis.NewIntResult(count, nil)
+}
+
+func (r *memoryRedis) Expire(ctx context.Context, key string, expiration time.Duration) *redis.BoolCmd {
+	r.ttls[key] = expiration
+	return redis.NewBoolResult(true, nil)
+}
+
+func (r *memoryRedis) LPush(ctx context.Context, key string, values ...interface{}) *redis.IntCmd {
+	for _, value := range values {
+		r.lists[key] = append([]interface{}{value}, r.lists[key]...)
+	}
+	return redis.NewIntResult(int64(len(r.lists[key])), nil)
+}
+
+func (r *memoryRedis) ZAdd(ctx context.Context, key string, members ...redis.Z) *redis.IntCmd {
+	r.sets[key] = append(r.sets[key], members...)
+	return redis.NewIntResult(int64(len(members)), nil)
+}
+
+func TestRedisNode_EngineKeysAreScopedToTenant(t *testing.T) {
+	client := newMemoryRedis()
+	node := nodes.NewRedisNode(client)
+	first, second := uuid.New(), uuid.New()
+
+	_, err := node.Execute(tenant.WithID(context.Background(), first), map[string]interface{}{
+		"operation": "set", "key": "token", "value": "secret",
+	}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"wf:kv:" + first.String() + ":token": "secret"}, client.values)
+
+	result, err := node.Execute(tenant.WithID(context.Background(), second), map[string]interface{}{
+		"operation": "get", "key": "token",
+	}, nil)
+	require.NoError(t, err)
+	assert.Nil(t, result.(map[string]interface{})["result"])
+}
+
+func TestRedisNode_Operations(t *testing.T) {
+	client := newMemoryRedis()
+	node := nodes.NewRedisNode(client)
+	ctx := context.Background()
+	prefix := "wf:kv:" + tenant.DefaultID.String() + ":"
+	input := map[string]interface{}{"user": "ada", "profile": map[string]interface{}{"plan": "pro"}}
+
+	run := func(config map[string]interface{}) interface{} {
+		t.Helper()
+		require.NoError(t, node.ValidateConfig(config))
+		result, err := node.Execute(ctx, config, input)
+		require.NoError(t, err)
+		return result.(map[string]interface{})["result"]
+	}
+
+	// Objects are stored as JSON and parsed back on request
+	run(map[string]interface{}{"operation": "set", "key": "profile:{{user}}", "value": map[string]interface{}{"plan": "{{profile.plan}}"}, "ttl": 60})
+	assert.Equal(t, `{"plan":"pro"}`, client.values[prefix+"profile:ada"])
+	assert.Equal(t, time.Minute, client.ttls[prefix+"profile:ada"])
+	assert.Equal(t, map[string]interface{}{"plan": "pro"},
+		run(map[string]interface{}{"operation": "get", "key": "profile:ada", "parse_json": true}))
+	assert.Equal(t, `{"plan":"pro"}`, run(map[string]interface{}{"operation": "get", "key": "profile:ada"}))
+
+	// Counters default to an increment of one and may expire
+	assert.Equal(t, int64(1), run(map[string]interface{}{"operation": "incr", "key": "hits"}))
+	assert.Equal(t, int64(6), run(map[string]interface{}{"operation": "incr", "key": "hits", "increment": 5, "ttl": 30}))
+	assert.Equal(t, 30*time.Second, client.ttls[prefix+"hits"])
+
+	assert.Equal(t, int64(2), run(map[string]interface{}{"operation": "lpush", "key": "queue", "values": []interface{}{"a", "{{user}}"}}))
+	assert.Equal(t, []interface{}{"ada", "a"}, client.lists[prefix+"queue"])
+
+	assert.Equal(t, int64(1), run(map[string]interface{}{"operation": "zadd", "key": "scores", "value": "{{user}}", "score": 42}))
+	assert.Equal(t, []redis.Z{{Score: 42, Member: "ada"}}, client.sets[prefix+"scores"])
+
+	assert.Equal(t, int64(1), run(map[string]interface{}{"operation": "del", "key": "hits"}))
+	assert.Nil(t, run(map[string]interface{}{"operation": "get", "key": "hits"}))
+}
+
+func TestRedisNode_ValidateConfig(t *testing.T) {
+	node := nodes.NewRedisNode(nil)
+
+	assert.ErrorContains(t, node.ValidateConfig(map[string]interface{}{"operation": "flushall", "key": "k"}), "invalid operation")
+	assert.ErrorContains(t, node.ValidateConfig(map[string]interface{}{"operation": "get"}), "key is required")
+	assert.ErrorContains(t, node.ValidateConfig(map[string]interface{}{"operation": "expire", "key": "k"}), "ttl must be positive")
+	assert.ErrorContains(t, node.ValidateConfig(map[string]interface{}{"operation": "lpush", "key": "k"}), "value or values")
+
+	// Without the engine's Redis an external connection is required
+	_, err := node.Execute(context.Background(), map[string]interface{}{"operation": "get", "key": "k"}, nil)
+	assert.ErrorContains(t, err, "connection_url is required")
+}
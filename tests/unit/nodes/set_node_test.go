@@ -0,0 +1,126 @@
+package nodes_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/nuumz/f1ow/internal/nodes"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetNode_Execute(t *testing.T) {
+	node := nodes.NewSetNode()
+
+	config := map[string]interface{}{
+		"operations": []interface{}{
+			map[string]interface{}{"operation": "set", "path": "customer.name", "value": "{{user.first}} {{user.last}}"},
+			map[string]interface{}{"operation": "set", "path": "customer.age", "value": "{{user.age}}", "type": "integer"},
+			map[string]interface{}{"operation": "rename", "path": "user.email", "to": "customer.email"},
+			map[string]interface{}{"operation": "cast", "path": "total", "type": "number"},
+			map[string]interface{}{"operation": "remove", "path": "user"},
+		},
+	}
+
+	input := map[string]interface{}{
+		"user": map[string]interface{}{
+			"first": "Ada",
+			"last":  "Lovelace",
+			"age":   "36",
+			"email": "ada@example.com",
+		},
+		"total": "12.5",
+	}
+
+	result, err := node.Execute(context.Background(), config, input)
+	require.NoError(t, err)
+
+	resultMap, ok := result.(map[string]interface{})
+	require.True(t, ok)
+
+	customer, ok := resultMap["customer"].(map[string]interface{})
+	require.True(t, ok)
+	assert.Equal(t, "Ada Lovelace", customer["name"])
+	assert.Equal(t, int64(36), customer["age"])
+	assert.Equal(t, "ada@example.com", customer["email"])
+	assert.Equal(t, 12.5, resultMap["total"])
+	assert.NotContains(t, resultMap, "user")
+
+	// Input must not be mutated
+	assert.Contains(t, input["user"], "email")
+}
+
+func TestSetNode_KeepOnlySet(t *testing.T) {
+	node := nodes.NewSetNode()
+
+	config := map[string]interface{}{
+		"keep_only_set": true,
+		"operations": []interface{}{
+			map[string]interface{}{"path": "id", "value": "{{order.id}}"},
+		},
+	}
+
+	input := map[string]interface{}{
+		"order": map[string]interface{}{"id": float64(7)},
+		"noise": true,
+	}
+
+	result, err := node.Execute(context.Background(), config, input)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"id": float64(7)}, result)
+}
+
+func TestSetNode_ValidateConfig(t *testing.T) {
+	node := nodes.NewSetNode()
+
+	tests := []struct {
+		name    string
+		config  interface{}
+		wantErr bool
+	}{
+		{
+			name: "valid config",
+			config: map[string]interface{}{
+				"operations": []interface{}{
+					map[string]interface{}{"operation": "set", "path": "a", "value": 1},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name:    "no operations",
+			config:  map[string]interface{}{},
+			wantErr: true,
+		},
+		{
+			name: "rename without target",
+			config: map[string]interface{}{
+				"operations": []interface{}{
+					map[string]interface{}{"operation": "rename", "path": "a"},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid cast type",
+			config: map[string]interface{}{
+				"operations": []interface{}{
+					map[string]interface{}{"operation": "cast", "path": "a", "type": "date"},
+				},
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := node.ValidateConfig(tt.config)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,65 @@
+package nodes_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/nuumz/f1ow/internal/engine"
+	"github.com/nuumz/f1ow/internal/nodes"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetVariableNode_Run(t *testing.T) {
+	node := nodes.NewSetVariableNode().(engine.TypedNode)
+
+	config := engine.NodeConfig{
+		"variables": []interface{}{
+			map[string]interface{}{"name": "seen", "operation": "increment"},
+			map[string]interface{}{"name": "seen", "operation": "increment", "value": "{{batch}}"},
+			map[string]interface{}{"name": "ids", "operation": "append", "value": "{{order.id}}"},
+			map[string]interface{}{"name": "last_order", "value": "{{order.id}}"},
+		},
+	}
+	input := engine.NodeInput{
+		Data:      map[string]interface{}{"order": map[string]interface{}{"id": "o-3"}, "batch": "2"},
+		Variables: map[string]interface{}{"seen": float64(4), "ids": []interface{}{"o-1", "o-2"}, "kept": true},
+	}
+
+	output, err := node.Run(context.Background(), config, input)
+	require.NoError(t, err)
+
+	want := map[string]interface{}{
+		"seen":       float64(7),
+		"ids":        []interface{}{"o-1", "o-2", "o-3"},
+		"last_order": "o-3",
+	}
+	assert.Equal(t, want, output.Variables)
+	assert.Equal(t, want, output.Data)
+	assert.Equal(t, []interface{}{"o-1", "o-2"}, input.Variables["ids"], "current variables are not modified")
+}
+
+func TestSetVariableNode_IncrementRejectsNonNumbers(t *testing.T) {
+	node := nodes.NewSetVariableNode().(engine.TypedNode)
+
+	_, err := node.Run(context.Background(), engine.NodeConfig{
+		"variables": []interface{}{map[string]interface{}{"name": "status", "operation": "increment"}},
+	}, engine.NodeInput{Variables: map[string]interface{}{"status": "done"}})
+	assert.Error(t, err)
+}
+
+func TestSetVariableNode_ValidateConfig(t *testing.T) {
+	node := nodes.NewSetVariableNode()
+
+	assert.NoError(t, node.ValidateConfig(map[string]interface{}{
+		"variables": []interface{}{map[string]interface{}{"name": "count", "operation": "increment"}},
+	}))
+	assert.Error(t, node.ValidateConfig(map[string]interface{}{"variables": []interface{}{}}))
+	assert.Error(t, node.ValidateConfig(map[string]interface{}{
+		"variables": []interface{}{map[string]interface{}{"value": 1}},
+	}))
+	assert.Error(t, node.ValidateConfig(map[string]interface{}{
+		"variables": []interface{}{map[string]interface{}{"name": "count", "operation": "multiply"}},
+	}))
+}
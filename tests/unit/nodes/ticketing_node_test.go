@@ -0,0 +1,90 @@
+package nodes_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/nuumz/f1ow/internal/nodes"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJiraNode_TransitionByName(t *testing.T) {
+	var applied map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, token, ok := r.BasicAuth()
+		assert.True(t, ok)
+		assert.Equal(t, "ops@example.com", user)
+		assert.Equal(t, "tok", token)
+
+		switch r.Method + " " + r.URL.Path {
+		case "GET /rest/api/2/issue/OPS-7/transitions":
+			w.Write([]byte(`{"transitions":[{"id":"11","name":"In Progress","to":{"name":"In Progress"}},{"id":"31","name":"Done","to":{"name":"Done"}}]}`))
+		case "POST /rest/api/2/issue/OPS-7/transitions":
+			json.NewDecoder(r.Body).Decode(&applied)
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	node := nodes.NewJiraNode()
+	config := map[string]interface{}{
+		"operation":  "transition_issue",
+		"base_url":   server.URL,
+		"email":      "ops@example.com",
+		"api_token":  "tok",
+		"issue_key":  "{{ticket}}",
+		"transition": "done",
+		"comment":    "Resolved by {{runner}}",
+	}
+	require.NoError(t, node.ValidateConfig(config))
+
+	result, err := node.Execute(context.Background(), config, map[string]interface{}{"ticket": "OPS-7", "runner": "f1ow"})
+	require.NoError(t, err)
+
+	data := result.(map[string]interface{})["data"].(map[string]interface{})
+	assert.Equal(t, "Done", data["to_status"])
+	assert.Equal(t, map[string]interface{}{"id": "31"}, applied["transition"])
+	assert.Contains(t, applied["update"].(map[string]interface{})["comment"], map[string]interface{}{
+		"add": map[string]interface{}{"body": "Resolved by f1ow"},
+	})
+
+	config["transition"] = "Reopen"
+	_, err = node.Execute(context.Background(), config, map[string]interface{}{"ticket": "OPS-7"})
+	assert.ErrorContains(t, err, "In Progress, Done")
+}
+
+func TestServiceNowNode_SearchUnwrapsResult(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer tok", r.Header.Get("Authorization"))
+		assert.Equal(t, "/api/now/table/incident", r.URL.Path)
+		assert.Equal(t, "active=true^priority=1", r.URL.Query().Get("sysparm_query"))
+		assert.Equal(t, "10", r.URL.Query().Get("sysparm_limit"))
+		w.Write([]byte(`{"result":[{"sys_id":"abc","number":"INC0010001"}]}`))
+	}))
+	defer server.Close()
+
+	node := nodes.NewServiceNowNode()
+	config := map[string]interface{}{
+		"operation":    "search",
+		"instance_url": server.URL,
+		"auth_type":    "bearer",
+		"token":        "tok",
+		"query":        "active=true^priority={{priority}}",
+		"limit":        10,
+	}
+	require.NoError(t, node.ValidateConfig(config))
+
+	result, err := node.Execute(context.Background(), config, map[string]interface{}{"priority": 1})
+	require.NoError(t, err)
+
+	records := result.(map[string]interface{})["data"].([]interface{})
+	require.Len(t, records, 1)
+	assert.Equal(t, "INC0010001", records[0].(map[string]interface{})["number"])
+}
@@ -0,0 +1,113 @@
+package nodes_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/nuumz/f1ow/internal/nodes"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVectorStoreNode_Qdrant(t *testing.T) {
+	var created, upserted, searched map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "secret", r.Header.Get("api-key"))
+
+		var body map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&body)
+
+		switch r.Method + " " + r.URL.Path {
+		case "GET /collections/docs":
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"status":{"error":"Not found"}}`))
+		case "PUT /collections/docs":
+			created = body
+			w.Write([]byte(`{"result":true}`))
+		case "PUT /collections/docs/points":
+			upserted = body
+			w.Write([]byte(`{"result":{"status":"completed"}}`))
+		case "POST /collections/docs/points/search":
+			searched = body
+			points := upserted["points"].([]interface{})
+			w.Write([]byte(`{"result":[{"id":"` + points[0].(map[string]interface{})["id"].(string) + `","score":0.92,"payload":{"_id":"doc-1","title":"Intro"}},{"id":7,"score":0.4,"payload":{}}]}`))
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	node := nodes.NewVectorStoreNode()
+	base := map[string]interface{}{
+		"backend":    "qdrant",
+		"url":        server.URL,
+		"api_key":    "secret",
+		"collection": "docs",
+	}
+
+	upsertConfig := mergeInput(base, "operation", "upsert")
+	upsertConfig["create_if_missing"] = true
+	require.NoError(t, node.ValidateConfig(upsertConfig))
+
+	result, err := node.Execute(context.Background(), upsertConfig, map[string]interface{}{
+		"items": []interface{}{
+			map[string]interface{}{"id": "doc-1", "embedding": []interface{}{0.1, 0.2}, "title": "Intro"},
+			map[string]interface{}{"id": 7, "embedding": []interface{}{0.3, 0.4}, "title": "Other"},
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 2, result.(map[string]interface{})["count"])
+	assert.Equal(t, map[string]interface{}{"size": float64(2), "distance": "Cosine"}, created["vectors"])
+
+	points := upserted["points"].([]interface{})
+	first := points[0].(map[string]interface{})
+	assert.NotEqual(t, "doc-1", first["id"])
+	assert.Equal(t, map[string]interface{}{"_id": "doc-1", "title": "Intro"}, first["payload"])
+	assert.Equal(t, float64(7), points[1].(map[string]interface{})["id"])
+
+	queryConfig := mergeInput(base, "operation", "query")
+	queryConfig["vector"] = "{{embedding}}"
+	queryConfig["top_k"] = 3
+	queryConfig["min_score"] = 0.5
+	queryConfig["filter"] = map[string]interface{}{"lang": "{{lang}}"}
+
+	result, err = node.Execute(context.Background(), queryConfig, map[string]interface{}{
+		"embedding": []interface{}{0.1, 0.2},
+		"lang":      "en",
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, float64(3), searched["limit"])
+	assert.Equal(t, map[string]interface{}{
+		"must": []interface{}{map[string]interface{}{"key": "lang", "match": map[string]interface{}{"value": "en"}}},
+	}, searched["filter"])
+	assert.Equal(t, map[string]interface{}{
+		"count": 1,
+		"matches": []interface{}{
+			map[string]interface{}{"id": "doc-1", "score": 0.92, "metadata": map[string]interface{}{"title": "Intro"}},
+		},
+	}, result)
+}
+
+func TestVectorStoreNode_ValidateConfig(t *testing.T) {
+	node := nodes.NewVectorStoreNode()
+
+	err := node.ValidateConfig(map[string]interface{}{
+		"backend":           "pgvector",
+		"operation":         "upsert",
+		"connection_string": "postgres://localhost/db",
+		"table":             "docs; DROP TABLE users",
+	})
+	assert.ErrorContains(t, err, "invalid table name")
+
+	err = node.ValidateConfig(map[string]interface{}{
+		"backend":           "pgvector",
+		"operation":         "delete",
+		"connection_string": "postgres://localhost/db",
+	})
+	assert.ErrorContains(t, err, "ids or filter is required")
+}
@@ -0,0 +1,119 @@
+package quota_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/nuumz/f1ow/internal/models"
+	"github.com/nuumz/f1ow/internal/quota"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// store is a quota.Store with fixed quotas and counts
+type store struct {
+	plan       string
+	quotas     models.Quotas
+	active     int
+	executions int
+	reads      int
+}
+
+func (s *store) TenantQuotas(context.Context, uuid.UUID) (string, models.Quotas, error) {
+	s.reads++
+	return s.plan, s.quotas, nil
+}
+
+func (s *store) CountActiveWorkflows(context.Context, uuid.UUID) (int, error) {
+	return s.active, nil
+}
+
+func (s *store) CountExecutionsSince(context.Context, uuid.UUID, time.Time) (int, error) {
+	return s.executions, nil
+}
+
+func intp(n int) *int { return &n }
+
+func TestEnforcer_TenantQuotasOverrideDefaults(t *testing.T) {
+	s := &store{plan: "pro", quotas: models.Quotas{MaxExecutionsPerDay: intp(1000)}}
+	enforcer := quota.New(models.Quotas{MaxExecutionsPerDay: intp(10), MaxActiveWorkflows: intp(5)}, s)
+
+	plan, quotas, err := enforcer.Quotas(context.Background(), uuid.New())
+	require.NoError(t, err)
+	assert.Equal(t, "pro", plan)
+	assert.Equal(t, 1000, *quotas.MaxExecutionsPerDay)
+	assert.Equal(t, 5, *quotas.MaxActiveWorkflows)
+	assert.Equal(t, 0, *quotas.MaxPayloadSize, "unset everywhere is unlimited")
+}
+
+func TestEnforcer_CachesQuotasUntilForgotten(t *testing.T) {
+	s := &store{}
+	enforcer := quota.New(models.Quotas{}, s)
+	tenantID := uuid.New()
+
+	for i := 0; i < 3; i++ {
+		_, _, err := enforcer.Quotas(context.Background(), tenantID)
+		require.NoError(t, err)
+	}
+	assert.Equal(t, 1, s.reads)
+
+	enforcer.Forget(tenantID)
+	_, _, err := enforcer.Quotas(context.Background(), tenantID)
+	require.NoError(t, err)
+	assert.Equal(t, 2, s.reads)
+}
+
+func TestEnforcer_CheckExecutions(t *testing.T) {
+	ctx := context.Background()
+	s := &store{quotas: models.Quotas{MaxExecutionsPerDay: intp(10), MaxPayloadSize: intp(20)}, executions: 9}
+	enforcer := quota.New(models.Quotas{}, s)
+	tenantID := uuid.New()
+
+	assert.NoError(t, enforcer.CheckExecutions(ctx, tenantID, map[string]interface{}{"a": 1}))
+
+	var exceeded *quota.ExceededError
+	err := enforcer.CheckExecutions(ctx, tenantID, map[string]interface{}{"a": 1}, map[string]interface{}{"b": 2})
+	require.True(t, errors.As(err, &exceeded))
+	assert.Equal(t, quota.ExecutionsPerDay, exceeded.Quota)
+	assert.Equal(t, 11, exceeded.Used)
+	assert.Greater(t, exceeded.RetryAfter, time.Duration(0))
+	assert.LessOrEqual(t, exceeded.RetryAfter, 24*time.Hour)
+
+	err = enforcer.CheckExecutions(ctx, tenantID, map[string]interface{}{"text": "more than twenty bytes"})
+	require.True(t, errors.As(err, &exceeded))
+	assert.Equal(t, quota.PayloadSize, exceeded.Quota)
+	assert.Zero(t, exceeded.RetryAfter, "a smaller input is needed, not a wait")
+}
+
+func TestEnforcer_CheckActivation(t *testing.T) {
+	ctx := context.Background()
+	s := &store{quotas: models.Quotas{MaxActiveWorkflows: intp(3)}, active: 2}
+	enforcer := quota.New(models.Quotas{}, s)
+
+	assert.NoError(t, enforcer.CheckActivation(ctx, uuid.New(), 1))
+	err := enforcer.CheckActivation(ctx, uuid.New(), 2)
+	var exceeded *quota.ExceededError
+	require.True(t, errors.As(err, &exceeded))
+	assert.Equal(t, quota.ActiveWorkflows, exceeded.Quota)
+	assert.Equal(t, "quota exceeded: at most 3 workflows may be active", err.Error())
+}
+
+func TestEnforcer_NilAllowsEverything(t *testing.T) {
+	var enforcer *quota.Enforcer
+	ctx := context.Background()
+
+	assert.NoError(t, enforcer.CheckActivation(ctx, uuid.New(), 100))
+	assert.NoError(t, enforcer.CheckExecutions(ctx, uuid.New(), map[string]interface{}{}))
+	limits, err := enforcer.Limits(ctx, uuid.New())
+	require.NoError(t, err)
+	assert.Equal(t, 0, *limits.Quotas.MaxExecutionsPerDay)
+}
+
+func TestValidate(t *testing.T) {
+	assert.NoError(t, quota.Validate(models.Quotas{MaxActiveWorkflows: intp(0)}))
+	assert.EqualError(t, quota.Validate(models.Quotas{MaxPayloadSize: intp(-1)}), "max_payload_size must not be negative")
+}
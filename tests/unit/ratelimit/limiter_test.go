@@ -0,0 +1,65 @@
+package ratelimit_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/nuumz/f1ow/internal/ratelimit"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRule(t *testing.T) {
+	rule, err := ratelimit.ParseRule("5000/h")
+	require.NoError(t, err)
+	assert.Equal(t, 5000.0, rule.Limit)
+	assert.Equal(t, time.Hour, rule.Period)
+
+	rule, err = ratelimit.ParseRule("10/30s")
+	require.NoError(t, err)
+	assert.Equal(t, 30*time.Second, rule.Period)
+
+	for _, spec := range []string{"10", "0/s", "x/s", "10/fortnight"} {
+		_, err := ratelimit.ParseRule(spec)
+		assert.Error(t, err, spec)
+	}
+}
+
+func TestMemoryBackend_TakesUntilBurstExhausted(t *testing.T) {
+	backend := ratelimit.NewMemoryBackend()
+	rule := ratelimit.Rule{Limit: 2, Period: time.Minute}
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		wait, err := backend.Take(ctx, "k", rule)
+		require.NoError(t, err)
+		assert.Zero(t, wait)
+	}
+
+	wait, err := backend.Take(ctx, "k", rule)
+	require.NoError(t, err)
+	assert.Greater(t, wait, 25*time.Second)
+}
+
+func TestLimiter_WaitURLAppliesHostRules(t *testing.T) {
+	limiter := ratelimit.NewLimiter(ratelimit.NewMemoryBackend(), nil)
+	require.NoError(t, limiter.ConfigureHosts("*.example.com=1/h"))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	require.NoError(t, limiter.WaitURL(ctx, "https://api.example.com/a"))
+	assert.ErrorIs(t, limiter.WaitURL(ctx, "https://api.example.com/b"), context.DeadlineExceeded)
+
+	// Hosts without a rule are not limited
+	assert.NoError(t, limiter.WaitURL(ctx, "https://other.test/"))
+	assert.NoError(t, limiter.WaitURL(ctx, "https://other.test/"))
+}
+
+func TestLimiter_ConfigureHostsRejectsInvalidEntries(t *testing.T) {
+	limiter := ratelimit.NewLimiter(ratelimit.NewMemoryBackend(), nil)
+	assert.Error(t, limiter.ConfigureHosts("api.example.com"))
+	assert.Error(t, limiter.ConfigureHosts("api.example.com=fast"))
+}
@@ -0,0 +1,99 @@
+package schedules_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/nuumz/f1ow/internal/schedules"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCron_Next(t *testing.T) {
+	// A Wednesday
+	from := time.Date(2026, 3, 4, 10, 7, 30, 0, time.UTC)
+
+	cases := []struct {
+		expr string
+		want time.Time
+	}{
+		{"* * * * *", time.Date(2026, 3, 4, 10, 8, 0, 0, time.UTC)},
+		{"*/15 * * * *", time.Date(2026, 3, 4, 10, 15, 0, 0, time.UTC)},
+		{"5/20 * * * *", time.Date(2026, 3, 4, 10, 25, 0, 0, time.UTC)},
+		{"0 9-17 * * MON-FRI", time.Date(2026, 3, 4, 11, 0, 0, 0, time.UTC)},
+		{"30 8 * * sat,sun", time.Date(2026, 3, 7, 8, 30, 0, 0, time.UTC)},
+		{"0 0 * * 7", time.Date(2026, 3, 8, 0, 0, 0, 0, time.UTC)},
+		{"0 0 1 * *", time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC)},
+		{"@yearly", time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{"@hourly", time.Date(2026, 3, 4, 11, 0, 0, 0, time.UTC)},
+		// With both days restricted, either matches: the 15th or a Friday
+		{"0 12 15 * FRI", time.Date(2026, 3, 6, 12, 0, 0, 0, time.UTC)},
+		{"0 0 29 2 *", time.Date(2028, 2, 29, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, tc := range cases {
+		cron, err := schedules.ParseCron(tc.expr)
+		require.NoError(t, err, tc.expr)
+		assert.Equal(t, tc.want, cron.Next(from), tc.expr)
+	}
+
+	never, err := schedules.ParseCron("0 0 30 2 *")
+	require.NoError(t, err)
+	assert.True(t, never.Next(from).IsZero())
+}
+
+func TestCron_NextAcrossDaylightSaving(t *testing.T) {
+	loc, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skip("timezone database unavailable")
+	}
+	cron, err := schedules.ParseCron("30 2 * * *")
+	require.NoError(t, err)
+
+	// 02:30 does not exist on March 8th 2026, so that day has no run
+	next := cron.Next(time.Date(2026, 3, 7, 12, 0, 0, 0, loc))
+	assert.Equal(t, time.Date(2026, 3, 9, 2, 30, 0, 0, loc), next)
+
+	// Through the hour repeated when clocks go back, runs keep moving forward
+	hourly, err := schedules.ParseCron("0 * * * *")
+	require.NoError(t, err)
+	from := time.Date(2026, 11, 1, 0, 30, 0, 0, loc)
+	var runs []time.Time
+	for next := hourly.Next(from); len(runs) < 4; next = hourly.Next(next) {
+		runs = append(runs, next)
+	}
+	for i := 1; i < len(runs); i++ {
+		assert.True(t, runs[i].After(runs[i-1]), "run %d moves forward", i)
+	}
+}
+
+func TestParseCron_Errors(t *testing.T) {
+	for _, expr := range []string{
+		"", "* * * *", "* * * * * *", "60 * * * *", "* 24 * * *", "* * 0 * *",
+		"* * * 13 *", "* * * * 8", "*/0 * * * *", "5-1 * * * *", "* * * FOO *", "@fortnightly",
+	} {
+		_, err := schedules.ParseCron(expr)
+		assert.Error(t, err, expr)
+	}
+}
+
+func TestPreview(t *testing.T) {
+	from := time.Date(2026, 3, 4, 10, 7, 0, 0, time.UTC)
+	times, err := schedules.Preview("0 9 * * MON-FRI", "Asia/Bangkok", from, 5)
+	if err != nil {
+		t.Skip("timezone database unavailable")
+	}
+	require.Len(t, times, 5)
+	assert.Equal(t, "2026-03-05T09:00:00+07:00", times[0].Format(time.RFC3339))
+	assert.Equal(t, "2026-03-06T09:00:00+07:00", times[1].Format(time.RFC3339))
+	assert.Equal(t, "2026-03-09T09:00:00+07:00", times[2].Format(time.RFC3339), "the weekend is skipped")
+
+	_, err = schedules.Preview("0 9 * * *", "Mars/Olympus", from, 5)
+	assert.ErrorIs(t, err, schedules.ErrInvalid)
+	_, err = schedules.Preview("every day", "", from, 5)
+	assert.ErrorIs(t, err, schedules.ErrInvalid)
+
+	times, err = schedules.Preview("0 0 30 2 *", "", from, 5)
+	require.NoError(t, err)
+	assert.Empty(t, times)
+}
@@ -0,0 +1,137 @@
+package schedules_test
+
+import (
+	"context"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/nuumz/f1ow/internal/models"
+	"github.com/nuumz/f1ow/internal/schedules"
+	"github.com/nuumz/f1ow/internal/storage"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recorder records the executions a manager starts
+type recorder struct {
+	mu   sync.Mutex
+	runs []string
+}
+
+func (r *recorder) run(ctx context.Context, workflowID string, input map[string]interface{}) (*models.Execution, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.runs = append(r.runs, workflowID)
+	return &models.Execution{ID: uuid.New(), Status: models.ExecutionStatusPending, Input: input}, nil
+}
+
+// newManager returns a manager storing schedules in SQLite, with a workflow
+// to schedule
+func newManager(t *testing.T) (*schedules.Manager, *storage.DB, *recorder, uuid.UUID) {
+	t.Helper()
+	db, err := storage.NewDB("sqlite://" + filepath.Join(t.TempDir(), "f1ow.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	_, err = db.Migrate(context.Background())
+	require.NoError(t, err)
+
+	userID := uuid.New()
+	_, err = db.Exec(`INSERT INTO users (id, email) VALUES ($1, $2)`, userID, "schedules@example.com")
+	require.NoError(t, err)
+	workflow := &models.Workflow{Name: "nightly", UserID: userID, Status: models.WorkflowStatusActive}
+	require.NoError(t, db.CreateWorkflow(context.Background(), workflow))
+
+	runs := &recorder{}
+	return schedules.NewManager(db, runs.run, logrus.New()), db, runs, workflow.ID
+}
+
+func TestManager_CreatePauseResume(t *testing.T) {
+	manager, _, _, workflowID := newManager(t)
+	ctx := context.Background()
+
+	_, err := manager.Create(ctx, &models.Schedule{WorkflowID: workflowID, CronExpr: "every night", IsActive: true})
+	assert.ErrorIs(t, err, schedules.ErrInvalid)
+	_, err = manager.Create(ctx, &models.Schedule{WorkflowID: workflowID, CronExpr: "@daily", Timezone: "Nowhere/Special", IsActive: true})
+	assert.ErrorIs(t, err, schedules.ErrInvalid)
+
+	created, err := manager.Create(ctx, &models.Schedule{
+		WorkflowID: workflowID, Name: "Nightly", CronExpr: "0 2 * * *", IsActive: true,
+		Input: map[string]interface{}{"full": true},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "UTC", created.Timezone)
+	require.NotNil(t, created.NextRunAt)
+	assert.Equal(t, 2, created.NextRunAt.Hour())
+	assert.True(t, created.NextRunAt.After(time.Now()))
+
+	paused, err := manager.SetActive(ctx, created.ID, false)
+	require.NoError(t, err)
+	assert.False(t, paused.IsActive)
+	assert.Nil(t, paused.NextRunAt, "paused schedules have no next run")
+
+	resumed, err := manager.SetActive(ctx, created.ID, true)
+	require.NoError(t, err)
+	require.NotNil(t, resumed.NextRunAt)
+	assert.Equal(t, map[string]interface{}{"full": true}, resumed.Input)
+
+	list, err := manager.List(ctx, workflowID)
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	assert.Equal(t, "Nightly", list[0].Name)
+	list, err = manager.List(ctx, uuid.New())
+	require.NoError(t, err)
+	assert.Empty(t, list)
+
+	require.NoError(t, manager.Delete(ctx, created.ID))
+	_, err = manager.Get(ctx, created.ID)
+	assert.ErrorIs(t, err, schedules.ErrNotFound)
+}
+
+func TestManager_RunDue(t *testing.T) {
+	manager, db, runs, workflowID := newManager(t)
+	ctx := context.Background()
+
+	due, err := manager.Create(ctx, &models.Schedule{WorkflowID: workflowID, CronExpr: "*/5 * * * *", IsActive: true})
+	require.NoError(t, err)
+	_, err = manager.Create(ctx, &models.Schedule{WorkflowID: workflowID, CronExpr: "*/5 * * * *", IsActive: false})
+	require.NoError(t, err)
+
+	// Fall an hour behind
+	past := time.Now().Add(-time.Hour).Truncate(time.Minute).UTC()
+	due.NextRunAt = &past
+	require.NoError(t, db.UpdateSchedule(ctx, due))
+
+	started, err := manager.RunDue(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, started, "missed runs are not caught up, and paused schedules do not run")
+	assert.Equal(t, []string{workflowID.String()}, runs.runs)
+
+	advanced, err := manager.Get(ctx, due.ID)
+	require.NoError(t, err)
+	require.NotNil(t, advanced.LastRunAt)
+	require.NotNil(t, advanced.NextRunAt)
+	assert.True(t, advanced.NextRunAt.After(time.Now()))
+
+	started, err = manager.RunDue(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, started)
+
+	// Another instance that listed the schedule before it was advanced
+	// cannot run it again
+	ok, err := db.AdvanceSchedule(ctx, advanced, past)
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	execution, err := manager.RunNow(ctx, due.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.ExecutionStatusPending, execution.Status)
+	assert.Len(t, runs.runs, 2)
+	unchanged, err := manager.Get(ctx, due.ID)
+	require.NoError(t, err)
+	assert.Equal(t, advanced.NextRunAt.Unix(), unchanged.NextRunAt.Unix(), "running now leaves the next run")
+}
@@ -0,0 +1,73 @@
+package scheduling_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/nuumz/f1ow/internal/binarydata"
+	"github.com/nuumz/f1ow/internal/config"
+	"github.com/nuumz/f1ow/internal/engine"
+	"github.com/nuumz/f1ow/internal/models"
+	"github.com/nuumz/f1ow/internal/scheduling"
+	"github.com/nuumz/f1ow/internal/storage"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunInProcess_StartsScheduling(t *testing.T) {
+	cfg, err := config.Load("")
+	require.NoError(t, err)
+	cfg.Nodes.FileStoragePath = t.TempDir()
+
+	db, err := storage.NewDB("sqlite://" + filepath.Join(t.TempDir(), "f1ow.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	_, err = db.Migrate(context.Background())
+	require.NoError(t, err)
+
+	userID := uuid.New()
+	_, err = db.Exec(`INSERT INTO users (id, email) VALUES ($1, $2)`, userID, "serve@example.com")
+	require.NoError(t, err)
+	workflow := &models.Workflow{Name: "inbox", UserID: userID, Status: models.WorkflowStatusActive,
+		Definition: models.WorkflowDefinition{Nodes: []models.Node{
+			{ID: "mail", Type: "email_trigger", Config: map[string]interface{}{
+				"host": "127.0.0.1", "port": 1, "security": "none", "username": "bot", "poll_interval": 3600,
+			}},
+			{ID: "poll", Type: "poll_trigger", Config: map[string]interface{}{
+				"request": map[string]interface{}{"url": "http://127.0.0.1:1", "method": "GET"},
+			}},
+		}}}
+	require.NoError(t, db.CreateWorkflow(context.Background(), workflow))
+
+	store, err := binarydata.NewStore(binarydata.Config{Storage: "filesystem", Path: filepath.Join(t.TempDir(), "binary")}, nil)
+	require.NoError(t, err)
+	binaryData := binarydata.NewManager(store, db, logrus.New())
+
+	// As serve builds it without Redis
+	eng := engine.NewEngine(db, nil,
+		engine.WithBinaryData(binaryData),
+		engine.WithQueue(engine.NewMemoryQueue(db)),
+		engine.WithWorker(engine.WorkerOptions{ID: "serve", Concurrency: 1}))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stopped := scheduling.RunInProcess(ctx, eng, db, binaryData, cfg)
+
+	// The email trigger runs with the database seen store; the poll
+	// trigger, which needs Redis for its cursor, does not
+	assert.Eventually(t, func() bool {
+		return len(eng.Triggers().Running()) > 0
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, []string{workflow.ID.String() + "/mail"}, eng.Triggers().Running())
+
+	cancel()
+	select {
+	case <-stopped:
+	case <-time.After(10 * time.Second):
+		t.Fatal("the in-process worker did not stop")
+	}
+}
@@ -0,0 +1,42 @@
+package storage_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/nuumz/f1ow/internal/binarydata"
+	"github.com/nuumz/f1ow/internal/models"
+	"github.com/nuumz/f1ow/internal/tenant"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBinaryData_ScopedToExecutionTenant(t *testing.T) {
+	db := newSQLiteDB(t)
+	ctx := tenant.WithID(context.Background(), tenant.DefaultID)
+	workflow := &models.Workflow{Name: "export", UserID: createSQLiteUser(t, db), Status: models.WorkflowStatusActive}
+	require.NoError(t, db.CreateWorkflow(ctx, workflow))
+	execution := &models.Execution{WorkflowID: workflow.ID, Status: models.ExecutionStatusCompleted}
+	require.NoError(t, db.CreateExecution(ctx, execution))
+
+	data := &models.BinaryData{
+		ID: uuid.New(), ExecutionID: execution.ID, Storage: "filesystem", StorageKey: "export/report.csv",
+		FileName: "report.csv", MimeType: "text/csv", Size: 42, CreatedAt: time.Now(),
+	}
+	require.NoError(t, db.SaveBinaryData(ctx, data))
+
+	stored, err := db.GetBinaryData(ctx, data.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "report.csv", stored.FileName)
+	assert.Equal(t, execution.ID, stored.ExecutionID)
+
+	_, err = db.GetBinaryData(tenant.WithID(context.Background(), uuid.New()), data.ID)
+	assert.ErrorIs(t, err, binarydata.ErrNotFound, "another tenant cannot read the execution's files")
+
+	// Unscoped callers, such as cleanup, still see it
+	_, err = db.GetBinaryData(context.Background(), data.ID)
+	assert.NoError(t, err)
+}
@@ -0,0 +1,51 @@
+package storage_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/nuumz/f1ow/internal/models"
+	"github.com/nuumz/f1ow/internal/storage"
+	"github.com/nuumz/f1ow/internal/tenant"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExecutionBatch_CountsExecutionsByStatus(t *testing.T) {
+	db := newSQLiteDB(t)
+	ctx := context.Background()
+	workflow := &models.Workflow{Name: "import", UserID: createSQLiteUser(t, db), Status: models.WorkflowStatusActive}
+	require.NoError(t, db.CreateWorkflow(ctx, workflow))
+
+	batch := &models.ExecutionBatch{WorkflowID: workflow.ID, Concurrency: 2}
+	require.NoError(t, db.CreateExecutionBatch(ctx, batch))
+	assert.NotEqual(t, uuid.Nil, batch.ID)
+
+	var ids []uuid.UUID
+	for _, status := range []models.ExecutionStatus{models.ExecutionStatusPending, models.ExecutionStatusPending, models.ExecutionStatusCompleted} {
+		execution := &models.Execution{WorkflowID: workflow.ID, Status: status}
+		require.NoError(t, db.CreateExecution(ctx, execution))
+		ids = append(ids, execution.ID)
+	}
+	require.NoError(t, db.AddBatchExecutions(ctx, batch.ID, ids))
+
+	stored, err := db.GetExecutionBatch(ctx, batch.ID)
+	require.NoError(t, err)
+	assert.Equal(t, workflow.ID, stored.WorkflowID)
+	assert.Equal(t, 2, stored.Concurrency)
+	assert.Equal(t, 3, stored.Total)
+	assert.Equal(t, map[models.ExecutionStatus]int{
+		models.ExecutionStatusPending:   2,
+		models.ExecutionStatusCompleted: 1,
+	}, stored.Counts)
+	assert.False(t, stored.Done)
+
+	_, err = db.GetExecutionBatch(ctx, uuid.New())
+	assert.ErrorIs(t, err, storage.ErrBatchNotFound)
+
+	// Batches are scoped by their workflow's tenant
+	_, err = db.GetExecutionBatch(tenant.WithID(ctx, uuid.New()), batch.ID)
+	assert.ErrorIs(t, err, storage.ErrBatchNotFound)
+}
@@ -0,0 +1,30 @@
+package storage_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nuumz/f1ow/internal/storage"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExecutionCursorRoundTrip(t *testing.T) {
+	startedAt := time.Date(2024, 5, 1, 12, 30, 0, 123456789, time.UTC)
+	id := uuid.New()
+
+	cursor := storage.EncodeExecutionCursor(startedAt, id)
+	decodedAt, decodedID, err := storage.DecodeExecutionCursor(cursor)
+	require.NoError(t, err)
+	assert.True(t, startedAt.Equal(decodedAt))
+	assert.Equal(t, id, decodedID)
+}
+
+func TestDecodeExecutionCursorRejectsGarbage(t *testing.T) {
+	for _, cursor := range []string{"not base64!", "bm9waXBl", storage.EncodeExecutionCursor(time.Now(), uuid.Nil)[:10]} {
+		_, _, err := storage.DecodeExecutionCursor(cursor)
+		assert.Error(t, err, cursor)
+	}
+}
@@ -0,0 +1,109 @@
+package storage_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/nuumz/f1ow/internal/models"
+	"github.com/nuumz/f1ow/internal/storage"
+	"github.com/nuumz/f1ow/internal/tenant"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newExternalTask(t *testing.T, db *storage.DB, topic string) *models.ExternalTask {
+	t.Helper()
+	ctx := context.Background()
+	workflow := &models.Workflow{Name: "billing", UserID: createSQLiteUser(t, db), Status: models.WorkflowStatusActive}
+	require.NoError(t, db.CreateWorkflow(ctx, workflow))
+
+	task := &models.ExternalTask{
+		Topic:       topic,
+		WorkflowID:  workflow.ID,
+		ExecutionID: uuid.New(),
+		NodeID:      "charge",
+		Payload:     map[string]interface{}{"amount": float64(42)},
+	}
+	require.NoError(t, db.CreateExternalTask(ctx, task))
+	return task
+}
+
+func TestExternalTasks_LockAndComplete(t *testing.T) {
+	db := newSQLiteDB(t)
+	ctx := context.Background()
+	task := newExternalTask(t, db, "charge-card")
+
+	none, err := db.LockExternalTasks(ctx, "worker-a", []string{"send-email"}, 10, time.Minute)
+	require.NoError(t, err)
+	assert.Empty(t, none)
+
+	locked, err := db.LockExternalTasks(ctx, "worker-a", []string{"charge-card"}, 10, time.Minute)
+	require.NoError(t, err)
+	require.Len(t, locked, 1)
+	assert.Equal(t, task.ID, locked[0].ID)
+	assert.Equal(t, models.ExternalTaskLocked, locked[0].Status)
+	assert.Equal(t, "worker-a", locked[0].WorkerID)
+	assert.Equal(t, 1, locked[0].Attempts)
+	assert.Equal(t, map[string]interface{}{"amount": float64(42)}, locked[0].Payload)
+
+	// A locked task is not handed to another worker
+	others, err := db.LockExternalTasks(ctx, "worker-b", []string{"charge-card"}, 10, time.Minute)
+	require.NoError(t, err)
+	assert.Empty(t, others)
+	assert.ErrorIs(t, db.CompleteExternalTask(ctx, task.ID, "worker-b", nil), storage.ErrExternalTaskNotLocked)
+
+	require.NoError(t, db.CompleteExternalTask(ctx, task.ID, "worker-a", map[string]interface{}{"charge_id": "ch_1"}))
+	stored, err := db.GetExternalTask(ctx, task.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.ExternalTaskCompleted, stored.Status)
+	assert.Equal(t, map[string]interface{}{"charge_id": "ch_1"}, stored.Result)
+	assert.Nil(t, stored.LockedUntil)
+
+	_, err = db.GetExternalTask(tenant.WithID(ctx, uuid.New()), task.ID)
+	assert.ErrorIs(t, err, storage.ErrExternalTaskNotFound)
+}
+
+func TestExternalTasks_ExpiredLocksAndRetries(t *testing.T) {
+	db := newSQLiteDB(t)
+	ctx := context.Background()
+	task := newExternalTask(t, db, "charge-card")
+
+	// A lock that has expired frees the task for another worker
+	_, err := db.LockExternalTasks(ctx, "worker-a", []string{"charge-card"}, 1, -time.Second)
+	require.NoError(t, err)
+	locked, err := db.LockExternalTasks(ctx, "worker-b", []string{"charge-card"}, 1, time.Minute)
+	require.NoError(t, err)
+	require.Len(t, locked, 1)
+	assert.Equal(t, 2, locked[0].Attempts)
+
+	// A failure with retries left makes the task available again once its
+	// retry timeout passes
+	retryAt := time.Now().Add(-time.Second)
+	require.NoError(t, db.FailExternalTask(ctx, task.ID, "worker-b", "card declined", &retryAt))
+	stored, err := db.GetExternalTask(ctx, task.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.ExternalTaskPending, stored.Status)
+	locked, err = db.LockExternalTasks(ctx, "worker-a", []string{"charge-card"}, 1, time.Minute)
+	require.NoError(t, err)
+	require.Len(t, locked, 1)
+
+	waiting := newExternalTask(t, db, "refund")
+	_, err = db.LockExternalTasks(ctx, "worker-a", []string{"refund"}, 1, time.Minute)
+	require.NoError(t, err)
+	retryAt = time.Now().Add(time.Hour)
+	require.NoError(t, db.FailExternalTask(ctx, waiting.ID, "worker-a", "gateway down", &retryAt))
+	none, err := db.LockExternalTasks(ctx, "worker-b", []string{"refund"}, 1, time.Minute)
+	require.NoError(t, err)
+	assert.Empty(t, none)
+
+	// Without retries the task fails
+	require.NoError(t, db.FailExternalTask(ctx, task.ID, "worker-a", "card declined", nil))
+	stored, err = db.GetExternalTask(ctx, task.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.ExternalTaskFailed, stored.Status)
+	require.NotNil(t, stored.Error)
+	assert.Equal(t, "card declined", *stored.Error)
+}
@@ -0,0 +1,130 @@
+package storage_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/nuumz/f1ow/internal/models"
+	"github.com/nuumz/f1ow/internal/storage"
+	"github.com/nuumz/f1ow/internal/tenant"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryRepository_WorkflowVersions(t *testing.T) {
+	repo := storage.NewMemoryRepository()
+	ctx := context.Background()
+
+	workflow := &models.Workflow{Name: "orders", Tags: []string{"billing"}}
+	require.NoError(t, repo.CreateWorkflow(ctx, workflow))
+	assert.Equal(t, 1, workflow.Version)
+	assert.Equal(t, models.WorkflowStatusDraft, workflow.Status)
+
+	workflow.Name = "orders v2"
+	require.NoError(t, repo.UpdateWorkflow(ctx, workflow))
+	assert.Equal(t, 2, workflow.Version)
+
+	versions, err := repo.ListWorkflowVersions(ctx, workflow.ID)
+	require.NoError(t, err)
+	require.Len(t, versions, 2)
+	assert.Equal(t, 2, versions[0].Version)
+
+	first, err := repo.GetWorkflowVersion(ctx, workflow.ID, 1)
+	require.NoError(t, err)
+	assert.Equal(t, "orders", first.Name)
+
+	// Changes to the caller's copy do not reach the store
+	workflow.Tags[0] = "changed"
+	stored, err := repo.GetWorkflow(ctx, workflow.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"billing"}, stored.Tags)
+
+	require.NoError(t, repo.DeleteWorkflow(ctx, workflow.ID))
+	listed, total, err := repo.ListWorkflows(ctx, storage.WorkflowListOptions{})
+	require.NoError(t, err)
+	assert.Empty(t, listed)
+	assert.Zero(t, total)
+}
+
+func TestMemoryRepository_TenantScoping(t *testing.T) {
+	repo := storage.NewMemoryRepository()
+	tenantA := tenant.WithID(context.Background(), uuid.New())
+	tenantB := tenant.WithID(context.Background(), uuid.New())
+
+	workflow := &models.Workflow{Name: "private"}
+	require.NoError(t, repo.CreateWorkflow(tenantA, workflow))
+
+	_, err := repo.GetWorkflow(tenantB, workflow.ID)
+	assert.ErrorIs(t, err, storage.ErrWorkflowNotFound)
+	assert.ErrorIs(t, repo.UpdateWorkflow(tenantB, workflow), storage.ErrWorkflowNotFound)
+
+	_, err = repo.GetWorkflow(tenantA, workflow.ID)
+	assert.NoError(t, err)
+}
+
+func TestMemoryRepository_ClaimFencing(t *testing.T) {
+	repo := storage.NewMemoryRepository()
+	ctx := context.Background()
+
+	execution := &models.Execution{WorkflowID: uuid.New(), Status: models.ExecutionStatusPending}
+	require.NoError(t, repo.CreateExecution(ctx, execution))
+
+	first, err := repo.ClaimExecution(ctx, execution.ID, "worker-1")
+	require.NoError(t, err)
+	require.NoError(t, repo.AppendJournal(ctx, execution.ID, first, "fetch", map[string]interface{}{"count": 1}))
+
+	// A second claim fences off the first owner
+	second, err := repo.ClaimExecution(ctx, execution.ID, "worker-2")
+	require.NoError(t, err)
+	assert.Greater(t, second, first)
+	assert.ErrorIs(t, repo.AppendJournal(ctx, execution.ID, first, "store", nil), storage.ErrExecutionFenced)
+	assert.ErrorIs(t, repo.SaveNodeExecutions(ctx, execution.ID, first, []models.NodeExecution{{NodeID: "store"}}),
+		storage.ErrExecutionFenced)
+
+	execution.ClaimToken = first
+	execution.Status = models.ExecutionStatusCompleted
+	assert.ErrorIs(t, repo.UpdateExecution(ctx, execution), storage.ErrExecutionFenced)
+
+	journal, err := repo.GetJournal(ctx, execution.ID)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"fetch": map[string]interface{}{"count": float64(1)}}, journal)
+
+	requeued, err := repo.RequeueExecution(ctx, execution.ID, "worker-1")
+	require.NoError(t, err)
+	assert.False(t, requeued)
+
+	cancelled, err := repo.CancelExecution(ctx, execution.ID, "stopped")
+	require.NoError(t, err)
+	assert.True(t, cancelled)
+	_, err = repo.ClaimExecution(ctx, execution.ID, "worker-3")
+	assert.ErrorIs(t, err, storage.ErrExecutionNotClaimable)
+}
+
+func TestMemoryRepository_ListExecutionsPages(t *testing.T) {
+	repo := storage.NewMemoryRepository()
+	ctx := context.Background()
+	workflowID := uuid.New()
+
+	for i := 0; i < 5; i++ {
+		require.NoError(t, repo.CreateExecution(ctx, &models.Execution{WorkflowID: workflowID, Status: models.ExecutionStatusCompleted}))
+	}
+
+	seen := map[uuid.UUID]bool{}
+	opts := storage.ExecutionListOptions{WorkflowID: &workflowID, Limit: 2}
+	for {
+		page, err := repo.ListExecutions(ctx, opts)
+		require.NoError(t, err)
+		assert.Equal(t, 5, page.Total)
+		for _, execution := range page.Executions {
+			assert.False(t, seen[execution.ID], "execution listed twice")
+			seen[execution.ID] = true
+		}
+		if page.NextCursor == "" {
+			break
+		}
+		opts.Cursor = page.NextCursor
+	}
+	assert.Len(t, seen, 5)
+}
@@ -0,0 +1,64 @@
+package storage_test
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/nuumz/f1ow/internal/storage"
+	"github.com/nuumz/f1ow/migrations"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadMigrations_OrdersByVersion(t *testing.T) {
+	fsys := fstest.MapFS{
+		"010_tenants.sql":       {Data: []byte("CREATE TABLE tenants (id TEXT);")},
+		"002_workflows.sql":     {Data: []byte("CREATE TABLE workflows (id TEXT);")},
+		"README.md":             {Data: []byte("not a migration")},
+		"mysql/001_initial.sql": {Data: []byte("CREATE TABLE x (id TEXT);")},
+	}
+
+	list, err := storage.LoadMigrations(fsys, ".")
+	require.NoError(t, err)
+	require.Len(t, list, 2)
+	assert.Equal(t, 2, list[0].Version)
+	assert.Equal(t, "workflows", list[0].Name)
+	assert.Equal(t, 10, list[1].Version)
+}
+
+func TestLoadMigrations_RejectsDuplicateVersions(t *testing.T) {
+	fsys := fstest.MapFS{
+		"003_a.sql": {Data: []byte("SELECT 1;")},
+		"003_b.sql": {Data: []byte("SELECT 2;")},
+	}
+
+	_, err := storage.LoadMigrations(fsys, ".")
+	assert.Error(t, err)
+}
+
+func TestLoadMigrations_EmbeddedSetsMatch(t *testing.T) {
+	postgres, err := storage.LoadMigrations(migrations.FS, ".")
+	require.NoError(t, err)
+	mysql, err := storage.LoadMigrations(migrations.FS, "mysql")
+	require.NoError(t, err)
+
+	require.NotEmpty(t, postgres)
+	assert.Equal(t, postgres[len(postgres)-1].Version, mysql[len(mysql)-1].Version)
+}
+
+func TestSplitStatements(t *testing.T) {
+	script := `-- Header comment
+CREATE TABLE a (
+    id INT -- inline
+);
+
+CREATE INDEX idx_a ON a(id);
+INSERT INTO a VALUES (1)`
+
+	statements := storage.SplitStatements(script)
+	require.Len(t, statements, 3)
+	assert.Equal(t, "CREATE TABLE a (\n    id INT -- inline\n);", statements[0])
+	assert.Equal(t, "CREATE INDEX idx_a ON a(id);", statements[1])
+	assert.Equal(t, "INSERT INTO a VALUES (1)", statements[2])
+}
@@ -0,0 +1,64 @@
+package storage_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/nuumz/f1ow/internal/models"
+	"github.com/nuumz/f1ow/internal/storage"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newSQLiteReplica returns the DSN of a SQLite file standing in for a
+// replica, migrated when migrated is true
+func newSQLiteReplica(t *testing.T, migrated bool) string {
+	t.Helper()
+	dsn := "sqlite://" + filepath.Join(t.TempDir(), "replica.db")
+	db, err := storage.NewDB(dsn)
+	require.NoError(t, err)
+	defer db.Close()
+	if migrated {
+		_, err = db.Migrate(context.Background())
+		require.NoError(t, err)
+	}
+	return dsn
+}
+
+func TestReplicas_ListingsReadFromReplica(t *testing.T) {
+	db := newSQLiteDB(t)
+	ctx := context.Background()
+	require.NoError(t, db.ConnectReplicas([]string{newSQLiteReplica(t, true)}))
+	require.NoError(t, db.CheckReplicas(ctx))
+
+	workflow := &models.Workflow{Name: "primary-only", UserID: createSQLiteUser(t, db), Status: models.WorkflowStatusActive}
+	require.NoError(t, db.CreateWorkflow(ctx, workflow))
+
+	// The stand-in replica never receives the write
+	_, total, err := db.ListWorkflows(ctx, storage.WorkflowListOptions{})
+	require.NoError(t, err)
+	assert.Zero(t, total)
+
+	// Reads by ID stay on the primary
+	loaded, err := db.GetWorkflow(ctx, workflow.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "primary-only", loaded.Name)
+}
+
+func TestReplicas_FallBackToPrimaryWhenReplicaFails(t *testing.T) {
+	db := newSQLiteDB(t)
+	ctx := context.Background()
+	// Without the schema every query on the replica fails
+	require.NoError(t, db.ConnectReplicas([]string{newSQLiteReplica(t, false)}))
+
+	workflow := &models.Workflow{Name: "fallback", UserID: createSQLiteUser(t, db), Status: models.WorkflowStatusActive}
+	require.NoError(t, db.CreateWorkflow(ctx, workflow))
+
+	workflows, total, err := db.ListWorkflows(ctx, storage.WorkflowListOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, 1, total)
+	require.Len(t, workflows, 1)
+	assert.Equal(t, workflow.ID, workflows[0].ID)
+}
@@ -0,0 +1,403 @@
+package storage_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/nuumz/f1ow/internal/models"
+	"github.com/nuumz/f1ow/internal/storage"
+	"github.com/nuumz/f1ow/internal/tenant"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newSQLiteDB(t *testing.T) *storage.DB {
+	t.Helper()
+	db, err := storage.NewDB("sqlite://" + filepath.Join(t.TempDir(), "f1ow.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	_, err = db.Migrate(context.Background())
+	require.NoError(t, err)
+	return db
+}
+
+func createSQLiteUser(t *testing.T, db *storage.DB) uuid.UUID {
+	t.Helper()
+	id := uuid.New()
+	_, err := db.Exec(`INSERT INTO users (id, email) VALUES ($1, $2)`, id, id.String()+"@example.com")
+	require.NoError(t, err)
+	return id
+}
+
+func TestSQLite_MigrateIsIdempotent(t *testing.T) {
+	db := newSQLiteDB(t)
+
+	applied, err := db.Migrate(context.Background())
+	require.NoError(t, err)
+	assert.Empty(t, applied)
+
+	statuses, err := db.MigrationStatus(context.Background())
+	require.NoError(t, err)
+	for _, status := range statuses {
+		assert.NotNil(t, status.AppliedAt, "%03d_%s", status.Version, status.Name)
+	}
+}
+
+func TestSQLite_WorkflowRoundTrip(t *testing.T) {
+	db := newSQLiteDB(t)
+	ctx := context.Background()
+
+	workflow := &models.Workflow{
+		Name:   "nightly",
+		UserID: createSQLiteUser(t, db),
+		Status: models.WorkflowStatusActive,
+		Tags:   []string{"etl", "nightly"},
+		Definition: models.WorkflowDefinition{
+			Nodes: []models.Node{{ID: "fetch", Type: "http"}},
+		},
+	}
+	require.NoError(t, db.CreateWorkflow(ctx, workflow))
+
+	loaded, err := db.GetWorkflow(ctx, workflow.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "nightly", loaded.Name)
+	assert.Equal(t, []string{"etl", "nightly"}, loaded.Tags)
+	assert.True(t, loaded.IsActive)
+	require.Len(t, loaded.Definition.Nodes, 1)
+	assert.Equal(t, "fetch", loaded.Definition.Nodes[0].ID)
+
+	tagged, total, err := db.ListWorkflows(ctx, storage.WorkflowListOptions{Tags: []string{"etl"}})
+	require.NoError(t, err)
+	assert.Equal(t, 1, total)
+	assert.Len(t, tagged, 1)
+
+	_, total, err = db.ListWorkflows(ctx, storage.WorkflowListOptions{Tags: []string{"hourly"}})
+	require.NoError(t, err)
+	assert.Zero(t, total)
+}
+
+func TestSQLite_WorkflowStats(t *testing.T) {
+	db := newSQLiteDB(t)
+	ctx := context.Background()
+
+	workflow := &models.Workflow{Name: "stats", UserID: createSQLiteUser(t, db), Status: models.WorkflowStatusActive}
+	require.NoError(t, db.CreateWorkflow(ctx, workflow))
+
+	since := time.Now().Add(-time.Hour)
+	for i, status := range []models.ExecutionStatus{models.ExecutionStatusCompleted, models.ExecutionStatusFailed} {
+		// CreateExecution starts the execution now
+		completed := time.Now().Add(time.Duration(i+1) * time.Second)
+		execution := &models.Execution{
+			WorkflowID:  workflow.ID,
+			Status:      status,
+			CompletedAt: &completed,
+			Context: models.ExecutionContext{NodeExecutions: map[string]models.NodeExecution{
+				"fetch": {NodeID: "fetch", Status: status},
+			}},
+		}
+		require.NoError(t, db.CreateExecution(ctx, execution))
+	}
+
+	stats, err := db.GetWorkflowStats(ctx, workflow.ID, since)
+	require.NoError(t, err)
+	assert.Equal(t, 2, stats.Total)
+	assert.Equal(t, 0.5, stats.SuccessRate)
+	assert.InDelta(t, 1000, stats.DurationP50Ms, 100)
+	assert.InDelta(t, 2000, stats.DurationP95Ms, 100)
+	require.Len(t, stats.PerDay, 1)
+	require.Len(t, stats.NodeFailures, 1)
+	assert.Equal(t, "fetch", stats.NodeFailures[0].NodeID)
+	require.NotNil(t, stats.LastFailure)
+}
+
+func TestSQLite_ClaimFencing(t *testing.T) {
+	db := newSQLiteDB(t)
+	ctx := context.Background()
+
+	workflow := &models.Workflow{Name: "fenced", UserID: createSQLiteUser(t, db), Status: models.WorkflowStatusActive}
+	require.NoError(t, db.CreateWorkflow(ctx, workflow))
+	execution := &models.Execution{WorkflowID: workflow.ID, Status: models.ExecutionStatusPending}
+	require.NoError(t, db.CreateExecution(ctx, execution))
+
+	stale, err := db.ClaimExecution(ctx, execution.ID, "worker-1")
+	require.NoError(t, err)
+	require.NoError(t, db.AppendJournal(ctx, execution.ID, stale, "fetch", map[string]interface{}{"rows": 3}))
+
+	// A second claim fences off the first owner
+	current, err := db.ClaimExecution(ctx, execution.ID, "worker-2")
+	require.NoError(t, err)
+	assert.Greater(t, current, stale)
+
+	assert.ErrorIs(t, db.AppendJournal(ctx, execution.ID, stale, "store", nil), storage.ErrExecutionFenced)
+	failed := "stale worker"
+	err = db.UpdateExecution(ctx, &models.Execution{ID: execution.ID, Status: models.ExecutionStatusFailed, Error: &failed, ClaimToken: stale},
+		models.NodeExecution{NodeID: "store", Status: models.ExecutionStatusFailed, StartedAt: time.Now()})
+	assert.ErrorIs(t, err, storage.ErrExecutionFenced)
+
+	stored, err := db.GetExecution(ctx, execution.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.ExecutionStatusRunning, stored.Status, "the stale update is not applied")
+	runs, err := db.ListNodeExecutions(ctx, execution.ID)
+	require.NoError(t, err)
+	assert.Empty(t, runs, "nor are its node runs")
+	journal, err := db.GetJournal(ctx, execution.ID)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"fetch": map[string]interface{}{"rows": float64(3)}}, journal)
+
+	// The current owner still writes
+	require.NoError(t, db.AppendJournal(ctx, execution.ID, current, "store", map[string]interface{}{"stored": true}))
+	completed := time.Now()
+	require.NoError(t, db.UpdateExecution(ctx, &models.Execution{ID: execution.ID, Status: models.ExecutionStatusCompleted,
+		CompletedAt: &completed, ClaimToken: current}))
+	stored, err = db.GetExecution(ctx, execution.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.ExecutionStatusCompleted, stored.Status)
+
+	// Cancelling moves the token on as well
+	cancelling := &models.Execution{WorkflowID: workflow.ID, Status: models.ExecutionStatusPending}
+	require.NoError(t, db.CreateExecution(ctx, cancelling))
+	token, err := db.ClaimExecution(ctx, cancelling.ID, "worker-1")
+	require.NoError(t, err)
+	cancelled, err := db.CancelExecution(ctx, cancelling.ID, "stopped")
+	require.NoError(t, err)
+	require.True(t, cancelled)
+	assert.ErrorIs(t, db.AppendJournal(ctx, cancelling.ID, token, "fetch", nil), storage.ErrExecutionFenced)
+	_, err = db.ClaimExecution(ctx, cancelling.ID, "worker-2")
+	assert.ErrorIs(t, err, storage.ErrExecutionNotClaimable)
+}
+
+func TestSQLite_QueuedJobs(t *testing.T) {
+	db := newSQLiteDB(t)
+	ctx := context.Background()
+
+	job := storage.QueuedJob{
+		ID:        uuid.New().String(),
+		Queue:     "default",
+		Score:     1,
+		Payload:   []byte(`{"id":"job-1"}`),
+		CreatedAt: time.Now(),
+	}
+	require.NoError(t, db.SaveQueuedJob(ctx, job))
+
+	jobs, err := db.ListQueuedJobs(ctx)
+	require.NoError(t, err)
+	require.Len(t, jobs, 1)
+	assert.Equal(t, job.ID, jobs[0].ID)
+	assert.JSONEq(t, `{"id":"job-1"}`, string(jobs[0].Payload))
+
+	require.NoError(t, db.DeleteQueuedJob(ctx, job.ID))
+	jobs, err = db.ListQueuedJobs(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, jobs)
+}
+
+func TestSQLite_NodeExecutions(t *testing.T) {
+	db := newSQLiteDB(t)
+	ctx := context.Background()
+
+	workflow := &models.Workflow{Name: "runs", UserID: createSQLiteUser(t, db), Status: models.WorkflowStatusActive}
+	require.NoError(t, db.CreateWorkflow(ctx, workflow))
+	execution := &models.Execution{WorkflowID: workflow.ID, Status: models.ExecutionStatusRunning}
+	require.NoError(t, db.CreateExecution(ctx, execution))
+
+	started := time.Now()
+	require.NoError(t, db.SaveNodeExecutions(ctx, execution.ID, 0, []models.NodeExecution{
+		{NodeID: "fetch", Status: models.ExecutionStatusRunning, StartedAt: started},
+	}))
+	runs, err := db.ListNodeExecutions(ctx, execution.ID)
+	require.NoError(t, err)
+	require.Len(t, runs, 1)
+	assert.Equal(t, models.ExecutionStatusRunning, runs[0].Status)
+
+	// A later run of the same node replaces the earlier one
+	completed := started.Add(time.Second)
+	require.NoError(t, db.SaveNodeExecutions(ctx, execution.ID, 0, []models.NodeExecution{
+		{NodeID: "fetch", Status: models.ExecutionStatusCompleted, StartedAt: started, CompletedAt: &completed,
+			Output: map[string]interface{}{"rows": float64(3)}},
+	}))
+
+	execution.Status = models.ExecutionStatusCompleted
+	require.NoError(t, db.UpdateExecution(ctx, execution, models.NodeExecution{
+		NodeID: "store", Status: models.ExecutionStatusCompleted, StartedAt: completed,
+	}))
+
+	loaded, err := db.GetExecution(ctx, execution.ID)
+	require.NoError(t, err)
+	require.Len(t, loaded.Context.NodeExecutions, 2)
+	assert.Equal(t, models.ExecutionStatusCompleted, loaded.Context.NodeExecutions["fetch"].Status)
+	assert.Equal(t, map[string]interface{}{"rows": float64(3)}, loaded.Context.NodeExecutions["fetch"].Output)
+	assert.Contains(t, loaded.Context.NodeExecutions, "store")
+}
+
+func TestSQLite_NodeSnapshot(t *testing.T) {
+	db := newSQLiteDB(t)
+	ctx := context.Background()
+
+	workflow := &models.Workflow{Name: "snapshots", UserID: createSQLiteUser(t, db), Status: models.WorkflowStatusActive}
+	require.NoError(t, db.CreateWorkflow(ctx, workflow))
+	execution := &models.Execution{WorkflowID: workflow.ID, Status: models.ExecutionStatusRunning}
+	require.NoError(t, db.CreateExecution(ctx, execution))
+
+	snapshot := &models.NodeSnapshot{
+		Input:     map[string]interface{}{"order": "A-1"},
+		Variables: map[string]interface{}{"order": "A-1"},
+		Upstream:  []string{"fetch"},
+	}
+	require.NoError(t, db.SaveNodeExecutions(ctx, execution.ID, 0, []models.NodeExecution{
+		{NodeID: "fetch", Status: models.ExecutionStatusCompleted, StartedAt: time.Now()},
+		{NodeID: "store", Status: models.ExecutionStatusCompleted, StartedAt: time.Now(), Snapshot: snapshot},
+	}))
+
+	loaded, err := db.GetNodeSnapshot(ctx, execution.ID, "store")
+	require.NoError(t, err)
+	assert.Equal(t, snapshot, loaded)
+
+	_, err = db.GetNodeSnapshot(ctx, execution.ID, "fetch")
+	assert.ErrorIs(t, err, storage.ErrNodeSnapshotNotFound, "runs recorded without a snapshot")
+	_, err = db.GetNodeSnapshot(ctx, execution.ID, "notify")
+	assert.ErrorIs(t, err, storage.ErrNodeSnapshotNotFound)
+}
+
+func TestSQLite_NodeExecutionsFenced(t *testing.T) {
+	db := newSQLiteDB(t)
+	ctx := context.Background()
+
+	workflow := &models.Workflow{Name: "fenced", UserID: createSQLiteUser(t, db), Status: models.WorkflowStatusActive}
+	require.NoError(t, db.CreateWorkflow(ctx, workflow))
+	execution := &models.Execution{WorkflowID: workflow.ID, Status: models.ExecutionStatusPending}
+	require.NoError(t, db.CreateExecution(ctx, execution))
+
+	stale, err := db.ClaimExecution(ctx, execution.ID, "worker-1")
+	require.NoError(t, err)
+	_, err = db.ClaimExecution(ctx, execution.ID, "worker-2")
+	require.NoError(t, err)
+
+	err = db.SaveNodeExecutions(ctx, execution.ID, stale, []models.NodeExecution{
+		{NodeID: "fetch", Status: models.ExecutionStatusCompleted, StartedAt: time.Now()},
+	})
+	assert.ErrorIs(t, err, storage.ErrExecutionFenced)
+
+	runs, err := db.ListNodeExecutions(ctx, execution.ID)
+	require.NoError(t, err)
+	assert.Empty(t, runs)
+}
+
+func TestSQLite_ExecutionUsage(t *testing.T) {
+	db := newSQLiteDB(t)
+	ctx := context.Background()
+	userID := createSQLiteUser(t, db)
+
+	finish := func(workflow *models.Workflow, usage models.ExecutionUsage) *models.Execution {
+		execution := &models.Execution{WorkflowID: workflow.ID, Status: models.ExecutionStatusRunning}
+		require.NoError(t, db.CreateExecution(ctx, execution))
+		completed := time.Now()
+		execution.Status, execution.CompletedAt, execution.Usage = models.ExecutionStatusCompleted, &completed, &usage
+		require.NoError(t, db.UpdateExecution(ctx, execution))
+		return execution
+	}
+	chatty := &models.Workflow{Name: "chatty", UserID: userID, Status: models.WorkflowStatusActive}
+	require.NoError(t, db.CreateWorkflow(ctx, chatty))
+	quiet := &models.Workflow{Name: "quiet", UserID: userID, Status: models.WorkflowStatusActive}
+	require.NoError(t, db.CreateWorkflow(ctx, quiet))
+
+	first := finish(chatty, models.ExecutionUsage{Nodes: 3, RuntimeMs: 120, HTTPBytesReceived: 2048, LLMPromptTokens: 50, LLMCompletionTokens: 20})
+	finish(chatty, models.ExecutionUsage{Nodes: 2, RuntimeMs: 80, HTTPBytesSent: 512})
+	finish(quiet, models.ExecutionUsage{Nodes: 1, RuntimeMs: 5})
+
+	stored, err := db.GetExecution(ctx, first.ID)
+	require.NoError(t, err)
+	require.NotNil(t, stored.Usage)
+	assert.Equal(t, *first.Usage, *stored.Usage)
+
+	period := storage.UsageReportOptions{From: time.Now().Add(-time.Hour), To: time.Now().Add(time.Hour)}
+	report, err := db.GetUsageReport(ctx, period)
+	require.NoError(t, err)
+	require.Len(t, report.Rollups, 1)
+	assert.Equal(t, 3, report.Executions)
+	assert.Equal(t, models.ExecutionUsage{
+		Nodes: 6, RuntimeMs: 205, HTTPBytesSent: 512, HTTPBytesReceived: 2048, LLMPromptTokens: 50, LLMCompletionTokens: 20,
+	}, report.Total)
+
+	period.ByWorkflow = true
+	report, err = db.GetUsageReport(ctx, period)
+	require.NoError(t, err)
+	require.Len(t, report.Rollups, 2)
+	byWorkflow := map[uuid.UUID]models.UsageRollup{}
+	for _, rollup := range report.Rollups {
+		byWorkflow[*rollup.WorkflowID] = rollup
+	}
+	assert.Equal(t, 2, byWorkflow[chatty.ID].Executions)
+	assert.Equal(t, 5, byWorkflow[chatty.ID].Usage.Nodes)
+	assert.Equal(t, 1, byWorkflow[quiet.ID].Executions)
+
+	// Other tenants and other periods see none of it
+	report, err = db.GetUsageReport(tenant.WithID(ctx, uuid.New()), period)
+	require.NoError(t, err)
+	assert.Empty(t, report.Rollups)
+	report, err = db.GetUsageReport(ctx, storage.UsageReportOptions{From: time.Now().Add(-2 * time.Hour), To: time.Now().Add(-time.Hour)})
+	require.NoError(t, err)
+	assert.Zero(t, report.Executions)
+}
+
+func TestSQLite_PlansAndTenantQuotas(t *testing.T) {
+	db := newSQLiteDB(t)
+	ctx := context.Background()
+	hundred, ten := 100, 10
+
+	plan := &models.Plan{Name: "team", Quotas: models.Quotas{MaxExecutionsPerDay: &hundred, MaxActiveWorkflows: &ten}}
+	require.NoError(t, db.SavePlan(ctx, plan))
+	plan.Description = "For teams"
+	require.NoError(t, db.SavePlan(ctx, plan))
+	plans, err := db.ListPlans(ctx)
+	require.NoError(t, err)
+	require.Len(t, plans, 1)
+	assert.Equal(t, "For teams", plans[0].Description)
+
+	five := 5
+	acme := &models.Tenant{Name: "acme", Plan: "team", Quotas: &models.Quotas{MaxActiveWorkflows: &five}}
+	require.NoError(t, db.CreateTenant(ctx, acme))
+	name, quotas, err := db.TenantQuotas(ctx, acme.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "team", name)
+	assert.Equal(t, 5, *quotas.MaxActiveWorkflows, "the tenant's own quota wins")
+	assert.Equal(t, 100, *quotas.MaxExecutionsPerDay, "the rest come from the plan")
+	assert.Nil(t, quotas.MaxPayloadSize)
+
+	assert.ErrorIs(t, db.UpdateTenantQuotas(ctx, acme.ID, models.TenantQuotas{Plan: "enterprise"}), storage.ErrPlanNotFound)
+	assert.ErrorIs(t, db.DeletePlan(ctx, "team"), storage.ErrPlanInUse)
+	require.NoError(t, db.UpdateTenantQuotas(ctx, acme.ID, models.TenantQuotas{}))
+	name, quotas, err = db.TenantQuotas(ctx, acme.ID)
+	require.NoError(t, err)
+	assert.Empty(t, name)
+	assert.Equal(t, models.Quotas{}, quotas)
+	require.NoError(t, db.DeletePlan(ctx, "team"))
+	assert.ErrorIs(t, db.DeletePlan(ctx, "team"), storage.ErrPlanNotFound)
+}
+
+func TestSQLite_QuotaCounts(t *testing.T) {
+	db := newSQLiteDB(t)
+	ctx := context.Background()
+	userID := createSQLiteUser(t, db)
+
+	active := &models.Workflow{Name: "active", UserID: userID, Status: models.WorkflowStatusActive}
+	require.NoError(t, db.CreateWorkflow(ctx, active))
+	require.NoError(t, db.CreateWorkflow(ctx, &models.Workflow{Name: "draft", UserID: userID}))
+	for i := 0; i < 2; i++ {
+		require.NoError(t, db.CreateExecution(ctx, &models.Execution{WorkflowID: active.ID, Status: models.ExecutionStatusPending}))
+	}
+
+	count, err := db.CountActiveWorkflows(ctx, tenant.DefaultID)
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+	count, err = db.CountExecutionsSince(ctx, tenant.DefaultID, time.Now().Add(-time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, 2, count)
+	count, err = db.CountExecutionsSince(ctx, uuid.New(), time.Now().Add(-time.Hour))
+	require.NoError(t, err)
+	assert.Zero(t, count)
+}
@@ -0,0 +1,56 @@
+package storage_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/nuumz/f1ow/internal/models"
+	"github.com/nuumz/f1ow/internal/storage"
+	"github.com/nuumz/f1ow/internal/tenant"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWorkflowState_SetGetDelete(t *testing.T) {
+	db := newSQLiteDB(t)
+	ctx := context.Background()
+	workflow := &models.Workflow{Name: "poller", UserID: createSQLiteUser(t, db), Status: models.WorkflowStatusActive}
+	require.NoError(t, db.CreateWorkflow(ctx, workflow))
+
+	_, err := db.GetWorkflowState(ctx, workflow.ID, "cursor")
+	assert.ErrorIs(t, err, storage.ErrStateNotFound)
+
+	cursor := map[string]interface{}{"after": "2026-10-01T00:00:00Z", "page": float64(3)}
+	require.NoError(t, db.SetWorkflowState(ctx, &models.WorkflowState{WorkflowID: workflow.ID, Key: "cursor", Value: cursor}))
+	state, err := db.GetWorkflowState(ctx, workflow.ID, "cursor")
+	require.NoError(t, err)
+	assert.Equal(t, cursor, state.Value)
+	assert.False(t, state.UpdatedAt.IsZero())
+
+	// Setting a key again replaces its value
+	require.NoError(t, db.SetWorkflowState(ctx, &models.WorkflowState{WorkflowID: workflow.ID, Key: "cursor", Value: "done"}))
+	state, err = db.GetWorkflowState(ctx, workflow.ID, "cursor")
+	require.NoError(t, err)
+	assert.Equal(t, "done", state.Value)
+
+	require.NoError(t, db.DeleteWorkflowState(ctx, workflow.ID, "cursor"))
+	_, err = db.GetWorkflowState(ctx, workflow.ID, "cursor")
+	assert.ErrorIs(t, err, storage.ErrStateNotFound)
+	assert.ErrorIs(t, db.DeleteWorkflowState(ctx, workflow.ID, "cursor"), storage.ErrStateNotFound)
+}
+
+func TestWorkflowState_ScopedToWorkflowTenant(t *testing.T) {
+	db := newSQLiteDB(t)
+	ctx := context.Background()
+	workflow := &models.Workflow{Name: "poller", UserID: createSQLiteUser(t, db), Status: models.WorkflowStatusActive}
+	require.NoError(t, db.CreateWorkflow(ctx, workflow))
+	require.NoError(t, db.SetWorkflowState(ctx, &models.WorkflowState{WorkflowID: workflow.ID, Key: "count", Value: 1}))
+
+	otherTenant := tenant.WithID(ctx, uuid.New())
+	_, err := db.GetWorkflowState(otherTenant, workflow.ID, "count")
+	assert.ErrorIs(t, err, storage.ErrWorkflowNotFound)
+	err = db.SetWorkflowState(otherTenant, &models.WorkflowState{WorkflowID: workflow.ID, Key: "count", Value: 2})
+	assert.ErrorIs(t, err, storage.ErrWorkflowNotFound)
+}
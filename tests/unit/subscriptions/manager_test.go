@@ -0,0 +1,258 @@
+package subscriptions_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/nuumz/f1ow/internal/models"
+	"github.com/nuumz/f1ow/internal/subscriptions"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type memoryStore struct {
+	mu            sync.Mutex
+	subscriptions map[uuid.UUID]models.EventSubscription
+	deliveries    map[uuid.UUID]models.EventDelivery
+}
+
+func newMemoryStore() *memoryStore {
+	return &memoryStore{
+		subscriptions: make(map[uuid.UUID]models.EventSubscription),
+		deliveries:    make(map[uuid.UUID]models.EventDelivery),
+	}
+}
+
+func (s *memoryStore) CreateEventSubscription(ctx context.Context, subscription *models.EventSubscription) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.subscriptions[subscription.ID] = *subscription
+	return nil
+}
+
+func (s *memoryStore) GetEventSubscription(ctx context.Context, id uuid.UUID) (*models.EventSubscription, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	subscription, ok := s.subscriptions[id]
+	if !ok {
+		return nil, subscriptions.ErrNotFound
+	}
+	return &subscription, nil
+}
+
+func (s *memoryStore) ListEventSubscriptions(ctx context.Context) ([]models.EventSubscription, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	list := []models.EventSubscription{}
+	for _, subscription := range s.subscriptions {
+		list = append(list, subscription)
+	}
+	return list, nil
+}
+
+func (s *memoryStore) UpdateEventSubscription(ctx context.Context, subscription *models.EventSubscription) error {
+	return s.CreateEventSubscription(ctx, subscription)
+}
+
+func (s *memoryStore) DeleteEventSubscription(ctx context.Context, id uuid.UUID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.subscriptions, id)
+	return nil
+}
+
+func (s *memoryStore) CreateEventDelivery(ctx context.Context, delivery *models.EventDelivery) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.deliveries[delivery.ID] = *delivery
+	return nil
+}
+
+func (s *memoryStore) UpdateEventDelivery(ctx context.Context, delivery *models.EventDelivery) error {
+	return s.CreateEventDelivery(ctx, delivery)
+}
+
+func (s *memoryStore) GetEventDelivery(ctx context.Context, id uuid.UUID) (*models.EventDelivery, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delivery, ok := s.deliveries[id]
+	if !ok {
+		return nil, subscriptions.ErrDeliveryNotFound
+	}
+	return &delivery, nil
+}
+
+func (s *memoryStore) ListEventDeliveries(ctx context.Context, subscriptionID uuid.UUID, limit int) ([]models.EventDelivery, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	list := []models.EventDelivery{}
+	for _, delivery := range s.deliveries {
+		if delivery.SubscriptionID == subscriptionID {
+			list = append(list, delivery)
+		}
+	}
+	return list, nil
+}
+
+func (s *memoryStore) ListDueEventDeliveries(ctx context.Context, now time.Time, limit int) ([]models.EventDelivery, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	list := []models.EventDelivery{}
+	for _, delivery := range s.deliveries {
+		if delivery.Status == models.EventDeliveryPending && delivery.NextAttemptAt != nil && !delivery.NextAttemptAt.After(now) {
+			list = append(list, delivery)
+		}
+	}
+	return list, nil
+}
+
+// receiver records the requests it gets and answers with status
+type receiver struct {
+	mu       sync.Mutex
+	status   int
+	requests []*http.Request
+	bodies   [][]byte
+}
+
+func (r *receiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	body, _ := io.ReadAll(req.Body)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.requests = append(r.requests, req)
+	r.bodies = append(r.bodies, body)
+	w.WriteHeader(r.status)
+}
+
+func setup(t *testing.T, events ...string) (*subscriptions.Manager, *memoryStore, *receiver, *models.EventSubscription) {
+	store := newMemoryStore()
+	manager := subscriptions.NewManager(store, logrus.New())
+	target := &receiver{status: http.StatusOK}
+	server := httptest.NewServer(target)
+	t.Cleanup(server.Close)
+
+	subscription, err := manager.Create(context.Background(), &models.EventSubscription{URL: server.URL, Events: events, Enabled: true})
+	require.NoError(t, err)
+	return manager, store, target, subscription
+}
+
+func TestManager_ValidatesSubscriptions(t *testing.T) {
+	manager := subscriptions.NewManager(newMemoryStore(), logrus.New())
+	ctx := context.Background()
+
+	for _, subscription := range []models.EventSubscription{
+		{URL: "ftp://example.com", Events: []string{"*"}},
+		{URL: "/relative", Events: []string{"*"}},
+		{URL: "https://example.com/hook"},
+		{URL: "https://example.com/hook", Events: []string{"failed"}},
+	} {
+		_, err := manager.Create(ctx, &subscription)
+		assert.ErrorIs(t, err, subscriptions.ErrInvalid, "%+v", subscription)
+	}
+
+	created, err := manager.Create(ctx, &models.EventSubscription{URL: "https://example.com/hook", Events: []string{"execution.*"}})
+	require.NoError(t, err)
+	assert.Contains(t, created.Secret, subscriptions.SecretPrefix, "a secret is generated and shown once")
+
+	fetched, err := manager.Get(ctx, created.ID)
+	require.NoError(t, err)
+	assert.Empty(t, fetched.Secret)
+}
+
+func TestManager_DeliversSignedMatchingEvents(t *testing.T) {
+	manager, store, target, subscription := setup(t, "execution.*", "workflow.deleted")
+	ctx := context.Background()
+
+	for _, eventType := range []string{"execution.failed", "workflow.updated", "workflow.deleted", "node.started"} {
+		require.NoError(t, manager.HandleEvent(ctx, subscriptions.Event{ID: "1-" + eventType, Type: eventType, Data: map[string]string{"k": "v"}}))
+	}
+
+	require.Len(t, target.requests, 2)
+	req, body := target.requests[0], target.bodies[0]
+	assert.Equal(t, "execution.failed", req.Header.Get(subscriptions.EventHeader))
+	timestamp, err := strconv.ParseInt(req.Header.Get(subscriptions.TimestampHeader), 10, 64)
+	require.NoError(t, err)
+	assert.Equal(t, subscriptions.Sign(subscription.Secret, timestamp, body), req.Header.Get(subscriptions.SignatureHeader))
+	assert.JSONEq(t, `{"id":"1-execution.failed","type":"execution.failed","data":{"k":"v"}}`, string(body))
+	assert.Equal(t, "workflow.deleted", target.requests[1].Header.Get(subscriptions.EventHeader))
+
+	deliveries, err := manager.Deliveries(ctx, subscription.ID, 0)
+	require.NoError(t, err)
+	require.Len(t, deliveries, 2)
+	for _, delivery := range deliveries {
+		assert.Equal(t, models.EventDeliverySucceeded, delivery.Status)
+		assert.Equal(t, 1, delivery.Attempts)
+		assert.NotNil(t, delivery.DeliveredAt)
+	}
+	assert.Len(t, store.deliveries, 2)
+}
+
+func TestManager_RetriesFailedDeliveriesWithBackoff(t *testing.T) {
+	manager, store, target, subscription := setup(t, "*")
+	ctx := context.Background()
+	target.status = http.StatusServiceUnavailable
+
+	require.NoError(t, manager.HandleEvent(ctx, subscriptions.Event{ID: "1-0", Type: "execution.failed"}))
+	deliveries, err := manager.Deliveries(ctx, subscription.ID, 0)
+	require.NoError(t, err)
+	require.Len(t, deliveries, 1)
+	delivery := deliveries[0]
+	assert.Equal(t, models.EventDeliveryPending, delivery.Status)
+	assert.Equal(t, http.StatusServiceUnavailable, *delivery.ResponseStatus)
+	require.NotNil(t, delivery.NextAttemptAt)
+	assert.WithinDuration(t, time.Now().Add(time.Minute), *delivery.NextAttemptAt, 5*time.Second)
+
+	// Not due yet
+	attempted, err := manager.RetryDue(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, attempted)
+
+	// Each failed retry waits longer, until the delivery is given up
+	for i := 0; i < 5; i++ {
+		stored := store.deliveries[delivery.ID]
+		past := time.Now().Add(-time.Second)
+		stored.NextAttemptAt = &past
+		store.deliveries[delivery.ID] = stored
+
+		attempted, err = manager.RetryDue(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, 1, attempted)
+	}
+	failed := store.deliveries[delivery.ID]
+	assert.Equal(t, models.EventDeliveryFailed, failed.Status)
+	assert.Equal(t, 6, failed.Attempts)
+	assert.Nil(t, failed.NextAttemptAt)
+	assert.Len(t, target.requests, 6)
+
+	// Redelivery sends the payload again as a new delivery
+	target.status = http.StatusNoContent
+	redelivered, err := manager.Redeliver(ctx, subscription.ID, delivery.ID)
+	require.NoError(t, err)
+	assert.NotEqual(t, delivery.ID, redelivered.ID)
+	assert.Equal(t, models.EventDeliverySucceeded, redelivered.Status)
+	assert.Equal(t, target.bodies[0], target.bodies[len(target.bodies)-1])
+
+	_, err = manager.Redeliver(ctx, uuid.New(), delivery.ID)
+	assert.ErrorIs(t, err, subscriptions.ErrNotFound)
+}
+
+func TestManager_SkipsDisabledSubscriptions(t *testing.T) {
+	manager, _, target, subscription := setup(t, "*")
+	ctx := context.Background()
+
+	subscription.Enabled = false
+	subscription.Secret = ""
+	_, err := manager.Update(ctx, subscription)
+	require.NoError(t, err)
+
+	require.NoError(t, manager.HandleEvent(ctx, subscriptions.Event{ID: "1-0", Type: "execution.failed"}))
+	assert.Empty(t, target.requests)
+}
@@ -0,0 +1,73 @@
+package triggers_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/nuumz/f1ow/internal/triggers"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const multipartMessage = "From: Ada Lovelace <ada@example.com>\r\n" +
+	"To: ops@example.com\r\n" +
+	"Subject: =?UTF-8?Q?Invoice_=E2=9C=93?=\r\n" +
+	"Message-Id: <abc123@example.com>\r\n" +
+	"Date: Mon, 02 Jan 2006 15:04:05 +0000\r\n" +
+	"MIME-Version: 1.0\r\n" +
+	"Content-Type: multipart/mixed; boundary=outer\r\n" +
+	"\r\n" +
+	"--outer\r\n" +
+	"Content-Type: multipart/alternative; boundary=inner\r\n" +
+	"\r\n" +
+	"--inner\r\n" +
+	"Content-Type: text/plain; charset=utf-8\r\n" +
+	"\r\n" +
+	"Please find the invoice attached.\r\n" +
+	"--inner\r\n" +
+	"Content-Type: text/html; charset=utf-8\r\n" +
+	"Content-Transfer-Encoding: quoted-printable\r\n" +
+	"\r\n" +
+	"<p>Please find the invoice =\r\nattached.</p>\r\n" +
+	"--inner--\r\n" +
+	"--outer\r\n" +
+	"Content-Type: application/pdf; name=invoice.pdf\r\n" +
+	"Content-Disposition: attachment; filename=invoice.pdf\r\n" +
+	"Content-Transfer-Encoding: base64\r\n" +
+	"\r\n" +
+	"JVBERi0x\r\nLjQK\r\n" +
+	"--outer--\r\n"
+
+func TestParseEmailMessage(t *testing.T) {
+	payload, err := triggers.ParseEmailMessage(strings.NewReader(multipartMessage), true)
+	require.NoError(t, err)
+
+	assert.Equal(t, "abc123@example.com", payload["message_id"])
+	assert.Equal(t, "Invoice ✓", payload["subject"])
+	assert.Equal(t, "2006-01-02T15:04:05Z", payload["date"])
+
+	from := payload["from"].([]interface{})
+	require.Len(t, from, 1)
+	assert.Equal(t, "ada@example.com", from[0].(map[string]interface{})["address"])
+
+	assert.Contains(t, payload["text"], "Please find the invoice attached.")
+	assert.Contains(t, payload["html"], "<p>Please find the invoice attached.</p>")
+
+	attachments := payload["attachments"].([]interface{})
+	require.Len(t, attachments, 1)
+	attachment := attachments[0].(map[string]interface{})
+	assert.Equal(t, "invoice.pdf", attachment["file_name"])
+	assert.Equal(t, "application/pdf", attachment["mime_type"])
+	assert.Equal(t, "JVBERi0xLjQK", attachment["data"])
+}
+
+func TestParseEmailMessage_PlainText(t *testing.T) {
+	raw := "From: a@example.com\r\nSubject: hi\r\n\r\nhello"
+
+	payload, err := triggers.ParseEmailMessage(strings.NewReader(raw), false)
+	require.NoError(t, err)
+
+	assert.Equal(t, "hello", payload["text"])
+	assert.Empty(t, payload["attachments"])
+}
@@ -0,0 +1,99 @@
+package triggers_test
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/nuumz/f1ow/internal/models"
+	"github.com/nuumz/f1ow/internal/tenant"
+	"github.com/nuumz/f1ow/internal/triggers"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeWatchedFile writes a file in the default tenant's directory of root
+func writeWatchedFile(t *testing.T, root, name, content string, modTime time.Time) {
+	filePath := filepath.Join(root, tenant.DefaultID.String(), name)
+	require.NoError(t, os.MkdirAll(filepath.Dir(filePath), 0o755))
+	require.NoError(t, os.WriteFile(filePath, []byte(content), 0o644))
+	require.NoError(t, os.Chtimes(filePath, modTime, modTime))
+}
+
+func newFileWatchTrigger(t *testing.T, root string, store *memoryStore, config map[string]interface{}) *triggers.FileWatchTrigger {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+
+	factory := triggers.NewFileWatchTriggerFactory(root, store, logger)
+	trigger, err := factory("wf-1", models.Node{ID: "watch", Type: "file_watch_trigger", Config: config})
+	require.NoError(t, err)
+	return trigger.(*triggers.FileWatchTrigger)
+}
+
+func TestFileWatchTrigger_EmitsNewAndModifiedFiles(t *testing.T) {
+	ctx := context.Background()
+	root := t.TempDir()
+	store := newMemoryStore()
+	config := map[string]interface{}{"path": "inbox", "pattern": "*.csv", "recursive": true}
+	base := time.Now().Add(-time.Hour)
+
+	// The first poll only records existing files
+	writeWatchedFile(t, root, "inbox/existing.csv", "a", base)
+	rec := &recorder{}
+	require.NoError(t, newFileWatchTrigger(t, root, store, config).Poll(ctx, rec.emit))
+	assert.Empty(t, rec.payloads)
+
+	writeWatchedFile(t, root, "inbox/new.csv", "b", base.Add(time.Minute))
+	writeWatchedFile(t, root, "inbox/nested/deep.csv", "c", base.Add(2*time.Minute))
+	writeWatchedFile(t, root, "inbox/ignored.txt", "d", base.Add(time.Minute))
+	writeWatchedFile(t, root, "inbox/existing.csv", "changed", base.Add(3*time.Minute))
+
+	require.NoError(t, newFileWatchTrigger(t, root, store, config).Poll(ctx, rec.emit))
+	require.Len(t, rec.payloads, 3)
+	assert.Equal(t, "inbox/new.csv", rec.payloads[0]["path"])
+	assert.Equal(t, "created", rec.payloads[0]["event"])
+	assert.Equal(t, "inbox/nested/deep.csv", rec.payloads[1]["path"])
+	assert.Equal(t, "inbox/existing.csv", rec.payloads[2]["path"])
+	assert.Equal(t, "modified", rec.payloads[2]["event"])
+	assert.Equal(t, "existing.csv", rec.payloads[2]["name"])
+
+	// Nothing changed since
+	rec.payloads = nil
+	require.NoError(t, newFileWatchTrigger(t, root, store, config).Poll(ctx, rec.emit))
+	assert.Empty(t, rec.payloads)
+}
+
+func TestFileWatchTrigger_MaxFilesAndEvents(t *testing.T) {
+	ctx := context.Background()
+	root := t.TempDir()
+	store := newMemoryStore()
+	config := map[string]interface{}{
+		"path":          "drop",
+		"events":        []interface{}{"created"},
+		"max_files":     1,
+		"emit_existing": true,
+	}
+	base := time.Now().Add(-time.Hour)
+
+	writeWatchedFile(t, root, "drop/a.bin", "a", base)
+	writeWatchedFile(t, root, "drop/b.bin", "b", base.Add(time.Minute))
+
+	rec := &recorder{}
+	require.NoError(t, newFileWatchTrigger(t, root, store, config).Poll(ctx, rec.emit))
+	require.Len(t, rec.payloads, 1)
+	assert.Equal(t, "drop/a.bin", rec.payloads[0]["path"])
+
+	// Modifications are recorded without starting executions
+	writeWatchedFile(t, root, "drop/a.bin", "changed", base.Add(2*time.Minute))
+	require.NoError(t, newFileWatchTrigger(t, root, store, config).Poll(ctx, rec.emit))
+	require.Len(t, rec.payloads, 2)
+	assert.Equal(t, "drop/b.bin", rec.payloads[1]["path"])
+
+	require.NoError(t, newFileWatchTrigger(t, root, store, config).Poll(ctx, rec.emit))
+	assert.Len(t, rec.payloads, 2)
+}
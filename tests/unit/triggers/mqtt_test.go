@@ -0,0 +1,157 @@
+package triggers_test
+
+import (
+	"context"
+	"io"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/nuumz/f1ow/internal/models"
+	"github.com/nuumz/f1ow/internal/nodes"
+	"github.com/nuumz/f1ow/internal/triggers"
+
+	"github.com/eclipse/paho.mqtt.golang/packets"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mqttBroker is a minimal MQTT broker that forwards published messages to
+// subscribers of the exact topic at QoS 0
+type mqttBroker struct {
+	addr        string
+	mu          sync.Mutex
+	subscribers map[string][]*mqttSession
+	subscribed  chan string
+}
+
+type mqttSession struct {
+	mu   sync.Mutex
+	conn net.Conn
+}
+
+func (s *mqttSession) write(packet packets.ControlPacket) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	packet.Write(s.conn)
+}
+
+func newMQTTBroker(t *testing.T) *mqttBroker {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+
+	broker := &mqttBroker{
+		addr:        listener.Addr().String(),
+		subscribers: make(map[string][]*mqttSession),
+		subscribed:  make(chan string, 10),
+	}
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			t.Cleanup(func() { conn.Close() })
+			go broker.serve(&mqttSession{conn: conn})
+		}
+	}()
+	return broker
+}
+
+func (b *mqttBroker) serve(session *mqttSession) {
+	for {
+		packet, err := packets.ReadPacket(session.conn)
+		if err != nil {
+			return
+		}
+
+		switch p := packet.(type) {
+		case *packets.ConnectPacket:
+			session.write(packets.NewControlPacket(packets.Connack))
+		case *packets.SubscribePacket:
+			b.mu.Lock()
+			for _, topic := range p.Topics {
+				b.subscribers[topic] = append(b.subscribers[topic], session)
+			}
+			b.mu.Unlock()
+			suback := packets.NewControlPacket(packets.Suback).(*packets.SubackPacket)
+			suback.MessageID = p.MessageID
+			suback.ReturnCodes = p.Qoss
+			session.write(suback)
+			for _, topic := range p.Topics {
+				b.subscribed <- topic
+			}
+		case *packets.PublishPacket:
+			if p.Qos == 1 {
+				puback := packets.NewControlPacket(packets.Puback).(*packets.PubackPacket)
+				puback.MessageID = p.MessageID
+				session.write(puback)
+			}
+			b.mu.Lock()
+			subscribers := b.subscribers[p.TopicName]
+			b.mu.Unlock()
+			for _, subscriber := range subscribers {
+				forward := packets.NewControlPacket(packets.Publish).(*packets.PublishPacket)
+				forward.TopicName = p.TopicName
+				forward.Payload = p.Payload
+				forward.Retain = p.Retain
+				subscriber.write(forward)
+			}
+		case *packets.PingreqPacket:
+			session.write(packets.NewControlPacket(packets.Pingresp))
+		case *packets.DisconnectPacket:
+			session.conn.Close()
+			return
+		}
+	}
+}
+
+func TestMQTTTrigger_ReceivesPublishedMessages(t *testing.T) {
+	broker := newMQTTBroker(t)
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+
+	factory := triggers.NewMQTTTriggerFactory(logger)
+	trigger, err := factory("wf-1", models.Node{ID: "sensors", Type: "mqtt_trigger", Config: map[string]interface{}{
+		"broker": "tcp://" + broker.addr, "topics": []interface{}{"sensors/temp"}, "skip_retained": true,
+	}})
+	require.NoError(t, err)
+
+	received := make(chan map[string]interface{}, 10)
+	require.NoError(t, trigger.Start(context.Background(), func(ctx context.Context, workflowID string, payload map[string]interface{}) error {
+		received <- payload
+		return nil
+	}))
+	defer trigger.Stop()
+
+	select {
+	case topic := <-broker.subscribed:
+		assert.Equal(t, "sensors/temp", topic)
+	case <-time.After(5 * time.Second):
+		t.Fatal("the trigger did not subscribe")
+	}
+
+	publish := func(message map[string]interface{}, retain bool) {
+		_, err := nodes.NewMQTTNode().Execute(context.Background(), map[string]interface{}{
+			"broker": "tcp://" + broker.addr, "topic": "sensors/{{sensor}}", "message": message, "qos": 1, "retain": retain, "timeout": 5,
+		}, map[string]interface{}{"sensor": "temp"})
+		require.NoError(t, err)
+	}
+
+	// Retained messages are skipped when configured
+	publish(map[string]interface{}{"id": "retained"}, true)
+	publish(map[string]interface{}{"id": "live"}, false)
+
+	select {
+	case payload := <-received:
+		assert.Equal(t, "sensors/temp", payload["topic"])
+		assert.Equal(t, map[string]interface{}{"id": "live"}, payload["payload"])
+		assert.Equal(t, false, payload["retained"])
+	case <-time.After(5 * time.Second):
+		t.Fatal("the trigger did not receive the message")
+	}
+	assert.Empty(t, received)
+}
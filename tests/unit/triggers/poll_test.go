@@ -0,0 +1,216 @@
+package triggers_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/nuumz/f1ow/internal/models"
+	"github.com/nuumz/f1ow/internal/triggers"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryStore implements the cache and seen stores in memory
+type memoryStore struct {
+	mu    sync.Mutex
+	cache map[string][]byte
+	seen  map[string]bool
+}
+
+func newMemoryStore() *memoryStore {
+	return &memoryStore{cache: map[string][]byte{}, seen: map[string]bool{}}
+}
+
+func (s *memoryStore) GetCache(ctx context.Context, key string) ([]byte, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	value, ok := s.cache[key]
+	return value, ok, nil
+}
+
+func (s *memoryStore) SetCache(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.cache[key] = value
+	return nil
+}
+
+func (s *memoryStore) DeleteCache(ctx context.Context, key string, prefix bool) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.cache, key)
+	return 1, nil
+}
+
+func (s *memoryStore) MarkSeen(ctx context.Context, scope string, keys []string, ttl time.Duration) ([]bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	seen := make([]bool, len(keys))
+	for i, key := range keys {
+		seen[i] = s.seen[scope+":"+key]
+		s.seen[scope+":"+key] = true
+	}
+	return seen, nil
+}
+
+// itemServer serves the current items under "data" with an ETag
+type itemServer struct {
+	mu    sync.Mutex
+	items []map[string]interface{}
+	etag  string
+}
+
+func (s *itemServer) set(etag string, items ...map[string]interface{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.items = items
+	s.etag = etag
+}
+
+func (s *itemServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if r.Header.Get("If-None-Match") == s.etag {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	w.Header().Set("ETag", s.etag)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"data": s.items})
+}
+
+type recorder struct {
+	payloads []map[string]interface{}
+}
+
+func (r *recorder) emit(ctx context.Context, workflowID string, payload map[string]interface{}) error {
+	r.payloads = append(r.payloads, payload)
+	return nil
+}
+
+func (r *recorder) ids() []interface{} {
+	ids := []interface{}{}
+	for _, payload := range r.payloads {
+		ids = append(ids, payload["id"])
+	}
+	r.payloads = nil
+	return ids
+}
+
+func newPollTrigger(t *testing.T, store *memoryStore, config map[string]interface{}) *triggers.PollTrigger {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+
+	factory := triggers.NewPollTriggerFactory(store, store, logger)
+	trigger, err := factory("wf-1", models.Node{ID: "poll", Type: "poll_trigger", Config: config})
+	require.NoError(t, err)
+	return trigger.(*triggers.PollTrigger)
+}
+
+func item(id int, updated string) map[string]interface{} {
+	return map[string]interface{}{"id": id, "updated_at": updated}
+}
+
+func TestPollTrigger_CursorEmitsOnlyNewItems(t *testing.T) {
+	ctx := context.Background()
+	server := &itemServer{}
+	ts := httptest.NewServer(server)
+	defer ts.Close()
+
+	store := newMemoryStore()
+	config := map[string]interface{}{
+		"request":      map[string]interface{}{"url": ts.URL, "method": "GET"},
+		"items_path":   "data",
+		"cursor_field": "updated_at",
+		"max_items":    2,
+	}
+	rec := &recorder{}
+
+	// The first poll only records the cursor
+	server.set("v1", item(1, "2024-01-01T00:00:00Z"), item(2, "2024-01-02T00:00:00Z"))
+	require.NoError(t, newPollTrigger(t, store, config).Poll(ctx, rec.emit))
+	assert.Empty(t, rec.ids())
+
+	// Not modified
+	require.NoError(t, newPollTrigger(t, store, config).Poll(ctx, rec.emit))
+	assert.Empty(t, rec.ids())
+
+	// New items are emitted oldest first, at most max_items per poll; the
+	// cursor survives a new trigger instance
+	server.set("v2",
+		item(5, "2024-01-05T00:00:00Z"),
+		item(2, "2024-01-02T00:00:00Z"),
+		item(3, "2024-01-03T00:00:00Z"),
+		item(4, "2024-01-04T00:00:00Z"),
+	)
+	require.NoError(t, newPollTrigger(t, store, config).Poll(ctx, rec.emit))
+	assert.Equal(t, []interface{}{float64(3), float64(4)}, rec.ids())
+
+	// The remaining item is fetched even though the ETag is unchanged
+	require.NoError(t, newPollTrigger(t, store, config).Poll(ctx, rec.emit))
+	assert.Equal(t, []interface{}{float64(5)}, rec.ids())
+
+	require.NoError(t, newPollTrigger(t, store, config).Poll(ctx, rec.emit))
+	assert.Empty(t, rec.ids())
+}
+
+func TestPollTrigger_IDDedupe(t *testing.T) {
+	ctx := context.Background()
+	server := &itemServer{}
+	ts := httptest.NewServer(server)
+	defer ts.Close()
+
+	store := newMemoryStore()
+	config := map[string]interface{}{
+		"request":       map[string]interface{}{"url": ts.URL, "method": "GET"},
+		"items_path":    "data",
+		"dedupe":        "id",
+		"id_field":      "id",
+		"emit_existing": true,
+	}
+	rec := &recorder{}
+
+	server.set("v1", item(1, ""), item(2, ""))
+	require.NoError(t, newPollTrigger(t, store, config).Poll(ctx, rec.emit))
+	assert.Equal(t, []interface{}{float64(1), float64(2)}, rec.ids())
+
+	server.set("v2", item(3, ""), item(1, ""), item(2, ""))
+	require.NoError(t, newPollTrigger(t, store, config).Poll(ctx, rec.emit))
+	assert.Equal(t, []interface{}{float64(3)}, rec.ids())
+}
+
+func TestPollTriggerFactory_ValidatesConfig(t *testing.T) {
+	factory := triggers.NewPollTriggerFactory(newMemoryStore(), newMemoryStore(), logrus.New())
+
+	_, err := factory("wf-1", models.Node{ID: "poll", Config: map[string]interface{}{
+		"request": map[string]interface{}{"url": "http://example.com", "method": "GET"},
+		"dedupe":  "cursor",
+	}})
+	assert.Error(t, err, "cursor dedupe requires cursor_field")
+
+	_, err = factory("wf-1", models.Node{ID: "poll", Config: map[string]interface{}{
+		"source": "database",
+		"driver": "sqlite",
+		"dsn":    "file.db",
+		"query":  "SELECT 1",
+		"dedupe": "hash",
+	}})
+	assert.Error(t, err)
+}
+
+func TestPollTriggerFactory_RequiresStateStore(t *testing.T) {
+	factory := triggers.NewPollTriggerFactory(nil, newMemoryStore(), logrus.New())
+
+	_, err := factory("wf-1", models.Node{ID: "poll", Config: map[string]interface{}{
+		"request": map[string]interface{}{"url": "http://example.com", "method": "GET"},
+	}})
+	assert.ErrorContains(t, err, "need Redis")
+}
@@ -0,0 +1,92 @@
+package triggers_test
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"testing"
+
+	"github.com/nuumz/f1ow/internal/triggers"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerifyGitHubSignature(t *testing.T) {
+	body := []byte(`{"action":"opened"}`)
+	mac := hmac.New(sha256.New, []byte("s3cret"))
+	mac.Write(body)
+	signature := "sha256=" + hex.EncodeToString(mac.Sum(nil))
+
+	assert.True(t, triggers.VerifyGitHubSignature("s3cret", body, signature))
+	assert.False(t, triggers.VerifyGitHubSignature("other", body, signature))
+	assert.False(t, triggers.VerifyGitHubSignature("s3cret", []byte(`{"action":"closed"}`), signature))
+	assert.False(t, triggers.VerifyGitHubSignature("s3cret", body, ""))
+	assert.False(t, triggers.VerifyGitHubSignature("", body, signature))
+}
+
+func TestVerifyGitLabToken(t *testing.T) {
+	assert.True(t, triggers.VerifyGitLabToken("token", "token"))
+	assert.False(t, triggers.VerifyGitLabToken("token", "wrong"))
+	assert.False(t, triggers.VerifyGitLabToken("", ""))
+}
+
+func TestEventAccepted(t *testing.T) {
+	assert.True(t, triggers.EventAccepted(nil, nil, "push", ""))
+	assert.True(t, triggers.EventAccepted([]string{"pull_request"}, []string{"opened"}, "pull_request", "opened"))
+	assert.False(t, triggers.EventAccepted([]string{"pull_request"}, nil, "push", ""))
+	assert.False(t, triggers.EventAccepted(nil, []string{"opened"}, "pull_request", "closed"))
+}
+
+func TestGitHubEventPayload(t *testing.T) {
+	var body map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"action": "opened",
+		"repository": {"full_name": "acme/api"},
+		"sender": {"login": "ada"},
+		"pull_request": {
+			"number": 42, "title": "Add cache", "state": "open", "merged": false,
+			"html_url": "https://github.com/acme/api/pull/42",
+			"user": {"login": "ada"},
+			"head": {"ref": "feature/cache", "sha": "abc123"},
+			"base": {"ref": "main"}
+		}
+	}`), &body))
+
+	payload := triggers.GitHubEventPayload("pull_request", body)
+
+	assert.Equal(t, "github", payload["provider"])
+	assert.Equal(t, "opened", payload["action"])
+	assert.Equal(t, "acme/api", payload["repository"])
+	assert.Equal(t, "ada", payload["sender"])
+	assert.Equal(t, float64(42), payload["number"])
+	assert.Equal(t, "feature/cache", payload["head_branch"])
+	assert.Equal(t, "main", payload["base_branch"])
+	assert.Equal(t, "abc123", payload["head_sha"])
+	assert.Equal(t, body, payload["payload"])
+}
+
+func TestGitLabEventPayloadPush(t *testing.T) {
+	var body map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"ref": "refs/heads/main", "before": "000", "after": "def456",
+		"user_username": "grace",
+		"project": {"path_with_namespace": "acme/api"},
+		"commits": [{"id": "def456", "message": "Fix build", "author": {"name": "Grace"}, "url": "https://gitlab.com/c/def456"}]
+	}`), &body))
+
+	event := triggers.GitLabEventName("Push Hook")
+	payload := triggers.GitLabEventPayload(event, body)
+
+	assert.Equal(t, "push", event)
+	assert.Equal(t, "gitlab", payload["provider"])
+	assert.Equal(t, "main", payload["branch"])
+	assert.Equal(t, "grace", payload["sender"])
+	assert.Equal(t, "acme/api", payload["repository"])
+
+	commits := payload["commits"].([]map[string]interface{})
+	require.Len(t, commits, 1)
+	assert.Equal(t, "Fix build", commits[0]["message"])
+	assert.Equal(t, "Grace", commits[0]["author"])
+}
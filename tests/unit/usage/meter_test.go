@@ -0,0 +1,57 @@
+package usage_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nuumz/f1ow/internal/usage"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMeter_CountsHTTPBodies(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(io.Discard, r.Body)
+		w.Write([]byte(strings.Repeat("x", 300)))
+	}))
+	defer server.Close()
+
+	meter := usage.NewMeter()
+	client := meter.Client(http.DefaultClient)
+	assert.Nil(t, http.DefaultClient.Transport, "the client passed in is not modified")
+
+	resp, err := client.Post(server.URL, "text/plain", strings.NewReader(strings.Repeat("y", 120)))
+	require.NoError(t, err)
+	_, err = io.Copy(io.Discard, resp.Body)
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	counted := meter.Usage()
+	assert.Equal(t, int64(120), counted.HTTPBytesSent)
+	assert.Equal(t, int64(300), counted.HTTPBytesReceived)
+}
+
+func TestMeter_Context(t *testing.T) {
+	ctx := context.Background()
+	_, ok := usage.FromContext(ctx)
+	assert.False(t, ok)
+	usage.AddTokens(ctx, 10, 5) // no meter, nothing counted
+
+	meter := usage.NewMeter()
+	ctx = usage.WithMeter(ctx, meter)
+	usage.AddTokens(ctx, 10, 5)
+	meter.AddNode(1500 * time.Millisecond)
+	meter.AddNode(500 * time.Millisecond)
+
+	counted := meter.Usage()
+	assert.Equal(t, 2, counted.Nodes)
+	assert.Equal(t, int64(2000), counted.RuntimeMs)
+	assert.Equal(t, int64(10), counted.LLMPromptTokens)
+	assert.Equal(t, int64(5), counted.LLMCompletionTokens)
+}
@@ -0,0 +1,218 @@
+package variables_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/nuumz/f1ow/internal/credentials"
+	"github.com/nuumz/f1ow/internal/engine"
+	"github.com/nuumz/f1ow/internal/models"
+	"github.com/nuumz/f1ow/internal/variables"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type memoryStore struct {
+	mu    sync.Mutex
+	items map[uuid.UUID]models.Variable
+}
+
+func newMemoryStore() *memoryStore {
+	return &memoryStore{items: make(map[uuid.UUID]models.Variable)}
+}
+
+func (s *memoryStore) CreateVariable(ctx context.Context, variable *models.Variable) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.items[variable.ID] = *variable
+	return nil
+}
+
+func (s *memoryStore) GetVariable(ctx context.Context, id uuid.UUID) (*models.Variable, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	variable, ok := s.items[id]
+	if !ok {
+		return nil, variables.ErrNotFound
+	}
+	return &variable, nil
+}
+
+func (s *memoryStore) ListVariables(ctx context.Context) ([]models.Variable, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	list := []models.Variable{}
+	for _, variable := range s.items {
+		list = append(list, variable)
+	}
+	return list, nil
+}
+
+func (s *memoryStore) UpdateVariable(ctx context.Context, variable *models.Variable) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.items[variable.ID]; !ok {
+		return variables.ErrNotFound
+	}
+	s.items[variable.ID] = *variable
+	return nil
+}
+
+func (s *memoryStore) DeleteVariable(ctx context.Context, id uuid.UUID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.items, id)
+	return nil
+}
+
+func newManager(t *testing.T) (*variables.Manager, *memoryStore) {
+	cipher, err := credentials.NewCipher("test-key")
+	require.NoError(t, err)
+	store := newMemoryStore()
+	return variables.NewManager(store, cipher), store
+}
+
+func TestManager_SecretsAreEncryptedAndHidden(t *testing.T) {
+	manager, store := newManager(t)
+	ctx := context.Background()
+
+	created, err := manager.Create(ctx, &models.Variable{Name: "API_TOKEN", Value: "s3cret", Secret: true})
+	require.NoError(t, err)
+	assert.Nil(t, created.Value)
+	assert.NotContains(t, store.items[created.ID].Data, "s3cret")
+
+	got, err := manager.Get(ctx, created.ID)
+	require.NoError(t, err)
+	assert.Nil(t, got.Value)
+
+	values, err := manager.Resolve(ctx, "")
+	require.NoError(t, err)
+	assert.Equal(t, "s3cret", values["API_TOKEN"])
+
+	_, err = variables.NewManager(newMemoryStore(), nil).Create(ctx, &models.Variable{Name: "TOKEN", Secret: true})
+	assert.ErrorIs(t, err, variables.ErrSecretsDisabled)
+}
+
+func TestManager_Validation(t *testing.T) {
+	manager, _ := newManager(t)
+	ctx := context.Background()
+
+	_, err := manager.Create(ctx, &models.Variable{Name: "not-valid"})
+	assert.ErrorIs(t, err, variables.ErrInvalidName)
+
+	_, err = manager.Create(ctx, &models.Variable{Name: "REGION", Value: "eu"})
+	require.NoError(t, err)
+	_, err = manager.Create(ctx, &models.Variable{Name: "REGION", Value: "us"})
+	assert.ErrorIs(t, err, variables.ErrExists)
+	_, err = manager.Create(ctx, &models.Variable{Name: "REGION", Value: "us", Environment: "production"})
+	assert.NoError(t, err)
+}
+
+func TestManager_EnvironmentOverridesGlobal(t *testing.T) {
+	manager, _ := newManager(t)
+	ctx := context.Background()
+
+	for _, variable := range []models.Variable{
+		{Name: "REGION", Value: "eu"},
+		{Name: "LIMIT", Value: 10.0},
+		{Name: "REGION", Value: "us", Environment: "production"},
+		{Name: "DEBUG", Value: true, Environment: "staging"},
+	} {
+		variable := variable
+		_, err := manager.Create(ctx, &variable)
+		require.NoError(t, err)
+	}
+
+	values, err := manager.Resolve(ctx, "production")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"REGION": "us", "LIMIT": 10.0}, values)
+
+	values, err = manager.Resolve(ctx, "")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"REGION": "eu", "LIMIT": 10.0}, values)
+
+	global := ""
+	list, err := manager.List(ctx, &global)
+	require.NoError(t, err)
+	assert.Len(t, list, 2)
+}
+
+// captureNode records the input it is executed with
+type captureNode struct {
+	input map[string]interface{}
+}
+
+func (n *captureNode) Execute(ctx context.Context, config interface{}, input interface{}) (interface{}, error) {
+	n.input, _ = input.(map[string]interface{})
+	return map[string]interface{}{}, nil
+}
+func (n *captureNode) ValidateConfig(config interface{}) error { return nil }
+func (n *captureNode) GetSchema() engine.NodeSchema            { return engine.NodeSchema{} }
+func (n *captureNode) Type() string                            { return "capture" }
+func (n *captureNode) Name() string                            { return "Capture" }
+func (n *captureNode) Description() string                     { return "" }
+func (n *captureNode) Category() string                        { return "" }
+func (n *captureNode) Icon() string                            { return "" }
+
+func TestEngine_VariablePrecedence(t *testing.T) {
+	manager, _ := newManager(t)
+	ctx := context.Background()
+	for _, variable := range []models.Variable{
+		{Name: "REGION", Value: "eu"},
+		{Name: "OWNER", Value: "ops"},
+		{Name: "TIER", Value: "global"},
+		{Name: "REGION", Value: "us", Environment: "production"},
+		{Name: "TIER", Value: "production", Environment: "production"},
+	} {
+		variable := variable
+		_, err := manager.Create(ctx, &variable)
+		require.NoError(t, err)
+	}
+
+	eng := engine.NewEngine(nil, nil, engine.WithVariables(manager), engine.WithEnvironment("production"))
+	node := &captureNode{}
+	require.NoError(t, eng.RegisterNode("capture", node))
+
+	workflow := &models.Workflow{Definition: models.WorkflowDefinition{
+		Nodes:     []models.Node{{ID: "a", Type: "capture"}},
+		Variables: map[string]interface{}{"TIER": "workflow"},
+	}}
+	_, err := eng.Run(ctx, workflow, nil)
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]interface{}{"REGION": "us", "OWNER": "ops", "TIER": "workflow"}, node.input["vars"])
+}
+
+func TestEngine_NodeSnapshotRedactsSecrets(t *testing.T) {
+	manager, _ := newManager(t)
+	ctx := context.Background()
+	for _, variable := range []models.Variable{
+		{Name: "API_TOKEN", Value: "s3cret", Secret: true},
+		{Name: "REGION", Value: "eu"},
+	} {
+		variable := variable
+		_, err := manager.Create(ctx, &variable)
+		require.NoError(t, err)
+	}
+
+	eng := engine.NewEngine(nil, nil, engine.WithVariables(manager))
+	require.NoError(t, eng.RegisterNode("capture", &captureNode{}))
+
+	workflow := &models.Workflow{Definition: models.WorkflowDefinition{
+		Nodes: []models.Node{{ID: "a", Type: "capture"}, {ID: "b", Type: "capture"}},
+		Edges: []models.Edge{{Source: "a", Target: "b"}},
+	}}
+	execution, err := eng.Run(ctx, workflow, map[string]interface{}{"auth": "Bearer s3cret", "order": "A-1"})
+	require.NoError(t, err)
+
+	snapshot := execution.Context.NodeExecutions["b"].Snapshot
+	require.NotNil(t, snapshot)
+	assert.Equal(t, []string{"a"}, snapshot.Upstream)
+	assert.NotContains(t, snapshot.Input, "nodeOutputs", "upstream outputs are stored with their own runs")
+	assert.Equal(t, map[string]interface{}{"API_TOKEN": "[redacted]", "REGION": "eu"}, snapshot.Input["vars"])
+	assert.Equal(t, "[redacted]", snapshot.Input["auth"])
+	assert.Equal(t, map[string]interface{}{"auth": "[redacted]", "order": "A-1"}, snapshot.Variables)
+}